@@ -1,14 +1,17 @@
 module github.com/saeedalam/teamcontext
 
-go 1.22
+go 1.23
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/smacker/go-tree-sitter v0.0.0-20240827094217-dd81d9e9be82
 	github.com/spf13/cobra v1.8.0
+	github.com/tiktoken-go/tokenizer v0.6.2
 	modernc.org/sqlite v1.29.0
 )
 
 require (
+	github.com/dlclark/regexp2 v1.11.5 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/saeedalam/teamcontext/internal/extractor"
 	"github.com/saeedalam/teamcontext/internal/imports"
 	"github.com/saeedalam/teamcontext/internal/search"
 	"github.com/saeedalam/teamcontext/internal/skeleton"
@@ -38,19 +39,23 @@ const (
 // maxSnippetLines caps each snippet to keep response compact.
 const maxSnippetLines = 20
 
-// maxExamples caps the number of examples returned.
-const maxExamples = 3
+// defaultMaxExamples caps the number of examples returned.
+const defaultMaxExamples = 3
 
-// maxImportsPerType caps imports per file type.
-const maxImportsPerType = 5
+// defaultMaxImportsPerType caps imports per file type.
+const defaultMaxImportsPerType = 5
 
+// Upper bounds for configured limits; past them the token budget would
+// cut most of what they add.
+const (
+	maxExamplesLimit       = 10
+	maxImportsPerTypeLimit = 20
+)
 
 
-// tokenBudget is the approximate max token count for the whole response.
-const tokenBudget = 4000
 
-// charsPerToken is a rough estimate for token counting.
-const charsPerToken = 4
+// tokenBudget is the max token count for the whole response.
+const tokenBudget = 4000
 
 // Blueprint is the structured output for AI agents
 type Blueprint struct {
@@ -68,6 +73,9 @@ type Blueprint struct {
 	// Templatized code snippets per file type
 	Snippets map[string]*SnippetEntry `json:"snippets,omitempty"`
 
+	// What each placeholder in snippets and imports stood for in the example
+	Placeholders map[string]string `json:"placeholders,omitempty"`
+
 	// Required imports per file type
 	Imports map[string][]string `json:"imports,omitempty"`
 
@@ -80,15 +88,31 @@ type Blueprint struct {
 	// Warnings and pitfalls to avoid
 	Warnings []Warning `json:"warnings,omitempty"`
 
+	// Sensitive paths (from config) the task touches, and who must review it
+	SensitivePaths    []string `json:"sensitive_paths,omitempty"`
+	RequiredReviewers []string `json:"required_reviewers,omitempty"`
+
 	// Files that typically change together
 	Correlations []Correlation `json:"correlations,omitempty"`
 
 	// Step-by-step checklist (app-specific)
 	Checklist []string `json:"checklist,omitempty"`
 
+	// How tests are run here, closest to the target first
+	TestCommands []TestCommand `json:"test_commands,omitempty"`
+
+	// How tests build their data and set up their environment (add-test only)
+	Fixtures *Fixtures `json:"fixtures,omitempty"`
+
+	// What was trimmed to fit the token budget
+	Budget *BudgetReport `json:"budget,omitempty"`
+
 	// Metadata
-	Confidence float64 `json:"confidence"` // 0-1, how confident we are in this blueprint
-	Source     string  `json:"source"`     // Where this blueprint came from
+	Confidence            float64               `json:"confidence"`       // 0-1, calibrated (see confidence.go)
+	ConfidenceLevel       string                `json:"confidence_level"` // high, medium, low
+	ConfidenceBreakdown   []ConfidenceComponent `json:"confidence_breakdown,omitempty"`
+	ConfidenceExplanation []string              `json:"confidence_explanation,omitempty"`
+	Source                string                `json:"source"` // Where this blueprint came from
 }
 
 // FilePattern describes the file structure for a task
@@ -101,9 +125,10 @@ type FilePattern struct {
 
 // Example is a real file to use as a pattern
 type Example struct {
-	Path        string `json:"path"`
-	Description string `json:"description"`
-	Skeleton    string `json:"skeleton,omitempty"`
+	Path        string   `json:"path"`
+	Description string   `json:"description"`
+	Skeleton    string   `json:"skeleton,omitempty"`
+	Routes      []string `json:"routes,omitempty"` // NestJS controllers: "GET /users/:id -> UsersController.findOne [JwtAuthGuard]"
 }
 
 // SnippetEntry is a templatized code snippet for a file type.
@@ -122,6 +147,8 @@ type Conventions struct {
 	DI              string           `json:"di,omitempty"`
 	ErrorHandling   string           `json:"error_handling,omitempty"`
 	Naming          *NamingConvention `json:"naming,omitempty"`
+
+	envelopeMarker string // literal a handler using the envelope contains
 }
 
 // NamingConvention holds detected naming patterns.
@@ -159,6 +186,14 @@ type Generator struct {
 	projectRoot string
 	tcDir       string
 	jsonStore   *storage.JSONStore
+	templates   map[string]*nameTemplate // by feature name
+
+	warnings       []types.Warning // team warnings, loaded once per Generate
+	warningsLoaded bool
+
+	limits      types.BlueprintConfig // set by the caller, over the project config
+	maxExamples int                   // resolved once per Generate
+	maxImports  int
 }
 
 // NewGenerator creates a blueprint generator
@@ -167,18 +202,21 @@ func NewGenerator(projectRoot, tcDir string, jsonStore *storage.JSONStore) *Gene
 		projectRoot: projectRoot,
 		tcDir:       tcDir,
 		jsonStore:   jsonStore,
+		maxExamples: defaultMaxExamples,
+		maxImports:  defaultMaxImportsPerType,
 	}
 }
 
 // Generate creates a blueprint for the given task
 func (g *Generator) Generate(taskType TaskType, app, path string) (*Blueprint, error) {
 	bp := &Blueprint{
-		TaskType:   taskType,
-		App:        app,
-		Path:       path,
-		Confidence: 0.5,
-		Source:     "pattern-analysis-v2",
+		TaskType: taskType,
+		App:      app,
+		Path:     path,
+		Source:   "pattern-analysis-v2",
 	}
+	g.warnings, g.warningsLoaded = nil, false
+	g.resolveLimits()
 
 	bp.Description = g.getTaskDescription(taskType)
 
@@ -197,16 +235,86 @@ func (g *Generator) Generate(taskType TaskType, app, path string) (*Blueprint, e
 		g.generateGenericBlueprint(bp)
 	}
 
+	// Concrete test commands instead of generic "run tests" advice
+	bp.TestCommands = DetectTestCommands(g.projectRoot, g.testCommandDir(bp))
+	bp.Checklist = ChecklistWithTestCommands(bp.Checklist, bp.TestCommands)
+	if taskType == TaskAddTest && len(bp.TestCommands) > 0 && bp.TestCommands[0].Single != "" {
+		bp.Checklist = append(bp.Checklist, "Run just the new test — `"+bp.TestCommands[0].Single+"`")
+	}
+
 	g.addRelevantDecisions(bp)
 	g.addRelevantWarnings(bp)
+	g.addSensitivePaths(bp)
 	g.addCorrelations(bp)
 
+	// Score what was found before the budget trims anything
+	g.calibrateConfidence(bp)
+
 	// Enforce token budget
 	g.enforceTokenBudget(bp)
 
 	return bp, nil
 }
 
+// SetLimits overrides the project config's example and import limits for
+// the next blueprints; zero values keep the config's
+func (g *Generator) SetLimits(limits types.BlueprintConfig) {
+	g.limits = limits
+}
+
+// resolveLimits picks each limit from the caller, else the project config,
+// else the default, capped to keep blueprints within budget
+func (g *Generator) resolveLimits() {
+	var cfg types.BlueprintConfig
+	if g.jsonStore != nil {
+		if c, err := g.jsonStore.GetConfig(); err == nil {
+			cfg = c.Blueprint
+		}
+	}
+	pick := func(request, config, def, limit int) int {
+		n := def
+		if config > 0 {
+			n = config
+		}
+		if request > 0 {
+			n = request
+		}
+		if n > limit {
+			n = limit
+		}
+		return n
+	}
+	g.maxExamples = pick(g.limits.MaxExamples, cfg.MaxExamples, defaultMaxExamples, maxExamplesLimit)
+	g.maxImports = pick(g.limits.MaxImportsPerType, cfg.MaxImportsPerType, defaultMaxImportsPerType, maxImportsPerTypeLimit)
+}
+
+// testCommandDir is where test commands are looked up from: the target's
+// directory, else the app's, else the project root
+func (g *Generator) testCommandDir(bp *Blueprint) string {
+	if bp.Path != "" {
+		path := bp.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(g.projectRoot, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+		return filepath.Dir(path)
+	}
+	if bp.App != "" {
+		if module, ok := g.gradleModule(bp.App); ok {
+			return filepath.Join(g.projectRoot, filepath.FromSlash(module.Dir))
+		}
+		for _, dir := range []string{"apps", "packages", "services", ""} {
+			candidate := filepath.Join(g.projectRoot, dir, bp.App)
+			if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+				return candidate
+			}
+		}
+	}
+	return g.projectRoot
+}
+
 func (g *Generator) getTaskDescription(taskType TaskType) string {
 	descriptions := map[TaskType]string{
 		TaskAddEndpoint: "Add a new REST API endpoint with controller, service, and module",
@@ -229,12 +337,12 @@ func (g *Generator) getTaskDescription(taskType TaskType) string {
 func (g *Generator) generateEndpointBlueprint(bp *Blueprint) {
 	framework := g.detectFramework()
 
-	// Find examples (prefer newest/best)
-	examples := g.findEndpointExamples(bp.App)
+	// Detect conventions first so examples can be ranked by compliance
+	conventions := g.detectConventions(bp.App)
+
+	// Find examples (ranked by quality, newest breaks ties)
+	examples := g.findEndpointExamples(bp.App, conventions)
 	bp.Examples = examples
-	if len(examples) > 0 {
-		bp.Confidence += 0.3
-	}
 
 	// File pattern based on detected framework
 	switch framework {
@@ -263,6 +371,17 @@ func (g *Generator) generateEndpointBlueprint(bp *Blueprint) {
 		bp.FilePattern = g.rustAxumEndpointPattern(bp.App)
 	case "rust":
 		bp.FilePattern = g.rustGenericEndpointPattern(bp.App)
+	// Dart
+	case "flutter", "dart":
+		bp.FilePattern = g.flutterFeaturePattern(bp.App)
+	// PHP and Ruby
+	case "php-laravel":
+		bp.FilePattern = g.laravelEndpointPattern()
+	case "ruby-rails":
+		bp.FilePattern = g.railsEndpointPattern()
+	// Gradle: Android, Kotlin Multiplatform, Spring Boot, Kotlin, Java
+	case "android", "kotlin-multiplatform", "spring-boot", "kotlin", "java":
+		bp.FilePattern = g.gradleFeaturePattern(bp.App, framework)
 	default:
 		bp.FilePattern = g.genericEndpointPattern(bp.App)
 	}
@@ -271,25 +390,23 @@ func (g *Generator) generateEndpointBlueprint(bp *Blueprint) {
 	bp.Source = "pattern-analysis:" + framework
 
 	// --- v2: Extract snippets from best example ---
-	if len(examples) > 0 {
+	if layers := g.layeredFiles(framework); layers != nil && len(examples) > 0 {
+		// Laravel and Rails: the example is a controller, the feature's
+		// other files are found by name in their own directories
+		bp.Snippets = g.extractLayeredSnippets(examples[0].Path, layers)
+		bp.Placeholders = g.nameTemplate(layeredFeatureName(examples[0].Path, layers)).placeholders
+	} else if len(examples) > 0 {
 		bestExample := examples[0]
 		exDir := filepath.Join(g.projectRoot, bestExample.Path)
 		featureName := g.extractFeatureName(bestExample.Path)
 
 		bp.Snippets = g.extractSnippets(exDir, featureName)
 		bp.Imports = g.extractImports(exDir, featureName)
-
-		// Add skeleton to the best example
-		if len(bp.Snippets) > 0 {
-			bp.Confidence += 0.1
-		}
+		bp.Placeholders = g.nameTemplate(featureName).placeholders
 	}
 
 	// --- v2: Detect conventions ---
-	bp.Conventions = g.detectConventions(bp.App)
-	if bp.Conventions != nil {
-		bp.Confidence += 0.1
-	}
+	bp.Conventions = conventions
 
 	// --- v2: App-specific checklist based on framework ---
 	bp.Checklist = g.buildEndpointChecklist(bp.Conventions, framework)
@@ -298,9 +415,6 @@ func (g *Generator) generateEndpointBlueprint(bp *Blueprint) {
 func (g *Generator) generateFeatureBlueprint(bp *Blueprint) {
 	examples := g.findFeatureExamples(bp.App)
 	bp.Examples = examples
-	if len(examples) > 0 {
-		bp.Confidence += 0.3
-	}
 
 	bp.FilePattern = &FilePattern{
 		BasePath: g.inferBasePath(bp.App, "feature"),
@@ -320,6 +434,7 @@ func (g *Generator) generateFeatureBlueprint(bp *Blueprint) {
 		featureName := g.extractFeatureName(bestExample.Path)
 		bp.Snippets = g.extractSnippets(exDir, featureName)
 		bp.Imports = g.extractImports(exDir, featureName)
+		bp.Placeholders = g.nameTemplate(featureName).placeholders
 	}
 
 	bp.Conventions = g.detectConventions(bp.App)
@@ -328,7 +443,6 @@ func (g *Generator) generateFeatureBlueprint(bp *Blueprint) {
 
 func (g *Generator) generateBugFixBlueprint(bp *Blueprint) {
 	if bp.Path == "" {
-		bp.Confidence = 0.1
 		bp.Checklist = []string{
 			"Provide a file path for targeted bug-fix guidance",
 			"Without a path, only generic advice is available",
@@ -336,8 +450,6 @@ func (g *Generator) generateBugFixBlueprint(bp *Blueprint) {
 		return
 	}
 
-	bp.Confidence = 0.6
-
 	// Extract skeleton of the target file
 	absPath := bp.Path
 	if !filepath.IsAbs(absPath) {
@@ -373,7 +485,6 @@ func (g *Generator) generateBugFixBlueprint(bp *Blueprint) {
 
 func (g *Generator) generateRefactorBlueprint(bp *Blueprint) {
 	if bp.Path == "" {
-		bp.Confidence = 0.1
 		bp.Checklist = []string{
 			"Provide a file path for targeted refactor guidance",
 			"Without a path, only generic advice is available",
@@ -381,8 +492,6 @@ func (g *Generator) generateRefactorBlueprint(bp *Blueprint) {
 		return
 	}
 
-	bp.Confidence = 0.6
-
 	// Extract skeleton of the target file
 	absPath := bp.Path
 	if !filepath.IsAbs(absPath) {
@@ -420,7 +529,6 @@ func (g *Generator) generateTestBlueprint(bp *Blueprint) {
 	// v2: Scope test examples to the correct app
 	bp.Examples = g.findTestExamplesForApp(bp.App, testFramework)
 	if len(bp.Examples) > 0 {
-		bp.Confidence += 0.2
 		// Extract test snippet from best example
 		bestTest := bp.Examples[0]
 		testPath := filepath.Join(g.projectRoot, bestTest.Path)
@@ -430,10 +538,15 @@ func (g *Generator) generateTestBlueprint(bp *Blueprint) {
 			bp.Snippets = map[string]*SnippetEntry{"test": snippet}
 		}
 		bp.Imports = g.extractImportsFromFile(testPath, featureName)
+		bp.Placeholders = g.nameTemplate(featureName).placeholders
 	}
 
 	bp.Conventions = g.detectConventions(bp.App)
 	bp.Checklist = g.buildTestChecklist(bp.Conventions, testFramework)
+
+	// Reuse the project's factories and hooks rather than ad-hoc test data
+	bp.Fixtures = DetectFixtures(g.projectRoot, g.testCommandDir(bp))
+	bp.Checklist = append(bp.Checklist, fixtureChecklist(bp.Fixtures)...)
 }
 
 func (g *Generator) generateGenericBlueprint(bp *Blueprint) {
@@ -450,8 +563,27 @@ func (g *Generator) generateGenericBlueprint(bp *Blueprint) {
 // Snippet extraction (v2 core)
 // ---------------------------------------------------------------------------
 
+// namedFileTypes are the suffixes of handler files named after their
+// feature, mapped to snippet keys
+var namedFileTypes = []struct{ suffix, key string }{
+	{"_handler.go", "controller"},
+	{"_service.go", "service"},
+	{"Controller.java", "controller"},
+	{"Service.java", "service"},
+	{"Controller.kt", "controller"},
+	{"Service.kt", "service"},
+	{"_router.py", "controller"},
+	{"_routes.py", "controller"},
+	{"_views.py", "controller"},
+	{"_service.py", "service"},
+	{"_screen.dart", "screen"},
+	{"_page.dart", "screen"},
+	{"_service.dart", "service"},
+	{"_repository.dart", "service"},
+}
+
 // extractSnippets reads files from the example directory, parses their
-// skeleton, and templatizes the feature name to {Name}/{name}.
+// skeleton, and templatizes the feature name (see templatize).
 func (g *Generator) extractSnippets(exDir, featureName string) map[string]*SnippetEntry {
 	snippets := make(map[string]*SnippetEntry)
 
@@ -485,6 +617,29 @@ func (g *Generator) extractSnippets(exDir, featureName string) map[string]*Snipp
 		}
 	}
 
+	// Go, JVM and Python handlers live side by side in one directory and
+	// are named after their feature: user_handler.go, UserController.java
+	if len(snippets) == 0 {
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			for _, ft := range namedFileTypes {
+				stem, ok := strings.CutSuffix(e.Name(), ft.suffix)
+				if !ok || stem == "" || snippets[ft.key] != nil {
+					continue
+				}
+				absPath := filepath.Join(exDir, e.Name())
+				relPath, _ := filepath.Rel(g.projectRoot, absPath)
+				snippet := g.extractSingleSnippet(absPath, stem, g.snippetDescription(ft.key))
+				if snippet != nil {
+					snippet.SourceFile = relPath
+					snippets[ft.key] = snippet
+				}
+			}
+		}
+	}
+
 	// Check for schemas/ and types/ subdirectories
 	schemasDir := filepath.Join(exDir, "schemas")
 	if entries, err := os.ReadDir(schemasDir); err == nil {
@@ -549,7 +704,7 @@ func (g *Generator) extractSingleSnippet(absPath, featureName, description strin
 
 	// Build a condensed representation: imports + skeleton
 	lines := strings.Split(string(content), "\n")
-	condensed := g.condenseFile(lines, featureName)
+	condensed := g.condenseFile(lines, featureName, condenseProfileFor(absPath))
 	if condensed == "" {
 		return nil
 	}
@@ -562,29 +717,43 @@ func (g *Generator) extractSingleSnippet(absPath, featureName, description strin
 
 // condenseFile builds a condensed, templatized view of a source file.
 // It keeps decorators, class/function signatures, constructor, and strips
-// method bodies, JSDoc comments, and blank lines.
-func (g *Generator) condenseFile(lines []string, featureName string) string {
+// method bodies, JSDoc comments, and blank lines. The profile says what
+// decorators and declarations look like in the file's framework (see
+// condense.go).
+func (g *Generator) condenseFile(lines []string, featureName string, profile *condenseProfile) string {
 	if len(lines) == 0 {
 		return ""
 	}
+	if profile.indented {
+		return g.condenseIndented(lines, featureName, profile)
+	}
+	if profile.braceOnOwnLine {
+		lines = joinOwnLineBraces(lines)
+	}
 
 	// Pre-compiled patterns
-	importRe := regexp.MustCompile(`^\s*import\s+`)
-	jsdocStartRe := regexp.MustCompile(`^\s*/\*\*`)
+	importRe := regexp.MustCompile(`^\s*(?:import|package)\s+`)
+	jsdocStartRe := regexp.MustCompile(`^\s*/\*\*?`)
 	jsdocEndRe := regexp.MustCompile(`\*/\s*$`)
 	blockCommentRe := regexp.MustCompile(`^\s*\*`)
 	lineCommentRe := regexp.MustCompile(`^\s*//`)
-	decoratorRe := regexp.MustCompile(`^\s*@(\w+)`)
-	classRe := regexp.MustCompile(`^\s*(export\s+)?(abstract\s+)?class\s+\w+`)
-	constructorRe := regexp.MustCompile(`^\s*constructor\s*\(`)
-	methodRe := regexp.MustCompile(`^\s*(private\s+|public\s+|protected\s+)?(static\s+)?(async\s+)?(\w+)\s*(<[^>]+>)?\s*\(`)
 	exportConstRe := regexp.MustCompile(`^\s*export\s+const\s+\w+`)
-	exportTypeRe := regexp.MustCompile(`^\s*export\s+(?:type|interface)\s+`)
 
-	// Phase 1: skip leading imports
+	// Phase 1: skip leading imports (and the package clause, Go/JVM)
 	startIdx := 0
+	inImportBlock := false
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
+		if inImportBlock {
+			inImportBlock = trimmed != ")"
+			startIdx = i + 1
+			continue
+		}
+		if trimmed == "import (" {
+			inImportBlock = true
+			startIdx = i + 1
+			continue
+		}
 		if trimmed == "" || importRe.MatchString(line) {
 			startIdx = i + 1
 			continue
@@ -598,6 +767,8 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 	skipUntilBrace := -1  // skip body lines until braceDepth drops to this
 	inJSDoc := false
 	seenLines := make(map[string]bool) // deduplication
+	keptInBody := false                // lines kept from the body being skipped
+	bodyIndent := ""                   // indentation of its signature
 
 	addLine := func(s string) {
 		key := strings.TrimSpace(s)
@@ -644,8 +815,17 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 			if braceDepth < 0 {
 				braceDepth = 0
 			}
+			if profile.keepLines != nil && braceDepth > skipUntilBrace && profile.keepLines.MatchString(line) {
+				addLine(closeOpenBody(line))
+				keptInBody = true
+			}
 			if braceDepth <= skipUntilBrace {
 				skipUntilBrace = -1
+				if keptInBody {
+					// Closers repeat, so they bypass deduplication
+					result = append(result, bodyIndent+"}")
+					keptInBody = false
+				}
 				// Add closing brace at class level
 				if classDepth >= 0 && braceDepth == classDepth {
 					addLine("}")
@@ -659,25 +839,20 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 		lineOpen := strings.Count(line, "{")
 		lineClose := strings.Count(line, "}")
 
-		// --- @Module / @Injectable decorator blocks (check BEFORE generic decorator) ---
-		if strings.HasPrefix(trimmed, "@Module") || strings.HasPrefix(trimmed, "@Injectable") {
-			block := g.collectDecoratorBlock(lines, i)
-			addLine(block)
-			blockLines := strings.Count(block, "\n")
-			i += blockLines
-			braceDepth += strings.Count(block, "{") - strings.Count(block, "}")
-			continue
-		}
-
-		// --- Decorators (@UseGuards, @Controller, @Post, etc.) ---
-		if decoratorRe.MatchString(trimmed) {
-			// Skip parameter decorators used inside method signatures (e.g. @Body, @Query, @Param)
-			paramDecorators := map[string]bool{
-				"Body": true, "Query": true, "Param": true, "Headers": true,
-				"Req": true, "Res": true, "Session": true, "UploadedFile": true,
-				"UploadedFiles": true, "Ip": true, "HostParam": true,
+		// --- Decorators and annotations (@UseGuards, @Controller, @GetMapping, etc.) ---
+		if name := decoratorName(trimmed); name != "" {
+			// Skip parameter decorators used inside method signatures (e.g. @Body, @PathVariable)
+			if profile.paramDecorators[name] {
+				continue
 			}
-			if sub := decoratorRe.FindStringSubmatch(trimmed); len(sub) > 1 && paramDecorators[sub[1]] {
+			// Keep argument blocks whole: @Module({...}), or any decorator
+			// whose arguments span lines
+			if profile.blockDecorators[name] || openParens(trimmed) {
+				block := g.collectDecoratorBlock(lines, i)
+				addLine(block)
+				blockLines := strings.Count(block, "\n")
+				i += blockLines
+				braceDepth += strings.Count(block, "{") - strings.Count(block, "}")
 				continue
 			}
 			addLine(line)
@@ -685,7 +860,7 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 		}
 
 		// --- Class declaration ---
-		if classRe.MatchString(trimmed) {
+		if profile.class != nil && profile.class.MatchString(trimmed) {
 			addLine(line)
 			braceDepth += lineOpen - lineClose
 			classDepth = braceDepth - 1 // class body is one level above closing
@@ -693,13 +868,16 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 		}
 
 		// --- Constructor ---
-		if classDepth >= 0 && constructorRe.MatchString(trimmed) {
+		if classDepth >= 0 && profile.constructor != nil && profile.constructor.MatchString(trimmed) {
 			sig := g.collectSignature(lines, i)
 			addLine(sig)
 			sigLines := strings.Count(sig, "\n")
+			// Count braces in the source lines; collectSignature closes the
+			// body it cuts off
+			raw := strings.Join(lines[i:i+sigLines+1], "\n")
 			i += sigLines
-			sigOpen := strings.Count(sig, "{")
-			sigClose := strings.Count(sig, "}")
+			sigOpen := strings.Count(raw, "{")
+			sigClose := strings.Count(raw, "}")
 			braceDepth += sigOpen - sigClose
 			// Only skip body if the signature opened a brace that isn't closed
 			if sigOpen > sigClose {
@@ -708,23 +886,26 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 			continue
 		}
 
-		// --- Methods (inside class body) ---
-		if classDepth >= 0 && methodRe.MatchString(trimmed) {
+		// --- Methods (inside class body), or functions when the profile has no classes ---
+		if (classDepth >= 0 || (profile.topLevelFuncs && braceDepth == 0)) && profile.method.MatchString(trimmed) {
 			// Ignore control flow keywords that look like methods
-			sub := methodRe.FindStringSubmatch(trimmed)
-			if len(sub) > 4 {
-				name := sub[4]
-				if name == "if" || name == "for" || name == "while" || name == "switch" || name == "catch" {
-					braceDepth += lineOpen - lineClose
-					continue
-				}
+			if controlFlowLineRe.MatchString(trimmed) {
+				braceDepth += lineOpen - lineClose
+				continue
 			}
 			sig := g.collectSignature(lines, i)
-			addLine(sig)
 			sigLines := strings.Count(sig, "\n")
+			raw := strings.Join(lines[i:i+sigLines+1], "\n")
+			sigOpen := strings.Count(raw, "{")
+			sigClose := strings.Count(raw, "}")
+			if sigOpen > sigClose && profile.keepLines != nil && g.bodyKeepsLines(lines, i+sigLines, profile) {
+				// The body holds routes or a wrapped handler: open it so they
+				// can be listed inside
+				sig = strings.TrimSuffix(sig, " }")
+				bodyIndent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			}
+			addLine(sig)
 			i += sigLines
-			sigOpen := strings.Count(sig, "{")
-			sigClose := strings.Count(sig, "}")
 			braceDepth += sigOpen - sigClose
 			if sigOpen > sigClose {
 				skipUntilBrace = braceDepth - (sigOpen - sigClose)
@@ -733,7 +914,7 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 		}
 
 		// --- export const (schemas, const-enums) ---
-		if exportConstRe.MatchString(trimmed) {
+		if profile == nestProfile && exportConstRe.MatchString(trimmed) {
 			addLine(line)
 			if lineOpen > lineClose {
 				addLine("  // ...")
@@ -747,8 +928,8 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 			continue
 		}
 
-		// --- export type/interface ---
-		if exportTypeRe.MatchString(trimmed) {
+		// --- export type/interface, Go struct/interface ---
+		if profile.typeDecl != nil && profile.typeDecl.MatchString(trimmed) {
 			addLine(line)
 			if lineOpen > lineClose {
 				addLine("  // fields...")
@@ -785,6 +966,22 @@ func (g *Generator) condenseFile(lines []string, featureName string) string {
 	return strings.TrimSpace(text)
 }
 
+// bodyKeepsLines reports whether the body opened by the signature ending
+// at line sigEnd has lines the profile keeps
+func (g *Generator) bodyKeepsLines(lines []string, sigEnd int, profile *condenseProfile) bool {
+	depth := 0
+	for j := sigEnd; j < len(lines); j++ {
+		if j > sigEnd && profile.keepLines.MatchString(lines[j]) {
+			return true
+		}
+		depth += strings.Count(lines[j], "{") - strings.Count(lines[j], "}")
+		if depth <= 0 && j > sigEnd {
+			return false
+		}
+	}
+	return false
+}
+
 // collectSignature collects a function/constructor signature, possibly multi-line.
 func (g *Generator) collectSignature(lines []string, start int) string {
 	sig := lines[start]
@@ -825,6 +1022,11 @@ func (g *Generator) snippetDescription(key string) string {
 		"schema":     "Zod validation schema pattern",
 		"types":      "Type definitions pattern",
 		"test":       "Unit test pattern",
+		"screen":     "Flutter screen widget pattern",
+		"model":      "Model pattern",
+		"request":    "Form request validation pattern",
+		"resource":   "API resource pattern",
+		"serializer": "Serializer pattern",
 	}
 	if d, ok := descs[key]; ok {
 		return d
@@ -923,8 +1125,8 @@ func (g *Generator) extractImportsFromFile(absPath, featureName string) map[stri
 	}
 
 	// Cap
-	if len(templatized) > maxImportsPerType {
-		templatized = templatized[:maxImportsPerType]
+	if len(templatized) > g.maxImports {
+		templatized = templatized[:g.maxImports]
 	}
 
 	// Determine the key from file suffix
@@ -970,8 +1172,9 @@ func (g *Generator) detectConventions(app string) *Conventions {
 	}
 
 	// Response envelope detection
-	if envelope := g.detectResponseEnvelope(searchPath); envelope != "" {
-		conv.ResponseEnvelope = envelope
+	if envelope := g.detectResponseEnvelope(searchPath); envelope != nil {
+		conv.ResponseEnvelope = envelope.description
+		conv.envelopeMarker = envelope.marker
 		detected = true
 	}
 
@@ -1051,15 +1254,6 @@ func (g *Generator) detectValidation(searchPath string) string {
 	return ""
 }
 
-func (g *Generator) detectResponseEnvelope(searchPath string) string {
-	// Check for { statusCode, data } pattern in controllers
-	matches, err := search.SearchCode(`statusCode.*data|data.*statusCode`, searchPath, "*.controller.ts", 10)
-	if err == nil && len(matches) > 0 {
-		return "{ statusCode: number, data: T } — controller wraps, service returns plain"
-	}
-	return ""
-}
-
 func (g *Generator) detectLogging(searchPath string) string {
 	matches, err := search.SearchCode(`new Logger\(`, searchPath, "*.service.ts", 10)
 	if err == nil && len(matches) > 0 {
@@ -1243,6 +1437,16 @@ func (g *Generator) buildEndpointChecklist(conv *Conventions, framework string)
 		return g.buildAxumChecklist()
 	case "rust":
 		return g.buildRustGenericChecklist()
+	case "flutter", "dart":
+		return g.buildFlutterChecklist()
+	case "php-laravel":
+		return g.buildLaravelChecklist()
+	case "ruby-rails":
+		return g.buildRailsChecklist()
+	case "android", "kotlin-multiplatform":
+		return g.buildAndroidChecklist(framework)
+	case "spring-boot", "kotlin", "java":
+		return g.buildSpringChecklist()
 	default:
 		// NestJS/Express/TypeScript default
 		return g.buildNestJSChecklist(conv)
@@ -1400,6 +1604,72 @@ func (g *Generator) buildRustGenericChecklist() []string {
 	}
 }
 
+// Flutter Checklists
+
+func (g *Generator) buildFlutterChecklist() []string {
+	return []string{
+		"Create model class in {name}_model.dart — fromJson/toJson, or a freezed class",
+		"Create repository in {name}_repository.dart — API calls return models",
+		"Create screen widget in {name}_screen.dart — StatelessWidget, or StatefulWidget with its State",
+		"Wire state management (provider, bloc or riverpod) the way sibling features do",
+		"Register the route in the app router",
+		"Add widget test {name}_screen_test.dart with testWidgets",
+	}
+}
+
+// PHP and Ruby Checklists
+
+func (g *Generator) buildLaravelChecklist() []string {
+	return []string{
+		"Create migration — php artisan make:migration create_{names_snake}_table",
+		"Create model in app/Models/{Name}.php — $fillable, casts and relationships",
+		"Create form requests Store{Name}Request and Update{Name}Request — rules() and authorize()",
+		"Create API resource {Name}Resource — shape the JSON response",
+		"Create controller {Name}Controller — type-hint the form requests, return resources",
+		"Register routes — Route::apiResource('{names}', {Name}Controller::class)",
+		"Add auth — auth:sanctum middleware or a {Name}Policy",
+		"Add feature test tests/Feature/{Name}Test.php — RefreshDatabase, model factories",
+	}
+}
+
+func (g *Generator) buildRailsChecklist() []string {
+	return []string{
+		"Create model and migration — bin/rails generate model {Name}, validations and associations",
+		"Create controller {Names}Controller — strong parameters in a private {name_snake}_params",
+		"Render JSON the way the app already does — serializer in app/serializers or a jbuilder view",
+		"Register routes in config/routes.rb — resources :{names_snake}",
+		"Add before_action filters for auth and loading the record",
+		"Run migrations — bin/rails db:migrate",
+		"Add request spec spec/requests/{names_snake}_spec.rb, or a Minitest test/controllers/{names_snake}_controller_test.rb",
+	}
+}
+
+func (g *Generator) buildAndroidChecklist(framework string) []string {
+	checklist := []string{
+		"Create UI state and ViewModel in {Name}ViewModel.kt — expose StateFlow, launch work in viewModelScope",
+		"Create repository in {Name}Repository.kt — interface plus implementation, suspend functions",
+		"Create screen in {Name}Screen.kt — @Composable taking state and callbacks, or a Fragment",
+		"Provide dependencies with the DI the module already uses (Hilt @Inject, Koin module)",
+		"Add the destination to the navigation graph",
+		"Add a unit test {Name}ViewModelTest.kt in src/test",
+	}
+	if framework == "kotlin-multiplatform" {
+		checklist[2] = "Keep shared logic in commonMain; put platform code behind expect/actual in androidMain and iosMain"
+	}
+	return checklist
+}
+
+func (g *Generator) buildSpringChecklist() []string {
+	return []string{
+		"Create request/response DTOs with validation annotations (@field:NotBlank, @Valid)",
+		"Create controller {Name}Controller — @RestController with @RequestMapping(\"/{name}\")",
+		"Create service {Name}Service — @Service, transactions at this layer",
+		"Create repository {Name}Repository — Spring Data interface or the module's data access",
+		"Map exceptions with the existing @ControllerAdvice",
+		"Add a test {Name}ControllerTest — @WebMvcTest or MockMvc",
+	}
+}
+
 func (g *Generator) buildFeatureChecklist(conv *Conventions) []string {
 	checklist := []string{
 		"Create module structure with @Module decorator",
@@ -1439,65 +1709,19 @@ func (g *Generator) buildTestChecklist(conv *Conventions, framework string) []st
 	return checklist
 }
 
-// ---------------------------------------------------------------------------
-// Token budget enforcement
-// ---------------------------------------------------------------------------
-
-func (g *Generator) enforceTokenBudget(bp *Blueprint) {
-	estimated := g.estimateTokens(bp)
-	if estimated <= tokenBudget {
-		return
-	}
-
-	// Drop snippets in order: test, types, schema, module, service, controller
-	dropOrder := []string{"test", "types", "schema", "module", "service", "controller"}
-	for _, key := range dropOrder {
-		if bp.Snippets != nil {
-			delete(bp.Snippets, key)
-		}
-		estimated = g.estimateTokens(bp)
-		if estimated <= tokenBudget {
-			return
-		}
-	}
-
-	// Drop imports
-	bp.Imports = nil
-	estimated = g.estimateTokens(bp)
-	if estimated <= tokenBudget {
-		return
-	}
-
-	// Drop conventions
-	bp.Conventions = nil
-}
-
-func (g *Generator) estimateTokens(bp *Blueprint) int {
-	data, err := json.Marshal(bp)
-	if err != nil {
-		return 0
-	}
-	return len(data) / charsPerToken
-}
-
 // ---------------------------------------------------------------------------
 // Templatize helpers
 // ---------------------------------------------------------------------------
 
-// templatize replaces a feature name (e.g. "rma") with {name} and its
-// PascalCase variant (e.g. "Rma") with {Name}.
+// templatize replaces every spelling of a feature name (e.g. "users") and
+// of its glossary aliases with placeholders: {name}/{names} (kebab),
+// {name_snake}, {nameCamel}, {Name}/{Names} and {NAME_CONST}/{NAMES_CONST}.
+// See templatize.go.
 func (g *Generator) templatize(text, featureName string) string {
 	if featureName == "" {
 		return text
 	}
-
-	pascal := toPascalCase(featureName)
-
-	// Replace PascalCase first (longer match) to avoid partial replacements
-	text = strings.ReplaceAll(text, pascal, "{Name}")
-	text = strings.ReplaceAll(text, featureName, "{name}")
-
-	return text
+	return g.nameTemplate(featureName).apply(text)
 }
 
 // toPascalCase converts "rma" -> "Rma", "alarm-events" -> "AlarmEvents"
@@ -1538,8 +1762,13 @@ func (g *Generator) extractFeatureNameFromFile(filePath string) string {
 	return filepath.Base(dir)
 }
 
-// appSourcePath returns the source directory for an app.
+// appSourcePath returns the source directory for an app. In a Gradle
+// build, app names a module (:feature:login, feature/login or login) and
+// its main source set is returned.
 func (g *Generator) appSourcePath(app string) string {
+	if module, ok := g.gradleModule(app); ok && module.MainSourceDir() != "" {
+		return filepath.Join(g.projectRoot, filepath.FromSlash(module.MainSourceDir()))
+	}
 	if app == "" {
 		srcPath := filepath.Join(g.projectRoot, "src", "app")
 		if _, err := os.Stat(srcPath); err == nil {
@@ -1561,95 +1790,29 @@ func (g *Generator) appSourcePath(app string) string {
 	return ""
 }
 
+// gradleModule returns the Gradle module an app names, or the app module
+// of the build when app is empty
+func (g *Generator) gradleModule(app string) (GradleModule, bool) {
+	modules := GradleModules(g.projectRoot)
+	if modules == nil {
+		return GradleModule{}, false
+	}
+	if app == "" {
+		return gradleAppModule(modules)
+	}
+	return FindGradleModule(modules, app)
+}
+
 // ---------------------------------------------------------------------------
 // Existing helpers (kept from v1)
 // ---------------------------------------------------------------------------
 
 func (g *Generator) detectFramework() string {
-	// Check for Node.js frameworks (package.json)
-	packageJSON := filepath.Join(g.projectRoot, "package.json")
-	if data, err := os.ReadFile(packageJSON); err == nil {
-		content := string(data)
-		// NestJS
-		nestIndicators := []string{"@nestjs/core", "@Module", "@Controller"}
-		for _, indicator := range nestIndicators {
-			if strings.Contains(content, indicator) {
-				return "nestjs"
-			}
-		}
-		// Express
-		if strings.Contains(content, "express") {
-			return "express"
-		}
-	}
-
-	// Check for Go frameworks (go.mod)
-	goMod := filepath.Join(g.projectRoot, "go.mod")
-	if data, err := os.ReadFile(goMod); err == nil {
-		content := string(data)
-		if strings.Contains(content, "github.com/gin-gonic/gin") {
-			return "go-gin"
-		}
-		if strings.Contains(content, "github.com/labstack/echo") {
-			return "go-echo"
-		}
-		// Generic Go if no specific framework
-		return "go"
-	}
-
-	// Check for Python frameworks (requirements.txt, pyproject.toml, setup.py)
-	pythonFiles := []string{"requirements.txt", "pyproject.toml", "setup.py", "Pipfile"}
-	for _, pf := range pythonFiles {
-		pyFile := filepath.Join(g.projectRoot, pf)
-		if data, err := os.ReadFile(pyFile); err == nil {
-			content := string(data)
-			if strings.Contains(content, "fastapi") {
-				return "python-fastapi"
-			}
-			if strings.Contains(content, "flask") {
-				return "python-flask"
-			}
-			if strings.Contains(content, "django") {
-				return "python-django"
-			}
-		}
-	}
-
-	// Check for Rust frameworks (Cargo.toml)
-	cargoToml := filepath.Join(g.projectRoot, "Cargo.toml")
-	if data, err := os.ReadFile(cargoToml); err == nil {
-		content := string(data)
-		if strings.Contains(content, "actix-web") {
-			return "rust-actix"
-		}
-		if strings.Contains(content, "axum") {
-			return "rust-axum"
-		}
-		if strings.Contains(content, "rocket") {
-			return "rust-rocket"
-		}
-		// Generic Rust
-		return "rust"
-	}
-
-	return "unknown"
+	return DetectFrameworks(g.projectRoot).Framework
 }
 
 func (g *Generator) detectTestFramework() string {
-	packageJSON := filepath.Join(g.projectRoot, "package.json")
-	if data, err := os.ReadFile(packageJSON); err == nil {
-		content := string(data)
-		if strings.Contains(content, "jest") {
-			return "jest"
-		}
-		if strings.Contains(content, "mocha") {
-			return "mocha"
-		}
-		if strings.Contains(content, "vitest") {
-			return "vitest"
-		}
-	}
-	return "unknown"
+	return DetectFrameworks(g.projectRoot).TestFramework
 }
 
 func (g *Generator) nestJSEndpointPattern(app string) *FilePattern {
@@ -1902,6 +2065,102 @@ func (g *Generator) rustGenericEndpointPattern(app string) *FilePattern {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Gradle Patterns (Android, Kotlin Multiplatform, Spring Boot)
+// ---------------------------------------------------------------------------
+
+// gradleFeaturePattern places a new feature in the app module's base
+// package: the source set directory followed down through its single
+// package directories (src/main/kotlin/com/acme/app/)
+func (g *Generator) gradleFeaturePattern(app, framework string) *FilePattern {
+	ext := ".kt"
+	basePath := "src/main/kotlin/{name}/"
+	module, ok := g.gradleModule(app)
+	if ok && module.MainSourceDir() != "" {
+		if module.Language == "java" {
+			ext = ".java"
+		}
+		dir := module.MainSourceDir()
+		for {
+			entries, err := os.ReadDir(filepath.Join(g.projectRoot, filepath.FromSlash(dir)))
+			if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+				break
+			}
+			dir += "/" + entries[0].Name()
+		}
+		basePath = dir + "/{name}/"
+	}
+
+	pattern := &FilePattern{BasePath: basePath}
+	switch framework {
+	case "android", "kotlin-multiplatform":
+		pattern.Files = []string{"{Name}ViewModel" + ext, "{Name}Repository" + ext, "{Name}Screen" + ext}
+		if ok && module.Kind == GradleAndroidApp {
+			pattern.RegisterIn = []string{strings.TrimPrefix(module.Dir+"/src/main/AndroidManifest.xml", "/")}
+		}
+	default:
+		pattern.Files = []string{"{Name}Controller" + ext, "{Name}Service" + ext, "{Name}Repository" + ext}
+	}
+	return pattern
+}
+
+// ---------------------------------------------------------------------------
+// Flutter Patterns
+// ---------------------------------------------------------------------------
+
+func (g *Generator) flutterFeaturePattern(app string) *FilePattern {
+	basePath := "lib/{name}/"
+	for _, dir := range []string{"lib/features/", "lib/src/features/", "lib/screens/", "lib/src/"} {
+		if _, err := os.Stat(filepath.Join(g.projectRoot, dir)); err == nil {
+			basePath = dir + "{name}/"
+			break
+		}
+	}
+	return &FilePattern{
+		BasePath: basePath,
+		Files: []string{
+			"{name}_screen.dart",
+			"{name}_repository.dart",
+			"{name}_model.dart",
+		},
+		RegisterIn: []string{
+			"lib/main.dart",
+			"lib/router.dart",
+		},
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Laravel and Rails Patterns
+// ---------------------------------------------------------------------------
+
+// laravelEndpointPattern spreads a feature over Laravel's layer
+// directories. Routes go in routes/api.php, or routes/web.php in apps
+// that never installed API routes (Laravel 11 ships without one).
+func (g *Generator) laravelEndpointPattern() *FilePattern {
+	routes := "routes/api.php"
+	if _, err := os.Stat(filepath.Join(g.projectRoot, routes)); err != nil {
+		if _, err := os.Stat(filepath.Join(g.projectRoot, "routes", "web.php")); err == nil {
+			routes = "routes/web.php"
+		}
+	}
+	return &FilePattern{
+		BasePath:    "./",
+		Files:       layerPaths(g.layeredFiles("php-laravel")),
+		Directories: []string{"database/migrations"},
+		RegisterIn:  []string{routes},
+	}
+}
+
+func (g *Generator) railsEndpointPattern() *FilePattern {
+	return &FilePattern{
+		BasePath:    "./",
+		Files:       layerPaths(g.layeredFiles("ruby-rails")),
+		Directories: []string{"db/migrate"},
+		RegisterIn:  []string{"config/routes.rb"},
+	}
+}
+
 func (g *Generator) inferRustBasePath(app, kind string) string {
 	// Common Rust project patterns
 	patterns := []string{
@@ -1957,7 +2216,7 @@ func (g *Generator) inferBasePath(app, kind string) string {
 	return "apps/" + app + "/src/app/v1/{name}/"
 }
 
-func (g *Generator) findEndpointExamples(app string) []Example {
+func (g *Generator) findEndpointExamples(app string, conv *Conventions) []Example {
 	var examples []Example
 	framework := g.detectFramework()
 
@@ -2005,6 +2264,34 @@ func (g *Generator) findEndpointExamples(app string) []Example {
 		}
 		descSuffix = " endpoint (handler + service)"
 
+	case framework == "php-laravel":
+		searchPaths = []string{filepath.Join(g.projectRoot, "app", "Http", "Controllers")}
+		patterns = []*regexp.Regexp{
+			regexp.MustCompile(`^\w+Controller\.php$`), // not the base Controller.php
+		}
+		descSuffix = " endpoint (controller + model + request + resource)"
+
+	case framework == "ruby-rails":
+		searchPaths = []string{filepath.Join(g.projectRoot, "app", "controllers")}
+		patterns = []*regexp.Regexp{
+			regexp.MustCompile(`_controller\.rb$`),
+		}
+		descSuffix = " endpoint (controller + model + serializer)"
+
+	case isGradleFramework(framework):
+		// The module's main source set; Android and multiplatform
+		// features are anchored by their ViewModel
+		if dir := g.appSourcePath(app); dir != "" {
+			searchPaths = []string{dir}
+		}
+		if framework == "android" || framework == "kotlin-multiplatform" {
+			patterns = []*regexp.Regexp{regexp.MustCompile(`ViewModel\.(?:kt|java)$`)}
+			descSuffix = " feature (ViewModel + repository + screen)"
+		} else {
+			patterns = []*regexp.Regexp{regexp.MustCompile(`(?:Controller|Resource)\.(?:kt|java)$`)}
+			descSuffix = " endpoint (controller + service + repository)"
+		}
+
 	default: // NestJS/Express/TypeScript
 		searchPaths = []string{
 			filepath.Join(g.projectRoot, "apps", app, "src", "app", "v1"),
@@ -2017,11 +2304,8 @@ func (g *Generator) findEndpointExamples(app string) []Example {
 		descSuffix = " endpoint (controller + service + module)"
 	}
 
-	type exampleWithMtime struct {
-		example Example
-		mtime   int64
-	}
-	var candidates []exampleWithMtime
+	var candidates []exampleCandidate
+	layered := featureLayers[framework] != nil
 
 	for _, searchPath := range searchPaths {
 		if _, err := os.Stat(searchPath); err != nil {
@@ -2035,7 +2319,7 @@ func (g *Generator) findEndpointExamples(app string) []Example {
 
 			// Skip common non-source files
 			basename := filepath.Base(path)
-			if basename == "mod.rs" || basename == "__init__.py" || basename == "index.ts" {
+			if basename == "mod.rs" || basename == "__init__.py" || basename == "index.ts" || basename == "application_controller.rb" {
 				return nil
 			}
 
@@ -2044,18 +2328,24 @@ func (g *Generator) findEndpointExamples(app string) []Example {
 				if pattern.MatchString(basename) {
 					relPath, _ := filepath.Rel(g.projectRoot, path)
 					dirPath := filepath.Dir(relPath)
+					if layered {
+						// One directory holds every controller: the
+						// example is the controller itself
+						dirPath = filepath.ToSlash(relPath)
+					}
 
 					// Extract name from filename
 					name := basename
-					for _, suffix := range []string{".controller.ts", "_handler.go", ".go", ".py", ".rs"} {
+					for _, suffix := range []string{"Controller.php", "_controller.rb", ".controller.ts", "_handler.go", ".go", ".py", ".rs", ".kt", ".java", "Controller", "Resource", "ViewModel"} {
 						name = strings.TrimSuffix(name, suffix)
 					}
 
-					candidates = append(candidates, exampleWithMtime{
+					candidates = append(candidates, exampleCandidate{
 						example: Example{
 							Path:        dirPath,
 							Description: name + descSuffix,
 						},
+						file:  path,
 						mtime: info.ModTime().Unix(),
 					})
 					break
@@ -2069,18 +2359,30 @@ func (g *Generator) findEndpointExamples(app string) []Example {
 		}
 	}
 
-	// Sort by modification time (newest first — best example)
-	sort.Slice(candidates, func(i, j int) bool {
+	// Rank by quality rather than recency alone, so a hastily hacked
+	// endpoint doesn't become the "best example".
+	warnings := g.loadWarnings()
+	for i := range candidates {
+		g.scoreExampleCandidate(&candidates[i], framework, conv, warnings)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
 		return candidates[i].mtime > candidates[j].mtime
 	})
 
 	for i, c := range candidates {
-		if i >= maxExamples {
+		if i >= g.maxExamples {
 			break
 		}
 		ex := c.example
+		ex.Routes = exampleRoutes(c.file)
 		if i == 0 {
-			ex.Description += " — newest, best example to follow"
+			ex.Description += " — best example to follow"
+			if len(c.reasons) > 0 {
+				ex.Description += " (" + strings.Join(c.reasons, ", ") + ")"
+			}
 		}
 		examples = append(examples, ex)
 	}
@@ -2088,6 +2390,184 @@ func (g *Generator) findEndpointExamples(app string) []Example {
 	return examples
 }
 
+// exampleRoutes lists the routes a NestJS controller example serves, from
+// its decorator arguments, so the paths and guards to follow come with it
+func exampleRoutes(file string) []string {
+	if !strings.HasSuffix(file, ".ts") {
+		return nil
+	}
+	sk, err := skeleton.ParseFile(file)
+	if err != nil {
+		return nil
+	}
+	var routes []string
+	for _, ep := range extractor.NestEndpoints(file, sk) {
+		route := ep.Method + " " + ep.Path + " -> " + ep.Controller + "." + ep.Handler
+		if ep.Auth != "" {
+			route += " [" + ep.Auth + "]"
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// exampleCandidate is an endpoint example being ranked.
+type exampleCandidate struct {
+	example Example
+	file    string // absolute path of the matched entry file
+	mtime   int64
+	score   float64
+	reasons []string
+}
+
+// companionSuffixes lists the files that make an endpoint example complete,
+// per framework family.
+func companionSuffixes(framework string) []string {
+	switch {
+	case strings.HasPrefix(framework, "go"):
+		return []string{"service.go", "routes.go"}
+	case strings.HasPrefix(framework, "python"):
+		return []string{"service.py", "schemas.py"}
+	case strings.HasPrefix(framework, "rust"):
+		return []string{"service.rs", "models.rs"}
+	case framework == "android" || framework == "kotlin-multiplatform":
+		return []string{"Repository.kt", "Screen.kt"}
+	case isGradleFramework(framework):
+		return []string{"Service.kt", "Repository.kt"}
+	case featureLayers[framework] != nil:
+		// Laravel and Rails: see layeredCompleteness
+		return nil
+	default:
+		return []string{".service.ts", ".module.ts"}
+	}
+}
+
+// isTestFileName reports whether a file name looks like a test file.
+func isTestFileName(name string) bool {
+	return strings.HasSuffix(name, ".spec.ts") || strings.HasSuffix(name, ".test.ts") ||
+		strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, "_test.py") ||
+		strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.rs") ||
+		strings.HasSuffix(name, "Test.kt") || strings.HasSuffix(name, "Test.java") || strings.HasSuffix(name, "Tests.kt") ||
+		strings.HasSuffix(name, "Test.php") || strings.HasSuffix(name, "_spec.rb") || strings.HasSuffix(name, "_test.rb")
+}
+
+// scoreExampleCandidate weighs convention compliance, test presence, file
+// completeness, and warning association. Recency only breaks ties.
+func (g *Generator) scoreExampleCandidate(c *exampleCandidate, framework string, conv *Conventions, warnings []types.Warning) {
+	score := 0.0
+	dir := filepath.Dir(c.file)
+	entries, _ := os.ReadDir(dir)
+
+	// Completeness: controller + service + module (or framework equivalent)
+	companions := companionSuffixes(framework)
+	found, total := 0, len(companions)
+	for _, suffix := range companions {
+		for _, e := range entries {
+			name := e.Name()
+			if strings.HasSuffix(name, ".java") {
+				// Java modules follow the same naming as Kotlin ones
+				name = strings.TrimSuffix(name, ".java") + ".kt"
+			}
+			if !e.IsDir() && strings.HasSuffix(name, suffix) && !isTestFileName(e.Name()) {
+				found++
+				break
+			}
+		}
+	}
+	layerTested := false
+	if layers := g.layeredFiles(framework); layers != nil {
+		// Laravel and Rails keep the feature's other files, tests
+		// included, in their own directories
+		found, total, layerTested = g.layeredCompleteness(c.file, layers)
+	}
+	score += 3.0 * float64(found) / float64(total)
+	if found == total {
+		c.reasons = append(c.reasons, "complete")
+	}
+
+	// Tests alongside the example (or inline Rust test modules)
+	content, _ := os.ReadFile(c.file)
+	hasTests := layerTested || strings.Contains(string(content), "#[cfg(test)]")
+	for _, e := range entries {
+		if !e.IsDir() && isTestFileName(e.Name()) {
+			hasTests = true
+			break
+		}
+	}
+	if hasTests {
+		score += 2.0
+		c.reasons = append(c.reasons, "tested")
+	}
+
+	// Convention compliance
+	if conv != nil {
+		markers := conventionMarkers(conv)
+		matched := 0
+		for _, m := range markers {
+			if strings.Contains(string(content), m) {
+				matched++
+			}
+		}
+		if len(markers) > 0 {
+			score += 2.0 * float64(matched) / float64(len(markers))
+			if matched == len(markers) {
+				c.reasons = append(c.reasons, "follows conventions")
+			}
+		}
+	}
+
+	// Warning association: penalize areas the team has flagged
+	warned := 0
+	for _, w := range warnings {
+		for _, f := range w.RelatedFiles {
+			if f != "" && (pathWithin(f, c.example.Path) || pathWithin(c.example.Path, f)) {
+				warned++
+				break
+			}
+		}
+	}
+	score -= 1.5 * float64(warned)
+	if warned > 0 {
+		c.reasons = append(c.reasons, "has warnings")
+	}
+
+	c.score = score
+}
+
+// conventionMarkers returns literal strings a compliant file should contain.
+func conventionMarkers(conv *Conventions) []string {
+	var markers []string
+	if conv.AuthGuard != "" {
+		markers = append(markers, strings.Fields(conv.AuthGuard)[0])
+	}
+	if strings.HasPrefix(conv.Validation, "ZodPipe") {
+		markers = append(markers, "ZodPipe")
+	} else if strings.HasPrefix(conv.Validation, "ValidationPipe") {
+		markers = append(markers, "ValidationPipe")
+	}
+	if conv.envelopeMarker != "" {
+		markers = append(markers, conv.envelopeMarker)
+	}
+	return markers
+}
+
+// loadWarnings returns the team warnings from the store, read once per
+// blueprint; nil if unavailable.
+func (g *Generator) loadWarnings() []types.Warning {
+	if !g.warningsLoaded && g.jsonStore != nil {
+		g.warnings, _ = g.jsonStore.GetWarnings()
+		g.warningsLoaded = true
+	}
+	return g.warnings
+}
+
+// pathWithin reports whether path is dir or lies under it, matching whole
+// path segments so "users/" is not inside "user"
+func pathWithin(path, dir string) bool {
+	dir = strings.TrimSuffix(dir, "/")
+	return dir != "" && (path == dir || strings.HasPrefix(path, dir+"/"))
+}
+
 func (g *Generator) findFeatureExamples(app string) []Example {
 	var examples []Example
 
@@ -2103,7 +2583,7 @@ func (g *Generator) findFeatureExamples(app string) []Example {
 			return nil
 		}
 
-		if modulePattern.MatchString(path) && !strings.Contains(path, "app.module") && len(examples) < maxExamples {
+		if modulePattern.MatchString(path) && !strings.Contains(path, "app.module") && len(examples) < g.maxExamples {
 			relPath, _ := filepath.Rel(g.projectRoot, path)
 			examples = append(examples, Example{
 				Path:        filepath.Dir(relPath),
@@ -2127,6 +2607,12 @@ func (g *Generator) findTestExamplesForApp(app, framework string) []Example {
 	}
 
 	testPattern := regexp.MustCompile(`\.spec\.ts$|\.test\.ts$`)
+	if module, ok := g.gradleModule(app); ok {
+		// Gradle tests live in their own source sets (src/test,
+		// src/commonTest), next to the main one
+		searchPath = filepath.Join(g.projectRoot, filepath.FromSlash(module.Dir), "src")
+		testPattern = regexp.MustCompile(`Tests?\.(?:kt|java)$`)
+	}
 
 	filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
@@ -2301,21 +2787,11 @@ func (g *Generator) addRelevantDecisions(bp *Blueprint) {
 	}
 
 	bp.Decisions = relevant
-
-	if len(relevant) > 0 {
-		bp.Confidence += 0.1
-	}
 }
 
 func (g *Generator) addRelevantWarnings(bp *Blueprint) {
-	warningsFile := filepath.Join(g.tcDir, "knowledge", "warnings.json")
-	data, err := os.ReadFile(warningsFile)
-	if err != nil {
-		return
-	}
-
-	var warnings []types.Warning
-	if err := json.Unmarshal(data, &warnings); err != nil {
+	warnings := g.loadWarnings()
+	if len(warnings) == 0 {
 		return
 	}
 
@@ -2337,10 +2813,60 @@ func (g *Generator) addRelevantWarnings(bp *Blueprint) {
 	}
 
 	bp.Warnings = relevant
+}
+
+// addSensitivePaths applies the sensitive path policies covering the
+// task's target: their warnings go first and are never capped, and their
+// checklist items and reviewer sign-off are added to the checklist
+func (g *Generator) addSensitivePaths(bp *Blueprint) {
+	candidates := []string{bp.Path}
+	if bp.App != "" {
+		candidates = append(candidates, g.appSourcePath(bp.App))
+	}
+	var targets []string
+	for _, p := range candidates {
+		if p == "" {
+			continue
+		}
+		if filepath.IsAbs(p) {
+			rel, err := filepath.Rel(g.projectRoot, p)
+			if err != nil {
+				continue
+			}
+			p = rel
+		}
+		targets = append(targets, p)
+	}
+	policies := g.jsonStore.SensitivePathsFor(targets...)
+	if len(policies) == 0 {
+		return
+	}
 
-	if len(relevant) > 0 {
-		bp.Confidence += 0.1
+	var mandatory []Warning
+	var checklist []string
+	seenReviewer := make(map[string]bool)
+	for _, policy := range policies {
+		bp.SensitivePaths = append(bp.SensitivePaths, policy.Path)
+		description := "Sensitive path " + policy.Path
+		if policy.Reason != "" {
+			description += ": " + policy.Reason
+		}
+		for _, w := range policy.Warnings {
+			mandatory = append(mandatory, Warning{Title: w, Description: description, Severity: "critical"})
+		}
+		checklist = append(checklist, policy.Checklist...)
+		for _, r := range policy.Reviewers {
+			if !seenReviewer[r] {
+				seenReviewer[r] = true
+				bp.RequiredReviewers = append(bp.RequiredReviewers, r)
+			}
+		}
+	}
+	if len(bp.RequiredReviewers) > 0 {
+		checklist = append(checklist, "Get approval from "+strings.Join(bp.RequiredReviewers, ", ")+" (sensitive: "+strings.Join(bp.SensitivePaths, ", ")+")")
 	}
+	bp.Warnings = append(mandatory, bp.Warnings...)
+	bp.Checklist = append(bp.Checklist, checklist...)
 }
 
 func (g *Generator) getTaskKeywords(taskType TaskType, app string) []string {
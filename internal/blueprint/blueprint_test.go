@@ -3,7 +3,9 @@ package blueprint
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/saeedalam/teamcontext/internal/storage"
 	"github.com/saeedalam/teamcontext/pkg/types"
@@ -156,6 +158,11 @@ func TestGenerateEndpointBlueprint(t *testing.T) {
 	if blueprint.Confidence == 0 {
 		t.Error("Confidence should be set")
 	}
+
+	// Examples carry the routes their decorators declare
+	if len(blueprint.Examples) == 0 || strings.Join(blueprint.Examples[0].Routes, "; ") != "GET /users -> UsersController.findAll; POST /users -> UsersController.create" {
+		t.Errorf("example routes = %+v", blueprint.Examples)
+	}
 	
 	t.Logf("Blueprint: TaskType=%s, Confidence=%.2f, Checklist=%d items, Decisions=%d",
 		blueprint.TaskType, blueprint.Confidence, len(blueprint.Checklist), len(blueprint.Decisions))
@@ -306,6 +313,94 @@ func TestBlueprintIncludesWarnings(t *testing.T) {
 	}
 }
 
+func TestBlueprintSensitivePaths(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	createNestJSProject(t, projectDir)
+
+	config := &types.Config{
+		Name: "test-app",
+		SensitivePaths: []types.SensitivePath{{
+			Path:      "src/app/payments",
+			Reason:    "PCI scope",
+			Warnings:  []string{"Never log card numbers"},
+			Reviewers: []string{"@payments-team"},
+			Checklist: []string{"Add an audit log entry for every state change"},
+		}},
+	}
+	if err := store.SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	generator := NewGenerator(projectDir, tcDir, store)
+	blueprint, err := generator.Generate(TaskAddEndpoint, "", "src/app/payments/refunds")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(blueprint.Warnings) == 0 || blueprint.Warnings[0].Title != "Never log card numbers" || blueprint.Warnings[0].Severity != "critical" {
+		t.Errorf("Expected the mandatory warning first, got %+v", blueprint.Warnings)
+	}
+	if len(blueprint.RequiredReviewers) != 1 || blueprint.RequiredReviewers[0] != "@payments-team" {
+		t.Errorf("Expected @payments-team as required reviewer, got %v", blueprint.RequiredReviewers)
+	}
+	checklist := strings.Join(blueprint.Checklist, "\n")
+	if !strings.Contains(checklist, "audit log entry") || !strings.Contains(checklist, "Get approval from @payments-team") {
+		t.Errorf("Expected the sensitive path checklist items, got %v", blueprint.Checklist)
+	}
+
+	// Other paths are unaffected
+	blueprint, err = generator.Generate(TaskAddEndpoint, "", "src/app/users")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(blueprint.SensitivePaths) != 0 || len(blueprint.RequiredReviewers) != 0 {
+		t.Errorf("Expected no sensitive paths for src/app/users, got %v", blueprint.SensitivePaths)
+	}
+}
+
+func TestTemplatizeNameVariants(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	config := &types.Config{
+		Name:     "test-app",
+		Glossary: map[string][]string{"rma": {"return-request"}},
+	}
+	if err := store.SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	generator := NewGenerator(projectDir, tcDir, store)
+
+	tests := []struct {
+		feature string
+		input   string
+		want    string
+	}{
+		{"users", "import { UsersService } from './users.service';", "import { {Names}Service } from './{names}.service';"},
+		{"users", "create(@Body() createUserDto: CreateUserDto)", "create(@Body() create{Name}Dto: Create{Name}Dto)"},
+		{"users", "const USERS_QUEUE = 'user-events'; let userCount = 0", "const {NAMES_CONST}_QUEUE = '{name}-events'; let {name}Count = 0"},
+		{"alarm-events", "export class AlarmEventsController { alarmEvent: AlarmEvent; ALARM_EVENT_TTL; alarm_events_table }",
+			"export class {Names}Controller { {nameCamel}: {Name}; {NAME_CONST}_TTL; {names_snake}_table }"},
+		{"categories", "getCategory(): Category[] { return this.categories }", "get{Name}(): {Name}[] { return this.{names} }"},
+		// Whole words only
+		{"rma", "format(rmaId, RMA_STATUS, Rmas)", "format({name}Id, {NAME_CONST}_STATUS, {Names})"},
+		// Glossary aliases map to the same placeholders
+		{"rma", "ReturnRequestService handles return-requests for each rma", "{Name}Service handles {names} for each {name}"},
+	}
+	for _, tt := range tests {
+		if got := generator.templatize(tt.input, tt.feature); got != tt.want {
+			t.Errorf("templatize(%q, %q)\n  got  %q\n  want %q", tt.input, tt.feature, got, tt.want)
+		}
+	}
+
+	placeholders := generator.nameTemplate("alarm-events").placeholders
+	if placeholders["{Name}"] != "AlarmEvent" || placeholders["{names}"] != "alarm-events" || placeholders["{NAMES_CONST}"] != "ALARM_EVENTS" {
+		t.Errorf("Unexpected placeholders: %v", placeholders)
+	}
+}
+
 // =============================================================================
 // EDGE CASES
 // =============================================================================
@@ -362,3 +457,578 @@ func TestBlueprintMultipleTaskTypes(t *testing.T) {
 			taskType, blueprint.Confidence, len(blueprint.Checklist))
 	}
 }
+
+func TestFindEndpointExamplesPrefersCompleteOverNewest(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	createNestJSProject(t, projectDir)
+
+	// A newer, hastily hacked endpoint: controller only, no service/module/tests
+	hackDir := filepath.Join(projectDir, "src", "app", "hack")
+	if err := os.MkdirAll(hackDir, 0755); err != nil {
+		t.Fatalf("Failed to create hack dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(hackDir, "hack.controller.ts"), []byte("@Controller('hack')\nexport class HackController {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to create controller: %v", err)
+	}
+
+	// Make the complete users example older than the hack
+	usersDir := filepath.Join(projectDir, "src", "app", "users")
+	for _, name := range []string{"users.module.ts", "users.controller.spec.ts"} {
+		if err := os.WriteFile(filepath.Join(usersDir, name), []byte("export {}\n"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(usersDir, "users.controller.ts"), old, old)
+
+	generator := NewGenerator(projectDir, tcDir, store)
+	examples := generator.findEndpointExamples("test-app", nil)
+	if len(examples) < 2 {
+		t.Fatalf("Expected at least 2 examples, got %d", len(examples))
+	}
+	if examples[0].Path != filepath.Join("src", "app", "users") {
+		t.Errorf("Expected complete users example first, got %s", examples[0].Path)
+	}
+}
+
+func TestExampleWarningsMatchWholePathSegments(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	createNestJSProject(t, projectDir)
+	usersFile := filepath.Join(projectDir, "src", "app", "users", "users.controller.ts")
+
+	score := func() (float64, []string) {
+		generator := NewGenerator(projectDir, tcDir, store)
+		c := exampleCandidate{file: usersFile}
+		c.example.Path = filepath.Join("src", "app", "users")
+		generator.scoreExampleCandidate(&c, "nestjs", nil, generator.loadWarnings())
+		return c.score, c.reasons
+	}
+	base, _ := score()
+
+	// A sibling directory sharing a prefix is not the example's area
+	if err := store.AddWarning(&types.Warning{Content: "user is legacy", RelatedFiles: []string{"src/app/user"}}); err != nil {
+		t.Fatalf("AddWarning: %v", err)
+	}
+	if got, reasons := score(); got != base {
+		t.Errorf("warning on src/app/user changed the users score: %v -> %v %v", base, got, reasons)
+	}
+
+	if err := store.AddWarning(&types.Warning{Content: "controller is fragile", RelatedFiles: []string{"src/app/users/users.controller.ts"}}); err != nil {
+		t.Fatalf("AddWarning: %v", err)
+	}
+	got, reasons := score()
+	if got >= base || !strings.Contains(strings.Join(reasons, ","), "has warnings") {
+		t.Errorf("warning inside src/app/users not applied: %v -> %v %v", base, got, reasons)
+	}
+}
+
+func TestPathWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"src/users", "src/users", true},
+		{"src/users/a.ts", "src/users", true},
+		{"src/users/a.ts", "src/users/", true},
+		{"src/users", "src/user", false},
+		{"src/user", "src/users", false},
+		{"src/users", "", false},
+	}
+	for _, tt := range tests {
+		if got := pathWithin(tt.path, tt.dir); got != tt.want {
+			t.Errorf("pathWithin(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestBlueprintConfidenceBreakdown(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	createNestJSProject(t, projectDir)
+
+	generator := NewGenerator(projectDir, tcDir, store)
+
+	withoutPath, err := generator.Generate(TaskFixBug, "", "")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	withPath, err := generator.Generate(TaskFixBug, "", "src/app/users/users.controller.ts")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if withPath.Confidence <= withoutPath.Confidence {
+		t.Errorf("Parsed target should raise confidence: %.2f <= %.2f", withPath.Confidence, withoutPath.Confidence)
+	}
+	if withoutPath.ConfidenceLevel != ConfidenceLow {
+		t.Errorf("Expected low confidence without a path, got '%s'", withoutPath.ConfidenceLevel)
+	}
+
+	totalWeight := 0.0
+	for _, c := range withPath.ConfidenceBreakdown {
+		totalWeight += c.Weight
+		if c.Score < 0 || c.Score > 1 {
+			t.Errorf("Component %s score out of range: %.2f", c.Name, c.Score)
+		}
+	}
+	if totalWeight < 0.99 || totalWeight > 1.01 {
+		t.Errorf("Component weights should sum to 1, got %.2f", totalWeight)
+	}
+	if len(withPath.ConfidenceExplanation) != len(withPath.ConfidenceBreakdown) {
+		t.Errorf("Expected one explanation per component, got %d for %d",
+			len(withPath.ConfidenceExplanation), len(withPath.ConfidenceBreakdown))
+	}
+}
+
+func TestDetectResponseEnvelopeCrossStack(t *testing.T) {
+	cases := []struct {
+		name   string
+		files  map[string]string
+		want   string
+		marker string
+	}{
+		{
+			name: "gin wrapper struct",
+			files: map[string]string{
+				"internal/response/response.go": "package response\n\ntype Response struct {\n\tCode    int         `json:\"code\"`\n\tMessage string      `json:\"message\"`\n\tData    interface{} `json:\"data,omitempty\"`\n}\n",
+				"internal/handler/user.go":      "package handler\n\nfunc GetUser(c *gin.Context) {\n\tc.JSON(http.StatusOK, response.Response{Code: 0, Data: user})\n}\n\nfunc ListUsers(c *gin.Context) {\n\tc.JSON(http.StatusOK, response.Response{Code: 0, Data: users})\n}\n",
+			},
+			want:   "{ code: int, message: string, data: T }",
+			marker: "Response",
+		},
+		{
+			name: "fastapi generic model",
+			files: map[string]string{
+				"app/schemas.py": "class APIResponse(GenericModel, Generic[T]):\n    success: bool\n    data: Optional[T] = None\n\n\nclass User(BaseModel):\n    id: int\n",
+				"app/routes.py":  "@router.get(\"/users/{id}\", response_model=APIResponse[User])\ndef get_user(id: int):\n    pass\n",
+			},
+			want:   "{ success: bool, data: T }",
+			marker: "APIResponse",
+		},
+		{
+			name: "spring ResponseEntity wrapper",
+			files: map[string]string{
+				"src/main/java/com/acme/ApiResponse.java":    "public class ApiResponse<T> {\n    private boolean success;\n    private String message;\n    private T data;\n}\n",
+				"src/main/java/com/acme/UserController.java": "public class UserController {\n    public ResponseEntity<ApiResponse<UserDto>> get() {\n        return ResponseEntity.ok(ApiResponse.ok(dto));\n    }\n}\n",
+			},
+			want:   "{ success: boolean, message: String, data: T }",
+			marker: "ApiResponse",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectDir, tcDir, store, cleanup := setupTestProject(t)
+			defer cleanup()
+
+			for rel, content := range tc.files {
+				path := filepath.Join(projectDir, rel)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create dir: %v", err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", rel, err)
+				}
+			}
+
+			generator := NewGenerator(projectDir, tcDir, store)
+			env := generator.detectResponseEnvelope(generator.appSourcePath(""))
+			if env == nil {
+				t.Fatal("Expected an envelope to be detected")
+			}
+			if !strings.HasPrefix(env.description, tc.want) {
+				t.Errorf("Expected envelope %q, got %q", tc.want, env.description)
+			}
+			if env.marker != tc.marker {
+				t.Errorf("Expected marker %q, got %q", tc.marker, env.marker)
+			}
+		})
+	}
+}
+
+func TestDetectTestCommands(t *testing.T) {
+	projectDir, _, _, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	files := map[string]string{
+		"pnpm-lock.yaml":        "",
+		"Makefile":              "VERSION := 1\n\ntest:\n\tpnpm -r test\n\ntest-e2e: build\n\tplaywright test\n",
+		"apps/api/package.json": `{"scripts": {"build": "tsc", "test": "jest", "test:cov": "jest --coverage"}}`,
+		"apps/web/package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`,
+		"apps/api/src/users.ts": "export const users = []\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cmds := DetectTestCommands(projectDir, filepath.Join(projectDir, "apps", "api", "src"))
+	var got []string
+	for _, c := range cmds {
+		got = append(got, c.Shell())
+	}
+	want := []string{"cd apps/api && pnpm test", "cd apps/api && pnpm run test:cov", "make test", "make test-e2e"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("DetectTestCommands = %v, want %v", got, want)
+	}
+	if cmds[0].Single != "pnpm exec jest {file}" {
+		t.Errorf("Single = %q, want jest runner", cmds[0].Single)
+	}
+
+	// The placeholder npm script is not a way to run tests
+	for _, c := range DetectTestCommands(projectDir, "apps/web") {
+		if c.Dir == "apps/web" {
+			t.Errorf("unexpected command from placeholder script: %+v", c)
+		}
+	}
+
+	checklist := ChecklistWithTestCommands([]string{"Make changes", "Run tests after each change"}, cmds)
+	if checklist[1] != "Run tests after each change — `cd apps/api && pnpm test`" {
+		t.Errorf("checklist step not made concrete: %q", checklist[1])
+	}
+	checklist = ChecklistWithTestCommands([]string{"Add unit tests"}, cmds)
+	if len(checklist) != 2 || !strings.Contains(checklist[1], "pnpm test") {
+		t.Errorf("expected a run step to be appended, got %v", checklist)
+	}
+}
+
+func TestDetectFlutterProject(t *testing.T) {
+	projectDir, _, _, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	pubspec := "name: shop\n\ndependencies:\n  flutter:\n    sdk: flutter\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "pubspec.yaml"), []byte(pubspec), 0644); err != nil {
+		t.Fatalf("Failed to write pubspec.yaml: %v", err)
+	}
+	InvalidateFrameworks(projectDir)
+
+	if f := DetectFrameworks(projectDir).Framework; f != "flutter" {
+		t.Errorf("framework = %q, want flutter", f)
+	}
+	cmds := DetectTestCommands(projectDir, "lib")
+	if len(cmds) != 1 || cmds[0].Command != "flutter test" || cmds[0].Single != "flutter test {file}" {
+		t.Errorf("DetectTestCommands = %+v", cmds)
+	}
+	if !isFixtureSource("test/cart_screen_test.dart") || isFixtureSource("lib/cart/cart_screen.dart") {
+		t.Error("Dart test files should be fixture sources, library files not")
+	}
+}
+
+func TestDetectFixtures(t *testing.T) {
+	projectDir, _, _, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	files := map[string]string{
+		"tests/factories.py": "import factory\n\nclass UserFactory(factory.django.DjangoModelFactory):\n    class Meta:\n        model = User\n\n    email = factory.Faker(\"email\")\n",
+		"tests/conftest.py":  "import pytest\n\n@pytest.fixture\ndef db():\n    conn = connect()\n    yield conn\n    conn.close()\n",
+		"tests/test_users.py": "from testcontainers.postgres import PostgresContainer\n\ndef test_create(db):\n    user = UserFactory()\n",
+		"internal/render/render_test.go": "package render\n\nvar update = flag.Bool(\"update\", false, \"update golden files\")\n\nfunc TestRender(t *testing.T) {\n\tdir := t.TempDir()\n\tt.Cleanup(func() {})\n}\n",
+		"internal/render/testdata/page.golden": "<html></html>\n",
+		"src/users.py": "class UserFactory(factory.Factory):\n    pass\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	fx := DetectFixtures(projectDir, "tests")
+	if fx == nil {
+		t.Fatal("expected fixtures to be detected")
+	}
+	kinds := make(map[string]FixtureKind)
+	for _, k := range fx.Kinds {
+		kinds[k.Kind+"/"+k.Library] = k
+	}
+	if k, ok := kinds["factory/factory_boy"]; !ok || k.Example != "tests/factories.py" {
+		t.Errorf("factory_boy factory not detected: %+v", fx.Kinds)
+	}
+	if _, ok := kinds["testcontainers/testcontainers"]; !ok {
+		t.Errorf("testcontainers not detected: %+v", fx.Kinds)
+	}
+	if _, ok := kinds["golden/"]; ok {
+		t.Errorf("golden files outside tests/ should not be reported: %+v", fx.Kinds)
+	}
+	if len(fx.Examples) == 0 || !strings.Contains(fx.Examples[0].Code, "class UserFactory") || !strings.Contains(fx.Examples[0].Code, "model = User") {
+		t.Errorf("expected the factory definition as an example, got %+v", fx.Examples)
+	}
+	if strings.Contains(fx.Examples[0].Code, "email") {
+		t.Errorf("example should stop at the blank line: %q", fx.Examples[0].Code)
+	}
+	var setup []string
+	for _, c := range fx.Setup {
+		setup = append(setup, c.Convention)
+	}
+	if !containsAll(setup, "pytest fixture with yield teardown") {
+		t.Errorf("expected yield fixtures in setup conventions, got %v", setup)
+	}
+
+	// Go tests: golden files and testing hooks
+	fx = DetectFixtures(projectDir, "internal/render")
+	if fx == nil {
+		t.Fatal("expected fixtures under internal/render")
+	}
+	kinds = make(map[string]FixtureKind)
+	for _, k := range fx.Kinds {
+		kinds[k.Kind+"/"+k.Library] = k
+	}
+	if _, ok := kinds["golden/"]; !ok {
+		t.Errorf("golden files not detected: %+v", fx.Kinds)
+	}
+	setup = nil
+	for _, c := range fx.Setup {
+		setup = append(setup, c.Convention)
+	}
+	if !containsAll(setup, "t.Cleanup", "t.TempDir") {
+		t.Errorf("expected t.Cleanup and t.TempDir, got %v", setup)
+	}
+	checklist := strings.Join(fixtureChecklist(fx), "\n")
+	if !strings.Contains(checklist, "golden files") || !strings.Contains(checklist, "t.Cleanup") {
+		t.Errorf("checklist does not mention the fixtures: %s", checklist)
+	}
+
+	// Source files are not test helpers
+	if isFixtureSource("src/users.py") {
+		t.Error("src/users.py is not a test file")
+	}
+}
+
+func containsAll(list []string, want ...string) bool {
+	have := make(map[string]bool, len(list))
+	for _, s := range list {
+		have[s] = true
+	}
+	for _, w := range want {
+		if !have[w] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCondenseFrameworkProfiles(t *testing.T) {
+	g := &Generator{}
+
+	spring := `package com.acme.users;
+
+import org.springframework.web.bind.annotation.*;
+
+@RestController
+@RequestMapping(
+    value = "/users",
+    produces = "application/json")
+public class UserController {
+    private final UserService userService;
+
+    public UserController(UserService userService) {
+        this.userService = userService;
+    }
+
+    @GetMapping("/{id}")
+    @PreAuthorize("hasRole('ADMIN')")
+    public ResponseEntity<User> getUser(
+        @PathVariable Long id,
+        @RequestParam(required = false) String expand) {
+        if (id == null) {
+            return ResponseEntity.badRequest().build();
+        }
+        return ResponseEntity.ok(userService.find(id));
+    }
+}
+`
+	got := g.condenseFile(strings.Split(spring, "\n"), "user", condenseProfileFor("UserController.java"))
+	for _, want := range []string{"@RestController", "produces = \"application/json\")", "public class {Name}Controller {", "public {Name}Controller({Name}Service {name}Service) { }", "@GetMapping(\"/{id}\")", "@PreAuthorize", "public ResponseEntity<{Name}> get{Name}("} {
+		if !strings.Contains(got, want) {
+			t.Errorf("spring snippet missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"package com.acme", "import org", "badRequest", "\n@PathVariable", "\n        @RequestParam(required = false) String expand) {\n        if"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("spring snippet should not contain %q", unwanted)
+		}
+	}
+
+	fastapi := `from fastapi import APIRouter, Depends
+
+router = APIRouter(
+    prefix="/users",
+    tags=["users"],
+)
+
+
+class UserOut(BaseModel):
+    """A user as returned by the API."""
+    id: int
+    email: str
+
+
+@router.get(
+    "/{user_id}",
+    response_model=UserOut,
+)
+async def get_user(user_id: int, db: Session = Depends(get_db)):
+    user = db.get(User, user_id)
+    if user is None:
+        raise HTTPException(404)
+    return user
+`
+	got = g.condenseFile(strings.Split(fastapi, "\n"), "user", condenseProfileFor("users.py"))
+	for _, want := range []string{"router = APIRouter(", "prefix=", "class {Name}Out(BaseModel):", "    email: str", "@router.get(", "response_model={Name}Out,\n)", "async def get_{name}({name}_id: int, db: Session = Depends(get_db)):\n    ..."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fastapi snippet missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"from fastapi", "HTTPException", "A user as returned"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("fastapi snippet should not contain %q", unwanted)
+		}
+	}
+
+	gin := `package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler serves users
+type UserHandler struct {
+	svc *UserService
+}
+
+func (h *UserHandler) Register(r *gin.RouterGroup) {
+	users := r.Group("/users")
+	users.Use(RequireAuth())
+	users.GET("/:id", h.GetUser)
+}
+
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+func (h *UserHandler) GetUser(c *gin.Context) {
+	c.JSON(http.StatusOK, h.svc.Find(c.Param("id")))
+}
+`
+	got = g.condenseFile(strings.Split(gin, "\n"), "user", condenseProfileFor("user_handler.go"))
+	for _, want := range []string{"type {Name}Handler struct {", "func (h *{Name}Handler) Register(r *gin.RouterGroup) {\n\t{names} := r.Group(\"/{names}\")", "\t{names}.GET(\"/:id\", h.Get{Name})\n}", "func RequireAuth() gin.HandlerFunc {\n\treturn func(c *gin.Context) { ... }\n}", "func (h *{Name}Handler) Get{Name}(c *gin.Context) { }"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("gin snippet missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"package handlers", "net/http", "AbortWithStatus"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("gin snippet should not contain %q", unwanted)
+		}
+	}
+}
+
+func TestEnforceTokenBudgetTrimsLeastValuableFirst(t *testing.T) {
+	g := &Generator{}
+	longCode := func(n int) string {
+		var lines []string
+		for i := 0; i < n; i++ {
+			lines = append(lines, "export function handle{Name}Request(input: {Name}Input): Promise<{Name}Output> { return this.{name}Service.process(input) }")
+		}
+		return strings.Join(lines, "\n")
+	}
+	bp := &Blueprint{
+		TaskType: TaskAddEndpoint,
+		Snippets: map[string]*SnippetEntry{
+			"controller": {Code: longCode(40), SourceFile: "users.controller.ts"},
+			"service":    {Code: longCode(40), SourceFile: "users.service.ts"},
+			"test":       {Code: longCode(40), SourceFile: "users.spec.ts"},
+		},
+		Conventions: &Conventions{AuthGuard: "JwtAuthGuard", Validation: "class-validator"},
+		Decisions:   []Decision{{ID: "d1", Title: "Use JwtAuthGuard on every controller"}},
+	}
+
+	g.enforceTokenBudget(bp)
+
+	if bp.Budget == nil || len(bp.Budget.Trimmed) == 0 {
+		t.Fatal("expected a budget report listing the cuts")
+	}
+	if bp.Budget.OriginalTokens <= tokenBudget || bp.Budget.Tokens > tokenBudget {
+		t.Errorf("budget tokens = %d -> %d, want over %d before and within it after", bp.Budget.OriginalTokens, bp.Budget.Tokens, tokenBudget)
+	}
+	if first := bp.Budget.Trimmed[0]; first.Section != "snippets.test" || first.Action != "shortened" || first.TokensSaved <= 0 {
+		t.Errorf("first cut = %+v, want the test snippet shortened", first)
+	}
+	if bp.Snippets["controller"] == nil {
+		t.Error("controller snippet should be shortened, not dropped, while shorter cuts suffice")
+	}
+	if bp.Conventions == nil || len(bp.Decisions) != 1 {
+		t.Error("conventions and decisions should survive when trimming snippets is enough")
+	}
+
+	small := &Blueprint{TaskType: TaskFixBug, Checklist: []string{"Fix the code"}}
+	g.enforceTokenBudget(small)
+	if small.Budget != nil {
+		t.Errorf("blueprint within budget should not report cuts, got %+v", small.Budget)
+	}
+}
+
+func TestBlueprintLimits(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	createNestJSProject(t, projectDir)
+	for _, name := range []string{"orders", "invoices", "refunds"} {
+		dir := filepath.Join(projectDir, "src", "app", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		code := "@Controller('" + name + "')\nexport class " + toPascalCase(name) + "Controller {}\n"
+		if err := os.WriteFile(filepath.Join(dir, name+".controller.ts"), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples := func(limits types.BlueprintConfig) int {
+		generator := NewGenerator(projectDir, tcDir, store)
+		generator.SetLimits(limits)
+		blueprint, err := generator.Generate(TaskAddEndpoint, "", "")
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		return len(blueprint.Examples)
+	}
+	if got := examples(types.BlueprintConfig{}); got != defaultMaxExamples {
+		t.Errorf("default examples = %d, want %d", got, defaultMaxExamples)
+	}
+
+	if err := store.SaveConfig(&types.Config{Name: "test-app", Blueprint: types.BlueprintConfig{MaxExamples: 1}}); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	if got := examples(types.BlueprintConfig{}); got != 1 {
+		t.Errorf("configured examples = %d, want 1", got)
+	}
+	// The request wins over the config
+	if got := examples(types.BlueprintConfig{MaxExamples: 4}); got != 4 {
+		t.Errorf("requested examples = %d, want 4", got)
+	}
+
+	generator := NewGenerator(projectDir, tcDir, store)
+	generator.SetLimits(types.BlueprintConfig{MaxExamples: 50, MaxImportsPerType: 2})
+	generator.resolveLimits()
+	if generator.maxExamples != maxExamplesLimit || generator.maxImports != 2 {
+		t.Errorf("limits = %d examples, %d imports; want %d and 2", generator.maxExamples, generator.maxImports, maxExamplesLimit)
+	}
+}
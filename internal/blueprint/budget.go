@@ -0,0 +1,178 @@
+package blueprint
+
+import (
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/tokenest"
+)
+
+// ---------------------------------------------------------------------------
+// Token budget enforcement
+// ---------------------------------------------------------------------------
+
+// BudgetReport says how a blueprint was fitted to the token budget
+type BudgetReport struct {
+	Limit          int          `json:"limit"`
+	OriginalTokens int          `json:"original_tokens"`
+	Tokens         int          `json:"tokens"`
+	Trimmed        []BudgetTrim `json:"trimmed"`
+}
+
+// BudgetTrim is one cut made to fit the budget
+type BudgetTrim struct {
+	Section     string `json:"section"` // e.g. "snippets.test", "examples", "conventions"
+	Action      string `json:"action"`  // shortened or dropped
+	TokensSaved int    `json:"tokens_saved"`
+}
+
+// Shortened sizes, kept before a section is dropped altogether
+const (
+	trimmedSnippetLines  = 8
+	trimmedSkeletonLines = 15
+	trimmedCorrelations  = 3
+	trimmedImports       = 2
+)
+
+// snippetDropOrder lists snippet kinds least useful first: the controller
+// shows the most of how a feature is wired
+var snippetDropOrder = []string{"test", "types", "schema", "module", "service", "controller"}
+
+// budgetStep is one cut; apply reports whether there was anything to cut
+type budgetStep struct {
+	section string
+	action  string
+	apply   func(bp *Blueprint) bool
+}
+
+// budgetSteps lists the cuts least valuable first. Content is shortened
+// before it is dropped, so every snippet is cut to a few lines before any
+// one disappears, and conventions, which apply to every file written, go
+// last. Decisions, warnings and the checklist are never cut.
+func budgetSteps() []budgetStep {
+	var steps []budgetStep
+	for _, key := range snippetDropOrder {
+		steps = append(steps, budgetStep{"snippets." + key, "shortened", func(bp *Blueprint) bool {
+			s := bp.Snippets[key]
+			if s == nil {
+				return false
+			}
+			code, cut := firstLines(s.Code, trimmedSnippetLines)
+			s.Code = code
+			return cut
+		}})
+	}
+	steps = append(steps,
+		budgetStep{"examples", "shortened", func(bp *Blueprint) bool {
+			cut := false
+			for i := range bp.Examples {
+				var c bool
+				bp.Examples[i].Skeleton, c = firstLines(bp.Examples[i].Skeleton, trimmedSkeletonLines)
+				cut = cut || c
+			}
+			return cut
+		}},
+		// The kinds still name a file to read
+		budgetStep{"fixtures.examples", "dropped", func(bp *Blueprint) bool {
+			if bp.Fixtures == nil || len(bp.Fixtures.Examples) == 0 {
+				return false
+			}
+			bp.Fixtures.Examples = nil
+			return true
+		}},
+		budgetStep{"correlations", "shortened", func(bp *Blueprint) bool {
+			if len(bp.Correlations) <= trimmedCorrelations {
+				return false
+			}
+			bp.Correlations = bp.Correlations[:trimmedCorrelations]
+			return true
+		}},
+	)
+	for _, key := range snippetDropOrder {
+		steps = append(steps, budgetStep{"snippets." + key, "dropped", func(bp *Blueprint) bool {
+			if bp.Snippets[key] == nil {
+				return false
+			}
+			delete(bp.Snippets, key)
+			return true
+		}})
+	}
+	steps = append(steps,
+		budgetStep{"imports", "shortened", func(bp *Blueprint) bool {
+			cut := false
+			for kind, imps := range bp.Imports {
+				if len(imps) > trimmedImports {
+					bp.Imports[kind] = imps[:trimmedImports]
+					cut = true
+				}
+			}
+			return cut
+		}},
+		budgetStep{"imports", "dropped", func(bp *Blueprint) bool {
+			if bp.Imports == nil {
+				return false
+			}
+			bp.Imports = nil
+			return true
+		}},
+		// Paths and descriptions still point at the files to follow
+		budgetStep{"examples", "dropped", func(bp *Blueprint) bool {
+			cut := false
+			for i := range bp.Examples {
+				if bp.Examples[i].Skeleton != "" {
+					bp.Examples[i].Skeleton = ""
+					cut = true
+				}
+			}
+			return cut
+		}},
+		budgetStep{"conventions", "dropped", func(bp *Blueprint) bool {
+			if bp.Conventions == nil {
+				return false
+			}
+			bp.Conventions = nil
+			return true
+		}},
+	)
+	return steps
+}
+
+// enforceTokenBudget cuts the least valuable content until the blueprint
+// fits tokenBudget, counting tokens the way tool responses are counted, and
+// records each cut and what it saved in bp.Budget
+func (g *Generator) enforceTokenBudget(bp *Blueprint) {
+	tokens := tokenest.CountJSON(bp)
+	if tokens <= tokenBudget {
+		return
+	}
+
+	report := &BudgetReport{Limit: tokenBudget, OriginalTokens: tokens}
+	for _, step := range budgetSteps() {
+		if tokens <= tokenBudget {
+			break
+		}
+		if !step.apply(bp) {
+			continue
+		}
+		after := tokenest.CountJSON(bp)
+		report.Trimmed = append(report.Trimmed, BudgetTrim{
+			Section:     step.section,
+			Action:      step.action,
+			TokensSaved: tokens - after,
+		})
+		tokens = after
+	}
+	if len(bp.Snippets) == 0 {
+		bp.Snippets = nil
+	}
+	bp.Budget = report
+	report.Tokens = tokenest.CountJSON(bp)
+}
+
+// firstLines keeps the first n lines of text, marking the cut with "..."
+func firstLines(text string, n int) (string, bool) {
+	lines := strings.Split(text, "\n")
+	if len(lines) <= n+1 {
+		return text, false
+	}
+	return strings.Join(lines[:n], "\n") + "\n...", true
+}
@@ -0,0 +1,294 @@
+package blueprint
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// condenseProfile tells condenseFile how a framework marks up the code
+// worth keeping in a snippet: which decorators or annotations carry a
+// block worth quoting whole, which only annotate parameters, and what
+// declarations look like
+type condenseProfile struct {
+	name string
+
+	// Decorators kept with their whole argument block, e.g. @Module({...}).
+	// Any decorator whose arguments span lines is collected the same way.
+	blockDecorators map[string]bool
+	// Decorators that annotate parameters; dropped when they start a line
+	// of a signature the signature collector did not consume
+	paramDecorators map[string]bool
+
+	class       *regexp.Regexp // class declarations, whose bodies are walked
+	constructor *regexp.Regexp // constructors inside a class
+	method      *regexp.Regexp // methods inside a class, or functions when topLevelFuncs
+	typeDecl    *regexp.Regexp // types and interfaces, condensed to "fields..."
+
+	// Functions outside classes are condensed like methods (Go)
+	topLevelFuncs bool
+	// Lines kept that are not declarations: in Go, route registrations and
+	// the handler a middleware wrapper returns, from inside skipped bodies;
+	// in Python, the module-level router the decorators hang off
+	keepLines *regexp.Regexp
+	// Blocks are delimited by indentation rather than braces (Python)
+	indented bool
+	// Indented blocks also close with this keyword (Ruby's end)
+	blockEnd string
+	// Opening braces of classes and methods go on a line of their own
+	// (PHP's PSR-12); they are joined to the declaration
+	braceOnOwnLine bool
+}
+
+var (
+	decoratorLineRe   = regexp.MustCompile(`^\s*@([\w.]+)`)
+	controlFlowLineRe = regexp.MustCompile(`^(?:if|for|while|switch|catch|return|else|do|try|when)\b`)
+)
+
+// nestProfile covers NestJS and other TypeScript/JavaScript code
+var nestProfile = &condenseProfile{
+	name:            "nestjs",
+	blockDecorators: map[string]bool{"Module": true, "Injectable": true},
+	paramDecorators: map[string]bool{
+		"Body": true, "Query": true, "Param": true, "Headers": true,
+		"Req": true, "Res": true, "Session": true, "UploadedFile": true,
+		"UploadedFiles": true, "Ip": true, "HostParam": true,
+	},
+	class:       regexp.MustCompile(`^\s*(export\s+)?(abstract\s+)?class\s+\w+`),
+	constructor: regexp.MustCompile(`^\s*constructor\s*\(`),
+	method:      regexp.MustCompile(`^\s*(private\s+|public\s+|protected\s+)?(static\s+)?(async\s+)?(\w+)\s*(<[^>]+>)?\s*\(`),
+	typeDecl:    regexp.MustCompile(`^\s*export\s+(?:type|interface)\s+`),
+}
+
+// springProfile covers Spring (and other annotated JVM code) in Java and
+// Kotlin
+var springProfile = &condenseProfile{
+	name: "spring",
+	paramDecorators: map[string]bool{
+		"RequestBody": true, "PathVariable": true, "RequestParam": true, "RequestHeader": true,
+		"RequestPart": true, "CookieValue": true, "MatrixVariable": true, "ModelAttribute": true,
+		"Valid": true, "Validated": true, "AuthenticationPrincipal": true, "NotNull": true, "Nullable": true,
+	},
+	class:       regexp.MustCompile(`^\s*(?:(?:public|protected|private|abstract|final|static|open|data|sealed|internal|inner)\s+)*(?:class|interface|enum|record|object)\s+\w+`),
+	constructor: regexp.MustCompile(`^\s*(?:(?:public|protected|private)\s+)?(?:constructor|[A-Z]\w*)\s*\(`),
+	method:      regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|final|abstract|synchronized|default|override|open|internal|suspend|inline)\s+)*(?:fun\s+(?:<[^>]+>\s*)?\w+|(?:<[^>]+>\s+)?[\w.]+(?:<[^(]*?>)?(?:\[\])*\s+\w+)\s*\(`),
+}
+
+// ginProfile covers Go HTTP code: Gin, Echo, Fiber, chi and net/http.
+// Go has no decorators; routes are registered in code and middleware
+// wraps handlers, so those lines are kept from function bodies.
+var ginProfile = &condenseProfile{
+	name:          "gin",
+	method:        regexp.MustCompile(`^func\s+`),
+	typeDecl:      regexp.MustCompile(`^type\s+\w+(?:\[[^\]]*\])?\s+(?:struct|interface)\s*\{`),
+	topLevelFuncs: true,
+	keepLines:     regexp.MustCompile(`^\s*(?:\w+\s*:=\s*)?\w+\.(?:GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|Any|Handle|HandleFunc|Use|Group|Get|Post|Put|Patch|Delete|Route|Mount)\(|^\s*return\s+func\(|^\s*return\s+http\.HandlerFunc\(`),
+}
+
+// fastapiProfile covers decorated Python: FastAPI, Flask and Django views
+var fastapiProfile = &condenseProfile{
+	name:      "fastapi",
+	class:     regexp.MustCompile(`^\s*class\s+\w+`),
+	method:    regexp.MustCompile(`^\s*(?:async\s+)?def\s+\w+`),
+	indented:  true,
+	keepLines: regexp.MustCompile(`^\w+\s*=\s*(?:APIRouter|FastAPI|Blueprint|Flask|Router)\(`),
+}
+
+// laravelProfile covers PHP classes: Laravel controllers, models, form
+// requests and resources
+var laravelProfile = &condenseProfile{
+	name:           "laravel",
+	class:          regexp.MustCompile(`^\s*(?:(?:final|abstract|readonly)\s+)*(?:class|interface|trait|enum)\s+\w+`),
+	constructor:    regexp.MustCompile(`^\s*(?:public\s+)?function\s+__construct\s*\(`),
+	method:         regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|final|abstract)\s+)*function\s+\w+\s*\(`),
+	braceOnOwnLine: true,
+}
+
+// railsProfile covers Ruby: Rails controllers, models and serializers,
+// whose class-level calls (before_action, has_many, validates) are kept
+// like Python's class fields
+var railsProfile = &condenseProfile{
+	name:     "rails",
+	class:    regexp.MustCompile(`^\s*(?:class|module)\s+[\w:]+`),
+	method:   regexp.MustCompile(`^\s*def\s+[\w.]+[?!=]?`),
+	indented: true,
+	blockEnd: "end",
+}
+
+// condenseProfileFor picks the profile for a file by its extension
+func condenseProfileFor(path string) *condenseProfile {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".java", ".kt", ".kts", ".scala", ".groovy":
+		return springProfile
+	case ".go":
+		return ginProfile
+	case ".py":
+		return fastapiProfile
+	case ".php":
+		return laravelProfile
+	case ".rb":
+		return railsProfile
+	}
+	return nestProfile
+}
+
+// joinOwnLineBraces moves a "{" standing on a line of its own to the end
+// of the line before, so declarations open their block on their own line
+func joinOwnLineBraces(lines []string) []string {
+	joined := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "{" && len(joined) > 0 {
+			joined[len(joined)-1] = strings.TrimRight(joined[len(joined)-1], " \t\r") + " {"
+			continue
+		}
+		joined = append(joined, line)
+	}
+	return joined
+}
+
+// decoratorName returns the name of a decorator line without its receiver:
+// "Body" for @Body(), "get" for @router.get("/")
+func decoratorName(trimmed string) string {
+	sub := decoratorLineRe.FindStringSubmatch(trimmed)
+	if len(sub) < 2 {
+		return ""
+	}
+	name := sub[1]
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// openParens reports whether a line leaves parentheses or braces open, so
+// a decorator's arguments continue on the next lines
+func openParens(line string) bool {
+	return strings.Count(line, "(")+strings.Count(line, "{")+strings.Count(line, "[") >
+		strings.Count(line, ")")+strings.Count(line, "}")+strings.Count(line, "]")
+}
+
+// closeOpenBody turns a kept line that opens a block, such as
+// "return func(c *gin.Context) {", into a one-line placeholder
+func closeOpenBody(line string) string {
+	if strings.Count(line, "{") <= strings.Count(line, "}") {
+		return line
+	}
+	idx := strings.LastIndex(line, "{")
+	return strings.TrimRight(line[:idx+1], " ") + " ... }"
+}
+
+// condenseIndented is condenseFile for languages whose blocks are set by
+// indentation: decorators, class headers and their fields, and function
+// signatures are kept; function bodies become "...".
+func (g *Generator) condenseIndented(lines []string, featureName string, profile *condenseProfile) string {
+	var result []string
+	seen := make(map[string]bool)
+	add := func(s string) {
+		key := strings.TrimSpace(s)
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		result = append(result, s)
+	}
+
+	bodyIndent := -1 // skip lines indented deeper than this
+	inDocstring := ""
+	var classIndents []int // open classes, for blockEnd closers
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if inDocstring != "" {
+			if strings.Contains(trimmed, inDocstring) {
+				inDocstring = ""
+			}
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if bodyIndent >= 0 {
+			if indent > bodyIndent {
+				continue
+			}
+			bodyIndent = -1
+		}
+		if profile.blockEnd != "" && trimmed == profile.blockEnd {
+			// Method closers are written with their "...", class closers
+			// are kept; those of skipped blocks are dropped
+			if n := len(classIndents); n > 0 && classIndents[n-1] == indent {
+				classIndents = classIndents[:n-1]
+				result = append(result, line)
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, `"""`) || strings.HasPrefix(trimmed, `'''`) {
+			quote := trimmed[:3]
+			if len(trimmed) < 6 || !strings.HasSuffix(trimmed, quote) {
+				inDocstring = quote
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "from ") {
+			continue
+		}
+
+		switch {
+		case decoratorLineRe.MatchString(trimmed):
+			block := line
+			for depth := 0; openParens(block) && i+1 < len(lines) && depth < maxSnippetLines; depth++ {
+				i++
+				block += "\n" + strings.TrimRight(lines[i], " \t\r")
+			}
+			result = append(result, block)
+		case profile.method.MatchString(trimmed):
+			sig := line
+			for j := 0; sigContinues(sig, profile) && i+1 < len(lines) && j < 8; j++ {
+				i++
+				sig += "\n" + strings.TrimRight(lines[i], " \t\r")
+			}
+			if profile.blockEnd != "" {
+				result = append(result, sig, strings.Repeat(" ", indent+2)+"...", strings.Repeat(" ", indent)+profile.blockEnd)
+			} else {
+				result = append(result, sig, strings.Repeat(" ", indent+4)+"...")
+			}
+			bodyIndent = indent
+		case profile.class.MatchString(trimmed):
+			add(line)
+			if profile.blockEnd != "" {
+				classIndents = append(classIndents, indent)
+			}
+		case indent == 0 && profile.keepLines != nil && profile.keepLines.MatchString(trimmed):
+			block := line
+			for depth := 0; openParens(block) && i+1 < len(lines) && depth < maxSnippetLines; depth++ {
+				i++
+				block += "\n" + strings.TrimRight(lines[i], " \t\r")
+			}
+			add(block)
+		case indent > 0 && !controlFlowLineRe.MatchString(trimmed):
+			// Class-level fields: pydantic and dataclass attributes, Meta
+			add(line)
+		}
+		if len(result) >= maxSnippetLines {
+			break
+		}
+	}
+
+	if len(result) > maxSnippetLines {
+		result = result[:maxSnippetLines]
+	}
+	text := g.templatize(strings.Join(result, "\n"), featureName)
+	return strings.TrimSpace(text)
+}
+
+// sigContinues reports whether a function signature of an indented
+// language runs on to the next line: until the colon in Python, while
+// parentheses are open in Ruby
+func sigContinues(sig string, profile *condenseProfile) bool {
+	if profile.blockEnd != "" {
+		return openParens(sig)
+	}
+	return !strings.HasSuffix(strings.TrimSpace(sig), ":")
+}
@@ -0,0 +1,175 @@
+package blueprint
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Confidence levels. The score is calibrated so that each band maps to how
+// much of the blueprint an agent can follow without further exploration.
+const (
+	ConfidenceHigh   = "high"   // >= 0.75: follow file pattern, snippets and checklist as-is
+	ConfidenceMedium = "medium" // >= 0.45: follow, but verify against the examples
+	ConfidenceLow    = "low"    // < 0.45: treat as a starting point, explore first
+)
+
+// ConfidenceComponent is one weighted input to a blueprint's confidence
+type ConfidenceComponent struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"` // share of the total score; weights sum to 1
+	Score  float64 `json:"score"`  // 0-1 for this component
+	Detail string  `json:"detail"`
+}
+
+// confidenceWeights lists the components that matter for each task type.
+// Components missing from a task's list do not affect its score.
+var confidenceWeights = map[TaskType]map[string]float64{
+	TaskAddEndpoint: {"framework": 0.15, "examples": 0.35, "snippets": 0.15, "conventions": 0.2, "decisions": 0.1, "warnings": 0.05},
+	TaskAddFeature:  {"examples": 0.4, "snippets": 0.2, "conventions": 0.25, "decisions": 0.1, "warnings": 0.05},
+	TaskAddService:  {"examples": 0.4, "snippets": 0.2, "conventions": 0.25, "decisions": 0.1, "warnings": 0.05},
+	TaskAddTest:     {"examples": 0.45, "snippets": 0.2, "conventions": 0.2, "decisions": 0.1, "warnings": 0.05},
+	TaskFixBug:      {"target": 0.5, "correlations": 0.2, "decisions": 0.15, "warnings": 0.15},
+	TaskRefactor:    {"target": 0.5, "correlations": 0.3, "decisions": 0.1, "warnings": 0.1},
+}
+
+// genericConfidenceCeiling caps blueprints for unknown task types, which
+// only carry a generic checklist plus matched knowledge.
+const genericConfidenceCeiling = 0.4
+
+// componentOrder keeps the breakdown and explanation stable
+var componentOrder = []string{"target", "framework", "examples", "snippets", "conventions", "correlations", "decisions", "warnings"}
+
+// calibrateConfidence replaces the blueprint's confidence with a weighted
+// score over its components and explains which parts to trust.
+func (g *Generator) calibrateConfidence(bp *Blueprint) {
+	weights, ok := confidenceWeights[bp.TaskType]
+	ceiling := 1.0
+	if !ok {
+		weights = map[string]float64{"decisions": 0.5, "warnings": 0.5}
+		ceiling = genericConfidenceCeiling
+	}
+
+	bp.ConfidenceBreakdown = nil
+	bp.ConfidenceExplanation = nil
+
+	total := 0.0
+	for _, name := range componentOrder {
+		weight, ok := weights[name]
+		if !ok {
+			continue
+		}
+		score, detail, advice := scoreComponent(name, bp)
+		total += weight * score
+		bp.ConfidenceBreakdown = append(bp.ConfidenceBreakdown, ConfidenceComponent{
+			Name:   name,
+			Weight: weight,
+			Score:  round2(score),
+			Detail: detail,
+		})
+		bp.ConfidenceExplanation = append(bp.ConfidenceExplanation,
+			fmt.Sprintf("%s (%.0f%%): %s — %s", name, weight*100, detail, advice))
+	}
+
+	bp.Confidence = round2(total * ceiling)
+	switch {
+	case bp.Confidence >= 0.75:
+		bp.ConfidenceLevel = ConfidenceHigh
+	case bp.Confidence >= 0.45:
+		bp.ConfidenceLevel = ConfidenceMedium
+	default:
+		bp.ConfidenceLevel = ConfidenceLow
+	}
+}
+
+// scoreComponent returns a 0-1 score, what was found, and how far an agent
+// should trust the corresponding part of the blueprint.
+func scoreComponent(name string, bp *Blueprint) (float64, string, string) {
+	switch name {
+	case "target":
+		if bp.Path == "" {
+			return 0, "no target path given", "guidance is generic; pass path for targeted help"
+		}
+		for _, ex := range bp.Examples {
+			if ex.Path == bp.Path && ex.Skeleton != "" {
+				return 1, "target file parsed", "trust the target structure"
+			}
+		}
+		return 0.4, "target path given but not parsed", "read the target file before changing it"
+
+	case "framework":
+		framework := strings.TrimPrefix(bp.Source, "pattern-analysis:")
+		if framework == "" || framework == "unknown" || framework == bp.Source {
+			return 0.2, "framework not detected", "file pattern is a generic guess; check project layout"
+		}
+		return 1, "framework detected: " + framework, "trust the file pattern"
+
+	case "examples":
+		n := len(bp.Examples)
+		switch {
+		case n == 0:
+			return 0, "no examples found", "explore sibling code before writing"
+		case n == 1:
+			return 0.6, "1 example found", "follow it, but it may be atypical"
+		case n == 2:
+			return 0.8, "2 examples found", "follow the first example"
+		}
+		return 1, fmt.Sprintf("%d examples found", n), "follow the first example"
+
+	case "snippets":
+		if len(bp.Snippets) == 0 {
+			return 0, "no snippets extracted", "write code from the examples instead"
+		}
+		score := 0.7
+		if len(bp.Imports) > 0 {
+			score = 1
+		}
+		return score, fmt.Sprintf("%d snippets, %d import groups", len(bp.Snippets), len(bp.Imports)), "use snippets as templates"
+
+	case "conventions":
+		detected := countConventions(bp.Conventions)
+		if detected == 0 {
+			return 0, "no conventions detected", "confirm auth, validation and error handling in a sibling file"
+		}
+		return math.Min(1, float64(detected)/4), fmt.Sprintf("%d conventions detected", detected), "respect detected conventions; verify anything not listed"
+
+	case "correlations":
+		if len(bp.Correlations) == 0 {
+			return 0, "no co-change data", "search for usages manually before changing"
+		}
+		return 1, fmt.Sprintf("%d co-changing file groups", len(bp.Correlations)), "update correlated files together"
+
+	case "decisions":
+		if len(bp.Decisions) == 0 {
+			return 0, "no matching team decisions", "no recorded constraints; ask if unsure"
+		}
+		return math.Min(1, float64(len(bp.Decisions))/2), fmt.Sprintf("%d decisions matched", len(bp.Decisions)), "these are binding team choices"
+
+	case "warnings":
+		if len(bp.Warnings) == 0 {
+			return 0, "no matching warnings", "no known pitfalls recorded"
+		}
+		return 1, fmt.Sprintf("%d warnings matched", len(bp.Warnings)), "check each before finishing"
+	}
+	return 0, "", ""
+}
+
+func countConventions(c *Conventions) int {
+	if c == nil {
+		return 0
+	}
+	n := 0
+	for _, v := range []string{c.AuthGuard, c.Validation, c.ResponseEnvelope, c.Logging, c.DI, c.ErrorHandling} {
+		if v != "" {
+			n++
+		}
+	}
+	if c.Naming != nil && (c.Naming.Files != "" || c.Naming.Classes != "" || c.Naming.Methods != "" || c.Naming.Types != "") {
+		n++
+	}
+	return n
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,344 @@
+package blueprint
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+)
+
+// responseEnvelope is a detected response shape and a literal string that
+// compliant handler files contain.
+type responseEnvelope struct {
+	description string
+	marker      string
+	hits        int // handler call sites using the shape
+}
+
+// envelopeField is one field of a wrapper type
+type envelopeField struct {
+	name string
+	typ  string
+}
+
+var (
+	ginWrapperRe     = regexp.MustCompile(`\.(?:JSON|IndentedJSON|PureJSON)\(\s*[\w.]+\s*,\s*&?([\w.]+)\s*\{`)
+	ginHKeyRe        = regexp.MustCompile(`gin\.H\{\s*"(\w+)"`)
+	goFieldRe        = regexp.MustCompile(`^\s*(\w+)\s+([\w.*\[\]{}]+)\s*` + "`" + `[^` + "`" + `]*json:"([\w-]+)`)
+	fastAPIModelRe   = regexp.MustCompile(`response_model\s*=\s*([\w.]+)(?:\[\s*([\w.]+))?`)
+	pyFieldRe        = regexp.MustCompile(`^\s+(\w+)\s*:\s*([^=#\n]+)`)
+	springWrapperRe  = regexp.MustCompile(`ResponseEntity<\s*([\w.]+)\s*(<)?`)
+	javaFieldRe      = regexp.MustCompile(`^\s*(?:private|protected|public)\s+(?:final\s+)?([\w.<>?, \[\]]+?)\s+(\w+)\s*[;=]`)
+	kotlinFieldRe    = regexp.MustCompile(`\b(?:val|var)\s+(\w+)\s*:\s*([\w.<>?]+)`)
+	genericPayloadRe = regexp.MustCompile(`^(T|any|interface\{\}|Any|Object|Optional\[T\]|T\?|Optional\[Any\])$`)
+)
+
+// detectResponseEnvelope finds how handlers shape their responses, across
+// NestJS, Gin, FastAPI and Spring, and returns the most used shape.
+func (g *Generator) detectResponseEnvelope(searchPath string) *responseEnvelope {
+	// Go, Python and Java services rarely live under src/app
+	if _, err := os.Stat(searchPath); err != nil {
+		searchPath = g.projectRoot
+	}
+
+	var best *responseEnvelope
+	for _, detect := range []func(string) *responseEnvelope{
+		detectNestEnvelope,
+		detectGinEnvelope,
+		detectFastAPIEnvelope,
+		detectSpringEnvelope,
+	} {
+		if env := detect(searchPath); env != nil && (best == nil || env.hits > best.hits) {
+			best = env
+		}
+	}
+	return best
+}
+
+// detectNestEnvelope checks for { statusCode, data } in NestJS controllers
+func detectNestEnvelope(searchPath string) *responseEnvelope {
+	matches, err := search.SearchCode(`statusCode.*data|data.*statusCode`, searchPath, "*.controller.ts", 10)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+	return &responseEnvelope{
+		description: "{ statusCode: number, data: T } — controller wraps, service returns plain",
+		marker:      "statusCode",
+		hits:        len(matches),
+	}
+}
+
+// detectGinEnvelope finds the wrapper struct (or gin.H keys) passed to c.JSON
+func detectGinEnvelope(searchPath string) *responseEnvelope {
+	matches, err := search.SearchCode(`\.(JSON|IndentedJSON|PureJSON)\(`, searchPath, "*.go", 50)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+
+	wrappers := make(map[string]int)
+	keys := make(map[string]int)
+	hCalls := 0
+	for _, m := range matches {
+		if sub := ginWrapperRe.FindStringSubmatch(m.Content); sub != nil {
+			name := sub[1]
+			if name == "gin.H" || name == "H" || strings.HasPrefix(name, "map") {
+				hCalls++
+			} else {
+				wrappers[name]++
+			}
+		}
+		for _, sub := range ginHKeyRe.FindAllStringSubmatch(m.Content, -1) {
+			keys[sub[1]]++
+		}
+	}
+
+	wrapper, wrapperHits := mostCommon(wrappers)
+	if wrapper != "" && wrapperHits >= hCalls {
+		typeName := wrapper[strings.LastIndex(wrapper, ".")+1:]
+		shape := typeName
+		if fields := findGoStructFields(searchPath, typeName); len(fields) > 0 {
+			shape = renderEnvelope(fields)
+		}
+		return &responseEnvelope{
+			description: fmt.Sprintf("%s — handlers respond with c.JSON(status, %s{...})", shape, wrapper),
+			marker:      typeName,
+			hits:        wrapperHits,
+		}
+	}
+
+	if hCalls == 0 {
+		return nil
+	}
+	var keyNames []string
+	for k := range keys {
+		keyNames = append(keyNames, k)
+	}
+	sort.Slice(keyNames, func(i, j int) bool {
+		if keys[keyNames[i]] != keys[keyNames[j]] {
+			return keys[keyNames[i]] > keys[keyNames[j]]
+		}
+		return keyNames[i] < keyNames[j]
+	})
+	if len(keyNames) > 3 {
+		keyNames = keyNames[:3]
+	}
+	var fields []string
+	for _, k := range keyNames {
+		fields = append(fields, fmt.Sprintf("%q: ...", k))
+	}
+	return &responseEnvelope{
+		description: fmt.Sprintf("gin.H{%s} — handlers respond with c.JSON(status, gin.H{...}), no wrapper struct", strings.Join(fields, ", ")),
+		marker:      "gin.H",
+		hits:        hCalls,
+	}
+}
+
+// detectFastAPIEnvelope reads route response_model declarations. A generic
+// model (APIResponse[User]) is the envelope; otherwise routes return their
+// schema directly.
+func detectFastAPIEnvelope(searchPath string) *responseEnvelope {
+	matches, err := search.SearchCode(`response_model\s*=`, searchPath, "*.py", 50)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+
+	wrappers := make(map[string]int)
+	for _, m := range matches {
+		if sub := fastAPIModelRe.FindStringSubmatch(m.Content); sub != nil && sub[2] != "" && sub[1] != "List" && sub[1] != "list" {
+			wrappers[sub[1]]++
+		}
+	}
+
+	wrapper, hits := mostCommon(wrappers)
+	if wrapper == "" {
+		return &responseEnvelope{
+			description: "response_model=<Schema> on every route — return Pydantic models, no wrapper",
+			marker:      "response_model",
+			hits:        len(matches),
+		}
+	}
+
+	typeName := wrapper[strings.LastIndex(wrapper, ".")+1:]
+	shape := typeName + "[T]"
+	if fields := findPythonClassFields(searchPath, typeName); len(fields) > 0 {
+		shape = renderEnvelope(fields)
+	}
+	return &responseEnvelope{
+		description: fmt.Sprintf("%s — routes declare response_model=%s[Model]", shape, wrapper),
+		marker:      typeName,
+		hits:        hits,
+	}
+}
+
+// detectSpringEnvelope reads ResponseEntity<...> return types. A generic
+// type inside (ResponseEntity<ApiResponse<UserDto>>) is the envelope.
+func detectSpringEnvelope(searchPath string) *responseEnvelope {
+	var matches []string
+	for _, glob := range []string{"*.java", "*.kt"} {
+		found, err := search.SearchCode(`ResponseEntity<`, searchPath, glob, 50)
+		if err != nil {
+			continue
+		}
+		for _, m := range found {
+			matches = append(matches, m.Content)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	wrappers := make(map[string]int)
+	for _, content := range matches {
+		if sub := springWrapperRe.FindStringSubmatch(content); sub != nil && sub[2] != "" &&
+			sub[1] != "List" && sub[1] != "Map" && sub[1] != "Page" && sub[1] != "Set" {
+			wrappers[sub[1]]++
+		}
+	}
+
+	wrapper, hits := mostCommon(wrappers)
+	if wrapper == "" {
+		return &responseEnvelope{
+			description: "ResponseEntity<Dto> — return ResponseEntity.ok(dto), no wrapper type",
+			marker:      "ResponseEntity",
+			hits:        len(matches),
+		}
+	}
+
+	typeName := wrapper[strings.LastIndex(wrapper, ".")+1:]
+	shape := typeName + "<T>"
+	if fields := findJVMClassFields(searchPath, typeName); len(fields) > 0 {
+		shape = renderEnvelope(fields)
+	}
+	return &responseEnvelope{
+		description: fmt.Sprintf("%s — controllers return ResponseEntity<%s<Dto>>", shape, wrapper),
+		marker:      typeName,
+		hits:        hits,
+	}
+}
+
+// findGoStructFields returns the JSON fields of a Go struct definition
+func findGoStructFields(searchPath, name string) []envelopeField {
+	return readTypeFields(searchPath, `type\s+`+name+`(\[[^]]*\])?\s+struct`, "*.go", func(line string) (envelopeField, bool) {
+		sub := goFieldRe.FindStringSubmatch(line)
+		if sub == nil || sub[3] == "-" {
+			return envelopeField{}, false
+		}
+		return envelopeField{name: sub[3], typ: sub[2]}, true
+	}, func(line string) bool {
+		return strings.HasPrefix(strings.TrimSpace(line), "}")
+	})
+}
+
+// findPythonClassFields returns the annotated fields of a Pydantic model
+func findPythonClassFields(searchPath, name string) []envelopeField {
+	return readTypeFields(searchPath, `class\s+`+name+`\b`, "*.py", func(line string) (envelopeField, bool) {
+		sub := pyFieldRe.FindStringSubmatch(line)
+		if sub == nil || sub[1] == "class" || sub[1] == "model_config" {
+			return envelopeField{}, false
+		}
+		return envelopeField{name: sub[1], typ: strings.TrimSpace(sub[2])}, true
+	}, func(line string) bool {
+		// Class body ends at the next unindented line
+		return line != "" && line[0] != ' ' && line[0] != '\t'
+	})
+}
+
+// findJVMClassFields returns the fields of a Java class or Kotlin data class
+func findJVMClassFields(searchPath, name string) []envelopeField {
+	pattern := `(class|record)\s+` + name + `\b`
+	parse := func(line string) (envelopeField, bool) {
+		if sub := javaFieldRe.FindStringSubmatch(line); sub != nil && !strings.Contains(line, " static ") {
+			return envelopeField{name: sub[2], typ: sub[1]}, true
+		}
+		return envelopeField{}, false
+	}
+	end := func(line string) bool { return strings.HasPrefix(line, "}") }
+
+	if fields := readTypeFields(searchPath, pattern, "*.java", parse, end); len(fields) > 0 {
+		return fields
+	}
+
+	// Kotlin declares fields in the primary constructor
+	matches, err := search.SearchCode(pattern, searchPath, "*.kt", 1)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+	var fields []envelopeField
+	for _, sub := range kotlinFieldRe.FindAllStringSubmatch(matches[0].Content, -1) {
+		fields = append(fields, envelopeField{name: sub[1], typ: sub[2]})
+	}
+	return fields
+}
+
+// readTypeFields locates a type definition and parses field lines from its
+// body until end reports the body is over.
+func readTypeFields(searchPath, defPattern, glob string, parse func(string) (envelopeField, bool), end func(string) bool) []envelopeField {
+	matches, err := search.SearchCode(defPattern, searchPath, glob, 1)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+
+	path := matches[0].Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(searchPath, path)
+		if _, err := os.Stat(path); err != nil {
+			path = matches[0].Path
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var fields []envelopeField
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if lineNum <= matches[0].Line {
+			continue
+		}
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if end(line) || len(fields) >= 8 {
+			break
+		}
+		if field, ok := parse(line); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// renderEnvelope formats fields as { name: type }, showing the generic
+// payload field as T.
+func renderEnvelope(fields []envelopeField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		typ := strings.TrimSpace(f.typ)
+		if genericPayloadRe.MatchString(typ) {
+			typ = "T"
+		}
+		parts[i] = f.name + ": " + typ
+	}
+	return "{ " + strings.Join(parts, ", ") + " }"
+}
+
+// mostCommon returns the key with the highest count, ties broken by name
+func mostCommon(counts map[string]int) (string, int) {
+	best, bestCount := "", 0
+	for k, c := range counts {
+		if c > bestCount || (c == bestCount && k < best) {
+			best, bestCount = k, c
+		}
+	}
+	return best, bestCount
+}
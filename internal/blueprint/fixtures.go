@@ -0,0 +1,357 @@
+package blueprint
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fixtures describes how the project's tests build their data and manage
+// their environment, so a new test uses the same factories, golden files
+// and setup hooks as its neighbours instead of inventing its own
+type Fixtures struct {
+	Kinds    []FixtureKind       `json:"kinds,omitempty"`
+	Examples []SnippetEntry      `json:"examples,omitempty"` // factory and builder definitions
+	Setup    []FixtureConvention `json:"setup,omitempty"`    // setup/teardown hooks, most used first
+}
+
+// FixtureKind is one way of building test data found in the project
+type FixtureKind struct {
+	Kind    string `json:"kind"`              // factory, builder, golden, snapshot, testcontainers, fixture_files, fake_data
+	Library string `json:"library,omitempty"` // e.g. "factory_boy", "FactoryBot"
+	Files   int    `json:"files"`             // test files using it
+	Example string `json:"example"`           // a file or directory showing it
+}
+
+// FixtureConvention is a setup or teardown hook the tests use
+type FixtureConvention struct {
+	Convention string `json:"convention"` // e.g. "t.Cleanup", "beforeEach"
+	Phase      string `json:"phase"`      // setup, teardown
+	Files      int    `json:"files"`
+	Example    string `json:"example"`
+}
+
+// maxFixtureFiles caps the test files read per detection
+const maxFixtureFiles = 1500
+
+// maxFixtureFileSize skips generated or vendored giants
+const maxFixtureFileSize = 256 * 1024
+
+// maxFixtureExamples caps the factory snippets returned
+const maxFixtureExamples = 2
+
+// maxFixtureSnippetLines caps the length of one factory snippet
+const maxFixtureSnippetLines = 15
+
+// fixtureSignal is a pattern in test code that reveals a fixture kind
+type fixtureSignal struct {
+	kind, library string
+	pattern       *regexp.Regexp
+	definition    bool // the match starts a factory or builder worth quoting
+}
+
+var fixtureSignals = []fixtureSignal{
+	{kind: "factory", library: "factory_boy", pattern: regexp.MustCompile(`(?m)^class\s+\w+\(\s*factory\.[\w.]*Factory\s*\)`), definition: true},
+	{kind: "factory", library: "FactoryBot", pattern: regexp.MustCompile(`(?m)^\s*factory\s+:\w+`), definition: true},
+	{kind: "factory", library: "fishery", pattern: regexp.MustCompile(`(?m)^.*\bFactory\.define\b`), definition: true},
+	{kind: "factory", library: "Laravel factories", pattern: regexp.MustCompile(`(?m)^class\s+\w+Factory\s+extends\s+Factory\b`), definition: true},
+	{kind: "factory", pattern: regexp.MustCompile(`(?m)^(?:export\s+)?(?:func|function|def|const)\s+(?:[Nn]ew|[Mm]ake|[Cc]reate|[Bb]uild)_?\w*(?:Fixture|Factory|_fixture|_factory)\b`), definition: true},
+	{kind: "factory", pattern: regexp.MustCompile(`(?m)^(?:export\s+)?(?:public\s+)?(?:class|type|object)\s+\w+Factory\b`), definition: true},
+	{kind: "builder", pattern: regexp.MustCompile(`(?m)^(?:export\s+)?(?:public\s+)?(?:class|type|struct|object)\s+\w+Builder\b`), definition: true},
+	{kind: "builder", pattern: regexp.MustCompile(`(?m)^func\s+(?:\([^)]*\)\s+)?[Nn]ew\w+Builder\(`), definition: true},
+	{kind: "golden", pattern: regexp.MustCompile(`\.golden\b|flag\.Bool\("update"`)},
+	{kind: "snapshot", library: "jest snapshots", pattern: regexp.MustCompile(`\.toMatch(?:Inline)?Snapshot\(`)},
+	{kind: "snapshot", library: "syrupy", pattern: regexp.MustCompile(`\bsnapshot\s*==|from syrupy\b`)},
+	{kind: "testcontainers", library: "testcontainers", pattern: regexp.MustCompile(`testcontainers`)},
+	{kind: "fake_data", library: "faker", pattern: regexp.MustCompile(`@faker-js/faker|\bfrom faker import\b|\bimport faker\b|\bFaker::|gofakeit|go-faker`)},
+	{kind: "fixture_files", library: "pytest fixtures", pattern: regexp.MustCompile(`@pytest\.fixture`)},
+}
+
+// setupSignal is a setup or teardown hook
+type setupSignal struct {
+	convention, phase string
+	pattern           *regexp.Regexp
+}
+
+var setupSignals = []setupSignal{
+	{"TestMain", "setup", regexp.MustCompile(`func TestMain\(\s*\w+\s+\*testing\.M\)`)},
+	{"t.Cleanup", "teardown", regexp.MustCompile(`\b\w+\.Cleanup\(func\(\)`)},
+	{"t.TempDir", "setup", regexp.MustCompile(`\b\w+\.TempDir\(\)`)},
+	{"beforeEach", "setup", regexp.MustCompile(`\bbeforeEach\(`)},
+	{"beforeAll", "setup", regexp.MustCompile(`\bbeforeAll\(`)},
+	{"afterEach", "teardown", regexp.MustCompile(`\bafterEach\(`)},
+	{"afterAll", "teardown", regexp.MustCompile(`\bafterAll\(`)},
+	{"pytest fixture with yield teardown", "teardown", regexp.MustCompile(`(?s)@pytest\.fixture.{0,400}?\n\s+yield\b`)},
+	{"setUp", "setup", regexp.MustCompile(`\bdef setUp\(self\)|\bsetUp\(\)\s*(?::|\{)`)},
+	{"tearDown", "teardown", regexp.MustCompile(`\bdef tearDown\(self\)|\btearDown\(\)\s*(?::|\{)`)},
+	{"@BeforeEach", "setup", regexp.MustCompile(`@BeforeEach\b|@Before\b`)},
+	{"@AfterEach", "teardown", regexp.MustCompile(`@AfterEach\b|@After\b`)},
+	{"@BeforeAll", "setup", regexp.MustCompile(`@BeforeAll\b|@BeforeClass\b`)},
+	{"before do (RSpec)", "setup", regexp.MustCompile(`(?m)^\s*before(?:\(:\w+\))?\s+do\b`)},
+	{"let (RSpec)", "setup", regexp.MustCompile(`(?m)^\s*let!?\(:\w+\)`)},
+	{"after do (RSpec)", "teardown", regexp.MustCompile(`(?m)^\s*after(?:\(:\w+\))?\s+do\b`)},
+}
+
+// fixtureDirs are directories whose files are fixtures by convention
+var fixtureDirs = map[string]string{
+	"testdata":      "fixture_files",
+	"fixtures":      "fixture_files",
+	"__fixtures__":  "fixture_files",
+	"__snapshots__": "snapshot",
+	"factories":     "factory",
+}
+
+// DetectFixtures finds how tests under dir build their data: factories,
+// builders, golden files, testcontainers and the setup/teardown hooks they
+// use. When dir holds no tests, the whole project is searched, since
+// factories often live in a shared spec/ or tests/ tree. Returns nil when
+// nothing is found.
+func DetectFixtures(projectRoot, dir string) *Fixtures {
+	if dir == "" {
+		dir = projectRoot
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectRoot, dir)
+	}
+	if rel, err := filepath.Rel(projectRoot, dir); err != nil || strings.HasPrefix(rel, "..") {
+		dir = projectRoot
+	}
+
+	fx := detectFixturesIn(projectRoot, dir)
+	if fx == nil && dir != projectRoot {
+		fx = detectFixturesIn(projectRoot, projectRoot)
+	}
+	return fx
+}
+
+// fixtureTally counts the files showing one kind or convention
+type fixtureTally struct {
+	files   int
+	example string
+}
+
+func detectFixturesIn(projectRoot, dir string) *Fixtures {
+	kinds := make(map[[2]string]*fixtureTally)
+	setup := make(map[string]*fixtureTally)
+	phases := make(map[string]string)
+	var examples []SnippetEntry
+	goldenDirs := make(map[string]bool)
+	scanned := 0
+
+	tally := func(m map[[2]string]*fixtureTally, key [2]string, example string) {
+		t, ok := m[key]
+		if !ok {
+			t = &fixtureTally{example: example}
+			m[key] = t
+		}
+		t.files++
+	}
+
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := info.Name()
+		rel, _ := filepath.Rel(projectRoot, path)
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "dist" || name == "build" || name == "target") {
+				return filepath.SkipDir
+			}
+			if kind, ok := fixtureDirs[name]; ok {
+				tally(kinds, [2]string{kind, ""}, rel+"/")
+			}
+			return nil
+		}
+		if scanned >= maxFixtureFiles {
+			return filepath.SkipAll
+		}
+		if strings.HasSuffix(name, ".golden") {
+			// Counted once per directory; the tests reading them count too
+			if dirRel := filepath.ToSlash(filepath.Dir(rel)); !goldenDirs[dirRel] {
+				goldenDirs[dirRel] = true
+				tally(kinds, [2]string{"golden", ""}, dirRel+"/")
+			}
+			return nil
+		}
+		if !isFixtureSource(rel) || info.Size() > maxFixtureFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		scanned++
+		content := string(data)
+
+		seen := make(map[[2]string]bool)
+		for _, sig := range fixtureSignals {
+			loc := sig.pattern.FindStringIndex(content)
+			if loc == nil {
+				continue
+			}
+			key := [2]string{sig.kind, sig.library}
+			if !seen[key] {
+				seen[key] = true
+				tally(kinds, key, rel)
+			}
+			if sig.definition && len(examples) < maxFixtureExamples {
+				examples = append(examples, SnippetEntry{
+					Description: fixtureDescription(sig),
+					Code:        snippetFrom(content, loc[0]),
+					SourceFile:  rel,
+				})
+			}
+		}
+		for _, sig := range setupSignals {
+			if sig.pattern.MatchString(content) {
+				t, ok := setup[sig.convention]
+				if !ok {
+					t = &fixtureTally{example: rel}
+					setup[sig.convention] = t
+					phases[sig.convention] = sig.phase
+				}
+				t.files++
+			}
+		}
+		return nil
+	})
+
+	if len(kinds) == 0 && len(setup) == 0 {
+		return nil
+	}
+
+	fx := &Fixtures{Examples: examples}
+	for key, t := range kinds {
+		fx.Kinds = append(fx.Kinds, FixtureKind{Kind: key[0], Library: key[1], Files: t.files, Example: t.example})
+	}
+	sort.Slice(fx.Kinds, func(i, j int) bool {
+		if fx.Kinds[i].Files != fx.Kinds[j].Files {
+			return fx.Kinds[i].Files > fx.Kinds[j].Files
+		}
+		if fx.Kinds[i].Kind != fx.Kinds[j].Kind {
+			return fx.Kinds[i].Kind < fx.Kinds[j].Kind
+		}
+		return fx.Kinds[i].Library < fx.Kinds[j].Library
+	})
+	for convention, t := range setup {
+		fx.Setup = append(fx.Setup, FixtureConvention{Convention: convention, Phase: phases[convention], Files: t.files, Example: t.example})
+	}
+	sort.Slice(fx.Setup, func(i, j int) bool {
+		if fx.Setup[i].Files != fx.Setup[j].Files {
+			return fx.Setup[i].Files > fx.Setup[j].Files
+		}
+		return fx.Setup[i].Convention < fx.Setup[j].Convention
+	})
+	return fx
+}
+
+// isFixtureSource reports whether a project-relative file is test code or a
+// test helper: test files by name, and anything under a test, spec or
+// factories directory
+func isFixtureSource(rel string) bool {
+	switch strings.ToLower(filepath.Ext(rel)) {
+	case ".go", ".ts", ".tsx", ".js", ".jsx", ".mjs", ".py", ".rb", ".java", ".kt", ".php", ".cs", ".rs", ".ex", ".exs", ".dart":
+	default:
+		return false
+	}
+	base := filepath.Base(rel)
+	lower := strings.ToLower(base)
+	switch {
+	case strings.HasSuffix(lower, "_test.go"),
+		strings.Contains(lower, ".spec."), strings.Contains(lower, ".test."),
+		strings.HasPrefix(lower, "test_"), strings.HasSuffix(lower, "_test.py"), lower == "conftest.py",
+		strings.HasSuffix(lower, "_spec.rb"), strings.HasSuffix(lower, "_test.dart"),
+		strings.HasSuffix(base, "Test.java"), strings.HasSuffix(base, "Test.kt"), strings.HasSuffix(base, "Tests.cs"),
+		strings.HasSuffix(base, "Test.php"):
+		return true
+	}
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") {
+		switch strings.ToLower(part) {
+		case "test", "tests", "spec", "specs", "__tests__", "testutil", "testutils", "testing", "factories", "fixtures", "testhelpers":
+			return true
+		}
+	}
+	return false
+}
+
+// fixtureDescription names a quoted definition
+func fixtureDescription(sig fixtureSignal) string {
+	desc := strings.ToUpper(sig.kind[:1]) + sig.kind[1:] + " definition"
+	if sig.library != "" {
+		desc += " (" + sig.library + ")"
+	}
+	return desc
+}
+
+// snippetFrom quotes the lines starting at offset, up to the end of the
+// definition: the first blank line once code is indented back to the start,
+// or maxFixtureSnippetLines
+func snippetFrom(content string, offset int) string {
+	// Start at the beginning of the matched line
+	start := strings.LastIndexByte(content[:offset], '\n') + 1
+	scanner := bufio.NewScanner(strings.NewReader(content[start:]))
+	var lines []string
+	for scanner.Scan() && len(lines) < maxFixtureSnippetLines {
+		line := scanner.Text()
+		if len(lines) > 0 && strings.TrimSpace(line) == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fixtureChecklist turns detected fixtures into test checklist steps
+func fixtureChecklist(fx *Fixtures) []string {
+	if fx == nil {
+		return nil
+	}
+	var steps []string
+	for _, k := range fx.Kinds {
+		switch k.Kind {
+		case "factory", "builder":
+			step := "Build test data with the existing " + k.Kind + "s"
+			if k.Library != "" {
+				step += " (" + k.Library + ")"
+			}
+			steps = append(steps, step+", e.g. "+k.Example+", instead of literal structs")
+		case "golden":
+			steps = append(steps, "Compare output against golden files, as in "+k.Example)
+		case "snapshot":
+			steps = append(steps, "Use snapshots ("+k.Library+") for large outputs, as in "+k.Example)
+		case "testcontainers":
+			steps = append(steps, "Use testcontainers for databases and brokers, as in "+k.Example+"; do not mock them")
+		case "fixture_files":
+			if k.Library != "" {
+				steps = append(steps, "Share setup through "+k.Library+", as in "+k.Example)
+			} else {
+				steps = append(steps, "Keep input data in fixture files next to the tests, as in "+k.Example)
+			}
+		default:
+			continue
+		}
+		if len(steps) >= 3 {
+			break
+		}
+	}
+	var setup, teardown []string
+	for _, c := range fx.Setup {
+		if c.Phase == "setup" && len(setup) < 2 {
+			setup = append(setup, c.Convention)
+		}
+		if c.Phase == "teardown" && len(teardown) < 2 {
+			teardown = append(teardown, c.Convention)
+		}
+	}
+	if len(setup) > 0 {
+		steps = append(steps, "Set up with "+strings.Join(setup, " / ")+" like the existing tests")
+	}
+	if len(teardown) > 0 {
+		steps = append(steps, "Clean up with "+strings.Join(teardown, " / "))
+	}
+	return steps
+}
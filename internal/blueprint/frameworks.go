@@ -0,0 +1,204 @@
+package blueprint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Frameworks is what a project's manifests say it is built and tested with
+type Frameworks struct {
+	Framework     string `json:"framework"`      // nestjs, go-gin, python-fastapi, php-laravel, ruby-rails, android, ... or unknown
+	TestFramework string `json:"test_framework"` // jest, mocha, vitest or unknown
+}
+
+// Names lists the detected frameworks, leaving out unknown ones
+func (f Frameworks) Names() []string {
+	var names []string
+	for _, name := range []string{f.Framework, f.TestFramework} {
+		if name != "" && name != "unknown" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// manifestFiles are the files framework detection reads
+var manifestFiles = map[string]bool{
+	"package.json":     true,
+	"go.mod":           true,
+	"Cargo.toml":       true,
+	"pyproject.toml":   true,
+	"requirements.txt": true,
+	"setup.py":         true,
+	"Pipfile":          true,
+	"pubspec.yaml":     true,
+	"composer.json":    true,
+	"Gemfile":          true,
+	// Gradle: modules are included by settings.gradle, and each module's
+	// build.gradle applies the plugins that decide its kind
+	"settings.gradle":     true,
+	"settings.gradle.kts": true,
+	"build.gradle":        true,
+	"build.gradle.kts":    true,
+}
+
+// IsManifest reports whether a change to path can change the detected
+// frameworks. Only manifests at the project root are read, but a
+// project-relative path is all callers have, so any directory matches.
+func IsManifest(path string) bool {
+	return manifestFiles[filepath.Base(path)]
+}
+
+// frameworkCache holds the detection per project root until a manifest
+// changes; blueprints call it on every generation
+var frameworkCache = struct {
+	sync.Mutex
+	byRoot map[string]Frameworks
+}{byRoot: make(map[string]Frameworks)}
+
+// DetectFrameworks returns the frameworks of the project at projectRoot,
+// reading its manifests only the first time or after InvalidateFrameworks
+func DetectFrameworks(projectRoot string) Frameworks {
+	frameworkCache.Lock()
+	defer frameworkCache.Unlock()
+	if f, ok := frameworkCache.byRoot[projectRoot]; ok {
+		return f
+	}
+	f := Frameworks{
+		Framework:     detectFramework(projectRoot),
+		TestFramework: detectTestFramework(projectRoot),
+	}
+	frameworkCache.byRoot[projectRoot] = f
+	return f
+}
+
+// InvalidateFrameworks drops the cached detection for a project, for the
+// watcher to call when a manifest changes
+func InvalidateFrameworks(projectRoot string) {
+	frameworkCache.Lock()
+	defer frameworkCache.Unlock()
+	delete(frameworkCache.byRoot, projectRoot)
+}
+
+// railsGem matches the rails gem in a Gemfile: gem "rails", "~> 7.1"
+var railsGem = regexp.MustCompile(`(?m)^\s*gem\s+['"]rails['"]`)
+
+func detectFramework(projectRoot string) string {
+	// Laravel and Rails apps carry a package.json for their frontend
+	// assets, so their own manifests are read first
+	composerJSON := filepath.Join(projectRoot, "composer.json")
+	if data, err := os.ReadFile(composerJSON); err == nil {
+		if strings.Contains(string(data), `"laravel/framework"`) {
+			return "php-laravel"
+		}
+	}
+	gemfile := filepath.Join(projectRoot, "Gemfile")
+	if data, err := os.ReadFile(gemfile); err == nil {
+		if railsGem.Match(data) {
+			return "ruby-rails"
+		}
+	}
+
+	// Check for Node.js frameworks (package.json)
+	packageJSON := filepath.Join(projectRoot, "package.json")
+	if data, err := os.ReadFile(packageJSON); err == nil {
+		content := string(data)
+		// NestJS
+		nestIndicators := []string{"@nestjs/core", "@Module", "@Controller"}
+		for _, indicator := range nestIndicators {
+			if strings.Contains(content, indicator) {
+				return "nestjs"
+			}
+		}
+		// Express
+		if strings.Contains(content, "express") {
+			return "express"
+		}
+	}
+
+	// Check for Go frameworks (go.mod)
+	goMod := filepath.Join(projectRoot, "go.mod")
+	if data, err := os.ReadFile(goMod); err == nil {
+		content := string(data)
+		if strings.Contains(content, "github.com/gin-gonic/gin") {
+			return "go-gin"
+		}
+		if strings.Contains(content, "github.com/labstack/echo") {
+			return "go-echo"
+		}
+		// Generic Go if no specific framework
+		return "go"
+	}
+
+	// Check for Python frameworks (requirements.txt, pyproject.toml, setup.py)
+	pythonFiles := []string{"requirements.txt", "pyproject.toml", "setup.py", "Pipfile"}
+	for _, pf := range pythonFiles {
+		pyFile := filepath.Join(projectRoot, pf)
+		if data, err := os.ReadFile(pyFile); err == nil {
+			content := string(data)
+			if strings.Contains(content, "fastapi") {
+				return "python-fastapi"
+			}
+			if strings.Contains(content, "flask") {
+				return "python-flask"
+			}
+			if strings.Contains(content, "django") {
+				return "python-django"
+			}
+		}
+	}
+
+	// Check for Rust frameworks (Cargo.toml)
+	cargoToml := filepath.Join(projectRoot, "Cargo.toml")
+	if data, err := os.ReadFile(cargoToml); err == nil {
+		content := string(data)
+		if strings.Contains(content, "actix-web") {
+			return "rust-actix"
+		}
+		if strings.Contains(content, "axum") {
+			return "rust-axum"
+		}
+		if strings.Contains(content, "rocket") {
+			return "rust-rocket"
+		}
+		// Generic Rust
+		return "rust"
+	}
+
+	// Check for Flutter (pubspec.yaml depends on the flutter SDK)
+	pubspec := filepath.Join(projectRoot, "pubspec.yaml")
+	if data, err := os.ReadFile(pubspec); err == nil {
+		if strings.Contains(string(data), "sdk: flutter") {
+			return "flutter"
+		}
+		return "dart"
+	}
+
+	// Check for Gradle builds (settings.gradle, build.gradle): Android,
+	// Kotlin Multiplatform, Spring Boot, or plain Kotlin/Java modules
+	if modules := GradleModules(projectRoot); modules != nil {
+		return gradleFramework(modules)
+	}
+
+	return "unknown"
+}
+
+func detectTestFramework(projectRoot string) string {
+	packageJSON := filepath.Join(projectRoot, "package.json")
+	if data, err := os.ReadFile(packageJSON); err == nil {
+		content := string(data)
+		if strings.Contains(content, "jest") {
+			return "jest"
+		}
+		if strings.Contains(content, "mocha") {
+			return "mocha"
+		}
+		if strings.Contains(content, "vitest") {
+			return "vitest"
+		}
+	}
+	return "unknown"
+}
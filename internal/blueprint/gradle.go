@@ -0,0 +1,307 @@
+package blueprint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GradleModule is a project of a Gradle build: an Android app or library,
+// a Kotlin Multiplatform module, a Spring Boot service or a plain
+// Kotlin/Java library
+type GradleModule struct {
+	Name       string            `json:"name"` // Gradle path, e.g. :feature:login; ":" for the root project
+	Dir        string            `json:"dir"`  // project-relative, "" for the root project
+	Kind       string            `json:"kind"` // android-app, android-library, kotlin-multiplatform, spring-boot, kotlin-jvm, java or unknown
+	Language   string            `json:"language,omitempty"`
+	SourceSets []GradleSourceSet `json:"source_sets,omitempty"`
+}
+
+// GradleSourceSet is a source directory of a module: src/main/kotlin,
+// src/commonMain/kotlin, src/androidTest/java, ...
+type GradleSourceSet struct {
+	Name string `json:"name"` // main, commonMain, androidMain, test, ...
+	Dir  string `json:"dir"`  // project-relative
+	Test bool   `json:"test,omitempty"`
+}
+
+// Gradle module kinds
+const (
+	GradleAndroidApp     = "android-app"
+	GradleAndroidLibrary = "android-library"
+	GradleMultiplatform  = "kotlin-multiplatform"
+	GradleSpringBoot     = "spring-boot"
+	GradleKotlinJVM      = "kotlin-jvm"
+	GradleJava           = "java"
+)
+
+var (
+	gradleInclude    = regexp.MustCompile(`\binclude\b\s*(\()?`)
+	gradleString     = regexp.MustCompile(`["']([^"']+)["']`)
+	gradleProjectDir = regexp.MustCompile(`project\(\s*["'](:?[^"']+)["']\s*\)\.projectDir\s*=\s*(?:file\(\s*["']([^"']+)["']\s*\)|(?:new\s+)?File\(\s*(?:settingsDir|rootDir|rootProject\.projectDir)\s*,\s*["']([^"']+)["']\s*\))`)
+	gradleComment    = regexp.MustCompile(`(?m)//.*$|/\*[\s\S]*?\*/`)
+
+	// Plugins that decide a module's kind, most specific first: a
+	// multiplatform module with an Android target is multiplatform.
+	// Version catalog aliases (libs.plugins.android.application) and
+	// convention plugins (acme.android.library) match too.
+	gradleKinds = []struct {
+		kind string
+		re   *regexp.Regexp
+	}{
+		{GradleMultiplatform, regexp.MustCompile(`kotlin\(\s*"multiplatform"\s*\)|kotlin[.-]multiplatform|kotlinMultiplatform`)},
+		{GradleAndroidApp, regexp.MustCompile(`com\.android\.application|android[.-]application|androidApplication`)},
+		{GradleAndroidLibrary, regexp.MustCompile(`com\.android\.library|android[.-]library|androidLibrary`)},
+		{GradleSpringBoot, regexp.MustCompile(`org\.springframework\.boot|spring[.-]boot`)},
+		{GradleKotlinJVM, regexp.MustCompile(`kotlin\(\s*"jvm"\s*\)|kotlin[.-]jvm|kotlinJvm|apply\s+plugin:\s*["']kotlin["']`)},
+		{GradleJava, regexp.MustCompile(`(?m)id\s*\(?\s*["'](?:java|java-library|application)["']|apply\s+plugin:\s*["'](?:java|java-library|application)["']|^\s*(?:java|` + "`java-library`" + `|application)\s*$`)},
+	}
+
+	// Main source sets in the order an app's code is looked for
+	gradleMainSets = []string{"commonMain", "main", "androidMain", "jvmMain", "desktopMain", "iosMain", "jsMain"}
+)
+
+// GradleModules reads settings.gradle(.kts) at the project root and returns
+// the modules it includes, with their directories (projectDir overrides
+// honored), kinds and source sets. A single-project build is returned as
+// its root module. nil when the project is not built with Gradle.
+func GradleModules(projectRoot string) []GradleModule {
+	settings := readFirst(projectRoot, "settings.gradle.kts", "settings.gradle")
+	if settings == "" && readFirst(projectRoot, "build.gradle.kts", "build.gradle") == "" {
+		return nil
+	}
+	settings = gradleComment.ReplaceAllString(settings, "")
+
+	dirs := make(map[string]string)
+	for _, m := range gradleProjectDir.FindAllStringSubmatch(settings, -1) {
+		dir := m[2]
+		if dir == "" {
+			dir = m[3]
+		}
+		dirs[gradlePath(m[1])] = filepath.ToSlash(filepath.Clean(dir))
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, loc := range gradleInclude.FindAllStringSubmatchIndex(settings, -1) {
+		rest := settings[loc[1]:]
+		var args string
+		if loc[2] >= 0 {
+			end := strings.Index(rest, ")")
+			if end < 0 {
+				end = len(rest)
+			}
+			args = rest[:end]
+		} else {
+			// Groovy: include ':app', ':lib' continued after trailing commas
+			for {
+				line := rest
+				if i := strings.Index(rest, "\n"); i >= 0 {
+					line, rest = rest[:i], rest[i+1:]
+				} else {
+					rest = ""
+				}
+				args += line
+				if !strings.HasSuffix(strings.TrimSpace(line), ",") || rest == "" {
+					break
+				}
+			}
+		}
+		for _, s := range gradleString.FindAllStringSubmatch(args, -1) {
+			name := gradlePath(s[1])
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	var modules []GradleModule
+	if len(names) == 0 || readFirst(projectRoot, "build.gradle.kts", "build.gradle") != "" {
+		if root := readGradleModule(projectRoot, ":", ""); len(names) == 0 || len(root.SourceSets) > 0 {
+			modules = append(modules, root)
+		}
+	}
+	for _, name := range names {
+		dir, ok := dirs[name]
+		if !ok {
+			dir = strings.ReplaceAll(strings.TrimPrefix(name, ":"), ":", "/")
+		}
+		modules = append(modules, readGradleModule(projectRoot, name, dir))
+	}
+	return modules
+}
+
+// gradlePath normalizes a module name to its Gradle path: app -> :app
+func gradlePath(name string) string {
+	return ":" + strings.TrimPrefix(strings.TrimSpace(name), ":")
+}
+
+// readGradleModule reads a module's build file for its kind and lists its
+// source sets: every src/<set>/kotlin or src/<set>/java directory
+func readGradleModule(projectRoot, name, dir string) GradleModule {
+	m := GradleModule{Name: name, Dir: dir, Kind: "unknown"}
+	abs := filepath.Join(projectRoot, filepath.FromSlash(dir))
+	build := gradleComment.ReplaceAllString(readFirst(abs, "build.gradle.kts", "build.gradle"), "")
+	for _, k := range gradleKinds {
+		if k.re.MatchString(build) {
+			m.Kind = k.kind
+			break
+		}
+	}
+
+	sets, _ := os.ReadDir(filepath.Join(abs, "src"))
+	kotlin, java := false, false
+	for _, set := range sets {
+		if !set.IsDir() {
+			continue
+		}
+		for _, lang := range []string{"kotlin", "java"} {
+			if info, err := os.Stat(filepath.Join(abs, "src", set.Name(), lang)); err == nil && info.IsDir() {
+				m.SourceSets = append(m.SourceSets, GradleSourceSet{
+					Name: set.Name(),
+					Dir:  strings.TrimPrefix(dir+"/src/"+set.Name()+"/"+lang, "/"),
+					Test: strings.Contains(strings.ToLower(set.Name()), "test"),
+				})
+				if lang == "kotlin" {
+					kotlin = true
+				} else {
+					java = true
+				}
+			}
+		}
+	}
+	switch {
+	case kotlin:
+		m.Language = "kotlin"
+	case java:
+		m.Language = "java"
+	case m.Kind == GradleMultiplatform || m.Kind == GradleKotlinJVM:
+		m.Language = "kotlin"
+	case m.Kind == GradleJava:
+		m.Language = "java"
+	}
+	return m
+}
+
+// MainSourceDir is the project-relative directory an app's code lives in:
+// commonMain for multiplatform modules, else main, else the first
+// non-test source set; Kotlin before Java. "" when there is none.
+func (m GradleModule) MainSourceDir() string {
+	for _, name := range gradleMainSets {
+		for _, lang := range []string{"/kotlin", "/java"} {
+			for _, set := range m.SourceSets {
+				if set.Name == name && strings.HasSuffix(set.Dir, lang) {
+					return set.Dir
+				}
+			}
+		}
+	}
+	for _, set := range m.SourceSets {
+		if !set.Test {
+			return set.Dir
+		}
+	}
+	return ""
+}
+
+// IsApp reports whether the module builds something that runs, rather
+// than a library
+func (m GradleModule) IsApp() bool {
+	return m.Kind == GradleAndroidApp || m.Kind == GradleSpringBoot
+}
+
+// FindGradleModule finds the module an app name refers to: its Gradle path
+// (:feature:login or feature:login), its directory (feature/login), or its
+// last segment (login) when only one module ends with it
+func FindGradleModule(modules []GradleModule, app string) (GradleModule, bool) {
+	if app == "" {
+		return GradleModule{}, false
+	}
+	want := gradlePath(strings.ReplaceAll(strings.Trim(filepath.ToSlash(app), "/"), "/", ":"))
+	var byLast []GradleModule
+	for _, m := range modules {
+		if m.Name == ":" {
+			continue
+		}
+		if m.Name == want || m.Dir == strings.Trim(filepath.ToSlash(app), "/") {
+			return m, true
+		}
+		if strings.HasSuffix(m.Name, ":"+strings.TrimPrefix(want, ":")) {
+			byLast = append(byLast, m)
+		}
+	}
+	if len(byLast) == 1 {
+		return byLast[0], true
+	}
+	return GradleModule{}, false
+}
+
+// gradleFramework names the framework of a Gradle build from its modules:
+// Android and multiplatform win over Spring Boot, which wins over plain
+// Kotlin or Java
+func gradleFramework(modules []GradleModule) string {
+	kinds := make(map[string]bool)
+	for _, m := range modules {
+		kinds[m.Kind] = true
+	}
+	switch {
+	case kinds[GradleMultiplatform]:
+		return "kotlin-multiplatform"
+	case kinds[GradleAndroidApp] || kinds[GradleAndroidLibrary]:
+		return "android"
+	case kinds[GradleSpringBoot]:
+		return "spring-boot"
+	}
+	for _, m := range modules {
+		if m.Language == "kotlin" {
+			return "kotlin"
+		}
+	}
+	return "java"
+}
+
+// isGradleFramework reports whether a detected framework comes from a
+// Gradle build
+func isGradleFramework(framework string) bool {
+	switch framework {
+	case "android", "kotlin-multiplatform", "spring-boot", "kotlin", "java":
+		return true
+	}
+	return false
+}
+
+// gradleAppModule picks the module an app-less blueprint targets: the only
+// runnable module, else the root project's sources, else the first module
+// with sources
+func gradleAppModule(modules []GradleModule) (GradleModule, bool) {
+	var apps []GradleModule
+	for _, m := range modules {
+		if m.IsApp() && m.MainSourceDir() != "" {
+			apps = append(apps, m)
+		}
+	}
+	if len(apps) == 1 {
+		return apps[0], true
+	}
+	sorted := append([]GradleModule(nil), modules...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name == ":" && sorted[j].Name != ":" })
+	for _, m := range sorted {
+		if m.MainSourceDir() != "" {
+			return m, true
+		}
+	}
+	return GradleModule{}, false
+}
+
+// readFirst returns the content of the first of names that exists in dir
+func readFirst(dir string, names ...string) string {
+	for _, name := range names {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return string(data)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package blueprint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeProjectFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGradleMultiModuleProject(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	writeProjectFiles(t, projectDir, map[string]string{
+		"settings.gradle.kts": `pluginManagement { repositories { google() } }
+rootProject.name = "shop"
+include(":app")
+include(
+    ":core:data",
+    ":feature:login", // sign-in flow
+)
+// include(":legacy")
+include(":shared")
+project(":shared").projectDir = file("kmp/shared")
+`,
+		"build.gradle.kts":                                               "plugins { alias(libs.plugins.android.application) apply false }\n",
+		"app/build.gradle.kts":                                           "plugins {\n    alias(libs.plugins.android.application)\n    alias(libs.plugins.kotlin.android)\n}\n",
+		"core/data/build.gradle.kts":                                     "plugins { id(\"com.android.library\") }\n",
+		"feature/login/build.gradle.kts":                                 "plugins { id(\"shop.android.library\") }\n",
+		"kmp/shared/build.gradle.kts":                                    "plugins { kotlin(\"multiplatform\") }\n",
+		"app/src/main/AndroidManifest.xml":                               "<manifest/>\n",
+		"app/src/main/kotlin/com/shop/app/MainActivity.kt":               "class MainActivity\n",
+		"app/src/main/kotlin/com/shop/app/cart/CartViewModel.kt":         "class CartViewModel : ViewModel()\n",
+		"app/src/main/kotlin/com/shop/app/cart/CartRepository.kt":        "class CartRepository\n",
+		"app/src/main/kotlin/com/shop/app/cart/CartScreen.kt":            "@Composable fun CartScreen() {}\n",
+		"app/src/test/kotlin/com/shop/app/cart/CartViewModelTest.kt":     "class CartViewModelTest\n",
+		"feature/login/src/main/kotlin/com/shop/login/LoginViewModel.kt": "class LoginViewModel\n",
+		"kmp/shared/src/commonMain/kotlin/com/shop/shared/Greeting.kt":   "class Greeting\n",
+		"kmp/shared/src/androidMain/kotlin/com/shop/shared/Platform.kt":  "actual fun platform() = \"Android\"\n",
+	})
+	InvalidateFrameworks(projectDir)
+
+	modules := GradleModules(projectDir)
+	var got []string
+	for _, m := range modules {
+		got = append(got, m.Name+"="+m.Dir+"("+m.Kind+")")
+	}
+	// The root project has no sources of its own; the commented include
+	// is ignored
+	want := []string{
+		":app=app(android-app)",
+		":core:data=core/data(android-library)",
+		":feature:login=feature/login(android-library)",
+		":shared=kmp/shared(kotlin-multiplatform)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("modules = %v, want %v", got, want)
+	}
+	if dir := modules[3].MainSourceDir(); dir != "kmp/shared/src/commonMain/kotlin" {
+		t.Errorf("shared main source dir = %q", dir)
+	}
+
+	for app, want := range map[string]string{"login": ":feature:login", "feature/login": ":feature:login", ":core:data": ":core:data", "core:data": ":core:data"} {
+		if m, ok := FindGradleModule(modules, app); !ok || m.Name != want {
+			t.Errorf("FindGradleModule(%q) = %q, %v; want %s", app, m.Name, ok, want)
+		}
+	}
+	if _, ok := FindGradleModule(modules, "checkout"); ok {
+		t.Error("unknown module found")
+	}
+
+	if f := DetectFrameworks(projectDir).Framework; f != "kotlin-multiplatform" {
+		t.Errorf("framework = %q, want kotlin-multiplatform", f)
+	}
+
+	generator := NewGenerator(projectDir, tcDir, store)
+	// Without an app, the Android application module is the target
+	if dir := generator.appSourcePath(""); dir != filepath.Join(projectDir, "app", "src", "main", "kotlin") {
+		t.Errorf("appSourcePath(\"\") = %q", dir)
+	}
+	if dir := generator.appSourcePath("shared"); dir != filepath.Join(projectDir, "kmp", "shared", "src", "commonMain", "kotlin") {
+		t.Errorf("appSourcePath(shared) = %q", dir)
+	}
+
+	bp, err := generator.Generate(TaskAddEndpoint, "app", "")
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if bp.FilePattern == nil || bp.FilePattern.BasePath != "app/src/main/kotlin/com/shop/app/{name}/" {
+		t.Fatalf("file pattern = %+v", bp.FilePattern)
+	}
+	if !reflect.DeepEqual(bp.FilePattern.RegisterIn, []string{"app/src/main/AndroidManifest.xml"}) {
+		t.Errorf("register in = %v", bp.FilePattern.RegisterIn)
+	}
+	if len(bp.Examples) == 0 || filepath.ToSlash(bp.Examples[0].Path) != "app/src/main/kotlin/com/shop/app/cart" || !strings.Contains(bp.Examples[0].Description, "complete") {
+		t.Errorf("examples = %+v", bp.Examples)
+	}
+	if !strings.Contains(strings.Join(bp.Checklist, "\n"), "commonMain") {
+		t.Errorf("checklist = %v", bp.Checklist)
+	}
+
+	tests := generator.findTestExamplesForApp("app", "kotlin-multiplatform")
+	if len(tests) != 1 || filepath.ToSlash(tests[0].Path) != "app/src/test/kotlin/com/shop/app/cart/CartViewModelTest.kt" {
+		t.Errorf("test examples = %+v", tests)
+	}
+}
+
+func TestGradleGroovySettings(t *testing.T) {
+	projectDir := t.TempDir()
+	writeProjectFiles(t, projectDir, map[string]string{
+		"settings.gradle":              "rootProject.name = 'orders'\ninclude 'api',\n        ':worker'\nproject(':worker').projectDir = new File(settingsDir, 'services/worker')\n",
+		"api/build.gradle":             "plugins {\n    id 'org.springframework.boot' version '3.2.0'\n    id 'java'\n}\n",
+		"services/worker/build.gradle": "apply plugin: 'java'\n",
+		"api/src/main/java/com/acme/orders/OrderController.java": "class OrderController {}\n",
+		"services/worker/src/main/java/com/acme/worker/Job.java": "class Job {}\n",
+	})
+
+	modules := GradleModules(projectDir)
+	if len(modules) != 2 || modules[0].Name != ":api" || modules[0].Kind != GradleSpringBoot || modules[0].Language != "java" ||
+		modules[1].Dir != "services/worker" || modules[1].Kind != GradleJava {
+		t.Fatalf("modules = %+v", modules)
+	}
+	if f := detectFramework(projectDir); f != "spring-boot" {
+		t.Errorf("framework = %q, want spring-boot", f)
+	}
+}
@@ -0,0 +1,154 @@
+package blueprint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Layered layouts
+// Laravel and Rails keep each layer in its own directory (controllers,
+// models, requests, tests) rather than a feature's files side by side. A
+// feature is the files named after it across those directories.
+// ---------------------------------------------------------------------------
+
+// layerFile is where one file of a feature lives in a layered layout:
+// a project-relative path with name placeholders, and its snippet key
+type layerFile struct {
+	path string
+	key  string
+}
+
+// featureLayers lists the files of one feature per layered framework. The
+// controller, which examples are found by, comes first; a framework may
+// list several test locations, of which the project's own are used.
+var featureLayers = map[string][]layerFile{
+	"php-laravel": {
+		{"app/Http/Controllers/{Name}Controller.php", "controller"},
+		{"app/Models/{Name}.php", "model"},
+		{"app/Http/Requests/Store{Name}Request.php", "request"},
+		{"app/Http/Resources/{Name}Resource.php", "resource"},
+		{"tests/Feature/{Name}Test.php", "test"},
+	},
+	"ruby-rails": {
+		{"app/controllers/{names_snake}_controller.rb", "controller"},
+		{"app/models/{name_snake}.rb", "model"},
+		{"app/serializers/{name_snake}_serializer.rb", "serializer"},
+		{"spec/requests/{names_snake}_spec.rb", "test"},
+		{"test/controllers/{names_snake}_controller_test.rb", "test"},
+	},
+}
+
+// layeredFiles returns the feature files of a layered framework, keeping
+// the test locations whose top directory exists (the first one when none
+// does), or nil when the framework groups files by feature
+func (g *Generator) layeredFiles(framework string) []layerFile {
+	layers := featureLayers[framework]
+	if layers == nil {
+		return nil
+	}
+	var files, tests []layerFile
+	for _, f := range layers {
+		if f.key != "test" {
+			files = append(files, f)
+			continue
+		}
+		top, _, _ := strings.Cut(f.path, "/")
+		if _, err := os.Stat(filepath.Join(g.projectRoot, top)); err == nil {
+			tests = append(tests, f)
+		}
+	}
+	if len(tests) == 0 {
+		for _, f := range layers {
+			if f.key == "test" {
+				tests = append(tests, f)
+				break
+			}
+		}
+	}
+	return append(files, tests...)
+}
+
+// layerPaths lists the paths of a feature's files, with placeholders
+func layerPaths(layers []layerFile) []string {
+	paths := make([]string, len(layers))
+	for i, f := range layers {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// layeredFeatureName is the feature a controller of a layered layout is
+// named after: "OrderController.php" is "Order", "orders_controller.rb"
+// is "orders"
+func layeredFeatureName(file string, layers []layerFile) string {
+	base := filepath.Base(file)
+	pattern := filepath.Base(layers[0].path)
+	if i := strings.Index(pattern, "}"); i >= 0 {
+		base = strings.TrimSuffix(base, pattern[i+1:])
+	}
+	return base
+}
+
+// featurePath fills the name placeholders of a path with the spellings of
+// a feature name: "app/models/{name_snake}.rb" is "app/models/order.rb"
+// for "orders"
+func featurePath(path, featureName string) string {
+	words := singularWords(nameWords(featureName))
+	if len(words) == 0 {
+		return path
+	}
+	plural := append(append([]string{}, words[:len(words)-1]...), pluralWord(words[len(words)-1]))
+	for _, p := range namePlaceholders {
+		path = strings.ReplaceAll(path, p.plural, p.spell(plural))
+		path = strings.ReplaceAll(path, p.singular, p.spell(words))
+	}
+	return path
+}
+
+// layeredCompleteness counts the files of the feature a controller serves
+// that exist besides it, and whether one of them is a test
+func (g *Generator) layeredCompleteness(controller string, layers []layerFile) (found, total int, tested bool) {
+	featureName := layeredFeatureName(controller, layers)
+	for _, f := range layers[1:] {
+		if f.key != "test" {
+			total++
+		}
+		if _, err := os.Stat(filepath.Join(g.projectRoot, featurePath(f.path, featureName))); err != nil {
+			continue
+		}
+		if f.key == "test" {
+			tested = true
+		} else {
+			found++
+		}
+	}
+	return found, total, tested
+}
+
+// extractLayeredSnippets condenses the files of the feature a controller
+// (project-relative, possibly in a namespace directory such as
+// Controllers/Api) serves, one snippet per layer that exists
+func (g *Generator) extractLayeredSnippets(controller string, layers []layerFile) map[string]*SnippetEntry {
+	featureName := layeredFeatureName(controller, layers)
+	snippets := make(map[string]*SnippetEntry)
+	for i, f := range layers {
+		if snippets[f.key] != nil {
+			continue
+		}
+		relPath := featurePath(f.path, featureName)
+		if i == 0 {
+			relPath = filepath.ToSlash(controller)
+		}
+		snippet := g.extractSingleSnippet(filepath.Join(g.projectRoot, relPath), featureName, g.snippetDescription(f.key))
+		if snippet != nil {
+			snippet.SourceFile = relPath
+			snippets[f.key] = snippet
+		}
+	}
+	if len(snippets) == 0 {
+		return nil
+	}
+	return snippets
+}
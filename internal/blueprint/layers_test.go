@@ -0,0 +1,216 @@
+package blueprint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const laravelOrderController = `<?php
+
+namespace App\Http\Controllers;
+
+use App\Http\Requests\StoreOrderRequest;
+use App\Http\Resources\OrderResource;
+use App\Models\Order;
+
+class OrderController extends Controller
+{
+    public function __construct(
+        private readonly OrderService $orders,
+    ) {}
+
+    /**
+     * Store a new order.
+     */
+    public function store(StoreOrderRequest $request): OrderResource
+    {
+        $order = Order::create($request->validated());
+
+        return new OrderResource($order);
+    }
+
+    public function show(Order $order): OrderResource
+    {
+        return new OrderResource($order);
+    }
+}
+`
+
+func TestLaravelEndpointBlueprint(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	writeProjectFiles(t, projectDir, map[string]string{
+		"composer.json":                       `{"require": {"php": "^8.2", "laravel/framework": "^11.0"}}`,
+		"package.json":                        `{"devDependencies": {"laravel-vite-plugin": "^1.0", "vite": "^5.0"}}`,
+		"artisan":                             "#!/usr/bin/env php\n",
+		"routes/web.php":                      "<?php\n",
+		"app/Http/Controllers/Controller.php": "<?php\n\nabstract class Controller\n{\n}\n",
+		"app/Http/Controllers/OrderController.php":       laravelOrderController,
+		"app/Models/Order.php":                           "<?php\n\nclass Order extends Model\n{\n    public function lines(): HasMany\n    {\n        return $this->hasMany(OrderLine::class);\n    }\n}\n",
+		"app/Http/Requests/StoreOrderRequest.php":        "<?php\n\nclass StoreOrderRequest extends FormRequest\n{\n    public function rules(): array\n    {\n        return [];\n    }\n}\n",
+		"app/Http/Resources/OrderResource.php":           "<?php\n\nclass OrderResource extends JsonResource\n{\n}\n",
+		"tests/Feature/OrderTest.php":                    "<?php\n\nclass OrderTest extends TestCase\n{\n}\n",
+		"app/Http/Controllers/Api/InvoiceController.php": "<?php\n\nclass InvoiceController extends Controller\n{\n}\n",
+	})
+	// The complete order example is older than the bare invoice one
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(projectDir, "app", "Http", "Controllers", "OrderController.php"), old, old)
+	InvalidateFrameworks(projectDir)
+
+	if f := DetectFrameworks(projectDir).Framework; f != "php-laravel" {
+		t.Fatalf("framework = %q, want php-laravel", f)
+	}
+
+	bp, err := NewGenerator(projectDir, tcDir, store).Generate(TaskAddEndpoint, "", "invoices")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(bp.FilePattern.RegisterIn) != 1 || bp.FilePattern.RegisterIn[0] != "routes/web.php" {
+		t.Errorf("register in = %v, want routes/web.php without routes/api.php", bp.FilePattern.RegisterIn)
+	}
+	if !containsAll(bp.FilePattern.Files, "app/Http/Controllers/{Name}Controller.php", "app/Models/{Name}.php", "tests/Feature/{Name}Test.php") {
+		t.Errorf("files = %v", bp.FilePattern.Files)
+	}
+	if len(bp.Examples) != 2 || bp.Examples[0].Path != "app/Http/Controllers/OrderController.php" ||
+		!strings.Contains(bp.Examples[0].Description, "complete, tested") {
+		t.Fatalf("examples = %+v, want the complete order controller first", bp.Examples)
+	}
+
+	controller := bp.Snippets["controller"]
+	if controller == nil {
+		t.Fatalf("snippets = %+v, want a controller", bp.Snippets)
+	}
+	for _, want := range []string{"class {Name}Controller extends Controller {", "private readonly {Name}Service ${names},", "public function store(Store{Name}Request $request): {Name}Resource { }", "public function show({Name} ${name}): {Name}Resource { }"} {
+		if !strings.Contains(controller.Code, want) {
+			t.Errorf("controller snippet missing %q:\n%s", want, controller.Code)
+		}
+	}
+	for _, unwanted := range []string{"namespace", "use App", "validated()", "Store a new"} {
+		if strings.Contains(controller.Code, unwanted) {
+			t.Errorf("controller snippet should not contain %q", unwanted)
+		}
+	}
+	for key, file := range map[string]string{"model": "app/Models/Order.php", "request": "app/Http/Requests/StoreOrderRequest.php", "test": "tests/Feature/OrderTest.php"} {
+		if s := bp.Snippets[key]; s == nil || s.SourceFile != file {
+			t.Errorf("%s snippet = %+v, want one from %s", key, s, file)
+		}
+	}
+	if !strings.Contains(strings.Join(bp.Checklist, "\n"), "Route::apiResource") {
+		t.Errorf("checklist = %v", bp.Checklist)
+	}
+
+	cmds := DetectTestCommands(projectDir, "")
+	if len(cmds) != 1 || cmds[0].Command != "php artisan test" {
+		t.Errorf("DetectTestCommands = %+v", cmds)
+	}
+}
+
+const railsOrdersController = `class OrdersController < ApplicationController
+  before_action :authenticate_user!
+  before_action :set_order, only: %i[show update]
+
+  # GET /orders/1
+  def show
+    render json: OrderSerializer.new(@order)
+  end
+
+  def create
+    order = Order.new(order_params)
+    if order.save
+      render json: order, status: :created
+    else
+      render json: order.errors, status: :unprocessable_entity
+    end
+  end
+
+  private
+
+  def order_params
+    params.require(:order).permit(:total, :customer_id)
+  end
+end
+`
+
+func TestRailsEndpointBlueprint(t *testing.T) {
+	projectDir, tcDir, store, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	writeProjectFiles(t, projectDir, map[string]string{
+		"Gemfile":          "source \"https://rubygems.org\"\n\ngem \"rails\", \"~> 7.1\"\ngem \"pg\"\n",
+		".rspec":           "--require spec_helper\n",
+		"config/routes.rb": "Rails.application.routes.draw do\nend\n",
+		"app/controllers/application_controller.rb":   "class ApplicationController < ActionController::API\nend\n",
+		"app/controllers/orders_controller.rb":        railsOrdersController,
+		"app/models/order.rb":                         "class Order < ApplicationRecord\n  belongs_to :customer\n  validates :total, numericality: { greater_than: 0 }\n\n  def paid?\n    payments.any?\n  end\nend\n",
+		"app/serializers/order_serializer.rb":         "class OrderSerializer\n  attributes :id, :total\nend\n",
+		"spec/requests/orders_spec.rb":                "require \"rails_helper\"\n",
+		"app/controllers/admin/reports_controller.rb": "module Admin\n  class ReportsController < ApplicationController\n  end\nend\n",
+	})
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(projectDir, "app", "controllers", "orders_controller.rb"), old, old)
+	InvalidateFrameworks(projectDir)
+
+	if f := DetectFrameworks(projectDir).Framework; f != "ruby-rails" {
+		t.Fatalf("framework = %q, want ruby-rails", f)
+	}
+
+	bp, err := NewGenerator(projectDir, tcDir, store).Generate(TaskAddEndpoint, "", "invoices")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(bp.FilePattern.RegisterIn) != 1 || bp.FilePattern.RegisterIn[0] != "config/routes.rb" {
+		t.Errorf("register in = %v", bp.FilePattern.RegisterIn)
+	}
+	files := strings.Join(bp.FilePattern.Files, " ")
+	if !strings.Contains(files, "spec/requests/{names_snake}_spec.rb") || strings.Contains(files, "test/controllers") {
+		t.Errorf("files = %v, want the RSpec location only", bp.FilePattern.Files)
+	}
+	if len(bp.Examples) != 2 || bp.Examples[0].Path != "app/controllers/orders_controller.rb" ||
+		!strings.HasPrefix(bp.Examples[0].Description, "orders endpoint") {
+		t.Fatalf("examples = %+v, want the orders controller first", bp.Examples)
+	}
+
+	controller := bp.Snippets["controller"]
+	if controller == nil {
+		t.Fatalf("snippets = %+v, want a controller", bp.Snippets)
+	}
+	want := "class {Names}Controller < ApplicationController\n" +
+		"  before_action :authenticate_user!\n" +
+		"  before_action :set_{name}, only: %i[show update]\n" +
+		"  def show\n    ...\n  end\n" +
+		"  def create\n    ...\n  end\n" +
+		"  private\n" +
+		"  def {name}_params\n    ...\n  end\n" +
+		"end"
+	if controller.Code != want {
+		t.Errorf("controller snippet =\n%s\nwant\n%s", controller.Code, want)
+	}
+	if s := bp.Snippets["model"]; s == nil || !strings.Contains(s.Code, "  validates :total") || !strings.Contains(s.Code, "  def paid?\n    ...\n  end") {
+		t.Errorf("model snippet = %+v", s)
+	}
+	if s := bp.Snippets["serializer"]; s == nil || s.SourceFile != "app/serializers/order_serializer.rb" {
+		t.Errorf("serializer snippet = %+v", s)
+	}
+
+	cmds := DetectTestCommands(projectDir, "")
+	if len(cmds) != 1 || cmds[0].Command != "bundle exec rspec" {
+		t.Errorf("DetectTestCommands = %+v", cmds)
+	}
+}
+
+func TestFeaturePath(t *testing.T) {
+	for _, tc := range []struct{ path, feature, want string }{
+		{"app/controllers/{names_snake}_controller.rb", "line_items", "app/controllers/line_items_controller.rb"},
+		{"app/models/{name_snake}.rb", "line_items", "app/models/line_item.rb"},
+		{"app/Http/Requests/Store{Name}Request.php", "Category", "app/Http/Requests/StoreCategoryRequest.php"},
+		{"tests/Feature/{Name}Test.php", "CategoriesController", "tests/Feature/CategoriesControllerTest.php"},
+	} {
+		if got := featurePath(tc.path, tc.feature); got != tc.want {
+			t.Errorf("featurePath(%q, %q) = %q, want %q", tc.path, tc.feature, got, tc.want)
+		}
+	}
+}
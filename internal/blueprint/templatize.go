@@ -0,0 +1,247 @@
+package blueprint
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ---------------------------------------------------------------------------
+// Name templates
+// Example files spell the feature name in many ways: users.service.ts,
+// UsersService, createUserDto, USERS_QUEUE, users_repository.go. A name
+// template maps every spelling, singular and plural, to one placeholder so
+// snippets are fully parameterized whichever variant the example used.
+// ---------------------------------------------------------------------------
+
+// namePlaceholders lists the placeholders for each spelling of a name, in
+// the order they are tried when two spellings are the same string (single
+// word names are identical in kebab, snake and camel case).
+var namePlaceholders = []struct {
+	singular, plural string
+	spell            func(words []string) string
+}{
+	{"{name}", "{names}", func(w []string) string { return strings.Join(w, "-") }},
+	{"{name_snake}", "{names_snake}", func(w []string) string { return strings.Join(w, "_") }},
+	{"{nameCamel}", "{namesCamel}", func(w []string) string {
+		pascal := toPascalCase(strings.Join(w, "-"))
+		return strings.ToLower(pascal[:len(w[0])]) + pascal[len(w[0]):]
+	}},
+	{"{Name}", "{Names}", func(w []string) string { return toPascalCase(strings.Join(w, "-")) }},
+	{"{NAME_CONST}", "{NAMES_CONST}", func(w []string) string { return strings.ToUpper(strings.Join(w, "_")) }},
+}
+
+// nameReplacement is one spelling of a name and its placeholder
+type nameReplacement struct {
+	literal     string
+	placeholder string
+}
+
+// nameTemplate replaces every spelling of a feature name, and of its
+// glossary aliases, with placeholders
+type nameTemplate struct {
+	replacements []nameReplacement // longest literal first
+	placeholders map[string]string // placeholder -> the example's spelling
+}
+
+// nameTemplate returns the template for a feature name, reading glossary
+// aliases from config the first time
+func (g *Generator) nameTemplate(featureName string) *nameTemplate {
+	if t, ok := g.templates[featureName]; ok {
+		return t
+	}
+	var glossary map[string][]string
+	if g.jsonStore != nil {
+		if cfg, err := g.jsonStore.GetConfig(); err == nil {
+			glossary = cfg.Glossary
+		}
+	}
+	t := newNameTemplate(featureName, glossary)
+	if g.templates == nil {
+		g.templates = make(map[string]*nameTemplate)
+	}
+	g.templates[featureName] = t
+	return t
+}
+
+// newNameTemplate builds the spellings of a feature name and of every
+// glossary term it is an alias of (or that is an alias of it)
+func newNameTemplate(featureName string, glossary map[string][]string) *nameTemplate {
+	t := &nameTemplate{placeholders: make(map[string]string)}
+	words := singularWords(nameWords(featureName))
+	if len(words) == 0 {
+		return t
+	}
+
+	seen := make(map[string]bool)
+	add := func(words []string, primary bool) {
+		plural := append(append([]string{}, words[:len(words)-1]...), pluralWord(words[len(words)-1]))
+		for _, p := range namePlaceholders {
+			for _, form := range []struct {
+				words       []string
+				placeholder string
+			}{{words, p.singular}, {plural, p.plural}} {
+				literal := p.spell(form.words)
+				if seen[literal] {
+					continue
+				}
+				seen[literal] = true
+				t.replacements = append(t.replacements, nameReplacement{literal: literal, placeholder: form.placeholder})
+				if primary {
+					t.placeholders[form.placeholder] = literal
+				}
+			}
+		}
+	}
+	add(words, true)
+	for _, alias := range glossaryAliases(words, glossary) {
+		add(alias, false)
+	}
+
+	sort.SliceStable(t.replacements, func(i, j int) bool {
+		return len(t.replacements[i].literal) > len(t.replacements[j].literal)
+	})
+	return t
+}
+
+// glossaryAliases returns the other terms of every glossary entry that
+// contains the name, as singular words
+func glossaryAliases(words []string, glossary map[string][]string) [][]string {
+	key := strings.Join(words, "-")
+	var aliases [][]string
+	for term, termAliases := range glossary {
+		group := append([]string{term}, termAliases...)
+		matches := false
+		for _, entry := range group {
+			if strings.Join(singularWords(nameWords(entry)), "-") == key {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		for _, entry := range group {
+			if w := singularWords(nameWords(entry)); len(w) > 0 && strings.Join(w, "-") != key {
+				aliases = append(aliases, w)
+			}
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return strings.Join(aliases[i], "-") < strings.Join(aliases[j], "-")
+	})
+	return aliases
+}
+
+// apply replaces each spelling with its placeholder. Matches must sit on
+// identifier word boundaries, so "rma" is replaced in "rmaService" and
+// "RMA_QUEUE" but not inside "format".
+func (t *nameTemplate) apply(text string) string {
+	if len(t.replacements) == 0 {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		matched := false
+		for _, r := range t.replacements {
+			if strings.HasPrefix(text[i:], r.literal) && onWordBoundary(text, i, i+len(r.literal)) {
+				b.WriteString(r.placeholder)
+				i += len(r.literal)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(text[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// onWordBoundary reports whether text[start:end] is a whole identifier
+// word: not continued by letters of the same case on either side
+func onWordBoundary(text string, start, end int) bool {
+	first, last := rune(text[start]), rune(text[end-1])
+	allUpper := strings.ToUpper(text[start:end]) == text[start:end]
+	if start > 0 {
+		prev := rune(text[start-1])
+		switch {
+		case allUpper && unicode.IsLetter(prev):
+			return false
+		case unicode.IsLower(first) && unicode.IsLetter(prev):
+			return false
+		case unicode.IsUpper(first) && unicode.IsUpper(prev):
+			return false
+		}
+	}
+	if end < len(text) {
+		next := rune(text[end])
+		switch {
+		case allUpper && unicode.IsLetter(next):
+			return false
+		case unicode.IsLower(last) && unicode.IsLower(next):
+			return false
+		}
+	}
+	return true
+}
+
+// nameWords splits a name into lowercase words on separators and
+// camelCase humps: "alarm-events", "alarmEvents" -> [alarm events]
+func nameWords(name string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' ' || r == '/'
+	}) {
+		start := 0
+		runes := []rune(part)
+		for i := 1; i < len(runes); i++ {
+			if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// singularWords makes the last word of a name singular
+func singularWords(words []string) []string {
+	if len(words) == 0 {
+		return words
+	}
+	result := append([]string{}, words...)
+	result[len(result)-1] = singularWord(result[len(result)-1])
+	return result
+}
+
+// singularWord is a small English singularizer for identifiers:
+// "categories" -> "category", "addresses" -> "address", "users" -> "user"
+func singularWord(w string) string {
+	switch {
+	case len(w) > 4 && strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"),
+		strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "uses") && !strings.HasSuffix(w, "ouses") && !strings.HasSuffix(w, "auses"):
+		return w[:len(w)-2]
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") &&
+		!strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// pluralWord is the inverse of singularWord
+func pluralWord(w string) string {
+	switch {
+	case len(w) > 1 && strings.HasSuffix(w, "y") && !strings.ContainsRune("aeiou", rune(w[len(w)-2])):
+		return w[:len(w)-1] + "ies"
+	case strings.HasSuffix(w, "s"), strings.HasSuffix(w, "x"), strings.HasSuffix(w, "z"),
+		strings.HasSuffix(w, "ch"), strings.HasSuffix(w, "sh"):
+		return w + "es"
+	}
+	return w + "s"
+}
@@ -0,0 +1,275 @@
+package blueprint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TestCommand is a concrete way to run the project's tests, read from its
+// build files rather than guessed from the language
+type TestCommand struct {
+	Command string `json:"command"`          // e.g. "pnpm test"
+	Dir     string `json:"dir,omitempty"`    // project-relative directory to run it in, empty for the root
+	Source  string `json:"source"`           // where it was detected, e.g. "package.json scripts.test"
+	Single  string `json:"single,omitempty"` // runs one test file or package; {file} / {dir} are placeholders
+}
+
+// Shell returns the command as one line, changing into Dir first
+func (c TestCommand) Shell() string {
+	if c.Dir == "" {
+		return c.Command
+	}
+	return "cd " + c.Dir + " && " + c.Command
+}
+
+// maxMakeTargets caps the make targets reported per Makefile
+const maxMakeTargets = 3
+
+var makeTestTarget = regexp.MustCompile(`(?m)^(test[\w.-]*)\s*:([^=]|$)`)
+
+// testEcosystems orders detected commands: project scripts and make
+// targets say how the team runs tests, so they come before language defaults
+var testEcosystems = []string{"node", "make", "python", "go", "rust", "jvm", "dart", "elixir", "ruby", "php"}
+
+// noTestScript is the placeholder npm init writes into scripts.test
+const noTestScript = "no test specified"
+
+// DetectTestCommands finds how tests are run for code in dir, looking in
+// dir and each parent up to projectRoot. The closest build file wins per
+// ecosystem, so an app's package.json takes precedence over the root one,
+// and commands from closer directories come first.
+func DetectTestCommands(projectRoot, dir string) []TestCommand {
+	if dir == "" {
+		dir = projectRoot
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectRoot, dir)
+	}
+	if rel, err := filepath.Rel(projectRoot, dir); err != nil || strings.HasPrefix(rel, "..") {
+		dir = projectRoot
+	}
+
+	var commands []TestCommand
+	seen := make(map[string]bool)
+	for d := dir; ; d = filepath.Dir(d) {
+		rel, _ := filepath.Rel(projectRoot, d)
+		if rel == "." {
+			rel = ""
+		}
+		detected := detectTestCommandsIn(projectRoot, d)
+		for _, ecosystem := range testEcosystems {
+			found := detected[ecosystem]
+			if len(found) == 0 || seen[ecosystem] {
+				continue
+			}
+			seen[ecosystem] = true
+			for i := range found {
+				found[i].Dir = filepath.ToSlash(rel)
+			}
+			commands = append(commands, found...)
+		}
+		if d == projectRoot || d == filepath.Dir(d) {
+			break
+		}
+	}
+
+	return commands
+}
+
+// detectTestCommandsIn reads the build files of a single directory, keyed
+// by ecosystem
+func detectTestCommandsIn(projectRoot, dir string) map[string][]TestCommand {
+	found := make(map[string][]TestCommand)
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(data)
+	}
+
+	// Node: package.json scripts, run with the package manager in use
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			Scripts map[string]string `json:"scripts"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			pm := nodePackageManager(projectRoot, dir)
+			var names []string
+			for name, script := range pkg.Scripts {
+				if (name == "test" || strings.HasPrefix(name, "test:")) && !strings.Contains(script, noTestScript) {
+					names = append(names, name)
+				}
+			}
+			sort.Slice(names, func(i, j int) bool {
+				return names[i] == "test" || (names[j] != "test" && names[i] < names[j])
+			})
+			for _, name := range names {
+				cmd := TestCommand{Command: pm + " run " + name, Source: "package.json scripts." + name}
+				if name == "test" {
+					cmd.Command = pm + " test"
+				}
+				cmd.Single = nodeSingleTest(pm, pkg.Scripts[name])
+				found["node"] = append(found["node"], cmd)
+			}
+		}
+	}
+
+	// Make: test, test-unit, test.integration, ...
+	for _, name := range []string{"Makefile", "makefile", "GNUmakefile"} {
+		content := read(name)
+		if content == "" {
+			continue
+		}
+		for _, m := range makeTestTarget.FindAllStringSubmatch(content, -1) {
+			if len(found["make"]) >= maxMakeTargets {
+				break
+			}
+			found["make"] = append(found["make"], TestCommand{Command: "make " + m[1], Source: name + " target " + m[1]})
+		}
+		break
+	}
+
+	if exists("go.mod") {
+		found["go"] = []TestCommand{{Command: "go test ./...", Source: "go.mod", Single: "go test ./{dir}"}}
+	}
+
+	// Python: pytest when configured, else Django's runner or tox
+	pyproject := read("pyproject.toml")
+	runner := ""
+	switch {
+	case exists("poetry.lock") || strings.Contains(pyproject, "[tool.poetry]"):
+		runner = "poetry run "
+	case exists("uv.lock"):
+		runner = "uv run "
+	}
+	switch {
+	case exists("pytest.ini"):
+		found["python"] = []TestCommand{{Command: runner + "pytest", Source: "pytest.ini", Single: runner + "pytest {file}"}}
+	case strings.Contains(pyproject, "[tool.pytest"):
+		found["python"] = []TestCommand{{Command: runner + "pytest", Source: "pyproject.toml [tool.pytest]", Single: runner + "pytest {file}"}}
+	case strings.Contains(read("setup.cfg"), "[tool:pytest]"):
+		found["python"] = []TestCommand{{Command: runner + "pytest", Source: "setup.cfg [tool:pytest]", Single: runner + "pytest {file}"}}
+	case strings.Contains(read("tox.ini"), "[pytest]"):
+		found["python"] = []TestCommand{{Command: runner + "pytest", Source: "tox.ini [pytest]", Single: runner + "pytest {file}"}}
+	case exists("conftest.py"):
+		found["python"] = []TestCommand{{Command: runner + "pytest", Source: "conftest.py", Single: runner + "pytest {file}"}}
+	case exists("manage.py"):
+		found["python"] = []TestCommand{{Command: "python manage.py test", Source: "manage.py"}}
+	case strings.Contains(read("tox.ini"), "[tox]"):
+		found["python"] = []TestCommand{{Command: "tox", Source: "tox.ini"}}
+	}
+
+	if exists("Cargo.toml") {
+		found["rust"] = []TestCommand{{Command: "cargo test", Source: "Cargo.toml"}}
+	}
+
+	switch {
+	case exists("gradlew"):
+		found["jvm"] = []TestCommand{{Command: "./gradlew test", Source: "gradlew"}}
+	case exists("build.gradle") || exists("build.gradle.kts"):
+		found["jvm"] = []TestCommand{{Command: "gradle test", Source: "build.gradle"}}
+	case exists("mvnw"):
+		found["jvm"] = []TestCommand{{Command: "./mvnw test", Source: "mvnw"}}
+	case exists("pom.xml"):
+		found["jvm"] = []TestCommand{{Command: "mvn test", Source: "pom.xml"}}
+	}
+
+	if pubspec := read("pubspec.yaml"); pubspec != "" {
+		runner := "dart test"
+		if strings.Contains(pubspec, "sdk: flutter") {
+			runner = "flutter test"
+		}
+		found["dart"] = []TestCommand{{Command: runner, Source: "pubspec.yaml", Single: runner + " {file}"}}
+	}
+
+	if exists("mix.exs") {
+		found["elixir"] = []TestCommand{{Command: "mix test", Source: "mix.exs", Single: "mix test {file}"}}
+	}
+	switch {
+	case exists("Gemfile") && exists(".rspec"):
+		found["ruby"] = []TestCommand{{Command: "bundle exec rspec", Source: ".rspec", Single: "bundle exec rspec {file}"}}
+	case exists("Gemfile") && exists("bin/rails") && exists("test"):
+		found["ruby"] = []TestCommand{{Command: "bin/rails test", Source: "bin/rails", Single: "bin/rails test {file}"}}
+	}
+
+	// PHP: Laravel's artisan runner, else the Pest or PHPUnit binary
+	if composer := read("composer.json"); composer != "" {
+		switch {
+		case exists("artisan"):
+			found["php"] = []TestCommand{{Command: "php artisan test", Source: "artisan", Single: "php artisan test {file}"}}
+		case strings.Contains(composer, `"pestphp/pest"`):
+			found["php"] = []TestCommand{{Command: "vendor/bin/pest", Source: "composer.json", Single: "vendor/bin/pest {file}"}}
+		case strings.Contains(composer, `"phpunit/phpunit"`):
+			found["php"] = []TestCommand{{Command: "vendor/bin/phpunit", Source: "composer.json", Single: "vendor/bin/phpunit {file}"}}
+		}
+	}
+
+	return found
+}
+
+// nodePackageManager picks the package manager from the nearest lockfile
+func nodePackageManager(projectRoot, dir string) string {
+	locks := []struct{ file, pm string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+		{"package-lock.json", "npm"},
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, lock := range locks {
+			if _, err := os.Stat(filepath.Join(d, lock.file)); err == nil {
+				return lock.pm
+			}
+		}
+		if d == projectRoot || d == filepath.Dir(d) {
+			return "npm"
+		}
+	}
+}
+
+// nodeSingleTest returns the command running one file with the runner a
+// test script invokes
+func nodeSingleTest(pm, script string) string {
+	runner := map[string]string{"npm": "npx", "pnpm": "pnpm exec", "yarn": "yarn", "bun": "bunx"}[pm]
+	switch {
+	case strings.Contains(script, "vitest"):
+		return runner + " vitest run {file}"
+	case strings.Contains(script, "jest"):
+		return runner + " jest {file}"
+	case strings.Contains(script, "mocha"):
+		return runner + " mocha {file}"
+	}
+	return ""
+}
+
+// ChecklistWithTestCommands makes "run tests" steps concrete: generic steps
+// get the detected command appended, and a step is added when the
+// checklist never says to run the tests.
+func ChecklistWithTestCommands(checklist []string, commands []TestCommand) []string {
+	if len(commands) == 0 {
+		return checklist
+	}
+	run := "`" + commands[0].Shell() + "`"
+
+	out := make([]string, 0, len(checklist)+1)
+	mentioned := false
+	for _, step := range checklist {
+		lower := strings.ToLower(step)
+		if strings.Contains(lower, "run tests") || strings.Contains(lower, "verify test") || strings.Contains(lower, "test thoroughly") {
+			step += " — " + run
+			mentioned = true
+		}
+		out = append(out, step)
+	}
+	if !mentioned {
+		out = append(out, "Run tests — "+run)
+	}
+	return out
+}
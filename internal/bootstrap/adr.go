@@ -0,0 +1,183 @@
+package bootstrap
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// adrDirs are the usual homes of architecture decision records; adr-tools
+// also names its directory in .adr-dir
+var adrDirs = []string{
+	"docs/adr", "docs/adrs", "docs/decisions", "docs/architecture/decisions",
+	"doc/adr", "doc/architecture/decisions", "adr", "adrs", "architecture/decisions",
+}
+
+var (
+	// "ADR-0003: Use Postgres", "3. Use Postgres", "0003 - Use Postgres"
+	adrNumbering = regexp.MustCompile(`(?i)^(?:adr[-\s]?\d+|\d+)\s*[.:)-]?\s*`)
+	// "Status: Accepted", "* Status: accepted", "status: accepted" (MADR front matter)
+	adrStatusLine = regexp.MustCompile(`(?i)^[*-]?\s*\**status\**:\s*\**(\w+)`)
+	adrDeciders   = regexp.MustCompile(`(?i)^[*-]?\s*\**deciders\**:\s*(.+)`)
+)
+
+// maxADRText caps the reason and context taken from an ADR's sections
+const maxADRText = 400
+
+// mineADRs turns each accepted, superseded or deprecated ADR into a
+// decision: its title is the content, its context section the reason, its
+// decision section the context and its other considered options the
+// alternatives. Proposed and draft ADRs are not decisions yet and are
+// skipped.
+func mineADRs(root string) []types.Decision {
+	dirs := adrDirs
+	if custom := strings.TrimSpace(readFile(root, ".adr-dir")); custom != "" {
+		dirs = append([]string{path.Clean(filepath.ToSlash(custom))}, dirs...)
+	}
+
+	var decisions []types.Decision
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+		if err != nil || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		var names []string
+		for _, e := range entries {
+			name := strings.ToLower(e.Name())
+			if e.IsDir() || !strings.HasSuffix(name, ".md") || name == "readme.md" || name == "index.md" || strings.Contains(name, "template") {
+				continue
+			}
+			names = append(names, e.Name())
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rel := dir + "/" + name
+			if d, ok := parseADR(readFile(root, rel), rel); ok {
+				decisions = append(decisions, d)
+			}
+		}
+	}
+	return decisions
+}
+
+// parseADR reads a Nygard or MADR style record
+func parseADR(content, rel string) (types.Decision, bool) {
+	var title, status, deciders, section string
+	sections := make(map[string][]string)
+	var order []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "# ") && title == "":
+			title = strings.TrimSpace(adrNumbering.ReplaceAllString(strings.TrimSpace(trimmed[2:]), ""))
+			section = ""
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			section = strings.ToLower(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			order = append(order, section)
+			continue
+		}
+		if m := adrStatusLine.FindStringSubmatch(trimmed); m != nil && status == "" {
+			status = strings.ToLower(m[1])
+			continue
+		}
+		if m := adrDeciders.FindStringSubmatch(trimmed); m != nil {
+			deciders = strings.TrimSpace(m[1])
+			continue
+		}
+		if section == "status" && status == "" && trimmed != "" {
+			status = strings.ToLower(strings.Fields(trimmed)[0])
+		}
+		if section != "" {
+			sections[section] = append(sections[section], trimmed)
+		}
+	}
+	if title == "" {
+		return types.Decision{}, false
+	}
+
+	decision := types.Decision{
+		Content:      title,
+		Author:       deciders,
+		Tags:         []string{"adr"},
+		RelatedFiles: []string{rel},
+	}
+	switch strings.Trim(status, "*_") {
+	case "", "accepted", "approved", "done", "decided":
+		decision.Status = "active"
+	case "superseded", "replaced":
+		decision.Status = "superseded"
+	case "deprecated", "rejected", "obsolete", "retired":
+		decision.Status = "archived"
+	default:
+		// proposed, draft, open
+		return types.Decision{}, false
+	}
+
+	var decided string
+	for _, name := range order {
+		body := firstParagraph(sections[name])
+		switch {
+		case decision.Reason == "" && (strings.Contains(name, "context") || name == "problem"):
+			decision.Reason = body
+		case decided == "" && strings.Contains(name, "decision") && !strings.Contains(name, "driver"):
+			decided = body
+		}
+	}
+	// MADR lists the chosen option among the considered ones
+	for _, name := range order {
+		if !strings.Contains(name, "option") && !strings.Contains(name, "alternative") {
+			continue
+		}
+		for _, item := range listItems(sections[name]) {
+			if !strings.Contains(strings.ToLower(decided), strings.ToLower(item)) {
+				decision.Alternatives = append(decision.Alternatives, item)
+			}
+		}
+	}
+	decision.Context = "ADR " + rel
+	if decided != "" {
+		decision.Context += ": " + decided
+	}
+	if decision.Reason == "" {
+		decision.Reason = decided
+	}
+	return decision, true
+}
+
+// firstParagraph joins a section's lines up to its first blank line
+func firstParagraph(lines []string) string {
+	var parts []string
+	for _, line := range lines {
+		if line == "" {
+			if len(parts) > 0 {
+				break
+			}
+			continue
+		}
+		parts = append(parts, line)
+	}
+	text := strings.Join(parts, " ")
+	if len(text) > maxADRText {
+		text = strings.TrimSpace(text[:maxADRText-3]) + "..."
+	}
+	return text
+}
+
+// listItems returns the text of a section's top-level bullets
+func listItems(lines []string) []string {
+	var items []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "- ") {
+			items = append(items, strings.Trim(strings.TrimSpace(line[2:]), "*_`"))
+		}
+	}
+	return items
+}
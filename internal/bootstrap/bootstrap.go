@@ -0,0 +1,194 @@
+// Package bootstrap mines the knowledge a repository already records
+// before TeamContext arrives: architecture decision records become
+// decisions, CODEOWNERS entries become experts, lint and CI configs become
+// patterns, the constructs lint configs ban become compliance rules, and
+// dependency bot configs become a decision on how dependencies are updated.
+package bootstrap
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// PatternSource marks patterns mined by bootstrap
+const PatternSource = "bootstrap"
+
+// Result is what a repository's artifacts yield
+type Result struct {
+	Decisions  []types.Decision `json:"decisions"`
+	Patterns   []types.Pattern  `json:"patterns"`
+	Owners     []git.CodeOwners `json:"owners"`
+	OwnersFile string           `json:"owners_file,omitempty"`
+	Sources    []string         `json:"sources"` // project-relative files mined
+}
+
+// Summary counts what Apply stored; items already known are skipped
+type Summary struct {
+	Decisions int `json:"decisions"`
+	Patterns  int `json:"patterns"`
+	Owners    int `json:"owners"` // directories with CODEOWNERS experts
+	Skipped   int `json:"skipped"`
+}
+
+// Mine reads the ADRs, CODEOWNERS, lint configs, CI workflows and
+// dependency bot configs under root
+func Mine(root string) *Result {
+	r := &Result{}
+	r.addDecisions(mineADRs(root))
+	r.addPatterns(mineLintConfigs(root))
+	r.addDecisions(lintBans(root))
+	r.addPatterns(mineCIWorkflows(root))
+	r.addDecisions(mineDependencyBots(root))
+
+	if r.OwnersFile = git.FindCodeowners(root); r.OwnersFile != "" {
+		r.Owners = git.ReadCodeowners(root)
+		r.Sources = append(r.Sources, r.OwnersFile)
+	}
+
+	sort.Strings(r.Sources)
+	return r
+}
+
+func (r *Result) addDecisions(decisions []types.Decision) {
+	for _, d := range decisions {
+		r.Decisions = append(r.Decisions, d)
+		r.addSources(d.RelatedFiles...)
+	}
+}
+
+func (r *Result) addPatterns(patterns []types.Pattern) {
+	for _, p := range patterns {
+		r.Patterns = append(r.Patterns, p)
+		r.addSources(p.Examples...)
+	}
+}
+
+func (r *Result) addSources(files ...string) {
+	for _, f := range files {
+		found := false
+		for _, s := range r.Sources {
+			if s == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Sources = append(r.Sources, f)
+		}
+	}
+}
+
+// Apply stores a result: decisions whose content and patterns whose name
+// are not already recorded, each decision linked to the file it came from
+// in the knowledge graph, and the CODEOWNERS owners merged into the cached
+// experts. Running it again adds only what is new.
+func Apply(store *storage.JSONStore, tcDir string, r *Result) (*Summary, error) {
+	summary := &Summary{}
+
+	existing, err := store.GetDecisions()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, d := range existing {
+		known[strings.ToLower(d.Content)] = true
+	}
+	for i := range r.Decisions {
+		d := r.Decisions[i]
+		if known[strings.ToLower(d.Content)] {
+			summary.Skipped++
+			continue
+		}
+		if err := store.AddDecision(&d); errors.Is(err, storage.ErrDuplicate) {
+			summary.Skipped++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, f := range d.RelatedFiles {
+			store.AddEdge(&types.Edge{FromType: "decision", FromID: d.ID, ToType: "file", ToID: f, Relation: "affects"})
+		}
+		known[strings.ToLower(d.Content)] = true
+		summary.Decisions++
+	}
+
+	patterns, err := store.GetPatterns()
+	if err != nil {
+		return nil, err
+	}
+	knownPatterns := make(map[string]bool, len(patterns))
+	for _, p := range patterns {
+		knownPatterns[strings.ToLower(p.Name)] = true
+	}
+	for i := range r.Patterns {
+		p := r.Patterns[i]
+		if knownPatterns[strings.ToLower(p.Name)] {
+			summary.Skipped++
+			continue
+		}
+		if err := store.AddPattern(&p); errors.Is(err, storage.ErrDuplicate) {
+			summary.Skipped++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		knownPatterns[strings.ToLower(p.Name)] = true
+		summary.Patterns++
+	}
+
+	if len(r.Owners) > 0 {
+		if err := mergeExperts(filepath.Join(tcDir, "knowledge", "git-experts.json"), r.Owners); err != nil {
+			return nil, err
+		}
+		summary.Owners = len(r.Owners)
+	}
+	return summary, nil
+}
+
+// mergeExperts adds CODEOWNERS owners to the cached experts, creating the
+// cache when git history has not been analyzed yet
+func mergeExperts(path string, owners []git.CodeOwners) error {
+	var experts []git.DirectoryExpert
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &experts); err != nil {
+			return err
+		}
+	}
+	experts = git.MergeCodeowners(experts, owners)
+
+	data, err := json.MarshalIndent(experts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// readFile returns a project file's content, or "" when it cannot be read
+func readFile(root, rel string) string {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// firstExisting returns the first of rels that exists under root
+func firstExisting(root string, rels ...string) string {
+	for _, rel := range rels {
+		if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel))); err == nil && !info.IsDir() {
+			return rel
+		}
+	}
+	return ""
+}
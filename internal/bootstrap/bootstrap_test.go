@@ -0,0 +1,224 @@
+package bootstrap
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/storage"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMine(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"docs/adr/0001-record-architecture-decisions.md": `# 1. Record architecture decisions
+
+Date: 2021-03-04
+
+## Status
+
+Accepted
+
+## Context
+
+We need to record the architectural decisions made on this project.
+
+## Decision
+
+We will use Architecture Decision Records.
+`,
+		"docs/adr/0002-use-postgres.md": `---
+status: superseded
+deciders: Ana, Ben
+---
+# ADR-0002: Use PostgreSQL for orders
+
+## Context and Problem Statement
+
+Orders need transactions across tables.
+
+## Considered Options
+
+* PostgreSQL
+* MongoDB
+
+## Decision Outcome
+
+Chosen option: PostgreSQL, because it has transactions.
+`,
+		"docs/adr/0003-event-sourcing.md": "# 3. Event sourcing\n\n## Status\n\nProposed\n",
+		"docs/adr/template.md":            "# Title\n",
+		".github/CODEOWNERS": `# Default owners
+*            @acme/core
+/services/billing/   @acme/payments dev@acme.io
+docs/*.md    @acme/docs
+`,
+		".eslintrc.json": `{
+  "extends": ["eslint:recommended", "prettier"],
+  "rules": {
+    "no-console": "error",
+    "no-debugger": "warn",
+    "eqeqeq": ["error", "always"]
+  }
+}`,
+		".prettierrc": "{\n  \"semi\": false,\n  \"singleQuote\": true\n}\n",
+		".github/workflows/ci.yml": `name: CI
+on: [push]
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: npm run lint
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Test
+        run: |
+          npm test -- --coverage
+          npm run e2e
+`,
+		".github/dependabot.yml": `version: 2
+updates:
+  - package-ecosystem: npm
+    directory: "/"
+    schedule:
+      interval: weekly
+  - package-ecosystem: github-actions
+    directory: "/"
+    schedule:
+      interval: monthly
+`,
+	})
+
+	r := Mine(root)
+
+	var contents, statuses []string
+	for _, d := range r.Decisions {
+		contents = append(contents, d.Content)
+		statuses = append(statuses, d.Status)
+	}
+	wantContents := []string{
+		"Record architecture decisions",
+		"Use PostgreSQL for orders",
+		"Avoid console (ESLint no-console)",
+		"Dependencies are updated by Dependabot: npm (weekly), github-actions (monthly)",
+	}
+	if !reflect.DeepEqual(contents, wantContents) {
+		t.Fatalf("decisions = %q", contents)
+	}
+	if !reflect.DeepEqual(statuses, []string{"active", "superseded", "", ""}) {
+		t.Errorf("statuses = %q", statuses)
+	}
+	first := r.Decisions[0]
+	if first.Reason != "We need to record the architectural decisions made on this project." ||
+		first.Context != "ADR docs/adr/0001-record-architecture-decisions.md: We will use Architecture Decision Records." {
+		t.Errorf("first ADR = %+v", first)
+	}
+	madr := r.Decisions[1]
+	if madr.Author != "Ana, Ben" || madr.Reason != "Orders need transactions across tables." || !reflect.DeepEqual(madr.Alternatives, []string{"MongoDB"}) {
+		t.Errorf("MADR = %+v", madr)
+	}
+
+	var names []string
+	for _, p := range r.Patterns {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"ESLint rules", "Prettier rules", "CI: GitHub Actions CI"}) {
+		t.Fatalf("patterns = %q", names)
+	}
+	wantRules := [][]string{
+		{"extends eslint:recommended", "extends prettier", "no-console: error", "no-debugger: warn", "eqeqeq: error"},
+		{"semi: false", "singleQuote: true"},
+		{"lint: npm run lint", "test: npm test -- --coverage"},
+	}
+	for i, p := range r.Patterns {
+		if !reflect.DeepEqual(p.Rules, wantRules[i]) {
+			t.Errorf("%s rules = %q", p.Name, p.Rules)
+		}
+	}
+
+	wantOwners := []git.CodeOwners{
+		{Directory: ".", Owners: []string{"@acme/core"}, Line: 2},
+		{Directory: "services/billing", Owners: []string{"@acme/payments", "dev@acme.io"}, Line: 3},
+		{Directory: "docs", Owners: []string{"@acme/docs"}, Line: 4},
+	}
+	if r.OwnersFile != ".github/CODEOWNERS" || !reflect.DeepEqual(r.Owners, wantOwners) {
+		t.Errorf("owners = %+v", r.Owners)
+	}
+}
+
+func TestApply(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"docs/decisions/0001-use-go.md": "# Use Go\n\n## Context\n\nOne static binary.\n",
+		"CODEOWNERS":                    "/services/billing/ @acme/payments dev@acme.io\n",
+		".golangci.yml":                 "linters:\n  enable:\n    - gosec\n    - revive\n",
+		".teamcontext/knowledge/.keep":  "",
+		".teamcontext/knowledge/git-experts.json": `[{"directory": "services/billing", "file_count": 3, "total_commits": 10,
+  "top_experts": [{"name": "Dev", "email": "dev@acme.io", "commits": 10, "ownership": 1, "active": true}]}]`,
+	})
+	tcDir := filepath.Join(root, ".teamcontext")
+	store := storage.NewJSONStore(tcDir)
+
+	r := Mine(root)
+	summary, err := Apply(store, tcDir, r)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if *summary != (Summary{Decisions: 1, Patterns: 1, Owners: 1}) {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	// A second run finds everything recorded
+	summary, err = Apply(store, tcDir, Mine(root))
+	if err != nil || *summary != (Summary{Owners: 1, Skipped: 2}) {
+		t.Errorf("second summary = %+v, %v", summary, err)
+	}
+
+	decisions, _ := store.GetDecisions()
+	if len(decisions) != 1 || decisions[0].Reason != "One static binary." {
+		t.Errorf("decisions = %+v", decisions)
+	}
+	patterns, _ := store.GetPatterns()
+	if len(patterns) != 1 || !reflect.DeepEqual(patterns[0].Rules, []string{"linter gosec enabled", "linter revive enabled"}) || patterns[0].Source != PatternSource {
+		t.Errorf("patterns = %+v", patterns)
+	}
+	edges, _ := store.GetEdgesFrom("decision", decisions[0].ID)
+	if len(edges) != 1 || edges[0].ToID != "docs/decisions/0001-use-go.md" {
+		t.Errorf("edges = %+v", edges)
+	}
+
+	// The team joins the history expert, who is not listed twice
+	data, err := os.ReadFile(filepath.Join(tcDir, "knowledge", "git-experts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var experts []git.DirectoryExpert
+	if err := json.Unmarshal(data, &experts); err != nil {
+		t.Fatal(err)
+	}
+	if len(experts) != 1 || len(experts[0].TopExperts) != 2 {
+		t.Fatalf("experts = %+v", experts)
+	}
+	team := experts[0].TopExperts[1]
+	if team.Name != "@acme/payments" || team.Source != git.CodeownersSource || !team.Active {
+		t.Errorf("team = %+v", team)
+	}
+}
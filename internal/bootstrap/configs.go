@@ -0,0 +1,455 @@
+package bootstrap
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// maxRules caps the rules kept per pattern
+const maxRules = 25
+
+var (
+	// "no-console": "error", 'eqeqeq': ['error', 'always'], semi: 2
+	eslintRule    = regexp.MustCompile(`["']?([@\w][\w@/-]*)["']?\s*:\s*\[?\s*["']?(error|warn|2|1)\b`)
+	eslintExtends = regexp.MustCompile(`["']?extends["']?\s*:\s*(\[[^\]]*\]|["'][^"']*["'])`)
+	quoted        = regexp.MustCompile(`["']([^"']+)["']`)
+	tomlList      = regexp.MustCompile(`^(?:extend-)?select\s*=\s*\[(.*)\]`)
+)
+
+// eslintBans are the rules that forbid a construct outright, by the name
+// compliance checks look for in code
+var eslintBans = map[string]string{
+	"no-console":  "console",
+	"no-debugger": "debugger",
+	"no-eval":     "eval",
+	"no-alert":    "alert",
+}
+
+// ruffBans are the Ruff codes that forbid a call, by the call's name
+var ruffBans = map[string]string{
+	"T201": "print",
+	"T203": "pprint",
+	"T100": "breakpoint",
+	"S307": "eval",
+	"S102": "exec",
+}
+
+// lintConfig is a linter or formatter and the files configuring it
+type lintConfig struct {
+	name  string
+	files []string
+	rules func(content string) []string
+}
+
+var lintConfigs = []lintConfig{
+	{"ESLint", []string{".eslintrc", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml", ".eslintrc.js", ".eslintrc.cjs", "eslint.config.js", "eslint.config.mjs", "eslint.config.cjs"}, eslintRules},
+	{"golangci-lint", []string{".golangci.yml", ".golangci.yaml"}, golangciRules},
+	{"Ruff", []string{"ruff.toml", ".ruff.toml", "pyproject.toml"}, ruffRules},
+	{"Prettier", []string{".prettierrc", ".prettierrc.json", ".prettierrc.yml", ".prettierrc.yaml"}, keyValueRules},
+	{"EditorConfig", []string{".editorconfig"}, editorconfigRules},
+}
+
+// mineLintConfigs turns each configured linter or formatter into a
+// pattern listing the rules it enforces
+func mineLintConfigs(root string) []types.Pattern {
+	var patterns []types.Pattern
+	for _, lc := range lintConfigs {
+		for _, rel := range lc.files {
+			content := readFile(root, rel)
+			if content == "" || (rel == "pyproject.toml" && !strings.Contains(content, "[tool.ruff")) {
+				continue
+			}
+			rules := lc.rules(content)
+			if len(rules) > maxRules {
+				rules = rules[:maxRules]
+			}
+			patterns = append(patterns, types.Pattern{
+				Name:        lc.name + " rules",
+				Description: fmt.Sprintf("Code must pass %s as configured in %s", lc.name, rel),
+				Rules:       rules,
+				Examples:    []string{rel},
+				Source:      PatternSource,
+			})
+			break
+		}
+	}
+	return patterns
+}
+
+// lintBans turns the lint rules that ban a construct into decisions that
+// compliance checks flag the construct with
+func lintBans(root string) []types.Decision {
+	var decisions []types.Decision
+	ban := func(term, rule, rel string) {
+		decisions = append(decisions, types.Decision{
+			Content:      fmt.Sprintf("Avoid %s (%s)", term, rule),
+			Reason:       fmt.Sprintf("%s is an error in %s, so lint fails on it", rule, rel),
+			Tags:         []string{"compliance", "lint"},
+			RelatedFiles: []string{rel},
+		})
+	}
+
+	for _, rel := range lintConfigs[0].files {
+		content := readFile(root, rel)
+		if content == "" {
+			continue
+		}
+		for _, m := range eslintRule.FindAllStringSubmatch(content, -1) {
+			if term, ok := eslintBans[m[1]]; ok && (m[2] == "error" || m[2] == "2") {
+				ban(term, "ESLint "+m[1], rel)
+			}
+		}
+		break
+	}
+
+	for _, rel := range []string{"ruff.toml", ".ruff.toml", "pyproject.toml"} {
+		content := readFile(root, rel)
+		if content == "" || (rel == "pyproject.toml" && !strings.Contains(content, "[tool.ruff")) {
+			continue
+		}
+		var codes []string
+		for code := range ruffBans {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		selected := ruffSelected(content)
+		for _, code := range codes {
+			for _, sel := range selected {
+				if sel == "ALL" || strings.HasPrefix(code, sel) {
+					ban(ruffBans[code], "Ruff "+code, rel)
+					break
+				}
+			}
+		}
+		break
+	}
+	return decisions
+}
+
+func eslintRules(content string) []string {
+	var rules []string
+	if m := eslintExtends.FindStringSubmatch(content); m != nil {
+		for _, q := range quoted.FindAllStringSubmatch(m[1], -1) {
+			rules = append(rules, "extends "+q[1])
+		}
+	}
+	for _, m := range eslintRule.FindAllStringSubmatch(content, -1) {
+		level := m[2]
+		switch level {
+		case "2":
+			level = "error"
+		case "1":
+			level = "warn"
+		}
+		rules = append(rules, m[1]+": "+level)
+	}
+	return rules
+}
+
+// golangciRules lists the linters enabled under linters.enable
+func golangciRules(content string) []string {
+	var rules []string
+	inEnable := false
+	enableIndent := 0
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if trimmed == "enable:" {
+			inEnable, enableIndent = true, indent
+			continue
+		}
+		if inEnable && strings.HasPrefix(trimmed, "- ") && indent >= enableIndent {
+			rules = append(rules, "linter "+strings.TrimSpace(trimmed[2:])+" enabled")
+			continue
+		}
+		inEnable = false
+		if trimmed == "enable-all: true" {
+			rules = append(rules, "all linters enabled")
+		}
+	}
+	return rules
+}
+
+// ruffRules lists the selected rule codes and the line length
+func ruffRules(content string) []string {
+	var rules []string
+	if selected := ruffSelected(content); len(selected) > 0 {
+		rules = append(rules, "select "+strings.Join(selected, ", "))
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "line-length") {
+			rules = append(rules, strings.Join(strings.Fields(trimmed), " "))
+		}
+	}
+	return rules
+}
+
+// ruffSelected returns the codes of Ruff's select and extend-select lists
+func ruffSelected(content string) []string {
+	var codes []string
+	for _, line := range strings.Split(content, "\n") {
+		if m := tomlList.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			for _, q := range quoted.FindAllStringSubmatch(m[1], -1) {
+				codes = append(codes, q[1])
+			}
+		}
+	}
+	return codes
+}
+
+// keyValueRules lists the top-level options of a JSON or YAML config
+func keyValueRules(content string) []string {
+	var rules []string
+	for _, line := range strings.Split(content, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			// JSON options are indented once
+			if strings.HasPrefix(line, "  \"") && !strings.HasPrefix(line, "   ") {
+				line = strings.TrimSpace(line)
+			} else {
+				continue
+			}
+		}
+		key, value, ok := strings.Cut(strings.TrimSuffix(strings.TrimSpace(line), ","), ":")
+		key = strings.Trim(key, `"' `)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if !ok || key == "" || value == "" || strings.HasPrefix(key, "#") || strings.ContainsAny(value, "{[") {
+			continue
+		}
+		rules = append(rules, key+": "+value)
+	}
+	return rules
+}
+
+// editorconfigRules lists the settings that apply to every file ([*])
+func editorconfigRules(content string) []string {
+	var rules []string
+	inAll := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			inAll = trimmed == "[*]"
+			continue
+		}
+		if !inAll || trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+		if key, value, ok := strings.Cut(trimmed, "="); ok {
+			rules = append(rules, strings.TrimSpace(key)+" = "+strings.TrimSpace(value))
+		}
+	}
+	return rules
+}
+
+// mineCIWorkflows turns each CI pipeline into a pattern whose rules are the
+// commands its jobs run, the checks every change must pass
+func mineCIWorkflows(root string) []types.Pattern {
+	var patterns []types.Pattern
+	workflows, _ := filepath.Glob(filepath.Join(root, ".github", "workflows", "*.y*ml"))
+	sort.Strings(workflows)
+	for _, path := range workflows {
+		rel := ".github/workflows/" + filepath.Base(path)
+		name, rules := githubWorkflow(readFile(root, rel))
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if len(rules) > 0 {
+			patterns = append(patterns, ciPattern("GitHub Actions "+name, rel, rules))
+		}
+	}
+	if content := readFile(root, ".gitlab-ci.yml"); content != "" {
+		if rules := gitlabJobs(content); len(rules) > 0 {
+			patterns = append(patterns, ciPattern("GitLab CI", ".gitlab-ci.yml", rules))
+		}
+	}
+	return patterns
+}
+
+func ciPattern(name, rel string, rules []string) types.Pattern {
+	if len(rules) > maxRules {
+		rules = rules[:maxRules]
+	}
+	return types.Pattern{
+		Name:        "CI: " + name,
+		Description: fmt.Sprintf("Checks that run on every change (%s); run them locally before pushing", rel),
+		Rules:       rules,
+		Examples:    []string{rel},
+		Source:      PatternSource,
+	}
+}
+
+// githubWorkflow returns a workflow's name and its run steps as
+// "job: command"; multi-line commands keep their first line
+func githubWorkflow(content string) (string, []string) {
+	var name, job string
+	var rules []string
+	inJobs := false
+	jobIndent := -1
+	block := -1 // indent of a "run: |" step whose first line is next
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if block >= 0 {
+			first := indent > block
+			block = -1
+			if first {
+				rules = append(rules, job+": "+trimmed)
+				continue
+			}
+		}
+		if indent == 0 {
+			inJobs = trimmed == "jobs:"
+			if strings.HasPrefix(trimmed, "name:") {
+				name = strings.Trim(strings.TrimSpace(trimmed[5:]), `"'`)
+			}
+			continue
+		}
+		if !inJobs {
+			continue
+		}
+		if jobIndent < 0 {
+			jobIndent = indent
+		}
+		if indent == jobIndent && strings.HasSuffix(trimmed, ":") {
+			job = strings.TrimSuffix(trimmed, ":")
+			continue
+		}
+		step := strings.TrimPrefix(trimmed, "- ")
+		if !strings.HasPrefix(step, "run:") {
+			continue
+		}
+		command := strings.TrimSpace(step[4:])
+		if command == "|" || command == ">" || command == "|-" || command == ">-" {
+			block = indent
+			continue
+		}
+		rules = append(rules, job+": "+strings.Trim(command, `"'`))
+	}
+	return name, rules
+}
+
+// gitlabReserved are .gitlab-ci.yml's top-level keys that are not jobs
+var gitlabReserved = map[string]bool{
+	"stages": true, "variables": true, "default": true, "include": true, "image": true,
+	"services": true, "workflow": true, "before_script": true, "after_script": true, "cache": true,
+}
+
+// gitlabJobs returns each job's script commands as "job: command"
+func gitlabJobs(content string) []string {
+	var rules []string
+	job := ""
+	inScript := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if line[0] != ' ' {
+			key := strings.TrimSuffix(trimmed, ":")
+			job = ""
+			if !gitlabReserved[key] && !strings.HasPrefix(key, ".") && strings.HasSuffix(trimmed, ":") {
+				job = key
+			}
+			inScript = false
+			continue
+		}
+		if job == "" {
+			continue
+		}
+		switch {
+		case trimmed == "script:":
+			inScript = true
+		case inScript && strings.HasPrefix(trimmed, "- "):
+			rules = append(rules, job+": "+strings.Trim(strings.TrimSpace(trimmed[2:]), `"'`))
+		default:
+			inScript = false
+		}
+	}
+	return rules
+}
+
+// mineDependencyBots records how dependencies are kept up to date:
+// Dependabot's ecosystems and schedules, or Renovate's presets
+func mineDependencyBots(root string) []types.Decision {
+	var decisions []types.Decision
+	if rel := firstExisting(root, ".github/dependabot.yml", ".github/dependabot.yaml"); rel != "" {
+		if updates := dependabotUpdates(readFile(root, rel)); len(updates) > 0 {
+			decisions = append(decisions, types.Decision{
+				Content:      "Dependencies are updated by Dependabot: " + strings.Join(updates, ", "),
+				Reason:       "Configured in " + rel + "; review and merge its pull requests rather than bumping these dependencies by hand",
+				Tags:         []string{"dependencies"},
+				RelatedFiles: []string{rel},
+			})
+		}
+	}
+	if rel := firstExisting(root, "renovate.json", "renovate.json5", ".github/renovate.json", ".renovaterc", ".renovaterc.json"); rel != "" {
+		content := "Dependencies are updated by Renovate"
+		if m := eslintExtends.FindStringSubmatch(readFile(root, rel)); m != nil {
+			var presets []string
+			for _, q := range quoted.FindAllStringSubmatch(m[1], -1) {
+				presets = append(presets, q[1])
+			}
+			if len(presets) > 0 {
+				content += " (extends " + strings.Join(presets, ", ") + ")"
+			}
+		}
+		decisions = append(decisions, types.Decision{
+			Content:      content,
+			Reason:       "Configured in " + rel + "; review and merge its pull requests rather than bumping dependencies by hand",
+			Tags:         []string{"dependencies"},
+			RelatedFiles: []string{rel},
+		})
+	}
+	return decisions
+}
+
+// dependabotUpdates lists each update entry as "npm in /web (weekly)"
+func dependabotUpdates(content string) []string {
+	var updates []string
+	var ecosystem, directory, interval string
+	flush := func() {
+		if ecosystem != "" {
+			entry := ecosystem
+			if directory != "" && directory != "/" {
+				entry += " in " + directory
+			}
+			if interval != "" {
+				entry += " (" + interval + ")"
+			}
+			updates = append(updates, entry)
+		}
+		ecosystem, directory, interval = "", "", ""
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "- ") {
+			flush()
+			trimmed = strings.TrimSpace(trimmed[2:])
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		switch key {
+		case "package-ecosystem":
+			ecosystem = value
+		case "directory":
+			directory = value
+		case "interval":
+			interval = value
+		}
+	}
+	flush()
+	return updates
+}
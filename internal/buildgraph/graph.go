@@ -0,0 +1,520 @@
+// Package buildgraph ingests Bazel and Pants BUILD files. In monorepos built
+// with these tools the declared target deps, not source imports, are the real
+// dependency graph, so the targets and the files they own are merged into the
+// knowledge graph as authoritative edges.
+package buildgraph
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Graph node type and relations written to the knowledge graph
+const (
+	NodeTarget        = "build_target"
+	RelationBuiltBy   = "built_by"   // file -> build_target
+	RelationDependsOn = "depends_on" // build_target -> build_target
+)
+
+// buildFileNames are the file names that declare a package
+var buildFileNames = map[string]bool{
+	"BUILD":       true,
+	"BUILD.bazel": true,
+	"BUILD.pants": true,
+}
+
+// srcAttrs and depAttrs are the rule attributes holding owned files and
+// dependencies, for both Bazel and Pants rules.
+var (
+	srcAttrs = []string{"srcs", "hdrs", "sources", "source"}
+	depAttrs = []string{"deps", "runtime_deps", "exports", "implementation_deps", "dependencies"}
+)
+
+// pantsDefaultSources are the sources Pants targets own when none are given
+var pantsDefaultSources = map[string][]string{
+	"python_sources":     {"*.py", "!test_*.py", "!*_test.py", "!tests.py", "!conftest.py"},
+	"python_tests":       {"test_*.py", "*_test.py", "tests.py"},
+	"python_test_utils":  {"conftest.py"},
+	"go_package":         {"*.go"},
+	"java_sources":       {"*.java", "!*Test.java"},
+	"junit_tests":        {"*Test.java"},
+	"scala_sources":      {"*.scala", "!*Test.scala", "!*Spec.scala"},
+	"scalatest_tests":    {"*Spec.scala"},
+	"kotlin_sources":     {"*.kt", "!*Test.kt"},
+	"shell_sources":      {"*.sh"},
+	"shunit2_tests":      {"*_test.sh", "test_*.sh"},
+	"protobuf_sources":   {"*.proto"},
+	"javascript_sources": {"*.js", "!*.test.js"},
+	"typescript_sources": {"*.ts", "!*.test.ts", "!*.d.ts"},
+}
+
+// skipDirs are never searched for BUILD files
+var skipDirs = map[string]bool{
+	"node_modules": true, ".git": true, "vendor": true, "dist": true,
+	"__pycache__": true, ".cache": true, ".pants.d": true,
+}
+
+// Target is one rule declared in a BUILD file
+type Target struct {
+	Label     string   `json:"label"` // //pkg:name
+	Kind      string   `json:"kind"`  // go_library, python_sources, ...
+	BuildFile string   `json:"build_file"`
+	Srcs      []string `json:"srcs"` // project-relative files
+	Deps      []string `json:"deps"` // labels of targets in this repo
+}
+
+// Graph is the parsed build graph of a project
+type Graph struct {
+	Targets map[string]*Target `json:"targets"` // by label
+	Tool    string             `json:"tool"`    // bazel or pants
+	Errors  []string           `json:"errors,omitempty"`
+}
+
+// IsBuildFile reports whether path is a BUILD file
+func IsBuildFile(path string) bool {
+	return buildFileNames[filepath.Base(path)]
+}
+
+// Detect returns "bazel", "pants" or "" depending on the workspace markers
+// at the project root.
+func Detect(projectRoot string) string {
+	for _, f := range []string{"pants.toml", "pants.ini"} {
+		if _, err := os.Stat(filepath.Join(projectRoot, f)); err == nil {
+			return "pants"
+		}
+	}
+	for _, f := range []string{"WORKSPACE", "WORKSPACE.bazel", "MODULE.bazel"} {
+		if _, err := os.Stat(filepath.Join(projectRoot, f)); err == nil {
+			return "bazel"
+		}
+	}
+	return ""
+}
+
+// Ingest parses every BUILD file under projectRoot. It returns nil when the
+// project is neither a Bazel nor a Pants workspace. Unparseable BUILD files
+// are reported in Graph.Errors and skipped.
+func Ingest(projectRoot string) (*Graph, error) {
+	tool := Detect(projectRoot)
+	if tool == "" {
+		return nil, nil
+	}
+
+	g := &Graph{Targets: make(map[string]*Target), Tool: tool}
+	err := filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != projectRoot && (skipDirs[name] || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "bazel-")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !buildFileNames[info.Name()] {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(projectRoot, filepath.Dir(path))
+		pkg := filepath.ToSlash(rel)
+		if pkg == "." {
+			pkg = ""
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			g.Errors = append(g.Errors, err.Error())
+			return nil
+		}
+		targets, perr := parsePackage(projectRoot, pkg, filepath.ToSlash(filepath.Join(pkg, info.Name())), string(data), tool)
+		if perr != nil {
+			g.Errors = append(g.Errors, perr.Error())
+		}
+		for _, t := range targets {
+			g.Targets[t.Label] = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g.resolveFileDeps()
+	return g, nil
+}
+
+// parsePackage turns the rules of one BUILD file into targets
+func parsePackage(projectRoot, pkg, buildFile, src, tool string) ([]*Target, error) {
+	calls, err := parseBuildFile(src)
+	if err != nil {
+		err = &parseError{file: buildFile, err: err}
+	}
+
+	var targets []*Target
+	for _, c := range calls {
+		kind := c.Name[strings.LastIndex(c.Name, ".")+1:]
+
+		name := ""
+		if v, ok := c.Kwargs["name"]; ok && v.Str != nil {
+			name = *v.Str
+		}
+		if name == "" {
+			if tool != "pants" {
+				continue // Bazel rules must be named; anything else is a macro call we can't see into
+			}
+			name = filepath.Base(pkg)
+			if pkg == "" {
+				name = filepath.Base(projectRoot)
+			}
+		}
+
+		t := &Target{
+			Label:     formatLabel(pkg, name),
+			Kind:      kind,
+			BuildFile: buildFile,
+		}
+
+		hasSrcs := false
+		for _, attr := range srcAttrs {
+			v, ok := c.Kwargs[attr]
+			if !ok {
+				continue
+			}
+			hasSrcs = true
+			t.Srcs = append(t.Srcs, expandSrcs(projectRoot, pkg, v)...)
+		}
+		if !hasSrcs && tool == "pants" {
+			if patterns, ok := pantsDefaultSources[kind]; ok {
+				t.Srcs = globPackage(projectRoot, pkg, patterns)
+			}
+		}
+
+		for _, attr := range depAttrs {
+			v, ok := c.Kwargs[attr]
+			if !ok {
+				continue
+			}
+			for _, dep := range v.strings() {
+				if label := normalizeLabel(pkg, dep, tool); label != "" {
+					t.Deps = append(t.Deps, label)
+				}
+			}
+		}
+
+		t.Srcs = dedupe(t.Srcs)
+		t.Deps = dedupe(t.Deps)
+		targets = append(targets, t)
+	}
+	return targets, err
+}
+
+type parseError struct {
+	file string
+	err  error
+}
+
+func (e *parseError) Error() string { return e.file + ": " + e.err.Error() }
+
+// expandSrcs resolves a srcs attribute to project-relative files. glob()
+// patterns are expanded against the package directory; labels pointing at
+// other targets are skipped.
+func expandSrcs(projectRoot, pkg string, v value) []string {
+	var out []string
+	switch {
+	case v.Call != nil && v.Call.Name == "glob":
+		var patterns []string
+		if inc, ok := v.Call.Kwargs["include"]; ok {
+			patterns = append(patterns, inc.strings()...)
+		}
+		for _, a := range v.Call.Args {
+			if len(patterns) == 0 {
+				patterns = append(patterns, a.strings()...)
+			}
+		}
+		if exc, ok := v.Call.Kwargs["exclude"]; ok {
+			for _, e := range exc.strings() {
+				patterns = append(patterns, "!"+e)
+			}
+		}
+		return globPackage(projectRoot, pkg, patterns)
+	case v.Call != nil && v.Call.Name == "select":
+		for _, a := range v.Call.Args {
+			out = append(out, expandSrcs(projectRoot, pkg, a)...)
+		}
+		return out
+	case v.Str != nil:
+		src := *v.Str
+		if strings.HasPrefix(src, "@") {
+			return nil
+		}
+		if strings.HasPrefix(src, "//") {
+			// //pkg:file.go refers to a file in another package
+			label := strings.TrimPrefix(src, "//")
+			src = strings.Replace(label, ":", "/", 1)
+		} else {
+			src = strings.TrimPrefix(src, ":")
+			// Pants sources may use !excludes; they only matter for globs
+			if strings.HasPrefix(src, "!") {
+				return nil
+			}
+			if strings.ContainsAny(src, "*?[") {
+				return globPackage(projectRoot, pkg, []string{src})
+			}
+			src = joinPkg(pkg, src)
+		}
+		if info, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(src))); err == nil && !info.IsDir() {
+			return []string{src}
+		}
+		return nil
+	}
+	for _, item := range v.List {
+		out = append(out, expandSrcs(projectRoot, pkg, item)...)
+	}
+	return out
+}
+
+// globPackage matches patterns against the package directory. "**" matches
+// any number of directories and a leading "!" excludes. Subdirectories that
+// contain their own BUILD file belong to another package and are skipped,
+// as Bazel does.
+func globPackage(projectRoot, pkg string, patterns []string) []string {
+	var include, exclude []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			exclude = append(exclude, p[1:])
+		} else {
+			include = append(include, p)
+		}
+	}
+	if len(include) == 0 {
+		return nil
+	}
+
+	recursive := false
+	for _, p := range include {
+		if strings.Contains(p, "/") {
+			recursive = true
+		}
+	}
+
+	dir := filepath.Join(projectRoot, filepath.FromSlash(pkg))
+	var out []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path == dir {
+				return nil
+			}
+			if !recursive || skipDirs[info.Name()] || strings.HasPrefix(info.Name(), ".") || hasBuildFile(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if matchAny(include, rel) && !matchAny(exclude, rel) {
+			out = append(out, joinPkg(pkg, rel))
+		}
+		return nil
+	})
+	sort.Strings(out)
+	return out
+}
+
+func hasBuildFile(dir string) bool {
+	for name := range buildFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated path against a pattern where "**"
+// spans any number of path segments.
+func matchGlob(pattern, path string) bool {
+	patParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(path, "/")
+
+	var match func(pi, si int) bool
+	match = func(pi, si int) bool {
+		if pi == len(patParts) {
+			return si == len(pathParts)
+		}
+		if patParts[pi] == "**" {
+			for k := si; k <= len(pathParts); k++ {
+				if match(pi+1, k) {
+					return true
+				}
+			}
+			return false
+		}
+		if si == len(pathParts) {
+			return false
+		}
+		ok, err := filepath.Match(patParts[pi], pathParts[si])
+		return err == nil && ok && match(pi+1, si+1)
+	}
+	return match(0, 0)
+}
+
+// normalizeLabel turns a dependency string into an absolute //pkg:name
+// label. External repositories (@maven//..., @pypi_requests, 3rdparty in
+// Pants lockfiles) are not part of the project graph and return "".
+func normalizeLabel(pkg, dep, tool string) string {
+	dep = strings.TrimSpace(dep)
+	if dep == "" || strings.HasPrefix(dep, "!") {
+		return ""
+	}
+	// @//pkg:x and @@//pkg:x are the main repository
+	if strings.HasPrefix(dep, "@") {
+		trimmed := strings.TrimLeft(dep, "@")
+		if !strings.HasPrefix(trimmed, "//") {
+			return ""
+		}
+		dep = trimmed
+	}
+
+	var target string
+	switch {
+	case strings.HasPrefix(dep, "//"):
+		target = dep[2:]
+	case strings.HasPrefix(dep, ":"):
+		return formatLabel(pkg, dep[1:])
+	case strings.HasPrefix(dep, "./"):
+		target = joinPkg(pkg, dep[2:])
+	case tool == "pants":
+		// Pants addresses without // are relative to the build root
+		target = dep
+	default:
+		// A bare name in a Bazel deps list is a target in the same package
+		return formatLabel(pkg, dep)
+	}
+
+	depPkg, name := target, ""
+	if i := strings.LastIndex(target, ":"); i >= 0 {
+		depPkg, name = target[:i], target[i+1:]
+	}
+	depPkg = strings.Trim(depPkg, "/")
+	if name == "" {
+		// //pkg is short for //pkg:pkg, but Pants also lets a dep name a file
+		if tool == "pants" && filepath.Ext(depPkg) != "" {
+			return "file:" + depPkg
+		}
+		name = filepath.Base(depPkg)
+	}
+	return formatLabel(depPkg, name)
+}
+
+// resolveFileDeps replaces Pants file addresses with the targets owning
+// those files.
+func (g *Graph) resolveFileDeps() {
+	owners := g.FileOwners()
+	for _, t := range g.Targets {
+		var deps []string
+		for _, d := range t.Deps {
+			if !strings.HasPrefix(d, "file:") {
+				deps = append(deps, d)
+				continue
+			}
+			for _, owner := range owners[strings.TrimPrefix(d, "file:")] {
+				if owner != t.Label {
+					deps = append(deps, owner)
+				}
+			}
+		}
+		t.Deps = dedupe(deps)
+	}
+}
+
+// FileOwners maps each project-relative file to the labels of the targets
+// listing it in their sources.
+func (g *Graph) FileOwners() map[string][]string {
+	owners := make(map[string][]string)
+	for _, label := range g.labels() {
+		for _, src := range g.Targets[label].Srcs {
+			owners[src] = append(owners[src], label)
+		}
+	}
+	return owners
+}
+
+// Edges returns the build graph as knowledge graph edges: one built_by
+// edge per owned file and one depends_on edge per dependency on a target
+// declared in this project.
+func (g *Graph) Edges() []types.Edge {
+	var edges []types.Edge
+	for _, label := range g.labels() {
+		t := g.Targets[label]
+		for _, src := range t.Srcs {
+			edges = append(edges, types.Edge{
+				FromType: "file",
+				FromID:   src,
+				ToType:   NodeTarget,
+				ToID:     t.Label,
+				Relation: RelationBuiltBy,
+			})
+		}
+		for _, dep := range t.Deps {
+			if _, ok := g.Targets[dep]; !ok {
+				continue
+			}
+			edges = append(edges, types.Edge{
+				FromType: NodeTarget,
+				FromID:   t.Label,
+				ToType:   NodeTarget,
+				ToID:     dep,
+				Relation: RelationDependsOn,
+			})
+		}
+	}
+	return edges
+}
+
+// labels returns target labels in a stable order
+func (g *Graph) labels() []string {
+	labels := make([]string, 0, len(g.Targets))
+	for label := range g.Targets {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func formatLabel(pkg, name string) string {
+	return "//" + pkg + ":" + name
+}
+
+func joinPkg(pkg, rel string) string {
+	if pkg == "" {
+		return rel
+	}
+	return pkg + "/" + rel
+}
+
+func dedupe(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var out []string
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package buildgraph
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+	return root
+}
+
+func TestIngestBazel(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"WORKSPACE": "",
+		"server/BUILD.bazel": `
+load("@io_bazel_rules_go//go:def.bzl", "go_library")
+
+# The HTTP server
+go_library(
+    name = "server",
+    srcs = glob(["*.go"], exclude = ["*_test.go"]),
+    deps = [
+        "//lib/auth",
+        "//lib/db:db",
+        ":handlers",
+        "@com_github_pkg_errors//:errors",
+    ] + select({
+        "//conditions:default": [],
+    }),
+)
+
+go_library(
+    name = "handlers",
+    srcs = ["handlers/routes.go"],
+)
+`,
+		"server/main.go":            "package main",
+		"server/main_test.go":       "package main",
+		"server/handlers/routes.go": "package handlers",
+		"lib/auth/BUILD":            `go_library(name = "auth", srcs = ["auth.go"], deps = ["//lib/db"])`,
+		"lib/auth/auth.go":          "package auth",
+		"lib/db/BUILD":              `go_library(name = "db", srcs = ["db.go"])`,
+		"lib/db/db.go":              "package db",
+	})
+
+	g, err := Ingest(root)
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+	if g == nil || g.Tool != "bazel" {
+		t.Fatalf("Expected a bazel graph, got %+v", g)
+	}
+
+	server := g.Targets["//server:server"]
+	if server == nil {
+		t.Fatalf("Missing //server:server, got %v", g.labels())
+	}
+	if want := []string{"server/main.go"}; !reflect.DeepEqual(server.Srcs, want) {
+		t.Errorf("srcs = %v, want %v", server.Srcs, want)
+	}
+	if want := []string{"//lib/auth:auth", "//lib/db:db", "//server:handlers"}; !reflect.DeepEqual(server.Deps, want) {
+		t.Errorf("deps = %v, want %v", server.Deps, want)
+	}
+
+	var dependsOn, builtBy int
+	for _, e := range g.Edges() {
+		switch e.Relation {
+		case RelationDependsOn:
+			dependsOn++
+		case RelationBuiltBy:
+			builtBy++
+		}
+	}
+	if dependsOn != 4 || builtBy != 4 {
+		t.Errorf("Expected 4 depends_on and 4 built_by edges, got %d and %d", dependsOn, builtBy)
+	}
+}
+
+func TestIngestPants(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"pants.toml":            "",
+		"src/app/BUILD":         "python_sources(dependencies=['src/lib', 'src/util/strings.py'])\npython_tests(name='tests')",
+		"src/app/main.py":       "",
+		"src/app/test_main.py":  "",
+		"src/lib/BUILD":         "python_sources()",
+		"src/lib/core.py":       "",
+		"src/util/BUILD":        "python_sources(name='util')",
+		"src/util/strings.py":   "",
+		"3rdparty/python/BUILD": "python_requirements()",
+	})
+
+	g, err := Ingest(root)
+	if err != nil {
+		t.Fatalf("Ingest failed: %v", err)
+	}
+
+	app := g.Targets["//src/app:app"]
+	if app == nil {
+		t.Fatalf("Missing //src/app:app, got %v", g.labels())
+	}
+	if want := []string{"src/app/main.py"}; !reflect.DeepEqual(app.Srcs, want) {
+		t.Errorf("srcs = %v, want %v", app.Srcs, want)
+	}
+	if want := []string{"//src/lib:lib", "//src/util:util"}; !reflect.DeepEqual(app.Deps, want) {
+		t.Errorf("deps = %v, want %v", app.Deps, want)
+	}
+	if tests := g.Targets["//src/app:tests"]; tests == nil || !reflect.DeepEqual(tests.Srcs, []string{"src/app/test_main.py"}) {
+		t.Errorf("Expected tests target owning test_main.py, got %+v", tests)
+	}
+}
+
+func TestIngestWithoutBuildTool(t *testing.T) {
+	root := writeTree(t, map[string]string{"BUILD": `go_library(name = "x")`})
+
+	g, err := Ingest(root)
+	if err != nil || g != nil {
+		t.Errorf("Expected no graph without WORKSPACE or pants.toml, got %+v, %v", g, err)
+	}
+}
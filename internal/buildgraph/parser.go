@@ -0,0 +1,349 @@
+package buildgraph
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// call is a top-level rule invocation in a BUILD file, e.g.
+// go_library(name = "x", srcs = [...], deps = [...])
+type call struct {
+	Name   string
+	Line   int
+	Kwargs map[string]value
+	Args   []value
+}
+
+// value is the subset of Starlark values that matter for the build graph:
+// strings, lists (also dict values and concatenations) and nested calls
+// such as glob() or select().
+type value struct {
+	Str    *string
+	List   []value
+	Call   *call
+	IsDict bool
+}
+
+// strings flattens a value to every string it contains, following lists,
+// concatenations, dict values and select() branches. glob() calls are left
+// to the caller since they need the package directory.
+func (v value) strings() []string {
+	switch {
+	case v.Str != nil:
+		return []string{*v.Str}
+	case v.Call != nil:
+		if v.Call.Name == "select" {
+			var out []string
+			for _, a := range v.Call.Args {
+				out = append(out, a.strings()...)
+			}
+			return out
+		}
+		return nil
+	}
+	var out []string
+	for _, item := range v.List {
+		out = append(out, item.strings()...)
+	}
+	return out
+}
+
+// token kinds produced by the lexer
+const (
+	tokIdent = iota
+	tokString
+	tokNumber
+	tokPunct
+	tokEOF
+)
+
+type token struct {
+	kind int
+	text string
+	line int
+}
+
+// lex splits a BUILD file into identifiers, strings, numbers and single
+// punctuation characters. Comments are dropped.
+func lex(src string) []token {
+	var toks []token
+	line := 1
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\\':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			start := line
+			text, n := lexString(src[i:])
+			line += strings.Count(src[i:i+n], "\n")
+			toks = append(toks, token{tokString, text, start})
+			i += n
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] == '.' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			// String prefixes: r"...", b"..."
+			if j < len(src) && (src[j] == '"' || src[j] == '\'') && j-i == 1 && strings.ContainsAny(src[i:j], "rRbB") {
+				i = j
+				continue
+			}
+			toks = append(toks, token{tokIdent, src[i:j], line})
+			i = j
+		case unicode.IsDigit(rune(c)):
+			j := i
+			for j < len(src) && (unicode.IsDigit(rune(src[j])) || src[j] == '.' || src[j] == 'x') {
+				j++
+			}
+			toks = append(toks, token{tokNumber, src[i:j], line})
+			i = j
+		default:
+			toks = append(toks, token{tokPunct, string(c), line})
+			i++
+		}
+	}
+	return append(toks, token{tokEOF, "", line})
+}
+
+// lexString reads a single, double or triple quoted string at the start of
+// s and returns its contents and the number of bytes consumed.
+func lexString(s string) (string, int) {
+	quote := s[:1]
+	if strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''") {
+		quote = s[:3]
+	}
+	var b strings.Builder
+	i := len(quote)
+	for i < len(s) {
+		if strings.HasPrefix(s[i:], quote) {
+			return b.String(), i + len(quote)
+		}
+		if s[i] == '\\' && i+1 < len(s) {
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		}
+		if len(quote) == 1 && s[i] == '\n' {
+			break // unterminated
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String(), i
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+// parseBuildFile returns the top-level calls of a BUILD file. Statements
+// other than calls (load, assignments, defs) are skipped.
+func parseBuildFile(src string) ([]call, error) {
+	p := &parser{toks: lex(src)}
+	var calls []call
+	prev := ""
+	for p.peek().kind != tokEOF {
+		t := p.next()
+		if t.kind != tokIdent || !p.isPunct("(") {
+			if t.kind == tokPunct && (t.text == "(" || t.text == "[" || t.text == "{") {
+				p.skipBalanced(t.text)
+			}
+			prev = t.text
+			continue
+		}
+		p.next() // (
+		c, err := p.parseCallArgs(t.text, t.line)
+		if err != nil {
+			return calls, err
+		}
+		// Macro definitions look like calls but declare nothing
+		if prev != "def" && t.text != "load" && t.text != "package" {
+			calls = append(calls, c)
+		}
+		prev = ""
+	}
+	return calls, nil
+}
+
+// parseCallArgs parses arguments up to and including the closing paren
+func (p *parser) parseCallArgs(name string, line int) (call, error) {
+	c := call{Name: name, Line: line, Kwargs: make(map[string]value)}
+	for {
+		if p.isPunct(")") {
+			p.next()
+			return c, nil
+		}
+		if p.peek().kind == tokEOF {
+			return c, fmt.Errorf("line %d: unterminated call to %s", line, name)
+		}
+		if p.isPunct(",") {
+			p.next()
+			continue
+		}
+		// Keyword argument?
+		if t := p.peek(); t.kind == tokIdent && p.toks[p.pos+1].kind == tokPunct && p.toks[p.pos+1].text == "=" {
+			p.next()
+			p.next()
+			c.Kwargs[t.text] = p.parseExpr()
+			continue
+		}
+		c.Args = append(c.Args, p.parseExpr())
+	}
+}
+
+// parseExpr parses a value and any "+" concatenations that follow it
+func (p *parser) parseExpr() value {
+	v := p.parseOperand()
+	if !p.isPunct("+") {
+		return v
+	}
+	parts := []value{v}
+	for p.isPunct("+") {
+		p.next()
+		parts = append(parts, p.parseOperand())
+	}
+	return value{List: parts}
+}
+
+func (p *parser) parseOperand() value {
+	t := p.next()
+	switch {
+	case t.kind == tokString:
+		s := t.text
+		// Adjacent string literals concatenate
+		for p.peek().kind == tokString {
+			s += p.next().text
+		}
+		return value{Str: &s}
+	case t.kind == tokIdent && p.isPunct("("):
+		p.next()
+		c, _ := p.parseCallArgs(t.text, t.line)
+		return value{Call: &c}
+	case t.kind == tokPunct && t.text == "[":
+		return value{List: p.parseSequence("]")}
+	case t.kind == tokPunct && t.text == "(":
+		return value{List: p.parseSequence(")")}
+	case t.kind == tokPunct && t.text == "{":
+		return p.parseDict()
+	case t.kind == tokPunct && t.text == "-":
+		return p.parseOperand()
+	}
+	// Identifiers, numbers, comprehensions: nothing usable
+	p.skipTrailer()
+	return value{}
+}
+
+// parseSequence parses list or tuple items up to the closing bracket.
+// Comprehensions are skipped.
+func (p *parser) parseSequence(closing string) []value {
+	var items []value
+	for {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return items
+		}
+		if t.kind == tokPunct && t.text == closing {
+			p.next()
+			return items
+		}
+		if t.kind == tokPunct && t.text == "," {
+			p.next()
+			continue
+		}
+		if t.kind == tokIdent && t.text == "for" {
+			p.skipUntil(closing)
+			return items
+		}
+		before := p.pos
+		items = append(items, p.parseExpr())
+		if p.pos == before {
+			p.next()
+		}
+	}
+}
+
+// parseDict keeps only the values; select() branches are unioned
+func (p *parser) parseDict() value {
+	v := value{IsDict: true}
+	for {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return v
+		}
+		if t.kind == tokPunct && t.text == "}" {
+			p.next()
+			return v
+		}
+		if t.kind == tokPunct && (t.text == "," || t.text == ":") {
+			p.next()
+			continue
+		}
+		p.parseExpr() // key
+		if p.isPunct(":") {
+			p.next()
+			v.List = append(v.List, p.parseExpr())
+		}
+	}
+}
+
+// skipTrailer skips attribute access, indexing and calls after an
+// identifier we don't evaluate (e.g. CONSTANT[0] or ctx.attr(x))
+func (p *parser) skipTrailer() {
+	for p.isPunct("(") || p.isPunct("[") {
+		p.skipBalanced(p.next().text)
+	}
+}
+
+// skipBalanced skips past the bracket matching an already consumed opener
+func (p *parser) skipBalanced(open string) {
+	closing := map[string]string{"(": ")", "[": "]", "{": "}"}[open]
+	p.skipUntil(closing)
+}
+
+func (p *parser) skipUntil(closing string) {
+	depth := 0
+	for {
+		t := p.next()
+		if t.kind == tokEOF {
+			return
+		}
+		if t.kind != tokPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 && t.text == closing {
+				return
+			}
+			depth--
+		}
+	}
+}
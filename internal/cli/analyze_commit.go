@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/saeedalam/teamcontext/internal/storage"
@@ -37,6 +38,12 @@ It inspects the commit diff and message to detect and auto-capture:
     - Config file changes
     - TODO/FIXME/HACK additions
 
+  DECISION PROMPTS:
+    - Commits touching architecture paths (adr/, architecture/, infra/,
+      migrations/, *.proto, openapi specs, ...) or mentioning "decision",
+      "adr" or "architecture" without a "Decision:" line queue an
+      add_decision suggestion, pre-filled from the commit message and diff
+      summary, for the next MCP tool response
 Use --quiet to suppress output (recommended for git hooks).
 
 Examples:
@@ -82,6 +89,7 @@ func runAnalyzeCommit(cmd *cobra.Command, args []string) {
 	// =========================================================================
 	// AUTO-EXTRACT DECISIONS FROM COMMIT MESSAGE
 	// =========================================================================
+	decisionsCaptured := 0
 	decisions := extractDecisionsFromMessage(commitMsg)
 	for _, dec := range decisions {
 		decision := &types.Decision{
@@ -93,6 +101,7 @@ func runAnalyzeCommit(cmd *cobra.Command, args []string) {
 			Tags:     []string{"auto-captured", "git-commit"},
 		}
 		if err := jsonStore.AddDecision(decision); err == nil {
+			decisionsCaptured++
 			findings = append(findings, fmt.Sprintf("Decision captured: %s → %s", dec.title, decision.ID))
 		}
 	}
@@ -130,6 +139,7 @@ func runAnalyzeCommit(cmd *cobra.Command, args []string) {
 				Tags:    []string{"auto-captured", "pull-request"},
 			}
 			if err := jsonStore.AddDecision(decision); err == nil {
+				decisionsCaptured++
 				findings = append(findings, fmt.Sprintf("PR Decision: %s → %s", dec.title, decision.ID))
 			}
 		}
@@ -148,6 +158,19 @@ func runAnalyzeCommit(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// =========================================================================
+	// SUGGEST RECORDING A DECISION FOR ARCHITECTURE-RELEVANT COMMITS
+	// =========================================================================
+	changedFiles := extractChangedFiles(diffStat)
+	if decisionsCaptured == 0 {
+		if candidate := detectDecisionCandidate(commitMsg, changedFiles); candidate != nil {
+			hook := buildDecisionHook(commitHash, commitMsg, diffStat, candidate)
+			if err := jsonStore.AddPendingHook(hook); err == nil {
+				findings = append(findings, fmt.Sprintf("Decision suggested: %s", candidate.reason))
+			}
+		}
+	}
+
 	// =========================================================================
 	// EXISTING DETECTION LOGIC
 	// =========================================================================
@@ -189,7 +212,6 @@ func runAnalyzeCommit(cmd *cobra.Command, args []string) {
 		"requirements.txt", "Pipfile", "pyproject.toml", "Gemfile", "Gemfile.lock",
 		"pom.xml", "build.gradle", "composer.json", "pubspec.yaml",
 	}
-	changedFiles := extractChangedFiles(diffStat)
 	for _, cf := range changedFiles {
 		base := filepath.Base(cf)
 		for _, df := range depFiles {
@@ -291,6 +313,144 @@ func extractChangedFiles(diffStat string) []string {
 	return files
 }
 
+// architecturePathMarkers identify directories whose changes usually
+// reflect an architectural choice worth recording.
+var architecturePathMarkers = []string{
+	"adr/", "adrs/", "architecture/", "decisions/", "rfcs/", "design/",
+	"infra/", "infrastructure/", "terraform/", "helm/", "k8s/",
+	"migrations/", "proto/",
+}
+
+// architectureFileMarkers identify individual files that define
+// architecture: service contracts, API specs and architecture docs.
+var architectureFileMarkers = []string{
+	".proto", "openapi", "swagger", "architecture.md", ".graphql",
+}
+
+// decisionKeywordPattern matches commit messages that talk about a decision
+var decisionKeywordPattern = regexp.MustCompile(`(?i)\b(decision|decided|adr|architecture|architectural)\b`)
+
+type decisionCandidate struct {
+	reason string
+	files  []string
+	tags   []string
+}
+
+// detectDecisionCandidate reports whether a commit likely embodies an
+// architectural decision, based on the paths it touches and the keywords
+// in its message. It returns nil for ordinary commits.
+func detectDecisionCandidate(commitMsg string, changedFiles []string) *decisionCandidate {
+	var archFiles []string
+	for _, f := range changedFiles {
+		lower := strings.ToLower(filepath.ToSlash(f))
+		matched := false
+		for _, marker := range architecturePathMarkers {
+			if strings.HasPrefix(lower, marker) || strings.Contains(lower, "/"+marker) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			base := filepath.Base(lower)
+			for _, marker := range architectureFileMarkers {
+				if strings.Contains(base, marker) {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			archFiles = append(archFiles, f)
+		}
+	}
+
+	keyword := decisionKeywordPattern.FindString(commitMsg)
+
+	switch {
+	case len(archFiles) > 0 && keyword != "":
+		return &decisionCandidate{
+			reason: fmt.Sprintf("commit mentions %q and touches %d architecture file(s)", strings.ToLower(keyword), len(archFiles)),
+			files:  archFiles,
+			tags:   []string{"architecture", "adr"},
+		}
+	case len(archFiles) > 0:
+		return &decisionCandidate{
+			reason: fmt.Sprintf("commit touches %d architecture file(s)", len(archFiles)),
+			files:  archFiles,
+			tags:   []string{"architecture"},
+		}
+	case keyword != "":
+		return &decisionCandidate{
+			reason: fmt.Sprintf("commit message mentions %q", strings.ToLower(keyword)),
+			files:  changedFiles,
+			tags:   []string{"adr"},
+		}
+	}
+	return nil
+}
+
+// buildDecisionHook turns a decision candidate into an add_decision hook
+// pre-filled with the commit subject, body and diff summary.
+func buildDecisionHook(commitHash, commitMsg, diffStat string, candidate *decisionCandidate) *types.PendingHook {
+	subject, body := splitCommitMessage(commitMsg)
+
+	context := fmt.Sprintf("Commit %s", commitHash[:min(len(commitHash), 8)])
+	if summary := diffSummary(diffStat); summary != "" {
+		context += " (" + summary + ")"
+	}
+	if body != "" {
+		context += ": " + body
+	}
+
+	files := candidate.files
+	if len(files) > 10 {
+		files = files[:10]
+	}
+
+	return &types.PendingHook{
+		ConversationHook: types.ConversationHook{
+			Action:   types.HookActionAddDecision,
+			Tool:     "add_decision",
+			Priority: types.HookPrioritySuggested,
+			Reason:   fmt.Sprintf("Commit %s looks like an architectural decision (%s). Ask the user whether to record it.", commitHash[:min(len(commitHash), 8)], candidate.reason),
+			Arguments: map[string]interface{}{
+				"content":       subject,
+				"reason":        "",
+				"context":       context,
+				"related_files": files,
+				"tags":          append([]string{"git-commit"}, candidate.tags...),
+			},
+			Condition: "Fill in 'reason' with the user's explanation; skip if the commit is not a real decision",
+		},
+		Source: "post-commit",
+	}
+}
+
+// splitCommitMessage returns the subject line and the body joined into a
+// single line.
+func splitCommitMessage(commitMsg string) (string, string) {
+	lines := strings.Split(strings.TrimSpace(commitMsg), "\n")
+	subject := strings.TrimSpace(lines[0])
+	var body []string
+	for _, line := range lines[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			body = append(body, line)
+		}
+	}
+	return subject, strings.Join(body, " ")
+}
+
+// diffSummary returns the "N files changed, X insertions(+), Y deletions(-)"
+// line from a diff --stat output.
+func diffSummary(diffStat string) string {
+	lines := strings.Split(strings.TrimSpace(diffStat), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if strings.Contains(last, "changed") {
+		return last
+	}
+	return ""
+}
+
 func assessImpact(findings []string) string {
 	for _, f := range findings {
 		if strings.Contains(f, "Revert") || strings.Contains(f, "Large deletion") {
@@ -455,3 +455,90 @@ func TestAssessImpact(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectDecisionCandidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       string
+		files     []string
+		expectNil bool
+		expectTag string
+	}{
+		{
+			name:      "ADR document added",
+			msg:       "Add record for event sourcing",
+			files:     []string{"docs/adr/0007-event-sourcing.md"},
+			expectTag: "architecture",
+		},
+		{
+			name:      "Proto contract changed",
+			msg:       "Add cancel RPC",
+			files:     []string{"api/orders.proto"},
+			expectTag: "architecture",
+		},
+		{
+			name:      "Keyword in message only",
+			msg:       "Move caching to Redis\n\nDecision made after load testing.",
+			files:     []string{"src/cache/store.ts"},
+			expectTag: "adr",
+		},
+		{
+			name:      "Ordinary commit",
+			msg:       "Fix typo in login error message",
+			files:     []string{"src/auth/login.ts"},
+			expectNil: true,
+		},
+		{
+			name:      "Keyword inside another word",
+			msg:       "Validate email address format",
+			files:     []string{"src/users/validate.ts"},
+			expectNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detectDecisionCandidate(tt.msg, tt.files)
+			if tt.expectNil {
+				if result != nil {
+					t.Errorf("Expected no candidate, got %q", result.reason)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatal("Expected a decision candidate, got nil")
+			}
+			found := false
+			for _, tag := range result.tags {
+				if tag == tt.expectTag {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected tag '%s', got %v", tt.expectTag, result.tags)
+			}
+		})
+	}
+}
+
+func TestBuildDecisionHook(t *testing.T) {
+	diffStat := ` migrations/0042_orders.sql | 40 ++++
+ 1 file changed, 40 insertions(+)`
+	candidate := detectDecisionCandidate("Split orders table\n\nKeeps archive rows out of hot path.", extractChangedFiles(diffStat))
+	if candidate == nil {
+		t.Fatal("Expected a decision candidate for a migration")
+	}
+
+	hook := buildDecisionHook("abcdef1234567890", "Split orders table\n\nKeeps archive rows out of hot path.", diffStat, candidate)
+
+	if hook.Tool != "add_decision" {
+		t.Errorf("Expected tool 'add_decision', got '%s'", hook.Tool)
+	}
+	if hook.Arguments["content"] != "Split orders table" {
+		t.Errorf("Expected content from commit subject, got '%v'", hook.Arguments["content"])
+	}
+	expectedContext := "Commit abcdef12 (1 file changed, 40 insertions(+)): Keeps archive rows out of hot path."
+	if hook.Arguments["context"] != expectedContext {
+		t.Errorf("Expected context '%s', got '%v'", expectedContext, hook.Arguments["context"])
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// maxAuditListed caps how many edges are printed per problem kind
+const maxAuditListed = 20
+
+var auditFix bool
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check stored knowledge for inconsistencies",
+}
+
+var auditGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Report invalid, duplicate and circular knowledge graph edges",
+	Long: `Check every edge in knowledge/graph.json and report:
+- Invalid edges: self-loops, missing ends, unknown relations, or a relation
+  between node types it cannot connect (e.g. a decision that "warns" a file)
+- Duplicate edges
+- Supersedes cycles, where a decision ends up superseding itself
+
+With --fix the reported edges are removed. New edges are validated when
+they are added, so problems come from older versions or hand edits.
+
+Example:
+  teamcontext audit graph
+  teamcontext audit graph --fix`,
+	Run: runAuditGraph,
+}
+
+func runAuditGraph(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	audit, err := jsonStore.AuditGraph(auditFix)
+	if err != nil {
+		fmt.Printf("Error auditing graph: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Checked %d edges\n", audit.TotalEdges)
+	if audit.Problems() == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+
+	if len(audit.Invalid) > 0 {
+		fmt.Printf("\nInvalid edges (%d):\n", len(audit.Invalid))
+		for i, issue := range audit.Invalid {
+			if i == maxAuditListed {
+				fmt.Printf("  ... and %d more\n", len(audit.Invalid)-maxAuditListed)
+				break
+			}
+			fmt.Printf("  %s — %s\n", formatEdge(issue.Edge), issue.Reason)
+		}
+	}
+	if len(audit.Duplicates) > 0 {
+		fmt.Printf("\nDuplicate edges (%d):\n", len(audit.Duplicates))
+		for i, e := range audit.Duplicates {
+			if i == maxAuditListed {
+				fmt.Printf("  ... and %d more\n", len(audit.Duplicates)-maxAuditListed)
+				break
+			}
+			fmt.Printf("  %s\n", formatEdge(e))
+		}
+	}
+	if len(audit.Cycles) > 0 {
+		fmt.Printf("\nSupersedes cycles (%d):\n", len(audit.Cycles))
+		for _, issue := range audit.Cycles {
+			fmt.Printf("  %s — %s\n", formatEdge(issue.Edge), issue.Reason)
+		}
+	}
+
+	fmt.Println()
+	if audit.Fixed {
+		fmt.Printf("Removed %d edges.\n", audit.Problems())
+	} else {
+		fmt.Printf("%d edges to remove. Run 'teamcontext audit graph --fix' to remove them.\n", audit.Problems())
+	}
+}
+
+func formatEdge(e types.Edge) string {
+	return fmt.Sprintf("%s:%s -[%s]-> %s:%s", e.FromType, e.FromID, e.Relation, e.ToType, e.ToID)
+}
+
+func init() {
+	auditGraphCmd.Flags().BoolVar(&auditFix, "fix", false, "Remove the reported edges")
+	auditCmd.AddCommand(auditGraphCmd)
+	rootCmd.AddCommand(auditCmd)
+}
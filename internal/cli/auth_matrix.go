@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/extractor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authMatrixApp       string
+	authMatrixUnguarded bool
+	authMatrixOutput    string
+	authMatrixStrict    bool
+)
+
+var authMatrixCmd = &cobra.Command{
+	Use:   "auth-matrix [path]",
+	Short: "Report the authentication and authorization on every endpoint",
+	Long: `Extract the REST endpoints under path (default: current directory) and
+list the guards, middleware and decorators that authenticate and authorize
+each one. Endpoints with no check are flagged as unguarded; endpoints opened
+on purpose (@Public(), [AllowAnonymous], @PermitAll, AllowAny) are public.
+
+Checks come from the handler, its controller, its router or group
+middleware, and app-wide guards (APP_GUARD, useGlobalGuards, Spring
+Security anyRequest(), ASP.NET FallbackPolicy). Middleware mounted on a
+router from another file is not seen, so review unguarded endpoints before
+fixing them.
+
+Example:
+  teamcontext auth-matrix apps/billing
+  teamcontext auth-matrix --unguarded
+  teamcontext auth-matrix -o docs/security/auth-matrix.md
+  teamcontext auth-matrix --strict   # exit 1 when an endpoint is unguarded (CI)`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runAuthMatrix,
+}
+
+func runAuthMatrix(cmd *cobra.Command, args []string) {
+	path := "."
+	if len(args) == 1 {
+		path = args[0]
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	app := authMatrixApp
+	if app == "" {
+		app = filepath.Base(absPath)
+	}
+	surface, err := extractor.ExtractAPISurface(absPath, app)
+	if err != nil {
+		fmt.Printf("Error extracting API surface: %v\n", err)
+		return
+	}
+	if len(surface.Endpoints) == 0 {
+		fmt.Printf("No REST endpoints found under %s\n", path)
+		return
+	}
+	matrix := extractor.BuildAuthMatrix(surface, extractor.AuthMatrixOptions{Dir: absPath, Root: absPath})
+
+	if authMatrixOutput != "" {
+		if err := os.MkdirAll(filepath.Dir(authMatrixOutput), 0755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if err := os.WriteFile(authMatrixOutput, []byte(extractor.FormatAuthMatrixMarkdown(matrix)), 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", authMatrixOutput, err)
+			return
+		}
+		fmt.Printf("Wrote auth matrix for %d endpoints to %s\n", len(matrix.Endpoints), authMatrixOutput)
+	} else {
+		printAuthMatrix(matrix, authMatrixUnguarded)
+	}
+
+	fmt.Printf("\n%d endpoints: %d guarded, %d public, %d unguarded\n", len(matrix.Endpoints), matrix.Guarded, matrix.Public, matrix.Unguarded)
+	if authMatrixStrict && matrix.Unguarded > 0 {
+		os.Exit(1)
+	}
+}
+
+func printAuthMatrix(matrix *extractor.AuthMatrix, unguardedOnly bool) {
+	if len(matrix.Global) > 0 {
+		fmt.Printf("Global: %s\n\n", strings.Join(matrix.Global, ", "))
+	}
+	for _, e := range matrix.Endpoints {
+		if unguardedOnly && e.Status != extractor.AuthUnguarded {
+			continue
+		}
+		status := "  "
+		checks := strings.Join(append(append([]string{}, e.Authentication...), e.Authorization...), ", ")
+		switch e.Status {
+		case extractor.AuthUnguarded:
+			status = "!!"
+			checks = "UNGUARDED"
+		case extractor.AuthPublic:
+			checks = "public: " + e.Public
+		}
+		fmt.Printf("%s %-6s %-40s %s  (%s:%d)\n", status, e.Method, e.Path, checks, e.File, e.Line)
+	}
+}
+
+func init() {
+	authMatrixCmd.Flags().StringVar(&authMatrixApp, "app", "", "Report title (default: directory name)")
+	authMatrixCmd.Flags().BoolVar(&authMatrixUnguarded, "unguarded", false, "Only list unguarded endpoints")
+	authMatrixCmd.Flags().StringVarP(&authMatrixOutput, "output", "o", "", "Write the matrix as a markdown table to this file")
+	authMatrixCmd.Flags().BoolVar(&authMatrixStrict, "strict", false, "Exit with status 1 when an endpoint is unguarded")
+	rootCmd.AddCommand(authMatrixCmd)
+}
@@ -0,0 +1,560 @@
+package cli
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/internal/worker"
+	"github.com/saeedalam/teamcontext/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark the indexing pipeline",
+	Long: `Benchmark the indexing pipeline and print a JSON report.
+
+Measures:
+- Indexing throughput (files/sec) of a full project index
+- Skeleton parse time per language
+- SQLite write latency (single writes and batched transactions)
+- Search latency for file and code-content queries
+
+By default the current repository is benchmarked. The index is built in a
+temporary directory, so the project's .teamcontext is never touched. Use
+--synthetic to generate a reproducible corpus instead, which makes reports
+comparable across machines and releases.
+
+Pass --baseline with an earlier report to flag metrics that regressed by
+more than --threshold percent; the command then exits with status 1.
+The temporary index is removed whether the run passes or fails.
+
+Example:
+  teamcontext bench
+  teamcontext bench --synthetic 2000 --output bench.json
+  teamcontext bench --synthetic 2000 --baseline bench-v1.2.json`,
+	RunE:          runBench,
+	SilenceUsage:  true,
+	SilenceErrors: true, // Execute prints the error
+}
+
+var (
+	benchSynthetic  int
+	benchOutput     string
+	benchIterations int
+	benchBaseline   string
+	benchThreshold  float64
+)
+
+func init() {
+	benchCmd.Flags().IntVar(&benchSynthetic, "synthetic", 0, "Generate a synthetic corpus with this many files instead of using the current repo")
+	benchCmd.Flags().StringVarP(&benchOutput, "output", "o", "", "Write the JSON report to this file")
+	benchCmd.Flags().IntVar(&benchIterations, "iterations", 20, "Repetitions per search query and write samples per 100")
+	benchCmd.Flags().StringVar(&benchBaseline, "baseline", "", "Earlier report to compare against")
+	benchCmd.Flags().Float64Var(&benchThreshold, "threshold", 20, "Regression threshold in percent for --baseline")
+	rootCmd.AddCommand(benchCmd)
+}
+
+// maxParseSamplesPerLanguage bounds skeleton timing on large repos
+const maxParseSamplesPerLanguage = 300
+
+// BenchReport is the JSON output of `teamcontext bench`
+type BenchReport struct {
+	Version   string                  `json:"version"`
+	Commit    string                  `json:"commit"`
+	GoVersion string                  `json:"go_version"`
+	Platform  string                  `json:"platform"`
+	CPUs      int                     `json:"cpus"`
+	Source    string                  `json:"source"` // repo or synthetic
+	Files     int                     `json:"files"`
+	StartedAt time.Time               `json:"started_at"`
+	Indexing  BenchIndexing           `json:"indexing"`
+	Skeleton  map[string]BenchLatency `json:"skeleton_parse"` // by language
+	SQLite    map[string]BenchLatency `json:"sqlite_write"`   // single, batch_per_file
+	Search    map[string]BenchLatency `json:"search"`         // files, code_content
+	Errors    []string                `json:"errors,omitempty"`
+}
+
+// BenchIndexing is the throughput of a full project index
+type BenchIndexing struct {
+	Files       int     `json:"files"`
+	Seconds     float64 `json:"seconds"`
+	FilesPerSec float64 `json:"files_per_sec"`
+}
+
+// BenchLatency summarizes samples in milliseconds
+type BenchLatency struct {
+	Samples int     `json:"samples"`
+	AvgMs   float64 `json:"avg_ms"`
+	P50Ms   float64 `json:"p50_ms"`
+	P95Ms   float64 `json:"p95_ms"`
+	MaxMs   float64 `json:"max_ms"`
+}
+
+// newBenchReport returns an empty report describing this build and machine
+func newBenchReport() *BenchReport {
+	return &BenchReport{
+		Version:   buildVersion,
+		Commit:    buildCommit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		StartedAt: time.Now(),
+		Skeleton:  make(map[string]BenchLatency),
+		SQLite:    make(map[string]BenchLatency),
+		Search:    make(map[string]BenchLatency),
+	}
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchIterations <= 0 {
+		benchIterations = 1
+	}
+
+	report := newBenchReport()
+
+	// Corpus: the current repo, or a generated one
+	projectRoot := ""
+	if benchSynthetic > 0 {
+		dir, err := os.MkdirTemp("", "teamcontext-bench-*")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		if err := writeSyntheticCorpus(dir, benchSynthetic); err != nil {
+			return fmt.Errorf("generating corpus: %w", err)
+		}
+		projectRoot = dir
+		report.Source = "synthetic"
+	} else {
+		if root, err := findGitRoot(); err == nil {
+			projectRoot = root
+		} else if cwd, err := os.Getwd(); err == nil {
+			projectRoot = cwd
+		}
+		report.Source = "repo"
+	}
+
+	return benchProject(projectRoot, report)
+}
+
+// benchProject indexes projectRoot into a throwaway directory, fills the
+// report and writes it out. It returns an error when the run failed or
+// regressed against --baseline; the throwaway index is removed either way.
+func benchProject(projectRoot string, report *BenchReport) error {
+	// The index lives in a throwaway directory inside the project, since
+	// the worker treats the index's parent as the project root
+	tcDir, err := os.MkdirTemp(projectRoot, ".teamcontext-bench-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tcDir)
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	sqliteIndex, err := storage.NewSQLiteIndex(tcDir)
+	if err != nil {
+		return fmt.Errorf("opening index: %w", err)
+	}
+	defer sqliteIndex.Close()
+
+	// 1. Indexing throughput
+	fmt.Fprintf(os.Stderr, "Benchmarking indexing of %s...\n", projectRoot)
+	workerMgr := worker.NewManager(tcDir, jsonStore, sqliteIndex)
+	start := time.Now()
+	indexed, err := workerMgr.InitProject()
+	elapsed := time.Since(start).Seconds()
+	if err != nil {
+		report.Errors = append(report.Errors, "indexing: "+err.Error())
+	}
+	report.Files = indexed
+	report.Indexing = BenchIndexing{Files: indexed, Seconds: round3(elapsed)}
+	if elapsed > 0 {
+		report.Indexing.FilesPerSec = round3(float64(indexed) / elapsed)
+	}
+
+	files, _ := jsonStore.GetFilesIndex()
+
+	// 2. Skeleton parse time per language
+	fmt.Fprintf(os.Stderr, "Benchmarking skeleton parsing...\n")
+	byLanguage := make(map[string][]float64)
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fi := files[path]
+		if fi.Language == "" || len(byLanguage[fi.Language]) >= maxParseSamplesPerLanguage {
+			continue
+		}
+		abs := filepath.Join(projectRoot, path)
+		t := time.Now()
+		if _, err := skeleton.ParseFile(abs); err != nil {
+			continue
+		}
+		byLanguage[fi.Language] = append(byLanguage[fi.Language], msSince(t))
+	}
+	for lang, samples := range byLanguage {
+		report.Skeleton[lang] = summarizeLatency(samples)
+	}
+
+	// 3. SQLite write latency, in a separate database so the indexed
+	// corpus doesn't skew search numbers
+	fmt.Fprintf(os.Stderr, "Benchmarking SQLite writes...\n")
+	writeDir, err := os.MkdirTemp("", "teamcontext-bench-db-*")
+	if err == nil {
+		defer os.RemoveAll(writeDir)
+		if writeIndex, err := storage.NewSQLiteIndex(writeDir); err == nil {
+			single, batch := benchSQLiteWrites(writeIndex, benchIterations*100)
+			writeIndex.Close()
+			report.SQLite["single"] = summarizeLatency(single)
+			report.SQLite["batch_per_file"] = summarizeLatency(batch)
+		} else {
+			report.Errors = append(report.Errors, "sqlite: "+err.Error())
+		}
+	}
+
+	// 4. Search latency over the indexed corpus
+	fmt.Fprintf(os.Stderr, "Benchmarking search...\n")
+	queries := benchQueries(files)
+	var fileSearch, contentSearch []float64
+	for i := 0; i < benchIterations; i++ {
+		for _, q := range queries {
+			t := time.Now()
+			sqliteIndex.SearchFiles(q, "", 20)
+			fileSearch = append(fileSearch, msSince(t))
+
+			t = time.Now()
+			sqliteIndex.SearchCodeContent(q, "", 20)
+			contentSearch = append(contentSearch, msSince(t))
+		}
+	}
+	report.Search["files"] = summarizeLatency(fileSearch)
+	report.Search["code_content"] = summarizeLatency(contentSearch)
+
+	data, _ := json.MarshalIndent(report, "", "  ")
+	if benchOutput != "" {
+		if err := os.WriteFile(benchOutput, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", benchOutput)
+	} else {
+		fmt.Println(string(data))
+	}
+
+	if benchBaseline != "" {
+		regressions, err := compareBench(benchBaseline, report, benchThreshold)
+		if err != nil {
+			return fmt.Errorf("reading baseline: %w", err)
+		}
+		if len(regressions) > 0 {
+			fmt.Fprintf(os.Stderr, "\n%d regression(s) over %.0f%%:\n", len(regressions), benchThreshold)
+			for _, r := range regressions {
+				fmt.Fprintf(os.Stderr, "  - %s\n", r)
+			}
+			return fmt.Errorf("%d regression(s) against %s", len(regressions), benchBaseline)
+		}
+		fmt.Fprintf(os.Stderr, "\nNo regressions over %.0f%% against %s\n", benchThreshold, benchBaseline)
+	}
+	return nil
+}
+
+// benchSQLiteWrites times n single-row writes, then n writes batched in
+// transactions of 100, returning per-file latencies for both.
+func benchSQLiteWrites(idx *storage.SQLiteIndex, n int) ([]float64, []float64) {
+	fileIndex := func(i int, prefix string) *types.FileIndex {
+		return &types.FileIndex{
+			Path:      fmt.Sprintf("%s/module%d/file%d.go", prefix, i/50, i),
+			Language:  "go",
+			Summary:   fmt.Sprintf("Handles request %d for the bench service", i),
+			Exports:   []types.Export{{Name: fmt.Sprintf("Handler%d", i), Kind: "function"}},
+			LineCount: 120,
+		}
+	}
+
+	var single []float64
+	for i := 0; i < n; i++ {
+		t := time.Now()
+		idx.IndexFile(fileIndex(i, "single"))
+		single = append(single, msSince(t))
+	}
+
+	var batch []float64
+	const batchSize = 100
+	for i := 0; i < n; i += batchSize {
+		size := batchSize
+		if i+size > n {
+			size = n - i
+		}
+		t := time.Now()
+		idx.WithTransaction(func(tx *sql.Tx) error {
+			for j := 0; j < size; j++ {
+				idx.IndexFileTx(tx, fileIndex(i+j, "batch"))
+			}
+			return nil
+		})
+		perFile := msSince(t) / float64(size)
+		for j := 0; j < size; j++ {
+			batch = append(batch, perFile)
+		}
+	}
+	return single, batch
+}
+
+// benchQueries picks search terms that exist in the corpus: the most common
+// exported names, plus a miss to measure the empty-result path.
+func benchQueries(files map[string]types.FileIndex) []string {
+	counts := make(map[string]int)
+	for _, fi := range files {
+		for _, e := range fi.Exports {
+			if len(e.Name) >= 4 {
+				counts[strings.ToLower(e.Name)]++
+			}
+		}
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > 5 {
+		names = names[:5]
+	}
+	return append(names, "handler", "config", "zzqxnomatch")
+}
+
+// compareBench reports metrics in the current report that are worse than
+// the baseline by more than threshold percent.
+func compareBench(baselinePath string, current *BenchReport, threshold float64) ([]string, error) {
+	data, err := os.ReadFile(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	var baseline BenchReport
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, err
+	}
+
+	var regressions []string
+	limit := 1 + threshold/100
+
+	if baseline.Indexing.FilesPerSec > 0 && current.Indexing.FilesPerSec*limit < baseline.Indexing.FilesPerSec {
+		regressions = append(regressions, fmt.Sprintf("indexing: %.1f files/sec (baseline %.1f)",
+			current.Indexing.FilesPerSec, baseline.Indexing.FilesPerSec))
+	}
+
+	// Latencies: compare p95, ignoring sub-millisecond noise
+	check := func(group string, base, cur map[string]BenchLatency) {
+		keys := make([]string, 0, len(cur))
+		for k := range cur {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b, ok := base[k]
+			if !ok || b.P95Ms <= 0 {
+				continue
+			}
+			c := cur[k]
+			if c.P95Ms > b.P95Ms*limit && c.P95Ms-b.P95Ms > 0.5 {
+				regressions = append(regressions, fmt.Sprintf("%s %s: p95 %.2fms (baseline %.2fms)", group, k, c.P95Ms, b.P95Ms))
+			}
+		}
+	}
+	check("skeleton_parse", baseline.Skeleton, current.Skeleton)
+	check("sqlite_write", baseline.SQLite, current.SQLite)
+	check("search", baseline.Search, current.Search)
+
+	if baseline.Source != current.Source || baseline.Files != current.Files {
+		fmt.Fprintf(os.Stderr, "Note: baseline corpus differs (%s, %d files) from this run (%s, %d files)\n",
+			baseline.Source, baseline.Files, current.Source, current.Files)
+	}
+	return regressions, nil
+}
+
+// syntheticTemplates are small but representative sources per language, so
+// parse and index numbers exercise each skeleton parser.
+var syntheticTemplates = map[string]string{
+	"go": `package mod%[1]d
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Service%[1]d handles requests for module %[1]d
+type Service%[1]d struct {
+	name  string
+	count int
+}
+
+// NewService%[1]d creates a service
+func NewService%[1]d(name string) *Service%[1]d {
+	return &Service%[1]d{name: name}
+}
+
+// Handle processes one request
+func (s *Service%[1]d) Handle(input string) (string, error) {
+	if input == "" {
+		return "", errors.New("empty input")
+	}
+	s.count++
+	return fmt.Sprintf("%%s:%%s", s.name, input), nil
+}
+`,
+	"ts": `import { Injectable } from './di';
+
+export interface Request%[1]d {
+  id: string;
+  payload: Record<string, unknown>;
+}
+
+@Injectable()
+export class Handler%[1]d {
+  private count = 0;
+
+  async handle(req: Request%[1]d): Promise<string> {
+    this.count++;
+    return req.id + ':' + this.count;
+  }
+}
+
+export function createHandler%[1]d(): Handler%[1]d {
+  return new Handler%[1]d();
+}
+`,
+	"py": `from dataclasses import dataclass
+
+
+@dataclass
+class Config%[1]d:
+    name: str
+    retries: int = 3
+
+
+class Worker%[1]d:
+    """Processes jobs for module %[1]d."""
+
+    def __init__(self, config: Config%[1]d):
+        self.config = config
+
+    def run(self, job: dict) -> str:
+        return f"{self.config.name}:{job.get('id')}"
+
+
+def build_worker_%[1]d(name: str) -> Worker%[1]d:
+    return Worker%[1]d(Config%[1]d(name=name))
+`,
+	"java": `package bench.mod%[1]d;
+
+import java.util.List;
+
+public class Repository%[1]d {
+    private final List<String> items;
+
+    public Repository%[1]d(List<String> items) {
+        this.items = items;
+    }
+
+    public String find(int index) {
+        return items.get(index);
+    }
+
+    public int size() {
+        return items.size();
+    }
+}
+`,
+	"rs": `use std::collections::HashMap;
+
+pub struct Cache%[1]d {
+    entries: HashMap<String, String>,
+}
+
+impl Cache%[1]d {
+    pub fn new() -> Self {
+        Cache%[1]d { entries: HashMap::new() }
+    }
+
+    pub fn get(&self, key: &str) -> Option<&String> {
+        self.entries.get(key)
+    }
+}
+
+pub fn handler_%[1]d(key: &str) -> String {
+    key.to_uppercase()
+}
+`,
+}
+
+// writeSyntheticCorpus writes n files spread over languages and packages.
+// The output depends only on n, so runs are comparable across releases.
+func writeSyntheticCorpus(dir string, n int) error {
+	exts := make([]string, 0, len(syntheticTemplates))
+	for ext := range syntheticTemplates {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	for i := 0; i < n; i++ {
+		ext := exts[i%len(exts)]
+		pkg := filepath.Join(dir, "src", fmt.Sprintf("pkg%d", i/25), fmt.Sprintf("mod%d", i))
+		if err := os.MkdirAll(pkg, 0755); err != nil {
+			return err
+		}
+		content := fmt.Sprintf(syntheticTemplates[ext], i)
+		if err := os.WriteFile(filepath.Join(pkg, "file."+ext), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func summarizeLatency(samples []float64) BenchLatency {
+	if len(samples) == 0 {
+		return BenchLatency{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, s := range sorted {
+		sum += s
+	}
+	pct := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return sorted[i]
+	}
+	return BenchLatency{
+		Samples: len(sorted),
+		AvgMs:   round3(sum / float64(len(sorted))),
+		P50Ms:   round3(pct(0.5)),
+		P95Ms:   round3(pct(0.95)),
+		MaxMs:   round3(sorted[len(sorted)-1]),
+	}
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t).Nanoseconds()) / 1e6
+}
+
+func round3(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setBenchFlags sets the bench flags for one test and restores them after
+func setBenchFlags(t *testing.T, output, baseline string) {
+	t.Helper()
+	oldOutput, oldBaseline, oldIterations, oldThreshold := benchOutput, benchBaseline, benchIterations, benchThreshold
+	t.Cleanup(func() {
+		benchOutput, benchBaseline, benchIterations, benchThreshold = oldOutput, oldBaseline, oldIterations, oldThreshold
+	})
+	benchOutput, benchBaseline, benchIterations, benchThreshold = output, baseline, 1, 20
+}
+
+// benchLeftovers lists the throwaway index directories left in root
+func benchLeftovers(t *testing.T, root string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(root, ".teamcontext-bench-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestBenchProjectRemovesIndex(t *testing.T) {
+	out := t.TempDir()
+	missing := filepath.Join(out, "missing.json")
+
+	regressed := filepath.Join(out, "fast-baseline.json")
+	data, _ := json.Marshal(BenchReport{Indexing: BenchIndexing{FilesPerSec: 1e12}})
+	if err := os.WriteFile(regressed, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		output   string
+		baseline string
+		wantErr  string
+	}{
+		{"success", filepath.Join(out, "report.json"), "", ""},
+		{"unreadable baseline", filepath.Join(out, "report2.json"), missing, "reading baseline"},
+		{"regression", filepath.Join(out, "report3.json"), regressed, "regression"},
+		{"unwritable report", filepath.Join(out, "no-such-dir", "report.json"), "", "writing report"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := writeSyntheticCorpus(root, 5); err != nil {
+				t.Fatal(err)
+			}
+			setBenchFlags(t, tt.output, tt.baseline)
+
+			err := benchProject(root, newBenchReport())
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("benchProject: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if left := benchLeftovers(t, root); len(left) > 0 {
+				t.Errorf("temporary index left in the project: %v", left)
+			}
+		})
+	}
+}
+
+func TestBenchProjectReport(t *testing.T) {
+	root := t.TempDir()
+	if err := writeSyntheticCorpus(root, 10); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(t.TempDir(), "report.json")
+	setBenchFlags(t, output, "")
+
+	if err := benchProject(root, newBenchReport()); err != nil {
+		t.Fatalf("benchProject: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report BenchReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Files != 10 || report.Indexing.Files != 10 {
+		t.Errorf("files = %d, indexing = %+v; want 10", report.Files, report.Indexing)
+	}
+	if report.Search["files"].Samples == 0 {
+		t.Errorf("no search samples in %+v", report.Search)
+	}
+}
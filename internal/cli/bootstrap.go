@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/bootstrap"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var bootstrapDryRun bool
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Seed knowledge from ADRs, CODEOWNERS, lint and CI configs",
+	Long: `Mine what the repository already records into initial knowledge:
+
+- ADRs (docs/adr, docs/decisions, .adr-dir, ...) become decisions; proposed
+  ones are skipped, superseded and deprecated ones keep that status
+- CODEOWNERS entries become experts for their directories
+- ESLint, golangci-lint, Ruff, Prettier and EditorConfig configs become
+  patterns listing their rules
+- Lint rules that ban a construct (no-console, no-eval, Ruff T201, ...)
+  become decisions that check_compliance flags the construct with
+- GitHub Actions and GitLab CI jobs become patterns listing the commands
+  every change must pass
+- Dependabot and Renovate configs become a decision on dependency updates
+
+Decisions and patterns already recorded are skipped, so running it again
+only adds what is new.
+
+Example:
+  teamcontext bootstrap
+  teamcontext bootstrap --dry-run   # list what would be added`,
+	Run: runBootstrap,
+}
+
+func runBootstrap(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+
+	result := bootstrap.Mine(filepath.Dir(tcDir))
+	if len(result.Sources) == 0 {
+		fmt.Println("No ADRs, CODEOWNERS, lint, CI or dependency bot configs found.")
+		return
+	}
+
+	fmt.Printf("Found %s\n\n", strings.Join(result.Sources, ", "))
+	for _, d := range result.Decisions {
+		fmt.Printf("  decision  %s\n", d.Content)
+	}
+	for _, p := range result.Patterns {
+		fmt.Printf("  pattern   %s (%d rules)\n", p.Name, len(p.Rules))
+	}
+	for _, o := range result.Owners {
+		fmt.Printf("  experts   %s: %s\n", o.Directory, strings.Join(o.Owners, ", "))
+	}
+
+	if bootstrapDryRun {
+		fmt.Println("\nDry run: nothing was stored.")
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	summary, err := bootstrap.Apply(jsonStore, tcDir, result)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// Make the new decisions searchable right away
+	if summary.Decisions > 0 {
+		if sqliteIndex, err := storage.NewSQLiteIndex(tcDir); err == nil {
+			if err := sqliteIndex.RebuildFromJSON(jsonStore); err != nil {
+				fmt.Printf("Warning: could not update the search index: %v\n", err)
+			}
+			sqliteIndex.Close()
+		}
+	}
+
+	fmt.Printf("\nAdded %d decisions, %d patterns and experts for %d directories", summary.Decisions, summary.Patterns, summary.Owners)
+	if summary.Skipped > 0 {
+		fmt.Printf(" (%d already recorded)", summary.Skipped)
+	}
+	fmt.Println(".")
+}
+
+func init() {
+	bootstrapCmd.Flags().BoolVar(&bootstrapDryRun, "dry-run", false, "List what would be added without storing it")
+	rootCmd.AddCommand(bootstrapCmd)
+}
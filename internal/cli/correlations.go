@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	correlationMinCoChanges   int
+	correlationMinCorrelation float64
+	correlationWindowDays     int
+	correlationMaxResults     int
+	correlationSave           bool
+)
+
+var correlationsCmd = &cobra.Command{
+	Use:   "correlations [path]",
+	Short: "Regenerate co-change correlations with tuned thresholds",
+	Long: `Re-mine files that change together from git history, for the whole
+project or only the files under path, and update the correlations used by
+get_file_correlations and blueprints.
+
+Thresholds come from "correlations" in .teamcontext/config.json (per
+subdirectory under "paths") and can be overridden with flags. Use --save to
+store the flags as the subdirectory's thresholds, so reindex keeps them.
+
+Example:
+  teamcontext correlations
+  teamcontext correlations services/billing --min-co-changes 4 --window-days 180
+  teamcontext correlations services/billing --min-co-changes 4 --save`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runCorrelations,
+}
+
+func runCorrelations(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+	projectRoot := filepath.Dir(tcDir)
+
+	path := ""
+	if len(args) == 1 {
+		path = strings.Trim(filepath.ToSlash(filepath.Clean(args[0])), "/")
+		if path == "." {
+			path = ""
+		}
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	config, err := jsonStore.GetConfig()
+	if err != nil {
+		fmt.Printf("Error reading config: %v\n", err)
+		return
+	}
+
+	flags := types.CorrelationConfig{
+		MinCoChanges:   correlationMinCoChanges,
+		MinCorrelation: correlationMinCorrelation,
+		WindowDays:     correlationWindowDays,
+		MaxResults:     correlationMaxResults,
+	}
+	cfg := config.Correlations
+	if path == "" {
+		cfg = git.WithOverride(cfg, flags)
+	} else {
+		paths := make(map[string]types.CorrelationConfig, len(cfg.Paths)+1)
+		for p, override := range cfg.Paths {
+			paths[p] = override
+		}
+		paths[path] = git.WithOverride(paths[path], flags)
+		cfg.Paths = paths
+	}
+
+	knowledgeDir := filepath.Join(tcDir, "knowledge")
+	existing, _ := git.LoadCorrelations(knowledgeDir)
+	correlations, err := git.RegenerateCorrelations(projectRoot, path, cfg, existing)
+	if err != nil {
+		fmt.Printf("Error mining correlations: %v\n", err)
+		return
+	}
+	if err := git.WriteCorrelations(knowledgeDir, correlations); err != nil {
+		fmt.Printf("Error writing correlations: %v\n", err)
+		return
+	}
+
+	if correlationSave {
+		config.Correlations = cfg
+		if err := jsonStore.SaveConfig(config); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return
+		}
+	}
+
+	used := git.ForPath(cfg, path)
+	scope := "project"
+	if path != "" {
+		scope = path
+	}
+	window := "all history"
+	if used.WindowDays > 0 {
+		window = fmt.Sprintf("last %d days", used.WindowDays)
+	}
+	fmt.Printf("Correlations regenerated for %s (%s)\n", scope, window)
+	fmt.Println("")
+
+	var inScope []git.FileCorrelation
+	for _, c := range correlations {
+		if path == "" || (strings.HasPrefix(c.File1, path+"/") && strings.HasPrefix(c.File2, path+"/")) {
+			inScope = append(inScope, c)
+		}
+	}
+	fmt.Printf("  Pairs found:     %d\n", len(inScope))
+	fmt.Printf("  Total stored:    %d\n", len(correlations))
+	for i, c := range inScope {
+		if i == 10 {
+			fmt.Printf("  ... and %d more\n", len(inScope)-10)
+			break
+		}
+		fmt.Printf("  %.0f%%  %s <-> %s (%d co-changes, %s)\n", c.Correlation*100, c.File1, c.File2, c.CoChanges, c.Confidence)
+	}
+	if correlationSave {
+		fmt.Println("")
+		fmt.Println("Thresholds saved to .teamcontext/config.json; reindex will keep using them.")
+	}
+}
+
+func init() {
+	correlationsCmd.Flags().IntVar(&correlationMinCoChanges, "min-co-changes", 0, "Commits a pair must share (default 9)")
+	correlationsCmd.Flags().Float64Var(&correlationMinCorrelation, "min-correlation", 0, "Confidence floor, 0-1 (default 0.5)")
+	correlationsCmd.Flags().IntVar(&correlationWindowDays, "window-days", 0, "Only mine commits from the last N days (default: all history)")
+	correlationsCmd.Flags().IntVar(&correlationMaxResults, "max-results", 0, "Correlations kept (default 50)")
+	correlationsCmd.Flags().BoolVar(&correlationSave, "save", false, "Store these thresholds in config for this path")
+	rootCmd.AddCommand(correlationsCmd)
+}
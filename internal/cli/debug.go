@@ -36,6 +36,8 @@ var debugMcpCmd = &cobra.Command{
 		switch toolName {
 		case "get_tree":
 			resp, err = server.HandleToolCall("get_tree", params)
+		case "get_code_map":
+			resp, err = server.HandleToolCall("get_code_map", params)
 		case "get_signature":
 			resp, err = server.HandleToolCall("get_signature", params)
 		default:
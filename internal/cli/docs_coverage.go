@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/doccoverage"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	docsCoverageMin      float64
+	docsCoveragePackages int
+	docsCoverageStrict   bool
+)
+
+var docsCoverageCmd = &cobra.Command{
+	Use:   "docs-coverage [path]",
+	Short: "Report which public exports lack doc comments",
+	Long: `Count the public classes, functions, methods, interfaces, types and
+enums under path (default: the whole project) that have doc comments, per
+package, least documented first, and list the module and service
+directories without a README. Test and generated files are not counted.
+
+Thresholds come from "docs_coverage" in .teamcontext/config.json: "min"
+for everything scanned and "paths" for critical directories. --min
+overrides the overall one.
+
+Example:
+  teamcontext docs-coverage
+  teamcontext docs-coverage services/billing --min 80
+  teamcontext docs-coverage --strict   # exit 1 when a threshold is not met (CI)`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDocsCoverage,
+}
+
+func runDocsCoverage(cmd *cobra.Command, args []string) {
+	// Without a .teamcontext directory, report on the working directory
+	// with no configured thresholds
+	var cfg types.DocsCoverageConfig
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if tcDir, err := findTeamContextDirFromCwd(); err == nil {
+		projectRoot = filepath.Dir(tcDir)
+		if config, err := storage.NewJSONStore(tcDir).GetConfig(); err == nil {
+			cfg = config.DocsCoverage
+		}
+	}
+	if docsCoverageMin > 0 {
+		cfg.Min = docsCoverageMin
+	}
+
+	path := ""
+	if len(args) == 1 {
+		absPath, err := filepath.Abs(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		path, err = filepath.Rel(projectRoot, absPath)
+		if err != nil || strings.HasPrefix(path, "..") {
+			fmt.Printf("Error: %s is outside the project\n", args[0])
+			return
+		}
+	}
+
+	report, err := doccoverage.Analyze(projectRoot, path, cfg)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	printDocsCoverage(report, docsCoveragePackages)
+
+	if docsCoverageStrict && report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printDocsCoverage(report *doccoverage.Report, maxPackages int) {
+	for i, pkg := range report.Packages {
+		if maxPackages > 0 && i == maxPackages {
+			fmt.Printf("   ... %d more packages\n", len(report.Packages)-maxPackages)
+			break
+		}
+		fmt.Printf("%5.1f%%  %-50s %d/%d\n", pkg.Percent, pkg.Path, pkg.Documented, pkg.Exports)
+		if len(pkg.Undocumented) > 0 {
+			fmt.Printf("        missing: %s\n", strings.Join(pkg.Undocumented, ", "))
+		}
+	}
+
+	if len(report.MissingReadmes) > 0 {
+		fmt.Printf("\nNo README: %s\n", strings.Join(report.MissingReadmes, ", "))
+	}
+
+	fmt.Printf("\n%d/%d public exports documented (%.1f%%)\n", report.Documented, report.Exports, report.Percent)
+	for _, t := range report.Thresholds {
+		path := t.Path
+		if path == "" {
+			path = "overall"
+		}
+		status := "ok"
+		if !t.Passed {
+			status = "FAILED"
+		}
+		fmt.Printf("  %-6s %s: %.1f%% (min %.1f%%)\n", status, path, t.Percent, t.Min)
+	}
+}
+
+func init() {
+	docsCoverageCmd.Flags().Float64Var(&docsCoverageMin, "min", 0, "Minimum percent documented across the scan, overriding the config")
+	docsCoverageCmd.Flags().IntVar(&docsCoveragePackages, "packages", 30, "Max packages listed (0 for all)")
+	docsCoverageCmd.Flags().BoolVar(&docsCoverageStrict, "strict", false, "Exit with status 1 when a threshold is not met")
+	rootCmd.AddCommand(docsCoverageCmd)
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the TeamContext installation and index health",
+	Long: `Check that the SQLite index opens and is migrated, and that it agrees
+with the JSON files (the source of truth). The two drift apart when one
+write of an update succeeds and the other fails. Reported:
+- Missing rows: records in the JSON files that the index lacks
+- Stale rows: index rows that differ from their record
+- Orphaned rows: index rows whose record is gone, such as deleted files
+- Stale chunks: code search chunks of files that are no longer indexed
+
+With --fix the index is repaired from the JSON files. Set
+index.consistency_check in config.json to have the worker repair drift
+during its periodic reindex.
+
+Example:
+  teamcontext doctor
+  teamcontext doctor --fix`,
+	Run: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	if _, err := jsonStore.GetConfig(); err != nil {
+		fmt.Printf("Config:           ERROR (%v)\n", err)
+	} else {
+		fmt.Println("Config:           OK")
+	}
+	if search.CheckRipgrep() {
+		fmt.Println("ripgrep:          installed")
+	} else {
+		fmt.Println("ripgrep:          NOT FOUND (code search will use fallback)")
+	}
+
+	sqliteIndex, err := storage.NewSQLiteIndex(tcDir)
+	if err != nil {
+		fmt.Printf("SQLite index:     ERROR (%v)\n", err)
+		fmt.Println("                  Run 'teamcontext rebuild' to recreate it")
+		return
+	}
+	defer sqliteIndex.Close()
+
+	current, latest, err := sqliteIndex.SchemaVersion()
+	if err != nil {
+		fmt.Printf("SQLite schema:    ERROR (%v)\n", err)
+		return
+	}
+	mode, _ := sqliteIndex.JournalMode()
+	fmt.Printf("SQLite schema:    v%d/%d, %s journal\n", current, latest, mode)
+
+	drift, err := sqliteIndex.CheckConsistency(jsonStore)
+	if err != nil {
+		fmt.Printf("Consistency:      ERROR (%v)\n", err)
+		return
+	}
+	if drift.Count() == 0 {
+		fmt.Println("Consistency:      OK (index matches the JSON files)")
+		return
+	}
+	fmt.Printf("Consistency:      %d mismatches\n", drift.Count())
+
+	printDrift("Missing rows", drift.Missing)
+	printDrift("Stale rows", drift.Stale)
+	printDrift("Orphaned rows", drift.Orphaned)
+	if len(drift.StaleChunks) > 0 {
+		printDrift("Stale chunks", map[string][]string{"code_chunks": drift.StaleChunks})
+	}
+
+	fmt.Println()
+	if !doctorFix {
+		fmt.Println("Run 'teamcontext doctor --fix' to repair the index.")
+		return
+	}
+	repaired, err := sqliteIndex.RepairDrift(jsonStore, drift)
+	if err != nil {
+		fmt.Printf("Error repairing index: %v\n", err)
+		return
+	}
+	fmt.Printf("Repaired %d mismatches.\n", repaired)
+}
+
+func printDrift(title string, byTable map[string][]string) {
+	tables := make([]string, 0, len(byTable))
+	for table := range byTable {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		ids := byTable[table]
+		fmt.Printf("\n%s in %s (%d):\n", title, table, len(ids))
+		for i, id := range ids {
+			if i == maxAuditListed {
+				fmt.Printf("  ... and %d more\n", len(ids)-maxAuditListed)
+				break
+			}
+			fmt.Printf("  %s\n", id)
+		}
+	}
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the index from the JSON files")
+	rootCmd.AddCommand(doctorCmd)
+}
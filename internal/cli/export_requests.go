@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/saeedalam/teamcontext/internal/extractor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportRequestsFormat  string
+	exportRequestsBaseURL string
+	exportRequestsApp     string
+	exportRequestsOutput  string
+)
+
+var exportRequestsCmd = &cobra.Command{
+	Use:   "export-requests [path]",
+	Short: "Generate a Postman, Insomnia or .http request collection",
+	Long: `Extract the REST endpoints under path (default: current directory) and
+write a request collection you can run right away:
+- http:     VS Code REST Client / JetBrains HTTP Client .http file
+- postman:  Postman collection v2.1
+- insomnia: Insomnia export v4
+
+Requests use baseUrl, token and path parameter variables. POST, PUT and
+PATCH bodies are example payloads built from the DTO, pydantic model, Java or
+C# class the handler takes.
+
+Example:
+  teamcontext export-requests apps/billing
+  teamcontext export-requests apps/billing --format postman -o billing.postman_collection.json
+  teamcontext export-requests --base-url http://localhost:8080`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runExportRequests,
+}
+
+func runExportRequests(cmd *cobra.Command, args []string) {
+	path := "."
+	if len(args) == 1 {
+		path = args[0]
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	app := exportRequestsApp
+	if app == "" {
+		app = filepath.Base(absPath)
+	}
+	surface, err := extractor.ExtractAPISurface(absPath, app)
+	if err != nil {
+		fmt.Printf("Error extracting API surface: %v\n", err)
+		return
+	}
+	if len(surface.Endpoints) == 0 {
+		fmt.Printf("No REST endpoints found under %s\n", path)
+		return
+	}
+
+	payloads, err := extractor.ExtractPayloadTypes(absPath)
+	if err != nil {
+		fmt.Printf("Error reading request types: %v\n", err)
+		return
+	}
+	collection, err := extractor.BuildRequestCollection(surface, payloads, extractor.CollectionOptions{
+		Format:  exportRequestsFormat,
+		BaseURL: exportRequestsBaseURL,
+		Root:    absPath,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	output := exportRequestsOutput
+	if output == "" {
+		output = collection.FileName
+	}
+	if err := os.WriteFile(output, []byte(collection.Content), 0644); err != nil {
+		fmt.Printf("Error writing %s: %v\n", output, err)
+		return
+	}
+
+	fmt.Printf("Wrote %d requests to %s\n", collection.Requests, output)
+	fmt.Printf("  Example bodies: %d\n", collection.WithExample)
+	if len(collection.UnknownBodies) > 0 {
+		fmt.Printf("  Body types not found (sent as {}): %v\n", collection.UnknownBodies)
+	}
+}
+
+func init() {
+	exportRequestsCmd.Flags().StringVar(&exportRequestsFormat, "format", extractor.CollectionHTTP, "Collection format: http, postman or insomnia")
+	exportRequestsCmd.Flags().StringVar(&exportRequestsBaseURL, "base-url", extractor.DefaultBaseURL, "Value of the baseUrl variable")
+	exportRequestsCmd.Flags().StringVar(&exportRequestsApp, "app", "", "Collection name (default: directory name)")
+	exportRequestsCmd.Flags().StringVarP(&exportRequestsOutput, "output", "o", "", "File to write (default: <app>.http, <app>.postman_collection.json or <app>.insomnia.json)")
+	rootCmd.AddCommand(exportRequestsCmd)
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportSymbolsFormat string
+	exportSymbolsOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the index for other tools",
+}
+
+var exportSymbolsCmd = &cobra.Command{
+	Use:   "symbols",
+	Short: "Export indexed symbols as a ctags file or an LSIF dump",
+	Long: `Dump every indexed file's symbols, from its skeleton (classes, methods,
+functions, interfaces, types, enums, constants) and the exports the indexer
+recorded, so editors and code intelligence tools can reuse the index:
+- ctags: Universal Ctags extended format, sorted, with kind, scope,
+         signature and language fields; symbols that are not exported
+         are marked file-local
+- lsif:  LSIF 0.6 dump with a definition, hover and document symbols per
+         symbol, and export monikers for exported ones
+
+Skeletons come from the cache when files are unchanged since they were
+indexed. Run 'teamcontext index' first.
+
+Example:
+  teamcontext export symbols                     # ./tags
+  teamcontext export symbols --format lsif       # ./dump.lsif
+  teamcontext export symbols --format ctags -o - | grep Invoice`,
+	Run: runExportSymbols,
+}
+
+func runExportSymbols(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+	if exportSymbolsFormat != "ctags" && exportSymbolsFormat != "lsif" {
+		fmt.Printf("Error: unknown format %q (use ctags or lsif)\n", exportSymbolsFormat)
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	sqliteIndex, err := storage.NewSQLiteIndex(tcDir)
+	if err != nil {
+		fmt.Printf("Error initializing SQLite: %v\n", err)
+		return
+	}
+	defer sqliteIndex.Close()
+
+	workerMgr := worker.NewManager(tcDir, jsonStore, sqliteIndex)
+	projectRoot := filepath.Dir(tcDir)
+
+	files, err := jsonStore.GetFilesIndex()
+	if err != nil {
+		fmt.Printf("Error loading file index: %v\n", err)
+		return
+	}
+	paths := make([]string, 0, len(files))
+	for path, file := range files {
+		if file.DeletedAt == nil {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var symbols []skeleton.Symbol
+	skipped := 0
+	for _, path := range paths {
+		absPath := relpath.ToAbs(projectRoot, path)
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			skipped++
+			continue
+		}
+		// Files no parser reads still contribute their indexed exports
+		sk, _ := workerMgr.Skeleton(absPath)
+		symbols = append(symbols, skeleton.Symbols(path, sk, content, files[path].Exports)...)
+	}
+
+	var out string
+	if exportSymbolsFormat == "lsif" {
+		if out, err = skeleton.FormatLSIF(projectRoot, symbols); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	} else {
+		out = skeleton.FormatCtags(symbols)
+	}
+
+	output := exportSymbolsOutput
+	if output == "-" {
+		fmt.Print(out)
+		return
+	}
+	if output == "" {
+		output = "tags"
+		if exportSymbolsFormat == "lsif" {
+			output = "dump.lsif"
+		}
+	}
+	if err := os.WriteFile(output, []byte(out), 0644); err != nil {
+		fmt.Printf("Error writing %s: %v\n", output, err)
+		return
+	}
+
+	fmt.Printf("Wrote %d symbols from %d files to %s\n", len(symbols), len(paths)-skipped, output)
+	if skipped > 0 {
+		fmt.Printf("  Skipped %d indexed files missing from disk (run 'teamcontext index' to refresh)\n", skipped)
+	}
+}
+
+func init() {
+	exportSymbolsCmd.Flags().StringVar(&exportSymbolsFormat, "format", "ctags", "Output format: ctags or lsif")
+	exportSymbolsCmd.Flags().StringVarP(&exportSymbolsOutput, "output", "o", "", "File to write, or - for stdout (default: tags or dump.lsif)")
+	exportCmd.AddCommand(exportSymbolsCmd)
+	rootCmd.AddCommand(exportCmd)
+}
@@ -64,7 +64,7 @@ func runIndex(cmd *cobra.Command, args []string) {
 	fmt.Println("Indexing complete!")
 	fmt.Println("")
 	fmt.Printf("  Files indexed:     %d\n", indexed)
-	fmt.Printf("  Skeletons cached:  %d\n", stats.SkeletonsCached)
+	fmt.Printf("  Skeletons cached:  %d (%d unchanged since the last run)\n", stats.SkeletonsCached, stats.SkeletonsReused)
 	fmt.Printf("  Git checks:        %d\n", stats.GitChecks)
 	fmt.Println("")
 	fmt.Println("The background worker will keep the index updated as files change.")
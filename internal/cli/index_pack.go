@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+var packOutput string
+
+var indexPackCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Pack the index into a portable artifact",
+	Long: `Pack the file index, SQLite search index and code tree into a
+single artifact keyed by the current commit.
+
+Build the index once in CI and let developers download it instead of
+indexing from scratch. Knowledge (decisions, warnings, ...) is not
+included; it is shared through the repository.
+
+Example:
+  teamcontext index pack                          # teamcontext-index-<commit>.tar.gz
+  teamcontext index pack -o index.tar.gz`,
+	Run: runIndexPack,
+}
+
+var indexUnpackCmd = &cobra.Command{
+	Use:   "unpack <artifact>",
+	Short: "Replace the local index with a packed artifact",
+	Long: `Unpack an artifact created by 'teamcontext index pack' and reconcile it
+with the working tree: files whose content differs from when the artifact
+was built are reindexed, deleted files are removed and new files indexed.
+
+Stop 'teamcontext serve' first; it holds the SQLite index open.
+
+Example:
+  teamcontext index unpack teamcontext-index-3f9c2a1b.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	Run:  runIndexUnpack,
+}
+
+func runIndexPack(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	sqliteIndex, err := storage.NewSQLiteIndex(tcDir)
+	if err != nil {
+		fmt.Printf("Error initializing SQLite: %v\n", err)
+		return
+	}
+	defer sqliteIndex.Close()
+
+	workerMgr := worker.NewManager(tcDir, jsonStore, sqliteIndex)
+
+	out := packOutput
+	if out == "" {
+		out = "teamcontext-index.tar.gz"
+		if commit, err := git.GetHeadHash(filepath.Dir(tcDir)); err == nil {
+			out = fmt.Sprintf("teamcontext-index-%s.tar.gz", commit[:8])
+		}
+	}
+
+	manifest, err := workerMgr.PackIndex(out)
+	if err != nil {
+		os.Remove(out)
+		fmt.Printf("Error packing index: %v\n", err)
+		return
+	}
+
+	info, _ := os.Stat(out)
+	fmt.Println("Index packed!")
+	fmt.Println("")
+	fmt.Printf("  Artifact:  %s\n", out)
+	if info != nil {
+		fmt.Printf("  Size:      %.1f MB\n", float64(info.Size())/(1024*1024))
+	}
+	fmt.Printf("  Commit:    %s\n", orNone(manifest.Commit))
+	fmt.Printf("  Files:     %d\n", len(manifest.Files))
+}
+
+func runIndexUnpack(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+
+	fmt.Printf("Unpacking %s...\n", args[0])
+	manifest, err := worker.UnpackIndex(tcDir, args[0])
+	if err != nil {
+		fmt.Printf("Error unpacking index: %v\n", err)
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	sqliteIndex, err := storage.NewSQLiteIndex(tcDir)
+	if err != nil {
+		fmt.Printf("Error initializing SQLite: %v\n", err)
+		return
+	}
+	defer sqliteIndex.Close()
+
+	workerMgr := worker.NewManager(tcDir, jsonStore, sqliteIndex)
+
+	fmt.Println("Reconciling with the working tree...")
+	report, err := workerMgr.ReconcileIndex(manifest)
+	if err != nil {
+		fmt.Printf("Error reconciling index: %v\n", err)
+		return
+	}
+
+	fmt.Println("Index unpacked!")
+	fmt.Println("")
+	fmt.Printf("  Built at commit:   %s\n", orNone(report.ArtifactCommit))
+	fmt.Printf("  Local commit:      %s\n", orNone(report.LocalCommit))
+	fmt.Printf("  Files up to date:  %d\n", report.Unchanged)
+	fmt.Printf("  Modified:          %d\n", report.Modified)
+	fmt.Printf("  Added:             %d\n", report.Added)
+	fmt.Printf("  Removed:           %d\n", report.Removed)
+	fmt.Printf("  Reindexed:         %d\n", report.Indexed)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+func init() {
+	indexPackCmd.Flags().StringVarP(&packOutput, "output", "o", "", "Artifact path (default: teamcontext-index-<commit>.tar.gz)")
+	indexCmd.AddCommand(indexPackCmd)
+	indexCmd.AddCommand(indexUnpackCmd)
+}
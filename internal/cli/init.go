@@ -170,17 +170,17 @@ cache/
 
 		// Phase 2: Git History
 		fmt.Println("  [2/2] Starting git history processing...")
-		report, err := git.ProcessGitHistory(cwd)
+		var cfg types.Config
+		if configData, err := os.ReadFile(filepath.Join(tcDir, "config.json")); err == nil {
+			json.Unmarshal(configData, &cfg)
+		}
+		report, err := git.ProcessGitHistoryIncremental(cwd, filepath.Join(tcDir, "cache", "git-analysis.json"), cfg.Correlations)
 		if err != nil {
 			fmt.Printf("  [WARNING] Git history processing failed: %v\n", err)
 		} else {
 			// Cross-reference linked repos if configured
-			configPath := filepath.Join(tcDir, "config.json")
-			if configData, err := os.ReadFile(configPath); err == nil {
-				var cfg types.Config
-				if json.Unmarshal(configData, &cfg) == nil && len(cfg.LinkedRepos) > 0 {
-					git.CrossReferenceLinkedRepos(report, cfg.LinkedRepos)
-				}
+			if len(cfg.LinkedRepos) > 0 {
+				git.CrossReferenceLinkedRepos(report, cfg.LinkedRepos)
 			}
 
 			knowledgeDir := filepath.Join(tcDir, "knowledge")
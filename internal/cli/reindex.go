@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"sync"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/saeedalam/teamcontext/internal/git"
 	"github.com/saeedalam/teamcontext/internal/storage"
 	"github.com/saeedalam/teamcontext/internal/worker"
+	"github.com/saeedalam/teamcontext/pkg/types"
 )
 
 var reindexCmd = &cobra.Command{
@@ -19,6 +21,7 @@ var reindexCmd = &cobra.Command{
 This command:
 - Re-scans all files for skeletons, imports, and graph edges
 - Re-processes git history for experts, risks, and correlations
+  (incrementally: only commits since the last run are analyzed)
 - PRESERVES decisions, warnings, insights, patterns, and conversations
 
 Use this when:
@@ -26,11 +29,21 @@ Use this when:
 - The index seems out of date
 - You want to refresh git intelligence
 
+Use --full-git to discard the cached git aggregates and re-analyze the
+full history.
+
 Example:
-  teamcontext reindex`,
+  teamcontext reindex
+  teamcontext reindex --full-git`,
 	Run: runReindex,
 }
 
+var reindexFullGit bool
+
+func init() {
+	reindexCmd.Flags().BoolVar(&reindexFullGit, "full-git", false, "Re-analyze full git history instead of only new commits")
+}
+
 func runReindex(cmd *cobra.Command, args []string) {
 	tcDir, err := findTeamContextDirFromCwd()
 	if err != nil {
@@ -69,7 +82,15 @@ func runReindex(cmd *cobra.Command, args []string) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		report, err := git.ProcessGitHistory(projectRoot)
+		statePath := filepath.Join(tcDir, "cache", "git-analysis.json")
+		if reindexFullGit {
+			os.Remove(statePath)
+		}
+		var correlationCfg types.CorrelationConfig
+		if cfg, err := storage.NewJSONStore(tcDir).GetConfig(); err == nil {
+			correlationCfg = cfg.Correlations
+		}
+		report, err := git.ProcessGitHistoryIncremental(projectRoot, statePath, correlationCfg)
 		if err != nil {
 			gitErr = err
 			return
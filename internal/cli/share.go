@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/share"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareFormat string
+	shareOutput string
+	shareUpload bool
+)
+
+var shareCmd = &cobra.Command{
+	Use:   "share <decision-id|warning-id|architecture>",
+	Short: "Export a decision, warning or the architecture as a standalone page",
+	Long: `Write one knowledge item as a markdown or HTML page another team can
+read without TeamContext. Secrets (API keys, tokens, private keys, passwords
+in connection strings and assignments) are redacted, and a provenance footer
+records the project, item, author, commit and who shared it.
+
+With --upload the page is published to share.upload_url (an HTTP PUT, with
+TEAMCONTEXT_SHARE_TOKEN as bearer token) or copied to share.upload_dir, and
+its link printed. Uploads are limited to share.max_per_hour per clone
+(default 10).
+
+IDs may be shortened to any unique prefix.
+
+Example:
+  teamcontext share dec-3f9a1c0b7e2d                   # ./dec-3f9a1c0b7e2d.md
+  teamcontext share warn-81c0 --format html --upload
+  teamcontext share architecture -o - | pbcopy`,
+	Args: cobra.ExactArgs(1),
+	Run:  runShare,
+}
+
+func runShare(cmd *cobra.Command, args []string) {
+	tcDir, err := findTeamContextDirFromCwd()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'teamcontext init' first to initialize TeamContext.")
+		return
+	}
+	if shareFormat == "md" {
+		shareFormat = "markdown"
+	}
+	if shareFormat != "markdown" && shareFormat != "html" {
+		fmt.Printf("Error: unknown format %q (use markdown or html)\n", shareFormat)
+		return
+	}
+
+	jsonStore := storage.NewJSONStore(tcDir)
+	config, err := jsonStore.GetConfig()
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		return
+	}
+	projectRoot := filepath.Dir(tcDir)
+	now := time.Now()
+	prov := share.Provenance{
+		Project:   config.Name,
+		SharedAt:  now,
+		Generator: "teamcontext " + buildVersion,
+	}
+	if out, err := runGit(projectRoot, "rev-parse", "HEAD"); err == nil {
+		prov.Commit = strings.TrimSpace(out)
+	}
+	if out, err := runGit(projectRoot, "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		prov.Branch = strings.TrimSpace(out)
+	}
+	if out, err := runGit(projectRoot, "config", "user.name"); err == nil {
+		prov.SharedBy = strings.TrimSpace(out)
+	}
+
+	artifact, err := findShareItem(jsonStore, args[0], prov)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	var content string
+	if shareFormat == "html" {
+		if content, err = artifact.HTML(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	} else {
+		content = artifact.Markdown()
+	}
+	name := artifact.FileName(shareFormat)
+
+	if shareOutput == "-" {
+		fmt.Print(content)
+	} else if shareOutput != "" || !shareUpload {
+		output := shareOutput
+		if output == "" {
+			output = name
+		}
+		if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", output, err)
+			return
+		}
+		fmt.Printf("Wrote %s\n", output)
+	}
+	if artifact.Redactions > 0 {
+		fmt.Fprintf(os.Stderr, "  Redacted %d secret(s); review the page before sending it on\n", artifact.Redactions)
+	}
+
+	if shareUpload {
+		link, err := share.Publish(tcDir, config.Share, artifact, name, []byte(content), now)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("Shared: %s\n", link)
+	}
+}
+
+// findShareItem builds the artifact of the decision or warning with the
+// given ID or unique ID prefix, or of the architecture view
+func findShareItem(store *storage.JSONStore, id string, prov share.Provenance) (*share.Artifact, error) {
+	if id == "architecture" {
+		arch, err := store.GetArchitecture()
+		if err != nil {
+			return nil, err
+		}
+		if arch.Description == "" && arch.Diagram == "" && len(arch.Services) == 0 {
+			return nil, fmt.Errorf("no architecture recorded yet")
+		}
+		return share.Architecture(*arch, prov), nil
+	}
+
+	decisions, err := store.GetDecisions()
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := store.GetWarnings()
+	if err != nil {
+		return nil, err
+	}
+	var matches []*share.Artifact
+	var ids []string
+	for _, d := range decisions {
+		if d.ID == id {
+			return share.Decision(d, prov), nil
+		}
+		if strings.HasPrefix(d.ID, id) {
+			matches = append(matches, share.Decision(d, prov))
+			ids = append(ids, d.ID)
+		}
+	}
+	for _, w := range warnings {
+		if w.ID == id {
+			return share.Warning(w, prov), nil
+		}
+		if strings.HasPrefix(w.ID, id) {
+			matches = append(matches, share.Warning(w, prov))
+			ids = append(ids, w.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no decision or warning with ID %q", id)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("ID %q is ambiguous: %s", id, strings.Join(ids, ", "))
+}
+
+func init() {
+	shareCmd.Flags().StringVar(&shareFormat, "format", "markdown", "Output format: markdown or html")
+	shareCmd.Flags().StringVarP(&shareOutput, "output", "o", "", "File to write, or - for stdout (default: <id>.md or <id>.html, not written with --upload)")
+	shareCmd.Flags().BoolVar(&shareUpload, "upload", false, "Publish to the configured share location and print its link")
+	rootCmd.AddCommand(shareCmd)
+}
@@ -51,5 +51,27 @@ func runStats(cmd *cobra.Command, args []string) {
 	fmt.Println("│                                             │")
 	fmt.Println("│ Conversations                               │")
 	fmt.Printf("│   Total:             %-20d │\n", stats.Conversations)
+	if len(stats.Contributors) > 0 {
+		fmt.Println("│                                             │")
+		fmt.Println("│ Contributors                                │")
+		for i, c := range stats.Contributors {
+			if i == maxStatsContributors {
+				fmt.Printf("│   ... and %-33d │\n", len(stats.Contributors)-i)
+				break
+			}
+			name := c.Author
+			if c.Agent {
+				name += " *"
+			}
+			if len(name) > 30 {
+				name = name[:27] + "..."
+			}
+			fmt.Printf("│   %-30s %10d │\n", name, c.Total)
+		}
+		fmt.Printf("│   From agents or no one: %-18s │\n", fmt.Sprintf("%.0f%%", stats.AgentShare*100))
+	}
 	fmt.Println("└─────────────────────────────────────────────┘")
 }
+
+// maxStatsContributors is how many authors the stats leaderboard lists
+const maxStatsContributors = 10
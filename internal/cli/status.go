@@ -115,6 +115,10 @@ func runStatus(cmd *cobra.Command, args []string) {
 		stats, _ := sqliteIndex.GetStats()
 		fmt.Printf("  SQLite index:     OK (%d files, %d decisions)\n",
 			stats["files"], stats["decisions"])
+		if current, latest, err := sqliteIndex.SchemaVersion(); err == nil {
+			mode, _ := sqliteIndex.JournalMode()
+			fmt.Printf("  SQLite schema:    v%d/%d, %s journal\n", current, latest, mode)
+		}
 		sqliteIndex.Close()
 	}
 
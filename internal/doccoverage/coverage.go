@@ -0,0 +1,263 @@
+// Package doccoverage reports how much of a codebase's public surface is
+// documented: the exports with doc comments in each package, the
+// directories that should have a README and do not, and whether the
+// thresholds configured for critical areas are met.
+package doccoverage
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// maxUndocumented caps the undocumented exports listed per package
+const maxUndocumented = 10
+
+// sourceExts are the languages whose exports are counted
+var sourceExts = map[string]bool{
+	".go": true, ".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true,
+	".py": true, ".java": true, ".cs": true, ".rs": true, ".kt": true, ".swift": true,
+	".rb": true, ".php": true, ".scala": true, ".dart": true, ".c": true, ".cpp": true,
+	".h": true, ".hpp": true, ".ex": true, ".exs": true, ".erl": true, ".hrl": true,
+	".proto": true, ".m": true, ".mm": true,
+}
+
+// skipDirs hold dependencies, build output and fixtures, not documented code
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true, "target": true,
+	"bin": true, "obj": true, "_build": true, "deps": true, "__pycache__": true,
+	"venv": true, "testdata": true, "__tests__": true, "coverage": true,
+}
+
+// manifests mark the root of a module, which should have a README
+var manifests = []string{
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "setup.py", "pom.xml",
+	"build.gradle", "build.gradle.kts", "mix.exs", "pubspec.yaml", "composer.json", "Gemfile",
+}
+
+// serviceRoots hold one app, service or package per child directory
+var serviceRoots = map[string]bool{"apps": true, "services": true, "packages": true, "libs": true}
+
+// Package is the documentation coverage of one directory's files
+type Package struct {
+	Path         string   `json:"path"` // project-relative directory
+	Exports      int      `json:"exports"`
+	Documented   int      `json:"documented"`
+	Percent      float64  `json:"percent"`
+	Undocumented []string `json:"undocumented,omitempty"` // "Name (file:line)", the first few
+}
+
+// Threshold is a configured minimum and whether the code under its path
+// meets it
+type Threshold struct {
+	Path    string  `json:"path"` // "" for everything scanned
+	Min     float64 `json:"min"`
+	Percent float64 `json:"percent"`
+	Passed  bool    `json:"passed"`
+}
+
+// Report is the documentation coverage of a directory tree
+type Report struct {
+	Path           string      `json:"path"`
+	Exports        int         `json:"exports"`
+	Documented     int         `json:"documented"`
+	Percent        float64     `json:"percent"`
+	Packages       []Package   `json:"packages"`
+	MissingReadmes []string    `json:"missing_readmes,omitempty"`
+	Thresholds     []Threshold `json:"thresholds,omitempty"`
+	Failed         int         `json:"failed"` // thresholds not met
+}
+
+// Analyze computes the documentation coverage of the project-relative
+// path (empty for the whole project). Test and generated files are not
+// counted. Packages are sorted least documented first. Thresholds are
+// checked for the paths with exports in the scan; cfg.Min applies to the
+// whole scan.
+func Analyze(projectRoot, path string, cfg types.DocsCoverageConfig) (*Report, error) {
+	path = strings.Trim(filepath.ToSlash(filepath.Clean(path)), "/")
+	if path == "." {
+		path = ""
+	}
+	root := filepath.Join(projectRoot, filepath.FromSlash(path))
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Path: path, Packages: []Package{}}
+	packages := make(map[string]*Package)
+	var readmeDirs []string
+
+	err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(projectRoot, file)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if file != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			if (file == root && path == "") || needsReadme(file, rel) {
+				readmeDirs = append(readmeDirs, rel)
+			}
+			return nil
+		}
+		if !sourceExts[strings.ToLower(filepath.Ext(file))] || isTestFile(d.Name()) {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil || skeleton.IsGenerated(file, content) {
+			return nil
+		}
+		sk, err := skeleton.ParseFile(file)
+		if err != nil {
+			return nil
+		}
+		exports := skeleton.Exports(sk, content)
+		if len(exports) == 0 {
+			return nil
+		}
+
+		dir := filepath.ToSlash(filepath.Dir(rel))
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &Package{Path: dir}
+			packages[dir] = pkg
+		}
+		for _, e := range exports {
+			pkg.Exports++
+			if e.Documented {
+				pkg.Documented++
+			} else if len(pkg.Undocumented) < maxUndocumented {
+				pkg.Undocumented = append(pkg.Undocumented, e.Name+" ("+d.Name()+":"+strconv.Itoa(e.Line)+")")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		pkg.Percent = percent(pkg.Documented, pkg.Exports)
+		report.Exports += pkg.Exports
+		report.Documented += pkg.Documented
+		report.Packages = append(report.Packages, *pkg)
+	}
+	report.Percent = percent(report.Documented, report.Exports)
+	sort.Slice(report.Packages, func(i, j int) bool {
+		a, b := report.Packages[i], report.Packages[j]
+		if a.Percent != b.Percent {
+			return a.Percent < b.Percent
+		}
+		return a.Path < b.Path
+	})
+
+	// Only directories with code under them need a README
+	for _, dir := range readmeDirs {
+		if hasCodeUnder(report.Packages, dir) && !hasReadme(filepath.Join(projectRoot, filepath.FromSlash(dir))) {
+			report.MissingReadmes = append(report.MissingReadmes, dir)
+		}
+	}
+
+	if cfg.Min > 0 {
+		report.addThreshold("", cfg.Min, report.Exports, report.Documented)
+	}
+	var paths []string
+	for p := range cfg.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, key := range paths {
+		p := strings.Trim(filepath.ToSlash(key), "/")
+		exports, documented := 0, 0
+		for _, pkg := range report.Packages {
+			if within(pkg.Path, p) {
+				exports += pkg.Exports
+				documented += pkg.Documented
+			}
+		}
+		if exports > 0 {
+			report.addThreshold(p, cfg.Paths[key], exports, documented)
+		}
+	}
+	return report, nil
+}
+
+func (r *Report) addThreshold(path string, min float64, exports, documented int) {
+	t := Threshold{Path: path, Min: min, Percent: percent(documented, exports)}
+	t.Passed = t.Percent >= min
+	if !t.Passed {
+		r.Failed++
+	}
+	r.Thresholds = append(r.Thresholds, t)
+}
+
+// needsReadme reports whether a directory is one readers look for a
+// README in: a module root or an app/service/package of a monorepo
+func needsReadme(dir, rel string) bool {
+	if parent := filepath.Base(filepath.Dir(dir)); serviceRoots[parent] && strings.Count(rel, "/") == 1 {
+		return true
+	}
+	for _, m := range manifests {
+		if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func hasReadme(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(strings.ToLower(e.Name()), "readme") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCodeUnder(packages []Package, dir string) bool {
+	for _, pkg := range packages {
+		if within(pkg.Path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether a project-relative path is dir or under it; ""
+// and "." are the project root
+func within(path, dir string) bool {
+	if dir == "" || dir == "." {
+		return true
+	}
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// isTestFile reports whether a file name follows a test naming convention
+func isTestFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") ||
+		strings.HasSuffix(base, "_test") || strings.HasSuffix(base, "_spec") ||
+		strings.HasPrefix(name, "test_") || strings.HasSuffix(base, "Test") || strings.HasSuffix(base, "Tests")
+}
+
+// percent is documented out of exports, to one decimal; 100 when there is
+// nothing to document
+func percent(documented, exports int) float64 {
+	if exports == 0 {
+		return 100
+	}
+	return math.Round(float64(documented)*1000/float64(exports)) / 10
+}
@@ -0,0 +1,124 @@
+package doccoverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"README.md":               "# Shop\n",
+		"services/billing/go.mod": "module billing\n",
+		"services/billing/charge.go": `package billing
+
+// Charge is a payment taken from a card
+type Charge struct {
+	ID string
+}
+
+// Capture settles an authorized charge
+func (c *Charge) Capture() error { return nil }
+
+func (c *Charge) Refund() error { return nil }
+
+func New() *Charge { return &Charge{} }
+
+func helper() {}
+`,
+		"services/billing/charge_test.go": "package billing\n\nfunc TestCharge() {}\n",
+		"apps/web/README.md":              "# Web\n",
+		"apps/web/src/cart.ts": `/** A shopping cart */
+export class Cart {
+  @Log()
+  add(item: string) {}
+
+  private total() {}
+}
+
+export function emptyCart(): Cart { return new Cart(); }
+`,
+		"apps/api/app.py": `def create_app():
+    """Builds the app."""
+    return None
+
+
+class Settings:
+    debug = False
+
+
+def _private():
+    pass
+`,
+	})
+
+	report, err := Analyze(root, "", types.DocsCoverageConfig{
+		Min:   60,
+		Paths: map[string]float64{"services/billing": 50, "apps/web/": 90},
+	})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	byPath := make(map[string]Package)
+	for _, pkg := range report.Packages {
+		byPath[pkg.Path] = pkg
+	}
+	// Charge, Charge.Capture, Charge.Refund, New; the test file and helper are not counted
+	if billing := byPath["services/billing"]; billing.Exports != 4 || billing.Documented != 2 ||
+		!reflect.DeepEqual(billing.Undocumented, []string{"Charge.Refund (charge.go:11)", "New (charge.go:13)"}) {
+		t.Errorf("billing = %+v", billing)
+	}
+	// Cart is documented; add has only a decorator above it, total is private
+	if web := byPath["apps/web/src"]; web.Exports != 3 || web.Documented != 1 {
+		t.Errorf("web = %+v", web)
+	}
+	if api := byPath["apps/api"]; api.Exports != 2 || api.Documented != 1 || api.Percent != 50 {
+		t.Errorf("api = %+v", api)
+	}
+	if report.Exports != 9 || report.Documented != 4 || report.Percent != 44.4 {
+		t.Errorf("total = %d/%d (%v%%)", report.Documented, report.Exports, report.Percent)
+	}
+	if report.Packages[0].Path != "apps/web/src" {
+		t.Errorf("least documented first, got %s", report.Packages[0].Path)
+	}
+
+	if !reflect.DeepEqual(report.MissingReadmes, []string{"apps/api", "services/billing"}) {
+		t.Errorf("missing READMEs = %v", report.MissingReadmes)
+	}
+
+	want := []Threshold{
+		{Path: "", Min: 60, Percent: 44.4, Passed: false},
+		{Path: "apps/web", Min: 90, Percent: 33.3, Passed: false},
+		{Path: "services/billing", Min: 50, Percent: 50, Passed: true},
+	}
+	if !reflect.DeepEqual(report.Thresholds, want) || report.Failed != 2 {
+		t.Errorf("thresholds = %+v, failed %d", report.Thresholds, report.Failed)
+	}
+
+	// A sub-path is reported on its own; thresholds outside it are skipped
+	sub, err := Analyze(root, "services/billing", types.DocsCoverageConfig{Paths: map[string]float64{"apps/web": 90}})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(sub.Packages) != 1 || sub.Exports != 4 || len(sub.Thresholds) != 0 {
+		t.Errorf("sub-path report = %+v", sub)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
 )
 
 // APIEndpoint represents a REST API endpoint
@@ -17,6 +20,9 @@ type APIEndpoint struct {
 	File       string   `json:"file"`
 	Line       int      `json:"line"`
 	Params     []string `json:"params,omitempty"`
+	Body       string   `json:"body,omitempty"`      // Request body type (DTO, pydantic model, ...)
+	Streaming  string   `json:"streaming,omitempty"` // gRPC: client, server or bidi
+	Spec       string   `json:"spec,omitempty"`      // OpenAPI: documented, undocumented (code only) or missing (spec only)
 }
 
 // KafkaHandler represents a Kafka consumer/producer
@@ -35,6 +41,7 @@ type APISurface struct {
 	Endpoints      []APIEndpoint  `json:"endpoints"`
 	KafkaConsumers []KafkaHandler `json:"kafka_consumers,omitempty"`
 	KafkaProducers []KafkaHandler `json:"kafka_producers,omitempty"`
+	Spec           *SpecCoverage  `json:"spec,omitempty"` // set by MergeOpenAPISpecs
 }
 
 // NestJS/Express patterns
@@ -77,8 +84,65 @@ var (
 	// C# patterns (ASP.NET)
 	aspnetRoutePattern = regexp.MustCompile(`\[Http(Get|Post|Put|Patch|Delete)\s*\(\s*['"]?([^'")\]]*)?['"]?\s*\)\]`)
 	aspnetControllerRoute = regexp.MustCompile(`\[Route\s*\(\s*['"]([^'"]+)['"]\s*\)\]`)
+
+	// Protocol Buffers (gRPC services, google.api.http transcoding)
+	protoCommentPattern = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	protoPackagePattern = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	protoServicePattern = regexp.MustCompile(`\bservice\s+(\w+)\s*\{`)
+	protoRPCPattern     = regexp.MustCompile(`\brpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)`)
+	protoHTTPPattern    = regexp.MustCompile(`\b(get|post|put|patch|delete)\s*:\s*"([^"]+)"`)
+	protoBodyPattern    = regexp.MustCompile(`\bbody\s*:\s*"([^"]*)"`)
+	protoPathParam      = regexp.MustCompile(`\{([\w.]+)`)
+
+	// Request body parameters in handler signatures
+	nestBodyPattern    = regexp.MustCompile(`@Body\(\)\s*\w+\??\s*:\s*([\w.]+)`)
+	springBodyPattern  = regexp.MustCompile(`@RequestBody\s+(?:@\w+\s+)*(?:final\s+)?([\w.]+)`)
+	aspnetBodyPattern  = regexp.MustCompile(`\[FromBody\]\s*([\w.]+)`)
+	pythonParamPattern = regexp.MustCompile(`(\w+)\s*:\s*([A-Z][\w.]*)\s*(=\s*\w+)?`)
 )
 
+// bodyTypeNear returns the body type captured by pattern in the handler
+// signature below a route decorator, stopping at the handler body
+func bodyTypeNear(lines []string, decoratorLine int, pattern *regexp.Regexp) string {
+	for j := decoratorLine + 1; j < len(lines) && j < decoratorLine+10; j++ {
+		if m := pattern.FindStringSubmatch(lines[j]); m != nil {
+			return m[1]
+		}
+		if strings.Contains(lines[j], "{") && !strings.HasPrefix(strings.TrimSpace(lines[j]), "@") {
+			return ""
+		}
+	}
+	return ""
+}
+
+// pythonBodyType returns the first class-typed parameter of a FastAPI
+// handler that is not injected with Depends/Query/Header/..., which FastAPI
+// reads from the request body
+func pythonBodyType(lines []string, defLine int) string {
+	var signature strings.Builder
+	for j := defLine; j < len(lines) && j < defLine+10; j++ {
+		signature.WriteString(lines[j])
+		if strings.HasSuffix(strings.TrimSpace(lines[j]), ":") {
+			break
+		}
+	}
+	sig := signature.String()
+	if open := strings.Index(sig, "("); open >= 0 {
+		sig = sig[open+1:]
+	}
+	for _, m := range pythonParamPattern.FindAllStringSubmatch(sig, -1) {
+		if m[3] != "" {
+			continue // Depends(...), Query(...), Header(...)
+		}
+		switch m[2] {
+		case "Request", "Response", "BackgroundTasks", "Session", "AsyncSession", "WebSocket", "UploadFile":
+			continue
+		}
+		return m[2]
+	}
+	return ""
+}
+
 // ExtractAPISurface extracts API endpoints from a directory
 func ExtractAPISurface(dirPath string, appName string) (*APISurface, error) {
 	surface := &APISurface{
@@ -123,6 +187,8 @@ func ExtractAPISurface(dirPath string, appName string) (*APISurface, error) {
 			extractJavaEndpoints(text, path, surface)
 		case ".cs":
 			extractCSharpEndpoints(text, path, surface)
+		case ".proto":
+			extractProtoEndpoints(text, path, surface)
 		}
 
 		return nil
@@ -150,6 +216,8 @@ func ExtractAPISurfaceFromFile(filePath string) (*APISurface, error) {
 
 	if ext == ".go" {
 		extractGoEndpoints(text, filePath, surface)
+	} else if ext == ".proto" {
+		extractProtoEndpoints(text, filePath, surface)
 	} else {
 		extractTSEndpoints(text, filePath, surface)
 		extractKafkaHandlers(text, filePath, surface)
@@ -188,6 +256,17 @@ func extractTSEndpoints(content string, filePath string, surface *APISurface) {
 		classAuth = m[1]
 	}
 
+	// The skeleton's decorator arguments place each handler under its own
+	// class's prefix and guards, however the decorators are laid out
+	routes := make(map[string]APIEndpoint)
+	if strings.Contains(content, "@Controller") {
+		if sk, err := skeleton.ParseContent(filePath, []byte(content)); err == nil {
+			for _, ep := range NestEndpoints(filePath, sk) {
+				routes[ep.Method+" "+ep.Handler] = ep
+			}
+		}
+	}
+
 	// Find endpoints
 	for i, line := range lines {
 		for method, pattern := range nestMethodPatterns {
@@ -229,20 +308,37 @@ func extractTSEndpoints(content string, filePath string, surface *APISurface) {
 					File:       filePath,
 					Line:       i + 1,
 				}
-
-				// Extract path params
-				paramPattern := regexp.MustCompile(`:(\w+)`)
-				if params := paramPattern.FindAllStringSubmatch(path, -1); params != nil {
-					for _, p := range params {
-						endpoint.Params = append(endpoint.Params, p[1])
-					}
+				if method == "POST" || method == "PUT" || method == "PATCH" {
+					endpoint.Body = bodyTypeNear(lines, i, nestBodyPattern)
 				}
+				if route, ok := routes[method+" "+handlerName]; ok {
+					endpoint.Path = route.Path
+					endpoint.Controller = route.Controller
+					endpoint.Auth = route.Auth
+					delete(routes, method+" "+handlerName)
+				}
+				endpoint.Params = nestRouteParams(endpoint.Path)
 
 				surface.Endpoints = append(surface.Endpoints, endpoint)
 			}
 		}
 	}
 
+	// Routes the line scan can't read: array paths, constants, options
+	// objects. Go through them in file order.
+	var unmatched []APIEndpoint
+	for _, route := range routes {
+		unmatched = append(unmatched, route)
+	}
+	sort.Slice(unmatched, func(i, j int) bool { return unmatched[i].Line < unmatched[j].Line })
+	for _, route := range unmatched {
+		if route.Method == "POST" || route.Method == "PUT" || route.Method == "PATCH" {
+			// Line is the handler's; start looking on it
+			route.Body = bodyTypeNear(lines, route.Line-2, nestBodyPattern)
+		}
+		surface.Endpoints = append(surface.Endpoints, route)
+	}
+
 	// Express routes
 	for _, pattern := range []*regexp.Regexp{expressRouterPattern, expressAppPattern} {
 		matches := pattern.FindAllStringSubmatchIndex(content, -1)
@@ -373,6 +469,7 @@ func extractPythonEndpoints(content string, filePath string, surface *APISurface
 			path := m[2]
 
 			handlerName := ""
+			body := ""
 			for j := i + 1; j < len(lines) && j < i+5; j++ {
 				trimmed := strings.TrimSpace(lines[j])
 				if strings.HasPrefix(trimmed, "async def ") || strings.HasPrefix(trimmed, "def ") {
@@ -384,6 +481,9 @@ func extractPythonEndpoints(content string, filePath string, surface *APISurface
 					if len(parts) > idx {
 						handlerName = strings.Split(parts[idx], "(")[0]
 					}
+					if method == "POST" || method == "PUT" || method == "PATCH" {
+						body = pythonBodyType(lines, j)
+					}
 					break
 				}
 			}
@@ -394,6 +494,7 @@ func extractPythonEndpoints(content string, filePath string, surface *APISurface
 				Handler: handlerName,
 				File:    filePath,
 				Line:    i + 1,
+				Body:    body,
 			})
 		}
 	}
@@ -482,6 +583,7 @@ func extractJavaEndpoints(content string, filePath string, surface *APISurface)
 				Handler: handlerName,
 				File:    filePath,
 				Line:    i + 1,
+				Body:    bodyTypeNear(lines, i, springBodyPattern),
 			})
 		}
 	}
@@ -538,7 +640,117 @@ func extractCSharpEndpoints(content string, filePath string, surface *APISurface
 				Handler: handlerName,
 				File:    filePath,
 				Line:    i + 1,
+				Body:    bodyTypeNear(lines, i, aspnetBodyPattern),
 			})
 		}
 	}
 }
+
+// Protocol Buffers endpoint extraction: every rpc of a service is a gRPC
+// endpoint at /package.Service/Method, its request message as the body.
+// An rpc with a google.api.http option is also served over REST by
+// grpc-gateway or Envoy transcoding, at each path the option binds.
+func extractProtoEndpoints(content string, filePath string, surface *APISurface) {
+	// Blank out comments, keeping offsets for line numbers
+	content = protoCommentPattern.ReplaceAllStringFunc(content, func(c string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, c)
+	})
+
+	pkg := ""
+	if m := protoPackagePattern.FindStringSubmatch(content); m != nil {
+		pkg = m[1] + "."
+	}
+
+	for _, svc := range protoServicePattern.FindAllStringSubmatchIndex(content, -1) {
+		service := content[svc[2]:svc[3]]
+		body := content[svc[1]:protoBlockEnd(content, svc[1]-1)]
+		offset := svc[1]
+
+		for _, rpc := range protoRPCPattern.FindAllStringSubmatchIndex(body, -1) {
+			name := body[rpc[2]:rpc[3]]
+			request := body[rpc[6]:rpc[7]]
+			line := strings.Count(content[:offset+rpc[0]], "\n") + 1
+
+			endpoint := APIEndpoint{
+				Method:     "GRPC",
+				Path:       "/" + pkg + service + "/" + name,
+				Handler:    name,
+				Controller: service,
+				File:       filePath,
+				Line:       line,
+				Body:       request,
+			}
+			clientStream, serverStream := rpc[4] != -1, rpc[8] != -1
+			switch {
+			case clientStream && serverStream:
+				endpoint.Streaming = "bidi"
+			case clientStream:
+				endpoint.Streaming = "client"
+			case serverStream:
+				endpoint.Streaming = "server"
+			}
+			surface.Endpoints = append(surface.Endpoints, endpoint)
+
+			// The rpc's options block, if it has one
+			rest := strings.TrimLeft(body[rpc[1]:], " \t\r\n")
+			if !strings.HasPrefix(rest, "{") {
+				continue
+			}
+			options := rest[:protoBlockEnd(rest, 0)]
+			if !strings.Contains(options, "google.api.http") {
+				continue
+			}
+			restBody := ""
+			if m := protoBodyPattern.FindStringSubmatch(options); m != nil {
+				restBody = m[1]
+				if restBody == "*" {
+					restBody = request
+				}
+			}
+			for _, m := range protoHTTPPattern.FindAllStringSubmatch(options, -1) {
+				rest := APIEndpoint{
+					Method:     strings.ToUpper(m[1]),
+					Path:       m[2],
+					Handler:    name,
+					Controller: service,
+					File:       filePath,
+					Line:       line,
+				}
+				if rest.Method == "POST" || rest.Method == "PUT" || rest.Method == "PATCH" {
+					rest.Body = restBody
+				}
+				for _, p := range protoPathParam.FindAllStringSubmatch(m[2], -1) {
+					rest.Params = append(rest.Params, p[1])
+				}
+				surface.Endpoints = append(surface.Endpoints, rest)
+			}
+		}
+	}
+}
+
+// protoBlockEnd returns the index of the brace closing the one at open,
+// or the end of content if it is not closed
+func protoBlockEnd(content string, open int) int {
+	depth := 0
+	for i := open; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"':
+			if end := strings.IndexByte(content[i+1:], '"'); end >= 0 {
+				i += end + 1
+			}
+		}
+	}
+	return len(content)
+}
@@ -0,0 +1,134 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const paymentsProto = `syntax = "proto3";
+
+package billing.v1;
+
+import "google/api/annotations.proto";
+
+// rpc Legacy(Old) returns (Old); is gone
+service Payments {
+  rpc Charge(ChargeRequest) returns (Charge) {
+    option (google.api.http) = {
+      post: "/v1/charges"
+      body: "*"
+      additional_bindings { put: "/v1/charges/{charge_id}" body: "*" }
+    };
+  }
+  rpc GetCharge(GetChargeRequest) returns (Charge) {
+    option (google.api.http) = { get: "/v1/charges/{charge_id}" };
+  }
+  rpc WatchCharges(WatchRequest) returns (stream Charge);
+  rpc Sync(stream SyncEvent) returns (stream SyncEvent);
+}
+
+message Charge {
+  string id = 1;
+}
+`
+
+func TestProtoEndpoints(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "payments.proto"), []byte(paymentsProto), 0644); err != nil {
+		t.Fatal(err)
+	}
+	surface, err := ExtractAPISurface(dir, "billing")
+	if err != nil {
+		t.Fatalf("ExtractAPISurface: %v", err)
+	}
+
+	byRoute := make(map[string]APIEndpoint)
+	for _, ep := range surface.Endpoints {
+		byRoute[ep.Method+" "+ep.Path] = ep
+	}
+	if len(surface.Endpoints) != 7 {
+		t.Errorf("endpoints = %+v, want 4 rpcs and 3 REST bindings", surface.Endpoints)
+	}
+
+	charge, ok := byRoute["GRPC /billing.v1.Payments/Charge"]
+	if !ok || charge.Handler != "Charge" || charge.Controller != "Payments" || charge.Body != "ChargeRequest" || charge.Line != 9 {
+		t.Errorf("Charge = %+v", charge)
+	}
+	if watch := byRoute["GRPC /billing.v1.Payments/WatchCharges"]; watch.Streaming != "server" {
+		t.Errorf("WatchCharges streaming = %q, want server", watch.Streaming)
+	}
+	if sync := byRoute["GRPC /billing.v1.Payments/Sync"]; sync.Streaming != "bidi" {
+		t.Errorf("Sync streaming = %q, want bidi", sync.Streaming)
+	}
+
+	if post := byRoute["POST /v1/charges"]; post.Handler != "Charge" || post.Body != "ChargeRequest" {
+		t.Errorf("POST /v1/charges = %+v", post)
+	}
+	if put := byRoute["PUT /v1/charges/{charge_id}"]; put.Handler != "Charge" || len(put.Params) != 1 || put.Params[0] != "charge_id" {
+		t.Errorf("PUT binding = %+v", put)
+	}
+	if get := byRoute["GET /v1/charges/{charge_id}"]; get.Handler != "GetCharge" || get.Body != "" {
+		t.Errorf("GET binding = %+v", get)
+	}
+
+	// Only the REST bindings can be exported as requests
+	collection, err := BuildRequestCollection(surface, nil, CollectionOptions{})
+	if err != nil {
+		t.Fatalf("BuildRequestCollection: %v", err)
+	}
+	if collection.Requests != 3 {
+		t.Errorf("collection has %d requests, want the 3 REST bindings", collection.Requests)
+	}
+}
+
+const ordersController = `@Controller({ path: 'orders', version: '1' })
+@UseGuards(JwtAuthGuard)
+export class OrdersController {
+  @Get(':id')
+  findOne(@Param('id', ParseIntPipe) id: number) {
+    return this.orders.find(id);
+  }
+
+  @Public()
+  @Post(['checkout', 'buy'])
+  @UseGuards(ThrottlerGuard)
+  checkout(@Body() dto: CheckoutDto) {
+    return this.orders.checkout(dto);
+  }
+}
+
+@Controller('admin/orders')
+export class AdminOrdersController {
+  @Delete(':id')
+  remove(@Param('id') id: string) {}
+}
+`
+
+func TestNestEndpointsFromDecorators(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "orders.controller.ts"), []byte(ordersController), 0644); err != nil {
+		t.Fatal(err)
+	}
+	surface, err := ExtractAPISurface(dir, "shop")
+	if err != nil {
+		t.Fatalf("ExtractAPISurface: %v", err)
+	}
+
+	byRoute := make(map[string]APIEndpoint)
+	for _, ep := range surface.Endpoints {
+		byRoute[ep.Method+" "+ep.Path] = ep
+	}
+	if len(surface.Endpoints) != 3 {
+		t.Errorf("endpoints = %+v", surface.Endpoints)
+	}
+	if ep, ok := byRoute["GET /orders/:id"]; !ok || ep.Controller != "OrdersController" || ep.Auth != "JwtAuthGuard" || len(ep.Params) != 1 || ep.Params[0] != "id" {
+		t.Errorf("GET /orders/:id = %+v", ep)
+	}
+	if ep, ok := byRoute["POST /orders/checkout"]; !ok || ep.Handler != "checkout" || ep.Auth != "ThrottlerGuard" || ep.Body != "CheckoutDto" || ep.Line != 12 {
+		t.Errorf("POST /orders/checkout = %+v", ep)
+	}
+	if ep, ok := byRoute["DELETE /admin/orders/:id"]; !ok || ep.Controller != "AdminOrdersController" || ep.Auth != "" {
+		t.Errorf("DELETE /admin/orders/:id = %+v", ep)
+	}
+}
@@ -0,0 +1,811 @@
+package extractor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Endpoint protection in an auth matrix
+const (
+	AuthGuarded   = "guarded"   // an authentication or authorization check applies
+	AuthPublic    = "public"    // explicitly opened: @Public(), [AllowAnonymous], @PermitAll, AllowAny
+	AuthUnguarded = "unguarded" // no check found
+)
+
+// AuthMatrixEntry is one endpoint and the checks applied to it
+type AuthMatrixEntry struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Handler    string `json:"handler,omitempty"`
+	Controller string `json:"controller,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	// Authentication lists guards, middleware and decorators that check who calls
+	Authentication []string `json:"authentication,omitempty"`
+	// Authorization lists role, permission and policy checks
+	Authorization []string `json:"authorization,omitempty"`
+	// Public is the marker that opens the endpoint
+	Public string `json:"public,omitempty"`
+	Status string `json:"status"`
+}
+
+// AuthMatrix is the API surface with the authentication and authorization
+// each endpoint gets, from its own decorators, its controller, router or
+// group middleware, and guards registered for the whole app
+type AuthMatrix struct {
+	App       string            `json:"app"`
+	Endpoints []AuthMatrixEntry `json:"endpoints"`
+	Guarded   int               `json:"guarded"`
+	Public    int               `json:"public"`
+	Unguarded int               `json:"unguarded"`
+	// Global lists app-wide guards that apply to every endpoint
+	Global []string `json:"global,omitempty"`
+	// Mechanisms counts the endpoints each check applies to
+	Mechanisms map[string]int `json:"mechanisms,omitempty"`
+}
+
+// AuthMatrixOptions configures BuildAuthMatrix
+type AuthMatrixOptions struct {
+	// Dir is searched for app-wide guards (APP_GUARD, useGlobalGuards,
+	// Spring Security anyRequest(), ASP.NET FallbackPolicy)
+	Dir string
+	// Root makes endpoint file paths relative
+	Root string
+}
+
+// authMark is one check found for an endpoint
+type authMark struct {
+	kind  string // authn, authz or public
+	label string
+}
+
+var (
+	publicMarkerRe = regexp.MustCompile(`(?i)^(?:public|is_?public|skip_?auth|no_?auth|allow_?anonymous|anonymous|permit_?all|allow_?any|unauthenticated|unprotected)$`)
+	authzNameRe    = regexp.MustCompile(`(?i)role|permission|polic|scopes|require_?scope|abilit|acl|authoriz|secured|admin|owner|grant|casl|deny_?all`)
+	authnNameRe    = regexp.MustCompile(`(?i)auth|jwt|login|token|passport|bearer|api_?key|protect|current_?(?:active_?)?user|signed_?in|logged_?in|verify|principal|credential|oauth|oidc`)
+	// Swagger decorators (@ApiBearerAuth) document auth without enforcing it
+	swaggerDecoratorRe = regexp.MustCompile(`^Api[A-Z]`)
+
+	// Decorators and attributes whose arguments are the checks
+	authContainers = map[string]bool{
+		"UseGuards": true, "permission_classes": true, "authentication_classes": true,
+	}
+
+	routeCallPattern  = regexp.MustCompile(`(\w+)\.(?i:get|post|put|patch|delete)\s*\(`)
+	useCallPattern    = regexp.MustCompile(`(\w+)\.(?:Use|use)\s*\(`)
+	goGroupPattern    = regexp.MustCompile(`(\w+)\s*:?=\s*(\w+)\.Group\s*\(`)
+	dependsPattern    = regexp.MustCompile(`\b(?:Depends|Security)\s*\(`)
+	fastapiAppPattern = regexp.MustCompile(`\b(?:APIRouter|FastAPI)\s*\(`)
+	classDeclPattern  = regexp.MustCompile(`^\s*(?:export\s+)?(?:(?:public|internal|abstract|sealed|partial|final|default)\s+)*class\s+\w+`)
+	argNamePattern    = regexp.MustCompile(`^(?:new\s+)?([\w.]+)`)
+	callNamePattern   = regexp.MustCompile(`([\w.]+)\s*\(`)
+
+	nestAppGuardPattern    = regexp.MustCompile(`provide:\s*APP_GUARD\s*,\s*useClass:\s*(\w+)`)
+	nestGlobalGuardPattern = regexp.MustCompile(`useGlobalGuards\s*\(`)
+	springAnyRequestAuth   = regexp.MustCompile(`anyRequest\(\)\s*\.\s*(authenticated|hasRole|hasAnyRole|hasAuthority|hasAnyAuthority)\(`)
+	aspnetFallbackPolicy   = regexp.MustCompile(`FallbackPolicy\s*=|MapControllers\(\)\s*\.\s*RequireAuthorization\(`)
+)
+
+// BuildAuthMatrix finds the authentication and authorization applied to
+// each endpoint of an API surface. Method-level markers win over controller
+// and router ones: @Public() on a handler opens it even under a class or
+// global guard. Checks configured outside the endpoint's file other than
+// the app-wide guards in opts.Dir (middleware mounted on a router in
+// another file, Spring Security request matchers) are not seen.
+func BuildAuthMatrix(surface *APISurface, opts AuthMatrixOptions) *AuthMatrix {
+	matrix := &AuthMatrix{App: surface.App, Endpoints: []AuthMatrixEntry{}, Mechanisms: make(map[string]int)}
+
+	var global map[string][]authMark
+	if opts.Dir != "" {
+		global = globalAuthMarks(opts.Dir)
+	}
+	for _, ext := range []string{".ts", ".java", ".cs"} {
+		for _, m := range global[ext] {
+			matrix.Global = append(matrix.Global, m.label)
+		}
+	}
+
+	files := make(map[string]*authFile)
+	seen := make(map[string]bool)
+	for _, ep := range surface.Endpoints {
+		// gRPC auth lives in interceptors, not in the .proto contract
+		if ep.Method == "GRPC" {
+			continue
+		}
+		// @app.post is matched as both Flask and FastAPI
+		key := fmt.Sprintf("%s:%d:%s", ep.File, ep.Line, ep.Method)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		af, ok := files[ep.File]
+		if !ok {
+			af = readAuthFile(ep.File)
+			files[ep.File] = af
+		}
+		entry := AuthMatrixEntry{
+			Method:     ep.Method,
+			Path:       ep.Path,
+			Handler:    ep.Handler,
+			Controller: ep.Controller,
+			File:       ep.File,
+			Line:       ep.Line,
+		}
+		if opts.Root != "" {
+			if rel, err := filepath.Rel(opts.Root, ep.File); err == nil {
+				entry.File = filepath.ToSlash(rel)
+			}
+		}
+		// App-wide guards cover the endpoints of their own framework
+		ext := strings.ToLower(filepath.Ext(ep.File))
+		if ext == ".js" {
+			ext = ".ts"
+		}
+		if af != nil {
+			applyAuthMarks(&entry, af.endpointMarks(ep.Line-1), af.outerMarks(ep.Line-1), global[ext])
+		} else {
+			applyAuthMarks(&entry, nil, nil, global[ext])
+		}
+
+		switch entry.Status {
+		case AuthGuarded:
+			matrix.Guarded++
+		case AuthPublic:
+			matrix.Public++
+		default:
+			matrix.Unguarded++
+		}
+		for _, label := range append(append([]string{}, entry.Authentication...), entry.Authorization...) {
+			matrix.Mechanisms[label]++
+		}
+		matrix.Endpoints = append(matrix.Endpoints, entry)
+	}
+
+	sort.SliceStable(matrix.Endpoints, func(i, j int) bool {
+		a, b := matrix.Endpoints[i], matrix.Endpoints[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return matrix
+}
+
+// applyAuthMarks sets the checks and status of an endpoint from the marks
+// on the handler itself, on its controller, router or group, and app-wide
+func applyAuthMarks(entry *AuthMatrixEntry, own, outer, global []authMark) {
+	if public := findMark(own, "public"); public != "" {
+		entry.Public = public
+		entry.Status = AuthPublic
+		return
+	}
+	if !hasCheck(own) {
+		if public := findMark(outer, "public"); public != "" {
+			entry.Public = public
+			entry.Status = AuthPublic
+			return
+		}
+	}
+	marks := append(append(append([]authMark{}, own...), outer...), global...)
+
+	seen := make(map[string]bool)
+	for _, m := range marks {
+		if seen[m.kind+m.label] {
+			continue
+		}
+		seen[m.kind+m.label] = true
+		switch m.kind {
+		case "authn":
+			entry.Authentication = append(entry.Authentication, m.label)
+		case "authz":
+			entry.Authorization = append(entry.Authorization, m.label)
+		}
+	}
+	if len(entry.Authentication) > 0 || len(entry.Authorization) > 0 {
+		entry.Status = AuthGuarded
+	} else {
+		entry.Status = AuthUnguarded
+	}
+}
+
+func findMark(marks []authMark, kind string) string {
+	for _, m := range marks {
+		if m.kind == kind {
+			return m.label
+		}
+	}
+	return ""
+}
+
+func hasCheck(marks []authMark) bool {
+	for _, m := range marks {
+		if m.kind == "authn" || m.kind == "authz" {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyAuthName says whether a decorator, guard or middleware name is
+// an authentication check, an authorization check or a public marker
+func classifyAuthName(name string) string {
+	short := name[strings.LastIndex(name, ".")+1:]
+	switch {
+	case publicMarkerRe.MatchString(short):
+		return "public"
+	case short == "Authorize" || strings.EqualFold(short, "IsAuthenticated") || short == "Authenticated":
+		// Bare [Authorize] only requires a signed-in user
+		return "authn"
+	case authzNameRe.MatchString(name):
+		return "authz"
+	case authnNameRe.MatchString(name):
+		return "authn"
+	}
+	return ""
+}
+
+// markFor classifies a name and labels it; "" kind when it is no check
+func markFor(name, label string) authMark {
+	return authMark{kind: classifyAuthName(name), label: label}
+}
+
+// authFile is a source file read once for every endpoint it declares
+type authFile struct {
+	ext        string
+	lines      []string
+	content    string
+	lineStarts []int
+	classes    []int // 0-based class declaration lines
+	fileMarks  []authMark
+	useEvents  []useEvent
+}
+
+// useEvent is middleware attached to a router or group at an offset:
+// router.use(auth), api.Use(AuthRequired()), or admin := api.Group("/admin", auth)
+type useEvent struct {
+	offset   int
+	receiver string
+	parent   string
+	marks    []authMark
+}
+
+func readAuthFile(path string) *authFile {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	af := &authFile{
+		ext:     strings.ToLower(filepath.Ext(path)),
+		content: string(content),
+	}
+	af.lines = strings.Split(af.content, "\n")
+	offset := 0
+	for i, line := range af.lines {
+		af.lineStarts = append(af.lineStarts, offset)
+		offset += len(line) + 1
+		if classDeclPattern.MatchString(line) {
+			af.classes = append(af.classes, i)
+		}
+	}
+
+	switch af.ext {
+	case ".go", ".ts", ".js":
+		af.useEvents = parseUseEvents(af.content)
+	case ".py":
+		// FastAPI(dependencies=[...]) and APIRouter(dependencies=[...])
+		for _, loc := range fastapiAppPattern.FindAllStringIndex(af.content, -1) {
+			inner, _ := balancedParens(af.content, loc[1]-1)
+			af.fileMarks = append(af.fileMarks, dependsMarks(inner)...)
+		}
+	}
+	return af
+}
+
+// endpointMarks returns the checks declared on the handler whose route is
+// on line i: its decorators, annotations or attributes, its route
+// middleware, and FastAPI dependencies in its signature
+func (af *authFile) endpointMarks(i int) []authMark {
+	if i < 0 || i >= len(af.lines) {
+		return nil
+	}
+	switch af.ext {
+	case ".go":
+		return af.routeMiddleware(i)
+	case ".ts", ".js":
+		if marks := af.routeMiddleware(i); marks != nil {
+			return marks
+		}
+		return decoratorMarks(af.decoratorBlock(i), '@')
+	case ".py":
+		if !strings.HasPrefix(strings.TrimSpace(af.lines[i]), "@") {
+			// Django: path("admin/", login_required(view))
+			var marks []authMark
+			for _, m := range callNamePattern.FindAllStringSubmatch(af.lines[i], -1) {
+				if mark := markFor(m[1], m[1]); mark.kind != "" {
+					marks = append(marks, mark)
+				}
+			}
+			return marks
+		}
+		block := af.pythonBlock(i)
+		marks := decoratorMarks(block, '@')
+		return append(marks, dependsMarks(strings.Join(block, "\n"))...)
+	case ".java":
+		return decoratorMarks(af.decoratorBlock(i), '@')
+	case ".cs":
+		return decoratorMarks(af.decoratorBlock(i), '[')
+	}
+	return nil
+}
+
+// outerMarks returns the checks that cover the handler on line i from
+// outside it: class-level decorators and attributes, router and group
+// middleware registered before it, and FastAPI router dependencies
+func (af *authFile) outerMarks(i int) []authMark {
+	marks := append([]authMark{}, af.fileMarks...)
+	class := -1
+	for _, c := range af.classes {
+		if c <= i {
+			class = c
+		}
+	}
+	if class >= 0 {
+		open := byte('@')
+		if af.ext == ".cs" {
+			open = '['
+		}
+		marks = append(marks, decoratorMarks(af.linesAbove(class), open)...)
+	}
+	if len(af.useEvents) > 0 {
+		if m := routeCallPattern.FindStringSubmatch(af.lines[i]); m != nil {
+			marks = append(marks, middlewareFor(af.useEvents, m[1], af.lineStarts[i], 0)...)
+		}
+	}
+	return marks
+}
+
+// routeMiddleware classifies the middleware passed to an Express, gin or
+// echo route call before its handler. Returns nil when line i is not a
+// route call.
+func (af *authFile) routeMiddleware(i int) []authMark {
+	loc := routeCallPattern.FindStringIndex(af.lines[i])
+	if loc == nil {
+		return nil
+	}
+	inner, _ := balancedParens(af.content, af.lineStarts[i]+loc[1]-1)
+	args := splitArgs(inner)
+	marks := []authMark{}
+	for n, arg := range args {
+		if n == 0 {
+			continue // the path
+		}
+		m := argNamePattern.FindStringSubmatch(arg)
+		if m == nil {
+			continue
+		}
+		// The last argument is the handler; it only counts when it wraps
+		// one: RequireAuth(h.Get)
+		if n == len(args)-1 && !strings.Contains(arg, "(") {
+			continue
+		}
+		if mark := markFor(m[1], argLabel(arg, m[1])); mark.kind != "" {
+			marks = append(marks, mark)
+		}
+	}
+	return marks
+}
+
+// decoratorBlock returns the lines stacked on a handler around its route
+// decorator on line i: decorators above it, and those below it down to the
+// handler signature
+func (af *authFile) decoratorBlock(i int) []string {
+	block := af.linesAbove(i)
+	block = append(block, af.lines[i])
+	for j := i + 1; j < len(af.lines) && j < i+12; j++ {
+		trimmed := strings.TrimSpace(af.lines[j])
+		if trimmed == "" {
+			break
+		}
+		block = append(block, af.lines[j])
+		if strings.HasSuffix(trimmed, "{") || strings.HasSuffix(trimmed, ";") || strings.HasSuffix(trimmed, "=>") {
+			break
+		}
+	}
+	return block
+}
+
+// linesAbove returns the decorator lines directly above line i, stopping at
+// the end of the previous member
+func (af *authFile) linesAbove(i int) []string {
+	start := i
+	for j := i - 1; j >= 0 && j > i-12; j-- {
+		trimmed := strings.TrimSpace(af.lines[j])
+		if trimmed == "" || strings.HasSuffix(trimmed, "}") || strings.HasSuffix(trimmed, "{") || strings.HasSuffix(trimmed, ";") {
+			break
+		}
+		start = j
+	}
+	return af.lines[start:i]
+}
+
+// pythonBlock returns the decorators above and below a route decorator and
+// the handler signature, where FastAPI dependencies are declared
+func (af *authFile) pythonBlock(i int) []string {
+	start := i
+	for j := i - 1; j >= 0 && j > i-10; j-- {
+		if !strings.HasPrefix(strings.TrimSpace(af.lines[j]), "@") {
+			break
+		}
+		start = j
+	}
+	end := i
+	inSignature := false
+	for j := i + 1; j < len(af.lines) && j < i+15; j++ {
+		trimmed := strings.TrimSpace(af.lines[j])
+		end = j
+		if strings.HasPrefix(trimmed, "def ") || strings.HasPrefix(trimmed, "async def ") {
+			inSignature = true
+		}
+		if inSignature && strings.HasSuffix(trimmed, ":") {
+			break
+		}
+	}
+	return af.lines[start : end+1]
+}
+
+// decoratorMarks classifies the decorators (open '@') or attributes
+// (open '[') in lines. Comment lines are skipped.
+func decoratorMarks(lines []string, open byte) []authMark {
+	var marks []authMark
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") || (open == '@' && strings.HasPrefix(trimmed, "#")) {
+			continue
+		}
+		for i := 0; i < len(trimmed); i++ {
+			if trimmed[i] != open {
+				continue
+			}
+			m := argNamePattern.FindStringSubmatch(trimmed[i+1:])
+			if m == nil {
+				continue
+			}
+			name := m[1]
+			if open == '@' && swaggerDecoratorRe.MatchString(name) {
+				continue
+			}
+			end := i + 1 + len(m[0])
+			args := ""
+			if end < len(trimmed) && trimmed[end] == '(' {
+				args, _ = balancedParens(trimmed, end)
+			}
+
+			if authContainers[name] {
+				for _, arg := range splitArgs(strings.Trim(args, "[] ")) {
+					arg = strings.Trim(arg, "[] ")
+					if am := argNamePattern.FindStringSubmatch(arg); am != nil {
+						if mark := markFor(am[1], argLabel(arg, am[1])); mark.kind != "" {
+							marks = append(marks, mark)
+						}
+					}
+				}
+				continue
+			}
+
+			label := string(open) + name
+			if args != "" {
+				label += "(" + shortArgs(args) + ")"
+			}
+			if open == '[' {
+				label += "]"
+			}
+			// [Authorize(Roles = "Admin")] authenticates and authorizes
+			if name == "Authorize" && args != "" {
+				marks = append(marks, authMark{kind: "authn", label: "[Authorize]"}, authMark{kind: "authz", label: label})
+				continue
+			}
+			if mark := markFor(name, label); mark.kind != "" {
+				marks = append(marks, mark)
+			}
+		}
+	}
+	return marks
+}
+
+// dependsMarks classifies FastAPI Depends(...) and Security(...) arguments
+func dependsMarks(text string) []authMark {
+	var marks []authMark
+	for _, loc := range dependsPattern.FindAllStringIndex(text, -1) {
+		inner, _ := balancedParens(text, loc[1]-1)
+		args := splitArgs(inner)
+		if len(args) == 0 {
+			continue
+		}
+		m := argNamePattern.FindStringSubmatch(args[0])
+		if m == nil {
+			continue
+		}
+		mark := markFor(m[1], "Depends("+m[1]+")")
+		if strings.HasPrefix(text[loc[0]:], "Security") {
+			mark.label = "Security(" + m[1] + ")"
+			if mark.kind == "" {
+				mark.kind = "authn"
+			}
+		}
+		if mark.kind != "" {
+			marks = append(marks, mark)
+		}
+	}
+	return marks
+}
+
+// parseUseEvents finds middleware attached to routers and groups:
+// router.use(...), r.Use(...) and v1 := r.Group("/v1", ...)
+func parseUseEvents(content string) []useEvent {
+	var events []useEvent
+	for _, loc := range useCallPattern.FindAllStringSubmatchIndex(content, -1) {
+		inner, _ := balancedParens(content, loc[1]-1)
+		events = append(events, useEvent{
+			offset:   loc[0],
+			receiver: content[loc[2]:loc[3]],
+			marks:    middlewareMarks(splitArgs(inner)),
+		})
+	}
+	for _, loc := range goGroupPattern.FindAllStringSubmatchIndex(content, -1) {
+		inner, _ := balancedParens(content, loc[1]-1)
+		args := splitArgs(inner)
+		if len(args) > 0 {
+			args = args[1:] // the prefix
+		}
+		events = append(events, useEvent{
+			offset:   loc[0],
+			receiver: content[loc[2]:loc[3]],
+			parent:   content[loc[4]:loc[5]],
+			marks:    middlewareMarks(args),
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].offset < events[j].offset })
+	return events
+}
+
+func middlewareMarks(args []string) []authMark {
+	var marks []authMark
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "'") || strings.HasPrefix(arg, "\"") || strings.HasPrefix(arg, "`") {
+			continue // app.use('/admin', ...) mount path
+		}
+		m := argNamePattern.FindStringSubmatch(arg)
+		if m == nil {
+			continue
+		}
+		if mark := markFor(m[1], argLabel(arg, m[1])); mark.kind != "" {
+			marks = append(marks, mark)
+		}
+	}
+	return marks
+}
+
+// middlewareFor returns the middleware a router or group has at offset,
+// including what the group it was created from had then
+func middlewareFor(events []useEvent, receiver string, offset, depth int) []authMark {
+	if depth > 5 {
+		return nil
+	}
+	var marks []authMark
+	for _, ev := range events {
+		if ev.offset >= offset || ev.receiver != receiver {
+			continue
+		}
+		if ev.parent != "" {
+			// Assigning a new group replaces what the variable had
+			marks = nil
+			if ev.parent != receiver {
+				marks = middlewareFor(events, ev.parent, ev.offset, depth+1)
+			}
+		}
+		marks = append(marks, ev.marks...)
+	}
+	return marks
+}
+
+// globalAuthMarks finds guards registered for a whole app under dir, by
+// the extension of the files they cover
+func globalAuthMarks(dir string) map[string][]authMark {
+	marks := make(map[string][]authMark)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if name == "node_modules" || name == "dist" || name == ".git" ||
+				name == "vendor" || name == "target" || name == "bin" || name == "obj" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".ts" && ext != ".java" && ext != ".cs" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		text := string(content)
+		where := " (global, " + filepath.Base(path) + ")"
+
+		switch ext {
+		case ".ts":
+			for _, m := range nestAppGuardPattern.FindAllStringSubmatch(text, -1) {
+				if mark := markFor(m[1], m[1]+where); mark.kind == "authn" || mark.kind == "authz" {
+					marks[ext] = append(marks[ext], mark)
+				}
+			}
+			for _, loc := range nestGlobalGuardPattern.FindAllStringIndex(text, -1) {
+				inner, _ := balancedParens(text, loc[1]-1)
+				for _, arg := range splitArgs(inner) {
+					if m := argNamePattern.FindStringSubmatch(arg); m != nil {
+						if mark := markFor(m[1], m[1]+where); mark.kind == "authn" || mark.kind == "authz" {
+							marks[ext] = append(marks[ext], mark)
+						}
+					}
+				}
+			}
+		case ".java":
+			if m := springAnyRequestAuth.FindStringSubmatch(text); m != nil {
+				kind := "authz"
+				if m[1] == "authenticated" {
+					kind = "authn"
+				}
+				marks[ext] = append(marks[ext], authMark{kind: kind, label: "anyRequest()." + m[1] + "()" + where})
+			}
+		case ".cs":
+			if aspnetFallbackPolicy.MatchString(text) {
+				marks[ext] = append(marks[ext], authMark{kind: "authn", label: "FallbackPolicy" + where})
+			}
+		}
+		return nil
+	})
+	return marks
+}
+
+// balancedParens returns what is inside the parenthesis opening at s[open]
+// and the offset after its closing one. Unbalanced input runs to the end.
+func balancedParens(s string, open int) (string, int) {
+	if open < 0 || open >= len(s) || s[open] != '(' {
+		return "", open
+	}
+	depth := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[open+1 : i], i + 1
+			}
+		}
+	}
+	return s[open+1:], len(s)
+}
+
+// splitArgs splits an argument list on its top-level commas
+func splitArgs(s string) []string {
+	var args []string
+	depth := 0
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		args = append(args, last)
+	}
+	return args
+}
+
+// argLabel labels a middleware argument: the call as written when short,
+// otherwise its name
+func argLabel(arg, name string) string {
+	arg = strings.Join(strings.Fields(arg), " ")
+	if len(arg) <= 40 {
+		return arg
+	}
+	return name
+}
+
+// shortArgs keeps decorator arguments readable in labels
+func shortArgs(args string) string {
+	args = strings.Join(strings.Fields(args), " ")
+	if len(args) > 40 {
+		return args[:37] + "..."
+	}
+	return args
+}
+
+// UnguardedEndpoints returns the endpoints with no check found
+func (m *AuthMatrix) UnguardedEndpoints() []AuthMatrixEntry {
+	var unguarded []AuthMatrixEntry
+	for _, e := range m.Endpoints {
+		if e.Status == AuthUnguarded {
+			unguarded = append(unguarded, e)
+		}
+	}
+	return unguarded
+}
+
+// FormatAuthMatrixMarkdown renders the matrix as a markdown table for
+// security reviews, unguarded endpoints flagged
+func FormatAuthMatrixMarkdown(m *AuthMatrix) string {
+	var sb strings.Builder
+	sb.WriteString("# Endpoint auth matrix: " + m.App + "\n\n")
+	sb.WriteString(fmt.Sprintf("%d endpoints: %d guarded, %d public, %d unguarded\n\n", len(m.Endpoints), m.Guarded, m.Public, m.Unguarded))
+	if len(m.Global) > 0 {
+		sb.WriteString("Global: " + strings.Join(m.Global, ", ") + "\n\n")
+	}
+	sb.WriteString("| Status | Method | Path | Authentication | Authorization | Handler |\n")
+	sb.WriteString("|--------|--------|------|----------------|---------------|---------|\n")
+	for _, e := range m.Endpoints {
+		status := e.Status
+		switch e.Status {
+		case AuthUnguarded:
+			status = "**UNGUARDED**"
+		case AuthPublic:
+			status = "public (" + e.Public + ")"
+		}
+		handler := fmt.Sprintf("%s:%d", e.File, e.Line)
+		if e.Handler != "" {
+			handler = e.Handler + " (" + handler + ")"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | `%s` | %s | %s | %s |\n",
+			status, e.Method, e.Path, markdownCell(e.Authentication), markdownCell(e.Authorization), handler))
+	}
+	return sb.String()
+}
+
+func markdownCell(labels []string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	return strings.ReplaceAll(strings.Join(labels, ", "), "|", "\\|")
+}
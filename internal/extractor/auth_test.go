@@ -0,0 +1,327 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// authMatrixFor writes files into a fresh project and builds its auth matrix
+func authMatrixFor(t *testing.T, files map[string]string) *AuthMatrix {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	surface, err := ExtractAPISurface(dir, "app")
+	if err != nil {
+		t.Fatalf("ExtractAPISurface: %v", err)
+	}
+	return BuildAuthMatrix(surface, AuthMatrixOptions{Dir: dir, Root: dir})
+}
+
+// authWant is the expected protection of one endpoint
+type authWant struct {
+	status string
+	authn  []string
+	authz  []string
+	public string
+}
+
+// checkAuthMatrix compares the endpoints of a matrix, keyed "METHOD path",
+// with the expected protection
+func checkAuthMatrix(t *testing.T, m *AuthMatrix, want map[string]authWant) {
+	t.Helper()
+	got := make(map[string]authWant)
+	for _, e := range m.Endpoints {
+		got[e.Method+" "+e.Path] = authWant{status: e.Status, authn: e.Authentication, authz: e.Authorization, public: e.Public}
+	}
+	for key, w := range want {
+		g, ok := got[key]
+		if !ok {
+			t.Errorf("%s: not in the matrix (have %v)", key, got)
+			continue
+		}
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("%s = %+v, want %+v", key, g, w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("matrix has %d endpoints, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestAuthMatrixFrameworks(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]authWant
+	}{
+		{
+			name: "nest",
+			files: map[string]string{
+				"src/users.controller.ts": `import { Controller, Get, Delete, UseGuards } from '@nestjs/common';
+
+@Controller('users')
+@UseGuards(JwtAuthGuard)
+export class UsersController {
+  @Get()
+  findAll() {}
+
+  @Public()
+  @Get('count')
+  count() {}
+
+  @Delete(':id')
+  @Roles('admin')
+  remove() {}
+}
+`,
+				"src/health.controller.ts": `@Controller('health')
+@ApiBearerAuth()
+export class HealthController {
+  @Get()
+  check() {}
+}
+`,
+			},
+			want: map[string]authWant{
+				"GET /users":        {status: AuthGuarded, authn: []string{"JwtAuthGuard"}},
+				"GET /users/count":  {status: AuthPublic, public: "@Public"},
+				"DELETE /users/:id": {status: AuthGuarded, authn: []string{"JwtAuthGuard"}, authz: []string{"@Roles('admin')"}},
+				// Swagger decorators document auth without enforcing it
+				"GET /health": {status: AuthUnguarded},
+			},
+		},
+		{
+			name: "nest app guard",
+			files: map[string]string{
+				"src/app.module.ts": `@Module({
+  providers: [{ provide: APP_GUARD, useClass: ApiKeyAuthGuard }],
+})
+export class AppModule {}
+`,
+				"src/health.controller.ts": `@Controller('health')
+export class HealthController {
+  @Get()
+  check() {}
+
+  @Public()
+  @Get('live')
+  live() {}
+}
+`,
+			},
+			want: map[string]authWant{
+				"GET /health":      {status: AuthGuarded, authn: []string{"ApiKeyAuthGuard (global, app.module.ts)"}},
+				"GET /health/live": {status: AuthPublic, public: "@Public"},
+			},
+		},
+		{
+			name: "express",
+			files: map[string]string{
+				"routes.js": `const router = express.Router();
+
+router.get('/status', status);
+router.post('/orders', requireAuth, createOrder);
+
+router.use(authenticate);
+router.get('/me', me);
+router.delete('/orders/:id', checkPermission('orders:delete'), deleteOrder);
+`,
+			},
+			want: map[string]authWant{
+				"GET /status":        {status: AuthUnguarded},
+				"POST /orders":       {status: AuthGuarded, authn: []string{"requireAuth"}},
+				"GET /me":            {status: AuthGuarded, authn: []string{"authenticate"}},
+				"DELETE /orders/:id": {status: AuthGuarded, authn: []string{"authenticate"}, authz: []string{"checkPermission('orders:delete')"}},
+			},
+		},
+		{
+			name: "gin",
+			files: map[string]string{
+				"routes.go": `package routes
+
+func Register(r *gin.Engine) {
+	r.GET("/health", health)
+	g := r.Group("/api", AuthRequired())
+	g.GET("/me", me)
+	g.DELETE("/users/:id", RequireRole("admin"), deleteUser)
+}
+`,
+			},
+			want: map[string]authWant{
+				"GET /health":       {status: AuthUnguarded},
+				"GET /me":           {status: AuthGuarded, authn: []string{"AuthRequired()"}},
+				"DELETE /users/:id": {status: AuthGuarded, authn: []string{"AuthRequired()"}, authz: []string{`RequireRole("admin")`}},
+			},
+		},
+		{
+			name: "fastapi",
+			files: map[string]string{
+				"main.py": `from fastapi import APIRouter, Depends, Security
+
+router = APIRouter()
+
+
+@router.get("/items")
+def list_items():
+    pass
+
+
+@router.post("/items")
+def create_item(user=Depends(get_current_user)):
+    pass
+
+
+@router.delete("/items/{id}")
+def delete_item(id: int, user=Security(check_scopes, scopes=["items:write"])):
+    pass
+`,
+			},
+			want: map[string]authWant{
+				"GET /items":         {status: AuthUnguarded},
+				"POST /items":        {status: AuthGuarded, authn: []string{"Depends(get_current_user)"}},
+				"DELETE /items/{id}": {status: AuthGuarded, authz: []string{"Security(check_scopes)"}},
+			},
+		},
+		{
+			name: "flask",
+			files: map[string]string{
+				"app.py": `from flask import Flask
+
+app = Flask(__name__)
+
+
+@app.route("/")
+def index():
+    pass
+
+
+@app.route("/admin")
+@login_required
+@roles_required("admin")
+def admin():
+    pass
+`,
+			},
+			want: map[string]authWant{
+				"GET /":      {status: AuthUnguarded},
+				"GET /admin": {status: AuthGuarded, authn: []string{"@login_required"}, authz: []string{`@roles_required("admin")`}},
+			},
+		},
+		{
+			name: "django",
+			files: map[string]string{
+				"urls.py": `urlpatterns = [
+    path("about/", about),
+    path("account/", login_required(account)),
+]
+`,
+			},
+			want: map[string]authWant{
+				"ANY /about":   {status: AuthUnguarded},
+				"ANY /account": {status: AuthGuarded, authn: []string{"login_required"}},
+			},
+		},
+		{
+			name: "spring",
+			files: map[string]string{
+				"OrderController.java": `@RestController
+public class OrderController {
+    @GetMapping("/orders/open")
+    @PermitAll
+    public List<Order> open() {}
+
+    @PreAuthorize("hasRole('ADMIN')")
+    @DeleteMapping("/orders/{id}")
+    public void delete() {}
+
+    @GetMapping("/orders/{id}")
+    public Order get() {}
+}
+`,
+				"SecurityConfig.java": `public class SecurityConfig {
+    SecurityFilterChain chain(HttpSecurity http) {
+        http.authorizeHttpRequests(a -> a.anyRequest().authenticated());
+    }
+}
+`,
+			},
+			want: map[string]authWant{
+				"GET /orders/open":    {status: AuthPublic, public: "@PermitAll"},
+				"DELETE /orders/{id}": {status: AuthGuarded, authn: []string{"anyRequest().authenticated() (global, SecurityConfig.java)"}, authz: []string{`@PreAuthorize("hasRole('ADMIN')")`}},
+				"GET /orders/{id}":    {status: AuthGuarded, authn: []string{"anyRequest().authenticated() (global, SecurityConfig.java)"}},
+			},
+		},
+		{
+			name: "aspnet",
+			files: map[string]string{
+				"AccountsController.cs": `[ApiController]
+[Authorize]
+public class AccountsController : ControllerBase
+{
+    [HttpGet("accounts")]
+    public IActionResult List() {}
+
+    [AllowAnonymous]
+    [HttpGet("accounts/ping")]
+    public IActionResult Ping() {}
+
+    [Authorize(Roles = "Admin")]
+    [HttpDelete("accounts/{id}")]
+    public IActionResult Delete() {}
+}
+`,
+			},
+			want: map[string]authWant{
+				"GET /accounts":         {status: AuthGuarded, authn: []string{"[Authorize]"}},
+				"GET /accounts/ping":    {status: AuthPublic, public: "[AllowAnonymous]"},
+				"DELETE /accounts/{id}": {status: AuthGuarded, authn: []string{"[Authorize]"}, authz: []string{`[Authorize(Roles = "Admin")]`}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkAuthMatrix(t, authMatrixFor(t, tt.files), tt.want)
+		})
+	}
+}
+
+func TestAuthMatrixSummary(t *testing.T) {
+	m := authMatrixFor(t, map[string]string{
+		"routes.js": `router.get('/status', status);
+router.post('/orders', requireAuth, createOrder);
+router.put('/orders/:id', requireAuth, updateOrder);
+router.get('/open', allowAnonymous, open);
+`,
+	})
+	if m.Guarded != 2 || m.Public != 1 || m.Unguarded != 1 {
+		t.Errorf("guarded %d, public %d, unguarded %d, want 2, 1 and 1", m.Guarded, m.Public, m.Unguarded)
+	}
+	if !reflect.DeepEqual(m.Mechanisms, map[string]int{"requireAuth": 2}) {
+		t.Errorf("mechanisms = %v, want requireAuth on 2 endpoints", m.Mechanisms)
+	}
+	if unguarded := m.UnguardedEndpoints(); len(unguarded) != 1 || unguarded[0].Path != "/status" || unguarded[0].File != "routes.js" {
+		t.Errorf("unguarded = %+v, want /status in routes.js", unguarded)
+	}
+
+	md := FormatAuthMatrixMarkdown(m)
+	for _, want := range []string{
+		"4 endpoints: 2 guarded, 1 public, 1 unguarded",
+		"| **UNGUARDED** | GET | `/status` | - | - |",
+		"| public (allowAnonymous) | GET | `/open` |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Request collection formats
+const (
+	CollectionHTTP     = "http"     // VS Code REST Client / JetBrains HTTP Client .http file
+	CollectionPostman  = "postman"  // Postman collection v2.1
+	CollectionInsomnia = "insomnia" // Insomnia export v4
+)
+
+// CollectionFormats lists the supported request collection formats
+var CollectionFormats = []string{CollectionHTTP, CollectionPostman, CollectionInsomnia}
+
+// DefaultBaseURL is the baseUrl variable when none is given
+const DefaultBaseURL = "http://localhost:3000"
+
+// CollectionOptions configures BuildRequestCollection
+type CollectionOptions struct {
+	Format  string
+	BaseURL string
+	// Root makes endpoint file paths in request descriptions relative
+	Root string
+}
+
+// RequestCollection is a generated collection
+type RequestCollection struct {
+	Format   string `json:"format"`
+	FileName string `json:"file_name"`
+	Requests int    `json:"requests"`
+	// WithExample counts requests whose body was inferred from a payload type
+	WithExample int `json:"with_example"`
+	// UnknownBodies are body types no declaration was found for
+	UnknownBodies []string `json:"unknown_bodies,omitempty"`
+	Content       string   `json:"content"`
+}
+
+// collectionRequest is a format-neutral request
+type collectionRequest struct {
+	Name   string
+	Folder string
+	Method string
+	// Path has path parameters as {name}
+	Path   string
+	Params []string
+	Auth   bool
+	Body   string
+	Source string
+}
+
+// routeParamPattern matches :id, {id}, {id:int} and Flask/Django <int:id>
+var routeParamPattern = regexp.MustCompile(`:(\w+)|\{(\w+)(?::[^}]*)?\}|<(?:\w+:)?(\w+)>`)
+
+// BuildRequestCollection turns an API surface into a request collection.
+// POST, PUT and PATCH bodies are example payloads built from the endpoint's
+// body type when it is declared in payloads, and {} otherwise.
+func BuildRequestCollection(surface *APISurface, payloads PayloadTypes, opts CollectionOptions) (*RequestCollection, error) {
+	if opts.Format == "" {
+		opts.Format = CollectionHTTP
+	}
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+
+	collection := &RequestCollection{Format: opts.Format}
+	unknown := make(map[string]bool)
+
+	// Routes matched by more than one framework pattern (@app.post is both
+	// Flask and FastAPI) are merged, keeping whatever each match found
+	var endpoints []APIEndpoint
+	byRoute := make(map[string]int)
+	for _, ep := range surface.Endpoints {
+		if ep.Method == "GRPC" {
+			continue // not callable as an HTTP request
+		}
+		if ep.Method == "ANY" {
+			ep.Method = "GET"
+		}
+		path, _ := normalizeRoute(ep.Path)
+		key := ep.Method + " " + path
+		i, ok := byRoute[key]
+		if !ok {
+			byRoute[key] = len(endpoints)
+			endpoints = append(endpoints, ep)
+			continue
+		}
+		if endpoints[i].Handler == "" {
+			endpoints[i].Handler = ep.Handler
+		}
+		if endpoints[i].Body == "" {
+			endpoints[i].Body = ep.Body
+		}
+		if endpoints[i].Auth == "" {
+			endpoints[i].Auth = ep.Auth
+		}
+	}
+
+	var requests []collectionRequest
+	for _, ep := range endpoints {
+		method := ep.Method
+		path, params := normalizeRoute(ep.Path)
+		req := collectionRequest{
+			Name:   method + " " + path,
+			Folder: ep.Controller,
+			Method: method,
+			Path:   path,
+			Params: params,
+			Auth:   ep.Auth != "",
+			Source: ep.File,
+		}
+		if ep.Handler != "" {
+			req.Name = ep.Handler
+		}
+		if req.Folder == "" {
+			req.Folder = strings.TrimSuffix(filepath.Base(ep.File), filepath.Ext(ep.File))
+		}
+		if opts.Root != "" {
+			if rel, err := filepath.Rel(opts.Root, ep.File); err == nil {
+				req.Source = filepath.ToSlash(rel)
+			}
+		}
+		req.Source = fmt.Sprintf("%s:%d", req.Source, ep.Line)
+
+		if method == "POST" || method == "PUT" || method == "PATCH" {
+			req.Body = "{}"
+			if ep.Body != "" {
+				if example, ok := ExamplePayload(ep.Body, payloads); ok {
+					req.Body = example
+					collection.WithExample++
+				} else {
+					unknown[ep.Body] = true
+				}
+			}
+		}
+		requests = append(requests, req)
+	}
+	sort.SliceStable(requests, func(i, j int) bool {
+		if requests[i].Folder != requests[j].Folder {
+			return requests[i].Folder < requests[j].Folder
+		}
+		return requests[i].Path < requests[j].Path
+	})
+	collection.Requests = len(requests)
+	collection.UnknownBodies = sortedSet(unknown)
+
+	vars := collectionVariables(requests)
+	var err error
+	switch opts.Format {
+	case CollectionHTTP:
+		collection.FileName = surface.App + ".http"
+		collection.Content = httpCollection(surface.App, opts.BaseURL, vars, requests)
+	case CollectionPostman:
+		collection.FileName = surface.App + ".postman_collection.json"
+		collection.Content, err = postmanCollection(surface.App, opts.BaseURL, vars, requests)
+	case CollectionInsomnia:
+		collection.FileName = surface.App + ".insomnia.json"
+		collection.Content, err = insomniaCollection(surface.App, opts.BaseURL, vars, requests)
+	default:
+		return nil, fmt.Errorf("unknown format %q (use %s)", opts.Format, strings.Join(CollectionFormats, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// normalizeRoute rewrites the path parameters of any framework to {name}
+func normalizeRoute(path string) (string, []string) {
+	var params []string
+	path = routeParamPattern.ReplaceAllStringFunc(path, func(match string) string {
+		m := routeParamPattern.FindStringSubmatch(match)
+		name := m[1] + m[2] + m[3]
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path, params
+}
+
+// collectionVariable is a variable shared by the requests, with its default
+type collectionVariable struct {
+	Name  string
+	Value string
+}
+
+// collectionVariables returns the token (when any endpoint is guarded) and
+// path parameter variables. baseUrl is added by each format.
+func collectionVariables(requests []collectionRequest) []collectionVariable {
+	var vars []collectionVariable
+	seen := make(map[string]bool)
+	for _, req := range requests {
+		if req.Auth && !seen["token"] {
+			seen["token"] = true
+			vars = append(vars, collectionVariable{Name: "token"})
+		}
+	}
+	for _, req := range requests {
+		for _, param := range req.Params {
+			if seen[param] {
+				continue
+			}
+			seen[param] = true
+			value := "value"
+			if lower := strings.ToLower(param); lower == "pk" || strings.HasSuffix(lower, "id") {
+				value = "1"
+			}
+			vars = append(vars, collectionVariable{Name: param, Value: value})
+		}
+	}
+	return vars
+}
+
+// withVariables replaces {param} with the format's variable syntax
+func withVariables(path string, params []string, format func(string) string) string {
+	for _, param := range params {
+		path = strings.ReplaceAll(path, "{"+param+"}", format(param))
+	}
+	return path
+}
+
+func httpCollection(app, baseURL string, vars []collectionVariable, requests []collectionRequest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s API requests, generated by teamcontext from the extracted API surface\n\n", app)
+	fmt.Fprintf(&b, "@baseUrl = %s\n", baseURL)
+	for _, v := range vars {
+		fmt.Fprintf(&b, "@%s = %s\n", v.Name, v.Value)
+	}
+	for _, req := range requests {
+		fmt.Fprintf(&b, "\n### %s / %s\n", req.Folder, req.Name)
+		fmt.Fprintf(&b, "# %s\n", req.Source)
+		fmt.Fprintf(&b, "%s {{baseUrl}}%s\n", req.Method, withVariables(req.Path, req.Params, func(p string) string { return "{{" + p + "}}" }))
+		if req.Auth {
+			b.WriteString("Authorization: Bearer {{token}}\n")
+		}
+		if req.Body != "" {
+			b.WriteString("Content-Type: application/json\n\n")
+			b.WriteString(req.Body)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func postmanCollection(app, baseURL string, vars []collectionVariable, requests []collectionRequest) (string, error) {
+	type kv struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	type item struct {
+		Name    string                 `json:"name"`
+		Request map[string]interface{} `json:"request,omitempty"`
+		Item    []*item                `json:"item,omitempty"`
+	}
+
+	var folders []*item
+	byFolder := make(map[string]*item)
+	for _, req := range requests {
+		folder := byFolder[req.Folder]
+		if folder == nil {
+			folder = &item{Name: req.Folder}
+			byFolder[req.Folder] = folder
+			folders = append(folders, folder)
+		}
+
+		path := withVariables(req.Path, req.Params, func(p string) string { return ":" + p })
+		url := map[string]interface{}{
+			"raw":  "{{baseUrl}}" + path,
+			"host": []string{"{{baseUrl}}"},
+			"path": strings.Split(strings.Trim(path, "/"), "/"),
+		}
+		if len(req.Params) > 0 {
+			var pathVars []kv
+			for _, param := range req.Params {
+				pathVars = append(pathVars, kv{Key: param, Value: "{{" + param + "}}"})
+			}
+			url["variable"] = pathVars
+		}
+		headers := []kv{}
+		if req.Auth {
+			headers = append(headers, kv{Key: "Authorization", Value: "Bearer {{token}}"})
+		}
+		request := map[string]interface{}{
+			"method":      req.Method,
+			"header":      headers,
+			"url":         url,
+			"description": req.Source,
+		}
+		if req.Body != "" {
+			request["header"] = append(headers, kv{Key: "Content-Type", Value: "application/json"})
+			request["body"] = map[string]interface{}{
+				"mode":    "raw",
+				"raw":     req.Body,
+				"options": map[string]interface{}{"raw": map[string]string{"language": "json"}},
+			}
+		}
+		folder.Item = append(folder.Item, &item{Name: req.Name, Request: request})
+	}
+
+	variables := []kv{{Key: "baseUrl", Value: baseURL}}
+	for _, v := range vars {
+		variables = append(variables, kv{Key: v.Name, Value: v.Value})
+	}
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"info": map[string]string{
+			"name":   app,
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item":     folders,
+		"variable": variables,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func insomniaCollection(app, baseURL string, vars []collectionVariable, requests []collectionRequest) (string, error) {
+	type header struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	const workspaceID = "wrk_teamcontext"
+	env := map[string]string{"baseUrl": baseURL}
+	for _, v := range vars {
+		env[v.Name] = v.Value
+	}
+	resources := []map[string]interface{}{
+		{"_id": workspaceID, "_type": "workspace", "name": app},
+		{"_id": "env_teamcontext", "_type": "environment", "parentId": workspaceID, "name": "Base Environment", "data": env},
+	}
+
+	groups := make(map[string]string)
+	for i, req := range requests {
+		groupID, ok := groups[req.Folder]
+		if !ok {
+			groupID = fmt.Sprintf("fld_%d", len(groups)+1)
+			groups[req.Folder] = groupID
+			resources = append(resources, map[string]interface{}{
+				"_id": groupID, "_type": "request_group", "parentId": workspaceID, "name": req.Folder,
+			})
+		}
+
+		headers := []header{}
+		if req.Auth {
+			headers = append(headers, header{Name: "Authorization", Value: "Bearer {{ _.token }}"})
+		}
+		resource := map[string]interface{}{
+			"_id":         fmt.Sprintf("req_%d", i+1),
+			"_type":       "request",
+			"parentId":    groupID,
+			"name":        req.Name,
+			"description": req.Source,
+			"method":      req.Method,
+			"url":         "{{ _.baseUrl }}" + withVariables(req.Path, req.Params, func(p string) string { return "{{ _." + p + " }}" }),
+			"body":        map[string]string{},
+		}
+		if req.Body != "" {
+			headers = append(headers, header{Name: "Content-Type", Value: "application/json"})
+			resource["body"] = map[string]string{"mimeType": "application/json", "text": req.Body}
+		}
+		resource["headers"] = headers
+		resources = append(resources, resource)
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"_type":           "export",
+		"__export_format": 4,
+		"__export_source": "teamcontext",
+		"resources":       resources,
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func sortedSet(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -20,8 +20,10 @@ type ConfigVar struct {
 
 // ConfigMap holds all extracted configuration
 type ConfigMap struct {
-	EnvVars     []ConfigVar `json:"env_vars"`
-	ConfigFiles []string    `json:"config_files"`
+	EnvVars      []ConfigVar   `json:"env_vars"`
+	ConfigFiles  []string      `json:"config_files"`
+	Environments []Environment `json:"environments"`
+	Warnings     []EnvWarning  `json:"warnings"` // vars read in code but not defined for an environment
 }
 
 // Patterns for config extraction
@@ -53,6 +55,9 @@ func ExtractConfigMap(dirPath string) (*ConfigMap, error) {
 	}
 
 	seen := make(map[string]bool)
+	envs := newEnvCollector()
+	var reads []ConfigVar
+	read := make(map[string]bool)
 
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -77,6 +82,7 @@ func ExtractConfigMap(dirPath string) (*ConfigMap, error) {
 		// Parse .env files
 		if strings.HasPrefix(base, ".env") {
 			vars, _ := extractEnvFile(path)
+			envs.addDotenv(path, vars)
 			for _, v := range vars {
 				if !seen[v.Name] {
 					seen[v.Name] = true
@@ -90,16 +96,38 @@ func ExtractConfigMap(dirPath string) (*ConfigMap, error) {
 		if ext == ".ts" || ext == ".js" || ext == ".go" || ext == ".py" {
 			vars, _ := extractEnvUsage(path)
 			for _, v := range vars {
+				if !read[v.Name] {
+					read[v.Name] = true
+					reads = append(reads, v)
+				}
 				if !seen[v.Name] {
 					seen[v.Name] = true
 					configMap.EnvVars = append(configMap.EnvVars, v)
 				}
 			}
+			return nil
+		}
+
+		// Helm values and CI files define the variables of each environment
+		if ext == ".yaml" || ext == ".yml" {
+			if isCIFile(path) || strings.HasPrefix(base, "values") {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return nil
+				}
+				if isCIFile(path) {
+					envs.addCI(path, string(content))
+				} else {
+					envs.addHelmValues(path, string(content))
+				}
+			}
 		}
 
 		return nil
 	})
 
+	configMap.Environments = envs.environments()
+	configMap.Warnings = envWarnings(configMap.Environments, reads)
 	return configMap, err
 }
 
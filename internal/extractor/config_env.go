@@ -0,0 +1,375 @@
+package extractor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Environment is a deploy target (staging, production, ...) and the
+// variables defined for it by .env.<env> files, Helm values-<env>.yaml
+// files and CI jobs that deploy to it
+type Environment struct {
+	Name    string   `json:"name"`
+	Sources []string `json:"sources"`
+	Vars    []string `json:"vars"`
+}
+
+// EnvWarning is a variable the code reads that an environment does not
+// define, a common cause of failed deploys
+type EnvWarning struct {
+	Name        string `json:"name"`
+	Environment string `json:"environment"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Message     string `json:"message"`
+}
+
+var (
+	helmValuesPattern = regexp.MustCompile(`^values[-._](\w[\w.-]*)\.ya?ml$`)
+	yamlKeyPattern    = regexp.MustCompile(`^(\s*)(-\s+)?([\w.$/{}-]+|"[^"]+"|'[^']+'):(?:\s+(.*))?$`)
+	yamlListPattern   = regexp.MustCompile(`^(\s*)-\s+['"]?(\w+)=`)
+	envNamePattern    = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+)
+
+// envAliases folds the usual abbreviations so .env.prod, values-production.yaml
+// and a CI job deploying to "Production" describe the same environment
+var envAliases = map[string]string{
+	"dev": "development", "develop": "development",
+	"prod": "production", "prd": "production",
+	"stage": "staging", "stg": "staging",
+}
+
+// envTemplates are .env.<suffix> files documenting variables, not environments
+var envTemplates = map[string]bool{
+	"example": true, "sample": true, "template": true, "defaults": true, "dist": true, "schema": true,
+}
+
+// helmEnvKeys hold a container's variables in Helm values files
+var helmEnvKeys = map[string]bool{
+	"env": true, "extraenv": true, "extraenvvars": true, "envvars": true, "environment": true,
+}
+
+// runtimeEnvVars are set by the OS, shell or runtime, not by a deployment
+var runtimeEnvVars = map[string]bool{
+	"NODE_ENV": true, "HOME": true, "PATH": true, "USER": true, "PWD": true, "SHELL": true,
+	"TERM": true, "LANG": true, "TZ": true, "TMPDIR": true, "HOSTNAME": true, "CI": true,
+}
+
+// envFile is one file's variables for an environment, before the base file
+// of its directory (.env or values.yaml) is merged in
+type envFile struct {
+	env  string
+	path string
+	base string // key of the base file it layers on
+	vars []string
+}
+
+// envCollector gathers environment definitions while a project is walked
+type envCollector struct {
+	files []envFile
+	bases map[string][]string
+}
+
+func newEnvCollector() *envCollector {
+	return &envCollector{bases: make(map[string][]string)}
+}
+
+// addDotenv records a .env file: .env is the base every .env.<env> in its
+// directory layers on, .env.<env>[.local] defines env, templates are skipped
+func (c *envCollector) addDotenv(path string, vars []ConfigVar) {
+	var names []string
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+	base := filepath.Base(path)
+	key := "dotenv:" + filepath.Dir(path)
+	if base == ".env" {
+		c.bases[key] = append(c.bases[key], names...)
+		return
+	}
+	if !strings.HasPrefix(base, ".env.") {
+		return
+	}
+	suffix := strings.TrimPrefix(base, ".env.")
+	if suffix != "local" {
+		suffix = strings.TrimSuffix(suffix, ".local")
+	}
+	if envTemplates[strings.ToLower(suffix)] {
+		return
+	}
+	c.files = append(c.files, envFile{env: NormalizeEnvironment(suffix), path: path, base: key, vars: names})
+}
+
+// addHelmValues records a Helm values file: values.yaml is the base, and
+// values-<env>.yaml (or values.<env>.yaml) overrides it for env
+func (c *envCollector) addHelmValues(path, content string) {
+	base := filepath.Base(path)
+	key := "helm:" + filepath.Dir(path)
+	if base == "values.yaml" || base == "values.yml" {
+		c.bases[key] = append(c.bases[key], yamlEnvVars(strings.Split(content, "\n"), helmEnvKeys)...)
+		return
+	}
+	m := helmValuesPattern.FindStringSubmatch(base)
+	if m == nil {
+		return
+	}
+	vars := yamlEnvVars(strings.Split(content, "\n"), helmEnvKeys)
+	c.files = append(c.files, envFile{env: NormalizeEnvironment(m[1]), path: path, base: key, vars: vars})
+}
+
+// addCI records the environments a GitHub Actions workflow or GitLab CI
+// file deploys to, with the variables set for those jobs
+func (c *envCollector) addCI(path, content string) {
+	for env, vars := range ciEnvironments(strings.Split(content, "\n")) {
+		c.files = append(c.files, envFile{env: env, path: path, vars: vars})
+	}
+}
+
+// environments merges the collected files per environment, sorted by name
+func (c *envCollector) environments() []Environment {
+	vars := make(map[string]map[string]bool)
+	sources := make(map[string][]string)
+	for _, f := range c.files {
+		if vars[f.env] == nil {
+			vars[f.env] = make(map[string]bool)
+		}
+		for _, name := range c.bases[f.base] {
+			vars[f.env][name] = true
+		}
+		for _, name := range f.vars {
+			vars[f.env][name] = true
+		}
+		sources[f.env] = append(sources[f.env], f.path)
+	}
+
+	envs := []Environment{}
+	for name, defined := range vars {
+		env := Environment{Name: name, Sources: sources[name], Vars: []string{}}
+		for v := range defined {
+			env.Vars = append(env.Vars, v)
+		}
+		sort.Strings(env.Sources)
+		sort.Strings(env.Vars)
+		envs = append(envs, env)
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+	return envs
+}
+
+// envWarnings lists, per environment, the variables read in code that the
+// environment does not define. Config keys only count when they are named
+// like env vars (configService.get('DATABASE_URL')).
+func envWarnings(envs []Environment, reads []ConfigVar) []EnvWarning {
+	warnings := []EnvWarning{}
+	for _, env := range envs {
+		defined := make(map[string]bool, len(env.Vars))
+		for _, v := range env.Vars {
+			defined[v] = true
+		}
+		for _, r := range reads {
+			if defined[r.Name] || runtimeEnvVars[r.Name] || (r.Source != "env" && !envNamePattern.MatchString(r.Name)) {
+				continue
+			}
+			warnings = append(warnings, EnvWarning{
+				Name:        r.Name,
+				Environment: env.Name,
+				File:        r.File,
+				Line:        r.Line,
+				Message:     fmt.Sprintf("%s is read at %s:%d but not defined for %s", r.Name, filepath.Base(r.File), r.Line, env.Name),
+			})
+		}
+	}
+	return warnings
+}
+
+// NormalizeEnvironment lowercases an environment name and folds its
+// aliases (prod is production, stg is staging)
+func NormalizeEnvironment(name string) string {
+	name = strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'`))
+	if alias, ok := envAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// isCIFile reports whether path is a GitHub Actions workflow or GitLab CI file
+func isCIFile(path string) bool {
+	slash := filepath.ToSlash(path)
+	ext := filepath.Ext(path)
+	return strings.Contains(slash, ".github/workflows/") && (ext == ".yml" || ext == ".yaml") ||
+		filepath.Base(path) == ".gitlab-ci.yml"
+}
+
+// yamlLine splits a YAML line into its indent, whether it starts a list
+// item, and its key and value; ok is false for blanks, comments and scalars
+func yamlLine(line string) (indent int, item bool, key, value string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return 0, false, "", "", false
+	}
+	m := yamlKeyPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false, "", "", false
+	}
+	value = strings.TrimSpace(m[4])
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return len(m[1]), m[2] != "", strings.Trim(m[3], `"'`), value, true
+}
+
+// indentOf counts the leading spaces of a line, -1 for blanks and comments
+func indentOf(line string) int {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return -1
+	}
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// yamlEnvVars collects the variable names under every block keyed by one
+// of keys (lowercase), at any depth
+func yamlEnvVars(lines []string, keys map[string]bool) []string {
+	var vars []string
+	for i := 0; i < len(lines); i++ {
+		indent, _, key, value, ok := yamlLine(lines[i])
+		if ok && value == "" && keys[strings.ToLower(key)] {
+			var names []string
+			names, i = yamlEnvBlock(lines, i, indent)
+			vars = append(vars, names...)
+		}
+	}
+	return vars
+}
+
+// yamlEnvBlock reads the variables of the block opened on line start: map
+// keys (KEY: value), Kubernetes-style list items (- name: KEY) and
+// compose-style ones (- KEY=value). It returns them with the block's last line.
+func yamlEnvBlock(lines []string, start, indent int) ([]string, int) {
+	var names []string
+	child := -1
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		lineIndent := indentOf(lines[i])
+		if lineIndent < 0 {
+			continue
+		}
+		if lineIndent <= indent && !(lineIndent == indent && strings.HasPrefix(strings.TrimSpace(lines[i]), "- ")) {
+			break
+		}
+		end = i
+		if child < 0 {
+			child = lineIndent
+		}
+		if m := yamlListPattern.FindStringSubmatch(lines[i]); m != nil && len(m[1]) == child {
+			names = append(names, m[2])
+			continue
+		}
+		keyIndent, item, key, value, ok := yamlLine(lines[i])
+		if !ok {
+			continue
+		}
+		switch {
+		case item && key == "name":
+			if name := strings.Trim(value, `"'`); name != "" {
+				names = append(names, name)
+			}
+		case !item && keyIndent == child:
+			names = append(names, key)
+		}
+	}
+	return names, end
+}
+
+// ciEnvironments maps each environment a CI file deploys to onto the
+// variables of its jobs: GitHub Actions jobs sit under jobs:, GitLab jobs
+// at the top level; top-level env: and variables: apply to every job
+func ciEnvironments(lines []string) map[string][]string {
+	github := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "jobs:") {
+			github = true
+		}
+	}
+
+	type job struct {
+		env  string
+		vars []string
+	}
+	var global []string
+	var jobs []*job
+	var current *job
+	inJobs := false
+	jobIndent := -1
+
+	for i := 0; i < len(lines); i++ {
+		indent, _, key, value, ok := yamlLine(lines[i])
+		if !ok {
+			continue
+		}
+		if indent == 0 {
+			current = nil
+			inJobs = key == "jobs"
+			if key == "env" || key == "variables" {
+				var names []string
+				names, i = yamlEnvBlock(lines, i, indent)
+				global = append(global, names...)
+				continue
+			}
+			if !github && !strings.HasPrefix(key, ".") {
+				current = &job{}
+				jobs = append(jobs, current)
+			}
+			continue
+		}
+		if github && inJobs {
+			if jobIndent < 0 {
+				jobIndent = indent
+			}
+			if indent == jobIndent {
+				current = &job{}
+				jobs = append(jobs, current)
+				continue
+			}
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case key == "environment" && value != "":
+			current.env = value
+		case key == "environment":
+			// environment: {name: production, url: ...}
+			for j := i + 1; j < len(lines); j++ {
+				childIndent, _, childKey, childValue, ok := yamlLine(lines[j])
+				if !ok {
+					continue
+				}
+				if childIndent <= indent {
+					break
+				}
+				if childKey == "name" {
+					current.env = childValue
+				}
+			}
+		case (key == "env" || key == "variables") && value == "":
+			var names []string
+			names, i = yamlEnvBlock(lines, i, indent)
+			current.vars = append(current.vars, names...)
+		}
+	}
+
+	envs := make(map[string][]string)
+	for _, j := range jobs {
+		// Environments computed at run time cannot be resolved statically
+		if j.env == "" || strings.ContainsAny(j.env, "${}") {
+			continue
+		}
+		env := NormalizeEnvironment(j.env)
+		envs[env] = append(envs[env], append(append([]string{}, global...), j.vars...)...)
+	}
+	return envs
+}
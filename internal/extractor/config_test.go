@@ -0,0 +1,112 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigEnvironments(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/db.ts": `const url = process.env.DATABASE_URL;
+const key = process.env.STRIPE_KEY;
+const mode = process.env.NODE_ENV;
+const flag = configService.get('FEATURE_FLAGS_URL');
+const port = configService.get('server.port');
+`,
+		".env":            "DATABASE_URL=postgres://localhost/app\n",
+		".env.staging":    "STRIPE_KEY=sk_test\n",
+		".env.example":    "DATABASE_URL=\nSTRIPE_KEY=\nFEATURE_FLAGS_URL=\n",
+		".env.prod.local": "FEATURE_FLAGS_URL=https://flags\n",
+		"deploy/chart/values.yaml": `image: app
+env:
+  - name: DATABASE_URL
+    valueFrom:
+      secretKeyRef:
+        name: db
+        key: url
+`,
+		"deploy/chart/values-production.yaml": `extraEnv:
+  STRIPE_KEY: sk_live
+resources:
+  limits:
+    cpu: 1
+`,
+		".github/workflows/deploy.yml": `name: deploy
+on: push
+env:
+  REGISTRY: ghcr.io
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      DATABASE_URL: postgres://ci
+  release:
+    runs-on: ubuntu-latest
+    environment:
+      name: Production
+      url: https://app.example.com
+    steps:
+      - name: Deploy
+        env:
+          DEPLOY_TOKEN: ${{ secrets.TOKEN }}
+        run: ./deploy.sh
+  preview:
+    environment: ${{ inputs.target }}
+`,
+		".gitlab-ci.yml": `variables:
+  REGISTRY: registry.gitlab.com
+deploy_qa:
+  environment: qa
+  variables:
+    DATABASE_URL:
+      value: postgres://qa
+      description: QA database
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configMap, err := ExtractConfigMap(dir)
+	if err != nil {
+		t.Fatalf("ExtractConfigMap: %v", err)
+	}
+
+	envs := make(map[string][]string)
+	for _, env := range configMap.Environments {
+		envs[env.Name] = env.Vars
+	}
+	want := map[string][]string{
+		// .env is the base of .env.staging; .env.example is only a template
+		"staging": {"DATABASE_URL", "STRIPE_KEY"},
+		// .env.prod.local, values.yaml under values-production.yaml, and the
+		// release job with the workflow's env
+		"production": {"DATABASE_URL", "DEPLOY_TOKEN", "FEATURE_FLAGS_URL", "REGISTRY", "STRIPE_KEY"},
+		"qa":         {"DATABASE_URL", "REGISTRY"},
+	}
+	if !reflect.DeepEqual(envs, want) {
+		t.Errorf("environments = %v, want %v", envs, want)
+	}
+
+	// NODE_ENV is set by the runtime and server.port is not an env var
+	var warnings []string
+	for _, w := range configMap.Warnings {
+		warnings = append(warnings, w.Environment+":"+w.Name)
+	}
+	wantWarnings := []string{"qa:STRIPE_KEY", "qa:FEATURE_FLAGS_URL", "staging:FEATURE_FLAGS_URL"}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("warnings = %v, want %v", warnings, wantWarnings)
+	}
+	if w := configMap.Warnings[0]; w.Line != 2 || w.Message != "STRIPE_KEY is read at db.ts:2 but not defined for qa" {
+		t.Errorf("warning = %+v", w)
+	}
+}
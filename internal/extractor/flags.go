@@ -0,0 +1,409 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Feature flag providers
+const (
+	FlagLaunchDarkly = "launchdarkly"
+	FlagUnleash      = "unleash"
+	FlagFlagsmith    = "flagsmith"
+	FlagGrowthBook   = "growthbook"
+	FlagOpenFeature  = "openfeature"
+	FlagSplit        = "split"
+	FlagConfigCat    = "configcat"
+	FlagFlipper      = "flipper"
+	FlagWaffle       = "waffle"
+	FlagCustom       = "custom" // homegrown helpers: isFeatureEnabled('x')
+	FlagEnv          = "env"    // environment toggles: FEATURE_X, X_ENABLED
+)
+
+// FlagUsage is one place code reads a flag
+type FlagUsage struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// FeatureFlag is a flag key with where the code reads it and where it is
+// defined
+type FeatureFlag struct {
+	Key       string      `json:"key"`
+	Provider  string      `json:"provider"`
+	Usages    []FlagUsage `json:"usages"`
+	DefinedIn []string    `json:"defined_in,omitempty"`
+}
+
+// FlagWarning is a flag the code reads that no flag source defines
+type FlagWarning struct {
+	Key     string `json:"key"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// FlagInventory lists the feature flags of a project
+type FlagInventory struct {
+	Flags []FeatureFlag `json:"flags"`
+	// Sources are the files flags are defined in: flag files and .env files
+	Sources  []string      `json:"sources"`
+	Warnings []FlagWarning `json:"warnings"`
+	// Unused are keys a flag file defines that no code reads, candidates
+	// for cleanup
+	Unused []string `json:"unused,omitempty"`
+}
+
+// FlagOptions configures ExtractFeatureFlags
+type FlagOptions struct {
+	// Sources are extra flag definition files, relative to Root
+	Sources []string
+	// Root makes file paths relative
+	Root string
+}
+
+// flagCall matches an SDK or helper call whose first argument is the key
+type flagCall struct {
+	provider string
+	re       *regexp.Regexp
+}
+
+// flagKeyArg is a quoted key, or a Ruby symbol
+const flagKeyArg = `\s*(?:['"\x60]([\w.:/-]+)['"\x60]|:(\w+))`
+
+var (
+	flagCalls = []flagCall{
+		{FlagLaunchDarkly, regexp.MustCompile(`\.(?:variation|variationDetail|boolVariation|stringVariation|numberVariation|jsonVariation|BoolVariation|StringVariation|IntVariation|Float64Variation|JSONVariation|bool_variation|string_variation)\(` + flagKeyArg)},
+		{FlagUnleash, regexp.MustCompile(`(?:\.(?:isEnabled|is_enabled|IsEnabled|getVariant|get_variant|GetVariant)|\buse(?:Flag|Variant))\(` + flagKeyArg)},
+		{FlagFlagsmith, regexp.MustCompile(`\.(?:hasFeature|has_feature|getFeatureValue|get_feature_value|is_feature_enabled)\(` + flagKeyArg)},
+		{FlagGrowthBook, regexp.MustCompile(`(?:\.(?:isOn|isOff|getFeatureValue|evalFeature|is_on|is_off|eval_feature)|\buseFeature(?:IsOn|Value)?)\(` + flagKeyArg)},
+		{FlagOpenFeature, regexp.MustCompile(`\.(?:getBooleanValue|getStringValue|getNumberValue|getObjectValue|getBooleanDetails|get_boolean_value|get_string_value|BooleanValue|StringValue|IntValue|FloatValue|ObjectValue)\((?:\s*ctx\s*,)?` + flagKeyArg)},
+		{FlagSplit, regexp.MustCompile(`\.(?:getTreatment|get_treatment|getTreatmentWithConfig)\(\s*(?:[\w.]+\s*,\s*)?` + flagKeyArg[3:])},
+		{FlagConfigCat, regexp.MustCompile(`\.(?:getValueAsync|GetValue|GetValueAsync|get_value)\(` + flagKeyArg)},
+		{FlagFlipper, regexp.MustCompile(`\bFlipper(?:\.enabled\?|\[)\(?` + flagKeyArg)},
+		{FlagWaffle, regexp.MustCompile(`\b(?:flag_is_active|switch_is_active|sample_is_active)\(\s*(?:[\w.]+\s*,\s*)?` + flagKeyArg[3:])},
+		{FlagCustom, regexp.MustCompile(`\b(?:is_?[Ff]eature_?(?:[Ee]nabled|[Oo]n|[Aa]ctive)|[Ff]eature_?[Ee]nabled\??|is_?[Ff]lag_?(?:[Ee]nabled|[Oo]n)|[Ff]lag_?[Ee]nabled\??|has_?[Ff]eature_?[Ff]lag)\s*\(?` + flagKeyArg)},
+	}
+
+	// Environment variables that look like toggles rather than settings
+	envTogglePattern    = regexp.MustCompile(`^(?:FEATURE|FEATURES|FF|FLAG|ENABLE|DISABLE)_\w+$|^\w+_(?:ENABLED|DISABLED|FLAG)$`)
+	envSettingSuffixRe  = regexp.MustCompile(`_(?:URL|URI|KEY|TOKEN|SECRET|HOST|PORT|PATH|ID|SDK_KEY|API_KEY)$`)
+	flagFilePattern     = regexp.MustCompile(`(?i)^(?:feature[-_]?flags?|flags|features|toggles|feature[-_]?toggles)(?:\.[\w-]+)?\.(?:json|ya?ml|ts|js)$`)
+	rubyEnvPattern      = regexp.MustCompile(`ENV(?:\.fetch\(|\[)\s*['"](\w+)['"]`)
+	flagObjectKeyRe     = regexp.MustCompile(`^\s*['"]?([\w.:-]+)['"]?\s*:`)
+	flagSourceExtension = map[string]bool{
+		".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true, ".go": true, ".py": true,
+		".rb": true, ".java": true, ".kt": true, ".cs": true, ".php": true, ".swift": true, ".dart": true,
+	}
+)
+
+// ExtractFeatureFlags inventories the feature flags read by code under dir:
+// provider SDK calls, homegrown helpers and environment toggles. Keys are
+// checked against flag definition files (flags.json, feature-flags.yaml,
+// flags.ts, ...) and, for environment toggles, .env files.
+func ExtractFeatureFlags(dir string, opts FlagOptions) (*FlagInventory, error) {
+	if opts.Root == "" {
+		opts.Root = dir
+	}
+	rel := func(path string) string {
+		if r, err := filepath.Rel(opts.Root, path); err == nil && !strings.HasPrefix(r, "..") {
+			return filepath.ToSlash(r)
+		}
+		return filepath.ToSlash(path)
+	}
+
+	flags := make(map[string]*FeatureFlag)
+	defined := make(map[string][]string) // key -> flag files defining it
+	envDefined := make(map[string]bool)
+	var sources []string
+
+	addUsage := func(key, provider, file string, line int) {
+		f, ok := flags[key]
+		if !ok {
+			f = &FeatureFlag{Key: key, Provider: provider}
+			flags[key] = f
+		}
+		for _, u := range f.Usages {
+			if u.File == file && u.Line == line {
+				return
+			}
+		}
+		f.Usages = append(f.Usages, FlagUsage{File: file, Line: line})
+	}
+
+	var flagFiles []string
+	configured := make(map[string]bool)
+	for _, src := range opts.Sources {
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(opts.Root, src)
+		}
+		flagFiles = append(flagFiles, src)
+		configured[src] = true
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if name == "node_modules" || name == "dist" || name == ".git" || name == "vendor" || name == ".teamcontext" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		base := info.Name()
+
+		if strings.HasPrefix(base, ".env") {
+			vars, _ := extractEnvFile(path)
+			toggles := false
+			for _, v := range vars {
+				if isEnvToggle(v.Name) {
+					envDefined[v.Name] = true
+					toggles = true
+				}
+			}
+			if toggles {
+				sources = append(sources, rel(path))
+			}
+			return nil
+		}
+		if flagFilePattern.MatchString(base) {
+			flagFiles = append(flagFiles, path)
+			return nil
+		}
+		if !flagSourceExtension[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		file := rel(path)
+		lines := strings.Split(string(content), "\n")
+		for i, line := range lines {
+			for _, call := range flagCalls {
+				for _, m := range call.re.FindAllStringSubmatch(line, -1) {
+					key := m[1]
+					if key == "" {
+						key = m[2]
+					}
+					addUsage(key, call.provider, file, i+1)
+				}
+			}
+		}
+		envReads := envReadPatterns(filepath.Ext(path))
+		for i, line := range lines {
+			for _, re := range envReads {
+				for _, m := range re.FindAllStringSubmatch(line, -1) {
+					if isEnvToggle(m[1]) {
+						addUsage(m[1], FlagEnv, file, i+1)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seenFiles := make(map[string]bool)
+	for _, path := range flagFiles {
+		if seenFiles[path] {
+			continue
+		}
+		seenFiles[path] = true
+		keys, err := flagFileKeys(path)
+		if err != nil {
+			// A configured source that cannot be read is an error; a
+			// detected file that merely looks like a flag file is not
+			if configured[path] {
+				return nil, fmt.Errorf("flag source %s: %w", rel(path), err)
+			}
+			continue
+		}
+		sources = append(sources, rel(path))
+		for _, key := range keys {
+			defined[key] = append(defined[key], rel(path))
+		}
+	}
+
+	inv := &FlagInventory{Flags: []FeatureFlag{}, Sources: sources, Warnings: []FlagWarning{}}
+	haveFlagFiles := len(defined) > 0
+	for _, f := range flags {
+		f.DefinedIn = defined[f.Key]
+		if f.Provider == FlagEnv && envDefined[f.Key] {
+			f.DefinedIn = append(f.DefinedIn, ".env")
+		}
+		sort.Slice(f.Usages, func(i, j int) bool {
+			if f.Usages[i].File != f.Usages[j].File {
+				return f.Usages[i].File < f.Usages[j].File
+			}
+			return f.Usages[i].Line < f.Usages[j].Line
+		})
+		inv.Flags = append(inv.Flags, *f)
+
+		// Environment toggles are checked against .env files; the others
+		// only when the project keeps its flags in files
+		checked := haveFlagFiles
+		if f.Provider == FlagEnv {
+			checked = haveFlagFiles || len(envDefined) > 0
+		}
+		if checked && len(f.DefinedIn) == 0 {
+			where := "any flag file"
+			if f.Provider == FlagEnv {
+				where = "any .env file or flag file"
+			}
+			inv.Warnings = append(inv.Warnings, FlagWarning{
+				Key:     f.Key,
+				File:    f.Usages[0].File,
+				Line:    f.Usages[0].Line,
+				Message: fmt.Sprintf("flag %s is read in code but not defined in %s", f.Key, where),
+			})
+		}
+	}
+	for key := range defined {
+		if _, ok := flags[key]; !ok {
+			inv.Unused = append(inv.Unused, key)
+		}
+	}
+
+	sort.Slice(inv.Flags, func(i, j int) bool { return inv.Flags[i].Key < inv.Flags[j].Key })
+	sort.Slice(inv.Warnings, func(i, j int) bool { return inv.Warnings[i].Key < inv.Warnings[j].Key })
+	sort.Strings(inv.Sources)
+	sort.Strings(inv.Unused)
+	return inv, nil
+}
+
+// envReadPatterns returns the environment-read patterns for a file
+// extension; config.get and viper lookups are left out as they are not
+// environment variables
+func envReadPatterns(ext string) []*regexp.Regexp {
+	switch ext {
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs":
+		return []*regexp.Regexp{processEnvPattern, processEnvBracket}
+	case ".go":
+		return []*regexp.Regexp{osGetenvPattern, osLookupEnvPattern}
+	case ".py":
+		return []*regexp.Regexp{osEnvironPattern, osGetenvPyPattern}
+	case ".rb":
+		return []*regexp.Regexp{rubyEnvPattern}
+	}
+	return nil
+}
+
+// isEnvToggle reports whether an environment variable looks like an on/off
+// switch rather than a setting such as FEATURE_FLAGS_URL
+func isEnvToggle(name string) bool {
+	return envTogglePattern.MatchString(name) && !envSettingSuffixRe.MatchString(name)
+}
+
+// flagFileKeys reads the flag keys a definition file declares: the keys of
+// a JSON or YAML object, under "flags", "features" or "toggles" when there
+// is such a key, or the keys of the object literals in a flags.ts/js file
+func flagFileKeys(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, err
+		}
+		for _, wrapper := range []string{"flags", "features", "toggles", "featureFlags", "feature_flags"} {
+			if inner, ok := doc[wrapper]; ok {
+				var nested map[string]json.RawMessage
+				if json.Unmarshal(inner, &nested) == nil {
+					doc = nested
+					break
+				}
+				// A list of {"key": ...} or {"name": ...} objects
+				var list []map[string]interface{}
+				if json.Unmarshal(inner, &list) == nil {
+					var keys []string
+					for _, item := range list {
+						for _, field := range []string{"key", "name", "id"} {
+							if k, ok := item[field].(string); ok {
+								keys = append(keys, k)
+								break
+							}
+						}
+					}
+					return keys, nil
+				}
+			}
+		}
+		keys := make([]string, 0, len(doc))
+		for k := range doc {
+			keys = append(keys, k)
+		}
+		return keys, nil
+	case ".yaml", ".yml":
+		return yamlFlagKeys(strings.Split(string(content), "\n")), nil
+	default:
+		// Keys of the outermost object literal; nested keys such as
+		// enabled or description belong to a flag, they are not flags
+		var keys []string
+		depth := 0
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "//") {
+				continue
+			}
+			if depth == 1 {
+				if m := flagObjectKeyRe.FindStringSubmatch(line); m != nil {
+					keys = append(keys, m[1])
+				}
+			}
+			depth += strings.Count(line, "{") - strings.Count(line, "}")
+		}
+		return keys, nil
+	}
+}
+
+// yamlFlagKeys returns the top-level keys of a YAML flag file, or the keys
+// one level under a flags/features/toggles key
+func yamlFlagKeys(lines []string) []string {
+	var top []string
+	var nested []string
+	inWrapper, wrapperIndent, childIndent := false, 0, -1
+	for _, line := range lines {
+		indent, _, key, _, ok := yamlLine(line)
+		if !ok || key == "" {
+			continue
+		}
+		key = strings.Trim(key, `"'`)
+		if inWrapper && indent <= wrapperIndent {
+			inWrapper = false
+		}
+		if inWrapper {
+			if childIndent < 0 {
+				childIndent = indent
+			}
+			if indent == childIndent {
+				nested = append(nested, key)
+			}
+			continue
+		}
+		if indent == 0 {
+			switch key {
+			case "flags", "features", "toggles", "feature_flags", "featureFlags":
+				inWrapper, wrapperIndent, childIndent = true, indent, -1
+			default:
+				top = append(top, key)
+			}
+		}
+	}
+	if len(nested) > 0 {
+		return nested
+	}
+	return top
+}
@@ -0,0 +1,105 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractFeatureFlags(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/checkout.ts": `const on = await ldClient.variation('new-checkout', user, false);
+if (unleash.isEnabled("dark-mode")) {}
+const beta = process.env.FEATURE_BETA_SEARCH === 'true';
+const url = process.env.FEATURE_FLAGS_URL;
+if (isFeatureEnabled('bulk-export')) {}
+`,
+		"src/orders.ts": `if (await ldClient.variation('new-checkout', user, false)) {}
+`,
+		"api/flags.go": `package api
+
+func enabled() bool {
+	return client.BoolVariation("ghost-flag", ctx, false) && os.Getenv("PAYMENTS_ENABLED") == "1"
+}
+`,
+		"app/models/user.rb": `Flipper.enabled?(:beta_search, user)
+`,
+		"config/flags.json": `{"flags": {"new-checkout": {"on": true}, "dark-mode": {"on": false}, "legacy-banner": {"on": false}}}`,
+		"src/features.ts": `export const features = {
+  'bulk-export': { enabled: true, description: 'CSV export' },
+  beta_search: { enabled: false },
+};
+`,
+		".env": "FEATURE_BETA_SEARCH=true\nDATABASE_URL=postgres://localhost\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inv, err := ExtractFeatureFlags(dir, FlagOptions{})
+	if err != nil {
+		t.Fatalf("ExtractFeatureFlags: %v", err)
+	}
+
+	providers := make(map[string]string)
+	for _, f := range inv.Flags {
+		providers[f.Key] = f.Provider
+	}
+	// FEATURE_FLAGS_URL is a setting, not a toggle
+	wantProviders := map[string]string{
+		"new-checkout":        FlagLaunchDarkly,
+		"dark-mode":           FlagUnleash,
+		"ghost-flag":          FlagLaunchDarkly,
+		"bulk-export":         FlagCustom,
+		"beta_search":         FlagFlipper,
+		"FEATURE_BETA_SEARCH": FlagEnv,
+		"PAYMENTS_ENABLED":    FlagEnv,
+	}
+	if !reflect.DeepEqual(providers, wantProviders) {
+		t.Errorf("flags = %v, want %v", providers, wantProviders)
+	}
+
+	for _, f := range inv.Flags {
+		if f.Key != "new-checkout" {
+			continue
+		}
+		want := []FlagUsage{{File: "src/checkout.ts", Line: 1}, {File: "src/orders.ts", Line: 1}}
+		if !reflect.DeepEqual(f.Usages, want) {
+			t.Errorf("new-checkout usages = %v, want %v", f.Usages, want)
+		}
+		if !reflect.DeepEqual(f.DefinedIn, []string{"config/flags.json"}) {
+			t.Errorf("new-checkout defined in %v", f.DefinedIn)
+		}
+	}
+
+	if want := []string{".env", "config/flags.json", "src/features.ts"}; !reflect.DeepEqual(inv.Sources, want) {
+		t.Errorf("sources = %v, want %v", inv.Sources, want)
+	}
+
+	var missing []string
+	for _, w := range inv.Warnings {
+		missing = append(missing, w.Key)
+	}
+	if want := []string{"PAYMENTS_ENABLED", "ghost-flag"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("warnings = %v, want %v", missing, want)
+	}
+	if w := inv.Warnings[1]; w.File != "api/flags.go" || w.Line != 4 {
+		t.Errorf("warning = %+v", w)
+	}
+	if want := []string{"legacy-banner"}; !reflect.DeepEqual(inv.Unused, want) {
+		t.Errorf("unused = %v, want %v", inv.Unused, want)
+	}
+
+	// A configured source that does not exist is reported
+	if _, err := ExtractFeatureFlags(dir, FlagOptions{Sources: []string{"flags/missing.yaml"}}); err == nil {
+		t.Error("expected an error for a missing configured source")
+	}
+}
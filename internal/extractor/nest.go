@@ -0,0 +1,119 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// nestRouteDecorators maps NestJS route decorators to HTTP methods
+var nestRouteDecorators = map[string]string{
+	"@Get": "GET", "@Post": "POST", "@Put": "PUT", "@Patch": "PATCH",
+	"@Delete": "DELETE", "@Options": "OPTIONS", "@Head": "HEAD", "@All": "ALL",
+}
+
+var (
+	// @Controller({ path: 'users', version: '1' })
+	nestPathOption = regexp.MustCompile("\\bpath\\s*:\\s*(['\"`][^'\"`]*['\"`])")
+
+	// :id parameters of an Express-style route
+	nestParamPattern = regexp.MustCompile(`:(\w+)`)
+)
+
+// NestEndpoints lists the routes of the NestJS controllers in a parsed
+// skeleton from their decorator arguments: the class's @Controller prefix,
+// each handler's @Get/@Post/... path, and the guards of @UseGuards on the
+// handler, else on the class. Line is the handler's, not its decorator's.
+func NestEndpoints(file string, sk *types.CodeSkeleton) []APIEndpoint {
+	var endpoints []APIEndpoint
+	for _, cls := range sk.Classes {
+		if !hasDecorator(cls.Decorators, "@Controller") {
+			continue
+		}
+		prefix := nestRoutePath(cls.DecoratorArgs["@Controller"])
+		classAuth := strings.Join(cls.DecoratorArgs["@UseGuards"], ", ")
+		for _, m := range cls.Methods {
+			for _, d := range m.Decorators {
+				method, ok := nestRouteDecorators[d]
+				if !ok {
+					continue
+				}
+				auth := classAuth
+				if guards := m.DecoratorArgs["@UseGuards"]; len(guards) > 0 {
+					auth = strings.Join(guards, ", ")
+				}
+				path := nestJoinRoute(prefix, nestRoutePath(m.DecoratorArgs[d]))
+				endpoints = append(endpoints, APIEndpoint{
+					Method:     method,
+					Path:       path,
+					Handler:    m.Name,
+					Controller: cls.Name,
+					Auth:       auth,
+					File:       file,
+					Line:       m.Line,
+					Params:     nestRouteParams(path),
+				})
+			}
+		}
+	}
+	return endpoints
+}
+
+// nestRoutePath is the path a route or controller decorator was given: a
+// string, the path option of an options object, or the first of several
+// paths. Other expressions (a ROUTES.USERS constant) are kept in braces.
+func nestRoutePath(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	arg := args[0]
+	if s, ok := skeleton.StringArg(arg); ok {
+		return s
+	}
+	switch {
+	case strings.HasPrefix(arg, "{"):
+		if m := nestPathOption.FindStringSubmatch(arg); m != nil {
+			if s, ok := skeleton.StringArg(m[1]); ok {
+				return s
+			}
+		}
+		return ""
+	case strings.HasPrefix(arg, "["):
+		first, _, _ := strings.Cut(strings.Trim(arg, "[] "), ",")
+		if s, ok := skeleton.StringArg(strings.TrimSpace(first)); ok {
+			return s
+		}
+	}
+	return "{" + arg + "}"
+}
+
+// nestJoinRoute joins a controller prefix and a handler path into one route
+func nestJoinRoute(parts ...string) string {
+	var segments []string
+	for _, p := range parts {
+		if p = strings.Trim(p, "/"); p != "" {
+			segments = append(segments, p)
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// nestRouteParams lists the :name parameters of a route
+func nestRouteParams(path string) []string {
+	var params []string
+	for _, m := range nestParamPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, m[1])
+	}
+	return params
+}
+
+func hasDecorator(decorators []string, name string) bool {
+	for _, d := range decorators {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
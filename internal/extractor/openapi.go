@@ -0,0 +1,371 @@
+package extractor
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SpecCoverage compares the endpoints declared in OpenAPI/Swagger specs
+// with the ones found in code
+type SpecCoverage struct {
+	Files        []string `json:"files"`
+	Declared     int      `json:"declared"`
+	Implemented  int      `json:"implemented"`
+	Missing      []string `json:"missing"`      // "GET /users/{id}" declared in a spec but not found in code
+	Undocumented []string `json:"undocumented"` // found in code but declared in no spec
+}
+
+// openAPIMethods are the operations of an OpenAPI path item
+var openAPIMethods = map[string]bool{
+	"get": true, "post": true, "put": true, "patch": true, "delete": true, "head": true, "options": true,
+}
+
+// FindOpenAPISpecs lists the OpenAPI/Swagger documents under dir: YAML or
+// JSON files named like openapi.yaml or swagger.json that declare an
+// openapi or swagger version
+func FindOpenAPISpecs(dir string) []string {
+	var specs []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if name == "node_modules" || name == "dist" || name == ".git" ||
+				name == "vendor" || name == "target" || name == "bin" || name == "obj" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		base := strings.ToLower(info.Name())
+		ext := filepath.Ext(base)
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+		if !strings.Contains(base, "openapi") && !strings.Contains(base, "swagger") {
+			return nil
+		}
+		if content, err := os.ReadFile(path); err == nil && isOpenAPISpec(string(content)) {
+			specs = append(specs, path)
+		}
+		return nil
+	})
+	return specs
+}
+
+// isOpenAPISpec reports whether a document declares an OpenAPI or Swagger version
+func isOpenAPISpec(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(line, "openapi:") || strings.HasPrefix(line, "swagger:") ||
+			strings.HasPrefix(trimmed, `"openapi"`) || strings.HasPrefix(trimmed, `"swagger"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseOpenAPISpec returns the endpoints an OpenAPI 3 or Swagger 2 document
+// declares, prefixed with its basePath or first server's path. Handler is
+// the operationId, Controller the first tag and Body the request schema.
+func ParseOpenAPISpec(path string) ([]APIEndpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+		return parseOpenAPIJSON(string(content), path)
+	}
+	return parseOpenAPIYAML(string(content), path), nil
+}
+
+func parseOpenAPIJSON(content, path string) ([]APIEndpoint, error) {
+	type schemaRef struct {
+		Ref string `json:"$ref"`
+	}
+	type operation struct {
+		OperationID string   `json:"operationId"`
+		Tags        []string `json:"tags"`
+		RequestBody struct {
+			Content map[string]struct {
+				Schema schemaRef `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+		Parameters []struct {
+			In     string    `json:"in"`
+			Schema schemaRef `json:"schema"`
+		} `json:"parameters"`
+	}
+	var doc struct {
+		BasePath string `json:"basePath"`
+		Servers  []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, err
+	}
+
+	prefix := doc.BasePath
+	if prefix == "" && len(doc.Servers) > 0 {
+		prefix = serverPath(doc.Servers[0].URL)
+	}
+
+	var paths []string
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var endpoints []APIEndpoint
+	for _, p := range paths {
+		// JSON keeps no positions; the path key's first occurrence is close enough
+		line := 0
+		offset := strings.Index(content, `"`+p+`"`)
+		if offset >= 0 {
+			line = strings.Count(content[:offset], "\n") + 1
+		}
+		var methods []string
+		for m := range doc.Paths[p] {
+			if openAPIMethods[strings.ToLower(m)] {
+				methods = append(methods, m)
+			}
+		}
+		sort.Strings(methods)
+		for _, m := range methods {
+			var op operation
+			if err := json.Unmarshal(doc.Paths[p][m], &op); err != nil {
+				continue
+			}
+			ep := APIEndpoint{
+				Method:  strings.ToUpper(m),
+				Path:    joinRoute(prefix, p),
+				Handler: op.OperationID,
+				File:    path,
+				Line:    line,
+			}
+			if len(op.Tags) > 0 {
+				ep.Controller = op.Tags[0]
+			}
+			if media, ok := op.RequestBody.Content["application/json"]; ok {
+				ep.Body = refName(media.Schema.Ref)
+			}
+			for _, param := range op.Parameters {
+				if param.In == "body" {
+					ep.Body = refName(param.Schema.Ref)
+				}
+			}
+			_, ep.Params = normalizeRoute(p)
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, nil
+}
+
+// parseOpenAPIYAML reads the paths of a YAML spec line by line: path keys,
+// their operations, and each operation's operationId, first tag and
+// request body $ref
+func parseOpenAPIYAML(content, path string) []APIEndpoint {
+	lines := strings.Split(content, "\n")
+
+	prefix := ""
+	for i, line := range lines {
+		indent, _, key, value, ok := yamlLine(line)
+		if !ok || indent != 0 {
+			continue
+		}
+		if key == "basePath" {
+			prefix = strings.Trim(value, `"'`)
+		}
+		if key == "servers" && prefix == "" {
+			for j := i + 1; j < len(lines); j++ {
+				childIndent, _, childKey, childValue, ok := yamlLine(lines[j])
+				if ok && childIndent == 0 {
+					break
+				}
+				if ok && childKey == "url" {
+					prefix = serverPath(strings.Trim(childValue, `"'`))
+					break
+				}
+			}
+		}
+	}
+
+	var endpoints []APIEndpoint
+	var current *APIEndpoint
+	inPaths := false
+	routeIndent, methodIndent := -1, -1
+	route := ""
+	refIndent := -1 // the requestBody (or in: body parameter) whose $ref is the body
+	wantTag := false
+	flush := func() {
+		if current != nil {
+			endpoints = append(endpoints, *current)
+			current = nil
+		}
+	}
+
+	for i, line := range lines {
+		indent, _, key, value, ok := yamlLine(line)
+		if !ok {
+			// The first tag listed under tags:
+			if trimmed := strings.TrimSpace(line); wantTag && current != nil && strings.HasPrefix(trimmed, "- ") {
+				current.Controller = strings.Trim(strings.TrimSpace(trimmed[2:]), `"'`)
+				wantTag = false
+			}
+			continue
+		}
+		if indent == 0 {
+			flush()
+			inPaths = key == "paths"
+			continue
+		}
+		if !inPaths {
+			continue
+		}
+		if routeIndent < 0 {
+			routeIndent = indent
+		}
+		switch {
+		case indent <= routeIndent:
+			flush()
+			route = strings.Trim(key, `"'`)
+			methodIndent = -1
+		case methodIndent < 0 || indent <= methodIndent:
+			flush()
+			methodIndent = indent
+			if openAPIMethods[strings.ToLower(key)] {
+				_, params := normalizeRoute(route)
+				current = &APIEndpoint{
+					Method: strings.ToUpper(key),
+					Path:   joinRoute(prefix, route),
+					File:   path,
+					Line:   i + 1,
+					Params: params,
+				}
+				refIndent, wantTag = -1, false
+			}
+		case current != nil:
+			value = strings.Trim(value, `"'`)
+			wantTag = false
+			if indent <= refIndent {
+				refIndent = -1
+			}
+			switch {
+			case key == "operationId":
+				current.Handler = value
+			case key == "tags" && value == "":
+				wantTag = true
+			case key == "tags" && current.Controller == "":
+				current.Controller = strings.Trim(strings.Split(strings.Trim(value, "[]"), ",")[0], ` "'`)
+			case key == "requestBody", key == "in" && value == "body":
+				refIndent = indent
+			case key == "$ref" && refIndent >= 0 && current.Body == "":
+				current.Body = refName(value)
+			}
+		}
+	}
+	flush()
+	return endpoints
+}
+
+// MergeOpenAPISpecs adds the endpoints declared in specFiles to the surface
+// and compares them with the ones found in code. Endpoints in both are
+// marked documented, code-only ones undocumented, and spec-only ones are
+// added marked missing. A declared path matches a route that equals it or
+// ends with it (or the other way round), so global prefixes like /api
+// declared on only one side still match; parameter names are ignored.
+func MergeOpenAPISpecs(surface *APISurface, specFiles []string) {
+	coverage := &SpecCoverage{Files: specFiles, Missing: []string{}, Undocumented: []string{}}
+
+	var declared []APIEndpoint
+	for _, file := range specFiles {
+		if endpoints, err := ParseOpenAPISpec(file); err == nil {
+			declared = append(declared, endpoints...)
+		}
+	}
+	coverage.Declared = len(declared)
+
+	matched := make([]bool, len(surface.Endpoints))
+	for _, spec := range declared {
+		found := false
+		for i := range surface.Endpoints {
+			ep := &surface.Endpoints[i]
+			if ep.Method == "GRPC" || !sameRoute(spec, *ep) {
+				continue
+			}
+			matched[i], found = true, true
+			if ep.Handler == "" {
+				ep.Handler = spec.Handler
+			}
+		}
+		if found {
+			coverage.Implemented++
+			continue
+		}
+		spec.Spec = "missing"
+		surface.Endpoints = append(surface.Endpoints, spec)
+		coverage.Missing = append(coverage.Missing, spec.Method+" "+spec.Path)
+	}
+
+	undocumented := make(map[string]bool)
+	for i := range matched {
+		ep := &surface.Endpoints[i]
+		switch {
+		case ep.Method == "GRPC":
+		case matched[i]:
+			ep.Spec = "documented"
+		default:
+			ep.Spec = "undocumented"
+			if route := ep.Method + " " + ep.Path; !undocumented[route] {
+				undocumented[route] = true
+				coverage.Undocumented = append(coverage.Undocumented, route)
+			}
+		}
+	}
+	surface.Spec = coverage
+}
+
+// sameRoute reports whether a declared and an implemented endpoint are the
+// same operation; ANY (Django) matches every method
+func sameRoute(spec, code APIEndpoint) bool {
+	if code.Method != "ANY" && code.Method != spec.Method {
+		return false
+	}
+	a, b := routeKey(spec.Path), routeKey(code.Path)
+	return a == b || strings.HasSuffix(a, b) || strings.HasSuffix(b, a)
+}
+
+// routeKey normalizes a route for matching: lowercase, parameters blanked
+// and no trailing slash
+func routeKey(path string) string {
+	path = routeParamPattern.ReplaceAllString(strings.ToLower(path), "{}")
+	return "/" + strings.Trim(path, "/")
+}
+
+// joinRoute prefixes a declared path with the spec's base path
+func joinRoute(prefix, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return path
+	}
+	return prefix + "/" + strings.TrimPrefix(path, "/")
+}
+
+// serverPath returns the path of a server URL (https://api.example.com/v1 is /v1)
+func serverPath(raw string) string {
+	if u, err := url.Parse(raw); err == nil && !strings.Contains(raw, "{") {
+		return u.Path
+	}
+	return ""
+}
+
+// refName returns the schema a $ref points to (#/components/schemas/User is User)
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
@@ -0,0 +1,136 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const usersOpenAPI = `openapi: 3.0.3
+info:
+  title: Users
+  version: "1.0"
+servers:
+  - url: https://api.example.com/api
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UserList'
+    post:
+      operationId: createUser
+      tags:
+        - users
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateUser'
+  /users/{userId}:
+    parameters:
+      - name: userId
+        in: path
+    delete:
+      operationId: deleteUser
+components:
+  schemas:
+    User:
+      type: object
+`
+
+const ordersSwagger = `{
+  "swagger": "2.0",
+  "basePath": "/v2",
+  "paths": {
+    "/orders": {
+      "post": {
+        "operationId": "placeOrder",
+        "parameters": [{"in": "body", "schema": {"$ref": "#/definitions/Order"}}]
+      }
+    }
+  }
+}`
+
+func TestOpenAPISpecs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"docs/openapi.yaml": usersOpenAPI,
+		"swagger.json":      ordersSwagger,
+		"openapi-notes.md":  "openapi: not a spec",
+		"src/users.controller.ts": `@Controller('users')
+export class UsersController {
+  @Get()
+  list() {}
+
+  @Get(':id')
+  get(@Param('id') id: string) {}
+
+  @Delete(':id')
+  remove(@Param('id') id: string) {}
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	specs := FindOpenAPISpecs(dir)
+	if len(specs) != 2 {
+		t.Fatalf("specs = %v", specs)
+	}
+
+	declared, err := ParseOpenAPISpec(filepath.Join(dir, "docs", "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("ParseOpenAPISpec: %v", err)
+	}
+	want := []APIEndpoint{
+		{Method: "GET", Path: "/api/users", Handler: "listUsers", Controller: "users", Line: 9},
+		{Method: "POST", Path: "/api/users", Handler: "createUser", Controller: "users", Body: "CreateUser", Line: 18},
+		{Method: "DELETE", Path: "/api/users/{userId}", Handler: "deleteUser", Params: []string{"userId"}, Line: 31},
+	}
+	for i := range declared {
+		declared[i].File = ""
+	}
+	if !reflect.DeepEqual(declared, want) {
+		t.Errorf("declared = %+v", declared)
+	}
+
+	surface, err := ExtractAPISurface(dir, "users")
+	if err != nil {
+		t.Fatalf("ExtractAPISurface: %v", err)
+	}
+	MergeOpenAPISpecs(surface, specs)
+
+	// The global /api prefix and parameter names do not matter
+	spec := surface.Spec
+	if spec.Declared != 4 || spec.Implemented != 2 {
+		t.Errorf("declared %d, implemented %d", spec.Declared, spec.Implemented)
+	}
+	if !reflect.DeepEqual(spec.Missing, []string{"POST /api/users", "POST /v2/orders"}) {
+		t.Errorf("missing = %v", spec.Missing)
+	}
+	if !reflect.DeepEqual(spec.Undocumented, []string{"GET /users/:id"}) {
+		t.Errorf("undocumented = %v", spec.Undocumented)
+	}
+
+	marks := make(map[string]string)
+	for _, ep := range surface.Endpoints {
+		marks[ep.Method+" "+ep.Path] = ep.Spec
+	}
+	if marks["DELETE /users/:id"] != "documented" || marks["POST /v2/orders"] != "missing" {
+		t.Errorf("endpoints = %v", marks)
+	}
+}
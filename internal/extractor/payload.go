@@ -0,0 +1,522 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PayloadField is a field of a request body type
+type PayloadField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// PayloadType is a request body type: a TS DTO class or interface, a
+// pydantic model, a Java class or record, or a C# class or record
+type PayloadType struct {
+	Name    string         `json:"name"`
+	File    string         `json:"file"`
+	Extends string         `json:"extends,omitempty"`
+	Fields  []PayloadField `json:"fields"`
+}
+
+// PayloadTypes indexes payload types by name
+type PayloadTypes map[string]PayloadType
+
+// Payload type patterns
+var (
+	tsClassPattern = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?(?:class|interface)\s+(\w+)(?:<[^>]*>)?(?:\s+extends\s+(?:(?:PartialType|OmitType|PickType|IntersectionType)\(\s*)?(\w+))?`)
+	tsFieldPattern = regexp.MustCompile(`^\s*(?:@\w+(?:\([^)]*\))?\s*)*(?:(?:public|readonly|declare)\s+)*(\w+)([?!])?\s*:\s*([^;=]+)`)
+
+	pyClassPattern = regexp.MustCompile(`^class\s+(\w+)\s*\(([^)]*)\)\s*:`)
+	pyFieldPattern = regexp.MustCompile(`^(\s+)(\w+)\s*:\s*([^=#]+?)\s*(=.*)?$`)
+
+	javaClassPattern  = regexp.MustCompile(`\bclass\s+(\w+)(?:<[^>]*>)?(?:\s+extends\s+(\w+))?`)
+	javaFieldPattern  = regexp.MustCompile(`^\s*(?:private|protected|public)\s+(?:final\s+)?([\w.<>,?\[\]\s]+?)\s+(\w+)\s*(=[^;]*)?;`)
+	csClassPattern    = regexp.MustCompile(`\bclass\s+(\w+)(?:<[^>]*>)?(?:\s*:\s*(\w+))?`)
+	csPropertyPattern = regexp.MustCompile(`^\s*public\s+(?:required\s+)?([\w.<>,?\[\]\s]+?)\s+(\w+)\s*\{\s*get;`)
+	recordPattern     = regexp.MustCompile(`\brecord\s+(\w+)\s*\(([^)]*)\)`)
+)
+
+// pyModelBases are base classes marking a Python class as a request model
+var pyModelBases = map[string]bool{
+	"BaseModel": true, "Schema": true, "BaseSchema": true, "SQLModel": true,
+	"TypedDict": true, "Serializer": true, "ModelSerializer": true,
+}
+
+// pyEnumBases are base classes of enums, which are not payload types
+var pyEnumBases = map[string]bool{"Enum": true, "IntEnum": true, "StrEnum": true, "Flag": true, "IntFlag": true}
+
+// ExtractPayloadTypes collects the types request bodies can be declared
+// with under a directory
+func ExtractPayloadTypes(dirPath string) (PayloadTypes, error) {
+	payloads := make(PayloadTypes)
+
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if name == "node_modules" || name == "dist" || name == ".git" ||
+				name == "vendor" || name == "target" || name == "bin" || name == "obj" ||
+				name == "__pycache__" || name == ".venv" || name == "venv" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var extract func([]string, string, PayloadTypes)
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ts":
+			extract = extractTSPayloads
+		case ".py":
+			extract = extractPythonPayloads
+		case ".java":
+			extract = extractJavaPayloads
+		case ".cs":
+			extract = extractCSharpPayloads
+		default:
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		extract(strings.Split(string(content), "\n"), path, payloads)
+		return nil
+	})
+
+	return payloads, err
+}
+
+// classBody returns the lines directly inside the braces opened on or
+// after lines[start]
+func classBody(lines []string, start int) []string {
+	var body []string
+	depth := 0
+	opened := false
+	for i := start; i < len(lines); i++ {
+		if opened && depth == 1 {
+			body = append(body, lines[i])
+		}
+		depth += strings.Count(lines[i], "{") - strings.Count(lines[i], "}")
+		if depth > 0 {
+			opened = true
+		}
+		if opened && depth <= 0 {
+			break
+		}
+	}
+	return body
+}
+
+func extractTSPayloads(lines []string, filePath string, payloads PayloadTypes) {
+	for i, line := range lines {
+		m := tsClassPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		pt := PayloadType{Name: m[1], File: filePath, Extends: m[2]}
+		for _, field := range classBody(lines, i) {
+			// Methods, private/static members and decorator-only lines don't
+			// match; function-typed properties are skipped
+			fm := tsFieldPattern.FindStringSubmatch(field)
+			if fm == nil || strings.Contains(fm[3], "=>") {
+				continue
+			}
+			pt.Fields = append(pt.Fields, PayloadField{
+				Name:     fm[1],
+				Type:     strings.TrimSpace(fm[3]),
+				Optional: fm[2] == "?",
+			})
+		}
+		payloads[pt.Name] = pt
+	}
+}
+
+func extractPythonPayloads(lines []string, filePath string, payloads PayloadTypes) {
+	for i, line := range lines {
+		m := pyClassPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		pt := PayloadType{Name: m[1], File: filePath}
+		isModel, isEnum := false, false
+		for _, base := range strings.Split(m[2], ",") {
+			base = strings.TrimSpace(base)
+			if idx := strings.LastIndex(base, "."); idx >= 0 {
+				base = base[idx+1:]
+			}
+			if pyEnumBases[base] {
+				isEnum = true
+			} else if pyModelBases[base] {
+				isModel = true
+			} else if base != "" && pt.Extends == "" {
+				pt.Extends = base
+			}
+		}
+
+		indent := ""
+		for _, field := range lines[i+1:] {
+			if strings.TrimSpace(field) == "" {
+				continue
+			}
+			if !strings.HasPrefix(field, " ") && !strings.HasPrefix(field, "\t") {
+				break
+			}
+			fm := pyFieldPattern.FindStringSubmatch(field)
+			if fm == nil {
+				continue
+			}
+			if indent == "" {
+				indent = fm[1]
+			}
+			if fm[1] != indent || strings.HasPrefix(fm[2], "_") || fm[2] == "model_config" {
+				continue
+			}
+			typ := strings.TrimSpace(fm[3])
+			pt.Fields = append(pt.Fields, PayloadField{
+				Name:     fm[2],
+				Type:     typ,
+				Optional: fm[4] != "" || strings.HasPrefix(typ, "Optional[") || strings.Contains(typ, "None"),
+			})
+		}
+		// Plain classes are kept when they subclass another model
+		if !isEnum && (isModel || pt.Extends != "") {
+			payloads[pt.Name] = pt
+		}
+	}
+}
+
+func extractJavaPayloads(lines []string, filePath string, payloads PayloadTypes) {
+	for i, line := range lines {
+		if m := recordPattern.FindStringSubmatch(line); m != nil {
+			payloads[m[1]] = PayloadType{Name: m[1], File: filePath, Fields: recordFields(m[2], false)}
+			continue
+		}
+		m := javaClassPattern.FindStringSubmatch(line)
+		if m == nil || strings.HasPrefix(strings.TrimSpace(line), "//") || strings.HasPrefix(strings.TrimSpace(line), "*") {
+			continue
+		}
+		pt := PayloadType{Name: m[1], File: filePath, Extends: m[2]}
+		for _, field := range classBody(lines, i) {
+			if strings.Contains(field, " static ") {
+				continue
+			}
+			if fm := javaFieldPattern.FindStringSubmatch(field); fm != nil {
+				pt.Fields = append(pt.Fields, PayloadField{Name: fm[2], Type: strings.TrimSpace(fm[1])})
+			}
+		}
+		payloads[pt.Name] = pt
+	}
+}
+
+func extractCSharpPayloads(lines []string, filePath string, payloads PayloadTypes) {
+	for i, line := range lines {
+		if m := recordPattern.FindStringSubmatch(line); m != nil {
+			payloads[m[1]] = PayloadType{Name: m[1], File: filePath, Fields: recordFields(m[2], true)}
+			continue
+		}
+		m := csClassPattern.FindStringSubmatch(line)
+		if m == nil || strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		pt := PayloadType{Name: m[1], File: filePath, Extends: m[2]}
+		for _, field := range classBody(lines, i) {
+			if strings.Contains(field, " static ") {
+				continue
+			}
+			if fm := csPropertyPattern.FindStringSubmatch(field); fm != nil {
+				typ := strings.TrimSpace(fm[1])
+				pt.Fields = append(pt.Fields, PayloadField{
+					Name:     camelCase(fm[2]),
+					Type:     typ,
+					Optional: strings.HasSuffix(typ, "?"),
+				})
+			}
+		}
+		payloads[pt.Name] = pt
+	}
+}
+
+// recordFields parses "Type name, Type name" record components. ASP.NET
+// serializes C# properties in camelCase.
+func recordFields(components string, camel bool) []PayloadField {
+	var fields []PayloadField
+	for _, component := range splitTopLevel(components) {
+		parts := strings.Fields(strings.TrimSpace(component))
+		// Drop annotations/attributes such as @NotNull or [Required]
+		for len(parts) > 0 && (strings.HasPrefix(parts[0], "@") || strings.HasPrefix(parts[0], "[")) {
+			parts = parts[1:]
+		}
+		if len(parts) < 2 {
+			continue
+		}
+		name := parts[len(parts)-1]
+		if camel {
+			name = camelCase(name)
+		}
+		fields = append(fields, PayloadField{Name: name, Type: strings.Join(parts[:len(parts)-1], " ")})
+	}
+	return fields
+}
+
+// splitTopLevel splits on commas outside of <> and []
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '<', '[', '(':
+			depth++
+		case '>', ']', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func camelCase(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// maxPayloadDepth bounds nested types in example payloads
+const maxPayloadDepth = 4
+
+// exampleObject is a JSON object that keeps its fields in declaration order
+type exampleObject []exampleField
+
+type exampleField struct {
+	Key   string
+	Value interface{}
+}
+
+// MarshalJSON writes the fields in order
+func (o exampleObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ExamplePayload builds an example JSON body for a payload type, with values
+// inferred from field types and names. It returns false for unknown types.
+func ExamplePayload(typeName string, payloads PayloadTypes) (string, bool) {
+	if _, ok := payloads[baseTypeName(typeName)]; !ok {
+		return "", false
+	}
+	data, err := json.MarshalIndent(exampleValue(typeName, "", payloads, 0), "", "  ")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// payloadFields returns a type's fields after the ones it inherits
+func payloadFields(name string, payloads PayloadTypes, seen map[string]bool) []PayloadField {
+	pt, ok := payloads[name]
+	if !ok || seen[name] {
+		return nil
+	}
+	seen[name] = true
+	fields := payloadFields(pt.Extends, payloads, seen)
+	for _, f := range pt.Fields {
+		replaced := false
+		for i := range fields {
+			if fields[i].Name == f.Name {
+				fields[i] = f
+				replaced = true
+			}
+		}
+		if !replaced {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+var (
+	genericPattern  = regexp.MustCompile(`^([\w.]+)\s*[<\[](.*)[>\]]$`)
+	exampleDate     = "2024-01-01T00:00:00Z"
+	exampleListKind = map[string]bool{
+		"array": true, "list": true, "set": true, "sequence": true, "tuple": true, "frozenset": true,
+		"collection": true, "ienumerable": true, "icollection": true, "ilist": true, "ireadonlylist": true,
+		"ireadonlycollection": true, "hashset": true, "conlist": true,
+	}
+	exampleOptionalKind = map[string]bool{"optional": true, "nullable": true, "annotated": true}
+	exampleMapKind      = map[string]bool{
+		"dict": true, "map": true, "record": true, "dictionary": true, "idictionary": true,
+		"hashmap": true, "mapping": true, "partial": true,
+	}
+)
+
+// exampleValue returns an example for a declared type. field is the field
+// name, used to pick realistic strings (emails, URLs, ids).
+func exampleValue(typ, field string, payloads PayloadTypes, depth int) interface{} {
+	typ = strings.TrimSpace(typ)
+
+	// Unions: first non-null member (string | null, X | None)
+	if parts := splitUnion(typ); len(parts) > 1 {
+		for _, part := range parts {
+			if p := strings.TrimSpace(part); p != "null" && p != "undefined" && p != "None" {
+				return exampleValue(p, field, payloads, depth)
+			}
+		}
+	}
+	typ = strings.TrimSuffix(typ, "?")
+
+	if strings.HasSuffix(typ, "[]") {
+		return []interface{}{exampleValue(strings.TrimSuffix(typ, "[]"), field, payloads, depth)}
+	}
+	if m := genericPattern.FindStringSubmatch(typ); m != nil {
+		outer := strings.ToLower(m[1][strings.LastIndex(m[1], ".")+1:])
+		args := splitTopLevel(m[2])
+		switch {
+		case exampleListKind[outer]:
+			return []interface{}{exampleValue(args[0], field, payloads, depth)}
+		case exampleOptionalKind[outer]:
+			return exampleValue(args[0], field, payloads, depth)
+		case exampleMapKind[outer]:
+			return exampleObject{}
+		case outer == "literal":
+			return literalValue(args[0])
+		}
+		typ = m[1]
+	}
+
+	name := baseTypeName(typ)
+	if _, ok := payloads[name]; ok {
+		if depth >= maxPayloadDepth {
+			return exampleObject{}
+		}
+		obj := exampleObject{}
+		for _, f := range payloadFields(name, payloads, make(map[string]bool)) {
+			obj = append(obj, exampleField{Key: f.Name, Value: exampleValue(f.Type, f.Name, payloads, depth+1)})
+		}
+		return obj
+	}
+
+	// Python's date is a calendar date; Date in TS and Java is a timestamp
+	if name == "date" {
+		return exampleDate[:10]
+	}
+	switch strings.ToLower(name) {
+	case "number", "int", "integer", "long", "short", "byte", "int32", "int64", "uint", "biginteger", "bigint", "conint", "positiveint":
+		return 0
+	case "float", "double", "decimal", "bigdecimal", "confloat":
+		return 0.0
+	case "boolean", "bool":
+		return false
+	case "localdate", "dateonly":
+		return exampleDate[:10]
+	case "date", "datetime", "localdatetime", "instant", "offsetdatetime", "zoneddatetime", "datetimeoffset", "timestamp":
+		return exampleDate
+	case "uuid", "guid", "uuid4":
+		return "00000000-0000-0000-0000-000000000000"
+	case "any", "object", "unknown", "dict", "map", "jsonnode", "jsonvalue", "json":
+		return exampleObject{}
+	case "list", "array", "set":
+		return []interface{}{}
+	case "emailstr":
+		return "user@example.com"
+	case "httpurl", "anyurl", "uri", "url":
+		return "https://example.com"
+	}
+	if strings.HasPrefix(typ, "'") || strings.HasPrefix(typ, `"`) {
+		return strings.Trim(typ, `'"`)
+	}
+	return exampleString(field)
+}
+
+// exampleString picks a string example from the field name
+func exampleString(field string) string {
+	lower := strings.ToLower(field)
+	switch {
+	case strings.Contains(lower, "email"):
+		return "user@example.com"
+	case strings.Contains(lower, "password") || strings.Contains(lower, "secret"):
+		return "change-me"
+	case strings.Contains(lower, "url") || strings.Contains(lower, "uri") || strings.Contains(lower, "link"):
+		return "https://example.com"
+	case strings.Contains(lower, "phone"):
+		return "+15555550100"
+	case strings.HasSuffix(lower, "date") || strings.HasSuffix(field, "At") || strings.HasSuffix(lower, "_at"):
+		return exampleDate
+	case lower == "id" || strings.HasSuffix(field, "Id") || strings.HasSuffix(lower, "_id"):
+		return "1"
+	case lower == "":
+		return "string"
+	}
+	return field
+}
+
+// literalValue returns the first member of a Literal["a", "b"]
+func literalValue(args string) interface{} {
+	first := strings.TrimSpace(splitTopLevel(args)[0])
+	if strings.HasPrefix(first, "'") || strings.HasPrefix(first, `"`) {
+		return strings.Trim(first, `'"`)
+	}
+	return first
+}
+
+// splitUnion splits a TS or Python union on top-level |
+func splitUnion(typ string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range typ {
+		switch r {
+		case '<', '[', '(', '{':
+			depth++
+		case '>', ']', ')', '}':
+			depth--
+		case '|':
+			if depth == 0 {
+				parts = append(parts, typ[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, typ[start:])
+}
+
+// baseTypeName strips qualifiers and generics: models.CreateUser -> CreateUser
+func baseTypeName(typ string) string {
+	typ = strings.TrimSpace(typ)
+	if idx := strings.IndexAny(typ, "<["); idx >= 0 {
+		typ = typ[:idx]
+	}
+	if idx := strings.LastIndex(typ, "."); idx >= 0 {
+		typ = typ[idx+1:]
+	}
+	return strings.TrimSuffix(typ, "?")
+}
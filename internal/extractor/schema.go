@@ -30,7 +30,7 @@ type SchemaField struct {
 // Relation represents a relationship between models
 type Relation struct {
 	Name       string   `json:"name"`
-	Type       string   `json:"type"` // "one-to-one", "one-to-many", "many-to-many"
+	Type       string   `json:"type"` // "one-to-one", "one-to-many", "many-to-one", "many-to-many"
 	Model      string   `json:"model"`
 	Fields     []string `json:"fields,omitempty"`
 	References []string `json:"references,omitempty"`
@@ -38,7 +38,8 @@ var (
 	typeormColumnPattern = regexp.MustCompile(`@Column\s*\([^)]*\)\s*(\w+)\s*[?:]?\s*:\s*(\w+)`)
 )
 
-// ExtractMultiLangSchema extracts schema/models from multiple languages
+// ExtractMultiLangSchema extracts schema/models from multiple languages,
+// including the tables raw SQL schemas and migrations define
 func ExtractMultiLangSchema(path string) (*SchemaInfo, error) {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -57,6 +58,9 @@ func ExtractMultiLangSchema(path string) (*SchemaInfo, error) {
 		".nx": true, ".cache": true, "coverage": true,
 	}
 
+	// SQL statements are replayed across files, in the order migrations run
+	sql := newSQLSchema()
+
 	if info.IsDir() {
 		err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 			if err != nil || info.IsDir() {
@@ -93,10 +97,13 @@ func ExtractMultiLangSchema(path string) (*SchemaInfo, error) {
 				extractJavaModels(text, filePath, schema)
 			case ".ts":
 				extractTypeORMModels(text, filePath, schema)
+			case ".sql":
+				sql.parse(text, filePath)
 			}
 
 			return nil
 		})
+		sql.addTo(schema)
 		return schema, err
 	}
 
@@ -119,6 +126,9 @@ func ExtractMultiLangSchema(path string) (*SchemaInfo, error) {
 		extractJavaModels(text, path, schema)
 	case ".ts":
 		extractTypeORMModels(text, path, schema)
+	case ".sql":
+		sql.parse(text, path)
+		sql.addTo(schema)
 	}
 
 	return schema, nil
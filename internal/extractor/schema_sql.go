@@ -0,0 +1,543 @@
+package extractor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Raw SQL DDL: schema dumps and migrations. CREATE TABLE defines a model
+// and later ALTER TABLE, DROP TABLE and ALTER TYPE statements are replayed
+// on it, so a directory of migrations yields the schema they end with.
+
+const sqlName = `((?:[\w"` + "`" + `\[\]]+\.)?[\w"` + "`" + `\[\]]+)`
+
+var (
+	sqlCreateTable = regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:(?:GLOBAL|LOCAL)\s+)?(?:TEMP(?:ORARY)?\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + sqlName + `\s*\(`)
+	sqlAlterTable  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?` + sqlName + `\s+(.*)$`)
+	sqlDropTable   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(.*?)(?:\s+(?:CASCADE|RESTRICT))?$`)
+	sqlCreateEnum  = regexp.MustCompile(`(?is)^CREATE\s+TYPE\s+` + sqlName + `\s+AS\s+ENUM\s*\((.*)\)$`)
+	sqlAlterEnum   = regexp.MustCompile(`(?is)^ALTER\s+TYPE\s+` + sqlName + `\s+ADD\s+VALUE\s+(?:IF\s+NOT\s+EXISTS\s+)?'([^']*)'`)
+
+	// Where a column's type ends and its constraints begin
+	sqlColumnKeyword = regexp.MustCompile(`(?i)\s(?:NOT\s+NULL|NULL|DEFAULT|PRIMARY\s+KEY|UNIQUE|REFERENCES|CHECK|CONSTRAINT|GENERATED|COLLATE|AUTO_INCREMENT|AUTOINCREMENT|COMMENT|IDENTITY|ON\s+UPDATE)\b`)
+	sqlDefault       = regexp.MustCompile(`(?i)\bDEFAULT\s+('(?:[^']|'')*'|\([^)]*\)|[\w.:]+(?:\([^)]*\))?)`)
+	sqlReferences    = regexp.MustCompile(`(?i)\bREFERENCES\s+` + sqlName + `\s*(?:\(\s*([^)]*)\))?`)
+	sqlKeyColumns    = regexp.MustCompile(`(?i)^(?:CONSTRAINT\s+\S+\s+)?(PRIMARY\s+KEY|UNIQUE(?:\s+KEY|\s+INDEX)?|FOREIGN\s+KEY)\s*(?:\S+\s*)?\(([^)]*)\)(.*)$`)
+	sqlTableItem     = regexp.MustCompile(`(?i)^(?:CONSTRAINT|PRIMARY\s+KEY|FOREIGN\s+KEY|UNIQUE|CHECK|INDEX|KEY|FULLTEXT|SPATIAL|EXCLUDE|PERIOD)\b`)
+	sqlUnique        = regexp.MustCompile(`\bUNIQUE\b`)
+	sqlColumnWord    = regexp.MustCompile(`(?i)^COLUMN\s+`)
+	sqlIfExists      = regexp.MustCompile(`(?i)^IF\s+(?:NOT\s+)?EXISTS\s+`)
+	sqlQuoted        = regexp.MustCompile(`'((?:[^']|'')*)'`)
+	sqlDollarQuote   = regexp.MustCompile(`^\$\w*\$`)
+	flywayUndo       = regexp.MustCompile(`^u\d+(?:[._]\d+)*__`)
+)
+
+// sqlDownMarkers end the "up" part of a migration that holds both directions
+var sqlDownMarkers = []string{"-- +goose Down", "-- migrate:down", "-- +migrate Down"}
+
+// sqlSchema collects the tables and enum types of SQL files, per directory
+// so that services with their own migrations keep their own tables
+type sqlSchema struct {
+	models     map[string]*SchemaModel
+	modelOrder []string
+	enums      map[string]*SchemaEnum
+	enumOrder  []string
+}
+
+func newSQLSchema() *sqlSchema {
+	return &sqlSchema{models: make(map[string]*SchemaModel), enums: make(map[string]*SchemaEnum)}
+}
+
+// isDownMigration reports whether a file only reverts a migration
+func isDownMigration(filePath string) bool {
+	base := strings.ToLower(filepath.Base(filePath))
+	return strings.HasSuffix(base, ".down.sql") || strings.HasSuffix(base, "_down.sql") ||
+		base == "down.sql" || flywayUndo.MatchString(base)
+}
+
+// parse replays one file's DDL statements
+func (s *sqlSchema) parse(content, filePath string) {
+	if isDownMigration(filePath) {
+		return
+	}
+	for _, marker := range sqlDownMarkers {
+		if i := strings.Index(content, marker); i >= 0 {
+			content = content[:i]
+		}
+	}
+	dir := filepath.Dir(filePath)
+	for _, stmt := range splitSQL(content) {
+		text := stmt.text
+		switch {
+		case sqlCreateTable.MatchString(text):
+			m := sqlCreateTable.FindStringSubmatchIndex(text)
+			name := sqlUnquote(text[m[2]:m[3]])
+			body, _ := sqlParens(text[m[1]-1:])
+			model := &SchemaModel{
+				Name:       name,
+				Fields:     []SchemaField{},
+				Attributes: []string{"table:" + name},
+				File:       filePath,
+				Line:       stmt.line,
+			}
+			for _, item := range sqlSplitList(body) {
+				applyTableItem(model, item)
+			}
+			key := sqlKey(dir, name)
+			if _, exists := s.models[key]; !exists {
+				s.modelOrder = append(s.modelOrder, key)
+			}
+			s.models[key] = model
+		case sqlAlterTable.MatchString(text):
+			m := sqlAlterTable.FindStringSubmatch(text)
+			key := sqlKey(dir, sqlUnquote(m[1]))
+			model, ok := s.models[key]
+			if !ok {
+				continue
+			}
+			for _, action := range sqlSplitList(m[2]) {
+				if renamed := applyAlterAction(model, action); renamed != "" {
+					// RENAME TO moves the table, and the foreign keys to it, to its new name
+					old := key
+					delete(s.models, old)
+					key = sqlKey(dir, renamed)
+					s.models[key] = model
+					for i, k := range s.modelOrder {
+						if k == old {
+							s.modelOrder[i] = key
+						}
+					}
+					s.renameReferences(dir, old, renamed)
+				}
+			}
+		case sqlDropTable.MatchString(text):
+			m := sqlDropTable.FindStringSubmatch(text)
+			for _, name := range strings.Split(m[1], ",") {
+				delete(s.models, sqlKey(dir, sqlUnquote(strings.TrimSpace(name))))
+			}
+		case sqlCreateEnum.MatchString(text):
+			m := sqlCreateEnum.FindStringSubmatch(text)
+			enum := &SchemaEnum{Name: sqlUnquote(m[1]), Values: sqlStrings(m[2]), File: filePath, Line: stmt.line}
+			key := sqlKey(dir, enum.Name)
+			if _, exists := s.enums[key]; !exists {
+				s.enumOrder = append(s.enumOrder, key)
+			}
+			s.enums[key] = enum
+		case sqlAlterEnum.MatchString(text):
+			m := sqlAlterEnum.FindStringSubmatch(text)
+			if enum, ok := s.enums[sqlKey(dir, sqlUnquote(m[1]))]; ok {
+				enum.Values = append(enum.Values, m[2])
+			}
+		}
+	}
+}
+
+// renameReferences points the foreign keys to a renamed table at its new name
+func (s *sqlSchema) renameReferences(dir, oldKey, name string) {
+	for key, model := range s.models {
+		if !strings.HasPrefix(key, dir+"\x00") {
+			continue
+		}
+		for i, r := range model.Relations {
+			if sqlKey(dir, r.Model) != oldKey {
+				continue
+			}
+			model.Relations[i].Model = name
+			for j := range model.Fields {
+				for k, a := range model.Fields[j].Attributes {
+					if a == "REFERENCES "+r.Model {
+						model.Fields[j].Attributes[k] = "REFERENCES " + name
+					}
+				}
+			}
+		}
+	}
+}
+
+// addTo appends the tables and enums still defined after every statement
+func (s *sqlSchema) addTo(schema *SchemaInfo) {
+	for _, key := range s.modelOrder {
+		if model, ok := s.models[key]; ok {
+			schema.Models = append(schema.Models, *model)
+		}
+	}
+	for _, key := range s.enumOrder {
+		schema.Enums = append(schema.Enums, *s.enums[key])
+	}
+}
+
+// applyTableItem adds a column definition or table constraint to a model
+func applyTableItem(model *SchemaModel, item string) {
+	if m := sqlKeyColumns.FindStringSubmatch(item); m != nil {
+		columns := parseArrayItems(m[2])
+		for i := range columns {
+			columns[i] = sqlUnquote(columns[i])
+		}
+		kind := strings.ToUpper(strings.Fields(m[1])[0])
+		switch kind {
+		case "PRIMARY":
+			for _, c := range columns {
+				if f := sqlField(model, c); f != nil {
+					f.IsOptional = false
+					f.Attributes = appendAttribute(f.Attributes, "PRIMARY KEY")
+				}
+			}
+		case "UNIQUE":
+			if len(columns) == 1 {
+				if f := sqlField(model, columns[0]); f != nil {
+					f.Attributes = appendAttribute(f.Attributes, "UNIQUE")
+				}
+			}
+		case "FOREIGN":
+			if r := sqlReferences.FindStringSubmatch(m[3]); r != nil {
+				addSQLRelation(model, columns, r)
+			}
+		}
+		return
+	}
+	if sqlTableItem.MatchString(item) {
+		return
+	}
+
+	field, ref := parseSQLColumn(item)
+	if field == nil {
+		return
+	}
+	if f := sqlField(model, field.Name); f != nil {
+		*f = *field
+	} else {
+		model.Fields = append(model.Fields, *field)
+	}
+	if ref != nil {
+		addSQLRelation(model, []string{field.Name}, ref)
+	}
+}
+
+// applyAlterAction applies one ALTER TABLE action, returning the table's
+// new name for RENAME TO
+func applyAlterAction(model *SchemaModel, action string) string {
+	words := strings.Fields(action)
+	if len(words) < 2 {
+		return ""
+	}
+	verb := strings.ToUpper(words[0])
+	rest := strings.TrimSpace(action[len(words[0]):])
+	rest = sqlColumnWord.ReplaceAllString(rest, "")
+	upperRest := strings.ToUpper(rest)
+
+	switch verb {
+	case "ADD":
+		rest = sqlIfExists.ReplaceAllString(rest, "")
+		applyTableItem(model, rest)
+	case "MODIFY":
+		applyTableItem(model, rest)
+	case "CHANGE":
+		// CHANGE old new_definition (MySQL)
+		if parts := strings.Fields(rest); len(parts) > 2 {
+			dropSQLColumn(model, sqlUnquote(parts[0]))
+			applyTableItem(model, strings.TrimSpace(rest[len(parts[0]):]))
+		}
+	case "DROP":
+		if strings.HasPrefix(upperRest, "CONSTRAINT") || strings.HasPrefix(upperRest, "INDEX") ||
+			strings.HasPrefix(upperRest, "PRIMARY") || strings.HasPrefix(upperRest, "FOREIGN") {
+			return ""
+		}
+		rest = sqlIfExists.ReplaceAllString(rest, "")
+		if parts := strings.Fields(rest); len(parts) > 0 {
+			dropSQLColumn(model, sqlUnquote(parts[0]))
+		}
+	case "RENAME":
+		parts := strings.Fields(rest)
+		switch {
+		case len(parts) == 2 && strings.EqualFold(parts[0], "TO"):
+			name := sqlUnquote(parts[1])
+			model.Name = name
+			model.Attributes = []string{"table:" + name}
+			return name
+		case len(parts) == 3 && strings.EqualFold(parts[1], "TO"):
+			if f := sqlField(model, sqlUnquote(parts[0])); f != nil {
+				f.Name = sqlUnquote(parts[2])
+			}
+			for i := range model.Relations {
+				if model.Relations[i].Name == sqlUnquote(parts[0]) {
+					model.Relations[i].Name = sqlUnquote(parts[2])
+					model.Relations[i].Fields = []string{sqlUnquote(parts[2])}
+				}
+			}
+		}
+	case "ALTER":
+		parts := strings.Fields(rest)
+		if len(parts) < 2 {
+			return ""
+		}
+		f := sqlField(model, sqlUnquote(parts[0]))
+		if f == nil {
+			return ""
+		}
+		change := strings.TrimSpace(rest[len(parts[0]):])
+		upper := strings.ToUpper(change)
+		switch {
+		case strings.HasPrefix(upper, "TYPE "), strings.HasPrefix(upper, "SET DATA TYPE "):
+			typ := change[strings.Index(upper, "TYPE ")+5:]
+			if i := strings.Index(strings.ToUpper(typ), " USING "); i >= 0 {
+				typ = typ[:i]
+			}
+			f.Type = strings.TrimSpace(typ)
+		case strings.HasPrefix(upper, "SET NOT NULL"):
+			f.IsOptional = false
+		case strings.HasPrefix(upper, "DROP NOT NULL"):
+			f.IsOptional = true
+		case strings.HasPrefix(upper, "SET DEFAULT "):
+			f.Default = strings.TrimSpace(change[len("SET DEFAULT "):])
+		case strings.HasPrefix(upper, "DROP DEFAULT"):
+			f.Default = ""
+		}
+	}
+	return ""
+}
+
+// parseSQLColumn reads "email varchar(255) NOT NULL UNIQUE REFERENCES ..."
+func parseSQLColumn(def string) (*SchemaField, []string) {
+	parts := strings.Fields(def)
+	if len(parts) < 2 {
+		return nil, nil
+	}
+	field := &SchemaField{Name: sqlUnquote(parts[0]), IsOptional: true}
+	rest := " " + strings.TrimSpace(def[len(parts[0]):])
+	typ := rest
+	if loc := sqlColumnKeyword.FindStringIndex(rest); loc != nil {
+		typ = rest[:loc[0]]
+	}
+	field.Type = strings.TrimSpace(typ)
+	if strings.HasSuffix(field.Type, "[]") {
+		field.IsArray = true
+	}
+
+	constraints := strings.ToUpper(rest[len(typ):])
+	if strings.Contains(constraints, "NOT NULL") {
+		field.IsOptional = false
+	}
+	if strings.Contains(constraints, "PRIMARY KEY") {
+		field.IsOptional = false
+		field.Attributes = append(field.Attributes, "PRIMARY KEY")
+	}
+	if sqlUnique.MatchString(constraints) {
+		field.Attributes = append(field.Attributes, "UNIQUE")
+	}
+	if m := sqlDefault.FindStringSubmatch(rest); m != nil {
+		field.Default = m[1]
+	}
+	if strings.Contains(constraints, "AUTO_INCREMENT") || strings.Contains(constraints, "AUTOINCREMENT") || strings.Contains(constraints, "GENERATED") {
+		field.Attributes = append(field.Attributes, "GENERATED")
+	}
+	return field, sqlReferences.FindStringSubmatch(rest)
+}
+
+// addSQLRelation records a foreign key from columns to the referenced table
+func addSQLRelation(model *SchemaModel, columns []string, ref []string) {
+	target := sqlUnquote(ref[1])
+	relation := Relation{Name: strings.Join(columns, ","), Type: "many-to-one", Model: target, Fields: columns}
+	if ref[2] != "" {
+		for _, r := range parseArrayItems(ref[2]) {
+			relation.References = append(relation.References, sqlUnquote(r))
+		}
+	}
+	// A unique foreign key allows one row per referenced row
+	if len(columns) == 1 {
+		if f := sqlField(model, columns[0]); f != nil {
+			for _, a := range f.Attributes {
+				if a == "UNIQUE" || a == "PRIMARY KEY" {
+					relation.Type = "one-to-one"
+				}
+			}
+			f.Attributes = appendAttribute(f.Attributes, "REFERENCES "+target)
+		}
+	}
+	model.Relations = append(model.Relations, relation)
+}
+
+// appendAttribute adds an attribute a field does not have yet
+func appendAttribute(attrs []string, attr string) []string {
+	for _, a := range attrs {
+		if a == attr {
+			return attrs
+		}
+	}
+	return append(attrs, attr)
+}
+
+func dropSQLColumn(model *SchemaModel, name string) {
+	for i, f := range model.Fields {
+		if strings.EqualFold(f.Name, name) {
+			model.Fields = append(model.Fields[:i], model.Fields[i+1:]...)
+			break
+		}
+	}
+	var kept []Relation
+	for _, r := range model.Relations {
+		if !strings.EqualFold(r.Name, name) {
+			kept = append(kept, r)
+		}
+	}
+	model.Relations = kept
+}
+
+func sqlField(model *SchemaModel, name string) *SchemaField {
+	for i := range model.Fields {
+		if strings.EqualFold(model.Fields[i].Name, name) {
+			return &model.Fields[i]
+		}
+	}
+	return nil
+}
+
+// sqlKey identifies a table within a directory; the public schema is the default
+func sqlKey(dir, name string) string {
+	return dir + "\x00" + strings.TrimPrefix(strings.ToLower(name), "public.")
+}
+
+// sqlUnquote strips identifier quotes: "users", `users`, [users]
+func sqlUnquote(name string) string {
+	return strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(strings.TrimSpace(name))
+}
+
+// sqlStrings returns the quoted values of a list: 'a', 'b'
+func sqlStrings(list string) []string {
+	values := []string{}
+	for _, m := range sqlQuoted.FindAllStringSubmatch(list, -1) {
+		values = append(values, strings.ReplaceAll(m[1], "''", "'"))
+	}
+	return values
+}
+
+// sqlParens returns the text inside the parenthesis text starts with
+func sqlParens(text string) (string, bool) {
+	depth := 0
+	inString := false
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\'':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return text[1:i], true
+			}
+		}
+	}
+	return strings.TrimPrefix(text, "("), false
+}
+
+// sqlSplitList splits on the commas outside parentheses and strings
+func sqlSplitList(text string) []string {
+	var items []string
+	depth, start := 0, 0
+	inString := false
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\'':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+// sqlStatement is one statement with comments removed and whitespace
+// collapsed, and the line it starts on
+type sqlStatement struct {
+	text string
+	line int
+}
+
+// splitSQL splits a script on semicolons outside strings, comments and
+// dollar-quoted function bodies
+func splitSQL(content string) []sqlStatement {
+	var statements []sqlStatement
+	var sb strings.Builder
+	line, start := 1, 0
+	flush := func() {
+		if text := strings.Join(strings.Fields(sb.String()), " "); text != "" {
+			statements = append(statements, sqlStatement{text: text, line: start})
+		}
+		sb.Reset()
+		start = 0
+	}
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c == '\n' {
+			line++
+		}
+		switch {
+		case strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				i = len(content)
+				continue
+			}
+			i += end - 1
+			continue
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			}
+			line += strings.Count(content[i:i+2+end], "\n")
+			i += end + 3
+			continue
+		case c == '$':
+			// $$ ... $$ or $tag$ ... $tag$
+			if m := sqlDollarQuote.FindString(content[i:]); m != "" {
+				end := strings.Index(content[i+len(m):], m)
+				if end >= 0 {
+					body := content[i : i+len(m)+end+len(m)]
+					line += strings.Count(body, "\n")
+					sb.WriteString(" ")
+					i += len(body) - 1
+					continue
+				}
+			}
+		case c == '\'':
+			end := i + 1
+			for end < len(content) && !(content[end] == '\'' && (end+1 == len(content) || content[end+1] != '\'')) {
+				if content[end] == '\'' {
+					end++ // '' escapes a quote
+				}
+				end++
+			}
+			if end >= len(content) {
+				end = len(content) - 1
+			}
+			if start == 0 {
+				start = line
+			}
+			line += strings.Count(content[i:end+1], "\n")
+			sb.WriteString(content[i : end+1])
+			i = end
+			continue
+		case c == ';':
+			flush()
+			continue
+		}
+		if start == 0 && c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			start = line
+		}
+		sb.WriteByte(c)
+	}
+	flush()
+	return statements
+}
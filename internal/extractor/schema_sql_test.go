@@ -0,0 +1,117 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSQLSchema(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"migrations/001_init.up.sql": `-- Initial schema
+CREATE TYPE order_status AS ENUM ('pending', 'paid');
+
+CREATE TABLE IF NOT EXISTS "users" (
+  id          SERIAL PRIMARY KEY,
+  email       VARCHAR(255) NOT NULL UNIQUE,
+  name        text,
+  created_at  timestamp with time zone DEFAULT now()
+);
+
+/* orders; one per checkout */
+CREATE TABLE orders (
+  id bigint NOT NULL,
+  user_id integer NOT NULL,
+  total numeric(10, 2) DEFAULT 0,
+  status order_status NOT NULL DEFAULT 'pending',
+  tags text[],
+  legacy_ref varchar(20),
+  PRIMARY KEY (id),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN NEW.updated_at = now(); RETURN NEW; END;
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE audit_log (id int);
+`,
+		"migrations/001_init.down.sql": "DROP TABLE orders;\nDROP TABLE users;\n",
+		"migrations/002_orders.sql": `-- +goose Up
+ALTER TABLE orders
+  ADD COLUMN shipped_at timestamptz,
+  DROP COLUMN legacy_ref,
+  ALTER COLUMN total TYPE numeric(12, 2),
+  RENAME COLUMN user_id TO customer_id;
+ALTER TABLE users RENAME TO customers;
+ALTER TYPE order_status ADD VALUE 'refunded';
+DROP TABLE IF EXISTS audit_log;
+
+-- +goose Down
+DROP TABLE orders;
+`,
+		"reports/schema.sql": "CREATE TABLE orders (id int);\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := ExtractMultiLangSchema(dir)
+	if err != nil {
+		t.Fatalf("ExtractMultiLangSchema: %v", err)
+	}
+
+	// Tables are tracked per directory; the report's orders is its own
+	if len(schema.Models) != 3 {
+		t.Fatalf("models = %+v", schema.Models)
+	}
+	customers, orders := schema.Models[0], schema.Models[1]
+	if customers.Name != "customers" || customers.Line != 4 || !reflect.DeepEqual(customers.Attributes, []string{"table:customers"}) {
+		t.Errorf("customers = %+v", customers)
+	}
+	wantCustomers := []SchemaField{
+		{Name: "id", Type: "SERIAL", Attributes: []string{"PRIMARY KEY"}},
+		{Name: "email", Type: "VARCHAR(255)", Attributes: []string{"UNIQUE"}},
+		{Name: "name", Type: "text", IsOptional: true},
+		{Name: "created_at", Type: "timestamp with time zone", IsOptional: true, Default: "now()"},
+	}
+	if !reflect.DeepEqual(customers.Fields, wantCustomers) {
+		t.Errorf("customers fields = %+v", customers.Fields)
+	}
+
+	if orders.Name != "orders" || orders.Line != 12 {
+		t.Errorf("orders = %+v", orders)
+	}
+	wantOrders := []SchemaField{
+		{Name: "id", Type: "bigint", Attributes: []string{"PRIMARY KEY"}},
+		{Name: "customer_id", Type: "integer", Attributes: []string{"REFERENCES customers"}},
+		{Name: "total", Type: "numeric(12, 2)", IsOptional: true, Default: "0"},
+		{Name: "status", Type: "order_status", Default: "'pending'"},
+		{Name: "tags", Type: "text[]", IsOptional: true, IsArray: true},
+		{Name: "shipped_at", Type: "timestamptz", IsOptional: true},
+	}
+	if !reflect.DeepEqual(orders.Fields, wantOrders) {
+		t.Errorf("orders fields = %+v", orders.Fields)
+	}
+	wantRelations := []Relation{{Name: "customer_id", Type: "many-to-one", Model: "customers", Fields: []string{"customer_id"}, References: []string{"id"}}}
+	if !reflect.DeepEqual(orders.Relations, wantRelations) {
+		t.Errorf("orders relations = %+v", orders.Relations)
+	}
+
+	if report := schema.Models[2]; report.File != filepath.Join(dir, "reports", "schema.sql") || len(report.Fields) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+
+	want := []SchemaEnum{{Name: "order_status", Values: []string{"pending", "paid", "refunded"}, File: filepath.Join(dir, "migrations", "001_init.up.sql"), Line: 2}}
+	if !reflect.DeepEqual(schema.Enums, want) {
+		t.Errorf("enums = %+v", schema.Enums)
+	}
+}
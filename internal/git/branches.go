@@ -0,0 +1,37 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// ListBranches returns the local branches and the commit each points to
+func ListBranches(repoPath string) (map[string]string, error) {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname:short) %(objectname)", "refs/heads")
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name, tip, ok := strings.Cut(line, " "); ok {
+			branches[name] = tip
+		}
+	}
+	return branches, nil
+}
+
+// DefaultBranch returns the branch features are merged into: origin's
+// HEAD, else main or master
+func DefaultBranch(repoPath string) string {
+	return detectDefaultBranch(repoPath)
+}
+
+// IsAncestor reports whether commit is reachable from ref, e.g. whether a
+// branch tip was merged into the default branch
+func IsAncestor(repoPath, commit, ref string) bool {
+	return isAncestor(repoPath, commit, ref)
+}
@@ -0,0 +1,146 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CodeownersSource marks expert entries taken from a CODEOWNERS file
+// rather than computed from commits
+const CodeownersSource = "CODEOWNERS"
+
+// codeownersLocations are where GitHub and GitLab look for CODEOWNERS, in order
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// CodeOwners maps a directory to the owners CODEOWNERS assigns it
+type CodeOwners struct {
+	Directory string   `json:"directory"`
+	Owners    []string `json:"owners"`
+	Line      int      `json:"line"`
+}
+
+// FindCodeowners returns the project-relative path of the repo's CODEOWNERS
+// file, or "" when it has none
+func FindCodeowners(repoPath string) string {
+	for _, rel := range codeownersLocations {
+		if info, err := os.Stat(filepath.Join(repoPath, rel)); err == nil && !info.IsDir() {
+			return rel
+		}
+	}
+	return ""
+}
+
+// ReadCodeowners parses the repo's CODEOWNERS file into directory owners.
+// File patterns count for their directory and wildcards for the directory
+// above them; a later rule for the same directory replaces an earlier one,
+// as in CODEOWNERS itself. GitLab [Section] headers are skipped.
+func ReadCodeowners(repoPath string) []CodeOwners {
+	rel := FindCodeowners(repoPath)
+	if rel == "" {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(repoPath, rel))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	byDir := make(map[string]CodeOwners)
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		dir := codeownersDir(fields[0])
+		byDir[dir] = CodeOwners{Directory: dir, Owners: fields[1:], Line: lineNum}
+	}
+
+	owners := make([]CodeOwners, 0, len(byDir))
+	for _, o := range byDir {
+		owners = append(owners, o)
+	}
+	sort.Slice(owners, func(i, j int) bool { return owners[i].Line < owners[j].Line })
+	return owners
+}
+
+// codeownersDir turns a CODEOWNERS pattern into the directory it covers, in
+// the form experts use ("." for the root): /src/auth/ is src/auth,
+// docs/*.md is docs, *.js is .
+func codeownersDir(pattern string) string {
+	isDir := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var parts []string
+	for _, part := range strings.Split(pattern, "/") {
+		if strings.ContainsAny(part, "*?[") {
+			isDir = true
+			break
+		}
+		parts = append(parts, part)
+	}
+	dir := strings.Join(parts, "/")
+	// A last segment with an extension names a file
+	if !isDir && strings.Contains(path.Base(dir), ".") {
+		dir = path.Dir(dir)
+	}
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// MergeCodeowners adds the owners CODEOWNERS assigns to each directory to
+// its experts, after the ones found in history, marked with Source
+// CODEOWNERS. Owners already among a directory's experts (by name or
+// email) are not repeated, and entries from an earlier merge are replaced,
+// so merging again is safe.
+func MergeCodeowners(experts []DirectoryExpert, owners []CodeOwners) []DirectoryExpert {
+	index := make(map[string]int, len(experts))
+	for i := range experts {
+		var kept []ExpertEntry
+		for _, e := range experts[i].TopExperts {
+			if e.Source != CodeownersSource {
+				kept = append(kept, e)
+			}
+		}
+		experts[i].TopExperts = kept
+		index[experts[i].Directory] = i
+	}
+
+	for _, o := range owners {
+		i, ok := index[o.Directory]
+		if !ok {
+			experts = append(experts, DirectoryExpert{Directory: o.Directory})
+			i = len(experts) - 1
+			index[o.Directory] = i
+		}
+		for _, owner := range o.Owners {
+			entry := ExpertEntry{Name: owner, Active: true, Source: CodeownersSource}
+			if !strings.HasPrefix(owner, "@") {
+				entry.Email = owner
+			}
+			known := false
+			for _, e := range experts[i].TopExperts {
+				if strings.EqualFold(e.Name, strings.TrimPrefix(owner, "@")) || (entry.Email != "" && strings.EqualFold(e.Email, entry.Email)) || e.Name == owner {
+					known = true
+					break
+				}
+			}
+			if !known {
+				experts[i].TopExperts = append(experts[i].TopExperts, entry)
+			}
+		}
+	}
+	return experts
+}
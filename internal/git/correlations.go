@@ -0,0 +1,242 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Correlation mining defaults, used for zero values in CorrelationConfig
+const (
+	defaultMinCoChanges    = 9
+	defaultMinCorrelation  = 0.5
+	defaultMaxCorrelations = 50
+)
+
+// Confidence labels stay absolute so lowered thresholds show up as "low"
+const (
+	mediumConfidenceCoChanges = 9
+	highConfidenceCoChanges   = 16
+)
+
+// correlationDefaults fills unset thresholds with the defaults
+func correlationDefaults(cfg types.CorrelationConfig) types.CorrelationConfig {
+	if cfg.MinCoChanges <= 0 {
+		cfg.MinCoChanges = defaultMinCoChanges
+	}
+	if cfg.MinCorrelation <= 0 {
+		cfg.MinCorrelation = defaultMinCorrelation
+	}
+	if cfg.MaxResults <= 0 {
+		cfg.MaxResults = defaultMaxCorrelations
+	}
+	return cfg
+}
+
+// rankCorrelations turns co-change counts into correlations that pass the
+// thresholds, strongest first. Correlation is co-changes divided by the
+// commits of the less frequently changed file of the pair.
+func rankCorrelations(coChanges, fileCommits map[string]int, cfg types.CorrelationConfig) []FileCorrelation {
+	cfg = correlationDefaults(cfg)
+
+	var correlations []FileCorrelation
+	for key, count := range coChanges {
+		if count < cfg.MinCoChanges {
+			continue
+		}
+		parts := strings.SplitN(key, "\x00", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		a, b := parts[0], parts[1]
+
+		minCommits := fileCommits[a]
+		if fileCommits[b] < minCommits {
+			minCommits = fileCommits[b]
+		}
+		if minCommits == 0 {
+			continue
+		}
+
+		corr := float64(count) / float64(minCommits)
+		if corr < cfg.MinCorrelation {
+			continue
+		}
+
+		conf := "low"
+		if count >= highConfidenceCoChanges {
+			conf = "high"
+		} else if count >= mediumConfidenceCoChanges {
+			conf = "medium"
+		}
+
+		correlations = append(correlations, FileCorrelation{
+			File1:       a,
+			File2:       b,
+			CoChanges:   count,
+			Correlation: corr,
+			Confidence:  conf,
+		})
+	}
+
+	sortCorrelations(correlations)
+	if len(correlations) > cfg.MaxResults {
+		correlations = correlations[:cfg.MaxResults]
+	}
+	return correlations
+}
+
+func sortCorrelations(correlations []FileCorrelation) {
+	sort.Slice(correlations, func(i, j int) bool {
+		if correlations[i].Correlation != correlations[j].Correlation {
+			return correlations[i].Correlation > correlations[j].Correlation
+		}
+		return correlations[i].CoChanges > correlations[j].CoChanges
+	})
+}
+
+// MineCorrelations runs a dedicated git log pass for co-change correlations,
+// limited to cfg.WindowDays and, when path is set, to files under that
+// project-relative directory. Unlike the incremental analysis it re-reads
+// history on every call, so windows and subdirectories can be tuned freely.
+func MineCorrelations(repoPath, path string, cfg types.CorrelationConfig) ([]FileCorrelation, error) {
+	args := []string{
+		"log",
+		detectDefaultBranch(repoPath),
+		"--pretty=format:%H|%h|%an|%ae|%aI|%s",
+		"--numstat",
+		"-n", fmt.Sprintf("%d", maxHistoryCommits),
+	}
+	if cfg.WindowDays > 0 {
+		args = append(args, fmt.Sprintf("--since=%d.days.ago", cfg.WindowDays))
+	}
+	if path = strings.Trim(path, "/"); path != "" && path != "." {
+		args = append(args, "--", path)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	state := newAnalysisState("")
+	for _, c := range parseProcessorLog(string(output)) {
+		state.addCommit(c)
+	}
+	return rankCorrelations(state.CoChanges, state.FileCommits, cfg), nil
+}
+
+// ForPath returns the thresholds for a subdirectory: its override from
+// cfg.Paths with unset fields inherited from cfg
+func ForPath(cfg types.CorrelationConfig, path string) types.CorrelationConfig {
+	override := cfg.Paths[strings.Trim(path, "/")]
+	cfg.Paths = nil
+	return WithOverride(cfg, override)
+}
+
+// WithOverride returns base with the fields set in override replaced
+func WithOverride(base, override types.CorrelationConfig) types.CorrelationConfig {
+	if override.MinCoChanges > 0 {
+		base.MinCoChanges = override.MinCoChanges
+	}
+	if override.MinCorrelation > 0 {
+		base.MinCorrelation = override.MinCorrelation
+	}
+	if override.WindowDays > 0 {
+		base.WindowDays = override.WindowDays
+	}
+	if override.MaxResults > 0 {
+		base.MaxResults = override.MaxResults
+	}
+	return base
+}
+
+// RegenerateCorrelations re-mines the correlations between files under path
+// (the whole project when empty) and merges them into existing.
+// Subdirectories below path with their own thresholds in cfg.Paths are
+// mined with those.
+func RegenerateCorrelations(repoPath, path string, cfg types.CorrelationConfig, existing []FileCorrelation) ([]FileCorrelation, error) {
+	path = strings.Trim(path, "/")
+	mined, err := MineCorrelations(repoPath, path, ForPath(cfg, path))
+	if err != nil {
+		return nil, err
+	}
+	return mergePathOverrides(repoPath, path, cfg, MergeCorrelations(existing, mined, path))
+}
+
+// mergePathOverrides re-mines each subdirectory in cfg.Paths below within
+// with its own thresholds and merges the results into correlations
+func mergePathOverrides(repoPath, within string, cfg types.CorrelationConfig, correlations []FileCorrelation) ([]FileCorrelation, error) {
+	paths := make([]string, 0, len(cfg.Paths))
+	for path := range cfg.Paths {
+		path = strings.Trim(path, "/")
+		if path != within && (within == "" || strings.HasPrefix(path, within+"/")) {
+			paths = append(paths, path)
+		}
+	}
+	// Parents first, so a nested override wins over its parent's
+	sort.Strings(paths)
+	for _, path := range paths {
+		mined, err := MineCorrelations(repoPath, path, ForPath(cfg, path))
+		if err != nil {
+			return nil, err
+		}
+		correlations = MergeCorrelations(correlations, mined, path)
+	}
+	return correlations, nil
+}
+
+// MergeCorrelations replaces the correlations between files under path with
+// mined ones and keeps the rest, strongest first
+func MergeCorrelations(existing, mined []FileCorrelation, path string) []FileCorrelation {
+	path = strings.Trim(path, "/")
+	under := func(f string) bool {
+		return path == "" || path == "." || f == path || strings.HasPrefix(f, path+"/")
+	}
+
+	merged := make([]FileCorrelation, 0, len(existing)+len(mined))
+	for _, c := range existing {
+		if !(under(c.File1) && under(c.File2)) {
+			merged = append(merged, c)
+		}
+	}
+	merged = append(merged, mined...)
+	sortCorrelations(merged)
+	return merged
+}
+
+// correlationsFile is the report file holding correlations in the knowledge dir
+const correlationsFile = "git-correlations.json"
+
+// LoadCorrelations reads the correlations written by the last analysis
+func LoadCorrelations(knowledgeDir string) ([]FileCorrelation, error) {
+	data, err := os.ReadFile(filepath.Join(knowledgeDir, correlationsFile))
+	if err != nil {
+		return nil, err
+	}
+	var correlations []FileCorrelation
+	if err := json.Unmarshal(data, &correlations); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", correlationsFile, err)
+	}
+	return correlations, nil
+}
+
+// WriteCorrelations replaces the correlations report in the knowledge dir
+func WriteCorrelations(knowledgeDir string, correlations []FileCorrelation) error {
+	if correlations == nil {
+		correlations = []FileCorrelation{}
+	}
+	content, err := json.MarshalIndent(correlations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", correlationsFile, err)
+	}
+	return os.WriteFile(filepath.Join(knowledgeDir, correlationsFile), content, 0644)
+}
@@ -0,0 +1,158 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// pairs flattens correlations to "file1+file2" entries in order
+func pairs(correlations []FileCorrelation) []string {
+	var out []string
+	for _, c := range correlations {
+		out = append(out, c.File1+"+"+c.File2)
+	}
+	return out
+}
+
+func TestRankCorrelationsThresholds(t *testing.T) {
+	coChanges := map[string]int{
+		"a.go\x00b.go": 20, // 20 of 20 commits: high
+		"a.go\x00c.go": 10, // 10 of 12 commits: medium
+		"d.go\x00e.go": 4,  // 4 of 4 commits: low
+		"a.go\x00f.go": 9,  // 9 of 30 commits: weak
+	}
+	fileCommits := map[string]int{"a.go": 40, "b.go": 20, "c.go": 12, "d.go": 4, "e.go": 6, "f.go": 30}
+
+	tests := []struct {
+		name string
+		cfg  types.CorrelationConfig
+		want []string
+	}{
+		{"defaults", types.CorrelationConfig{}, []string{"a.go+b.go", "a.go+c.go"}},
+		{"lower co-changes", types.CorrelationConfig{MinCoChanges: 3}, []string{"a.go+b.go", "d.go+e.go", "a.go+c.go"}},
+		{"lower correlation", types.CorrelationConfig{MinCorrelation: 0.2}, []string{"a.go+b.go", "a.go+c.go", "a.go+f.go"}},
+		{"higher correlation", types.CorrelationConfig{MinCorrelation: 0.9}, []string{"a.go+b.go"}},
+		{"max results", types.CorrelationConfig{MinCoChanges: 3, MaxResults: 2}, []string{"a.go+b.go", "d.go+e.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pairs(rankCorrelations(coChanges, fileCommits, tt.cfg)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("correlations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	confidence := make(map[string]string)
+	for _, c := range rankCorrelations(coChanges, fileCommits, types.CorrelationConfig{MinCoChanges: 1, MinCorrelation: 0.1}) {
+		confidence[c.File1+"+"+c.File2] = c.Confidence
+	}
+	want := map[string]string{"a.go+b.go": "high", "a.go+c.go": "medium", "a.go+f.go": "medium", "d.go+e.go": "low"}
+	if !reflect.DeepEqual(confidence, want) {
+		t.Errorf("confidence = %v, want %v (labels do not move with thresholds)", confidence, want)
+	}
+}
+
+func TestForPath(t *testing.T) {
+	cfg := types.CorrelationConfig{
+		MinCoChanges: 5,
+		WindowDays:   90,
+		Paths: map[string]types.CorrelationConfig{
+			"services/billing": {MinCoChanges: 3, MaxResults: 10},
+		},
+	}
+	got := ForPath(cfg, "/services/billing/")
+	want := types.CorrelationConfig{MinCoChanges: 3, WindowDays: 90, MaxResults: 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForPath(billing) = %+v, want %+v", got, want)
+	}
+	if got := ForPath(cfg, "services/orders"); got.MinCoChanges != 5 || got.Paths != nil {
+		t.Errorf("ForPath(orders) = %+v, want the project thresholds", got)
+	}
+}
+
+func TestMergeCorrelations(t *testing.T) {
+	existing := []FileCorrelation{
+		{File1: "svc/a.go", File2: "svc/b.go", Correlation: 0.9},
+		{File1: "svc/a.go", File2: "lib/x.go", Correlation: 0.8},
+		{File1: "lib/x.go", File2: "lib/y.go", Correlation: 0.7},
+	}
+	mined := []FileCorrelation{{File1: "svc/a.go", File2: "svc/c.go", Correlation: 0.75}}
+
+	got := pairs(MergeCorrelations(existing, mined, "svc/"))
+	want := []string{"svc/a.go+lib/x.go", "svc/a.go+svc/c.go", "lib/x.go+lib/y.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged = %v, want %v (pairs crossing the path are kept)", got, want)
+	}
+	if got := MergeCorrelations(existing, mined, ""); len(got) != 1 {
+		t.Errorf("merging the whole project kept %v", pairs(got))
+	}
+}
+
+func TestRegenerateCorrelations(t *testing.T) {
+	r := newFixtureRepo(t)
+	for i := 0; i < 3; i++ {
+		r.commit("ada", "svc/a.go", "svc/b.go")
+	}
+	for i := 0; i < 2; i++ {
+		r.commit("ada", "lib/x.go", "lib/y.go")
+	}
+	for i := 0; i < 2; i++ {
+		r.commit("ada", "svc/a.go", "svc/b.go")
+	}
+	// Commit n is dated 2026-01-01 12:00 plus n days; a window of this many
+	// days starts between commits 3 and 4
+	fourth := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC).AddDate(0, 0, 3)
+	window := int(time.Since(fourth).Hours() / 24)
+
+	tests := []struct {
+		name string
+		path string
+		cfg  types.CorrelationConfig
+		want []string
+	}{
+		{"whole project", "", types.CorrelationConfig{MinCoChanges: 2}, []string{"svc/a.go+svc/b.go", "lib/x.go+lib/y.go"}},
+		{"path override", "", types.CorrelationConfig{MinCoChanges: 2, Paths: map[string]types.CorrelationConfig{"lib": {MinCoChanges: 3}}}, []string{"svc/a.go+svc/b.go"}},
+		{"window", "", types.CorrelationConfig{MinCoChanges: 3, WindowDays: window}, nil},
+		{"window keeps recent pairs", "", types.CorrelationConfig{MinCoChanges: 2, WindowDays: window}, []string{"svc/a.go+svc/b.go", "lib/x.go+lib/y.go"}},
+		{"subdirectory", "svc", types.CorrelationConfig{MinCoChanges: 2}, []string{"svc/a.go+svc/b.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RegenerateCorrelations(r.dir, tt.path, tt.cfg, nil)
+			if err != nil {
+				t.Fatalf("RegenerateCorrelations: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("correlations = %v, want %v", pairs(got), tt.want)
+			}
+			for _, want := range tt.want {
+				if !containsPair(got, want) {
+					t.Errorf("correlations = %v, missing %s", pairs(got), want)
+				}
+			}
+		})
+	}
+
+	// Regenerating a subdirectory keeps what was mined elsewhere
+	existing := []FileCorrelation{{File1: "lib/x.go", File2: "lib/y.go", CoChanges: 2, Correlation: 1}}
+	got, err := RegenerateCorrelations(r.dir, "svc", types.CorrelationConfig{MinCoChanges: 50}, existing)
+	if err != nil {
+		t.Fatalf("RegenerateCorrelations: %v", err)
+	}
+	if !reflect.DeepEqual(got, existing) {
+		t.Errorf("correlations = %+v, want only the existing lib pair", got)
+	}
+}
+
+// containsPair reports whether correlations hold a pair in either order
+func containsPair(correlations []FileCorrelation, pair string) bool {
+	for _, c := range correlations {
+		if c.File1+"+"+c.File2 == pair || c.File2+"+"+c.File1 == pair {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"bytes"
+	"errors"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -184,6 +185,100 @@ func GetBranch(repoPath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetUserIdentity returns the configured user.name and user.email, either
+// of which may be empty when git isn't set up
+func GetUserIdentity(repoPath string) (name, email string) {
+	get := func(key string) string {
+		cmd := exec.Command("git", "config", "--get", key)
+		cmd.Dir = repoPath
+		output, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	return get("user.name"), get("user.email")
+}
+
+// ErrUnknownCommit is returned when a recorded commit no longer exists,
+// e.g. after a force push followed by garbage collection
+var ErrUnknownCommit = errors.New("commit not found in repository")
+
+// ErrUnrelatedHistory is returned when two commits share no history, e.g.
+// after a branch was replaced wholesale, so a diff between them says nothing
+// about what changed
+var ErrUnrelatedHistory = errors.New("commits have no common ancestor")
+
+// GetHeadHash returns the full hash of HEAD
+func GetHeadHash(repoPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetChangedFilesBetween returns the files whose content differs between two
+// commits. rewritten reports that from is not an ancestor of to, i.e. history
+// was rebased, reset or force-pushed in between. The diff is taken between
+// the two trees rather than along the log, so changes dropped by the rewrite
+// are included as well as new ones. Renames are listed as a delete plus an add.
+// It returns ErrUnknownCommit when from is gone and ErrUnrelatedHistory when
+// the commits have no merge base.
+func GetChangedFilesBetween(repoPath, from, to string) (files []string, rewritten bool, err error) {
+	check := exec.Command("git", "cat-file", "-e", from+"^{commit}")
+	check.Dir = repoPath
+	if check.Run() != nil {
+		return nil, true, ErrUnknownCommit
+	}
+
+	base := exec.Command("git", "merge-base", from, to)
+	base.Dir = repoPath
+	out, err := base.Output()
+	if err != nil {
+		return nil, true, ErrUnrelatedHistory
+	}
+	rewritten = strings.TrimSpace(string(out)) != from
+
+	cmd := exec.Command("git", "diff", "--name-only", "--no-renames", from, to)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, rewritten, err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, rewritten, nil
+}
+
+// GetTrackedFiles returns the paths git tracks in the working tree, relative
+// to the repository root
+func GetTrackedFiles(repoPath string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "-z")
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
 // GetFileHistory returns the history of a specific file
 func GetFileHistory(repoPath, filePath string, limit int) ([]types.GitChange, error) {
 	if limit <= 0 {
@@ -351,3 +446,44 @@ func estimateImpact(change types.GitChange) string {
 	}
 	return "low"
 }
+
+// GetFileAtRef returns a file's content at a commit, branch or tag. ok is
+// false when the file doesn't exist there. The path is relative to
+// repoPath, which may be a directory inside the repository.
+func GetFileAtRef(repoPath, ref, filePath string) (content []byte, ok bool, err error) {
+	check := exec.Command("git", "cat-file", "-e", ref+"^{commit}")
+	check.Dir = repoPath
+	if check.Run() != nil {
+		return nil, false, ErrUnknownCommit
+	}
+	cmd := exec.Command("git", "show", ref+":./"+filePath)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, false, nil
+	}
+	return output, true, nil
+}
+
+// GetChangedFiles returns the files under repoPath that differ between a
+// commit and another, or the working tree when to is empty, relative to
+// repoPath
+func GetChangedFiles(repoPath, from, to string) ([]string, error) {
+	args := []string{"diff", "--name-only", "--no-renames", "--relative", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, ErrUnknownCommit
+	}
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
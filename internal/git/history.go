@@ -2,7 +2,7 @@ package git
 
 import (
 	"os/exec"
-	"path/filepath"
+	"path"
 	"regexp"
 	"sort"
 	"strconv"
@@ -72,6 +72,17 @@ type KnowledgeRisk struct {
 	ExpertIsActive bool     `json:"expert_is_active"`
 	LastActivity   string   `json:"last_activity"`
 	Mitigation     string   `json:"mitigation"`
+
+	// Suggested follow-up when the primary expert is no longer active
+	TransferPlan *TransferPlan `json:"transfer_plan,omitempty"`
+}
+
+// TransferPlan turns a knowledge risk into actionable knowledge transfer
+type TransferPlan struct {
+	Successors      []ExpertEntry `json:"successors"`        // closest active contributors
+	FilesToDocument []string      `json:"files_to_document"` // key files, most important first
+	Steps           []string      `json:"steps"`
+	Onboarding      string        `json:"onboarding"` // generated onboarding notes for the area
 }
 
 // FileCorrelation represents files that usually change together
@@ -322,7 +333,7 @@ func (h *HistoryAnalyzer) AnalyzeKnowledgeRisk() ([]KnowledgeRisk, error) {
 	// Group by directory
 	dirFiles := make(map[string][]string)
 	for _, file := range files {
-		dir := filepath.Dir(file)
+		dir := path.Dir(file)
 		dirFiles[dir] = append(dirFiles[dir], file)
 	}
 
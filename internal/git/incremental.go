@@ -0,0 +1,305 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// analysisStateVersion is bumped whenever the aggregate layout changes;
+// states with another version are discarded and rebuilt from full history.
+const analysisStateVersion = 1
+
+// maxHistoryCommits caps the analyzed history to the most recent commits to
+// avoid OOM on very large repos. Full and incremental passes cover the same
+// window, so both produce the same reports.
+var maxHistoryCommits = 2000
+
+// maxCorrelatedFiles skips huge commits (merges, bulk changes) when counting
+// co-changes, since they would correlate unrelated files.
+const maxCorrelatedFiles = 30
+
+// AnalysisState holds the raw aggregates behind a GitHistoryReport together
+// with the last analyzed commit, so later runs only process new commits and
+// merge them in instead of re-reading the whole history.
+type AnalysisState struct {
+	Version         int                                `json:"version"`
+	Branch          string                             `json:"branch"`
+	LastCommit      string                             `json:"last_commit"`
+	UpdatedAt       time.Time                          `json:"updated_at"`
+	CommitCount     int                                `json:"commit_count"`
+	FirstCommitDate time.Time                          `json:"first_commit_date"`
+	LastCommitDate  time.Time                          `json:"last_commit_date"`
+	Contributors    map[string]*contributorTotals      `json:"contributors"`
+	FileChanges     map[string]int                     `json:"file_changes"`
+	DirExperts      map[string]map[string]*ExpertEntry `json:"dir_experts"`  // dir -> email -> expert
+	FileCommits     map[string]int                     `json:"file_commits"` // commits per file, excluding huge commits
+	CoChanges       map[string]int                     `json:"co_changes"`   // "fileA\x00fileB" -> count
+}
+
+// contributorTotals is the cumulative activity of one author
+type contributorTotals struct {
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	Commits    int       `json:"commits"`
+	Added      int       `json:"lines_added"`
+	Removed    int       `json:"lines_removed"`
+	LastCommit time.Time `json:"last_commit"`
+}
+
+func newAnalysisState(branch string) *AnalysisState {
+	return &AnalysisState{
+		Version:      analysisStateVersion,
+		Branch:       branch,
+		Contributors: make(map[string]*contributorTotals),
+		FileChanges:  make(map[string]int),
+		DirExperts:   make(map[string]map[string]*ExpertEntry),
+		FileCommits:  make(map[string]int),
+		CoChanges:    make(map[string]int),
+	}
+}
+
+// ProcessGitHistoryIncremental computes the git reports using the aggregates
+// persisted at statePath. Only commits after the last analyzed one are read
+// from git. The state is rebuilt from full history when it is missing, from
+// another format version, when history was rewritten (the last analyzed
+// commit is no longer an ancestor of the branch), or when new commits push
+// the oldest out of the maxHistoryCommits window. Correlations use the
+// thresholds in correlationCfg; with a time window they are mined from a
+// separate pass, as the aggregates carry no dates, and subdirectories with
+// their own thresholds are mined separately and merged in. Owners from
+// CODEOWNERS are added to the experts.
+func ProcessGitHistoryIncremental(repoPath, statePath string, correlationCfg types.CorrelationConfig) (*GitHistoryReport, error) {
+	state, err := analyzeHistory(repoPath, LoadAnalysisState(statePath))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := SaveAnalysisState(statePath, state); err != nil {
+		return nil, err
+	}
+
+	report := state.buildReport(currentBranch(repoPath), correlationCfg)
+	report.Experts = MergeCodeowners(report.Experts, ReadCodeowners(repoPath))
+	if correlationCfg.WindowDays > 0 && state.CommitCount > 0 {
+		correlations, err := MineCorrelations(repoPath, "", correlationCfg)
+		if err != nil {
+			return nil, err
+		}
+		report.Correlations = correlations
+	}
+
+	if report.Correlations, err = mergePathOverrides(repoPath, "", correlationCfg, report.Correlations); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// LoadAnalysisState reads a persisted analysis state. It returns nil when the
+// file is missing, unreadable, or from another format version.
+func LoadAnalysisState(statePath string) *AnalysisState {
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		return nil
+	}
+	var state AnalysisState
+	if err := json.Unmarshal(data, &state); err != nil || state.Version != analysisStateVersion {
+		return nil
+	}
+	if state.Contributors == nil || state.FileChanges == nil || state.DirExperts == nil ||
+		state.FileCommits == nil || state.CoChanges == nil {
+		return nil
+	}
+	return &state
+}
+
+// SaveAnalysisState persists the aggregates for the next incremental run
+func SaveAnalysisState(statePath string, state *AnalysisState) error {
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("creating state dir: %w", err)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshaling git analysis state: %w", err)
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return fmt.Errorf("writing git analysis state: %w", err)
+	}
+	return nil
+}
+
+// analyzeHistory folds commits into prev, or into a fresh state when prev is
+// nil or can no longer be extended.
+func analyzeHistory(repoPath string, prev *AnalysisState) (*AnalysisState, error) {
+	// Detect default branch: try main, then master, then HEAD
+	defaultBranch := detectDefaultBranch(repoPath)
+
+	state := prev
+	if state != nil && (state.Branch != defaultBranch || !isAncestor(repoPath, state.LastCommit, defaultBranch)) {
+		state = nil
+	}
+
+	if state != nil {
+		// New commits are folded in only while the state still holds the whole
+		// window. Past the cap the oldest commits would have to be taken out,
+		// which the aggregates can't do, so the window is read again in full.
+		room := maxHistoryCommits - state.CommitCount
+		if room > 0 {
+			cmd := exec.Command("git", "log",
+				state.LastCommit+".."+defaultBranch,
+				"--pretty=format:%H|%h|%an|%ae|%aI|%s",
+				"--numstat",
+				"-n", fmt.Sprintf("%d", room+1),
+			)
+			cmd.Dir = repoPath
+			if output, err := cmd.Output(); err == nil {
+				if commits := parseProcessorLog(string(output)); len(commits) <= room {
+					return state.addCommits(commits), nil
+				}
+			}
+		}
+	}
+
+	// Full history on default branch only — gives accurate ownership without
+	// feature-branch noise. Capped for safety.
+	state = newAnalysisState(defaultBranch)
+	args := []string{
+		"log",
+		defaultBranch,
+		"--pretty=format:%H|%h|%an|%ae|%aI|%s",
+		"--numstat",
+		"-n", fmt.Sprintf("%d", maxHistoryCommits),
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		// Fallback: if the branch doesn't exist, try --all with the old approach.
+		// There is no single tip to resume from, so the state is not incremental.
+		state = newAnalysisState("")
+		args = []string{
+			"log",
+			"--all",
+			"--pretty=format:%H|%h|%an|%ae|%aI|%s",
+			"--numstat",
+			"-n", fmt.Sprintf("%d", maxHistoryCommits),
+		}
+		cmd = exec.Command("git", args...)
+		cmd.Dir = repoPath
+		output, err = cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git log failed: %w", err)
+		}
+	}
+
+	return state.addCommits(parseProcessorLog(string(output))), nil
+}
+
+// addCommits merges commits, listed newest first as git log prints them,
+// and moves the state to the newest
+func (st *AnalysisState) addCommits(commits []processedCommit) *AnalysisState {
+	for _, c := range commits {
+		st.addCommit(c)
+	}
+	if len(commits) > 0 && st.Branch != "" {
+		st.LastCommit = commits[0].Hash
+	}
+	st.UpdatedAt = time.Now()
+	return st
+}
+
+// addCommit merges a single commit into the aggregates
+func (st *AnalysisState) addCommit(c processedCommit) {
+	st.CommitCount++
+	if st.FirstCommitDate.IsZero() || c.Date.Before(st.FirstCommitDate) {
+		st.FirstCommitDate = c.Date
+	}
+	if c.Date.After(st.LastCommitDate) {
+		st.LastCommitDate = c.Date
+	}
+
+	// Contributor stats
+	ct, exists := st.Contributors[c.AuthorEmail]
+	if !exists {
+		ct = &contributorTotals{
+			Name:  c.Author,
+			Email: c.AuthorEmail,
+		}
+		st.Contributors[c.AuthorEmail] = ct
+	}
+	ct.Commits++
+	ct.Added += c.Insertions
+	ct.Removed += c.Deletions
+	if c.Date.After(ct.LastCommit) {
+		ct.LastCommit = c.Date
+	}
+
+	// File and directory stats
+	for _, f := range c.FilesChanged {
+		st.FileChanges[f]++
+
+		dir := path.Dir(f)
+		if st.DirExperts[dir] == nil {
+			st.DirExperts[dir] = make(map[string]*ExpertEntry)
+		}
+		entry, exists := st.DirExperts[dir][c.AuthorEmail]
+		if !exists {
+			entry = &ExpertEntry{
+				Name:  c.Author,
+				Email: c.AuthorEmail,
+			}
+			st.DirExperts[dir][c.AuthorEmail] = entry
+		}
+		entry.Commits++
+		if c.Date.After(entry.LastCommit) {
+			entry.LastCommit = c.Date
+		}
+	}
+
+	// Co-changes: every pair of files in the commit
+	files := c.FilesChanged
+	if len(files) > maxCorrelatedFiles {
+		return
+	}
+	for _, f := range files {
+		st.FileCommits[f]++
+	}
+	for i := 0; i < len(files); i++ {
+		for j := i + 1; j < len(files); j++ {
+			a, b := files[i], files[j]
+			if a > b {
+				a, b = b, a
+			}
+			st.CoChanges[a+"\x00"+b]++
+		}
+	}
+}
+
+// isAncestor reports whether commit is reachable from ref
+func isAncestor(repoPath, commit, ref string) bool {
+	if commit == "" {
+		return false
+	}
+	cmd := exec.Command("git", "merge-base", "--is-ancestor", commit, ref)
+	cmd.Dir = repoPath
+	return cmd.Run() == nil
+}
+
+// currentBranch returns the checked-out branch name, or "" when detached
+func currentBranch(repoPath string) string {
+	cmd := exec.Command("git", "branch", "--show-current")
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,144 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fixtureRepo is a git repo on branch main whose commits are made by commit
+type fixtureRepo struct {
+	t   *testing.T
+	dir string
+	n   int
+}
+
+func newFixtureRepo(t *testing.T) *fixtureRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &fixtureRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	return r
+}
+
+func (r *fixtureRepo) git(args ...string) {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// commit writes files as author and commits them, a day after the last commit
+func (r *fixtureRepo) commit(author string, files ...string) {
+	r.t.Helper()
+	r.n++
+	for _, f := range files {
+		path := filepath.Join(r.dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("change %d\n", r.n)), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.git("add", "-A")
+	date := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC).AddDate(0, 0, r.n).Format(time.RFC3339)
+	cmd := exec.Command("git", "-c", "user.name="+author, "-c", "user.email="+author+"@example.com",
+		"commit", "-q", "-m", fmt.Sprintf("commit %d", r.n))
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		r.t.Fatalf("commit: %v\n%s", err, out)
+	}
+}
+
+func analyze(t *testing.T, dir string, prev *AnalysisState) *AnalysisState {
+	t.Helper()
+	state, err := analyzeHistory(dir, prev)
+	if err != nil {
+		t.Fatalf("analyzeHistory: %v", err)
+	}
+	return state
+}
+
+// assertSameState compares everything but the analysis time
+func assertSameState(t *testing.T, incremental, full *AnalysisState) {
+	t.Helper()
+	a, b := *incremental, *full
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("incremental state differs from a full pass:\nincremental: %+v\nfull:        %+v", a, b)
+	}
+}
+
+func TestIncrementalMatchesFullHistory(t *testing.T) {
+	r := newFixtureRepo(t)
+	r.commit("ana", "api/users.go", "api/users_test.go")
+	r.commit("ben", "web/app.ts")
+	r.commit("ana", "api/users.go", "api/orders.go")
+
+	state := analyze(t, r.dir, nil)
+	if state.CommitCount != 3 {
+		t.Fatalf("CommitCount = %d, want 3", state.CommitCount)
+	}
+
+	r.commit("ben", "api/users.go", "api/orders.go")
+	r.commit("cy", "web/app.ts", "web/app.css")
+	incremental := analyze(t, r.dir, state)
+	assertSameState(t, incremental, analyze(t, r.dir, nil))
+
+	if got := incremental.CoChanges["api/orders.go\x00api/users.go"]; got != 2 {
+		t.Errorf("orders/users co-changes = %d, want 2", got)
+	}
+}
+
+func TestIncrementalKeepsTheHistoryWindow(t *testing.T) {
+	defer func(n int) { maxHistoryCommits = n }(maxHistoryCommits)
+	maxHistoryCommits = 4
+
+	r := newFixtureRepo(t)
+	r.commit("ana", "a.go")
+	r.commit("ben", "b.go")
+	r.commit("ana", "a.go", "b.go")
+	state := analyze(t, r.dir, nil)
+
+	// One more fits the window and is folded in
+	r.commit("cy", "c.go")
+	state = analyze(t, r.dir, state)
+	assertSameState(t, state, analyze(t, r.dir, nil))
+
+	// Two more push the oldest commits out of it
+	r.commit("ben", "b.go")
+	r.commit("cy", "c.go", "a.go")
+	state = analyze(t, r.dir, state)
+	full := analyze(t, r.dir, nil)
+	assertSameState(t, state, full)
+	if full.CommitCount != 4 || full.Contributors["ana@example.com"].Commits != 1 {
+		t.Errorf("window not applied: %d commits, ana %+v", full.CommitCount, full.Contributors["ana@example.com"])
+	}
+}
+
+func TestIncrementalRebuildsRewrittenHistory(t *testing.T) {
+	r := newFixtureRepo(t)
+	r.commit("ana", "a.go")
+	r.commit("ben", "b.go")
+	state := analyze(t, r.dir, nil)
+
+	r.git("reset", "-q", "--hard", "HEAD~1")
+	r.commit("cy", "c.go")
+	state = analyze(t, r.dir, state)
+	assertSameState(t, state, analyze(t, r.dir, nil))
+	if _, ok := state.Contributors["ben@example.com"]; ok {
+		t.Error("commit dropped by the rewrite is still counted")
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
 )
 
 // GitHistoryReport contains all computed git history analysis
@@ -70,119 +73,56 @@ type ExpertEntry struct {
 	Active       bool      `json:"active"`
 	ActiveInRepo string    `json:"active_in_repo,omitempty"` // which linked repo they're active in
 	LastCommit   time.Time `json:"last_commit,omitempty"`
+	Source       string    `json:"source,omitempty"` // CODEOWNERS when listed there rather than found in history
 }
 
 // ProcessGitHistory runs a single git log pass and computes all reports.
 // Reads all commits on the main branch (main or master) for full ownership
 // history, capped at 2000 to avoid OOM on very large repos.
 func ProcessGitHistory(repoPath string) (*GitHistoryReport, error) {
-	// Detect default branch: try main, then master, then HEAD
-	defaultBranch := detectDefaultBranch(repoPath)
-
-	// Full history on default branch only — gives accurate ownership without
-	// feature-branch noise. Capped at 2000 commits for safety.
-	args := []string{
-		"log",
-		defaultBranch,
-		"--pretty=format:%H|%h|%an|%ae|%aI|%s",
-		"--numstat",
-		"-n", "2000",
-	}
-
-	cmd := exec.Command("git", args...)
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	state, err := analyzeHistory(repoPath, nil)
 	if err != nil {
-		// Fallback: if the branch doesn't exist, try --all with the old approach
-		args = []string{
-			"log",
-			"--all",
-			"--pretty=format:%H|%h|%an|%ae|%aI|%s",
-			"--numstat",
-			"-n", "2000",
-		}
-		cmd = exec.Command("git", args...)
-		cmd.Dir = repoPath
-		output, err = cmd.Output()
-		if err != nil {
-			return nil, fmt.Errorf("git log failed: %w", err)
-		}
+		return nil, err
 	}
+	return state.buildReport(currentBranch(repoPath), types.CorrelationConfig{}), nil
+}
 
-	// Parse all commits
-	commits := parseProcessorLog(string(output))
-	if len(commits) == 0 {
+// buildReport derives the summary, experts, risks and correlations from the
+// accumulated aggregates. Activity flags are recomputed against the current
+// time so a cached state never reports stale "active" contributors.
+func (st *AnalysisState) buildReport(branch string, correlationCfg types.CorrelationConfig) *GitHistoryReport {
+	if st.CommitCount == 0 {
 		return &GitHistoryReport{
 			ProcessedAt: time.Now(),
-		}, nil
-	}
-
-	// Get current branch
-	branch := ""
-	branchCmd := exec.Command("git", "branch", "--show-current")
-	branchCmd.Dir = repoPath
-	if branchOut, err := branchCmd.Output(); err == nil {
-		branch = strings.TrimSpace(string(branchOut))
+		}
 	}
 
-	// Build in-memory structures from the single pass
-	contributorMap := make(map[string]*ContributorInfo)
-	fileChangeCount := make(map[string]int)
-	dirContributors := make(map[string]map[string]*ExpertEntry) // dir -> email -> expert
-	commitFiles := make([][]string, 0, len(commits))           // for correlation
-
 	threeMonthsAgo := time.Now().AddDate(0, -3, 0)
 
-	for _, c := range commits {
-		// Contributor stats
-		ci, exists := contributorMap[c.AuthorEmail]
-		if !exists {
-			ci = &ContributorInfo{
-				Name:  c.Author,
-				Email: c.AuthorEmail,
-			}
-			contributorMap[c.AuthorEmail] = ci
-		}
-		ci.Commits++
-		ci.Added += c.Insertions
-		ci.Removed += c.Deletions
-		if c.Date.After(threeMonthsAgo) {
-			ci.Active = true
+	contributorMap := make(map[string]*ContributorInfo, len(st.Contributors))
+	for email, ct := range st.Contributors {
+		contributorMap[email] = &ContributorInfo{
+			Name:    ct.Name,
+			Email:   ct.Email,
+			Commits: ct.Commits,
+			Added:   ct.Added,
+			Removed: ct.Removed,
+			Active:  ct.LastCommit.After(threeMonthsAgo),
 		}
-
-		// File and directory stats
-		for _, f := range c.FilesChanged {
-			fileChangeCount[f]++
-
-			dir := filepath.Dir(f)
-			if dirContributors[dir] == nil {
-				dirContributors[dir] = make(map[string]*ExpertEntry)
-			}
-			entry, exists := dirContributors[dir][c.AuthorEmail]
-			if !exists {
-				entry = &ExpertEntry{
-					Name:  c.Author,
-					Email: c.AuthorEmail,
-				}
-				dirContributors[dir][c.AuthorEmail] = entry
-			}
-			entry.Commits++
-			if c.Date.After(entry.LastCommit) {
-				entry.LastCommit = c.Date
-			}
-			if c.Date.After(threeMonthsAgo) {
-				entry.Active = true
-			}
+	}
+	fileChangeCount := st.FileChanges
+	dirContributors := st.DirExperts
+	for _, contributors := range dirContributors {
+		for _, entry := range contributors {
+			entry.Active = entry.LastCommit.After(threeMonthsAgo)
 		}
-
-		commitFiles = append(commitFiles, c.FilesChanged)
 	}
 
 	// === Build Summary ===
 	summary := GitSummary{
-		TotalCommits:    len(commits),
-		FirstCommitDate: commits[len(commits)-1].Date.Format(time.RFC3339),
-		LastCommitDate:  commits[0].Date.Format(time.RFC3339),
+		TotalCommits:    st.CommitCount,
+		FirstCommitDate: st.FirstCommitDate.Format(time.RFC3339),
+		LastCommitDate:  st.LastCommitDate.Format(time.RFC3339),
 		ActiveBranch:    branch,
 	}
 
@@ -231,7 +171,7 @@ func ProcessGitHistory(repoPath string) (*GitHistoryReport, error) {
 
 		// Count unique files in this dir
 		for f := range fileChangeCount {
-			if filepath.Dir(f) == dir {
+			if path.Dir(f) == dir {
 				de.FileCount++
 			}
 		}
@@ -263,7 +203,7 @@ func ProcessGitHistory(repoPath string) (*GitHistoryReport, error) {
 		// Skip trivial directories
 		fileCount := 0
 		for f := range fileChangeCount {
-			if filepath.Dir(f) == dir {
+			if path.Dir(f) == dir {
 				fileCount++
 			}
 		}
@@ -359,76 +299,8 @@ func ProcessGitHistory(repoPath string) (*GitHistoryReport, error) {
 	})
 
 	// === Build Correlations ===
-	// Count co-changes: for each commit, every pair of files incremented
-	coChangeCount := make(map[string]int) // "fileA\x00fileB" -> count
-	fileCommitCount := make(map[string]int)
-
-	for _, files := range commitFiles {
-		if len(files) > 30 {
-			continue // skip huge commits (merges, bulk changes)
-		}
-		for _, f := range files {
-			fileCommitCount[f]++
-		}
-		for i := 0; i < len(files); i++ {
-			for j := i + 1; j < len(files); j++ {
-				a, b := files[i], files[j]
-				if a > b {
-					a, b = b, a
-				}
-				coChangeCount[a+"\x00"+b]++
-			}
-		}
-	}
-
-	var correlations []FileCorrelation
-	for key, count := range coChangeCount {
-		if count < 5 {
-			continue
-		}
-		parts := strings.SplitN(key, "\x00", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		a, b := parts[0], parts[1]
-
-		// Correlation = coChanges / min(commits(a), commits(b))
-		minCommits := fileCommitCount[a]
-		if fileCommitCount[b] < minCommits {
-			minCommits = fileCommitCount[b]
-		}
-		if minCommits == 0 {
-			continue
-		}
-
-		corr := float64(count) / float64(minCommits)
-		if corr < 0.5 {
-			continue
-		}
-
-		conf := "medium"
-		if count > 15 {
-			conf = "high"
-		} else if count <= 8 {
-			// Below medium threshold — skip (only store medium+ confidence)
-			continue
-		}
-
-		correlations = append(correlations, FileCorrelation{
-			File1:       a,
-			File2:       b,
-			CoChanges:   count,
-			Correlation: corr,
-			Confidence:  conf,
-		})
-	}
-
-	sort.Slice(correlations, func(i, j int) bool {
-		return correlations[i].Correlation > correlations[j].Correlation
-	})
-	if len(correlations) > 50 {
-		correlations = correlations[:50]
-	}
+	// Co-change counts are accumulated per commit in the state
+	correlations := rankCorrelations(st.CoChanges, st.FileCommits, correlationCfg)
 
 	return &GitHistoryReport{
 		Summary:      summary,
@@ -436,9 +308,9 @@ func ProcessGitHistory(repoPath string) (*GitHistoryReport, error) {
 		Risks:        risks,
 		Correlations: correlations,
 		ProcessedAt:  time.Now(),
-		CommitCount:  len(commits),
+		CommitCount:  st.CommitCount,
 		Contributors: len(contributorMap),
-	}, nil
+	}
 }
 
 // WriteReportFiles writes the report to individual JSON files in the knowledge dir
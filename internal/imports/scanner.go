@@ -2,11 +2,13 @@ package imports
 
 import (
 	"bufio"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/saeedalam/teamcontext/internal/skeleton"
 	"github.com/saeedalam/teamcontext/pkg/types"
 )
 
@@ -24,6 +26,23 @@ var (
 	// Python
 	pyImport     = regexp.MustCompile(`^\s*import\s+([\w.]+)`)
 	pyFromImport = regexp.MustCompile(`^\s*from\s+([\w.]+)\s+import`)
+
+	// Rust
+	rustMod         = regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(\w+)\s*;`)
+	rustUse         = regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?use\s+`)
+	rustExternCrate = regexp.MustCompile(`^\s*extern\s+crate\s+(\w+)`)
+	rustPathAttr    = regexp.MustCompile(`^\s*#\[path\s*=\s*"([^"]+)"\]`)
+
+	// Dart: import, export and part directives
+	dartDirective   = regexp.MustCompile(`^\s*(?:import|export|part)\s+['"]([^'"]+)['"]`)
+	dartPackageName = regexp.MustCompile(`(?m)^name:\s*['"]?([\w]+)`)
+
+	// Terraform: the source of a module block
+	tfModuleSource = regexp.MustCompile(`^\s*source\s*=\s*"([^"]+)"`)
+
+	// Objective-C: #import and #include of headers, and @import of modules
+	objcInclude = regexp.MustCompile(`^\s*#\s*(?:import|include)\s*([<"])([^>"]+)[>"]`)
+	objcModule  = regexp.MustCompile(`^\s*@import\s+([\w.]+)\s*;`)
 )
 
 // ScanFile parses imports from a source file
@@ -46,6 +65,16 @@ func ScanFile(filePath string) ([]types.ImportResult, error) {
 		results = scanGo(scanner, filePath)
 	case ".py":
 		results = scanPython(scanner, filePath)
+	case ".rs":
+		results = scanRust(scanner, filePath)
+	case ".dart":
+		results = scanDart(scanner, filePath)
+	case ".ipynb":
+		results = scanNotebook(f, filePath)
+	case ".tf":
+		results = scanTerraform(scanner, filePath)
+	case ".m", ".mm", ".h":
+		results = scanObjC(scanner, filePath)
 	default:
 		// Try TypeScript patterns as fallback
 		results = scanTypeScript(scanner, filePath)
@@ -54,6 +83,27 @@ func ScanFile(filePath string) ([]types.ImportResult, error) {
 	return results, scanner.Err()
 }
 
+// scanNotebook scans the code cells of a Jupyter notebook as one script in
+// the kernel's language
+func scanNotebook(r io.Reader, source string) []types.ImportResult {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+	nb, err := skeleton.ParseNotebook(content)
+	if err != nil {
+		return nil
+	}
+	scanner := bufio.NewScanner(strings.NewReader(nb.Script()))
+	switch nb.Language {
+	case "python":
+		return scanPython(scanner, source)
+	case "javascript", "typescript":
+		return scanTypeScript(scanner, source)
+	}
+	return nil
+}
+
 func scanTypeScript(scanner *bufio.Scanner, source string) []types.ImportResult {
 	var results []types.ImportResult
 	seen := make(map[string]bool)
@@ -190,6 +240,395 @@ func scanPython(scanner *bufio.Scanner, source string) []types.ImportResult {
 	return results
 }
 
+// scanRust follows the module tree: "mod x;" declarations resolve to x.rs
+// or x/mod.rs, and crate::, self:: and super:: use-paths resolve to the file
+// of the deepest module they name. Other crates are packages.
+func scanRust(scanner *bufio.Scanner, source string) []types.ImportResult {
+	var results []types.ImportResult
+	seen := make(map[string]bool)
+	add := func(r types.ImportResult) {
+		if seen[r.Imported] || r.Imported == source {
+			return
+		}
+		seen[r.Imported] = true
+		results = append(results, r)
+	}
+
+	modDir := rustModuleDir(source)
+	pathAttr := ""
+	var stmt strings.Builder
+	inUse := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		trimmed := strings.TrimSpace(line)
+
+		// use statements may span lines until the semicolon
+		if !inUse && rustUse.MatchString(line) {
+			inUse = true
+			stmt.Reset()
+		}
+		if inUse {
+			stmt.WriteString(trimmed)
+			stmt.WriteString(" ")
+			if !strings.Contains(trimmed, ";") {
+				continue
+			}
+			inUse = false
+			for _, part := range strings.Split(stmt.String(), ";") {
+				if !rustUse.MatchString(part) {
+					continue
+				}
+				raw := strings.TrimSpace(part) + ";"
+				for _, path := range expandRustUseTree(rustUse.ReplaceAllString(part, "")) {
+					add(resolveRustUse(source, modDir, path, raw))
+				}
+			}
+			continue
+		}
+
+		if m := rustPathAttr.FindStringSubmatch(line); m != nil {
+			pathAttr = m[1]
+			continue
+		}
+
+		// mod x; with an optional #[path = "..."] override
+		if m := rustMod.FindStringSubmatch(line); m != nil {
+			if pathAttr != "" {
+				add(types.ImportResult{
+					Source:     source,
+					Imported:   filepath.Join(filepath.Dir(source), pathAttr),
+					ImportType: "relative",
+					Raw:        trimmed,
+				})
+			} else if file := rustModuleFile(filepath.Join(modDir, m[1])); file != "" {
+				add(types.ImportResult{Source: source, Imported: file, ImportType: "relative", Raw: trimmed})
+			}
+			pathAttr = ""
+			continue
+		}
+
+		if m := rustExternCrate.FindStringSubmatch(line); m != nil {
+			add(types.ImportResult{Source: source, Imported: m[1], ImportType: classifyRustCrate(m[1]), Raw: trimmed})
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#[") {
+			pathAttr = ""
+		}
+	}
+	return results
+}
+
+// scanDart reads import, export and part directives. dart: libraries are
+// builtin, relative URIs and package: URIs of the file's own package (named
+// in the nearest pubspec.yaml) resolve to files, and other packages are
+// packages.
+func scanDart(scanner *bufio.Scanner, source string) []types.ImportResult {
+	var results []types.ImportResult
+	seen := make(map[string]bool)
+	pkgName, pkgRoot := dartPackage(source)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := dartDirective.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		uri := m[1]
+		result := types.ImportResult{Source: source, Imported: uri, Raw: strings.TrimSpace(line)}
+		switch {
+		case strings.HasPrefix(uri, "dart:"):
+			result.ImportType = "builtin"
+		case strings.HasPrefix(uri, "package:"):
+			name, rest, _ := strings.Cut(strings.TrimPrefix(uri, "package:"), "/")
+			result.ImportType = "package"
+			if name == pkgName && pkgName != "" {
+				result.Imported = filepath.Join(pkgRoot, "lib", filepath.FromSlash(rest))
+				result.ImportType = "relative"
+			}
+		default:
+			result.Imported = resolveRelative(source, uri)
+			result.ImportType = "relative"
+		}
+		if seen[result.Imported] {
+			continue
+		}
+		seen[result.Imported] = true
+		results = append(results, result)
+	}
+	return results
+}
+
+// scanTerraform reads the sources of module blocks. Local modules
+// (./modules/vpc) resolve to their main.tf, or their first .tf file;
+// registry, git and other remote modules are packages.
+func scanTerraform(scanner *bufio.Scanner, source string) []types.ImportResult {
+	var results []types.ImportResult
+	inModule := false
+	depth := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if depth == 0 {
+			inModule = strings.HasPrefix(trimmed, "module ")
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		m := tfModuleSource.FindStringSubmatch(line)
+		if !inModule || m == nil {
+			continue
+		}
+		result := types.ImportResult{Source: source, Imported: m[1], ImportType: "package", Raw: trimmed}
+		if strings.HasPrefix(m[1], "./") || strings.HasPrefix(m[1], "../") {
+			result.Imported = terraformModuleFile(resolveRelative(source, m[1]))
+			result.ImportType = "relative"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// appleFrameworks are the SDK frameworks Objective-C code imports most
+var appleFrameworks = map[string]bool{
+	"Foundation": true, "UIKit": true, "AppKit": true, "Cocoa": true, "CoreFoundation": true,
+	"CoreGraphics": true, "CoreData": true, "CoreLocation": true, "QuartzCore": true,
+	"AVFoundation": true, "WebKit": true, "MapKit": true, "Security": true, "SwiftUI": true,
+	"Combine": true, "objc": true, "dispatch": true, "XCTest": true,
+}
+
+// scanObjC reads #import and #include directives and @import statements.
+// Quoted headers resolve next to the file; angle-bracketed ones and modules
+// are SDK frameworks (builtin), C library headers (builtin) or packages
+// such as CocoaPods and Swift packages.
+func scanObjC(scanner *bufio.Scanner, source string) []types.ImportResult {
+	var results []types.ImportResult
+	seen := make(map[string]bool)
+	add := func(r types.ImportResult) {
+		if !seen[r.Imported] {
+			seen[r.Imported] = true
+			results = append(results, r)
+		}
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if m := objcModule.FindStringSubmatch(line); m != nil {
+			module := strings.SplitN(m[1], ".", 2)[0]
+			add(types.ImportResult{Source: source, Imported: m[1], ImportType: classifyObjCFramework(module), Raw: trimmed})
+			continue
+		}
+		m := objcInclude.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if m[1] == `"` {
+			add(types.ImportResult{Source: source, Imported: resolveRelative(source, m[2]), ImportType: "relative", Raw: trimmed})
+			continue
+		}
+		framework := m[2]
+		if i := strings.Index(framework, "/"); i >= 0 {
+			framework = framework[:i]
+		} else {
+			framework = "" // <stdio.h>: the C library
+		}
+		add(types.ImportResult{Source: source, Imported: m[2], ImportType: classifyObjCFramework(framework), Raw: trimmed})
+	}
+	return results
+}
+
+// classifyObjCFramework tells SDK frameworks and the C library (an empty
+// name) from third-party packages
+func classifyObjCFramework(name string) string {
+	if name == "" || appleFrameworks[name] {
+		return "builtin"
+	}
+	return "package"
+}
+
+// terraformModuleFile is the file standing for a local module directory:
+// main.tf, else the first .tf file, else the directory itself
+func terraformModuleFile(dir string) string {
+	if _, err := os.Stat(filepath.Join(dir, "main.tf")); err == nil {
+		return filepath.Join(dir, "main.tf")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.tf")); len(files) > 0 {
+		return files[0]
+	}
+	return dir
+}
+
+// dartPackage finds the package a Dart file belongs to: the name and
+// directory of the nearest pubspec.yaml
+func dartPackage(source string) (name, root string) {
+	for dir := filepath.Dir(source); ; dir = filepath.Dir(dir) {
+		if data, err := os.ReadFile(filepath.Join(dir, "pubspec.yaml")); err == nil {
+			if m := dartPackageName.FindSubmatch(data); m != nil {
+				return string(m[1]), dir
+			}
+			return "", dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", ""
+		}
+	}
+}
+
+// resolveRustUse resolves one expanded use-path (crate::db::pool::Pool) to
+// the file of the deepest module it names
+func resolveRustUse(source, modDir, path, raw string) types.ImportResult {
+	segs := strings.Split(path, "::")
+	result := types.ImportResult{Source: source, Imported: segs[0], Raw: raw}
+
+	var base string
+	switch segs[0] {
+	case "crate":
+		base = rustCrateSrc(source)
+		segs = segs[1:]
+	case "self":
+		base = modDir
+		segs = segs[1:]
+	case "super":
+		base = modDir
+		for len(segs) > 0 && segs[0] == "super" {
+			base = filepath.Dir(base)
+			segs = segs[1:]
+		}
+	default:
+		// A child module in scope, else another crate
+		if file := rustResolveSegments(modDir, segs); file != "" {
+			result.Imported = file
+			result.ImportType = "relative"
+			return result
+		}
+		result.ImportType = classifyRustCrate(segs[0])
+		return result
+	}
+
+	result.ImportType = "relative"
+	if file := rustResolveSegments(base, segs); file != "" {
+		result.Imported = file
+	} else if file := rustModuleFile(base); file != "" {
+		// The item is defined in (or re-exported by) the module itself
+		result.Imported = file
+	} else {
+		result.Imported = base
+	}
+	return result
+}
+
+// rustResolveSegments returns the file of the longest module prefix of segs
+// under dir, or "" when the first segment is not a module there
+func rustResolveSegments(dir string, segs []string) string {
+	for i := len(segs); i >= 1; i-- {
+		if file := rustModuleFile(filepath.Join(append([]string{dir}, segs[:i]...)...)); file != "" {
+			return file
+		}
+	}
+	return ""
+}
+
+// rustModuleFile returns the file defining the module at path: path.rs,
+// path/mod.rs, or lib.rs/main.rs for a crate's src directory
+func rustModuleFile(path string) string {
+	candidates := []string{path + ".rs", filepath.Join(path, "mod.rs")}
+	if filepath.Base(path) == "src" {
+		candidates = append(candidates, filepath.Join(path, "lib.rs"), filepath.Join(path, "main.rs"))
+	}
+	for _, c := range candidates {
+		if info, err := os.Stat(c); err == nil && !info.IsDir() {
+			return c
+		}
+	}
+	return ""
+}
+
+// rustModuleDir is where a file's child modules live: its own directory for
+// mod.rs, lib.rs and main.rs, else a directory named after the file
+func rustModuleDir(source string) string {
+	dir := filepath.Dir(source)
+	switch filepath.Base(source) {
+	case "mod.rs", "lib.rs", "main.rs":
+		return dir
+	}
+	return filepath.Join(dir, strings.TrimSuffix(filepath.Base(source), ".rs"))
+}
+
+// rustCrateSrc finds the src directory of the crate containing source
+func rustCrateSrc(source string) string {
+	for dir := filepath.Dir(source); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "Cargo.toml")); err == nil {
+			return filepath.Join(dir, "src")
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return filepath.Dir(source)
+}
+
+// expandRustUseTree flattens a use tree into paths:
+// crate::{db::Pool, api::{self, Router as R}} -> crate::db::Pool, crate::api, crate::api::Router
+func expandRustUseTree(tree string) []string {
+	tree = strings.TrimPrefix(strings.TrimSpace(tree), "::")
+	open := strings.Index(tree, "{")
+	if open < 0 {
+		if i := strings.Index(tree, " as "); i >= 0 {
+			tree = tree[:i]
+		}
+		tree = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(tree), "::*"), "::self")
+		if tree == "" || tree == "self" {
+			return nil
+		}
+		return []string{tree}
+	}
+
+	prefix := tree[:open]
+	closeIdx := strings.LastIndex(tree, "}")
+	if closeIdx < open {
+		closeIdx = len(tree)
+	}
+	var paths []string
+	level, start := 0, open+1
+	inner := tree[:closeIdx]
+	for i := open + 1; i <= len(inner); i++ {
+		if i < len(inner) {
+			switch inner[i] {
+			case '{':
+				level++
+				continue
+			case '}':
+				level--
+				continue
+			case ',':
+				if level > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if item := strings.TrimSpace(inner[start:i]); item != "" {
+			if item == "self" {
+				paths = append(paths, expandRustUseTree(strings.TrimSuffix(prefix, "::"))...)
+			} else {
+				paths = append(paths, expandRustUseTree(prefix+item)...)
+			}
+		}
+		start = i + 1
+	}
+	return paths
+}
+
+// classifyRustCrate determines the import type for a crate name
+func classifyRustCrate(name string) string {
+	switch name {
+	case "std", "core", "alloc", "proc_macro", "test":
+		return "builtin"
+	}
+	return "package"
+}
+
 // classifyTSImport determines the import type for TS/JS imports
 func classifyTSImport(imported string) string {
 	if strings.HasPrefix(imported, ".") {
@@ -0,0 +1,202 @@
+// Package issues validates issue tracker keys and fetches ticket metadata
+// from Jira and GitHub Issues.
+package issues
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// ErrNotFound is returned when the tracker has no issue with the key
+var ErrNotFound = errors.New("issue not found")
+
+// StaleAfter is how long cached metadata is shown before it is fetched again
+const StaleAfter = time.Hour
+
+const defaultGitHubAPI = "https://api.github.com"
+
+var (
+	// Jira: PAY-142
+	jiraKeyRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[1-9]\d*$`)
+
+	// GitHub: acme/api#87, #87 or 87 with a configured repo
+	githubKeyRe = regexp.MustCompile(`^(?:([\w.-]+/[\w.-]+))?#?([1-9]\d*)$`)
+)
+
+// client is shared by all fetches; trackers that hang must not block tools
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// ParseKey validates a key and returns its provider and canonical form.
+// Jira keys are upper-cased; GitHub keys are qualified with the configured
+// repo when they only carry a number.
+func ParseKey(key string, cfg types.IssueConfig) (provider, canonical string, err error) {
+	key = strings.TrimSpace(key)
+	if upper := strings.ToUpper(key); jiraKeyRe.MatchString(upper) && cfg.Provider != "github" {
+		return "jira", upper, nil
+	}
+	if m := githubKeyRe.FindStringSubmatch(key); m != nil && cfg.Provider != "jira" {
+		repo := m[1]
+		if repo == "" {
+			repo = cfg.Repo
+		}
+		if repo == "" {
+			return "", "", fmt.Errorf("issue %q has no repository; use owner/repo#%s or set issues.repo in config", key, m[2])
+		}
+		return "github", repo + "#" + m[2], nil
+	}
+	switch cfg.Provider {
+	case "jira":
+		return "", "", fmt.Errorf("invalid Jira key %q, expected PROJECT-123", key)
+	case "github":
+		return "", "", fmt.Errorf("invalid GitHub issue %q, expected owner/repo#123 or #123", key)
+	}
+	return "", "", fmt.Errorf("invalid issue key %q, expected a Jira key (PROJECT-123) or a GitHub issue (owner/repo#123)", key)
+}
+
+// Fetch returns the issue's current title and status. The key must be
+// canonical (see ParseKey). Network and auth failures are returned as
+// errors; a missing issue as ErrNotFound.
+func Fetch(provider, key string, cfg types.IssueConfig) (*types.Issue, error) {
+	switch provider {
+	case "jira":
+		return fetchJira(key, cfg)
+	case "github":
+		return fetchGitHub(key, cfg)
+	}
+	return nil, fmt.Errorf("unsupported issue provider: %s", provider)
+}
+
+// LinkURL is the browser URL of an issue, derivable without the API
+func LinkURL(provider, key string, cfg types.IssueConfig) string {
+	switch provider {
+	case "jira":
+		if cfg.BaseURL == "" {
+			return ""
+		}
+		return strings.TrimRight(cfg.BaseURL, "/") + "/browse/" + key
+	case "github":
+		repo, number, _ := strings.Cut(key, "#")
+		return "https://github.com/" + repo + "/issues/" + number
+	}
+	return ""
+}
+
+func fetchJira(key string, cfg types.IssueConfig) (*types.Issue, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("issues.base_url is not configured for Jira")
+	}
+	endpoint := strings.TrimRight(cfg.BaseURL, "/") + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,status,assignee"
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	email, token := os.Getenv("JIRA_EMAIL"), os.Getenv("JIRA_API_TOKEN")
+	switch {
+	case email != "" && token != "":
+		// Jira Cloud: email + API token
+		req.SetBasicAuth(email, token)
+	case token != "":
+		// Jira Data Center: personal access token
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	var body struct {
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			Assignee *struct {
+				DisplayName string `json:"displayName"`
+			} `json:"assignee"`
+		} `json:"fields"`
+	}
+	if err := getJSON(req, &body); err != nil {
+		return nil, err
+	}
+
+	issue := &types.Issue{
+		Key:       key,
+		Provider:  "jira",
+		URL:       LinkURL("jira", key, cfg),
+		Title:     body.Fields.Summary,
+		Status:    body.Fields.Status.Name,
+		FetchedAt: time.Now(),
+	}
+	if body.Fields.Assignee != nil {
+		issue.Assignee = body.Fields.Assignee.DisplayName
+	}
+	return issue, nil
+}
+
+func fetchGitHub(key string, cfg types.IssueConfig) (*types.Issue, error) {
+	repo, number, _ := strings.Cut(key, "#")
+	api := defaultGitHubAPI
+	if cfg.Provider == "github" && cfg.BaseURL != "" {
+		api = strings.TrimRight(cfg.BaseURL, "/")
+	}
+	req, err := http.NewRequest("GET", api+"/repos/"+repo+"/issues/"+number, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	var body struct {
+		Title    string `json:"title"`
+		State    string `json:"state"`
+		HTMLURL  string `json:"html_url"`
+		Assignee *struct {
+			Login string `json:"login"`
+		} `json:"assignee"`
+	}
+	if err := getJSON(req, &body); err != nil {
+		return nil, err
+	}
+
+	issue := &types.Issue{
+		Key:       key,
+		Provider:  "github",
+		URL:       body.HTMLURL,
+		Title:     body.Title,
+		Status:    body.State,
+		FetchedAt: time.Now(),
+	}
+	if issue.URL == "" {
+		issue.URL = LinkURL("github", key, cfg)
+	}
+	if body.Assignee != nil {
+		issue.Assignee = body.Assignee.Login
+	}
+	return issue, nil
+}
+
+func getJSON(req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("tracker rejected credentials (HTTP %d); check JIRA_EMAIL/JIRA_API_TOKEN or GITHUB_TOKEN", resp.StatusCode)
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("tracker returned HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,74 @@
+package issues
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		cfg       types.IssueConfig
+		provider  string
+		canonical string
+		wantErr   bool
+	}{
+		{key: "PAY-142", provider: "jira", canonical: "PAY-142"},
+		{key: "pay-142", provider: "jira", canonical: "PAY-142"},
+		{key: "acme/api#87", provider: "github", canonical: "acme/api#87"},
+		{key: "#87", cfg: types.IssueConfig{Repo: "acme/api"}, provider: "github", canonical: "acme/api#87"},
+		{key: "87", cfg: types.IssueConfig{Provider: "github", Repo: "acme/api"}, provider: "github", canonical: "acme/api#87"},
+		{key: "#87", wantErr: true},
+		{key: "PAY-142", cfg: types.IssueConfig{Provider: "github", Repo: "acme/api"}, wantErr: true},
+		{key: "PAY-0", wantErr: true},
+		{key: "not a key", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		provider, canonical, err := ParseKey(tt.key, tt.cfg)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseKey(%q) = %s %s, want error", tt.key, provider, canonical)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseKey(%q) error: %v", tt.key, err)
+			continue
+		}
+		if provider != tt.provider || canonical != tt.canonical {
+			t.Errorf("ParseKey(%q) = %s %s, want %s %s", tt.key, provider, canonical, tt.provider, tt.canonical)
+		}
+	}
+}
+
+func TestFetchJira(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/PAY-142" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"fields":{"summary":"Retry failed payouts","status":{"name":"In Progress"},"assignee":{"displayName":"Dana"}}}`))
+	}))
+	defer srv.Close()
+
+	cfg := types.IssueConfig{Provider: "jira", BaseURL: srv.URL}
+	issue, err := Fetch("jira", "PAY-142", cfg)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if issue.Title != "Retry failed payouts" || issue.Status != "In Progress" || issue.Assignee != "Dana" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+	if issue.URL != srv.URL+"/browse/PAY-142" {
+		t.Errorf("unexpected URL: %s", issue.URL)
+	}
+
+	if _, err := Fetch("jira", "PAY-999", cfg); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing issue, got %v", err)
+	}
+}
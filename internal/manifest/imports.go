@@ -0,0 +1,212 @@
+package manifest
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/imports"
+)
+
+// UndeclaredImport is an import of a package no manifest above the
+// importing file declares: it resolves only through a transitive
+// dependency, a globally installed package or not at all
+type UndeclaredImport struct {
+	File      string `json:"file"`
+	Package   string `json:"package"`
+	Ecosystem string `json:"ecosystem"`
+	Raw       string `json:"raw,omitempty"`
+}
+
+// importEcosystems are the languages whose imports are checked against
+// manifests, by file extension
+var importEcosystems = map[string]string{
+	".ts": NPM, ".tsx": NPM, ".js": NPM, ".jsx": NPM, ".mjs": NPM, ".cjs": NPM,
+	".go": Go, ".py": PyPI, ".rs": Cargo,
+}
+
+// Undeclared scans the imports of project-relative files for packages
+// their manifests don't declare. Files without a manifest of their
+// ecosystem above them are not checked.
+func (inv *Inventory) Undeclared(root string, files []string) []UndeclaredImport {
+	local := make(map[string]bool)
+	for _, m := range inv.Manifests {
+		if m.Name != "" {
+			local[m.Ecosystem+"\x00"+normalizeName(m.Ecosystem, m.Name)] = true
+		}
+	}
+	var found []UndeclaredImport
+	for _, file := range files {
+		eco := importEcosystems[strings.ToLower(path.Ext(file))]
+		if eco == "" {
+			continue
+		}
+		dir := path.Dir(file)
+		m := inv.ManifestFor(eco, dir)
+		if m == nil {
+			continue
+		}
+		results, err := imports.ScanFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, r := range results {
+			if r.ImportType != "package" {
+				continue
+			}
+			pkg := importPackage(eco, r.Imported)
+			if pkg == "" || seen[pkg] || local[eco+"\x00"+normalizeName(eco, pkg)] {
+				continue
+			}
+			seen[pkg] = true
+			if inv.declaresImport(eco, dir, pkg, m, root) {
+				continue
+			}
+			found = append(found, UndeclaredImport{File: file, Package: pkg, Ecosystem: eco, Raw: r.Raw})
+		}
+	}
+	return found
+}
+
+// declaresImport reports whether a package an import names is declared,
+// matching how each ecosystem maps imports to packages
+func (inv *Inventory) declaresImport(eco, dir, pkg string, m *Manifest, root string) bool {
+	switch eco {
+	case Go:
+		// Go imports name packages inside modules: a require of the module,
+		// or of a path above it, declares it
+		if m.Name != "" && (pkg == m.Name || strings.HasPrefix(pkg, m.Name+"/")) {
+			return true
+		}
+		for _, d := range inv.Dependencies {
+			if d.Direct && d.Ecosystem == Go && within(dir, path.Dir(d.Manifest)) && (pkg == d.Name || strings.HasPrefix(pkg, d.Name+"/")) {
+				return true
+			}
+		}
+		return false
+	case PyPI:
+		if pythonStdlib[pkg] || isLocalPythonModule(root, path.Dir(m.Path), dir, pkg) {
+			return true
+		}
+		for _, dist := range pythonDistributions[pkg] {
+			if inv.Declared(eco, dir, dist) {
+				return true
+			}
+		}
+	case NPM:
+		// Types-only packages declare the package they type
+		if inv.Declared(eco, dir, "@types/"+strings.TrimPrefix(strings.ReplaceAll(pkg, "/", "__"), "@")) {
+			return true
+		}
+	}
+	return inv.Declared(eco, dir, pkg)
+}
+
+// importPackage returns the package an import names, or "" for the
+// standard library and project-local imports
+func importPackage(eco, imported string) string {
+	switch eco {
+	case NPM:
+		if strings.HasPrefix(imported, "node:") || nodeBuiltins[strings.SplitN(imported, "/", 2)[0]] {
+			return ""
+		}
+		// Path aliases (@/components, ~/lib, #internal) and URLs are not packages
+		if strings.HasPrefix(imported, "@/") || strings.HasPrefix(imported, "~") || strings.HasPrefix(imported, "#") ||
+			strings.HasPrefix(imported, "/") || strings.Contains(imported, ":") {
+			return ""
+		}
+		segs := strings.SplitN(imported, "/", 3)
+		if strings.HasPrefix(imported, "@") && len(segs) >= 2 {
+			return segs[0] + "/" + segs[1]
+		}
+		return segs[0]
+	case PyPI:
+		return strings.SplitN(imported, ".", 2)[0]
+	case Cargo:
+		switch imported {
+		case "crate", "self", "super", "std", "core", "alloc":
+			return ""
+		}
+		return imported
+	}
+	return imported
+}
+
+// isLocalPythonModule reports whether a top-level module is part of the
+// project: a package or module beside the importing file, at the
+// manifest's directory or in its src layout
+func isLocalPythonModule(root, manifestDir, dir, module string) bool {
+	for _, base := range []string{dir, manifestDir, path.Join(manifestDir, "src")} {
+		for _, name := range []string{module, module + ".py"} {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(base), name)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pythonDistributions are the distributions well-known modules are
+// installed by, where the names differ
+var pythonDistributions = map[string][]string{
+	"yaml":        {"PyYAML"},
+	"bs4":         {"beautifulsoup4"},
+	"sklearn":     {"scikit-learn"},
+	"skimage":     {"scikit-image"},
+	"PIL":         {"Pillow"},
+	"cv2":         {"opencv-python", "opencv-python-headless", "opencv-contrib-python"},
+	"dateutil":    {"python-dateutil"},
+	"dotenv":      {"python-dotenv"},
+	"jwt":         {"PyJWT"},
+	"jose":        {"python-jose"},
+	"attr":        {"attrs"},
+	"OpenSSL":     {"pyOpenSSL"},
+	"Crypto":      {"pycryptodome"},
+	"magic":       {"python-magic"},
+	"psycopg2":    {"psycopg2-binary"},
+	"google":      {"protobuf", "google-cloud-storage", "google-api-python-client", "google-auth"},
+	"grpc":        {"grpcio"},
+	"multipart":   {"python-multipart"},
+	"socketio":    {"python-socketio"},
+	"git":         {"GitPython"},
+	"serial":      {"pyserial"},
+	"zmq":         {"pyzmq"},
+	"win32api":    {"pywin32"},
+	"docx":        {"python-docx"},
+	"pptx":        {"python-pptx"},
+	"Levenshtein": {"python-Levenshtein"},
+	"telegram":    {"python-telegram-bot"},
+	"slugify":     {"python-slugify"},
+	"markdown":    {"Markdown"},
+	"faiss":       {"faiss-cpu", "faiss-gpu"},
+}
+
+// pythonStdlib are the top-level modules of the Python standard library
+var pythonStdlib = toSet(`__future__ abc argparse array ast asyncio atexit base64 bdb binascii bisect builtins bz2
+calendar cgi cmath cmd codecs collections colorsys concurrent configparser contextlib contextvars copy copyreg
+cProfile csv ctypes curses dataclasses datetime dbm decimal difflib dis doctest email encodings ensurepip enum
+errno faulthandler fcntl filecmp fileinput fnmatch fractions ftplib functools gc getopt getpass gettext glob
+graphlib grp gzip hashlib heapq hmac html http imaplib importlib inspect io ipaddress itertools json keyword
+linecache locale logging lzma mailbox marshal math mimetypes mmap multiprocessing netrc numbers operator optparse
+os pathlib pdb pickle pkgutil platform plistlib poplib posixpath pprint profile pstats pty pwd py_compile queue
+quopri random re readline reprlib resource rlcompleter runpy sched secrets select selectors shelve shlex shutil
+signal site smtplib socket socketserver sqlite3 ssl stat statistics string stringprep struct subprocess
+sys sysconfig syslog tabnanny tarfile tempfile termios textwrap threading time timeit tkinter token tokenize
+tomllib trace traceback tracemalloc tty turtle types typing unicodedata unittest urllib uuid venv warnings wave
+weakref webbrowser winreg wsgiref xml xmlrpc zipapp zipfile zipimport zlib zoneinfo _thread`)
+
+// nodeBuiltins are the modules Node.js ships with
+var nodeBuiltins = toSet(`assert async_hooks buffer child_process cluster console constants crypto dgram
+diagnostics_channel dns domain events fs http http2 https inspector module net os path perf_hooks process
+punycode querystring readline repl stream string_decoder sys timers tls trace_events tty url util v8 vm
+wasi worker_threads zlib`)
+
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,328 @@
+// Package manifest reads package manifests and lockfiles into a dependency
+// inventory: every library a project declares, the version its lockfile
+// resolves it to and the license when a lockfile or installed package
+// records one. npm, Go, Python, Cargo, RubyGems, Composer, Maven and Gradle
+// manifests are read wherever they sit in the tree, so each package of a
+// monorepo keeps its own dependencies.
+package manifest
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ecosystems, as package URLs name them
+const (
+	NPM      = "npm"
+	Go       = "golang"
+	PyPI     = "pypi"
+	Cargo    = "cargo"
+	RubyGems = "gem"
+	Composer = "composer"
+	Maven    = "maven"
+)
+
+// Dependency is one library a manifest declares, or a lockfile pins
+// without a manifest declaring it (a transitive dependency)
+type Dependency struct {
+	Name       string `json:"name"`
+	Ecosystem  string `json:"ecosystem"`
+	Version    string `json:"version,omitempty"`    // resolved by the lockfile, else the exact version declared
+	Constraint string `json:"constraint,omitempty"` // as declared: ^4.17.0, >=2.28, ~> 7.0
+	License    string `json:"license,omitempty"`
+	Manifest   string `json:"manifest"` // project-relative manifest or lockfile
+	Dev        bool   `json:"dev,omitempty"`
+	Direct     bool   `json:"direct"` // declared in the manifest, not only locked
+}
+
+// Manifest is a manifest file and the package it describes
+type Manifest struct {
+	Path      string `json:"path"` // project-relative
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name,omitempty"`     // package, module or crate name
+	Lockfile  string `json:"lockfile,omitempty"` // project-relative lockfile versions came from
+}
+
+// Inventory is every dependency found under a project root
+type Inventory struct {
+	Manifests    []Manifest   `json:"manifests"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// skipDirs hold installed dependencies and build output, not manifests
+var skipDirs = map[string]bool{
+	"node_modules": true, ".git": true, "vendor": true, "dist": true, "build": true,
+	"target": true, "__pycache__": true, ".venv": true, "venv": true, ".tox": true,
+	".gradle": true, ".teamcontext": true, "Pods": true,
+}
+
+// manifestReaders read a manifest, given its directory and content, into
+// the package it describes and the dependencies it declares
+var manifestReaders = map[string]func(dir, content string) (string, []Dependency){
+	"package.json":     readPackageJSON,
+	"go.mod":           readGoMod,
+	"requirements.txt": readRequirements,
+	"pyproject.toml":   readPyproject,
+	"Cargo.toml":       readCargoToml,
+	"Gemfile":          readGemfile,
+	"composer.json":    readComposerJSON,
+	"pom.xml":          readPom,
+	"build.gradle":     readGradle,
+	"build.gradle.kts": readGradle,
+}
+
+// lockReaders read a lockfile into the versions, and licenses when
+// recorded, it pins by package name
+var lockReaders = map[string]func(content string) map[string]Dependency{
+	"package-lock.json": readPackageLock,
+	"yarn.lock":         readYarnLock,
+	"poetry.lock":       readPackageTables,
+	"uv.lock":           readPackageTables,
+	"Cargo.lock":        readPackageTables,
+	"Gemfile.lock":      readGemfileLock,
+	"composer.lock":     readComposerLock,
+}
+
+// lockfilesFor are the lockfiles that pin a manifest's dependencies, in
+// the order they are looked for
+var lockfilesFor = map[string][]string{
+	"package.json":   {"package-lock.json", "yarn.lock"},
+	"pyproject.toml": {"poetry.lock", "uv.lock"},
+	"Cargo.toml":     {"Cargo.lock"},
+	"Gemfile":        {"Gemfile.lock"},
+	"composer.json":  {"composer.lock"},
+}
+
+// Scan reads every manifest under root. Versions come from the lockfile
+// next to a manifest or in the nearest directory above it, as npm, Cargo
+// and Poetry workspaces keep one lockfile at their root.
+func Scan(root string) *Inventory {
+	inv := &Inventory{}
+	filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if p != root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") && d.Name() != ".github") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := d.Name()
+		read, ok := manifestReaders[name]
+		if !ok && isRequirementsFile(name) {
+			read, ok = readRequirements, true
+		}
+		if !ok {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil
+		}
+		rel := relPath(root, p)
+		dir := filepath.Dir(p)
+		pkg, deps := read(dir, string(data))
+		if len(deps) == 0 && pkg == "" {
+			return nil
+		}
+		m := Manifest{Path: rel, Ecosystem: ecosystemOf(name), Name: pkg}
+		lockPath, locked := findLockfile(root, dir, name, m.Ecosystem)
+		if lockPath != "" {
+			m.Lockfile = relPath(root, lockPath)
+		}
+		for _, dep := range deps {
+			dep.Manifest = rel
+			dep.Direct = true
+			if isRequirementsFile(name) && (strings.Contains(name, "dev") || strings.Contains(name, "test")) {
+				dep.Dev = true
+			}
+			if l, ok := locked[lockKey(dep)]; ok {
+				dep.Version = l.Version
+				if l.License != "" {
+					dep.License = l.License
+				}
+			}
+			if dep.License == "" && dep.Ecosystem == NPM {
+				dep.License = installedLicense(dir, root, dep.Name)
+			}
+			inv.Dependencies = append(inv.Dependencies, dep)
+		}
+		inv.Manifests = append(inv.Manifests, m)
+		return nil
+	})
+	inv.addTransitive(root)
+	sort.SliceStable(inv.Dependencies, func(i, j int) bool {
+		a, b := inv.Dependencies[i], inv.Dependencies[j]
+		if a.Direct != b.Direct {
+			return a.Direct
+		}
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		return a.Name < b.Name
+	})
+	return inv
+}
+
+// addTransitive adds the packages lockfiles pin that no manifest declares
+func (inv *Inventory) addTransitive(root string) {
+	declared := make(map[string]bool)
+	for _, d := range inv.Dependencies {
+		declared[d.Ecosystem+"\x00"+normalizeName(d.Ecosystem, d.Name)] = true
+	}
+	seen := make(map[string]bool)
+	for _, m := range inv.Manifests {
+		if m.Lockfile == "" || seen[m.Lockfile] {
+			continue
+		}
+		seen[m.Lockfile] = true
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(m.Lockfile)))
+		if err != nil {
+			continue
+		}
+		locked := normalizeKeys(m.Ecosystem, lockReaders[path.Base(m.Lockfile)](string(data)))
+		names := make([]string, 0, len(locked))
+		for name := range locked {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			key := m.Ecosystem + "\x00" + name
+			if declared[key] || name == normalizeName(m.Ecosystem, m.Name) {
+				continue
+			}
+			declared[key] = true
+			l := locked[name]
+			inv.Dependencies = append(inv.Dependencies, Dependency{
+				Name:      l.Name,
+				Ecosystem: m.Ecosystem,
+				Version:   l.Version,
+				License:   l.License,
+				Manifest:  m.Lockfile,
+				Dev:       l.Dev,
+			})
+		}
+	}
+}
+
+// Declared reports whether the manifests of a directory, or of the
+// directories above it, declare a package
+func (inv *Inventory) Declared(ecosystem, dir, name string) bool {
+	name = normalizeName(ecosystem, name)
+	for _, d := range inv.Dependencies {
+		if d.Direct && d.Ecosystem == ecosystem && normalizeName(ecosystem, d.Name) == name && within(dir, path.Dir(d.Manifest)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ManifestFor returns the nearest manifest of an ecosystem at or above a
+// project-relative directory
+func (inv *Inventory) ManifestFor(ecosystem, dir string) *Manifest {
+	var best *Manifest
+	for i, m := range inv.Manifests {
+		mdir := path.Dir(m.Path)
+		if m.Ecosystem != ecosystem || !within(dir, mdir) {
+			continue
+		}
+		if best == nil || len(mdir) > len(path.Dir(best.Path)) {
+			best = &inv.Manifests[i]
+		}
+	}
+	return best
+}
+
+// findLockfile returns the lockfile for a manifest and what it pins, keyed
+// by normalized name
+func findLockfile(root, dir, manifest, ecosystem string) (string, map[string]Dependency) {
+	names := lockfilesFor[manifest]
+	if len(names) == 0 {
+		return "", nil
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, name := range names {
+			data, err := os.ReadFile(filepath.Join(d, name))
+			if err != nil {
+				continue
+			}
+			return filepath.Join(d, name), normalizeKeys(ecosystem, lockReaders[name](string(data)))
+		}
+		if rel, err := filepath.Rel(root, d); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return "", nil
+		}
+	}
+}
+
+// lockKey is the name lockfiles pin a dependency under
+func lockKey(d Dependency) string {
+	return normalizeName(d.Ecosystem, d.Name)
+}
+
+// normalizeKeys keys what a lockfile pins by normalized name
+func normalizeKeys(ecosystem string, locked map[string]Dependency) map[string]Dependency {
+	keyed := make(map[string]Dependency, len(locked))
+	for name, d := range locked {
+		keyed[normalizeName(ecosystem, name)] = d
+	}
+	return keyed
+}
+
+// normalizeName compares package names as their registry does: PyPI
+// ignores case and treats -, _ and . alike, as Cargo treats - and _
+func normalizeName(ecosystem, name string) string {
+	switch ecosystem {
+	case PyPI:
+		name = strings.ToLower(name)
+		return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+	case Cargo:
+		return strings.ReplaceAll(name, "_", "-")
+	case Composer:
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+func ecosystemOf(manifest string) string {
+	switch manifest {
+	case "package.json":
+		return NPM
+	case "go.mod":
+		return Go
+	case "pyproject.toml":
+		return PyPI
+	case "Cargo.toml":
+		return Cargo
+	case "Gemfile":
+		return RubyGems
+	case "composer.json":
+		return Composer
+	case "pom.xml", "build.gradle", "build.gradle.kts":
+		return Maven
+	}
+	return PyPI // requirements files
+}
+
+// isRequirementsFile matches requirements.txt and variants such as
+// requirements-dev.txt, whose packages are development dependencies
+func isRequirementsFile(name string) bool {
+	return strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt")
+}
+
+// within reports whether dir is base or below it; both project-relative
+func within(dir, base string) bool {
+	dir, base = path.Clean(dir), path.Clean(base)
+	return base == "." || dir == base || strings.HasPrefix(dir, base+"/")
+}
+
+func relPath(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
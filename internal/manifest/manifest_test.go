@@ -0,0 +1,241 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func find(inv *Inventory, ecosystem, name string) *Dependency {
+	for i, d := range inv.Dependencies {
+		if d.Ecosystem == ecosystem && d.Name == name {
+			return &inv.Dependencies[i]
+		}
+	}
+	return nil
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"name": "web", "workspaces": ["packages/*"],
+  "dependencies": {"express": "^4.18.0", "@babel/core": "7.24.0"},
+  "devDependencies": {"jest": "^29.0.0"}}`,
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {
+  "": {"name": "web"},
+  "node_modules/express": {"version": "4.18.2", "license": "MIT"},
+  "node_modules/@babel/core": {"version": "7.24.0", "license": "MIT"},
+  "node_modules/jest": {"version": "29.7.0", "dev": true},
+  "node_modules/debug": {"version": "2.6.9", "license": "MIT"},
+  "node_modules/express/node_modules/debug": {"version": "3.0.0"},
+  "node_modules/ui": {"resolved": "packages/ui", "link": true}}}`,
+		"node_modules/jest/package.json": `{"name": "jest", "license": "MIT"}`,
+		"packages/ui/package.json":       `{"name": "ui", "dependencies": {"react": "^18.2.0"}}`,
+		"go.mod": `module example.com/svc
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/google/uuid v1.6.0
+	golang.org/x/sys v0.20.0 // indirect
+)
+`,
+		"ml/pyproject.toml": `[tool.poetry]
+name = "ml"
+
+[tool.poetry.dependencies]
+python = "^3.11"
+numpy = "^1.26"
+scikit-learn = { version = "1.4.0", extras = ["all"] }
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.0"
+`,
+		"ml/poetry.lock": `[[package]]
+name = "numpy"
+version = "1.26.4"
+
+[[package]]
+name = "scikit-learn"
+version = "1.4.0"
+
+[[package]]
+name = "joblib"
+version = "1.3.2"
+`,
+		"ml/requirements-dev.txt": "black==24.1.0  # formatter\n-r requirements.txt\nruff>=0.3\n",
+		"core/Cargo.toml": `[package]
+name = "core"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+tokio = "1"
+
+[dev-dependencies]
+proptest = "1.4"
+`,
+		"Gemfile": `source "https://rubygems.org"
+gem "rails", "~> 7.1"
+group :development, :test do
+  gem "rspec-rails"
+end
+`,
+		"Gemfile.lock": "GEM\n  specs:\n    rails (7.1.3)\n      actionpack (= 7.1.3)\n    rspec-rails (6.1.1)\n",
+		"api/pom.xml": `<project>
+  <artifactId>api</artifactId>
+  <dependencies>
+    <dependency><groupId>org.junit.jupiter</groupId><artifactId>junit-jupiter</artifactId><version>5.10.0</version><scope>test</scope></dependency>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>${guava.version}</version>
+    </dependency>
+  </dependencies>
+</project>`,
+		"node_modules/left-pad/package.json": `{"name": "left-pad", "dependencies": {"x": "1"}}`,
+	})
+
+	inv := Scan(root)
+
+	var paths []string
+	for _, m := range inv.Manifests {
+		paths = append(paths, m.Path)
+	}
+	wantPaths := []string{"Gemfile", "api/pom.xml", "core/Cargo.toml", "go.mod", "ml/pyproject.toml", "ml/requirements-dev.txt", "package.json", "packages/ui/package.json"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("manifests = %v, want %v", paths, wantPaths)
+	}
+
+	tests := []struct {
+		eco, name string
+		want      Dependency
+	}{
+		{NPM, "express", Dependency{Version: "4.18.2", Constraint: "^4.18.0", License: "MIT", Manifest: "package.json", Direct: true}},
+		{NPM, "jest", Dependency{Version: "29.7.0", Constraint: "^29.0.0", License: "MIT", Manifest: "package.json", Dev: true, Direct: true}},
+		{NPM, "react", Dependency{Constraint: "^18.2.0", Manifest: "packages/ui/package.json", Direct: true}},
+		{NPM, "debug", Dependency{Version: "2.6.9", License: "MIT", Manifest: "package-lock.json"}},
+		{Go, "github.com/spf13/cobra", Dependency{Version: "v1.8.0", Constraint: "v1.8.0", Manifest: "go.mod", Direct: true}},
+		{Go, "golang.org/x/sys", Dependency{Version: "v0.20.0", Constraint: "v0.20.0 // indirect", Manifest: "go.mod", Direct: true}},
+		{PyPI, "scikit-learn", Dependency{Version: "1.4.0", Constraint: "1.4.0", Manifest: "ml/pyproject.toml", Direct: true}},
+		{PyPI, "pytest", Dependency{Constraint: "^8.0", Manifest: "ml/pyproject.toml", Dev: true, Direct: true}},
+		{PyPI, "joblib", Dependency{Version: "1.3.2", Manifest: "ml/poetry.lock"}},
+		{PyPI, "black", Dependency{Version: "24.1.0", Constraint: "==24.1.0", Manifest: "ml/requirements-dev.txt", Dev: true, Direct: true}},
+		{Cargo, "serde", Dependency{Constraint: "1.0", Manifest: "core/Cargo.toml", Direct: true}},
+		{Cargo, "proptest", Dependency{Constraint: "1.4", Manifest: "core/Cargo.toml", Dev: true, Direct: true}},
+		{RubyGems, "rails", Dependency{Version: "7.1.3", Constraint: "~> 7.1", Manifest: "Gemfile", Direct: true}},
+		{RubyGems, "rspec-rails", Dependency{Version: "6.1.1", Manifest: "Gemfile", Dev: true, Direct: true}},
+		{Maven, "com.google.guava:guava", Dependency{Constraint: "${guava.version}", Manifest: "api/pom.xml", Direct: true}},
+		{Maven, "org.junit.jupiter:junit-jupiter", Dependency{Version: "5.10.0", Constraint: "5.10.0", Manifest: "api/pom.xml", Dev: true, Direct: true}},
+	}
+	for _, tt := range tests {
+		got := find(inv, tt.eco, tt.name)
+		if got == nil {
+			t.Errorf("%s %s not found", tt.eco, tt.name)
+			continue
+		}
+		tt.want.Name, tt.want.Ecosystem = tt.name, tt.eco
+		if *got != tt.want {
+			t.Errorf("%s %s = %+v, want %+v", tt.eco, tt.name, *got, tt.want)
+		}
+	}
+
+	// Workspace packages and python itself are not dependencies
+	for _, name := range []string{"ui", "python", "x", "actionpack"} {
+		for _, d := range inv.Dependencies {
+			if d.Name == name && d.Ecosystem != RubyGems {
+				t.Errorf("unexpected dependency %+v", d)
+			}
+		}
+	}
+
+	if m := inv.ManifestFor(NPM, "packages/ui/src"); m == nil || m.Path != "packages/ui/package.json" {
+		t.Errorf("ManifestFor = %+v", m)
+	}
+	if !inv.Declared(NPM, "packages/ui/src", "express") {
+		t.Error("express should be declared for packages/ui through the root manifest")
+	}
+	if inv.Declared(NPM, ".", "react") {
+		t.Error("react is declared only for packages/ui")
+	}
+}
+
+func TestUndeclared(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"dependencies": {"lodash": "^4.17.21", "@tanstack/react-query": "^5.0.0"}, "devDependencies": {"@types/node": "^20"}}`,
+		"src/app.ts": `import fs from "node:fs";
+import path from "path";
+import { debounce } from "lodash/debounce";
+import { useQuery } from "@tanstack/react-query";
+import { Button } from "@/components/button";
+import axios from "axios";
+import { helper } from "./helper";
+`,
+		"go.mod": "module example.com/svc\n\nrequire github.com/spf13/cobra v1.8.0\n",
+		"cmd/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/svc/internal/app"
+	"github.com/spf13/cobra"
+	"github.com/rs/zerolog/log"
+)
+`,
+		"requirements.txt": "PyYAML==6.0\nrequests\n",
+		"scripts/job.py": `import os
+import yaml
+import requests
+import numpy as np
+from mylib import util
+`,
+		"mylib/__init__.py": "",
+		"notes/readme.py":   "import pandas\n",
+	})
+	inv := Scan(root)
+
+	got := inv.Undeclared(root, []string{"src/app.ts", "cmd/main.go", "scripts/job.py"})
+	var pkgs []string
+	for _, u := range got {
+		pkgs = append(pkgs, u.Ecosystem+":"+u.Package)
+	}
+	want := []string{"npm:axios", "golang:github.com/rs/zerolog/log", "pypi:numpy"}
+	if !reflect.DeepEqual(pkgs, want) {
+		t.Errorf("undeclared = %v, want %v", pkgs, want)
+	}
+	if len(got) > 0 && got[0].File != "src/app.ts" {
+		t.Errorf("file = %q", got[0].File)
+	}
+}
+
+func TestSBOM(t *testing.T) {
+	bom := SBOM([]Dependency{
+		{Name: "@babel/core", Ecosystem: NPM, Version: "7.24.0", License: "MIT"},
+		{Name: "Django_REST", Ecosystem: PyPI, Version: "3.14", License: "BSD-3-Clause OR MIT"},
+		{Name: "com.google.guava:guava", Ecosystem: Maven, Dev: true},
+	})
+	want := []Component{
+		{Type: "library", Group: "@babel", Name: "core", Version: "7.24.0", PURL: "pkg:npm/%40babel/core@7.24.0", Licenses: []LicenseChoice{{License: &License{ID: "MIT"}}}},
+		{Type: "library", Name: "Django_REST", Version: "3.14", PURL: "pkg:pypi/django-rest@3.14", Licenses: []LicenseChoice{{Expression: "BSD-3-Clause OR MIT"}}},
+		{Type: "library", Group: "com.google.guava", Name: "guava", PURL: "pkg:maven/com.google.guava/guava", Scope: "optional"},
+	}
+	if !reflect.DeepEqual(bom.Components, want) {
+		t.Errorf("components = %+v", bom.Components)
+	}
+}
@@ -0,0 +1,677 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	goRequire       = regexp.MustCompile(`^(?:require\s+)?([^\s()]+)\s+(v[^\s]+)(\s*//\s*indirect)?`)
+	pep508          = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(\(?[<>=!~^][^;#]*\)?)?`)
+	tomlHeader      = regexp.MustCompile(`^\[\[?([^\]]+)\]\]?\s*(?:#.*)?$`)
+	tomlKeyValue    = regexp.MustCompile(`^("[^"]+"|'[^']+'|[\w.-]+)\s*=\s*(.*)$`)
+	tomlVersion     = regexp.MustCompile(`\bversion\s*=\s*["']([^"']+)["']`)
+	gemLine         = regexp.MustCompile(`^gem\s+['"]([^'"]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
+	gemGroup        = regexp.MustCompile(`^group\s+(.+?)\s+do\b`)
+	gemLockSpec     = regexp.MustCompile(`^    ([^\s(]+) \(([^)]+)\)$`)
+	pomDependency   = regexp.MustCompile(`(?s)<dependency>(.*?)</dependency>`)
+	pomTag          = regexp.MustCompile(`(?s)<(groupId|artifactId|version|scope)>\s*([^<]*?)\s*</`)
+	pomArtifactID   = regexp.MustCompile(`(?s)<project[^>]*>.*?<artifactId>\s*([^<]+?)\s*</artifactId>`)
+	gradleNotation  = regexp.MustCompile(`^\s*(\w+)\s*\(?\s*["']([^"':]+):([^"':]+)(?::([^"':@]+))?[^"']*["']`)
+	yarnVersionLine = regexp.MustCompile(`^\s+version:?\s+"?([^"\s]+)"?`)
+)
+
+// --- npm ---
+
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	License              json.RawMessage   `json:"license"`
+}
+
+func readPackageJSON(dir, content string) (string, []Dependency) {
+	var pkg packageJSON
+	if json.Unmarshal([]byte(content), &pkg) != nil {
+		return "", nil
+	}
+	var deps []Dependency
+	add := func(m map[string]string, dev bool) {
+		for _, name := range sortedKeys(m) {
+			if !containsDep(deps, name) {
+				deps = append(deps, Dependency{Name: name, Ecosystem: NPM, Constraint: m[name], Version: exactVersion(m[name]), Dev: dev})
+			}
+		}
+	}
+	add(pkg.Dependencies, false)
+	add(pkg.PeerDependencies, false)
+	add(pkg.OptionalDependencies, false)
+	add(pkg.DevDependencies, true)
+	return pkg.Name, deps
+}
+
+func readPackageLock(content string) map[string]Dependency {
+	var lock struct {
+		Packages map[string]struct {
+			Version string          `json:"version"`
+			License json.RawMessage `json:"license"`
+			Dev     bool            `json:"dev"`
+			Link    bool            `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+			Dev     bool   `json:"dev"`
+		} `json:"dependencies"`
+	}
+	if json.Unmarshal([]byte(content), &lock) != nil {
+		return nil
+	}
+	locked := make(map[string]Dependency)
+	// lockfileVersion 2 and 3 key packages by their install path; only
+	// top-level ones are the versions the project's imports resolve to
+	for key, p := range lock.Packages {
+		name, ok := strings.CutPrefix(key, "node_modules/")
+		// Links are workspace packages of the project itself
+		if !ok || p.Link || strings.Contains(name, "/node_modules/") {
+			continue
+		}
+		locked[name] = Dependency{Name: name, Version: p.Version, License: licenseString(p.License), Dev: p.Dev}
+	}
+	for name, p := range lock.Dependencies {
+		if _, ok := locked[name]; !ok {
+			locked[name] = Dependency{Name: name, Version: p.Version, Dev: p.Dev}
+		}
+	}
+	return locked
+}
+
+// readYarnLock reads yarn.lock, classic and Berry: a header naming the
+// package and its ranges ("lodash@^4.17.0, lodash@^4.17.21":) then an
+// indented version line
+func readYarnLock(content string) map[string]Dependency {
+	locked := make(map[string]Dependency)
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line[0] != ' ' && strings.HasSuffix(line, ":") {
+			names = names[:0]
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				spec = strings.Trim(strings.TrimSpace(spec), `"`)
+				if at := strings.LastIndex(spec, "@"); at > 0 {
+					names = append(names, spec[:at])
+				}
+			}
+			continue
+		}
+		if m := yarnVersionLine.FindStringSubmatch(line); m != nil && len(names) > 0 {
+			for _, name := range names {
+				if _, ok := locked[name]; !ok {
+					locked[name] = Dependency{Name: name, Version: m[1]}
+				}
+			}
+			names = names[:0]
+		}
+	}
+	return locked
+}
+
+// installedLicense reads the license of an npm package installed in the
+// node_modules next to its manifest or in a directory above it
+func installedLicense(dir, root, name string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		data, err := os.ReadFile(filepath.Join(d, "node_modules", filepath.FromSlash(name), "package.json"))
+		if err == nil {
+			var pkg packageJSON
+			if json.Unmarshal(data, &pkg) == nil {
+				return licenseString(pkg.License)
+			}
+			return ""
+		}
+		if rel, err := filepath.Rel(root, d); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+	}
+}
+
+// licenseString reads a license field: "MIT", {"type": "MIT"}, or a list
+// of either, joined as an SPDX expression
+func licenseString(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var obj struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(raw, &obj) == nil && obj.Type != "" {
+		return obj.Type
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		var ids []string
+		for _, item := range list {
+			if id := licenseString(item); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return strings.Join(ids, " OR ")
+	}
+	return ""
+}
+
+// --- Go ---
+
+func readGoMod(dir, content string) (string, []Dependency) {
+	module := ""
+	var deps []Dependency
+	inRequire := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "module "):
+			module = strings.Trim(strings.TrimSpace(trimmed[len("module "):]), `"`)
+			continue
+		case strings.HasPrefix(trimmed, "require ("):
+			inRequire = true
+			continue
+		case inRequire && trimmed == ")":
+			inRequire = false
+			continue
+		case !inRequire && !strings.HasPrefix(trimmed, "require "):
+			continue
+		}
+		if m := goRequire.FindStringSubmatch(trimmed); m != nil {
+			dep := Dependency{Name: m[1], Ecosystem: Go, Version: m[2], Constraint: m[2]}
+			if m[3] != "" {
+				dep.Constraint += " // indirect"
+			}
+			deps = append(deps, dep)
+		}
+	}
+	return module, deps
+}
+
+// --- Python ---
+
+// readRequirements reads a pip requirements file: one PEP 508 requirement
+// per line, skipping options (-r, -e, --index-url) and URLs
+func readRequirements(dir, content string) (string, []Dependency) {
+	var deps []Dependency
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || line[0] == '#' || line[0] == '-' || strings.Contains(line, "://") {
+			continue
+		}
+		if dep, ok := pythonRequirement(line); ok && !containsDep(deps, dep.Name) {
+			deps = append(deps, dep)
+		}
+	}
+	return "", deps
+}
+
+// pythonRequirement reads a PEP 508 requirement: requests[socks]>=2.28; python_version>"3"
+func pythonRequirement(spec string) (Dependency, bool) {
+	m := pep508.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return Dependency{}, false
+	}
+	constraint := strings.Trim(strings.TrimSpace(m[2]), "()")
+	dep := Dependency{Name: m[1], Ecosystem: PyPI, Constraint: constraint}
+	if v, ok := strings.CutPrefix(constraint, "=="); ok && !strings.ContainsAny(v, ",*") {
+		dep.Version = strings.TrimSpace(v)
+	}
+	return dep, true
+}
+
+// readPyproject reads PEP 621 [project] dependencies and optional
+// dependencies, and Poetry's dependency tables
+func readPyproject(dir, content string) (string, []Dependency) {
+	name := ""
+	var deps []Dependency
+	add := func(d Dependency) {
+		if !containsDep(deps, d.Name) && strings.ToLower(d.Name) != "python" {
+			deps = append(deps, d)
+		}
+	}
+	for _, e := range tomlEntries(content) {
+		switch {
+		case (e.table == "project" || e.table == "tool.poetry") && e.key == "name":
+			name = tomlString(e.value)
+		case e.table == "project" && e.key == "dependencies":
+			for _, spec := range tomlArray(e.value) {
+				if d, ok := pythonRequirement(spec); ok {
+					add(d)
+				}
+			}
+		case e.table == "project.optional-dependencies" || e.table == "dependency-groups":
+			for _, spec := range tomlArray(e.value) {
+				if d, ok := pythonRequirement(spec); ok {
+					d.Dev = isDevGroup(e.key)
+					add(d)
+				}
+			}
+		case e.table == "tool.poetry.dependencies" || e.table == "tool.poetry.dev-dependencies" ||
+			strings.HasPrefix(e.table, "tool.poetry.group.") && strings.HasSuffix(e.table, ".dependencies"):
+			d := Dependency{Name: tomlString(e.key), Ecosystem: PyPI, Constraint: tomlConstraint(e.value)}
+			d.Version = exactVersion(d.Constraint)
+			d.Dev = e.table == "tool.poetry.dev-dependencies" || isDevGroup(strings.TrimSuffix(strings.TrimPrefix(e.table, "tool.poetry.group."), ".dependencies"))
+			add(d)
+		}
+	}
+	return name, deps
+}
+
+// isDevGroup reports whether an extra or dependency group is for
+// development rather than an optional feature
+func isDevGroup(group string) bool {
+	switch strings.ToLower(group) {
+	case "dev", "development", "test", "tests", "testing", "lint", "docs", "typing":
+		return true
+	}
+	return false
+}
+
+// readPackageTables reads the [[package]] tables of poetry.lock, uv.lock
+// and Cargo.lock
+func readPackageTables(content string) map[string]Dependency {
+	locked := make(map[string]Dependency)
+	var cur Dependency
+	index := -1
+	flush := func() {
+		if cur.Name != "" {
+			if _, ok := locked[cur.Name]; !ok {
+				locked[cur.Name] = cur
+			}
+		}
+		cur = Dependency{}
+	}
+	for _, e := range tomlEntries(content) {
+		if e.index != index {
+			flush()
+			index = e.index
+		}
+		if e.table != "package" {
+			continue
+		}
+		switch e.key {
+		case "name":
+			cur.Name = tomlString(e.value)
+		case "version":
+			cur.Version = tomlString(e.value)
+		case "category":
+			cur.Dev = tomlString(e.value) == "dev"
+		}
+	}
+	flush()
+	return locked
+}
+
+// --- Cargo ---
+
+func readCargoToml(dir, content string) (string, []Dependency) {
+	name := ""
+	var deps []Dependency
+	for _, e := range tomlEntries(content) {
+		switch e.table {
+		case "package":
+			if e.key == "name" {
+				name = tomlString(e.value)
+			}
+		case "dependencies", "dev-dependencies", "build-dependencies", "workspace.dependencies":
+			d := Dependency{Name: tomlString(e.key), Ecosystem: Cargo, Constraint: tomlConstraint(e.value), Dev: e.table == "dev-dependencies"}
+			if !containsDep(deps, d.Name) {
+				deps = append(deps, d)
+			}
+		default:
+			// [dependencies.serde] tables and target-specific dependencies
+			if dep, ok := strings.CutPrefix(e.table, "dependencies."); ok && e.key == "version" && !containsDep(deps, dep) {
+				deps = append(deps, Dependency{Name: dep, Ecosystem: Cargo, Constraint: tomlString(e.value)})
+			}
+		}
+	}
+	return name, deps
+}
+
+// --- RubyGems ---
+
+func readGemfile(dir, content string) (string, []Dependency) {
+	var deps []Dependency
+	dev := false
+	depth := 0
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if m := gemGroup.FindStringSubmatch(trimmed); m != nil {
+			depth++
+			dev = !strings.Contains(m[1], ":production") && !strings.Contains(m[1], ":default")
+			continue
+		}
+		if trimmed == "end" && depth > 0 {
+			depth--
+			if depth == 0 {
+				dev = false
+			}
+			continue
+		}
+		if m := gemLine.FindStringSubmatch(trimmed); m != nil && !containsDep(deps, m[1]) {
+			deps = append(deps, Dependency{Name: m[1], Ecosystem: RubyGems, Constraint: m[2], Version: exactVersion(m[2]), Dev: dev})
+		}
+	}
+	return "", deps
+}
+
+// readGemfileLock reads the specs of Gemfile.lock: "    rails (7.1.2)"
+func readGemfileLock(content string) map[string]Dependency {
+	locked := make(map[string]Dependency)
+	for _, line := range strings.Split(content, "\n") {
+		if m := gemLockSpec.FindStringSubmatch(strings.TrimRight(line, "\r")); m != nil {
+			if _, ok := locked[m[1]]; !ok {
+				locked[m[1]] = Dependency{Name: m[1], Version: m[2]}
+			}
+		}
+	}
+	return locked
+}
+
+// --- Composer ---
+
+func readComposerJSON(dir, content string) (string, []Dependency) {
+	var pkg struct {
+		Name       string            `json:"name"`
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if json.Unmarshal([]byte(content), &pkg) != nil {
+		return "", nil
+	}
+	var deps []Dependency
+	add := func(m map[string]string, dev bool) {
+		for _, name := range sortedKeys(m) {
+			// The PHP version and extensions are platform requirements
+			if name == "php" || strings.HasPrefix(name, "ext-") || strings.HasPrefix(name, "lib-") || containsDep(deps, name) {
+				continue
+			}
+			deps = append(deps, Dependency{Name: name, Ecosystem: Composer, Constraint: m[name], Version: exactVersion(m[name]), Dev: dev})
+		}
+	}
+	add(pkg.Require, false)
+	add(pkg.RequireDev, true)
+	return pkg.Name, deps
+}
+
+func readComposerLock(content string) map[string]Dependency {
+	type lockedPackage struct {
+		Name    string   `json:"name"`
+		Version string   `json:"version"`
+		License []string `json:"license"`
+	}
+	var lock struct {
+		Packages    []lockedPackage `json:"packages"`
+		PackagesDev []lockedPackage `json:"packages-dev"`
+	}
+	if json.Unmarshal([]byte(content), &lock) != nil {
+		return nil
+	}
+	locked := make(map[string]Dependency)
+	for i, list := range [][]lockedPackage{lock.Packages, lock.PackagesDev} {
+		for _, p := range list {
+			locked[p.Name] = Dependency{Name: p.Name, Version: strings.TrimPrefix(p.Version, "v"), License: strings.Join(p.License, " OR "), Dev: i == 1}
+		}
+	}
+	return locked
+}
+
+// --- Maven and Gradle ---
+
+// readPom reads the dependencies of a pom.xml as groupId:artifactId.
+// Versions set through properties are kept as written: ${spring.version}.
+func readPom(dir, content string) (string, []Dependency) {
+	name := ""
+	if m := pomArtifactID.FindStringSubmatch(stripPomSections(content)); m != nil {
+		name = m[1]
+	}
+	var deps []Dependency
+	for _, block := range pomDependency.FindAllStringSubmatch(content, -1) {
+		tags := make(map[string]string)
+		for _, t := range pomTag.FindAllStringSubmatch(block[1], -1) {
+			tags[t[1]] = t[2]
+		}
+		if tags["groupId"] == "" || tags["artifactId"] == "" {
+			continue
+		}
+		d := Dependency{
+			Name:       tags["groupId"] + ":" + tags["artifactId"],
+			Ecosystem:  Maven,
+			Constraint: tags["version"],
+			Dev:        tags["scope"] == "test",
+		}
+		if !strings.Contains(d.Constraint, "${") {
+			d.Version = d.Constraint
+		}
+		if !containsDep(deps, d.Name) {
+			deps = append(deps, d)
+		}
+	}
+	return name, deps
+}
+
+// stripPomSections drops the parent and dependency sections of a pom, so
+// the first artifactId left is the project's own
+func stripPomSections(content string) string {
+	for _, tag := range []string{"parent", "dependencies", "dependencyManagement", "build", "plugins"} {
+		re := regexp.MustCompile(`(?s)<` + tag + `>.*?</` + tag + `>`)
+		content = re.ReplaceAllString(content, "")
+	}
+	return content
+}
+
+// readGradle reads string notation dependencies of build.gradle and
+// build.gradle.kts: implementation("com.squareup.okhttp3:okhttp:4.12.0")
+func readGradle(dir, content string) (string, []Dependency) {
+	var deps []Dependency
+	for _, line := range strings.Split(content, "\n") {
+		m := gradleNotation.FindStringSubmatch(line)
+		if m == nil || !isGradleConfiguration(m[1]) {
+			continue
+		}
+		d := Dependency{
+			Name:       m[2] + ":" + m[3],
+			Ecosystem:  Maven,
+			Constraint: m[4],
+			Dev:        strings.HasPrefix(m[1], "test") || strings.HasPrefix(m[1], "androidTest"),
+		}
+		if !strings.ContainsAny(d.Constraint, "$+[") {
+			d.Version = d.Constraint
+		}
+		if !containsDep(deps, d.Name) {
+			deps = append(deps, d)
+		}
+	}
+	return "", deps
+}
+
+// isGradleConfiguration reports whether a call adds to a dependency
+// configuration: implementation, api, kapt, testImplementation, ...
+func isGradleConfiguration(name string) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range []string{"implementation", "api", "compileonly", "runtimeonly", "kapt", "ksp", "annotationprocessor", "classpath", "compile", "runtime"} {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- TOML ---
+
+// tomlEntry is a key and its raw value in a table of a TOML file. Arrays
+// spanning lines are joined into one value.
+type tomlEntry struct {
+	table string
+	index int // counts table headers, telling [[array]] tables apart
+	key   string
+	value string
+}
+
+// tomlEntries reads the key/value pairs of a TOML file, enough of TOML for
+// manifests and lockfiles
+func tomlEntries(content string) []tomlEntry {
+	var entries []tomlEntry
+	table := ""
+	index := 0
+	var pending *tomlEntry
+	depth := 0
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(stripTomlComment(line))
+		if pending != nil {
+			pending.value += " " + trimmed
+			depth += strings.Count(trimmed, "[") + strings.Count(trimmed, "{") - strings.Count(trimmed, "]") - strings.Count(trimmed, "}")
+			if depth <= 0 {
+				entries = append(entries, *pending)
+				pending = nil
+			}
+			continue
+		}
+		if m := tomlHeader.FindStringSubmatch(trimmed); m != nil {
+			table = strings.TrimSpace(m[1])
+			index++
+			continue
+		}
+		m := tomlKeyValue.FindStringSubmatch(trimmed)
+		if m == nil {
+			continue
+		}
+		e := tomlEntry{table: table, index: index, key: tomlString(m[1]), value: strings.TrimSpace(m[2])}
+		// Dotted keys: serde.version = "1" in [dependencies]
+		if i := strings.Index(e.key, "."); i > 0 && !strings.HasPrefix(m[1], `"`) && !strings.HasPrefix(m[1], "'") {
+			e.table = strings.Trim(table+"."+e.key[:i], ".")
+			e.key = e.key[i+1:]
+		}
+		depth = strings.Count(e.value, "[") + strings.Count(e.value, "{") - strings.Count(e.value, "]") - strings.Count(e.value, "}")
+		if depth > 0 {
+			pending = &e
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// stripTomlComment drops a # comment outside strings
+func stripTomlComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlString unquotes a TOML string value or key
+func tomlString(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// tomlArray returns the strings of a TOML array
+func tomlArray(v string) []string {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "[") {
+		return nil
+	}
+	var items []string
+	for _, item := range splitOutsideQuotes(strings.TrimSuffix(strings.TrimPrefix(v, "["), "]")) {
+		if s := tomlString(item); s != "" {
+			items = append(items, s)
+		}
+	}
+	return items
+}
+
+// tomlConstraint reads a dependency's version from a string or an inline
+// table: "1.0" or { version = "1.0", features = ["derive"] }
+func tomlConstraint(v string) string {
+	if strings.HasPrefix(strings.TrimSpace(v), "{") {
+		if m := tomlVersion.FindStringSubmatch(v); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	return tomlString(v)
+}
+
+// splitOutsideQuotes splits on commas that are not inside quotes
+func splitOutsideQuotes(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// exactVersion returns a constraint that pins one version, else ""
+func exactVersion(constraint string) string {
+	v := strings.TrimPrefix(strings.TrimSpace(constraint), "=")
+	v = strings.TrimPrefix(v, "v")
+	if v == "" || strings.ContainsAny(v, "^~<>*|, xX") || strings.Contains(v, ":") {
+		return ""
+	}
+	if v[0] < '0' || v[0] > '9' {
+		return ""
+	}
+	return v
+}
+
+func containsDep(deps []Dependency, name string) bool {
+	for _, d := range deps {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
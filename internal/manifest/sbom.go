@@ -0,0 +1,85 @@
+package manifest
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CycloneDX is a CycloneDX 1.5 software bill of materials, with the fields
+// an inventory fills
+type CycloneDX struct {
+	BOMFormat   string      `json:"bomFormat"`
+	SpecVersion string      `json:"specVersion"`
+	Version     int         `json:"version"`
+	Components  []Component `json:"components"`
+}
+
+// Component is a library in a CycloneDX SBOM
+type Component struct {
+	Type     string          `json:"type"`
+	Name     string          `json:"name"`
+	Group    string          `json:"group,omitempty"`
+	Version  string          `json:"version,omitempty"`
+	PURL     string          `json:"purl,omitempty"`
+	Scope    string          `json:"scope,omitempty"`
+	Licenses []LicenseChoice `json:"licenses,omitempty"`
+}
+
+// LicenseChoice holds an SPDX license ID or, for anything else, an expression
+type LicenseChoice struct {
+	License    *License `json:"license,omitempty"`
+	Expression string   `json:"expression,omitempty"`
+}
+
+// License is an SPDX license ID
+type License struct {
+	ID string `json:"id"`
+}
+
+// SBOM renders dependencies as a CycloneDX SBOM
+func SBOM(deps []Dependency) *CycloneDX {
+	bom := &CycloneDX{BOMFormat: "CycloneDX", SpecVersion: "1.5", Version: 1, Components: []Component{}}
+	for _, d := range deps {
+		c := Component{Type: "library", Name: d.Name, Version: d.Version, PURL: PURL(d)}
+		switch {
+		case d.Ecosystem == Maven:
+			if group, artifact, ok := strings.Cut(d.Name, ":"); ok {
+				c.Group, c.Name = group, artifact
+			}
+		case strings.Count(d.Name, "/") == 1 && (d.Ecosystem == NPM || d.Ecosystem == Composer):
+			c.Group, c.Name, _ = strings.Cut(d.Name, "/")
+		}
+		if d.Dev {
+			c.Scope = "optional"
+		}
+		switch {
+		case d.License == "":
+		case strings.ContainsAny(d.License, " ()"):
+			c.Licenses = []LicenseChoice{{Expression: d.License}}
+		default:
+			c.Licenses = []LicenseChoice{{License: &License{ID: d.License}}}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	return bom
+}
+
+// PURL is a dependency's package URL: pkg:npm/%40babel/core@7.24.0
+func PURL(d Dependency) string {
+	name := d.Name
+	switch d.Ecosystem {
+	case Maven:
+		name = strings.Replace(name, ":", "/", 1)
+	case PyPI:
+		name = normalizeName(PyPI, name)
+	}
+	segs := strings.Split(name, "/")
+	for i, s := range segs {
+		segs[i] = strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+	}
+	purl := "pkg:" + d.Ecosystem + "/" + strings.Join(segs, "/")
+	if d.Version != "" {
+		purl += "@" + url.PathEscape(d.Version)
+	}
+	return purl
+}
@@ -0,0 +1,314 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// maxAnchorContext is how many lines of an anchored region are kept to
+// find it again; the first lines of a region are enough to recognize it
+const maxAnchorContext = 8
+
+// region is a 1-based, inclusive line range
+type region struct {
+	start, end int
+}
+
+func (r region) overlaps(o region) bool {
+	return r.start <= o.end && o.start <= r.end
+}
+
+// captureAnchors checks the anchors of a new warning against the files on
+// disk. A symbol without lines is anchored to the symbol's lines, lines
+// without a symbol keep no symbol, and the anchored lines are kept as the
+// context that finds the region again.
+func (s *Server) captureAnchors(anchors []types.CodeAnchor) ([]types.CodeAnchor, error) {
+	projectRoot := filepath.Dir(s.basePath)
+	var out []types.CodeAnchor
+	for _, a := range anchors {
+		a.File = relpath.Normalize(a.File)
+		a.Symbol = strings.TrimSpace(a.Symbol)
+		a.Moved, a.Lost = false, false
+		if a.File == "" {
+			return nil, fmt.Errorf("anchor file is required")
+		}
+		if a.StartLine == 0 && a.Symbol == "" {
+			return nil, fmt.Errorf("anchor in %s needs start_line or symbol", a.File)
+		}
+		lines, err := readLines(filepath.Join(projectRoot, a.File))
+		if err != nil {
+			return nil, fmt.Errorf("anchor file %s: %w", a.File, err)
+		}
+
+		if a.StartLine == 0 {
+			r, ok := symbolRegions(filepath.Join(projectRoot, a.File), len(lines))[a.Symbol]
+			if !ok {
+				return nil, fmt.Errorf("symbol %s not found in %s", a.Symbol, a.File)
+			}
+			a.StartLine, a.EndLine = r.start, r.end
+		}
+		if a.EndLine == 0 {
+			a.EndLine = a.StartLine
+		}
+		if a.StartLine < 1 || a.EndLine < a.StartLine || a.EndLine > len(lines) {
+			return nil, fmt.Errorf("anchor lines %d-%d are outside %s (%d lines)", a.StartLine, a.EndLine, a.File, len(lines))
+		}
+		a.Context = anchorContext(lines, region{a.StartLine, a.EndLine})
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// anchorContext returns the trimmed lines that identify a region
+func anchorContext(lines []string, r region) []string {
+	end := r.end
+	if end-r.start+1 > maxAnchorContext {
+		end = r.start + maxAnchorContext - 1
+	}
+	context := make([]string, 0, end-r.start+1)
+	for _, line := range lines[r.start-1 : end] {
+		context = append(context, strings.TrimSpace(line))
+	}
+	return context
+}
+
+// anchorLocator finds anchored regions in the current files, reading and
+// parsing each file once
+type anchorLocator struct {
+	projectRoot string
+	lines       map[string][]string
+	symbols     map[string]map[string]region
+}
+
+func newAnchorLocator(projectRoot string) *anchorLocator {
+	return &anchorLocator{
+		projectRoot: projectRoot,
+		lines:       make(map[string][]string),
+		symbols:     make(map[string]map[string]region),
+	}
+}
+
+func (l *anchorLocator) fileLines(file string) []string {
+	lines, ok := l.lines[file]
+	if !ok {
+		lines, _ = readLines(filepath.Join(l.projectRoot, file))
+		l.lines[file] = lines
+	}
+	return lines
+}
+
+func (l *anchorLocator) fileSymbols(file string) map[string]region {
+	symbols, ok := l.symbols[file]
+	if !ok {
+		symbols = symbolRegions(filepath.Join(l.projectRoot, file), len(l.fileLines(file)))
+		l.symbols[file] = symbols
+	}
+	return symbols
+}
+
+// locate returns the anchor with its lines where the region is now. The
+// context is looked for first: at the anchored lines, then wherever most of
+// its lines match, nearest the old position. A symbol anchor whose lines
+// can't be recognized follows the symbol. Otherwise the anchor is lost.
+func (l *anchorLocator) locate(a types.CodeAnchor) types.CodeAnchor {
+	lines := l.fileLines(a.File)
+	if len(lines) == 0 {
+		a.Lost = true
+		return a
+	}
+	span := a.EndLine - a.StartLine
+	if start, ok := matchContext(lines, a.Context, a.StartLine); ok {
+		a.Moved = start != a.StartLine
+		a.StartLine, a.EndLine = start, start+span
+		if a.EndLine > len(lines) {
+			a.EndLine = len(lines)
+		}
+		return a
+	}
+	if a.Symbol != "" {
+		if r, ok := l.fileSymbols(a.File)[a.Symbol]; ok {
+			a.Moved = r.start != a.StartLine || r.end != a.EndLine
+			a.StartLine, a.EndLine = r.start, r.end
+			return a
+		}
+	}
+	a.Lost = true
+	return a
+}
+
+// matchContext finds where the context lines are in the file: the start
+// line at which the most of them match, at least half, nearest the old
+// start when several match as well
+func matchContext(lines, context []string, oldStart int) (int, bool) {
+	if len(context) == 0 {
+		return 0, false
+	}
+	significant := 0
+	for _, c := range context {
+		if c != "" {
+			significant++
+		}
+	}
+	if significant == 0 {
+		return 0, false
+	}
+
+	best, bestScore, bestDistance := 0, 0, 0
+	for start := 1; start <= len(lines); start++ {
+		score := 0
+		for i, c := range context {
+			if c != "" && start+i <= len(lines) && strings.TrimSpace(lines[start+i-1]) == c {
+				score++
+			}
+		}
+		distance := start - oldStart
+		if distance < 0 {
+			distance = -distance
+		}
+		if score > bestScore || (score == bestScore && score > 0 && distance < bestDistance) {
+			best, bestScore, bestDistance = start, score, distance
+		}
+	}
+	if bestScore*2 < significant {
+		return 0, false
+	}
+	return best, true
+}
+
+// symbolRegions maps the functions, classes and methods (as Class.method)
+// a file declares to their lines. Skeletons only know where declarations
+// start, so each ends where the next one begins; a class ends at the next
+// top-level declaration.
+func symbolRegions(path string, lineCount int) map[string]region {
+	regions := make(map[string]region)
+	skel, err := skeleton.ParseFile(path)
+	if err != nil {
+		return regions
+	}
+
+	var topLevel, all []int
+	for _, c := range skel.Classes {
+		topLevel = append(topLevel, c.Line)
+		if c.Constructor != nil {
+			all = append(all, c.Constructor.Line)
+		}
+		for _, m := range c.Methods {
+			all = append(all, m.Line)
+		}
+	}
+	for _, f := range skel.Functions {
+		topLevel = append(topLevel, f.Line)
+	}
+	for _, t := range append(append([]types.TypeDef{}, skel.Interfaces...), skel.Types...) {
+		topLevel = append(topLevel, t.Line)
+	}
+	for _, e := range skel.Enums {
+		topLevel = append(topLevel, e.Line)
+	}
+	all = append(all, topLevel...)
+	sort.Ints(topLevel)
+	sort.Ints(all)
+
+	nextAfter := func(starts []int, line int) int {
+		i := sort.SearchInts(starts, line+1)
+		if i == len(starts) {
+			return lineCount
+		}
+		return starts[i] - 1
+	}
+	add := func(name string, line int, starts []int) {
+		if _, ok := regions[name]; !ok && line > 0 {
+			regions[name] = region{line, nextAfter(starts, line)}
+		}
+	}
+	for _, c := range skel.Classes {
+		add(c.Name, c.Line, topLevel)
+		if c.Constructor != nil {
+			add(c.Name+".constructor", c.Constructor.Line, all)
+		}
+		for _, m := range c.Methods {
+			add(c.Name+"."+m.Name, m.Line, all)
+		}
+	}
+	for _, f := range skel.Functions {
+		add(f.Name, f.Line, all)
+	}
+	return regions
+}
+
+// targetRegions resolves the symbols get_context was asked about to their
+// lines in each target file. A file none of the symbols are in has no
+// regions, so all its warnings apply.
+func (l *anchorLocator) targetRegions(files, symbols []string) map[string][]region {
+	targets := make(map[string][]region)
+	for _, file := range files {
+		fileSymbols := l.fileSymbols(file)
+		for _, sym := range symbols {
+			for name, r := range fileSymbols {
+				// create matches UserService.create as well
+				if name == sym || strings.HasSuffix(name, "."+sym) {
+					targets[file] = append(targets[file], r)
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// inTargetRegions reports whether a warning is about the target regions.
+// In a file with target regions, a warning counts when one of its anchors
+// there overlaps a region or is lost, or when it names the file without
+// anchoring it. A warning with nothing in those files always counts.
+func inTargetRegions(w types.Warning, targets map[string][]region) bool {
+	anchored := make(map[string]bool)
+	constrained := false
+	for _, a := range w.Anchors {
+		anchored[a.File] = true
+		regions, ok := targets[a.File]
+		if !ok {
+			continue
+		}
+		constrained = true
+		if a.Lost {
+			return true
+		}
+		for _, r := range regions {
+			if r.overlaps(region{a.StartLine, a.EndLine}) {
+				return true
+			}
+		}
+	}
+	for _, file := range w.RelatedFiles {
+		if _, ok := targets[file]; ok && !anchored[file] {
+			return true
+		}
+	}
+	return !constrained
+}
+
+// locateAll returns a copy of the anchors with their current lines
+func (l *anchorLocator) locateAll(anchors []types.CodeAnchor) []types.CodeAnchor {
+	if len(anchors) == 0 {
+		return anchors
+	}
+	located := make([]types.CodeAnchor, len(anchors))
+	for i, a := range anchors {
+		located[i] = l.locate(a)
+	}
+	return located
+}
+
+func readLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
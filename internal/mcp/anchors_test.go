@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+const anchoredService = `export class PaymentService {
+  charge(amount: number) {
+    return this.gateway.charge(amount);
+  }
+
+  refund(id: string) {
+    // the gateway refunds twice when retried
+    return this.gateway.refund(id);
+  }
+}
+`
+
+func TestAnchoredWarnings(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "src/pay.ts", anchoredService)
+
+	mustCall(t, s, "add_warning", map[string]interface{}{
+		"content": "Never retry refunds",
+		"reason":  "the gateway is not idempotent",
+		"anchors": []map[string]interface{}{{"file": "src/pay.ts", "symbol": "PaymentService.refund"}},
+	})
+	mustCall(t, s, "add_warning", map[string]interface{}{
+		"content":       "Amounts are in cents",
+		"reason":        "the gateway takes integers",
+		"related_files": []string{"src/pay.ts"},
+	})
+	if _, err := callTool(t, s, "add_warning", map[string]interface{}{
+		"content": "x", "reason": "y",
+		"anchors": []map[string]interface{}{{"file": "src/pay.ts", "symbol": "PaymentService.void"}},
+	}); err == nil {
+		t.Error("an anchor to a missing symbol should be rejected")
+	}
+
+	warnings, _ := s.jsonStore.GetWarnings()
+	anchor := warnings[0].Anchors[0]
+	if anchor.StartLine != 6 || anchor.EndLine != 10 || len(anchor.Context) != 5 {
+		t.Errorf("anchor = %+v, want lines 6-10 with their context", anchor)
+	}
+	if len(warnings[0].RelatedFiles) != 1 || warnings[0].RelatedFiles[0] != "src/pay.ts" {
+		t.Errorf("related files = %v, want the anchored file", warnings[0].RelatedFiles)
+	}
+
+	contents := func(symbols ...string) []string {
+		result := mustCall(t, s, "get_context", map[string]interface{}{
+			"intent":         "change payments",
+			"target_files":   []string{"src/pay.ts"},
+			"target_symbols": symbols,
+		}).(*types.ContextResponse)
+		var out []string
+		for _, w := range result.Warnings {
+			out = append(out, w.Content)
+		}
+		return out
+	}
+	if got := contents("charge"); strings.Join(got, "|") != "Amounts are in cents" {
+		t.Errorf("warnings for charge = %v, want only the file-wide one", got)
+	}
+	if got := contents("refund"); len(got) != 2 {
+		t.Errorf("warnings for refund = %v, want both", got)
+	}
+	if got := contents(); len(got) != 2 {
+		t.Errorf("warnings for the whole file = %v, want both", got)
+	}
+
+	// Code added above the refund moves it; the anchor follows
+	writeProjectFile(t, s, "src/pay.ts", "import { Gateway } from './gateway';\n\n"+
+		strings.Replace(anchoredService, "  refund(id: string) {", "  capture(id: string) {\n    return this.gateway.capture(id);\n  }\n\n  refund(id: string) {", 1))
+	result := mustCall(t, s, "get_context", map[string]interface{}{
+		"intent":         "change payments",
+		"target_files":   []string{"src/pay.ts"},
+		"target_symbols": []string{"capture"},
+	}).(*types.ContextResponse)
+	for _, w := range result.Warnings {
+		if w.Content == "Never retry refunds" {
+			t.Errorf("the refund warning should not apply to capture: %+v", w.Anchors)
+		}
+	}
+	result = mustCall(t, s, "get_context", map[string]interface{}{
+		"intent":         "change payments",
+		"target_files":   []string{"src/pay.ts"},
+		"target_symbols": []string{"refund"},
+	}).(*types.ContextResponse)
+	var moved *types.CodeAnchor
+	for _, w := range result.Warnings {
+		if len(w.Anchors) > 0 {
+			moved = &w.Anchors[0]
+		}
+	}
+	if moved == nil || !moved.Moved || moved.StartLine != 12 || moved.EndLine != 16 {
+		t.Errorf("moved anchor = %+v, want lines 12-16", moved)
+	}
+}
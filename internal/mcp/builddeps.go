@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/buildgraph"
+)
+
+// buildDeps indexes the Bazel/Pants edges of the knowledge graph. When a
+// file is owned by a build target, its declared deps are authoritative and
+// replace scanned imports for dependency and impact queries.
+type buildDeps struct {
+	owners map[string][]string // file -> targets listing it in srcs
+	files  map[string][]string // target -> owned files
+	deps   map[string][]string // target -> targets it depends on
+	rdeps  map[string][]string // target -> targets depending on it
+}
+
+type buildNeighbor struct {
+	path   string
+	target string
+}
+
+// loadBuildDeps returns nil when the project has no ingested build graph
+func (s *Server) loadBuildDeps() *buildDeps {
+	graph, err := s.jsonStore.GetKnowledgeGraph()
+	if err != nil {
+		return nil
+	}
+
+	b := &buildDeps{
+		owners: make(map[string][]string),
+		files:  make(map[string][]string),
+		deps:   make(map[string][]string),
+		rdeps:  make(map[string][]string),
+	}
+	found := false
+	for _, e := range graph.Edges {
+		switch e.Relation {
+		case buildgraph.RelationBuiltBy:
+			b.owners[e.FromID] = append(b.owners[e.FromID], e.ToID)
+			b.files[e.ToID] = append(b.files[e.ToID], e.FromID)
+			found = true
+		case buildgraph.RelationDependsOn:
+			b.deps[e.FromID] = append(b.deps[e.FromID], e.ToID)
+			b.rdeps[e.ToID] = append(b.rdeps[e.ToID], e.FromID)
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return b
+}
+
+// owns reports whether file belongs to a build target
+func (b *buildDeps) owns(file string) bool {
+	return b != nil && len(b.owners[file]) > 0
+}
+
+// targetsOf returns the labels of the targets owning file
+func (b *buildDeps) targetsOf(file string) []string {
+	if b == nil {
+		return nil
+	}
+	return b.owners[file]
+}
+
+// neighbors returns the files of the targets the file's targets depend on
+// (upstream) or that depend on them (downstream), each with the target that
+// links it.
+func (b *buildDeps) neighbors(file string, upstream bool) []buildNeighbor {
+	edges := b.rdeps
+	if upstream {
+		edges = b.deps
+	}
+
+	seen := map[string]bool{file: true}
+	var out []buildNeighbor
+	for _, owner := range b.owners[file] {
+		targets := append([]string(nil), edges[owner]...)
+		sort.Strings(targets)
+		for _, t := range targets {
+			files := append([]string(nil), b.files[t]...)
+			sort.Strings(files)
+			for _, f := range files {
+				if seen[f] {
+					continue
+				}
+				seen[f] = true
+				out = append(out, buildNeighbor{path: f, target: t})
+			}
+		}
+	}
+	return out
+}
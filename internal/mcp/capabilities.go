@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Capability status values reported by get_capabilities
+const (
+	CapabilityAvailable = "available"
+	CapabilityDegraded  = "degraded"
+	CapabilityDisabled  = "disabled"
+)
+
+// EnvironmentCapabilities records what the current environment can provide.
+// It is detected once at startup so tools can fail fast instead of mid-call.
+type EnvironmentCapabilities struct {
+	DetectedAt     time.Time `json:"detected_at"`
+	GitBinary      bool      `json:"git_binary"`
+	GitRepository  bool      `json:"git_repository"`
+	Ripgrep        bool      `json:"ripgrep"`
+	GitKnowledge   bool      `json:"git_knowledge"`   // pre-computed git-*.json reports exist
+	TreeIndex      bool      `json:"tree_index"`      // tree.yaml generated by the indexer
+	SkeletonParser string    `json:"skeleton_parser"` // "tree-sitter" or "regex"
+	IssueTracker   bool      `json:"issue_tracker"`   // link_issue and linked tickets call the tracker's API
+}
+
+// ToolCapability is the availability of a single tool in this environment
+type ToolCapability struct {
+	Tool   string `json:"tool"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// toolRequirement describes how a tool depends on the environment.
+// Tools that need a requirement are disabled without it; tools that merely
+// benefit from it are degraded.
+type toolRequirement struct {
+	needsGit       bool // live git commands, no cache fallback
+	gitCacheOrLive bool // reads git-*.json, falls back to live git
+	needsTree      bool // reads tree.yaml
+	prefersRipgrep bool // falls back to grep without ripgrep
+}
+
+var toolRequirements = map[string]toolRequirement{
+	"search_code":           {prefersRipgrep: true},
+	"get_tree":              {needsTree: true},
+	"get_recent_changes":    {needsGit: true},
+	"get_file_history":      {needsGit: true},
+	"get_commit_context":    {needsGit: true},
+	"get_skeleton_diff":     {needsGit: true},
+	"find_experts":          {gitCacheOrLive: true},
+	"get_knowledge_risks":   {gitCacheOrLive: true},
+	"get_file_correlations": {gitCacheOrLive: true},
+	"query":                 {gitCacheOrLive: true},
+	"onboard":               {gitCacheOrLive: true},
+}
+
+// detectCapabilities probes the environment for the optional dependencies
+// that individual tools rely on. The only network access is to a configured
+// issue tracker; without one, or when it can't be reached, every tool still
+// works from local data.
+func detectCapabilities(basePath string, issueCfg types.IssueConfig) *EnvironmentCapabilities {
+	projectRoot := filepath.Dir(basePath)
+	caps := &EnvironmentCapabilities{
+		DetectedAt:     time.Now(),
+		Ripgrep:        search.CheckRipgrep(),
+		SkeletonParser: skeleton.ParserName(),
+		IssueTracker:   issueCfg.Provider != "" || issueCfg.BaseURL != "" || issueCfg.Repo != "",
+	}
+
+	if _, err := exec.LookPath("git"); err == nil {
+		caps.GitBinary = true
+		cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+		cmd.Dir = projectRoot
+		if out, err := cmd.Output(); err == nil && len(out) > 0 {
+			caps.GitRepository = true
+		}
+	}
+
+	caps.refreshIndexState(basePath)
+	return caps
+}
+
+// refreshIndexState re-checks the generated artifacts, which can appear after
+// startup (e.g. 'teamcontext index' running while the server is up). Binaries
+// and repository state are only probed once.
+func (c *EnvironmentCapabilities) refreshIndexState(basePath string) {
+	_, err := os.Stat(filepath.Join(basePath, "knowledge", "git-experts.json"))
+	c.GitKnowledge = err == nil
+	_, err = os.Stat(filepath.Join(basePath, "tree.yaml"))
+	c.TreeIndex = err == nil
+}
+
+// toolStatus evaluates a single tool against the detected capabilities
+func (c *EnvironmentCapabilities) toolStatus(name string) ToolCapability {
+	req, ok := toolRequirements[name]
+	if !ok {
+		return ToolCapability{Tool: name, Status: CapabilityAvailable}
+	}
+
+	gitUsable := c.GitBinary && c.GitRepository
+
+	switch {
+	case req.needsGit && !gitUsable:
+		return ToolCapability{Tool: name, Status: CapabilityDisabled, Reason: c.gitReason()}
+	case req.gitCacheOrLive && !c.GitKnowledge && !gitUsable:
+		if name == "query" || name == "onboard" {
+			return ToolCapability{Tool: name, Status: CapabilityDegraded, Reason: "no git knowledge cache and " + c.gitReason() + "; expert data omitted"}
+		}
+		return ToolCapability{Tool: name, Status: CapabilityDisabled, Reason: "no git knowledge cache and " + c.gitReason()}
+	case req.gitCacheOrLive && !c.GitKnowledge:
+		return ToolCapability{Tool: name, Status: CapabilityDegraded, Reason: "git knowledge cache missing; falling back to slower live git analysis (run 'teamcontext init' or 'teamcontext index')"}
+	case req.needsTree && !c.TreeIndex:
+		return ToolCapability{Tool: name, Status: CapabilityDisabled, Reason: "tree.yaml not generated; run 'teamcontext index' first"}
+	case req.prefersRipgrep && !c.Ripgrep:
+		return ToolCapability{Tool: name, Status: CapabilityDegraded, Reason: "ripgrep (rg) not installed; using slower grep fallback with a fixed set of extensions"}
+	}
+
+	return ToolCapability{Tool: name, Status: CapabilityAvailable}
+}
+
+func (c *EnvironmentCapabilities) gitReason() string {
+	if !c.GitBinary {
+		return "git binary not found in PATH"
+	}
+	return "project is not a git repository"
+}
+
+// disabledReason returns a non-empty reason when the tool cannot work in
+// this environment, so calls can be rejected up front.
+func (s *Server) disabledReason(name string) string {
+	if s.capabilities == nil {
+		return ""
+	}
+	if _, ok := toolRequirements[name]; !ok {
+		return ""
+	}
+	s.capabilities.refreshIndexState(s.basePath)
+	tc := s.capabilities.toolStatus(name)
+	if tc.Status == CapabilityDisabled {
+		return tc.Reason
+	}
+	return ""
+}
+
+// handleGetCapabilities reports which tools are fully functional, degraded,
+// or disabled in the current environment.
+func (s *Server) handleGetCapabilities(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Refresh bool `json:"refresh"`
+	}
+	json.Unmarshal(params, &p)
+
+	if p.Refresh || s.capabilities == nil {
+		s.capabilities = detectCapabilities(s.basePath, s.issueConfig())
+	} else {
+		s.capabilities.refreshIndexState(s.basePath)
+	}
+
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var degraded, disabled []ToolCapability
+	available := 0
+	for _, name := range names {
+		tc := s.capabilities.toolStatus(name)
+		switch tc.Status {
+		case CapabilityDisabled:
+			disabled = append(disabled, tc)
+		case CapabilityDegraded:
+			degraded = append(degraded, tc)
+		default:
+			available++
+		}
+	}
+
+	return map[string]interface{}{
+		"environment":     s.capabilities,
+		"offline_capable": true,
+		"available_count": available,
+		"degraded":        degraded,
+		"disabled":        disabled,
+		"summary":         fmt.Sprintf("%d tools available, %d degraded, %d disabled", available, len(degraded), len(disabled)),
+	}, nil
+}
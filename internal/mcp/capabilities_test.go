@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestToolStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		caps   EnvironmentCapabilities
+		tool   string
+		status string
+		reason string
+	}{
+		{"unlisted tool", EnvironmentCapabilities{}, "get_project", CapabilityAvailable, ""},
+		{"live git without binary", EnvironmentCapabilities{}, "get_file_history", CapabilityDisabled, "git binary not found"},
+		{"live git outside a repo", EnvironmentCapabilities{GitBinary: true}, "get_file_history", CapabilityDisabled, "not a git repository"},
+		{"live git", EnvironmentCapabilities{GitBinary: true, GitRepository: true}, "get_file_history", CapabilityAvailable, ""},
+		{"skeleton diff outside a repo", EnvironmentCapabilities{GitBinary: true}, "get_skeleton_diff", CapabilityDisabled, "not a git repository"},
+		{"git cache missing, live git works", EnvironmentCapabilities{GitBinary: true, GitRepository: true}, "find_experts", CapabilityDegraded, "falling back"},
+		{"no git at all", EnvironmentCapabilities{}, "find_experts", CapabilityDisabled, "no git knowledge cache"},
+		{"query without git data", EnvironmentCapabilities{}, "query", CapabilityDegraded, "expert data omitted"},
+		{"git cache only", EnvironmentCapabilities{GitKnowledge: true}, "find_experts", CapabilityAvailable, ""},
+		{"no tree", EnvironmentCapabilities{}, "get_tree", CapabilityDisabled, "tree.yaml"},
+		{"no ripgrep", EnvironmentCapabilities{}, "search_code", CapabilityDegraded, "ripgrep"},
+		{"ripgrep", EnvironmentCapabilities{Ripgrep: true}, "search_code", CapabilityAvailable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.caps.toolStatus(tt.tool)
+			if got.Status != tt.status {
+				t.Errorf("status = %s, want %s (%s)", got.Status, tt.status, got.Reason)
+			}
+			if !strings.Contains(got.Reason, tt.reason) {
+				t.Errorf("reason = %q, want it to mention %q", got.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestDetectCapabilitiesIssueTracker(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), ".teamcontext")
+
+	if detectCapabilities(basePath, types.IssueConfig{}).IssueTracker {
+		t.Error("IssueTracker should be false without issue config")
+	}
+	if !detectCapabilities(basePath, types.IssueConfig{Provider: "jira", BaseURL: "https://acme.atlassian.net"}).IssueTracker {
+		t.Error("IssueTracker should be true with a Jira site configured")
+	}
+	if !detectCapabilities(basePath, types.IssueConfig{Repo: "acme/api"}).IssueTracker {
+		t.Error("IssueTracker should be true with a GitHub repo configured")
+	}
+}
+
+func TestRefreshIndexState(t *testing.T) {
+	s := setupTestServer(t)
+	if s.disabledReason("get_tree") == "" {
+		t.Fatal("get_tree should be disabled before tree.yaml exists")
+	}
+	if err := os.WriteFile(filepath.Join(s.basePath, "tree.yaml"), []byte("root: .\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if reason := s.disabledReason("get_tree"); reason != "" {
+		t.Errorf("get_tree still disabled after tree.yaml was written: %s", reason)
+	}
+}
+
+func TestHandleGetCapabilities(t *testing.T) {
+	s := setupTestServer(t)
+	m := resultMap(t, mustCall(t, s, "get_capabilities", map[string]interface{}{"refresh": true}))
+	env, ok := m["environment"].(*EnvironmentCapabilities)
+	if !ok {
+		t.Fatalf("environment is %T", m["environment"])
+	}
+	if env.IssueTracker || env.SkeletonParser == "" {
+		t.Errorf("environment = %+v", env)
+	}
+	if m["available_count"].(int) == 0 {
+		t.Error("no tools reported available")
+	}
+}
@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// DESTRUCTIVE OPERATIONS
+// Tools that archive, delete or overwrite knowledge take dry_run=true and
+// return the exact changes instead of making them. On a shared server
+// (server.require_confirmation in config.json) they only run with the
+// confirm_token that a dry run of the same call returned, so nobody's
+// knowledge disappears on an agent's whim.
+// =============================================================================
+
+// confirmTokenTTL is how long a dry run's confirm token stays valid
+const confirmTokenTTL = 10 * time.Minute
+
+// plannedChange is one change a destructive tool makes, reported by its dry
+// run
+type plannedChange struct {
+	Action string      `json:"action"` // create, update, delete, archive
+	Kind   string      `json:"kind"`   // feature, conversation, insight, project, architecture, evolution_event
+	ID     string      `json:"id,omitempty"`
+	Field  string      `json:"field,omitempty"` // for updates
+	Detail string      `json:"detail,omitempty"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// pendingConfirmation is a dry run waiting to be confirmed
+type pendingConfirmation struct {
+	tool       string
+	paramsHash string
+	expires    time.Time
+}
+
+// confirmationStore holds the confirm tokens issued by dry runs
+type confirmationStore struct {
+	mu      sync.Mutex
+	pending map[string]pendingConfirmation
+}
+
+// issue returns a token confirming this exact call
+func (c *confirmationStore) issue(tool, paramsHash string) (string, time.Time, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(b)
+	expires := time.Now().Add(confirmTokenTTL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[string]pendingConfirmation)
+	}
+	for t, p := range c.pending {
+		if time.Now().After(p.expires) {
+			delete(c.pending, t)
+		}
+	}
+	c.pending[token] = pendingConfirmation{tool: tool, paramsHash: paramsHash, expires: expires}
+	return token, expires, nil
+}
+
+// check reports why a token does not confirm a call, or nil if it does
+func (c *confirmationStore) check(token, tool, paramsHash string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[token]
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown or already used confirm_token: run %s with dry_run=true again", tool)
+	case time.Now().After(p.expires):
+		delete(c.pending, token)
+		return fmt.Errorf("confirm_token expired: run %s with dry_run=true again", tool)
+	case p.tool != tool || p.paramsHash != paramsHash:
+		return fmt.Errorf("confirm_token was issued for a different call: repeat the dry run's arguments exactly, or dry-run these")
+	}
+	return nil
+}
+
+// redeem uses a token up; each confirms one call
+func (c *confirmationStore) redeem(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, token)
+}
+
+// requireConfirmation reports whether destructive tools need a confirm token
+func (s *Server) requireConfirmation() bool {
+	cfg, err := s.jsonStore.GetConfig()
+	return err == nil && cfg.Server.RequireConfirmation
+}
+
+// destructive wraps the handler of a tool that archives, deletes or
+// overwrites knowledge. The handler itself must honor dry_run and write
+// nothing when it is set; the wrapper adds the confirm token flow.
+func (s *Server) destructive(tool string, handler ToolHandler) ToolHandler {
+	return func(params json.RawMessage) (interface{}, error) {
+		var p struct {
+			DryRun       bool   `json:"dry_run"`
+			ConfirmToken string `json:"confirm_token"`
+		}
+		json.Unmarshal(params, &p)
+		required := s.requireConfirmation()
+		hash := confirmParamsHash(params)
+
+		if p.DryRun {
+			result, err := handler(params)
+			if err != nil || !required {
+				return result, err
+			}
+			token, expires, err := s.confirmations.issue(tool, hash)
+			if err != nil {
+				return nil, err
+			}
+			if m, ok := result.(map[string]interface{}); ok {
+				m["confirm_token"] = token
+				m["confirm_expires_at"] = expires
+			}
+			return result, nil
+		}
+
+		if required {
+			if p.ConfirmToken == "" {
+				return nil, fmt.Errorf("%s needs confirmation on this server: call it with dry_run=true, review the changes, then repeat the call with the confirm_token it returns", tool)
+			}
+			if err := s.confirmations.check(p.ConfirmToken, tool, hash); err != nil {
+				return nil, err
+			}
+		}
+		result, err := handler(params)
+		if err == nil && required {
+			s.confirmations.redeem(p.ConfirmToken)
+		}
+		return result, err
+	}
+}
+
+// confirmParamsHash identifies a call by its arguments, without dry_run and
+// confirm_token, independent of key order
+func confirmParamsHash(params json.RawMessage) string {
+	var args map[string]interface{}
+	json.Unmarshal(params, &args)
+	delete(args, "dry_run")
+	delete(args, "confirm_token")
+	// encoding/json sorts map keys, so equal arguments marshal equally
+	canonical, _ := json.Marshal(args)
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// dryRunResult is the response of a dry run
+func dryRunResult(changes []plannedChange) map[string]interface{} {
+	return map[string]interface{}{
+		"dry_run": true,
+		"changes": changes,
+	}
+}
+
+// fieldChanges lists the top-level fields that differ between two versions
+// of a record, sorted by field name
+func fieldChanges(kind, id string, before, after interface{}) []plannedChange {
+	var b, a map[string]interface{}
+	if raw, err := json.Marshal(before); err == nil {
+		json.Unmarshal(raw, &b)
+	}
+	if raw, err := json.Marshal(after); err == nil {
+		json.Unmarshal(raw, &a)
+	}
+	fields := make([]string, 0, len(a)+len(b))
+	for f := range mergeFieldKeys(a, b) {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	var changes []plannedChange
+	for _, f := range fields {
+		if f == "updated_at" || reflect.DeepEqual(b[f], a[f]) {
+			continue
+		}
+		changes = append(changes, plannedChange{Action: "update", Kind: kind, ID: id, Field: f, Before: b[f], After: a[f]})
+	}
+	return changes
+}
+
+func mergeFieldKeys(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// requireConfirmation turns on confirm tokens for destructive tools
+func requireConfirmation(t *testing.T, s *Server) {
+	t.Helper()
+	if err := s.jsonStore.SaveConfig(&types.Config{Server: types.ServerConfig{RequireConfirmation: true}}); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+}
+
+func projectName(t *testing.T, s *Server) string {
+	t.Helper()
+	project, _ := s.jsonStore.GetProject()
+	if project == nil {
+		return ""
+	}
+	return project.Name
+}
+
+// dryRunToken dry-runs update_project and returns its confirm token
+func dryRunToken(t *testing.T, s *Server, params map[string]interface{}) string {
+	t.Helper()
+	args := map[string]interface{}{"dry_run": true}
+	for k, v := range params {
+		args[k] = v
+	}
+	result := resultMap(t, mustCall(t, s, "update_project", args))
+	token, _ := result["confirm_token"].(string)
+	if token == "" {
+		t.Fatalf("dry run returned no confirm_token: %+v", result)
+	}
+	return token
+}
+
+func TestDryRunChangesNothing(t *testing.T) {
+	s := setupTestServer(t)
+	mustCall(t, s, "update_project", map[string]interface{}{"name": "shop", "description": "storefront"})
+
+	result := resultMap(t, mustCall(t, s, "update_project", map[string]interface{}{"name": "store", "dry_run": true}))
+	changes := result["changes"].([]plannedChange)
+	if len(changes) != 1 || changes[0].Field != "name" || changes[0].Before != "shop" || changes[0].After != "store" {
+		t.Errorf("changes = %+v, want name shop -> store only", changes)
+	}
+	if _, ok := result["confirm_token"]; ok {
+		t.Error("confirm_token issued although confirmation is not required")
+	}
+	if got := projectName(t, s); got != "shop" {
+		t.Errorf("dry run wrote the project: name = %q", got)
+	}
+
+	// Without require_confirmation the call runs directly
+	mustCall(t, s, "update_project", map[string]interface{}{"name": "store"})
+	if got := projectName(t, s); got != "store" {
+		t.Errorf("name = %q, want store", got)
+	}
+}
+
+func TestConfirmTokenFlow(t *testing.T) {
+	s := setupTestServer(t)
+	requireConfirmation(t, s)
+
+	// Missing token
+	_, err := callTool(t, s, "update_project", map[string]interface{}{"name": "shop"})
+	if err == nil || !strings.Contains(err.Error(), "dry_run=true") {
+		t.Fatalf("call without a token: err = %v, want a request to dry-run first", err)
+	}
+
+	// Token mismatch: a token nobody issued
+	if _, err := callTool(t, s, "update_project", map[string]interface{}{"name": "shop", "confirm_token": "deadbeef"}); err == nil {
+		t.Error("unknown token accepted")
+	}
+
+	// Changed params: the token confirms only the call that was dry-run
+	token := dryRunToken(t, s, map[string]interface{}{"name": "shop", "description": "storefront"})
+	if _, err := callTool(t, s, "update_project", map[string]interface{}{"name": "other", "description": "storefront", "confirm_token": token}); err == nil {
+		t.Error("token accepted for different arguments")
+	}
+	if _, err := callTool(t, s, "update_architecture", map[string]interface{}{"name": "shop", "description": "storefront", "confirm_token": token}); err == nil {
+		t.Error("token accepted for a different tool")
+	}
+	if got := projectName(t, s); got != "" {
+		t.Fatalf("rejected calls wrote the project: name = %q", got)
+	}
+
+	// The matching call runs, with its arguments in any order, once
+	raw := json.RawMessage(`{"confirm_token": "` + token + `", "description": "storefront", "name": "shop"}`)
+	if _, err := s.HandleToolCall("update_project", raw); err != nil {
+		t.Fatalf("confirmed call failed: %v", err)
+	}
+	if got := projectName(t, s); got != "shop" {
+		t.Errorf("name = %q, want shop", got)
+	}
+	if _, err := s.HandleToolCall("update_project", raw); err == nil {
+		t.Error("token accepted twice")
+	}
+}
+
+func TestConfirmTokenExpires(t *testing.T) {
+	s := setupTestServer(t)
+	requireConfirmation(t, s)
+	token := dryRunToken(t, s, map[string]interface{}{"name": "shop"})
+
+	s.confirmations.mu.Lock()
+	p := s.confirmations.pending[token]
+	p.expires = time.Now().Add(-time.Second)
+	s.confirmations.pending[token] = p
+	s.confirmations.mu.Unlock()
+
+	_, err := callTool(t, s, "update_project", map[string]interface{}{"name": "shop", "confirm_token": token})
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expired token: err = %v", err)
+	}
+}
+
+func TestFailedDryRunIssuesNoToken(t *testing.T) {
+	s := setupTestServer(t)
+	requireConfirmation(t, s)
+
+	// A dry run of a failing call issues no token
+	if _, err := callTool(t, s, "archive_feature", map[string]interface{}{"id": "missing", "dry_run": true}); err == nil {
+		t.Fatal("dry run of a missing feature succeeded")
+	}
+	if len(s.confirmations.pending) != 0 {
+		t.Errorf("failed dry run issued %d tokens", len(s.confirmations.pending))
+	}
+}
+
+func TestConfirmParamsHash(t *testing.T) {
+	a := confirmParamsHash(json.RawMessage(`{"name": "shop", "languages": ["go"], "dry_run": true}`))
+	b := confirmParamsHash(json.RawMessage(`{"languages": ["go"], "confirm_token": "abc", "name": "shop"}`))
+	c := confirmParamsHash(json.RawMessage(`{"name": "shop", "languages": ["go", "ts"]}`))
+	if a != b {
+		t.Error("hash depends on key order, dry_run or confirm_token")
+	}
+	if a == c {
+		t.Error("hash ignores a changed argument")
+	}
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// CROSS-SOURCE DEDUPLICATION
+// query, search and get_context find knowledge through several paths
+// (keyword, semantic, graph, docs). An item found by more than one path is
+// listed once, with a relevance that reflects every path that found it.
+// =============================================================================
+
+// mergeRelevance combines two scores for the same item: the best one,
+// raised by a quarter of the other, so an item matched by keyword and
+// semantic search outranks one matched by either alone
+func mergeRelevance(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + b/4
+}
+
+// relevanceSet collects the hits of one search across its sources
+type relevanceSet struct {
+	hits       map[string]*types.Source // by type + ID
+	order      []string                 // keys in the order first found
+	duplicates int                      // hits merged into an earlier one
+	weights    map[string]float64       // search.weights: score multiplier per source
+}
+
+func newRelevanceSet() *relevanceSet {
+	return &relevanceSet{hits: make(map[string]*types.Source)}
+}
+
+// add records that source found the item with the given score, scaled by
+// the source's weight
+func (r *relevanceSet) add(docType, id, source string, score float64) {
+	if w, ok := r.weights[source]; ok && w >= 0 {
+		score *= w
+	}
+	key := docType + "\x00" + id
+	hit, ok := r.hits[key]
+	if !ok {
+		r.hits[key] = &types.Source{Type: docType, ID: id, Relevance: score, MatchedBy: []string{source}}
+		r.order = append(r.order, key)
+		return
+	}
+	r.duplicates++
+	hit.Relevance = mergeRelevance(hit.Relevance, score)
+	hit.MatchedBy = appendUnique(hit.MatchedBy, source)
+}
+
+// has reports whether any source found the item
+func (r *relevanceSet) has(docType, id string) bool {
+	_, ok := r.hits[docType+"\x00"+id]
+	return ok
+}
+
+// remove drops an item, e.g. a file already covered by a more specific hit
+func (r *relevanceSet) remove(docType, id string) {
+	key := docType + "\x00" + id
+	if _, ok := r.hits[key]; !ok {
+		return
+	}
+	delete(r.hits, key)
+	for i, k := range r.order {
+		if k == key {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// rescore applies relevance feedback to the merged score of every item
+func (r *relevanceSet) rescore(model *search.FeedbackModel, query string) {
+	for _, hit := range r.hits {
+		score := model.Rescore(hit.Type, hit.ID, query, hit.Relevance)
+		switch {
+		case score > hit.Relevance:
+			r.explain(hit.Type, hit.ID, "ranked up by feedback on similar searches")
+		case score < hit.Relevance:
+			r.explain(hit.Type, hit.ID, "ranked down by feedback on similar searches")
+		}
+		hit.Relevance = score
+	}
+}
+
+// ranked returns the IDs of one type by merged relevance, ties in the
+// order they were found
+func (r *relevanceSet) ranked(docType string) []string {
+	var hits []*types.Source
+	for _, key := range r.order {
+		if hit := r.hits[key]; hit.Type == docType {
+			hits = append(hits, hit)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Relevance > hits[j].Relevance })
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+// sources returns the given items with their merged relevance, capped at 1
+// and rounded for the response
+func (r *relevanceSet) sources(docType string, ids []string) []types.Source {
+	result := make([]types.Source, 0, len(ids))
+	for _, id := range ids {
+		if hit, ok := r.hits[docType+"\x00"+id]; ok {
+			src := *hit
+			src.Relevance = math.Round(math.Min(src.Relevance, 1)*100) / 100
+			result = append(result, src)
+		}
+	}
+	return result
+}
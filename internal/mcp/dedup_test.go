@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestMergeRelevance(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{0.8, 0.4, 0.9},
+		{0.4, 0.8, 0.9},
+		{0.5, 0, 0.5},
+		{0.9, 0.9, 1.125},
+	}
+	for _, tt := range tests {
+		if got := mergeRelevance(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("mergeRelevance(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRelevanceSet(t *testing.T) {
+	hits := newRelevanceSet()
+	hits.add("decision", "single", "keyword", 0.6)
+	hits.add("decision", "both", "keyword", 0.5)
+	hits.add("decision", "both", "semantic", 0.4)
+	hits.add("decision", "both", "semantic", 0.2)
+	hits.add("decision", "tie", "graph", 0.6)
+	hits.add("file", "both", "keyword", 0.9)
+	hits.add("file", "doc.md", "keyword", 0.3)
+
+	if hits.duplicates != 2 {
+		t.Errorf("duplicates = %d, want 2", hits.duplicates)
+	}
+	// 0.5 + 0.4/4 = 0.6, then + 0.2/4 = 0.65: above both single-path hits,
+	// which keep the order they were found in
+	if got, want := hits.ranked("decision"), []string{"both", "single", "tie"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ranked decisions = %v, want %v", got, want)
+	}
+
+	src := hits.sources("decision", []string{"both", "missing"})
+	if len(src) != 1 || src[0].Relevance != 0.65 || !reflect.DeepEqual(src[0].MatchedBy, []string{"keyword", "semantic"}) {
+		t.Errorf("sources = %+v, want one hit at 0.65 matched by keyword and semantic", src)
+	}
+	if !hits.has("file", "both") || hits.has("warning", "both") {
+		t.Error("hits are not keyed by type and ID")
+	}
+
+	hits.remove("file", "doc.md")
+	hits.remove("file", "never-added")
+	if got := hits.ranked("file"); !reflect.DeepEqual(got, []string{"both"}) {
+		t.Errorf("ranked files after remove = %v", got)
+	}
+
+	hits.add("file", "capped", "keyword", 0.95)
+	hits.add("file", "capped", "semantic", 0.95)
+	if src := hits.sources("file", []string{"capped"}); src[0].Relevance != 1 {
+		t.Errorf("merged relevance = %v, want capped at 1", src[0].Relevance)
+	}
+}
+
+// semanticServer is a test server with a TF-IDF vocabulary, so knowledge
+// added through tools is found by keyword and semantic search
+func semanticServer(t *testing.T) *Server {
+	t.Helper()
+	s := setupTestServer(t)
+	engine := search.NewTFIDFEngine()
+	engine.BuildVocabulary([]string{
+		"retry payments with exponential backoff", "retry payments backoff",
+		"cache invoices for an hour", "cache invoices hour",
+	})
+	if err := s.sqliteIndex.StoreVocab(engine); err != nil {
+		t.Fatalf("StoreVocab: %v", err)
+	}
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Retry payments with exponential backoff", "reason": "provider rate limits"})
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Cache invoices for an hour", "reason": "slow provider"})
+	return s
+}
+
+func TestRelevanceSetWeights(t *testing.T) {
+	hits := newRelevanceSet()
+	hits.weights = map[string]float64{"semantic": 2, "keyword": 0.5}
+	hits.add("decision", "kw", "keyword", 0.8)
+	hits.add("decision", "sem", "semantic", 0.3)
+	hits.add("decision", "graph", "graph", 0.5)
+
+	if got := hits.ranked("decision"); !reflect.DeepEqual(got, []string{"sem", "graph", "kw"}) {
+		t.Errorf("ranked = %v, want semantic hits first and keyword hits last", got)
+	}
+}
+
+func TestQueryListsEachHitOnce(t *testing.T) {
+	s := semanticServer(t)
+	result := mustCall(t, s, "query", map[string]interface{}{"question": "retry payments backoff"}).(*types.QueryResponse)
+
+	if len(result.Decisions) != 1 || result.Merged != 1 {
+		t.Fatalf("decisions = %d, duplicates_merged = %d; want the decision once, merged once", len(result.Decisions), result.Merged)
+	}
+	src := result.Sources[0]
+	if !reflect.DeepEqual(src.MatchedBy, []string{"keyword", "semantic"}) || src.Relevance <= 0.5 {
+		t.Errorf("source = %+v, want matched by keyword and semantic with a relevance above the keyword score", src)
+	}
+}
+
+func TestGetContextListsEachHitOnce(t *testing.T) {
+	s := semanticServer(t)
+	result := mustCall(t, s, "get_context", map[string]interface{}{"intent": "retry payments backoff"}).(*types.ContextResponse)
+	if len(result.Decisions) != 1 || result.Merged != 1 {
+		t.Errorf("decisions = %d, duplicates_merged = %d; want the decision once, merged once", len(result.Decisions), result.Merged)
+	}
+}
+
+func TestSearchDropsFilesCitedByDocs(t *testing.T) {
+	s := setupTestServer(t)
+	for _, f := range []*types.FileIndex{
+		{Path: "README.md", Language: "markdown", Summary: "payments overview"},
+		{Path: "src/payments.go", Language: "go", Summary: "payments client"},
+	} {
+		if err := s.sqliteIndex.IndexFile(f); err != nil {
+			t.Fatalf("IndexFile: %v", err)
+		}
+	}
+	if err := s.sqliteIndex.IndexCodeChunks("README.md", []storage.CodeChunk{
+		{FilePath: "README.md", ChunkType: "doc", ChunkName: "Payments", StartLine: 1, EndLine: 3, Content: "# Payments\nHow payments are retried", Language: "markdown"},
+	}); err != nil {
+		t.Fatalf("IndexCodeChunks: %v", err)
+	}
+
+	result := resultMap(t, mustCall(t, s, "search", map[string]interface{}{"query": "payments"}))
+	files, _ := result["files"].([]types.FileIndex)
+	if len(files) != 1 || files[0].Path != "src/payments.go" {
+		t.Errorf("files = %+v, want only src/payments.go", files)
+	}
+	if docs, _ := result["docs"].([]types.DocHit); len(docs) != 1 || docs[0].Path != "README.md" {
+		t.Errorf("docs = %+v, want the README section", docs)
+	}
+	if result["duplicates_merged"] != 1 {
+		t.Errorf("duplicates_merged = %v, want 1", result["duplicates_merged"])
+	}
+}
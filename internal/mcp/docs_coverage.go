@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/doccoverage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// handleDocsCoverage reports the share of public exports with doc comments
+// per package, the module and service directories missing a README, and
+// the thresholds from config (docs_coverage) that are not met
+func (s *Server) handleDocsCoverage(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path     string  `json:"path"`
+		Min      float64 `json:"min"`
+		Packages int     `json:"packages"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Packages <= 0 {
+		p.Packages = 20
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	if filepath.IsAbs(p.Path) {
+		rel, err := filepath.Rel(projectRoot, p.Path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("path must be inside the project: %s", p.Path)
+		}
+		p.Path = rel
+	}
+
+	var cfg types.DocsCoverageConfig
+	if config, err := s.jsonStore.GetConfig(); err == nil {
+		cfg = config.DocsCoverage
+	}
+	if p.Min > 0 {
+		cfg.Min = p.Min
+	}
+
+	report, err := doccoverage.Analyze(projectRoot, p.Path, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("path not found: %w", err)
+	}
+
+	// Least documented first; the rest are counted
+	result := map[string]interface{}{
+		"path":            report.Path,
+		"exports":         report.Exports,
+		"documented":      report.Documented,
+		"percent":         report.Percent,
+		"packages":        report.Packages,
+		"thresholds":      report.Thresholds,
+		"failed":          report.Failed,
+		"passed":          report.Failed == 0,
+		"missing_readmes": report.MissingReadmes,
+	}
+	if len(report.Packages) > p.Packages {
+		result["packages"] = report.Packages[:p.Packages]
+		result["more_packages"] = len(report.Packages) - p.Packages
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// RELEVANCE EXPLANATIONS
+// Each query result carries short reasons it matched ("matched tags: auth,
+// jwt", "file imports it", "owns 62% of internal/auth") so agents can judge
+// whether to trust and cite it, not only how it scored.
+// =============================================================================
+
+// maxReasons caps the reasons listed per result; the first found are kept
+const maxReasons = 4
+
+// explain records why an item matched. Repeated and empty reasons are dropped.
+func (r *relevanceSet) explain(docType, id, reason string) {
+	hit, ok := r.hits[docType+"\x00"+id]
+	if !ok || reason == "" || len(hit.Reasons) == maxReasons {
+		return
+	}
+	hit.Reasons = appendUnique(hit.Reasons, reason)
+}
+
+// queryTerms are the words of a question worth naming in a reason
+func queryTerms(query string) []string {
+	terms := extractKeyTerms(strings.ToLower(query))
+	if len(terms) == 0 {
+		terms = strings.Fields(strings.ToLower(query))
+	}
+	return terms
+}
+
+// knowledgeReason names the question's terms found in a decision, warning
+// or conversation: its tags first, then the rest of its text
+func knowledgeReason(tags []string, text, query string) string {
+	tagSet := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		tagSet[strings.ToLower(t)] = true
+	}
+	text = strings.ToLower(text)
+	var tagged, found []string
+	for _, term := range queryTerms(query) {
+		switch {
+		case tagSet[term]:
+			tagged = appendUnique(tagged, term)
+		case strings.Contains(text, term):
+			found = appendUnique(found, term)
+		}
+	}
+	var parts []string
+	if len(tagged) > 0 {
+		parts = append(parts, "matched tags: "+strings.Join(tagged, ", "))
+	}
+	if len(found) > 0 {
+		parts = append(parts, "matched terms: "+strings.Join(found, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// fileReason names the part of an indexed file the question's terms were
+// found in: its path, its exports or its summary
+func fileReason(f types.FileIndex, query string) string {
+	path := strings.ToLower(f.Path)
+	summary := strings.ToLower(f.Summary)
+	var inPath, inSummary, exports []string
+	for _, term := range queryTerms(query) {
+		if strings.Contains(path, term) {
+			inPath = appendUnique(inPath, term)
+		}
+		for _, e := range f.Exports {
+			if strings.Contains(strings.ToLower(e.Name), term) && len(exports) < 3 {
+				exports = appendUnique(exports, e.Name)
+			}
+		}
+		if strings.Contains(summary, term) {
+			inSummary = appendUnique(inSummary, term)
+		}
+	}
+	var parts []string
+	if len(inPath) > 0 {
+		parts = append(parts, "path matches: "+strings.Join(inPath, ", "))
+	}
+	if len(exports) > 0 {
+		parts = append(parts, "exports "+strings.Join(exports, ", "))
+	}
+	if len(inSummary) > 0 {
+		parts = append(parts, "summary mentions: "+strings.Join(inSummary, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// semanticReason describes a TF-IDF hit by its similarity
+func semanticReason(similarity float64) string {
+	return fmt.Sprintf("similar wording (%.2f similarity)", math.Round(similarity*100)/100)
+}
+
+// edgeReason describes how the knowledge graph reached an item from one
+// already found: "file a.go imports it" along the edge's direction,
+// "imports file a.go" against it
+func edgeReason(fromType, fromID, relation string, outgoing bool) string {
+	relation = strings.ReplaceAll(relation, "_", " ")
+	if outgoing {
+		return fmt.Sprintf("%s %s %s it", fromType, fromID, relation)
+	}
+	return fmt.Sprintf("%s %s %s", relation, fromType, fromID)
+}
+
+// commitReason names the commit whose message matched the question
+func commitReason(hash, message string) string {
+	subject := strings.SplitN(message, "\n", 2)[0]
+	if len(subject) > 60 {
+		subject = strings.TrimSpace(subject[:57]) + "..."
+	}
+	return fmt.Sprintf("changed in commit %s: %s", hash, subject)
+}
+
+// ownershipReason states an expert's share of a directory's commits, or
+// that CODEOWNERS names them
+func ownershipReason(expert git.ExpertEntry, area string) string {
+	if expert.Source == git.CodeownersSource {
+		return "code owner of " + area
+	}
+	return fmt.Sprintf("owns %.0f%% of %s", expert.Ownership*100, area)
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestQueryExplainsMatches(t *testing.T) {
+	s := semanticServer(t)
+	mustCall(t, s, "add_decision", map[string]interface{}{
+		"content": "Validate JWT expiry in the gateway",
+		"reason":  "tokens outlive sessions",
+		"tags":    []string{"auth", "jwt"},
+	})
+
+	result := mustCall(t, s, "query", map[string]interface{}{"question": "retry payments backoff"}).(*types.QueryResponse)
+	reasons := result.Sources[0].Reasons
+	if len(reasons) != 2 || reasons[0] != "matched terms: retry, payments, backoff" || !strings.HasPrefix(reasons[1], "similar wording (") {
+		t.Errorf("reasons = %q", reasons)
+	}
+
+	result = mustCall(t, s, "query", map[string]interface{}{"question": "jwt auth expiry"}).(*types.QueryResponse)
+	if len(result.Sources) != 1 || !reflect.DeepEqual(result.Sources[0].Reasons, []string{"matched tags: jwt, auth; matched terms: expiry"}) {
+		t.Errorf("sources = %+v", result.Sources)
+	}
+}
+
+func TestReasons(t *testing.T) {
+	f := types.FileIndex{
+		Path:    "internal/auth/jwt.go",
+		Summary: "Parses and validates tokens",
+		Exports: []types.Export{{Name: "ValidateToken"}, {Name: "Claims"}},
+	}
+	if got := fileReason(f, "validate jwt tokens"); got != "path matches: jwt; exports ValidateToken; summary mentions: validate, tokens" {
+		t.Errorf("fileReason = %q", got)
+	}
+	if got := edgeReason("file", "api/handler.go", "imports", true); got != "file api/handler.go imports it" {
+		t.Errorf("edgeReason = %q", got)
+	}
+	if got := edgeReason("decision", "dec-1", "related_to", false); got != "related to decision dec-1" {
+		t.Errorf("edgeReason = %q", got)
+	}
+	if got := ownershipReason(git.ExpertEntry{Ownership: 0.624}, "internal/auth"); got != "owns 62% of internal/auth" {
+		t.Errorf("ownershipReason = %q", got)
+	}
+	if got := ownershipReason(git.ExpertEntry{Name: "@acme/auth", Source: git.CodeownersSource}, "internal/auth"); got != "code owner of internal/auth" {
+		t.Errorf("ownershipReason = %q", got)
+	}
+
+	hits := newRelevanceSet()
+	hits.add("file", "a.go", "keyword", 0.5)
+	for _, r := range []string{"one", "one", "", "two", "three", "four", "five"} {
+		hits.explain("file", "a.go", r)
+	}
+	hits.explain("file", "missing.go", "ignored")
+	if got := hits.sources("file", []string{"a.go"})[0].Reasons; !reflect.DeepEqual(got, []string{"one", "two", "three", "four"}) {
+		t.Errorf("reasons = %v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+)
+
+// feedbackDocTypes are the result types rate_result accepts
+var feedbackDocTypes = []string{"file", "decision", "warning", "pattern", "doc", "conversation"}
+
+// loadFeedbackModel returns nil when no feedback has been recorded
+func (s *Server) loadFeedbackModel() *search.FeedbackModel {
+	feedback, err := s.jsonStore.GetSearchFeedback()
+	if err != nil || len(feedback) == 0 {
+		return nil
+	}
+	model := search.NewFeedbackModel()
+	model.SetWeight(s.currentConfig().Search.FeedbackWeight)
+	for _, fb := range feedback {
+		model.Add(fb.DocType, fb.DocID, fb.Query, fb.Helpful)
+	}
+	return model
+}
+
+// rerankByFeedback reorders results that come back in rank order without a
+// score (FTS, keyword matches). Rank is turned into a score of 1/(rank+1) so
+// a strongly rated result moves a place or two, not to the other end.
+func rerankByFeedback[T any](model *search.FeedbackModel, items []T, docType, query string, id func(T) string) []T {
+	if model == nil || len(items) < 2 {
+		return items
+	}
+	scores := make([]float64, len(items))
+	for i, item := range items {
+		scores[i] = model.Rescore(docType, id(item), query, 1/float64(i+1))
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	out := make([]T, len(items))
+	for i, j := range order {
+		out[i] = items[j]
+	}
+	return out
+}
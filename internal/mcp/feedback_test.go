@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestRelevanceSetRescoreKeepsRealScores(t *testing.T) {
+	hits := newRelevanceSet()
+	hits.add("decision", "strong", "keyword", 0.9)
+	hits.add("decision", "close", "keyword", 0.85)
+	hits.add("decision", "weak", "semantic", 0.2)
+
+	model := search.NewFeedbackModel()
+	model.Add("decision", "weak", "retry policy", true)
+	model.Add("decision", "weak", "retry policy", true)
+	hits.rescore(model, "retry policy")
+
+	// A rank-based score (1/(rank+1)) would lift "weak" over "close";
+	// scaling its real score does not
+	if got, want := hits.ranked("decision"), []string{"strong", "close", "weak"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ranked = %v, want %v", got, want)
+	}
+	if src := hits.sources("decision", []string{"weak"}); src[0].Relevance <= 0.2 {
+		t.Errorf("weak relevance = %v, want boosted above 0.2", src[0].Relevance)
+	}
+}
+
+func TestQueryAppliesFeedbackToScores(t *testing.T) {
+	s := setupTestServer(t)
+	for _, d := range []*types.Decision{
+		{Content: "Retry payments with exponential backoff"},
+		{Content: "Retry webhooks at most three times"},
+	} {
+		if err := s.jsonStore.AddDecision(d); err != nil {
+			t.Fatalf("AddDecision: %v", err)
+		}
+	}
+
+	first := mustCall(t, s, "query", map[string]interface{}{"question": "retry"}).(*types.QueryResponse)
+	if len(first.Decisions) != 2 {
+		t.Fatalf("decisions = %d, want 2", len(first.Decisions))
+	}
+	last := first.Decisions[1]
+
+	mustCall(t, s, "rate_result", map[string]interface{}{"query": "retry", "type": "decision", "id": last.ID, "helpful": true})
+
+	again := mustCall(t, s, "query", map[string]interface{}{"question": "retry"}).(*types.QueryResponse)
+	if again.Decisions[0].ID != last.ID {
+		t.Errorf("rated decision %s not ranked first: %v", last.ID, again.Decisions)
+	}
+	if again.Sources[0].ID != last.ID || again.Sources[0].Relevance <= 0.5 {
+		t.Errorf("sources = %+v, want the rated decision first with a boosted relevance", again.Sources)
+	}
+}
+
+func TestRateResultRejectsBadParams(t *testing.T) {
+	s := setupTestServer(t)
+	if _, err := s.HandleToolCall("rate_result", json.RawMessage(`{"query": "retry", "helpful": "yes"}`)); err == nil {
+		t.Error("malformed params accepted")
+	}
+	if _, err := callTool(t, s, "rate_result", map[string]interface{}{"query": "retry", "type": "commit", "id": "x", "helpful": true}); err == nil {
+		t.Error("unknown type accepted")
+	}
+	if _, err := callTool(t, s, "rate_result", map[string]interface{}{"query": "retry", "type": "file", "id": "x"}); err == nil {
+		t.Error("missing helpful accepted")
+	}
+}
@@ -0,0 +1,355 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// FEATURE HANDOFF
+// Everything someone taking over a feature needs, in one document
+// =============================================================================
+
+const (
+	handoffConversations = 5  // most recent conversations included
+	handoffFiles         = 30 // touched files included
+	handoffExperts       = 5  // code experts named besides the people on the feature
+)
+
+// closedIssueStatuses are tracker statuses of work that is finished
+var closedIssueStatuses = map[string]bool{
+	"closed": true, "done": true, "resolved": true, "merged": true,
+	"cancelled": true, "canceled": true, "won't do": true,
+}
+
+func (s *Server) handleHandoffFeature(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Feature string `json:"feature"`
+		To      string `json:"to"`
+		Format  string `json:"format"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Feature == "" {
+		p.Feature = s.session.ActiveFeature
+	}
+	if p.Feature == "" {
+		return nil, fmt.Errorf("feature is required")
+	}
+
+	feature, err := s.jsonStore.GetFeature(p.Feature)
+	if err != nil {
+		return nil, fmt.Errorf("feature not found: %w", err)
+	}
+	handoff := s.buildHandoff(feature, p.To)
+
+	if p.Format == "markdown" {
+		return map[string]interface{}{
+			"feature": handoff.Feature,
+			"content": formatHandoffMarkdown(handoff),
+		}, nil
+	}
+	return handoff, nil
+}
+
+// buildHandoff gathers the state of a feature for the person taking it over
+func (s *Server) buildHandoff(feature *types.Feature, to string) *types.FeatureHandoff {
+	h := &types.FeatureHandoff{
+		Feature:      feature.ID,
+		Description:  feature.Description,
+		Status:       feature.Status,
+		Branch:       feature.Branch,
+		CurrentState: feature.CurrentState,
+		From:         feature.Owner,
+		To:           to,
+		GeneratedAt:  time.Now(),
+	}
+	if h.From == "" {
+		h.From = s.currentAuthor()
+	}
+
+	// Decisions in force; superseded ones are history, not handoff
+	decisions, _ := s.jsonStore.GetDecisionsByFeature(feature.ID)
+	issueKeys := append([]string{}, feature.Issues...)
+	for _, d := range decisions {
+		if d.Status == "" || d.Status == "active" {
+			h.Decisions = append(h.Decisions, d)
+			issueKeys = append(issueKeys, d.Issues...)
+		}
+	}
+
+	warnings, _ := s.jsonStore.GetWarnings()
+	for _, w := range warnings {
+		if w.Feature == feature.ID || containsString(feature.Warnings, w.ID) {
+			h.Warnings = append(h.Warnings, w)
+		}
+	}
+
+	// Open items: tickets not yet done
+	for _, issue := range s.linkedIssues(issueKeys) {
+		if closedIssueStatuses[strings.ToLower(issue.Status)] {
+			continue
+		}
+		h.OpenItems = append(h.OpenItems, types.HandoffItem{
+			Kind: "issue", ID: issue.Key, Title: issue.Title, Status: issue.Status, URL: issue.URL,
+		})
+	}
+
+	// Open plan steps
+	plans, _ := s.jsonStore.GetPlans(feature.ID)
+	for _, plan := range plans {
+		if plan.Status != "active" {
+			continue
+		}
+		for i, step := range plan.Steps {
+			if step.Status != "done" && step.Status != "skipped" {
+				h.OpenItems = append(h.OpenItems, types.HandoffItem{
+					Kind: "plan_step", ID: fmt.Sprintf("%s#%d", plan.ID, i+1), Title: step.Description, Status: step.Status,
+				})
+			}
+		}
+	}
+
+	// Pending decisions: insights nobody has reviewed yet
+	insights, _ := s.jsonStore.GetInsights()
+	for _, in := range insights {
+		if in.Feature == feature.ID && in.ReviewedAt == nil {
+			h.PendingDecisions = append(h.PendingDecisions, types.HandoffItem{Kind: "insight", ID: in.ID, Title: in.Content})
+		}
+	}
+
+	conversations, _ := s.jsonStore.GetConversations(feature.ID)
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].CreatedAt.After(conversations[j].CreatedAt)
+	})
+	if len(conversations) > handoffConversations {
+		conversations = conversations[:handoffConversations]
+	}
+	h.Conversations = conversations
+
+	// Touched files: the feature's own list first, then what recent work
+	// and its knowledge point at
+	paths := append([]string{}, feature.RelevantFiles...)
+	for _, c := range conversations {
+		paths = append(paths, c.FilesDiscussed...)
+	}
+	for _, d := range h.Decisions {
+		paths = append(paths, d.RelatedFiles...)
+	}
+	for _, w := range h.Warnings {
+		paths = append(paths, w.RelatedFiles...)
+	}
+	indexed, _ := s.jsonStore.GetFilesIndex()
+	for _, path := range uniqueStrings(paths) {
+		if len(h.Files) == handoffFiles {
+			break
+		}
+		f := types.HandoffFile{Path: path}
+		if fi, ok := indexed[path]; ok {
+			if fi.DeletedAt != nil {
+				continue
+			}
+			f.Summary = fi.Summary
+		}
+		h.Files = append(h.Files, f)
+	}
+
+	h.Contacts = s.handoffContacts(feature, conversations, h.Files)
+	return h
+}
+
+// handoffContacts names the feature's owner and contributors, then the
+// experts of the directories its files are in
+func (s *Server) handoffContacts(feature *types.Feature, conversations []types.Conversation, files []types.HandoffFile) []types.HandoffContact {
+	var contacts []types.HandoffContact
+	known := make(map[string]bool)
+	addPerson := func(name, role string) {
+		if name == "" || name == types.UnattributedAuthor || known[strings.ToLower(name)] {
+			return
+		}
+		known[strings.ToLower(name)] = true
+		contacts = append(contacts, types.HandoffContact{Name: name, Role: role})
+	}
+	addPerson(feature.Owner, "owner")
+	for _, c := range feature.Contributors {
+		addPerson(c, "contributor")
+	}
+	for _, c := range conversations {
+		addPerson(c.Author, "contributor")
+	}
+
+	var areas []git.DirectoryExpert
+	if err := s.loadGitKnowledge("git-experts.json", &areas); err != nil {
+		return contacts
+	}
+	experts := make(map[string]*types.HandoffContact)
+	var order []string
+	for _, f := range files {
+		area := closestExpertArea(f.Path, areas)
+		if area == nil {
+			continue
+		}
+		for i, e := range area.TopExperts {
+			if i == deepExpertsPerArea {
+				break
+			}
+			key := strings.ToLower(e.Email)
+			if key == "" {
+				key = strings.ToLower(e.Name)
+			}
+			c, ok := experts[key]
+			if !ok {
+				c = &types.HandoffContact{Name: e.Name, Email: e.Email, Role: "expert", Active: e.Active}
+				experts[key] = c
+				order = append(order, key)
+			}
+			if !containsString(c.Areas, area.Directory) {
+				c.Areas = append(c.Areas, area.Directory)
+			}
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(experts[order[i]].Areas) > len(experts[order[j]].Areas)
+	})
+
+	added := 0
+	for _, key := range order {
+		e := experts[key]
+		// People already on the feature get their email and areas
+		if known[strings.ToLower(e.Name)] {
+			for i := range contacts {
+				if strings.EqualFold(contacts[i].Name, e.Name) {
+					contacts[i].Email, contacts[i].Areas, contacts[i].Active = e.Email, e.Areas, e.Active
+				}
+			}
+			continue
+		}
+		if added == handoffExperts {
+			continue
+		}
+		contacts = append(contacts, *e)
+		added++
+	}
+	return contacts
+}
+
+// formatHandoffMarkdown renders a handoff as a document to paste into a
+// ticket or chat
+func formatHandoffMarkdown(h *types.FeatureHandoff) string {
+	var sb strings.Builder
+	sb.WriteString("# Handoff: " + h.Feature + "\n\n")
+	if h.Description != "" {
+		sb.WriteString(h.Description + "\n\n")
+	}
+	sb.WriteString("- **Status:** " + h.Status + "\n")
+	if h.Branch != "" {
+		sb.WriteString("- **Branch:** `" + h.Branch + "`\n")
+	}
+	if h.From != "" {
+		sb.WriteString("- **From:** " + h.From + "\n")
+	}
+	if h.To != "" {
+		sb.WriteString("- **To:** " + h.To + "\n")
+	}
+	sb.WriteString("- **Generated:** " + h.GeneratedAt.Format("2006-01-02 15:04") + "\n")
+
+	if h.CurrentState != "" {
+		sb.WriteString("\n## Current state\n\n" + h.CurrentState + "\n")
+	}
+
+	if len(h.OpenItems) > 0 {
+		sb.WriteString("\n## Open items\n\n")
+		for _, item := range h.OpenItems {
+			sb.WriteString("- [ ] " + handoffItemLine(item) + "\n")
+		}
+	}
+	if len(h.PendingDecisions) > 0 {
+		sb.WriteString("\n## Pending decisions\n\nInsights to promote to a decision or warning, or dismiss:\n\n")
+		for _, item := range h.PendingDecisions {
+			sb.WriteString("- [ ] " + handoffItemLine(item) + "\n")
+		}
+	}
+
+	if len(h.Decisions) > 0 {
+		sb.WriteString("\n## Decisions\n\n")
+		for _, d := range h.Decisions {
+			sb.WriteString("- **" + d.Content + "**")
+			if d.Reason != "" {
+				sb.WriteString(" — " + d.Reason)
+			}
+			sb.WriteString(" (`" + d.ID + "`)\n")
+		}
+	}
+	if len(h.Warnings) > 0 {
+		sb.WriteString("\n## Warnings\n\n")
+		for _, w := range h.Warnings {
+			sb.WriteString("- ")
+			if w.Severity != "" {
+				sb.WriteString("[" + w.Severity + "] ")
+			}
+			sb.WriteString(w.Content + "\n")
+		}
+	}
+
+	if len(h.Conversations) > 0 {
+		sb.WriteString("\n## Recent conversations\n\n")
+		for _, c := range h.Conversations {
+			sb.WriteString("- " + c.CreatedAt.Format("2006-01-02"))
+			if c.Author != "" {
+				sb.WriteString(" (" + c.Author + ")")
+			}
+			sb.WriteString(": " + c.Summary + "\n")
+			for _, kp := range c.KeyPoints {
+				sb.WriteString("  - " + kp + "\n")
+			}
+		}
+	}
+
+	if len(h.Files) > 0 {
+		sb.WriteString("\n## Files\n\n")
+		for _, f := range h.Files {
+			sb.WriteString("- `" + f.Path + "`")
+			if f.Summary != "" {
+				sb.WriteString(" — " + f.Summary)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(h.Contacts) > 0 {
+		sb.WriteString("\n## Contacts\n\n")
+		for _, c := range h.Contacts {
+			sb.WriteString("- " + c.Name)
+			if c.Email != "" {
+				sb.WriteString(" <" + c.Email + ">")
+			}
+			sb.WriteString(" — " + c.Role)
+			if len(c.Areas) > 0 {
+				sb.WriteString(", knows `" + strings.Join(c.Areas, "`, `") + "`")
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+func handoffItemLine(item types.HandoffItem) string {
+	line := item.ID
+	if item.URL != "" {
+		line = "[" + item.ID + "](" + item.URL + ")"
+	}
+	if item.Title != "" {
+		line += ": " + item.Title
+	}
+	if item.Status != "" {
+		line += " (" + item.Status + ")"
+	}
+	return line
+}
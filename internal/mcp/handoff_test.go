@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestHandoffFeature(t *testing.T) {
+	s := setupTestServer(t)
+	mustCall(t, s, "start_feature", map[string]interface{}{"id": "refunds", "description": "Partial refunds"})
+	feature, _ := s.jsonStore.GetFeature("refunds")
+	feature.Owner = "Dana"
+	feature.CurrentState = "API done, UI missing"
+	feature.RelevantFiles = []string{"billing/refund.go"}
+	if err := s.jsonStore.UpdateFeature(feature); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.SaveFileIndex(&types.FileIndex{Path: "billing/refund.go", Summary: "Refund calculation"}); err != nil {
+		t.Fatal(err)
+	}
+	decision := &types.Decision{Content: "Refunds go back to the original card", Reason: "Chargeback rules", Feature: "refunds", Status: "active"}
+	insight := &types.Insight{Content: "Maybe cap refunds at the order total", Feature: "refunds"}
+	if err := s.jsonStore.AddDecision(decision); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.AddInsight(insight); err != nil {
+		t.Fatal(err)
+	}
+	for _, summary := range []string{"Designed the refund API", "Wired the refund UI"} {
+		conv := &types.Conversation{Feature: "refunds", Summary: summary, Author: "Sam", FilesDiscussed: []string{"web/refund.tsx"}}
+		if err := s.jsonStore.SaveConversation(conv); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond) // saving stamps the time
+	}
+	experts, _ := json.Marshal([]git.DirectoryExpert{{Directory: "billing", TopExperts: []git.ExpertEntry{
+		{Name: "Dana", Email: "dana@example.com", Active: true},
+		{Name: "Lee", Email: "lee@example.com", Active: true},
+	}}})
+	if err := os.WriteFile(filepath.Join(s.basePath, "knowledge", "git-experts.json"), experts, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := mustCall(t, s, "handoff_feature", map[string]interface{}{"feature": "refunds", "to": "Kim"}).(*types.FeatureHandoff)
+	if h.From != "Dana" || h.To != "Kim" || h.CurrentState != "API done, UI missing" {
+		t.Errorf("handoff = from %q to %q, state %q", h.From, h.To, h.CurrentState)
+	}
+	if len(h.Decisions) != 1 || h.Decisions[0].ID != decision.ID {
+		t.Errorf("decisions = %+v, want %s", h.Decisions, decision.ID)
+	}
+	if len(h.PendingDecisions) != 1 || h.PendingDecisions[0].ID != insight.ID {
+		t.Errorf("pending decisions = %+v, want the unreviewed insight %s", h.PendingDecisions, insight.ID)
+	}
+	if len(h.Conversations) != 2 || h.Conversations[0].Summary != "Wired the refund UI" {
+		t.Errorf("conversations = %+v, want the newest first", h.Conversations)
+	}
+	wantFiles := []types.HandoffFile{{Path: "billing/refund.go", Summary: "Refund calculation"}, {Path: "web/refund.tsx"}}
+	if !reflect.DeepEqual(h.Files, wantFiles) {
+		t.Errorf("files = %+v, want %+v", h.Files, wantFiles)
+	}
+	wantContacts := []types.HandoffContact{
+		{Name: "Dana", Email: "dana@example.com", Role: "owner", Areas: []string{"billing"}, Active: true},
+		{Name: "Sam", Role: "contributor"},
+		{Name: "Lee", Email: "lee@example.com", Role: "expert", Areas: []string{"billing"}, Active: true},
+	}
+	if !reflect.DeepEqual(h.Contacts, wantContacts) {
+		t.Errorf("contacts = %+v\nwant %+v", h.Contacts, wantContacts)
+	}
+
+	md := resultMap(t, mustCall(t, s, "handoff_feature", map[string]interface{}{"feature": "refunds", "format": "markdown"}))["content"].(string)
+	for _, want := range []string{
+		"# Handoff: refunds",
+		"## Current state\n\nAPI done, UI missing",
+		"- [ ] " + insight.ID + ": Maybe cap refunds at the order total",
+		"- `billing/refund.go` — Refund calculation",
+		"- Lee <lee@example.com> — expert, knows `billing`",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+
+	if _, err := callTool(t, s, "handoff_feature", map[string]interface{}{"feature": "missing"}); err == nil {
+		t.Error("handoff of an unknown feature succeeded")
+	}
+}
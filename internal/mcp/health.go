@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+)
+
+// staleIndexGrace is how far the last full index may trail the latest
+// commit before the index counts as stale; the git watcher covers less
+const staleIndexGrace = 24 * time.Hour
+
+// healthProblem is something that makes tools return empty or partial
+// results, with what the agent should do about it
+type healthProblem struct {
+	Problem string
+	Fix     string
+}
+
+// checkHealth inspects the index and git analysis the way tools will see
+// them. It returns nothing when the server is ready to use.
+func (s *Server) checkHealth() []healthProblem {
+	projectRoot := filepath.Dir(s.basePath)
+	var problems []healthProblem
+
+	files, _ := s.jsonStore.GetFilesIndex()
+	live := 0
+	for _, f := range files {
+		if f.DeletedAt == nil {
+			live++
+		}
+	}
+
+	// The watcher, index_file and summaries rewrite single entries, so only
+	// a full index or reindex says the whole tree was looked at
+	var lastFullIndex time.Time
+	if report, _ := s.jsonStore.GetIndexCapReport(); report != nil {
+		lastFullIndex = report.RunAt
+	}
+
+	gitUsable := s.capabilities == nil || (s.capabilities.GitBinary && s.capabilities.GitRepository)
+
+	switch {
+	case live == 0:
+		problems = append(problems, healthProblem{
+			Problem: "The file index is empty, so search, query, skeleton and blueprint tools will return no results.",
+			Fix:     fmt.Sprintf("Run `teamcontext index` in %s (or `teamcontext init` if the project was never set up), then call index_status to confirm.", projectRoot),
+		})
+	case gitUsable && !lastFullIndex.IsZero():
+		if changes, err := git.GetRecentChanges(projectRoot, "", 1); err == nil && len(changes) > 0 {
+			if lastCommit := changes[0].Date; lastCommit.Sub(lastFullIndex) > staleIndexGrace {
+				problems = append(problems, healthProblem{
+					Problem: fmt.Sprintf("The index was last fully built %s but the latest commit is from %s; results may miss recent code.", lastFullIndex.Format("2006-01-02"), lastCommit.Format("2006-01-02")),
+					Fix:     fmt.Sprintf("Run `teamcontext reindex` in %s to refresh it (knowledge is preserved).", projectRoot),
+				})
+			}
+		}
+	}
+
+	if gitUsable {
+		_, stateErr := os.Stat(filepath.Join(s.basePath, "cache", "git-analysis.json"))
+		_, expertsErr := os.Stat(filepath.Join(s.basePath, "knowledge", "git-experts.json"))
+		if stateErr != nil && expertsErr != nil {
+			problems = append(problems, healthProblem{
+				Problem: "Git history has never been analyzed, so find_experts, get_knowledge_risks and get_file_correlations fall back to slow live git and query/onboard omit experts.",
+				Fix:     fmt.Sprintf("Run `teamcontext reindex` in %s to analyze history once; later runs are incremental.", projectRoot),
+			})
+		}
+	}
+
+	return problems
+}
+
+// healthInstructions renders problems as initialize instructions, so the
+// agent fixes setup before spending turns on tools that return nothing
+func healthInstructions(problems []healthProblem) string {
+	if len(problems) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("TeamContext is not fully set up for this project. Before relying on its tools:\n")
+	for i, p := range problems {
+		fmt.Fprintf(&sb, "%d. %s %s\n", i+1, p.Problem, p.Fix)
+	}
+	sb.WriteString("Knowledge tools (list_decisions, add_decision, ...) work regardless. Call get_capabilities for per-tool status.")
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// commitProject makes a git repository of the project with one commit
+// dated now
+func commitProject(t *testing.T, s *Server) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	writeProjectFile(t, s, "main.go", "package main\n")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "main.go"},
+		{"-c", "user.name=Ada", "-c", "user.email=ada@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		gitConfig(t, s, args...)
+	}
+	s.capabilities = detectCapabilities(s.basePath, s.issueConfig())
+}
+
+func TestCheckHealth(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	problemsMatching := func(problems []healthProblem, substr string) int {
+		n := 0
+		for _, p := range problems {
+			if strings.Contains(p.Problem, substr) {
+				n++
+			}
+		}
+		return n
+	}
+	indexProject := func(t *testing.T, s *Server, fullIndex time.Time) {
+		t.Helper()
+		if err := s.jsonStore.SaveFileIndex(&types.FileIndex{Path: "main.go", Language: "go"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.jsonStore.SaveIndexCapReport(&types.IndexCapReport{RunAt: fullIndex}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	analyzeHistory := func(t *testing.T, s *Server) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(s.basePath, "cache", "git-analysis.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("empty index", func(t *testing.T) {
+		s := setupTestServer(t)
+		commitProject(t, s)
+		analyzeHistory(t, s)
+
+		problems := s.checkHealth()
+		if len(problems) != 1 || !strings.Contains(problems[0].Problem, "index is empty") {
+			t.Fatalf("problems = %+v, want only the empty index", problems)
+		}
+		if !strings.Contains(problems[0].Fix, "teamcontext index") {
+			t.Errorf("fix = %q", problems[0].Fix)
+		}
+	})
+
+	t.Run("stale index", func(t *testing.T) {
+		s := setupTestServer(t)
+		commitProject(t, s)
+		analyzeHistory(t, s)
+		indexProject(t, s, time.Now().Add(-72*time.Hour))
+		// The watcher touching an entry since doesn't make the index fresh
+		if err := s.jsonStore.SaveFileIndex(&types.FileIndex{Path: "util.go", Language: "go"}); err != nil {
+			t.Fatal(err)
+		}
+
+		problems := s.checkHealth()
+		if len(problems) != 1 || problemsMatching(problems, "last fully built") != 1 {
+			t.Fatalf("problems = %+v, want only the stale index", problems)
+		}
+		if !strings.Contains(problems[0].Fix, "teamcontext reindex") {
+			t.Errorf("fix = %q", problems[0].Fix)
+		}
+	})
+
+	t.Run("git history never analyzed", func(t *testing.T) {
+		s := setupTestServer(t)
+		commitProject(t, s)
+		indexProject(t, s, time.Now())
+
+		problems := s.checkHealth()
+		if len(problems) != 1 || problemsMatching(problems, "never been analyzed") != 1 {
+			t.Fatalf("problems = %+v, want only the missing git analysis", problems)
+		}
+	})
+
+	t.Run("healthy", func(t *testing.T) {
+		s := setupTestServer(t)
+		commitProject(t, s)
+		analyzeHistory(t, s)
+		indexProject(t, s, time.Now())
+
+		problems := s.checkHealth()
+		if len(problems) != 0 {
+			t.Fatalf("problems = %+v, want none", problems)
+		}
+		if got := healthInstructions(problems); got != "" {
+			t.Errorf("instructions = %q, want none", got)
+		}
+	})
+}
+
+func TestHealthInstructions(t *testing.T) {
+	got := healthInstructions([]healthProblem{
+		{Problem: "The file index is empty.", Fix: "Run `teamcontext index`."},
+		{Problem: "Git history has never been analyzed.", Fix: "Run `teamcontext reindex`."},
+	})
+	for _, want := range []string{
+		"1. The file index is empty. Run `teamcontext index`.\n",
+		"2. Git history has never been analyzed. Run `teamcontext reindex`.\n",
+		"get_capabilities",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instructions missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+)
+
+// authorEnv overrides the git identity stamped on knowledge created through
+// the server, e.g. when agents run under a shared CI or bot account
+const authorEnv = "TEAMCONTEXT_AUTHOR"
+
+// currentAuthor returns who knowledge recorded in this session belongs to:
+// TEAMCONTEXT_AUTHOR when set, else the local git user as "Name <email>".
+// Agents rarely know the human they work for, so this fills Author when the
+// tool call leaves it empty. It is resolved once per server.
+func (s *Server) currentAuthor() string {
+	s.authorOnce.Do(func() {
+		if author := strings.TrimSpace(os.Getenv(authorEnv)); author != "" {
+			s.author = author
+			return
+		}
+		name, email := git.GetUserIdentity(filepath.Dir(s.basePath))
+		switch {
+		case name != "" && email != "":
+			s.author = name + " <" + email + ">"
+		case name != "":
+			s.author = name
+		default:
+			s.author = email
+		}
+	})
+	return s.author
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// gitConfig sets a repository-local git config value in the project
+func gitConfig(t *testing.T, s *Server, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = filepath.Dir(s.basePath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestCurrentAuthorFromGitIdentity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv(authorEnv, "")
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   string
+	}{
+		{"name and email", map[string]string{"user.name": "Ada Lovelace", "user.email": "ada@example.com"}, "Ada Lovelace <ada@example.com>"},
+		{"name only", map[string]string{"user.name": "Ada Lovelace"}, "Ada Lovelace"},
+		{"email only", map[string]string{"user.email": "ada@example.com"}, "ada@example.com"},
+		{"no identity", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := setupTestServer(t)
+			gitConfig(t, s, "init", "-q")
+			for key, value := range tt.config {
+				gitConfig(t, s, "config", key, value)
+			}
+			if got := s.currentAuthor(); got != tt.want {
+				t.Errorf("currentAuthor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKnowledgeIsStampedWithAuthor(t *testing.T) {
+	t.Setenv(authorEnv, "  ci-bot  ")
+	s := setupTestServer(t)
+
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Use Postgres for billing", "reason": "ledger needs transactions"})
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Use Redis for sessions", "reason": "fast expiry", "author": "grace"})
+	mustCall(t, s, "add_warning", map[string]interface{}{"content": "Never log card numbers", "reason": "PCI scope", "severity": "critical"})
+	mustCall(t, s, "add_insight", map[string]interface{}{"content": "Invoices are generated nightly"})
+	mustCall(t, s, "save_conversation", map[string]interface{}{"feature": "billing", "summary": "Discussed billing storage"})
+
+	decisions, _ := s.jsonStore.GetDecisions()
+	authors := make(map[string]string)
+	for _, d := range decisions {
+		authors[d.Content] = d.Author
+	}
+	if authors["Use Postgres for billing"] != "ci-bot" {
+		t.Errorf("decision author = %q, want the TEAMCONTEXT_AUTHOR override", authors["Use Postgres for billing"])
+	}
+	if authors["Use Redis for sessions"] != "grace" {
+		t.Errorf("explicit author replaced: %q", authors["Use Redis for sessions"])
+	}
+	if warnings, _ := s.jsonStore.GetWarnings(); len(warnings) != 1 || warnings[0].Author != "ci-bot" {
+		t.Errorf("warnings = %+v, want one stamped ci-bot", warnings)
+	}
+	if insights, _ := s.jsonStore.GetInsights(); len(insights) != 1 || insights[0].Author != "ci-bot" {
+		t.Errorf("insights = %+v, want one stamped ci-bot", insights)
+	}
+	if convs, _ := s.jsonStore.GetConversations("billing"); len(convs) != 1 || convs[0].Author != "ci-bot" {
+		t.Errorf("conversations = %+v, want one stamped ci-bot", convs)
+	}
+}
+
+func TestMergeConversationsKeepsSharedAuthor(t *testing.T) {
+	same := []types.Conversation{
+		{ID: "conv-1", Summary: "a", Author: "ada"},
+		{ID: "conv-2", Summary: "b", Author: "ada"},
+	}
+	if merged, _ := mergeConversations(same, ""); merged.Author != "ada" {
+		t.Errorf("shared author = %q, want ada", merged.Author)
+	}
+	mixed := []types.Conversation{
+		{ID: "conv-1", Summary: "a", Author: "ada"},
+		{ID: "conv-2", Summary: "b", Author: "grace"},
+	}
+	if merged, _ := mergeConversations(mixed, ""); merged.Author != "" {
+		t.Errorf("mixed authors merged as %q, want empty", merged.Author)
+	}
+}
+
+func TestContributionLeaderboard(t *testing.T) {
+	t.Setenv(authorEnv, "ada")
+	s := setupTestServer(t)
+
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Use Postgres for billing", "reason": "ledger needs transactions"})
+	mustCall(t, s, "index_file", map[string]interface{}{"path": "src/billing.go", "summary": "Creates invoices"})
+	mustCall(t, s, "add_warning", map[string]interface{}{"content": "Never log card numbers", "reason": "PCI scope", "author": "release-bot"})
+
+	if file, err := s.jsonStore.GetFileIndex("src/billing.go"); err != nil || file.SummaryAuthor != "ada" || file.SummarizedAt == nil {
+		t.Errorf("indexed file = %+v (%v), want the summary stamped ada", file, err)
+	}
+
+	stats := mustCall(t, s, "get_stats", map[string]interface{}{}).(*types.Stats)
+	if len(stats.Contributors) != 2 {
+		t.Fatalf("contributors = %+v, want ada and release-bot", stats.Contributors)
+	}
+	if ada := stats.Contributors[0]; ada.Author != "ada" || ada.Decisions != 1 || ada.FileSummaries != 1 || ada.Agent {
+		t.Errorf("top contributor = %+v, want ada with a decision and a file summary", ada)
+	}
+	if bot := stats.Contributors[1]; bot.Author != "release-bot" || bot.Warnings != 1 || !bot.Agent {
+		t.Errorf("second contributor = %+v, want release-bot flagged as an agent", bot)
+	}
+	if stats.AgentShare != 0.33 {
+		t.Errorf("agent share = %v, want 0.33", stats.AgentShare)
+	}
+
+	feed := resultMap(t, mustCall(t, s, "get_feed", map[string]interface{}{"since": "7d"}))
+	if contributors, _ := feed["contributors"].([]types.AuthorStats); len(contributors) != 2 {
+		t.Errorf("feed contributors = %v, want the same two authors", feed["contributors"])
+	}
+	feed = resultMap(t, mustCall(t, s, "get_feed", map[string]interface{}{"type": "decision"}))
+	if _, ok := feed["contributors"]; ok {
+		t.Error("a feed filtered by type should not list contributors")
+	}
+}
@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// defaultMaxResponseBytes bounds the serialized size of any tool response
+const defaultMaxResponseBytes = 64 * 1024
+
+// toolResponseLimits overrides the default for tools whose output grows with
+// the size of the project or the requested depth.
+var toolResponseLimits = map[string]int{
+	"get_graph":        32 * 1024,
+	"trace_flow":       32 * 1024,
+	"get_dependencies": 32 * 1024,
+	"get_related":      32 * 1024,
+	"get_code_map":     48 * 1024,
+	"get_tree":         48 * 1024,
+}
+
+// responseLimit is the size limit of a tool's responses: its entry in
+// server.response_limits, else its built-in limit, else
+// server.max_response_bytes or the default
+func responseLimit(tool string, cfg types.ServerConfig) int {
+	if l := cfg.ResponseLimits[tool]; l > 0 {
+		return l
+	}
+	if l, ok := toolResponseLimits[tool]; ok {
+		return l
+	}
+	if cfg.MaxResponseBytes > 0 {
+		return cfg.MaxResponseBytes
+	}
+	return defaultMaxResponseBytes
+}
+
+// truncationMetadataBytes is reserved for the truncated/omitted/suggestion
+// fields so the final response still fits the limit.
+const truncationMetadataBytes = 512
+
+const truncatedMarker = "\n... [truncated]"
+
+// narrowingHints suggest how to ask a tool for less when it was truncated
+var narrowingHints = map[string]string{
+	"get_graph":                 "Pass node_type and node_id to get the edges of a single node",
+	"trace_flow":                "Lower depth, or pass query so relevant files are listed first",
+	"call_graph":                "Pass function to follow a single function, or lower depth",
+	"get_module_boundaries":     "Pass module or path to look at one area, or lower limit",
+	"get_dependencies":          "Lower depth, or use direction 'upstream' or 'downstream' instead of 'both'",
+	"get_related":               "Lower max_depth",
+	"get_code_map":              "Pass path or language, lower max_depth, or use dirs_only",
+	"get_tree":                  "Pass path to zoom into a subdirectory",
+	"search_code":               "Use a more specific query or a lower limit",
+	"search_files":              "Use a more specific query or a lower limit",
+	"get_evolution_timeline":    "Pass a narrower time range or limit",
+	"scan_imports":              "Scan a single file or a smaller directory",
+	"get_schema_models":         "Pass the path of a single schema file or package",
+	"get_api_surface":           "Pass the path of a single app or controller",
+	"extraction_plan":           "Lower limit, or pass a smaller directory",
+	"get_dependencies_manifest": "Pass ecosystem, name or the path of a single package, or lower limit",
+	"export_requests":           "Pass output to write the collection to a file, or the path of a single controller",
+	"get_auth_matrix":           "Pass status 'unguarded', or the path of a single app or controller",
+	"list_feature_flags":        "Pass provider, key or path, or use missing_only",
+	"get_worker_log":            "Pass level, since or contains, or lower limit",
+}
+
+// limitResponseSize truncates oversized map and struct results
+// deterministically: the largest top-level lists are cut to a prefix (and
+// then the largest strings) until the response fits the tool's limit.
+// Truncated responses carry truncated=true, the number of omitted items per
+// field, and a suggestion for narrower parameters.
+func limitResponseSize(tool string, result interface{}, cfg types.ServerConfig) interface{} {
+	limit := responseLimit(tool, cfg)
+	if responseSize(result) <= limit {
+		return result
+	}
+
+	body, ok := result.(map[string]interface{})
+	if !ok {
+		if body, ok = structToMap(result); !ok {
+			return result
+		}
+	}
+	// Leave room for the truncation metadata added below
+	limit -= truncationMetadataBytes
+
+	omitted := make(map[string]int)
+
+	// Lists first: they shrink without losing the shape of the answer
+	for _, key := range fieldsBySize(body, reflect.Slice) {
+		v := reflect.ValueOf(body[key])
+		total := v.Len()
+
+		// Largest prefix that fits
+		lo, hi := 0, total
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			body[key] = v.Slice(0, mid).Interface()
+			if responseSize(body) <= limit {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		body[key] = v.Slice(0, lo).Interface()
+		if lo < total {
+			omitted[key] = total - lo
+		}
+		if responseSize(body) <= limit {
+			break
+		}
+	}
+
+	// Then long strings (e.g. rendered trees)
+	if responseSize(body) > limit {
+		for _, key := range fieldsBySize(body, reflect.String) {
+			text := body[key].(string)
+
+			lo, hi := 0, len(text)
+			for lo < hi {
+				mid := (lo + hi + 1) / 2
+				body[key] = text[:mid] + truncatedMarker
+				if responseSize(body) <= limit {
+					lo = mid
+				} else {
+					hi = mid - 1
+				}
+			}
+			// Don't split a multi-byte character
+			for lo > 0 && lo < len(text) && !utf8.RuneStart(text[lo]) {
+				lo--
+			}
+			body[key] = text[:lo] + truncatedMarker
+			omitted[key+"_chars"] = len(text) - lo
+			if responseSize(body) <= limit {
+				break
+			}
+		}
+	}
+
+	body["truncated"] = true
+	body["omitted"] = omitted
+	hint, ok := narrowingHints[tool]
+	if !ok {
+		hint = "Narrow the request with path, limit or depth parameters"
+	}
+	body["suggestion"] = hint
+
+	return body
+}
+
+// structToMap converts a struct (or pointer to one) to its JSON object form so
+// it can be truncated field by field like a map result.
+func structToMap(result interface{}) (map[string]interface{}, bool) {
+	v := reflect.ValueOf(result)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, false
+	}
+	// Keep large integers exact
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var body map[string]interface{}
+	if err := dec.Decode(&body); err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// fieldsBySize returns the top-level keys holding values of the given kind,
+// largest serialized value first, with key order breaking ties.
+func fieldsBySize(body map[string]interface{}, kind reflect.Kind) []string {
+	type field struct {
+		key  string
+		size int
+	}
+	var fields []field
+	for key, val := range body {
+		if val == nil {
+			continue
+		}
+		v := reflect.ValueOf(val)
+		if v.Kind() != kind {
+			continue
+		}
+		if kind == reflect.Slice && (v.Len() == 0 || v.Type().Elem().Kind() == reflect.Uint8) {
+			continue
+		}
+		fields = append(fields, field{key, responseSize(val)})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].size != fields[j].size {
+			return fields[i].size > fields[j].size
+		}
+		return fields[i].key < fields[j].key
+	})
+
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.key
+	}
+	return keys
+}
+
+// responseSize is the size of v as delivered to clients (indented JSON)
+func responseSize(v interface{}) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func bigList(n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("item-%04d-%s", i, strings.Repeat("x", 40))
+	}
+	return items
+}
+
+func TestLimitResponseSizeLeavesSmallResults(t *testing.T) {
+	small := map[string]interface{}{"items": []string{"a", "b"}}
+	got := resultMap(t, limitResponseSize("search_code", small, types.ServerConfig{}))
+	if _, ok := got["truncated"]; ok {
+		t.Errorf("small response marked truncated: %v", got)
+	}
+
+	type card struct {
+		Name string `json:"name"`
+	}
+	if got := limitResponseSize("search_code", card{Name: "x"}, types.ServerConfig{}); got != (card{Name: "x"}) {
+		t.Errorf("small struct = %#v, want it returned unchanged", got)
+	}
+}
+
+func TestLimitResponseSizeTruncatesLists(t *testing.T) {
+	body := map[string]interface{}{
+		"query":   "users",
+		"results": bigList(3000),
+		"small":   []string{"keep"},
+	}
+	got := resultMap(t, limitResponseSize("search_code", body, types.ServerConfig{}))
+
+	if size := responseSize(got); size > defaultMaxResponseBytes {
+		t.Fatalf("size = %d, want <= %d", size, defaultMaxResponseBytes)
+	}
+	if got["truncated"] != true {
+		t.Errorf("truncated = %v, want true", got["truncated"])
+	}
+	kept := len(got["results"].([]string))
+	if omitted := got["omitted"].(map[string]int)["results"]; kept == 0 || kept+omitted != 3000 {
+		t.Errorf("kept %d + omitted %d, want a non-empty prefix of 3000", kept, omitted)
+	}
+	if got["results"].([]string)[0] != bigList(1)[0] {
+		t.Errorf("results were not cut to a prefix")
+	}
+	if len(got["small"].([]string)) != 1 || got["query"] != "users" {
+		t.Errorf("fields that fit were changed: %v %v", got["small"], got["query"])
+	}
+	if got["suggestion"] != narrowingHints["search_code"] {
+		t.Errorf("suggestion = %v", got["suggestion"])
+	}
+}
+
+func TestLimitResponseSizePerToolLimit(t *testing.T) {
+	body := map[string]interface{}{"edges": bigList(800)}
+	if responseSize(body) <= toolResponseLimits["get_graph"] || responseSize(body) > defaultMaxResponseBytes {
+		t.Fatalf("fixture size %d does not sit between the limits", responseSize(body))
+	}
+	if got := resultMap(t, limitResponseSize("get_graph", body, types.ServerConfig{})); got["truncated"] != true {
+		t.Errorf("get_graph response over its own limit was not truncated")
+	}
+	body = map[string]interface{}{"edges": bigList(800)}
+	if got := resultMap(t, limitResponseSize("search_code", body, types.ServerConfig{})); got["truncated"] != nil {
+		t.Errorf("response under the default limit was truncated")
+	}
+}
+
+func TestLimitResponseSizeTruncatesStringsOnRuneBoundary(t *testing.T) {
+	body := map[string]interface{}{"tree": strings.Repeat("é", 40000)}
+	got := resultMap(t, limitResponseSize("get_tree", body, types.ServerConfig{}))
+
+	tree := got["tree"].(string)
+	if !strings.HasSuffix(tree, truncatedMarker) {
+		t.Errorf("tree does not end with the truncation marker")
+	}
+	if !utf8.ValidString(tree) {
+		t.Errorf("truncated tree is not valid UTF-8")
+	}
+	if got["omitted"].(map[string]int)["tree_chars"] == 0 {
+		t.Errorf("omitted = %v, want tree_chars", got["omitted"])
+	}
+	if size := responseSize(got); size > toolResponseLimits["get_tree"] {
+		t.Errorf("size = %d, want <= %d", size, toolResponseLimits["get_tree"])
+	}
+}
+
+func TestLimitResponseSizeTruncatesStructs(t *testing.T) {
+	type node struct {
+		Path string `json:"path"`
+	}
+	type codeMap struct {
+		Root  string `json:"root"`
+		Total int64  `json:"total"`
+		Nodes []node `json:"nodes"`
+	}
+	result := &codeMap{Root: "src", Total: 1 << 60}
+	for _, item := range bigList(3000) {
+		result.Nodes = append(result.Nodes, node{Path: item})
+	}
+
+	got := resultMap(t, limitResponseSize("get_code_map", result, types.ServerConfig{}))
+	if size := responseSize(got); size > toolResponseLimits["get_code_map"] {
+		t.Fatalf("size = %d, want <= %d", size, toolResponseLimits["get_code_map"])
+	}
+	if got["truncated"] != true || got["suggestion"] != narrowingHints["get_code_map"] {
+		t.Errorf("truncated, suggestion = %v, %v", got["truncated"], got["suggestion"])
+	}
+	kept := len(got["nodes"].([]interface{}))
+	if omitted := got["omitted"].(map[string]int)["nodes"]; kept == 0 || kept+omitted != 3000 {
+		t.Errorf("kept %d + omitted %d, want a non-empty prefix of 3000", kept, omitted)
+	}
+	if got["root"] != "src" {
+		t.Errorf("root = %v, want src", got["root"])
+	}
+	if got["total"] != json.Number("1152921504606846976") {
+		t.Errorf("total = %v, want the exact integer", got["total"])
+	}
+}
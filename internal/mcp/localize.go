@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// languageEnv sets this developer's default working language when a tool
+// call doesn't pass one
+const languageEnv = "TEAMCONTEXT_LANGUAGE"
+
+// knowledgeTypes are the item types that can carry translations
+var knowledgeTypes = []string{"decision", "warning", "insight"}
+
+// normalizeLanguage lowercases a language tag and uses '-' as separator
+// ("de_DE" -> "de-de")
+func normalizeLanguage(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
+
+// preferredLanguage returns the requested language, or the developer's
+// default from TEAMCONTEXT_LANGUAGE
+func preferredLanguage(requested string) string {
+	if requested != "" {
+		return normalizeLanguage(requested)
+	}
+	return normalizeLanguage(os.Getenv(languageEnv))
+}
+
+// sameLanguage matches tags on their primary subtag, so "de" matches "de-at"
+func sameLanguage(a, b string) bool {
+	a, b = normalizeLanguage(a), normalizeLanguage(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	return strings.SplitN(a, "-", 2)[0] == strings.SplitN(b, "-", 2)[0]
+}
+
+// findTranslation returns the translation for lang, preferring an exact tag
+// over one that only shares the primary subtag
+func findTranslation(translations map[string]types.Translation, lang string) (string, types.Translation, bool) {
+	if t, ok := translations[lang]; ok {
+		return lang, t, true
+	}
+	for _, tag := range translationLanguages(translations) {
+		if sameLanguage(tag, lang) {
+			return tag, translations[tag], true
+		}
+	}
+	return "", types.Translation{}, false
+}
+
+// translatedText returns the translated field, or the original when the
+// translation leaves it empty
+func translatedText(original, translated string) string {
+	if translated != "" {
+		return translated
+	}
+	return original
+}
+
+func localizeDecision(d types.Decision, lang string) types.Decision {
+	if lang == "" {
+		return d
+	}
+	translations := d.Translations
+	d.Translations = nil
+	if sameLanguage(d.Language, lang) {
+		return d
+	}
+	if tag, t, ok := findTranslation(translations, lang); ok {
+		d.Content = translatedText(d.Content, t.Content)
+		d.Reason = translatedText(d.Reason, t.Reason)
+		d.Context = translatedText(d.Context, t.Context)
+		d.TranslatedFrom = d.Language
+		d.Language = tag
+	}
+	return d
+}
+
+func localizeWarning(w types.Warning, lang string) types.Warning {
+	if lang == "" {
+		return w
+	}
+	translations := w.Translations
+	w.Translations = nil
+	if sameLanguage(w.Language, lang) {
+		return w
+	}
+	if tag, t, ok := findTranslation(translations, lang); ok {
+		w.Content = translatedText(w.Content, t.Content)
+		w.Reason = translatedText(w.Reason, t.Reason)
+		w.Evidence = translatedText(w.Evidence, t.Evidence)
+		w.TranslatedFrom = w.Language
+		w.Language = tag
+	}
+	return w
+}
+
+// localizeDecisions serves decisions in lang. Decisions from the SQLite
+// index carry no language data, so the full records are read from JSON.
+func (s *Server) localizeDecisions(decisions []types.Decision, lang string) []types.Decision {
+	if lang == "" || len(decisions) == 0 {
+		return decisions
+	}
+	byID := make(map[string]types.Decision)
+	if all, err := s.jsonStore.GetDecisions(); err == nil {
+		for _, d := range all {
+			byID[d.ID] = d
+		}
+	}
+	out := make([]types.Decision, len(decisions))
+	for i, d := range decisions {
+		if full, ok := byID[d.ID]; ok {
+			d.Language = full.Language
+			d.Translations = full.Translations
+		}
+		out[i] = localizeDecision(d, lang)
+	}
+	return out
+}
+
+// localizeWarnings serves warnings in lang, like localizeDecisions
+func (s *Server) localizeWarnings(warnings []types.Warning, lang string) []types.Warning {
+	if lang == "" || len(warnings) == 0 {
+		return warnings
+	}
+	byID := make(map[string]types.Warning)
+	if all, err := s.jsonStore.GetWarnings(); err == nil {
+		for _, w := range all {
+			byID[w.ID] = w
+		}
+	}
+	out := make([]types.Warning, len(warnings))
+	for i, w := range warnings {
+		if full, ok := byID[w.ID]; ok {
+			w.Language = full.Language
+			w.Translations = full.Translations
+		}
+		out[i] = localizeWarning(w, lang)
+	}
+	return out
+}
+
+// translationLanguages returns the sorted languages an item is translated to
+func translationLanguages(translations map[string]types.Translation) []string {
+	languages := make([]string, 0, len(translations))
+	for lang := range translations {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// knowledgeText is the text of an item in all its languages, for keyword
+// matching and semantic vectors
+func knowledgeText(translations map[string]types.Translation, fields ...string) string {
+	text := strings.Join(fields, " ")
+	if len(translations) > 0 {
+		text += " " + storage.TranslationText(translations)
+	}
+	return text
+}
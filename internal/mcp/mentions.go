@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// maxMentionFiles skips a mentioned identifier exported by more files than
+// this: a name that common says nothing about where the decision applies
+const maxMentionFiles = 3
+
+// minMentionLength skips identifiers too short to be a deliberate mention
+const minMentionLength = 4
+
+var (
+	backtickedRe = regexp.MustCompile("`([^`]+)`")
+	identifierRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// mentionedIdentifiers returns the identifiers in free text that look like
+// code: backticked names, camelCase or PascalCase words with more than one
+// hump, and snake_case words
+func mentionedIdentifiers(text string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if len(name) >= minMentionLength && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, m := range backtickedRe.FindAllStringSubmatch(text, -1) {
+		// `payments.NewClient()` mentions payments and NewClient
+		for _, word := range identifierRe.FindAllString(m[1], -1) {
+			add(word)
+		}
+	}
+	for _, word := range identifierRe.FindAllString(text, -1) {
+		if looksLikeCode(word) {
+			add(word)
+		}
+	}
+	return names
+}
+
+// looksLikeCode reports whether a plain word is written like an identifier
+// rather than prose: it joins words with an underscore or a case change
+func looksLikeCode(word string) bool {
+	if strings.Contains(strings.Trim(word, "_"), "_") {
+		return true
+	}
+	humps := 0
+	for i, r := range word {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(rune(word[i-1])) {
+			humps++
+		}
+	}
+	return humps > 0
+}
+
+// symbolMentions maps each identifier the text mentions to the indexed
+// files exporting it. Identifiers exported by too many files are ambiguous
+// and left out.
+func symbolMentions(text string, files map[string]types.FileIndex) map[string][]string {
+	exporters := make(map[string][]string)
+	for path, fi := range files {
+		for _, exp := range fi.Exports {
+			exporters[exp.Name] = append(exporters[exp.Name], path)
+		}
+	}
+
+	mentions := make(map[string][]string)
+	for _, name := range mentionedIdentifiers(text) {
+		paths := uniqueStrings(exporters[name])
+		if len(paths) == 0 || len(paths) > maxMentionFiles {
+			continue
+		}
+		sort.Strings(paths)
+		mentions[name] = paths
+	}
+	return mentions
+}
+
+// addDecisionMentionEdges links a decision to the files exporting the
+// identifiers its content, reason and context mention, so "Use
+// PaymentGatewayClient for all charges" reaches the client without listing
+// it in related_files. Returns the symbols mentioned with their files and
+// the number of edges added.
+func (s *Server) addDecisionMentionEdges(decision *types.Decision) (map[string][]string, int) {
+	files, err := s.jsonStore.GetFilesIndex()
+	if err != nil || len(files) == 0 {
+		return nil, 0
+	}
+	mentions := symbolMentions(strings.Join([]string{decision.Content, decision.Reason, decision.Context}, "\n"), files)
+
+	related := make(map[string]bool, len(decision.RelatedFiles))
+	for _, f := range decision.RelatedFiles {
+		related[f] = true
+	}
+	linked := make(map[string]bool)
+	for _, paths := range mentions {
+		for _, path := range paths {
+			// Listed files already have an affects edge
+			if related[path] || linked[path] {
+				continue
+			}
+			linked[path] = true
+			s.jsonStore.AddEdge(&types.Edge{
+				FromType: "decision",
+				FromID:   decision.ID,
+				ToType:   "file",
+				ToID:     path,
+				Relation: "mentions",
+			})
+		}
+	}
+	return mentions, len(linked)
+}
+
+// decisionMentions maps each decision to the files it mentions by symbol
+func (s *Server) decisionMentions() map[string][]string {
+	graph, err := s.jsonStore.GetKnowledgeGraph()
+	if err != nil {
+		return nil
+	}
+	mentions := make(map[string][]string)
+	for _, e := range graph.Edges {
+		if e.Relation == "mentions" && e.FromType == "decision" && e.ToType == "file" {
+			mentions[e.FromID] = append(mentions[e.FromID], e.ToID)
+		}
+	}
+	return mentions
+}
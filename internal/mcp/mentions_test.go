@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestMentionedIdentifiers(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Use PaymentGatewayClient for all charges", []string{"PaymentGatewayClient"}},
+		{"Call `payments.NewClient()` once", []string{"payments", "NewClient"}},
+		{"Keep retry_policy and maxRetries in sync", []string{"retry_policy", "maxRetries"}},
+		{"Postgres is the Source of truth", nil},
+		{"Wrap `db` in `TxRunner`", []string{"TxRunner"}},
+		{"__init__ and _private are not mentions", nil},
+		{"`NewClient` and NewClient count once", []string{"NewClient"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := mentionedIdentifiers(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mentionedIdentifiers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbolMentionsSkipsAmbiguousNames(t *testing.T) {
+	files := map[string]types.FileIndex{
+		"pay/client.go": {Exports: []types.Export{{Name: "PaymentGatewayClient"}, {Name: "NewClient"}}},
+		"pay/mock.go":   {Exports: []types.Export{{Name: "PaymentGatewayClient"}}},
+	}
+	for _, path := range []string{"a.go", "b.go", "c.go", "d.go"} {
+		files[path] = types.FileIndex{Exports: []types.Export{{Name: "HandleRequest"}}}
+	}
+
+	got := symbolMentions("Use PaymentGatewayClient, not HandleRequest or UnknownThing", files)
+	want := map[string][]string{"PaymentGatewayClient": {"pay/client.go", "pay/mock.go"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mentions = %v, want %v (HandleRequest is exported by too many files)", got, want)
+	}
+}
+
+func TestAddDecisionLinksMentionedSymbols(t *testing.T) {
+	s := setupTestServer(t)
+	files := map[string]types.FileIndex{
+		"pay/client.go": {Path: "pay/client.go", Language: "go", Exports: []types.Export{{Name: "PaymentGatewayClient", Kind: "struct"}}},
+		"pay/retry.go":  {Path: "pay/retry.go", Language: "go", Exports: []types.Export{{Name: "retry_policy", Kind: "variable"}}},
+		"pay/ledger.go": {Path: "pay/ledger.go", Language: "go", Exports: []types.Export{{Name: "LedgerEntry", Kind: "struct"}}},
+	}
+	if err := s.jsonStore.SaveFilesIndexBulk(files); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+
+	result := resultMap(t, mustCall(t, s, "add_decision", map[string]interface{}{
+		"content":       "Use PaymentGatewayClient for all charges",
+		"reason":        "the retry_policy lives there",
+		"context":       "LedgerEntry rows are written by the caller",
+		"related_files": []string{"pay/ledger.go"},
+	}))
+	want := map[string][]string{
+		"PaymentGatewayClient": {"pay/client.go"},
+		"retry_policy":         {"pay/retry.go"},
+		"LedgerEntry":          {"pay/ledger.go"},
+	}
+	if got := result["linked_symbols"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("linked_symbols = %v, want %v", got, want)
+	}
+	// One affects edge, the feature edge and two mentions: the listed
+	// ledger file is not linked twice
+	if got := result["graph_edges_created"]; got != 4 {
+		t.Errorf("graph_edges_created = %v, want 4", got)
+	}
+
+	mentioned := s.decisionMentions()[result["id"].(string)]
+	sort.Strings(mentioned)
+	if !reflect.DeepEqual(mentioned, []string{"pay/client.go", "pay/retry.go"}) {
+		t.Errorf("mentions edges = %v, want client and retry only", mentioned)
+	}
+
+	plain := resultMap(t, mustCall(t, s, "add_decision", map[string]interface{}{"content": "Invoices are immutable", "reason": "audit"}))
+	if _, ok := plain["linked_symbols"]; ok {
+		t.Errorf("decision without identifiers has linked_symbols: %v", plain["linked_symbols"])
+	}
+}
+
+func TestGetContextFindsDecisionsByMention(t *testing.T) {
+	s := setupTestServer(t)
+	files := map[string]types.FileIndex{
+		"pay/client.go": {Path: "pay/client.go", Language: "go", Exports: []types.Export{{Name: "PaymentGatewayClient", Kind: "struct"}}},
+	}
+	if err := s.jsonStore.SaveFilesIndexBulk(files); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Use PaymentGatewayClient for all charges", "reason": "one place to retry"})
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": "Invoices are immutable", "reason": "audit"})
+
+	result := mustCall(t, s, "get_context", map[string]interface{}{
+		"intent":       "add refunds",
+		"target_files": []string{"pay/client.go"},
+	}).(*types.ContextResponse)
+	if len(result.Decisions) != 1 || result.Decisions[0].Content != "Use PaymentGatewayClient for all charges" {
+		t.Errorf("decisions = %+v, want only the one mentioning the client", result.Decisions)
+	}
+}
@@ -0,0 +1,272 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/tokenest"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// baselineTokensKey is set by handlers that replace something larger (raw
+// files, full conversations) with a compact answer. It holds the token cost
+// of that raw material and is consumed by applyTokenMetrics.
+const baselineTokensKey = "baseline_tokens"
+
+// TokenMetrics is the token accounting attached to every tool response
+type TokenMetrics struct {
+	TokensUsed     int `json:"tokens_used"`
+	TokensSaved    int `json:"tokens_saved"`
+	BaselineTokens int `json:"baseline_tokens,omitempty"`
+	SavingsPercent int `json:"savings_percent,omitempty"`
+}
+
+// applyTokenMetrics computes tokens_used/tokens_saved for a tool result and
+// embeds them in the body so agents see them inline: as keys of map results,
+// and in the TokensUsed/TokensSaved fields of struct results that have them
+// (e.g. *types.QueryResponse). Every result also carries them in _meta.
+// It returns the serialized result and the metrics.
+func applyTokenMetrics(result interface{}) ([]byte, TokenMetrics) {
+	var m TokenMetrics
+
+	body, isMap := result.(map[string]interface{})
+	if isMap {
+		if b, ok := body[baselineTokensKey].(int); ok {
+			m.BaselineTokens = b
+		}
+		delete(body, baselineTokensKey)
+	}
+	used, saved, hasFields := tokenFields(result)
+	if hasFields {
+		used.SetInt(0)
+		saved.SetInt(0)
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	m.TokensUsed = tokenest.Count(string(resultJSON))
+	if m.BaselineTokens > m.TokensUsed {
+		m.TokensSaved = m.BaselineTokens - m.TokensUsed
+		m.SavingsPercent = m.TokensSaved * 100 / m.BaselineTokens
+	}
+
+	switch {
+	case isMap:
+		body["tokens_used"] = m.TokensUsed
+		body["tokens_saved"] = m.TokensSaved
+		if m.BaselineTokens > 0 {
+			body["savings_percent"] = m.SavingsPercent
+		}
+		resultJSON, _ = json.MarshalIndent(body, "", "  ")
+	case hasFields:
+		used.SetInt(int64(m.TokensUsed))
+		saved.SetInt(int64(m.TokensSaved))
+		resultJSON, _ = json.MarshalIndent(result, "", "  ")
+	}
+
+	return resultJSON, m
+}
+
+// tokenFields returns the settable int TokensUsed and TokensSaved fields of
+// a struct pointer result
+func tokenFields(result interface{}) (used, saved reflect.Value, ok bool) {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return used, saved, false
+	}
+	used = v.Elem().FieldByName("TokensUsed")
+	saved = v.Elem().FieldByName("TokensSaved")
+	if !used.IsValid() || !saved.IsValid() || used.Kind() != reflect.Int || saved.Kind() != reflect.Int {
+		return used, saved, false
+	}
+	return used, saved, used.CanSet() && saved.CanSet()
+}
+
+// tokenSpendFlushCalls and tokenSpendFlushInterval bound how much spend is
+// held in memory before it is merged into cache/token-spend.json
+const (
+	tokenSpendFlushCalls    = 20
+	tokenSpendFlushInterval = 30 * time.Second
+)
+
+// tokenLedger keeps the token spend of this session in memory so a tool call
+// doesn't read and rewrite the ledger file
+type tokenLedger struct {
+	mu        sync.Mutex
+	totals    map[string]int // tokens used per feature, including pending spend
+	budgets   map[string]int // token budget per feature, 0 for none
+	pending   map[string]*types.FeatureTokenSpend
+	calls     int // calls recorded since the last flush
+	lastFlush time.Time
+}
+
+// recordTokenSpend charges a tool response to a feature's token ledger,
+// attributing it to the directories named in the call's arguments, and
+// returns the feature's total spend including this call.
+func (s *Server) recordTokenSpend(feature, toolName string, args json.RawMessage, m TokenMetrics) int {
+	l := &s.tokenSpend
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.totals == nil {
+		l.totals = make(map[string]int)
+		if ledger, err := s.jsonStore.GetTokenSpend(); err == nil {
+			for id, spend := range ledger {
+				l.totals[id] = spend.TokensUsed
+			}
+		}
+		l.lastFlush = time.Now()
+	}
+	if l.pending == nil {
+		l.pending = make(map[string]*types.FeatureTokenSpend)
+	}
+
+	now := time.Now()
+	spend, ok := l.pending[feature]
+	if !ok {
+		spend = &types.FeatureTokenSpend{
+			Feature:   feature,
+			FirstSeen: now,
+			ByTool:    make(map[string]*types.ToolTokenSpend),
+			ByArea:    make(map[string]int),
+		}
+		l.pending[feature] = spend
+	}
+	spend.ToolCalls++
+	spend.TokensUsed += m.TokensUsed
+	spend.TokensSaved += m.TokensSaved
+	spend.LastSeen = now
+
+	ts, ok := spend.ByTool[toolName]
+	if !ok {
+		ts = &types.ToolTokenSpend{}
+		spend.ByTool[toolName] = ts
+	}
+	ts.Calls++
+	ts.TokensUsed += m.TokensUsed
+	ts.TokensSaved += m.TokensSaved
+
+	// Split the response's tokens across the areas it covered
+	if areas := spendAreas(args); len(areas) > 0 {
+		share := m.TokensUsed / len(areas)
+		for _, area := range areas {
+			spend.ByArea[area] += share
+		}
+	}
+
+	l.totals[feature] += m.TokensUsed
+	l.calls++
+	if l.calls >= tokenSpendFlushCalls || now.Sub(l.lastFlush) >= tokenSpendFlushInterval {
+		s.flushTokenSpendLocked()
+	}
+	return l.totals[feature]
+}
+
+// flushTokenSpend merges the spend held in memory into the ledger file
+func (s *Server) flushTokenSpend() {
+	s.tokenSpend.mu.Lock()
+	defer s.tokenSpend.mu.Unlock()
+	s.flushTokenSpendLocked()
+}
+
+func (s *Server) flushTokenSpendLocked() {
+	l := &s.tokenSpend
+	if err := s.jsonStore.MergeTokenSpend(l.pending); err != nil {
+		// Keep the spend and try again on the next flush
+		fmt.Fprintf(os.Stderr, "Recording token spend failed: %v\n", err)
+		return
+	}
+	l.pending = nil
+	l.calls = 0
+	l.lastFlush = time.Now()
+	// Budgets may have been set since they were cached
+	l.budgets = nil
+}
+
+// featureBudget returns a feature's token budget, cached between flushes
+func (s *Server) featureBudget(feature string) int {
+	l := &s.tokenSpend
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if budget, ok := l.budgets[feature]; ok {
+		return budget
+	}
+	budget := 0
+	if f, err := s.jsonStore.GetFeature(feature); err == nil && f != nil {
+		budget = f.TokenBudget
+	}
+	if l.budgets == nil {
+		l.budgets = make(map[string]int)
+	}
+	l.budgets[feature] = budget
+	return budget
+}
+
+// forgetFeatureBudget drops a cached budget, e.g. when the feature is
+// (re)created with a new one
+func (s *Server) forgetFeatureBudget(feature string) {
+	s.tokenSpend.mu.Lock()
+	defer s.tokenSpend.mu.Unlock()
+	delete(s.tokenSpend.budgets, feature)
+}
+
+// spendAreas returns the directories a tool call targeted, from the same
+// path arguments session tracking uses.
+func spendAreas(args json.RawMessage) []string {
+	var argMap map[string]interface{}
+	if err := json.Unmarshal(args, &argMap); err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, key := range []string{"path", "file", "file_path", "directory"} {
+		if v, ok := argMap[key].(string); ok && v != "" {
+			paths = append(paths, v)
+		}
+	}
+	if files, ok := argMap["files"].([]interface{}); ok {
+		for _, f := range files {
+			if fs, ok := f.(string); ok && fs != "" {
+				paths = append(paths, fs)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var areas []string
+	for _, p := range paths {
+		area := strings.Trim(filepath.ToSlash(p), "/")
+		if filepath.Ext(area) != "" {
+			area = filepath.ToSlash(filepath.Dir(area))
+		}
+		if area == "" {
+			area = "."
+		}
+		if !seen[area] {
+			seen[area] = true
+			areas = append(areas, area)
+		}
+	}
+	return areas
+}
+
+// tokenBudgetWarning returns a warning the first time in a session that a
+// feature's spend, as returned by recordTokenSpend, reaches its token budget.
+func (s *Server) tokenBudgetWarning(feature string, used int) string {
+	if s.session.BudgetWarned[feature] {
+		return ""
+	}
+	budget := s.featureBudget(feature)
+	if budget <= 0 || used < budget {
+		return ""
+	}
+
+	s.session.BudgetWarned[feature] = true
+	return fmt.Sprintf("Feature '%s' has used %d of its %d token budget. Call get_token_report to see which tools and areas cost the most.",
+		feature, used, budget)
+}
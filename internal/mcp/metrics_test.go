@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestApplyTokenMetricsMap(t *testing.T) {
+	result := map[string]interface{}{
+		"summary":         "compact answer",
+		baselineTokensKey: 1000,
+	}
+	data, m := applyTokenMetrics(result)
+	if m.TokensUsed == 0 || m.BaselineTokens != 1000 || m.TokensSaved != 1000-m.TokensUsed {
+		t.Fatalf("metrics = %+v", m)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body[baselineTokensKey]; ok {
+		t.Error("baseline_tokens key should be consumed")
+	}
+	if int(body["tokens_used"].(float64)) != m.TokensUsed || int(body["tokens_saved"].(float64)) != m.TokensSaved {
+		t.Errorf("body metrics = %v/%v, want %+v", body["tokens_used"], body["tokens_saved"], m)
+	}
+	if body["savings_percent"] == nil {
+		t.Error("savings_percent missing with a baseline")
+	}
+}
+
+func TestApplyTokenMetricsStruct(t *testing.T) {
+	result := &types.QueryResponse{Answer: "Payments are retried by the worker"}
+	data, m := applyTokenMetrics(result)
+	if m.TokensUsed == 0 {
+		t.Fatal("no tokens counted for a struct result")
+	}
+	if result.TokensUsed != m.TokensUsed {
+		t.Errorf("QueryResponse.TokensUsed = %d, want %d", result.TokensUsed, m.TokensUsed)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatal(err)
+	}
+	if int(body["tokens_used"].(float64)) != m.TokensUsed {
+		t.Errorf("serialized tokens_used = %v, want %d", body["tokens_used"], m.TokensUsed)
+	}
+
+	// Stale values set by a handler are not counted or kept
+	result.TokensUsed = 99999
+	_, again := applyTokenMetrics(result)
+	if again.TokensUsed != m.TokensUsed {
+		t.Errorf("recount = %d, want %d", again.TokensUsed, m.TokensUsed)
+	}
+}
+
+func TestApplyTokenMetricsOtherResults(t *testing.T) {
+	for _, result := range []interface{}{
+		[]string{"a", "b"},
+		"plain text",
+		&types.Decision{Content: "no token fields"},
+	} {
+		if _, m := applyTokenMetrics(result); m.TokensUsed == 0 {
+			t.Errorf("%T: no tokens counted", result)
+		}
+	}
+
+	// A nil struct pointer has no fields to set
+	if data, _ := applyTokenMetrics((*types.QueryResponse)(nil)); string(data) != "null" {
+		t.Errorf("nil result serialized as %s", data)
+	}
+}
+
+func TestTokenBudgetWarningCrossesOnTheCall(t *testing.T) {
+	s := setupTestServer(t)
+	if err := s.jsonStore.CreateFeature(&types.Feature{ID: "payments", TokenBudget: 100}); err != nil {
+		t.Fatalf("CreateFeature: %v", err)
+	}
+
+	steps := []struct {
+		used     int
+		total    int
+		warnings bool
+	}{
+		{60, 60, false},
+		{40, 100, true},  // reaching the budget warns on this call, not the next
+		{10, 110, false}, // once per session
+	}
+	for i, step := range steps {
+		total := s.recordTokenSpend("payments", "search", nil, TokenMetrics{TokensUsed: step.used})
+		if total != step.total {
+			t.Fatalf("step %d: total = %d, want %d", i, total, step.total)
+		}
+		warning := s.tokenBudgetWarning("payments", total)
+		if (warning != "") != step.warnings {
+			t.Errorf("step %d: warning = %q, want warning=%v", i, warning, step.warnings)
+		}
+	}
+
+	// No budget, no warning
+	total := s.recordTokenSpend("_global", "search", nil, TokenMetrics{TokensUsed: 1 << 20})
+	if warning := s.tokenBudgetWarning("_global", total); warning != "" {
+		t.Errorf("warning without a budget: %q", warning)
+	}
+}
+
+// toolsCallOutput runs a tools/call request through the server and returns
+// what it wrote to stdout
+func toolsCallOutput(t *testing.T, s *Server, name string, args map[string]interface{}) string {
+	t.Helper()
+	params, err := json.Marshal(map[string]interface{}{"name": name, "arguments": args})
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	s.handleToolsCall(&Request{JSONRPC: "2.0", ID: 1, Method: "tools/call", Params: params})
+	w.Close()
+	return string(<-done)
+}
+
+func TestTokenBudgetWarningInToolResponse(t *testing.T) {
+	s := setupTestServer(t)
+	mustCall(t, s, "start_feature", map[string]interface{}{"id": "tiny", "token_budget": 1})
+	s.session.ActiveFeature = "tiny"
+
+	// The first response alone exceeds a 1-token budget
+	resp := toolsCallOutput(t, s, "list_features", map[string]interface{}{})
+	if !strings.Contains(resp, "token_budget_warning") {
+		t.Errorf("first response over budget has no warning:\n%s", resp)
+	}
+	if resp := toolsCallOutput(t, s, "list_features", map[string]interface{}{}); strings.Contains(resp, "token_budget_warning") {
+		t.Errorf("warning repeated in the same session")
+	}
+}
+
+func TestTokenSpendFlushes(t *testing.T) {
+	s := setupTestServer(t)
+	// Spend from an earlier session counts toward the totals
+	if err := s.jsonStore.MergeTokenSpend(map[string]*types.FeatureTokenSpend{
+		"payments": {Feature: "payments", ToolCalls: 1, TokensUsed: 500,
+			ByTool: map[string]*types.ToolTokenSpend{"search": {Calls: 1, TokensUsed: 500}}},
+	}); err != nil {
+		t.Fatalf("MergeTokenSpend: %v", err)
+	}
+
+	args := json.RawMessage(`{"path": "internal/billing/charge.go"}`)
+	if total := s.recordTokenSpend("payments", "search", args, TokenMetrics{TokensUsed: 10}); total != 510 {
+		t.Errorf("total = %d, want 510", total)
+	}
+	ledger, _ := s.jsonStore.GetTokenSpend()
+	if ledger["payments"].TokensUsed != 500 {
+		t.Errorf("ledger written on every call: %d", ledger["payments"].TokensUsed)
+	}
+
+	for i := 1; i < tokenSpendFlushCalls; i++ {
+		s.recordTokenSpend("payments", "get_file", nil, TokenMetrics{TokensUsed: 1})
+	}
+	ledger, _ = s.jsonStore.GetTokenSpend()
+	spend := ledger["payments"]
+	if spend == nil || spend.ToolCalls != 1+tokenSpendFlushCalls || spend.TokensUsed != 510+tokenSpendFlushCalls-1 {
+		t.Fatalf("after %d calls ledger = %+v", tokenSpendFlushCalls, spend)
+	}
+	if spend.ByTool["search"].Calls != 2 || spend.ByTool["get_file"].Calls != tokenSpendFlushCalls-1 {
+		t.Errorf("by tool = search %+v, get_file %+v", spend.ByTool["search"], spend.ByTool["get_file"])
+	}
+	if spend.ByArea["internal/billing"] != 10 {
+		t.Errorf("by area = %v", spend.ByArea)
+	}
+
+	// The report flushes what is still pending
+	s.recordTokenSpend("payments", "search", nil, TokenMetrics{TokensUsed: 7})
+	report := resultMap(t, mustCall(t, s, "get_token_report", map[string]interface{}{"feature": "payments"}))
+	features := report["features"].([]map[string]interface{})
+	if got := features[0]["tokens_used"]; got != 510+tokenSpendFlushCalls-1+7 {
+		t.Errorf("report tokens_used = %v", got)
+	}
+}
@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// GET_CONTEXT PERSONAS
+// The same task needs different context depending on who is asking. A
+// persona decides which sections get_context fills, and in which order they
+// draw on the token budget.
+// =============================================================================
+
+// contextPersonas lists the sections each persona gets, most important
+// first. The empty persona is the default.
+var contextPersonas = map[string][]string{
+	"":            {"warnings", "decisions", "patterns"},
+	"implementer": {"patterns", "snippets", "warnings", "decisions"},
+	"reviewer":    {"warnings", "compliance", "correlations", "decisions", "patterns"},
+	"architect":   {"decisions", "architecture", "drift", "warnings", "patterns"},
+}
+
+// contextSections returns the sections for a persona
+func contextSections(persona string) ([]string, error) {
+	sections, ok := contextPersonas[persona]
+	if !ok {
+		return nil, fmt.Errorf("unknown persona %q (implementer, reviewer, architect)", persona)
+	}
+	return sections, nil
+}
+
+// contextSnippets returns code matching the intent, for implementers and
+// for query with include_code. Chunks outside the scope are skipped.
+func (s *Server) contextSnippets(intent string, limit int, scope *queryScope) []types.CodeSnippet {
+	fetch := limit
+	if scope.narrowed() {
+		fetch = limit * 6
+	}
+	chunks, err := s.sqliteIndex.SearchCodeContent(intent, "", fetch)
+	if err != nil {
+		return nil
+	}
+	snippets := make([]types.CodeSnippet, 0, limit)
+	for _, chunk := range chunks {
+		if !scope.allowsFile(chunk.FilePath, chunk.Language) {
+			continue
+		}
+		if len(snippets) == limit {
+			break
+		}
+		snippets = append(snippets, types.CodeSnippet{
+			Path:      chunk.FilePath,
+			StartLine: chunk.StartLine,
+			EndLine:   chunk.EndLine,
+			Content:   chunk.Content,
+			Context:   fmt.Sprintf("%s: %s", chunk.ChunkType, chunk.ChunkName),
+			Relevance: 1.0,
+		})
+	}
+	return snippets
+}
+
+// contextCompliance runs check_compliance on each target file, for reviewers.
+// Files that cannot be read are skipped.
+func (s *Server) contextCompliance(files []string) []types.ComplianceViolation {
+	var violations []types.ComplianceViolation
+	for _, f := range files {
+		params, _ := json.Marshal(map[string]string{"file_path": f})
+		result, err := s.handleCheckCompliance(params)
+		if err != nil {
+			continue
+		}
+		found, _ := result.(map[string]interface{})["violations"].([]types.ComplianceViolation)
+		for _, v := range found {
+			v.File = f
+			violations = append(violations, v)
+		}
+	}
+	// Blockers first
+	rank := map[string]int{"blocker": 0, "warning": 1, "info": 2}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return rank[violations[i].Severity] < rank[violations[j].Severity]
+	})
+	return violations
+}
+
+// contextCorrelations returns files that usually change with the target
+// files, strongest first, for reviewers. Only the cached git analysis is
+// used; get_context does not run git.
+func (s *Server) contextCorrelations(files []string, minCorrelation float64) []types.CoChangeHit {
+	var cached []git.FileCorrelation
+	if err := s.loadGitKnowledge("git-correlations.json", &cached); err != nil {
+		return nil
+	}
+	targets := make(map[string]bool, len(files))
+	for _, f := range files {
+		targets[f] = true
+	}
+	var hits []types.CoChangeHit
+	for _, c := range cached {
+		if c.Correlation < minCorrelation {
+			continue
+		}
+		f1, f2 := relpath.Normalize(c.File1), relpath.Normalize(c.File2)
+		switch {
+		case targets[f1] && !targets[f2]:
+			hits = append(hits, types.CoChangeHit{File: f2, ChangesWith: f1, Correlation: c.Correlation})
+		case targets[f2] && !targets[f1]:
+			hits = append(hits, types.CoChangeHit{File: f1, ChangesWith: f2, Correlation: c.Correlation})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Correlation > hits[j].Correlation })
+	return hits
+}
+
+// contextArchitecture returns the documented architecture narrowed to the
+// services owning the target files and the data flows between them, for
+// architects. Without target files the whole architecture is returned.
+func (s *Server) contextArchitecture(files []string) *types.Architecture {
+	arch, err := s.jsonStore.GetArchitecture()
+	if err != nil || arch == nil || (arch.Description == "" && len(arch.Services) == 0) {
+		return nil
+	}
+	if len(files) == 0 {
+		return arch
+	}
+	narrowed := &types.Architecture{Description: arch.Description, UpdatedAt: arch.UpdatedAt}
+	owners := make(map[string]bool)
+	for _, svc := range arch.Services {
+		for _, f := range files {
+			if serviceOwns(svc, f) {
+				narrowed.Services = append(narrowed.Services, svc)
+				owners[svc.Name] = true
+				break
+			}
+		}
+	}
+	for _, flow := range arch.DataFlows {
+		if owners[flow.From] || owners[flow.To] {
+			narrowed.DataFlows = append(narrowed.DataFlows, flow)
+		}
+	}
+	return narrowed
+}
+
+// contextDrift finds where the code has moved away from recorded knowledge,
+// for architects: decisions and warnings pointing at deleted files, and
+// target files importing services their own service does not declare as
+// dependencies.
+func (s *Server) contextDrift(files []string, decisions []types.Decision, warnings []types.Warning) []types.DriftFinding {
+	var findings []types.DriftFinding
+
+	deleted, _ := s.jsonStore.GetDeletedFiles()
+	if len(deleted) > 0 {
+		check := func(id, kind string, related []string) {
+			for _, f := range related {
+				if _, ok := deleted[relpath.Normalize(f)]; ok {
+					findings = append(findings, types.DriftFinding{
+						Kind:   "deleted_file",
+						File:   f,
+						ID:     id,
+						Detail: fmt.Sprintf("%s %s refers to %s, which has been deleted", kind, id, f),
+					})
+				}
+			}
+		}
+		for _, d := range decisions {
+			check(d.ID, "decision", d.RelatedFiles)
+		}
+		for _, w := range warnings {
+			check(w.ID, "warning", w.RelatedFiles)
+		}
+	}
+
+	arch, err := s.jsonStore.GetArchitecture()
+	if err != nil || arch == nil || len(arch.Services) == 0 {
+		return findings
+	}
+	ownerOf := func(file string) *types.ServiceNode {
+		for i := range arch.Services {
+			if serviceOwns(arch.Services[i], file) {
+				return &arch.Services[i]
+			}
+		}
+		return nil
+	}
+	for _, f := range files {
+		from := ownerOf(f)
+		if from == nil {
+			continue
+		}
+		edges, _ := s.jsonStore.GetEdgesFrom("file", f)
+		for _, e := range edges {
+			if e.Relation != "imports" || e.ToType != "file" {
+				continue
+			}
+			to := ownerOf(e.ToID)
+			if to == nil || to.Name == from.Name || containsString(from.Dependencies, to.Name) {
+				continue
+			}
+			findings = append(findings, types.DriftFinding{
+				Kind:   "undeclared_dependency",
+				File:   f,
+				Detail: fmt.Sprintf("%s (%s) imports %s from %s, which %s does not list as a dependency", f, from.Name, e.ToID, to.Name, from.Name),
+			})
+		}
+	}
+	return findings
+}
+
+// serviceOwns reports whether a file belongs to a service: one of the
+// service's files, or under one of its directories
+func serviceOwns(svc types.ServiceNode, file string) bool {
+	for _, f := range svc.Files {
+		if relpath.Within(file, f) && relpath.Normalize(f) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/internal/tokenest"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+const (
+	personaDecision = "Retry payments with backoff"
+	personaWarning  = "Retry payments idempotently"
+)
+
+// setupPersonaProject records knowledge, code, co-changes and architecture
+// around src/payments/client.go, enough for every persona section
+func setupPersonaProject(t *testing.T) *Server {
+	t.Helper()
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "src/payments/client.go", "package payments\n\nfunc Retry() {}\n")
+	if err := s.jsonStore.SaveFilesIndexBulk(map[string]types.FileIndex{
+		"src/payments/client.go": {Path: "src/payments/client.go", Language: "go"},
+		"src/payments/old.go":    {Path: "src/payments/old.go", Language: "go"},
+		"src/orders/api.go":      {Path: "src/orders/api.go", Language: "go"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.MarkFileDeleted("src/payments/old.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	mustCall(t, s, "add_decision", map[string]interface{}{"content": personaDecision, "reason": "rate limits", "related_files": []string{"src/payments/client.go", "src/payments/old.go"}})
+	mustCall(t, s, "add_warning", map[string]interface{}{"content": personaWarning, "reason": "double charges", "severity": "critical", "related_files": []string{"src/payments/client.go"}})
+
+	if err := s.sqliteIndex.IndexCodeChunks("src/payments/client.go", []storage.CodeChunk{
+		{FilePath: "src/payments/client.go", ChunkType: "function", ChunkName: "Retry", StartLine: 3, EndLine: 3, Content: "func Retry() {} // retry payments", Language: "go"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	correlations := `[
+		{"file1": "src/payments/client.go", "file2": "src/payments/ledger.go", "co_changes": 12, "correlation": 0.8},
+		{"file1": "README.md", "file2": "src/payments/client.go", "co_changes": 3, "correlation": 0.2}
+	]`
+	if err := os.WriteFile(filepath.Join(s.basePath, "knowledge", "git-correlations.json"), []byte(correlations), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.SaveArchitecture(&types.Architecture{
+		Description: "shop",
+		Services: []types.ServiceNode{
+			{Name: "payments", Files: []string{"src/payments"}},
+			{Name: "orders", Files: []string{"src/orders"}},
+			{Name: "billing", Files: []string{"src/billing"}},
+		},
+		DataFlows: []types.DataFlow{{From: "payments", To: "orders"}, {From: "billing", To: "reporting"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.AddEdge(&types.Edge{FromType: "file", FromID: "src/payments/client.go", ToType: "file", ToID: "src/orders/api.go", Relation: "imports"}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func personaContext(t *testing.T, s *Server, persona string, maxTokens int) *types.ContextResponse {
+	t.Helper()
+	return mustCall(t, s, "get_context", map[string]interface{}{
+		"intent":       "retry payments",
+		"target_files": []string{"src/payments/client.go"},
+		"persona":      persona,
+		"max_tokens":   maxTokens,
+	}).(*types.ContextResponse)
+}
+
+func TestGetContextPersonaSections(t *testing.T) {
+	s := setupPersonaProject(t)
+
+	tests := []struct {
+		persona                                         string
+		snippets, compliance, correlations, arch, drift bool
+	}{
+		{"", false, false, false, false, false},
+		{"implementer", true, false, false, false, false},
+		{"reviewer", false, true, true, false, false},
+		{"architect", false, false, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run("persona "+tt.persona, func(t *testing.T) {
+			r := personaContext(t, s, tt.persona, 0)
+			if len(r.Decisions) != 1 || len(r.Warnings) != 1 {
+				t.Errorf("decisions = %d, warnings = %d; every persona gets both", len(r.Decisions), len(r.Warnings))
+			}
+			if got := len(r.Snippets) > 0; got != tt.snippets {
+				t.Errorf("snippets = %v, want present %v", r.Snippets, tt.snippets)
+			}
+			if got := len(r.Compliance) > 0; got != tt.compliance {
+				t.Errorf("compliance = %v, want present %v", r.Compliance, tt.compliance)
+			}
+			if got := len(r.Correlations) > 0; got != tt.correlations {
+				t.Errorf("correlations = %v, want present %v", r.Correlations, tt.correlations)
+			}
+			if got := r.Architecture != nil; got != tt.arch {
+				t.Errorf("architecture = %+v, want present %v", r.Architecture, tt.arch)
+			}
+			if got := len(r.Drift) > 0; got != tt.drift {
+				t.Errorf("drift = %v, want present %v", r.Drift, tt.drift)
+			}
+		})
+	}
+
+	if _, err := callTool(t, s, "get_context", map[string]interface{}{"intent": "retry payments", "persona": "manager"}); err == nil {
+		t.Error("unknown persona accepted")
+	}
+}
+
+func TestGetContextReviewer(t *testing.T) {
+	s := setupPersonaProject(t)
+	r := personaContext(t, s, "reviewer", 0)
+
+	if len(r.Compliance) != 2 || r.Compliance[0].Severity != "blocker" || r.Compliance[0].File != "src/payments/client.go" {
+		t.Errorf("compliance = %+v, want the warning as a blocker first, then the decision", r.Compliance)
+	}
+	want := types.CoChangeHit{File: "src/payments/ledger.go", ChangesWith: "src/payments/client.go", Correlation: 0.8}
+	if len(r.Correlations) != 1 || r.Correlations[0] != want {
+		t.Errorf("correlations = %+v, want only %+v above the threshold", r.Correlations, want)
+	}
+}
+
+func TestGetContextArchitect(t *testing.T) {
+	s := setupPersonaProject(t)
+	r := personaContext(t, s, "architect", 0)
+
+	if len(r.Architecture.Services) != 1 || r.Architecture.Services[0].Name != "payments" {
+		t.Errorf("services = %+v, want only payments, which owns the target file", r.Architecture.Services)
+	}
+	if len(r.Architecture.DataFlows) != 1 || r.Architecture.DataFlows[0].To != "orders" {
+		t.Errorf("data flows = %+v, want only payments -> orders", r.Architecture.DataFlows)
+	}
+	kinds := make(map[string]string)
+	for _, f := range r.Drift {
+		kinds[f.Kind] = f.File
+	}
+	if kinds["deleted_file"] != "src/payments/old.go" || kinds["undeclared_dependency"] != "src/payments/client.go" {
+		t.Errorf("drift = %+v, want the deleted file and the undeclared orders dependency", r.Drift)
+	}
+
+	// Declaring the dependency resolves the drift
+	arch, _ := s.jsonStore.GetArchitecture()
+	arch.Services[0].Dependencies = []string{"orders"}
+	if err := s.jsonStore.SaveArchitecture(arch); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range personaContext(t, s, "architect", 0).Drift {
+		if f.Kind == "undeclared_dependency" {
+			t.Errorf("declared dependency still reported: %+v", f)
+		}
+	}
+}
+
+func TestGetContextPersonaBudgetOrder(t *testing.T) {
+	s := setupPersonaProject(t)
+	decisionCost := tokenest.Count(personaDecision + "rate limits")
+	warningCost := tokenest.Count(personaWarning + "double charges")
+	budget := decisionCost
+	if warningCost > budget {
+		budget = warningCost
+	}
+	if decisionCost+warningCost <= budget {
+		t.Fatal("budget fits both items")
+	}
+
+	// With room for one item, the persona's first section wins
+	if r := personaContext(t, s, "reviewer", budget); len(r.Warnings) != 1 || len(r.Decisions) != 0 {
+		t.Errorf("reviewer: warnings = %d, decisions = %d; want the warning", len(r.Warnings), len(r.Decisions))
+	}
+	if r := personaContext(t, s, "architect", budget); len(r.Decisions) != 1 || len(r.Warnings) != 0 {
+		t.Errorf("architect: warnings = %d, decisions = %d; want the decision", len(r.Warnings), len(r.Decisions))
+	}
+}
+
+func TestServiceOwns(t *testing.T) {
+	svc := types.ServiceNode{Name: "payments", Files: []string{"src/payments", "cmd/pay/main.go", ""}}
+	for file, want := range map[string]bool{
+		"src/payments/client.go": true,
+		"src/payments":           true,
+		"cmd/pay/main.go":        true,
+		"src/payments-old/x.go":  false,
+		"src/orders/api.go":      false,
+		"README.md":              false,
+	} {
+		if got := serviceOwns(svc, file); got != want {
+			t.Errorf("serviceOwns(%s) = %v, want %v", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// PLANS
+// Machine-readable task state: an agent saves its plan for a feature, marks
+// steps as it works, and picks the plan up again in a later session
+// =============================================================================
+
+// planStepStatuses are the states a step can be in; done and skipped
+// steps are finished
+var planStepStatuses = map[string]bool{
+	"pending": true, "in_progress": true, "done": true, "blocked": true, "skipped": true,
+}
+
+func (s *Server) handleSavePlan(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Feature string           `json:"feature"`
+		Goal    string           `json:"goal"`
+		Steps   []types.PlanStep `json:"steps"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Feature == "" {
+		p.Feature = s.session.ActiveFeature
+	}
+	if p.Feature == "" {
+		return nil, fmt.Errorf("feature is required")
+	}
+	if p.Goal == "" {
+		return nil, fmt.Errorf("goal is required")
+	}
+	if len(p.Steps) == 0 {
+		return nil, fmt.Errorf("steps are required")
+	}
+	if _, err := s.jsonStore.GetFeature(p.Feature); err != nil {
+		return nil, fmt.Errorf("feature not found: %w", err)
+	}
+	for i := range p.Steps {
+		step := &p.Steps[i]
+		if strings.TrimSpace(step.Description) == "" {
+			return nil, fmt.Errorf("step %d has no description", i+1)
+		}
+		if step.Status != "" && !planStepStatuses[step.Status] {
+			return nil, fmt.Errorf("step %d: unknown status %q", i+1, step.Status)
+		}
+		step.Files = relpath.NormalizeAll(step.Files)
+		step.UpdatedAt = nil
+	}
+
+	plan := &types.Plan{
+		Feature: p.Feature,
+		Goal:    p.Goal,
+		Steps:   p.Steps,
+		Author:  s.currentAuthor(),
+	}
+	if err := s.jsonStore.SavePlan(plan); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":        plan.ID,
+		"feature":   plan.Feature,
+		"steps":     len(plan.Steps),
+		"next_step": nextPlanStep(plan),
+	}, nil
+}
+
+func (s *Server) handleUpdatePlan(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		PlanID   string           `json:"plan_id"`
+		Step     int              `json:"step"`
+		Status   string           `json:"status"`
+		Note     string           `json:"note"`
+		AddSteps []types.PlanStep `json:"add_steps"`
+		Abandon  bool             `json:"abandon"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.PlanID == "" {
+		return nil, fmt.Errorf("plan_id is required")
+	}
+	if p.Step == 0 && len(p.AddSteps) == 0 && !p.Abandon {
+		return nil, fmt.Errorf("nothing to update: pass step with status or note, add_steps, or abandon")
+	}
+	if (p.Status != "" || p.Note != "") && p.Step == 0 {
+		return nil, fmt.Errorf("step is required with status or note")
+	}
+	if p.Status != "" && !planStepStatuses[p.Status] {
+		return nil, fmt.Errorf("unknown status %q: use pending, in_progress, done, blocked or skipped", p.Status)
+	}
+
+	plan, err := s.jsonStore.UpdatePlan(p.PlanID, func(plan *types.Plan) error {
+		now := time.Now()
+		if p.Step != 0 {
+			if p.Step < 1 || p.Step > len(plan.Steps) {
+				return fmt.Errorf("step %d out of range: the plan has %d steps", p.Step, len(plan.Steps))
+			}
+			step := &plan.Steps[p.Step-1]
+			if p.Status != "" {
+				step.Status = p.Status
+			}
+			if p.Note != "" {
+				step.Note = p.Note
+			}
+			step.UpdatedAt = &now
+		}
+		for _, step := range p.AddSteps {
+			if strings.TrimSpace(step.Description) == "" {
+				return fmt.Errorf("added steps need a description")
+			}
+			step.Files = relpath.NormalizeAll(step.Files)
+			step.Status = "pending"
+			step.UpdatedAt = nil
+			plan.Steps = append(plan.Steps, step)
+		}
+
+		switch {
+		case p.Abandon || plan.Status == "abandoned":
+			plan.Status = "abandoned"
+		case nextPlanStep(plan) == nil:
+			plan.Status = "completed"
+		default:
+			plan.Status = "active"
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return planProgress(plan), nil
+}
+
+func (s *Server) handleGetPlan(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		PlanID  string `json:"plan_id"`
+		Feature string `json:"feature"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.PlanID != "" {
+		plan, err := s.jsonStore.GetPlan(p.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		return planProgress(plan), nil
+	}
+
+	if p.Feature == "" {
+		p.Feature = s.session.ActiveFeature
+	}
+	if p.Feature == "" {
+		return nil, fmt.Errorf("plan_id or feature is required")
+	}
+	plans, err := s.jsonStore.GetPlans(p.Feature)
+	if err != nil {
+		return nil, err
+	}
+	// The most recent plan still in progress, else the most recent one
+	for i := len(plans) - 1; i >= 0; i-- {
+		if plans[i].Status == "active" {
+			return planProgress(&plans[i]), nil
+		}
+	}
+	if len(plans) > 0 {
+		return planProgress(&plans[len(plans)-1]), nil
+	}
+	return nil, fmt.Errorf("feature %s has no plans", p.Feature)
+}
+
+// nextPlanStep is the first step not finished, the one to resume with;
+// nil when every step is done or skipped
+func nextPlanStep(plan *types.Plan) map[string]interface{} {
+	for i, step := range plan.Steps {
+		if step.Status != "done" && step.Status != "skipped" {
+			return map[string]interface{}{
+				"step":        i + 1,
+				"description": step.Description,
+				"files":       step.Files,
+				"status":      step.Status,
+			}
+		}
+	}
+	return nil
+}
+
+// planProgress is a plan as returned to agents, with its progress counted
+func planProgress(plan *types.Plan) map[string]interface{} {
+	finished := 0
+	for _, step := range plan.Steps {
+		if step.Status == "done" || step.Status == "skipped" {
+			finished++
+		}
+	}
+	result := map[string]interface{}{
+		"plan":     plan,
+		"progress": fmt.Sprintf("%d/%d steps finished", finished, len(plan.Steps)),
+	}
+	if next := nextPlanStep(plan); next != nil && plan.Status == "active" {
+		result["next_step"] = next
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestPlanLifecycle(t *testing.T) {
+	s := setupTestServer(t)
+	mustCall(t, s, "start_feature", map[string]interface{}{"id": "refunds", "description": "Partial refunds"})
+
+	if _, err := callTool(t, s, "save_plan", map[string]interface{}{"feature": "missing", "goal": "x", "steps": []map[string]interface{}{{"description": "y"}}}); err == nil {
+		t.Error("a plan for a missing feature should be rejected")
+	}
+	saved := resultMap(t, mustCall(t, s, "save_plan", map[string]interface{}{
+		"feature": "refunds",
+		"goal":    "Ship partial refunds",
+		"steps": []map[string]interface{}{
+			{"description": "Add the refund endpoint", "files": []string{"./billing/refund.go"}},
+			{"description": "Add the refund button"},
+			{"description": "Document the API"},
+		},
+	}))
+	id := saved["id"].(string)
+
+	mustCall(t, s, "update_plan", map[string]interface{}{"plan_id": id, "step": 1, "status": "done"})
+	mustCall(t, s, "update_plan", map[string]interface{}{"plan_id": id, "step": 2, "status": "blocked", "note": "waiting on design"})
+	if _, err := callTool(t, s, "update_plan", map[string]interface{}{"plan_id": id, "step": 4, "status": "done"}); err == nil {
+		t.Error("a step out of range should be rejected")
+	}
+	if _, err := callTool(t, s, "update_plan", map[string]interface{}{"plan_id": id, "step": 1, "status": "finished"}); err == nil {
+		t.Error("an unknown status should be rejected")
+	}
+
+	// A later session resumes from the feature alone
+	resumed := resultMap(t, mustCall(t, s, "get_plan", map[string]interface{}{"feature": "refunds"}))
+	plan := resumed["plan"].(*types.Plan)
+	if plan.ID != id || plan.Status != "active" || plan.Steps[0].Files[0] != "billing/refund.go" || plan.Steps[1].Note != "waiting on design" {
+		t.Errorf("plan = %+v", plan)
+	}
+	if next := resumed["next_step"].(map[string]interface{}); next["step"] != 2 || next["status"] != "blocked" {
+		t.Errorf("next step = %v, want the blocked step 2", next)
+	}
+	if resumed["progress"] != "1/3 steps finished" {
+		t.Errorf("progress = %v", resumed["progress"])
+	}
+
+	ctx := resultMap(t, mustCall(t, s, "resume_context", map[string]interface{}{"feature": "refunds"}))
+	if plans, _ := ctx["plans"].([]map[string]interface{}); len(plans) != 1 {
+		t.Errorf("resume_context plans = %v", ctx["plans"])
+	}
+	handoff := mustCall(t, s, "handoff_feature", map[string]interface{}{"feature": "refunds"}).(*types.FeatureHandoff)
+	steps := 0
+	for _, item := range handoff.OpenItems {
+		if item.Kind == "plan_step" {
+			steps++
+		}
+	}
+	if steps != 2 {
+		t.Errorf("handoff open items = %+v, want the 2 unfinished steps", handoff.OpenItems)
+	}
+
+	mustCall(t, s, "update_plan", map[string]interface{}{"plan_id": id, "step": 2, "status": "done"})
+	done := resultMap(t, mustCall(t, s, "update_plan", map[string]interface{}{"plan_id": id, "step": 3, "status": "skipped"}))
+	if plan := done["plan"].(*types.Plan); plan.Status != "completed" || done["next_step"] != nil {
+		t.Errorf("finished plan = %+v, next = %v", plan, done["next_step"])
+	}
+}
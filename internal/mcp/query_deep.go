@@ -0,0 +1,306 @@
+package mcp
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Deep queries spend longer on a question: they follow the knowledge graph
+// from what the quick searches found, search commit history and every saved
+// conversation, and name the experts of each file in the answer. Each
+// search runs only while the latency budget lasts.
+const (
+	defaultDeepBudget = 8 * time.Second
+	maxDeepBudget     = 30 * time.Second
+
+	deepGraphHops      = 2   // how far the graph is followed from a hit
+	deepHistoryCommits = 500 // most recent commits searched
+	deepHistoryHits    = 10
+	deepExpertsPerArea = 3
+)
+
+// deepQuery widens the hits of a query with the searches of deep mode
+type deepQuery struct {
+	s         *Server
+	query     string
+	terms     []string
+	scope     *queryScope
+	hits      *relevanceSet
+	decisions map[string]types.Decision  // in scope, by ID
+	warnings  map[string]types.Warning   // in scope, by ID
+	files     map[string]types.FileIndex // found so far, by path
+	indexed   map[string]types.FileIndex // loaded when first needed
+	history   []types.HistoryHit
+	start     time.Time
+	deadline  time.Time
+	report    *types.DeepAnalysis
+}
+
+// deepBudget is the latency budget asked for, defaulted and capped
+func deepBudget(budgetMs int) time.Duration {
+	budget := time.Duration(budgetMs) * time.Millisecond
+	if budget <= 0 {
+		return defaultDeepBudget
+	}
+	if budget > maxDeepBudget {
+		return maxDeepBudget
+	}
+	return budget
+}
+
+func newDeepQuery(s *Server, query string, budget time.Duration) *deepQuery {
+	now := time.Now()
+	return &deepQuery{
+		s:        s,
+		query:    query,
+		terms:    extractKeyTerms(strings.ToLower(query)),
+		start:    now,
+		deadline: now.Add(budget),
+		report:   &types.DeepAnalysis{BudgetMs: budget.Milliseconds()},
+	}
+}
+
+func (d *deepQuery) expired() bool {
+	return !time.Now().Before(d.deadline)
+}
+
+// step runs one search when there is budget left. run returns false when
+// the budget ran out before it finished.
+func (d *deepQuery) step(name string, run func() bool) {
+	if d.expired() || !run() {
+		d.report.Skipped = append(d.report.Skipped, name)
+		return
+	}
+	d.report.Steps = append(d.report.Steps, name)
+}
+
+// finish records how long the deep searches took
+func (d *deepQuery) finish() *types.DeepAnalysis {
+	d.report.ElapsedMs = time.Since(d.start).Milliseconds()
+	return d.report
+}
+
+// file looks up an indexed file the scope allows
+func (d *deepQuery) file(p string) (types.FileIndex, bool) {
+	if f, ok := d.files[p]; ok {
+		return f, true
+	}
+	if d.indexed == nil {
+		d.indexed, _ = d.s.jsonStore.GetFilesIndex()
+	}
+	f, ok := d.indexed[p]
+	if !ok || f.DeletedAt != nil || !d.scope.allowsFile(f.Path, f.Language) {
+		return types.FileIndex{}, false
+	}
+	d.files[p] = f
+	return f, true
+}
+
+// graph follows the knowledge graph out from the decisions, warnings and
+// files found so far. Knowledge further away scores lower; nodes such as
+// features and symbols are passed through but not listed.
+func (d *deepQuery) graph() bool {
+	kg, err := d.s.jsonStore.GetKnowledgeGraph()
+	if err != nil || kg == nil {
+		return true
+	}
+	type node struct{ typ, id string }
+	type link struct {
+		to       node
+		relation string
+		outgoing bool // the edge runs from the node found to this one
+	}
+	neighbors := make(map[node][]link)
+	for _, e := range kg.Edges {
+		from, to := node{e.FromType, e.FromID}, node{e.ToType, e.ToID}
+		neighbors[from] = append(neighbors[from], link{to, e.Relation, true})
+		neighbors[to] = append(neighbors[to], link{from, e.Relation, false})
+	}
+
+	seen := make(map[node]bool)
+	var frontier []node
+	for _, typ := range []string{"decision", "warning", "file"} {
+		for _, id := range d.hits.ranked(typ) {
+			n := node{typ, id}
+			seen[n] = true
+			frontier = append(frontier, n)
+		}
+	}
+	for hop := 1; hop <= deepGraphHops && len(frontier) > 0; hop++ {
+		score := 0.4 / float64(hop)
+		var next []node
+		for _, n := range frontier {
+			if d.expired() {
+				return false
+			}
+			for _, l := range neighbors[n] {
+				m := l.to
+				if seen[m] {
+					continue
+				}
+				seen[m] = true
+				next = append(next, m)
+				found := false
+				switch m.typ {
+				case "decision":
+					_, found = d.decisions[m.id]
+				case "warning":
+					_, found = d.warnings[m.id]
+				case "file":
+					_, found = d.file(m.id)
+				}
+				if found {
+					d.hits.add(m.typ, m.id, "graph", score)
+					d.hits.explain(m.typ, m.id, edgeReason(n.typ, n.id, l.relation, l.outgoing))
+				}
+			}
+		}
+		frontier = next
+	}
+	return true
+}
+
+// commitHistory searches recent commit messages for the question's terms;
+// the files those commits changed become hits too
+func (d *deepQuery) commitHistory() bool {
+	if len(d.terms) == 0 {
+		return true
+	}
+	type result struct {
+		commits []git.CommitInfo
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		commits, err := git.NewHistoryAnalyzer(filepath.Dir(d.s.basePath)).GetCommitHistory(time.Time{}, deepHistoryCommits)
+		done <- result{commits, err}
+	}()
+	var r result
+	select {
+	case r = <-done:
+	case <-time.After(time.Until(d.deadline)):
+		return false
+	}
+	if r.err != nil {
+		return true // not a git repository
+	}
+
+	for _, c := range r.commits {
+		if !matchesTerms(c.Message, d.terms) {
+			continue
+		}
+		hit := types.HistoryHit{Hash: c.ShortHash, Author: c.Author, Date: c.Date, Message: c.Message}
+		for _, p := range c.FilesChanged {
+			if _, ok := d.file(p); !ok {
+				continue
+			}
+			d.hits.add("file", p, "history", 0.3)
+			d.hits.explain("file", p, commitReason(c.ShortHash, c.Message))
+			if len(hit.Files) < 5 {
+				hit.Files = append(hit.Files, p)
+			}
+		}
+		d.history = append(d.history, hit)
+		if len(d.history) == deepHistoryHits {
+			break
+		}
+	}
+	return true
+}
+
+// conversations searches every saved conversation by keyword, not only the
+// ones semantic search ranks
+func (d *deepQuery) conversations() bool {
+	if len(d.terms) == 0 {
+		return true
+	}
+	convs, err := d.s.jsonStore.GetAllConversations()
+	if err != nil {
+		return true
+	}
+	for _, c := range convs {
+		if d.expired() {
+			return false
+		}
+		text := c.Topic + " " + c.Summary + " " + strings.Join(c.KeyPoints, " ") + " " + strings.Join(c.FilesDiscussed, " ")
+		if matchesTerms(text, d.terms) {
+			d.hits.add("conversation", c.ID, "keyword", 0.5)
+			d.hits.explain("conversation", c.ID, knowledgeReason(nil, text, d.query))
+		}
+	}
+	return true
+}
+
+// crossReferenceExperts adds the top experts of the closest directory of
+// each file in the answer to the experts found by name or area
+func (d *deepQuery) crossReferenceExperts(files []types.FileIndex, areas []git.DirectoryExpert, experts []types.GitExpertHit) ([]types.GitExpertHit, bool) {
+	seen := make(map[string]bool)
+	for _, e := range experts {
+		seen[e.Email+"\x00"+e.Area] = true
+	}
+	for _, f := range files {
+		if d.expired() {
+			return experts, false
+		}
+		area := closestExpertArea(f.Path, areas)
+		if area == nil {
+			continue
+		}
+		for i, expert := range area.TopExperts {
+			if i == deepExpertsPerArea {
+				break
+			}
+			id := expert.Email
+			if id == "" {
+				id = expert.Name // CODEOWNERS teams have no email
+			}
+			key := id + "\x00" + area.Directory
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			experts = append(experts, types.GitExpertHit{
+				Name:      expert.Name,
+				Email:     expert.Email,
+				Area:      area.Directory,
+				Ownership: expert.Ownership,
+				Active:    expert.Active,
+				Reason:    ownershipReason(expert, area.Directory) + ", which holds " + f.Path,
+			})
+		}
+	}
+	return experts, true
+}
+
+// closestExpertArea is the deepest directory with known experts that holds
+// the file
+func closestExpertArea(file string, areas []git.DirectoryExpert) *git.DirectoryExpert {
+	var best *git.DirectoryExpert
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		for i := range areas {
+			if strings.TrimSuffix(areas[i].Directory, "/") == dir {
+				best = &areas[i]
+				break
+			}
+		}
+		if best != nil || dir == "." || dir == "/" {
+			return best
+		}
+	}
+}
+
+// scopedExperts keeps the expert areas a query scope allows
+func scopedExperts(areas []git.DirectoryExpert, scope *queryScope) []git.DirectoryExpert {
+	var kept []git.DirectoryExpert
+	for _, de := range areas {
+		if scope.allowsLocation(de.Directory) {
+			kept = append(kept, de)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// CONFIG HOT-RELOAD
+// config.json is watched while the server runs. Changes to exclusions,
+// worker intervals, ranking weights and response limits take effect
+// without a restart, and each reload shows up in get_feed.
+// =============================================================================
+
+// configPollInterval is how often config.json is checked for changes
+const configPollInterval = 2 * time.Second
+
+// currentConfig returns the project config as last loaded. Settings read
+// on every call (response limits, ranking weights) come from here rather
+// than from config.json.
+func (s *Server) currentConfig() *types.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	if s.config == nil {
+		return &types.Config{}
+	}
+	return s.config
+}
+
+// loadConfig reads config.json at startup. A missing or broken config
+// leaves the defaults; the watcher applies it once it is fixed.
+func (s *Server) loadConfig() {
+	cfg := &types.Config{}
+	data, err := os.ReadFile(s.configPath())
+	if err == nil {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: config.json: %v\n", err)
+			cfg = &types.Config{}
+		}
+	}
+	s.configMu.Lock()
+	s.config, s.configData = cfg, data
+	s.configMu.Unlock()
+}
+
+func (s *Server) configPath() string {
+	return filepath.Join(s.basePath, "config.json")
+}
+
+// watchConfig applies changes to config.json until stop is closed
+func (s *Server) watchConfig(stop <-chan struct{}) {
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			changed, err := s.reloadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			} else if len(changed) > 0 {
+				fmt.Fprintf(os.Stderr, "Config reloaded: %s\n", strings.Join(changed, ", "))
+			}
+		}
+	}
+}
+
+// reloadConfig applies config.json when its content changed since it was
+// last loaded, and returns the settings that changed. A config that does
+// not parse is reported once and the one in effect is kept.
+func (s *Server) reloadConfig() ([]string, error) {
+	data, err := os.ReadFile(s.configPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	s.configMu.Lock()
+	if bytes.Equal(data, s.configData) {
+		s.configMu.Unlock()
+		return nil, nil
+	}
+	s.configData = data
+	old := s.config
+	s.configMu.Unlock()
+
+	cfg := &types.Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("config.json not reloaded: %w", err)
+	}
+	changed := configChanges(old, cfg)
+	s.configMu.Lock()
+	s.config = cfg
+	s.configMu.Unlock()
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	if s.workerManager != nil {
+		s.workerManager.ApplyConfig(cfg)
+		s.workerManager.LogConfigReload(changed)
+	}
+	return changed, nil
+}
+
+// configChanges names the settings that differ between two configs: the
+// keys of each section that changed ("index.exclude", "search.weights"),
+// or the top-level key when it is not a section
+func configChanges(old, cfg *types.Config) []string {
+	before, after := configFields(old), configFields(cfg)
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	var changed []string
+	for k := range keys {
+		if !bytes.Equal(before[k], after[k]) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// configFields flattens a config to its top-level keys, and the keys of
+// its sections one level down, with their JSON values
+func configFields(cfg *types.Config) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	if cfg == nil {
+		return fields
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return fields
+	}
+	var top map[string]json.RawMessage
+	if json.Unmarshal(data, &top) != nil {
+		return fields
+	}
+	for k, v := range top {
+		var section map[string]json.RawMessage
+		if json.Unmarshal(v, &section) == nil {
+			for sk, sv := range section {
+				fields[k+"."+sk] = sv
+			}
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloadConfig(t *testing.T) {
+	s := setupTestServer(t)
+	s.loadConfig()
+	path := filepath.Join(s.basePath, "config.json")
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{
+  "name": "shop",
+  "index": {"exclude": ["fixtures", "apps/*/generated"]},
+  "worker": {"reindex_minutes": 1},
+  "search": {"weights": {"semantic": 0.5}},
+  "server": {"mcp_enabled": false, "rest_enabled": false, "response_limits": {"search_code": 2048}}
+}`)
+
+	changed, err := s.reloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"index.exclude", "name", "search.weights", "server.response_limits", "worker.reindex_minutes"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	if got := s.workerManager.GetConfig().ReindexInterval; got != time.Minute {
+		t.Errorf("reindex interval = %v, want 1m", got)
+	}
+	if got := responseLimit("search_code", s.currentConfig().Server); got != 2048 {
+		t.Errorf("search_code limit = %d, want 2048", got)
+	}
+	if got := responseLimit("get_tree", s.currentConfig().Server); got != toolResponseLimits["get_tree"] {
+		t.Errorf("get_tree limit = %d, want the built-in one", got)
+	}
+
+	// Unchanged content is not applied again
+	if changed, err := s.reloadConfig(); err != nil || changed != nil {
+		t.Errorf("second reload = %v, %v", changed, err)
+	}
+
+	// A broken config is reported and the one in effect kept
+	write(`{"name": "shop", "index": {`)
+	if _, err := s.reloadConfig(); err == nil {
+		t.Error("broken config reloaded without error")
+	}
+	if s.currentConfig().Search.Weights["semantic"] != 0.5 {
+		t.Error("broken config replaced the one in effect")
+	}
+
+	feed := resultMap(t, mustCall(t, s, "get_feed", map[string]interface{}{"type": "config"}))
+	data, _ := json.Marshal(feed["entries"])
+	var entries []struct {
+		Type  string `json:"type"`
+		Title string `json:"title"`
+	}
+	json.Unmarshal(data, &entries)
+	if len(entries) != 1 || entries[0].Type != "config" || !strings.HasPrefix(entries[0].Title, "Config reloaded: index.exclude, name,") {
+		t.Errorf("feed entries = %+v, want the one reload", entries)
+	}
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// DIRECTORY ROLLUPS
+// Broad file searches collapse directories with many matches into one entry,
+// so a query matching 40 handlers in one package costs one line, not 40
+// =============================================================================
+
+// defaultRollupThreshold is how many matches in one directory collapse it
+const defaultRollupThreshold = 5
+
+// dirRollup stands in for the matching files of one directory
+type dirRollup struct {
+	Path      string   `json:"path"`
+	Count     int      `json:"count"`
+	Summary   string   `json:"summary"`
+	Languages []string `json:"languages,omitempty"`
+	Files     []string `json:"files"` // the first few matches, in result order
+}
+
+// rollupSample is how many file paths a rollup lists
+const rollupSample = 3
+
+// rollupFiles collapses every directory with at least threshold of the
+// matching files. Entries keep the order of their first match: a file, or
+// a directory at the position of its first file. Both count against limit.
+func rollupFiles(files []types.FileIndex, threshold, limit int) ([]types.FileIndex, []dirRollup) {
+	byDir := make(map[string][]types.FileIndex)
+	for _, f := range files {
+		dir := path.Dir(f.Path)
+		byDir[dir] = append(byDir[dir], f)
+	}
+
+	var kept []types.FileIndex
+	var rollups []dirRollup
+	emitted := make(map[string]bool)
+	for _, f := range files {
+		if len(kept)+len(rollups) >= limit {
+			break
+		}
+		dir := path.Dir(f.Path)
+		members := byDir[dir]
+		if len(members) < threshold {
+			kept = append(kept, f)
+			continue
+		}
+		if emitted[dir] {
+			continue
+		}
+		emitted[dir] = true
+		rollups = append(rollups, newDirRollup(dir, members))
+	}
+	return kept, rollups
+}
+
+func newDirRollup(dir string, members []types.FileIndex) dirRollup {
+	r := dirRollup{Path: dir, Count: len(members), Summary: rollupSummary(members)}
+	langs := make(map[string]int)
+	for i, f := range members {
+		if i < rollupSample {
+			r.Files = append(r.Files, f.Path)
+		}
+		if f.Language != "" {
+			langs[f.Language]++
+		}
+	}
+	for lang := range langs {
+		r.Languages = append(r.Languages, lang)
+	}
+	sort.Slice(r.Languages, func(i, j int) bool {
+		a, b := r.Languages[i], r.Languages[j]
+		if langs[a] != langs[b] {
+			return langs[a] > langs[b]
+		}
+		return a < b
+	})
+	return r
+}
+
+// rollupSummary describes a directory by its matching files: the first
+// sentence of the first two distinct summaries and the exports most of its
+// files share or lead with, e.g. "Handles refunds; Validates card
+// payloads. Exports: Handler, Register, Validate"
+func rollupSummary(members []types.FileIndex) string {
+	var sentences []string
+	seen := make(map[string]bool)
+	for _, f := range members {
+		s := firstSentence(f.Summary)
+		if s == "" || seen[strings.ToLower(s)] {
+			continue
+		}
+		seen[strings.ToLower(s)] = true
+		sentences = append(sentences, s)
+		if len(sentences) == 2 {
+			break
+		}
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, f := range members {
+		for _, e := range f.Exports {
+			if counts[e.Name] == 0 {
+				order = append(order, e.Name)
+			}
+			counts[e.Name]++
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	if len(order) > 5 {
+		order = order[:5]
+	}
+
+	summary := strings.Join(sentences, "; ")
+	if len(order) > 0 {
+		if summary != "" {
+			summary += ". "
+		}
+		summary += "Exports: " + strings.Join(order, ", ")
+	}
+	if summary == "" {
+		summary = fmt.Sprintf("%d matching files", len(members))
+	}
+	return summary
+}
+
+// firstSentence returns a summary up to its first full stop
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestRollupFiles(t *testing.T) {
+	var files []types.FileIndex
+	files = append(files, types.FileIndex{Path: "README.md", Language: "markdown"})
+	for i := 1; i <= 6; i++ {
+		files = append(files, types.FileIndex{
+			Path:     fmt.Sprintf("api/handlers/h%d.go", i),
+			Language: "go",
+			Summary:  fmt.Sprintf("Handles route %d. Registered at startup.", i%2),
+			Exports:  []types.Export{{Name: "Register"}, {Name: fmt.Sprintf("H%d", i)}},
+		})
+	}
+	files = append(files, types.FileIndex{Path: "api/server.go", Language: "go"})
+
+	kept, rollups := rollupFiles(files, 5, 20)
+	if len(kept) != 2 || kept[0].Path != "README.md" || kept[1].Path != "api/server.go" {
+		t.Errorf("kept = %+v", kept)
+	}
+	want := []dirRollup{{
+		Path:      "api/handlers",
+		Count:     6,
+		Summary:   "Handles route 1; Handles route 0. Exports: Register, H1, H2, H3, H4",
+		Languages: []string{"go"},
+		Files:     []string{"api/handlers/h1.go", "api/handlers/h2.go", "api/handlers/h3.go"},
+	}}
+	if !reflect.DeepEqual(rollups, want) {
+		t.Errorf("rollups = %+v, want %+v", rollups, want)
+	}
+
+	// A rollup counts as one entry against the limit
+	kept, rollups = rollupFiles(files, 5, 2)
+	if len(kept) != 1 || len(rollups) != 1 {
+		t.Errorf("limit 2: kept %d files and %d rollups", len(kept), len(rollups))
+	}
+
+	// Below the threshold nothing collapses
+	if kept, rollups = rollupFiles(files, 10, 20); len(kept) != len(files) || len(rollups) != 0 {
+		t.Errorf("threshold 10: kept %d files and %d rollups", len(kept), len(rollups))
+	}
+}
+
+func TestSearchFilesRollupAndExpand(t *testing.T) {
+	s := setupTestServer(t)
+	for i := 0; i < 7; i++ {
+		f := &types.FileIndex{Path: fmt.Sprintf("billing/refund_%d.go", i), Language: "go", Summary: "Refund flow step"}
+		if err := s.sqliteIndex.IndexFile(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.sqliteIndex.IndexFile(&types.FileIndex{Path: "docs/refunds.md", Language: "markdown", Summary: "Refund policy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := resultMap(t, mustCall(t, s, "search_files", map[string]interface{}{"query": "refund*"}))
+	if result["total"] != 8 {
+		t.Errorf("total = %v, want 8", result["total"])
+	}
+	if files := result["files"].([]types.FileIndex); len(files) != 1 || files[0].Path != "docs/refunds.md" {
+		t.Errorf("files = %+v", files)
+	}
+	dirs, _ := result["directories"].([]dirRollup)
+	if len(dirs) != 1 || dirs[0].Path != "billing" || dirs[0].Count != 7 || dirs[0].Summary != "Refund flow step" {
+		t.Errorf("directories = %+v", dirs)
+	}
+
+	result = resultMap(t, mustCall(t, s, "search_files", map[string]interface{}{"query": "refund*", "expand": "billing/", "limit": 5}))
+	if result["directory"] != "billing" || result["total"] != 7 || len(result["files"].([]types.FileIndex)) != 5 {
+		t.Errorf("expand = %+v", result)
+	}
+	if _, ok := result["directories"]; ok {
+		t.Error("an expanded directory should not be rolled up again")
+	}
+}
@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sandboxPathArgs are the argument names treated as paths for tools whose
+// schema marks none (e.g. tools called only through debug-mcp)
+var sandboxPathArgs = []string{"path", "file", "file_path", "paths"}
+
+var (
+	toolPathArgsOnce sync.Once
+	toolPathArgs     map[string]map[string]bool // tool -> argument names
+)
+
+// pathArgs returns the names of a tool's arguments that name files or
+// directories, from the Path marks in its schema
+func pathArgs(tool string) map[string]bool {
+	toolPathArgsOnce.Do(func() {
+		toolPathArgs = make(map[string]map[string]bool)
+		for _, t := range toolDefinitions() {
+			args := make(map[string]bool)
+			for name, prop := range t.InputSchema.Properties {
+				if prop.Path {
+					args[name] = true
+				}
+			}
+			toolPathArgs[t.Name] = args
+		}
+	})
+	if args := toolPathArgs[tool]; len(args) > 0 {
+		return args
+	}
+	fallback := make(map[string]bool, len(sandboxPathArgs))
+	for _, name := range sandboxPathArgs {
+		fallback[name] = true
+	}
+	return fallback
+}
+
+// sandboxViolation is the structured error returned when a tool is asked to
+// read outside the allowed roots
+type sandboxViolation struct {
+	Error        string   `json:"error"`
+	Tool         string   `json:"tool"`
+	Argument     string   `json:"argument"`
+	Path         string   `json:"path"`
+	AllowedRoots []string `json:"allowed_roots"`
+	Hint         string   `json:"hint"`
+}
+
+// sandboxRoots returns the directories tools may read: the project root,
+// linked repos and the configured allow-list. nil means the sandbox is off.
+func (s *Server) sandboxRoots() []string {
+	projectRoot := filepath.Dir(s.basePath)
+	roots := []string{projectRoot}
+	cfg, err := s.jsonStore.GetConfig()
+	if err != nil {
+		return roots
+	}
+	if cfg.Sandbox.Disabled {
+		return nil
+	}
+	for _, p := range append(append([]string{}, cfg.LinkedRepos...), cfg.Sandbox.AllowPaths...) {
+		if p == "" {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(projectRoot, p)
+		}
+		roots = append(roots, filepath.Clean(p))
+	}
+	return roots
+}
+
+// checkSandbox rejects calls whose path arguments resolve outside the
+// sandbox roots. Path arguments are found at any depth, so a path inside an
+// object or a list of objects is checked too. Relative paths are taken from
+// the project root, and symlinks are followed so a link can't lead out of
+// the tree.
+func (s *Server) checkSandbox(tool string, args json.RawMessage) *sandboxViolation {
+	var raw interface{}
+	if json.Unmarshal(args, &raw) != nil {
+		return nil
+	}
+
+	var found []sandboxPath
+	collectPathArgs(raw, "", pathArgs(tool), &found)
+	if len(found) == 0 {
+		return nil
+	}
+
+	roots := s.sandboxRoots()
+	if roots == nil {
+		return nil
+	}
+	for _, f := range found {
+		if f.path == "" || withinRoots(f.path, roots) {
+			continue
+		}
+		return &sandboxViolation{
+			Error:        "path_outside_sandbox",
+			Tool:         tool,
+			Argument:     f.arg,
+			Path:         f.path,
+			AllowedRoots: roots,
+			Hint:         "Only files under the project root, linked repos and sandbox.allow_paths in .teamcontext/config.json can be read.",
+		}
+	}
+	return nil
+}
+
+// sandboxPath is one path found in a tool's arguments
+type sandboxPath struct {
+	arg  string // dotted location, e.g. "changes[0].file"
+	path string
+}
+
+// collectPathArgs walks decoded JSON and collects the strings held by keys
+// in names, alone or in lists
+func collectPathArgs(value interface{}, at string, names map[string]bool, found *[]sandboxPath) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			loc := k
+			if at != "" {
+				loc = at + "." + k
+			}
+			if names[k] {
+				switch pv := v[k].(type) {
+				case string:
+					*found = append(*found, sandboxPath{loc, pv})
+					continue
+				case []interface{}:
+					for _, item := range pv {
+						if str, ok := item.(string); ok {
+							*found = append(*found, sandboxPath{loc, str})
+						}
+					}
+				}
+			}
+			collectPathArgs(v[k], loc, names, found)
+		}
+	case []interface{}:
+		for i, item := range v {
+			collectPathArgs(item, fmt.Sprintf("%s[%d]", at, i), names, found)
+		}
+	}
+}
+
+// withinRoots reports whether path resolves inside one of roots
+func withinRoots(path string, roots []string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(roots[0], path)
+	}
+	resolved := resolveSymlinks(filepath.Clean(path))
+	for _, root := range roots {
+		root = resolveSymlinks(root)
+		if resolved == root || strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSymlinks follows links in the longest existing prefix of path, so
+// paths to files not created yet still resolve
+func resolveSymlinks(path string) string {
+	rest := ""
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(dir); err == nil {
+			if real, err := filepath.EvalSymlinks(dir); err == nil {
+				return filepath.Join(real, rest)
+			}
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// outsideDir is a directory next to the project, outside the sandbox
+func outsideDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCheckSandbox(t *testing.T) {
+	s := setupTestServer(t)
+	root := filepath.Dir(s.basePath)
+	outside := outsideDir(t)
+	writeProjectFile(t, s, "src/app.go", "package main\n")
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "inner")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    string
+		wantArg string // "" when the call is allowed
+	}{
+		{"relative inside", "get_skeleton", `{"path": "src/app.go"}`, ""},
+		{"absolute inside", "get_skeleton", `{"path": "` + root + `/src/app.go"}`, ""},
+		{"not yet created inside", "get_skeleton", `{"path": "src/new/file.go"}`, ""},
+		{"dot-dot escape", "get_skeleton", `{"path": "../outside/secret.txt"}`, "path"},
+		{"dot-dot through a subdirectory", "get_skeleton", `{"path": "src/../../secret.txt"}`, "path"},
+		{"absolute outside", "get_skeleton", `{"path": "/etc/passwd"}`, "path"},
+		{"symlink escape", "get_skeleton", `{"path": "escape/secret.txt"}`, "path"},
+		{"symlink inside", "get_skeleton", `{"path": "inner/app.go"}`, ""},
+		{"list argument", "index", `{"paths": ["src/app.go", "/etc/passwd"]}`, "paths"},
+		{"target_files", "get_context", `{"intent": "x", "target_files": ["src/app.go", "../secret.txt"]}`, "target_files"},
+		{"files", "find_experts", `{"files": ["/etc/hosts"]}`, "files"},
+		{"output", "export_requests", `{"path": "src", "output": "../collection.http"}`, "output"},
+		{"nested object", "get_skeleton", `{"options": {"path": "/etc/passwd"}}`, "options.path"},
+		{"list of objects", "unknown_tool", `{"items": [{"file": "src/app.go"}, {"file": "/etc/passwd"}]}`, "items[1].file"},
+		{"unmarked argument", "add_decision", `{"content": "x", "related_files": ["/elsewhere/x.go"]}`, ""},
+		{"empty path", "get_skeleton", `{"path": ""}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := s.checkSandbox(tt.tool, json.RawMessage(tt.args))
+			switch {
+			case tt.wantArg == "" && v != nil:
+				t.Fatalf("allowed call rejected: %+v", v)
+			case tt.wantArg != "" && v == nil:
+				t.Fatalf("escape via %s not caught", tt.wantArg)
+			case v != nil && (v.Argument != tt.wantArg || v.Error != "path_outside_sandbox" || v.Tool != tt.tool):
+				t.Errorf("violation = %+v, want argument %q", v, tt.wantArg)
+			}
+		})
+	}
+}
+
+func TestCheckSandboxAllowedRoots(t *testing.T) {
+	s := setupTestServer(t)
+	root := filepath.Dir(s.basePath)
+	shared := outsideDir(t)
+	linked := outsideDir(t)
+	other := outsideDir(t)
+
+	// Relative allow-list entries are taken from the project root
+	rel, err := filepath.Rel(root, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.SaveConfig(&types.Config{
+		LinkedRepos: []string{linked},
+		Sandbox:     types.SandboxConfig{AllowPaths: []string{rel}},
+	}); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+
+	check := func(path string) *sandboxViolation {
+		return s.checkSandbox("get_skeleton", json.RawMessage(`{"path": "`+path+`"}`))
+	}
+	for _, allowed := range []string{shared + "/secret.txt", linked + "/secret.txt", "src/app.go"} {
+		if v := check(allowed); v != nil {
+			t.Errorf("%s rejected: %+v", allowed, v)
+		}
+	}
+	v := check(other + "/secret.txt")
+	if v == nil {
+		t.Fatal("path outside every allowed root accepted")
+	}
+	if len(v.AllowedRoots) != 3 || v.AllowedRoots[0] != root {
+		t.Errorf("allowed_roots = %v, want the project, the linked repo and the allow-list entry", v.AllowedRoots)
+	}
+
+	if err := s.jsonStore.SaveConfig(&types.Config{Sandbox: types.SandboxConfig{Disabled: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if v := check(other + "/secret.txt"); v != nil {
+		t.Errorf("disabled sandbox still rejects: %+v", v)
+	}
+}
+
+func TestSchemaMarksPathArguments(t *testing.T) {
+	for _, tool := range []string{"get_skeleton", "get_context", "find_experts", "export_requests", "check_compliance"} {
+		if len(pathArgs(tool)) == 0 {
+			t.Errorf("%s declares no path arguments", tool)
+		}
+	}
+	if args := pathArgs("get_context"); !args["target_files"] || args["intent"] {
+		t.Errorf("get_context path args = %v, want target_files only", args)
+	}
+	for _, tool := range toolDefinitions() {
+		for name, prop := range tool.InputSchema.Properties {
+			if prop.Path && prop.Type != "string" && prop.Type != "array" {
+				t.Errorf("%s.%s is marked as a path but has type %s", tool.Name, name, prop.Type)
+			}
+			if !prop.Path && (name == "path" || name == "file" || name == "file_path") {
+				t.Errorf("%s.%s is not marked as a path", tool.Name, name)
+			}
+		}
+	}
+	data, _ := json.Marshal(toolDefinitions()[0].InputSchema)
+	if strings.Contains(string(data), `"Path"`) {
+		t.Errorf("path marks leak into the published schema: %s", data)
+	}
+}
@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/blueprint"
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// QUERY SCOPES
+// A scope narrows query to part of the project: "feature:auth",
+// "path:src/billing", "app:web", "lang:go". Terms are separated by spaces or
+// commas. Terms of the same kind widen the scope, terms of different kinds
+// narrow it: "lang:go lang:python path:services" is Go or Python under
+// services/.
+// =============================================================================
+
+// langAliases maps the short names people type to indexed language names
+var langAliases = map[string]string{
+	"ts": "typescript", "js": "javascript", "py": "python", "golang": "go",
+	"rb": "ruby", "cs": "csharp", "c#": "csharp", "rs": "rust", "kt": "kotlin",
+	"c++": "cpp", "sh": "shell", "bash": "shell", "md": "markdown",
+	"ipynb": "jupyter", "notebook": "jupyter",
+	"objective-c": "objc", "obj-c": "objc", "objective-c++": "objcpp", "obj-c++": "objcpp",
+}
+
+// queryScope is a parsed scope. A nil scope allows everything.
+type queryScope struct {
+	features []string
+	dirs     []string // from path: and app:, project-relative
+	langs    []string
+
+	// Resolved once from the scoped features: their files, including those
+	// of their decisions and warnings, and the IDs of their knowledge
+	featureFiles []string
+	featureItems map[string]bool
+}
+
+// parseQueryScope parses a scope string. app: names are resolved to their
+// directory the way get_blueprint does (apps/, packages/, services/, the
+// root), then to documented architecture services.
+func (s *Server) parseQueryScope(scope string) (*queryScope, error) {
+	terms := strings.FieldsFunc(scope, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	q := &queryScope{}
+	for _, term := range terms {
+		kind, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid scope %q: use feature:ID, path:DIR, app:NAME or lang:LANGUAGE", term)
+		}
+		switch kind {
+		case "feature":
+			q.features = append(q.features, value)
+		case "path":
+			q.dirs = append(q.dirs, relpath.Normalize(value))
+		case "app":
+			dirs := s.appDirs(value)
+			if len(dirs) == 0 {
+				return nil, fmt.Errorf("unknown app %q: no apps/, packages/ or services/ directory or architecture service by that name", value)
+			}
+			q.dirs = append(q.dirs, dirs...)
+		case "lang":
+			lang := strings.ToLower(value)
+			if alias, ok := langAliases[lang]; ok {
+				lang = alias
+			}
+			q.langs = append(q.langs, lang)
+		default:
+			return nil, fmt.Errorf("unknown scope %q: use feature:ID, path:DIR, app:NAME or lang:LANGUAGE", kind)
+		}
+	}
+
+	if len(q.features) == 0 {
+		return q, nil
+	}
+	q.featureItems = make(map[string]bool)
+	for _, id := range q.features {
+		// Knowledge can name a feature that was never started; it still
+		// matches by its feature field
+		feature, err := s.jsonStore.GetFeature(id)
+		if err != nil {
+			continue
+		}
+		q.featureFiles = append(q.featureFiles, relpath.NormalizeAll(feature.RelevantFiles)...)
+		for _, itemID := range append(append([]string{}, feature.Decisions...), feature.Warnings...) {
+			q.featureItems[itemID] = true
+		}
+	}
+	decisions, _ := s.jsonStore.GetDecisions()
+	for _, d := range decisions {
+		if q.featureItems[d.ID] || containsString(q.features, d.Feature) {
+			q.featureFiles = append(q.featureFiles, relpath.NormalizeAll(d.RelatedFiles)...)
+		}
+	}
+	warnings, _ := s.jsonStore.GetWarnings()
+	for _, w := range warnings {
+		if q.featureItems[w.ID] || containsString(q.features, w.Feature) {
+			q.featureFiles = append(q.featureFiles, relpath.NormalizeAll(w.RelatedFiles)...)
+		}
+	}
+	return q, nil
+}
+
+// appDirs resolves an app name to the directories holding it: a Gradle
+// module (:feature:login, feature/login or login), an apps/, packages/ or
+// services/ directory, or a documented service
+func (s *Server) appDirs(app string) []string {
+	root := filepath.Dir(s.basePath)
+	if module, ok := blueprint.FindGradleModule(blueprint.GradleModules(root), app); ok {
+		return []string{module.Dir}
+	}
+	for _, dir := range []string{"apps", "packages", "services", ""} {
+		rel := relpath.Normalize(filepath.Join(dir, app))
+		if info, err := os.Stat(relpath.ToAbs(root, rel)); err == nil && info.IsDir() {
+			return []string{rel}
+		}
+	}
+	if arch, err := s.jsonStore.GetArchitecture(); err == nil && arch != nil {
+		for _, svc := range arch.Services {
+			if strings.EqualFold(svc.Name, app) {
+				return relpath.NormalizeAll(svc.Files)
+			}
+		}
+	}
+	return nil
+}
+
+// narrowed reports whether the scope restricts anything
+func (q *queryScope) narrowed() bool {
+	return q != nil && (len(q.features) > 0 || len(q.dirs) > 0 || len(q.langs) > 0)
+}
+
+// allowsFile reports whether a file is in scope. language is the indexed
+// language; when empty it is not checked.
+func (q *queryScope) allowsFile(path, language string) bool {
+	if !q.narrowed() {
+		return true
+	}
+	path = relpath.Normalize(path)
+	if len(q.dirs) > 0 && !withinAny(path, q.dirs) {
+		return false
+	}
+	if len(q.langs) > 0 && language != "" && !containsString(q.langs, language) {
+		return false
+	}
+	if len(q.features) > 0 && !withinAny(path, q.featureFiles) {
+		return false
+	}
+	return true
+}
+
+// allowsFeature reports whether an item recorded under a feature, such as
+// a conversation, is in scope
+func (q *queryScope) allowsFeature(feature string) bool {
+	return q == nil || len(q.features) == 0 || containsString(q.features, feature)
+}
+
+// allowsLocation is allowsFile for items that have a path but no language
+// or feature: documentation sections, git expertise areas
+func (q *queryScope) allowsLocation(path string) bool {
+	if q == nil || len(q.dirs) == 0 {
+		return true
+	}
+	path = relpath.Normalize(path)
+	for _, dir := range q.dirs {
+		// An area containing the scoped directory is relevant too
+		if relpath.Within(path, dir) || relpath.Within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsKnowledge reports whether a decision or warning is in scope: it
+// belongs to or is listed by a scoped feature, and when path, app or lang
+// is given, at least one of its related files passes them. fileLanguage
+// looks up the indexed language of a related file.
+func (q *queryScope) allowsKnowledge(id, feature string, relatedFiles []string, fileLanguage func(string) string) bool {
+	if !q.narrowed() {
+		return true
+	}
+	if len(q.features) > 0 && !containsString(q.features, feature) && !q.featureItems[id] {
+		return false
+	}
+	if len(q.dirs) == 0 && len(q.langs) == 0 {
+		return true
+	}
+	for _, f := range relpath.NormalizeAll(relatedFiles) {
+		if len(q.dirs) > 0 && !withinAny(f, q.dirs) {
+			continue
+		}
+		if len(q.langs) > 0 && !containsString(q.langs, fileLanguage(f)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// withinAny reports whether path is one of dirs or under one of them
+func withinAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if relpath.Within(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedLanguage returns a function giving the indexed language of a file,
+// falling back to its extension for files not in the index
+func scopedLanguage(indexed map[string]types.FileIndex) func(string) string {
+	return func(path string) string {
+		if f, ok := indexed[path]; ok && f.Language != "" {
+			return f.Language
+		}
+		return detectLanguageFromPath(path)
+	}
+}
@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestParseQueryScope(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "apps/web/index.ts", "export {}\n")
+	writeProjectFile(t, s, "tools/cli/main.go", "package main\n")
+	if err := s.jsonStore.SaveArchitecture(&types.Architecture{
+		Services: []types.ServiceNode{{Name: "Billing", Files: []string{"./services/billing/", "lib/invoice.go"}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		scope string
+		dirs  []string
+		langs []string
+	}{
+		{"path:./src/billing/", []string{"src/billing"}, nil},
+		{"app:web", []string{"apps/web"}, nil},
+		{"app:tools/cli", []string{"tools/cli"}, nil},
+		{"app:billing", []string{"services/billing", "lib/invoice.go"}, nil},
+		{"lang:TS, lang:py lang:golang", nil, []string{"typescript", "python", "go"}},
+		{"app:web lang:ts", []string{"apps/web"}, []string{"typescript"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			q, err := s.parseQueryScope(tt.scope)
+			if err != nil {
+				t.Fatalf("parseQueryScope: %v", err)
+			}
+			if !reflect.DeepEqual(q.dirs, tt.dirs) || !reflect.DeepEqual(q.langs, tt.langs) {
+				t.Errorf("dirs = %v, langs = %v; want %v and %v", q.dirs, q.langs, tt.dirs, tt.langs)
+			}
+		})
+	}
+
+	if q, err := s.parseQueryScope(" , "); q != nil || err != nil || q.narrowed() {
+		t.Errorf("empty scope = %+v, %v; want nil", q, err)
+	}
+	for _, bad := range []string{"billing", "path:", "owner:ada", "app:missing"} {
+		if _, err := s.parseQueryScope(bad); err == nil {
+			t.Errorf("scope %q accepted", bad)
+		}
+	}
+}
+
+func TestFeatureScopeResolvesFiles(t *testing.T) {
+	s := setupTestServer(t)
+	listed := &types.Decision{Content: "Sign tokens with RS256", RelatedFiles: []string{"src/auth/jwt.go"}}
+	tagged := &types.Warning{Content: "Sessions expire after an hour", Feature: "auth", RelatedFiles: []string{"src/session/store.go"}}
+	other := &types.Decision{Content: "Invoices are immutable", Feature: "billing", RelatedFiles: []string{"src/billing/invoice.go"}}
+	for _, d := range []*types.Decision{listed, other} {
+		if err := s.jsonStore.AddDecision(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.jsonStore.AddWarning(tagged); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.jsonStore.CreateFeature(&types.Feature{ID: "auth", RelevantFiles: []string{"src/auth/login.go"}, Decisions: []string{listed.ID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := s.parseQueryScope("feature:auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := append([]string{}, q.featureFiles...)
+	sort.Strings(files)
+	if want := []string{"src/auth/jwt.go", "src/auth/login.go", "src/session/store.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("feature files = %v, want %v", files, want)
+	}
+	if !q.allowsKnowledge(listed.ID, "", listed.RelatedFiles, nil) || !q.allowsKnowledge(tagged.ID, "auth", nil, nil) {
+		t.Error("knowledge listed by or tagged with the feature is out of scope")
+	}
+	if q.allowsKnowledge(other.ID, "billing", other.RelatedFiles, nil) {
+		t.Error("knowledge of another feature is in scope")
+	}
+	if !q.allowsFile("src/session/store.go", "go") || q.allowsFile("src/billing/invoice.go", "go") {
+		t.Error("feature scope does not follow the files its knowledge names")
+	}
+	if !q.allowsFeature("auth") || q.allowsFeature("billing") {
+		t.Error("allowsFeature does not match the scoped feature")
+	}
+}
+
+func TestQueryScopeAllows(t *testing.T) {
+	q := &queryScope{dirs: []string{"services/billing"}, langs: []string{"go", "python"}}
+	language := func(path string) string { return detectLanguageFromPath(path) }
+
+	files := []struct {
+		path, lang string
+		want       bool
+	}{
+		{"services/billing/invoice.go", "go", true},
+		{"services/billing/tax.py", "python", true},
+		{"services/billing/ui.ts", "typescript", false},
+		{"services/billing-old/invoice.go", "go", false},
+		{"services/billing/unknown", "", true},
+	}
+	for _, f := range files {
+		if got := q.allowsFile(f.path, f.lang); got != f.want {
+			t.Errorf("allowsFile(%s, %s) = %v, want %v", f.path, f.lang, got, f.want)
+		}
+	}
+
+	knowledge := []struct {
+		name    string
+		related []string
+		want    bool
+	}{
+		{"one related file in scope", []string{"web/app.ts", "services/billing/invoice.go"}, true},
+		{"in the directory, wrong language", []string{"services/billing/ui.ts"}, false},
+		{"right language, outside the directory", []string{"services/orders/order.go"}, false},
+		{"no related files", nil, false},
+	}
+	for _, k := range knowledge {
+		if got := q.allowsKnowledge("dec-1", "", k.related, language); got != k.want {
+			t.Errorf("%s: allowsKnowledge = %v, want %v", k.name, got, k.want)
+		}
+	}
+
+	for path, want := range map[string]bool{"services": true, "services/billing/api": true, "services/orders": false} {
+		if got := q.allowsLocation(path); got != want {
+			t.Errorf("allowsLocation(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	var none *queryScope
+	if !none.allowsFile("anything.rs", "rust") || !none.allowsKnowledge("x", "", nil, language) || !none.allowsLocation("x") || !none.allowsFeature("x") {
+		t.Error("a nil scope filters")
+	}
+}
+
+func TestQueryHonoursScope(t *testing.T) {
+	s := setupTestServer(t)
+	for _, d := range []*types.Decision{
+		{Content: "Retry billing webhooks", RelatedFiles: []string{"services/billing/webhook.go"}},
+		{Content: "Retry failed uploads in the browser", RelatedFiles: []string{"web/src/upload.ts"}},
+		{Content: "Retry login with a fresh token", Feature: "auth"},
+	} {
+		if err := s.jsonStore.AddDecision(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]types.FileIndex{
+		"services/billing/webhook.go": {Path: "services/billing/webhook.go", Language: "go", Summary: "retry webhook delivery"},
+		"web/src/upload.ts":           {Path: "web/src/upload.ts", Language: "typescript", Summary: "retry uploads"},
+	}
+	if err := s.jsonStore.SaveFilesIndexBulk(files); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		f := f
+		if err := s.sqliteIndex.IndexFile(&f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		scope     string
+		decisions []string
+		files     []string
+	}{
+		{"", []string{"Retry billing webhooks", "Retry failed uploads in the browser", "Retry login with a fresh token"}, []string{"services/billing/webhook.go", "web/src/upload.ts"}},
+		{"path:services/billing", []string{"Retry billing webhooks"}, []string{"services/billing/webhook.go"}},
+		{"lang:ts", []string{"Retry failed uploads in the browser"}, []string{"web/src/upload.ts"}},
+		{"lang:go lang:ts", []string{"Retry billing webhooks", "Retry failed uploads in the browser"}, []string{"services/billing/webhook.go", "web/src/upload.ts"}},
+		{"path:services lang:ts", nil, nil},
+		{"feature:auth", []string{"Retry login with a fresh token"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run("scope "+tt.scope, func(t *testing.T) {
+			result := mustCall(t, s, "query", map[string]interface{}{"question": "retry", "scope": tt.scope}).(*types.QueryResponse)
+			var decisions, paths []string
+			for _, d := range result.Decisions {
+				decisions = append(decisions, d.Content)
+			}
+			for _, f := range result.Files {
+				paths = append(paths, f.Path)
+			}
+			sort.Strings(decisions)
+			sort.Strings(paths)
+			if !reflect.DeepEqual(decisions, tt.decisions) {
+				t.Errorf("decisions = %v, want %v", decisions, tt.decisions)
+			}
+			if !reflect.DeepEqual(paths, tt.files) {
+				t.Errorf("files = %v, want %v", paths, tt.files)
+			}
+		})
+	}
+
+	if _, err := callTool(t, s, "query", map[string]interface{}{"question": "retry", "scope": "team:payments"}); err == nil {
+		t.Error("unknown scope kind accepted")
+	}
+}
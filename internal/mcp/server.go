@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/saeedalam/teamcontext/internal/search"
@@ -32,12 +35,18 @@ type SessionTracker struct {
 	LastCheckpointID    string // ID of last auto-saved conversation
 	FeaturesStarted     []string
 	FeaturesArchived    []string
+	TokensUsed          int             // tokens returned to the client this session
+	TokensSaved         int             // tokens avoided versus reading raw sources
+	BudgetWarned        map[string]bool // features already warned about their token budget
+	SummaryPrompted     map[string]bool // files the agent was asked to summarize
 }
 
 func newSessionTracker() *SessionTracker {
 	return &SessionTracker{
-		StartedAt:    time.Now(),
-		FilesTouched: make(map[string]bool),
+		StartedAt:       time.Now(),
+		FilesTouched:    make(map[string]bool),
+		BudgetWarned:    make(map[string]bool),
+		SummaryPrompted: make(map[string]bool),
 	}
 }
 
@@ -50,6 +59,15 @@ type Server struct {
 	tools         map[string]ToolHandler
 	tfidfEngine   *search.TFIDFEngine // lazy-loaded TF-IDF engine for semantic search
 	session       *SessionTracker
+	capabilities  *EnvironmentCapabilities // detected at startup
+	author        string                   // resolved once by currentAuthor
+	authorOnce    sync.Once
+	confirmations confirmationStore // confirm tokens issued by dry runs
+	tokenSpend    tokenLedger       // spend not yet flushed to the ledger file
+
+	config     *types.Config // config.json as last loaded; see currentConfig
+	configData []byte        // its content, to tell when it changes
+	configMu   sync.RWMutex
 }
 
 // ToolHandler handles a tool call
@@ -83,6 +101,7 @@ type InitializeResult struct {
 	ProtocolVersion string       `json:"protocolVersion"`
 	ServerInfo      ServerInfo   `json:"serverInfo"`
 	Capabilities    Capabilities `json:"capabilities"`
+	Instructions    string       `json:"instructions,omitempty"` // setup problems the agent should fix first
 }
 
 // ServerInfo contains server information
@@ -119,6 +138,7 @@ type InputSchema struct {
 type Property struct {
 	Type        string `json:"type"`
 	Description string `json:"description,omitempty"`
+	Path        bool   `json:"-"` // names a file or directory; checked by the sandbox
 }
 
 // NewServer creates a new MCP server
@@ -141,6 +161,8 @@ func NewServer(basePath string) (*Server, error) {
 		tools:         make(map[string]ToolHandler),
 		session:       newSessionTracker(),
 	}
+	s.loadConfig()
+	s.capabilities = detectCapabilities(basePath, s.issueConfig())
 
 	s.registerTools()
 
@@ -172,6 +194,7 @@ func (s *Server) registerTools() {
 	s.tools["search"] = s.handleSearch
 	s.tools["search_files"] = s.handleSearchFiles
 	s.tools["search_code"] = s.handleSearchCode
+	s.tools["rate_result"] = s.handleRateResult
 
 	// Read tools
 	s.tools["get_project"] = s.handleGetProject
@@ -181,6 +204,7 @@ func (s *Server) registerTools() {
 	s.tools["list_warnings"] = s.handleListWarnings
 	s.tools["list_patterns"] = s.handleListPatterns
 	s.tools["get_stats"] = s.handleGetStats
+	s.tools["get_token_report"] = s.handleGetTokenReport
 	s.tools["get_architecture"] = s.handleGetArchitecture
 	s.tools["get_evolution_timeline"] = s.handleGetEvolutionTimeline
 
@@ -189,18 +213,30 @@ func (s *Server) registerTools() {
 	s.tools["add_decision"] = s.handleAddDecision
 	s.tools["add_warning"] = s.handleAddWarning
 	s.tools["add_insight"] = s.handleAddInsight
+	s.tools["list_unreviewed_insights"] = s.handleListUnreviewedInsights
+	s.tools["promote_insight_to_decision"] = s.handlePromoteInsightToDecision
+	s.tools["promote_insight_to_warning"] = s.handlePromoteInsightToWarning
+	s.tools["dismiss_insight"] = s.destructive("dismiss_insight", s.handleDismissInsight)
+	s.tools["add_translation"] = s.handleAddTranslation
+	s.tools["link_issue"] = s.handleLinkIssue
 	s.tools["add_pattern"] = s.handleAddPattern
 	s.tools["add_evolution_event"] = s.handleAddEvolutionEvent
 	s.tools["save_conversation"] = s.handleSaveConversation
 	s.tools["compact_conversation"] = s.handleCompactConversation
+	s.tools["merge_conversations"] = s.destructive("merge_conversations", s.handleMergeConversations)
+	s.tools["split_conversation"] = s.destructive("split_conversation", s.handleSplitConversation)
 	s.tools["update_feature_state"] = s.handleUpdateFeatureState
-	s.tools["update_architecture"] = s.handleUpdateArchitecture
-	s.tools["update_project"] = s.handleUpdateProject
+	s.tools["update_architecture"] = s.destructive("update_architecture", s.handleUpdateArchitecture)
+	s.tools["update_project"] = s.destructive("update_project", s.handleUpdateProject)
 
 	// Feature lifecycle tools
 	s.tools["start_feature"] = s.handleStartFeature
-	s.tools["archive_feature"] = s.handleArchiveFeature
+	s.tools["archive_feature"] = s.destructive("archive_feature", s.handleArchiveFeature)
 	s.tools["recall_feature"] = s.handleRecallFeature
+	s.tools["handoff_feature"] = s.handleHandoffFeature
+	s.tools["save_plan"] = s.handleSavePlan
+	s.tools["update_plan"] = s.handleUpdatePlan
+	s.tools["get_plan"] = s.handleGetPlan
 
 	// Knowledge graph traversal
 	s.tools["get_related"] = s.handleGetRelated
@@ -208,16 +244,24 @@ func (s *Server) registerTools() {
 	// Indexing tools
 	s.tools["index"] = s.handleIndex
 	s.tools["index_status"] = s.handleIndexStatus
+	s.tools["list_files_needing_summary"] = s.handleListFilesNeedingSummary
 	s.tools["get_graph"] = s.handleGetGraph
+	s.tools["get_graph_evolution"] = s.handleGetGraphEvolution
 
 	// Analysis tools
 	s.tools["scan_imports"] = s.handleScanImports
 	s.tools["get_tree"] = s.handleGetTree
+	s.tools["get_code_map"] = s.handleGetCodeMap
 	s.tools["get_dependencies"] = s.handleGetDependencies
 	s.tools["trace_flow"] = s.handleTraceFlow
+	s.tools["call_graph"] = s.handleCallGraph
+	s.tools["get_module_boundaries"] = s.handleGetModuleBoundaries
+	s.tools["find_implementations"] = s.handleFindImplementations
 
 	// Token-saving tools
 	s.tools["get_signature"] = s.handleGetSignature
+	s.tools["get_skeleton"] = s.handleGetSkeleton
+	s.tools["get_skeleton_diff"] = s.handleGetSkeletonDiff
 	s.tools["get_types"] = s.handleGetTypes
 	s.tools["search_snippets"] = s.handleSearchSnippets
 	s.tools["get_recent_changes"] = s.handleGetRecentChanges
@@ -227,12 +271,19 @@ func (s *Server) registerTools() {
 
 	// High-impact extraction tools
 	s.tools["get_api_surface"] = s.handleGetAPISurface
+	s.tools["export_requests"] = s.handleExportRequests
+	s.tools["get_auth_matrix"] = s.handleGetAuthMatrix
 	s.tools["get_schema_models"] = s.handleGetSchemaModels
 	s.tools["get_config_map"] = s.handleGetConfigMap
+	s.tools["list_feature_flags"] = s.handleListFeatureFlags
 	s.tools["get_blueprint"] = s.handleGetBlueprint
+	s.tools["get_service_card"] = s.handleGetServiceCard
+	s.tools["extraction_plan"] = s.handleExtractionPlan
+	s.tools["get_dependencies_manifest"] = s.handleGetDependenciesManifest
 
 	// Compliance & onboarding tools
 	s.tools["check_compliance"] = s.handleCheckCompliance
+	s.tools["docs_coverage"] = s.handleDocsCoverage
 	s.tools["onboard"] = s.handleOnboard
 	s.tools["get_feed"] = s.handleGetFeed
 
@@ -242,10 +293,20 @@ func (s *Server) registerTools() {
 	s.tools["get_knowledge_risks"] = s.handleGetKnowledgeRisks
 	s.tools["get_file_correlations"] = s.handleGetFileCorrelations
 	s.tools["get_commit_context"] = s.handleGetCommitContext
+	s.tools["explain_error"] = s.handleExplainError
+
+	// Environment tools
+	s.tools["get_capabilities"] = s.handleGetCapabilities
+	s.tools["get_health"] = s.handleGetHealth
+	s.tools["get_worker_log"] = s.handleGetWorkerLog
 }
 
 // Run starts the MCP server
 func (s *Server) Run() {
+	// Config changes apply while the server runs
+	stopWatch := make(chan struct{})
+	go s.watchConfig(stopWatch)
+
 	scanner := bufio.NewScanner(os.Stdin)
 	// Increase buffer size for large messages
 	buf := make([]byte, 0, 1024*1024)
@@ -272,7 +333,9 @@ func (s *Server) Run() {
 	}
 
 	// Graceful shutdown: auto-save session, stop workers, close storage
+	close(stopWatch)
 	s.autoSaveSession("session_end")
+	s.flushTokenSpend()
 
 	if s.workerManager != nil && s.workerManager.IsRunning() {
 		s.workerManager.Stop()
@@ -310,6 +373,15 @@ func (s *Server) handleInitialize(req *Request) {
 			},
 		},
 	}
+
+	// Warn up front when the index is empty or stale instead of letting
+	// every tool return nothing
+	if problems := s.checkHealth(); len(problems) > 0 {
+		result.Instructions = healthInstructions(problems)
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "Health: %s\n", p.Problem)
+		}
+	}
 	s.sendResult(req.ID, result)
 }
 
@@ -331,6 +403,35 @@ func (s *Server) handleToolsCall(req *Request) {
 		return
 	}
 
+	// Reject tools that cannot work in this environment before running them
+	if reason := s.disabledReason(params.Name); reason != "" {
+		s.sendResult(req.ID, map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("Error: %s is unavailable in this environment: %s. Call get_capabilities for details.", params.Name, reason),
+				},
+			},
+			"isError": true,
+		})
+		return
+	}
+
+	// Keep file reads inside the project and its allowed roots
+	if violation := s.checkSandbox(params.Name, params.Arguments); violation != nil {
+		body, _ := json.Marshal(violation)
+		s.sendResult(req.ID, map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": string(body),
+				},
+			},
+			"isError": true,
+		})
+		return
+	}
+
 	// Track session activity
 	s.trackToolCall(params.Name, params.Arguments)
 
@@ -351,8 +452,29 @@ func (s *Server) handleToolsCall(req *Request) {
 	// Post-call: track IDs of knowledge items created
 	s.trackResultIDs(params.Name, result)
 
-	// Format result as text content
-	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	// Keep oversized responses within the tool's size limit
+	result = limitResponseSize(params.Name, result, s.currentConfig().Server)
+
+	// Deliver hooks queued outside the session (e.g. by the post-commit hook)
+	s.attachPendingHooks(result)
+
+	// Ask for a real summary of a file in context that only has a generated one
+	s.attachSummaryHook(params.Name, params.Arguments, result)
+
+	// Format result as text content with consistent token accounting
+	resultJSON, metrics := applyTokenMetrics(result)
+	s.session.TokensUsed += metrics.TokensUsed
+	s.session.TokensSaved += metrics.TokensSaved
+
+	// Record first so the budget check counts this call
+	feature := s.currentFeature()
+	used := s.recordTokenSpend(feature, params.Name, params.Arguments, metrics)
+	if warning := s.tokenBudgetWarning(feature, used); warning != "" {
+		if body, ok := result.(map[string]interface{}); ok {
+			body["token_budget_warning"] = warning
+			resultJSON, _ = json.MarshalIndent(body, "", "  ")
+		}
+	}
 
 	s.sendResult(req.ID, map[string]interface{}{
 		"content": []map[string]interface{}{
@@ -361,12 +483,32 @@ func (s *Server) handleToolsCall(req *Request) {
 				"text": string(resultJSON),
 			},
 		},
+		"_meta": metrics,
 	})
 
 	// Auto-capture replaces the AI-driven save instruction
 	s.checkAutoCaptureTriggers(params.Name)
 }
 
+// attachPendingHooks appends queued hooks to a map result's _hooks so the
+// agent sees them on its next tool call. Non-map results leave them queued.
+func (s *Server) attachPendingHooks(result interface{}) {
+	body, ok := result.(map[string]interface{})
+	if !ok {
+		return
+	}
+	pending, err := s.jsonStore.TakePendingHooks()
+	if err != nil || len(pending) == 0 {
+		return
+	}
+
+	hooks, _ := body["_hooks"].([]types.ConversationHook)
+	for _, p := range pending {
+		hooks = append(hooks, p.ConversationHook)
+	}
+	body["_hooks"] = hooks
+}
+
 func (s *Server) sendResult(id interface{}, result interface{}) {
 	resp := Response{
 		JSONRPC: "2.0",
@@ -403,21 +545,74 @@ func (s *Server) send(resp Response) {
 
 func (s *Server) handleSearchFiles(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		Query    string `json:"query"`
-		Language string `json:"language"`
-		Limit    int    `json:"limit"`
+		Query          string `json:"query"`
+		Language       string `json:"language"`
+		Limit          int    `json:"limit"`
+		IncludeDeleted bool   `json:"include_deleted"`
+		Expand         string `json:"expand"`
+		RollupAt       int    `json:"rollup_threshold"`
 	}
 	json.Unmarshal(params, &p)
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+	if p.RollupAt <= 0 {
+		p.RollupAt = defaultRollupThreshold
+	}
+	p.Expand = strings.Trim(filepath.ToSlash(p.Expand), "/")
 
-	files, err := s.sqliteIndex.SearchFiles(p.Query, p.Language, p.Limit)
+	// Fetch past the limit so a rollup counts every match in its directory
+	fetch := p.Limit * 10
+	if fetch < 200 {
+		fetch = 200
+	}
+	files, err := s.sqliteIndex.SearchFiles(p.Query, p.Language, fetch)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"files": files,
-		"total": len(files),
-	}, nil
+	result := map[string]interface{}{}
+	if p.Expand != "" {
+		// Expanding a rollup lists the matches in that directory only
+		var inDir []types.FileIndex
+		for _, f := range files {
+			if path.Dir(f.Path) == p.Expand {
+				inDir = append(inDir, f)
+			}
+		}
+		result["directory"] = p.Expand
+		result["total"] = len(inDir)
+		if len(inDir) > p.Limit {
+			inDir = inDir[:p.Limit]
+		}
+		result["files"] = inDir
+	} else {
+		kept, rollups := rollupFiles(files, p.RollupAt, p.Limit)
+		result["files"] = kept
+		result["total"] = len(files)
+		if len(rollups) > 0 {
+			result["directories"] = rollups
+			result["hint"] = "Directories with many matches are rolled up; pass expand with a directory's path to list its files"
+		}
+	}
+
+	// Tombstones are excluded from the search index; match them by path on request
+	if p.IncludeDeleted {
+		deleted, _ := s.jsonStore.GetDeletedFiles()
+		var matches []types.FileIndex
+		for path, f := range deleted {
+			if p.Language != "" && f.Language != p.Language {
+				continue
+			}
+			if p.Query == "" || containsAny(path, p.Query) {
+				matches = append(matches, f)
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+		result["deleted_files"] = matches
+	}
+
+	return result, nil
 }
 
 func (s *Server) handleSearchCode(params json.RawMessage) (interface{}, error) {
@@ -433,7 +628,7 @@ func (s *Server) handleSearchCode(params json.RawMessage) (interface{}, error) {
 	}
 
 	// Get the project root (parent of .teamcontext)
-	projectRoot := s.basePath[:len(s.basePath)-len("/.teamcontext")]
+	projectRoot := filepath.Dir(s.basePath)
 
 	matches, err := search.SearchCode(p.Pattern, projectRoot, p.Glob, p.Limit)
 	if err != nil {
@@ -476,11 +671,21 @@ func (s *Server) handleGetFeature(params json.RawMessage) (interface{}, error) {
 	// Get conversations
 	conversations, _ := s.jsonStore.GetConversations(p.ID)
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"feature":       feature,
 		"decisions":     decisions,
 		"conversations": conversations,
-	}, nil
+	}
+
+	// Ticket status of the feature and its decisions
+	issueKeys := append([]string{}, feature.Issues...)
+	for _, d := range decisions {
+		issueKeys = append(issueKeys, d.Issues...)
+	}
+	if linked := s.linkedIssues(issueKeys); len(linked) > 0 {
+		result["issues"] = linked
+	}
+	return result, nil
 }
 
 func (s *Server) handleListFeatures(params json.RawMessage) (interface{}, error) {
@@ -523,9 +728,10 @@ func (s *Server) handleListFeatures(params json.RawMessage) (interface{}, error)
 
 func (s *Server) handleListDecisions(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		Feature string `json:"feature"`
-		Status  string `json:"status"`
-		Limit   int    `json:"limit"`
+		Feature  string `json:"feature"`
+		Status   string `json:"status"`
+		Limit    int    `json:"limit"`
+		Language string `json:"language"`
 	}
 	json.Unmarshal(params, &p)
 
@@ -538,6 +744,8 @@ func (s *Server) handleListDecisions(params json.RawMessage) (interface{}, error
 		}
 	}
 
+	decisions = s.localizeDecisions(decisions, preferredLanguage(p.Language))
+
 	return map[string]interface{}{
 		"decisions": decisions,
 		"total":     len(decisions),
@@ -548,6 +756,7 @@ func (s *Server) handleListWarnings(params json.RawMessage) (interface{}, error)
 	var p struct {
 		Feature  string `json:"feature"`
 		Severity string `json:"severity"`
+		Language string `json:"language"`
 	}
 	json.Unmarshal(params, &p)
 
@@ -560,6 +769,8 @@ func (s *Server) handleListWarnings(params json.RawMessage) (interface{}, error)
 		}
 	}
 
+	warnings = s.localizeWarnings(warnings, preferredLanguage(p.Language))
+
 	return map[string]interface{}{
 		"warnings": warnings,
 		"total":    len(warnings),
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/internal/worker"
+)
+
+// setupTestServer returns a server over an empty project in a temp dir,
+// with background workers stopped. The project root is filepath.Dir of
+// s.basePath.
+func setupTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	root, err := os.MkdirTemp("", "teamcontext-mcp-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+	// Resolve symlinks (macOS /var -> /private/var) so sandbox checks agree
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	basePath := filepath.Join(root, ".teamcontext")
+	for _, dir := range []string{"knowledge", "index", "features", "cache"} {
+		if err := os.MkdirAll(filepath.Join(basePath, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s dir: %v", dir, err)
+		}
+	}
+
+	jsonStore := storage.NewJSONStore(basePath)
+	sqliteIndex, err := storage.NewSQLiteIndex(basePath)
+	if err != nil {
+		t.Fatalf("NewSQLiteIndex failed: %v", err)
+	}
+	t.Cleanup(func() { sqliteIndex.Close() })
+
+	s := &Server{
+		jsonStore:     jsonStore,
+		sqliteIndex:   sqliteIndex,
+		workerManager: worker.NewManager(basePath, jsonStore, sqliteIndex),
+		basePath:      basePath,
+		tools:         make(map[string]ToolHandler),
+		session:       newSessionTracker(),
+	}
+	s.capabilities = detectCapabilities(basePath, s.issueConfig())
+	s.registerTools()
+	return s
+}
+
+// writeProjectFile writes content to a project-relative path
+func writeProjectFile(t *testing.T, s *Server, rel, content string) string {
+	t.Helper()
+	path := filepath.Join(filepath.Dir(s.basePath), filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", rel, err)
+	}
+	return path
+}
+
+// callTool runs a tool with params marshaled from a map
+func callTool(t *testing.T, s *Server, name string, params map[string]interface{}) (interface{}, error) {
+	t.Helper()
+	raw, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal params: %v", err)
+	}
+	return s.HandleToolCall(name, raw)
+}
+
+// resultMap asserts a tool result is a map
+func resultMap(t *testing.T, result interface{}) map[string]interface{} {
+	t.Helper()
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("result is %T, want map", result)
+	}
+	return m
+}
+
+// mustCall runs a tool and fails the test on error
+func mustCall(t *testing.T, s *Server, name string, params map[string]interface{}) interface{} {
+	t.Helper()
+	result, err := callTool(t, s, name, params)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return result
+}
@@ -72,9 +72,9 @@ func (s *Server) trackResultIDs(toolName string, result interface{}) {
 	}
 
 	switch toolName {
-	case "add_decision":
+	case "add_decision", "promote_insight_to_decision":
 		s.session.DecisionsMade = append(s.session.DecisionsMade, id)
-	case "add_warning":
+	case "add_warning", "promote_insight_to_warning":
 		s.session.WarningsAdded = append(s.session.WarningsAdded, id)
 	case "add_insight":
 		s.session.InsightsAdded = append(s.session.InsightsAdded, id)
@@ -126,22 +126,7 @@ func (s *Server) autoSaveSession(trigger string) {
 		return
 	}
 
-	// Determine feature
-	feature := s.session.ActiveFeature
-	if feature == "" {
-		features, err := s.jsonStore.GetFeatures()
-		if err == nil {
-			for _, f := range features {
-				if f.Status == "active" {
-					feature = f.ID
-					break
-				}
-			}
-		}
-	}
-	if feature == "" {
-		feature = "_global"
-	}
+	feature := s.currentFeature()
 
 	// Ensure the feature directory exists
 	featureDir := filepath.Join(s.basePath, "features", feature, "conversations")
@@ -203,13 +188,16 @@ func (s *Server) autoSaveSession(trigger string) {
 	}
 
 	conv := &types.Conversation{
-		Feature:        feature,
-		Summary:        summary,
-		KeyPoints:      keyPoints,
-		FilesDiscussed: filesTouched,
-		DecisionsMade:  s.session.DecisionsMade,
-		StartTime:      startTime,
-		EndTime:        time.Now(),
+		Feature:          feature,
+		Summary:          summary,
+		Author:           s.currentAuthor(),
+		KeyPoints:        keyPoints,
+		FilesDiscussed:   filesTouched,
+		DecisionsMade:    s.session.DecisionsMade,
+		OriginalTokens:   s.session.TokensUsed + s.session.TokensSaved,
+		CompressedTokens: s.session.TokensUsed,
+		StartTime:        startTime,
+		EndTime:          time.Now(),
 	}
 
 	if err := s.jsonStore.SaveConversation(conv); err != nil {
@@ -231,6 +219,23 @@ func (s *Server) autoSaveSession(trigger string) {
 		trigger, conv.ID, callsSinceLastSave, len(filesTouched))
 }
 
+// currentFeature returns the feature the session is working on: the one
+// named in tool arguments, else the first active feature, else "_global".
+func (s *Server) currentFeature() string {
+	if s.session.ActiveFeature != "" {
+		return s.session.ActiveFeature
+	}
+	features, err := s.jsonStore.GetFeatures()
+	if err == nil {
+		for _, f := range features {
+			if f.Status == "active" {
+				return f.ID
+			}
+		}
+	}
+	return "_global"
+}
+
 // buildSessionSummary creates a human-readable summary of the session
 func (s *Server) buildSessionSummary(trigger string) string {
 	duration := time.Since(s.session.StartedAt).Round(time.Minute)
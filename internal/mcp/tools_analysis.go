@@ -9,10 +9,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/saeedalam/teamcontext/internal/blueprint"
 	"github.com/saeedalam/teamcontext/internal/git"
 	"github.com/saeedalam/teamcontext/internal/imports"
+	"github.com/saeedalam/teamcontext/internal/relpath"
 	"github.com/saeedalam/teamcontext/internal/search"
 	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/internal/tokenest"
 	"github.com/saeedalam/teamcontext/internal/typeregistry"
 	"github.com/saeedalam/teamcontext/pkg/types"
 )
@@ -228,6 +231,193 @@ func (s *Server) handleGetTree(params json.RawMessage) (interface{}, error) {
 
 
 
+// codeMapDir accumulates one directory while building the code map
+type codeMapDir struct {
+	name      string
+	path      string
+	dirs      map[string]*codeMapDir
+	files     []types.FileIndex
+	fileCount int // all matching files below this directory
+}
+
+// handleGetCodeMap returns a structured directory map built from the files
+// index, filtered by path and language and bounded by depth and file limit.
+func (s *Server) handleGetCodeMap(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path      string `json:"path"`
+		App       string `json:"app"`
+		Language  string `json:"language"`
+		MaxDepth  int    `json:"max_depth"`
+		Limit     int    `json:"limit"`
+		Recursive *bool  `json:"recursive"`
+		DirsOnly  bool   `json:"dirs_only"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	files, err := s.jsonStore.GetFilesIndex()
+	if err != nil {
+		return nil, fmt.Errorf("files index not found - run 'teamcontext index'")
+	}
+
+	// app scopes the map to a Gradle module or workspace; a single
+	// directory becomes the root, several are filtered on
+	var appDirs []string
+	if p.App != "" {
+		if appDirs = s.appDirs(p.App); len(appDirs) == 0 {
+			return nil, fmt.Errorf("app %q not found: no Gradle module, apps/, packages/ or services/ directory or documented service has that name", p.App)
+		}
+		if p.Path == "" && len(appDirs) == 1 {
+			p.Path = appDirs[0]
+			appDirs = nil
+		}
+	}
+
+	root := strings.Trim(filepath.ToSlash(p.Path), "/")
+	if root == "." {
+		root = ""
+	}
+
+	// Same defaults as get_tree: shallow at the project root, full below a path
+	maxDepth := p.MaxDepth
+	if maxDepth <= 0 && root == "" {
+		maxDepth = 2
+	}
+	if p.Recursive != nil && !*p.Recursive {
+		maxDepth = 1
+	}
+	limit := p.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+
+	var paths []string
+	for path, fi := range files {
+		if root != "" && path != root && !strings.HasPrefix(path, root+"/") {
+			continue
+		}
+		if p.Language != "" && !strings.EqualFold(fi.Language, p.Language) {
+			continue
+		}
+		if len(appDirs) > 0 {
+			inApp := false
+			for _, dir := range appDirs {
+				inApp = inApp || relpath.Within(path, dir)
+			}
+			if !inApp {
+				continue
+			}
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		return map[string]interface{}{
+			"error": fmt.Sprintf("No indexed files under '%s' matching the filters", p.Path),
+		}, nil
+	}
+
+	top := &codeMapDir{path: root, dirs: make(map[string]*codeMapDir)}
+	for _, path := range paths {
+		rel := strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+		if rel == "" {
+			// path pointed at a single file
+			rel = filepath.Base(path)
+		}
+		parts := strings.Split(rel, "/")
+
+		node := top
+		node.fileCount++
+		for depth, part := range parts[:len(parts)-1] {
+			if maxDepth > 0 && depth+1 > maxDepth {
+				break
+			}
+			child, ok := node.dirs[part]
+			if !ok {
+				child = &codeMapDir{
+					name: part,
+					path: strings.TrimPrefix(node.path+"/"+part, "/"),
+					dirs: make(map[string]*codeMapDir),
+				}
+				node.dirs[part] = child
+			}
+			child.fileCount++
+			node = child
+		}
+		if maxDepth == 0 || len(parts) <= maxDepth {
+			node.files = append(node.files, files[path])
+		}
+	}
+
+	shown := 0
+	truncated := false
+	var build func(d *codeMapDir, depth int) []types.CodeMapNode
+	build = func(d *codeMapDir, depth int) []types.CodeMapNode {
+		var nodes []types.CodeMapNode
+
+		names := make([]string, 0, len(d.dirs))
+		for name := range d.dirs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := d.dirs[name]
+			node := types.CodeMapNode{
+				Name:    child.name,
+				Path:    child.path,
+				Type:    "directory",
+				Summary: fmt.Sprintf("%d files", child.fileCount),
+			}
+			if maxDepth > 0 && depth+1 >= maxDepth && (len(child.dirs) > 0 || len(child.files) < child.fileCount) {
+				node.Summary += " (collapsed, use path or max_depth to expand)"
+			}
+			node.Children = build(child, depth+1)
+			nodes = append(nodes, node)
+		}
+
+		if p.DirsOnly {
+			return nodes
+		}
+		for _, fi := range d.files {
+			if shown >= limit {
+				truncated = true
+				break
+			}
+			nodes = append(nodes, types.CodeMapNode{
+				Name:     filepath.Base(fi.Path),
+				Path:     fi.Path,
+				Type:     "file",
+				Summary:  fi.Summary,
+				Language: fi.Language,
+				Exports:  len(fi.Exports),
+			})
+			shown++
+		}
+		return nodes
+	}
+	tree := build(top, 0)
+
+	result := map[string]interface{}{
+		"path":        root,
+		"tree":        tree,
+		"total_files": top.fileCount,
+		"shown_files": shown,
+	}
+	if p.Language != "" {
+		result["language"] = p.Language
+	}
+	if maxDepth > 0 {
+		result["max_depth"] = maxDepth
+	}
+	if truncated {
+		result["truncated"] = true
+		result["note"] = fmt.Sprintf("Showing first %d files. Narrow with path/language or raise limit.", limit)
+	}
+	return result, nil
+}
+
 // handleGetSignature finds a file by name and returns its code signature (parsed live)
 func (s *Server) handleGetSignature(params json.RawMessage) (interface{}, error) {
 	var p struct {
@@ -370,23 +560,29 @@ func (s *Server) handleGetDependencies(params json.RawMessage) (interface{}, err
 	}
 
 	files, _ := s.jsonStore.GetFilesIndex()
+	build := s.loadBuildDeps()
 
 	var upstream, downstream []types.DependencyNode
 
 	if p.Direction == "upstream" || p.Direction == "both" {
 		visited := map[string]bool{}
-		upstream = s.collectDependencies(p.Path, "imports", p.Depth, 0, visited, files)
+		upstream = s.collectDependencies(p.Path, "imports", p.Depth, 0, visited, files, build)
 	}
 
 	if p.Direction == "downstream" || p.Direction == "both" {
 		visited := map[string]bool{}
-		downstream = s.collectDependencies(p.Path, "imported_by", p.Depth, 0, visited, files)
+		downstream = s.collectDependencies(p.Path, "imported_by", p.Depth, 0, visited, files, build)
 	}
 
 	result := map[string]interface{}{
 		"path":      p.Path,
 		"direction": p.Direction,
 		"depth":     p.Depth,
+		"source":    "imports",
+	}
+	if targets := build.targetsOf(p.Path); len(targets) > 0 {
+		result["source"] = "build_graph"
+		result["build_targets"] = targets
 	}
 
 	if p.Direction == "upstream" || p.Direction == "both" {
@@ -425,6 +621,7 @@ func (s *Server) handleTraceFlow(params json.RawMessage) (interface{}, error) {
 	}
 
 	files, _ := s.jsonStore.GetFilesIndex()
+	build := s.loadBuildDeps()
 
 	relation := "imports"
 	if p.Direction == "backward" {
@@ -467,7 +664,14 @@ func (s *Server) handleTraceFlow(params json.RawMessage) (interface{}, error) {
 
 		chain = append(chain, node)
 
-		// Follow edges
+		// Follow declared build deps when the file is owned by a build
+		// target, scanned import edges otherwise
+		if build.owns(filePath) {
+			for _, n := range build.neighbors(filePath, relation == "imports") {
+				dfs(n.path, depth+1)
+			}
+			return
+		}
 		edges, _ := s.jsonStore.GetEdgesFrom("file", filePath)
 		for _, e := range edges {
 			if e.Relation == relation && e.ToType == "file" {
@@ -499,13 +703,150 @@ func (s *Server) handleTraceFlow(params json.RawMessage) (interface{}, error) {
 
 
 
-// collectDependencies does BFS traversal to collect dependency nodes
-func (s *Server) collectDependencies(filePath, relation string, maxDepth, currentDepth int, visited map[string]bool, files map[string]types.FileIndex) []types.DependencyNode {
+// implementation is a type that implements or extends a trait/interface
+type implementation struct {
+	Type     string   `json:"type"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Relation string   `json:"relation"` // "implements" or "extends"
+	Methods  []string `json:"methods,omitempty"`
+}
+
+// handleFindImplementations finds the types implementing a trait or
+// interface, and the traits/interfaces extending it, across indexed files.
+// Go interfaces are satisfied implicitly and are not covered.
+func (s *Server) handleFindImplementations(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Name  string `json:"name"`
+		Path  string `json:"path"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if p.Limit <= 0 {
+		p.Limit = 50
+	}
+	// Accept a qualified name: crate::db::Store, com.acme.Store
+	name := p.Name
+	if i := strings.LastIndexAny(name, ":."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	files, _ := s.jsonStore.GetFilesIndex()
+	projectRoot := filepath.Dir(s.basePath)
+	paths := make([]string, 0, len(files))
+	for path, fi := range files {
+		if fi.DeletedAt != nil || (p.Path != "" && !strings.HasPrefix(path, p.Path)) {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var impls []implementation
+	var definitions []string
+	for _, path := range paths {
+		fullPath := filepath.Join(projectRoot, path)
+		// Only parse files that mention the name
+		content, err := os.ReadFile(fullPath)
+		if err != nil || !strings.Contains(string(content), name) {
+			continue
+		}
+		sk, err := skeleton.ParseFile(fullPath)
+		if err != nil {
+			continue
+		}
+
+		for _, iface := range sk.Interfaces {
+			if iface.Name == name {
+				definitions = append(definitions, fmt.Sprintf("%s:%d", path, iface.Line))
+			}
+			for _, parent := range iface.Extends {
+				if baseTypeName(parent) == name {
+					impls = append(impls, implementation{Type: iface.Name, File: path, Line: iface.Line, Relation: "extends"})
+				}
+			}
+		}
+		for _, cls := range sk.Classes {
+			relation := ""
+			for _, iface := range cls.Implements {
+				if baseTypeName(iface) == name {
+					relation = "implements"
+				}
+			}
+			if relation == "" && cls.Extends != "" && baseTypeName(cls.Extends) == name {
+				relation = "extends"
+			}
+			if relation == "" {
+				continue
+			}
+			impl := implementation{Type: cls.Name, File: path, Line: cls.Line, Relation: relation}
+			for _, m := range cls.Methods {
+				impl.Methods = append(impl.Methods, m.Name)
+			}
+			impls = append(impls, impl)
+		}
+	}
+
+	total := len(impls)
+	if len(impls) > p.Limit {
+		impls = impls[:p.Limit]
+	}
+	result := map[string]interface{}{
+		"name":            name,
+		"definitions":     definitions,
+		"implementations": impls,
+		"total":           total,
+	}
+	if total == 0 {
+		result["hint"] = "No implementations found. Check the name, or run index if the files are new. Go interfaces are implicit and not detected."
+	}
+	return result, nil
+}
+
+// baseTypeName strips paths and generic arguments: crate::db::Store<T> -> Store
+func baseTypeName(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.IndexAny(name, "<["); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndexAny(name, ":.\\"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// collectDependencies does BFS traversal to collect dependency nodes. Files
+// owned by a Bazel/Pants target follow the declared build deps instead of
+// import edges.
+func (s *Server) collectDependencies(filePath, relation string, maxDepth, currentDepth int, visited map[string]bool, files map[string]types.FileIndex, build *buildDeps) []types.DependencyNode {
 	if currentDepth >= maxDepth || visited[filePath] {
 		return nil
 	}
 	visited[filePath] = true
 
+	if build.owns(filePath) {
+		var nodes []types.DependencyNode
+		for _, n := range build.neighbors(filePath, relation == "imports") {
+			node := types.DependencyNode{
+				Path:        n.path,
+				Depth:       currentDepth + 1,
+				BuildTarget: n.target,
+			}
+			if fi, ok := files[n.path]; ok {
+				node.Summary = fi.Summary
+				node.Language = fi.Language
+			}
+			node.Children = s.collectDependencies(n.path, relation, maxDepth, currentDepth+1, visited, files, build)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
 	edges, _ := s.jsonStore.GetEdgesFrom("file", filePath)
 
 	var nodes []types.DependencyNode
@@ -522,7 +863,7 @@ func (s *Server) collectDependencies(filePath, relation string, maxDepth, curren
 			node.Summary = fi.Summary
 			node.Language = fi.Language
 		}
-		node.Children = s.collectDependencies(e.ToID, relation, maxDepth, currentDepth+1, visited, files)
+		node.Children = s.collectDependencies(e.ToID, relation, maxDepth, currentDepth+1, visited, files, build)
 		nodes = append(nodes, node)
 	}
 	return nodes
@@ -559,6 +900,7 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 		Limit     int    `json:"limit"`
 		MaxChars  int    `json:"max_chars"`
 		Recursive bool   `json:"recursive"` // Default to false
+		Full      bool   `json:"full"`      // Full skeletons for generated files too
 	}
 	if err := json.Unmarshal(params, &p); err != nil {
 		return nil, err
@@ -591,14 +933,17 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 	if info.IsDir() {
 		// Walk directory and extract skeletons from supported files (with limit)
 		var allSkeletons []*types.CodeSkeleton
-		var totalOriginalLines, totalSkeletonLines int
+		var totalOriginalLines, totalSkeletonLines, totalOriginalTokens int
 		filesProcessed := 0
 		filesSkipped := 0
+		generated := 0
 
 		supportedExts := map[string]bool{
 			".ts": true, ".tsx": true, ".js": true, ".jsx": true,
 			".go": true, ".py": true, ".java": true, ".cs": true,
-			".rb": true, ".rs": true, ".kt": true, ".swift": true,
+			".rb": true, ".rs": true, ".kt": true, ".swift": true, ".dart": true, ".m": true, ".mm": true,
+			".ex": true, ".exs": true, ".erl": true, ".hrl": true, ".proto": true, ".tf": true,
+			".ipynb": true, ".vue": true, ".svelte": true,
 		}
 
 		err := filepath.Walk(p.Path, func(filePath string, info os.FileInfo, err error) error {
@@ -630,9 +975,16 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 
 			sk, err := skeleton.ParseFile(filePath)
 			if err == nil {
+				if sk.Generated && !p.Full {
+					skeleton.Compact(sk)
+					generated++
+				}
 				allSkeletons = append(allSkeletons, sk)
 				totalOriginalLines += sk.LineCount
 				totalSkeletonLines += sk.SkeletonLines
+				if content, err := os.ReadFile(filePath); err == nil {
+					totalOriginalTokens += tokenest.Count(string(content))
+				}
 				filesProcessed++
 			}
 			return nil
@@ -642,11 +994,6 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 			return nil, err
 		}
 
-		savingsPercent := 0
-		if totalOriginalLines > 0 {
-			savingsPercent = 100 - (totalSkeletonLines * 100 / totalOriginalLines)
-		}
-
 		result := map[string]interface{}{
 			"path":            p.Path,
 			"files_processed": len(allSkeletons),
@@ -654,16 +1001,29 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 			"truncated":       filesSkipped > 0,
 			"original_lines":  totalOriginalLines,
 			"skeleton_lines":  totalSkeletonLines,
-			"tokens_saved":    fmt.Sprintf("%d%%", savingsPercent),
+			baselineTokensKey: totalOriginalTokens,
 		}
 
 		if p.Format == "json" {
 			result["skeletons"] = allSkeletons
+		} else if p.Format == "mermaid" {
+			// One diagram for the directory, so relations across files show
+			diagram := skeleton.FormatSkeletonMermaid(allSkeletons...)
+			for n := len(allSkeletons) - 1; len(diagram) > p.MaxChars && n > 0; n-- {
+				diagram = skeleton.FormatSkeletonMermaid(allSkeletons[:n]...)
+				result["output_truncated"] = true
+			}
+			result["skeleton"] = diagram
 		} else {
 			var combined strings.Builder
 			for _, sk := range allSkeletons {
-				combined.WriteString(fmt.Sprintf("\n// === %s ===\n", sk.Path))
-				formatted := skeleton.FormatSkeleton(sk)
+				var formatted string
+				if p.Format == "markdown" {
+					formatted = "\n" + skeleton.FormatSkeletonMarkdown(sk, s.markdownPath(sk.Path))
+				} else {
+					combined.WriteString(fmt.Sprintf("\n// === %s ===\n", sk.Path))
+					formatted = skeleton.FormatSkeleton(sk)
+				}
 				// Check if we'd exceed max_chars
 				if combined.Len()+len(formatted) > p.MaxChars {
 					combined.WriteString("\n// ... output truncated (max_chars reached) ...\n")
@@ -675,8 +1035,13 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 			result["skeleton"] = combined.String()
 		}
 
+		if generated > 0 {
+			result["generated_compacted"] = generated
+		}
 		if filesSkipped > 0 {
 			result["note"] = fmt.Sprintf("Use 'limit' param to increase (current: %d). Use 'path' to target specific subdirectory.", p.Limit)
+		} else if generated > 0 {
+			result["note"] = fmt.Sprintf("%d generated file(s) reduced to top-level exports. Pass full=true for their complete skeletons.", generated)
 		}
 
 		return result, nil
@@ -687,13 +1052,18 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse skeleton: %w", err)
 	}
+	counts := types.SkeletonCounts{
+		Classes: len(sk.Classes), Functions: len(sk.Functions), Interfaces: len(sk.Interfaces),
+		Types: len(sk.Types), Enums: len(sk.Enums),
+	}
+	if sk.Generated && !p.Full {
+		skeleton.Compact(sk)
+	}
 
-	// Calculate token savings estimate
-	originalTokens := sk.LineCount * 4 // rough estimate: 4 tokens per line
-	skeletonTokens := sk.SkeletonLines * 4
-	savingsPercent := 0
-	if originalTokens > 0 {
-		savingsPercent = 100 - (skeletonTokens * 100 / originalTokens)
+	// Baseline for token savings: reading the whole file instead
+	originalTokens := 0
+	if content, err := os.ReadFile(p.Path); err == nil {
+		originalTokens = tokenest.Count(string(content))
 	}
 
 	result := map[string]interface{}{
@@ -701,23 +1071,46 @@ func (s *Server) handleGetSkeleton(params json.RawMessage) (interface{}, error)
 		"language":        sk.Language,
 		"original_lines":  sk.LineCount,
 		"skeleton_lines":  sk.SkeletonLines,
-		"tokens_saved":    fmt.Sprintf("%d%%", savingsPercent),
-		"classes":         len(sk.Classes),
-		"functions":       len(sk.Functions),
-		"interfaces":      len(sk.Interfaces),
-		"types":           len(sk.Types),
-		"enums":           len(sk.Enums),
+		baselineTokensKey: originalTokens,
+		"classes":         counts.Classes,
+		"functions":       counts.Functions,
+		"interfaces":      counts.Interfaces,
+		"types":           counts.Types,
+		"enums":           counts.Enums,
+	}
+	if sk.Generated {
+		result["generated"] = true
+		if sk.Counts != nil {
+			result["note"] = "Generated file reduced to top-level exports. Pass full=true for the complete skeleton."
+		}
 	}
 
-	if p.Format == "json" {
+	switch p.Format {
+	case "json":
 		result["skeleton"] = sk
-	} else {
+	case "markdown":
+		result["skeleton"] = skeleton.FormatSkeletonMarkdown(sk, s.markdownPath(p.Path))
+	case "mermaid":
+		result["skeleton"] = skeleton.FormatSkeletonMermaid(sk)
+	default:
 		result["skeleton"] = skeleton.FormatSkeleton(sk)
 	}
 
 	return result, nil
 }
 
+// markdownPath makes a path project-relative with forward slashes, so line
+// links in Markdown output resolve from the repository root
+func (s *Server) markdownPath(path string) string {
+	projectRoot := filepath.Dir(s.basePath)
+	if abs, err := filepath.Abs(path); err == nil {
+		if rel, err := filepath.Rel(projectRoot, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
 func (s *Server) handleGetTypes(params json.RawMessage) (interface{}, error) {
 	var p struct {
 		Path   string `json:"path"`
@@ -743,24 +1136,25 @@ func (s *Server) handleGetTypes(params json.RawMessage) (interface{}, error) {
 	}
 
 	if info.IsDir() {
-		// Walk directory and extract types from all TypeScript files
+		// Walk directory and extract types from all TypeScript, Go, Rust, Java, C# and .proto files
 		err := filepath.Walk(p.Path, func(filePath string, info os.FileInfo, err error) error {
 			if err != nil || info.IsDir() {
 				if info != nil && info.IsDir() {
 					name := info.Name()
-					if name == "node_modules" || name == "dist" || name == ".git" {
+					if name == "node_modules" || name == "dist" || name == ".git" || name == "vendor" || name == "target" {
 						return filepath.SkipDir
 					}
 				}
 				return nil
 			}
 
-			ext := strings.ToLower(filepath.Ext(filePath))
-			if ext != ".ts" && ext != ".tsx" {
+			switch strings.ToLower(filepath.Ext(filePath)) {
+			case ".ts", ".tsx", ".go", ".rs", ".java", ".cs", ".proto":
+			default:
 				return nil
 			}
 
-			typeDefs, enumDefs, err := typeregistry.ExtractTypes(filePath)
+			typeDefs, enumDefs, err := extractFileTypes(filePath)
 			if err == nil {
 				allTypeDefs = append(allTypeDefs, typeDefs...)
 				allEnumDefs = append(allEnumDefs, enumDefs...)
@@ -773,7 +1167,7 @@ func (s *Server) handleGetTypes(params json.RawMessage) (interface{}, error) {
 		}
 	} else {
 		// Single file
-		typeDefs, enumDefs, err := typeregistry.ExtractTypes(p.Path)
+		typeDefs, enumDefs, err := extractFileTypes(p.Path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract types: %w", err)
 		}
@@ -799,6 +1193,22 @@ func (s *Server) handleGetTypes(params json.RawMessage) (interface{}, error) {
 	return result, nil
 }
 
+// extractFileTypes reads the type definitions of one file: TypeScript with
+// the type registry, other languages from their skeletons, structs and
+// protobuf messages with fields and enums with members
+func extractFileTypes(path string) ([]types.TypeDef, []types.EnumDef, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go", ".rs", ".java", ".cs", ".proto":
+		sk, err := skeleton.ParseFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		typeDefs := append(append([]types.TypeDef{}, sk.Interfaces...), sk.Types...)
+		return typeDefs, sk.Enums, nil
+	}
+	return typeregistry.ExtractTypes(path)
+}
+
 func (s *Server) handleSearchSnippets(params json.RawMessage) (interface{}, error) {
 	var p struct {
 		Query    string `json:"query"`
@@ -827,7 +1237,7 @@ func (s *Server) handleSearchSnippets(params json.RawMessage) (interface{}, erro
 	if err == nil && len(chunks) > 0 {
 		// Convert chunks to snippets
 		for _, chunk := range chunks {
-			tokenCount := len(chunk.Content) / 4
+			tokenCount := tokenest.Count(chunk.Content)
 			snippet := types.CodeSnippet{
 				Path:       chunk.FilePath,
 				StartLine:  chunk.StartLine,
@@ -844,7 +1254,7 @@ func (s *Server) handleSearchSnippets(params json.RawMessage) (interface{}, erro
 	// If no indexed results, fallback to grep-based search
 	if len(snippets) == 0 {
 		searchSource = "grep"
-		projectRoot := s.basePath[:len(s.basePath)-len("/.teamcontext")]
+		projectRoot := filepath.Dir(s.basePath)
 
 		matches, err := search.SearchCode(p.Query, projectRoot, p.Language, p.Limit*3)
 		if err != nil {
@@ -887,7 +1297,7 @@ func (s *Server) handleSearchSnippets(params json.RawMessage) (interface{}, erro
 				// Extract snippet
 				snippetLines := lines[startLine-1 : endLine]
 				snippetContent := strings.Join(snippetLines, "\n")
-				tokenCount := len(snippetContent) / 4
+				tokenCount := tokenest.Count(snippetContent)
 
 				snippet := types.CodeSnippet{
 					Path:       path,
@@ -932,7 +1342,7 @@ func (s *Server) handleGetRecentChanges(params json.RawMessage) (interface{}, er
 	}
 
 	// Get project root
-	projectRoot := s.basePath[:len(s.basePath)-len("/.teamcontext")]
+	projectRoot := filepath.Dir(s.basePath)
 
 	var changes []types.GitChange
 	var err error
@@ -1103,14 +1513,31 @@ func (s *Server) handleResumeContext(params json.RawMessage) (interface{}, error
 		convSummaries = append(convSummaries, entry)
 	}
 	context["conversations"] = convSummaries
+	// Plans still in progress, with the step to pick up
+	plans, _ := s.jsonStore.GetPlans(p.Feature)
+	var activePlans []map[string]interface{}
+	for i := range plans {
+		if plans[i].Status == "active" {
+			activePlans = append(activePlans, planProgress(&plans[i]))
+		}
+	}
+	if len(activePlans) > 0 {
+		context["plans"] = activePlans
+	}
 	context["decisions"] = decisions
 	context["decision_count"] = len(decisions)
 	context["conversation_count"] = len(conversations)
 
-	// Calculate tokens saved
-	estimatedFullTokens := 50000 // typical full context rebuild
-	compressedTokens := 2000 + len(decisions)*200 + len(conversations)*500
-	context["tokens_saved_estimate"] = fmt.Sprintf("%d%%", 100-(compressedTokens*100/estimatedFullTokens))
+	// Baseline for token savings: the original conversations plus full decisions
+	baseline := tokenest.CountJSON(decisions)
+	for _, conv := range conversations {
+		if conv.OriginalTokens > 0 {
+			baseline += conv.OriginalTokens
+		} else {
+			baseline += tokenest.CountJSON(conv)
+		}
+	}
+	context[baselineTokensKey] = baseline
 
 	// Add hooks to remind AI about conversation management
 	context["_hooks"] = []types.ConversationHook{
@@ -1236,13 +1663,26 @@ func (s *Server) handleGetTaskContext(params json.RawMessage) (interface{}, erro
 		}
 	}
 
+	// Say how tests are actually run here, next to the file if one is given
+	projectRoot := filepath.Dir(s.basePath)
+	testDir := projectRoot
+	if p.File != "" {
+		testDir = filepath.Dir(relpath.ToAbs(projectRoot, p.File))
+	}
+	testCommands := blueprint.DetectTestCommands(projectRoot, testDir)
+	bundle.Checklist = blueprint.ChecklistWithTestCommands(bundle.Checklist, testCommands)
+	if p.Task == "add-test" && len(testCommands) > 0 && testCommands[0].Single != "" {
+		bundle.Checklist = append(bundle.Checklist, "Run just the new test — `"+testCommands[0].Single+"`")
+	}
+
 	return map[string]interface{}{
-		"task":        bundle.Task,
-		"domain":      bundle.Domain,
-		"patterns":    bundle.Patterns,
-		"warnings":    bundle.Warnings,
-		"checklist":   bundle.Checklist,
-		"related_docs": bundle.RelatedDocs,
+		"task":          bundle.Task,
+		"domain":        bundle.Domain,
+		"patterns":      bundle.Patterns,
+		"warnings":      bundle.Warnings,
+		"checklist":     bundle.Checklist,
+		"test_commands": testCommands,
+		"related_docs":  bundle.RelatedDocs,
 	}, nil
 }
 
@@ -1276,13 +1716,37 @@ func detectLanguageFromPath(path string) string {
 		return "php"
 	case ".swift":
 		return "swift"
+	case ".m":
+		return "objc"
+	case ".mm":
+		return "objcpp"
 	case ".kt", ".kts":
 		return "kotlin"
 	case ".scala", ".sc":
 		return "scala"
-	default:
-		return "unknown"
-	}
+	case ".sh", ".bash", ".zsh":
+		return "shell"
+	case ".ipynb":
+		return "jupyter"
+	case ".dart":
+		return "dart"
+	case ".ex", ".exs":
+		return "elixir"
+	case ".erl", ".hrl":
+		return "erlang"
+	case ".proto":
+		return "protobuf"
+	case ".tf":
+		return "terraform"
+	case ".vue":
+		return "vue"
+	case ".svelte":
+		return "svelte"
+	}
+	if lang := skeleton.DetectFileLanguage(path); lang != "" {
+		return lang
+	}
+	return "unknown"
 }
 
 func findBlockEnd(lines []string, startIdx int, maxLines int) int {
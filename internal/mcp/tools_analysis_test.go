@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// indexFiles saves files to the index with a summary and language
+func indexFiles(t *testing.T, s *Server, files map[string]string) {
+	t.Helper()
+	bulk := make(map[string]types.FileIndex)
+	for path, lang := range files {
+		bulk[path] = types.FileIndex{Path: path, Language: lang, Summary: "summary of " + path}
+	}
+	if err := s.jsonStore.SaveFilesIndexBulk(bulk); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+}
+
+// codeMapPaths flattens a code map to "d:path" and "f:path" entries
+func codeMapPaths(nodes []types.CodeMapNode) []string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, n.Type[:1]+":"+n.Path)
+		out = append(out, codeMapPaths(n.Children)...)
+	}
+	return out
+}
+
+func TestGetCodeMap(t *testing.T) {
+	s := setupTestServer(t)
+	indexFiles(t, s, map[string]string{
+		"main.go":                 "go",
+		"api/users.go":            "go",
+		"api/handlers/orders.go":  "go",
+		"web/src/app.ts":          "typescript",
+		"web/src/components/a.ts": "typescript",
+	})
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   []string
+		total  int
+	}{
+		{
+			name:   "root defaults to depth 2",
+			params: map[string]interface{}{},
+			want:   []string{"d:api", "d:api/handlers", "f:api/users.go", "d:web", "d:web/src", "f:main.go"},
+			total:  5,
+		},
+		{
+			name:   "path expands fully",
+			params: map[string]interface{}{"path": "web"},
+			want:   []string{"d:web/src", "d:web/src/components", "f:web/src/components/a.ts", "f:web/src/app.ts"},
+			total:  2,
+		},
+		{
+			name:   "language filter",
+			params: map[string]interface{}{"language": "go", "max_depth": 5},
+			want:   []string{"d:api", "d:api/handlers", "f:api/handlers/orders.go", "f:api/users.go", "f:main.go"},
+			total:  3,
+		},
+		{
+			name:   "not recursive",
+			params: map[string]interface{}{"path": "api", "recursive": false},
+			want:   []string{"d:api/handlers", "f:api/users.go"},
+			total:  2,
+		},
+		{
+			name:   "dirs only",
+			params: map[string]interface{}{"dirs_only": true},
+			want:   []string{"d:api", "d:api/handlers", "d:web", "d:web/src"},
+			total:  5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := resultMap(t, mustCall(t, s, "get_code_map", tt.params))
+			got := codeMapPaths(m["tree"].([]types.CodeMapNode))
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("tree = %v, want %v", got, tt.want)
+			}
+			if m["total_files"] != tt.total {
+				t.Errorf("total_files = %v, want %d", m["total_files"], tt.total)
+			}
+		})
+	}
+}
+
+func TestGetCodeMapLimitAndMisses(t *testing.T) {
+	s := setupTestServer(t)
+	indexFiles(t, s, map[string]string{"a.go": "go", "b.go": "go", "c.go": "go"})
+
+	m := resultMap(t, mustCall(t, s, "get_code_map", map[string]interface{}{"limit": 2}))
+	if m["shown_files"] != 2 || m["truncated"] != true {
+		t.Errorf("shown=%v truncated=%v, want 2 and true", m["shown_files"], m["truncated"])
+	}
+
+	m = resultMap(t, mustCall(t, s, "get_code_map", map[string]interface{}{"language": "rust"}))
+	if m["error"] == nil {
+		t.Errorf("expected an error for a filter matching nothing, got %v", m)
+	}
+}
+
+func TestGetCodeMapGradleApp(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "settings.gradle.kts", "include(\":app\", \":feature:login\")\n")
+	writeProjectFile(t, s, "app/build.gradle.kts", "plugins { id(\"com.android.application\") }\n")
+	writeProjectFile(t, s, "feature/login/build.gradle.kts", "plugins { id(\"com.android.library\") }\n")
+	indexFiles(t, s, map[string]string{
+		"app/src/main/kotlin/MainActivity.kt":             "kotlin",
+		"feature/login/src/main/kotlin/LoginViewModel.kt": "kotlin",
+		"feature/login/build.gradle.kts":                  "kotlin",
+	})
+
+	m := resultMap(t, mustCall(t, s, "get_code_map", map[string]interface{}{"app": "login", "dirs_only": true}))
+	if m["path"] != "feature/login" || m["total_files"] != 2 {
+		t.Errorf("path = %v, total_files = %v; want feature/login and 2", m["path"], m["total_files"])
+	}
+	if _, err := callTool(t, s, "get_code_map", map[string]interface{}{"app": "checkout"}); err == nil {
+		t.Error("unknown app accepted")
+	}
+}
+
+func TestGetTypesReadsGoAndRustStructs(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "models/user.go", "package models\n\ntype User struct {\n\tID string `json:\"id\"`\n}\n")
+	writeProjectFile(t, s, "models/order.rs", "pub struct Order {\n    pub total: u64,\n}\n")
+	writeProjectFile(t, s, "models/vendor/skip.go", "package skip\n\ntype Skipped struct{ A int }\n")
+
+	dir := filepath.Join(filepath.Dir(s.basePath), "models")
+	result := resultMap(t, mustCall(t, s, "get_types", map[string]interface{}{"path": dir}))
+	if result["files_processed"] != 2 {
+		t.Errorf("files_processed = %v, want the Go and Rust files outside vendor", result["files_processed"])
+	}
+	text, _ := result["types"].(string)
+	for _, want := range []string{"struct User {\n  ID: string `json:\"id\"`;\n}", "struct Order {\n  total: u64;\n}"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("types are missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Skipped") {
+		t.Errorf("vendored types were read:\n%s", text)
+	}
+}
+
+func TestGetTypesListsEnumMembers(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "model/status.go", "package model\n\ntype Status int\n\nconst (\n\tActive Status = iota\n\tSuspended\n)\n")
+	writeProjectFile(t, s, "model/Role.java", "public enum Role {\n    ADMIN, MEMBER;\n}\n")
+	writeProjectFile(t, s, "model/Tier.cs", "public enum Tier\n{\n    Free,\n    Pro = 2\n}\n")
+	writeProjectFile(t, s, "model/plan.ts", "export enum Plan {\n  Monthly = 'monthly', // billed each month\n  Yearly = 'yearly',\n}\n")
+
+	dir := filepath.Join(filepath.Dir(s.basePath), "model")
+	result := resultMap(t, mustCall(t, s, "get_types", map[string]interface{}{"path": dir}))
+	text, _ := result["types"].(string)
+	for _, want := range []string{
+		"enum Status {\n  Active,\n  Suspended,\n}",
+		"enum Role {\n  ADMIN,\n  MEMBER,\n}",
+		"enum Tier {\n  Free,\n  Pro,\n}",
+		"enum Plan {\n  Monthly,\n  Yearly,\n}",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("types are missing %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+)
+
+// =============================================================================
+// CALL GRAPH
+// Which functions of a file call which, from the calls the skeleton parser
+// records. trace_flow follows imports between files; this stays inside one.
+// =============================================================================
+
+// callNode is a function or method of the file in the call graph
+type callNode struct {
+	Name     string   `json:"name"` // Class.method for methods
+	Line     int      `json:"line"`
+	Calls    []string `json:"calls,omitempty"`
+	CalledBy []string `json:"called_by,omitempty"`
+}
+
+// callStep is a function reached from the one asked about
+type callStep struct {
+	Name  string `json:"name"`
+	Line  int    `json:"line"`
+	Depth int    `json:"depth"`
+	Via   string `json:"via,omitempty"` // the function it was reached through
+}
+
+func (s *Server) handleCallGraph(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path      string `json:"path"`
+		Function  string `json:"function"`
+		Direction string `json:"direction"`
+		Depth     int    `json:"depth"`
+		Format    string `json:"format"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if p.Direction == "" {
+		p.Direction = "forward"
+	}
+	if p.Direction != "forward" && p.Direction != "backward" && p.Direction != "both" {
+		return nil, fmt.Errorf("unknown direction %q: use forward, backward or both", p.Direction)
+	}
+	if p.Depth <= 0 {
+		p.Depth = 3
+	}
+	if p.Depth > 10 {
+		p.Depth = 10
+	}
+
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("path not found: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory: call_graph works on one file; use trace_flow across files", p.Path)
+	}
+	sk, err := skeleton.ParseFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*callNode)
+	var order []string
+	addNode := func(name string, line int, calls []string) {
+		nodes[name] = &callNode{Name: name, Line: line, Calls: calls}
+		order = append(order, name)
+	}
+	for _, fn := range sk.Functions {
+		addNode(fn.Name, fn.Line, fn.Calls)
+	}
+	for _, c := range sk.Classes {
+		if c.Constructor != nil {
+			addNode(c.Name+"."+c.Constructor.Name, c.Constructor.Line, c.Constructor.Calls)
+		}
+		for _, m := range c.Methods {
+			addNode(c.Name+"."+m.Name, m.Line, m.Calls)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return nodes[order[i]].Line < nodes[order[j]].Line })
+
+	edges := 0
+	for _, name := range order {
+		for _, callee := range nodes[name].Calls {
+			if target, ok := nodes[callee]; ok {
+				target.CalledBy = append(target.CalledBy, name)
+				edges++
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"path":     relpath.FromAbs(filepath.Dir(s.basePath), p.Path),
+		"language": sk.Language,
+		"note":     "Best effort: calls are matched by name within this file, so calls through variables or callbacks may be missing",
+	}
+
+	if p.Function == "" {
+		list := make([]*callNode, 0, len(order))
+		var entryPoints []string
+		for _, name := range order {
+			n := nodes[name]
+			list = append(list, n)
+			if len(n.CalledBy) == 0 && len(n.Calls) > 0 {
+				entryPoints = append(entryPoints, name)
+			}
+		}
+		result["edges"] = edges
+		result["entry_points"] = entryPoints
+		if p.Format == "mermaid" {
+			result["diagram"] = callGraphMermaid(order, nodes, "")
+		} else {
+			result["functions"] = list
+		}
+		return result, nil
+	}
+
+	start := resolveCallNode(p.Function, order)
+	if start == "" {
+		return nil, fmt.Errorf("function %s not found in %s", p.Function, p.Path)
+	}
+	result["function"] = start
+	result["line"] = nodes[start].Line
+	if p.Direction == "forward" || p.Direction == "both" {
+		result["callees"] = walkCalls(start, p.Depth, nodes, func(n *callNode) []string { return n.Calls })
+	}
+	if p.Direction == "backward" || p.Direction == "both" {
+		result["callers"] = walkCalls(start, p.Depth, nodes, func(n *callNode) []string { return n.CalledBy })
+	}
+	if p.Format == "mermaid" {
+		result["diagram"] = callGraphMermaid(order, nodes, start)
+	}
+	return result, nil
+}
+
+// resolveCallNode finds a function by its qualified name, or by its bare
+// name when only one class has it
+func resolveCallNode(name string, order []string) string {
+	var matches []string
+	for _, n := range order {
+		if n == name {
+			return n
+		}
+		if strings.HasSuffix(n, "."+name) {
+			matches = append(matches, n)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0]
+	}
+	return ""
+}
+
+// walkCalls follows calls, or callers, breadth first up to depth
+func walkCalls(start string, depth int, nodes map[string]*callNode, next func(*callNode) []string) []callStep {
+	steps := []callStep{}
+	seen := map[string]bool{start: true}
+	frontier := []string{start}
+	for d := 1; d <= depth && len(frontier) > 0; d++ {
+		var following []string
+		for _, from := range frontier {
+			for _, name := range next(nodes[from]) {
+				n, ok := nodes[name]
+				if !ok || seen[name] {
+					continue
+				}
+				seen[name] = true
+				step := callStep{Name: name, Line: n.Line, Depth: d}
+				if d > 1 {
+					step.Via = from
+				}
+				steps = append(steps, step)
+				following = append(following, name)
+			}
+		}
+		frontier = following
+	}
+	return steps
+}
+
+// callGraphMermaid renders the calls as a Mermaid flowchart, with the
+// function asked about highlighted
+func callGraphMermaid(order []string, nodes map[string]*callNode, focus string) string {
+	id := func(name string) string {
+		return strings.NewReplacer(".", "_", "$", "_", "-", "_").Replace(name)
+	}
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, name := range order {
+		n := nodes[name]
+		if len(n.Calls) == 0 && len(n.CalledBy) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", id(name), name)
+	}
+	for _, name := range order {
+		for _, callee := range nodes[name].Calls {
+			if _, ok := nodes[callee]; ok {
+				fmt.Fprintf(&sb, "  %s --> %s\n", id(name), id(callee))
+			}
+		}
+	}
+	if focus != "" {
+		fmt.Fprintf(&sb, "  style %s stroke-width:3px\n", id(focus))
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCallGraph(t *testing.T) {
+	s := setupTestServer(t)
+	path := writeProjectFile(t, s, "billing/invoice.go", `package billing
+
+type Biller struct{}
+
+func (b *Biller) Charge(id string) error {
+	inv := load(id)
+	return b.send(total(inv))
+}
+
+func (b *Biller) send(amount int) error { return nil }
+
+func load(id string) []int { return parse(id) }
+
+func parse(id string) []int { return nil }
+
+func total(items []int) int { return len(items) }
+`)
+
+	graph := resultMap(t, mustCall(t, s, "call_graph", map[string]interface{}{"path": path}))
+	if graph["path"] != "billing/invoice.go" || graph["edges"] != 4 {
+		t.Errorf("graph = %+v", graph)
+	}
+	if got := graph["entry_points"].([]string); !reflect.DeepEqual(got, []string{"Biller.Charge"}) {
+		t.Errorf("entry_points = %v", got)
+	}
+
+	from := resultMap(t, mustCall(t, s, "call_graph", map[string]interface{}{"path": path, "function": "Charge", "direction": "both"}))
+	var callees []string
+	for _, step := range from["callees"].([]callStep) {
+		callees = append(callees, step.Name)
+	}
+	if !reflect.DeepEqual(callees, []string{"load", "Biller.send", "total", "parse"}) {
+		t.Errorf("callees of Charge = %v", callees)
+	}
+	if len(from["callers"].([]callStep)) != 0 {
+		t.Errorf("callers of Charge = %v", from["callers"])
+	}
+
+	up := resultMap(t, mustCall(t, s, "call_graph", map[string]interface{}{"path": path, "function": "parse", "direction": "backward", "format": "mermaid"}))
+	callers := up["callers"].([]callStep)
+	if len(callers) != 2 || callers[1].Name != "Biller.Charge" || callers[1].Via != "load" {
+		t.Errorf("callers of parse = %+v", callers)
+	}
+	if diagram := up["diagram"].(string); !strings.Contains(diagram, "Biller_Charge --> load") || !strings.Contains(diagram, "style parse") {
+		t.Errorf("diagram = %s", diagram)
+	}
+
+	if _, err := callTool(t, s, "call_graph", map[string]interface{}{"path": path, "function": "missing"}); err == nil {
+		t.Error("unknown function should be an error")
+	}
+}
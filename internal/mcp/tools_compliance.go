@@ -10,6 +10,9 @@ import (
 "time"
 
 "github.com/saeedalam/teamcontext/internal/git"
+"github.com/saeedalam/teamcontext/internal/storage"
+"github.com/saeedalam/teamcontext/internal/worker"
+"github.com/saeedalam/teamcontext/pkg/types"
 )
 
 // =============================================================================
@@ -60,13 +63,7 @@ func (s *Server) handleCheckCompliance(params json.RawMessage) (interface{}, err
 	warnings, _ := s.jsonStore.GetWarnings()
 	patterns, _ := s.jsonStore.GetPatterns()
 
-	type violation struct {
-		Type      string `json:"type"`      // "decision", "warning", "pattern"
-		ID        string `json:"id"`        // ID of the violated item
-		Severity  string `json:"severity"`  // "blocker", "warning", "info"
-		Message   string `json:"message"`   // What was violated
-		Reference string `json:"reference"` // The original decision/pattern text
-	}
+	type violation = types.ComplianceViolation
 
 	var violations []violation
 
@@ -173,6 +170,26 @@ func (s *Server) handleCheckCompliance(params json.RawMessage) (interface{}, err
 		}
 	}
 
+	// Sensitive paths are stricter: every finding there is a blocker
+	var touched []string
+	if p.FilePath != "" {
+		rel := p.FilePath
+		if filepath.IsAbs(rel) {
+			if r, err := filepath.Rel(filepath.Dir(s.basePath), rel); err == nil {
+				rel = r
+			}
+		}
+		touched = append(touched, rel)
+	} else if p.Diff != "" {
+		touched = diffFiles(p.Diff)
+	}
+	sensitive := s.jsonStore.SensitivePathsFor(touched...)
+	if len(sensitive) > 0 {
+		for i := range unique {
+			unique[i].Severity = "blocker"
+		}
+	}
+
 	compliant := len(unique) == 0
 	blockers := 0
 	for _, v := range unique {
@@ -181,13 +198,41 @@ func (s *Server) handleCheckCompliance(params json.RawMessage) (interface{}, err
 		}
 	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"source":     sourceName,
 		"compliant":  compliant,
 		"violations": unique,
 		"blockers":   blockers,
 		"checked":    fmt.Sprintf("%d decisions, %d warnings, %d patterns", len(decisions), len(warnings), len(patterns)),
-	}, nil
+	}
+	if len(sensitive) > 0 {
+		var mandatory, reviewers, checklist []string
+		for _, policy := range sensitive {
+			mandatory = append(mandatory, policy.Warnings...)
+			checklist = append(checklist, policy.Checklist...)
+			for _, r := range policy.Reviewers {
+				reviewers = appendUnique(reviewers, r)
+			}
+		}
+		result["sensitive_paths"] = sensitive
+		result["mandatory_warnings"] = mandatory
+		result["required_reviewers"] = reviewers
+		result["required_checklist"] = checklist
+	}
+	return result, nil
+}
+
+// diffFiles returns the files a unified diff touches
+func diffFiles(diff string) []string {
+	var files []string
+	for _, line := range strings.Split(diff, "\n") {
+		for _, prefix := range []string{"+++ b/", "--- a/"} {
+			if rest, ok := strings.CutPrefix(line, prefix); ok {
+				files = appendUnique(files, strings.TrimSpace(rest))
+			}
+		}
+	}
+	return files
 }
 
 // extractProhibitedTerms extracts terms that follow "avoid", "ban", "don't use", "never use", etc.
@@ -596,7 +641,7 @@ func (s *Server) handleGetFeed(params json.RawMessage) (interface{}, error) {
 			}
 			items = append(items, feedItem{
 				Type: "conversation", ID: c.ID, Title: c.Summary,
-				Feature: c.Feature, CreatedAt: c.CreatedAt,
+				Author: c.Author, Feature: c.Feature, CreatedAt: c.CreatedAt,
 			})
 		}
 	}
@@ -617,6 +662,20 @@ func (s *Server) handleGetFeed(params json.RawMessage) (interface{}, error) {
 		}
 	}
 
+	// Config changes applied while the server ran, from the worker log
+	if p.Type == "" || p.Type == "config" {
+		entries, _ := worker.ReadLog(s.basePath, worker.LogQuery{Contains: worker.ConfigReloaded, Since: sinceTime, Limit: p.Limit})
+		for _, e := range entries {
+			if !strings.HasPrefix(e.Message, worker.ConfigReloaded) {
+				continue
+			}
+			items = append(items, feedItem{
+				Type: "config", ID: "config-" + e.Time.UTC().Format("20060102T150405"), Title: e.Message,
+				Detail: "Applied without restarting the server", CreatedAt: e.Time,
+			})
+		}
+	}
+
 	// Sort by most recent
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].CreatedAt.After(items[j].CreatedAt)
@@ -627,10 +686,35 @@ func (s *Server) handleGetFeed(params json.RawMessage) (interface{}, error) {
 		items = items[:p.Limit]
 	}
 
-	return map[string]interface{}{
+	// Remind the team of insights nobody has reviewed for a while
+	if p.Type == "" || p.Type == "insight" {
+		if stale := s.staleInsightCount(); stale > 0 {
+			reminder := feedItem{
+				Type:      "reminder",
+				ID:        "insight-review",
+				Title:     fmt.Sprintf("%d unreviewed insight(s) older than %d days", stale, int(insightReviewAge.Hours()/24)),
+				Detail:    "Use list_unreviewed_insights, then promote_insight_to_decision, promote_insight_to_warning or dismiss_insight",
+				CreatedAt: time.Now(),
+			}
+			items = append([]feedItem{reminder}, items...)
+		}
+	}
+
+	result := map[string]interface{}{
 		"entries": items,
 		"total":   len(items),
-	}, nil
+	}
+
+	// Who the knowledge in this window came from, so a team can see when
+	// it is mostly agents writing it down
+	if p.Type == "" {
+		if contributors := s.jsonStore.Contributors(sinceTime); len(contributors) > 0 {
+			result["contributors"] = contributors
+			result["agent_share"] = storage.AgentShare(contributors)
+		}
+	}
+
+	return result, nil
 }
 
 func parseFeedSince(s string) time.Time {
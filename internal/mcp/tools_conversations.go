@@ -0,0 +1,498 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/internal/tokenest"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// CONVERSATION MAINTENANCE
+// Merge overlapping conversations and split sprawling ones by topic
+// =============================================================================
+
+// duplicatePointSimilarity is the term overlap above which two key points
+// say the same thing
+const duplicatePointSimilarity = 0.8
+
+// topicSimilarity is the term overlap a key point needs with a topic to
+// join it when splitting
+const topicSimilarity = 0.15
+
+// handleMergeConversations consolidates several conversations of a feature
+// into one, deduplicating key points and merging files and decisions.
+func (s *Server) handleMergeConversations(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Feature       string   `json:"feature"`
+		IDs           []string `json:"ids"`
+		All           bool     `json:"all"`
+		Summary       string   `json:"summary"`
+		KeepOriginals bool     `json:"keep_originals"`
+		DryRun        bool     `json:"dry_run"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Feature == "" {
+		return nil, fmt.Errorf("feature is required")
+	}
+	if len(p.IDs) == 0 && !p.All {
+		return nil, fmt.Errorf("ids (at least 2) or all=true is required")
+	}
+
+	conversations, err := s.jsonStore.GetConversations(p.Feature)
+	if err != nil {
+		return nil, err
+	}
+	selected := conversations
+	if !p.All {
+		byID := make(map[string]types.Conversation)
+		for _, c := range conversations {
+			byID[c.ID] = c
+		}
+		selected = nil
+		for _, id := range uniqueStrings(p.IDs) {
+			c, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("conversation not found in feature %s: %s", p.Feature, id)
+			}
+			selected = append(selected, c)
+		}
+	}
+	if len(selected) < 2 {
+		return nil, fmt.Errorf("need at least 2 conversations to merge, found %d", len(selected))
+	}
+
+	merged, pointsBefore := mergeConversations(selected, p.Summary)
+	if merged.Author == "" {
+		merged.Author = s.currentAuthor()
+	}
+	var removed []string
+	if !p.KeepOriginals {
+		removed = merged.MergedFrom
+	}
+	if p.DryRun {
+		changes := []plannedChange{{
+			Action: "create", Kind: "conversation",
+			Detail: fmt.Sprintf("merged from %d conversations, %d of %d key points kept", len(selected), len(merged.KeyPoints), pointsBefore),
+			After:  merged,
+		}}
+		for _, id := range removed {
+			changes = append(changes, plannedChange{Action: "delete", Kind: "conversation", ID: id})
+		}
+		return dryRunResult(changes), nil
+	}
+	if err := s.jsonStore.ReplaceConversations(p.Feature, []*types.Conversation{merged}, removed); err != nil {
+		return nil, err
+	}
+	for _, id := range removed {
+		s.sqliteIndex.DeleteSemanticVector(id)
+	}
+	s.storeSemanticVector(merged.ID, "conversation", conversationVectorText(merged))
+
+	tokensBefore := 0
+	for _, c := range selected {
+		tokensBefore += tokenest.CountJSON(c)
+	}
+	return map[string]interface{}{
+		"id":                 merged.ID,
+		"merged_from":        merged.MergedFrom,
+		"originals_removed":  len(removed) > 0,
+		"key_points_before":  pointsBefore,
+		"key_points_after":   len(merged.KeyPoints),
+		"duplicates_removed": pointsBefore - len(merged.KeyPoints),
+		"tokens_before":      tokensBefore,
+		"tokens_after":       tokenest.CountJSON(merged),
+	}, nil
+}
+
+// mergeConversations builds the consolidated conversation, oldest first.
+// It returns the number of key points before deduplication.
+func mergeConversations(convs []types.Conversation, summary string) (*types.Conversation, int) {
+	sort.SliceStable(convs, func(i, j int) bool {
+		return conversationStart(convs[i]).Before(conversationStart(convs[j]))
+	})
+
+	merged := &types.Conversation{Feature: convs[0].Feature, Topic: convs[0].Topic, Author: convs[0].Author}
+	var points, summaries []string
+	for _, c := range convs {
+		merged.MergedFrom = append(merged.MergedFrom, c.ID)
+		summaries = append(summaries, c.Summary)
+		points = append(points, c.KeyPoints...)
+		merged.FilesDiscussed = append(merged.FilesDiscussed, c.FilesDiscussed...)
+		merged.DecisionsMade = append(merged.DecisionsMade, c.DecisionsMade...)
+		merged.OriginalTokens += c.OriginalTokens
+		if start := conversationStart(c); merged.StartTime.IsZero() || start.Before(merged.StartTime) {
+			merged.StartTime = start
+		}
+		if c.EndTime.After(merged.EndTime) {
+			merged.EndTime = c.EndTime
+		}
+		if c.Topic != merged.Topic {
+			merged.Topic = ""
+		}
+		if c.Author != merged.Author {
+			merged.Author = ""
+		}
+	}
+
+	merged.KeyPoints = dedupKeyPoints(points)
+	merged.FilesDiscussed = uniqueStrings(merged.FilesDiscussed)
+	merged.DecisionsMade = uniqueStrings(merged.DecisionsMade)
+	if summary == "" {
+		summary = strings.Join(dedupKeyPoints(summaries), " ")
+	}
+	merged.Summary = summary
+	merged.CompressedTokens = tokenest.Count(merged.Summary + " " + strings.Join(merged.KeyPoints, " "))
+	return merged, len(points)
+}
+
+// dedupKeyPoints drops points that repeat an earlier one, keeping the more
+// detailed wording of each pair
+func dedupKeyPoints(points []string) []string {
+	var kept []string
+	var keptTerms []map[string]bool
+	for _, point := range points {
+		point = strings.TrimSpace(point)
+		if point == "" {
+			continue
+		}
+		terms := search.Terms(point)
+		dup := -1
+		for i, t := range keptTerms {
+			if strings.EqualFold(kept[i], point) || search.TermSimilarity(terms, t) >= duplicatePointSimilarity {
+				dup = i
+				break
+			}
+		}
+		if dup < 0 {
+			kept = append(kept, point)
+			keptTerms = append(keptTerms, terms)
+		} else if len(point) > len(kept[dup]) {
+			kept[dup] = point
+			keptTerms[dup] = terms
+		}
+	}
+	return kept
+}
+
+// handleSplitConversation breaks a large conversation into one conversation
+// per topic, either the given topics or clusters of related key points.
+func (s *Server) handleSplitConversation(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Feature string `json:"feature"`
+		ID      string `json:"id"`
+		Topics  []struct {
+			Name     string   `json:"name"`
+			Keywords []string `json:"keywords"`
+		} `json:"topics"`
+		MaxParts     int  `json:"max_parts"`
+		KeepOriginal bool `json:"keep_original"`
+		DryRun       bool `json:"dry_run"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Feature == "" || p.ID == "" {
+		return nil, fmt.Errorf("feature and id are required")
+	}
+	if p.MaxParts <= 0 {
+		p.MaxParts = 4
+	}
+
+	conversations, err := s.jsonStore.GetConversations(p.Feature)
+	if err != nil {
+		return nil, err
+	}
+	var original *types.Conversation
+	for i := range conversations {
+		if conversations[i].ID == p.ID {
+			original = &conversations[i]
+		}
+	}
+	if original == nil {
+		return nil, fmt.Errorf("conversation not found in feature %s: %s", p.Feature, p.ID)
+	}
+	if len(original.KeyPoints) < 2 {
+		return nil, fmt.Errorf("conversation %s has %d key points; nothing to split", p.ID, len(original.KeyPoints))
+	}
+
+	var groups []topicGroup
+	if len(p.Topics) > 0 {
+		named := make([]topicGroup, len(p.Topics))
+		for i, t := range p.Topics {
+			named[i].name = t.Name
+			named[i].terms = search.Terms(t.Name + " " + strings.Join(t.Keywords, " "))
+		}
+		groups = assignToTopics(original.KeyPoints, named)
+	} else {
+		groups = clusterKeyPoints(original.KeyPoints, p.MaxParts)
+	}
+	if len(groups) < 2 {
+		return nil, fmt.Errorf("key points of %s form a single topic; nothing to split", p.ID)
+	}
+
+	parts := splitConversation(original, groups)
+	var removed []string
+	if !p.KeepOriginal {
+		removed = []string{original.ID}
+	}
+	if p.DryRun {
+		var changes []plannedChange
+		for _, part := range parts {
+			changes = append(changes, plannedChange{
+				Action: "create", Kind: "conversation",
+				Detail: fmt.Sprintf("topic %q, %d key points", part.Topic, len(part.KeyPoints)),
+				After:  part,
+			})
+		}
+		for _, id := range removed {
+			changes = append(changes, plannedChange{Action: "delete", Kind: "conversation", ID: id})
+		}
+		return dryRunResult(changes), nil
+	}
+	if err := s.jsonStore.ReplaceConversations(p.Feature, parts, removed); err != nil {
+		return nil, err
+	}
+	for _, id := range removed {
+		s.sqliteIndex.DeleteSemanticVector(id)
+	}
+
+	var summaries []map[string]interface{}
+	for _, part := range parts {
+		s.storeSemanticVector(part.ID, "conversation", conversationVectorText(part))
+		summaries = append(summaries, map[string]interface{}{
+			"id":         part.ID,
+			"topic":      part.Topic,
+			"key_points": len(part.KeyPoints),
+			"files":      len(part.FilesDiscussed),
+		})
+	}
+	return map[string]interface{}{
+		"split_from":        original.ID,
+		"original_removed":  !p.KeepOriginal,
+		"parts":             summaries,
+		"tokens_before":     tokenest.CountJSON(original),
+		"largest_part_size": largestPartTokens(parts),
+	}, nil
+}
+
+// topicGroup is a topic and the key points assigned to it
+type topicGroup struct {
+	name   string
+	terms  map[string]bool
+	points []string
+}
+
+// assignToTopics puts each key point under the named topic it shares most
+// terms with; points matching none go to "Other"
+func assignToTopics(points []string, topics []topicGroup) []topicGroup {
+	other := topicGroup{name: "Other"}
+	for _, point := range points {
+		terms := search.Terms(point)
+		best, bestScore := -1, 0
+		for i, t := range topics {
+			score := 0
+			for term := range terms {
+				if t.terms[term] {
+					score++
+				}
+			}
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			other.points = append(other.points, point)
+		} else {
+			topics[best].points = append(topics[best].points, point)
+		}
+	}
+	var groups []topicGroup
+	for _, t := range append(topics, other) {
+		if len(t.points) > 0 {
+			groups = append(groups, t)
+		}
+	}
+	return groups
+}
+
+// clusterKeyPoints groups key points greedily by term overlap, then folds
+// the smallest clusters into their closest neighbour until at most maxParts
+// remain. Clusters are named after their most frequent words.
+func clusterKeyPoints(points []string, maxParts int) []topicGroup {
+	var groups []topicGroup
+	for _, point := range points {
+		terms := search.Terms(point)
+		best, bestScore := -1, topicSimilarity
+		for i := range groups {
+			if score := search.TermSimilarity(terms, groups[i].terms); score >= bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			groups = append(groups, topicGroup{terms: terms, points: []string{point}})
+			continue
+		}
+		groups[best].points = append(groups[best].points, point)
+		for t := range terms {
+			groups[best].terms[t] = true
+		}
+	}
+
+	for len(groups) > maxParts {
+		smallest := 0
+		for i := range groups {
+			if len(groups[i].points) < len(groups[smallest].points) {
+				smallest = i
+			}
+		}
+		closest, closestScore := -1, -1.0
+		for i := range groups {
+			if i == smallest {
+				continue
+			}
+			if score := search.TermSimilarity(groups[smallest].terms, groups[i].terms); score > closestScore {
+				closest, closestScore = i, score
+			}
+		}
+		groups[closest].points = append(groups[closest].points, groups[smallest].points...)
+		for t := range groups[smallest].terms {
+			groups[closest].terms[t] = true
+		}
+		groups = append(groups[:smallest], groups[smallest+1:]...)
+	}
+
+	for i := range groups {
+		groups[i].name = topicLabel(groups[i].points)
+	}
+	return groups
+}
+
+// topicLabel names a group after its two most frequent significant words
+func topicLabel(points []string) string {
+	counts := make(map[string]int)
+	for _, point := range points {
+		seen := make(map[string]bool)
+		for _, w := range strings.FieldsFunc(strings.ToLower(point), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+		}) {
+			if len(w) >= 4 && !labelStopwords[w] && !seen[w] {
+				seen[w] = true
+				counts[w]++
+			}
+		}
+	}
+	words := make([]string, 0, len(counts))
+	for w := range counts {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	if len(words) > 2 {
+		words = words[:2]
+	}
+	if len(words) == 0 {
+		return "General"
+	}
+	return strings.Join(words, " ")
+}
+
+// labelStopwords are frequent words that make poor topic names
+var labelStopwords = map[string]bool{
+	"that": true, "this": true, "with": true, "from": true, "into": true, "when": true,
+	"will": true, "should": true, "need": true, "needs": true, "have": true, "been": true,
+	"were": true, "added": true, "also": true, "than": true, "then": true, "them": true,
+	"they": true, "used": true, "uses": true, "using": true, "because": true,
+}
+
+// splitConversation creates one conversation per group. Files and decisions
+// go to the part whose key points mention them, or to the largest part.
+func splitConversation(original *types.Conversation, groups []topicGroup) []*types.Conversation {
+	largest := 0
+	for i := range groups {
+		if len(groups[i].points) > len(groups[largest].points) {
+			largest = i
+		}
+	}
+	owner := func(needle string) int {
+		needle = strings.ToLower(needle)
+		for i, g := range groups {
+			for _, point := range g.points {
+				if strings.Contains(strings.ToLower(point), needle) {
+					return i
+				}
+			}
+		}
+		return largest
+	}
+
+	parts := make([]*types.Conversation, len(groups))
+	for i, g := range groups {
+		summary := g.name + ": " + g.points[0]
+		if len(g.points) > 1 {
+			summary += "; " + g.points[1]
+		}
+		parts[i] = &types.Conversation{
+			Feature:        original.Feature,
+			Topic:          g.name,
+			Summary:        summary,
+			Author:         original.Author,
+			KeyPoints:      g.points,
+			SplitFrom:      original.ID,
+			StartTime:      original.StartTime,
+			EndTime:        original.EndTime,
+			OriginalTokens: original.OriginalTokens * len(g.points) / len(original.KeyPoints),
+		}
+	}
+	for _, f := range original.FilesDiscussed {
+		name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		i := owner(name)
+		parts[i].FilesDiscussed = append(parts[i].FilesDiscussed, f)
+	}
+	for _, d := range original.DecisionsMade {
+		i := owner(d)
+		parts[i].DecisionsMade = append(parts[i].DecisionsMade, d)
+	}
+	for _, part := range parts {
+		part.CompressedTokens = tokenest.Count(part.Summary + " " + strings.Join(part.KeyPoints, " "))
+	}
+	return parts
+}
+
+func largestPartTokens(parts []*types.Conversation) int {
+	largest := 0
+	for _, part := range parts {
+		if n := tokenest.CountJSON(part); n > largest {
+			largest = n
+		}
+	}
+	return largest
+}
+
+// conversationVectorText is the text indexed for semantic search, as in
+// save_conversation
+func conversationVectorText(c *types.Conversation) string {
+	return c.Summary + " " + strings.Join(c.KeyPoints, " ") + " " + strings.Join(c.FilesDiscussed, " ")
+}
+
+// conversationStart orders conversations, falling back to when they were
+// saved for ones recorded without a start time
+func conversationStart(c types.Conversation) time.Time {
+	if c.StartTime.IsZero() {
+		return c.CreatedAt
+	}
+	return c.StartTime
+}
@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/manifest"
+)
+
+// =============================================================================
+// DEPENDENCY MANIFEST
+// Declared and locked third-party packages with their licenses, the team
+// knowledge about each, and imports no manifest declares
+// =============================================================================
+
+// genericLibraryTerms are name segments too common to link knowledge by:
+// "@babel/core" should not pick up every decision mentioning "core"
+var genericLibraryTerms = map[string]bool{
+	"core": true, "common": true, "utils": true, "client": true, "server": true,
+	"types": true, "http": true, "test": true, "tests": true, "runtime": true,
+	"config": true, "plugin": true,
+}
+
+// dependencyEntry is a dependency with the decisions and warnings naming it
+type dependencyEntry struct {
+	manifest.Dependency
+	Decisions []string `json:"decisions,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// handleGetDependenciesManifest reads the project's manifests and lockfiles
+// into a dependency inventory, or a CycloneDX SBOM with format "cyclonedx"
+func (s *Server) handleGetDependenciesManifest(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Ecosystem  string `json:"ecosystem"`
+		Name       string `json:"name"`
+		Path       string `json:"path"`
+		Transitive bool   `json:"transitive"`
+		Format     string `json:"format"`
+		Limit      int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Limit <= 0 {
+		p.Limit = 200
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	relPath := filepath.ToSlash(p.Path)
+	if filepath.IsAbs(p.Path) {
+		if rel, err := filepath.Rel(projectRoot, p.Path); err == nil {
+			relPath = filepath.ToSlash(rel)
+		}
+	}
+	relPath = strings.Trim(strings.TrimPrefix(relPath, "./"), "/")
+	inScope := func(path string) bool {
+		return relPath == "" || relPath == "." || path == relPath || strings.HasPrefix(path, relPath+"/")
+	}
+
+	inv := manifest.Scan(projectRoot)
+	ecosystem := strings.ToLower(p.Ecosystem)
+	if ecosystem == "go" {
+		ecosystem = manifest.Go
+	}
+
+	var deps []manifest.Dependency
+	for _, d := range inv.Dependencies {
+		if !d.Direct && !p.Transitive {
+			continue
+		}
+		if ecosystem != "" && d.Ecosystem != ecosystem {
+			continue
+		}
+		if p.Name != "" && !strings.Contains(strings.ToLower(d.Name), strings.ToLower(p.Name)) {
+			continue
+		}
+		// A path narrows to manifests inside it, or above it that it builds with
+		if relPath != "" && relPath != "." && !inScope(d.Manifest) && !strings.HasPrefix(relPath+"/", dirPrefix(d.Manifest)) {
+			continue
+		}
+		deps = append(deps, d)
+	}
+
+	if p.Format == "cyclonedx" {
+		return manifest.SBOM(deps), nil
+	}
+
+	decisions, _ := s.jsonStore.GetDecisions()
+	warnings, _ := s.jsonStore.GetWarnings()
+	byEcosystem := make(map[string]int)
+	byLicense := make(map[string]int)
+	entries := make([]dependencyEntry, 0, len(deps))
+	for _, d := range deps {
+		byEcosystem[d.Ecosystem]++
+		license := d.License
+		if license == "" {
+			license = "unknown"
+		}
+		byLicense[license]++
+		if len(entries) >= p.Limit {
+			continue
+		}
+		e := dependencyEntry{Dependency: d}
+		if re := libraryMention(d); re != nil {
+			for _, dec := range decisions {
+				if dec.Status != "superseded" && dec.Status != "archived" && mentionsLibrary(re, dec.Content, dec.Reason, dec.Context, dec.Tags) {
+					e.Decisions = append(e.Decisions, dec.ID)
+				}
+			}
+			for _, w := range warnings {
+				if mentionsLibrary(re, w.Content, w.Reason, w.Evidence, w.Tags) {
+					e.Warnings = append(e.Warnings, w.ID)
+				}
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	var manifests []manifest.Manifest
+	for _, m := range inv.Manifests {
+		if relPath == "" || relPath == "." || inScope(m.Path) || strings.HasPrefix(relPath+"/", dirPrefix(m.Path)) {
+			manifests = append(manifests, m)
+		}
+	}
+
+	var sourceFiles []string
+	if files, err := s.jsonStore.GetFilesIndex(); err == nil {
+		for path, fi := range files {
+			if fi.DeletedAt == nil && inScope(path) {
+				sourceFiles = append(sourceFiles, path)
+			}
+		}
+	}
+	sort.Strings(sourceFiles)
+	undeclared := inv.Undeclared(projectRoot, sourceFiles)
+	if ecosystem != "" {
+		kept := undeclared[:0]
+		for _, u := range undeclared {
+			if u.Ecosystem == ecosystem {
+				kept = append(kept, u)
+			}
+		}
+		undeclared = kept
+	}
+
+	result := map[string]interface{}{
+		"manifests":    manifests,
+		"dependencies": entries,
+		"count":        len(deps),
+		"by_ecosystem": byEcosystem,
+		"by_license":   byLicense,
+	}
+	if len(deps) > len(entries) {
+		result["truncated"] = true
+	}
+	if len(undeclared) > 0 {
+		result["undeclared_imports"] = undeclared
+	}
+	return result, nil
+}
+
+// libraryMention matches a dependency's name as a whole word, and the last
+// segment of a scoped or path name when it is specific enough on its own:
+// "stripe" for github.com/stripe/stripe-go, "guava" for com.google.guava:guava
+func libraryMention(d manifest.Dependency) *regexp.Regexp {
+	var terms []string
+	if len(d.Name) >= 3 {
+		terms = append(terms, regexp.QuoteMeta(d.Name))
+	}
+	segs := strings.FieldsFunc(d.Name, func(r rune) bool { return r == '/' || r == ':' })
+	if len(segs) == 0 {
+		return nil
+	}
+	if last := segs[len(segs)-1]; last != d.Name && len(last) >= 4 && !genericLibraryTerms[strings.ToLower(last)] {
+		terms = append(terms, regexp.QuoteMeta(last))
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)(^|[^\w@/.-])(` + strings.Join(terms, "|") + `)($|[^\w/-])`)
+}
+
+// mentionsLibrary reports whether knowledge text or tags name a library
+func mentionsLibrary(re *regexp.Regexp, content, reason, extra string, tags []string) bool {
+	if re.MatchString(content) || re.MatchString(reason) || re.MatchString(extra) {
+		return true
+	}
+	for _, tag := range tags {
+		if re.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirPrefix is a manifest's directory with a trailing slash, "" at the root
+func dirPrefix(manifestPath string) string {
+	dir := filepath.ToSlash(filepath.Dir(manifestPath))
+	if dir == "." {
+		return ""
+	}
+	return dir + "/"
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/manifest"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestGetDependenciesManifest(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "web/package.json", `{"dependencies": {"moment": "^2.29.0", "@stripe/stripe-js": "^3.0.0"}}`)
+	writeProjectFile(t, s, "web/package-lock.json", `{"packages": {
+  "node_modules/moment": {"version": "2.29.4", "license": "MIT"},
+  "node_modules/@stripe/stripe-js": {"version": "3.1.0", "license": "MIT"},
+  "node_modules/dayjs": {"version": "1.11.10", "license": "MIT"}}}`)
+	writeProjectFile(t, s, "web/src/date.ts", "import moment from \"moment\";\nimport dayjs from \"dayjs\";\n")
+	writeProjectFile(t, s, "go.mod", "module example.com/shop\n\nrequire github.com/lib/pq v1.10.9\n")
+	if err := s.jsonStore.SaveFilesIndexBulk(map[string]types.FileIndex{
+		"web/src/date.ts": {Path: "web/src/date.ts", Language: "typescript"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	dec := &types.Decision{Content: "Replace moment with dayjs in new code", Reason: "Moment is in maintenance mode", Status: "active"}
+	if err := s.jsonStore.AddDecision(dec); err != nil {
+		t.Fatal(err)
+	}
+	warn := &types.Warning{Content: "stripe-js must be loaded from js.stripe.com", Severity: "warning"}
+	if err := s.jsonStore.AddWarning(warn); err != nil {
+		t.Fatal(err)
+	}
+
+	result := resultMap(t, mustCall(t, s, "get_dependencies_manifest", map[string]interface{}{"ecosystem": "npm"}))
+	deps := result["dependencies"].([]dependencyEntry)
+	if len(deps) != 2 {
+		t.Fatalf("dependencies = %+v", deps)
+	}
+	byName := make(map[string]dependencyEntry)
+	for _, d := range deps {
+		byName[d.Name] = d
+	}
+	if m := byName["moment"]; m.Version != "2.29.4" || m.License != "MIT" || !reflect.DeepEqual(m.Decisions, []string{dec.ID}) {
+		t.Errorf("moment = %+v", m)
+	}
+	if st := byName["@stripe/stripe-js"]; !reflect.DeepEqual(st.Warnings, []string{warn.ID}) || len(st.Decisions) != 0 {
+		t.Errorf("@stripe/stripe-js = %+v", st)
+	}
+
+	want := []manifest.UndeclaredImport{{File: "web/src/date.ts", Package: "dayjs", Ecosystem: manifest.NPM, Raw: `import dayjs from "dayjs";`}}
+	if got, _ := result["undeclared_imports"].([]manifest.UndeclaredImport); !reflect.DeepEqual(got, want) {
+		t.Errorf("undeclared_imports = %+v, want %+v", got, want)
+	}
+
+	result = resultMap(t, mustCall(t, s, "get_dependencies_manifest", map[string]interface{}{"transitive": true, "name": "dayjs"}))
+	if deps := result["dependencies"].([]dependencyEntry); len(deps) != 1 || deps[0].Direct || deps[0].Manifest != "web/package-lock.json" {
+		t.Errorf("transitive dayjs = %+v", deps)
+	}
+
+	bom, ok := mustCall(t, s, "get_dependencies_manifest", map[string]interface{}{"format": "cyclonedx", "ecosystem": "go"}).(*manifest.CycloneDX)
+	if !ok || len(bom.Components) != 1 || bom.Components[0].PURL != "pkg:golang/github.com/lib/pq@v1.10.9" {
+		t.Errorf("sbom = %+v", bom)
+	}
+}
@@ -0,0 +1,345 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/stacktrace"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// ERROR DIAGNOSIS
+// Turn a raw stack trace into leads: files, warnings, recent changes, experts
+// =============================================================================
+
+// recentChangeWindow is how recent a commit touching a failing line must be
+// to be called out as a likely cause
+const recentChangeWindow = 14 * 24 * time.Hour
+
+// errorFrame is a trace frame resolved to an indexed file
+type errorFrame struct {
+	File     string            `json:"file"`
+	Line     int               `json:"line,omitempty"`
+	Function string            `json:"function,omitempty"`
+	Commits  []git.CommitInfo  `json:"recent_commits,omitempty"`
+	Experts  []git.ExpertEntry `json:"experts,omitempty"`
+}
+
+// handleExplainError maps a stack trace or error message to indexed files
+// and gathers the warnings, decisions, recent commits and experts around
+// them as a starting point for diagnosis.
+func (s *Server) handleExplainError(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Error     string `json:"error"`
+		MaxFrames int    `json:"max_frames"`
+	}
+	json.Unmarshal(params, &p)
+
+	if strings.TrimSpace(p.Error) == "" {
+		return nil, fmt.Errorf("error is required")
+	}
+	if p.MaxFrames <= 0 {
+		p.MaxFrames = 5
+	}
+
+	trace := stacktrace.Parse(p.Error)
+	files, _ := s.jsonStore.GetFilesIndex()
+	projectRoot := filepath.Dir(s.basePath)
+	resolver := newFrameResolver(files, projectRoot)
+
+	// Project frames, innermost first; library and runtime frames are counted
+	var frames []*errorFrame
+	seen := make(map[string]bool)
+	external := 0
+	raisedExternally := false
+	for i, f := range trace.Frames {
+		path, ok := resolver.resolve(f)
+		if !ok {
+			external++
+			raisedExternally = raisedExternally || i == 0
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", path, f.Line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if len(frames) < p.MaxFrames {
+			frames = append(frames, &errorFrame{File: path, Line: f.Line, Function: f.Function})
+		}
+	}
+
+	var frameFiles []string
+	for _, f := range frames {
+		frameFiles = append(frameFiles, f.File)
+	}
+	frameFiles = uniqueStrings(frameFiles)
+
+	// Commits that touched the failing lines, and who knows the code
+	analyzer := git.NewHistoryAnalyzer(projectRoot)
+	var cachedExperts []git.DirectoryExpert
+	s.loadGitKnowledge("git-experts.json", &cachedExperts)
+	for i, f := range frames {
+		if i < 3 && f.Line > 0 {
+			start := f.Line - 2
+			if start < 1 {
+				start = 1
+			}
+			if ctx, err := analyzer.GetCommitContext(f.File, []int{start, f.Line + 2}); err == nil {
+				f.Commits = ctx.Commits
+				if len(f.Commits) > 3 {
+					f.Commits = f.Commits[:3]
+				}
+			}
+		}
+		f.Experts = expertsForFile(cachedExperts, f.File, 2)
+	}
+
+	// Knowledge tied to the files, or describing the same failure
+	terms := errorTerms(trace)
+	var relatedWarnings []types.Warning
+	warnings, _ := s.jsonStore.GetWarnings()
+	for _, w := range warnings {
+		if touchesFiles(w.RelatedFiles, frameFiles) || matchesTerms(knowledgeText(w.Translations, w.Content, w.Reason, w.Evidence), terms) {
+			relatedWarnings = append(relatedWarnings, w)
+		}
+	}
+	sort.SliceStable(relatedWarnings, func(i, j int) bool {
+		return severityRank(relatedWarnings[i].Severity) > severityRank(relatedWarnings[j].Severity)
+	})
+	var relatedDecisions []types.Decision
+	decisions, _ := s.jsonStore.GetDecisions()
+	for _, d := range decisions {
+		if d.Status != "superseded" && touchesFiles(d.RelatedFiles, frameFiles) {
+			relatedDecisions = append(relatedDecisions, d)
+		}
+	}
+
+	result := map[string]interface{}{
+		"error_type":      trace.ErrorType,
+		"message":         trace.Message,
+		"frames":          frames,
+		"external_frames": external,
+		"warnings":        relatedWarnings,
+		"decisions":       relatedDecisions,
+		"diagnosis":       diagnoseError(trace, frames, relatedWarnings, raisedExternally),
+	}
+	if len(trace.Frames) == 0 {
+		result["note"] = "No file:line frames found. Paste the full stack trace for file-level leads."
+	}
+	return result, nil
+}
+
+// diagnoseError turns the gathered context into ordered leads
+func diagnoseError(trace *stacktrace.Trace, frames []*errorFrame, warnings []types.Warning, raisedExternally bool) []string {
+	var leads []string
+
+	if len(frames) > 0 {
+		top := frames[0]
+		lead := fmt.Sprintf("Start at %s:%d", top.File, top.Line)
+		if top.Function != "" {
+			lead += " (" + top.Function + ")"
+		}
+		if raisedExternally {
+			lead += ", the innermost project frame; the error was raised in library or runtime code it called"
+		}
+		leads = append(leads, lead+".")
+	} else if len(trace.Frames) > 0 {
+		leads = append(leads, "None of the frames map to indexed files. The error is raised outside this project, or the index is stale (run index).")
+	}
+
+	for _, w := range warnings {
+		if len(leads) >= 4 {
+			break
+		}
+		leads = append(leads, fmt.Sprintf("Known %s: %s", w.Severity, w.Content))
+	}
+
+	cutoff := time.Now().Add(-recentChangeWindow)
+	for _, f := range frames {
+		for _, c := range f.Commits {
+			if c.Date.After(cutoff) {
+				leads = append(leads, fmt.Sprintf("%s:%d changed recently in %s by %s (%s): %s",
+					f.File, f.Line, c.ShortHash, c.Author, c.Date.Format("2006-01-02"), c.Message))
+				break
+			}
+		}
+	}
+
+	for _, f := range frames {
+		for _, e := range f.Experts {
+			if e.Active {
+				leads = append(leads, fmt.Sprintf("Ask %s, who owns %.0f%% of %s.", e.Name, e.Ownership*100, path.Dir(f.File)))
+				return leads
+			}
+		}
+	}
+	return leads
+}
+
+// frameResolver maps printed trace paths to indexed files
+type frameResolver struct {
+	files       map[string]types.FileIndex
+	byBase      map[string][]string
+	projectRoot string
+}
+
+func newFrameResolver(files map[string]types.FileIndex, projectRoot string) *frameResolver {
+	r := &frameResolver{files: files, byBase: make(map[string][]string), projectRoot: filepath.ToSlash(projectRoot)}
+	for p := range files {
+		base := path.Base(p)
+		r.byBase[base] = append(r.byBase[base], p)
+	}
+	for _, paths := range r.byBase {
+		sort.Strings(paths)
+	}
+	return r
+}
+
+// resolve finds the indexed file for a frame: by path relative to the
+// project root, else by the longest path suffix shared with one file.
+// Container and CI paths (/srv/app/src/x.ts) resolve by suffix.
+func (r *frameResolver) resolve(f stacktrace.Frame) (string, bool) {
+	path := strings.ReplaceAll(f.File, "\\", "/")
+	path = strings.TrimPrefix(path, "webpack:///")
+	path = strings.TrimPrefix(path, "./")
+	if r.projectRoot != "" && strings.HasPrefix(path, r.projectRoot+"/") {
+		path = strings.TrimPrefix(path, r.projectRoot+"/")
+	}
+	if _, ok := r.files[path]; ok {
+		return path, true
+	}
+
+	candidates := r.byBase[filepath.Base(path)]
+	if len(candidates) == 0 {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for k := 0; k < len(parts); k++ {
+		suffix := strings.Join(parts[k:], "/")
+		var matches []string
+		for _, c := range candidates {
+			if c == suffix {
+				// The suffix is a project-relative path
+				return c, true
+			}
+			if strings.HasSuffix(c, "/"+suffix) {
+				matches = append(matches, c)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], true
+		}
+		if len(matches) > 1 {
+			// Ambiguous; a JVM package path or a deeper suffix would have
+			// matched one file
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// expertsForFile returns the top experts of the deepest directory containing file
+func expertsForFile(experts []git.DirectoryExpert, file string, limit int) []git.ExpertEntry {
+	var best *git.DirectoryExpert
+	for i := range experts {
+		dir := experts[i].Directory
+		if dir != "." && dir != "" && path.Dir(file) != dir && !strings.HasPrefix(file, dir+"/") {
+			continue
+		}
+		if best == nil || len(dir) > len(best.Directory) {
+			best = &experts[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	out := best.TopExperts
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// touchesFiles reports whether related paths cover any of files, directly or
+// as a parent directory
+func touchesFiles(related, files []string) bool {
+	for _, r := range related {
+		r = strings.TrimSuffix(filepath.ToSlash(r), "/")
+		for _, f := range files {
+			if r == f || strings.HasPrefix(f, r+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errorTerms returns the distinctive words of the error type and message
+func errorTerms(trace *stacktrace.Trace) []string {
+	var terms []string
+	if trace.ErrorType != "" && trace.ErrorType != "panic" {
+		name := trace.ErrorType
+		if i := strings.LastIndexAny(name, ".$"); i >= 0 {
+			name = name[i+1:]
+		}
+		terms = append(terms, strings.ToLower(name))
+	}
+	words := strings.FieldsFunc(strings.ToLower(trace.Message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, w := range words {
+		if len(w) >= 4 && !commonErrorWords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return uniqueStrings(terms)
+}
+
+// commonErrorWords appear in most error messages and don't identify a failure
+var commonErrorWords = map[string]bool{
+	"error": true, "failed": true, "cannot": true, "could": true, "unable": true,
+	"with": true, "from": true, "that": true, "this": true, "when": true,
+	"invalid": true, "runtime": true, "exception": true, "value": true,
+}
+
+// matchesTerms requires two shared terms, or one when only one is known,
+// so a single common word doesn't pull in unrelated warnings
+func matchesTerms(text string, terms []string) bool {
+	if len(terms) == 0 {
+		return false
+	}
+	text = strings.ToLower(text)
+	needed := 2
+	if len(terms) == 1 {
+		needed = 1
+	}
+	hits := 0
+	for _, t := range terms {
+		if strings.Contains(text, t) {
+			hits++
+			if hits >= needed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 2
+	case "warning":
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,359 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/extractor"
+	"github.com/saeedalam/teamcontext/internal/imports"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// EXTRACTION PLAN
+// What moves, what breaks and what knowledge travels when a directory is
+// split out of the monorepo into its own service or repository
+// =============================================================================
+
+// sharedTypeKinds are the export kinds that make up a contract between the
+// extracted code and the rest of the project
+var sharedTypeKinds = map[string]bool{"class": true, "interface": true, "type": true, "struct": true, "enum": true}
+
+// boundaryLink is a project path on the other side of the extraction
+// boundary and the files on this side that reference it
+type boundaryLink struct {
+	Path  string   `json:"path"`
+	Files []string `json:"files"`
+}
+
+// extractionKnowledge is a decision, warning or insight that applies to the
+// extracted code. Shared items also cover code that stays behind and need
+// to be kept in both places.
+type extractionKnowledge struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	Shared  bool   `json:"shared,omitempty"`
+}
+
+// goModulePath returns the module path declared in the project's go.mod
+func goModulePath(projectRoot string) string {
+	f, err := os.Open(filepath.Join(projectRoot, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// projectImports returns the internal imports of each file: resolved import
+// edges, plus Go packages of this module, which are imported by path and
+// have no edges. A Go package import is the package directory.
+func (s *Server) projectImports(files map[string]types.FileIndex, projectRoot string) map[string][]string {
+	importsOf := make(map[string][]string)
+	if graph, err := s.jsonStore.GetKnowledgeGraph(); err == nil {
+		for _, e := range graph.Edges {
+			if e.Relation == "imports" && e.FromType == "file" && e.ToType == "file" {
+				importsOf[e.FromID] = append(importsOf[e.FromID], e.ToID)
+			}
+		}
+	}
+	if module := goModulePath(projectRoot); module != "" {
+		for path, fi := range files {
+			for _, imp := range fi.Imports {
+				if pkg, ok := strings.CutPrefix(imp, module+"/"); ok {
+					importsOf[path] = append(importsOf[path], pkg)
+				}
+			}
+		}
+	}
+	return importsOf
+}
+
+// handleExtractionPlan maps the boundary of a directory: everything it
+// imports from the rest of the project, everything importing it, the types
+// crossing the boundary, its env vars and the knowledge that travels with it.
+func (s *Server) handleExtractionPlan(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path  string `json:"path"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Limit <= 0 {
+		p.Limit = 30
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	relPath := filepath.ToSlash(p.Path)
+	if filepath.IsAbs(p.Path) {
+		if rel, err := filepath.Rel(projectRoot, p.Path); err == nil {
+			relPath = filepath.ToSlash(rel)
+		}
+	}
+	relPath = strings.Trim(relPath, "/")
+	if relPath == "" || relPath == "." {
+		return nil, fmt.Errorf("path is required: the directory to extract")
+	}
+	absPath := filepath.Join(projectRoot, relPath)
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("directory not found: %s", relPath)
+	}
+
+	inTarget := func(path string) bool {
+		path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+		return path == relPath || strings.HasPrefix(path, relPath+"/")
+	}
+
+	files, err := s.jsonStore.GetFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+	known := func(path string) bool {
+		if fi, ok := files[path]; ok && fi.DeletedAt == nil {
+			return true
+		}
+		_, err := os.Stat(filepath.Join(projectRoot, path))
+		return err == nil
+	}
+
+	importsOf := s.projectImports(files, projectRoot)
+	module := goModulePath(projectRoot)
+
+	var targetFiles []string
+	outbound := make(map[string]map[string]bool) // outside path -> target files importing it
+	inbound := make(map[string]map[string]bool)  // target path -> outside files importing it
+	for path, fi := range files {
+		if fi.DeletedAt != nil {
+			continue
+		}
+		inside := inTarget(path)
+		if inside {
+			targetFiles = append(targetFiles, path)
+		}
+		for _, imp := range importsOf[path] {
+			switch {
+			case inside && !inTarget(imp) && known(imp):
+				if outbound[imp] == nil {
+					outbound[imp] = make(map[string]bool)
+				}
+				outbound[imp][path] = true
+			case !inside && inTarget(imp):
+				if inbound[imp] == nil {
+					inbound[imp] = make(map[string]bool)
+				}
+				inbound[imp][path] = true
+			}
+		}
+	}
+	sort.Strings(targetFiles)
+	if len(targetFiles) == 0 {
+		return nil, fmt.Errorf("no indexed files under %s; run index first", relPath)
+	}
+
+	dependsOn := boundaryLinks(outbound)
+	dependedOnBy := boundaryLinks(inbound)
+
+	// Areas group outside dependencies by workspace or top-level directory
+	areas := make(map[string]int)
+	for _, link := range dependsOn {
+		area := serviceOf(link.Path)
+		if area == "" {
+			area = strings.SplitN(link.Path, "/", 2)[0]
+		}
+		areas[area]++
+	}
+	outsideDependents := make(map[string]bool)
+	for _, link := range dependedOnBy {
+		for _, f := range link.Files {
+			outsideDependents[f] = true
+		}
+	}
+
+	// Third-party packages the extracted code needs in its own manifest
+	packages := make(map[string]bool)
+	for _, path := range targetFiles {
+		results, err := imports.ScanFile(filepath.Join(projectRoot, path))
+		if err != nil {
+			continue
+		}
+		for _, imp := range results {
+			if imp.ImportType == "package" {
+				packages[imp.Imported] = true
+			}
+		}
+	}
+	if module != "" {
+		for pkg := range packages {
+			if pkg == module || strings.HasPrefix(pkg, module+"/") {
+				delete(packages, pkg)
+			}
+		}
+	}
+
+	// Types crossing the boundary: outside types the code uses must move to
+	// a shared library or be copied; inside types used elsewhere become the
+	// extracted service's contract
+	var sharedTypes []map[string]string
+	addTypes := func(links []boundaryLink, direction string) {
+		for _, link := range links {
+			for _, path := range filesUnder(files, link.Path) {
+				for _, exp := range files[path].Exports {
+					if sharedTypeKinds[exp.Kind] {
+						sharedTypes = append(sharedTypes, map[string]string{
+							"name": exp.Name, "kind": exp.Kind, "file": path, "direction": direction,
+						})
+					}
+				}
+			}
+		}
+	}
+	addTypes(dependsOn, "imported")
+	addTypes(dependedOnBy, "exported")
+
+	plan := map[string]interface{}{
+		"path":              relPath,
+		"file_count":        len(targetFiles),
+		"depends_on":        limitLinks(dependsOn, p.Limit),
+		"depends_on_total":  len(dependsOn),
+		"depends_on_areas":  areas,
+		"depended_on_by":    limitLinks(dependedOnBy, p.Limit),
+		"dependents_total":  len(outsideDependents),
+		"external_packages": sortedKeys(packages),
+	}
+	sharedTotal := len(sharedTypes)
+	if sharedTotal > p.Limit {
+		plan["shared_types_total"] = sharedTotal
+		sharedTypes = sharedTypes[:p.Limit]
+	}
+	plan["shared_types"] = sharedTypes
+
+	envCount := 0
+	if configMap, err := extractor.ExtractConfigMap(absPath); err == nil {
+		var envVars, required []string
+		for _, v := range configMap.EnvVars {
+			envVars = append(envVars, v.Name)
+			if v.Required {
+				required = append(required, v.Name)
+			}
+		}
+		envCount = len(uniqueSorted(envVars))
+		plan["env_vars"] = uniqueSorted(envVars)
+		if len(required) > 0 {
+			plan["required_env_vars"] = uniqueSorted(required)
+		}
+	}
+
+	// Knowledge travels when it is attached to the extracted files; it is
+	// shared when it is also attached to code that stays
+	classify := func(related []string) (applies, shared bool) {
+		for _, f := range related {
+			if inTarget(f) {
+				applies = true
+			} else {
+				shared = true
+			}
+		}
+		return applies, applies && shared
+	}
+	var decisions, warnings, insights []extractionKnowledge
+	if all, err := s.jsonStore.GetDecisions(); err == nil {
+		for _, d := range all {
+			if d.Status == "superseded" || d.Status == "archived" {
+				continue
+			}
+			if applies, shared := classify(d.RelatedFiles); applies {
+				decisions = append(decisions, extractionKnowledge{ID: d.ID, Content: d.Content, Shared: shared})
+			}
+		}
+	}
+	if all, err := s.jsonStore.GetWarnings(); err == nil {
+		for _, w := range all {
+			if applies, shared := classify(w.RelatedFiles); applies {
+				warnings = append(warnings, extractionKnowledge{ID: w.ID, Content: w.Content, Shared: shared})
+			}
+		}
+	}
+	if all, err := s.jsonStore.GetInsights(); err == nil {
+		for _, ins := range all {
+			if ins.PromotedTo != "" {
+				continue // travels as the decision or warning it became
+			}
+			if applies, shared := classify(ins.RelatedFiles); applies {
+				insights = append(insights, extractionKnowledge{ID: ins.ID, Content: ins.Content, Shared: shared})
+			}
+		}
+	}
+	patternIDs := make(map[string]bool)
+	for _, path := range targetFiles {
+		for _, id := range files[path].Patterns {
+			patternIDs[id] = true
+		}
+	}
+	var patterns []string
+	for id := range patternIDs {
+		if pattern, err := s.jsonStore.GetPattern(id); err == nil {
+			patterns = append(patterns, pattern.Name)
+		}
+	}
+	sort.Strings(patterns)
+	plan["knowledge"] = map[string]interface{}{
+		"decisions": decisions,
+		"warnings":  warnings,
+		"insights":  insights,
+		"patterns":  patterns,
+	}
+
+	plan["summary"] = fmt.Sprintf("%s has %d files. It imports %d paths across %d areas outside it and is imported by %d files that stay. %d types cross the boundary, it reads %d env vars, and %d decisions, %d warnings and %d insights travel with it.",
+		relPath, len(targetFiles), len(dependsOn), len(areas), len(outsideDependents), sharedTotal, envCount, len(decisions), len(warnings), len(insights))
+	return plan, nil
+}
+
+// boundaryLinks flattens path -> referencing files, most referenced first
+func boundaryLinks(refs map[string]map[string]bool) []boundaryLink {
+	links := make([]boundaryLink, 0, len(refs))
+	for path, files := range refs {
+		links = append(links, boundaryLink{Path: path, Files: sortedKeys(files)})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if len(links[i].Files) != len(links[j].Files) {
+			return len(links[i].Files) > len(links[j].Files)
+		}
+		return links[i].Path < links[j].Path
+	})
+	return links
+}
+
+func limitLinks(links []boundaryLink, limit int) []boundaryLink {
+	if len(links) > limit {
+		return links[:limit]
+	}
+	return links
+}
+
+// filesUnder returns the indexed files at path: the file itself, or the
+// files directly inside it when path is a (Go package) directory
+func filesUnder(files map[string]types.FileIndex, path string) []string {
+	if _, ok := files[path]; ok {
+		return []string{path}
+	}
+	var under []string
+	for p, fi := range files {
+		if fi.DeletedAt == nil && filepath.ToSlash(filepath.Dir(p)) == path {
+			under = append(under, p)
+		}
+	}
+	sort.Strings(under)
+	return under
+}
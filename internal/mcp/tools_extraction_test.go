@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// setupExtractionProject writes a small Go module where billing imports
+// common and is imported by orders
+func setupExtractionProject(t *testing.T) *Server {
+	t.Helper()
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "go.mod", "module example.com/shop\n\ngo 1.22\n")
+	writeProjectFile(t, s, "billing/invoice.go", `package billing
+
+import (
+	"os"
+
+	"example.com/shop/common"
+	"github.com/stripe/stripe-go"
+)
+
+var key = os.Getenv("STRIPE_KEY")
+
+func Charge(m common.Money) error { _ = stripe.Key; return nil }
+`)
+	writeProjectFile(t, s, "billing/types.go", "package billing\n\ntype Invoice struct{}\n")
+	writeProjectFile(t, s, "common/money.go", "package common\n\ntype Money struct{}\n")
+	writeProjectFile(t, s, "orders/order.go", "package orders\n\nimport \"example.com/shop/billing\"\n\nvar _ billing.Invoice\n")
+	writeProjectFile(t, s, "docs/empty/README", "nothing indexed here\n")
+
+	files := map[string]types.FileIndex{
+		"billing/invoice.go": {Path: "billing/invoice.go", Language: "go", Imports: []string{"os", "example.com/shop/common", "github.com/stripe/stripe-go"}, Exports: []types.Export{{Name: "Charge", Kind: "function"}}},
+		"billing/types.go":   {Path: "billing/types.go", Language: "go", Exports: []types.Export{{Name: "Invoice", Kind: "struct"}}},
+		"common/money.go":    {Path: "common/money.go", Language: "go", Exports: []types.Export{{Name: "Money", Kind: "struct"}}},
+		"orders/order.go":    {Path: "orders/order.go", Language: "go", Imports: []string{"example.com/shop/billing"}},
+	}
+	if err := s.jsonStore.SaveFilesIndexBulk(files); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+	return s
+}
+
+func TestExtractionPlanBoundary(t *testing.T) {
+	s := setupExtractionProject(t)
+	plan := resultMap(t, mustCall(t, s, "extraction_plan", map[string]interface{}{"path": "billing/"}))
+
+	if plan["path"] != "billing" || plan["file_count"] != 2 {
+		t.Errorf("path = %v, file_count = %v, want billing and 2", plan["path"], plan["file_count"])
+	}
+	want := []boundaryLink{{Path: "common", Files: []string{"billing/invoice.go"}}}
+	if got := plan["depends_on"].([]boundaryLink); !reflect.DeepEqual(got, want) {
+		t.Errorf("depends_on = %+v, want %+v", got, want)
+	}
+	want = []boundaryLink{{Path: "billing", Files: []string{"orders/order.go"}}}
+	if got := plan["depended_on_by"].([]boundaryLink); !reflect.DeepEqual(got, want) {
+		t.Errorf("depended_on_by = %+v, want %+v", got, want)
+	}
+	if got := plan["external_packages"].([]string); !reflect.DeepEqual(got, []string{"github.com/stripe/stripe-go"}) {
+		t.Errorf("external_packages = %v, want only the third-party package", got)
+	}
+	if got := plan["env_vars"].([]string); !reflect.DeepEqual(got, []string{"STRIPE_KEY"}) {
+		t.Errorf("env_vars = %v, want STRIPE_KEY", got)
+	}
+
+	directions := make(map[string]string)
+	for _, st := range plan["shared_types"].([]map[string]string) {
+		directions[st["name"]] = st["direction"]
+	}
+	if !reflect.DeepEqual(directions, map[string]string{"Money": "imported", "Invoice": "exported"}) {
+		t.Errorf("shared types = %v, want Money imported and Invoice exported", directions)
+	}
+}
+
+func TestExtractionPlanKnowledge(t *testing.T) {
+	s := setupExtractionProject(t)
+	local := &types.Decision{Content: "Invoices are immutable", RelatedFiles: []string{"billing/types.go"}}
+	elsewhere := &types.Decision{Content: "Orders use optimistic locking", RelatedFiles: []string{"orders/order.go"}}
+	for _, d := range []*types.Decision{local, elsewhere} {
+		if err := s.jsonStore.AddDecision(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shared := &types.Warning{Content: "Charge before confirming the order", RelatedFiles: []string{"billing/invoice.go", "orders/order.go"}}
+	if err := s.jsonStore.AddWarning(shared); err != nil {
+		t.Fatal(err)
+	}
+
+	knowledge := resultMap(t, mustCall(t, s, "extraction_plan", map[string]interface{}{"path": "billing"}))["knowledge"].(map[string]interface{})
+	decisions := knowledge["decisions"].([]extractionKnowledge)
+	if len(decisions) != 1 || decisions[0].ID != local.ID || decisions[0].Shared {
+		t.Errorf("decisions = %+v, want only the billing decision, not shared", decisions)
+	}
+	warnings := knowledge["warnings"].([]extractionKnowledge)
+	if len(warnings) != 1 || !warnings[0].Shared {
+		t.Errorf("warnings = %+v, want the warning marked shared", warnings)
+	}
+}
+
+func TestExtractionPlanErrors(t *testing.T) {
+	s := setupExtractionProject(t)
+	for _, path := range []string{"", ".", "missing", "billing/invoice.go", "docs/empty"} {
+		if _, err := callTool(t, s, "extraction_plan", map[string]interface{}{"path": path}); err == nil {
+			t.Errorf("path %q accepted", path)
+		}
+	}
+}
@@ -4,9 +4,12 @@ import (
 "encoding/json"
 "fmt"
 "path/filepath"
+"sort"
 "strings"
 
 "github.com/saeedalam/teamcontext/internal/git"
+"github.com/saeedalam/teamcontext/internal/relpath"
+"github.com/saeedalam/teamcontext/pkg/types"
 )
 
 // =============================================================================
@@ -148,8 +151,9 @@ func (s *Server) handleGetFileHistory(params json.RawMessage) (interface{}, erro
 
 func (s *Server) handleGetKnowledgeRisks(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		Area  string `json:"area"`
-		Limit int    `json:"limit"`
+		Area          string `json:"area"`
+		Limit         int    `json:"limit"`
+		TransferPlans *bool  `json:"transfer_plans"`
 	}
 	if err := json.Unmarshal(params, &p); err != nil {
 		return nil, err
@@ -206,14 +210,21 @@ func (s *Server) handleGetKnowledgeRisks(params json.RawMessage) (interface{}, e
 		truncated = true
 	}
 
+	// Turn departed-expert risks into knowledge-transfer plans
+	plans := 0
+	if p.TransferPlans == nil || *p.TransferPlans {
+		plans = s.addTransferPlans(risks)
+	}
+
 	return map[string]interface{}{
-		"risks":        risks,
-		"risk_counts":  riskCounts,
-		"returned":     len(risks),
-		"total_risks":  totalCount,
-		"truncated":    truncated,
-		"source":       source,
-		"note":         "CRITICAL risks need immediate attention. HIGH risks should be addressed soon.",
+		"risks":          risks,
+		"transfer_plans": plans,
+		"risk_counts":    riskCounts,
+		"returned":       len(risks),
+		"total_risks":    totalCount,
+		"truncated":      truncated,
+		"source":         source,
+		"note":           "CRITICAL risks need immediate attention. HIGH risks should be addressed soon. Follow transfer_plan steps where present.",
 	}, nil
 }
 
@@ -304,3 +315,212 @@ func (s *Server) handleGetCommitContext(params json.RawMessage) (interface{}, er
 		"summary":      context.Summary,
 	}, nil
 }
+
+// maxTransferPlans caps how many risks get a generated transfer plan
+const maxTransferPlans = 10
+
+// addTransferPlans attaches a knowledge-transfer plan to each risk whose
+// primary expert is no longer active and returns how many were added.
+func (s *Server) addTransferPlans(risks []git.KnowledgeRisk) int {
+	var cachedExperts []git.DirectoryExpert
+	s.loadGitKnowledge("git-experts.json", &cachedExperts)
+	files, _ := s.jsonStore.GetFilesIndex()
+	decisions, _ := s.jsonStore.GetDecisions()
+	warnings, _ := s.jsonStore.GetWarnings()
+
+	var summary git.GitSummary
+	changes := make(map[string]int)
+	if s.loadGitKnowledge("git-summary.json", &summary) == nil {
+		for _, fa := range summary.TopFiles {
+			changes[fa.Path] = fa.Changes
+		}
+	}
+
+	added := 0
+	for i := range risks {
+		if risks[i].ExpertIsActive || risks[i].PrimaryExpert == "" {
+			continue
+		}
+		if added >= maxTransferPlans {
+			break
+		}
+		risks[i].TransferPlan = buildTransferPlan(&risks[i], cachedExperts, files, decisions, warnings, changes)
+		added++
+	}
+	return added
+}
+
+// buildTransferPlan suggests who should take over an area, which files to
+// document first, and generates onboarding notes from recorded knowledge.
+func buildTransferPlan(risk *git.KnowledgeRisk, experts []git.DirectoryExpert, files map[string]types.FileIndex,
+	decisions []types.Decision, warnings []types.Warning, changes map[string]int) *git.TransferPlan {
+	area := risk.Area
+	inArea := func(path string) bool {
+		return relpath.Within(path, area)
+	}
+
+	// Successors: active contributors of the area, then of its parent and
+	// child directories, weighted by commits
+	type candidate struct {
+		entry  git.ExpertEntry
+		weight int
+	}
+	candidates := make(map[string]*candidate)
+	for _, de := range experts {
+		var factor int
+		switch {
+		case de.Directory == area:
+			factor = 3
+		case strings.HasPrefix(de.Directory, area+"/"):
+			factor = 2
+		case strings.HasPrefix(area, de.Directory+"/"):
+			factor = 1
+		default:
+			continue
+		}
+		for _, e := range de.TopExperts {
+			if !e.Active || e.Name == risk.PrimaryExpert {
+				continue
+			}
+			c, ok := candidates[e.Email]
+			if !ok {
+				c = &candidate{entry: git.ExpertEntry{Name: e.Name, Email: e.Email, Active: true}}
+				candidates[e.Email] = c
+			}
+			c.entry.Commits += e.Commits
+			c.weight += e.Commits * factor
+			if e.LastCommit.After(c.entry.LastCommit) {
+				c.entry.LastCommit = e.LastCommit
+			}
+		}
+	}
+	var ranked []*candidate
+	for _, c := range candidates {
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].weight != ranked[j].weight {
+			return ranked[i].weight > ranked[j].weight
+		}
+		return ranked[i].entry.Name < ranked[j].entry.Name
+	})
+	plan := &git.TransferPlan{}
+	for _, c := range ranked {
+		if len(plan.Successors) >= 3 {
+			break
+		}
+		plan.Successors = append(plan.Successors, c.entry)
+	}
+
+	// Files to document: most exported and most changed first (line counts
+	// are pruned from the stored index, so size can't be used here)
+	type keyFile struct {
+		path  string
+		score int
+	}
+	var keyFiles []keyFile
+	for path, fi := range files {
+		if !inArea(path) {
+			continue
+		}
+		keyFiles = append(keyFiles, keyFile{path, len(fi.Exports)*10 + changes[path]*5})
+	}
+	sort.Slice(keyFiles, func(i, j int) bool {
+		if keyFiles[i].score != keyFiles[j].score {
+			return keyFiles[i].score > keyFiles[j].score
+		}
+		return keyFiles[i].path < keyFiles[j].path
+	})
+	for _, kf := range keyFiles {
+		if len(plan.FilesToDocument) >= 5 {
+			break
+		}
+		plan.FilesToDocument = append(plan.FilesToDocument, kf.path)
+	}
+
+	// Recorded knowledge that touches the area
+	var areaDecisions []types.Decision
+	for _, d := range decisions {
+		for _, f := range d.RelatedFiles {
+			if inArea(f) {
+				areaDecisions = append(areaDecisions, d)
+				break
+			}
+		}
+	}
+	var areaWarnings []types.Warning
+	for _, w := range warnings {
+		for _, f := range w.RelatedFiles {
+			if inArea(f) {
+				areaWarnings = append(areaWarnings, w)
+				break
+			}
+		}
+	}
+
+	// Steps
+	owner := "a new maintainer"
+	if len(plan.Successors) > 0 {
+		owner = plan.Successors[0].Name
+	}
+	plan.Steps = append(plan.Steps, fmt.Sprintf("Assign %s as maintainer of %s", owner, area))
+	plan.Steps = append(plan.Steps, fmt.Sprintf("If %s is still reachable, schedule a handoff session walking through the files below", risk.PrimaryExpert))
+	if len(plan.FilesToDocument) > 0 {
+		plan.Steps = append(plan.Steps, fmt.Sprintf("Document the key files, starting with %s", plan.FilesToDocument[0]))
+	}
+	if len(areaDecisions) == 0 {
+		plan.Steps = append(plan.Steps, "No decisions are recorded for this area; capture the reasoning behind its design with add_decision")
+	}
+	plan.Steps = append(plan.Steps, fmt.Sprintf("Save the onboarding notes below as %s/README.md and review them with the new owner", area))
+
+	// Onboarding notes
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Onboarding: %s\n\n", area)
+	fmt.Fprintf(&b, "Former primary expert: %s", risk.PrimaryExpert)
+	if risk.LastActivity != "" {
+		fmt.Fprintf(&b, " (last activity: %s)", risk.LastActivity)
+	}
+	b.WriteString("\n")
+	if len(plan.Successors) > 0 {
+		var names []string
+		for _, e := range plan.Successors {
+			names = append(names, e.Name)
+		}
+		fmt.Fprintf(&b, "Ask: %s\n", strings.Join(names, ", "))
+	}
+	if len(plan.FilesToDocument) > 0 {
+		b.WriteString("\n## Key files\n")
+		for _, path := range plan.FilesToDocument {
+			if summary := files[path].Summary; summary != "" {
+				fmt.Fprintf(&b, "- %s — %s\n", path, summary)
+			} else {
+				fmt.Fprintf(&b, "- %s\n", path)
+			}
+		}
+	}
+	if len(areaDecisions) > 0 {
+		b.WriteString("\n## Decisions\n")
+		for i, d := range areaDecisions {
+			if i >= 5 {
+				break
+			}
+			fmt.Fprintf(&b, "- %s", d.Content)
+			if d.Reason != "" {
+				fmt.Fprintf(&b, " (why: %s)", d.Reason)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(areaWarnings) > 0 {
+		b.WriteString("\n## Pitfalls\n")
+		for i, w := range areaWarnings {
+			if i >= 5 {
+				break
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", w.Severity, w.Content)
+		}
+	}
+	plan.Onboarding = b.String()
+
+	return plan
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func exports(names ...string) []types.Export {
+	var out []types.Export
+	for _, n := range names {
+		out = append(out, types.Export{Name: n, Kind: "function"})
+	}
+	return out
+}
+
+func TestBuildTransferPlanOrdering(t *testing.T) {
+	s := setupTestServer(t)
+	// Stored through the JSON store, so the plan sees the pruned index
+	// (no line counts) exactly as addTransferPlans does
+	if err := s.jsonStore.SaveFilesIndexBulk(map[string]types.FileIndex{
+		"billing/huge.go":     {Path: "billing/huge.go", LineCount: 5000},
+		"billing/api.go":      {Path: "billing/api.go", Exports: exports("A", "B", "C")},
+		"billing/churn.go":    {Path: "billing/churn.go", Exports: exports("A")},
+		"billing/models.go":   {Path: "billing/models.go", Exports: exports("A", "B")},
+		"billing/a_tie.go":    {Path: "billing/a_tie.go", Exports: exports("A", "B")},
+		"billing/sub/deep.go": {Path: "billing/sub/deep.go", Exports: exports("A")},
+		"billing-v2/other.go": {Path: "billing-v2/other.go", Exports: exports("A", "B", "C", "D")},
+	}); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+	files, err := s.jsonStore.GetFilesIndex()
+	if err != nil {
+		t.Fatalf("GetFilesIndex: %v", err)
+	}
+	changes := map[string]int{"billing/churn.go": 5} // 10 + 25 beats 3 exports
+
+	experts := []git.DirectoryExpert{
+		{Directory: "billing", TopExperts: []git.ExpertEntry{
+			{Name: "Gone", Email: "gone@example.com", Commits: 90},
+			{Name: "Bo", Email: "bo@example.com", Commits: 10, Active: true},
+			{Name: "Inactive", Email: "old@example.com", Commits: 50},
+		}},
+		{Directory: "billing/sub", TopExperts: []git.ExpertEntry{
+			{Name: "Cy", Email: "cy@example.com", Commits: 20, Active: true},
+		}},
+		{Directory: ".", TopExperts: []git.ExpertEntry{
+			{Name: "Al", Email: "al@example.com", Commits: 25, Active: true},
+		}},
+		{Directory: "billing-v2", TopExperts: []git.ExpertEntry{
+			{Name: "Neighbor", Email: "n@example.com", Commits: 500, Active: true},
+		}},
+	}
+	warnings := []types.Warning{{Content: "double charges on retry", RelatedFiles: []string{"billing/api.go"}}}
+
+	risk := &git.KnowledgeRisk{Area: "billing", PrimaryExpert: "Gone"}
+	plan := buildTransferPlan(risk, experts, files, nil, warnings, changes)
+
+	wantFiles := []string{"billing/churn.go", "billing/api.go", "billing/a_tie.go", "billing/models.go", "billing/sub/deep.go"}
+	if !reflect.DeepEqual(plan.FilesToDocument, wantFiles) {
+		t.Errorf("FilesToDocument = %v, want %v", plan.FilesToDocument, wantFiles)
+	}
+
+	var successors []string
+	for _, e := range plan.Successors {
+		successors = append(successors, e.Name)
+	}
+	// Cy: 20 commits in a child dir (x2) beats Bo: 10 in the area (x3)
+	if want := []string{"Cy", "Bo"}; !reflect.DeepEqual(successors, want) {
+		t.Errorf("Successors = %v, want %v", successors, want)
+	}
+
+	if !strings.Contains(plan.Steps[0], "Assign Cy") {
+		t.Errorf("first step = %q, want Cy assigned", plan.Steps[0])
+	}
+	if !strings.Contains(plan.Onboarding, "double charges on retry") {
+		t.Errorf("onboarding notes miss the area warning:\n%s", plan.Onboarding)
+	}
+}
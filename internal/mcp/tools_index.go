@@ -5,6 +5,9 @@ import (
 "fmt"
 "os"
 "path/filepath"
+"sort"
+"strings"
+"time"
 
 "github.com/saeedalam/teamcontext/internal/blueprint"
 "github.com/saeedalam/teamcontext/internal/extractor"
@@ -64,7 +67,7 @@ func (s *Server) handleIndexStatus(params json.RawMessage) (interface{}, error)
 		indexedPaths = append(indexedPaths, path)
 	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"files_indexed":   stats.FilesIndexed,
 		"indexed_paths":   indexedPaths,
 		"decisions":       stats.Decisions,
@@ -72,7 +75,17 @@ func (s *Server) handleIndexStatus(params json.RawMessage) (interface{}, error)
 		"patterns":        stats.Patterns,
 		"features":        stats.Features,
 		"active_features": stats.ActiveFeatures,
-	}, nil
+	}
+
+	// What the index caps left out, so agents know search is partial
+	if report, err := s.jsonStore.GetIndexCapReport(); err == nil && report != nil {
+		result["caps"] = report
+		result["degraded"] = report.Degraded()
+		if report.Degraded() {
+			result["hint"] = "The index is partial: raise index.max_files, max_chunk_rows or max_vector_rows in .teamcontext/config.json and run `teamcontext reindex`"
+		}
+	}
+	return result, nil
 }
 
 func (s *Server) handleGetGraph(params json.RawMessage) (interface{}, error) {
@@ -112,6 +125,189 @@ func (s *Server) handleGetGraph(params json.RawMessage) (interface{}, error) {
 	}, nil
 }
 
+// couplingChange is how the import coupling between two areas changed over
+// the requested window
+type couplingChange struct {
+	Pair   string `json:"pair"` // "from -> to"
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Delta  int    `json:"delta"`
+	Status string `json:"status,omitempty"` // appeared, disappeared
+}
+
+// handleGetGraphEvolution replays the graph snapshots recorded at each
+// reindex: edge counts per period, and how coupling between services or
+// directories grew or shrank
+func (s *Server) handleGetGraphEvolution(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Since    string `json:"since"`
+		Until    string `json:"until"`
+		Area     string `json:"area"`
+		Interval string `json:"interval"`
+		Limit    int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Limit <= 0 {
+		p.Limit = 15
+	}
+	periodKey := func(t time.Time) string { return t.Format("2006-01") }
+	switch p.Interval {
+	case "", "month":
+		p.Interval = "month"
+	case "week":
+		periodKey = func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}
+	case "day":
+		periodKey = func(t time.Time) string { return t.Format("2006-01-02") }
+	default:
+		return nil, fmt.Errorf("invalid interval %q: use month, week or day", p.Interval)
+	}
+
+	var since, until time.Time
+	if p.Since != "" {
+		if since = parseFeedSince(p.Since); since.IsZero() {
+			return nil, fmt.Errorf("invalid since %q: use YYYY-MM-DD, RFC3339 or a duration like 90d", p.Since)
+		}
+	}
+	if p.Until != "" {
+		if until = parseFeedSince(p.Until); until.IsZero() {
+			return nil, fmt.Errorf("invalid until %q: use YYYY-MM-DD, RFC3339 or a duration like 90d", p.Until)
+		}
+	}
+
+	history, err := s.jsonStore.GetGraphHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	// The last snapshot of each period stands for it
+	inArea := func(pair string) bool {
+		if p.Area == "" {
+			return true
+		}
+		from, to, _ := strings.Cut(pair, " -> ")
+		return from == p.Area || to == p.Area
+	}
+	var periods []map[string]interface{}
+	var snapshots []types.GraphSnapshot
+	for _, snap := range history.Snapshots {
+		if (!since.IsZero() && snap.TakenAt.Before(since)) || (!until.IsZero() && snap.TakenAt.After(until)) {
+			continue
+		}
+		if n := len(snapshots); n > 0 && periodKey(snapshots[n-1].TakenAt) == periodKey(snap.TakenAt) {
+			snapshots[n-1] = snap
+			continue
+		}
+		snapshots = append(snapshots, snap)
+	}
+	if len(snapshots) == 0 {
+		return map[string]interface{}{
+			"periods": []interface{}{},
+			"hint":    "No graph snapshots in this window. One is recorded after each index and periodic reindex.",
+		}, nil
+	}
+
+	for _, snap := range snapshots {
+		coupling := 0
+		for pair, n := range snap.Coupling {
+			if inArea(pair) {
+				coupling += n
+			}
+		}
+		period := map[string]interface{}{
+			"period":    periodKey(snap.TakenAt),
+			"taken_at":  snap.TakenAt,
+			"edges":     snap.Edges,
+			"relations": snap.Relations,
+			"coupling":  coupling,
+		}
+		if snap.Commit != "" {
+			period["commit"] = snap.Commit
+		}
+		periods = append(periods, period)
+	}
+
+	// Compare the first and last period
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	var changes []couplingChange
+	for pair := range mergeKeys(first.Coupling, last.Coupling) {
+		if !inArea(pair) {
+			continue
+		}
+		c := couplingChange{Pair: pair, Before: first.Coupling[pair], After: last.Coupling[pair]}
+		c.Delta = c.After - c.Before
+		switch {
+		case c.Delta == 0:
+			continue
+		case c.Before == 0:
+			c.Status = "appeared"
+		case c.After == 0:
+			c.Status = "disappeared"
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		di, dj := changes[i].Delta, changes[j].Delta
+		if di < 0 {
+			di = -di
+		}
+		if dj < 0 {
+			dj = -dj
+		}
+		if di != dj {
+			return di > dj
+		}
+		return changes[i].Pair < changes[j].Pair
+	})
+	truncated := len(changes) > p.Limit
+	if truncated {
+		changes = changes[:p.Limit]
+	}
+
+	relationChanges := make(map[string]int)
+	for rel := range mergeKeys(first.Relations, last.Relations) {
+		if d := last.Relations[rel] - first.Relations[rel]; d != 0 {
+			relationChanges[rel] = d
+		}
+	}
+
+	before, after := periods[0]["coupling"].(int), periods[len(periods)-1]["coupling"].(int)
+	result := map[string]interface{}{
+		"interval":         p.Interval,
+		"periods":          periods,
+		"coupling_changes": changes,
+		"relation_changes": relationChanges,
+		"summary": fmt.Sprintf("Import coupling between areas went from %d to %d edges (%+d) across %d %s(s), %s to %s",
+			before, after, after-before, len(periods), p.Interval, periods[0]["period"], periods[len(periods)-1]["period"]),
+	}
+	if p.Area != "" {
+		result["area"] = p.Area
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	if len(periods) < 2 {
+		result["hint"] = "Only one period has snapshots; widen the window or use a shorter interval to see change"
+	}
+	return result, nil
+}
+
+// mergeKeys returns the keys present in either map
+func mergeKeys(a, b map[string]int) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
 func (s *Server) handleGetRelated(params json.RawMessage) (interface{}, error) {
 	var p struct {
 		NodeType string `json:"node_type"`
@@ -308,6 +504,7 @@ func (s *Server) handleGetAPISurface(params json.RawMessage) (interface{}, error
 	var p struct {
 		Path string `json:"path"`
 		App  string `json:"app"`
+		Spec string `json:"spec"`
 	}
 
 	if err := json.Unmarshal(params, &p); err != nil {
@@ -324,6 +521,7 @@ func (s *Server) handleGetAPISurface(params json.RawMessage) (interface{}, error
 	}
 
 	var surface *extractor.APISurface
+	var specs []string
 
 	if info.IsDir() {
 		appName := p.App
@@ -331,6 +529,7 @@ func (s *Server) handleGetAPISurface(params json.RawMessage) (interface{}, error
 			appName = filepath.Base(p.Path)
 		}
 		surface, err = extractor.ExtractAPISurface(p.Path, appName)
+		specs = extractor.FindOpenAPISpecs(p.Path)
 	} else {
 		surface, err = extractor.ExtractAPISurfaceFromFile(p.Path)
 	}
@@ -339,13 +538,219 @@ func (s *Server) handleGetAPISurface(params json.RawMessage) (interface{}, error
 		return nil, err
 	}
 
-	return map[string]interface{}{
+	// Compare with the OpenAPI/Swagger spec: an explicit one, else those
+	// found under the directory
+	if p.Spec != "" {
+		if _, err := os.Stat(p.Spec); err != nil {
+			return nil, fmt.Errorf("spec not found: %w", err)
+		}
+		specs = []string{p.Spec}
+	}
+	if len(specs) > 0 {
+		extractor.MergeOpenAPISpecs(surface, specs)
+	}
+
+	result := map[string]interface{}{
 		"app":             surface.App,
 		"endpoints":       surface.Endpoints,
 		"endpoint_count":  len(surface.Endpoints),
 		"kafka_consumers": surface.KafkaConsumers,
 		"kafka_producers": surface.KafkaProducers,
-	}, nil
+	}
+	if surface.Spec != nil {
+		result["spec"] = surface.Spec
+	}
+	return result, nil
+}
+
+// handleExportRequests turns the API surface of a directory or file into a
+// Postman, Insomnia or .http collection with example bodies inferred from
+// the DTOs/models the handlers accept
+func (s *Server) handleExportRequests(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path    string `json:"path"`
+		App     string `json:"app"`
+		Format  string `json:"format"`
+		BaseURL string `json:"base_url"`
+		Output  string `json:"output"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	if !filepath.IsAbs(p.Path) {
+		p.Path = filepath.Join(projectRoot, p.Path)
+	}
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("path not found: %w", err)
+	}
+
+	var surface *extractor.APISurface
+	typesDir := p.Path
+	if info.IsDir() {
+		appName := p.App
+		if appName == "" {
+			appName = filepath.Base(p.Path)
+		}
+		surface, err = extractor.ExtractAPISurface(p.Path, appName)
+	} else {
+		surface, err = extractor.ExtractAPISurfaceFromFile(p.Path)
+		if p.App != "" && surface != nil {
+			surface.App = p.App
+		}
+		// DTOs usually live next to the controller (dto/, schemas.py)
+		typesDir = filepath.Dir(p.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(surface.Endpoints) == 0 {
+		return nil, fmt.Errorf("no REST endpoints found under %s", p.Path)
+	}
+
+	payloads, err := extractor.ExtractPayloadTypes(typesDir)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := extractor.BuildRequestCollection(surface, payloads, extractor.CollectionOptions{
+		Format:  p.Format,
+		BaseURL: p.BaseURL,
+		Root:    projectRoot,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"format":         collection.Format,
+		"requests":       collection.Requests,
+		"with_example":   collection.WithExample,
+		"unknown_bodies": collection.UnknownBodies,
+	}
+	if p.Output == "" {
+		result["file_name"] = collection.FileName
+		result["content"] = collection.Content
+		return result, nil
+	}
+
+	// Written collections stay inside the project
+	output := p.Output
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(projectRoot, output)
+	}
+	if rel, err := filepath.Rel(projectRoot, output); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("output must be inside the project: %s", p.Output)
+	}
+	if info, err := os.Stat(output); err == nil && info.IsDir() {
+		output = filepath.Join(output, collection.FileName)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(output, []byte(collection.Content), 0644); err != nil {
+		return nil, err
+	}
+	rel, _ := filepath.Rel(projectRoot, output)
+	result["file"] = filepath.ToSlash(rel)
+	return result, nil
+}
+
+// handleGetAuthMatrix lists the authentication and authorization applied to
+// each endpoint of the API surface and flags endpoints with neither
+func (s *Server) handleGetAuthMatrix(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path   string `json:"path"`
+		App    string `json:"app"`
+		Status string `json:"status"`
+		Format string `json:"format"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if p.Status != "" && p.Status != extractor.AuthGuarded && p.Status != extractor.AuthPublic && p.Status != extractor.AuthUnguarded {
+		return nil, fmt.Errorf("status must be guarded, public or unguarded")
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	if !filepath.IsAbs(p.Path) {
+		p.Path = filepath.Join(projectRoot, p.Path)
+	}
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("path not found: %w", err)
+	}
+
+	var surface *extractor.APISurface
+	dir := p.Path
+	if info.IsDir() {
+		appName := p.App
+		if appName == "" {
+			appName = filepath.Base(p.Path)
+		}
+		surface, err = extractor.ExtractAPISurface(p.Path, appName)
+	} else {
+		surface, err = extractor.ExtractAPISurfaceFromFile(p.Path)
+		if p.App != "" && surface != nil {
+			surface.App = p.App
+		}
+		dir = filepath.Dir(p.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := extractor.BuildAuthMatrix(surface, extractor.AuthMatrixOptions{Dir: dir, Root: projectRoot})
+	if p.Format == "markdown" {
+		return map[string]interface{}{
+			"app":       matrix.App,
+			"unguarded": matrix.Unguarded,
+			"content":   extractor.FormatAuthMatrixMarkdown(matrix),
+		}, nil
+	}
+
+	endpoints := matrix.Endpoints
+	if p.Status != "" {
+		endpoints = []extractor.AuthMatrixEntry{}
+		for _, e := range matrix.Endpoints {
+			if e.Status == p.Status {
+				endpoints = append(endpoints, e)
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"app":            matrix.App,
+		"endpoints":      endpoints,
+		"endpoint_count": len(matrix.Endpoints),
+		"guarded":        matrix.Guarded,
+		"public":         matrix.Public,
+		"unguarded":      matrix.Unguarded,
+		"mechanisms":     matrix.Mechanisms,
+	}
+	if len(matrix.Global) > 0 {
+		result["global"] = matrix.Global
+	}
+	if matrix.Unguarded > 0 {
+		var flagged []string
+		for _, e := range matrix.UnguardedEndpoints() {
+			flagged = append(flagged, fmt.Sprintf("%s %s (%s:%d)", e.Method, e.Path, e.File, e.Line))
+		}
+		result["unguarded_endpoints"] = flagged
+		result["hint"] = "Unguarded endpoints have no check in their file, controller or app-wide guards. Confirm each is meant to be open or is protected by middleware mounted elsewhere."
+	}
+	return result, nil
 }
 
 func (s *Server) handleGetSchemaModels(params json.RawMessage) (interface{}, error) {
@@ -364,7 +769,7 @@ func (s *Server) handleGetSchemaModels(params json.RawMessage) (interface{}, err
 	// Try Prisma-specific extraction first
 	prismaSchema, prismaErr := extractor.ExtractSchemaModels(p.Path)
 
-	// Also try multi-language extraction (Go GORM, Python SQLAlchemy/Django, Java JPA, TypeORM)
+	// Also try multi-language extraction (Go GORM, Python SQLAlchemy/Django, Java JPA, TypeORM, SQL DDL)
 	multiSchema, multiErr := extractor.ExtractMultiLangSchema(p.Path)
 
 	// Merge results with deduplication
@@ -424,13 +829,14 @@ func (s *Server) handleGetSchemaModels(params json.RawMessage) (interface{}, err
 		"enums":           allEnums,
 		"enum_count":      len(allEnums),
 		"models_by_type":  modelsByLang,
-		"supported_langs": []string{"Prisma", "Go (GORM/sqlx)", "Python (SQLAlchemy/Django)", "Java (JPA/Hibernate)", "TypeScript (TypeORM)"},
+		"supported_langs": []string{"Prisma", "Go (GORM/sqlx)", "Python (SQLAlchemy/Django)", "Java (JPA/Hibernate)", "TypeScript (TypeORM)", "SQL (CREATE/ALTER TABLE, migrations)"},
 	}, nil
 }
 
 func (s *Server) handleGetConfigMap(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		Path string `json:"path"`
+		Path        string `json:"path"`
+		Environment string `json:"environment"`
 	}
 
 	if err := json.Unmarshal(params, &p); err != nil {
@@ -447,18 +853,113 @@ func (s *Server) handleGetConfigMap(params json.RawMessage) (interface{}, error)
 		return nil, err
 	}
 
+	// Narrow the environments and warnings to the target environment
+	environments, warnings := configMap.Environments, configMap.Warnings
+	if p.Environment != "" {
+		target := extractor.NormalizeEnvironment(p.Environment)
+		var known []string
+		environments = nil
+		for _, env := range configMap.Environments {
+			known = append(known, env.Name)
+			if env.Name == target {
+				environments = append(environments, env)
+			}
+		}
+		if environments == nil {
+			return nil, fmt.Errorf("environment %q is not defined by any .env.*, Helm values or CI file (found: %s)", p.Environment, strings.Join(known, ", "))
+		}
+		warnings = []extractor.EnvWarning{}
+		for _, w := range configMap.Warnings {
+			if w.Environment == target {
+				warnings = append(warnings, w)
+			}
+		}
+	}
+
 	return map[string]interface{}{
 		"env_vars":      configMap.EnvVars,
 		"env_var_count": len(configMap.EnvVars),
 		"config_files":  configMap.ConfigFiles,
+		"environments":  environments,
+		"warnings":      warnings,
 	}, nil
 }
 
+func (s *Server) handleListFeatureFlags(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path        string `json:"path"`
+		Provider    string `json:"provider"`
+		Key         string `json:"key"`
+		MissingOnly bool   `json:"missing_only"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	if p.Path == "" {
+		p.Path = projectRoot
+	}
+
+	opts := extractor.FlagOptions{Root: projectRoot}
+	if cfg, err := s.jsonStore.GetConfig(); err == nil {
+		opts.Sources = cfg.FeatureFlags.Sources
+	}
+	inventory, err := extractor.ExtractFeatureFlags(p.Path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make(map[string]bool)
+	for _, w := range inventory.Warnings {
+		missing[w.Key] = true
+	}
+	flags := []extractor.FeatureFlag{}
+	for _, f := range inventory.Flags {
+		if p.Provider != "" && !strings.EqualFold(f.Provider, p.Provider) {
+			continue
+		}
+		if p.Key != "" && !strings.Contains(strings.ToLower(f.Key), strings.ToLower(p.Key)) {
+			continue
+		}
+		if p.MissingOnly && !missing[f.Key] {
+			continue
+		}
+		flags = append(flags, f)
+	}
+	warnings := []extractor.FlagWarning{}
+	for _, w := range inventory.Warnings {
+		for _, f := range flags {
+			if f.Key == w.Key {
+				warnings = append(warnings, w)
+				break
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"flags":      flags,
+		"flag_count": len(flags),
+		"sources":    inventory.Sources,
+		"warnings":   warnings,
+	}
+	if !p.MissingOnly && len(inventory.Unused) > 0 {
+		result["unused"] = inventory.Unused
+	}
+	if len(inventory.Sources) == 0 && len(inventory.Flags) > 0 {
+		result["hint"] = "No flag definition file found, so missing flags cannot be detected. Set feature_flags.sources in .teamcontext/config.json to the files that define your flags."
+	}
+	return result, nil
+}
+
 func (s *Server) handleGetBlueprint(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		Task string `json:"task"`
-		App  string `json:"app"`
-		Path string `json:"path"`
+		Task              string `json:"task"`
+		App               string `json:"app"`
+		Path              string `json:"path"`
+		MaxExamples       int    `json:"max_examples"`
+		MaxImportsPerType int    `json:"max_imports_per_type"`
 	}
 
 	if err := json.Unmarshal(params, &p); err != nil {
@@ -489,6 +990,7 @@ func (s *Server) handleGetBlueprint(params json.RawMessage) (interface{}, error)
 	// Create blueprint generator
 	projectRoot := filepath.Dir(s.basePath)
 	gen := blueprint.NewGenerator(projectRoot, s.basePath, s.jsonStore)
+	gen.SetLimits(types.BlueprintConfig{MaxExamples: p.MaxExamples, MaxImportsPerType: p.MaxImportsPerType})
 
 	// Generate blueprint
 	bp, err := gen.Generate(taskType, p.App, p.Path)
@@ -498,19 +1000,22 @@ func (s *Server) handleGetBlueprint(params json.RawMessage) (interface{}, error)
 
 	// Return the blueprint as a structured response (v2: includes snippets, imports, conventions)
 	response := map[string]interface{}{
-		"task_type":    bp.TaskType,
-		"app":          bp.App,
-		"path":         bp.Path,
-		"description":  bp.Description,
-		"file_pattern": bp.FilePattern,
-		"examples":     bp.Examples,
-		"decisions":    bp.Decisions,
-		"warnings":     bp.Warnings,
-		"correlations": bp.Correlations,
-		"checklist":    bp.Checklist,
-		"confidence":   bp.Confidence,
-		"source":       bp.Source,
-		"usage_hint":   "Follow the checklist. Use snippets as code templates ({Name}/{name} = your feature). Use imports as-is. Respect conventions, decisions and warnings.",
+		"task_type":              bp.TaskType,
+		"app":                    bp.App,
+		"path":                   bp.Path,
+		"description":            bp.Description,
+		"file_pattern":           bp.FilePattern,
+		"examples":               bp.Examples,
+		"decisions":              bp.Decisions,
+		"warnings":               bp.Warnings,
+		"correlations":           bp.Correlations,
+		"checklist":              bp.Checklist,
+		"confidence":             bp.Confidence,
+		"confidence_level":       bp.ConfidenceLevel,
+		"confidence_breakdown":   bp.ConfidenceBreakdown,
+		"confidence_explanation": bp.ConfidenceExplanation,
+		"source":                 bp.Source,
+		"usage_hint":             "Follow the checklist. Use snippets as code templates ({Name}/{name} = your feature). Use imports as-is. Respect conventions, decisions and warnings. Trust each part as far as confidence_explanation says.",
 	}
 
 	// v2 fields — only include when populated to keep response compact
@@ -523,6 +1028,9 @@ func (s *Server) handleGetBlueprint(params json.RawMessage) (interface{}, error)
 	if bp.Conventions != nil {
 		response["conventions"] = bp.Conventions
 	}
+	if bp.Budget != nil {
+		response["budget"] = bp.Budget
+	}
 
 	return response, nil
 }
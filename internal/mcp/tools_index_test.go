@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/extractor"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// writeUsersController writes a NestJS controller whose create endpoint
+// takes a DTO declared next to it and whose import endpoint takes an
+// undeclared one
+func writeUsersController(t *testing.T, s *Server) {
+	t.Helper()
+	writeProjectFile(t, s, "src/users/users.controller.ts", `import { Controller, Get, Post, Body, Param, UseGuards } from '@nestjs/common';
+import { CreateUserDto } from './dto/create-user.dto';
+
+@UseGuards(JwtAuthGuard)
+@Controller('users')
+export class UsersController {
+  @Get(':id')
+  findOne(@Param('id') id: string) {
+    return {};
+  }
+
+  @Post()
+  create(@Body() dto: CreateUserDto) {
+    return {};
+  }
+
+  @Post('import')
+  importUsers(@Body() dto: ImportDto) {
+    return {};
+  }
+}
+`)
+	writeProjectFile(t, s, "src/users/dto/create-user.dto.ts", `export class CreateUserDto {
+  email: string;
+  age?: number;
+  tags: string[];
+}
+`)
+}
+
+func TestExportRequestsHTTP(t *testing.T) {
+	s := setupTestServer(t)
+	writeUsersController(t, s)
+
+	result := resultMap(t, mustCall(t, s, "export_requests", map[string]interface{}{"path": "src/users"}))
+	if result["requests"] != 3 || result["with_example"] != 1 || result["file_name"] != "users.http" {
+		t.Errorf("requests = %v, with_example = %v, file_name = %v; want 3, 1 and users.http", result["requests"], result["with_example"], result["file_name"])
+	}
+	if got := result["unknown_bodies"].([]string); !reflect.DeepEqual(got, []string{"ImportDto"}) {
+		t.Errorf("unknown_bodies = %v, want [ImportDto]", got)
+	}
+
+	content := result["content"].(string)
+	for _, want := range []string{
+		"@baseUrl = http://localhost:3000\n",
+		"@id = 1\n",
+		"GET {{baseUrl}}/users/{{id}}\n",
+		"POST {{baseUrl}}/users\nAuthorization: Bearer {{token}}\nContent-Type: application/json\n\n{\n  \"email\": \"user@example.com\",\n  \"age\": 0,\n  \"tags\": [",
+		"POST {{baseUrl}}/users/import\nAuthorization: Bearer {{token}}\nContent-Type: application/json\n\n{}\n",
+		"# src/users/users.controller.ts:12\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("collection is missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestExportRequestsJSONFormats(t *testing.T) {
+	s := setupTestServer(t)
+	writeUsersController(t, s)
+
+	tests := []struct {
+		format   string
+		fileName string
+	}{
+		{"postman", "team-api.postman_collection.json"},
+		{"insomnia", "team-api.insomnia.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			result := resultMap(t, mustCall(t, s, "export_requests", map[string]interface{}{
+				"path": "src/users", "app": "team-api", "format": tt.format, "base_url": "https://api.example.com/",
+			}))
+			if result["file_name"] != tt.fileName {
+				t.Errorf("file_name = %v, want %s", result["file_name"], tt.fileName)
+			}
+			content := result["content"].(string)
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+				t.Fatalf("%s collection is not JSON: %v", tt.format, err)
+			}
+			if !strings.Contains(content, "https://api.example.com\"") || strings.Contains(content, "https://api.example.com/\"") {
+				t.Errorf("base URL not set without its trailing slash:\n%s", content)
+			}
+			if !strings.Contains(content, `\"email\": \"user@example.com\"`) {
+				t.Errorf("example body missing:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestExportRequestsOutput(t *testing.T) {
+	s := setupTestServer(t)
+	writeUsersController(t, s)
+	root := filepath.Dir(s.basePath)
+	if err := os.MkdirAll(filepath.Join(root, "collections"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result := resultMap(t, mustCall(t, s, "export_requests", map[string]interface{}{"path": "src/users/users.controller.ts", "output": "collections"}))
+	if result["file"] != "collections/users.http" {
+		t.Errorf("file = %v, want the collection written into the directory", result["file"])
+	}
+	if _, ok := result["content"]; ok {
+		t.Error("content returned although the collection was written")
+	}
+	data, err := os.ReadFile(filepath.Join(root, "collections", "users.http"))
+	if err != nil || !strings.Contains(string(data), `"email": "user@example.com"`) {
+		t.Errorf("written collection = %q (%v), want the DTO example from the sibling dto directory", data, err)
+	}
+
+	if _, err := callTool(t, s, "export_requests", map[string]interface{}{"path": "src/users", "output": "../users.http"}); err == nil {
+		t.Error("output outside the project accepted")
+	}
+}
+
+func TestExportRequestsErrors(t *testing.T) {
+	s := setupTestServer(t)
+	writeUsersController(t, s)
+	writeProjectFile(t, s, "docs/README.md", "# Docs\n")
+
+	for _, params := range []map[string]interface{}{
+		{},
+		{"path": "missing"},
+		{"path": "docs"},
+		{"path": "src/users", "format": "curl"},
+	} {
+		if _, err := callTool(t, s, "export_requests", params); err == nil {
+			t.Errorf("%v accepted", params)
+		}
+	}
+}
+
+func TestListFeatureFlags(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "src/checkout.ts", `if (ldClient.variation('new-checkout', user, false)) {}
+if (unleash.isEnabled('dark-mode')) {}
+`)
+	writeProjectFile(t, s, "app/billing.rb", "return unless feature_enabled?(:invoice_v2)\n")
+	writeProjectFile(t, s, "ops/launchdarkly.yaml", "new-checkout:\n  on: true\nold-banner:\n  on: false\n")
+
+	// Without a flag file nothing can be reported missing
+	result := resultMap(t, mustCall(t, s, "list_feature_flags", map[string]interface{}{}))
+	if result["flag_count"] != 3 || result["hint"] == nil {
+		t.Fatalf("result = %v", result)
+	}
+	if w := result["warnings"].([]extractor.FlagWarning); len(w) != 0 {
+		t.Errorf("warnings without a flag source = %v", w)
+	}
+
+	if err := s.jsonStore.SaveConfig(&types.Config{
+		FeatureFlags: types.FeatureFlagConfig{Sources: []string{"ops/launchdarkly.yaml"}},
+	}); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	result = resultMap(t, mustCall(t, s, "list_feature_flags", map[string]interface{}{"missing_only": true}))
+	var keys []string
+	for _, f := range result["flags"].([]extractor.FeatureFlag) {
+		keys = append(keys, f.Key)
+	}
+	if want := []string{"dark-mode", "invoice_v2"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("missing flags = %v, want %v", keys, want)
+	}
+	if w := result["warnings"].([]extractor.FlagWarning); len(w) != 2 || w[1].File != "app/billing.rb" {
+		t.Errorf("warnings = %+v", w)
+	}
+
+	result = resultMap(t, mustCall(t, s, "list_feature_flags", map[string]interface{}{"provider": "launchdarkly"}))
+	flags := result["flags"].([]extractor.FeatureFlag)
+	if len(flags) != 1 || flags[0].Key != "new-checkout" || !reflect.DeepEqual(flags[0].DefinedIn, []string{"ops/launchdarkly.yaml"}) {
+		t.Errorf("launchdarkly flags = %+v", flags)
+	}
+	if unused := result["unused"].([]string); !reflect.DeepEqual(unused, []string{"old-banner"}) {
+		t.Errorf("unused = %v", unused)
+	}
+}
@@ -0,0 +1,264 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// INSIGHT REVIEW
+// Insights are quick notes. Reviewing them promotes the useful ones into
+// decisions or warnings and deletes the rest, so they don't pile up forever.
+// =============================================================================
+
+// insightReviewAge is how old an unreviewed insight gets before get_feed
+// reminds the team to review it
+const insightReviewAge = 14 * 24 * time.Hour
+
+// unreviewedInsight is an insight waiting for review, with its age
+type unreviewedInsight struct {
+	types.Insight
+	AgeDays int `json:"age_days"`
+}
+
+// handleListUnreviewedInsights lists insights that were neither promoted nor
+// dismissed, oldest first
+func (s *Server) handleListUnreviewedInsights(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		OlderThan string `json:"older_than"`
+		Feature   string `json:"feature"`
+		Limit     int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+
+	var before time.Time
+	if p.OlderThan != "" {
+		before = parseFeedSince(p.OlderThan)
+	}
+
+	insights, err := s.jsonStore.GetUnreviewedInsights()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var result []unreviewedInsight
+	stale := 0
+	for _, ins := range insights {
+		if p.Feature != "" && ins.Feature != p.Feature {
+			continue
+		}
+		if !before.IsZero() && ins.CreatedAt.After(before) {
+			continue
+		}
+		if now.Sub(ins.CreatedAt) >= insightReviewAge {
+			stale++
+		}
+		result = append(result, unreviewedInsight{Insight: ins, AgeDays: int(now.Sub(ins.CreatedAt).Hours() / 24)})
+	}
+	total := len(result)
+	if len(result) > p.Limit {
+		result = result[:p.Limit]
+	}
+
+	response := map[string]interface{}{
+		"insights": result,
+		"total":    total,
+		"stale":    stale,
+	}
+	if total > 0 {
+		response["hint"] = "Promote durable knowledge with promote_insight_to_decision or promote_insight_to_warning; remove the rest with dismiss_insight"
+	}
+	return response, nil
+}
+
+func (s *Server) handlePromoteInsightToDecision(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ID      string `json:"id"`
+		Content string `json:"content"`
+		Reason  string `json:"reason"`
+		Feature string `json:"feature"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	insight, err := s.insightForReview(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := types.Decision{
+		Content:      firstNonEmpty(p.Content, insight.Content),
+		Reason:       firstNonEmpty(p.Reason, insight.Context),
+		Feature:      firstNonEmpty(p.Feature, insight.Feature),
+		Author:       insight.Author,
+		Status:       "active",
+		RelatedFiles: insight.RelatedFiles,
+		Tags:         insight.Tags,
+		Language:     insight.Language,
+		Translations: insight.Translations,
+	}
+	if p.Reason != "" {
+		decision.Context = insight.Context
+	}
+	if decision.Reason == "" {
+		return nil, fmt.Errorf("reason is required: insight %s has no context to use as one", insight.ID)
+	}
+
+	raw, err := json.Marshal(decision)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.handleAddDecision(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.finishPromotion(insight, "decision", result)
+}
+
+func (s *Server) handlePromoteInsightToWarning(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ID       string `json:"id"`
+		Content  string `json:"content"`
+		Reason   string `json:"reason"`
+		Severity string `json:"severity"`
+		Feature  string `json:"feature"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	insight, err := s.insightForReview(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	warning := types.Warning{
+		Content:      firstNonEmpty(p.Content, insight.Content),
+		Reason:       firstNonEmpty(p.Reason, insight.Context),
+		Severity:     firstNonEmpty(p.Severity, "warning"),
+		Feature:      firstNonEmpty(p.Feature, insight.Feature),
+		Author:       insight.Author,
+		RelatedFiles: insight.RelatedFiles,
+		Tags:         insight.Tags,
+		Language:     insight.Language,
+		Translations: insight.Translations,
+	}
+	if p.Reason != "" {
+		warning.Evidence = insight.Context
+	}
+	if warning.Reason == "" {
+		return nil, fmt.Errorf("reason is required: insight %s has no context to use as one", insight.ID)
+	}
+
+	raw, err := json.Marshal(warning)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.handleAddWarning(raw)
+	if err != nil {
+		return nil, err
+	}
+	return s.finishPromotion(insight, "warning", result)
+}
+
+// finishPromotion marks the insight as promoted into the decision or warning
+// just created and drops its own search vector, so searches find the
+// promoted item instead of both
+func (s *Server) finishPromotion(insight *types.Insight, target string, created interface{}) (interface{}, error) {
+	result, _ := created.(map[string]interface{})
+	id, _ := result["id"].(string)
+	if err := s.jsonStore.PromoteInsight(insight.ID, id); err != nil {
+		return nil, err
+	}
+	s.sqliteIndex.DeleteSemanticVector(insight.ID)
+
+	result["type"] = target
+	result["insight_id"] = insight.ID
+	return result, nil
+}
+
+func (s *Server) handleDismissInsight(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		ID     string `json:"id"`
+		DryRun bool   `json:"dry_run"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	insight, err := s.insightForReview(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if p.DryRun {
+		return dryRunResult([]plannedChange{{Action: "delete", Kind: "insight", ID: insight.ID, Before: insight}}), nil
+	}
+
+	removed, err := s.jsonStore.DeleteInsight(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.sqliteIndex.DeleteSemanticVector(p.ID)
+
+	return map[string]interface{}{
+		"success": true,
+		"id":      removed.ID,
+		"content": removed.Content,
+	}, nil
+}
+
+// insightForReview looks up an insight that is still waiting for review
+func (s *Server) insightForReview(id string) (*types.Insight, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	insights, err := s.jsonStore.GetInsights()
+	if err != nil {
+		return nil, err
+	}
+	for i := range insights {
+		if insights[i].ID != id {
+			continue
+		}
+		if insights[i].PromotedTo != "" {
+			return nil, fmt.Errorf("insight %s was already promoted to %s", id, insights[i].PromotedTo)
+		}
+		return &insights[i], nil
+	}
+	return nil, fmt.Errorf("insight not found: %s", id)
+}
+
+// staleInsightCount counts unreviewed insights older than insightReviewAge
+func (s *Server) staleInsightCount() int {
+	insights, err := s.jsonStore.GetUnreviewedInsights()
+	if err != nil {
+		return 0
+	}
+	cutoff := time.Now().Add(-insightReviewAge)
+	count := 0
+	for _, ins := range insights {
+		if ins.CreatedAt.Before(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
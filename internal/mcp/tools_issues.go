@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/issues"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// ISSUE TRACKER LINKS
+// Tie decisions, warnings and features to Jira / GitHub tickets
+// =============================================================================
+
+// maxIssueRefreshes bounds tracker calls made while rendering one response
+const maxIssueRefreshes = 5
+
+// issueConfig returns the configured tracker, empty when none is set
+func (s *Server) issueConfig() types.IssueConfig {
+	cfg, err := s.jsonStore.GetConfig()
+	if err != nil || cfg == nil {
+		return types.IssueConfig{}
+	}
+	return cfg.Issues
+}
+
+// normalizeIssueKeys validates keys passed to add_* tools and returns their
+// canonical form, without contacting the tracker
+func (s *Server) normalizeIssueKeys(keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	cfg := s.issueConfig()
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		_, canonical, err := issues.ParseKey(key, cfg)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, canonical)
+	}
+	return uniqueStrings(out), nil
+}
+
+// handleLinkIssue validates an issue key against the tracker, caches its
+// title and status, and links it to a decision, warning or feature.
+func (s *Server) handleLinkIssue(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Key     string `json:"key"`
+		Type    string `json:"type"`
+		ID      string `json:"id"`
+		Refresh bool   `json:"refresh"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	if (p.Type == "") != (p.ID == "") {
+		return nil, fmt.Errorf("type and id must be given together")
+	}
+	if p.Type != "" && p.Type != "decision" && p.Type != "warning" && p.Type != "feature" {
+		return nil, fmt.Errorf("type must be one of: decision, warning, feature")
+	}
+
+	cfg := s.issueConfig()
+	provider, key, err := issues.ParseKey(p.Key, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, fetchErr := s.fetchIssue(provider, key, cfg, p.Refresh)
+	if errors.Is(fetchErr, issues.ErrNotFound) {
+		return nil, fmt.Errorf("%s does not exist in %s", key, provider)
+	}
+
+	if p.Type != "" {
+		if err := s.jsonStore.LinkIssue(p.Type, p.ID, key); err != nil {
+			return nil, err
+		}
+	}
+
+	result := map[string]interface{}{
+		"success": true,
+		"issue":   issue,
+	}
+	if p.Type != "" {
+		result["linked_to"] = p.Type + ":" + p.ID
+	}
+	if fetchErr != nil {
+		result["fetch_error"] = fetchErr.Error()
+		result["hint"] = "The link was stored without ticket details. Configure issues in .teamcontext/config.json and set JIRA_EMAIL/JIRA_API_TOKEN or GITHUB_TOKEN, then call again with refresh=true."
+	}
+	return result, nil
+}
+
+// fetchIssue returns cached metadata, fetching it when missing, stale or
+// forced. When the tracker can't be reached the cached or bare issue is
+// returned along with the error.
+func (s *Server) fetchIssue(provider, key string, cfg types.IssueConfig, force bool) (*types.Issue, error) {
+	cached := &types.Issue{Key: key, Provider: provider, URL: issues.LinkURL(provider, key, cfg)}
+	if all, err := s.jsonStore.GetIssues(); err == nil {
+		for i := range all {
+			if all[i].Key == key {
+				cached = &all[i]
+			}
+		}
+	}
+	if !force && !cached.FetchedAt.IsZero() && time.Since(cached.FetchedAt) < issues.StaleAfter {
+		return cached, nil
+	}
+
+	fetched, err := issues.Fetch(provider, key, cfg)
+	if err != nil {
+		if cached.FetchedAt.IsZero() && !errors.Is(err, issues.ErrNotFound) {
+			s.jsonStore.SaveIssue(cached)
+		}
+		return cached, err
+	}
+	s.jsonStore.SaveIssue(fetched)
+	return fetched, nil
+}
+
+// linkedIssues returns the tickets for keys with their status. With a
+// tracker configured, a few stale entries are refreshed from its API.
+func (s *Server) linkedIssues(keys []string) []types.Issue {
+	if len(keys) == 0 {
+		return nil
+	}
+	cfg := s.issueConfig()
+	cached := make(map[string]types.Issue)
+	if all, err := s.jsonStore.GetIssues(); err == nil {
+		for _, issue := range all {
+			cached[issue.Key] = issue
+		}
+	}
+
+	refreshes := 0
+	var out []types.Issue
+	for _, key := range uniqueStrings(keys) {
+		issue, ok := cached[key]
+		stale := !ok || time.Since(issue.FetchedAt) >= issues.StaleAfter
+		if stale && cfg.Provider != "" && refreshes < maxIssueRefreshes {
+			if provider, canonical, err := issues.ParseKey(key, cfg); err == nil {
+				refreshes++
+				if fetched, _ := s.fetchIssue(provider, canonical, cfg, true); fetched != nil {
+					issue, ok = *fetched, true
+				}
+			}
+		}
+		if !ok {
+			issue = types.Issue{Key: key}
+		}
+		out = append(out, issue)
+	}
+	return out
+}
@@ -2,15 +2,18 @@ package mcp
 
 import (
 "encoding/json"
+"errors"
 "fmt"
 "os"
-"path/filepath"
+"path"
 "regexp"
 "strings"
 "time"
 
+"github.com/saeedalam/teamcontext/internal/relpath"
 "github.com/saeedalam/teamcontext/internal/skeleton"
 "github.com/saeedalam/teamcontext/internal/storage"
+"github.com/saeedalam/teamcontext/internal/tokenest"
 "github.com/saeedalam/teamcontext/pkg/types"
 )
 
@@ -31,6 +34,28 @@ func (s *Server) handleIndexFile(params json.RawMessage) (interface{}, error) {
 	if file.Summary == "" {
 		return nil, fmt.Errorf("summary is required")
 	}
+	file.RelatedFiles = relpath.NormalizeAll(file.RelatedFiles)
+	file.SummarySource = types.SummarySourceAgent
+	if file.SummaryAuthor == "" {
+		file.SummaryAuthor = s.currentAuthor()
+	}
+	summarizedAt := time.Now()
+	file.SummarizedAt = &summarizedAt
+
+	// A summary-only call rewrites the summary and keeps what the indexer
+	// found; import edges are only created for imports the caller sent
+	callerImports := file.Imports
+	if existing, err := s.jsonStore.GetFileIndex(file.Path); err == nil {
+		if len(file.Exports) == 0 {
+			file.Exports = existing.Exports
+		}
+		if len(file.Imports) == 0 {
+			file.Imports = existing.Imports
+		}
+		if file.Language == "" {
+			file.Language = existing.Language
+		}
+	}
 
 	// Save to JSON store
 	if err := s.jsonStore.SaveFileIndex(&file); err != nil {
@@ -45,11 +70,11 @@ func (s *Server) handleIndexFile(params json.RawMessage) (interface{}, error) {
 
 	// Auto-create import edges from the imports array
 	importEdgesCreated := 0
-	for _, imp := range file.Imports {
+	for _, imp := range callerImports {
 		// Resolve relative imports
 		importPath := imp
 		if strings.HasPrefix(imp, ".") {
-			importPath = filepath.Clean(filepath.Join(filepath.Dir(file.Path), imp))
+			importPath = path.Join(path.Dir(file.Path), imp)
 		}
 
 		// Create "imports" edge
@@ -82,6 +107,46 @@ func (s *Server) handleIndexFile(params json.RawMessage) (interface{}, error) {
 
 		var chunks []storage.CodeChunk
 
+		// Notebooks are chunked in their script form, one chunk per code cell
+		// and one doc chunk per markdown cell
+		if language == "jupyter" {
+			if nb, err := skeleton.ParseNotebook(content); err == nil {
+				lines = strings.Split(nb.Script(), "\n")
+				next := 1
+				for _, cell := range nb.Cells {
+					if cell.Kind == "markdown" && strings.TrimSpace(cell.Source) != "" {
+						chunks = append(chunks, storage.CodeChunk{
+							FilePath:  file.Path,
+							ChunkType: "doc",
+							ChunkName: fmt.Sprintf("cell %d", cell.Index),
+							StartLine: next,
+							EndLine:   next,
+							Content:   strings.TrimSpace(cell.Source),
+							Language:  "markdown",
+						})
+						continue
+					}
+					if cell.Kind != "code" {
+						continue
+					}
+					next = cell.Line + cell.Lines
+					if strings.TrimSpace(cell.Source) == "" {
+						continue
+					}
+					endLine := cell.Line + cell.Lines - 1
+					chunks = append(chunks, storage.CodeChunk{
+						FilePath:  file.Path,
+						ChunkType: "cell",
+						ChunkName: fmt.Sprintf("cell %d", cell.Index),
+						StartLine: cell.Line,
+						EndLine:   endLine,
+						Content:   getLines(lines, cell.Line, endLine),
+						Language:  language,
+					})
+				}
+			}
+		}
+
 		// Try semantic chunks first
 		if sk, err := skeleton.ParseFile(file.Path); err == nil && sk != nil {
 			for _, fn := range sk.Functions {
@@ -113,7 +178,7 @@ func (s *Server) handleIndexFile(params json.RawMessage) (interface{}, error) {
 		}
 
 		// Add line-based chunks for uncovered code
-		if len(chunks) == 0 || len(lines) > len(chunks)*chunkSize*2 {
+		if language != "jupyter" && (len(chunks) == 0 || len(lines) > len(chunks)*chunkSize*2) {
 			for i := 0; i < len(lines); i += chunkSize {
 				endLine := i + chunkSize
 				if endLine > len(lines) {
@@ -151,6 +216,16 @@ func (s *Server) handleIndexFile(params json.RawMessage) (interface{}, error) {
 	}, nil
 }
 
+// duplicateResult answers a retried add with the item first recorded,
+// leaving the graph, timeline and search index as they are
+func duplicateResult(id string, createdAt time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         id,
+		"created_at": createdAt,
+		"duplicate":  true,
+	}
+}
+
 func (s *Server) handleAddDecision(params json.RawMessage) (interface{}, error) {
 	var decision types.Decision
 	if err := json.Unmarshal(params, &decision); err != nil {
@@ -163,9 +238,23 @@ func (s *Server) handleAddDecision(params json.RawMessage) (interface{}, error)
 	if decision.Reason == "" {
 		return nil, fmt.Errorf("reason is required")
 	}
+	decision.Language = normalizeLanguage(decision.Language)
+	decision.TranslatedFrom = ""
+	decision.RelatedFiles = relpath.NormalizeAll(decision.RelatedFiles)
+	issueKeys, err := s.normalizeIssueKeys(decision.Issues)
+	if err != nil {
+		return nil, err
+	}
+	decision.Issues = issueKeys
+	if decision.Author == "" {
+		decision.Author = s.currentAuthor()
+	}
 
 	// Save to JSON store (generates ID)
 	if err := s.jsonStore.AddDecision(&decision); err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return duplicateResult(decision.ID, decision.CreatedAt), nil
+		}
 		return nil, err
 	}
 
@@ -174,6 +263,7 @@ func (s *Server) handleAddDecision(params json.RawMessage) (interface{}, error)
 
 	// Add to knowledge graph
 	s.addDecisionEdges(&decision)
+	mentions, mentionEdges := s.addDecisionMentionEdges(&decision)
 
 	// Add evolution event for significant decisions
 	s.jsonStore.AddEvolutionEvent(&types.EvolutionEvent{
@@ -186,13 +276,17 @@ func (s *Server) handleAddDecision(params json.RawMessage) (interface{}, error)
 	})
 
 	// Store semantic vector for TF-IDF search
-	s.storeSemanticVector(decision.ID, "decision", decision.Content+" "+decision.Reason+" "+decision.Context)
+	s.storeSemanticVector(decision.ID, "decision", knowledgeText(decision.Translations, decision.Content, decision.Reason, decision.Context))
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"id":         decision.ID,
 		"created_at": decision.CreatedAt,
-		"graph_edges_created": len(decision.RelatedFiles) + len(decision.RelatedDecisions) + 1,
-	}, nil
+		"graph_edges_created": len(decision.RelatedFiles) + len(decision.RelatedDecisions) + 1 + mentionEdges,
+	}
+	if len(mentions) > 0 {
+		result["linked_symbols"] = mentions
+	}
+	return result, nil
 }
 
 func (s *Server) handleAddWarning(params json.RawMessage) (interface{}, error) {
@@ -207,9 +301,34 @@ func (s *Server) handleAddWarning(params json.RawMessage) (interface{}, error) {
 	if warning.Reason == "" {
 		return nil, fmt.Errorf("reason is required")
 	}
+	warning.Language = normalizeLanguage(warning.Language)
+	warning.TranslatedFrom = ""
+	warning.RelatedFiles = relpath.NormalizeAll(warning.RelatedFiles)
+	issueKeys, err := s.normalizeIssueKeys(warning.Issues)
+	if err != nil {
+		return nil, err
+	}
+	warning.Issues = issueKeys
+	anchors, err := s.captureAnchors(warning.Anchors)
+	if err != nil {
+		return nil, err
+	}
+	warning.Anchors = anchors
+	// Anchored files are related files, so file-level lookups still find it
+	for _, a := range anchors {
+		if !containsString(warning.RelatedFiles, a.File) {
+			warning.RelatedFiles = append(warning.RelatedFiles, a.File)
+		}
+	}
+	if warning.Author == "" {
+		warning.Author = s.currentAuthor()
+	}
 
 	// Save to JSON store (generates ID)
 	if err := s.jsonStore.AddWarning(&warning); err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return duplicateResult(warning.ID, warning.CreatedAt), nil
+		}
 		return nil, err
 	}
 
@@ -232,7 +351,7 @@ func (s *Server) handleAddWarning(params json.RawMessage) (interface{}, error) {
 	}
 
 	// Store semantic vector for TF-IDF search
-	s.storeSemanticVector(warning.ID, "warning", warning.Content+" "+warning.Reason+" "+warning.Evidence)
+	s.storeSemanticVector(warning.ID, "warning", knowledgeText(warning.Translations, warning.Content, warning.Reason, warning.Evidence))
 
 	return map[string]interface{}{
 		"id":         warning.ID,
@@ -250,8 +369,17 @@ func (s *Server) handleAddInsight(params json.RawMessage) (interface{}, error) {
 	if insight.Content == "" {
 		return nil, fmt.Errorf("content is required")
 	}
+	insight.Language = normalizeLanguage(insight.Language)
+	insight.TranslatedFrom = ""
+	insight.RelatedFiles = relpath.NormalizeAll(insight.RelatedFiles)
+	if insight.Author == "" {
+		insight.Author = s.currentAuthor()
+	}
 
 	if err := s.jsonStore.AddInsight(&insight); err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return duplicateResult(insight.ID, insight.CreatedAt), nil
+		}
 		return nil, err
 	}
 
@@ -266,7 +394,7 @@ func (s *Server) handleAddInsight(params json.RawMessage) (interface{}, error) {
 	})
 
 	// Store semantic vector for TF-IDF search
-	s.storeSemanticVector(insight.ID, "insight", insight.Content+" "+insight.Context)
+	s.storeSemanticVector(insight.ID, "insight", knowledgeText(insight.Translations, insight.Content, insight.Context))
 
 	return map[string]interface{}{
 		"id":         insight.ID,
@@ -274,6 +402,71 @@ func (s *Server) handleAddInsight(params json.RawMessage) (interface{}, error) {
 	}, nil
 }
 
+// handleAddTranslation stores a translated variant of a decision, warning
+// or insight, served to agents that ask for that language.
+func (s *Server) handleAddTranslation(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Type     string `json:"type"`
+		ID       string `json:"id"`
+		Language string `json:"language"`
+		types.Translation
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	p.Language = normalizeLanguage(p.Language)
+	if p.ID == "" || p.Language == "" {
+		return nil, fmt.Errorf("id and language are required")
+	}
+	if p.Content == "" && p.Reason == "" && p.Context == "" && p.Evidence == "" {
+		return nil, fmt.Errorf("at least one of content, reason, context or evidence is required")
+	}
+	if !containsString(knowledgeTypes, p.Type) {
+		return nil, fmt.Errorf("type must be one of: %s", strings.Join(knowledgeTypes, ", "))
+	}
+
+	if err := s.jsonStore.AddTranslation(p.Type, p.ID, p.Language, p.Translation); err != nil {
+		return nil, err
+	}
+
+	// Refresh the semantic vector so queries in the new language match
+	var languages []string
+	switch p.Type {
+	case "decision":
+		decisions, _ := s.jsonStore.GetDecisions()
+		for _, d := range decisions {
+			if d.ID == p.ID {
+				s.storeSemanticVector(d.ID, "decision", knowledgeText(d.Translations, d.Content, d.Reason, d.Context))
+				languages = translationLanguages(d.Translations)
+			}
+		}
+	case "warning":
+		warnings, _ := s.jsonStore.GetWarnings()
+		for _, w := range warnings {
+			if w.ID == p.ID {
+				s.storeSemanticVector(w.ID, "warning", knowledgeText(w.Translations, w.Content, w.Reason, w.Evidence))
+				languages = translationLanguages(w.Translations)
+			}
+		}
+	case "insight":
+		insights, _ := s.jsonStore.GetInsights()
+		for _, i := range insights {
+			if i.ID == p.ID {
+				s.storeSemanticVector(i.ID, "insight", knowledgeText(i.Translations, i.Content, i.Context))
+				languages = translationLanguages(i.Translations)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"success":      true,
+		"id":           p.ID,
+		"language":     p.Language,
+		"translations": languages,
+	}, nil
+}
+
 
 func (s *Server) handleSaveConversation(params json.RawMessage) (interface{}, error) {
 	var conv types.Conversation
@@ -287,6 +480,9 @@ func (s *Server) handleSaveConversation(params json.RawMessage) (interface{}, er
 	if conv.Summary == "" {
 		return nil, fmt.Errorf("summary is required")
 	}
+	if conv.Author == "" {
+		conv.Author = s.currentAuthor()
+	}
 
 	if err := s.jsonStore.SaveConversation(&conv); err != nil {
 		return nil, err
@@ -329,8 +525,7 @@ func (s *Server) handleCompactConversation(params json.RawMessage) (interface{},
 		return nil, fmt.Errorf("full_text is required")
 	}
 
-	// Count approximate tokens (rough estimate: 4 chars per token)
-	originalTokens := len(p.FullText) / 4
+	originalTokens := tokenest.Count(p.FullText)
 
 	// Create a compaction record
 	compaction := types.ConversationCompaction{
@@ -412,10 +607,12 @@ func (s *Server) handleUpdateFeatureState(params json.RawMessage) (interface{},
 
 func (s *Server) handleStartFeature(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		ID          string `json:"id"`
-		Branch      string `json:"branch"`
-		Extends     string `json:"extends"`
-		Description string `json:"description"`
+		ID          string   `json:"id"`
+		Branch      string   `json:"branch"`
+		Extends     string   `json:"extends"`
+		Description string   `json:"description"`
+		TokenBudget int      `json:"token_budget"`
+		Issues      []string `json:"issues"`
 	}
 	if err := json.Unmarshal(params, &p); err != nil {
 		return nil, err
@@ -424,17 +621,24 @@ func (s *Server) handleStartFeature(params json.RawMessage) (interface{}, error)
 	if p.ID == "" {
 		return nil, fmt.Errorf("id is required")
 	}
+	issueKeys, err := s.normalizeIssueKeys(p.Issues)
+	if err != nil {
+		return nil, err
+	}
 
 	feature := &types.Feature{
 		ID:          p.ID,
 		Branch:      p.Branch,
 		Extends:     p.Extends,
 		Description: p.Description,
+		TokenBudget: p.TokenBudget,
+		Issues:      issueKeys,
 	}
 
 	if err := s.jsonStore.CreateFeature(feature); err != nil {
 		return nil, err
 	}
+	s.forgetFeatureBudget(feature.ID)
 
 	// Inherit parent context if extends is set
 	inherited := 0
@@ -485,7 +689,9 @@ func (s *Server) handleStartFeature(params json.RawMessage) (interface{}, error)
 
 func (s *Server) handleArchiveFeature(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		ID string `json:"id"`
+		ID      string `json:"id"`
+		Summary string `json:"summary"`
+		DryRun  bool   `json:"dry_run"`
 	}
 	if err := json.Unmarshal(params, &p); err != nil {
 		return nil, err
@@ -498,6 +704,36 @@ func (s *Server) handleArchiveFeature(params json.RawMessage) (interface{}, erro
 	// Get feature to count decisions/warnings
 	decisions, _ := s.jsonStore.GetDecisionsByFeature(p.ID)
 
+	if p.DryRun {
+		feature, err := s.jsonStore.GetFeature(p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("feature not found: %s", p.ID)
+		}
+		conversations, _ := s.jsonStore.GetConversations(p.ID)
+		result := dryRunResult([]plannedChange{
+			{
+				Action: "archive", Kind: "feature", ID: p.ID,
+				Detail: fmt.Sprintf("features/%s/ moves to archive/%s/ with %d conversations; recall_feature brings it back", p.ID, p.ID, len(conversations)),
+				Before: feature.Status, After: "archived",
+			},
+			{Action: "create", Kind: "evolution_event", Detail: "milestone: Feature archived: " + p.ID},
+		})
+		result["decisions_preserved"] = len(decisions)
+		return result, nil
+	}
+
+	// Kept with the archived feature, and shown when it is recalled
+	if p.Summary != "" {
+		feature, err := s.jsonStore.GetFeature(p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("feature not found: %s", p.ID)
+		}
+		feature.ArchiveSummary = p.Summary
+		if err := s.jsonStore.UpdateFeature(feature); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.jsonStore.ArchiveFeature(p.ID); err != nil {
 		return nil, err
 	}
@@ -521,10 +757,16 @@ func (s *Server) handleUpdateProject(params json.RawMessage) (interface{}, error
 	if err := json.Unmarshal(params, &project); err != nil {
 		return nil, err
 	}
+	var opts struct {
+		DryRun bool `json:"dry_run"`
+	}
+	json.Unmarshal(params, &opts)
 
-	// Get existing project to merge
-	existing, _ := s.jsonStore.GetProject()
-	if existing != nil {
+	// Fields the call leaves out keep their current values
+	merge := func(existing *types.Project) {
+		if existing == nil {
+			return
+		}
 		if project.Name == "" {
 			project.Name = existing.Name
 		}
@@ -534,11 +776,28 @@ func (s *Server) handleUpdateProject(params json.RawMessage) (interface{}, error
 		if len(project.Languages) == 0 {
 			project.Languages = existing.Languages
 		}
+		if len(project.Frameworks) == 0 {
+			project.Frameworks = existing.Frameworks
+		}
+	}
+
+	if opts.DryRun {
+		existing, _ := s.jsonStore.GetProject()
+		merge(existing)
+		return dryRunResult(fieldChanges("project", "", existing, &project)), nil
 	}
 
-	if err := s.jsonStore.SaveProject(&project); err != nil {
+	// Merge and save in one step, so frameworks the watcher detects in the
+	// meantime aren't lost
+	saved, err := s.jsonStore.UpdateProject(func(current *types.Project) bool {
+		merge(current)
+		*current = project
+		return true
+	})
+	if err != nil {
 		return nil, err
 	}
+	project = *saved
 
 	// Add evolution event for project update
 	s.jsonStore.AddEvolutionEvent(&types.EvolutionEvent{
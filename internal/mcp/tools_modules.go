@@ -0,0 +1,504 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// MODULE BOUNDARIES
+// Bounded contexts inferred from the directory layout, documented services
+// and workspaces, merged where import cycles or co-changes show two
+// directories are really one module. Reports each module's public entry
+// points and imports reaching past them.
+// =============================================================================
+
+// moduleMergeMinLinks is how many imports and co-changes two directories
+// need between them before they are considered one module
+const moduleMergeMinLinks = 3
+
+// barrelNames are files that make up a module's public surface when they
+// sit at its root
+var barrelNames = map[string]bool{
+	"index.ts": true, "index.tsx": true, "index.js": true, "index.mjs": true,
+	"__init__.py": true, "mod.rs": true, "lib.rs": true,
+	"public-api.ts": true, "public_api.ts": true,
+}
+
+// moduleBoundary is one inferred module
+type moduleBoundary struct {
+	Name            string        `json:"name"`
+	Kind            string        `json:"kind"` // service, workspace or directory
+	Paths           []string      `json:"paths"`
+	Files           int           `json:"files"`
+	EntryPoints     []moduleEntry `json:"entry_points,omitempty"`
+	InternalImports int           `json:"internal_imports"`
+	Cohesion        float64       `json:"cohesion"` // internal imports over all its imports
+	DependsOn       []moduleLink  `json:"depends_on,omitempty"`
+	UsedBy          []moduleLink  `json:"used_by,omitempty"`
+	Violations      int           `json:"violations"`
+	MergeReason     string        `json:"merge_reason,omitempty"`
+}
+
+// moduleEntry is a file of a module's public surface
+type moduleEntry struct {
+	Path       string `json:"path"`
+	ImportedBy int    `json:"imported_by"` // files of other modules importing it
+}
+
+// moduleLink counts the imports from one module into another
+type moduleLink struct {
+	Module  string `json:"module"`
+	Imports int    `json:"imports"`
+}
+
+// moduleViolation is an import reaching past another module's surface
+type moduleViolation struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	FromModule string `json:"from_module"`
+	ToModule   string `json:"to_module"`
+	Reason     string `json:"reason"`
+}
+
+// moduleImport is an import between two indexed files, or of a Go package
+// directory, with the candidates on both sides
+type moduleImport struct {
+	from, to       string
+	toDir          string
+	fromMod, toMod *moduleCandidate
+}
+
+// moduleCandidate is a directory, service or workspace before merging
+type moduleCandidate struct {
+	name     string
+	kind     string
+	root     string
+	files    []string
+	barrels  []string // barrel files at the root
+	rootOnly []string // files directly in the root
+	parent   *moduleCandidate
+}
+
+// top is the candidate's merged module
+func (c *moduleCandidate) top() *moduleCandidate {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c
+}
+
+// surface reports whether a file of the module may be imported from other
+// modules, and why not when it may not. Modules with neither a barrel nor
+// files at their root have no recognizable surface, so nothing is flagged.
+func (c *moduleCandidate) surface(target, targetDir string) (bool, string) {
+	if len(c.barrels) > 0 {
+		// A Go package import of the root directory is the surface too
+		if target == c.root {
+			return true, ""
+		}
+		for _, b := range c.barrels {
+			if target == b {
+				return true, ""
+			}
+		}
+		return false, "bypasses " + path.Base(c.barrels[0]) + " of " + c.name
+	}
+	if len(c.rootOnly) > 0 && targetDir != c.root {
+		return false, "reaches into " + strings.TrimPrefix(targetDir, c.root+"/") + " inside " + c.name
+	}
+	return true, ""
+}
+
+// handleGetModuleBoundaries infers the modules of the project from its
+// layout, import density and co-change history.
+func (s *Server) handleGetModuleBoundaries(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path   string `json:"path"`
+		Module string `json:"module"`
+		Depth  int    `json:"depth"`
+		Limit  int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Depth <= 0 {
+		p.Depth = 2
+	}
+	if p.Depth > 4 {
+		p.Depth = 4
+	}
+	if p.Limit <= 0 {
+		p.Limit = 50
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	scope := relpath.FromAbs(projectRoot, p.Path)
+
+	files, err := s.jsonStore.GetFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+	var services []types.ServiceNode
+	if arch, err := s.jsonStore.GetArchitecture(); err == nil && arch != nil {
+		services = arch.Services
+	}
+
+	// Directories holding files themselves; the shallowest of them names the
+	// module of everything below it, so billing/internal/ stays in billing/
+	hasFiles := make(map[string]bool)
+	for f, fi := range files {
+		if fi.DeletedAt == nil {
+			hasFiles[path.Dir(relpath.Normalize(f))] = true
+		}
+	}
+
+	// Candidates: documented services, then workspaces, then directories
+	candidates := make(map[string]*moduleCandidate)
+	candidateOf := func(file, dir string) *moduleCandidate {
+		for _, svc := range services {
+			for _, f := range svc.Files {
+				if root := relpath.Normalize(f); root != "" && (relpath.Within(file, root) || relpath.Within(dir, root)) {
+					if c, ok := candidates["service:"+svc.Name]; ok {
+						return c
+					}
+					c := &moduleCandidate{name: svc.Name, kind: "service", root: root}
+					candidates["service:"+svc.Name] = c
+					return c
+				}
+			}
+		}
+		kind, root := "workspace", serviceOf(dir+"/_")
+		if root == "" {
+			if dir == "" || dir == "." {
+				return nil
+			}
+			parts := strings.Split(dir, "/")
+			if len(parts) > p.Depth {
+				parts = parts[:p.Depth]
+			}
+			for n := 1; n < len(parts); n++ {
+				if hasFiles[strings.Join(parts[:n], "/")] {
+					parts = parts[:n]
+					break
+				}
+			}
+			kind, root = "directory", strings.Join(parts, "/")
+		}
+		if c, ok := candidates[root]; ok {
+			return c
+		}
+		c := &moduleCandidate{name: root, kind: kind, root: root}
+		candidates[root] = c
+		return c
+	}
+
+	fileModule := make(map[string]*moduleCandidate)
+	for f, fi := range files {
+		if fi.DeletedAt != nil {
+			continue
+		}
+		f = relpath.Normalize(f)
+		dir := path.Dir(f)
+		c := candidateOf(f, dir)
+		if c == nil {
+			continue
+		}
+		fileModule[f] = c
+		c.files = append(c.files, f)
+		if dir == c.root {
+			c.rootOnly = append(c.rootOnly, f)
+			if barrelNames[path.Base(f)] {
+				c.barrels = append(c.barrels, f)
+			}
+		}
+	}
+	if len(fileModule) == 0 {
+		return nil, fmt.Errorf("no indexed files in directories; run index first")
+	}
+
+	// Resolve imports to the candidate and directory they land in. Go
+	// package imports name a directory rather than a file.
+	var links []moduleImport
+	for from, targets := range s.projectImports(files, projectRoot) {
+		from = relpath.Normalize(from)
+		fromMod := fileModule[from]
+		if fromMod == nil {
+			continue
+		}
+		for _, to := range uniqueStrings(targets) {
+			to = relpath.Normalize(to)
+			toDir := path.Dir(to)
+			toMod, ok := fileModule[to]
+			if !ok {
+				toDir = to
+				if toMod = candidateOf(to+"/_", to); toMod == nil || len(toMod.files) == 0 {
+					continue
+				}
+			}
+			links = append(links, moduleImport{from: from, to: to, toDir: toDir, fromMod: fromMod, toMod: toMod})
+		}
+	}
+
+	// Cached co-changes only; this tool does not run git
+	var correlations []git.FileCorrelation
+	s.loadGitKnowledge("git-correlations.json", &correlations)
+
+	// Merge directories that import each other, or keep changing together,
+	// more than they hang together on their own
+	pairKey := func(a, b *moduleCandidate) [2]*moduleCandidate {
+		if a.name > b.name {
+			a, b = b, a
+		}
+		return [2]*moduleCandidate{a, b}
+	}
+	internal := make(map[*moduleCandidate]int)
+	imports := make(map[[2]*moduleCandidate][2]int) // per direction, in pair order
+	coChanges := make(map[[2]*moduleCandidate]int)
+	for _, l := range links {
+		if l.fromMod == l.toMod {
+			internal[l.fromMod]++
+			continue
+		}
+		key := pairKey(l.fromMod, l.toMod)
+		counts := imports[key]
+		if key[0] == l.fromMod {
+			counts[0]++
+		} else {
+			counts[1]++
+		}
+		imports[key] = counts
+	}
+	coChangePairs := 0
+	for _, c := range correlations {
+		a, b := fileModule[relpath.Normalize(c.File1)], fileModule[relpath.Normalize(c.File2)]
+		if a == nil || b == nil {
+			continue
+		}
+		coChangePairs++
+		if a == b {
+			internal[a]++
+		} else {
+			coChanges[pairKey(a, b)]++
+		}
+	}
+
+	type mergeCandidate struct {
+		a, b             *moduleCandidate
+		imports, changes int
+		bothWays         bool
+	}
+	var merges []mergeCandidate
+	seenPairs := make(map[[2]*moduleCandidate]bool)
+	for key := range imports {
+		seenPairs[key] = true
+	}
+	for key := range coChanges {
+		seenPairs[key] = true
+	}
+	for key := range seenPairs {
+		a, b := key[0], key[1]
+		if a.kind != "directory" || b.kind != "directory" {
+			continue
+		}
+		counts := imports[key]
+		m := mergeCandidate{a: a, b: b, imports: counts[0] + counts[1], changes: coChanges[key], bothWays: counts[0] > 0 && counts[1] > 0}
+		total := m.imports + m.changes
+		if (!m.bothWays && m.changes < 2) || total < moduleMergeMinLinks || total <= min(internal[a], internal[b]) {
+			continue
+		}
+		merges = append(merges, m)
+	}
+	sort.Slice(merges, func(i, j int) bool {
+		ti, tj := merges[i].imports+merges[i].changes, merges[j].imports+merges[j].changes
+		if ti != tj {
+			return ti > tj
+		}
+		return merges[i].a.name+merges[i].b.name < merges[j].a.name+merges[j].b.name
+	})
+	reasons := make(map[*moduleCandidate][]string)
+	for _, m := range merges {
+		a, b := m.a.top(), m.b.top()
+		if a == b {
+			continue
+		}
+		// The larger side names the module
+		if len(b.files) > len(a.files) || len(b.files) == len(a.files) && b.name < a.name {
+			a, b = b, a
+		}
+		b.parent = a
+		a.files = append(a.files, b.files...)
+		var why []string
+		if m.bothWays {
+			why = append(why, fmt.Sprintf("%d imports both ways", m.imports))
+		} else if m.imports > 0 {
+			why = append(why, fmt.Sprintf("%d imports", m.imports))
+		}
+		if m.changes > 0 {
+			why = append(why, fmt.Sprintf("%d file pairs changing together", m.changes))
+		}
+		reasons[a] = append(reasons[a], reasons[b]...)
+		reasons[a] = append(reasons[a], fmt.Sprintf("%s and %s: %s", m.a.name, m.b.name, strings.Join(why, ", ")))
+	}
+
+	// Build the modules from the merged candidates
+	modules := make(map[*moduleCandidate]*moduleBoundary)
+	for _, c := range candidates {
+		if len(c.files) == 0 {
+			continue
+		}
+		t := c.top()
+		m, ok := modules[t]
+		if !ok {
+			m = &moduleBoundary{Name: t.name, Kind: t.kind, Files: len(t.files)}
+			if len(reasons[t]) > 0 {
+				m.MergeReason = strings.Join(reasons[t], "; ")
+			}
+			modules[t] = m
+		}
+		m.Paths = append(m.Paths, c.root)
+	}
+
+	type entryUse struct {
+		mod       *moduleCandidate
+		importers map[string]bool
+	}
+	entries := make(map[string]*entryUse) // surface file -> files of other modules importing it
+	ownImports := make(map[*moduleCandidate]int)
+	dependsOn := make(map[*moduleCandidate]map[string]int)
+	usedBy := make(map[*moduleCandidate]map[string]int)
+	external := make(map[*moduleCandidate]int)
+	var violations []moduleViolation
+	for _, l := range links {
+		from, to := l.fromMod.top(), l.toMod.top()
+		if from == to {
+			ownImports[from]++
+			continue
+		}
+		external[from]++
+		if dependsOn[from] == nil {
+			dependsOn[from] = make(map[string]int)
+		}
+		dependsOn[from][to.name]++
+		if usedBy[to] == nil {
+			usedBy[to] = make(map[string]int)
+		}
+		usedBy[to][from.name]++
+
+		if ok, reason := l.toMod.surface(l.to, l.toDir); !ok {
+			modules[from].Violations++
+			violations = append(violations, moduleViolation{From: l.from, To: l.to, FromModule: from.name, ToModule: to.name, Reason: reason})
+		} else {
+			if entries[l.to] == nil {
+				entries[l.to] = &entryUse{mod: l.toMod, importers: make(map[string]bool)}
+			}
+			entries[l.to].importers[l.from] = true
+		}
+	}
+
+	var result []*moduleBoundary
+	for t, m := range modules {
+		sort.Strings(m.Paths)
+		m.InternalImports = ownImports[t]
+		if total := m.InternalImports + external[t]; total > 0 {
+			m.Cohesion = math.Round(float64(m.InternalImports)/float64(total)*100) / 100
+		}
+		m.DependsOn = sortedModuleLinks(dependsOn[t])
+		m.UsedBy = sortedModuleLinks(usedBy[t])
+		for target, use := range entries {
+			if use.mod.top() == t {
+				m.EntryPoints = append(m.EntryPoints, moduleEntry{Path: target, ImportedBy: len(use.importers)})
+			}
+		}
+		sort.Slice(m.EntryPoints, func(i, j int) bool {
+			if m.EntryPoints[i].ImportedBy != m.EntryPoints[j].ImportedBy {
+				return m.EntryPoints[i].ImportedBy > m.EntryPoints[j].ImportedBy
+			}
+			return m.EntryPoints[i].Path < m.EntryPoints[j].Path
+		})
+		if len(m.EntryPoints) > 10 {
+			m.EntryPoints = m.EntryPoints[:10]
+		}
+
+		inScope := scope == ""
+		for _, root := range m.Paths {
+			if relpath.Within(root, scope) || relpath.Within(scope, root) {
+				inScope = true
+			}
+		}
+		if inScope && (p.Module == "" || m.Name == p.Module) {
+			result = append(result, m)
+		}
+	}
+	if p.Module != "" && len(result) == 0 {
+		return nil, fmt.Errorf("module %s not found; call get_module_boundaries without module to list them", p.Module)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Files != result[j].Files {
+			return result[i].Files > result[j].Files
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	// Violations of the listed modules, either way
+	listed := make(map[string]bool, len(result))
+	for _, m := range result {
+		listed[m.Name] = true
+	}
+	var shown []moduleViolation
+	for _, v := range violations {
+		if listed[v.FromModule] || listed[v.ToModule] {
+			shown = append(shown, v)
+		}
+	}
+	sort.Slice(shown, func(i, j int) bool {
+		if shown[i].From != shown[j].From {
+			return shown[i].From < shown[j].From
+		}
+		return shown[i].To < shown[j].To
+	})
+	violationCount := len(shown)
+	if len(shown) > p.Limit {
+		shown = shown[:p.Limit]
+	}
+
+	response := map[string]interface{}{
+		"modules":         result,
+		"module_count":    len(result),
+		"violations":      shown,
+		"violation_count": violationCount,
+		"signals": map[string]int{
+			"imports":         len(links),
+			"co_change_pairs": coChangePairs,
+		},
+		"note": "A module's surface is its index/__init__/mod.rs/lib.rs file when it has one, else the files at its root; imports of other files from outside the module are violations",
+	}
+	if coChangePairs == 0 {
+		response["hint"] = "No co-change history cached: run git analysis (teamcontext init) so directories that change together can be grouped"
+	}
+	return response, nil
+}
+
+// sortedModuleLinks lists import counts by module, most imports first
+func sortedModuleLinks(counts map[string]int) []moduleLink {
+	links := make([]moduleLink, 0, len(counts))
+	for name, n := range counts {
+		links = append(links, moduleLink{Module: name, Imports: n})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Imports != links[j].Imports {
+			return links[i].Imports > links[j].Imports
+		}
+		return links[i].Module < links[j].Module
+	})
+	return links
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestGetModuleBoundaries(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "go.mod", "module example.com/shop\n\ngo 1.22\n")
+
+	files := map[string]types.FileIndex{}
+	for _, f := range []string{
+		"billing/index.ts", "billing/plan.ts", "billing/internal/tax.ts",
+		"orders/order.ts",
+		"src/a/x.ts", "src/a/x2.ts", "src/b/y.ts",
+		"lib/c/one.ts", "lib/c/other.ts", "lib/d/two.ts",
+		"util/log.go", "cmd/app/main.go",
+	} {
+		files[f] = types.FileIndex{Path: f}
+	}
+	files["cmd/app/main.go"] = types.FileIndex{Path: "cmd/app/main.go", Language: "go", Imports: []string{"example.com/shop/util"}}
+	if err := s.jsonStore.SaveFilesIndexBulk(files); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+	imports := func(from, to string) types.Edge {
+		return types.Edge{FromType: "file", FromID: from, ToType: "file", ToID: to, Relation: "imports"}
+	}
+	if err := s.jsonStore.AddEdgesBulk([]types.Edge{
+		imports("billing/index.ts", "billing/plan.ts"),
+		imports("orders/order.ts", "billing/index.ts"),
+		imports("orders/order.ts", "billing/internal/tax.ts"),
+		// src/a and src/b import each other
+		imports("src/a/x.ts", "src/b/y.ts"),
+		imports("src/a/x2.ts", "src/b/y.ts"),
+		imports("src/b/y.ts", "src/a/x.ts"),
+		// lib/c and lib/d change together
+		imports("lib/c/one.ts", "lib/d/two.ts"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	correlations := []git.FileCorrelation{
+		{File1: "lib/c/one.ts", File2: "lib/d/two.ts", CoChanges: 12, Correlation: 0.8},
+		{File1: "lib/c/other.ts", File2: "lib/d/two.ts", CoChanges: 10, Correlation: 0.7},
+	}
+	data, _ := json.Marshal(correlations)
+	if err := os.WriteFile(filepath.Join(s.basePath, "knowledge", "git-correlations.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := resultMap(t, mustCall(t, s, "get_module_boundaries", map[string]interface{}{}))
+	modules := make(map[string]*moduleBoundary)
+	for _, m := range result["modules"].([]*moduleBoundary) {
+		modules[m.Name] = m
+	}
+
+	billing := modules["billing"]
+	if billing == nil || billing.Files != 3 || billing.InternalImports != 1 {
+		t.Fatalf("billing = %+v", billing)
+	}
+	if len(billing.EntryPoints) != 1 || billing.EntryPoints[0].Path != "billing/index.ts" || billing.EntryPoints[0].ImportedBy != 1 {
+		t.Errorf("billing entry points = %+v", billing.EntryPoints)
+	}
+	if len(billing.UsedBy) != 1 || billing.UsedBy[0] != (moduleLink{Module: "orders", Imports: 2}) {
+		t.Errorf("billing used_by = %+v", billing.UsedBy)
+	}
+
+	violations := result["violations"].([]moduleViolation)
+	want := moduleViolation{From: "orders/order.ts", To: "billing/internal/tax.ts", FromModule: "orders", ToModule: "billing", Reason: "bypasses index.ts of billing"}
+	if len(violations) != 1 || violations[0] != want {
+		t.Errorf("violations = %+v, want only %+v", violations, want)
+	}
+	if modules["orders"].Violations != 1 {
+		t.Errorf("orders violations = %d, want 1", modules["orders"].Violations)
+	}
+
+	// Directories importing each other are one module, named by the larger
+	if m := modules["src/a"]; m == nil || modules["src/b"] != nil || m.Files != 3 || m.MergeReason == "" || m.Cohesion != 1 {
+		t.Errorf("src/a and src/b should merge: %+v", m)
+	}
+	// So are directories that keep changing together
+	if m := modules["lib/c"]; m == nil || modules["lib/d"] != nil || len(m.Paths) != 2 {
+		t.Errorf("lib/c and lib/d should merge: %+v", m)
+	}
+
+	// A Go package import lands on the package directory
+	util := modules["util"]
+	if util == nil || len(util.EntryPoints) != 1 || util.EntryPoints[0].Path != "util" {
+		t.Errorf("util = %+v", util)
+	}
+
+	one := resultMap(t, mustCall(t, s, "get_module_boundaries", map[string]interface{}{"module": "orders"}))
+	if one["module_count"] != 1 || one["violation_count"] != 1 {
+		t.Errorf("module filter: %d modules, %d violations", one["module_count"], one["violation_count"])
+	}
+	if _, err := callTool(t, s, "get_module_boundaries", map[string]interface{}{"module": "missing"}); err == nil {
+		t.Error("expected an error for an unknown module")
+	}
+}
@@ -1,21 +1,37 @@
 package mcp
 
-// handleToolsList returns the schema definitions for all 54 MCP tools.
+// dryRunProperty and confirmTokenProperty are shared by the tools that
+// archive, delete or overwrite knowledge
+var (
+	dryRunProperty       = Property{Type: "boolean", Description: "Return the exact changes without making them"}
+	confirmTokenProperty = Property{Type: "string", Description: "Token from a dry run of the same call; required when the server has require_confirmation set"}
+)
+
+// handleToolsList returns the schema definitions for all 81 MCP tools.
 // This is called when the client requests "tools/list".
 
 func (s *Server) handleToolsList(req *Request) {
-	tools := []ToolInfo{
+	s.sendResult(req.ID, map[string]interface{}{"tools": toolDefinitions()})
+}
+
+// toolDefinitions returns the schema of every tool. Properties with Path set
+// name files or directories and are checked against the sandbox.
+func toolDefinitions() []ToolInfo {
+	return []ToolInfo{
 		// === SEARCH & QUERY TOOLS ===
 		// Use these to find information before making changes
 		{
 			Name:        "query",
-			Description: "ASK A QUESTION about the codebase. Use for ANY question: 'who developed X?', 'how does X work?', 'what are the risks in X?'. Returns relevant files, decisions, warnings, AND git experts (who owns/developed each area with ownership %). Always try this first.",
+			Description: "ASK A QUESTION about the codebase. Use for ANY question: 'who developed X?', 'how does X work?', 'what are the risks in X?'. Returns relevant files, decisions, warnings, documentation sections (type 'doc', citable by path and heading), AND git experts (who owns/developed each area with ownership %). Each source lists the reasons it matched (matched tags, file imports, similar wording) to judge what to cite. Always try this first.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
 					"question":     {Type: "string", Description: "Your question in plain English"},
-					"scope":        {Type: "string", Description: "Optional: 'feature:ID' to limit to a feature, default searches everything"},
-					"include_code": {Type: "boolean", Description: "Set true to include matching code snippets"},
+					"scope":        {Type: "string", Description: "Optional: limit results with 'feature:ID', 'path:DIR', 'app:NAME' or 'lang:LANGUAGE'. Combine terms with spaces, e.g. 'app:web lang:ts'. Default searches everything"},
+					"include_code": {Type: "boolean", Description: "Set true to include matching code snippets (scoped like files)"},
+					"language":     {Type: "string", Description: "Optional: language to serve knowledge in (e.g. 'de'). Uses translations when available; defaults to TEAMCONTEXT_LANGUAGE"},
+					"deep":         {Type: "boolean", Description: "Set true for a slower, more complete answer: follows the knowledge graph, searches commit history and all conversations, and names the experts of each file found"},
+					"budget_ms":    {Type: "integer", Description: "With deep: latency budget in milliseconds (default 8000, max 30000). Searches that don't fit are skipped and listed"},
 				},
 				Required: []string{"question"},
 			},
@@ -27,35 +43,42 @@ func (s *Server) handleToolsList(req *Request) {
 				Type: "object",
 				Properties: map[string]Property{
 					"intent":            {Type: "string", Description: "What you plan to do, e.g., 'add new API endpoint for users'"},
-					"target_files":      {Type: "array", Description: "List of file paths you plan to modify"},
+					"target_files":      {Type: "array", Description: "List of file paths you plan to modify", Path: true},
+					"target_symbols":    {Type: "array", Description: "Optional: functions, classes or methods (Class.method) you plan to modify in target_files. Warnings anchored to other parts of those files are left out"},
 					"proposed_approach": {Type: "string", Description: "Optional: your planned approach, system will validate against existing decisions"},
 					"max_tokens":        {Type: "integer", Description: "Maximum token budget for context (default 8000). Results ranked by relevance and trimmed to fit."},
+					"language":          {Type: "string", Description: "Optional: language to serve knowledge in (e.g. 'de'). Uses translations when available; defaults to TEAMCONTEXT_LANGUAGE"},
+					"persona":           {Type: "string", Description: "Optional: who the context is for. 'implementer' gets conventions and code snippets first, 'reviewer' gets warnings, compliance findings and co-changed files, 'architect' gets decisions, architecture and drift findings"},
 				},
 				Required: []string{"intent"},
 			},
 		},
 		{
 			Name:        "search",
-			Description: "SEARCH ALL KNOWLEDGE. Use when you need to find specific information across files, decisions, warnings, and patterns.",
+			Description: "SEARCH ALL KNOWLEDGE. Use when you need to find specific information across files, decisions, warnings, patterns, and documentation (READMEs, docs/).",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"query": {Type: "string", Description: "What to search for"},
-					"types": {Type: "array", Description: "Optional filter: ['file', 'decision', 'warning', 'pattern']"},
-					"limit": {Type: "integer", Description: "Max results, default 20"},
+					"query":    {Type: "string", Description: "What to search for"},
+					"types":    {Type: "array", Description: "Optional filter: ['file', 'decision', 'warning', 'pattern', 'doc']"},
+					"limit":    {Type: "integer", Description: "Max results, default 20"},
+					"language": {Type: "string", Description: "Optional: language to serve knowledge in (e.g. 'de'). Uses translations when available; defaults to TEAMCONTEXT_LANGUAGE"},
 				},
 				Required: []string{"query"},
 			},
 		},
 		{
 			Name:        "search_files",
-			Description: "FIND FILES by name, content, or description. Returns file paths with summaries. Use when you need to locate files.",
+			Description: "FIND FILES by name, content, or description. Returns file paths with summaries. Use when you need to locate files. Directories with many matches come back as one rollup (path, count, summary); pass expand to list one.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"query":    {Type: "string", Description: "Search term (matches path, summary, exports)"},
-					"language": {Type: "string", Description: "Optional: 'typescript', 'go', 'python', etc."},
-					"limit":    {Type: "integer", Description: "Max results, default 20"},
+					"query":            {Type: "string", Description: "Search term (matches path, summary, exports)"},
+					"language":         {Type: "string", Description: "Optional: 'typescript', 'go', 'python', etc."},
+					"limit":            {Type: "integer", Description: "Max results, default 20"},
+					"include_deleted":  {Type: "boolean", Description: "Also return tombstones of files deleted from disk (default false)"},
+					"expand":           {Type: "string", Description: "List the matching files of one directory returned as a rollup", Path: true},
+					"rollup_threshold": {Type: "integer", Description: "Matches in one directory that collapse it into a rollup, default 5"},
 				},
 			},
 		},
@@ -73,6 +96,21 @@ func (s *Server) handleToolsList(req *Request) {
 				Required: []string{"pattern"},
 			},
 		},
+		{
+			Name:        "rate_result",
+			Description: "RATE A SEARCH RESULT. Use after search, query or get_context when a result was clearly helpful or a waste of tokens. Feedback is shared with the team and boosts or demotes the result for similar queries.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"query":   {Type: "string", Description: "The query or intent the result was returned for"},
+					"type":    {Type: "string", Description: "Result type: file, decision, warning, pattern, doc, conversation"},
+					"id":      {Type: "string", Description: "Result ID, or path for files and docs"},
+					"helpful": {Type: "boolean", Description: "true if the result was useful, false if not"},
+					"reason":  {Type: "string", Description: "Optional: why it was (not) helpful"},
+				},
+				Required: []string{"query", "type", "id", "helpful"},
+			},
+		},
 		// === READ TOOLS ===
 		// Use these to read existing knowledge
 		{
@@ -109,10 +147,11 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"feature": {Type: "string", Description: "Filter by feature ID"},
-					"status":  {Type: "string", Description: "Filter: 'active' or 'superseded'"},
-					"tags":    {Type: "array", Description: "Filter by tags"},
-					"limit":   {Type: "integer", Description: "Max results, default 50"},
+					"feature":  {Type: "string", Description: "Filter by feature ID"},
+					"status":   {Type: "string", Description: "Filter: 'active' or 'superseded'"},
+					"tags":     {Type: "array", Description: "Filter by tags"},
+					"limit":    {Type: "integer", Description: "Max results, default 50"},
+					"language": {Type: "string", Description: "Optional: language to serve knowledge in (e.g. 'de'). Uses translations when available; defaults to TEAMCONTEXT_LANGUAGE"},
 				},
 			},
 		},
@@ -124,6 +163,7 @@ func (s *Server) handleToolsList(req *Request) {
 				Properties: map[string]Property{
 					"feature":  {Type: "string", Description: "Filter by feature ID"},
 					"severity": {Type: "string", Description: "Filter: 'info', 'warning', 'critical'"},
+					"language": {Type: "string", Description: "Optional: language to serve knowledge in (e.g. 'de'). Uses translations when available; defaults to TEAMCONTEXT_LANGUAGE"},
 				},
 			},
 		},
@@ -139,11 +179,22 @@ func (s *Server) handleToolsList(req *Request) {
 		},
 		{
 			Name:        "get_stats",
-			Description: "GET INDEX STATISTICS. Shows how much knowledge is indexed (files, decisions, warnings, etc.).",
+			Description: "GET INDEX STATISTICS. Shows how much knowledge is indexed (files, decisions, warnings, etc.) and who contributed it: a per-author leaderboard and the share that came from agents, bots or no one.",
 			InputSchema: InputSchema{
 				Type: "object",
 			},
 		},
+		{
+			Name:        "get_token_report",
+			Description: "GET TOKEN SPEND PER FEATURE. Shows estimated agent tokens consumed by tool responses for each feature, the most expensive tools and directories, budget status, and where recording knowledge would cut the cost.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"feature": {Type: "string", Description: "Optional: report on a single feature"},
+					"limit":   {Type: "integer", Description: "Max tools and areas listed per feature (default 10)"},
+				},
+			},
+		},
 		{
 			Name:        "get_architecture",
 			Description: "GET SYSTEM ARCHITECTURE. Shows the high-level structure: components, layers, data flow.",
@@ -153,12 +204,18 @@ func (s *Server) handleToolsList(req *Request) {
 		},
 		{
 			Name:        "get_evolution_timeline",
-			Description: "GET PROJECT HISTORY. Shows how the project has evolved over time.",
+			Description: "GET PROJECT HISTORY. Shows how the project has evolved over time. Search it to answer 'when did we migrate to Kafka and why': query matches event text and the decisions/warnings the events link to; expand_related inlines those.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"event_type": {Type: "string", Description: "Filter: 'decision', 'architecture_change', 'milestone'"},
-					"limit":      {Type: "integer", Description: "Max results, default 50"},
+					"event_type":     {Type: "string", Description: "Filter: 'decision', 'architecture_change', 'milestone'"},
+					"query":          {Type: "string", Description: "Text to search in titles, descriptions and related decisions/warnings; results ranked by relevance"},
+					"since":          {Type: "string", Description: "Only events on or after: YYYY-MM-DD, RFC3339 or a duration like '90d'"},
+					"until":          {Type: "string", Description: "Only events before: YYYY-MM-DD (inclusive), RFC3339 or a duration like '30d'"},
+					"impact":         {Type: "string", Description: "Exact impact: 'low', 'medium', 'high'"},
+					"min_impact":     {Type: "string", Description: "Minimum impact: 'medium' returns medium and high"},
+					"expand_related": {Type: "boolean", Description: "Inline the decisions, warnings and insights each event links to"},
+					"limit":          {Type: "integer", Description: "Max results, default 50"},
 				},
 			},
 		},
@@ -170,7 +227,7 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path":       {Type: "string", Description: "Absolute file path"},
+					"path":       {Type: "string", Description: "Absolute file path", Path: true},
 					"summary":    {Type: "string", Description: "One-sentence description of what this file does"},
 					"exports":    {Type: "array", Description: "Exported symbols: [{name: 'funcName', kind: 'function', line: 10}]"},
 					"imports":    {Type: "array", Description: "Imported modules: ['./utils', 'express']"},
@@ -195,6 +252,10 @@ func (s *Server) handleToolsList(req *Request) {
 					"related_files":     {Type: "array", Description: "File paths affected by this decision"},
 					"related_decisions": {Type: "array", Description: "IDs of related decisions"},
 					"tags":              {Type: "array", Description: "Tags: ['security', 'performance', 'api']"},
+					"issues":            {Type: "array", Description: "Issue tracker keys: ['PAY-142', 'acme/api#87']"},
+					"language":          {Type: "string", Description: "Language the text is written in, e.g. 'en', 'de'"},
+					"translations":      {Type: "object", Description: "Optional translated variants by language: {'de': {'content': '...', 'reason': '...'}}"},
+					"idempotency_key":   {Type: "string", Description: "Optional: key identifying this write, e.g. a request ID. A retry with the same key returns the decision first recorded; without a key, the same content is recognized instead"},
 				},
 				Required: []string{"content", "reason"},
 			},
@@ -205,13 +266,18 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"content":       {Type: "string", Description: "What to avoid (e.g., 'Don't use sync API in async context')"},
-					"reason":        {Type: "string", Description: "Why it's dangerous"},
-					"evidence":      {Type: "string", Description: "What went wrong when this was tried"},
-					"severity":      {Type: "string", Description: "'info', 'warning', or 'critical'"},
-					"feature":       {Type: "string", Description: "Feature ID if specific to a feature"},
-					"related_files": {Type: "array", Description: "File paths this warning applies to"},
-					"tags":          {Type: "array", Description: "Tags for categorization"},
+					"content":         {Type: "string", Description: "What to avoid (e.g., 'Don't use sync API in async context')"},
+					"reason":          {Type: "string", Description: "Why it's dangerous"},
+					"evidence":        {Type: "string", Description: "What went wrong when this was tried"},
+					"severity":        {Type: "string", Description: "'info', 'warning', or 'critical'"},
+					"feature":         {Type: "string", Description: "Feature ID if specific to a feature"},
+					"related_files":   {Type: "array", Description: "File paths this warning applies to"},
+					"anchors":         {Type: "array", Description: "Optional: parts of files the warning is about, instead of whole files: [{'file': 'src/pay.ts', 'symbol': 'PaymentService.refund'}] or [{'file': 'src/pay.ts', 'start_line': 40, 'end_line': 62}]. Anchors follow their code when it moves"},
+					"tags":            {Type: "array", Description: "Tags for categorization"},
+					"issues":          {Type: "array", Description: "Issue tracker keys: ['PAY-142', 'acme/api#87']"},
+					"language":        {Type: "string", Description: "Language the text is written in, e.g. 'en', 'de'"},
+					"translations":    {Type: "object", Description: "Optional translated variants by language: {'de': {'content': '...', 'reason': '...'}}"},
+					"idempotency_key": {Type: "string", Description: "Optional: key identifying this write, e.g. a request ID. A retry with the same key returns the warning first recorded; without a key, the same content is recognized instead"},
 				},
 				Required: []string{"content", "reason"},
 			},
@@ -222,26 +288,115 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"content":       {Type: "string", Description: "The insight"},
-					"context":       {Type: "string", Description: "Context for the insight"},
-					"feature":       {Type: "string", Description: "Related feature ID"},
-					"related_files": {Type: "array", Description: "Related files"},
-					"tags":          {Type: "array", Description: "Tags for categorization"},
+					"content":         {Type: "string", Description: "The insight"},
+					"context":         {Type: "string", Description: "Context for the insight"},
+					"feature":         {Type: "string", Description: "Related feature ID"},
+					"related_files":   {Type: "array", Description: "Related files"},
+					"tags":            {Type: "array", Description: "Tags for categorization"},
+					"language":        {Type: "string", Description: "Language the text is written in, e.g. 'en', 'de'"},
+					"translations":    {Type: "object", Description: "Optional translated variants by language: {'de': {'content': '...', 'reason': '...'}}"},
+					"idempotency_key": {Type: "string", Description: "Optional: key identifying this write, e.g. a request ID. A retry with the same key returns the insight first recorded; without a key, the same content is recognized instead"},
 				},
 				Required: []string{"content"},
 			},
 		},
+		{
+			Name:        "list_unreviewed_insights",
+			Description: "REVIEW INSIGHTS. Lists insights nobody has promoted or dismissed yet, oldest first, with their age. Use to turn useful ones into decisions or warnings and clean up the rest.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"older_than": {Type: "string", Description: "Optional: only insights older than this, e.g. '14d', '2025-01-01'"},
+					"feature":    {Type: "string", Description: "Optional: filter by feature ID"},
+					"limit":      {Type: "integer", Description: "Max results, default 20"},
+				},
+			},
+		},
+		{
+			Name:        "promote_insight_to_decision",
+			Description: "PROMOTE AN INSIGHT TO A DECISION. Creates a decision from the insight (keeping its files, tags, feature and author) and marks the insight as reviewed.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"id":      {Type: "string", Description: "Insight ID"},
+					"content": {Type: "string", Description: "Optional: decision text, defaults to the insight"},
+					"reason":  {Type: "string", Description: "Optional: why, defaults to the insight's context"},
+					"feature": {Type: "string", Description: "Optional: feature ID, defaults to the insight's"},
+				},
+				Required: []string{"id"},
+			},
+		},
+		{
+			Name:        "promote_insight_to_warning",
+			Description: "PROMOTE AN INSIGHT TO A WARNING. Creates a warning from the insight (keeping its files, tags, feature and author) and marks the insight as reviewed.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"id":       {Type: "string", Description: "Insight ID"},
+					"content":  {Type: "string", Description: "Optional: warning text, defaults to the insight"},
+					"reason":   {Type: "string", Description: "Optional: why, defaults to the insight's context"},
+					"severity": {Type: "string", Description: "'info', 'warning' or 'critical', default 'warning'"},
+					"feature":  {Type: "string", Description: "Optional: feature ID, defaults to the insight's"},
+				},
+				Required: []string{"id"},
+			},
+		},
+		{
+			Name:        "dismiss_insight",
+			Description: "DISMISS AN INSIGHT. Deletes an insight that turned out not to be worth keeping.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"id":            {Type: "string", Description: "Insight ID"},
+					"dry_run":       dryRunProperty,
+					"confirm_token": confirmTokenProperty,
+				},
+				Required: []string{"id"},
+			},
+		},
+		{
+			Name:        "add_translation",
+			Description: "TRANSLATE A DECISION, WARNING OR INSIGHT. Use when the team works in several languages. Agents asking for that language get the translation instead of the original.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"type":     {Type: "string", Description: "'decision', 'warning' or 'insight'"},
+					"id":       {Type: "string", Description: "ID of the item"},
+					"language": {Type: "string", Description: "Language of the translation, e.g. 'de'"},
+					"content":  {Type: "string", Description: "Translated content"},
+					"reason":   {Type: "string", Description: "Translated reason (decisions, warnings)"},
+					"context":  {Type: "string", Description: "Translated context (decisions, insights)"},
+					"evidence": {Type: "string", Description: "Translated evidence (warnings)"},
+				},
+				Required: []string{"type", "id", "language"},
+			},
+		},
+		{
+			Name:        "link_issue",
+			Description: "LINK A JIRA OR GITHUB ISSUE. Use to tie a decision, warning or feature to its ticket. Validates the key against the tracker and caches title and status, which get_feature shows inline.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"key":     {Type: "string", Description: "Jira key (PAY-142) or GitHub issue (acme/api#87, or #87 with issues.repo configured)"},
+					"type":    {Type: "string", Description: "Optional: 'decision', 'warning' or 'feature' to link to"},
+					"id":      {Type: "string", Description: "ID of the item to link (required with type)"},
+					"refresh": {Type: "boolean", Description: "Fetch title and status even if cached within the last hour"},
+				},
+				Required: []string{"key"},
+			},
+		},
 		{
 			Name:        "add_pattern",
 			Description: "Add a recognized pattern.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"name":          {Type: "string", Description: "Pattern name (e.g., Repository Pattern)"},
-					"description":   {Type: "string", Description: "Pattern description"},
-					"examples":      {Type: "array", Description: "File paths showing this pattern"},
-					"rules":         {Type: "array", Description: "Rules that define this pattern"},
-					"anti_patterns": {Type: "array", Description: "What violates this pattern"},
+					"name":            {Type: "string", Description: "Pattern name (e.g., Repository Pattern)"},
+					"description":     {Type: "string", Description: "Pattern description"},
+					"examples":        {Type: "array", Description: "File paths showing this pattern"},
+					"rules":           {Type: "array", Description: "Rules that define this pattern"},
+					"anti_patterns":   {Type: "array", Description: "What violates this pattern"},
+					"idempotency_key": {Type: "string", Description: "Optional: key identifying this write, e.g. a request ID. A retry with the same key returns the pattern first recorded; without a key, the same name is recognized instead"},
 				},
 				Required: []string{"name", "description"},
 			},
@@ -293,6 +448,40 @@ func (s *Server) handleToolsList(req *Request) {
 				Required: []string{"feature", "full_text"},
 			},
 		},
+		{
+			Name:        "merge_conversations",
+			Description: "MERGE RELATED CONVERSATIONS of a feature into one consolidated memory. Key points that say the same thing are deduplicated, files and decisions are unioned. Use when a long-running feature has many overlapping saved conversations.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"feature":        {Type: "string", Description: "Feature ID the conversations belong to"},
+					"ids":            {Type: "array", Description: "Conversation IDs to merge (at least 2)"},
+					"all":            {Type: "boolean", Description: "Merge every conversation of the feature instead of listing ids"},
+					"summary":        {Type: "string", Description: "Summary for the merged conversation (default: the deduplicated summaries joined)"},
+					"keep_originals": {Type: "boolean", Description: "Keep the source conversations (default: false, they are replaced)"},
+					"dry_run":        dryRunProperty,
+					"confirm_token":  confirmTokenProperty,
+				},
+				Required: []string{"feature"},
+			},
+		},
+		{
+			Name:        "split_conversation",
+			Description: "SPLIT A LARGE CONVERSATION by topic into smaller conversations so resume_context stays manageable. Give topics with keywords, or let key points be clustered automatically.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"feature":       {Type: "string", Description: "Feature ID the conversation belongs to"},
+					"id":            {Type: "string", Description: "Conversation ID to split"},
+					"topics":        {Type: "array", Description: "Topics as [{name, keywords}]; key points go to the topic they share most words with"},
+					"max_parts":     {Type: "integer", Description: "Maximum parts when clustering automatically (default: 4)"},
+					"keep_original": {Type: "boolean", Description: "Keep the original conversation (default: false, it is replaced)"},
+					"dry_run":       dryRunProperty,
+					"confirm_token": confirmTokenProperty,
+				},
+				Required: []string{"feature", "id"},
+			},
+		},
 		{
 			Name:        "update_feature_state",
 			Description: "Update the current state of a feature.",
@@ -312,10 +501,12 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"description": {Type: "string", Description: "Architecture description"},
-					"diagram":     {Type: "string", Description: "ASCII or mermaid diagram"},
-					"services":    {Type: "array", Description: "Service nodes [{name, description, files, dependencies}]"},
-					"data_flows":  {Type: "array", Description: "Data flows [{from, to, description}]"},
+					"description":   {Type: "string", Description: "Architecture description"},
+					"diagram":       {Type: "string", Description: "ASCII or mermaid diagram"},
+					"services":      {Type: "array", Description: "Service nodes [{name, description, files, dependencies}]"},
+					"data_flows":    {Type: "array", Description: "Data flows [{from, to, description}]"},
+					"dry_run":       dryRunProperty,
+					"confirm_token": confirmTokenProperty,
 				},
 			},
 		},
@@ -325,9 +516,11 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"name":        {Type: "string", Description: "Project name"},
-					"description": {Type: "string", Description: "Project description"},
-					"languages":   {Type: "array", Description: "Programming languages used"},
+					"name":          {Type: "string", Description: "Project name"},
+					"description":   {Type: "string", Description: "Project description"},
+					"languages":     {Type: "array", Description: "Programming languages used"},
+					"dry_run":       dryRunProperty,
+					"confirm_token": confirmTokenProperty,
 				},
 			},
 		},
@@ -338,10 +531,12 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"id":          {Type: "string", Description: "Feature ID (e.g., payment-retry)"},
-					"branch":      {Type: "string", Description: "Git branch name"},
-					"extends":     {Type: "string", Description: "Parent feature to inherit from"},
-					"description": {Type: "string", Description: "Initial description"},
+					"id":           {Type: "string", Description: "Feature ID (e.g., payment-retry)"},
+					"branch":       {Type: "string", Description: "Git branch name"},
+					"extends":      {Type: "string", Description: "Parent feature to inherit from"},
+					"description":  {Type: "string", Description: "Initial description"},
+					"token_budget": {Type: "integer", Description: "Optional: estimated agent tokens this feature may consume. Tool responses carry a warning once it is reached."},
+					"issues":       {Type: "array", Description: "Issue tracker keys for the feature's tickets"},
 				},
 				Required: []string{"id"},
 			},
@@ -352,8 +547,10 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"id":      {Type: "string", Description: "Feature ID to archive"},
-					"summary": {Type: "string", Description: "Archive summary"},
+					"id":            {Type: "string", Description: "Feature ID to archive"},
+					"summary":       {Type: "string", Description: "Archive summary, returned when the feature is recalled"},
+					"dry_run":       dryRunProperty,
+					"confirm_token": confirmTokenProperty,
 				},
 				Required: []string{"id"},
 			},
@@ -369,6 +566,58 @@ func (s *Server) handleToolsList(req *Request) {
 				Required: []string{"id"},
 			},
 		},
+		{
+			Name:        "handoff_feature",
+			Description: "HAND A FEATURE OVER to another developer. Bundles its current state, open tickets, pending decisions (unreviewed insights), decisions and warnings in force, recent conversations, touched files with summaries, and who to ask into one handoff document.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"feature": {Type: "string", Description: "Feature ID (default: the session's active feature)"},
+					"to":      {Type: "string", Description: "Optional: who takes the feature over, named in the document"},
+					"format":  {Type: "string", Description: "'json' (default) or 'markdown' for a document to paste into a ticket or chat"},
+				},
+			},
+		},
+		{
+			Name:        "save_plan",
+			Description: "SAVE AN EXECUTION PLAN for a feature: ordered steps with the files each touches. Mark steps with update_plan as you work; get_plan or resume_context picks the plan up in a later session.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"feature": {Type: "string", Description: "Feature ID (default: the session's active feature)"},
+					"goal":    {Type: "string", Description: "What the plan achieves"},
+					"steps":   {Type: "array", Description: "Ordered steps: [{'description': 'Add refund endpoint', 'files': ['src/pay/refund.ts']}]. Status defaults to 'pending'"},
+				},
+				Required: []string{"goal", "steps"},
+			},
+		},
+		{
+			Name:        "update_plan",
+			Description: "UPDATE PLAN PROGRESS. Set a step's status ('pending', 'in_progress', 'done', 'blocked', 'skipped') and note, add steps, or abandon the plan. The plan completes when every step is done or skipped.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"plan_id":   {Type: "string", Description: "Plan ID from save_plan"},
+					"step":      {Type: "integer", Description: "Step number, starting at 1"},
+					"status":    {Type: "string", Description: "New status of the step"},
+					"note":      {Type: "string", Description: "Optional: why it is blocked, what was done differently"},
+					"add_steps": {Type: "array", Description: "Optional: steps to append, like save_plan's steps"},
+					"abandon":   {Type: "boolean", Description: "Set true to abandon the plan"},
+				},
+				Required: []string{"plan_id"},
+			},
+		},
+		{
+			Name:        "get_plan",
+			Description: "RESUME A PLAN. Returns a plan with its progress and the next step to work on: by plan_id, or a feature's most recent active plan.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"plan_id": {Type: "string", Description: "Plan ID"},
+					"feature": {Type: "string", Description: "Feature ID (default: the session's active feature); used when plan_id is not given"},
+				},
+			},
+		},
 		// === INDEX & GRAPH TOOLS ===
 		{
 			Name:        "index",
@@ -377,17 +626,29 @@ func (s *Server) handleToolsList(req *Request) {
 				Type: "object",
 				Properties: map[string]Property{
 					"incremental": {Type: "boolean", Description: "Only index changed files"},
-					"paths":       {Type: "array", Description: "Specific paths to index"},
+					"paths":       {Type: "array", Description: "Specific paths to index", Path: true},
 				},
 			},
 		},
 		{
 			Name:        "index_status",
-			Description: "CHECK INDEX STATUS. Shows what has been indexed and any pending work.",
+			Description: "CHECK INDEX STATUS. Shows what has been indexed and any pending work, and what the index caps (index.max_files, max_chunk_rows, max_vector_rows) left out.",
 			InputSchema: InputSchema{
 				Type: "object",
 			},
 		},
+		{
+			Name:        "list_files_needing_summary",
+			Description: "FILES NEEDING A SUMMARY. Auto-indexed files whose summary is a placeholder or guessed from exports, ranked by how often sessions use them, how many files import them and how much they export. Write real summaries with index_file.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":   {Type: "string", Description: "Only files under this directory", Path: true},
+					"reason": {Type: "string", Description: "Only 'placeholder' (no real summary) or 'heuristic' (guessed from exports)"},
+					"limit":  {Type: "integer", Description: "Max files to return (default 20)"},
+				},
+			},
+		},
 		{
 			Name:        "get_graph",
 			Description: "GET KNOWLEDGE GRAPH. Shows connections between files, decisions, warnings, and features. Use to understand relationships.",
@@ -399,6 +660,20 @@ func (s *Server) handleToolsList(req *Request) {
 				},
 			},
 		},
+		{
+			Name:        "get_graph_evolution",
+			Description: "GRAPH EVOLUTION. Replays the graph snapshots recorded at each reindex: edge counts per month and how import coupling between services or directories grew or shrank. Evidence for architectural reviews.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"since":    {Type: "string", Description: "Start of the window: YYYY-MM-DD, RFC3339 or a duration like 180d"},
+					"until":    {Type: "string", Description: "End of the window, same formats as since"},
+					"area":     {Type: "string", Description: "Only coupling involving this service or directory, e.g. 'payments' or 'internal/mcp'"},
+					"interval": {Type: "string", Description: "Period to group snapshots by: 'month' (default), 'week', 'day'"},
+					"limit":    {Type: "integer", Description: "Max coupling changes to return (default 15)"},
+				},
+			},
+		},
 		{
 			Name:        "get_related",
 			Description: "Traverse the knowledge graph from a starting node. Find all connected decisions, warnings, patterns, and files.",
@@ -420,7 +695,7 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path": {Type: "string", Description: "File path to scan"},
+					"path": {Type: "string", Description: "File path to scan", Path: true},
 				},
 				Required: []string{"path"},
 			},
@@ -431,17 +706,33 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path": {Type: "string", Description: "Filter to a subdirectory (e.g. 'apps/notification')"},
+					"path": {Type: "string", Description: "Filter to a subdirectory (e.g. 'apps/notification')", Path: true},
+				},
+			},
+		},
+		{
+			Name:        "get_code_map",
+			Description: "GET CODE MAP. Structured directory overview from the index with per-file summaries, languages and export counts. Use path to zoom in.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":      {Type: "string", Description: "Subdirectory to map (default: project root)", Path: true},
+					"app":       {Type: "string", Description: "Only map this app: a Gradle module (':feature:login', 'feature/login' or 'login') or an apps/, packages/ or services/ directory"},
+					"language":  {Type: "string", Description: "Only include files of this language (e.g. 'go', 'typescript')"},
+					"max_depth": {Type: "integer", Description: "Directory levels to expand (default 2 at root, unlimited below a path)"},
+					"limit":     {Type: "integer", Description: "Max files to list (default 200)"},
+					"recursive": {Type: "boolean", Description: "Set false to list only the immediate children of path (default true)"},
+					"dirs_only": {Type: "boolean", Description: "Only show directories with file counts"},
 				},
 			},
 		},
 		{
 			Name:        "get_dependencies",
-			Description: "GET FILE DEPENDENCIES. Use to understand what a file imports (upstream) or what imports it (downstream).",
+			Description: "GET FILE DEPENDENCIES. Use to understand what a file imports (upstream) or what imports it (downstream). In Bazel/Pants repos, files owned by a build target follow the deps declared in BUILD files instead of imports.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path":      {Type: "string", Description: "File path to analyze"},
+					"path":      {Type: "string", Description: "File path to analyze", Path: true},
 					"direction": {Type: "string", Description: "'upstream' (what I import), 'downstream' (what imports me), or 'both'"},
 					"depth":     {Type: "integer", Description: "How many levels deep (default 1, max 5)"},
 				},
@@ -454,7 +745,7 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path":      {Type: "string", Description: "Starting file"},
+					"path":      {Type: "string", Description: "Starting file", Path: true},
 					"direction": {Type: "string", Description: "'forward' (follow imports) or 'backward' (follow importers)"},
 					"depth":     {Type: "integer", Description: "How far to trace (default 3, max 10)"},
 					"query":     {Type: "string", Description: "Optional: filter nodes by relevance to this topic"},
@@ -462,6 +753,47 @@ func (s *Server) handleToolsList(req *Request) {
 				Required: []string{"path"},
 			},
 		},
+		{
+			Name:        "call_graph",
+			Description: "WHO CALLS WHAT IN A FILE. Lists which functions and methods of one file call which, or, given a function, what it calls and what calls it, transitively. Complements trace_flow, which follows imports between files. Calls are matched by name, best effort.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":      {Type: "string", Description: "Source file", Path: true},
+					"function":  {Type: "string", Description: "Optional: function to start from; methods as Class.method, or the bare name when only one class has it"},
+					"direction": {Type: "string", Description: "With function: 'forward' (what it calls, default), 'backward' (what calls it) or 'both'"},
+					"depth":     {Type: "integer", Description: "How many calls deep to follow (default 3, max 10)"},
+					"format":    {Type: "string", Description: "'json' (default) or 'mermaid' for a flowchart"},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "get_module_boundaries",
+			Description: "MODULE BOUNDARIES. Infers the project's modules (bounded contexts) from documented services, workspaces and directories, merging directories that import each other or change together. Lists each module's public entry points, cohesion and dependencies, and boundary violations: imports reaching past another module's index file into its internals.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":   {Type: "string", Description: "Optional: only modules under this directory", Path: true},
+					"module": {Type: "string", Description: "Optional: one module by name, with the violations into and out of it"},
+					"depth":  {Type: "integer", Description: "Directory levels naming a module when no service or workspace owns it (default 2, max 4)"},
+					"limit":  {Type: "integer", Description: "Max violations to list (default 50)"},
+				},
+			},
+		},
+		{
+			Name:        "find_implementations",
+			Description: "FIND IMPLEMENTATIONS. Use to find every type implementing a trait or interface (Rust impl blocks, TS/Java/C#/PHP implements) and the traits/interfaces extending it.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"name":  {Type: "string", Description: "Trait or interface name, optionally qualified (crate::db::Store)"},
+					"path":  {Type: "string", Description: "Optional: only search files under this path", Path: true},
+					"limit": {Type: "integer", Description: "Max implementations to return (default 50)"},
+				},
+				Required: []string{"name"},
+			},
+		},
 		// === TOKEN-EFFICIENT TOOLS ===
 		// Use these instead of reading full files to save tokens
 		{
@@ -470,18 +802,48 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"file": {Type: "string", Description: "Filename (e.g. 'alarm.controller.ts') or full path"},
+					"file": {Type: "string", Description: "Filename (e.g. 'alarm.controller.ts') or full path", Path: true},
 				},
 				Required: []string{"file"},
 			},
 		},
+		{
+			Name:        "get_skeleton",
+			Description: "GET CODE SKELETON. Use to see a file's or directory's structure (classes, methods, functions, signatures) without bodies. Saves ~90% tokens. format='markdown' gives a linked outline for PR descriptions and docs, format='mermaid' a class diagram for architecture docs. Generated files are compacted unless full=true.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":      {Type: "string", Description: "File or directory path", Path: true},
+					"format":    {Type: "string", Description: "'text' (code-like, default), 'markdown' (outline with line links), 'mermaid' (classDiagram of classes, interfaces, structs and enums) or 'json'"},
+					"limit":     {Type: "integer", Description: "Max files for directories (default 20, max 100)"},
+					"max_chars": {Type: "integer", Description: "Max output characters for directories (default 50000)"},
+					"recursive": {Type: "boolean", Description: "Include subdirectories (default false)"},
+					"full":      {Type: "boolean", Description: "Return full skeletons for generated files too. By default generated code (protobuf stubs, API clients, files marked 'Code generated') is reduced to counts and top-level exports"},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "get_skeleton_diff",
+			Description: "WHAT CHANGED STRUCTURALLY? Compares skeletons of changed files between two commits: functions, classes, methods, properties, types, enums and constants added, removed or with a changed signature (old and new). Defaults to the last commit. Use to review a commit or branch without reading the line diff.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":   {Type: "string", Description: "Optional file or directory to limit the diff to", Path: true},
+					"commit": {Type: "string", Description: "Commit to compare with its parent (default: HEAD)"},
+					"from":   {Type: "string", Description: "Or a base commit, branch or tag, e.g. 'main'"},
+					"to":     {Type: "string", Description: "Commit to compare from with (default with from: the working tree)"},
+					"limit":  {Type: "integer", Description: "Max files reported (default 20)"},
+				},
+			},
+		},
 		{
 			Name:        "get_types",
-			Description: "GET TYPE DEFINITIONS from TypeScript files. Returns interfaces, types, enums - perfect for understanding data models. Saves 70% tokens.",
+			Description: "GET TYPE DEFINITIONS from TypeScript, Go, Rust, Java, C# and .proto files. Returns interfaces, types, enums with their members (Go: typed iota const blocks) and struct fields with their tags (protobuf: field numbers) - perfect for understanding data models. Saves 70% tokens.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path":   {Type: "string", Description: "File or directory path"},
+					"path":   {Type: "string", Description: "File or directory path", Path: true},
 					"format": {Type: "string", Description: "'json' or 'text' (default: text)"},
 				},
 				Required: []string{"path"},
@@ -507,7 +869,7 @@ func (s *Server) handleToolsList(req *Request) {
 				Type: "object",
 				Properties: map[string]Property{
 					"since":   {Type: "string", Description: "Time filter: '3 days ago', '1 week ago'"},
-					"path":    {Type: "string", Description: "Filter to changes in this path"},
+					"path":    {Type: "string", Description: "Filter to changes in this path", Path: true},
 					"limit":   {Type: "integer", Description: "Max commits (default 10)"},
 					"feature": {Type: "string", Description: "Filter to commits mentioning this feature"},
 				},
@@ -544,7 +906,7 @@ func (s *Server) handleToolsList(req *Request) {
 				Properties: map[string]Property{
 					"task":   {Type: "string", Description: "'add-api-endpoint', 'add-service', 'add-test', 'refactor', 'debug'"},
 					"domain": {Type: "string", Description: "Module name: 'notification', 'payment', 'auth'"},
-					"file":   {Type: "string", Description: "Specific file to get context for"},
+					"file":   {Type: "string", Description: "Specific file to get context for", Path: true},
 				},
 				Required: []string{"task"},
 			},
@@ -556,12 +918,24 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"file_path": {Type: "string", Description: "File path to check (reads current content)"},
+					"file_path": {Type: "string", Description: "File path to check (reads current content)", Path: true},
 					"diff":      {Type: "string", Description: "Code diff to check (alternative to file_path)"},
 					"code":      {Type: "string", Description: "Code snippet to check (alternative to file_path)"},
 				},
 			},
 		},
+		{
+			Name:        "docs_coverage",
+			Description: "DOCUMENTATION COVERAGE: the percentage of public exports (classes, functions, methods, interfaces, types, enums) with doc comments per package, least documented first with the undocumented names, plus module and service directories missing a README. Thresholds from docs_coverage in .teamcontext/config.json (overall min and per-path minimums for critical areas) are checked; 'passed' is false when one is not met.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":     {Type: "string", Description: "Optional: project-relative directory to report on (default: whole project)", Path: true},
+					"min":      {Type: "number", Description: "Optional: overall minimum percent, overriding the configured one"},
+					"packages": {Type: "integer", Description: "Max packages listed (default 20)"},
+				},
+			},
+		},
 		{
 			Name:        "onboard",
 			Description: "GET STRUCTURED ONBOARDING for a new team member. Returns the project's architecture, top decisions, active warnings, key patterns, code map, and expert contacts. One call to understand the entire project.",
@@ -576,64 +950,148 @@ func (s *Server) handleToolsList(req *Request) {
 		// === TEAM ACTIVITY TOOLS ===
 		{
 			Name:        "get_feed",
-			Description: "GET RECENT TEAM ACTIVITY. Shows a timeline of recent decisions, warnings, patterns, insights, conversations, and events. Use to see what the team has been working on or what changed recently.",
+			Description: "GET RECENT TEAM ACTIVITY. Shows a timeline of recent decisions, warnings, patterns, insights, conversations, events, and config.json changes applied while the server ran. Use to see what the team has been working on or what changed recently. Unfiltered feeds include who contributed knowledge in the window.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
 					"limit": {Type: "integer", Description: "Max entries to return (default 20)"},
 					"since": {Type: "string", Description: "Optional: ISO8601 date or duration (e.g., '7d', '24h', '2025-01-01')"},
-					"type":  {Type: "string", Description: "Optional: filter by type — 'decision', 'warning', 'pattern', 'insight', 'conversation', 'event'"},
+					"type":  {Type: "string", Description: "Optional: filter by type — 'decision', 'warning', 'pattern', 'insight', 'conversation', 'event', 'config'"},
 				},
 			},
 		},
 		// === HIGH-IMPACT EXTRACTION TOOLS ===
 		{
 			Name:        "get_api_surface",
-			Description: "GET ALL API ENDPOINTS from multiple languages: TypeScript (NestJS/Express), Go (gin/echo), Python (Flask/FastAPI/Django), Java (Spring), C# (ASP.NET). Also extracts Kafka consumers/producers. Saves 80% tokens vs reading controller files.",
+			Description: "GET ALL API ENDPOINTS from multiple languages: TypeScript (NestJS/Express), Go (gin/echo), Python (Flask/FastAPI/Django), Java (Spring), C# (ASP.NET), and gRPC services from .proto files (method GRPC, path /package.Service/Method, with streaming mode and google.api.http REST bindings). Also extracts Kafka consumers/producers. When an OpenAPI/Swagger spec (openapi.yaml, swagger.json, ...) is found, merges its endpoints and flags mismatches: spec 'missing' (documented, not implemented) and 'undocumented' (implemented, not in the spec). Saves 80% tokens vs reading controller files.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path": {Type: "string", Description: "Directory or file path to scan"},
+					"path": {Type: "string", Description: "Directory or file path to scan", Path: true},
 					"app":  {Type: "string", Description: "App name for labeling (e.g., 'notification', 'gateway')"},
+					"spec": {Type: "string", Description: "OpenAPI/Swagger spec to compare with (default: specs found under path)", Path: true},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "export_requests",
+			Description: "EXPORT A REQUEST COLLECTION from the extracted API surface: a VS Code REST Client / JetBrains .http file, Postman v2.1 collection or Insomnia export with baseUrl, token and path parameter variables. POST/PUT/PATCH bodies are example payloads inferred from the DTO, pydantic model, Java or C# class the handler takes. Pass output to write the file instead of returning it.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":     {Type: "string", Description: "Directory or controller file to scan", Path: true},
+					"app":      {Type: "string", Description: "Collection name (default: directory name)"},
+					"format":   {Type: "string", Description: "'http' (default), 'postman' or 'insomnia'"},
+					"base_url": {Type: "string", Description: "Value of the baseUrl variable (default: http://localhost:3000)"},
+					"output":   {Type: "string", Description: "Optional: project-relative file or directory to write the collection to", Path: true},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "get_auth_matrix",
+			Description: "ENDPOINT AUTH MATRIX for security review: every REST endpoint with the authentication (guards, JWT/session middleware, login_required, Depends(get_current_user), [Authorize]) and authorization (roles, permissions, policies) applied to it, from the handler, its controller, router/group middleware and app-wide guards (APP_GUARD, useGlobalGuards, Spring Security, ASP.NET FallbackPolicy). Endpoints with no check are flagged unguarded; @Public()/[AllowAnonymous]/@PermitAll ones are public.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":   {Type: "string", Description: "Directory or controller file to scan", Path: true},
+					"app":    {Type: "string", Description: "App name for labeling (default: directory name)"},
+					"status": {Type: "string", Description: "Optional: only list 'unguarded', 'public' or 'guarded' endpoints"},
+					"format": {Type: "string", Description: "'json' (default) or 'markdown' for a review-ready table"},
 				},
 				Required: []string{"path"},
 			},
 		},
 		{
 			Name:        "get_schema_models",
-			Description: "GET DATABASE MODELS from multiple languages: Prisma, Go (GORM/sqlx), Python (SQLAlchemy/Django), Java (JPA/Hibernate), TypeScript (TypeORM). Extracts models, fields, relations, enums. Saves 70% tokens vs reading full files.",
+			Description: "GET DATABASE MODELS from multiple languages: Prisma, Go (GORM/sqlx), Python (SQLAlchemy/Django), Java (JPA/Hibernate), TypeScript (TypeORM), raw SQL (CREATE TABLE plus the ALTER/DROP statements of migrations). Extracts models, fields, relations, enums. Saves 70% tokens vs reading full files.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path": {Type: "string", Description: "File or directory to scan for database models"},
+					"path": {Type: "string", Description: "File or directory to scan for database models", Path: true},
 				},
 				Required: []string{"path"},
 			},
 		},
 		{
 			Name:        "get_config_map",
-			Description: "GET ALL CONFIG/ENV VARS used in a project. Scans for process.env, os.Getenv, config files. Saves 60% tokens vs manually searching.",
+			Description: "GET ALL CONFIG/ENV VARS used in a project. Scans for process.env, os.Getenv, config files. Maps vars to environments (staging, production, ...) from .env.*, Helm values-<env>.yaml and CI deploy jobs, and warns when code reads a var an environment does not define. Saves 60% tokens vs manually searching.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"path": {Type: "string", Description: "Directory to scan for config usage"},
+					"path":        {Type: "string", Description: "Directory to scan for config usage", Path: true},
+					"environment": {Type: "string", Description: "Only report this environment and its warnings (e.g. 'production'; 'prod' and 'stg' aliases are folded)"},
 				},
 				Required: []string{"path"},
 			},
 		},
+		{
+			Name:        "list_feature_flags",
+			Description: "LIST FEATURE FLAGS read in code: LaunchDarkly, Unleash, Flagsmith, GrowthBook, OpenFeature, Split, ConfigCat, Flipper and Waffle calls, homegrown helpers like isFeatureEnabled('x'), and env toggles (FEATURE_*, *_ENABLED). Returns each key with its usage locations and defining files, warns when code reads a flag no flag file or .env defines, and lists defined flags nothing reads.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":         {Type: "string", Description: "Optional: directory to scan (default: project root)", Path: true},
+					"provider":     {Type: "string", Description: "Optional: only flags read through this provider (launchdarkly, unleash, flagsmith, growthbook, openfeature, split, configcat, flipper, waffle, custom, env)"},
+					"key":          {Type: "string", Description: "Optional: only flags whose key contains this text"},
+					"missing_only": {Type: "boolean", Description: "Optional: only flags read in code but missing from the flag sources"},
+				},
+			},
+		},
 		{
 			Name:        "get_blueprint",
 			Description: "GET TASK BLUEPRINT - The most powerful tool. Returns a complete action plan with file patterns, examples to follow, relevant decisions, warnings, and a checklist. Use this FIRST for any development task. Saves 50-70% tokens by eliminating exploration. Task types: 'add-endpoint', 'add-feature', 'add-service', 'fix-bug', 'refactor', 'add-test'.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"task": {Type: "string", Description: "Task type: 'add-endpoint', 'add-feature', 'add-service', 'fix-bug', 'refactor', 'add-test'"},
-					"app":  {Type: "string", Description: "App/module name (e.g., 'smart-smoke', 'notification')"},
-					"path": {Type: "string", Description: "Optional: specific path context for the task"},
+					"task":                 {Type: "string", Description: "Task type: 'add-endpoint', 'add-feature', 'add-service', 'fix-bug', 'refactor', 'add-test'"},
+					"app":                  {Type: "string", Description: "App/module name (e.g., 'smart-smoke', 'notification')"},
+					"path":                 {Type: "string", Description: "Optional: specific path context for the task", Path: true},
+					"max_examples":         {Type: "integer", Description: "Optional: example files to return (default 3 or the project's blueprint.max_examples, at most 10)"},
+					"max_imports_per_type": {Type: "integer", Description: "Optional: imports to return per file type (default 5 or the project's blueprint.max_imports_per_type, at most 20)"},
 				},
 				Required: []string{"task"},
 			},
 		},
+		{
+			Name:        "get_service_card",
+			Description: "GET SERVICE CARD. One-call context for an app/workspace: endpoints, events, models, env vars, key files, owners, open warnings, and which services it depends on or is used by. Use before cross-service work.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"app":  {Type: "string", Description: "App/service name under apps/, services/, packages/ or libs/ (e.g., 'billing')"},
+					"path": {Type: "string", Description: "Or a project-relative directory to describe instead", Path: true},
+				},
+			},
+		},
+		{
+			Name:        "extraction_plan",
+			Description: "PLAN A SERVICE EXTRACTION. For splitting a directory out of the monorepo: what it imports from the rest of the project (grouped by area), which files outside import it, types crossing the boundary, third-party packages, env vars, and the decisions/warnings/insights/patterns that travel with it (shared=true when they also cover code that stays).",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"path":  {Type: "string", Description: "Project-relative directory to extract (e.g., 'apps/billing' or 'internal/payments')", Path: true},
+					"limit": {Type: "integer", Description: "Max entries per dependency and type list (default: 30)"},
+				},
+				Required: []string{"path"},
+			},
+		},
+		{
+			Name:        "get_dependencies_manifest",
+			Description: "GET DEPENDENCY INVENTORY. Third-party packages from package.json, go.mod, requirements/pyproject, Cargo.toml, Gemfile, composer.json, pom.xml and Gradle files, with versions and licenses from lockfiles, the decisions and warnings naming each library, and undeclared_imports: imports of packages no manifest declares. Use before adding, upgrading or removing a library, or for license review. format 'cyclonedx' returns an SBOM.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"ecosystem":  {Type: "string", Description: "Only this ecosystem: npm, golang, pypi, cargo, gem, composer or maven"},
+					"name":       {Type: "string", Description: "Only packages whose name contains this (e.g., 'react')"},
+					"path":       {Type: "string", Description: "Project-relative package directory: its manifests and those above it", Path: true},
+					"transitive": {Type: "boolean", Description: "Include packages only lockfiles pin (default: false)"},
+					"format":     {Type: "string", Description: "'cyclonedx' for a CycloneDX 1.5 SBOM instead of the inventory"},
+					"limit":      {Type: "integer", Description: "Max dependencies listed (default: 200)"},
+				},
+			},
+		},
 		// === GIT INTELLIGENCE TOOLS ===
 		// Mine the team's institutional memory from Git history
 		{
@@ -642,7 +1100,7 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"files": {Type: "array", Description: "List of file paths to find experts for"},
+					"files": {Type: "array", Description: "List of file paths to find experts for", Path: true},
 					"area":  {Type: "string", Description: "Or specify an area/keyword to search (e.g., 'sms', 'payment')"},
 					"limit": {Type: "integer", Description: "Max results to return (default: 20, max: 50)"},
 				},
@@ -654,19 +1112,20 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"file": {Type: "string", Description: "File path to analyze"},
+					"file": {Type: "string", Description: "File path to analyze", Path: true},
 				},
 				Required: []string{"file"},
 			},
 		},
 		{
 			Name:        "get_knowledge_risks",
-			Description: "IDENTIFY BUS FACTOR / KNOWLEDGE RISKS. Shows areas where the main developer left (CRITICAL), single-person ownership (MEDIUM), or few contributors (HIGH). Use when asked about risks, bus factor, knowledge gaps, or team coverage. Returns top risks by severity.",
+			Description: "IDENTIFY BUS FACTOR / KNOWLEDGE RISKS. Shows areas where the main developer left (CRITICAL), single-person ownership (MEDIUM), or few contributors (HIGH). Use when asked about risks, bus factor, knowledge gaps, or team coverage. Returns top risks by severity, with a knowledge-transfer plan for areas whose expert left.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"area":  {Type: "string", Description: "Optional: limit to specific area/directory"},
-					"limit": {Type: "integer", Description: "Max risks to return (default: 30, max: 100)"},
+					"area":           {Type: "string", Description: "Optional: limit to specific area/directory"},
+					"limit":          {Type: "integer", Description: "Max risks to return (default: 30, max: 100)"},
+					"transfer_plans": {Type: "boolean", Description: "Attach knowledge-transfer plans (successors, files to document, onboarding notes) to areas whose expert left (default: true)"},
 				},
 			},
 		},
@@ -676,7 +1135,7 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"file":            {Type: "string", Description: "File to find correlations for"},
+					"file":            {Type: "string", Description: "File to find correlations for", Path: true},
 					"min_correlation": {Type: "number", Description: "Minimum correlation (0-1, default 0.3)"},
 				},
 				Required: []string{"file"},
@@ -688,13 +1147,55 @@ func (s *Server) handleToolsList(req *Request) {
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"file":  {Type: "string", Description: "File path"},
+					"file":  {Type: "string", Description: "File path", Path: true},
 					"lines": {Type: "array", Description: "Optional: specific line numbers to get context for"},
 				},
 				Required: []string{"file"},
 			},
 		},
-	}
+		{
+			Name:        "explain_error",
+			Description: "EXPLAIN AN ERROR. Paste a stack trace or error message (Go, Python, Node, JVM, .NET, Ruby, Rust). Maps frames to project files and returns related warnings and decisions, recent commits touching the failing lines, the experts to ask, and diagnosis leads.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"error":      {Type: "string", Description: "The stack trace or error message"},
+					"max_frames": {Type: "integer", Description: "Max project frames to analyze, innermost first (default 5)"},
+				},
+				Required: []string{"error"},
+			},
+		},
 
-	s.sendResult(req.ID, map[string]interface{}{"tools": tools})
+		// === ENVIRONMENT TOOLS ===
+		{
+			Name:        "get_capabilities",
+			Description: "CHECK WHAT WORKS HERE. Reports which tools are fully functional, degraded, or disabled in the current environment (git, ripgrep, pre-computed git knowledge, tree index). TeamContext runs offline; only link_issue and linked ticket status call a configured issue tracker.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"refresh": {Type: "boolean", Description: "Re-run environment detection instead of using the startup result"},
+				},
+			},
+		},
+		{
+			Name:        "get_health",
+			Description: "Is TeamContext working? Reports setup problems with the command that fixes each (empty or stale index, git history never analyzed), whether the background worker is running with its counters, and the errors it logged in the last 24 hours.",
+			InputSchema: InputSchema{
+				Type: "object",
+			},
+		},
+		{
+			Name:        "get_worker_log",
+			Description: "Query the background worker's log (reindexes, git changes, errors), newest first. Use it when get_health reports errors or results look out of date.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"level":    {Type: "string", Description: "Least severe level to return: info (default, everything), warn, or error"},
+					"since":    {Type: "string", Description: "Only entries after this: 7d, 24h, 30m, or a date (2024-01-15)"},
+					"contains": {Type: "string", Description: "Only entries whose message contains this text (case-insensitive)"},
+					"limit":    {Type: "number", Description: "Maximum entries (default: 50)"},
+				},
+			},
+		},
+	}
 }
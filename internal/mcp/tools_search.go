@@ -2,11 +2,20 @@ package mcp
 
 import (
 "encoding/json"
+"errors"
 "fmt"
+"path/filepath"
 "sort"
 "strings"
+"time"
+"unicode"
+"unicode/utf8"
 
 "github.com/saeedalam/teamcontext/internal/git"
+"github.com/saeedalam/teamcontext/internal/relpath"
+"github.com/saeedalam/teamcontext/internal/search"
+"github.com/saeedalam/teamcontext/internal/storage"
+"github.com/saeedalam/teamcontext/internal/tokenest"
 "github.com/saeedalam/teamcontext/pkg/types"
 )
 
@@ -23,6 +32,9 @@ func (s *Server) handleQuery(params json.RawMessage) (interface{}, error) {
 		Scope       string `json:"scope"`
 		IncludeCode bool   `json:"include_code"`
 		MaxTokens   int    `json:"max_tokens"`
+		Language    string `json:"language"`
+		Deep        bool   `json:"deep"`
+		BudgetMs    int    `json:"budget_ms"`
 	}
 	json.Unmarshal(params, &p)
 
@@ -30,54 +42,76 @@ func (s *Server) handleQuery(params json.RawMessage) (interface{}, error) {
 		return nil, fmt.Errorf("question is required")
 	}
 
+	scope, err := s.parseQueryScope(p.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deep queries search further and list more of what they find
+	limits := struct{ decisions, warnings, files, semantic int }{10, 5, 10, 10}
+	if p.Deep {
+		limits.decisions, limits.warnings, limits.files, limits.semantic = 20, 10, 20, 25
+	}
+
 	// Get all relevant knowledge
 	decisions, _ := s.jsonStore.GetDecisions()
 	warnings, _ := s.jsonStore.GetWarnings()
 	patterns, _ := s.jsonStore.GetPatterns()
 
-	// Filter by scope if specified
-	var featureID string
-	if len(p.Scope) > 8 && p.Scope[:8] == "feature:" {
-		featureID = p.Scope[8:]
+	// Scoped knowledge is judged by the language of its related files
+	var indexed map[string]types.FileIndex
+	if scope.narrowed() {
+		indexed, _ = s.jsonStore.GetFilesIndex()
 	}
+	languageOf := scopedLanguage(indexed)
 
-	// Simple keyword-based relevance scoring
+	// Simple keyword-based relevance scoring. Every search path records
+	// its hits in one set, so items found twice are listed once.
 	query := p.Question
-	var relevantDecisions []types.Decision
-	var relevantWarnings []types.Warning
+	hits := newRelevanceSet()
+	hits.weights = s.currentConfig().Search.Weights
+	decisionsByID := make(map[string]types.Decision)
+	warningsByID := make(map[string]types.Warning)
 
 	for _, d := range decisions {
-		if featureID != "" && d.Feature != featureID {
+		if !scope.allowsKnowledge(d.ID, d.Feature, d.RelatedFiles, languageOf) {
 			continue
 		}
+		decisionsByID[d.ID] = d
 		// Simple relevance check - contains query terms
-		if containsAny(d.Content+d.Reason+d.Context, query) {
-			relevantDecisions = append(relevantDecisions, d)
+		if text := knowledgeText(d.Translations, d.Content, d.Reason, d.Context); containsAny(text, query) {
+			hits.add("decision", d.ID, "keyword", 0.5)
+			hits.explain("decision", d.ID, knowledgeReason(d.Tags, text, query))
 		}
 	}
 
 	for _, w := range warnings {
-		if featureID != "" && w.Feature != featureID {
+		if !scope.allowsKnowledge(w.ID, w.Feature, w.RelatedFiles, languageOf) {
 			continue
 		}
-		if containsAny(w.Content+w.Reason+w.Evidence, query) {
-			relevantWarnings = append(relevantWarnings, w)
+		warningsByID[w.ID] = w
+		if text := knowledgeText(w.Translations, w.Content, w.Reason, w.Evidence); containsAny(text, query) {
+			hits.add("warning", w.ID, "keyword", 0.5)
+			hits.explain("warning", w.ID, knowledgeReason(w.Tags, text, query))
 		}
 	}
 
-	// Limit results
-	if len(relevantDecisions) > 10 {
-		relevantDecisions = relevantDecisions[:10]
+	// Search indexed files; a scope filters afterwards, so fetch more
+	fileLimit := 10
+	if scope.narrowed() {
+		fileLimit = 50
 	}
-	if len(relevantWarnings) > 5 {
-		relevantWarnings = relevantWarnings[:5]
-	}
-
-	// Search indexed files
-	var relevantFiles []types.FileIndex
-	files, err := s.sqliteIndex.SearchFiles(query, "", 10)
-	if err == nil && len(files) > 0 {
-		relevantFiles = files
+	filesByPath := make(map[string]types.FileIndex)
+	files, err := s.sqliteIndex.SearchFiles(query, "", fileLimit)
+	if err == nil {
+		for _, f := range files {
+			if !scope.allowsFile(f.Path, f.Language) {
+				continue
+			}
+			filesByPath[f.Path] = f
+			hits.add("file", f.Path, "keyword", 0.5)
+			hits.explain("file", f.Path, fileReason(f, query))
+		}
 	}
 
 	// Search git experts for matching contributors or areas
@@ -87,6 +121,9 @@ func (s *Server) handleQuery(params json.RawMessage) (interface{}, error) {
 		queryLower := strings.ToLower(query)
 		queryWords := strings.Fields(queryLower)
 		for _, de := range cachedExperts {
+			if !scope.allowsLocation(de.Directory) {
+				continue
+			}
 			dirLower := strings.ToLower(de.Directory)
 			dirMatch := false
 			for _, w := range queryWords {
@@ -105,11 +142,15 @@ func (s *Server) handleQuery(params json.RawMessage) (interface{}, error) {
 					Area:      de.Directory,
 					Ownership: expert.Ownership,
 					Active:    expert.Active,
+					Reason:    ownershipReason(expert, de.Directory),
 				})
 			}
 		}
 		// Also match by contributor name
 		for _, de := range cachedExperts {
+			if !scope.allowsLocation(de.Directory) {
+				continue
+			}
 			for _, expert := range de.TopExperts {
 				nameLower := strings.ToLower(expert.Name)
 				for _, w := range queryWords {
@@ -129,6 +170,7 @@ func (s *Server) handleQuery(params json.RawMessage) (interface{}, error) {
 								Area:      de.Directory,
 								Ownership: expert.Ownership,
 								Active:    expert.Active,
+								Reason:    fmt.Sprintf("name matches %q; %s", w, ownershipReason(expert, de.Directory)),
 							})
 						}
 						break
@@ -148,91 +190,228 @@ func (s *Server) handleQuery(params json.RawMessage) (interface{}, error) {
 	if engine != nil && len(engine.Vocabulary) > 0 {
 		queryVec := engine.Vectorize(query)
 		if queryVec != nil {
-			semanticResults, err := s.sqliteIndex.SearchSemantic(queryVec, "", 10)
+			semanticResults, err := s.sqliteIndex.SearchSemantic(queryVec, "", limits.semantic)
 			if err == nil && len(semanticResults) > 0 {
 				semanticSource = "tfidf"
-				// Merge semantic decisions/warnings that weren't already found by keyword
-				existingDecIDs := make(map[string]bool)
-				for _, d := range relevantDecisions {
-					existingDecIDs[d.ID] = true
-				}
-				existingWarnIDs := make(map[string]bool)
-				for _, w := range relevantWarnings {
-					existingWarnIDs[w.ID] = true
-				}
-
-				var semanticConvIDs []string
+				// Merge semantic hits into the keyword ones
 				for _, sr := range semanticResults {
 					switch sr.DocType {
 					case "decision":
-						if !existingDecIDs[sr.ID] {
-							for _, d := range decisions {
-								if d.ID == sr.ID {
-									relevantDecisions = append(relevantDecisions, d)
-									break
-								}
-							}
+						if _, ok := decisionsByID[sr.ID]; ok {
+							hits.add("decision", sr.ID, "semantic", sr.Similarity)
+							hits.explain("decision", sr.ID, semanticReason(sr.Similarity))
 						}
 					case "warning":
-						if !existingWarnIDs[sr.ID] {
-							for _, w := range warnings {
-								if w.ID == sr.ID {
-									relevantWarnings = append(relevantWarnings, w)
-									break
-								}
+						if _, ok := warningsByID[sr.ID]; ok {
+							hits.add("warning", sr.ID, "semantic", sr.Similarity)
+							hits.explain("warning", sr.ID, semanticReason(sr.Similarity))
+						}
+					case "file":
+						if _, ok := filesByPath[sr.ID]; !ok {
+							if indexed == nil {
+								indexed, _ = s.jsonStore.GetFilesIndex()
 							}
+							f, ok := indexed[sr.ID]
+							if !ok || f.DeletedAt != nil || !scope.allowsFile(f.Path, f.Language) {
+								continue
+							}
+							filesByPath[sr.ID] = f
 						}
+						hits.add("file", sr.ID, "semantic", sr.Similarity)
+						hits.explain("file", sr.ID, semanticReason(sr.Similarity))
 					case "conversation":
-						semanticConvIDs = append(semanticConvIDs, sr.ID)
+						hits.add("conversation", sr.ID, "semantic", sr.Similarity)
+						hits.explain("conversation", sr.ID, semanticReason(sr.Similarity))
 					}
 				}
+			}
+		}
+	}
 
-				// Load matching conversations from semantic results
-				if len(semanticConvIDs) > 0 {
-					allConvs, convErr := s.jsonStore.GetAllConversations()
-					if convErr == nil {
-						convIDSet := make(map[string]bool)
-						for _, cid := range semanticConvIDs {
-							convIDSet[cid] = true
-						}
-						for _, c := range allConvs {
-							if convIDSet[c.ID] {
-								relevantConversations = append(relevantConversations, c)
-							}
-						}
-					}
+	// Deep mode follows what was found so far, within its budget
+	var deep *deepQuery
+	if p.Deep {
+		deep = newDeepQuery(s, query, deepBudget(p.BudgetMs))
+		deep.scope, deep.hits = scope, hits
+		deep.decisions, deep.warnings = decisionsByID, warningsByID
+		deep.files, deep.indexed = filesByPath, indexed
+		deep.step("graph", deep.graph)
+		deep.step("history", deep.commitHistory)
+		deep.step("conversations", deep.conversations)
+	}
+
+	// Relevance feedback from earlier searches, applied to the merged
+	// scores before results are cut
+	fb := s.loadFeedbackModel()
+	if fb != nil {
+		hits.rescore(fb, query)
+	}
+
+	// Load matching conversations from semantic results, best first
+	if convIDs := hits.ranked("conversation"); len(convIDs) > 0 {
+		if allConvs, err := s.jsonStore.GetAllConversations(); err == nil {
+			convsByID := make(map[string]types.Conversation)
+			for _, c := range allConvs {
+				if scope.allowsFeature(c.Feature) {
+					convsByID[c.ID] = c
+				}
+			}
+			for _, id := range convIDs {
+				if c, ok := convsByID[id]; ok {
+					relevantConversations = append(relevantConversations, c)
 				}
 			}
 		}
 	}
 
+	// Documentation sections are more specific than the file they are in
+	var docs []types.DocHit
+	for _, d := range s.searchDocs(query, 5) {
+		if scope.allowsLocation(d.Path) {
+			docs = append(docs, d)
+		}
+	}
+	for _, d := range docs {
+		if hits.has("file", d.Path) {
+			hits.remove("file", d.Path)
+			hits.duplicates++
+		}
+	}
+
+	// Limit results, best merged relevance first
+	decisionIDs := hits.ranked("decision")
+	if len(decisionIDs) > limits.decisions {
+		decisionIDs = decisionIDs[:limits.decisions]
+	}
+	warningIDs := hits.ranked("warning")
+	if len(warningIDs) > limits.warnings {
+		warningIDs = warningIDs[:limits.warnings]
+	}
+	fileIDs := hits.ranked("file")
+	if len(fileIDs) > limits.files {
+		fileIDs = fileIDs[:limits.files]
+	}
+	var relevantDecisions []types.Decision
+	for _, id := range decisionIDs {
+		relevantDecisions = append(relevantDecisions, decisionsByID[id])
+	}
+	var relevantWarnings []types.Warning
+	for _, id := range warningIDs {
+		relevantWarnings = append(relevantWarnings, warningsByID[id])
+	}
+	var relevantFiles []types.FileIndex
+	for _, path := range fileIDs {
+		relevantFiles = append(relevantFiles, filesByPath[path])
+	}
+
+	// Name who knows the files of the answer, not only experts by name
+	if deep != nil {
+		deep.step("experts", func() bool {
+			var ok bool
+			gitExperts, ok = deep.crossReferenceExperts(relevantFiles, scopedExperts(cachedExperts, scope), gitExperts)
+			return ok
+		})
+	}
+
+	// Doc sections come back in FTS rank order without a score
+	docs = rerankByFeedback(fb, docs, "doc", query, func(d types.DocHit) string { return d.Path })
+
+	// Serve knowledge in the caller's working language
+	if lang := preferredLanguage(p.Language); lang != "" {
+		for i := range relevantDecisions {
+			relevantDecisions[i] = localizeDecision(relevantDecisions[i], lang)
+		}
+		for i := range relevantWarnings {
+			relevantWarnings[i] = localizeWarning(relevantWarnings[i], lang)
+		}
+	}
+
+	var snippets []types.CodeSnippet
+	if p.IncludeCode {
+		snippets = s.contextSnippets(query, 5, scope)
+	}
+
+	var sources []types.Source
+	sources = append(sources, hits.sources("decision", decisionIDs)...)
+	sources = append(sources, hits.sources("warning", warningIDs)...)
+	sources = append(sources, hits.sources("file", fileIDs)...)
+
+	// What the project is built with, recorded by the worker from manifests
+	var frameworks []string
+	if project, err := s.jsonStore.GetProject(); err == nil && project != nil && asksAboutFrameworks(query, project.Frameworks) {
+		frameworks = project.Frameworks
+	}
+
 	resp := &types.QueryResponse{
+		Sources:       sources,
 		Decisions:     relevantDecisions,
 		Warnings:      relevantWarnings,
 		Patterns:      patterns,
 		Files:         relevantFiles,
 		GitExperts:    gitExperts,
 		Conversations: relevantConversations,
+		Docs:          docs,
+		Snippets:      snippets,
+		Frameworks:    frameworks,
+		Merged:        hits.duplicates,
+	}
+	if deep != nil {
+		resp.History = deep.history
+		resp.Deep = deep.finish()
 	}
 	_ = semanticSource // available for future use in response metadata
 	return resp, nil
 }
 
+// stackWords mark a question about what the project is built with
+var stackWords = []string{"framework", "stack", "built with", "library", "libraries"}
+
+// asksAboutFrameworks reports whether a question is about the project's
+// stack or names one of its frameworks ("gin" names go-gin)
+func asksAboutFrameworks(question string, frameworks []string) bool {
+	if len(frameworks) == 0 {
+		return false
+	}
+	question = strings.ToLower(question)
+	for _, w := range stackWords {
+		if strings.Contains(question, w) {
+			return true
+		}
+	}
+	words := strings.FieldsFunc(question, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, f := range frameworks {
+		for _, part := range strings.Split(f, "-") {
+			if len(part) > 2 && containsString(words, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 	var p struct {
 		Intent           string   `json:"intent"`
 		TargetFiles      []string `json:"target_files"`
+		TargetSymbols    []string `json:"target_symbols"`
 		ProposedApproach string   `json:"proposed_approach"`
 		MaxTokens        int      `json:"max_tokens"`
+		Language         string   `json:"language"`
+		Persona          string   `json:"persona"`
 	}
 	json.Unmarshal(params, &p)
 
 	if p.Intent == "" {
 		return nil, fmt.Errorf("intent is required")
 	}
+	sections, err := contextSections(p.Persona)
+	if err != nil {
+		return nil, err
+	}
 	if p.MaxTokens <= 0 {
 		p.MaxTokens = 8000
 	}
+	p.TargetFiles = relpath.NormalizeAll(p.TargetFiles)
 
 	// Get all knowledge
 	decisions, _ := s.jsonStore.GetDecisions()
@@ -257,20 +436,71 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 	var scoredWarns []scoredWarning
 	var scoredPats []scoredPattern
 
-	existingDecIDs := make(map[string]bool)
-	existingWarnIDs := make(map[string]bool)
+	// An item found again by another path (semantic, graph, ancestors) is
+	// merged into its earlier entry instead of being listed twice
+	decIndex := make(map[string]int)
+	warnIndex := make(map[string]int)
+	merged := 0
+
+	// With target symbols, warnings anchored elsewhere in the target files
+	// are left out however they are found
+	locator := newAnchorLocator(filepath.Dir(s.basePath))
+	outsideTargets := make(map[string]bool)
+	if len(p.TargetSymbols) > 0 {
+		targets := locator.targetRegions(p.TargetFiles, p.TargetSymbols)
+		for _, w := range warnings {
+			if len(w.Anchors) == 0 {
+				continue
+			}
+			w.Anchors = locator.locateAll(w.Anchors)
+			if !inTargetRegions(w, targets) {
+				outsideTargets[w.ID] = true
+			}
+		}
+	}
+	addDecision := func(d types.Decision, score float64) {
+		if i, ok := decIndex[d.ID]; ok {
+			scoredDecs[i].score = mergeRelevance(scoredDecs[i].score, score)
+			merged++
+			return
+		}
+		decIndex[d.ID] = len(scoredDecs)
+		scoredDecs = append(scoredDecs, scoredDecision{d, score})
+	}
+	addWarning := func(w types.Warning, score float64) {
+		if outsideTargets[w.ID] {
+			return
+		}
+		if i, ok := warnIndex[w.ID]; ok {
+			scoredWarns[i].score = mergeRelevance(scoredWarns[i].score, score)
+			merged++
+			return
+		}
+		warnIndex[w.ID] = len(scoredWarns)
+		scoredWarns = append(scoredWarns, scoredWarning{w, score})
+	}
+	decisionsByID := make(map[string]types.Decision, len(decisions))
+	for _, d := range decisions {
+		decisionsByID[d.ID] = d
+	}
+	warningsByID := make(map[string]types.Warning, len(warnings))
+	for _, w := range warnings {
+		warningsByID[w.ID] = w
+	}
 
 	// Score decisions
+	mentioned := s.decisionMentions()
 	for _, d := range decisions {
 		score := 0.0
 		if hasOverlap(d.RelatedFiles, p.TargetFiles) {
 			score = 1.0 // file path match
-		} else if containsAny(d.Content+d.Reason, p.Intent) {
+		} else if hasOverlap(mentioned[d.ID], p.TargetFiles) {
+			score = 0.8 // mentions a symbol the files export
+		} else if containsAny(knowledgeText(d.Translations, d.Content, d.Reason), p.Intent) {
 			score = 0.5 // keyword match
 		}
 		if score > 0 {
-			scoredDecs = append(scoredDecs, scoredDecision{d, score})
-			existingDecIDs[d.ID] = true
+			addDecision(d, score)
 		}
 	}
 
@@ -279,15 +509,14 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 		score := 0.0
 		if hasOverlap(w.RelatedFiles, p.TargetFiles) {
 			score = 1.0
-		} else if containsAny(w.Content+w.Reason, p.Intent) {
+		} else if containsAny(knowledgeText(w.Translations, w.Content, w.Reason), p.Intent) {
 			score = 0.5
 		}
 		if w.Severity == "critical" && score > 0 {
 			score += 0.5 // boost critical warnings
 		}
 		if score > 0 {
-			scoredWarns = append(scoredWarns, scoredWarning{w, score})
-			existingWarnIDs[w.ID] = true
+			addWarning(w, score)
 		}
 	}
 
@@ -308,24 +537,12 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 				for _, sr := range semanticResults {
 					switch sr.DocType {
 					case "decision":
-						if !existingDecIDs[sr.ID] {
-							for _, d := range decisions {
-								if d.ID == sr.ID {
-									scoredDecs = append(scoredDecs, scoredDecision{d, sr.Similarity})
-									existingDecIDs[d.ID] = true
-									break
-								}
-							}
+						if d, ok := decisionsByID[sr.ID]; ok {
+							addDecision(d, sr.Similarity)
 						}
 					case "warning":
-						if !existingWarnIDs[sr.ID] {
-							for _, w := range warnings {
-								if w.ID == sr.ID {
-									scoredWarns = append(scoredWarns, scoredWarning{w, sr.Similarity})
-									existingWarnIDs[w.ID] = true
-									break
-								}
-							}
+						if w, ok := warningsByID[sr.ID]; ok {
+							addWarning(w, sr.Similarity)
 						}
 					}
 				}
@@ -333,79 +550,52 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 		}
 	}
 
-	// Graph traversal: find connected knowledge for target files
+	// Graph traversal: find connected knowledge for target files. Each item
+	// counts once per file, however many edges lead to it.
 	for _, tf := range p.TargetFiles {
 		edges, err := s.jsonStore.TraverseGraph("file", tf, 2)
 		if err != nil {
 			continue
 		}
+		seen := make(map[string]bool)
 		for _, e := range edges {
-			if e.ToType == "decision" && !existingDecIDs[e.ToID] {
-				for _, d := range decisions {
-					if d.ID == e.ToID {
-						scoredDecs = append(scoredDecs, scoredDecision{d, 0.7})
-						existingDecIDs[d.ID] = true
-						break
-					}
+			for _, node := range [][2]string{{e.ToType, e.ToID}, {e.FromType, e.FromID}} {
+				if seen[node[0]+":"+node[1]] {
+					continue
 				}
-			}
-			if e.ToType == "warning" && !existingWarnIDs[e.ToID] {
-				for _, w := range warnings {
-					if w.ID == e.ToID {
-						scoredWarns = append(scoredWarns, scoredWarning{w, 0.7})
-						existingWarnIDs[w.ID] = true
-						break
+				seen[node[0]+":"+node[1]] = true
+				switch node[0] {
+				case "decision":
+					if d, ok := decisionsByID[node[1]]; ok {
+						addDecision(d, 0.7)
 					}
-				}
-			}
-			if e.FromType == "decision" && !existingDecIDs[e.FromID] {
-				for _, d := range decisions {
-					if d.ID == e.FromID {
-						scoredDecs = append(scoredDecs, scoredDecision{d, 0.7})
-						existingDecIDs[d.ID] = true
-						break
-					}
-				}
-			}
-			if e.FromType == "warning" && !existingWarnIDs[e.FromID] {
-				for _, w := range warnings {
-					if w.ID == e.FromID {
-						scoredWarns = append(scoredWarns, scoredWarning{w, 0.7})
-						existingWarnIDs[w.ID] = true
-						break
+				case "warning":
+					if w, ok := warningsByID[node[1]]; ok {
+						addWarning(w, 0.7)
 					}
 				}
 			}
 		}
 	}
 
-	// Merge ancestor context
+	// Merge ancestor context (features sharing an ancestor count it once)
 	features, _ := s.jsonStore.GetFeatures()
+	inherited := make(map[string]bool)
 	for _, feat := range features {
 		if feat.Status == "active" && feat.Extends != "" {
 			ancestors, err := s.jsonStore.GetFeatureAncestors(feat.ID, 5)
 			if err == nil {
 				for _, ancestor := range ancestors {
 					for _, decID := range ancestor.Decisions {
-						if !existingDecIDs[decID] {
-							for _, d := range decisions {
-								if d.ID == decID {
-									scoredDecs = append(scoredDecs, scoredDecision{d, 0.6})
-									existingDecIDs[d.ID] = true
-									break
-								}
-							}
+						if d, ok := decisionsByID[decID]; ok && !inherited[decID] {
+							inherited[decID] = true
+							addDecision(d, 0.6)
 						}
 					}
 					for _, warnID := range ancestor.Warnings {
-						if !existingWarnIDs[warnID] {
-							for _, w := range warnings {
-								if w.ID == warnID {
-									scoredWarns = append(scoredWarns, scoredWarning{w, 0.6})
-									existingWarnIDs[w.ID] = true
-									break
-								}
-							}
+						if w, ok := warningsByID[warnID]; ok && !inherited[warnID] {
+							inherited[warnID] = true
+							addWarning(w, 0.6)
 						}
 					}
 				}
@@ -413,46 +603,131 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 		}
 	}
 
+	// Relevance feedback from earlier searches
+	feedback := s.loadFeedbackModel()
+	if feedback != nil {
+		for i := range scoredDecs {
+			scoredDecs[i].score = feedback.Rescore("decision", scoredDecs[i].decision.ID, p.Intent, scoredDecs[i].score)
+		}
+		for i := range scoredWarns {
+			scoredWarns[i].score = feedback.Rescore("warning", scoredWarns[i].warning.ID, p.Intent, scoredWarns[i].score)
+		}
+		for i := range scoredPats {
+			scoredPats[i].score = feedback.Rescore("pattern", scoredPats[i].pattern.ID, p.Intent, scoredPats[i].score)
+		}
+	}
+
 	// Sort by score descending
 	sort.Slice(scoredDecs, func(i, j int) bool { return scoredDecs[i].score > scoredDecs[j].score })
 	sort.Slice(scoredWarns, func(i, j int) bool { return scoredWarns[i].score > scoredWarns[j].score })
 	sort.Slice(scoredPats, func(i, j int) bool { return scoredPats[i].score > scoredPats[j].score })
 
-	// Token budget filling
-	estimateTokens := func(text string) int { return len(text) / 4 }
-	tokensUsed := 0
-
-	// 1. Critical warnings first
-	var relevantWarnings []types.Warning
-	for _, sw := range scoredWarns {
-		cost := estimateTokens(sw.warning.Content + sw.warning.Reason)
-		if tokensUsed+cost > p.MaxTokens {
-			break
+	// Serve knowledge in the caller's working language, before costing it
+	if lang := preferredLanguage(p.Language); lang != "" {
+		for i := range scoredDecs {
+			scoredDecs[i].decision = localizeDecision(scoredDecs[i].decision, lang)
 		}
-		relevantWarnings = append(relevantWarnings, sw.warning)
-		tokensUsed += cost
-	}
-
-	// 2. Decisions matching target files
-	var relevantDecisions []types.Decision
-	for _, sd := range scoredDecs {
-		cost := estimateTokens(sd.decision.Content + sd.decision.Reason)
-		if tokensUsed+cost > p.MaxTokens {
-			break
+		for i := range scoredWarns {
+			scoredWarns[i].warning = localizeWarning(scoredWarns[i].warning, lang)
 		}
-		relevantDecisions = append(relevantDecisions, sd.decision)
-		tokensUsed += cost
 	}
 
-	// 3. Patterns
+	// Token budget filling, in the order the persona needs the sections
+	estimateTokens := tokenest.Count
+	tokensUsed := 0
+
+	var relevantWarnings []types.Warning
+	var relevantDecisions []types.Decision
 	var relevantPatterns []types.Pattern
-	for _, sp := range scoredPats {
-		cost := estimateTokens(sp.pattern.Name + sp.pattern.Description)
-		if tokensUsed+cost > p.MaxTokens {
-			break
+	var snippets []types.CodeSnippet
+	var compliance []types.ComplianceViolation
+	var correlations []types.CoChangeHit
+	var architecture *types.Architecture
+	var drift []types.DriftFinding
+	for _, section := range sections {
+		switch section {
+		case "warnings":
+			for _, sw := range scoredWarns {
+				cost := estimateTokens(sw.warning.Content + sw.warning.Reason)
+				if tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				sw.warning.Anchors = locator.locateAll(sw.warning.Anchors)
+				relevantWarnings = append(relevantWarnings, sw.warning)
+				tokensUsed += cost
+			}
+		case "decisions":
+			for _, sd := range scoredDecs {
+				cost := estimateTokens(sd.decision.Content + sd.decision.Reason)
+				if tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				relevantDecisions = append(relevantDecisions, sd.decision)
+				tokensUsed += cost
+			}
+		case "patterns":
+			for _, sp := range scoredPats {
+				cost := estimateTokens(sp.pattern.Name + sp.pattern.Description)
+				if tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				relevantPatterns = append(relevantPatterns, sp.pattern)
+				tokensUsed += cost
+			}
+		case "snippets":
+			for _, sn := range s.contextSnippets(p.Intent, 5, nil) {
+				cost := estimateTokens(sn.Content)
+				if tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				sn.TokenCount = cost
+				snippets = append(snippets, sn)
+				tokensUsed += cost
+			}
+		case "compliance":
+			for _, v := range s.contextCompliance(p.TargetFiles) {
+				cost := estimateTokens(v.Message)
+				if tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				compliance = append(compliance, v)
+				tokensUsed += cost
+			}
+		case "correlations":
+			for _, c := range s.contextCorrelations(p.TargetFiles, 0.3) {
+				cost := estimateTokens(c.File + c.ChangesWith)
+				if len(correlations) == 10 || tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				correlations = append(correlations, c)
+				tokensUsed += cost
+			}
+		case "architecture":
+			if a := s.contextArchitecture(p.TargetFiles); a != nil {
+				data, _ := json.Marshal(a)
+				if cost := estimateTokens(string(data)); tokensUsed+cost <= p.MaxTokens {
+					architecture = a
+					tokensUsed += cost
+				}
+			}
+		case "drift":
+			var decs []types.Decision
+			for _, sd := range scoredDecs {
+				decs = append(decs, sd.decision)
+			}
+			var warns []types.Warning
+			for _, sw := range scoredWarns {
+				warns = append(warns, sw.warning)
+			}
+			for _, f := range s.contextDrift(p.TargetFiles, decs, warns) {
+				cost := estimateTokens(f.Detail)
+				if tokensUsed+cost > p.MaxTokens {
+					break
+				}
+				drift = append(drift, f)
+				tokensUsed += cost
+			}
 		}
-		relevantPatterns = append(relevantPatterns, sp.pattern)
-		tokensUsed += cost
 	}
 
 	// 4. Files
@@ -460,6 +735,7 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 	fileList = append(fileList, p.TargetFiles...)
 	searchedFiles, err := s.sqliteIndex.SearchFiles(p.Intent, "", 10)
 	if err == nil {
+		searchedFiles = rerankByFeedback(feedback, searchedFiles, "file", p.Intent, func(f types.FileIndex) string { return f.Path })
 		for _, f := range searchedFiles {
 			fileList = append(fileList, f.Path)
 		}
@@ -517,6 +793,21 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 	if len(relevantDecisions) > 0 {
 		suggestions = append(suggestions, "Consider existing decisions that may affect your approach")
 	}
+	blockers := 0
+	for _, v := range compliance {
+		if v.Severity == "blocker" {
+			blockers++
+		}
+	}
+	if blockers > 0 {
+		suggestions = append(suggestions, fmt.Sprintf("Resolve %d compliance blocker(s) before approving", blockers))
+	}
+	if len(correlations) > 0 {
+		suggestions = append(suggestions, fmt.Sprintf("%d file(s) usually change together with these - check they were updated too", len(correlations)))
+	}
+	if len(drift) > 0 {
+		suggestions = append(suggestions, fmt.Sprintf("%d drift finding(s) - update the recorded knowledge or bring the code back in line", len(drift)))
+	}
 	arch, _ := s.jsonStore.GetArchitecture()
 	if arch != nil && arch.Description != "" {
 		if containsAny(arch.Description, p.Intent) {
@@ -524,14 +815,45 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 		}
 	}
 
+	// Sensitive path policies are mandatory, so they bypass the budget
+	sensitive := s.jsonStore.SensitivePathsFor(p.TargetFiles...)
+	var reviewers []string
+	if len(sensitive) > 0 {
+		var notices []string
+		for _, policy := range sensitive {
+			notice := "Touches sensitive path " + policy.Path
+			if policy.Reason != "" {
+				notice += " (" + policy.Reason + ")"
+			}
+			notices = append(notices, notice+": follow its warnings and checklist")
+			for _, r := range policy.Reviewers {
+				reviewers = appendUnique(reviewers, r)
+			}
+			tokensUsed += estimateTokens(strings.Join(policy.Warnings, " ") + strings.Join(policy.Checklist, " "))
+		}
+		if len(reviewers) > 0 {
+			notices = append(notices, "Required reviewers: "+strings.Join(reviewers, ", "))
+		}
+		suggestions = append(notices, suggestions...)
+	}
+
 	return &types.ContextResponse{
-		Intent:      p.Intent,
-		Decisions:   relevantDecisions,
-		Warnings:    relevantWarnings,
-		Patterns:    relevantPatterns,
-		Files:       fileList,
-		Suggestions: suggestions,
-		GitExperts:  gitExperts,
+		Intent:            p.Intent,
+		Decisions:         relevantDecisions,
+		Warnings:          relevantWarnings,
+		Patterns:          relevantPatterns,
+		Files:             fileList,
+		Suggestions:       suggestions,
+		GitExperts:        gitExperts,
+		Sensitive:         sensitive,
+		RequiredReviewers: reviewers,
+		Merged:            merged,
+		Persona:           p.Persona,
+		Snippets:          snippets,
+		Compliance:        compliance,
+		Correlations:      correlations,
+		Architecture:      architecture,
+		Drift:             drift,
 		TokenBudget: &types.TokenBudget{
 			Requested: p.MaxTokens,
 			Used:      tokensUsed,
@@ -542,9 +864,10 @@ func (s *Server) handleGetContext(params json.RawMessage) (interface{}, error) {
 
 func (s *Server) handleSearch(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		Query string   `json:"query"`
-		Types []string `json:"types"`
-		Limit int      `json:"limit"`
+		Query    string   `json:"query"`
+		Types    []string `json:"types"`
+		Limit    int      `json:"limit"`
+		Language string   `json:"language"`
 	}
 	json.Unmarshal(params, &p)
 
@@ -557,28 +880,51 @@ func (s *Server) handleSearch(params json.RawMessage) (interface{}, error) {
 	}
 
 	results := make(map[string]interface{})
+	feedback := s.loadFeedbackModel()
+	merged := 0
 
 	// Search decisions
 	if len(p.Types) == 0 || containsString(p.Types, "decision") {
 		decisions, _ := s.sqliteIndex.SearchDecisions(p.Query, "", "", p.Limit)
+		decisions = s.localizeDecisions(decisions, preferredLanguage(p.Language))
 		if len(decisions) > 0 {
-			results["decisions"] = decisions
+			results["decisions"] = rerankByFeedback(feedback, decisions, "decision", p.Query, func(d types.Decision) string { return d.ID })
 		}
 	}
 
 	// Search warnings
 	if len(p.Types) == 0 || containsString(p.Types, "warning") {
 		warnings, _ := s.sqliteIndex.SearchWarnings(p.Query, "", "", p.Limit)
+		warnings = s.localizeWarnings(warnings, preferredLanguage(p.Language))
 		if len(warnings) > 0 {
-			results["warnings"] = warnings
+			results["warnings"] = rerankByFeedback(feedback, warnings, "warning", p.Query, func(w types.Warning) string { return w.ID })
+		}
+	}
+
+	// Search documentation (READMEs, docs/)
+	docPaths := make(map[string]bool)
+	if len(p.Types) == 0 || containsString(p.Types, "doc") {
+		if docs := s.searchDocs(p.Query, p.Limit); len(docs) > 0 {
+			results["docs"] = rerankByFeedback(feedback, docs, "doc", p.Query, func(d types.DocHit) string { return d.Path })
+			for _, d := range docs {
+				docPaths[d.Path] = true
+			}
 		}
 	}
 
-	// Search files
+	// Search files, skipping those already cited by a documentation section
 	if len(p.Types) == 0 || containsString(p.Types, "file") {
 		files, _ := s.sqliteIndex.SearchFiles(p.Query, "", p.Limit)
-		if len(files) > 0 {
-			results["files"] = files
+		var kept []types.FileIndex
+		for _, f := range files {
+			if docPaths[f.Path] {
+				merged++
+				continue
+			}
+			kept = append(kept, f)
+		}
+		if len(kept) > 0 {
+			results["files"] = rerankByFeedback(feedback, kept, "file", p.Query, func(f types.FileIndex) string { return f.Path })
 		}
 	}
 
@@ -592,13 +938,92 @@ func (s *Server) handleSearch(params json.RawMessage) (interface{}, error) {
 			}
 		}
 		if len(matchedPatterns) > 0 {
-			results["patterns"] = matchedPatterns
+			results["patterns"] = rerankByFeedback(feedback, matchedPatterns, "pattern", p.Query, func(pat types.Pattern) string { return pat.ID })
 		}
 	}
 
+	if merged > 0 {
+		results["duplicates_merged"] = merged
+	}
 	return results, nil
 }
 
+// handleRateResult records whether a search result was helpful for a query.
+// Later searches with similar queries boost or demote the result.
+func (s *Server) handleRateResult(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Query   string `json:"query"`
+		Type    string `json:"type"`
+		ID      string `json:"id"`
+		Helpful *bool  `json:"helpful"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Query == "" || p.ID == "" {
+		return nil, fmt.Errorf("query and id are required")
+	}
+	if p.Helpful == nil {
+		return nil, fmt.Errorf("helpful is required")
+	}
+	if !containsString(feedbackDocTypes, p.Type) {
+		return nil, fmt.Errorf("type must be one of: %s", strings.Join(feedbackDocTypes, ", "))
+	}
+
+	fb := &types.SearchFeedback{
+		Query:   p.Query,
+		DocType: p.Type,
+		DocID:   p.ID,
+		Helpful: *p.Helpful,
+		Reason:  p.Reason,
+	}
+	if err := s.jsonStore.AddSearchFeedback(fb); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"success": true,
+		"id":      fb.ID,
+	}
+	if model := s.loadFeedbackModel(); model != nil {
+		result["boost"] = model.Boost(p.Type, p.ID, p.Query)
+	}
+	return result, nil
+}
+
+// searchDocs finds documentation sections matching the query and returns
+// them as citable doc hits.
+func (s *Server) searchDocs(query string, limit int) []types.DocHit {
+	chunks, err := s.sqliteIndex.SearchDocs(query, limit)
+	if err != nil {
+		return nil
+	}
+
+	var hits []types.DocHit
+	for _, c := range chunks {
+		// Drop the heading line itself; it's already in Heading
+		body := c.Content
+		if idx := strings.Index(body, "\n"); idx >= 0 && strings.HasPrefix(strings.TrimSpace(body), "#") {
+			body = body[idx+1:]
+		}
+		body = strings.TrimSpace(body)
+		if utf8.RuneCountInString(body) > 300 {
+			body = string([]rune(body)[:297]) + "..."
+		}
+		hits = append(hits, types.DocHit{
+			Type:      "doc",
+			Path:      c.FilePath,
+			Heading:   c.ChunkName,
+			StartLine: c.StartLine,
+			EndLine:   c.EndLine,
+			Excerpt:   body,
+		})
+	}
+	return hits
+}
+
 func (s *Server) handleListPatterns(params json.RawMessage) (interface{}, error) {
 	var p struct {
 		Source string `json:"source"`
@@ -645,8 +1070,14 @@ func (s *Server) handleGetArchitecture(params json.RawMessage) (interface{}, err
 
 func (s *Server) handleGetEvolutionTimeline(params json.RawMessage) (interface{}, error) {
 	var p struct {
-		EventType string `json:"event_type"`
-		Limit     int    `json:"limit"`
+		EventType     string `json:"event_type"`
+		Query         string `json:"query"`
+		Since         string `json:"since"`
+		Until         string `json:"until"`
+		Impact        string `json:"impact"`
+		MinImpact     string `json:"min_impact"`
+		ExpandRelated bool   `json:"expand_related"`
+		Limit         int    `json:"limit"`
 	}
 	json.Unmarshal(params, &p)
 
@@ -659,30 +1090,183 @@ func (s *Server) handleGetEvolutionTimeline(params json.RawMessage) (interface{}
 		return nil, err
 	}
 
-	events := timeline.Events
+	var since, until time.Time
+	if p.Since != "" {
+		if since = parseFeedSince(p.Since); since.IsZero() {
+			return nil, fmt.Errorf("invalid since %q: use YYYY-MM-DD, RFC3339 or a duration like 90d", p.Since)
+		}
+	}
+	if p.Until != "" {
+		if until = parseFeedSince(p.Until); until.IsZero() {
+			return nil, fmt.Errorf("invalid until %q: use YYYY-MM-DD, RFC3339 or a duration like 90d", p.Until)
+		}
+		if len(p.Until) == len("2006-01-02") {
+			// A bare date includes the whole day
+			until = until.Add(24 * time.Hour)
+		}
+	}
+
+	// Related decisions and warnings explain the "why" of an event, so
+	// queries match their text too
+	var related map[string]timelineRelated
+	if p.Query != "" || p.ExpandRelated {
+		related = s.timelineRelatedItems()
+	}
+	queryTerms := search.Terms(p.Query)
 
-	// Filter by event type if provided
-	if p.EventType != "" {
-		var filtered []types.EvolutionEvent
-		for _, e := range events {
-			if e.EventType == p.EventType {
-				filtered = append(filtered, e)
+	type scoredEvent struct {
+		event types.EvolutionEvent
+		score int
+	}
+	var matched []scoredEvent
+	for _, e := range timeline.Events {
+		if p.EventType != "" && e.EventType != p.EventType {
+			continue
+		}
+		if p.Impact != "" && !strings.EqualFold(e.Impact, p.Impact) {
+			continue
+		}
+		if p.MinImpact != "" && impactRank(e.Impact) < impactRank(p.MinImpact) {
+			continue
+		}
+		if !since.IsZero() && e.Timestamp.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !e.Timestamp.Before(until) {
+			continue
+		}
+
+		score := 0
+		if len(queryTerms) > 0 {
+			eventTerms := search.Terms(e.Title + " " + e.Description + " " + e.Author)
+			relatedTerms := make(map[string]bool)
+			for _, id := range e.RelatedIDs {
+				if r, ok := related[id]; ok {
+					for t := range search.Terms(r.Content + " " + r.Reason) {
+						relatedTerms[t] = true
+					}
+				}
+			}
+			for t := range queryTerms {
+				if timelineTermIn(t, eventTerms) {
+					score += 2
+				} else if timelineTermIn(t, relatedTerms) {
+					score++
+				}
+			}
+			if score == 0 {
+				continue
 			}
 		}
-		events = filtered
+		matched = append(matched, scoredEvent{event: e, score: score})
 	}
 
-	// Limit results
-	if len(events) > p.Limit {
-		events = events[:p.Limit]
+	// Best matches first for queries; otherwise keep timeline order
+	if len(queryTerms) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].score != matched[j].score {
+				return matched[i].score > matched[j].score
+			}
+			return matched[i].event.Timestamp.Before(matched[j].event.Timestamp)
+		})
+	}
+
+	total := len(matched)
+	if len(matched) > p.Limit {
+		matched = matched[:p.Limit]
+	}
+
+	events := make([]interface{}, 0, len(matched))
+	for _, m := range matched {
+		if !p.ExpandRelated || len(m.event.RelatedIDs) == 0 {
+			events = append(events, m.event)
+			continue
+		}
+		var items []timelineRelated
+		for _, id := range m.event.RelatedIDs {
+			if r, ok := related[id]; ok {
+				items = append(items, r)
+			}
+		}
+		events = append(events, map[string]interface{}{
+			"id":          m.event.ID,
+			"event_type":  m.event.EventType,
+			"title":       m.event.Title,
+			"description": m.event.Description,
+			"author":      m.event.Author,
+			"impact":      m.event.Impact,
+			"related_ids": m.event.RelatedIDs,
+			"related":     items,
+			"timestamp":   m.event.Timestamp,
+		})
 	}
 
 	return map[string]interface{}{
-		"events": events,
-		"total":  len(events),
+		"events":  events,
+		"total":   total,
+		"showing": len(events),
 	}, nil
 }
 
+// timelineRelated is a decision, warning or insight referenced by an event
+type timelineRelated struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// timelineRelatedItems indexes the knowledge items events can refer to
+func (s *Server) timelineRelatedItems() map[string]timelineRelated {
+	items := make(map[string]timelineRelated)
+	if decisions, err := s.jsonStore.GetDecisions(); err == nil {
+		for _, d := range decisions {
+			items[d.ID] = timelineRelated{ID: d.ID, Type: "decision", Content: d.Content, Reason: d.Reason}
+		}
+	}
+	if warnings, err := s.jsonStore.GetWarnings(); err == nil {
+		for _, w := range warnings {
+			items[w.ID] = timelineRelated{ID: w.ID, Type: "warning", Content: w.Content, Reason: w.Reason}
+		}
+	}
+	if insights, err := s.jsonStore.GetInsights(); err == nil {
+		for _, in := range insights {
+			items[in.ID] = timelineRelated{ID: in.ID, Type: "insight", Content: in.Content, Reason: in.Context}
+		}
+	}
+	return items
+}
+
+// timelineTermIn matches a query term against stemmed terms, tolerating
+// stems that differ in their last letters ("migrate" / "migrat")
+func timelineTermIn(term string, terms map[string]bool) bool {
+	if terms[term] {
+		return true
+	}
+	if len(term) < 4 {
+		return false
+	}
+	for t := range terms {
+		if len(t) >= 4 && (strings.HasPrefix(t, term) || strings.HasPrefix(term, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// impactRank orders event impacts; unknown values rank lowest
+func impactRank(impact string) int {
+	switch strings.ToLower(impact) {
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
 func (s *Server) handleAddPattern(params json.RawMessage) (interface{}, error) {
 	var pattern types.Pattern
 	if err := json.Unmarshal(params, &pattern); err != nil {
@@ -697,6 +1281,9 @@ func (s *Server) handleAddPattern(params json.RawMessage) (interface{}, error) {
 	}
 
 	if err := s.jsonStore.AddPattern(&pattern); err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return duplicateResult(pattern.ID, pattern.CreatedAt), nil
+		}
 		return nil, err
 	}
 
@@ -749,6 +1336,10 @@ func (s *Server) handleUpdateArchitecture(params json.RawMessage) (interface{},
 	if err := json.Unmarshal(params, &arch); err != nil {
 		return nil, err
 	}
+	var opts struct {
+		DryRun bool `json:"dry_run"`
+	}
+	json.Unmarshal(params, &opts)
 
 	// Merge with existing
 	existing, _ := s.jsonStore.GetArchitecture()
@@ -767,6 +1358,10 @@ func (s *Server) handleUpdateArchitecture(params json.RawMessage) (interface{},
 		}
 	}
 
+	if opts.DryRun {
+		return dryRunResult(fieldChanges("architecture", "", existing, &arch)), nil
+	}
+
 	if err := s.jsonStore.SaveArchitecture(&arch); err != nil {
 		return nil, err
 	}
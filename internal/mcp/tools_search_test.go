@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// seedTimeline writes the evolution timeline directly so events keep
+// their timestamps
+func seedTimeline(t *testing.T, s *Server, events ...types.EvolutionEvent) {
+	t.Helper()
+	data, err := json.Marshal(types.EvolutionTimeline{Events: events})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(s.basePath, "knowledge", "evolution.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// timelineIDs returns the IDs of the events in a get_evolution_timeline result
+func timelineIDs(t *testing.T, result interface{}) []string {
+	t.Helper()
+	var ids []string
+	for _, e := range resultMap(t, result)["events"].([]interface{}) {
+		switch e := e.(type) {
+		case types.EvolutionEvent:
+			ids = append(ids, e.ID)
+		case map[string]interface{}:
+			ids = append(ids, e["id"].(string))
+		default:
+			t.Fatalf("event is %T", e)
+		}
+	}
+	return ids
+}
+
+func TestEvolutionTimelineFilters(t *testing.T) {
+	s := setupTestServer(t)
+	kafka := &types.Decision{Content: "Use Kafka consumer groups instead of cron polling", Reason: "polling missed orders under load"}
+	if err := s.jsonStore.AddDecision(kafka); err != nil {
+		t.Fatal(err)
+	}
+	day := func(date string) time.Time {
+		ts, _ := time.Parse("2006-01-02", date)
+		return ts.Add(12 * time.Hour)
+	}
+	seedTimeline(t, s,
+		types.EvolutionEvent{ID: "evt-kafka", EventType: "architecture_change", Title: "Move order events to Kafka", Impact: "high", Timestamp: day("2024-01-10")},
+		types.EvolutionEvent{ID: "evt-polling", EventType: "decision", Title: "Replace the order poller", Impact: "low", RelatedIDs: []string{kafka.ID}, Timestamp: day("2024-03-05")},
+		types.EvolutionEvent{ID: "evt-billing", EventType: "milestone", Title: "Launch billing", Impact: "medium", Timestamp: day("2024-06-01")},
+	)
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   []string
+	}{
+		{"no filters keeps timeline order", map[string]interface{}{}, []string{"evt-kafka", "evt-polling", "evt-billing"}},
+		{"query ranks own text over related text", map[string]interface{}{"query": "kafka"}, []string{"evt-kafka", "evt-polling"}},
+		{"query matches related reason", map[string]interface{}{"query": "missed orders"}, []string{"evt-polling"}},
+		{"query tolerates stems", map[string]interface{}{"query": "launched"}, []string{"evt-billing"}},
+		{"bare until date is inclusive", map[string]interface{}{"since": "2024-02-01", "until": "2024-03-05"}, []string{"evt-polling"}},
+		{"since RFC3339", map[string]interface{}{"since": "2024-03-06T00:00:00Z"}, []string{"evt-billing"}},
+		{"exact impact", map[string]interface{}{"impact": "LOW"}, []string{"evt-polling"}},
+		{"minimum impact", map[string]interface{}{"min_impact": "medium"}, []string{"evt-kafka", "evt-billing"}},
+		{"event type and query", map[string]interface{}{"event_type": "decision", "query": "kafka"}, []string{"evt-polling"}},
+		{"no match", map[string]interface{}{"query": "graphql"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timelineIDs(t, mustCall(t, s, "get_evolution_timeline", tt.params)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	limited := resultMap(t, mustCall(t, s, "get_evolution_timeline", map[string]interface{}{"limit": 1}))
+	if limited["total"] != 3 || limited["showing"] != 1 {
+		t.Errorf("limit 1: total = %v, showing = %v, want 3 and 1", limited["total"], limited["showing"])
+	}
+
+	for _, bad := range []map[string]interface{}{{"since": "last tuesday"}, {"until": "soon"}} {
+		if _, err := callTool(t, s, "get_evolution_timeline", bad); err == nil {
+			t.Errorf("%v accepted", bad)
+		}
+	}
+}
+
+func TestEvolutionTimelineExpandRelated(t *testing.T) {
+	s := setupTestServer(t)
+	decision := &types.Decision{Content: "Use Kafka for order events", Reason: "replay on consumer failure"}
+	if err := s.jsonStore.AddDecision(decision); err != nil {
+		t.Fatal(err)
+	}
+	warning := &types.Warning{Content: "Kafka topics must be created before deploy", Severity: "warning"}
+	if err := s.jsonStore.AddWarning(warning); err != nil {
+		t.Fatal(err)
+	}
+	seedTimeline(t, s,
+		types.EvolutionEvent{ID: "evt-1", Title: "Migrate to Kafka", RelatedIDs: []string{decision.ID, warning.ID, "dec-gone"}, Timestamp: time.Now()},
+		types.EvolutionEvent{ID: "evt-2", Title: "Unrelated", Timestamp: time.Now()},
+	)
+
+	events := resultMap(t, mustCall(t, s, "get_evolution_timeline", map[string]interface{}{"expand_related": true}))["events"].([]interface{})
+	expanded, ok := events[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("event with related items is %T, want an expanded map", events[0])
+	}
+	related := expanded["related"].([]timelineRelated)
+	if len(related) != 2 || related[0].Type != "decision" || related[0].Reason != decision.Reason || related[1].Type != "warning" {
+		t.Errorf("related = %+v, want the decision and warning, skipping the missing ID", related)
+	}
+	if _, ok := events[1].(types.EvolutionEvent); !ok {
+		t.Errorf("event without related items is %T, want it unchanged", events[1])
+	}
+}
+
+func TestQueryReportsFrameworks(t *testing.T) {
+	s := setupTestServer(t)
+	if err := s.jsonStore.SaveProject(&types.Project{Name: "shop", Frameworks: []string{"go-gin", "jest"}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		question string
+		want     []string
+	}{
+		{"which web framework do we use", []string{"go-gin", "jest"}},
+		{"how are gin handlers registered", []string{"go-gin", "jest"}},
+		{"how are invoices numbered", nil},
+	}
+	for _, tt := range tests {
+		resp := mustCall(t, s, "query", map[string]interface{}{"question": tt.question}).(*types.QueryResponse)
+		if !reflect.DeepEqual(resp.Frameworks, tt.want) {
+			t.Errorf("%q: frameworks = %v, want %v", tt.question, resp.Frameworks, tt.want)
+		}
+	}
+
+	mustCall(t, s, "update_project", map[string]interface{}{"description": "Online store"})
+	if project, _ := s.jsonStore.GetProject(); !reflect.DeepEqual(project.Frameworks, []string{"go-gin", "jest"}) {
+		t.Errorf("update_project dropped the frameworks: %v", project.Frameworks)
+	}
+}
+
+func TestDeepQueryFollowsGraph(t *testing.T) {
+	s := setupTestServer(t)
+	invoices := &types.Decision{Content: "Invoice numbers are allocated per tenant", Reason: "Auditors need gapless sequences"}
+	sequence := &types.Decision{Content: "Sequences live in a dedicated Postgres table", Reason: "Row locks keep them gapless"}
+	for _, d := range []*types.Decision{invoices, sequence} {
+		if err := s.jsonStore.AddDecision(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.jsonStore.AddEdge(&types.Edge{FromType: "decision", FromID: invoices.ID, ToType: "decision", ToID: sequence.ID, Relation: "related_to"}); err != nil {
+		t.Fatal(err)
+	}
+	decisionIDs := func(resp *types.QueryResponse) []string {
+		var ids []string
+		for _, d := range resp.Decisions {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	quick := mustCall(t, s, "query", map[string]interface{}{"question": "invoice"}).(*types.QueryResponse)
+	if got := decisionIDs(quick); !reflect.DeepEqual(got, []string{invoices.ID}) {
+		t.Errorf("quick query decisions = %v, want only %s", got, invoices.ID)
+	}
+	if quick.Deep != nil {
+		t.Errorf("quick query reported a deep analysis: %+v", quick.Deep)
+	}
+
+	deep := mustCall(t, s, "query", map[string]interface{}{"question": "invoice", "deep": true}).(*types.QueryResponse)
+	if got := decisionIDs(deep); !reflect.DeepEqual(got, []string{invoices.ID, sequence.ID}) {
+		t.Errorf("deep query decisions = %v, want %s then its graph neighbor %s", got, invoices.ID, sequence.ID)
+	}
+	if deep.Deep == nil || deep.Deep.BudgetMs != 8000 {
+		t.Fatalf("deep = %+v, want the default 8s budget", deep.Deep)
+	}
+	if !reflect.DeepEqual(deep.Deep.Steps, []string{"graph", "history", "conversations", "experts"}) || len(deep.Deep.Skipped) != 0 {
+		t.Errorf("steps = %v, skipped = %v, want every step run", deep.Deep.Steps, deep.Deep.Skipped)
+	}
+}
+
+func TestDeepQueryBudget(t *testing.T) {
+	for _, tt := range []struct {
+		ms   int
+		want time.Duration
+	}{
+		{0, defaultDeepBudget},
+		{500, 500 * time.Millisecond},
+		{120000, maxDeepBudget},
+	} {
+		if got := deepBudget(tt.ms); got != tt.want {
+			t.Errorf("deepBudget(%d) = %v, want %v", tt.ms, got, tt.want)
+		}
+	}
+
+	// Searches that don't fit the budget are skipped and reported
+	d := newDeepQuery(setupTestServer(t), "invoice", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	ran := false
+	d.step("graph", func() bool { ran = true; return true })
+	if ran {
+		t.Error("a step ran after the budget ran out")
+	}
+	d.step("history", func() bool { return false })
+	if report := d.finish(); len(report.Steps) != 0 || !reflect.DeepEqual(report.Skipped, []string{"graph", "history"}) {
+		t.Errorf("report = %+v, want graph and history skipped", report)
+	}
+}
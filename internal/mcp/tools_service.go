@@ -0,0 +1,370 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/blueprint"
+	"github.com/saeedalam/teamcontext/internal/extractor"
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// SERVICE CARD
+// One-call context for a single app/workspace in a monorepo
+// =============================================================================
+
+// serviceRoots are the conventional monorepo directories holding one
+// app/service/package per child directory.
+var serviceRoots = []string{"apps", "services", "packages", "libs"}
+
+// entrypointNames are file names that usually bootstrap a service
+var entrypointNames = map[string]bool{
+	"main.go": true, "main.ts": true, "main.py": true, "main.rs": true,
+	"index.ts": true, "index.js": true, "server.ts": true, "server.js": true,
+	"app.module.ts": true, "app.py": true, "manage.py": true, "lib.rs": true,
+}
+
+// listServices returns the workspaces of the project keyed by name, with
+// their project-relative paths.
+func (s *Server) listServices() map[string]string {
+	projectRoot := filepath.Dir(s.basePath)
+	services := make(map[string]string)
+	for _, root := range serviceRoots {
+		entries, err := os.ReadDir(filepath.Join(projectRoot, root))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			if _, exists := services[e.Name()]; !exists {
+				services[e.Name()] = root + "/" + e.Name()
+			}
+		}
+	}
+	// Gradle modules by path (feature:login) and, when unambiguous, by
+	// their last segment (login)
+	modules := blueprint.GradleModules(projectRoot)
+	for _, m := range modules {
+		if m.Name == ":" {
+			continue
+		}
+		name := strings.TrimPrefix(m.Name, ":")
+		if _, exists := services[name]; !exists {
+			services[name] = m.Dir
+		}
+		last := name[strings.LastIndex(name, ":")+1:]
+		if _, exists := services[last]; !exists {
+			if found, ok := blueprint.FindGradleModule(modules, last); ok && found.Name == m.Name {
+				services[last] = m.Dir
+			}
+		}
+	}
+	return services
+}
+
+// serviceOf returns the workspace path a project-relative file belongs to,
+// or "" if it is outside every workspace.
+func serviceOf(path string) string {
+	parts := strings.SplitN(filepath.ToSlash(path), "/", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	for _, root := range serviceRoots {
+		if parts[0] == root {
+			return parts[0] + "/" + parts[1]
+		}
+	}
+	return ""
+}
+
+// handleGetServiceCard bundles endpoints, models, env vars, key files,
+// owners, warnings and cross-service dependencies of one app.
+func (s *Server) handleGetServiceCard(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		App  string `json:"app"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	services := s.listServices()
+
+	relPath := strings.Trim(filepath.ToSlash(p.Path), "/")
+	if relPath == "" {
+		if p.App == "" {
+			return nil, fmt.Errorf("app or path is required")
+		}
+		var ok bool
+		relPath, ok = services[p.App]
+		if !ok {
+			names := make([]string, 0, len(services))
+			for name := range services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("app '%s' not found. Available: %s", p.App, strings.Join(names, ", "))
+		}
+	}
+	absPath := filepath.Join(projectRoot, relPath)
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("path not found: %s", relPath)
+	}
+	name := p.App
+	if name == "" {
+		name = filepath.Base(relPath)
+	}
+
+	inService := func(file string) bool {
+		file = filepath.ToSlash(file)
+		if filepath.IsAbs(file) {
+			if rel, err := filepath.Rel(projectRoot, file); err == nil {
+				file = filepath.ToSlash(rel)
+			}
+		}
+		return file == relPath || strings.HasPrefix(file, relPath+"/")
+	}
+	toRel := func(file string) string {
+		if rel, err := filepath.Rel(projectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+		return file
+	}
+
+	card := map[string]interface{}{
+		"app":  name,
+		"path": relPath,
+	}
+
+	// Endpoints and events
+	if surface, err := extractor.ExtractAPISurface(absPath, name); err == nil {
+		var endpoints []string
+		for _, ep := range surface.Endpoints {
+			line := fmt.Sprintf("%s %s", ep.Method, ep.Path)
+			if ep.Handler != "" {
+				line += " → " + ep.Handler
+			}
+			if ep.Auth != "" {
+				line += " [" + ep.Auth + "]"
+			}
+			endpoints = append(endpoints, line)
+		}
+		sort.Strings(endpoints)
+		card["endpoint_count"] = len(endpoints)
+		if len(endpoints) > 30 {
+			endpoints = endpoints[:30]
+			card["endpoints_truncated"] = true
+		}
+		card["endpoints"] = endpoints
+
+		var consumes, produces []string
+		for _, k := range surface.KafkaConsumers {
+			consumes = append(consumes, k.Topic)
+		}
+		for _, k := range surface.KafkaProducers {
+			produces = append(produces, k.Topic)
+		}
+		if len(consumes) > 0 || len(produces) > 0 {
+			card["events"] = map[string]interface{}{
+				"consumes": uniqueSorted(consumes),
+				"produces": uniqueSorted(produces),
+			}
+		}
+	}
+
+	// Models
+	var models []string
+	modelSeen := make(map[string]bool)
+	addModel := func(m extractor.SchemaModel) {
+		if modelSeen[m.Name] {
+			return
+		}
+		modelSeen[m.Name] = true
+		models = append(models, fmt.Sprintf("%s (%d fields) — %s", m.Name, len(m.Fields), toRel(m.File)))
+	}
+	if schema, err := extractor.ExtractSchemaModels(absPath); err == nil && schema != nil {
+		for _, m := range schema.Models {
+			addModel(m)
+		}
+	}
+	if schema, err := extractor.ExtractMultiLangSchema(absPath); err == nil && schema != nil {
+		for _, m := range schema.Models {
+			addModel(m)
+		}
+	}
+	sort.Strings(models)
+	if len(models) > 20 {
+		models = models[:20]
+	}
+	card["models"] = models
+
+	// Env vars and config-level service dependencies (e.g. BILLING_SERVICE_URL)
+	configDeps := make(map[string]bool)
+	if configMap, err := extractor.ExtractConfigMap(absPath); err == nil {
+		var envVars, required []string
+		for _, v := range configMap.EnvVars {
+			envVars = append(envVars, v.Name)
+			if v.Required {
+				required = append(required, v.Name)
+			}
+			upper := strings.ToUpper(v.Name)
+			for svc := range services {
+				svcUpper := strings.ToUpper(strings.ReplaceAll(svc, "-", "_"))
+				if svc != name && strings.HasPrefix(upper, svcUpper+"_") &&
+					(strings.Contains(upper, "URL") || strings.Contains(upper, "HOST") || strings.Contains(upper, "ENDPOINT")) {
+					configDeps[svc] = true
+				}
+			}
+		}
+		card["env_vars"] = uniqueSorted(envVars)
+		if len(required) > 0 {
+			card["required_env_vars"] = uniqueSorted(required)
+		}
+	}
+
+	// Key files: entrypoints first, then the files exporting the most symbols
+	files, _ := s.jsonStore.GetFilesIndex()
+	var serviceFiles []types.FileIndex
+	for path, fi := range files {
+		if inService(path) {
+			serviceFiles = append(serviceFiles, fi)
+		}
+	}
+	sort.Slice(serviceFiles, func(i, j int) bool {
+		ei := entrypointNames[filepath.Base(serviceFiles[i].Path)]
+		ej := entrypointNames[filepath.Base(serviceFiles[j].Path)]
+		if ei != ej {
+			return ei
+		}
+		if len(serviceFiles[i].Exports) != len(serviceFiles[j].Exports) {
+			return len(serviceFiles[i].Exports) > len(serviceFiles[j].Exports)
+		}
+		return serviceFiles[i].Path < serviceFiles[j].Path
+	})
+	var keyFiles []map[string]string
+	for _, fi := range serviceFiles {
+		if len(keyFiles) >= 8 {
+			break
+		}
+		keyFiles = append(keyFiles, map[string]string{"path": fi.Path, "summary": fi.Summary})
+	}
+	card["file_count"] = len(serviceFiles)
+	card["key_files"] = keyFiles
+
+	// Owners from the git experts cache, weighted by commits per directory
+	var cachedExperts []git.DirectoryExpert
+	if err := s.loadGitKnowledge("git-experts.json", &cachedExperts); err == nil {
+		type owner struct {
+			Name    string `json:"name"`
+			Commits int    `json:"commits"`
+			Active  bool   `json:"active"`
+		}
+		byEmail := make(map[string]*owner)
+		for _, de := range cachedExperts {
+			if !inService(de.Directory) {
+				continue
+			}
+			for _, e := range de.TopExperts {
+				o, ok := byEmail[e.Email]
+				if !ok {
+					o = &owner{Name: e.Name}
+					byEmail[e.Email] = o
+				}
+				o.Commits += e.Commits
+				o.Active = o.Active || e.Active
+			}
+		}
+		var owners []owner
+		for _, o := range byEmail {
+			owners = append(owners, *o)
+		}
+		sort.Slice(owners, func(i, j int) bool { return owners[i].Commits > owners[j].Commits })
+		if len(owners) > 3 {
+			owners = owners[:3]
+		}
+		card["owners"] = owners
+	}
+
+	// Warnings attached to the service's files or naming the app
+	if warnings, err := s.jsonStore.GetWarnings(); err == nil {
+		var related []map[string]string
+		for _, w := range warnings {
+			match := strings.Contains(strings.ToLower(w.Content), strings.ToLower(name))
+			for _, f := range w.RelatedFiles {
+				if inService(f) {
+					match = true
+					break
+				}
+			}
+			if match {
+				related = append(related, map[string]string{"id": w.ID, "severity": w.Severity, "content": w.Content})
+			}
+		}
+		if len(related) > 10 {
+			related = related[:10]
+		}
+		card["warnings"] = related
+	}
+
+	// Cross-service dependencies from import edges and workspace package imports
+	dependsOn := make(map[string]bool)
+	usedBy := make(map[string]bool)
+	if graph, err := s.jsonStore.GetKnowledgeGraph(); err == nil {
+		for _, e := range graph.Edges {
+			if e.Relation != "imports" || e.FromType != "file" || e.ToType != "file" {
+				continue
+			}
+			from, to := serviceOf(e.FromID), serviceOf(e.ToID)
+			if from == "" || to == "" || from == to {
+				continue
+			}
+			if from == relPath {
+				dependsOn[filepath.Base(to)] = true
+			} else if to == relPath {
+				usedBy[filepath.Base(from)] = true
+			}
+		}
+	}
+	for _, fi := range serviceFiles {
+		for _, imp := range fi.Imports {
+			for svc := range services {
+				if svc != name && (strings.HasSuffix(imp, "/"+svc) || strings.Contains(imp, "/"+svc+"/")) && strings.HasPrefix(imp, "@") {
+					dependsOn[svc] = true
+				}
+			}
+		}
+	}
+	for svc := range configDeps {
+		dependsOn[svc] = true
+	}
+	card["depends_on"] = sortedKeys(dependsOn)
+	card["used_by"] = sortedKeys(usedBy)
+
+	return card, nil
+}
+
+func uniqueSorted(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		seen[item] = true
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
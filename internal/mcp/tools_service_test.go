@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestGetServiceCardErrors(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "apps/orders/main.ts", "export const x = 1;\n")
+	writeProjectFile(t, s, "services/billing/index.ts", "export const y = 1;\n")
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"no app or path", map[string]interface{}{}, "app or path is required"},
+		{"unknown app lists available", map[string]interface{}{"app": "nope"}, "Available: billing, orders"},
+		{"missing path", map[string]interface{}{"path": "apps/missing"}, "path not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callTool(t, s, "get_service_card", tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetServiceCard(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "apps/orders/main.ts", "export const x = 1;\n")
+	writeProjectFile(t, s, "apps/billing/main.ts", "export const y = 1;\n")
+
+	files := map[string]types.FileIndex{
+		"apps/orders/main.ts":      {Path: "apps/orders/main.ts", Summary: "bootstrap"},
+		"apps/orders/util.ts":      {Path: "apps/orders/util.ts", Exports: []types.Export{{Name: "a"}}},
+		"apps/orders/service.ts":   {Path: "apps/orders/service.ts", Exports: []types.Export{{Name: "a"}, {Name: "b"}}, Imports: []string{"@acme/billing"}},
+		"apps/billing/main.ts":     {Path: "apps/billing/main.ts"},
+		"apps/orders-old/stale.ts": {Path: "apps/orders-old/stale.ts"},
+	}
+	if err := s.jsonStore.SaveFilesIndexBulk(files); err != nil {
+		t.Fatalf("SaveFilesIndexBulk: %v", err)
+	}
+	for _, w := range []*types.Warning{
+		{Severity: "high", Content: "retry storm", RelatedFiles: []string{"apps/orders/service.ts"}},
+		{Severity: "low", Content: "Orders totals are cached"},
+		{Severity: "low", Content: "billing only", RelatedFiles: []string{"apps/billing/main.ts"}},
+	} {
+		if err := s.jsonStore.AddWarning(w); err != nil {
+			t.Fatalf("AddWarning: %v", err)
+		}
+	}
+
+	card := resultMap(t, mustCall(t, s, "get_service_card", map[string]interface{}{"app": "orders"}))
+
+	if card["path"] != "apps/orders" {
+		t.Errorf("path = %v, want apps/orders", card["path"])
+	}
+	if card["file_count"] != 3 {
+		t.Errorf("file_count = %v, want 3 (sibling apps/orders-old excluded)", card["file_count"])
+	}
+
+	var keyPaths []string
+	for _, kf := range card["key_files"].([]map[string]string) {
+		keyPaths = append(keyPaths, kf["path"])
+	}
+	wantKeys := []string{"apps/orders/main.ts", "apps/orders/service.ts", "apps/orders/util.ts"}
+	if !reflect.DeepEqual(keyPaths, wantKeys) {
+		t.Errorf("key_files = %v, want %v (entrypoint, then most exports)", keyPaths, wantKeys)
+	}
+
+	var warnings []string
+	for _, w := range card["warnings"].([]map[string]string) {
+		warnings = append(warnings, w["content"])
+	}
+	if len(warnings) != 2 || !containsString(warnings, "retry storm") || !containsString(warnings, "Orders totals are cached") {
+		t.Errorf("warnings = %v, want the related-file one and the one naming the app", warnings)
+	}
+
+	if got := card["depends_on"].([]string); !reflect.DeepEqual(got, []string{"billing"}) {
+		t.Errorf("depends_on = %v, want [billing] from the @acme/billing import", got)
+	}
+}
+
+func TestGetServiceCardByPath(t *testing.T) {
+	s := setupTestServer(t)
+	writeProjectFile(t, s, "tools/cli/main.go", "package main\n")
+
+	card := resultMap(t, mustCall(t, s, "get_service_card", map[string]interface{}{"path": "/tools/cli/"}))
+	if card["app"] != "cli" || card["path"] != "tools/cli" {
+		t.Errorf("app, path = %v, %v; want cli, tools/cli", card["app"], card["path"])
+	}
+}
+
+func TestServiceOf(t *testing.T) {
+	tests := map[string]string{
+		"apps/web/src/main.ts":   "apps/web",
+		"packages/ui/index.ts":   "packages/ui",
+		"apps/web":               "",
+		"src/apps/web/main.ts":   "",
+		"libs\\shared\\index.ts": "",
+	}
+	for path, want := range tests {
+		if got := serviceOf(path); got != want {
+			t.Errorf("serviceOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
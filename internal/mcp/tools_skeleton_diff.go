@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// SKELETON DIFF
+// What a commit changed structurally: functions, classes, members and types
+// added, removed or re-signed, without reading the line diff
+// =============================================================================
+
+// fileSkeletonDiff is the structural change of one file
+type fileSkeletonDiff struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // added, deleted, modified
+	*skeleton.DiffReport
+}
+
+// handleGetSkeletonDiff compares skeletons of files at two commits, or at a
+// commit and the working tree. By default it reports the last commit.
+func (s *Server) handleGetSkeletonDiff(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path   string `json:"path"`
+		Commit string `json:"commit"`
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Limit  int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+	// A commit is compared with its parent; from alone with the working tree
+	if p.From == "" {
+		if p.Commit == "" {
+			p.Commit = "HEAD"
+		}
+		p.From, p.To = p.Commit+"^", p.Commit
+	}
+
+	projectRoot := filepath.Dir(s.basePath)
+	rel := relpath.FromAbs(projectRoot, p.Path)
+
+	var paths []string
+	if info, err := os.Stat(relpath.ToAbs(projectRoot, rel)); err == nil && !info.IsDir() {
+		paths = []string{rel}
+	} else {
+		changed, err := git.GetChangedFiles(projectRoot, p.From, p.To)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compare %s with %s: %w", p.From, describeRef(p.To), err)
+		}
+		for _, f := range changed {
+			if relpath.Within(f, rel) {
+				paths = append(paths, f)
+			}
+		}
+		// A file deleted since from no longer exists on disk
+		if len(paths) == 0 && rel != "" {
+			if _, ok, _ := git.GetFileAtRef(projectRoot, p.From, rel); ok {
+				paths = []string{rel}
+			}
+		}
+	}
+
+	var files []fileSkeletonDiff
+	unchanged, skipped := 0, 0
+	for _, path := range paths {
+		if len(files) >= p.Limit {
+			skipped++
+			continue
+		}
+		oldContent, hadOld, err := git.GetFileAtRef(projectRoot, p.From, path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", p.From, err)
+		}
+		newContent, hasNew, err := fileAtRef(projectRoot, p.To, path)
+		if err != nil {
+			return nil, err
+		}
+		if !hadOld && !hasNew {
+			continue
+		}
+		oldSk, newSk := parseVersion(path, oldContent, hadOld), parseVersion(path, newContent, hasNew)
+		if (oldSk == nil || oldSk.Language == "unknown") && (newSk == nil || newSk.Language == "unknown") {
+			continue
+		}
+		d := skeleton.Diff(oldSk, newSk)
+		if d.Empty() {
+			unchanged++
+			continue
+		}
+		status := "modified"
+		if !hadOld {
+			status = "added"
+		} else if !hasNew {
+			status = "deleted"
+		}
+		files = append(files, fileSkeletonDiff{Path: path, Status: status, DiffReport: d})
+	}
+
+	added, removed, changed := 0, 0, 0
+	for _, f := range files {
+		added += len(f.Added)
+		removed += len(f.Removed)
+		changed += len(f.Changed)
+	}
+	result := map[string]interface{}{
+		"from":    p.From,
+		"to":      describeRef(p.To),
+		"files":   files,
+		"added":   added,
+		"removed": removed,
+		"changed": changed,
+	}
+	if unchanged > 0 {
+		result["structurally_unchanged"] = unchanged
+	}
+	if skipped > 0 {
+		result["truncated"] = true
+		result["omitted_files"] = skipped
+	}
+	return result, nil
+}
+
+// fileAtRef reads a file at a commit, or from disk when ref is empty
+func fileAtRef(projectRoot, ref, path string) ([]byte, bool, error) {
+	if ref != "" {
+		return git.GetFileAtRef(projectRoot, ref, path)
+	}
+	content, err := os.ReadFile(relpath.ToAbs(projectRoot, path))
+	if err != nil {
+		return nil, false, nil
+	}
+	return content, true, nil
+}
+
+// parseVersion parses one side of a diff; nil when the file is absent
+func parseVersion(path string, content []byte, ok bool) *types.CodeSkeleton {
+	if !ok {
+		return nil
+	}
+	sk, err := skeleton.ParseContent(path, content)
+	if err != nil {
+		return nil
+	}
+	return sk
+}
+
+func describeRef(ref string) string {
+	if ref == "" {
+		return "working tree"
+	}
+	return ref
+}
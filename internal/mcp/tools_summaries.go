@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// SUMMARY REVIEW
+// Auto-indexed files get a placeholder or a summary guessed from their
+// exports. The queue ranks them so agents write real summaries for the files
+// that matter, and sessions are nudged to do it while a file is in context.
+// =============================================================================
+
+// maxSummaryPrompts caps the summary hooks added in one session
+const maxSummaryPrompts = 5
+
+// handleListFilesNeedingSummary lists files with placeholder or heuristic
+// summaries, most important first
+func (s *Server) handleListFilesNeedingSummary(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Path   string `json:"path"`
+		Reason string `json:"reason"`
+		Limit  int    `json:"limit"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+	if p.Reason != "" && p.Reason != "placeholder" && p.Reason != "heuristic" {
+		return nil, fmt.Errorf("reason must be placeholder or heuristic")
+	}
+
+	queue, err := s.jsonStore.FilesNeedingSummary(s.sessionFileAccesses())
+	if err != nil {
+		return nil, err
+	}
+
+	var files []types.SummaryCandidate
+	placeholders := 0
+	for _, c := range queue {
+		if !relpath.Within(c.Path, p.Path) || (p.Reason != "" && c.Reason != p.Reason) {
+			continue
+		}
+		if c.Reason == "placeholder" {
+			placeholders++
+		}
+		files = append(files, c)
+	}
+	total := len(files)
+	if len(files) > p.Limit {
+		files = files[:p.Limit]
+	}
+
+	response := map[string]interface{}{
+		"files":        files,
+		"total":        total,
+		"placeholders": placeholders,
+	}
+	if total > 0 {
+		response["hint"] = "Read each file and call index_file with its path and a one-sentence summary; exports and imports already indexed are kept"
+	}
+	return response, nil
+}
+
+// sessionFileAccesses counts this session's use of each file, which is not
+// in a saved conversation yet
+func (s *Server) sessionFileAccesses() map[string]int {
+	projectRoot := filepath.Dir(s.basePath)
+	accesses := make(map[string]int, len(s.session.FilesTouched))
+	for f := range s.session.FilesTouched {
+		accesses[relpath.FromAbs(projectRoot, f)]++
+	}
+	return accesses
+}
+
+// attachSummaryHook asks the agent to write a summary for a file this call
+// worked with when the file only has a generated one, while it is still in
+// context. Each file is asked for once, and only a few per session.
+func (s *Server) attachSummaryHook(toolName string, args json.RawMessage, result interface{}) {
+	if toolName == "index_file" || toolName == "list_files_needing_summary" || len(s.session.SummaryPrompted) >= maxSummaryPrompts {
+		return
+	}
+	body, ok := result.(map[string]interface{})
+	if !ok {
+		return
+	}
+	var argMap map[string]interface{}
+	if err := json.Unmarshal(args, &argMap); err != nil {
+		return
+	}
+	for _, key := range []string{"path", "file", "file_path"} {
+		v, ok := argMap[key].(string)
+		if !ok || v == "" {
+			continue
+		}
+		path := relpath.FromAbs(filepath.Dir(s.basePath), v)
+		if s.session.SummaryPrompted[path] {
+			continue
+		}
+		file, err := s.jsonStore.GetFileIndex(path)
+		if err != nil || file.DeletedAt != nil {
+			continue
+		}
+		reason := storage.SummaryReason(*file)
+		if reason == "" {
+			continue
+		}
+		s.session.SummaryPrompted[path] = true
+
+		hooks, _ := body["_hooks"].([]types.ConversationHook)
+		body["_hooks"] = append(hooks, types.ConversationHook{
+			Action:    types.HookActionIndexFile,
+			Tool:      "index_file",
+			Priority:  types.HookPriorityOptional,
+			Reason:    fmt.Sprintf("%s only has a %s summary (%q). If you have read it, record what it does in one sentence.", path, reason, file.Summary),
+			Condition: "file_content_in_context",
+			Arguments: map[string]interface{}{
+				"path":    path,
+				"summary": "",
+			},
+		})
+		return
+	}
+}
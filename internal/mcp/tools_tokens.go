@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// =============================================================================
+// TOKEN SPEND
+// Which features and areas cost the most agent context
+// =============================================================================
+
+// knowledgeGapShare is the share of a feature's spend above which an area
+// with no recorded knowledge is suggested for documentation.
+const knowledgeGapShare = 0.15
+
+// handleGetTokenReport reports estimated token spend per feature, broken down
+// by tool and by area, with budget status and where knowledge or caching
+// would cut the cost.
+func (s *Server) handleGetTokenReport(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Feature string `json:"feature"`
+		Limit   int    `json:"limit"`
+	}
+	json.Unmarshal(params, &p)
+	if p.Limit <= 0 {
+		p.Limit = 10
+	}
+
+	s.flushTokenSpend()
+	ledger, err := s.jsonStore.GetTokenSpend()
+	if err != nil {
+		return nil, err
+	}
+	if p.Feature != "" {
+		if _, ok := ledger[p.Feature]; !ok {
+			return nil, fmt.Errorf("no token spend recorded for feature '%s'", p.Feature)
+		}
+	}
+
+	var spends []*types.FeatureTokenSpend
+	total := 0
+	for id, spend := range ledger {
+		total += spend.TokensUsed
+		if p.Feature == "" || id == p.Feature {
+			spends = append(spends, spend)
+		}
+	}
+	sort.Slice(spends, func(i, j int) bool {
+		if spends[i].TokensUsed != spends[j].TokensUsed {
+			return spends[i].TokensUsed > spends[j].TokensUsed
+		}
+		return spends[i].Feature < spends[j].Feature
+	})
+
+	knownAreas := s.knowledgeAreas()
+
+	var features []map[string]interface{}
+	var suggestions []string
+	for _, spend := range spends {
+		entry := map[string]interface{}{
+			"feature":      spend.Feature,
+			"tool_calls":   spend.ToolCalls,
+			"tokens_used":  spend.TokensUsed,
+			"tokens_saved": spend.TokensSaved,
+			"first_seen":   spend.FirstSeen,
+			"last_seen":    spend.LastSeen,
+		}
+		if total > 0 {
+			entry["share_percent"] = spend.TokensUsed * 100 / total
+		}
+		if spend.TokensUsed+spend.TokensSaved > 0 {
+			entry["savings_percent"] = spend.TokensSaved * 100 / (spend.TokensUsed + spend.TokensSaved)
+		}
+
+		// Budget
+		if f, err := s.jsonStore.GetFeature(spend.Feature); err == nil && f != nil && f.TokenBudget > 0 {
+			entry["token_budget"] = f.TokenBudget
+			entry["budget_used_percent"] = spend.TokensUsed * 100 / f.TokenBudget
+			if spend.TokensUsed >= f.TokenBudget {
+				entry["over_budget"] = true
+			}
+		}
+
+		// Team-wide totals from saved sessions, which are shared through git
+		if convs, err := s.jsonStore.GetConversations(spend.Feature); err == nil && len(convs) > 0 {
+			used, original := 0, 0
+			for _, c := range convs {
+				used += c.CompressedTokens
+				original += c.OriginalTokens
+			}
+			entry["team_sessions"] = map[string]interface{}{
+				"sessions":    len(convs),
+				"tokens_used": used,
+				"tokens_raw":  original,
+			}
+		}
+
+		// Top tools
+		type toolRow struct {
+			Tool string `json:"tool"`
+			types.ToolTokenSpend
+		}
+		var tools []toolRow
+		for name, ts := range spend.ByTool {
+			tools = append(tools, toolRow{name, *ts})
+		}
+		sort.Slice(tools, func(i, j int) bool {
+			if tools[i].TokensUsed != tools[j].TokensUsed {
+				return tools[i].TokensUsed > tools[j].TokensUsed
+			}
+			return tools[i].Tool < tools[j].Tool
+		})
+		if len(tools) > p.Limit {
+			tools = tools[:p.Limit]
+		}
+		entry["top_tools"] = tools
+
+		// Top areas
+		type areaRow struct {
+			Area       string `json:"area"`
+			TokensUsed int    `json:"tokens_used"`
+			Knowledge  bool   `json:"has_knowledge"`
+		}
+		var areas []areaRow
+		for area, used := range spend.ByArea {
+			areas = append(areas, areaRow{area, used, hasKnowledge(knownAreas, area)})
+		}
+		sort.Slice(areas, func(i, j int) bool {
+			if areas[i].TokensUsed != areas[j].TokensUsed {
+				return areas[i].TokensUsed > areas[j].TokensUsed
+			}
+			return areas[i].Area < areas[j].Area
+		})
+		if len(areas) > p.Limit {
+			areas = areas[:p.Limit]
+		}
+		entry["top_areas"] = areas
+
+		// Where knowledge or cheaper tools would pay off
+		for _, a := range areas {
+			if a.Knowledge || spend.TokensUsed == 0 || float64(a.TokensUsed) < knowledgeGapShare*float64(spend.TokensUsed) {
+				continue
+			}
+			suggestions = append(suggestions, fmt.Sprintf(
+				"%s: %s costs %d%% of its tokens and has no recorded decisions, warnings or patterns. Recording them (or file summaries via index_file) lets agents use get_context instead of re-reading code.",
+				spend.Feature, a.Area, a.TokensUsed*100/spend.TokensUsed))
+		}
+		for _, t := range tools {
+			if t.TokensSaved > 0 || spend.TokensUsed == 0 || float64(t.TokensUsed) < knowledgeGapShare*float64(spend.TokensUsed) {
+				continue
+			}
+			suggestions = append(suggestions, fmt.Sprintf(
+				"%s: %s accounts for %d%% of its tokens with no measured savings. Narrower parameters or get_skeleton/get_blueprint may be cheaper.",
+				spend.Feature, t.Tool, t.TokensUsed*100/spend.TokensUsed))
+		}
+
+		features = append(features, entry)
+	}
+
+	result := map[string]interface{}{
+		"features":     features,
+		"total_tokens": total,
+		"note":         "Estimates from tool response sizes recorded by this developer's server. team_sessions sums saved sessions from all developers.",
+	}
+	if len(suggestions) > 0 {
+		result["suggestions"] = suggestions
+	}
+	return result, nil
+}
+
+// knowledgeAreas returns the directories of files referenced by decisions,
+// warnings and patterns.
+func (s *Server) knowledgeAreas() map[string]bool {
+	areas := make(map[string]bool)
+	add := func(files []string) {
+		for _, f := range files {
+			f = strings.Trim(strings.ReplaceAll(f, "\\", "/"), "/")
+			for dir := f; dir != "" && dir != "."; {
+				areas[dir] = true
+				i := strings.LastIndex(dir, "/")
+				if i < 0 {
+					break
+				}
+				dir = dir[:i]
+			}
+		}
+	}
+	if decisions, err := s.jsonStore.GetDecisions(); err == nil {
+		for _, d := range decisions {
+			add(d.RelatedFiles)
+		}
+	}
+	if warnings, err := s.jsonStore.GetWarnings(); err == nil {
+		for _, w := range warnings {
+			add(w.RelatedFiles)
+		}
+	}
+	if patterns, err := s.jsonStore.GetPatterns(); err == nil {
+		for _, p := range patterns {
+			add(p.Examples)
+		}
+	}
+	return areas
+}
+
+// hasKnowledge reports whether knowledge references files in area or below
+func hasKnowledge(known map[string]bool, area string) bool {
+	if known[area] {
+		return true
+	}
+	for k := range known {
+		if strings.HasPrefix(k, area+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/worker"
+)
+
+// recentErrorWindow is how far back get_health looks for worker errors
+const recentErrorWindow = 24 * time.Hour
+
+// handleGetWorkerLog queries the background worker's log, newest first
+func (s *Server) handleGetWorkerLog(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Level    string `json:"level"`
+		Since    string `json:"since"`
+		Contains string `json:"contains"`
+		Limit    int    `json:"limit"`
+	}
+	json.Unmarshal(params, &p)
+	if p.Limit <= 0 {
+		p.Limit = 50
+	}
+	switch p.Level {
+	case "", worker.LevelInfo, worker.LevelWarn, worker.LevelError:
+	default:
+		return nil, fmt.Errorf("unknown level %q: use info, warn or error", p.Level)
+	}
+
+	q := worker.LogQuery{Level: p.Level, Contains: p.Contains, Limit: p.Limit + 1}
+	if p.Since != "" {
+		if q.Since = parseFeedSince(p.Since); q.Since.IsZero() {
+			return nil, fmt.Errorf("invalid since %q: use 7d, 24h, 30m or a date", p.Since)
+		}
+	}
+	entries, err := worker.ReadLog(s.basePath, q)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	}
+	if len(entries) > p.Limit {
+		result["entries"] = entries[:p.Limit]
+		result["count"] = p.Limit
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+// handleGetHealth reports setup problems, the background worker's state and
+// the errors it logged in the last day
+func (s *Server) handleGetHealth(params json.RawMessage) (interface{}, error) {
+	problems := make([]map[string]string, 0)
+	for _, p := range s.checkHealth() {
+		problems = append(problems, map[string]string{"problem": p.Problem, "fix": p.Fix})
+	}
+
+	recent, _ := worker.ReadLog(s.basePath, worker.LogQuery{
+		Level: worker.LevelError,
+		Since: time.Now().Add(-recentErrorWindow),
+		Limit: 10,
+	})
+	if recent == nil {
+		recent = []worker.LogEntry{}
+	}
+
+	result := map[string]interface{}{
+		"healthy":       len(problems) == 0 && len(recent) == 0,
+		"problems":      problems,
+		"recent_errors": recent,
+	}
+	if s.workerManager != nil {
+		result["worker"] = map[string]interface{}{
+			"running": s.workerManager.IsRunning(),
+			"stats":   s.workerManager.GetStats(),
+		}
+	}
+	if len(recent) > 0 {
+		result["hint"] = "Call get_worker_log with level \"error\" for older errors, or without a level for the events around them"
+	}
+	return result, nil
+}
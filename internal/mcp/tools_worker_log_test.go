@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/worker"
+)
+
+func TestGetWorkerLogAndHealth(t *testing.T) {
+	s := setupTestServer(t)
+
+	now := time.Now().UTC()
+	lines := fmt.Sprintf(`{"time":%q,"level":"info","message":"Reindexed 3 files"}
+{"time":%q,"level":"error","message":"git diff: exit status 128"}
+{"time":%q,"level":"error","message":"auto-index old.go: permission denied"}
+`, now.Add(-time.Hour).Format(time.RFC3339), now.Add(-time.Minute).Format(time.RFC3339), now.Add(-72*time.Hour).Format(time.RFC3339))
+	if err := os.WriteFile(worker.LogPath(s.basePath), []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	log := resultMap(t, mustCall(t, s, "get_worker_log", map[string]interface{}{"level": "error", "since": "24h"}))
+	entries := log["entries"].([]worker.LogEntry)
+	if len(entries) != 1 || entries[0].Message != "git diff: exit status 128" {
+		t.Errorf("recent errors = %+v", entries)
+	}
+	log = resultMap(t, mustCall(t, s, "get_worker_log", map[string]interface{}{"limit": 2}))
+	if log["count"] != 2 || log["truncated"] != true {
+		t.Errorf("limited log = %+v", log)
+	}
+	if _, err := callTool(t, s, "get_worker_log", map[string]interface{}{"level": "debug"}); err == nil {
+		t.Error("unknown level should be rejected")
+	}
+
+	health := resultMap(t, mustCall(t, s, "get_health", nil))
+	if health["healthy"] != false {
+		t.Errorf("health with an empty index and a recent error = %+v", health)
+	}
+	if recent := health["recent_errors"].([]worker.LogEntry); len(recent) != 1 {
+		t.Errorf("recent_errors = %+v, want only the error from the last day", recent)
+	}
+	if _, ok := health["worker"]; !ok {
+		t.Error("health should report the worker")
+	}
+}
@@ -0,0 +1,73 @@
+// Package relpath is where project file paths change form.
+//
+// Everything TeamContext stores — the files index, graph nodes, code chunks,
+// related_files on knowledge items — uses forward-slash paths relative to the
+// project root ("src/app/users.ts"), on every OS, so an index built on
+// Windows matches one built on Linux and git's own path output. Paths are
+// converted to native form only at the filesystem boundary with ToAbs, and
+// back with FromAbs.
+package relpath
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Normalize returns p in stored form: forward slashes, cleaned, without a
+// leading "./". Backslashes are converted on every OS, since paths sent by
+// Windows clients can reach a server running elsewhere. "." becomes "".
+func Normalize(p string) string {
+	if p == "" {
+		return ""
+	}
+	p = path.Clean(strings.ReplaceAll(p, `\`, "/"))
+	if p == "." {
+		return ""
+	}
+	return strings.TrimPrefix(p, "./")
+}
+
+// NormalizeAll normalizes every path, dropping empty ones.
+func NormalizeAll(paths []string) []string {
+	if paths == nil {
+		return nil
+	}
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if n := Normalize(p); n != "" {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// FromAbs converts a native path to stored form relative to root. Paths
+// outside root are returned normalized but absolute.
+func FromAbs(root, abs string) string {
+	if !filepath.IsAbs(abs) {
+		return Normalize(abs)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return Normalize(abs)
+	}
+	return Normalize(rel)
+}
+
+// ToAbs converts a stored path to a native path under root. Absolute paths
+// are returned as they are.
+func ToAbs(root, rel string) string {
+	native := filepath.FromSlash(strings.ReplaceAll(rel, `\`, "/"))
+	if filepath.IsAbs(native) {
+		return native
+	}
+	return filepath.Join(root, native)
+}
+
+// Within reports whether stored path p is dir or lies under it. An empty
+// dir contains everything.
+func Within(p, dir string) bool {
+	p, dir = Normalize(p), Normalize(dir)
+	return dir == "" || p == dir || strings.HasPrefix(p, dir+"/")
+}
@@ -0,0 +1,87 @@
+package relpath
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{".", ""},
+		{"src/app/users.ts", "src/app/users.ts"},
+		{"./src/app/users.ts", "src/app/users.ts"},
+		{`src\app\users.ts`, "src/app/users.ts"},
+		{`.\src\app\users.ts`, "src/app/users.ts"},
+		{"src//app/../app/users.ts", "src/app/users.ts"},
+		{"src/app/", "src/app"},
+		{"/srv/app/users.ts", "/srv/app/users.ts"},
+		{`C:\work\app\users.ts`, "C:/work/app/users.ts"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	got := NormalizeAll([]string{`src\a.go`, "", ".", "./b.go"})
+	want := []string{"src/a.go", "b.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeAll = %v, want %v", got, want)
+	}
+	if NormalizeAll(nil) != nil {
+		t.Error("NormalizeAll(nil) should be nil")
+	}
+}
+
+func TestFromAbsToAbs(t *testing.T) {
+	root := t.TempDir()
+	abs := filepath.Join(root, "src", "app", "users.ts")
+
+	rel := FromAbs(root, abs)
+	if rel != "src/app/users.ts" {
+		t.Fatalf("FromAbs = %q, want src/app/users.ts", rel)
+	}
+	if back := ToAbs(root, rel); back != abs {
+		t.Errorf("ToAbs = %q, want %q", back, abs)
+	}
+	if back := ToAbs(root, `src\app\users.ts`); back != abs {
+		t.Errorf("ToAbs with backslashes = %q, want %q", back, abs)
+	}
+	if got := ToAbs(root, abs); got != abs {
+		t.Errorf("ToAbs of an absolute path = %q, want it unchanged", got)
+	}
+
+	outside := filepath.Join(filepath.Dir(root), "other", "x.go")
+	if got := FromAbs(root, outside); got != filepath.ToSlash(outside) {
+		t.Errorf("FromAbs outside root = %q, want %q", got, filepath.ToSlash(outside))
+	}
+	if got := FromAbs(root, "./src/a.go"); got != "src/a.go" {
+		t.Errorf("FromAbs of a relative path = %q", got)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		p, dir string
+		want   bool
+	}{
+		{"src/app/users.ts", "src", true},
+		{"src/app/users.ts", "src/app", true},
+		{`src\app\users.ts`, "src/app/", true},
+		{"src/app", "src/app", true},
+		{"src/application/x.ts", "src/app", false},
+		{"lib/x.ts", "src", false},
+		{"lib/x.ts", "", true},
+	}
+	for _, tt := range tests {
+		if got := Within(tt.p, tt.dir); got != tt.want {
+			t.Errorf("Within(%q, %q) = %v, want %v", tt.p, tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package relpath
+
+import "testing"
+
+func TestWindowsDrivePaths(t *testing.T) {
+	root := `C:\work\app`
+
+	if got := FromAbs(root, `C:\work\app\src\users.ts`); got != "src/users.ts" {
+		t.Errorf("FromAbs = %q, want src/users.ts", got)
+	}
+	if got := ToAbs(root, "src/users.ts"); got != `C:\work\app\src\users.ts` {
+		t.Errorf("ToAbs = %q", got)
+	}
+	// A different drive cannot be made relative
+	if got := FromAbs(root, `D:\other\x.go`); got != "D:/other/x.go" {
+		t.Errorf("FromAbs on another drive = %q, want D:/other/x.go", got)
+	}
+	if got := ToAbs(root, "D:/other/x.go"); got != `D:\other\x.go` {
+		t.Errorf("ToAbs of an absolute path = %q", got)
+	}
+}
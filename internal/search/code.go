@@ -49,7 +49,7 @@ func SearchCode(pattern string, path string, glob string, limit int) ([]types.Co
 
 	if err := cmd.Start(); err != nil {
 		// ripgrep not found, fallback to basic grep
-		return searchCodeFallback(pattern, path, limit)
+		return searchCodeFallback(pattern, path, glob, limit)
 	}
 
 	var matches []types.CodeMatch
@@ -77,21 +77,29 @@ func SearchCode(pattern string, path string, glob string, limit int) ([]types.Co
 	return matches, nil
 }
 
-// searchCodeFallback uses grep when ripgrep is not available
-func searchCodeFallback(pattern string, path string, limit int) ([]types.CodeMatch, error) {
+// searchCodeFallback uses grep when ripgrep is not available. Patterns are
+// written for ripgrep, so they are matched as extended regexps.
+func searchCodeFallback(pattern string, path string, glob string, limit int) ([]types.CodeMatch, error) {
 	args := []string{
-		"-r",             // recursive
-		"-n",             // line numbers
-		"-H",             // filename
-		"--include=*.go", // default to go files
-		"--include=*.ts",
-		"--include=*.js",
-		"--include=*.py",
-		"--include=*.java",
-		"--include=*.rs",
-		pattern,
-		path,
+		"-r", // recursive
+		"-n", // line numbers
+		"-H", // filename
+		"-E", // extended regexp, closest to ripgrep syntax
 	}
+	if glob != "" {
+		args = append(args, "--include="+glob)
+	} else {
+		args = append(args,
+			"--include=*.go", // default to source files
+			"--include=*.ts",
+			"--include=*.js",
+			"--include=*.py",
+			"--include=*.java",
+			"--include=*.rs",
+			"--include=*.ipynb",
+		)
+	}
+	args = append(args, pattern, path)
 
 	cmd := exec.Command("grep", args...)
 	output, err := cmd.Output()
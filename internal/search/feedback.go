@@ -0,0 +1,126 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// FeedbackWeight is how far feedback can move a result's score by default:
+// a fully boosted result scores up to 2x, a fully demoted one close to 0.
+const FeedbackWeight = 1.0
+
+// feedbackPrior is the share of a vote that applies to every query. The rest
+// is scaled by how similar the query is to the one that was rated, so a
+// judgment mostly affects related searches.
+const feedbackPrior = 0.2
+
+type feedbackVote struct {
+	terms   map[string]bool
+	helpful bool
+}
+
+// FeedbackModel learns per-document boosts from helpful/unhelpful judgments
+// and re-scores TF-IDF and FTS results with them.
+type FeedbackModel struct {
+	votes  map[string][]feedbackVote // docType:docID -> votes
+	weight float64                   // FeedbackWeight unless set
+}
+
+// NewFeedbackModel creates an empty feedback model.
+func NewFeedbackModel() *FeedbackModel {
+	return &FeedbackModel{
+		votes: make(map[string][]feedbackVote),
+	}
+}
+
+// SetWeight sets how far feedback moves a score, in place of
+// FeedbackWeight; 0 keeps the default.
+func (m *FeedbackModel) SetWeight(weight float64) {
+	m.weight = weight
+}
+
+// Add records a judgment of a document for a query.
+func (m *FeedbackModel) Add(docType, docID, query string, helpful bool) {
+	key := docType + ":" + docID
+	m.votes[key] = append(m.votes[key], feedbackVote{
+		terms:   queryTerms(query),
+		helpful: helpful,
+	})
+}
+
+// Boost returns a value in (-1, 1): positive when the document was found
+// helpful for similar queries, negative when it was found unhelpful.
+func (m *FeedbackModel) Boost(docType, docID, query string) float64 {
+	if m == nil {
+		return 0
+	}
+	votes := m.votes[docType+":"+docID]
+	if len(votes) == 0 {
+		return 0
+	}
+
+	terms := queryTerms(query)
+	sum := 0.0
+	for _, v := range votes {
+		w := feedbackPrior + (1-feedbackPrior)*jaccard(terms, v.terms)
+		if v.helpful {
+			sum += w
+		} else {
+			sum -= w
+		}
+	}
+	return math.Tanh(sum)
+}
+
+// Rescore applies the document's feedback boost to a base relevance score.
+func (m *FeedbackModel) Rescore(docType, docID, query string, score float64) float64 {
+	weight := FeedbackWeight
+	if m != nil && m.weight > 0 {
+		weight = m.weight
+	}
+	return score * (1 + weight*m.Boost(docType, docID, query))
+}
+
+// Terms returns the stemmed, synonym-folded words of a short text such as a
+// query or a key point, for comparing texts by TermSimilarity.
+func Terms(text string) map[string]bool {
+	return queryTerms(text)
+}
+
+// TermSimilarity is the Jaccard similarity of two term sets.
+func TermSimilarity(a, b map[string]bool) float64 {
+	return jaccard(a, b)
+}
+
+// queryTerms returns the stemmed words of a query. Unlike Tokenize it skips
+// bigrams, so word order doesn't change how similar two queries are.
+func queryTerms(text string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	for _, w := range words {
+		if len(w) < 2 || stopwords[w] {
+			continue
+		}
+		if syn, ok := synonyms[w]; ok {
+			w = syn
+		}
+		set[simpleStem(w)] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
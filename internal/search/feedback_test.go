@@ -0,0 +1,123 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func TestJaccard(t *testing.T) {
+	set := func(terms ...string) map[string]bool {
+		m := make(map[string]bool)
+		for _, term := range terms {
+			m[term] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name string
+		a, b map[string]bool
+		want float64
+	}{
+		{"identical", set("auth", "token"), set("auth", "token"), 1},
+		{"disjoint", set("auth"), set("billing"), 0},
+		{"half", set("auth", "token"), set("auth", "session"), 1.0 / 3},
+		{"subset", set("auth"), set("auth", "token"), 0.5},
+		{"empty", set(), set("auth"), 0},
+		{"both empty", set(), set(), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jaccard(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("jaccard = %v, want %v", got, tt.want)
+			}
+			if got := TermSimilarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TermSimilarity is not symmetric: %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTermsIgnoresOrderAndCase(t *testing.T) {
+	a := Terms("Refresh the JWT token")
+	b := Terms("token refresh, jwt")
+	if TermSimilarity(a, b) != 1 {
+		t.Errorf("Terms(%q) = %v, Terms(%q) = %v; want the same set", "Refresh the JWT token", a, "token refresh, jwt", b)
+	}
+	if len(Terms("a the of")) != 0 {
+		t.Errorf("stopwords and single letters were kept: %v", Terms("a the of"))
+	}
+}
+
+func TestFeedbackBoost(t *testing.T) {
+	m := NewFeedbackModel()
+	m.Add("file", "auth/jwt.go", "jwt token refresh", true)
+	m.Add("file", "legacy/session.go", "jwt token refresh", false)
+
+	tests := []struct {
+		name    string
+		docType string
+		docID   string
+		query   string
+		check   func(float64) bool
+		want    string
+	}{
+		{"helpful for the same query", "file", "auth/jwt.go", "jwt token refresh", func(b float64) bool { return b > 0.7 }, "> 0.7"},
+		{"helpful, related query", "file", "auth/jwt.go", "jwt refresh flow", func(b float64) bool { return b > 0.2 && b < 0.7 }, "between prior and full"},
+		{"helpful, unrelated query keeps the prior", "file", "auth/jwt.go", "billing invoices", func(b float64) bool { return math.Abs(b-math.Tanh(feedbackPrior)) < 1e-9 }, "tanh(prior)"},
+		{"unhelpful", "file", "legacy/session.go", "jwt token refresh", func(b float64) bool { return b < -0.7 }, "< -0.7"},
+		{"unrated document", "file", "other.go", "jwt token refresh", func(b float64) bool { return b == 0 }, "0"},
+		{"same ID, other type", "doc", "auth/jwt.go", "jwt token refresh", func(b float64) bool { return b == 0 }, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b := m.Boost(tt.docType, tt.docID, tt.query); !tt.check(b) {
+				t.Errorf("Boost = %v, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedbackBoostVotesCancelAndSaturate(t *testing.T) {
+	m := NewFeedbackModel()
+	m.Add("decision", "dec-1", "retry policy", true)
+	m.Add("decision", "dec-1", "retry policy", false)
+	if b := m.Boost("decision", "dec-1", "retry policy"); b != 0 {
+		t.Errorf("opposite votes: Boost = %v, want 0", b)
+	}
+
+	for i := 0; i < 50; i++ {
+		m.Add("decision", "dec-2", "retry policy", true)
+	}
+	if b := m.Boost("decision", "dec-2", "retry policy"); b < 0.99 || b > 1 {
+		t.Errorf("many votes: Boost = %v, want close to but not over 1", b)
+	}
+}
+
+func TestFeedbackRescore(t *testing.T) {
+	m := NewFeedbackModel()
+	m.Add("warning", "w-up", "cache invalidation", true)
+	m.Add("warning", "w-down", "cache invalidation", false)
+
+	up := m.Rescore("warning", "w-up", "cache invalidation", 0.5)
+	down := m.Rescore("warning", "w-down", "cache invalidation", 0.5)
+	same := m.Rescore("warning", "w-none", "cache invalidation", 0.5)
+	if !(up > 0.5 && up < 1) || !(down > 0 && down < 0.5) || same != 0.5 {
+		t.Errorf("Rescore(0.5) = up %v, down %v, unrated %v", up, down, same)
+	}
+
+	// Feedback scales the real score, so a much better match still wins
+	// over a weak one that was rated helpful
+	if boosted := m.Rescore("warning", "w-up", "cache invalidation", 0.1); boosted >= 0.5 {
+		t.Errorf("boosted weak match %v outranks an unrated strong one", boosted)
+	}
+
+	var none *FeedbackModel
+	if got := none.Rescore("warning", "w-up", "cache invalidation", 0.5); got != 0.5 {
+		t.Errorf("nil model Rescore = %v, want the score unchanged", got)
+	}
+	// A heavier weight moves scores further
+	m.SetWeight(2)
+	if heavy := m.Rescore("warning", "w-up", "cache invalidation", 0.5); heavy <= up {
+		t.Errorf("weight 2 Rescore = %v, want above %v", heavy, up)
+	}
+}
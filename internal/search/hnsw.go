@@ -0,0 +1,301 @@
+package search
+
+import (
+	"encoding/gob"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// HNSW defaults: links per node, and candidates considered while building
+const (
+	hnswM              = 16
+	hnswEfConstruction = 100
+)
+
+// sparseVector is a unit-length TF-IDF vector with its non-zero terms only
+type sparseVector struct {
+	Idx []uint16
+	Val []float32
+}
+
+// newSparseVector normalizes a dense vector; nil when it is all zeros
+func newSparseVector(v []float64) *sparseVector {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm == 0 {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+	sv := &sparseVector{}
+	for i, x := range v {
+		if x != 0 {
+			sv.Idx = append(sv.Idx, uint16(i))
+			sv.Val = append(sv.Val, float32(x/norm))
+		}
+	}
+	return sv
+}
+
+// dot is the cosine similarity of two unit vectors
+func (a *sparseVector) dot(b *sparseVector) float64 {
+	var sum float64
+	i, j := 0, 0
+	for i < len(a.Idx) && j < len(b.Idx) {
+		switch {
+		case a.Idx[i] == b.Idx[j]:
+			sum += float64(a.Val[i]) * float64(b.Val[j])
+			i++
+			j++
+		case a.Idx[i] < b.Idx[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return sum
+}
+
+// hnswNode is one document in the graph. Removed documents stay as
+// waypoints until the graph is rebuilt, but are never returned.
+type hnswNode struct {
+	ID      string
+	DocType string
+	Vec     sparseVector
+	Links   [][]int32 // neighbors per layer, layer 0 first
+	Deleted bool
+}
+
+// HNSW is a hierarchical navigable small world graph: an approximate
+// nearest neighbor index over semantic vectors, so similarity queries
+// visit a few hundred documents instead of all of them.
+type HNSW struct {
+	Nodes    []hnswNode
+	Entry    int32
+	MaxLevel int
+	// Fingerprint identifies the stored vectors the graph was built from
+	Fingerprint string
+
+	byID map[string]int32
+	rng  *rand.Rand
+}
+
+// ANNResult is a document found by an approximate search
+type ANNResult struct {
+	ID         string
+	DocType    string
+	Similarity float64
+}
+
+// NewHNSW returns an empty graph
+func NewHNSW() *HNSW {
+	return &HNSW{Entry: -1, byID: make(map[string]int32), rng: rand.New(rand.NewSource(1))}
+}
+
+// Len is the number of documents that can be returned
+func (h *HNSW) Len() int {
+	return len(h.byID)
+}
+
+// Removed is the number of removed documents still in the graph
+func (h *HNSW) Removed() int {
+	return len(h.Nodes) - len(h.byID)
+}
+
+// Add inserts a document, replacing an earlier vector with the same ID.
+// All-zero vectors can't be similar to anything and are left out.
+func (h *HNSW) Add(id, docType string, v []float64) {
+	h.Remove(id)
+	sv := newSparseVector(v)
+	if sv == nil {
+		return
+	}
+
+	level := int(-math.Log(1-h.rng.Float64()) / math.Log(hnswM))
+	n := int32(len(h.Nodes))
+	h.Nodes = append(h.Nodes, hnswNode{ID: id, DocType: docType, Vec: *sv, Links: make([][]int32, level+1)})
+	h.byID[id] = n
+
+	if h.Entry < 0 {
+		h.Entry, h.MaxLevel = n, level
+		return
+	}
+
+	ep := []int32{h.Entry}
+	for l := h.MaxLevel; l > level; l-- {
+		ep = h.searchLayer(sv, ep, 1, l)[:1]
+	}
+	for l := min(level, h.MaxLevel); l >= 0; l-- {
+		found := h.searchLayer(sv, ep, hnswEfConstruction, l)
+		neighbors := found
+		if len(neighbors) > hnswM {
+			neighbors = neighbors[:hnswM]
+		}
+		h.Nodes[n].Links[l] = append([]int32(nil), neighbors...)
+		for _, nb := range neighbors {
+			h.link(nb, n, l)
+		}
+		ep = found
+	}
+	if level > h.MaxLevel {
+		h.Entry, h.MaxLevel = n, level
+	}
+}
+
+// Remove drops a document from results
+func (h *HNSW) Remove(id string) {
+	if n, ok := h.byID[id]; ok {
+		h.Nodes[n].Deleted = true
+		delete(h.byID, id)
+	}
+}
+
+// link adds to from's neighbors at a layer, keeping only the closest when
+// it has too many
+func (h *HNSW) link(from, to int32, layer int) {
+	links := append(h.Nodes[from].Links[layer], to)
+	maxLinks := hnswM
+	if layer == 0 {
+		maxLinks = 2 * hnswM
+	}
+	if len(links) > maxLinks {
+		vec := &h.Nodes[from].Vec
+		sort.Slice(links, func(i, j int) bool {
+			return vec.dot(&h.Nodes[links[i]].Vec) > vec.dot(&h.Nodes[links[j]].Vec)
+		})
+		links = links[:maxLinks]
+	}
+	h.Nodes[from].Links[layer] = links
+}
+
+// searchLayer returns up to ef nodes of a layer closest to q, closest first
+func (h *HNSW) searchLayer(q *sparseVector, entry []int32, ef, layer int) []int32 {
+	type scored struct {
+		node int32
+		sim  float64
+	}
+	visited := make(map[int32]bool, ef*4)
+	var candidates, results []scored // candidates closest last, results closest first
+	for _, e := range entry {
+		visited[e] = true
+		s := scored{e, q.dot(&h.Nodes[e].Vec)}
+		candidates = append(candidates, s)
+		results = append(results, s)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].sim < candidates[j].sim })
+	sort.Slice(results, func(i, j int) bool { return results[i].sim > results[j].sim })
+	if len(results) > ef {
+		results = results[:ef]
+	}
+
+	for len(candidates) > 0 {
+		c := candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+		if len(results) >= ef && c.sim < results[len(results)-1].sim {
+			break
+		}
+		if layer >= len(h.Nodes[c.node].Links) {
+			continue
+		}
+		for _, nb := range h.Nodes[c.node].Links[layer] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+			s := scored{nb, q.dot(&h.Nodes[nb].Vec)}
+			if len(results) >= ef && s.sim <= results[len(results)-1].sim {
+				continue
+			}
+			i := sort.Search(len(results), func(i int) bool { return results[i].sim < s.sim })
+			results = append(results, scored{})
+			copy(results[i+1:], results[i:])
+			results[i] = s
+			if len(results) > ef {
+				results = results[:ef]
+			}
+			j := sort.Search(len(candidates), func(j int) bool { return candidates[j].sim > s.sim })
+			candidates = append(candidates, scored{})
+			copy(candidates[j+1:], candidates[j:])
+			candidates[j] = s
+		}
+	}
+
+	nodes := make([]int32, len(results))
+	for i, r := range results {
+		nodes[i] = r.node
+	}
+	return nodes
+}
+
+// Search returns up to k documents most similar to q, most similar first.
+// ef is how many candidates are explored: higher is slower but misses
+// fewer. accept filters documents by type; nil accepts all.
+func (h *HNSW) Search(q []float64, k, ef int, accept func(docType string) bool) []ANNResult {
+	sq := newSparseVector(q)
+	if sq == nil || h.Entry < 0 || k <= 0 {
+		return nil
+	}
+	if ef < k {
+		ef = k
+	}
+
+	ep := []int32{h.Entry}
+	for l := h.MaxLevel; l > 0; l-- {
+		ep = h.searchLayer(sq, ep, 1, l)[:1]
+	}
+
+	var results []ANNResult
+	for _, n := range h.searchLayer(sq, ep, ef, 0) {
+		node := &h.Nodes[n]
+		if node.Deleted || (accept != nil && !accept(node.DocType)) {
+			continue
+		}
+		results = append(results, ANNResult{ID: node.ID, DocType: node.DocType, Similarity: sq.dot(&node.Vec)})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+// Save writes the graph to a file
+func (h *HNSW) Save(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(h); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadHNSW reads a graph written by Save
+func LoadHNSW(path string) (*HNSW, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := NewHNSW()
+	if err := gob.NewDecoder(f).Decode(h); err != nil {
+		return nil, err
+	}
+	for i, n := range h.Nodes {
+		if !n.Deleted {
+			h.byID[n.ID] = int32(i)
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// randomDocs makes sparse vectors like TF-IDF ones: a few terms each out of
+// a vocabulary, with topics sharing terms so neighbors exist
+func randomDocs(n, dim int) [][]float64 {
+	rng := rand.New(rand.NewSource(7))
+	docs := make([][]float64, n)
+	for i := range docs {
+		v := make([]float64, dim)
+		topic := rng.Intn(20) * (dim / 20)
+		for t := 0; t < 12; t++ {
+			v[topic+rng.Intn(dim/20)] += rng.Float64()
+		}
+		for t := 0; t < 4; t++ {
+			v[rng.Intn(dim)] += rng.Float64() / 2
+		}
+		docs[i] = v
+	}
+	return docs
+}
+
+func docID(i int) string {
+	return "doc" + string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676))
+}
+
+func TestHNSWRecall(t *testing.T) {
+	docs := randomDocs(3000, 1000)
+	h := NewHNSW()
+	for i, v := range docs {
+		h.Add(docID(i), "decision", v)
+	}
+	if h.Len() != len(docs) {
+		t.Fatalf("Len = %d, want %d", h.Len(), len(docs))
+	}
+
+	const k = 10
+	hits, total := 0, 0
+	for q := 0; q < 50; q++ {
+		query := docs[q*37]
+		exact := make([]int, len(docs))
+		for i := range exact {
+			exact[i] = i
+		}
+		sort.Slice(exact, func(a, b int) bool {
+			return CosineSimilarity(query, docs[exact[a]]) > CosineSimilarity(query, docs[exact[b]])
+		})
+		want := make(map[string]bool)
+		for _, i := range exact[:k] {
+			want[docID(i)] = true
+		}
+		for _, r := range h.Search(query, k, 64, nil) {
+			if want[r.ID] {
+				hits++
+			}
+		}
+		total += k
+	}
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Errorf("recall@%d = %.2f, want at least 0.9", k, recall)
+	}
+}
+
+func TestHNSWRemoveFilterAndPersist(t *testing.T) {
+	docs := randomDocs(500, 400)
+	h := NewHNSW()
+	for i, v := range docs {
+		docType := "decision"
+		if i%2 == 1 {
+			docType = "warning"
+		}
+		h.Add(docID(i), docType, v)
+	}
+
+	top := h.Search(docs[0], 1, 64, nil)
+	if len(top) != 1 || top[0].ID != docID(0) || top[0].Similarity < 0.999 {
+		t.Fatalf("a document should be its own nearest neighbor: %+v", top)
+	}
+
+	h.Remove(docID(0))
+	for _, r := range h.Search(docs[0], 10, 64, nil) {
+		if r.ID == docID(0) {
+			t.Error("removed document returned")
+		}
+	}
+	for _, r := range h.Search(docs[0], 10, 256, func(dt string) bool { return dt == "warning" }) {
+		if r.DocType != "warning" {
+			t.Errorf("filter let %s through", r.DocType)
+		}
+	}
+
+	h.Fingerprint = "500:1:2"
+	path := filepath.Join(t.TempDir(), "semantic.hnsw")
+	if err := h.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHNSW(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Fingerprint != h.Fingerprint || loaded.Len() != h.Len() || loaded.Removed() != 1 {
+		t.Errorf("loaded graph: fingerprint %q, %d live, %d removed", loaded.Fingerprint, loaded.Len(), loaded.Removed())
+	}
+	if got := loaded.Search(docs[2], 1, 64, nil); len(got) != 1 || got[0].ID != docID(2) {
+		t.Errorf("search after load = %+v", got)
+	}
+	// Adding after a load keeps working
+	loaded.Add("extra", "decision", docs[0])
+	if got := loaded.Search(docs[0], 1, 64, nil); len(got) != 1 || got[0].ID != "extra" {
+		t.Errorf("search for a document added after load = %+v", got)
+	}
+}
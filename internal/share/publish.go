@@ -0,0 +1,190 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// DefaultMaxPerHour is how many artifacts may be published per hour when
+// share.max_per_hour is not set
+const DefaultMaxPerHour = 10
+
+// TokenEnv names the environment variable holding the upload token, sent
+// as a bearer token
+const TokenEnv = "TEAMCONTEXT_SHARE_TOKEN"
+
+// ErrRateLimited is returned when the hourly publish limit is used up
+var ErrRateLimited = errors.New("share rate limit reached")
+
+// ErrNotConfigured is returned when neither share.upload_url nor
+// share.upload_dir is set
+var ErrNotConfigured = errors.New("no share location configured; set share.upload_url or share.upload_dir in .teamcontext/config.json")
+
+// client is shared by uploads; a hanging server must not hang the command
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Published is one artifact published, as recorded in the share log
+type Published struct {
+	Kind string    `json:"kind"`
+	ID   string    `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+	At   time.Time `json:"at"`
+}
+
+// logPath is the share log under a .teamcontext directory. It lives in
+// cache/ with the other per-clone state, so the limit is per machine.
+func logPath(tcDir string) string {
+	return filepath.Join(tcDir, "cache", "shares.json")
+}
+
+// History returns the artifacts published from this clone, oldest first
+func History(tcDir string) ([]Published, error) {
+	data, err := os.ReadFile(logPath(tcDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var log []Published
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("reading share log: %w", err)
+	}
+	return log, nil
+}
+
+// checkRate returns ErrRateLimited, with the time the next publish is
+// allowed, when max publishes happened in the hour before now
+func checkRate(log []Published, now time.Time, max int) error {
+	if max <= 0 {
+		max = DefaultMaxPerHour
+	}
+	var recent []time.Time
+	for _, p := range log {
+		if now.Sub(p.At) < time.Hour {
+			recent = append(recent, p.At)
+		}
+	}
+	if len(recent) < max {
+		return nil
+	}
+	next := recent[len(recent)-max].Add(time.Hour)
+	return fmt.Errorf("%w: %d published in the last hour (share.max_per_hour = %d); next allowed at %s",
+		ErrRateLimited, len(recent), max, next.Format("15:04"))
+}
+
+// Publish uploads an artifact to the configured location and returns the
+// link it can be read at. Publishing is rate limited per clone: each
+// publish is logged under tcDir and refused once share.max_per_hour is
+// reached.
+func Publish(tcDir string, cfg types.ShareConfig, a *Artifact, name string, content []byte, now time.Time) (string, error) {
+	if cfg.UploadURL == "" && cfg.UploadDir == "" {
+		return "", ErrNotConfigured
+	}
+	log, err := History(tcDir)
+	if err != nil {
+		return "", err
+	}
+	if err := checkRate(log, now, cfg.MaxPerHour); err != nil {
+		return "", err
+	}
+
+	var link string
+	if cfg.UploadURL != "" {
+		link, err = upload(cfg.UploadURL, name, content)
+	} else {
+		link, err = copyTo(filepath.Dir(tcDir), cfg.UploadDir, name, content)
+	}
+	if err != nil {
+		return "", err
+	}
+	if cfg.PublicURL != "" {
+		link = strings.TrimRight(cfg.PublicURL, "/") + "/" + url.PathEscape(name)
+	}
+
+	// Drop entries older than the window so the log stays small
+	kept := log[:0]
+	for _, p := range log {
+		if now.Sub(p.At) < 24*time.Hour {
+			kept = append(kept, p)
+		}
+	}
+	kept = append(kept, Published{Kind: a.Provenance.Kind, ID: a.Provenance.ID, Name: name, URL: link, At: now})
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath(tcDir)), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(logPath(tcDir), data, 0644); err != nil {
+		return "", fmt.Errorf("published to %s but could not record it: %w", link, err)
+	}
+	return link, nil
+}
+
+// upload PUTs content to endpoint, with {name} replaced by the file name
+// or the name appended as a path segment. The response's Location header,
+// when there is one, is the link.
+func upload(endpoint, name string, content []byte) (string, error) {
+	target := strings.ReplaceAll(endpoint, "{name}", url.PathEscape(name))
+	if target == endpoint {
+		target = strings.TrimRight(endpoint, "/") + "/" + url.PathEscape(name)
+	}
+	req, err := http.NewRequest("PUT", target, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	contentType := "text/markdown; charset=utf-8"
+	if strings.HasSuffix(name, ".html") {
+		contentType = "text/html; charset=utf-8"
+	}
+	req.Header.Set("Content-Type", contentType)
+	if token := os.Getenv(TokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("uploading %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("uploading %s: %s %s", name, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if loc := resp.Header.Get("Location"); loc != "" {
+		if u, err := resp.Request.URL.Parse(loc); err == nil {
+			return u.String(), nil
+		}
+	}
+	return target, nil
+}
+
+// copyTo writes content into dir, relative to the project root unless
+// absolute, and returns the file's path as the link
+func copyTo(projectRoot, dir, name string, content []byte) (string, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectRoot, dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
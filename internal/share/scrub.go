@@ -0,0 +1,57 @@
+package share
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces every secret scrubbed from a shared artifact
+const Redacted = "[REDACTED]"
+
+// secretPatterns match credentials that end up pasted into decisions and
+// warnings: provider tokens, private keys, JWTs and bearer headers
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bgithub_pat_\w{22,}`),
+	regexp.MustCompile(`\bglpat-[\w-]{20,}`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\b[sr]k_(?:live|test)_[A-Za-z0-9]{16,}`),
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),
+	regexp.MustCompile(`\beyJ[\w-]{8,}\.eyJ[\w-]{8,}\.[\w-]{8,}`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{16,}=*`),
+}
+
+var (
+	// user:password@ in connection strings and URLs
+	urlCredentials = regexp.MustCompile(`(\b[a-z][a-z0-9+.-]*://)[^/\s:@]+:[^/\s@]+@`)
+
+	// password=..., API_KEY: "...", clientSecret = '...'; the name is kept
+	secretAssignment = regexp.MustCompile(`(?i)(\b[\w.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)\b["']?\s*[:=]\s*["']?)([^\s"',;]{6,})`)
+)
+
+// Scrub replaces secrets in text with Redacted and reports how many it
+// replaced. It errs on the side of redacting: a shared artifact leaves the
+// repository's access control behind.
+func Scrub(text string) (string, int) {
+	count := 0
+	for _, re := range secretPatterns {
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			count++
+			return Redacted
+		})
+	}
+	text = urlCredentials.ReplaceAllStringFunc(text, func(m string) string {
+		count++
+		return urlCredentials.ReplaceAllString(m, "${1}"+Redacted+"@")
+	})
+	text = secretAssignment.ReplaceAllStringFunc(text, func(m string) string {
+		sub := secretAssignment.FindStringSubmatch(m)
+		if strings.Contains(sub[2], Redacted) {
+			return m
+		}
+		count++
+		return sub[1] + Redacted
+	})
+	return text, count
+}
@@ -0,0 +1,315 @@
+// Package share turns a single knowledge item (a decision, a warning or the
+// architecture view) into a standalone markdown or HTML artifact another
+// team can read without TeamContext: secrets scrubbed, provenance included,
+// optionally published to a configured location at a limited rate.
+package share
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Provenance records where a shared artifact came from, so a reader can
+// trace it back to the repository and judge how current it is
+type Provenance struct {
+	Project    string    `json:"project,omitempty"`
+	Kind       string    `json:"kind"` // decision, warning or architecture
+	ID         string    `json:"id"`
+	Author     string    `json:"author,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	RecordedAt time.Time `json:"recorded_at,omitempty"` // when the item was recorded or last updated
+	Commit     string    `json:"commit,omitempty"`      // HEAD when the artifact was made
+	Branch     string    `json:"branch,omitempty"`
+	SharedBy   string    `json:"shared_by,omitempty"`
+	SharedAt   time.Time `json:"shared_at"`
+	Generator  string    `json:"generator,omitempty"` // teamcontext version
+}
+
+// Section is one headed part of an artifact: prose, a list, or a code
+// block such as a diagram or log excerpt
+type Section struct {
+	Heading  string   `json:"heading"`
+	Text     string   `json:"text,omitempty"`
+	Items    []string `json:"items,omitempty"`
+	Code     string   `json:"code,omitempty"`
+	CodeLang string   `json:"code_lang,omitempty"`
+}
+
+// Artifact is a knowledge item ready to share
+type Artifact struct {
+	Title      string     `json:"title"`
+	Sections   []Section  `json:"sections"`
+	Provenance Provenance `json:"provenance"`
+	Redactions int        `json:"redactions"` // secrets scrubbed from the content
+}
+
+// titleLength caps the part of an item's content used as its title
+const titleLength = 80
+
+// Decision builds the artifact of a decision. prov carries the
+// repository-level fields; the item's own are filled in.
+func Decision(d types.Decision, prov Provenance) *Artifact {
+	prov.Kind, prov.ID, prov.Author, prov.Status, prov.RecordedAt = "decision", d.ID, d.Author, d.Status, d.CreatedAt
+	a := &Artifact{Title: "Decision: " + headline(d.Content), Provenance: prov}
+	a.add(Section{Heading: "Decision", Text: d.Content})
+	a.add(Section{Heading: "Why", Text: d.Reason})
+	a.add(Section{Heading: "Context", Text: d.Context})
+	a.add(Section{Heading: "Alternatives considered", Items: d.Alternatives})
+	if d.Supersedes != "" {
+		a.add(Section{Heading: "Supersedes", Text: d.Supersedes})
+	}
+	a.add(Section{Heading: "Related files", Items: d.RelatedFiles})
+	a.add(Section{Heading: "Issues", Items: d.Issues})
+	a.add(Section{Heading: "Tags", Items: d.Tags})
+	a.scrub()
+	return a
+}
+
+// Warning builds the artifact of a warning, with its anchored regions as
+// the places it applies to
+func Warning(w types.Warning, prov Provenance) *Artifact {
+	prov.Kind, prov.ID, prov.Author, prov.Status, prov.RecordedAt = "warning", w.ID, w.Author, w.Severity, w.CreatedAt
+	a := &Artifact{Title: "Warning: " + headline(w.Content), Provenance: prov}
+	a.add(Section{Heading: "Warning", Text: w.Content})
+	a.add(Section{Heading: "Why", Text: w.Reason})
+	a.add(Section{Heading: "Evidence", Code: w.Evidence})
+
+	var where []string
+	anchored := make(map[string]bool)
+	for _, anchor := range w.Anchors {
+		anchored[anchor.File] = true
+		loc := anchor.File
+		if anchor.StartLine > 0 {
+			loc += fmt.Sprintf(":%d", anchor.StartLine)
+			if anchor.EndLine > anchor.StartLine {
+				loc += fmt.Sprintf("-%d", anchor.EndLine)
+			}
+		}
+		if anchor.Symbol != "" {
+			loc += " (" + anchor.Symbol + ")"
+		}
+		where = append(where, loc)
+	}
+	for _, f := range w.RelatedFiles {
+		if !anchored[f] {
+			where = append(where, f)
+		}
+	}
+	a.add(Section{Heading: "Applies to", Items: where})
+	a.add(Section{Heading: "Related decisions", Items: w.RelatedDecisions})
+	a.add(Section{Heading: "Issues", Items: w.Issues})
+	a.add(Section{Heading: "Tags", Items: w.Tags})
+	a.scrub()
+	return a
+}
+
+// mermaidStart recognizes a mermaid diagram, to label its code block
+var mermaidStart = regexp.MustCompile(`^\s*(?:graph|flowchart|sequenceDiagram|classDiagram|erDiagram|stateDiagram|C4\w+)\b`)
+
+// Architecture builds the artifact of the recorded architecture view
+func Architecture(arch types.Architecture, prov Provenance) *Artifact {
+	prov.Kind, prov.ID, prov.RecordedAt = "architecture", "architecture", arch.UpdatedAt
+	title := "Architecture"
+	if prov.Project != "" {
+		title += ": " + prov.Project
+	}
+	a := &Artifact{Title: title, Provenance: prov}
+	a.add(Section{Heading: "Overview", Text: arch.Description})
+	diagram := Section{Heading: "Diagram", Code: arch.Diagram}
+	if mermaidStart.MatchString(arch.Diagram) {
+		diagram.CodeLang = "mermaid"
+	}
+	a.add(diagram)
+
+	var services []string
+	for _, svc := range arch.Services {
+		line := svc.Name
+		if svc.Description != "" {
+			line += ": " + svc.Description
+		}
+		if len(svc.Dependencies) > 0 {
+			line += " (depends on " + strings.Join(svc.Dependencies, ", ") + ")"
+		}
+		services = append(services, line)
+	}
+	a.add(Section{Heading: "Services", Items: services})
+
+	var flows []string
+	for _, flow := range arch.DataFlows {
+		line := flow.From + " → " + flow.To
+		if flow.Description != "" {
+			line += ": " + flow.Description
+		}
+		flows = append(flows, line)
+	}
+	a.add(Section{Heading: "Data flows", Items: flows})
+	a.scrub()
+	return a
+}
+
+// add appends a section unless it has nothing in it
+func (a *Artifact) add(s Section) {
+	if strings.TrimSpace(s.Text) == "" && len(s.Items) == 0 && strings.TrimSpace(s.Code) == "" {
+		return
+	}
+	a.Sections = append(a.Sections, s)
+}
+
+// scrub redacts secrets from the title and every section
+func (a *Artifact) scrub() {
+	clean := func(s *string) {
+		var n int
+		*s, n = Scrub(*s)
+		a.Redactions += n
+	}
+	clean(&a.Title)
+	for i := range a.Sections {
+		s := &a.Sections[i]
+		clean(&s.Text)
+		clean(&s.Code)
+		items := make([]string, len(s.Items))
+		for j, item := range s.Items {
+			items[j] = item
+			clean(&items[j])
+		}
+		s.Items = items
+	}
+}
+
+// headline is the first line of content, shortened to a title
+func headline(content string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if r := []rune(line); len(r) > titleLength {
+		line = strings.TrimSpace(string(r[:titleLength-1])) + "…"
+	}
+	return line
+}
+
+// FileName is the name an artifact is written and published under
+func (a *Artifact) FileName(format string) string {
+	ext := ".md"
+	if format == "html" {
+		ext = ".html"
+	}
+	name := a.Provenance.ID
+	if a.Provenance.Kind == "architecture" {
+		name = "architecture-" + a.Provenance.SharedAt.Format("2006-01-02")
+	}
+	return name + ext
+}
+
+// provenanceLines lists the provenance fields that are set, in reading order
+func (a *Artifact) provenanceLines() [][2]string {
+	p := a.Provenance
+	var lines [][2]string
+	add := func(name, value string) {
+		if value != "" {
+			lines = append(lines, [2]string{name, value})
+		}
+	}
+	add("Project", p.Project)
+	add(strings.ToUpper(p.Kind[:1])+p.Kind[1:], p.ID)
+	add("Author", p.Author)
+	add("Status", p.Status)
+	if !p.RecordedAt.IsZero() {
+		add("Recorded", p.RecordedAt.UTC().Format(time.RFC3339))
+	}
+	if p.Commit != "" {
+		commit := p.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if p.Branch != "" {
+			commit += " (" + p.Branch + ")"
+		}
+		add("Commit", commit)
+	}
+	add("Shared by", p.SharedBy)
+	add("Shared", p.SharedAt.UTC().Format(time.RFC3339))
+	add("Generated by", p.Generator)
+	if a.Redactions > 0 {
+		add("Redacted", fmt.Sprintf("%d secret(s) removed before sharing", a.Redactions))
+	}
+	return lines
+}
+
+// Markdown renders the artifact as a markdown document
+func (a *Artifact) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", a.Title)
+	for _, s := range a.Sections {
+		fmt.Fprintf(&sb, "## %s\n\n", s.Heading)
+		if s.Text != "" {
+			sb.WriteString(strings.TrimSpace(s.Text) + "\n\n")
+		}
+		for _, item := range s.Items {
+			sb.WriteString("- " + item + "\n")
+		}
+		if len(s.Items) > 0 {
+			sb.WriteString("\n")
+		}
+		if s.Code != "" {
+			fmt.Fprintf(&sb, "```%s\n%s\n```\n\n", s.CodeLang, strings.TrimRight(s.Code, "\n"))
+		}
+	}
+	sb.WriteString("---\n\n**Provenance**\n\n")
+	for _, line := range a.provenanceLines() {
+		fmt.Fprintf(&sb, "- %s: %s\n", line[0], line[1])
+	}
+	return sb.String()
+}
+
+var htmlPage = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="{{.Provenance.Generator}}">
+<title>{{.Title}}</title>
+<style>
+body { font: 16px/1.55 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; }
+h1 { font-size: 1.6rem; border-bottom: 1px solid #d0d7de; padding-bottom: .4rem; }
+h2 { font-size: 1.15rem; margin-top: 1.6rem; }
+p { white-space: pre-wrap; }
+pre { background: #f6f8fa; padding: .8rem; overflow-x: auto; border-radius: 6px; }
+footer { margin-top: 2.5rem; border-top: 1px solid #d0d7de; font-size: .85rem; color: #59636e; }
+footer dt { float: left; clear: left; width: 7rem; font-weight: 600; }
+footer dd { margin-left: 7.5rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}<section>
+<h2>{{.Heading}}</h2>
+{{if .Text}}<p>{{.Text}}</p>
+{{end}}{{if .Items}}<ul>
+{{range .Items}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}{{if .Code}}<pre><code{{if .CodeLang}} class="language-{{.CodeLang}}"{{end}}>{{.Code}}</code></pre>
+{{end}}</section>
+{{end}}<footer>
+<p><strong>Provenance</strong></p>
+<dl>
+{{range .Lines}}<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>
+{{end}}</dl>
+</footer>
+</body>
+</html>
+`))
+
+// HTML renders the artifact as a self-contained HTML page: inline styles,
+// no scripts, nothing fetched when it is opened
+func (a *Artifact) HTML() (string, error) {
+	var buf bytes.Buffer
+	err := htmlPage.Execute(&buf, struct {
+		*Artifact
+		Lines [][2]string
+	}{a, a.provenanceLines()})
+	return buf.String(), err
+}
@@ -0,0 +1,114 @@
+package skeleton
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Skeleton parsers, for the index.parser setting
+const (
+	ParserAuto       = "auto"        // tree-sitter when the build has it, regex otherwise
+	ParserRegex      = "regex"       // line-based regex parsers only
+	ParserTreeSitter = "tree-sitter" // grammar-based, falling back to regex per language
+)
+
+// Backend parses source with a real grammar, which gets multi-line
+// signatures, nested classes and generics right where the regex parsers
+// guess. Parse fills skeleton and returns false for languages it has no
+// grammar for or source it cannot parse; ParseFile then falls back to the
+// regex parser.
+type Backend interface {
+	Name() string
+	Parse(path, language string, content []byte, skeleton *types.CodeSkeleton) bool
+}
+
+var (
+	backendMu sync.RWMutex
+	// backend is registered by builds that include one (-tags treesitter)
+	backend    Backend
+	backendOff bool
+)
+
+// backendLanguages are the extensions ParseFile offers to the backend
+var backendLanguages = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".ts":   "typescript",
+	".tsx":  "typescript",
+	".js":   "javascript",
+	".jsx":  "javascript",
+	".mjs":  "javascript",
+	".java": "java",
+}
+
+func registerBackend(b Backend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backend = b
+}
+
+// SetParser selects the skeleton parser. "regex" turns the grammar backend
+// off; "tree-sitter" fails when this build has none; "" and "auto" use the
+// backend when there is one.
+func SetParser(name string) error {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	switch name {
+	case "", ParserAuto:
+		backendOff = false
+	case ParserRegex:
+		backendOff = true
+	case ParserTreeSitter:
+		if backend == nil {
+			backendOff = true
+			return fmt.Errorf("parser %q is not in this build (build with -tags treesitter); using regex", name)
+		}
+		backendOff = false
+	default:
+		return fmt.Errorf("unknown parser %q: use %s, %s or %s", name, ParserAuto, ParserRegex, ParserTreeSitter)
+	}
+	return nil
+}
+
+// ParserName is the parser ParseFile uses for the languages the backend
+// covers: "tree-sitter" or "regex"
+func ParserName() string {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	if backend == nil || backendOff {
+		return ParserRegex
+	}
+	return backend.Name()
+}
+
+// skeletonFormat is bumped when the parsers start recording something new,
+// so skeletons cached by an older build are parsed again
+const skeletonFormat = 2
+
+// CacheKey identifies what ParseFile produces: the parser in use and the
+// skeleton format. A skeleton cached under another key must be parsed again.
+func CacheKey() string {
+	return fmt.Sprintf("%s/%d", ParserName(), skeletonFormat)
+}
+
+// parseWithBackend parses a file with the grammar backend when one is on
+// and covers its language
+func parseWithBackend(path, ext string, content []byte, skeleton *types.CodeSkeleton) bool {
+	lang, ok := backendLanguages[ext]
+	if !ok {
+		return false
+	}
+	backendMu.RLock()
+	b := backend
+	if backendOff {
+		b = nil
+	}
+	backendMu.RUnlock()
+	if b == nil || !b.Parse(path, lang, content, skeleton) {
+		return false
+	}
+	skeleton.Language = lang
+	return true
+}
@@ -0,0 +1,84 @@
+package skeleton
+
+import (
+	"testing"
+)
+
+func TestSetParser(t *testing.T) {
+	defer SetParser(ParserAuto)
+
+	if err := SetParser("bogus"); err == nil {
+		t.Error("SetParser(bogus) should fail")
+	}
+	if err := SetParser(ParserRegex); err != nil {
+		t.Fatalf("SetParser(regex): %v", err)
+	}
+	if got := ParserName(); got != ParserRegex {
+		t.Errorf("ParserName() = %q after SetParser(regex), want regex", got)
+	}
+
+	err := SetParser(ParserTreeSitter)
+	if backend == nil && err == nil {
+		t.Error("SetParser(tree-sitter) should fail when the build has no backend")
+	}
+	if backend != nil && (err != nil || ParserName() != ParserTreeSitter) {
+		t.Errorf("SetParser(tree-sitter) = %v, ParserName() = %q", err, ParserName())
+	}
+
+	if err := SetParser(""); err != nil {
+		t.Fatalf("SetParser(\"\"): %v", err)
+	}
+	want := ParserRegex
+	if backend != nil {
+		want = ParserTreeSitter
+	}
+	if got := ParserName(); got != want {
+		t.Errorf("ParserName() = %q with auto, want %q", got, want)
+	}
+}
+
+// Whichever backend is on, ParseFile gives the same skeleton shape
+func TestParseFileWithEitherParser(t *testing.T) {
+	defer SetParser(ParserAuto)
+
+	content := `package users
+
+// Service handles users
+type Service struct {
+	repo Repo
+}
+
+type Repo interface {
+	Find(id string) (*User, error)
+}
+
+const MaxUsers = 100
+
+func (s *Service) Get(id string) (*User, error) {
+	return s.repo.Find(id)
+}
+
+func helper() {}
+`
+	path, cleanup := setupTestFile(t, content, ".go")
+	defer cleanup()
+
+	for _, parser := range []string{ParserRegex, ParserAuto} {
+		if err := SetParser(parser); err != nil {
+			t.Fatalf("SetParser(%s): %v", parser, err)
+		}
+		sk, err := ParseFile(path)
+		if err != nil {
+			t.Fatalf("%s: ParseFile: %v", parser, err)
+		}
+		if sk.Language != "go" {
+			t.Errorf("%s: language = %q, want go", parser, sk.Language)
+		}
+		if !hasMethod(sk, "Get") || !hasFunction(sk, "helper") {
+			t.Errorf("%s: classes = %+v, functions = %+v, want method Get and function helper", parser, sk.Classes, sk.Functions)
+		}
+		if len(sk.Interfaces) != 1 || sk.Interfaces[0].Name != "Repo" {
+			t.Errorf("%s: interfaces = %+v, want Repo", parser, sk.Interfaces)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package skeleton
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+var (
+	callSite      = regexp.MustCompile(`(\.|->|::)?\s*\b([A-Za-z_$][\w$]*)\s*\(`)
+	stringLiteral = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|` + "`[^`]*`")
+)
+
+// Languages whose bodies end at an outdent or keyword rather than a brace
+var (
+	indentLanguages  = map[string]bool{"python": true}
+	keywordLanguages = map[string]bool{"ruby": true, "elixir": true, "erlang": true}
+	hashComments     = map[string]bool{"python": true, "ruby": true, "elixir": true, "shell": true}
+)
+
+// callable is a function or method of the file with the lines of its body
+type callable struct {
+	fn    *types.FunctionSig
+	class string
+	name  string // qualified: Class.method for methods
+	start int    // 0-based index of the declaration line
+	end   int
+}
+
+// linkCalls records in each function which functions and methods of the
+// same file it calls. It is best effort: call sites are matched by name,
+// so calls through variables or to same-named functions of other files
+// can be missed or misattributed.
+func linkCalls(content string, sk *types.CodeSkeleton) {
+	var fns []*callable
+	add := func(fn *types.FunctionSig, class string) {
+		if fn.Line <= 0 || fn.Name == "" {
+			return
+		}
+		name := fn.Name
+		if class != "" {
+			name = class + "." + fn.Name
+		}
+		fns = append(fns, &callable{fn: fn, class: class, name: name, start: fn.Line - 1})
+	}
+	for i := range sk.Functions {
+		add(&sk.Functions[i], "")
+	}
+	for i := range sk.Classes {
+		c := &sk.Classes[i]
+		if c.Constructor != nil {
+			add(c.Constructor, c.Name)
+		}
+		for j := range c.Methods {
+			add(&c.Methods[j], c.Name)
+		}
+	}
+	if len(fns) < 2 {
+		return
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		lines[i] = scrubLine(lines[i], sk.Language)
+	}
+	sort.SliceStable(fns, func(i, j int) bool { return fns[i].start < fns[j].start })
+	for i, c := range fns {
+		next := len(lines)
+		for _, d := range fns[i+1:] {
+			if d.start > c.start {
+				next = d.start
+				break
+			}
+		}
+		c.end = bodyEnd(lines, c.start, next, sk.Language)
+	}
+
+	// Functions by their bare name
+	byName := make(map[string][]*callable)
+	for _, c := range fns {
+		byName[c.fn.Name] = append(byName[c.fn.Name], c)
+	}
+
+	for _, c := range fns {
+		if c.start >= len(lines) {
+			continue
+		}
+		seen := make(map[string]bool)
+		var calls []string
+		declared := false
+		for n := c.start; n <= c.end && n < len(lines); n++ {
+			line := lines[n]
+			// The declaration, below any decorators, names the function itself
+			if !declared {
+				if i := strings.Index(line, c.fn.Name); i >= 0 {
+					line = line[i+len(c.fn.Name):]
+					declared = true
+				}
+			}
+			for _, m := range callSite.FindAllStringSubmatch(line, -1) {
+				target := resolveCall(c, m[2], m[1] != "", byName)
+				if target != "" && !seen[target] {
+					seen[target] = true
+					calls = append(calls, target)
+				}
+			}
+		}
+		c.fn.Calls = calls
+	}
+}
+
+// resolveCall picks which function a call by name refers to: the caller's
+// own class first, then a top-level function for a bare call, then a
+// method when only one class has it
+func resolveCall(caller *callable, name string, qualified bool, byName map[string][]*callable) string {
+	candidates := byName[name]
+	if len(candidates) == 0 {
+		return ""
+	}
+	var topLevel string
+	var methods []string
+	for _, d := range candidates {
+		if caller.class != "" && d.class == caller.class {
+			return d.name
+		}
+		if d.class == "" {
+			topLevel = d.name
+		} else {
+			methods = append(methods, d.name)
+		}
+	}
+	if !qualified && topLevel != "" {
+		return topLevel
+	}
+	if qualified && len(methods) == 1 {
+		return methods[0]
+	}
+	return ""
+}
+
+// bodyEnd finds the last line of the body declared at start: where its
+// braces close, where Python dedents, or else the line before the next
+// function
+func bodyEnd(lines []string, start, next int, language string) int {
+	last := next - 1
+	if last < start {
+		last = start
+	}
+	switch {
+	case indentLanguages[language]:
+		// Decorators sit above the def at the same indent
+		for start < len(lines)-1 && strings.HasPrefix(strings.TrimSpace(lines[start]), "@") {
+			start++
+		}
+		indent := leadingSpace(lines[start])
+		for n := start + 1; n < len(lines); n++ {
+			if strings.TrimSpace(lines[n]) == "" {
+				continue
+			}
+			if leadingSpace(lines[n]) <= indent && !strings.HasPrefix(strings.TrimSpace(lines[n]), ")") {
+				return n - 1
+			}
+		}
+		return len(lines) - 1
+	case keywordLanguages[language]:
+		return last
+	}
+
+	depth, opened := 0, false
+	for n := start; n < len(lines); n++ {
+		depth += braceDelta(lines[n])
+		if strings.Contains(lines[n], "{") {
+			opened = true
+		}
+		if opened && depth <= 0 {
+			return n
+		}
+		// A declaration without a body: abstract, or a prototype
+		if !opened && strings.HasSuffix(strings.TrimSpace(lines[n]), ";") {
+			return n
+		}
+		if !opened && n >= start+5 {
+			break
+		}
+	}
+	return last
+}
+
+// scrubLine blanks string literals and drops comments so their text isn't
+// read as calls
+func scrubLine(line, language string) string {
+	line = stringLiteral.ReplaceAllString(line, `""`)
+	comment := "//"
+	if hashComments[language] {
+		comment = "#"
+	}
+	if i := strings.Index(line, comment); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
+
+func leadingSpace(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
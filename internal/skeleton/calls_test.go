@@ -0,0 +1,112 @@
+package skeleton
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// callsOf returns the calls of every function and method, by qualified name
+func callsOf(sk *types.CodeSkeleton) map[string][]string {
+	calls := make(map[string][]string)
+	for _, fn := range sk.Functions {
+		calls[fn.Name] = fn.Calls
+	}
+	for _, c := range sk.Classes {
+		if c.Constructor != nil {
+			calls[c.Name+"."+c.Constructor.Name] = c.Constructor.Calls
+		}
+		for _, m := range c.Methods {
+			calls[c.Name+"."+m.Name] = m.Calls
+		}
+	}
+	return calls
+}
+
+func TestLinkCallsTypeScript(t *testing.T) {
+	sk, err := ParseContent("orders.ts", []byte(`export class OrderService {
+  constructor(private repo: Repo) {
+    this.reset();
+  }
+
+  place(order: Order): void {
+    validate(order);
+    // save(order) in a comment is not a call
+    const label = "total(order)";
+    this.repo.save(order);
+    this.notify(order);
+  }
+
+  notify(order: Order): void {
+    send(format(order));
+  }
+
+  reset(): void {}
+}
+
+export function validate(order: Order): void {
+  if (order.total() < 0) throw new Error("negative");
+}
+
+function format(order: Order): string {
+  return order.id;
+}
+
+function total(order: Order): number { return 0; }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"OrderService.constructor": {"OrderService.reset"},
+		"OrderService.place":       {"validate", "OrderService.notify"},
+		"OrderService.notify":      {"format"},
+		"OrderService.reset":       nil,
+		"validate":                 nil,
+		"format":                   nil,
+		"total":                    nil,
+	}
+	if got := callsOf(sk); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %v\nwant %v", got, want)
+	}
+}
+
+func TestLinkCallsGoAndPython(t *testing.T) {
+	goSk, _ := ParseContent("store.go", []byte(`package store
+
+type Store struct{}
+
+func (s *Store) Get(key string) string {
+	return s.load(normalize(key))
+}
+
+func (s *Store) load(key string) string { return key }
+
+func normalize(key string) string {
+	return strings.ToLower(key)
+}
+`))
+	if got := callsOf(goSk); !reflect.DeepEqual(got["Store.Get"], []string{"Store.load", "normalize"}) || got["normalize"] != nil {
+		t.Errorf("go calls = %v", got)
+	}
+
+	pySk, _ := ParseContent("jobs.py", []byte(`import os
+
+@retry
+def run(job):
+    prepare(job)
+    return execute(job)
+
+def prepare(job):
+    # run(job) is mentioned, not called
+    pass
+
+def execute(job):
+    return run(job) if job.retry else None
+`))
+	want := map[string][]string{"run": {"prepare", "execute"}, "prepare": nil, "execute": {"run"}}
+	if got := callsOf(pySk); !reflect.DeepEqual(got, want) {
+		t.Errorf("python calls = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,330 @@
+package skeleton
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+var (
+	// <script>, <script setup lang="ts">, <script context="module">
+	sfcScriptRe  = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+	sfcLangAttr  = regexp.MustCompile(`(?i)\blang\s*=\s*["']?(\w+)`)
+	sfcSetupAttr = regexp.MustCompile(`(?i)(?:^|\s)setup\b`)
+
+	vueOptionsRe     = regexp.MustCompile(`export\s+default\s+(?:defineComponent\s*\(\s*)?\{`)
+	vueDefineRe      = regexp.MustCompile(`\b(defineProps|defineEmits)\s*(<|\(\s*[\[{])`)
+	vueDefineOptions = regexp.MustCompile(`\bdefineOptions\s*\(\s*\{`)
+
+	svelteExportLet   = regexp.MustCompile(`(?m)^\s*export\s+let\s+(\w+)\s*(?::\s*([^=;]+?))?\s*(?:=|;|$)`)
+	svelteRunesRe     = regexp.MustCompile(`\blet\s*\{`)
+	svelteRunesTail   = regexp.MustCompile(`^\s*(?::\s*([\w.]+))?\s*=\s*\$props\s*\(`)
+	svelteRunesAssign = regexp.MustCompile(`^\s*=\s*\$props\s*\(`)
+
+	objectEntryRe = regexp.MustCompile(`(?s)^\s*['"]?([\w$-]+)['"]?\s*(?::\s*(.*))?$`)
+	typeMemberRe  = regexp.MustCompile(`(?s)^(?:readonly\s+)?['"]?([\w$-]+)['"]?\??\s*:\s*(.+)$`)
+	runtimeTypeRe = regexp.MustCompile(`\btype\s*:\s*([^,}\n]+)`)
+	emitSigRe     = regexp.MustCompile(`^\(\s*\w+\s*:\s*['"]([^'"]+)['"]`)
+)
+
+// parseComponent fills a skeleton from a Vue or Svelte single-file
+// component. Its script blocks are parsed as TypeScript or JavaScript with
+// the template and styles blanked out, so lines match the file.
+func parseComponent(filePath, content string, skeleton *types.CodeSkeleton) {
+	framework := strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+	skeleton.Language = framework
+
+	comp := &types.ComponentDef{
+		Name:      componentName(filePath),
+		Line:      1,
+		Framework: framework,
+		Script:    "javascript",
+	}
+	script := componentScript(content, comp)
+	parseTypeScript(script, skeleton)
+
+	switch framework {
+	case "vue":
+		vueComponent(script, comp)
+	case "svelte":
+		svelteComponent(script, comp)
+	}
+	skeleton.Component = comp
+}
+
+// componentScript returns the file with everything outside its script
+// blocks blanked out, and records the script language on comp
+func componentScript(content string, comp *types.ComponentDef) string {
+	var sb strings.Builder
+	pos := 0
+	for i, m := range sfcScriptRe.FindAllStringSubmatchIndex(content, -1) {
+		attrs := content[m[2]:m[3]]
+		if lang := sfcLangAttr.FindStringSubmatch(attrs); lang != nil {
+			switch strings.ToLower(lang[1]) {
+			case "ts", "tsx", "typescript":
+				comp.Script = "typescript"
+			}
+		}
+		if sfcSetupAttr.MatchString(attrs) {
+			comp.Setup = true
+		}
+		if i == 0 {
+			comp.Line = strings.Count(content[:m[0]], "\n") + 1
+		}
+		sb.WriteString(strings.Repeat("\n", strings.Count(content[pos:m[4]], "\n")))
+		sb.WriteString(content[m[4]:m[5]])
+		pos = m[5]
+	}
+	sb.WriteString(strings.Repeat("\n", strings.Count(content[pos:], "\n")))
+	return sb.String()
+}
+
+// componentName names a component after its file: user-card.vue is UserCard
+func componentName(filePath string) string {
+	base := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(base, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+// vueComponent reads props, emits and the component name from
+// defineProps/defineEmits in <script setup>, or from the options object
+func vueComponent(script string, comp *types.ComponentDef) {
+	for _, m := range vueDefineRe.FindAllStringSubmatchIndex(script, -1) {
+		macro := script[m[2]:m[3]]
+		open := m[5] - 1
+		var props []types.PropertyDef
+		var emits []string
+		if script[open] == '<' {
+			props, emits = vueTypeArgument(script, open)
+		} else {
+			props, emits = vueRuntimeArgument(script, open)
+		}
+		if macro == "defineProps" {
+			comp.Props = append(comp.Props, props...)
+		} else {
+			comp.Emits = append(comp.Emits, emits...)
+		}
+	}
+
+	options := ""
+	if loc := vueDefineOptions.FindStringIndex(script); loc != nil {
+		options = braceBody(script, loc[1]-1)
+	} else if loc := vueOptionsRe.FindStringIndex(script); loc != nil {
+		options = braceBody(script, loc[1]-1)
+	}
+	for _, item := range splitEnumBody(options, ",") {
+		key, value := objectEntry(item)
+		switch key {
+		case "name":
+			if name := memberName(value); name != "" && strings.ContainsAny(value[:1], `"'`) {
+				comp.Name = componentName(name)
+			}
+		case "props":
+			props, _ := vueRuntimeArgument(value, 0)
+			comp.Props = append(comp.Props, props...)
+		case "emits":
+			_, emits := vueRuntimeArgument(value, 0)
+			comp.Emits = append(comp.Emits, emits...)
+		}
+	}
+}
+
+// vueTypeArgument reads the type argument of defineProps<...>() or
+// defineEmits<...>(), inline or the name of an interface in the script
+func vueTypeArgument(script string, open int) ([]types.PropertyDef, []string) {
+	arg := strings.TrimSpace(script[open+1 : angleEnd(script, open)])
+	body := ""
+	if strings.HasPrefix(arg, "{") {
+		body = braceBody(arg, 0)
+	} else {
+		body = namedTypeBody(script, arg)
+	}
+
+	var props []types.PropertyDef
+	var emits []string
+	for _, item := range splitTypeMembers(body) {
+		item = strings.TrimSpace(item)
+		if m := emitSigRe.FindStringSubmatch(item); m != nil {
+			emits = append(emits, m[1])
+			continue
+		}
+		if m := typeMemberRe.FindStringSubmatch(item); m != nil {
+			props = append(props, types.PropertyDef{Name: m[1], Type: oneLine(strings.TrimSpace(m[2]))})
+			emits = append(emits, m[1])
+		}
+	}
+	return props, emits
+}
+
+// vueRuntimeArgument reads props or emits declared at runtime: an array of
+// names, or an object whose keys are the names
+func vueRuntimeArgument(s string, from int) ([]types.PropertyDef, []string) {
+	s = strings.TrimSpace(s[from:])
+	s = strings.TrimSpace(strings.TrimPrefix(s, "("))
+	var props []types.PropertyDef
+	var emits []string
+	switch {
+	case strings.HasPrefix(s, "["):
+		for _, item := range splitEnumBody(bracketBody(s, 0), ",") {
+			if name := memberName(item); name != "" {
+				props = append(props, types.PropertyDef{Name: name})
+				emits = append(emits, name)
+			}
+		}
+	case strings.HasPrefix(s, "{"):
+		for _, item := range splitEnumBody(braceBody(s, 0), ",") {
+			key, value := objectEntry(item)
+			if key == "" {
+				continue
+			}
+			props = append(props, types.PropertyDef{Name: key, Type: runtimePropType(value)})
+			emits = append(emits, key)
+		}
+	}
+	return props, emits
+}
+
+// runtimePropType is the type of a runtime prop declaration: String,
+// [String, Number], or the type of { type: Number, required: true }
+func runtimePropType(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		if m := runtimeTypeRe.FindStringSubmatch(value); m != nil {
+			return oneLine(strings.TrimSpace(m[1]))
+		}
+		return ""
+	}
+	return oneLine(value)
+}
+
+// svelteComponent reads props: export let in Svelte 4, the destructured
+// $props() of Svelte 5
+func svelteComponent(script string, comp *types.ComponentDef) {
+	for _, m := range svelteExportLet.FindAllStringSubmatch(script, -1) {
+		comp.Props = append(comp.Props, types.PropertyDef{Name: m[1], Type: strings.TrimSpace(m[2])})
+	}
+
+	for _, loc := range svelteRunesRe.FindAllStringIndex(script, -1) {
+		open := loc[1] - 1
+		end := closingBracket(script, open)
+		if end < 0 {
+			// let { left open while the file is being edited
+			continue
+		}
+		rest := script[end+1:]
+		typeBody := ""
+		if tail := svelteRunesTail.FindStringSubmatch(rest); tail != nil {
+			typeBody = namedTypeBody(script, tail[1])
+		} else {
+			// An inline type: let { a }: { a: string } = $props()
+			rest = strings.TrimSpace(rest)
+			if !strings.HasPrefix(rest, ":") {
+				continue
+			}
+			rest = strings.TrimSpace(rest[1:])
+			if !strings.HasPrefix(rest, "{") || !svelteRunesAssign.MatchString(rest[bracketEnd(rest, 0)+1:]) {
+				continue
+			}
+			typeBody = braceBody(rest, 0)
+		}
+		comp.Props = append(comp.Props, svelteProps(script[open+1:end], typeBody)...)
+	}
+}
+
+// svelteProps lists the names destructured from $props(), typed from the
+// members of their type when it is known
+func svelteProps(destructured, typeBody string) []types.PropertyDef {
+	typesByName := make(map[string]string)
+	for _, item := range splitTypeMembers(typeBody) {
+		if m := typeMemberRe.FindStringSubmatch(strings.TrimSpace(item)); m != nil {
+			typesByName[m[1]] = oneLine(strings.TrimSpace(m[2]))
+		}
+	}
+	var props []types.PropertyDef
+	for _, item := range splitEnumBody(destructured, ",") {
+		// ...rest collects the props not named
+		if name := memberName(item); name != "" {
+			props = append(props, types.PropertyDef{Name: name, Type: typesByName[name]})
+		}
+	}
+	return props
+}
+
+// namedTypeBody returns the members of an interface or object type alias
+// declared in the script, or "" when there is none
+func namedTypeBody(script, name string) string {
+	if !isGoTypeIdent(name) {
+		return ""
+	}
+	re := regexp.MustCompile(`\b(?:interface\s+` + name + `\b[^{]*|type\s+` + name + `\s*=\s*)\{`)
+	loc := re.FindStringIndex(script)
+	if loc == nil {
+		return ""
+	}
+	return braceBody(script, loc[1]-1)
+}
+
+// braceBody returns what is between the brace at s[open] and the one that
+// closes it, without comments
+func braceBody(s string, open int) string {
+	return enumBody(strings.Split(s[open:], "\n"), 0)
+}
+
+// objectEntry splits an object literal entry into its key and value
+func objectEntry(item string) (key, value string) {
+	m := objectEntryRe.FindStringSubmatch(item)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], strings.TrimSpace(m[2])
+}
+
+// splitTypeMembers splits the body of a TypeScript type literal on ; , and
+// newlines outside brackets, type arguments included
+func splitTypeMembers(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '(' || c == '[' || c == '{' || c == '<':
+			depth++
+		case c == ')' || c == ']' || c == '}' || (c == '>' && (i == 0 || body[i-1] != '=')):
+			depth--
+		case c == '"' || c == '\'' || c == '`':
+			if end := quoteEnd(body, i); end > i {
+				i = end
+			}
+		case depth == 0 && (c == ';' || c == ',' || c == '\n'):
+			parts = append(parts, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, body[start:])
+}
+
+// angleEnd returns the index of the > closing the type argument list
+// opened at s[open]; arrows (=>) inside it don't close it
+func angleEnd(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '<', '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '>':
+			if i > 0 && s[i-1] == '=' {
+				continue
+			}
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
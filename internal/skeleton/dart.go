@@ -0,0 +1,396 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Dart patterns. Class headers are matched on the header joined into one
+// line, since dart format wraps long extends/with clauses.
+var (
+	dartClassStart = regexp.MustCompile(`^\s*(?:(?:abstract|base|final|interface|sealed|mixin)\s+)*class\s+\w+`)
+	dartClass      = regexp.MustCompile(`^\s*((?:(?:abstract|base|final|interface|sealed|mixin)\s+)*)class\s+(\w+)(?:<.*?>)?(?:\s+extends\s+([\w.]+(?:<.*?>)?))?(?:\s+with\s+(.+?))?(?:\s+implements\s+(.+?))?\s*\{`)
+	dartMixin      = regexp.MustCompile(`^\s*(?:base\s+)?mixin\s+(\w+)(?:<[^{]*?>)?(?:\s+on\s+([^{]+?))?(?:\s+implements\s+[^{]+?)?\s*\{`)
+	dartExtension  = regexp.MustCompile(`^\s*extension\s+(?:type\s+)?(\w+)(?:<[^{]*?>)?\s+on\s+([^{]+?)\s*\{`)
+	dartEnum       = regexp.MustCompile(`^\s*enum\s+(\w+)`)
+	dartTypedef    = regexp.MustCompile(`^\s*typedef\s+(\w+)(?:<[^>]*>)?\s*=\s*(.+?);`)
+	dartAnnotation = regexp.MustCompile(`^\s*@(\w+)(?:\(.*\))?\s*$`)
+
+	// Functions, methods and getters: modifiers, return type, name and the
+	// opening parenthesis; parameters may continue on the next lines
+	dartFunction = regexp.MustCompile(`^\s*((?:(?:static|external)\s+)*)(?:([\w.]+(?:<.*>)?\??)\s+)?(?:(operator)\s*([^\s(]+)|(\w+))\s*(?:<[^(]*>)?\s*\(`)
+	dartGetter   = regexp.MustCompile(`^\s*((?:static\s+)*)(?:([\w.]+(?:<.*>)?\??)\s+)?get\s+(\w+)\s*(?:=>|\{|async)`)
+
+	// Fields and top-level variables: int count = 0; final String title;
+	dartField = regexp.MustCompile(`^\s*((?:(?:static|final|late|const|var|covariant|external)\s+)*)(?:([\w.]+(?:<.*>)?\??)\s+)?(\w+)\s*(?:=\s*(.+?))?;\s*(?://.*)?$`)
+)
+
+// dartKeywords start statements that look like calls: if (...) {
+var dartKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"return": true, "assert": true, "super": true, "this": true, "await": true,
+	"throw": true, "new": true, "else": true, "do": true, "yield": true,
+}
+
+// dartWidgetBases are the classes a Flutter widget extends
+var dartWidgetBases = []string{"StatelessWidget", "StatefulWidget", "State", "InheritedWidget", "ConsumerWidget", "ConsumerStatefulWidget", "HookWidget"}
+
+// parseDart extracts classes with their fields, constructors and methods,
+// mixins, extensions, enums, typedefs, functions and top-level constants.
+// Names starting with _ are library-private in Dart.
+func parseDart(content string, skeleton *types.CodeSkeleton) {
+	lines := strings.Split(content, "\n")
+
+	var current *types.ClassSkeleton
+	bodyDepth := 0 // brace depth of the current class body
+	depth := 0
+	var annotations []string
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "/*") {
+			continue
+		}
+
+		member := current != nil && depth == bodyDepth
+		if depth != 0 && !member {
+			depth += braceDelta(line)
+			if current != nil && depth < bodyDepth {
+				current = nil
+			}
+			continue
+		}
+
+		if m := dartAnnotation.FindStringSubmatch(line); m != nil {
+			annotations = append(annotations, "@"+m[1])
+			continue
+		}
+
+		if depth == 0 {
+			if dartClassStart.MatchString(line) {
+				// Join a wrapped header up to its opening brace
+				header, end := line, i
+				for !strings.Contains(header, "{") && !strings.Contains(header, ";") && end+1 < len(lines) && end < i+5 {
+					end++
+					header += " " + strings.TrimSpace(lines[end])
+				}
+				if m := dartClass.FindStringSubmatch(header); m != nil {
+					cls := types.ClassSkeleton{
+						Name:       m[2],
+						Line:       lineNo,
+						Extends:    strings.TrimSpace(m[3]),
+						IsAbstract: strings.Contains(m[1], "abstract") || strings.Contains(m[1], "sealed"),
+						IsExported: !strings.HasPrefix(m[2], "_"),
+					}
+					for _, clause := range []string{m[4], m[5]} {
+						cls.Implements = append(cls.Implements, splitTopLevel(clause)...)
+					}
+					skeleton.Classes = append(skeleton.Classes, cls)
+					current = &skeleton.Classes[len(skeleton.Classes)-1]
+					bodyDepth = 1
+				}
+				for ; i < end; i++ {
+					depth += braceDelta(lines[i])
+				}
+				depth += braceDelta(lines[end])
+				if depth < bodyDepth {
+					current = nil
+				}
+				annotations = nil
+				continue
+			}
+			if m := dartMixin.FindStringSubmatch(line); m != nil {
+				mixin := types.TypeDef{Name: m[1], Line: lineNo, Kind: "mixin", IsExported: !strings.HasPrefix(m[1], "_")}
+				if m[2] != "" {
+					mixin.Extends = splitTopLevel(m[2])
+				}
+				skeleton.Types = append(skeleton.Types, mixin)
+				depth += braceDelta(line)
+				continue
+			}
+			if m := dartExtension.FindStringSubmatch(line); m != nil {
+				skeleton.Types = append(skeleton.Types, types.TypeDef{
+					Name:       m[1],
+					Line:       lineNo,
+					Kind:       "extension",
+					IsExported: !strings.HasPrefix(m[1], "_"),
+					RawDef:     "on " + strings.TrimSpace(m[2]),
+				})
+				depth += braceDelta(line)
+				continue
+			}
+			if m := dartEnum.FindStringSubmatch(line); m != nil {
+				skeleton.Enums = append(skeleton.Enums, types.EnumDef{
+					Name:       m[1],
+					Line:       lineNo,
+					IsExported: !strings.HasPrefix(m[1], "_"),
+					Members:    enumMembers(enumBody(lines, i)),
+				})
+				depth += braceDelta(line)
+				continue
+			}
+			if m := dartTypedef.FindStringSubmatch(line); m != nil {
+				skeleton.Types = append(skeleton.Types, types.TypeDef{
+					Name:       m[1],
+					Line:       lineNo,
+					Kind:       "typedef",
+					IsExported: !strings.HasPrefix(m[1], "_"),
+					RawDef:     strings.TrimSpace(m[2]),
+				})
+				continue
+			}
+		}
+
+		// Constructors: Name(...), Name.named(...), const/factory Name(...)
+		if member {
+			if ctor, ok := dartConstructor(lines, i, current.Name); ok {
+				if current.Constructor == nil {
+					current.Constructor = &ctor
+				} else {
+					current.Methods = append(current.Methods, ctor)
+				}
+				i = dartSkipDeclaration(lines, i, &depth)
+				annotations = nil
+				continue
+			}
+		}
+
+		if fn, ok := dartFunctionSig(lines, i); ok {
+			fn.Decorators = annotations
+			annotations = nil
+			if member {
+				fn.IsExported = false
+				current.Methods = append(current.Methods, fn)
+			} else {
+				skeleton.Functions = append(skeleton.Functions, fn)
+			}
+			i = dartSkipDeclaration(lines, i, &depth)
+			if current != nil && depth < bodyDepth {
+				current = nil
+			}
+			continue
+		}
+
+		if m := dartField.FindStringSubmatch(line); m != nil && (m[1] != "" || m[2] != "") && !dartKeywords[m[2]] {
+			annotations = nil
+			if member {
+				current.Properties = append(current.Properties, types.PropertyDef{
+					Name:       m[3],
+					Type:       strings.TrimSpace(m[2]),
+					IsPrivate:  strings.HasPrefix(m[3], "_"),
+					IsReadonly: strings.Contains(m[1], "final") || strings.Contains(m[1], "const"),
+					IsStatic:   strings.Contains(m[1], "static"),
+				})
+			} else if strings.Contains(m[1], "const") || strings.Contains(m[1], "final") {
+				skeleton.Constants = append(skeleton.Constants, types.ConstDef{
+					Name:       m[3],
+					Line:       lineNo,
+					Type:       strings.TrimSpace(m[2]),
+					Value:      strings.TrimSpace(m[4]),
+					IsExported: !strings.HasPrefix(m[3], "_"),
+				})
+			}
+			continue
+		}
+
+		annotations = nil
+		depth += braceDelta(line)
+		if current != nil && depth < bodyDepth {
+			current = nil
+		}
+	}
+}
+
+// dartFunctionSig reads the function, method or getter declared on
+// lines[i], with parameters that may span lines
+func dartFunctionSig(lines []string, i int) (types.FunctionSig, bool) {
+	line := lines[i]
+	if m := dartGetter.FindStringSubmatch(line); m != nil {
+		return types.FunctionSig{
+			Name:       m[3],
+			Line:       i + 1,
+			ReturnType: strings.TrimSpace(m[2]),
+			IsStatic:   strings.Contains(m[1], "static"),
+			IsPrivate:  strings.HasPrefix(m[3], "_"),
+			IsExported: !strings.HasPrefix(m[3], "_"),
+			IsAsync:    strings.Contains(line, "async"),
+		}, true
+	}
+
+	m := dartFunction.FindStringSubmatchIndex(line)
+	if m == nil {
+		return types.FunctionSig{}, false
+	}
+	group := func(n int) string {
+		if m[2*n] < 0 {
+			return ""
+		}
+		return line[m[2*n]:m[2*n+1]]
+	}
+	name := group(5)
+	if group(3) != "" {
+		name = "operator " + group(4)
+	}
+	returnType := group(2)
+	if returnType == "set" {
+		returnType = ""
+	}
+	if dartKeywords[name] || dartKeywords[returnType] || name == "" {
+		return types.FunctionSig{}, false
+	}
+
+	params, rest, ok := dartParams(lines, i, m[1]-1)
+	if !ok {
+		return types.FunctionSig{}, false
+	}
+	// A call statement, not a declaration: foo(); or runApp(MyApp());
+	rest = strings.TrimSpace(rest)
+	if returnType == "" && !strings.HasPrefix(rest, "{") && !strings.HasPrefix(rest, "=>") && !strings.HasPrefix(rest, "async") {
+		return types.FunctionSig{}, false
+	}
+	return types.FunctionSig{
+		Name:       name,
+		Line:       i + 1,
+		Params:     parseDartParams(params),
+		ReturnType: returnType,
+		IsAsync:    strings.HasPrefix(rest, "async"),
+		IsStatic:   strings.Contains(group(1), "static"),
+		IsPrivate:  strings.HasPrefix(name, "_"),
+		IsExported: !strings.HasPrefix(name, "_"),
+	}, true
+}
+
+// dartConstructor reads a constructor of class on lines[i]
+func dartConstructor(lines []string, i int, class string) (types.FunctionSig, bool) {
+	re := regexp.MustCompile(`^\s*(?:(?:const|factory|external)\s+)*` + class + `(?:\.(\w+))?\s*\(`)
+	m := re.FindStringSubmatchIndex(lines[i])
+	if m == nil {
+		return types.FunctionSig{}, false
+	}
+	name := class
+	if m[2] >= 0 {
+		name = class + "." + lines[i][m[2]:m[3]]
+	}
+	params, _, ok := dartParams(lines, i, m[1]-1)
+	if !ok {
+		return types.FunctionSig{}, false
+	}
+	return types.FunctionSig{
+		Name:      name,
+		Line:      i + 1,
+		Params:    parseDartParams(params),
+		IsPrivate: strings.Contains(name, "._"),
+	}, true
+}
+
+// dartParams returns the parameter list opened by the parenthesis at
+// lines[i][open], and what follows its closing parenthesis; not ok when
+// the parenthesis is never closed, as in a file saved mid-edit
+func dartParams(lines []string, i, open int) (params, rest string, ok bool) {
+	end := i + 30
+	if end > len(lines) {
+		end = len(lines)
+	}
+	text := strings.Join(lines[i:end], "\n")
+	close := closingBracket(text, open)
+	if close < 0 {
+		return "", "", false
+	}
+	return text[open+1 : close], text[close+1:], true
+}
+
+// dartSkipDeclaration moves past a declaration starting on lines[i],
+// counting its braces into depth, and returns its last line: the one
+// with its closing parenthesis, or with the brace that opens its body
+func dartSkipDeclaration(lines []string, i int, depth *int) int {
+	parens := 0
+	for j := i; j < len(lines) && j < i+30; j++ {
+		parens += strings.Count(lines[j], "(") - strings.Count(lines[j], ")")
+		if parens <= 0 {
+			// Braces in the parameter list ({required this.x}) balance
+			// out; what remains is the body
+			for k := i; k <= j; k++ {
+				*depth += braceDelta(lines[k])
+			}
+			return j
+		}
+	}
+	*depth += braceDelta(lines[i])
+	return i
+}
+
+// parseDartParams parses Dart parameters: positional ones, then optional
+// positional [...] or named {...} ones; this.x and super.x take their type
+// from the field
+func parseDartParams(paramsStr string) []types.ParamDef {
+	required, optional := paramsStr, ""
+	depth := 0
+	for i, c := range paramsStr {
+		switch c {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		case '{', '[':
+			if depth == 0 {
+				// An unclosed group runs to the end of the list
+				end := closingBracket(paramsStr, i)
+				if end < 0 {
+					end = len(paramsStr)
+				}
+				required, optional = paramsStr[:i], paramsStr[i+1:end]
+			}
+		}
+		if optional != "" {
+			break
+		}
+	}
+
+	var params []types.ParamDef
+	add := func(item string, isOptional bool) {
+		item = strings.TrimSpace(dartAnnotationPrefix.ReplaceAllString(item, ""))
+		if strings.HasPrefix(item, "required ") {
+			item = strings.TrimSpace(item[len("required "):])
+			isOptional = false
+		}
+		p := types.ParamDef{Optional: isOptional}
+		if eq := strings.Index(item, "="); eq >= 0 {
+			p.Default = strings.TrimSpace(item[eq+1:])
+			item = strings.TrimSpace(item[:eq])
+		}
+		sep := strings.LastIndexAny(item, " \t\n")
+		p.Name, p.Type = item[sep+1:], strings.TrimSpace(item[:sep+1])
+		p.Name = strings.TrimPrefix(strings.TrimPrefix(p.Name, "this."), "super.")
+		if p.Name != "" {
+			params = append(params, p)
+		}
+	}
+	for _, item := range splitTopLevel(required) {
+		add(item, false)
+	}
+	for _, item := range splitTopLevel(optional) {
+		add(item, true)
+	}
+	return params
+}
+
+var dartAnnotationPrefix = regexp.MustCompile(`@\w+(?:\([^)]*\))?\s*`)
+
+// IsDartWidget reports whether a Dart class is a Flutter widget or the
+// state of one, from the class it extends
+func IsDartWidget(cls types.ClassSkeleton) bool {
+	base := cls.Extends
+	if i := strings.Index(base, "<"); i >= 0 {
+		base = base[:i]
+	}
+	for _, w := range dartWidgetBases {
+		if base == w {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package skeleton
+
+import (
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// maxDecoratorArg caps an argument kept in a skeleton; a @Module({...})
+// object is summarized, the blueprint quotes it whole when it matters
+const maxDecoratorArg = 160
+
+// maxDecoratorLines caps how far a decorator's argument list is followed
+// across lines before it is taken as unbalanced
+const maxDecoratorLines = 60
+
+// decoratorList collects the decorators of a declaration: their names, as
+// skeletons list them, and the arguments of those called with any
+type decoratorList struct {
+	names []string
+	args  map[string][]string
+}
+
+// add records a decorator as written, e.g. "@Get(':id')"
+func (d *decoratorList) add(text string) {
+	name, args := parseDecorator(text)
+	if name == "" {
+		return
+	}
+	d.names = append(d.names, "@"+name)
+	if len(args) == 0 {
+		return
+	}
+	if d.args == nil {
+		d.args = make(map[string][]string)
+	}
+	// A repeated decorator (@ApiResponse twice) keeps its first arguments
+	if _, ok := d.args["@"+name]; !ok {
+		d.args["@"+name] = args
+	}
+}
+
+// parseDecorator splits a decorator as written into its name, cut at the
+// first dot or paren like the skeleton names it, and its top-level
+// arguments as source text: "@Get(':id')" is Get and ["':id'"],
+// "@UseGuards(AuthGuard('jwt'), RolesGuard)" is UseGuards and
+// ["AuthGuard('jwt')", "RolesGuard"].
+func parseDecorator(text string) (string, []string) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "@")
+	name := text
+	if i := strings.IndexAny(name, "(. \t\r\n"); i >= 0 {
+		name = name[:i]
+	}
+	open := strings.Index(text, "(")
+	if open < 0 {
+		return name, nil
+	}
+	end := balancedEnd(text[open:])
+	if end < 0 {
+		return name, nil
+	}
+	return name, splitArgs(text[open+1 : open+end-1])
+}
+
+// balancedEnd returns the index just past the paren closing the one s
+// starts with, skipping string and template literals, or -1 when s ends
+// first
+func balancedEnd(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// splitArgs splits an argument list on its top-level commas, collapsing
+// whitespace and shortening long arguments
+func splitArgs(list string) []string {
+	var args []string
+	depth, start := 0, 0
+	var quote byte
+	flush := func(end int) {
+		arg := oneLine(list[start:end])
+		if r := []rune(arg); len(r) > maxDecoratorArg {
+			arg = string(r[:maxDecoratorArg-1]) + "…"
+		}
+		if arg != "" {
+			args = append(args, arg)
+		}
+	}
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	flush(len(list))
+	return args
+}
+
+// decoratorText gathers a decorator starting at column col of lines[i]
+// with its whole argument list, which may run over several lines. It
+// returns the text and the index of the line it ends on.
+func decoratorText(lines []string, i, col int) (string, int) {
+	text := lines[i][col:]
+	open := strings.Index(text, "(")
+	for j := i; ; j++ {
+		if open < 0 || balancedEnd(text[open:]) >= 0 {
+			if open >= 0 {
+				text = text[:open+balancedEnd(text[open:])]
+			}
+			return text, j
+		}
+		if j+1 >= len(lines) || j+1-i > maxDecoratorLines {
+			// Unbalanced: keep the name, let the lines be parsed as code
+			return text, i
+		}
+		text += "\n" + lines[j+1]
+	}
+}
+
+// DecoratorCall renders a decorator of fn or a class as it was called:
+// @Get(':id'), or the bare name when it took no arguments
+func DecoratorCall(decorator string, args map[string][]string) string {
+	if a, ok := args[decorator]; ok {
+		return decorator + "(" + strings.Join(a, ", ") + ")"
+	}
+	return decorator
+}
+
+// DecoratorCalls renders decorators with their arguments
+func DecoratorCalls(decorators []string, args map[string][]string) []string {
+	calls := make([]string, len(decorators))
+	for i, d := range decorators {
+		calls[i] = DecoratorCall(d, args)
+	}
+	return calls
+}
+
+// StringArg returns the value of a decorator argument that is a plain
+// string literal: "':id'" is ":id". Template literals with substitutions,
+// identifiers and objects are not.
+func StringArg(arg string) (string, bool) {
+	if len(arg) < 2 {
+		return "", false
+	}
+	q := arg[0]
+	if (q != '\'' && q != '"' && q != '`') || arg[len(arg)-1] != q {
+		return "", false
+	}
+	inner := arg[1 : len(arg)-1]
+	if strings.ContainsRune(inner, rune(q)) || (q == '`' && strings.Contains(inner, "${")) {
+		return "", false
+	}
+	return inner, true
+}
+
+// hasArgDecorators reports whether a function has decorators with
+// arguments worth printing in a skeleton
+func hasArgDecorators(fn types.FunctionSig) bool {
+	return len(fn.DecoratorArgs) > 0
+}
+
+// stripParamDecorators drops the decorators in front of a parameter:
+// "@Param('id', ParseIntPipe) id: number" is "id: number"
+func stripParamDecorators(p string) string {
+	for strings.HasPrefix(p, "@") {
+		end := 1
+		for end < len(p) && (p[end] == '_' || p[end] == '.' || p[end] == '$' || isAlnum(p[end])) {
+			end++
+		}
+		rest := strings.TrimLeft(p[end:], " \t")
+		if strings.HasPrefix(rest, "(") {
+			n := balancedEnd(rest)
+			if n < 0 {
+				return p
+			}
+			rest = rest[n:]
+		}
+		p = strings.TrimSpace(rest)
+	}
+	return p
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,105 @@
+package skeleton
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const usersController = `import { Controller, Get, Post, UseGuards } from '@nestjs/common';
+
+@Controller('users')
+@UseGuards(AuthGuard('jwt'), RolesGuard)
+export class UsersController {
+  constructor(@InjectRepository(User) private readonly repo: Repository<User>) {}
+
+  @Get(':id')
+  @ApiResponse({ status: 200, description: 'The user, if any' })
+  findOne(@Param('id', ParseIntPipe) id: number, @Query() query: QueryDto): Promise<User> {
+    return this.repo.findOneBy({ id });
+  }
+
+  @Post()
+  create(@Body() dto: CreateUserDto) {
+    return this.repo.save(dto);
+  }
+}
+
+@Module({
+  imports: [TypeOrmModule.forFeature([User])],
+  controllers: [UsersController],
+})
+export class UsersModule {}
+`
+
+func TestTypeScriptDecoratorArgs(t *testing.T) {
+	sk, err := ParseContent("users.controller.ts", []byte(usersController))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sk.Classes) != 2 {
+		t.Fatalf("classes = %+v", sk.Classes)
+	}
+
+	ctrl := sk.Classes[0]
+	if !reflect.DeepEqual(ctrl.Decorators, []string{"@Controller", "@UseGuards"}) {
+		t.Errorf("class decorators = %v", ctrl.Decorators)
+	}
+	if got := ctrl.DecoratorArgs["@UseGuards"]; !reflect.DeepEqual(got, []string{"AuthGuard('jwt')", "RolesGuard"}) {
+		t.Errorf("@UseGuards args = %q", got)
+	}
+	methods := make(map[string]int)
+	for i, m := range ctrl.Methods {
+		methods[m.Name] = i
+	}
+	for _, name := range []string{"findOne", "create"} {
+		if _, ok := methods[name]; !ok {
+			t.Fatalf("method %s missing from %+v", name, ctrl.Methods)
+		}
+	}
+	findOne := ctrl.Methods[methods["findOne"]]
+	if got := findOne.DecoratorArgs["@Get"]; !reflect.DeepEqual(got, []string{"':id'"}) {
+		t.Errorf("@Get args = %q", got)
+	}
+	if len(findOne.Params) != 2 || findOne.Params[0].Name != "id" || findOne.Params[0].Type != "number" || findOne.Params[1].Name != "query" {
+		t.Errorf("findOne params = %+v", findOne.Params)
+	}
+	if create := ctrl.Methods[methods["create"]]; len(create.Params) != 1 || create.Params[0].Name != "dto" {
+		t.Errorf("create params = %+v", create.Params)
+	}
+
+	mod := sk.Classes[1]
+	if mod.Name != "UsersModule" || !reflect.DeepEqual(mod.DecoratorArgs["@Module"], []string{"{ imports: [TypeOrmModule.forFeature([User])], controllers: [UsersController], }"}) {
+		t.Errorf("module = %s %q", mod.Name, mod.DecoratorArgs)
+	}
+
+	out := FormatSkeleton(sk)
+	for _, want := range []string{
+		"@Controller('users')\n",
+		"@UseGuards(AuthGuard('jwt'), RolesGuard)\n",
+		"@Get(':id')",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("skeleton missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestStringArg(t *testing.T) {
+	for _, tc := range []struct {
+		arg, want string
+		ok        bool
+	}{
+		{"':id'", ":id", true},
+		{`"users"`, "users", true},
+		{"`v1/users`", "v1/users", true},
+		{"`${PREFIX}/users`", "", false},
+		{"ROUTES.USERS", "", false},
+		{"{ path: 'users' }", "", false},
+	} {
+		got, ok := StringArg(tc.arg)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("StringArg(%s) = %q, %v", tc.arg, got, ok)
+		}
+	}
+}
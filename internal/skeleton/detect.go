@@ -0,0 +1,216 @@
+package skeleton
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Language detection by name and content
+// Scripts in bin/, Dockerfile variants and Makefile includes often have no
+// extension, or one that says nothing about the language (Dockerfile.dev,
+// Makefile.common). Their language comes from the file name, the shebang,
+// an editor modeline or the first lines of content.
+
+// detectHeadBytes is how much of a file is read to detect its language
+const detectHeadBytes = 1024
+
+// fileNameLanguages maps well-known file names, lowercased, to languages
+var fileNameLanguages = map[string]string{
+	"dockerfile":    "dockerfile",
+	"containerfile": "dockerfile",
+	"makefile":      "makefile",
+	"gnumakefile":   "makefile",
+	"rakefile":      "ruby",
+	"gemfile":       "ruby",
+	"podfile":       "ruby",
+	"vagrantfile":   "ruby",
+	"brewfile":      "ruby",
+	"guardfile":     "ruby",
+	"capfile":       "ruby",
+	"fastfile":      "ruby",
+	"jenkinsfile":   "groovy",
+	"build":         "starlark",
+	"build.bazel":   "starlark",
+	"workspace":     "starlark",
+	"module.bazel":  "starlark",
+	".bashrc":       "shell",
+	".bash_profile": "shell",
+	".zshrc":        "shell",
+	".profile":      "shell",
+	".envrc":        "shell",
+}
+
+// fileNamePrefixes catch variants: Dockerfile.dev, Makefile.common
+var fileNamePrefixes = map[string]string{
+	"dockerfile.":    "dockerfile",
+	"dockerfile-":    "dockerfile",
+	"containerfile.": "dockerfile",
+	"makefile.":      "makefile",
+	"jenkinsfile.":   "groovy",
+}
+
+// fileNameSuffixes catch extensions only these tools use
+var fileNameSuffixes = map[string]string{
+	".dockerfile": "dockerfile",
+	".mk":         "makefile",
+	".mak":        "makefile",
+	".bzl":        "starlark",
+	".gradle":     "groovy",
+}
+
+// interpreterLanguages maps shebang interpreters, without version
+// suffixes, to languages
+var interpreterLanguages = map[string]string{
+	"sh": "shell", "bash": "shell", "zsh": "shell", "dash": "shell", "ksh": "shell", "ash": "shell", "fish": "shell",
+	"python": "python", "pypy": "python",
+	"node": "javascript", "nodejs": "javascript",
+	"deno": "typescript", "bun": "typescript", "ts-node": "typescript", "tsx": "typescript",
+	"ruby": "ruby", "perl": "perl", "php": "php", "lua": "lua",
+	"rscript": "r", "pwsh": "powershell", "make": "makefile",
+	"awk": "awk", "gawk": "awk",
+}
+
+// modelineLanguages maps editor mode names to languages
+var modelineLanguages = map[string]string{
+	"sh": "shell", "bash": "shell", "zsh": "shell", "shell-script": "shell",
+	"python": "python", "ruby": "ruby", "perl": "perl", "php": "php",
+	"javascript": "javascript", "js": "javascript", "typescript": "typescript",
+	"make": "makefile", "makefile": "makefile", "dockerfile": "dockerfile",
+	"groovy": "groovy", "lua": "lua", "yaml": "yaml", "toml": "toml",
+}
+
+var (
+	// -*- mode: python -*- or -*- python -*-
+	emacsModeline = regexp.MustCompile(`-\*-\s*(?:.*mode:\s*)?([\w-]+)\s*(?:;.*)?-\*-`)
+	// vim: set ft=sh: or vi: filetype=python
+	vimModeline = regexp.MustCompile(`\bvim?:.*\b(?:ft|filetype)=([\w-]+)`)
+	// target: deps, followed by a tab-indented recipe
+	makeRule = regexp.MustCompile(`(?m)^[\w./%$(){}-]+(?:\s+[\w./%$(){}-]+)*\s*::?(?:[^=]|$).*\n\t\S`)
+	// VAR := value, VAR ?= value
+	makeAssign = regexp.MustCompile(`(?m)^[A-Z_][A-Z0-9_]*\s*(?::=|\?=|\+=)`)
+	// FROM image, as the first instruction of a Dockerfile
+	dockerFrom = regexp.MustCompile(`^FROM\s+\S`)
+)
+
+// DetectLanguage returns the language of a file its extension does not
+// identify, from its name, shebang, modeline or content; "" when unknown.
+// head is the start of the file; binary content is never detected.
+func DetectLanguage(path string, head []byte) string {
+	if len(head) > detectHeadBytes {
+		head = head[:detectHeadBytes]
+	}
+	binary := bytes.IndexByte(head, 0) >= 0
+	lines := strings.Split(string(head), "\n")
+
+	// A shebang beats the name: scripts/build is a shell script, not Bazel
+	if !binary {
+		if lang := shebangLanguage(lines[0]); lang != "" {
+			return lang
+		}
+	}
+
+	name := strings.ToLower(filepath.Base(filepath.ToSlash(path)))
+	if lang, ok := fileNameLanguages[name]; ok {
+		return lang
+	}
+	for prefix, lang := range fileNamePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return lang
+		}
+	}
+	for suffix, lang := range fileNameSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return lang
+		}
+	}
+
+	if len(head) == 0 || binary {
+		return ""
+	}
+	for i, line := range lines {
+		if i >= 5 {
+			break
+		}
+		if m := emacsModeline.FindStringSubmatch(line); m != nil {
+			if lang, ok := modelineLanguages[strings.ToLower(m[1])]; ok {
+				return lang
+			}
+		}
+	}
+	for _, line := range lines[max(0, len(lines)-5):] {
+		if m := vimModeline.FindStringSubmatch(line); m != nil {
+			if lang, ok := modelineLanguages[strings.ToLower(m[1])]; ok {
+				return lang
+			}
+		}
+	}
+
+	text := string(head)
+	switch {
+	case strings.HasPrefix(text, "<?php"):
+		return "php"
+	case dockerFrom.MatchString(firstCodeLine(lines)):
+		return "dockerfile"
+	case strings.Contains(text, ".PHONY:") || makeRule.MatchString(text) || makeAssign.MatchString(text):
+		return "makefile"
+	}
+	return ""
+}
+
+// DetectFileLanguage reads the start of a file and detects its language
+// with DetectLanguage. Only files without an extension are read; for the
+// rest the name alone decides.
+func DetectFileLanguage(path string) string {
+	base := filepath.Base(path)
+	if ext := filepath.Ext(base); ext != "" && ext != base {
+		return DetectLanguage(path, nil)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	head := make([]byte, detectHeadBytes)
+	n, _ := f.Read(head)
+	return DetectLanguage(path, head[:n])
+}
+
+// shebangLanguage returns the language of the interpreter named by a
+// "#!" line, looking through env and its flags
+func shebangLanguage(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+				continue
+			}
+			interpreter = filepath.Base(f)
+			break
+		}
+	}
+	// python3.11 -> python
+	interpreter = strings.ToLower(strings.TrimRight(interpreter, "0123456789."))
+	return interpreterLanguages[interpreter]
+}
+
+// firstCodeLine returns the first line that is neither blank nor a comment
+func firstCodeLine(lines []string) string {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return trimmed
+		}
+	}
+	return ""
+}
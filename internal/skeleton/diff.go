@@ -0,0 +1,241 @@
+package skeleton
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// DiffReport is what changed structurally between two skeletons of a file
+type DiffReport struct {
+	Added   []SymbolChange `json:"added,omitempty"`
+	Removed []SymbolChange `json:"removed,omitempty"`
+	Changed []SymbolChange `json:"changed,omitempty"`
+}
+
+// SymbolChange is a function, class, member or type that was added,
+// removed or changed. Members are named Class.member.
+type SymbolChange struct {
+	Kind         string `json:"kind"` // function, class, method, constructor, property, interface, type, enum, constant
+	Name         string `json:"name"`
+	Signature    string `json:"signature"`               // the new signature, or the removed one
+	OldSignature string `json:"old_signature,omitempty"` // changed symbols
+	Line         int    `json:"line,omitempty"`          // in the new file, or the old one for removals
+}
+
+// Empty reports whether nothing changed
+func (d *DiffReport) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// diffSymbol is a symbol keyed for comparison. Moving a symbol changes its
+// line but not its signature, so it is not reported.
+type diffSymbol struct {
+	kind, name, signature string
+	line                  int
+}
+
+// Diff compares two skeletons of a file. A nil skeleton is a file that
+// doesn't exist on that side: everything in the other is added or removed.
+// Overloads are matched by signature; when exactly one overload of a name
+// disappears and one appears, it is reported as changed.
+func Diff(old, new *types.CodeSkeleton) *DiffReport {
+	before := groupSymbols(skeletonSymbols(old))
+	after := groupSymbols(skeletonSymbols(new))
+
+	report := &DiffReport{}
+	for key, olds := range before {
+		news := after[key]
+		removed, added := unmatched(olds, news), unmatched(news, olds)
+		if len(removed) == 1 && len(added) == 1 {
+			report.Changed = append(report.Changed, SymbolChange{
+				Kind: added[0].kind, Name: added[0].name, Signature: added[0].signature,
+				OldSignature: removed[0].signature, Line: added[0].line,
+			})
+			continue
+		}
+		for _, s := range removed {
+			report.Removed = append(report.Removed, s.change())
+		}
+		for _, s := range added {
+			report.Added = append(report.Added, s.change())
+		}
+	}
+	for key, news := range after {
+		if _, ok := before[key]; ok {
+			continue
+		}
+		for _, s := range news {
+			report.Added = append(report.Added, s.change())
+		}
+	}
+	for _, list := range [][]SymbolChange{report.Added, report.Removed, report.Changed} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Line != list[j].Line {
+				return list[i].Line < list[j].Line
+			}
+			return list[i].Name < list[j].Name
+		})
+	}
+	return report
+}
+
+func (s diffSymbol) change() SymbolChange {
+	return SymbolChange{Kind: s.kind, Name: s.name, Signature: s.signature, Line: s.line}
+}
+
+// groupSymbols keys symbols by kind and name, overloads together
+func groupSymbols(symbols []diffSymbol) map[string][]diffSymbol {
+	groups := make(map[string][]diffSymbol)
+	for _, s := range symbols {
+		key := s.kind + "\x00" + s.name
+		groups[key] = append(groups[key], s)
+	}
+	return groups
+}
+
+// unmatched returns the symbols of a whose signature b lacks, counting
+// duplicates
+func unmatched(a, b []diffSymbol) []diffSymbol {
+	left := make(map[string]int)
+	for _, s := range b {
+		left[s.signature]++
+	}
+	var out []diffSymbol
+	for _, s := range a {
+		if left[s.signature] > 0 {
+			left[s.signature]--
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// skeletonSymbols flattens a skeleton into comparable symbols
+func skeletonSymbols(sk *types.CodeSkeleton) []diffSymbol {
+	if sk == nil {
+		return nil
+	}
+	var symbols []diffSymbol
+	for _, fn := range sk.Functions {
+		symbols = append(symbols, diffSymbol{"function", fn.Name, diffSignature(fn), fn.Line})
+	}
+	for _, cls := range sk.Classes {
+		symbols = append(symbols, diffSymbol{"class", cls.Name, diffClassSignature(cls), cls.Line})
+		if cls.Constructor != nil {
+			symbols = append(symbols, diffSymbol{"constructor", cls.Name + ".constructor", diffSignature(*cls.Constructor), cls.Constructor.Line})
+		}
+		for _, m := range cls.Methods {
+			symbols = append(symbols, diffSymbol{"method", cls.Name + "." + m.Name, diffSignature(m), m.Line})
+		}
+		for _, p := range cls.Properties {
+			symbols = append(symbols, diffSymbol{"property", cls.Name + "." + p.Name, propertySignature(p), cls.Line})
+		}
+	}
+	for _, t := range sk.Interfaces {
+		symbols = append(symbols, diffSymbol{"interface", t.Name, typeSignature("interface", t), t.Line})
+	}
+	for _, t := range sk.Types {
+		symbols = append(symbols, diffSymbol{"type", t.Name, typeSignature("type", t), t.Line})
+	}
+	for _, e := range sk.Enums {
+		sig := "enum " + e.Name + " { " + strings.Join(e.Members, ", ") + " }"
+		if e.IsExported {
+			sig = "export " + sig
+		}
+		symbols = append(symbols, diffSymbol{"enum", e.Name, sig, e.Line})
+	}
+	for _, c := range sk.Constants {
+		sig := "const " + c.Name
+		if c.Type != "" {
+			sig += ": " + c.Type
+		}
+		if c.Value != "" {
+			sig += " = " + c.Value
+		}
+		if c.IsExported {
+			sig = "export " + sig
+		}
+		symbols = append(symbols, diffSymbol{"constant", c.Name, sig, c.Line})
+	}
+	return symbols
+}
+
+// diffSignature is a function's signature with everything callers depend
+// on: visibility, modifiers, decorators, parameters and return type
+func diffSignature(fn types.FunctionSig) string {
+	sig := markdownSignature(fn)
+	if fn.IsExported {
+		sig = "export " + sig
+	}
+	if len(fn.Decorators) > 0 {
+		sig = strings.Join(DecoratorCalls(fn.Decorators, fn.DecoratorArgs), " ") + " " + sig
+	}
+	return sig
+}
+
+// diffClassSignature is a class's signature with its decorators, so a
+// moved route prefix (@Controller('users')) shows as a change
+func diffClassSignature(cls types.ClassSkeleton) string {
+	sig := classSignature(cls)
+	if len(cls.Decorators) > 0 {
+		sig = strings.Join(DecoratorCalls(cls.Decorators, cls.DecoratorArgs), " ") + " " + sig
+	}
+	return sig
+}
+
+func classSignature(cls types.ClassSkeleton) string {
+	sig := "class " + cls.Name
+	if cls.IsAbstract {
+		sig = "abstract " + sig
+	}
+	if cls.IsExported {
+		sig = "export " + sig
+	}
+	if cls.Extends != "" {
+		sig += " extends " + cls.Extends
+	}
+	if len(cls.Implements) > 0 {
+		sig += " implements " + strings.Join(cls.Implements, ", ")
+	}
+	return sig
+}
+
+func propertySignature(p types.PropertyDef) string {
+	sig := fieldSignature(p)
+	if p.IsStatic {
+		sig = "static " + sig
+	}
+	if p.IsReadonly {
+		sig = "readonly " + sig
+	}
+	if p.IsPrivate {
+		sig = "private " + sig
+	}
+	return sig
+}
+
+// typeSignature renders an interface or type with its fields, so a field
+// added to a struct shows as a change of the struct
+func typeSignature(kind string, t types.TypeDef) string {
+	sig := kind + " " + t.Name
+	if t.IsExported {
+		sig = "export " + sig
+	}
+	if len(t.Extends) > 0 {
+		sig += " extends " + strings.Join(t.Extends, ", ")
+	}
+	if len(t.Properties) > 0 {
+		fields := make([]string, len(t.Properties))
+		for i, p := range t.Properties {
+			fields[i] = fieldSignature(p)
+		}
+		return sig + " { " + strings.Join(fields, "; ") + " }"
+	}
+	if t.RawDef != "" {
+		sig += " = " + t.RawDef
+	}
+	return sig
+}
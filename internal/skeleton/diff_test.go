@@ -0,0 +1,104 @@
+package skeleton
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old, err := ParseContent("billing/service.ts", []byte(`export interface Invoice {
+  id: string;
+  total: number;
+}
+
+export class BillingService {
+  charge(invoice: Invoice): Promise<void> {}
+  refund(id: string): void {}
+}
+
+export function formatTotal(total: number): string {}
+function legacyRound(n: number): number {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := ParseContent("billing/service.ts", []byte(`export interface Invoice extends Auditable {
+  id: string;
+  total: number;
+}
+
+export class BillingService {
+  charge(invoice: Invoice, idempotencyKey: string): Promise<void> {}
+  refund(id: string): void {}
+  void(id: string): void {}
+}
+
+// moved down, otherwise unchanged
+export function formatTotal(total: number): string {}
+
+export class RefundPolicy {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := Diff(old, new)
+	names := func(changes []SymbolChange) []string {
+		var out []string
+		for _, c := range changes {
+			out = append(out, c.Kind+" "+c.Name)
+		}
+		return out
+	}
+	if got, want := names(d.Added), []string{"method BillingService.void", "class RefundPolicy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("added = %v, want %v", got, want)
+	}
+	if got, want := names(d.Removed), []string{"function legacyRound"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("removed = %v, want %v", got, want)
+	}
+	if got, want := names(d.Changed), []string{"interface Invoice", "method BillingService.charge"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("changed = %v, want %v", got, want)
+	}
+	charge := d.Changed[1]
+	if charge.OldSignature != "charge(invoice: Invoice): Promise<void>" || charge.Signature != "charge(invoice: Invoice, idempotencyKey: string): Promise<void>" {
+		t.Errorf("charge = %+v", charge)
+	}
+
+	// A new file is all additions, a deleted one all removals
+	if d := Diff(nil, old); len(d.Added) == 0 || len(d.Removed) != 0 || len(d.Changed) != 0 {
+		t.Errorf("diff from nothing = %+v", d)
+	}
+	if d := Diff(old, nil); len(d.Removed) == 0 || len(d.Added) != 0 {
+		t.Errorf("diff to nothing = %+v", d)
+	}
+	if d := Diff(old, old); !d.Empty() {
+		t.Errorf("diff with itself = %+v", d)
+	}
+}
+
+func TestDiffStructFields(t *testing.T) {
+	old, _ := ParseContent("user.go", []byte("package user\n\ntype User struct {\n\tID string `json:\"id\"`\n}\n"))
+	new, _ := ParseContent("user.go", []byte("package user\n\ntype User struct {\n\tID    string `json:\"id\"`\n\tEmail string `json:\"email\"`\n}\n"))
+	d := Diff(old, new)
+	if len(d.Changed) != 1 || d.Changed[0].Name != "User" || d.Changed[0].Signature != "export type User { ID: string `json:\"id\"`; Email: string `json:\"email\"` }" {
+		t.Errorf("struct diff = %+v", d)
+	}
+}
+
+func TestDiffOverloads(t *testing.T) {
+	old, _ := ParseContent("Parser.java", []byte(`public class Parser {
+    public Node parse(String s) {}
+    public Node parse(Reader r) {}
+}
+`))
+	new, _ := ParseContent("Parser.java", []byte(`public class Parser {
+    public Node parse(String s) {}
+    public Node parse(Reader r, Options o) {}
+    public Node parse(Path p) {}
+}
+`))
+	d := Diff(old, new)
+	if len(d.Changed) != 0 || len(d.Added) != 2 || len(d.Removed) != 1 {
+		t.Errorf("overloads = %+v", d)
+	}
+}
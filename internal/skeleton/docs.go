@@ -0,0 +1,136 @@
+package skeleton
+
+import (
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Export is a public symbol of a file and whether it has a doc comment
+type Export struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"` // class, function, method, interface, type or enum
+	Line       int    `json:"line"`
+	Documented bool   `json:"documented"`
+}
+
+// Exports lists the public classes, functions, methods, interfaces, types
+// and enums of a parsed file, each marked documented when the parser
+// captured its doc comment or a comment sits right above it (past its
+// decorators and attributes). Python symbols need a docstring. When the
+// language does not mark exports, every symbol not named private counts.
+func Exports(sk *types.CodeSkeleton, content []byte) []Export {
+	lines := strings.Split(string(content), "\n")
+	exportsMarked := marksExports(sk)
+	public := func(name string, exported, private bool) bool {
+		if private || strings.HasPrefix(name, "_") || strings.HasPrefix(name, "#") {
+			return false
+		}
+		if sk.Language == "go" {
+			return isExportedGo(name)
+		}
+		return exported || !exportsMarked
+	}
+
+	var exports []Export
+	add := func(name, kind string, line int, doc string) {
+		exports = append(exports, Export{
+			Name:       name,
+			Kind:       kind,
+			Line:       line,
+			Documented: doc != "" || hasDocComment(lines, line, sk.Language),
+		})
+	}
+	// Go and Rust classes only group methods under their receiver or impl
+	// type, which is counted where it is declared
+	grouping := sk.Language == "go" || sk.Language == "rust"
+	for _, cls := range sk.Classes {
+		if !public(cls.Name, cls.IsExported, false) {
+			continue
+		}
+		if !grouping {
+			add(cls.Name, "class", cls.Line, "")
+		}
+		for _, m := range cls.Methods {
+			// Methods follow their class, except Rust's, which need pub
+			if public(m.Name, m.IsExported || sk.Language != "rust", m.IsPrivate) {
+				add(cls.Name+"."+m.Name, "method", m.Line, m.DocComment)
+			}
+		}
+	}
+	for _, fn := range sk.Functions {
+		if public(fn.Name, fn.IsExported, fn.IsPrivate) {
+			add(fn.Name, "function", fn.Line, fn.DocComment)
+		}
+	}
+	for _, t := range sk.Interfaces {
+		if public(t.Name, t.IsExported, false) {
+			add(t.Name, "interface", t.Line, "")
+		}
+	}
+	for _, t := range sk.Types {
+		if public(t.Name, t.IsExported, false) {
+			add(t.Name, "type", t.Line, "")
+		}
+	}
+	for _, e := range sk.Enums {
+		if public(e.Name, e.IsExported, false) {
+			add(e.Name, "enum", e.Line, "")
+		}
+	}
+	return exports
+}
+
+// hasDocComment reports whether the symbol declared on line (1-based) is
+// documented: a docstring below a Python def or class, otherwise a comment
+// ending right above it
+func hasDocComment(lines []string, line int, language string) bool {
+	if line < 1 || line > len(lines) {
+		return false
+	}
+	if language == "python" {
+		// The docstring is the first statement after the signature
+		i := line - 1
+		for ; i < len(lines) && i < line+20; i++ {
+			if strings.HasSuffix(strings.TrimSpace(stripHashComment(lines[i])), ":") {
+				break
+			}
+		}
+		for i++; i < len(lines); i++ {
+			next := strings.TrimSpace(lines[i])
+			if next == "" {
+				continue
+			}
+			next = strings.TrimLeft(next, "rRuUbB")
+			return strings.HasPrefix(next, `"`) || strings.HasPrefix(next, "'")
+		}
+		return false
+	}
+
+	for i := line - 2; i >= 0; i-- {
+		above := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(above, "@doc"), strings.HasPrefix(above, "@moduledoc"), strings.HasPrefix(above, "@typedoc"):
+			return true
+		case strings.HasPrefix(above, "@"), strings.HasPrefix(above, "#["), strings.HasPrefix(above, "-spec"),
+			strings.HasPrefix(above, "[") && strings.HasSuffix(above, "]"):
+			continue // decorators, annotations, attributes and specs sit between a doc and its symbol
+		case strings.HasPrefix(above, "//"), strings.HasPrefix(above, "/*"), strings.HasPrefix(above, "*"),
+			strings.HasSuffix(above, "*/"), strings.HasPrefix(above, "--"), strings.HasPrefix(above, "%"):
+			return true
+		case strings.HasPrefix(above, "#"):
+			// Ruby, Elixir and shell comments; not C preprocessor lines
+			return !strings.HasPrefix(above, "#include") && !strings.HasPrefix(above, "#define") && !strings.HasPrefix(above, "#if")
+		}
+		return false
+	}
+	return false
+}
+
+// stripHashComment drops a trailing # comment from a line of Python
+func stripHashComment(line string) string {
+	if i := strings.Index(line, "#"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
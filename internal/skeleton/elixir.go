@@ -0,0 +1,330 @@
+package skeleton
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Elixir patterns. mix format indents blocks by two spaces, so a module or
+// function ends at the `end` in its own column.
+var (
+	exModule    = regexp.MustCompile(`^(\s*)(defmodule|defprotocol)\s+([\w.]+)\s+do\s*$`)
+	exImpl      = regexp.MustCompile(`^(\s*)defimpl\s+([\w.]+)\s*,\s*for:\s*([\w.]+)\s+do\s*$`)
+	exFunction  = regexp.MustCompile(`^(\s*)(def|defp|defmacro|defmacrop|defguard|defguardp|defdelegate)\s+([a-z_]\w*[?!]?)\s*(\()?`)
+	exBehaviour = regexp.MustCompile(`^\s*@behaviour\s+([\w.]+)`)
+	exUse       = regexp.MustCompile(`^\s*use\s+([\w.]+)`)
+	exImplAttr  = regexp.MustCompile(`^\s*@impl\s+(\S+)`)
+	exSpec      = regexp.MustCompile(`^\s*@spec\s+([a-z_]\w*[?!]?)\s*\(.*\)\s*::\s*(.+)$`)
+	exCallback  = regexp.MustCompile(`^\s*@(?:callback|macrocallback)\s+([a-z_]\w*[?!]?)\s*(\(.*\)\s*::\s*.+)$`)
+	exDoc       = regexp.MustCompile(`^\s*@doc\s+(?:~[sS])?"(.*)"\s*$`)
+	exDocStart  = regexp.MustCompile(`^\s*@doc\s+(?:~[sS])?"""`)
+	exDoBlock   = regexp.MustCompile(`\bdo\s*$`)
+	exAttribute = regexp.MustCompile(`^\s*@([a-z_]\w*)\s+(.+)$`)
+	exTypeAttr  = regexp.MustCompile(`^\s*@(type|typep|opaque)\s+([a-z_]\w*)(?:\(.*?\))?\s*::\s*(.+)$`)
+	exKeyword   = regexp.MustCompile(`^\s*\w+:\s`)
+	exStruct    = regexp.MustCompile(`(?s)^\s*defstruct\s+(.+)$`)
+	exField     = regexp.MustCompile(`^\s*(field|belongs_to|has_one|has_many|many_to_many|embeds_one|embeds_many)\s+:(\w+)\s*(?:,\s*(.+))?$`)
+)
+
+// exReservedAttributes are module attributes with a meaning of their own;
+// the others are the module's constants
+var exReservedAttributes = map[string]bool{
+	"doc": true, "moduledoc": true, "typedoc": true, "spec": true, "impl": true,
+	"behaviour": true, "callback": true, "macrocallback": true, "optional_callbacks": true,
+	"type": true, "typep": true, "opaque": true, "derive": true, "enforce_keys": true,
+	"since": true, "deprecated": true, "compile": true, "dialyzer": true, "on_load": true,
+	"external_resource": true, "before_compile": true, "after_compile": true,
+	"on_definition": true, "vsn": true, "file": true,
+}
+
+// parseElixir extracts modules as classes: their functions and macros as
+// methods (defp is private), behaviours and used modules as implemented,
+// struct and Ecto schema fields as properties. Protocols are abstract
+// classes; an implementation is named Protocol.Type after the module
+// Elixir defines for it. Callbacks a module declares make it a behaviour.
+func parseElixir(content string, skeleton *types.CodeSkeleton) {
+	lines := strings.Split(content, "\n")
+
+	type openModule struct {
+		class  int // index in skeleton.Classes
+		indent int
+	}
+	var modules []openModule
+	bodyIndent := -1 // column of the `end` of the function being skipped
+	inHeredoc := false
+	var decorators []string
+	doc := ""
+	specs := make(map[string]string)
+	seen := make(map[string]bool) // Module.name/arity, clauses after the first are skipped
+
+	current := func() *types.ClassSkeleton {
+		if len(modules) == 0 {
+			return nil
+		}
+		return &skeleton.Classes[modules[len(modules)-1].class]
+	}
+	openClass := func(cls types.ClassSkeleton, indent int) {
+		skeleton.Classes = append(skeleton.Classes, cls)
+		modules = append(modules, openModule{len(skeleton.Classes) - 1, indent})
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		// Heredocs (@moduledoc """, @doc """) hold examples that look like code
+		if strings.Count(line, `"""`)%2 == 1 || strings.Count(line, `'''`)%2 == 1 {
+			if !inHeredoc {
+				if exDocStart.MatchString(line) && i+1 < len(lines) {
+					doc = strings.TrimSpace(lines[i+1])
+				}
+			}
+			inHeredoc = !inHeredoc
+			continue
+		}
+		if inHeredoc || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if bodyIndent >= 0 {
+			if trimmed == "end" && indent == bodyIndent {
+				bodyIndent = -1
+			}
+			continue
+		}
+		if trimmed == "end" {
+			if n := len(modules); n > 0 && modules[n-1].indent == indent {
+				modules = modules[:n-1]
+			}
+			continue
+		}
+
+		if m := exModule.FindStringSubmatch(line); m != nil {
+			name := m[3]
+			// A nested module is named inside its parent
+			if parent := current(); parent != nil {
+				name = parent.Name + "." + name
+			}
+			openClass(types.ClassSkeleton{
+				Name:       name,
+				Line:       lineNo,
+				IsAbstract: m[2] == "defprotocol",
+				IsExported: true,
+			}, len(m[1]))
+			decorators, doc = nil, ""
+			continue
+		}
+		if m := exImpl.FindStringSubmatch(line); m != nil {
+			openClass(types.ClassSkeleton{
+				Name:       m[2] + "." + m[3],
+				Line:       lineNo,
+				Implements: []string{m[2]},
+				IsExported: true,
+			}, len(m[1]))
+			continue
+		}
+
+		cls := current()
+		if m := exFunction.FindStringSubmatch(line); m != nil {
+			text, end := line, i
+			if m[4] != "" {
+				text, end = joinParens(lines, i)
+			}
+			params := exParams(text, m[4] != "")
+			fn := types.FunctionSig{
+				Name:       m[3],
+				Line:       lineNo,
+				Params:     params,
+				ReturnType: specs[m[3]],
+				IsPrivate:  strings.HasSuffix(m[2], "p"),
+				IsExported: !strings.HasSuffix(m[2], "p"),
+				Decorators: decorators,
+				DocComment: doc,
+			}
+			if strings.HasPrefix(m[2], "defmacro") || strings.HasPrefix(m[2], "defguard") {
+				fn.Decorators = append(fn.Decorators, m[2])
+			}
+			decorators, doc = nil, ""
+
+			// A guard may follow on the next line
+			if end+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[end+1]), "when ") {
+				end++
+			}
+			// A do block is skipped to its end; do: keeps the body on the line
+			if exDoBlock.MatchString(strings.TrimSpace(lines[end])) {
+				bodyIndent = len(m[1])
+			}
+			i = end
+
+			owner := ""
+			if cls != nil {
+				owner = cls.Name
+			}
+			key := owner + "." + fn.Name + "/" + strconv.Itoa(len(params))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if cls != nil {
+				cls.Methods = append(cls.Methods, fn)
+			} else {
+				skeleton.Functions = append(skeleton.Functions, fn)
+			}
+			continue
+		}
+		if cls == nil {
+			continue
+		}
+
+		switch {
+		case exBehaviour.MatchString(line):
+			cls.Implements = append(cls.Implements, exBehaviour.FindStringSubmatch(line)[1])
+		case exUse.MatchString(line):
+			cls.Implements = append(cls.Implements, exUse.FindStringSubmatch(line)[1])
+		case exImplAttr.MatchString(line):
+			decorators = append(decorators, "@impl "+exImplAttr.FindStringSubmatch(line)[1])
+		case exSpec.MatchString(line):
+			m := exSpec.FindStringSubmatch(line)
+			specs[m[1]] = strings.TrimSpace(m[2])
+		case exDoc.MatchString(line):
+			doc = exDoc.FindStringSubmatch(line)[1]
+		case exCallback.MatchString(line):
+			m := exCallback.FindStringSubmatch(line)
+			behaviour := behaviourDef(skeleton, cls.Name, cls.Line)
+			behaviour.Properties = append(behaviour.Properties, types.PropertyDef{Name: m[1], Type: oneLine(m[2])})
+		case exTypeAttr.MatchString(line):
+			m := exTypeAttr.FindStringSubmatch(line)
+			skeleton.Types = append(skeleton.Types, types.TypeDef{
+				Name:       cls.Name + "." + m[2],
+				Line:       lineNo,
+				Kind:       "type",
+				IsExported: m[1] == "type",
+				RawDef:     strings.TrimSpace(m[3]),
+			})
+		case exStruct.MatchString(line):
+			text, end := line, i
+			if strings.ContainsAny(line, "[(") {
+				text, end = joinParens(lines, i)
+			}
+			cls.Properties = append(cls.Properties, exStructFields(exStruct.FindStringSubmatch(text)[1])...)
+			i = end
+		case exField.MatchString(line):
+			m := exField.FindStringSubmatch(line)
+			prop := types.PropertyDef{Name: m[2]}
+			// The type is the first argument after the name, unless options start there
+			if args := splitEnumBody(m[3], ","); m[3] != "" && !exKeyword.MatchString(args[0]) {
+				prop.Type = oneLine(strings.TrimPrefix(strings.TrimSpace(args[0]), ":"))
+			}
+			if m[1] != "field" {
+				prop.Type = strings.TrimSpace(m[1] + " " + prop.Type)
+			}
+			cls.Properties = append(cls.Properties, prop)
+		case exAttribute.MatchString(line):
+			m := exAttribute.FindStringSubmatch(line)
+			if !exReservedAttributes[m[1]] {
+				skeleton.Constants = append(skeleton.Constants, types.ConstDef{
+					Name:  "@" + m[1],
+					Line:  lineNo,
+					Value: strings.TrimSpace(m[2]),
+				})
+			}
+		}
+	}
+}
+
+// behaviourDef returns the behaviour the callbacks of a module are
+// collected in, adding it on the first callback
+func behaviourDef(skeleton *types.CodeSkeleton, name string, line int) *types.TypeDef {
+	for i := range skeleton.Interfaces {
+		if skeleton.Interfaces[i].Name == name && skeleton.Interfaces[i].Kind == "behaviour" {
+			return &skeleton.Interfaces[i]
+		}
+	}
+	skeleton.Interfaces = append(skeleton.Interfaces, types.TypeDef{Name: name, Line: line, Kind: "behaviour", IsExported: true})
+	return &skeleton.Interfaces[len(skeleton.Interfaces)-1]
+}
+
+// exParams reads the parameters of a def. Patterns are kept as written;
+// a default (opts \\ []) is split off.
+func exParams(text string, parens bool) []types.ParamDef {
+	if !parens {
+		return nil
+	}
+	var params []types.ParamDef
+	for _, item := range splitEnumBody(bracketBody(text, strings.Index(text, "(")), ",") {
+		item = oneLine(item)
+		if item == "" {
+			continue
+		}
+		p := types.ParamDef{Name: item}
+		if name, def, ok := strings.Cut(item, `\\`); ok {
+			p.Name, p.Default, p.Optional = strings.TrimSpace(name), strings.TrimSpace(def), true
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// exStructFields reads defstruct [:id, :name, age: 0] and defstruct
+// name: nil, age: 0
+func exStructFields(def string) []types.PropertyDef {
+	def = strings.TrimSpace(def)
+	if strings.HasPrefix(def, "[") {
+		def = bracketBody(def, 0)
+	}
+	var props []types.PropertyDef
+	for _, item := range splitEnumBody(def, ",") {
+		item = strings.TrimSpace(item)
+		if name, _, ok := strings.Cut(item, ":"); ok && name != "" && !strings.HasPrefix(item, ":") {
+			props = append(props, types.PropertyDef{Name: strings.TrimSpace(name)})
+		} else if name := strings.TrimPrefix(item, ":"); isGoTypeIdent(name) {
+			props = append(props, types.PropertyDef{Name: name})
+		}
+	}
+	return props
+}
+
+// joinParens joins the lines from start until its brackets are balanced
+// and returns the joined text and the index of its last line
+func joinParens(lines []string, start int) (string, int) {
+	text := lines[start]
+	end := start
+	for end+1 < len(lines) && end < start+20 && bracketDepth(text) > 0 {
+		end++
+		text += "\n" + lines[end]
+	}
+	return text, end
+}
+
+// bracketBody is the text inside the bracket at s[open], up to the end of
+// s when it is never closed, as in a file saved mid-edit
+func bracketBody(s string, open int) string {
+	end := closingBracket(s, open)
+	if end < 0 {
+		end = len(s)
+	}
+	return s[open+1 : end]
+}
+
+// bracketDepth is how many brackets are still open at the end of s
+func bracketDepth(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '"':
+			if end := quoteEnd(s, i); end > i {
+				i = end
+			}
+		}
+	}
+	return depth
+}
@@ -0,0 +1,235 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+var (
+	// The name a member starts with: an identifier or a quoted string
+	enumMemberName = regexp.MustCompile(`^(?:[A-Za-z_$][\w$]*|"[^"]*"|'[^']*')`)
+
+	// Attributes and annotations before a member: #[serde(...)], [Obsolete],
+	// @JsonProperty("x")
+	enumMemberAttr = regexp.MustCompile(`^(?:#!?\[|\[|@\w+(?:\.\w+)*)`)
+)
+
+// enumBody returns the source between the brace that opens the enum
+// declared on lines[start] and the brace that closes it, without comments
+func enumBody(lines []string, start int) string {
+	var sb strings.Builder
+	depth := 0
+	inBlockComment := false
+	for _, line := range lines[start:] {
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			if inBlockComment {
+				if strings.HasPrefix(line[i:], "*/") {
+					inBlockComment = false
+					i++
+				}
+				continue
+			}
+			switch {
+			case strings.HasPrefix(line[i:], "//"):
+				i = len(line)
+				continue
+			case strings.HasPrefix(line[i:], "/*"):
+				inBlockComment = true
+				i++
+				continue
+			case c == '"' || c == '\'' || c == '`':
+				if end := quoteEnd(line, i); end > i {
+					if depth > 0 {
+						sb.WriteString(line[i : end+1])
+					}
+					i = end
+					continue
+				}
+			case c == '{':
+				depth++
+				if depth == 1 {
+					continue
+				}
+			case c == '}':
+				depth--
+				if depth == 0 {
+					return sb.String()
+				}
+			}
+			if depth > 0 {
+				sb.WriteByte(c)
+			}
+		}
+		if depth > 0 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// quoteEnd returns the index of the quote closing the literal that starts
+// at line[start], or -1 when it isn't closed on the line (a Rust lifetime)
+func quoteEnd(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case line[start]:
+			return i
+		}
+	}
+	return -1
+}
+
+// enumMembers lists the members of an enum body whose members are
+// separated by commas: TypeScript, Rust, C#, C++, and Java and Kotlin,
+// where a semicolon ends the members and starts the class body
+func enumMembers(body string) []string {
+	body = splitEnumBody(body, ";")[0]
+	var members []string
+	for _, item := range splitEnumBody(body, ",") {
+		if name := memberName(item); name != "" {
+			members = append(members, name)
+		}
+	}
+	return members
+}
+
+// swiftEnumMembers lists the members of a Swift enum body from its case
+// declarations: case a, b(Int)
+func swiftEnumMembers(body string) []string {
+	var members []string
+	for _, stmt := range splitEnumBody(body, "\n;") {
+		stmt = strings.TrimPrefix(strings.TrimSpace(stmt), "indirect ")
+		if !strings.HasPrefix(stmt, "case ") {
+			continue
+		}
+		for _, item := range splitEnumBody(stmt[len("case "):], ",") {
+			if name := memberName(item); name != "" {
+				members = append(members, name)
+			}
+		}
+	}
+	return members
+}
+
+// splitEnumBody splits an enum body on any of seps outside brackets and
+// string literals. There is always at least one part.
+func splitEnumBody(body, seps string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == '"' || c == '\'' || c == '`':
+			if end := quoteEnd(body, i); end > i {
+				i = end
+			}
+		case depth == 0 && strings.IndexByte(seps, c) >= 0:
+			parts = append(parts, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, body[start:])
+}
+
+// memberName is the name an enum member item starts with, after its
+// attributes: #[default] Active = 1 is Active. Quoted names are unquoted.
+func memberName(item string) string {
+	item = strings.TrimSpace(item)
+	for {
+		m := enumMemberAttr.FindString(item)
+		if m == "" {
+			break
+		}
+		rest := item[len(m):]
+		if strings.HasSuffix(m, "[") {
+			rest = item[bracketEnd(item, strings.Index(item, "["))+1:]
+		} else if strings.HasPrefix(strings.TrimSpace(rest), "(") {
+			rest = strings.TrimSpace(rest)
+			rest = rest[bracketEnd(rest, 0)+1:]
+		}
+		item = strings.TrimSpace(rest)
+	}
+	name := enumMemberName.FindString(item)
+	if len(name) >= 2 && (name[0] == '"' || name[0] == '\'') {
+		name = name[1 : len(name)-1]
+	}
+	return name
+}
+
+// bracketEnd returns the index of the bracket closing the one at s[open],
+// or the last index when it isn't closed
+func bracketEnd(s string, open int) int {
+	if end := closingBracket(s, open); end >= 0 {
+		return end
+	}
+	return len(s) - 1
+}
+
+// closingBracket returns the index of the bracket closing the one at
+// s[open], or -1 when s ends first, as in a file saved mid-edit
+func closingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// goConstBlock groups the constants of a Go const block into enums. A
+// typed constant set with iota starts an enum named after its type; the
+// constants after it that repeat it implicitly, or have its type, are its
+// members. Other constants stay constants.
+type goConstBlock struct {
+	skeleton *types.CodeSkeleton
+	enum     int // index of the open enum in skeleton.Enums, -1 if none
+}
+
+func newGoConstBlock(skeleton *types.CodeSkeleton) *goConstBlock {
+	return &goConstBlock{skeleton: skeleton, enum: -1}
+}
+
+// add takes the names of one constant spec and reports whether they went
+// to an enum
+func (b *goConstBlock) add(names []string, line int, typ string, usesIota, implicit bool) bool {
+	switch {
+	case typ != "" && usesIota && isGoTypeIdent(typ):
+		b.skeleton.Enums = append(b.skeleton.Enums, types.EnumDef{Name: typ, Line: line, IsExported: isExportedGo(typ)})
+		b.enum = len(b.skeleton.Enums) - 1
+	case b.enum >= 0 && (implicit || typ == b.skeleton.Enums[b.enum].Name):
+	default:
+		b.enum = -1
+		return false
+	}
+	for _, name := range names {
+		if name != "_" {
+			b.skeleton.Enums[b.enum].Members = append(b.skeleton.Enums[b.enum].Members, name)
+		}
+	}
+	return true
+}
+
+// isGoTypeIdent reports whether a constant's type is a plain type name,
+// which an enum can be named after
+func isGoTypeIdent(typ string) bool {
+	return goIdent.MatchString(typ)
+}
+
+var (
+	goIdent = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	goIota  = regexp.MustCompile(`\biota\b`)
+)
@@ -0,0 +1,202 @@
+package skeleton
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Erlang patterns. Attributes and function clauses start in the first
+// column and end with a period.
+var (
+	erlModule    = regexp.MustCompile(`^-module\(\s*'?([\w@.]+)'?\s*\)`)
+	erlBehaviour = regexp.MustCompile(`^-behaviou?r\(\s*'?([\w@.]+)'?\s*\)`)
+	erlExport    = regexp.MustCompile(`(?s)^-export\(\s*\[(.*?)\]`)
+	erlExportAll = regexp.MustCompile(`^-compile\(.*\bexport_all\b`)
+	erlRecord    = regexp.MustCompile(`(?s)^-record\(\s*'?(\w+)'?\s*,\s*\{(.*)\}\s*\)`)
+	erlType      = regexp.MustCompile(`(?s)^-(type|opaque)\s+(\w+)\(.*?\)\s*::\s*(.+?)\.\s*$`)
+	erlDefine    = regexp.MustCompile(`(?s)^-define\(\s*(\w+)(?:\([^)]*\))?\s*,\s*(.+)\)\.\s*$`)
+	erlSpec      = regexp.MustCompile(`(?s)^-spec\s+(\w+)\(.*\)\s*->\s*(.+?)\.\s*$`)
+	erlCallback  = regexp.MustCompile(`(?s)^-callback\s+(\w+)(\(.*\)\s*->\s*.+?)\.\s*$`)
+	erlFunction  = regexp.MustCompile(`^([a-z]\w*|'[^']+')\(`)
+	erlDoc       = regexp.MustCompile(`^%+\s*@doc\s+(.+)$`)
+)
+
+// parseErlang extracts a module as a class: its functions as methods, by
+// name and arity, exported or private per its export list, with the return
+// types of their specs; behaviours as implemented; records, types,
+// macros, and the callbacks of a behaviour module. Header files (.hrl)
+// have no module, so their functions are top-level.
+func parseErlang(content string, skeleton *types.CodeSkeleton) {
+	lines := strings.Split(content, "\n")
+
+	var module *types.ClassSkeleton
+	var functions []types.FunctionSig
+	var arities []string // name/arity of each function, as exported
+	exported := make(map[string]bool)
+	exportAll := false
+	specs := make(map[string]string)
+	seen := make(map[string]bool)
+	doc := ""
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNo := i + 1
+
+		if m := erlDoc.FindStringSubmatch(line); m != nil {
+			doc = strings.TrimSpace(m[1])
+			continue
+		}
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '%' {
+			continue
+		}
+
+		if line[0] == '-' {
+			// An attribute runs to the period ending it
+			text, end := line, i
+			for end+1 < len(lines) && !erlAttributeEnds(text) && end < i+50 {
+				end++
+				text += "\n" + lines[end]
+			}
+			i = end
+
+			switch {
+			case erlModule.MatchString(text):
+				skeleton.Classes = append(skeleton.Classes, types.ClassSkeleton{
+					Name:       erlModule.FindStringSubmatch(text)[1],
+					Line:       lineNo,
+					IsExported: true,
+				})
+				module = &skeleton.Classes[len(skeleton.Classes)-1]
+			case erlBehaviour.MatchString(text):
+				if module != nil {
+					module.Implements = append(module.Implements, erlBehaviour.FindStringSubmatch(text)[1])
+				}
+			case erlExport.MatchString(text):
+				for _, f := range strings.Split(erlExport.FindStringSubmatch(text)[1], ",") {
+					exported[strings.Join(strings.Fields(erlStripComments(f)), "")] = true
+				}
+			case erlExportAll.MatchString(text):
+				exportAll = true
+			case erlRecord.MatchString(text):
+				m := erlRecord.FindStringSubmatch(text)
+				record := types.TypeDef{Name: m[1], Line: lineNo, Kind: "record", IsExported: true}
+				for _, field := range splitEnumBody(erlStripComments(m[2]), ",") {
+					record.Properties = append(record.Properties, erlRecordField(field))
+				}
+				skeleton.Types = append(skeleton.Types, record)
+			case erlType.MatchString(text):
+				m := erlType.FindStringSubmatch(text)
+				skeleton.Types = append(skeleton.Types, types.TypeDef{
+					Name:       m[2],
+					Line:       lineNo,
+					Kind:       "type",
+					IsExported: true,
+					RawDef:     oneLine(erlStripComments(m[3])),
+				})
+			case erlDefine.MatchString(text):
+				m := erlDefine.FindStringSubmatch(text)
+				skeleton.Constants = append(skeleton.Constants, types.ConstDef{
+					Name:  "?" + m[1],
+					Line:  lineNo,
+					Value: oneLine(erlStripComments(m[2])),
+				})
+			case erlSpec.MatchString(text):
+				m := erlSpec.FindStringSubmatch(text)
+				specs[m[1]] = oneLine(erlStripComments(m[2]))
+			case erlCallback.MatchString(text):
+				m := erlCallback.FindStringSubmatch(text)
+				name := "callbacks"
+				if module != nil {
+					name = module.Name
+				}
+				behaviour := behaviourDef(skeleton, name, lineNo)
+				behaviour.Properties = append(behaviour.Properties, types.PropertyDef{Name: m[1], Type: oneLine(erlStripComments(m[2]))})
+			}
+			continue
+		}
+
+		if m := erlFunction.FindStringSubmatch(line); m != nil {
+			text, end := joinParens(lines, i)
+			var params []types.ParamDef
+			for _, p := range splitEnumBody(bracketBody(text, strings.Index(text, "(")), ",") {
+				if p = oneLine(erlStripComments(p)); p != "" {
+					params = append(params, types.ParamDef{Name: p})
+				}
+			}
+			i = end
+
+			name := strings.Trim(m[1], "'")
+			key := name + "/" + strconv.Itoa(len(params))
+			if seen[key] {
+				doc = ""
+				continue
+			}
+			seen[key] = true
+			functions = append(functions, types.FunctionSig{
+				Name:       name,
+				Line:       lineNo,
+				Params:     params,
+				ReturnType: specs[name],
+				DocComment: doc,
+			})
+			arities = append(arities, key)
+			doc = ""
+		}
+	}
+
+	for i := range functions {
+		fn := &functions[i]
+		fn.IsExported = exportAll || exported[arities[i]]
+		fn.IsPrivate = module != nil && !fn.IsExported
+	}
+	if module != nil {
+		module.Methods = functions
+	} else {
+		skeleton.Functions = functions
+	}
+}
+
+// erlAttributeEnds reports whether an attribute is complete: it ends with
+// a period outside comments, strings and brackets
+func erlAttributeEnds(text string) bool {
+	text = strings.TrimSpace(erlStripComments(text))
+	return strings.HasSuffix(text, ".") && bracketDepth(text) <= 0
+}
+
+// erlStripComments drops the % comments of each line
+func erlStripComments(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		inString := false
+		for j := 0; j < len(line); j++ {
+			switch line[j] {
+			case '"':
+				inString = !inString
+			case '$':
+				j++ // $% is the character %
+			case '%':
+				if !inString {
+					line = line[:j]
+				}
+			}
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// erlRecordField reads a record field: name, name = Default, and the
+// type after ::
+func erlRecordField(field string) types.PropertyDef {
+	field = oneLine(field)
+	prop := types.PropertyDef{}
+	if before, typ, ok := strings.Cut(field, "::"); ok {
+		field, prop.Type = strings.TrimSpace(before), strings.TrimSpace(typ)
+	}
+	name, _, _ := strings.Cut(field, "=")
+	prop.Name = strings.Trim(strings.TrimSpace(name), "'")
+	return prop
+}
@@ -0,0 +1,184 @@
+package skeleton
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Generated code
+// Generated clients, protobuf stubs and ORM models can run to tens of
+// thousands of lines; their full skeleton is as useless to an agent as the
+// file itself. They are detected from the marker tools put in the header, or
+// from their path, and compacted to counts and top-level exports unless the
+// caller asks for the full skeleton.
+
+// generatedMarkers appear in the header of generated files
+var generatedMarkers = []string{
+	"code generated",
+	"do not edit",
+	"@generated",
+	"auto-generated",
+	"autogenerated",
+	"automatically generated",
+	"this file was generated",
+	"<auto-generated",
+}
+
+// generatedPathPatterns match generated files by name or directory
+var generatedPathPatterns = []string{
+	".pb.go", ".pb.gw.go", "_pb2.py", "_pb2_grpc.py", "_grpc.pb.go",
+	".pb.ts", "_pb.js", "_pb.d.ts",
+	".g.dart", ".freezed.dart",
+	".generated.", ".gen.go", "_gen.go", "zz_generated",
+	".designer.cs", ".g.cs",
+	"/generated/", "/__generated__/", "/gen/",
+}
+
+// generatedHeaderBytes is how much of a file is searched for a marker
+const generatedHeaderBytes = 2048
+
+// compactMaxPerKind caps the names kept per kind in a compacted skeleton
+const compactMaxPerKind = 100
+
+// IsGenerated reports whether a file is generated code, from a marker in
+// its first lines or from its path
+func IsGenerated(path string, content []byte) bool {
+	p := "/" + strings.ToLower(filepath.ToSlash(path))
+	for _, pattern := range generatedPathPatterns {
+		if strings.Contains(p, pattern) {
+			return true
+		}
+	}
+	header := content
+	if len(header) > generatedHeaderBytes {
+		header = header[:generatedHeaderBytes]
+	}
+	lower := strings.ToLower(string(header))
+	for _, marker := range generatedMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compact reduces a skeleton to its top-level exports, without members,
+// parameters or definitions, and records the full sizes in Counts. When the
+// language does not mark exports every top-level item is kept. Compacting
+// twice is a no-op.
+func Compact(sk *types.CodeSkeleton) {
+	if sk.Counts != nil {
+		return
+	}
+	counts := &types.SkeletonCounts{
+		Classes:    len(sk.Classes),
+		Functions:  len(sk.Functions),
+		Interfaces: len(sk.Interfaces),
+		Types:      len(sk.Types),
+		Enums:      len(sk.Enums),
+		Constants:  len(sk.Constants),
+	}
+	for _, cls := range sk.Classes {
+		counts.Methods += len(cls.Methods)
+		counts.Properties += len(cls.Properties)
+	}
+	for _, t := range sk.Types {
+		counts.Properties += len(t.Properties)
+	}
+	exportsMarked := marksExports(sk)
+	keep := func(exported bool, kept int) bool {
+		return (exported || !exportsMarked) && kept < compactMaxPerKind
+	}
+
+	var classes []types.ClassSkeleton
+	for _, cls := range sk.Classes {
+		if keep(cls.IsExported, len(classes)) {
+			classes = append(classes, types.ClassSkeleton{
+				Name: cls.Name, Line: cls.Line, Extends: cls.Extends,
+				IsAbstract: cls.IsAbstract, IsExported: cls.IsExported,
+			})
+		}
+	}
+	var functions []types.FunctionSig
+	for _, fn := range sk.Functions {
+		if keep(fn.IsExported, len(functions)) {
+			functions = append(functions, types.FunctionSig{Name: fn.Name, Line: fn.Line, IsExported: fn.IsExported})
+		}
+	}
+	compactTypes := func(defs []types.TypeDef) []types.TypeDef {
+		var result []types.TypeDef
+		for _, t := range defs {
+			if keep(t.IsExported, len(result)) {
+				rawDef := ""
+				if t.RawDef != "" {
+					rawDef = "..."
+				}
+				result = append(result, types.TypeDef{Name: t.Name, Line: t.Line, Kind: t.Kind, IsExported: t.IsExported, RawDef: rawDef})
+			}
+		}
+		return result
+	}
+	var enums []types.EnumDef
+	for _, e := range sk.Enums {
+		if keep(e.IsExported, len(enums)) {
+			enums = append(enums, types.EnumDef{Name: e.Name, Line: e.Line, IsExported: e.IsExported})
+		}
+	}
+	var constants []types.ConstDef
+	for _, c := range sk.Constants {
+		if keep(c.IsExported, len(constants)) {
+			constants = append(constants, types.ConstDef{Name: c.Name, Line: c.Line, IsExported: c.IsExported})
+		}
+	}
+
+	sk.Classes = classes
+	sk.Functions = functions
+	sk.Interfaces = compactTypes(sk.Interfaces)
+	sk.Types = compactTypes(sk.Types)
+	sk.Enums = enums
+	sk.Constants = constants
+	sk.Counts = counts
+	sk.SkeletonLines = estimateSkeletonLines(sk)
+}
+
+// marksExports reports whether the parser marked any top-level item as
+// exported; parsers for languages without an export concept never do
+func marksExports(sk *types.CodeSkeleton) bool {
+	for _, c := range sk.Classes {
+		if c.IsExported {
+			return true
+		}
+	}
+	for _, f := range sk.Functions {
+		if f.IsExported {
+			return true
+		}
+	}
+	for _, t := range append(append([]types.TypeDef{}, sk.Interfaces...), sk.Types...) {
+		if t.IsExported {
+			return true
+		}
+	}
+	for _, e := range sk.Enums {
+		if e.IsExported {
+			return true
+		}
+	}
+	for _, c := range sk.Constants {
+		if c.IsExported {
+			return true
+		}
+	}
+	return false
+}
+
+// compactNote describes what a compacted skeleton left out
+func compactNote(sk *types.CodeSkeleton) string {
+	c := sk.Counts
+	return "Generated file: top-level exports only (" +
+		itoa(c.Classes) + " classes, " + itoa(c.Methods) + " methods, " +
+		itoa(c.Functions) + " functions, " + itoa(c.Interfaces+c.Types) + " types, " +
+		itoa(c.Enums) + " enums in full). Request the full skeleton to see members."
+}
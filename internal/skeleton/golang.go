@@ -0,0 +1,267 @@
+package skeleton
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// parseGo reads Go source with go/parser, which gets multi-line
+// signatures, methods on generic types, grouped declarations and embedded
+// interfaces exactly. Source go/parser rejects falls back to the line-based
+// parser, which degrades line by line instead.
+func parseGo(content string, skeleton *types.CodeSkeleton) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		parseGoLines(content, skeleton)
+		return
+	}
+	g := goSource{fset: fset, content: content}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			fn := types.FunctionSig{
+				Name:       d.Name.Name,
+				Line:       g.line(d),
+				IsExported: d.Name.IsExported(),
+				Params:     g.params(d.Type.Params),
+				ReturnType: g.text(d.Type.Results),
+			}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				addGoMethod(skeleton, goReceiverType(g.text(d.Recv.List[0].Type)), fn)
+			} else {
+				skeleton.Functions = append(skeleton.Functions, fn)
+			}
+		case *ast.GenDecl:
+			switch d.Tok {
+			case token.TYPE:
+				for _, spec := range d.Specs {
+					g.typeSpec(spec.(*ast.TypeSpec), skeleton)
+				}
+			case token.CONST:
+				block := newGoConstBlock(skeleton)
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					var names []string
+					for _, name := range vs.Names {
+						names = append(names, name.Name)
+					}
+					if block.add(names, g.line(vs), g.text(vs.Type), g.usesIota(vs.Values), vs.Type == nil && len(vs.Values) == 0) {
+						continue
+					}
+					for _, name := range vs.Names {
+						if name.Name == "_" {
+							continue
+						}
+						skeleton.Constants = append(skeleton.Constants, types.ConstDef{
+							Name:       name.Name,
+							Line:       g.line(name),
+							Type:       g.text(vs.Type),
+							IsExported: name.IsExported(),
+						})
+					}
+				}
+			}
+		}
+	}
+	linkGoReceivers(skeleton)
+}
+
+// goSource maps AST nodes back to the source they were parsed from
+type goSource struct {
+	fset    *token.FileSet
+	content string
+}
+
+func (g goSource) line(n ast.Node) int {
+	return g.fset.Position(n.Pos()).Line
+}
+
+// text returns the source of a node on one line; "" for a nil node
+func (g goSource) text(n ast.Node) string {
+	if fl, ok := n.(*ast.FieldList); n == nil || ok && fl == nil {
+		return ""
+	}
+	start, end := g.fset.Position(n.Pos()).Offset, g.fset.Position(n.End()).Offset
+	return oneLine(g.content[start:end])
+}
+
+// usesIota reports whether any of the values refers to iota
+func (g goSource) usesIota(values []ast.Expr) bool {
+	for _, v := range values {
+		found := false
+		ast.Inspect(v, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// params lists a parameter list one name at a time: (a, b int) is a int
+// and b int. Unnamed parameters keep only their type.
+func (g goSource) params(list *ast.FieldList) []types.ParamDef {
+	if list == nil {
+		return nil
+	}
+	var params []types.ParamDef
+	for _, field := range list.List {
+		typ := g.text(field.Type)
+		if len(field.Names) == 0 {
+			params = append(params, types.ParamDef{Type: typ})
+			continue
+		}
+		for _, name := range field.Names {
+			params = append(params, types.ParamDef{Name: name.Name, Type: typ})
+		}
+	}
+	return params
+}
+
+// typeSpec adds a type declaration: interfaces with the interfaces they
+// embed, structs, and every other type with its definition
+func (g goSource) typeSpec(ts *ast.TypeSpec, skeleton *types.CodeSkeleton) {
+	td := types.TypeDef{
+		Name:       ts.Name.Name,
+		Line:       g.line(ts),
+		IsExported: ts.Name.IsExported(),
+	}
+	switch t := ts.Type.(type) {
+	case *ast.InterfaceType:
+		td.Kind = "interface"
+		for _, field := range t.Methods.List {
+			if len(field.Names) == 0 && isGoTypeName(field.Type) {
+				td.Extends = append(td.Extends, g.text(field.Type))
+			}
+		}
+		skeleton.Interfaces = append(skeleton.Interfaces, td)
+	case *ast.StructType:
+		td.Kind = "struct"
+		td.Properties = g.structFields(t)
+		skeleton.Types = append(skeleton.Types, td)
+	default:
+		td.Kind = "type"
+		if ts.Assign.IsValid() {
+			td.Kind = "alias"
+		}
+		td.RawDef = g.text(t)
+		skeleton.Types = append(skeleton.Types, td)
+	}
+}
+
+// structFields lists the fields of a struct with their tags. An embedded
+// field is named after its type, as Go names it: *pkg.Base is Base.
+func (g goSource) structFields(st *ast.StructType) []types.PropertyDef {
+	var fields []types.PropertyDef
+	for _, field := range st.Fields.List {
+		typ := g.text(field.Type)
+		tag := ""
+		if field.Tag != nil {
+			tag = goFieldTag(field.Tag.Value)
+		}
+		if len(field.Names) == 0 {
+			fields = append(fields, goField(goEmbeddedName(typ), typ, tag))
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				fields = append(fields, goField(name.Name, typ, tag))
+			}
+		}
+	}
+	return fields
+}
+
+func goField(name, typ, tag string) types.PropertyDef {
+	return types.PropertyDef{Name: name, Type: typ, Tag: tag, IsPrivate: !isExportedGo(name)}
+}
+
+// goEmbeddedName is the field name of an embedded type
+func goEmbeddedName(typ string) string {
+	name := goReceiverType(typ)
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// goFieldTag unquotes a struct tag literal: `json:"id"` is json:"id"
+func goFieldTag(literal string) string {
+	if tag, err := strconv.Unquote(literal); err == nil {
+		return tag
+	}
+	return literal
+}
+
+// isGoTypeName reports whether an embedded interface element names a type
+// (io.Reader, Store[T]) rather than a constraint such as ~int | ~string
+func isGoTypeName(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return true
+	case *ast.IndexExpr:
+		return isGoTypeName(e.X)
+	case *ast.IndexListExpr:
+		return isGoTypeName(e.X)
+	}
+	return false
+}
+
+// goReceiverType returns the type a method is declared on from its
+// receiver type: *List[T] is List
+func goReceiverType(receiver string) string {
+	receiver = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(receiver), "*"))
+	if i := strings.IndexByte(receiver, '['); i >= 0 {
+		receiver = receiver[:i]
+	}
+	return strings.TrimSpace(receiver)
+}
+
+// addGoMethod attaches a method to the class of its receiver type
+func addGoMethod(skeleton *types.CodeSkeleton, receiver string, fn types.FunctionSig) {
+	for i := range skeleton.Classes {
+		if skeleton.Classes[i].Name == receiver {
+			skeleton.Classes[i].Methods = append(skeleton.Classes[i].Methods, fn)
+			return
+		}
+	}
+	skeleton.Classes = append(skeleton.Classes, types.ClassSkeleton{
+		Name:       receiver,
+		Line:       fn.Line,
+		IsExported: isExportedGo(receiver),
+		Methods:    []types.FunctionSig{fn},
+	})
+}
+
+// linkGoReceivers places each receiver class at its type's declaration when
+// the file declares it, and orders the classes by line. Methods on types
+// declared elsewhere in the package stay at their first method.
+func linkGoReceivers(skeleton *types.CodeSkeleton) {
+	if len(skeleton.Classes) == 0 {
+		return
+	}
+	declared := make(map[string]int)
+	for _, t := range skeleton.Types {
+		declared[t.Name] = t.Line
+	}
+	for i := range skeleton.Classes {
+		if line, ok := declared[skeleton.Classes[i].Name]; ok {
+			skeleton.Classes[i].Line = line
+		}
+	}
+	sort.SliceStable(skeleton.Classes, func(i, j int) bool {
+		return skeleton.Classes[i].Line < skeleton.Classes[j].Line
+	})
+}
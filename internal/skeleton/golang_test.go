@@ -0,0 +1,250 @@
+package skeleton
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestGoParserDeclarations(t *testing.T) {
+	code := `package store
+
+import "io"
+
+type Store[T any] interface {
+	io.Closer
+	Reader[T]
+	Get(id string) (T, error)
+}
+
+type Number interface {
+	~int | ~float64
+}
+
+type (
+	List[T any] struct{ items []T }
+	ID          = string
+	Handler     func(w io.Writer, name string) error
+)
+
+const (
+	Red = iota
+	green
+	_
+)
+
+const Timeout int = 30
+
+// Push is declared over several lines
+func (l *List[T]) Push(
+	ctx context.Context,
+	items ...T,
+) (n int, err error) {
+	return 0, nil
+}
+
+func New[T any](a, b T, _ string) *List[T] { return nil }
+`
+	// Called directly: a tree-sitter build would parse the file itself
+	sk := &types.CodeSkeleton{}
+	parseGo(code, sk)
+
+	wantIfaces := []types.TypeDef{
+		{Name: "Store", Line: 5, Kind: "interface", IsExported: true, Extends: []string{"io.Closer", "Reader[T]"}},
+		{Name: "Number", Line: 11, Kind: "interface", IsExported: true},
+	}
+	if !reflect.DeepEqual(sk.Interfaces, wantIfaces) {
+		t.Errorf("interfaces = %+v, want %+v", sk.Interfaces, wantIfaces)
+	}
+	wantTypes := []types.TypeDef{
+		{Name: "List", Line: 16, Kind: "struct", IsExported: true, Properties: []types.PropertyDef{{Name: "items", Type: "[]T", IsPrivate: true}}},
+		{Name: "ID", Line: 17, Kind: "alias", IsExported: true, RawDef: "string"},
+		{Name: "Handler", Line: 18, Kind: "type", IsExported: true, RawDef: "func(w io.Writer, name string) error"},
+	}
+	if !reflect.DeepEqual(sk.Types, wantTypes) {
+		t.Errorf("types = %+v, want %+v", sk.Types, wantTypes)
+	}
+	wantConsts := []types.ConstDef{
+		{Name: "Red", Line: 22, IsExported: true},
+		{Name: "green", Line: 23},
+		{Name: "Timeout", Line: 27, Type: "int", IsExported: true},
+	}
+	if !reflect.DeepEqual(sk.Constants, wantConsts) {
+		t.Errorf("constants = %+v, want %+v", sk.Constants, wantConsts)
+	}
+	wantClasses := []types.ClassSkeleton{{Name: "List", Line: 16, IsExported: true, Methods: []types.FunctionSig{
+		{Name: "Push", Line: 30, IsExported: true, ReturnType: "(n int, err error)", Params: []types.ParamDef{
+			{Name: "ctx", Type: "context.Context"}, {Name: "items", Type: "...T"},
+		}},
+	}}}
+	if !reflect.DeepEqual(sk.Classes, wantClasses) {
+		t.Errorf("classes = %+v, want %+v", sk.Classes, wantClasses)
+	}
+	wantFuncs := []types.FunctionSig{
+		{Name: "New", Line: 37, IsExported: true, ReturnType: "*List[T]", Params: []types.ParamDef{
+			{Name: "a", Type: "T"}, {Name: "b", Type: "T"}, {Name: "_", Type: "string"},
+		}},
+	}
+	if !reflect.DeepEqual(sk.Functions, wantFuncs) {
+		t.Errorf("functions = %+v, want %+v", sk.Functions, wantFuncs)
+	}
+}
+
+func TestGoParserFallsBackOnSyntaxErrors(t *testing.T) {
+	filePath, cleanup := setupTestFile(t, "package x\n\nfunc Broken( {\n\nfunc Fine() {}\n", ".go")
+	defer cleanup()
+	sk, err := ParseFile(filePath)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(sk.Functions) != 1 || sk.Functions[0].Name != "Fine" {
+		t.Errorf("functions = %+v, want Fine from the line-based parser", sk.Functions)
+	}
+}
+
+func TestGoMethodsAttachToReceivers(t *testing.T) {
+	code := `package users
+
+func (s *Service) Find(id string) (*User, error) { return nil, nil }
+
+type Service struct{ repo Repo }
+
+func NewService(repo Repo) error { return nil }
+
+func (s Service) save(u *User) error { return nil }
+
+func (r *Repo) Load() {}
+`
+	for _, source := range []struct {
+		name  string
+		parse func(string, *types.CodeSkeleton)
+	}{
+		{"go/parser", parseGo},
+		{"line-based", parseGoLines},
+	} {
+		t.Run(source.name, func(t *testing.T) {
+			sk := &types.CodeSkeleton{}
+			source.parse(code, sk)
+
+			var classes []string
+			methods := make(map[string][]string)
+			for _, c := range sk.Classes {
+				classes = append(classes, c.Name)
+				for _, m := range c.Methods {
+					methods[c.Name] = append(methods[c.Name], m.Name)
+				}
+			}
+			// Service is placed at its declaration, after Repo's first method
+			if !reflect.DeepEqual(classes, []string{"Service", "Repo"}) {
+				t.Errorf("classes = %v, want Service then Repo", classes)
+			}
+			if !reflect.DeepEqual(methods, map[string][]string{"Service": {"Find", "save"}, "Repo": {"Load"}}) {
+				t.Errorf("methods = %v", methods)
+			}
+			if len(sk.Functions) != 1 || sk.Functions[0].Name != "NewService" {
+				t.Errorf("functions = %+v, want only NewService", sk.Functions)
+			}
+		})
+	}
+}
+
+func TestGoStructFields(t *testing.T) {
+	code := "package users\n\n" +
+		"type User struct {\n" +
+		"\tbase.Model\n" +
+		"\t*Audit `json:\"-\"`\n" +
+		"\tID, Email string `json:\"id\" db:\"id\"`\n" +
+		"\tRoles     map[string]bool // role -> granted\n" +
+		"\tprofile   struct {\n" +
+		"\t\tBio string\n" +
+		"\t}\n" +
+		"\t_ int\n" +
+		"}\n\n" +
+		"type Empty struct{}\n"
+	want := []types.PropertyDef{
+		{Name: "Model", Type: "base.Model"},
+		{Name: "Audit", Type: "*Audit", Tag: `json:"-"`},
+		{Name: "ID", Type: "string", Tag: `json:"id" db:"id"`},
+		{Name: "Email", Type: "string", Tag: `json:"id" db:"id"`},
+		{Name: "Roles", Type: "map[string]bool"},
+		{Name: "profile", IsPrivate: true},
+	}
+	// The line-based parser doesn't read nested struct bodies
+	for _, source := range []struct {
+		name    string
+		parse   func(string, *types.CodeSkeleton)
+		profile string
+	}{
+		{"go/parser", parseGo, "struct { Bio string }"},
+		{"line-based", parseGoLines, "struct"},
+	} {
+		t.Run(source.name, func(t *testing.T) {
+			sk := &types.CodeSkeleton{}
+			source.parse(code, sk)
+			if len(sk.Types) != 2 {
+				t.Fatalf("types = %+v, want User and Empty", sk.Types)
+			}
+			want[5].Type = source.profile
+			if got := sk.Types[0].Properties; !reflect.DeepEqual(got, want) {
+				t.Errorf("fields = %+v\nwant %+v", got, want)
+			}
+			if len(sk.Types[1].Properties) != 0 {
+				t.Errorf("Empty has fields %+v", sk.Types[1].Properties)
+			}
+		})
+	}
+}
+
+func TestGoIotaEnums(t *testing.T) {
+	code := `package orders
+
+type Status int
+
+const (
+	StatusPending Status = iota
+	StatusPaid
+	_
+	StatusShipped
+	MaxRetries = 3
+)
+
+const (
+	KB Size = 1 << (10 * (iota + 1))
+	MB
+)
+
+const (
+	Low = iota
+	High
+)
+`
+	wantEnums := []types.EnumDef{
+		{Name: "Status", Line: 6, IsExported: true, Members: []string{"StatusPending", "StatusPaid", "StatusShipped"}},
+		{Name: "Size", Line: 14, IsExported: true, Members: []string{"KB", "MB"}},
+	}
+	// Untyped iota constants have no type to name an enum after
+	wantConsts := []string{"MaxRetries", "Low", "High"}
+	for _, source := range []struct {
+		name  string
+		parse func(string, *types.CodeSkeleton)
+	}{
+		{"go/parser", parseGo},
+		{"line-based", parseGoLines},
+	} {
+		t.Run(source.name, func(t *testing.T) {
+			sk := &types.CodeSkeleton{}
+			source.parse(code, sk)
+			if !reflect.DeepEqual(sk.Enums, wantEnums) {
+				t.Errorf("enums = %+v\nwant %+v", sk.Enums, wantEnums)
+			}
+			var consts []string
+			for _, c := range sk.Constants {
+				consts = append(consts, c.Name)
+			}
+			if !reflect.DeepEqual(consts, wantConsts) {
+				t.Errorf("constants = %v, want %v", consts, wantConsts)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// mermaidClass is one box of a class diagram. Go structs and the methods
+// on them, or the parts of a C# partial class, merge into one box.
+type mermaidClass struct {
+	id         string
+	generics   string
+	stereotype string
+	members    []string
+	seen       map[string]bool
+	fieldTypes []string
+}
+
+var (
+	mermaidUnsafe  = regexp.MustCompile(`[{};"'\x60\n\r\t]`)
+	mermaidSpaces  = regexp.MustCompile(`\s+`)
+	mermaidNonWord = regexp.MustCompile(`\W+`)
+	mermaidWord    = regexp.MustCompile(`[A-Za-z_]\w*`)
+)
+
+// FormatSkeletonMermaid renders the classes, interfaces, structs and enums
+// of one or more skeletons as a Mermaid classDiagram: members with their
+// visibility, inheritance and implementation arrows, and an association
+// where a field's type is another class in the diagram.
+func FormatSkeletonMermaid(sks ...*types.CodeSkeleton) string {
+	var order []*mermaidClass
+	byID := make(map[string]*mermaidClass)
+	var relations []string
+	seenRelation := make(map[string]bool)
+
+	box := func(name, stereotype string) *mermaidClass {
+		id, generics := mermaidName(name)
+		if c, ok := byID[id]; ok {
+			if c.stereotype == "" {
+				c.stereotype = stereotype
+			}
+			return c
+		}
+		c := &mermaidClass{id: id, generics: generics, stereotype: stereotype, seen: make(map[string]bool)}
+		byID[id] = c
+		order = append(order, c)
+		return c
+	}
+	relate := func(from, arrow, to, label string) {
+		fromID, _ := mermaidName(from)
+		toID, _ := mermaidName(to)
+		if fromID == "" || toID == "" || fromID == toID {
+			return
+		}
+		rel := fromID + " " + arrow + " " + toID
+		if label != "" {
+			rel += " : " + label
+		}
+		if !seenRelation[rel] {
+			seenRelation[rel] = true
+			relations = append(relations, rel)
+		}
+	}
+
+	for _, sk := range sks {
+		if sk == nil {
+			continue
+		}
+		for _, t := range sk.Interfaces {
+			c := box(t.Name, "interface")
+			for _, p := range t.Properties {
+				c.addField(p)
+			}
+			for _, base := range t.Extends {
+				relate(base, "<|--", t.Name, "")
+			}
+		}
+		for _, t := range sk.Types {
+			if len(t.Properties) == 0 {
+				continue
+			}
+			stereotype := ""
+			if t.Kind != "" && t.Kind != "type" && t.Kind != "class" {
+				stereotype = t.Kind
+			}
+			c := box(t.Name, stereotype)
+			for _, p := range t.Properties {
+				c.addField(p)
+			}
+		}
+		for _, e := range sk.Enums {
+			c := box(e.Name, "enumeration")
+			for _, m := range e.Members {
+				c.add(mermaidText(m))
+			}
+		}
+		for _, cls := range sk.Classes {
+			stereotype := ""
+			if cls.IsAbstract {
+				stereotype = "abstract"
+			}
+			c := box(cls.Name, stereotype)
+			for _, p := range cls.Properties {
+				c.addField(p)
+			}
+			if cls.Constructor != nil {
+				c.addMethod(*cls.Constructor)
+			}
+			for _, m := range cls.Methods {
+				c.addMethod(m)
+			}
+			if cls.Extends != "" {
+				relate(cls.Extends, "<|--", cls.Name, "")
+			}
+			for _, iface := range cls.Implements {
+				relate(iface, "<|..", cls.Name, "")
+			}
+		}
+	}
+
+	// Fields typed with another class of the diagram are associations
+	for _, c := range order {
+		for _, ft := range c.fieldTypes {
+			name, typ, _ := strings.Cut(ft, "\x00")
+			for _, word := range mermaidWord.FindAllString(typ, -1) {
+				if target, ok := byID[word]; ok && target != c {
+					relate(c.id, "-->", target.id, name)
+				}
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("classDiagram\n")
+	for _, c := range order {
+		sb.WriteString("  class " + c.id + c.generics)
+		if c.stereotype == "" && len(c.members) == 0 {
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(" {\n")
+		if c.stereotype != "" {
+			sb.WriteString("    <<" + c.stereotype + ">>\n")
+		}
+		for _, m := range c.members {
+			sb.WriteString("    " + m + "\n")
+		}
+		sb.WriteString("  }\n")
+	}
+	for _, rel := range relations {
+		sb.WriteString("  " + rel + "\n")
+	}
+	return sb.String()
+}
+
+func (c *mermaidClass) add(member string) {
+	if member == "" || c.seen[member] {
+		return
+	}
+	c.seen[member] = true
+	c.members = append(c.members, member)
+}
+
+func (c *mermaidClass) addField(p types.PropertyDef) {
+	member := mermaidVisibility(p.IsPrivate)
+	if p.Type != "" {
+		member += mermaidText(p.Type) + " "
+	}
+	member += mermaidText(p.Name)
+	if p.IsStatic {
+		member += "$"
+	}
+	c.add(member)
+	if p.Type != "" {
+		c.fieldTypes = append(c.fieldTypes, mermaidText(p.Name)+"\x00"+p.Type)
+	}
+}
+
+func (c *mermaidClass) addMethod(fn types.FunctionSig) {
+	params := make([]string, len(fn.Params))
+	for i, p := range fn.Params {
+		params[i] = p.Name
+		if p.Type != "" {
+			params[i] = p.Type + " " + p.Name
+		}
+	}
+	member := mermaidVisibility(fn.IsPrivate) + mermaidText(fn.Name) + "(" + mermaidText(strings.Join(params, ", ")) + ")"
+	if fn.IsStatic {
+		member += "$"
+	}
+	if fn.ReturnType != "" {
+		member += " " + mermaidText(fn.ReturnType)
+	}
+	c.add(member)
+}
+
+func mermaidVisibility(private bool) string {
+	if private {
+		return "-"
+	}
+	return "+"
+}
+
+// mermaidName splits a type name into a Mermaid class ID and its generic
+// parameter: Box<T> is Box and ~T~, NSString+Feed is NSString_Feed. Mermaid
+// can't render several parameters, so Map<K, V> is just Map.
+func mermaidName(name string) (id, generics string) {
+	name = strings.TrimSpace(name)
+	if i := strings.IndexAny(name, "<["); i > 0 {
+		if params := strings.Trim(name[i+1:], "<>[] "); !strings.Contains(params, ",") {
+			generics = "~" + mermaidText(params) + "~"
+		}
+		name = name[:i]
+	}
+	// Qualified base types keep only their last part: models.Model is Model
+	if i := strings.LastIndexAny(name, ".:\\"); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+	return strings.Trim(mermaidNonWord.ReplaceAllString(name, "_"), "_"), generics
+}
+
+// mermaidText makes a type or signature safe inside a class body: generics
+// use tildes, and braces, quotes and semicolons are dropped
+func mermaidText(s string) string {
+	s = strings.NewReplacer("<", "~", ">", "~").Replace(s)
+	s = mermaidUnsafe.ReplaceAllString(s, " ")
+	return strings.TrimSpace(mermaidSpaces.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,75 @@
+package skeleton
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMermaidName(t *testing.T) {
+	for name, want := range map[string]string{"Box<T>": "Box~T~", "NSString+Feed": "NSString_Feed", "models.Model": "Model", "Repo[K, V]": "Repo"} {
+		if id, generics := mermaidName(name); id+generics != want {
+			t.Errorf("mermaidName(%q) = %q, want %q", name, id+generics, want)
+		}
+	}
+}
+
+func TestFormatSkeletonMermaid(t *testing.T) {
+	ts, err := ParseContent("shapes.ts", []byte(`export interface Drawable {
+  draw(): void;
+}
+
+export abstract class Shape implements Drawable {
+  private origin: Point;
+  static count: number;
+  constructor(origin: Point) {}
+  abstract area(): number;
+  draw(): void {}
+}
+
+export class Circle extends Shape {
+  radius: number;
+  area(): number {}
+}
+
+export class Point {
+  x: number;
+  y: number;
+}
+
+export enum Color { Red, Green }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSk, err := ParseContent("store.go", []byte(`package store
+
+type Store[T any] struct {
+	items map[string]T
+}
+
+func (s *Store[T]) Get(key string) (T, bool) { var zero T; return zero, false }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := FormatSkeletonMermaid(ts, goSk)
+	for _, want := range []string{
+		"classDiagram\n",
+		"  class Drawable {\n    <<interface>>\n",
+		"  class Shape {\n    <<abstract>>\n    -Point origin\n    +number count$\n    +constructor(Point origin)\n",
+		"  class Circle {\n    +number radius\n    +area() number\n  }\n",
+		"  class Color {\n    <<enumeration>>\n    Red\n    Green\n  }\n",
+		"  class Store {\n    <<struct>>\n    -map[string]T items\n    +Get(string key) (T, bool)\n  }\n",
+		"  Drawable <|.. Shape\n",
+		"  Shape <|-- Circle\n",
+		"  Shape --> Point : origin\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagram lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "class Store") != 1 {
+		t.Errorf("struct and its methods should share one box:\n%s", got)
+	}
+}
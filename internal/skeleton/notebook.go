@@ -0,0 +1,292 @@
+package skeleton
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Notebook is a Jupyter notebook read from its .ipynb JSON
+type Notebook struct {
+	Language string // kernel language; python when the notebook does not say
+	Cells    []NotebookCell
+	script   string
+}
+
+// NotebookCell is one cell of a notebook
+type NotebookCell struct {
+	Index  int    // 1-based, counting every cell
+	Kind   string // code, markdown or raw
+	Source string
+	Line   int // code cells: first line in the script form
+	Lines  int // code cells: lines in the script form
+}
+
+// ipynbFile is the part of the nbformat 4 JSON that indexing reads. Outputs
+// are ignored: they are results, often images, not logic.
+type ipynbFile struct {
+	Cells []struct {
+		CellType string          `json:"cell_type"`
+		Source   json.RawMessage `json:"source"`
+	} `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+var (
+	// IPython magics and shell escapes: %matplotlib inline, !pip install
+	notebookMagicRe  = regexp.MustCompile(`^\s*[%!]`)
+	notebookImportRe = regexp.MustCompile(`^\s*(?:from\s+([\w.]+)\s+import|import\s+([\w.]+(?:\s*,\s*[\w.]+)*))`)
+	// The end of a sentence: "trains. Then" but not "e.g. the" or "v1.2"
+	notebookSentenceRe = regexp.MustCompile(`[^.]{2}[.!?](\s+[A-Z]|$)`)
+)
+
+// maxCellSummary caps a markdown cell's summary
+const maxCellSummary = 160
+
+// notebookParsers parse the script form of a notebook by kernel language
+var notebookParsers = map[string]func(string, *types.CodeSkeleton){
+	"python":     parsePython,
+	"javascript": parseTypeScript,
+	"typescript": parseTypeScript,
+	"ruby":       parseRuby,
+	"scala":      parseScala,
+	"kotlin":     parseKotlin,
+	"java":       parseJava,
+	"go":         parseGo,
+	"rust":       parseRust,
+	"csharp":     parseCSharp,
+}
+
+// ParseNotebook reads the cells of a notebook and lays its code cells out
+// as one script
+func ParseNotebook(content []byte) (*Notebook, error) {
+	var file ipynbFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	nb := &Notebook{Language: notebookLanguage(file.Metadata.Kernelspec.Language, file.Metadata.LanguageInfo.Name)}
+	comment := "#"
+	if nb.Language != "python" && nb.Language != "r" && nb.Language != "julia" && nb.Language != "ruby" {
+		comment = "//"
+	}
+
+	var script []string
+	for i, c := range file.Cells {
+		cell := NotebookCell{Index: i + 1, Kind: c.CellType, Source: cellSource(c.Source)}
+		if cell.Kind == "code" {
+			// Percent format, as jupytext and editors write it
+			script = append(script, comment+" %% ["+strconv.Itoa(cell.Index)+"]")
+			cell.Line = len(script) + 1
+			for _, line := range strings.Split(strings.TrimRight(cell.Source, "\n"), "\n") {
+				if nb.Language == "python" && notebookMagicRe.MatchString(line) {
+					line = "# " + line
+				}
+				script = append(script, line)
+			}
+			cell.Lines = len(script) - cell.Line + 1
+		}
+		nb.Cells = append(nb.Cells, cell)
+	}
+	nb.script = strings.Join(script, "\n")
+	return nb, nil
+}
+
+// Script returns the code cells as one script, each cell starting with a
+// "# %% [n]" marker. Magics and shell escapes are commented out so the
+// script parses as the kernel language. Lines in a notebook's skeleton and
+// search chunks refer to this form.
+func (nb *Notebook) Script() string {
+	return nb.script
+}
+
+// notebookLanguage normalizes the kernel language: "python3" -> "python"
+func notebookLanguage(kernel, info string) string {
+	lang := strings.ToLower(kernel)
+	if lang == "" {
+		lang = strings.ToLower(info)
+	}
+	switch {
+	case lang == "":
+		return "python"
+	case strings.HasPrefix(lang, "python"):
+		return "python"
+	case lang == "c#":
+		return "csharp"
+	}
+	return lang
+}
+
+// cellSource joins a cell's source, stored as one string or a list of lines
+func cellSource(raw json.RawMessage) string {
+	var lines []string
+	if err := json.Unmarshal(raw, &lines); err == nil {
+		return strings.Join(lines, "")
+	}
+	var text string
+	json.Unmarshal(raw, &text)
+	return text
+}
+
+// parseNotebook fills a skeleton from a notebook: definitions from the
+// script form, and an outline of what each cell imports and defines
+func parseNotebook(content []byte, skeleton *types.CodeSkeleton) {
+	skeleton.Language = "jupyter"
+	nb, err := ParseNotebook(content)
+	if err != nil {
+		return
+	}
+	script := nb.Script()
+	skeleton.LineCount = strings.Count(script, "\n") + 1
+	if parse, ok := notebookParsers[nb.Language]; ok {
+		parse(script, skeleton)
+	}
+	skeleton.Cells = notebookOutline(nb, skeleton)
+}
+
+// notebookOutline lists the code cells with their imports and definitions,
+// and markdown cells with their heading and first sentence
+func notebookOutline(nb *Notebook, skeleton *types.CodeSkeleton) []types.CellOutline {
+	var outline []types.CellOutline
+	for _, cell := range nb.Cells {
+		switch cell.Kind {
+		case "markdown":
+			if title, summary := cell.Heading(), cell.Summary(); title != "" || summary != "" {
+				outline = append(outline, types.CellOutline{Index: cell.Index, Kind: "markdown", Title: title, Summary: summary})
+			}
+		case "code":
+			co := types.CellOutline{Index: cell.Index, Kind: "code", Line: cell.Line, Lines: cell.Lines}
+			inCell := func(line int) bool { return line >= cell.Line && line < cell.Line+cell.Lines }
+			for _, cls := range skeleton.Classes {
+				if inCell(cls.Line) {
+					co.Defines = append(co.Defines, cls.Name)
+				}
+			}
+			for _, fn := range skeleton.Functions {
+				if inCell(fn.Line) {
+					co.Defines = append(co.Defines, fn.Name)
+				}
+			}
+			if nb.Language == "python" {
+				co.Imports = notebookImports(cell.Source)
+			}
+			outline = append(outline, co)
+		}
+	}
+	return outline
+}
+
+// Heading returns the first heading of a markdown cell
+func (c NotebookCell) Heading() string {
+	if c.Kind != "markdown" {
+		return ""
+	}
+	for _, line := range strings.Split(c.Source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			return strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+	}
+	return ""
+}
+
+// Summary returns the first sentence of a markdown cell's prose, skipping
+// headings, images, HTML and code fences: "Loads events and trains."
+func (c NotebookCell) Summary() string {
+	if c.Kind != "markdown" {
+		return ""
+	}
+	var prose []string
+	inFence := false
+	for _, line := range strings.Split(c.Source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "![") || strings.HasPrefix(trimmed, "<") {
+			continue
+		}
+		if trimmed == "" {
+			if len(prose) > 0 {
+				break
+			}
+			continue
+		}
+		prose = append(prose, strings.TrimLeft(trimmed, "-*> "))
+	}
+	text := strings.Join(prose, " ")
+	if i := notebookSentenceRe.FindStringIndex(text); i != nil {
+		text = text[:i[0]+3]
+	}
+	if len(text) > maxCellSummary {
+		text = strings.TrimSpace(text[:maxCellSummary]) + "..."
+	}
+	return text
+}
+
+// notebookImports lists the modules a Python cell imports
+func notebookImports(source string) []string {
+	var modules []string
+	for _, line := range strings.Split(source, "\n") {
+		m := notebookImportRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if m[1] != "" {
+			modules = appendModule(modules, m[1])
+			continue
+		}
+		for _, mod := range strings.Split(m[2], ",") {
+			modules = appendModule(modules, strings.TrimSpace(mod))
+		}
+	}
+	return modules
+}
+
+func appendModule(modules []string, mod string) []string {
+	if mod == "" || containsString(modules, mod) {
+		return modules
+	}
+	return append(modules, mod)
+}
+
+// formatCellOutline renders one outline entry for FormatSkeleton
+func formatCellOutline(c types.CellOutline) string {
+	if c.Kind == "markdown" {
+		s := "[" + itoa(c.Index) + "]"
+		if c.Title != "" {
+			s += " # " + c.Title
+		}
+		if c.Summary != "" {
+			if c.Title != "" {
+				s += " -"
+			}
+			s += " " + c.Summary
+		}
+		return s
+	}
+	s := "[" + itoa(c.Index) + "] code L" + itoa(c.Line) + "-" + itoa(c.Line+c.Lines-1)
+	if len(c.Imports) > 0 {
+		s += ": imports " + strings.Join(c.Imports, ", ")
+	}
+	if len(c.Defines) > 0 {
+		if len(c.Imports) > 0 {
+			s += ";"
+		} else {
+			s += ":"
+		}
+		s += " defines " + strings.Join(c.Defines, ", ")
+	}
+	return s
+}
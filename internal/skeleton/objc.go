@@ -0,0 +1,426 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Objective-C patterns, matched against lines with comments and string
+// contents blanked out
+var (
+	objcInterface      = regexp.MustCompile(`^@interface\s+(\w+)\s*(<[^>]*>)?\s*(?:\(\s*(\w*)\s*\))?\s*(?::\s*(\w+))?\s*(?:<([^>]*)>)?`)
+	objcImplementation = regexp.MustCompile(`^@implementation\s+(\w+)\s*(?:\(\s*(\w*)\s*\))?`)
+	objcProtocol       = regexp.MustCompile(`^@protocol\s+(\w+)\s*(?:<([^>]*)>)?\s*$`)
+	objcProperty       = regexp.MustCompile(`^@property\s*(?:\(([^)]*)\))?\s*([^;]+);`)
+	objcBlockName      = regexp.MustCompile(`\(\s*\^\s*(?:_\w+\s+)*(\w+)\s*\)`)
+	objcTrailingName   = regexp.MustCompile(`(\w+)\s*(?:[A-Z][A-Z0-9_]+(?:\([^)]*\))?\s*)*$`)
+	objcEnum           = regexp.MustCompile(`^typedef\s+NS_(?:ENUM|OPTIONS|CLOSED_ENUM|ERROR_ENUM)\s*\(\s*\w+\s*,\s*(\w+)\s*\)`)
+	objcBlockTypedef   = regexp.MustCompile(`^typedef\s+(.+?)\s*\(\s*\^\s*(\w+)\s*\)\s*(\(.*\))\s*;`)
+	objcTypedef        = regexp.MustCompile(`^typedef\s+([\w\s<>*]+?)\s*\b(\w+)\s*;`)
+	objcConstant       = regexp.MustCompile(`^((?:(?:static|extern|FOUNDATION_EXPORT|UIKIT_EXTERN)\s+)*)(?:const\s+)?(\w+(?:\s*<[^>]*>)?\s*\*?)\s*const\s+(\w+)\s*(?:=\s*([^;]+))?;`)
+	objcCFunction      = regexp.MustCompile(`^((?:(?:static|inline|extern|FOUNDATION_EXPORT|FOUNDATION_STATIC_INLINE|NS_INLINE|UIKIT_EXTERN)\s+)*)(\w[\w\s]*?[\s*]+)(\w+)\s*\(([^()]*)\)\s*[{;]?\s*$`)
+
+	// objcHeaderMarker tells an Objective-C header from a C one
+	objcHeaderMarker = regexp.MustCompile(`(?m)^\s*(?:@interface|@protocol|@class|@import|NS_ASSUME_NONNULL_BEGIN|#import\s*<(?:Foundation|UIKit|AppKit|Cocoa)/)`)
+)
+
+// objcContainer is the @interface, @implementation or @protocol being read
+type objcContainer struct {
+	class   int  // index in skeleton.Classes, or -1 for a protocol
+	iface   int  // index in skeleton.Interfaces for a protocol
+	depth   int  // brace depth it was opened at; deeper lines are bodies
+	public  bool // @interface other than a class extension
+	private bool // class extension: @interface Name ()
+}
+
+// isObjCHeader reports whether a .h file declares Objective-C
+func isObjCHeader(content string) bool {
+	return objcHeaderMarker.MatchString(content)
+}
+
+// parseObjC extracts @interface and @implementation blocks as classes,
+// merged by name, with their properties and methods named by selector
+// (fetchWithURL:completion:) and marked static for + methods. Methods
+// declared in an @interface are exported, those in a class extension are
+// private, and those only in an @implementation are neither. Categories
+// are classes named Class+Category, as their files are. Protocols become
+// interfaces with the protocols they adopt and their properties; NS_ENUM
+// and NS_OPTIONS types become enums, and C functions, typedefs and
+// constants are read at file level. Objective-C++ is read the same way.
+func parseObjC(content string, skeleton *types.CodeSkeleton) {
+	raw := strings.Split(content, "\n")
+	lines := objcCode(content)
+
+	var c *objcContainer
+	depth := 0
+	inMacro := false
+	var method strings.Builder
+	methodLine := 0
+
+	classIndex := func(name string) int {
+		for i, cls := range skeleton.Classes {
+			if cls.Name == name {
+				return i
+			}
+		}
+		skeleton.Classes = append(skeleton.Classes, types.ClassSkeleton{Name: name, IsExported: true})
+		return len(skeleton.Classes) - 1
+	}
+
+	for i, line := range lines {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+		if inMacro || strings.HasPrefix(trimmed, "#") {
+			inMacro = strings.HasSuffix(trimmed, "\\")
+			continue
+		}
+		before := depth
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+
+		// A method signature runs until the ; declaring it or the { of its body
+		if methodLine > 0 || (c != nil && before == c.depth && (strings.HasPrefix(trimmed, "-") || strings.HasPrefix(trimmed, "+"))) {
+			if methodLine == 0 {
+				methodLine = lineNo
+				method.Reset()
+			}
+			method.WriteString(trimmed + " ")
+			end := strings.IndexAny(method.String(), ";{")
+			if end < 0 {
+				continue
+			}
+			if fn, ok := parseObjCMethod(method.String()[:end]); ok && c.class >= 0 {
+				fn.Line = methodLine
+				addObjCMethod(&skeleton.Classes[c.class], fn, c)
+			}
+			methodLine = 0
+			continue
+		}
+
+		if c != nil {
+			if before != c.depth {
+				continue // instance variables and method bodies
+			}
+			switch {
+			case strings.HasPrefix(trimmed, "@end"):
+				c = nil
+			case strings.HasPrefix(trimmed, "@property"):
+				prop, ok := parseObjCProperty(trimmed)
+				if !ok {
+					continue
+				}
+				if c.class < 0 {
+					iface := &skeleton.Interfaces[c.iface]
+					iface.Properties = append(iface.Properties, prop)
+					continue
+				}
+				prop.IsPrivate = c.private
+				cls := &skeleton.Classes[c.class]
+				if !hasObjCProperty(cls.Properties, prop.Name) {
+					cls.Properties = append(cls.Properties, prop)
+				}
+			}
+			continue
+		}
+		if before > 0 {
+			continue // bodies of C functions, enums and structs
+		}
+
+		if m := objcInterface.FindStringSubmatch(trimmed); m != nil {
+			name, category, protocols := m[1], m[3], m[5]
+			if m[4] == "" && protocols == "" && m[2] != "" && !strings.Contains(trimmed, "(") {
+				protocols = strings.Trim(m[2], "<>") // no superclass: the angle brackets list protocols
+			}
+			extension := strings.Contains(trimmed, "(") && category == ""
+			if category != "" {
+				name += "+" + category
+			}
+			c = &objcContainer{class: classIndex(name), depth: before, public: !extension, private: extension}
+			cls := &skeleton.Classes[c.class]
+			if cls.Line == 0 {
+				cls.Line = lineNo
+			}
+			if m[4] != "" {
+				cls.Extends = m[4]
+			}
+			for _, p := range splitAndTrim(protocols, ",") {
+				if !containsString(cls.Implements, p) {
+					cls.Implements = append(cls.Implements, p)
+				}
+			}
+			continue
+		}
+		if m := objcImplementation.FindStringSubmatch(trimmed); m != nil {
+			name := m[1]
+			if m[2] != "" {
+				name += "+" + m[2]
+			}
+			c = &objcContainer{class: classIndex(name), depth: before}
+			if cls := &skeleton.Classes[c.class]; cls.Line == 0 {
+				cls.Line = lineNo
+			}
+			continue
+		}
+		if m := objcProtocol.FindStringSubmatch(trimmed); m != nil {
+			skeleton.Interfaces = append(skeleton.Interfaces, types.TypeDef{
+				Name:       m[1],
+				Line:       lineNo,
+				Kind:       "protocol",
+				IsExported: true,
+				Extends:    splitAndTrim(m[2], ","),
+			})
+			c = &objcContainer{class: -1, iface: len(skeleton.Interfaces) - 1, depth: before}
+			continue
+		}
+
+		if m := objcEnum.FindStringSubmatch(trimmed); m != nil {
+			skeleton.Enums = append(skeleton.Enums, types.EnumDef{
+				Name:       m[1],
+				Line:       lineNo,
+				IsExported: true,
+				Members:    enumMembers(enumBody(raw, i)),
+			})
+			continue
+		}
+		if m := objcBlockTypedef.FindStringSubmatch(trimmed); m != nil {
+			skeleton.Types = append(skeleton.Types, types.TypeDef{Name: m[2], Line: lineNo, Kind: "alias", IsExported: true, RawDef: m[1] + " (^)" + m[3]})
+			continue
+		}
+		if m := objcTypedef.FindStringSubmatch(trimmed); m != nil && !strings.HasPrefix(m[1], "struct") && !strings.HasPrefix(m[1], "enum") {
+			skeleton.Types = append(skeleton.Types, types.TypeDef{Name: m[2], Line: lineNo, Kind: "alias", IsExported: true, RawDef: oneLine(m[1])})
+			continue
+		}
+		if m := objcConstant.FindStringSubmatch(trimmed); m != nil {
+			skeleton.Constants = append(skeleton.Constants, types.ConstDef{
+				Name:       m[3],
+				Line:       lineNo,
+				Type:       strings.TrimSpace(m[2]),
+				Value:      objcLiteral(raw[i], m[4]),
+				IsExported: !strings.Contains(m[1], "static"),
+			})
+			continue
+		}
+		if m := objcCFunction.FindStringSubmatch(trimmed); m != nil && !strings.HasPrefix(trimmed, "return") && !strings.HasPrefix(trimmed, "typedef") {
+			ret := oneLine(m[2])
+			if strings.HasSuffix(ret, "*") {
+				ret = strings.TrimSpace(strings.TrimRight(ret, "*")) + " *"
+			}
+			skeleton.Functions = append(skeleton.Functions, types.FunctionSig{
+				Name:       m[3],
+				Line:       lineNo,
+				Params:     parseObjCCParams(m[4]),
+				ReturnType: ret,
+				IsPrivate:  strings.Contains(m[1], "static"),
+				IsExported: !strings.Contains(m[1], "static"),
+			})
+		}
+	}
+}
+
+// addObjCMethod adds a method to its class unless the class already
+// declares it: an @implementation keeps its @interface's declaration
+func addObjCMethod(cls *types.ClassSkeleton, fn types.FunctionSig, c *objcContainer) {
+	for _, m := range cls.Methods {
+		if m.Name == fn.Name && m.IsStatic == fn.IsStatic {
+			return
+		}
+	}
+	fn.IsExported = c.public
+	fn.IsPrivate = c.private
+	cls.Methods = append(cls.Methods, fn)
+}
+
+// parseObjCMethod reads a method signature:
+// - (void)fetchWithURL:(NSURL *)url completion:(void (^)(NSData *))done
+// is fetchWithURL:completion: with parameters url and done. Macros after
+// the selector (NS_DESIGNATED_INITIALIZER) are ignored.
+func parseObjCMethod(sig string) (types.FunctionSig, bool) {
+	sig = strings.TrimSpace(sig)
+	if sig == "" {
+		return types.FunctionSig{}, false
+	}
+	fn := types.FunctionSig{IsStatic: sig[0] == '+', ReturnType: "id"}
+	rest := strings.TrimSpace(sig[1:])
+	if strings.HasPrefix(rest, "(") {
+		end := objcParenEnd(rest)
+		if end < 0 {
+			return fn, false
+		}
+		fn.ReturnType = oneLine(rest[1:end])
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	var selector strings.Builder
+	for rest != "" {
+		part := objcIdent(rest)
+		after := strings.TrimSpace(rest[len(part):])
+		if !strings.HasPrefix(after, ":") {
+			if selector.Len() == 0 {
+				selector.WriteString(part) // a unary selector: - (void)reload
+			}
+			break
+		}
+		selector.WriteString(part + ":")
+		after = strings.TrimSpace(after[1:])
+		param := types.ParamDef{Type: "id"}
+		if strings.HasPrefix(after, "(") {
+			end := objcParenEnd(after)
+			if end < 0 {
+				break
+			}
+			param.Type = oneLine(after[1:end])
+			after = strings.TrimSpace(after[end+1:])
+		}
+		param.Name = objcIdent(after)
+		fn.Params = append(fn.Params, param)
+		rest = strings.TrimSpace(after[len(param.Name):])
+		if strings.HasPrefix(rest, ",") {
+			break // variadic: , ...
+		}
+	}
+	fn.Name = selector.String()
+	return fn, fn.Name != ""
+}
+
+// parseObjCProperty reads an @property declaration. The name of a block
+// property is inside its type: void (^handler)(NSError *) is handler, of
+// type void (^)(NSError *).
+func parseObjCProperty(decl string) (types.PropertyDef, bool) {
+	m := objcProperty.FindStringSubmatch(decl)
+	if m == nil {
+		return types.PropertyDef{}, false
+	}
+	prop := types.PropertyDef{}
+	for _, attr := range splitAndTrim(m[1], ",") {
+		switch attr {
+		case "readonly":
+			prop.IsReadonly = true
+		case "class":
+			prop.IsStatic = true
+		}
+	}
+	body := strings.TrimSpace(m[2])
+	if b := objcBlockName.FindStringSubmatchIndex(body); b != nil {
+		prop.Name = body[b[2]:b[3]]
+		prop.Type = oneLine(body[:b[0]] + "(^)" + body[b[1]:])
+		return prop, true
+	}
+	n := objcTrailingName.FindStringSubmatchIndex(body)
+	if n == nil {
+		return prop, false
+	}
+	prop.Name = body[n[2]:n[3]]
+	prop.Type = strings.TrimSpace(body[:n[2]])
+	return prop, prop.Type != ""
+}
+
+// parseObjCCParams reads the parameters of a C function: (int count,
+// NSString *name). A lone void means none.
+func parseObjCCParams(s string) []types.ParamDef {
+	var params []types.ParamDef
+	for _, p := range splitAndTrim(s, ",") {
+		if p == "void" || p == "..." {
+			continue
+		}
+		m := objcTrailingName.FindStringSubmatchIndex(p)
+		if m == nil || m[2] == 0 {
+			params = append(params, types.ParamDef{Type: p})
+			continue
+		}
+		params = append(params, types.ParamDef{Name: p[m[2]:m[3]], Type: strings.TrimSpace(p[:m[2]])})
+	}
+	return params
+}
+
+func hasObjCProperty(props []types.PropertyDef, name string) bool {
+	for _, p := range props {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// objcIdent returns the identifier s starts with
+func objcIdent(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// objcParenEnd returns the index of the parenthesis closing the one s
+// starts with, or -1
+func objcParenEnd(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// objcLiteral returns a constant's value as written: the blanked line
+// locates it, the raw line holds the string contents
+func objcLiteral(rawLine, blanked string) string {
+	blanked = strings.TrimSpace(blanked)
+	if blanked == "" {
+		return ""
+	}
+	if i := strings.Index(rawLine, "="); i >= 0 {
+		value := strings.TrimSpace(rawLine[i+1:])
+		if j := strings.LastIndex(value, ";"); j >= 0 {
+			value = strings.TrimSpace(value[:j])
+		}
+		return value
+	}
+	return blanked
+}
+
+// objcCode returns the lines of a source file with comments blanked and
+// the contents of string and character literals replaced by spaces, so
+// braces and semicolons in them are not counted. Columns and line numbers
+// are kept.
+func objcCode(content string) []string {
+	b := []byte(content)
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '/':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			b[i], b[i+1] = ' ', ' '
+			for i += 2; i < len(b) && !(b[i] == '*' && i+1 < len(b) && b[i+1] == '/'); i++ {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+			}
+			if i+1 < len(b) {
+				b[i], b[i+1] = ' ', ' '
+				i++
+			}
+		case b[i] == '"' || b[i] == '\'':
+			quote := b[i]
+			for i++; i < len(b) && b[i] != quote && b[i] != '\n'; i++ {
+				if b[i] == '\\' && i+1 < len(b) && b[i+1] != '\n' {
+					b[i] = ' '
+					i++
+				}
+				b[i] = ' '
+			}
+		}
+	}
+	return strings.Split(string(b), "\n")
+}
@@ -18,8 +18,9 @@ var (
 	// Function/method patterns
 	tsFunction = regexp.MustCompile(`(?m)^(\s*)(export\s+)?(async\s+)?function\s+(\w+)\s*(<[^>]+>)?\s*\(([^)]*)\)(?:\s*:\s*([^{]+))?\s*\{`)
 	tsArrowExport = regexp.MustCompile(`(?m)^(\s*)export\s+const\s+(\w+)\s*=\s*(async\s+)?\([^)]*\)(?:\s*:\s*[^=]+)?\s*=>`)
-	tsMethod = regexp.MustCompile(`(?m)^(\s*)(private\s+|public\s+|protected\s+)?(static\s+)?(async\s+)?(\w+)\s*(<[^>]+>)?\s*\(([^)]*)\)(?:\s*:\s*([^{]+))?\s*\{`)
-	tsConstructor = regexp.MustCompile(`(?m)^(\s*)constructor\s*\(([^)]*)\)\s*\{`)
+	// Parameters may carry decorators with arguments: @Param('id') id: string
+	tsMethod = regexp.MustCompile(`(?m)^(\s*)(private\s+|public\s+|protected\s+)?(static\s+)?(async\s+)?(\w+)\s*(<[^>]+>)?\s*\(((?:[^()]|\([^()]*\))*)\)(?:\s*:\s*([^{]+))?\s*\{`)
+	tsConstructor = regexp.MustCompile(`(?m)^(\s*)constructor\s*\(((?:[^()]|\([^()]*\))*)\)\s*\{`)
 
 	// Interface/type patterns
 	tsInterface = regexp.MustCompile(`(?m)^(\s*)(export\s+)?interface\s+(\w+)(?:\s+extends\s+([\w,\s]+))?\s*\{`)
@@ -39,6 +40,14 @@ var (
 	goFunc  = regexp.MustCompile(`(?m)^func\s+(\((\w+)\s+\*?(\w+)\)\s+)?(\w+)\s*\(([^)]*)\)(?:\s*\(([^)]*)\)|\s*(\w+(?:\s*\*?\w+)?))?\s*\{`)
 	goType  = regexp.MustCompile(`(?m)^type\s+(\w+)\s+(struct|interface)\s*\{`)
 	goConst = regexp.MustCompile(`(?m)^const\s+(\w+)(?:\s+(\w+))?\s*=`)
+
+	// Const blocks, one spec per line: Red Color = iota, or just Green
+	goConstBlockStart = regexp.MustCompile(`^const\s*\(\s*(?://.*)?$`)
+	goConstSpec       = regexp.MustCompile(`^\s+(\w+(?:\s*,\s*\w+)*)(?:\s+([^=/\s][^=/]*?))?\s*(?:=\s*([^/]*?))?\s*(?://.*)?$`)
+
+	// Struct fields: named (a, b int `tag`) and embedded (*pkg.Base)
+	goStructField   = regexp.MustCompile("^\\s+(\\w+(?:\\s*,\\s*\\w+)*)\\s+([^`/\\s][^`/]*?)\\s*(`[^`]*`|\"(?:[^\"\\\\]|\\\\.)*\")?\\s*(?://.*)?$")
+	goEmbeddedField = regexp.MustCompile("^\\s+(\\*?[\\w.]+(?:\\[[^\\]]*\\])?)\\s*(`[^`]*`|\"(?:[^\"\\\\]|\\\\.)*\")?\\s*(?://.*)?$")
 )
 
 // Python patterns
@@ -66,18 +75,23 @@ var (
 	csProperty = regexp.MustCompile(`(?m)^(\s*)(public\s+|private\s+|protected\s+|internal\s+)?(static\s+)?(\w+(?:<[^>]+>)?(?:\[\]|\?)?)\s+(\w+)\s*\{\s*(get|set)`)
 	csAttribute = regexp.MustCompile(`(?m)^(\s*)\[(\w+)`)
 	csStruct = regexp.MustCompile(`(?m)^(\s*)(public\s+|internal\s+)?(readonly\s+)?(partial\s+)?struct\s+(\w+)(?:<[^>]+>)?\s*\{`)
-	csEnum = regexp.MustCompile(`(?m)^(\s*)(public\s+|internal\s+)?enum\s+(\w+)\s*\{`)
+	csEnum = regexp.MustCompile(`(?m)^(\s*)(public\s+|internal\s+)?enum\s+(\w+)(?:\s*:\s*\w+)?\s*(?:\{|$)`)
 )
 
 // Rust patterns
 var (
 	rustStruct = regexp.MustCompile(`(?m)^(\s*)(pub\s+)?struct\s+(\w+)(?:<[^>]+>)?\s*[\({]`)
 	rustEnum   = regexp.MustCompile(`(?m)^(\s*)(pub\s+)?enum\s+(\w+)(?:<[^>]+>)?\s*\{`)
-	rustTrait  = regexp.MustCompile(`(?m)^(\s*)(pub\s+)?trait\s+(\w+)(?:<[^>]+>)?(?:\s*:\s*[\w\s+]+)?\s*\{`)
+	rustTrait  = regexp.MustCompile(`(?m)^(\s*)(pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?trait\s+(\w+)(?:<[^>]+>)?(?:\s*:\s*([\w\s+:']+))?\s*(?:where\b[^{]*)?\{`)
+	rustImpl   = regexp.MustCompile(`^\s*(?:unsafe\s+)?impl\b(.*)$`)
 	rustFn     = regexp.MustCompile(`(?m)^(\s*)(pub\s+)?(async\s+)?fn\s+(\w+)(?:<[^>]+>)?\s*\(([^)]*)\)(?:\s*->\s*([^\{]+))?\s*\{`)
 	rustType   = regexp.MustCompile(`(?m)^(\s*)(pub\s+)?type\s+(\w+)(?:<[^>]+>)?\s*=`)
 	rustConst  = regexp.MustCompile(`(?m)^(\s*)(pub\s+)?const\s+(\w+)\s*:\s*([^=]+)\s*=`)
 	rustMacro  = regexp.MustCompile(`(?m)^(\s*)#\[(\w+)`)
+
+	// Struct fields, and the visibility of tuple struct fields
+	rustField      = regexp.MustCompile(`^\s*(pub(?:\([^)]*\))?\s+)?(?:r#)?(\w+)\s*:\s*(.+?)\s*,?\s*(?://.*)?$`)
+	rustVisibility = regexp.MustCompile(`^pub(?:\([^)]*\))?\s+`)
 )
 
 // C/C++ patterns
@@ -88,6 +102,7 @@ var (
 	cppNamespace = regexp.MustCompile(`(?m)^(\s*)namespace\s+(\w+)\s*\{`)
 	cppTypedef   = regexp.MustCompile(`(?m)^(\s*)typedef\s+(.+)\s+(\w+)\s*;`)
 	cppEnum      = regexp.MustCompile(`(?m)^(\s*)enum(?:\s+class)?\s+(\w+)(?:\s*:\s*\w+)?\s*\{`)
+	cppAccess    = regexp.MustCompile(`^\s*(public|private|protected)(?:\s+\w+)?\s*:`) // public:, and Qt's public slots:
 )
 
 // ParseFile extracts a code skeleton from a source file
@@ -96,7 +111,12 @@ func ParseFile(filePath string) (*types.CodeSkeleton, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ParseContent(filePath, content)
+}
 
+// ParseContent extracts a code skeleton from the content of a file, such
+// as a version read from git. The path picks the language.
+func ParseContent(filePath string, content []byte) (*types.CodeSkeleton, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	lines := strings.Split(string(content), "\n")
 	lineCount := len(lines)
@@ -104,6 +124,14 @@ func ParseFile(filePath string) (*types.CodeSkeleton, error) {
 	skeleton := &types.CodeSkeleton{
 		Path:      filePath,
 		LineCount: lineCount,
+		Generated: IsGenerated(filePath, content),
+	}
+
+	// A grammar backend, when built in, handles what it has grammars for
+	if parseWithBackend(filePath, ext, content, skeleton) {
+		linkCalls(string(content), skeleton)
+		skeleton.SkeletonLines = estimateSkeletonLines(skeleton)
+		return skeleton, nil
 	}
 
 	switch ext {
@@ -129,6 +157,12 @@ func ParseFile(filePath string) (*types.CodeSkeleton, error) {
 		skeleton.Language = "rust"
 		parseRust(string(content), skeleton)
 	case ".c", ".h":
+		// Objective-C headers share the .h extension with C
+		if ext == ".h" && isObjCHeader(string(content)) {
+			skeleton.Language = "objc"
+			parseObjC(string(content), skeleton)
+			break
+		}
 		skeleton.Language = "c"
 		parseCpp(string(content), skeleton)
 	case ".cpp", ".cc", ".cxx", ".hpp", ".hxx":
@@ -143,22 +177,68 @@ func ParseFile(filePath string) (*types.CodeSkeleton, error) {
 	case ".swift":
 		skeleton.Language = "swift"
 		parseSwift(string(content), skeleton)
+	case ".m":
+		skeleton.Language = "objc"
+		parseObjC(string(content), skeleton)
+	case ".mm":
+		skeleton.Language = "objcpp"
+		parseObjC(string(content), skeleton)
 	case ".kt", ".kts":
 		skeleton.Language = "kotlin"
 		parseKotlin(string(content), skeleton)
 	case ".scala":
 		skeleton.Language = "scala"
 		parseScala(string(content), skeleton)
+	case ".dart":
+		skeleton.Language = "dart"
+		parseDart(string(content), skeleton)
+	case ".ex", ".exs":
+		skeleton.Language = "elixir"
+		parseElixir(string(content), skeleton)
+	case ".erl", ".hrl":
+		skeleton.Language = "erlang"
+		parseErlang(string(content), skeleton)
+	case ".proto":
+		skeleton.Language = "protobuf"
+		parseProto(string(content), skeleton)
+	case ".tf":
+		skeleton.Language = "terraform"
+		parseTerraform(string(content), skeleton)
+	case ".ipynb":
+		parseNotebook(content, skeleton)
+	case ".vue", ".svelte":
+		parseComponent(filePath, string(content), skeleton)
 	default:
+		// Scripts without an extension, Dockerfile.dev, Makefile.common
 		skeleton.Language = "unknown"
+		if lang := DetectLanguage(filePath, content); lang != "" {
+			skeleton.Language = lang
+			if parse, ok := detectedParsers[lang]; ok {
+				parse(string(content), skeleton)
+			}
+		}
 	}
 
+	// Which functions of the file each function calls
+	linkCalls(string(content), skeleton)
+
 	// Calculate skeleton lines (rough estimate)
 	skeleton.SkeletonLines = estimateSkeletonLines(skeleton)
 
 	return skeleton, nil
 }
 
+// detectedParsers parse files whose language was detected from their name
+// or content rather than their extension
+var detectedParsers = map[string]func(string, *types.CodeSkeleton){
+	"typescript": parseTypeScript,
+	"javascript": parseTypeScript,
+	"python":     parsePython,
+	"ruby":       parseRuby,
+	"php":        parsePHP,
+	"shell":      parseShell,
+}
+
 func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 	lines := strings.Split(content, "\n")
 
@@ -167,25 +247,34 @@ func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 	inClassBody := false
 	braceCount := 0
 
-	pendingDecorators := []string{}
+	var pendingDecorators decoratorList
+	skipTo := -1 // last line of a multi-line decorator already read
 
 	for lineNum, line := range lines {
 		lineNo := lineNum + 1
+		if lineNum <= skipTo {
+			continue
+		}
 
-		// Collect decorators
-		if m := tsDecorator.FindStringSubmatch(line); m != nil {
-			pendingDecorators = append(pendingDecorators, "@"+m[2])
+		// Collect decorators with their arguments, which may span lines:
+		// @Module({ ... })
+		if m := tsDecorator.FindStringSubmatchIndex(line); m != nil {
+			text, end := decoratorText(lines, lineNum, m[3])
+			pendingDecorators.add(text)
+			skipTo = end
 			continue
 		}
 
 		// Class definition
 		if m := tsClass.FindStringSubmatch(line); m != nil {
 			cls := types.ClassSkeleton{
-				Name:       m[4],
-				Line:       lineNo,
-				Extends:    m[5],
-				IsAbstract: m[3] != "",
-				IsExported: m[2] != "",
+				Name:          m[4],
+				Line:          lineNo,
+				Extends:       m[5],
+				IsAbstract:    m[3] != "",
+				IsExported:    m[2] != "",
+				Decorators:    pendingDecorators.names,
+				DecoratorArgs: pendingDecorators.args,
 			}
 			if m[6] != "" {
 				cls.Implements = splitAndTrim(m[6], ",")
@@ -194,7 +283,7 @@ func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 			currentClass = &skeleton.Classes[len(skeleton.Classes)-1]
 			inClassBody = true
 			braceCount = 1
-			pendingDecorators = nil
+			pendingDecorators = decoratorList{}
 			continue
 		}
 
@@ -223,19 +312,20 @@ func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 		// Method (inside class)
 		if currentClass != nil {
 			if m := tsMethod.FindStringSubmatch(line); m != nil {
-				if m[5] != "constructor" && m[5] != "if" && m[5] != "for" && m[5] != "while" {
+				if m[5] != "constructor" && m[5] != "if" && m[5] != "for" && m[5] != "while" && m[5] != "switch" && m[5] != "catch" {
 					method := types.FunctionSig{
-						Name:       m[5],
-						Line:       lineNo,
-						IsPrivate:  strings.Contains(m[2], "private"),
-						IsStatic:   m[3] != "",
-						IsAsync:    m[4] != "",
-						Params:     parseParams(m[7]),
-						ReturnType: strings.TrimSpace(m[8]),
-						Decorators: pendingDecorators,
+						Name:          m[5],
+						Line:          lineNo,
+						IsPrivate:     strings.Contains(m[2], "private"),
+						IsStatic:      m[3] != "",
+						IsAsync:       m[4] != "",
+						Params:        parseParams(m[7]),
+						ReturnType:    strings.TrimSpace(m[8]),
+						Decorators:    pendingDecorators.names,
+						DecoratorArgs: pendingDecorators.args,
 					}
 					currentClass.Methods = append(currentClass.Methods, method)
-					pendingDecorators = nil
+					pendingDecorators = decoratorList{}
 				}
 				continue
 			}
@@ -259,16 +349,17 @@ func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 		// Standalone function
 		if m := tsFunction.FindStringSubmatch(line); m != nil {
 			fn := types.FunctionSig{
-				Name:       m[4],
-				Line:       lineNo,
-				IsExported: m[2] != "",
-				IsAsync:    m[3] != "",
-				Params:     parseParams(m[6]),
-				ReturnType: strings.TrimSpace(m[7]),
-				Decorators: pendingDecorators,
+				Name:          m[4],
+				Line:          lineNo,
+				IsExported:    m[2] != "",
+				IsAsync:       m[3] != "",
+				Params:        parseParams(m[6]),
+				ReturnType:    strings.TrimSpace(m[7]),
+				Decorators:    pendingDecorators.names,
+				DecoratorArgs: pendingDecorators.args,
 			}
 			skeleton.Functions = append(skeleton.Functions, fn)
-			pendingDecorators = nil
+			pendingDecorators = decoratorList{}
 			continue
 		}
 
@@ -318,6 +409,7 @@ func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 				Name:       m[3],
 				Line:       lineNo,
 				IsExported: m[2] != "",
+				Members:    enumMembers(enumBody(lines, lineNum)),
 			}
 			skeleton.Enums = append(skeleton.Enums, enumDef)
 			continue
@@ -325,12 +417,50 @@ func parseTypeScript(content string, skeleton *types.CodeSkeleton) {
 	}
 }
 
-func parseGo(content string, skeleton *types.CodeSkeleton) {
+// parseGoLines is the line-based Go parser, for source go/parser rejects
+func parseGoLines(content string, skeleton *types.CodeSkeleton) {
 	lines := strings.Split(content, "\n")
+	structIdx, structDepth := -1, 0 // the struct whose fields are being read
+	var constBlock *goConstBlock    // the const block being read
 
 	for lineNum, line := range lines {
 		lineNo := lineNum + 1
 
+		// Specs of the open const block
+		if constBlock != nil {
+			if strings.HasPrefix(strings.TrimSpace(line), ")") {
+				constBlock = nil
+			} else if m := goConstSpec.FindStringSubmatch(line); m != nil {
+				names := splitAndTrim(m[1], ",")
+				typ := strings.TrimSpace(m[2])
+				if !constBlock.add(names, lineNo, typ, goIota.MatchString(m[3]), typ == "" && m[3] == "") {
+					for _, name := range names {
+						if name != "_" {
+							skeleton.Constants = append(skeleton.Constants, types.ConstDef{Name: name, Line: lineNo, Type: typ, IsExported: isExportedGo(name)})
+						}
+					}
+				}
+			}
+			continue
+		}
+		if goConstBlockStart.MatchString(line) {
+			constBlock = newGoConstBlock(skeleton)
+			continue
+		}
+
+		// Fields of the open struct; nested struct bodies are skipped
+		if structIdx >= 0 {
+			if structDepth == 1 {
+				if field := parseGoFieldLine(line); len(field) > 0 {
+					skeleton.Types[structIdx].Properties = append(skeleton.Types[structIdx].Properties, field...)
+				}
+			}
+			if structDepth += braceDelta(line); structDepth <= 0 {
+				structIdx = -1
+			}
+			continue
+		}
+
 		// Function/method
 		if m := goFunc.FindStringSubmatch(line); m != nil {
 			fn := types.FunctionSig{
@@ -340,7 +470,11 @@ func parseGo(content string, skeleton *types.CodeSkeleton) {
 				Params:     parseGoParams(m[5]),
 				ReturnType: strings.TrimSpace(m[6] + m[7]),
 			}
-			skeleton.Functions = append(skeleton.Functions, fn)
+			if m[3] != "" {
+				addGoMethod(skeleton, m[3], fn)
+			} else {
+				skeleton.Functions = append(skeleton.Functions, fn)
+			}
 			continue
 		}
 
@@ -356,6 +490,9 @@ func parseGo(content string, skeleton *types.CodeSkeleton) {
 				skeleton.Interfaces = append(skeleton.Interfaces, td)
 			} else {
 				skeleton.Types = append(skeleton.Types, td)
+				if structDepth = braceDelta(line); structDepth > 0 {
+					structIdx = len(skeleton.Types) - 1
+				}
 			}
 			continue
 		}
@@ -371,6 +508,25 @@ func parseGo(content string, skeleton *types.CodeSkeleton) {
 			continue
 		}
 	}
+	linkGoReceivers(skeleton)
+}
+
+// parseGoFieldLine reads one line of a struct body: a, b int is two fields
+func parseGoFieldLine(line string) []types.PropertyDef {
+	if m := goStructField.FindStringSubmatch(line); m != nil {
+		typ := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[2]), "{"))
+		var fields []types.PropertyDef
+		for _, name := range splitAndTrim(m[1], ",") {
+			if name != "_" {
+				fields = append(fields, goField(name, typ, goFieldTag(m[3])))
+			}
+		}
+		return fields
+	}
+	if m := goEmbeddedField.FindStringSubmatch(line); m != nil {
+		return []types.PropertyDef{goField(goEmbeddedName(m[1]), m[1], goFieldTag(m[2]))}
+	}
+	return nil
 }
 
 func parsePython(content string, skeleton *types.CodeSkeleton) {
@@ -506,6 +662,7 @@ func parseJava(content string, skeleton *types.CodeSkeleton) {
 				Name:       m[3],
 				Line:       lineNo,
 				IsExported: strings.Contains(m[2], "public"),
+				Members:    enumMembers(enumBody(lines, lineNum)),
 			})
 			continue
 		}
@@ -624,6 +781,7 @@ func parseCSharp(content string, skeleton *types.CodeSkeleton) {
 				Name:       m[3],
 				Line:       lineNo,
 				IsExported: strings.Contains(m[2], "public"),
+				Members:    enumMembers(enumBody(lines, lineNum)),
 			})
 			continue
 		}
@@ -669,28 +827,108 @@ func parseCSharp(content string, skeleton *types.CodeSkeleton) {
 	}
 }
 
-// parseRust extracts skeleton from Rust files
+// parseRust extracts skeleton from Rust files. Each type with impl blocks
+// becomes a class: inherent and trait impls are merged, the traits go to
+// Implements and the impl fns to Methods.
 func parseRust(content string, skeleton *types.CodeSkeleton) {
 	lines := strings.Split(content, "\n")
 	pendingMacros := []string{}
 
+	implByType := make(map[string]int) // type name -> index in skeleton.Classes
+	currentImpl := -1
+	implDepth := 0
+	implOpen := false
+	depth := 0
+
+	structIdx := -1 // the struct whose fields are being read
+	structDepth := 0
+	var fieldAttrs []string
+
 	for lineNum, line := range lines {
 		lineNo := lineNum + 1
 
+		// Brace depth at the start of the line tells whether the impl is still open
+		before := depth
+		depth += braceDelta(line)
+		if currentImpl >= 0 {
+			if !implOpen && before > implDepth {
+				implOpen = true
+			}
+			if implOpen && before <= implDepth {
+				currentImpl = -1
+			}
+		}
+
+		// Fields of the open struct, with the attributes above each one
+		if structIdx >= 0 && before <= structDepth {
+			structIdx = -1
+		}
+		if structIdx >= 0 {
+			if before == structDepth+1 {
+				trimmed := strings.TrimSpace(line)
+				if strings.HasPrefix(trimmed, "#[") {
+					fieldAttrs = append(fieldAttrs, trimmed)
+					continue
+				}
+				if m := rustField.FindStringSubmatch(line); m != nil {
+					skeleton.Types[structIdx].Properties = append(skeleton.Types[structIdx].Properties, types.PropertyDef{
+						Name:      m[2],
+						Type:      m[3],
+						Tag:       strings.Join(fieldAttrs, " "),
+						IsPrivate: m[1] == "",
+					})
+				}
+				fieldAttrs = nil
+			}
+			continue
+		}
+
 		// Macro attribute
 		if m := rustMacro.FindStringSubmatch(line); m != nil {
 			pendingMacros = append(pendingMacros, "#["+m[2]+"]")
 			continue
 		}
 
-		// Struct
+		// Impl block: impl Type, impl Trait for Type, impl<T> Trait for Type<T>
+		if m := rustImpl.FindStringSubmatch(line); m != nil && currentImpl < 0 {
+			trait, typeName := parseRustImplHeader(m[1])
+			if typeName != "" {
+				idx, ok := implByType[typeName]
+				if !ok {
+					skeleton.Classes = append(skeleton.Classes, types.ClassSkeleton{
+						Name: typeName,
+						Line: lineNo,
+					})
+					idx = len(skeleton.Classes) - 1
+					implByType[typeName] = idx
+				}
+				if trait != "" && !containsString(skeleton.Classes[idx].Implements, trait) {
+					skeleton.Classes[idx].Implements = append(skeleton.Classes[idx].Implements, trait)
+				}
+				currentImpl = idx
+				implDepth = before
+				implOpen = strings.Contains(line, "{")
+			}
+			pendingMacros = nil
+			continue
+		}
+
+		// Struct: tuple fields are read from the line, named ones from the
+		// lines that follow
 		if m := rustStruct.FindStringSubmatch(line); m != nil {
-			skeleton.Types = append(skeleton.Types, types.TypeDef{
+			td := types.TypeDef{
 				Name:       m[3],
 				Line:       lineNo,
 				Kind:       "struct",
 				IsExported: m[2] != "",
-			})
+			}
+			if strings.HasSuffix(m[0], "(") {
+				td.Properties = parseRustTupleFields(line[len(m[0]):])
+			}
+			skeleton.Types = append(skeleton.Types, td)
+			if strings.HasSuffix(m[0], "{") && depth > before {
+				structIdx, structDepth, fieldAttrs = len(skeleton.Types)-1, before, nil
+			}
 			pendingMacros = nil
 			continue
 		}
@@ -701,24 +939,35 @@ func parseRust(content string, skeleton *types.CodeSkeleton) {
 				Name:       m[3],
 				Line:       lineNo,
 				IsExported: m[2] != "",
+				Members:    enumMembers(enumBody(lines, lineNum)),
 			})
 			pendingMacros = nil
 			continue
 		}
 
-		// Trait
+		// Trait, with its supertraits as Extends
 		if m := rustTrait.FindStringSubmatch(line); m != nil {
+			var supertraits []string
+			for _, bound := range splitAndTrim(m[4], "+") {
+				if strings.HasPrefix(bound, "'") || strings.HasPrefix(bound, "?") {
+					continue
+				}
+				if name := rustTypeName(bound); name != "" {
+					supertraits = append(supertraits, name)
+				}
+			}
 			skeleton.Interfaces = append(skeleton.Interfaces, types.TypeDef{
 				Name:       m[3],
 				Line:       lineNo,
 				Kind:       "trait",
 				IsExported: m[2] != "",
+				Extends:    supertraits,
 			})
 			pendingMacros = nil
 			continue
 		}
 
-		// Function
+		// Function, or method when inside an impl block
 		if m := rustFn.FindStringSubmatch(line); m != nil {
 			fn := types.FunctionSig{
 				Name:       m[4],
@@ -729,7 +978,12 @@ func parseRust(content string, skeleton *types.CodeSkeleton) {
 				ReturnType: strings.TrimSpace(m[6]),
 				Decorators: pendingMacros,
 			}
-			skeleton.Functions = append(skeleton.Functions, fn)
+			if currentImpl >= 0 {
+				fn.IsStatic = !strings.Contains(m[5], "self")
+				skeleton.Classes[currentImpl].Methods = append(skeleton.Classes[currentImpl].Methods, fn)
+			} else {
+				skeleton.Functions = append(skeleton.Functions, fn)
+			}
 			pendingMacros = nil
 			continue
 		}
@@ -755,6 +1009,153 @@ func parseRust(content string, skeleton *types.CodeSkeleton) {
 			})
 		}
 	}
+
+	// An impl'd type is exported when its declaration in this file is pub
+	for i := range skeleton.Classes {
+		name := skeleton.Classes[i].Name
+		for _, t := range skeleton.Types {
+			if t.Name == name {
+				skeleton.Classes[i].IsExported = t.IsExported
+			}
+		}
+		for _, e := range skeleton.Enums {
+			if e.Name == name {
+				skeleton.Classes[i].IsExported = e.IsExported
+			}
+		}
+	}
+}
+
+// parseRustTupleFields reads the fields of a tuple struct from what follows
+// its opening parenthesis; they are named by position: 0, 1, ...
+func parseRustTupleFields(rest string) []types.PropertyDef {
+	depth := 1
+	for i, r := range rest {
+		switch r {
+		case '(', '<', '[':
+			depth++
+		case ')', '>', ']':
+			depth--
+		}
+		if depth == 0 {
+			rest = rest[:i]
+			break
+		}
+	}
+	var fields []types.PropertyDef
+	for _, part := range splitTopLevel(rest) {
+		field := types.PropertyDef{Name: strconv.Itoa(len(fields)), Type: part, IsPrivate: true}
+		if m := rustVisibility.FindString(part); m != "" {
+			field.Type, field.IsPrivate = strings.TrimSpace(part[len(m):]), false
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// splitTopLevel splits a list on the commas outside brackets:
+// HashMap<K, V>, u32 is two items
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(', '<', '[', '{':
+			depth++
+		case ')', '>', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	var items []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			items = append(items, p)
+		}
+	}
+	return items
+}
+
+// parseRustImplHeader splits what follows "impl" into the trait (empty for
+// inherent impls) and the implementing type, both without paths or generics
+func parseRustImplHeader(header string) (trait, typeName string) {
+	header = strings.TrimSpace(header)
+	if strings.HasPrefix(header, "<") {
+		header = strings.TrimSpace(header[rustGenericsEnd(header):])
+	}
+	if i := strings.Index(header, "{"); i >= 0 {
+		header = header[:i]
+	}
+	if i := strings.Index(header, " where "); i >= 0 {
+		header = header[:i]
+	}
+	header = strings.TrimSuffix(strings.TrimSpace(header), "where")
+
+	// Split on the " for " outside generic arguments
+	level := 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '<':
+			level++
+		case '>':
+			level--
+		}
+		if level == 0 && strings.HasPrefix(header[i:], " for ") {
+			return rustTypeName(header[:i]), rustTypeName(header[i+5:])
+		}
+	}
+	return "", rustTypeName(header)
+}
+
+// rustTypeName reduces a type path to its name:
+// &'a mut crate::db::Pool<T> -> Pool
+func rustTypeName(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, "&!")
+	if strings.HasPrefix(s, "'") {
+		if i := strings.Index(s, " "); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+	s = strings.TrimPrefix(strings.TrimSpace(s), "mut ")
+	s = strings.TrimPrefix(strings.TrimSpace(s), "dyn ")
+	if i := strings.IndexAny(s, "<("); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "::"); i >= 0 {
+		s = s[i+2:]
+	}
+	return strings.TrimSpace(s)
+}
+
+// rustGenericsEnd returns the index just past the <...> that s starts with
+func rustGenericsEnd(s string) int {
+	level := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '<':
+			level++
+		case '>':
+			level--
+			if level == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// braceDelta counts opening minus closing braces outside line comments
+func braceDelta(line string) int {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.Count(line, "{") - strings.Count(line, "}")
 }
 
 // parseCpp extracts skeleton from C/C++ files
@@ -764,6 +1165,7 @@ func parseCpp(content string, skeleton *types.CodeSkeleton) {
 	var currentClass *types.ClassSkeleton
 	braceCount := 0
 	inClassBody := false
+	access := "private" // class members are private until a specifier says otherwise
 
 	for lineNum, line := range lines {
 		lineNo := lineNum + 1
@@ -789,6 +1191,7 @@ func parseCpp(content string, skeleton *types.CodeSkeleton) {
 			currentClass = &skeleton.Classes[len(skeleton.Classes)-1]
 			inClassBody = true
 			braceCount = 1
+			access = "private"
 			continue
 		}
 
@@ -805,12 +1208,22 @@ func parseCpp(content string, skeleton *types.CodeSkeleton) {
 		// Enum
 		if m := cppEnum.FindStringSubmatch(line); m != nil {
 			skeleton.Enums = append(skeleton.Enums, types.EnumDef{
-				Name: m[2],
-				Line: lineNo,
+				Name:    m[2],
+				Line:    lineNo,
+				Members: enumMembers(enumBody(lines, lineNum)),
 			})
 			continue
 		}
 
+		// Access specifiers directly in the class body; a member may follow
+		// on the same line
+		if currentClass != nil && braceCount == 1 {
+			if loc := cppAccess.FindStringSubmatchIndex(line); loc != nil && !strings.HasPrefix(line[loc[1]:], ":") {
+				access = line[loc[2]:loc[3]]
+				line = line[loc[1]:]
+			}
+		}
+
 		// Track braces
 		if inClassBody {
 			braceCount += strings.Count(line, "{") - strings.Count(line, "}")
@@ -844,7 +1257,7 @@ func parseCpp(content string, skeleton *types.CodeSkeleton) {
 					Params:     parseCppParams(m[5]),
 					ReturnType: m[3],
 					IsStatic:   strings.Contains(line, "static"),
-					IsPrivate:  false, // Would need to track access specifiers
+					IsPrivate:  access == "private",
 				}
 				currentClass.Methods = append(currentClass.Methods, method)
 			}
@@ -1112,6 +1525,7 @@ func parseSwift(content string, skeleton *types.CodeSkeleton) {
 				Name:       m[3],
 				Line:       lineNo,
 				IsExported: strings.Contains(m[2], "public"),
+				Members:    swiftEnumMembers(enumBody(lines, lineNum)),
 			})
 			continue
 		}
@@ -1231,6 +1645,7 @@ func parseKotlin(content string, skeleton *types.CodeSkeleton) {
 				Name:       m[3],
 				Line:       lineNo,
 				IsExported: !strings.Contains(m[2], "private"),
+				Members:    enumMembers(enumBody(lines, lineNum)),
 			})
 			continue
 		}
@@ -1372,6 +1787,30 @@ func parseScala(content string, skeleton *types.CodeSkeleton) {
 	}
 }
 
+// Shell patterns
+var (
+	// name() {, function name {, function name() {
+	shFunction = regexp.MustCompile(`^\s*(?:function\s+([\w:.-]+)\s*(?:\(\s*\))?|([\w:.-]+)\s*\(\s*\))\s*\{?\s*$`)
+)
+
+func parseShell(content string, skeleton *types.CodeSkeleton) {
+	for lineNum, line := range strings.Split(content, "\n") {
+		m := shFunction.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		skeleton.Functions = append(skeleton.Functions, types.FunctionSig{
+			Name:       name,
+			Line:       lineNum + 1,
+			IsExported: true,
+		})
+	}
+}
+
 // Language-specific parameter parsers
 
 func parseJavaParams(paramsStr string) []types.ParamDef {
@@ -1583,10 +2022,10 @@ func parseParams(paramsStr string) []types.ParamDef {
 	}
 
 	var params []types.ParamDef
-	// Simple split - doesn't handle nested generics perfectly
-	parts := strings.Split(paramsStr, ",")
+	// Split on top-level commas - doesn't handle nested generics perfectly
+	parts := splitArgs(paramsStr)
 	for _, p := range parts {
-		p = strings.TrimSpace(p)
+		p = stripParamDecorators(strings.TrimSpace(p))
 		if p == "" {
 			continue
 		}
@@ -1693,6 +2132,20 @@ func splitAndTrim(s, sep string) []string {
 	return result
 }
 
+// oneLine collapses a multi-line type or signature fragment
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func isExportedGo(name string) bool {
 	if len(name) == 0 {
 		return false
@@ -1705,8 +2158,14 @@ func estimateSkeletonLines(skeleton *types.CodeSkeleton) int {
 
 	for _, cls := range skeleton.Classes {
 		lines += 3 // class header + opening/closing brace
+		lines += len(cls.Decorators)
 		lines += len(cls.Properties)
 		lines += len(cls.Methods)
+		for _, m := range cls.Methods {
+			if hasArgDecorators(m) {
+				lines += len(m.Decorators)
+			}
+		}
 		if cls.Constructor != nil {
 			lines++
 		}
@@ -1715,8 +2174,17 @@ func estimateSkeletonLines(skeleton *types.CodeSkeleton) int {
 	lines += len(skeleton.Functions)
 	lines += len(skeleton.Interfaces) * 2
 	lines += len(skeleton.Types)
+	for _, t := range skeleton.Types {
+		if len(t.Properties) > 0 {
+			lines += len(t.Properties) + 1 // fields and the closing brace
+		}
+	}
 	lines += len(skeleton.Enums)
 	lines += len(skeleton.Constants)
+	lines += len(skeleton.Cells)
+	if skeleton.Component != nil {
+		lines += 1 + len(skeleton.Component.Props)
+	}
 
 	return lines
 }
@@ -1727,7 +2195,29 @@ func FormatSkeleton(sk *types.CodeSkeleton) string {
 
 	sb.WriteString("// " + sk.Path + " - SKELETON\n")
 	sb.WriteString("// Language: " + sk.Language + "\n")
-	sb.WriteString("// Original: " + itoa(sk.LineCount) + " lines -> Skeleton: " + itoa(sk.SkeletonLines) + " lines\n\n")
+	sb.WriteString("// Original: " + itoa(sk.LineCount) + " lines -> Skeleton: " + itoa(sk.SkeletonLines) + " lines\n")
+	if sk.Counts != nil {
+		sb.WriteString("// " + compactNote(sk) + "\n")
+	}
+	if len(sk.Cells) > 0 {
+		sb.WriteString("// Cells:\n")
+		for _, c := range sk.Cells {
+			sb.WriteString("//   " + formatCellOutline(c) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+
+	// Single-file component: its props, then what its script declares
+	if c := sk.Component; c != nil {
+		sb.WriteString("component " + c.Name + " (" + c.Framework + ", " + c.Script + ") {\n")
+		for _, p := range c.Props {
+			sb.WriteString("  prop " + fieldSignature(p) + "\n")
+		}
+		if len(c.Emits) > 0 {
+			sb.WriteString("  emits " + strings.Join(c.Emits, ", ") + "\n")
+		}
+		sb.WriteString("}\n")
+	}
 
 	// Interfaces
 	for _, iface := range sk.Interfaces {
@@ -1741,11 +2231,19 @@ func FormatSkeleton(sk *types.CodeSkeleton) string {
 		sb.WriteString(" { ... }\n")
 	}
 
-	// Types
+	// Types; structs with their fields
 	for _, t := range sk.Types {
 		if t.IsExported {
 			sb.WriteString("export ")
 		}
+		if len(t.Properties) > 0 {
+			sb.WriteString("type " + t.Name + " {\n")
+			for _, p := range t.Properties {
+				sb.WriteString("  " + fieldSignature(p) + "\n")
+			}
+			sb.WriteString("}\n")
+			continue
+		}
 		sb.WriteString("type " + t.Name + " = " + t.RawDef + "\n")
 	}
 
@@ -1754,12 +2252,19 @@ func FormatSkeleton(sk *types.CodeSkeleton) string {
 		if e.IsExported {
 			sb.WriteString("export ")
 		}
-		sb.WriteString("enum " + e.Name + " { ... }\n")
+		if len(e.Members) > 0 {
+			sb.WriteString("enum " + e.Name + " { " + strings.Join(e.Members, ", ") + " }\n")
+		} else {
+			sb.WriteString("enum " + e.Name + " { ... }\n")
+		}
 	}
 
 	// Classes
 	for _, cls := range sk.Classes {
 		sb.WriteString("\n")
+		for _, d := range DecoratorCalls(cls.Decorators, cls.DecoratorArgs) {
+			sb.WriteString(d + "\n")
+		}
 		if cls.IsExported {
 			sb.WriteString("export ")
 		}
@@ -1801,6 +2306,13 @@ func FormatSkeleton(sk *types.CodeSkeleton) string {
 
 		// Methods
 		for _, m := range cls.Methods {
+			// Decorators with arguments carry routes, guards and the like;
+			// bare ones (@override) add nothing worth a line
+			if hasArgDecorators(m) {
+				for _, d := range DecoratorCalls(m.Decorators, m.DecoratorArgs) {
+					sb.WriteString("  " + d + "\n")
+				}
+			}
 			sb.WriteString("  ")
 			if m.IsPrivate {
 				sb.WriteString("private ")
@@ -1839,6 +2351,158 @@ func FormatSkeleton(sk *types.CodeSkeleton) string {
 	return sb.String()
 }
 
+// FormatSkeletonMarkdown renders a skeleton as a nested Markdown outline for
+// PR descriptions and docs: the file as a heading, classes as subheadings,
+// members as bullets with signatures. Lines link to path#L<n>, which GitHub
+// and GitLab resolve relative to the document; pass a project-relative path.
+func FormatSkeletonMarkdown(sk *types.CodeSkeleton, path string) string {
+	var sb strings.Builder
+	if path == "" {
+		path = sk.Path
+	}
+	link := func(line int) string {
+		if line <= 0 {
+			return ""
+		}
+		return " ([L" + itoa(line) + "](" + path + "#L" + itoa(line) + "))"
+	}
+
+	sb.WriteString("## `" + path + "`\n\n")
+	sb.WriteString("_" + sk.Language + " · " + itoa(sk.LineCount) + " lines_\n")
+	if sk.Counts != nil {
+		sb.WriteString("\n> " + compactNote(sk) + "\n")
+	}
+
+	if len(sk.Cells) > 0 {
+		sb.WriteString("\n### Cells\n\n")
+		for _, c := range sk.Cells {
+			sb.WriteString("- " + strings.ReplaceAll(formatCellOutline(c), "`", "'") + "\n")
+		}
+	}
+
+	if c := sk.Component; c != nil {
+		sb.WriteString("\n### " + c.Framework + " component `" + c.Name + "`" + link(c.Line) + "\n\n")
+		for _, p := range c.Props {
+			sb.WriteString("- prop `" + strings.ReplaceAll(fieldSignature(p), "`", "'") + "`\n")
+		}
+		if len(c.Emits) > 0 {
+			sb.WriteString("- emits " + strings.Join(c.Emits, ", ") + "\n")
+		}
+	}
+
+	if len(sk.Interfaces) > 0 || len(sk.Types) > 0 || len(sk.Enums) > 0 {
+		sb.WriteString("\n### Types\n\n")
+		for _, iface := range sk.Interfaces {
+			kind := iface.Kind
+			if kind == "" {
+				kind = "interface"
+			}
+			sb.WriteString("- " + kind + " `" + iface.Name + "`")
+			if len(iface.Extends) > 0 {
+				sb.WriteString(" extends `" + strings.Join(iface.Extends, "`, `") + "`")
+			}
+			sb.WriteString(link(iface.Line) + "\n")
+		}
+		for _, t := range sk.Types {
+			kind := t.Kind
+			if kind == "" {
+				kind = "type"
+			}
+			sb.WriteString("- " + kind + " `" + t.Name + "`" + link(t.Line) + "\n")
+			for _, p := range t.Properties {
+				sb.WriteString("  - `" + strings.ReplaceAll(fieldSignature(p), "`", "'") + "`\n")
+			}
+		}
+		for _, e := range sk.Enums {
+			sb.WriteString("- enum `" + e.Name + "`")
+			if len(e.Members) > 0 {
+				sb.WriteString(": " + strings.Join(e.Members, ", "))
+			}
+			sb.WriteString(link(e.Line) + "\n")
+		}
+	}
+
+	for _, cls := range sk.Classes {
+		sb.WriteString("\n### ")
+		if cls.IsAbstract {
+			sb.WriteString("abstract ")
+		}
+		sb.WriteString("class `" + cls.Name + "`")
+		if cls.Extends != "" {
+			sb.WriteString(" extends `" + cls.Extends + "`")
+		}
+		if len(cls.Implements) > 0 {
+			sb.WriteString(" implements `" + strings.Join(cls.Implements, "`, `") + "`")
+		}
+		sb.WriteString(link(cls.Line) + "\n\n")
+
+		for _, p := range cls.Properties {
+			sig := p.Name
+			if p.Type != "" {
+				sig += ": " + p.Type
+			}
+			sb.WriteString("- `" + sig + "`\n")
+		}
+		if cls.Constructor != nil {
+			sb.WriteString("- `constructor(" + formatParams(cls.Constructor.Params) + ")`" + link(cls.Constructor.Line) + "\n")
+		}
+		for _, m := range cls.Methods {
+			sb.WriteString("- `" + markdownSignature(m) + "`" + link(m.Line) + "\n")
+		}
+	}
+
+	if len(sk.Functions) > 0 {
+		sb.WriteString("\n### Functions\n\n")
+		for _, fn := range sk.Functions {
+			sb.WriteString("- `" + markdownSignature(fn) + "`" + link(fn.Line) + "\n")
+		}
+	}
+
+	if len(sk.Constants) > 0 {
+		sb.WriteString("\n### Constants\n\n")
+		for _, c := range sk.Constants {
+			sig := c.Name
+			if c.Type != "" {
+				sig += ": " + c.Type
+			}
+			sb.WriteString("- `" + sig + "`" + link(c.Line) + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// fieldSignature is a struct field with its type and tag
+func fieldSignature(p types.PropertyDef) string {
+	sig := p.Name
+	if p.Type != "" {
+		sig += ": " + p.Type
+	}
+	if p.Tag != "" {
+		sig += " `" + p.Tag + "`"
+	}
+	return sig
+}
+
+// markdownSignature is a one-line signature with its modifiers
+func markdownSignature(fn types.FunctionSig) string {
+	sig := ""
+	if fn.IsPrivate {
+		sig += "private "
+	}
+	if fn.IsStatic {
+		sig += "static "
+	}
+	if fn.IsAsync {
+		sig += "async "
+	}
+	sig += fn.Name + "(" + formatParams(fn.Params) + ")"
+	if fn.ReturnType != "" {
+		sig += ": " + fn.ReturnType
+	}
+	return strings.ReplaceAll(sig, "`", "'")
+}
+
 func formatParams(params []types.ParamDef) string {
 	var parts []string
 	for _, p := range params {
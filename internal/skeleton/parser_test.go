@@ -1,8 +1,11 @@
 package skeleton
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/saeedalam/teamcontext/pkg/types"
@@ -39,6 +42,16 @@ func hasClass(skeleton *types.CodeSkeleton, name string) bool {
 	return false
 }
 
+// Helper to check if skeleton contains a top-level function by name
+func hasFunction(skeleton *types.CodeSkeleton, name string) bool {
+	for _, f := range skeleton.Functions {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper to check if skeleton contains a method by name in any class
 func hasMethod(skeleton *types.CodeSkeleton, methodName string) bool {
 	for _, c := range skeleton.Classes {
@@ -301,6 +314,162 @@ pub trait Repository {
 		len(skeleton.Classes), len(skeleton.Functions), len(skeleton.Interfaces))
 }
 
+func TestRustTraitImpls(t *testing.T) {
+	code := `
+pub struct Pool {
+    size: usize,
+}
+
+pub trait Store: Send + Sync + std::fmt::Debug {
+    fn get(&self, key: &str) -> Option<String>;
+}
+
+impl Pool {
+    pub fn new(size: usize) -> Self {
+        Pool { size }
+    }
+}
+
+impl<T: Clone> crate::db::Store for Pool
+where
+    T: Send,
+{
+    fn get(&self, key: &str) -> Option<String> {
+        if key.is_empty() {
+            return None;
+        }
+        None
+    }
+}
+
+impl Default for Pool {}
+
+pub fn connect() -> Pool {
+    Pool::new(4)
+}
+`
+
+	filePath, cleanup := setupTestFile(t, code, ".rs")
+	defer cleanup()
+
+	skeleton, err := ParseFile(filePath)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	if len(skeleton.Classes) != 1 {
+		t.Fatalf("Expected impl blocks merged into 1 class, got %d", len(skeleton.Classes))
+	}
+	pool := skeleton.Classes[0]
+	if pool.Name != "Pool" || !pool.IsExported {
+		t.Errorf("Expected exported class Pool, got %+v", pool)
+	}
+	if len(pool.Implements) != 2 || pool.Implements[0] != "Store" || pool.Implements[1] != "Default" {
+		t.Errorf("Expected Implements [Store Default], got %v", pool.Implements)
+	}
+	if len(pool.Methods) != 2 || !pool.Methods[0].IsStatic || pool.Methods[1].Name != "get" {
+		t.Errorf("Expected methods new (static) and get, got %+v", pool.Methods)
+	}
+	if len(skeleton.Functions) != 1 || skeleton.Functions[0].Name != "connect" {
+		t.Errorf("Expected free function connect outside impl blocks, got %+v", skeleton.Functions)
+	}
+
+	if !hasInterface(skeleton, "Store") {
+		t.Fatal("Expected trait Store")
+	}
+	extends := skeleton.Interfaces[0].Extends
+	if len(extends) != 3 || extends[2] != "Debug" {
+		t.Errorf("Expected supertraits [Send Sync Debug], got %v", extends)
+	}
+}
+
+func TestRustStructFields(t *testing.T) {
+	code := `
+#[derive(Serialize)]
+pub struct User {
+    pub id: u64,
+    #[serde(rename = "mail")]
+    #[serde(default)]
+    pub(crate) email: String, // normalized
+    roles: HashMap<String, Vec<Role>>,
+    r#type: Kind,
+}
+
+pub struct Meters(pub f64, HashMap<u8, u8>);
+
+impl User {
+    pub fn new(id: u64) -> Self {
+        User { id, email: String::new(), roles: HashMap::new(), r#type: Kind::A }
+    }
+}
+`
+
+	filePath, cleanup := setupTestFile(t, code, ".rs")
+	defer cleanup()
+
+	skeleton, err := ParseFile(filePath)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if len(skeleton.Types) != 2 {
+		t.Fatalf("Expected structs User and Meters, got %+v", skeleton.Types)
+	}
+	wantUser := []types.PropertyDef{
+		{Name: "id", Type: "u64"},
+		{Name: "email", Type: "String", Tag: `#[serde(rename = "mail")] #[serde(default)]`},
+		{Name: "roles", Type: "HashMap<String, Vec<Role>>", IsPrivate: true},
+		{Name: "type", Type: "Kind", IsPrivate: true},
+	}
+	if !reflect.DeepEqual(skeleton.Types[0].Properties, wantUser) {
+		t.Errorf("User fields = %+v\nwant %+v", skeleton.Types[0].Properties, wantUser)
+	}
+	wantMeters := []types.PropertyDef{
+		{Name: "0", Type: "f64"},
+		{Name: "1", Type: "HashMap<u8, u8>", IsPrivate: true},
+	}
+	if !reflect.DeepEqual(skeleton.Types[1].Properties, wantMeters) {
+		t.Errorf("Meters fields = %+v, want %+v", skeleton.Types[1].Properties, wantMeters)
+	}
+	if !hasMethod(skeleton, "new") {
+		t.Error("Expected the impl after the struct to be read")
+	}
+
+	out := FormatSkeleton(skeleton)
+	if !strings.Contains(out, "type User {\n  id: u64\n  email: String `#[serde(rename = \"mail\")] #[serde(default)]`\n") {
+		t.Errorf("Expected User's fields in the skeleton:\n%s", out)
+	}
+}
+
+func TestFormatSkeletonMarkdown(t *testing.T) {
+	sk := &types.CodeSkeleton{
+		Path:      "/abs/src/auth.ts",
+		Language:  "typescript",
+		LineCount: 120,
+		Classes: []types.ClassSkeleton{{
+			Name:       "AuthService",
+			Line:       10,
+			Implements: []string{"Service"},
+			Methods: []types.FunctionSig{
+				{Name: "login", Line: 20, IsAsync: true, Params: []types.ParamDef{{Name: "user", Type: "string"}}, ReturnType: "Promise<Token>"},
+			},
+		}},
+		Functions: []types.FunctionSig{{Name: "hash", Line: 90}},
+	}
+
+	md := FormatSkeletonMarkdown(sk, "src/auth.ts")
+	for _, want := range []string{
+		"## `src/auth.ts`",
+		"### class `AuthService` implements `Service` ([L10](src/auth.ts#L10))",
+		"- `async login(user: string): Promise<Token>` ([L20](src/auth.ts#L20))",
+		"### Functions",
+		"- `hash()` ([L90](src/auth.ts#L90))",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown outline missing %q:\n%s", want, md)
+		}
+	}
+}
+
 // =============================================================================
 // JAVA TESTS
 // =============================================================================
@@ -343,10 +512,1098 @@ public class UserService {
 	}
 }
 
+func TestCppAccessSpecifiers(t *testing.T) {
+	code := `
+class Connection : public Socket {
+    void reset();
+public:
+    explicit Connection(int fd);
+    bool send(const char* data, int len);
+    static Connection* open(int port);
+protected:
+    virtual void onClose();
+private: int retry(int attempts);
+public slots:
+    void reconnect();
+};
+
+int main(int argc, char** argv) {
+    return 0;
+}
+`
+
+	skeleton, err := ParseContent("connection.cpp", []byte(code))
+	if err != nil {
+		t.Fatalf("ParseContent failed: %v", err)
+	}
+	if len(skeleton.Classes) != 1 {
+		t.Fatalf("Expected 1 class, got %d", len(skeleton.Classes))
+	}
+
+	private := make(map[string]bool)
+	for _, m := range skeleton.Classes[0].Methods {
+		private[m.Name] = m.IsPrivate
+	}
+	want := map[string]bool{
+		"reset":      true, // before any specifier, class members are private
+		"Connection": false,
+		"send":       false,
+		"open":       false,
+		"onClose":    false, // protected is visible to subclasses
+		"retry":      true,
+		"reconnect":  false,
+	}
+	if !reflect.DeepEqual(private, want) {
+		t.Errorf("IsPrivate by method = %v, want %v", private, want)
+	}
+}
+
+// =============================================================================
+// GENERATED FILES
+// =============================================================================
+
+func TestGeneratedFileCompaction(t *testing.T) {
+	content := `// Code generated by openapi-generator. DO NOT EDIT.
+
+export interface User {
+  id: string;
+  name: string;
+}
+
+interface internalState {
+  cache: string;
+}
+
+export class UsersApi extends BaseAPI {
+  private basePath: string;
+
+  async getUser(id: string): Promise<User> {
+    return this.request(id);
+  }
+
+  async listUsers(limit: number): Promise<User[]> {
+    return this.request(limit);
+  }
+}
+
+export function createClient(basePath: string): UsersApi {
+  return new UsersApi(basePath);
+}
+
+function helper(x: number): number {
+  return x;
+}
+`
+	filePath, cleanup := setupTestFile(t, content, ".ts")
+	defer cleanup()
+
+	sk, err := ParseFile(filePath)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if !sk.Generated {
+		t.Fatal("Expected the header marker to flag the file as generated")
+	}
+	if !hasMethod(sk, "getUser") {
+		t.Fatal("ParseFile should still return the full skeleton")
+	}
+
+	Compact(sk)
+	if sk.Counts == nil || sk.Counts.Methods != 2 || sk.Counts.Functions != 2 {
+		t.Fatalf("Expected full counts to be kept, got %+v", sk.Counts)
+	}
+	if !hasClass(sk, "UsersApi") || hasMethod(sk, "getUser") {
+		t.Error("Compacted classes should keep their name but no members")
+	}
+	if len(sk.Functions) != 1 || sk.Functions[0].Name != "createClient" || sk.Functions[0].Params != nil {
+		t.Errorf("Expected only the exported function, without params, got %+v", sk.Functions)
+	}
+	if hasInterface(sk, "internalState") {
+		t.Error("Unexported interfaces should be dropped")
+	}
+	if out := FormatSkeleton(sk); !strings.Contains(out, "Generated file: top-level exports only") {
+		t.Errorf("Expected the formatted skeleton to say it was compacted, got:\n%s", out)
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    bool
+	}{
+		{"api/user.pb.go", "package api", true},
+		{"lib/models/user.g.dart", "part of 'user.dart';", true},
+		{"src/__generated__/schema.ts", "export type Query = {}", true},
+		{"app/Form1.Designer.cs", "namespace App {}", true},
+		{"orm/models.go", "// Code generated by sqlc. DO NOT EDIT.\npackage orm", true},
+		{"src/users.ts", "/* @generated */\nexport {}", true},
+		{"src/users.ts", "export class UsersService {}", false},
+		{"src/general/config.go", "package general", false},
+	}
+	for _, tt := range tests {
+		if got := IsGenerated(tt.path, []byte(tt.content)); got != tt.want {
+			t.Errorf("IsGenerated(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"bin/deploy", "#!/bin/bash\nset -e\n", "shell"},
+		{"scripts/migrate", "#!/usr/bin/env python3.11\nimport sys\n", "python"},
+		{"bin/serve", "#!/usr/bin/env -S node --enable-source-maps\n", "javascript"},
+		{"scripts/build", "#!/bin/sh\nmake all\n", "shell"},
+		{"BUILD", "go_library(name = \"x\")\n", "starlark"},
+		{"docker/Dockerfile.dev", "", "dockerfile"},
+		{"Dockerfile-worker", "", "dockerfile"},
+		{"build/Makefile.common", "", "makefile"},
+		{"rules.mk", "", "makefile"},
+		{"Gemfile", "source 'https://rubygems.org'\n", "ruby"},
+		{"images/base", "# syntax=docker/dockerfile:1\nFROM golang:1.22 AS build\n", "dockerfile"},
+		{"include/targets", ".PHONY: test\ntest:\n\tgo test ./...\n", "makefile"},
+		{"tools/run", "# -*- mode: python -*-\nprint(1)\n", "python"},
+		{"tools/check", "echo hi\n# vim: set ft=sh:\n", "shell"},
+		{"public/index", "<?php echo 1;", "php"},
+		{"scripts/imports", "from os import path\n", ""},
+		{"LICENSE", "MIT License\n", ""},
+		{"bin/tool", "\x7fELF\x00\x00#!/bin/sh", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.path, []byte(tt.content)); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseExtensionlessScript(t *testing.T) {
+	code := `#!/usr/bin/env bash
+set -euo pipefail
+
+build() {
+  go build ./...
+}
+
+function deploy {
+  build
+  kubectl apply -f k8s/
+}
+`
+	filePath, cleanup := setupTestFile(t, code, "")
+	defer cleanup()
+
+	skeleton, err := ParseFile(filePath)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if skeleton.Language != "shell" {
+		t.Errorf("Expected language shell, got %q", skeleton.Language)
+	}
+	if !hasFunction(skeleton, "build") || !hasFunction(skeleton, "deploy") {
+		t.Errorf("Expected functions build and deploy, got %+v", skeleton.Functions)
+	}
+
+	python := "#!/usr/bin/env python3\n\ndef main():\n    pass\n"
+	filePath, cleanup = setupTestFile(t, python, "")
+	defer cleanup()
+	skeleton, _ = ParseFile(filePath)
+	if skeleton.Language != "python" || !hasFunction(skeleton, "main") {
+		t.Errorf("Expected python with main, got %s %+v", skeleton.Language, skeleton.Functions)
+	}
+}
+
 // =============================================================================
 // EDGE CASES
 // =============================================================================
 
+func TestJupyterNotebook(t *testing.T) {
+	content := `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Churn model\n", "Loads events and trains."]},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": ["%matplotlib inline\n", "import pandas as pd\n", "from sklearn.linear_model import LogisticRegression"]},
+  {"cell_type": "code", "metadata": {}, "outputs": [{"output_type": "stream", "text": ["def not_code():\n"]}], "source": "def load_events(path):\n    return pd.read_csv(path)\n\nclass ChurnModel:\n    def fit(self, df):\n        pass\n"}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4
+}`
+	path, cleanup := setupTestFile(t, content, ".ipynb")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "jupyter" {
+		t.Errorf("language = %q, want jupyter", sk.Language)
+	}
+	if !hasFunction(sk, "load_events") || !hasClass(sk, "ChurnModel") || hasFunction(sk, "not_code") {
+		t.Errorf("definitions from code cells only expected, got functions %+v classes %+v", sk.Functions, sk.Classes)
+	}
+	if len(sk.Cells) != 3 {
+		t.Fatalf("expected heading and two code cells in the outline, got %+v", sk.Cells)
+	}
+	if sk.Cells[0].Title != "Churn model" || sk.Cells[0].Summary != "Loads events and trains." {
+		t.Errorf("markdown cell = %+v", sk.Cells[0])
+	}
+	if got := strings.Join(sk.Cells[1].Imports, ","); got != "pandas,sklearn.linear_model" {
+		t.Errorf("cell 2 imports = %q", got)
+	}
+	if got := strings.Join(sk.Cells[2].Defines, ","); got != "ChurnModel,load_events" {
+		t.Errorf("cell 3 defines = %q", got)
+	}
+
+	nb, err := ParseNotebook([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseNotebook failed: %v", err)
+	}
+	script := strings.Split(nb.Script(), "\n")
+	cell := nb.Cells[2]
+	if script[cell.Line-2] != "# %% [3]" || script[cell.Line-1] != "def load_events(path):" {
+		t.Errorf("cell 3 does not start at line %d of the script:\n%s", cell.Line, nb.Script())
+	}
+	if !strings.Contains(nb.Script(), "# %matplotlib inline") {
+		t.Error("magics should be commented out in the script")
+	}
+	if out := FormatSkeleton(sk); !strings.Contains(out, "[2] code L2-4: imports pandas, sklearn.linear_model") ||
+		!strings.Contains(out, "[1] # Churn model - Loads events and trains.") {
+		t.Errorf("cell outline missing from skeleton:\n%s", out)
+	}
+}
+
+func TestVueComponent(t *testing.T) {
+	content := `<template>
+  <div class="card" @click="select">{{ title }}</div>
+</template>
+
+<script setup lang="ts">
+import { computed } from 'vue'
+
+interface Props {
+  // Shown in the header
+  title: string
+  count?: number
+  tags: Map<string, number>
+}
+
+const props = withDefaults(defineProps<Props>(), { count: 0 })
+const emit = defineEmits<{
+  (e: 'select', id: number): void
+  (e: 'close'): void
+}>()
+
+function select() {
+  emit('select', props.count)
+}
+</script>
+
+<style scoped>
+.card { color: red; }
+</style>
+`
+	path, cleanup := setupTestFile(t, content, ".vue")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "vue" {
+		t.Errorf("language = %q, want vue", sk.Language)
+	}
+	c := sk.Component
+	if c == nil {
+		t.Fatal("expected a component")
+	}
+	if c.Name != "Test" || c.Script != "typescript" || !c.Setup || c.Line != 5 {
+		t.Errorf("component = %+v", c)
+	}
+	var props []string
+	for _, p := range c.Props {
+		props = append(props, p.Name+":"+p.Type)
+	}
+	if got := strings.Join(props, ","); got != "title:string,count:number,tags:Map<string, number>" {
+		t.Errorf("props = %q", got)
+	}
+	if got := strings.Join(c.Emits, ","); got != "select,close" {
+		t.Errorf("emits = %q", got)
+	}
+	if !hasFunction(sk, "select") || !hasInterface(sk, "Props") {
+		t.Errorf("script declarations missing: functions %+v interfaces %+v", sk.Functions, sk.Interfaces)
+	}
+	for _, fn := range sk.Functions {
+		if fn.Name == "select" && fn.Line != 21 {
+			t.Errorf("select at line %d, want the line in the .vue file, 21", fn.Line)
+		}
+	}
+	if out := FormatSkeleton(sk); !strings.Contains(out, "component Test (vue, typescript) {") || !strings.Contains(out, "prop count: number") {
+		t.Errorf("component missing from skeleton:\n%s", out)
+	}
+
+	// Options API, in JavaScript
+	content = `<template><span>{{ label }}</span></template>
+<script>
+export default {
+  name: 'user-badge',
+  props: {
+    label: String,
+    size: { type: Number, default: 1 },
+  },
+  emits: ['open'],
+  methods: { open() {} },
+}
+</script>
+`
+	path, cleanup = setupTestFile(t, content, ".vue")
+	defer cleanup()
+	sk, err = ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	c = sk.Component
+	if c == nil || c.Name != "UserBadge" || c.Script != "javascript" || c.Setup {
+		t.Fatalf("component = %+v", c)
+	}
+	if len(c.Props) != 2 || c.Props[0].Type != "String" || c.Props[1].Name != "size" || c.Props[1].Type != "Number" {
+		t.Errorf("props = %+v", c.Props)
+	}
+	if strings.Join(c.Emits, ",") != "open" {
+		t.Errorf("emits = %v", c.Emits)
+	}
+}
+
+func TestSvelteComponent(t *testing.T) {
+	// Svelte 4: props are exported lets
+	content := `<script lang="ts">
+  export let name: string;
+  export let count = 0;
+  export function reset() { count = 0 }
+</script>
+
+<h1>Hello {name}</h1>
+`
+	path, cleanup := setupTestFile(t, content, ".svelte")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "svelte" || sk.Component == nil || sk.Component.Script != "typescript" {
+		t.Fatalf("language %q, component %+v", sk.Language, sk.Component)
+	}
+	if got := sk.Component.Props; len(got) != 2 || got[0].Name != "name" || got[0].Type != "string" || got[1].Name != "count" {
+		t.Errorf("props = %+v", got)
+	}
+	if !hasFunction(sk, "reset") {
+		t.Errorf("exported function missing: %+v", sk.Functions)
+	}
+
+	// Svelte 5: props are destructured from $props()
+	content = `<script lang="ts">
+  interface Props { title: string; onclose?: () => void }
+  let { title, onclose = () => {}, ...rest }: Props = $props();
+</script>
+`
+	path, cleanup = setupTestFile(t, content, ".svelte")
+	defer cleanup()
+	sk, err = ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	got := sk.Component.Props
+	if len(got) != 2 || got[0].Name != "title" || got[0].Type != "string" || got[1].Name != "onclose" || got[1].Type != "() => void" {
+		t.Errorf("props = %+v", got)
+	}
+}
+
+func TestComponentTruncated(t *testing.T) {
+	// Files saved mid-edit leave props lists open
+	vue, err := ParseContent("src/Card.vue", []byte("<script>\nexport default { props: ['title', 'size'\n</script>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vue.Component == nil || len(vue.Component.Props) != 2 || vue.Component.Props[1].Name != "size" {
+		t.Errorf("vue component = %+v", vue.Component)
+	}
+	for _, content := range []string{"<script>\nexport default { props: [\n</script>", "<script>\n  let {"} {
+		if _, err := ParseContent("src/Card.svelte", []byte(content)); err != nil {
+			t.Errorf("%q: %v", content, err)
+		}
+		if _, err := ParseContent("src/Card.vue", []byte(content)); err != nil {
+			t.Errorf("%q: %v", content, err)
+		}
+	}
+}
+
+func TestDartFlutter(t *testing.T) {
+	content := `import 'package:flutter/material.dart';
+
+const kPadding = 8.0;
+
+typedef Callback = void Function(int value);
+
+enum Status { active, archived }
+
+void main() => runApp(const MyApp());
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key, required this.title});
+
+  final String title;
+  static const route = '/';
+
+  @override
+  Widget build(BuildContext context) {
+    if (title.isEmpty) {
+      return const SizedBox();
+    }
+    return MaterialApp(home: Text(title));
+  }
+}
+
+class _CounterState extends State<Counter>
+    with SingleTickerProviderStateMixin {
+  int _count = 0;
+
+  Future<void> _load(String id, [int retries = 3]) async {
+    setState(() {
+      _count++;
+    });
+  }
+
+  int get count => _count;
+}
+
+mixin Logging on Object {
+  void log(String message) {}
+}
+`
+	path, cleanup := setupTestFile(t, content, ".dart")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "dart" {
+		t.Errorf("language = %q, want dart", sk.Language)
+	}
+	if len(sk.Classes) != 2 {
+		t.Fatalf("expected 2 classes, got %+v", sk.Classes)
+	}
+
+	app := sk.Classes[0]
+	if app.Name != "MyApp" || app.Extends != "StatelessWidget" || !IsDartWidget(app) {
+		t.Errorf("MyApp = %+v", app)
+	}
+	if app.Constructor == nil || len(app.Constructor.Params) != 2 || app.Constructor.Params[1].Name != "title" {
+		t.Errorf("MyApp constructor = %+v", app.Constructor)
+	}
+	if len(app.Properties) != 2 || app.Properties[0].Name != "title" || app.Properties[0].Type != "String" || !app.Properties[1].IsStatic {
+		t.Errorf("MyApp fields = %+v", app.Properties)
+	}
+	if len(app.Methods) != 1 || app.Methods[0].Name != "build" || app.Methods[0].ReturnType != "Widget" ||
+		len(app.Methods[0].Decorators) != 1 || app.Methods[0].Decorators[0] != "@override" {
+		t.Errorf("MyApp methods = %+v", app.Methods)
+	}
+
+	state := sk.Classes[1]
+	if state.Name != "_CounterState" || state.IsExported || state.Extends != "State<Counter>" ||
+		len(state.Implements) != 1 || state.Implements[0] != "SingleTickerProviderStateMixin" {
+		t.Errorf("_CounterState = %+v", state)
+	}
+	var methods []string
+	for _, m := range state.Methods {
+		methods = append(methods, m.Name)
+	}
+	if strings.Join(methods, ",") != "_load,count" {
+		t.Errorf("_CounterState methods = %v", methods)
+	}
+	load := state.Methods[0]
+	if !load.IsAsync || !load.IsPrivate || len(load.Params) != 2 || !load.Params[1].Optional || load.Params[1].Default != "3" {
+		t.Errorf("_load = %+v", load)
+	}
+
+	if len(sk.Functions) != 1 || sk.Functions[0].Name != "main" {
+		t.Errorf("functions = %+v", sk.Functions)
+	}
+	if len(sk.Enums) != 1 || strings.Join(sk.Enums[0].Members, ",") != "active,archived" {
+		t.Errorf("enums = %+v", sk.Enums)
+	}
+	if len(sk.Constants) != 1 || sk.Constants[0].Name != "kPadding" {
+		t.Errorf("constants = %+v", sk.Constants)
+	}
+	var kinds []string
+	for _, typ := range sk.Types {
+		kinds = append(kinds, typ.Kind+" "+typ.Name)
+	}
+	if strings.Join(kinds, ",") != "typedef Callback,mixin Logging" {
+		t.Errorf("types = %v", kinds)
+	}
+}
+
+func TestDartTruncated(t *testing.T) {
+	// Files saved mid-edit leave parameter lists open
+	for _, content := range []string{"void main(", "c(", "func (", "class A {\n  A(this.x, {", "void f(int a, {int b"} {
+		sk, err := ParseContent("lib/main.dart", []byte(content))
+		if err != nil {
+			t.Fatalf("%q: %v", content, err)
+		}
+		if len(sk.Functions) != 0 {
+			t.Errorf("%q: functions = %+v, want none", content, sk.Functions)
+		}
+	}
+	if params := parseDartParams("int a, {int b"); len(params) != 2 || params[1].Name != "b" || !params[1].Optional {
+		t.Errorf("params of an unclosed named group = %+v", params)
+	}
+}
+
+func TestElixir(t *testing.T) {
+	content := `defmodule MyApp.Accounts.User do
+  @moduledoc """
+  A user.
+
+      def example(x), do: x
+  """
+  use Ecto.Schema
+  import Ecto.Changeset
+
+  @max_name 80
+
+  schema "users" do
+    field :name, :string
+    field :roles, {:array, :string}, default: []
+    belongs_to :org, MyApp.Org
+    timestamps()
+  end
+
+  @doc "Validates a user."
+  @spec changeset(t(), map()) :: Ecto.Changeset.t()
+  def changeset(user, attrs \\ %{}) do
+    user
+    |> cast(attrs, [:name])
+  end
+
+  def active?(%__MODULE__{} = user), do: user.active
+  def active?(_), do: false
+
+  defp normalize(name)
+       when is_binary(name) do
+    String.trim(name)
+  end
+
+  defmodule Query do
+    def by_org(org_id), do: org_id
+  end
+end
+
+defmodule MyApp.Cache do
+  @behaviour MyApp.Store
+  use GenServer
+
+  defstruct [:table, ttl: 60]
+
+  @callback fetch(key :: term()) :: {:ok, term()} | :error
+
+  @impl true
+  def init(opts) do
+    {:ok, opts}
+  end
+end
+
+defprotocol MyApp.Sizeable do
+  def size(data)
+end
+
+defimpl MyApp.Sizeable, for: Map do
+  def size(map), do: map_size(map)
+end
+`
+	path, cleanup := setupTestFile(t, content, ".ex")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "elixir" {
+		t.Errorf("language = %q, want elixir", sk.Language)
+	}
+	var modules []string
+	for _, c := range sk.Classes {
+		modules = append(modules, c.Name)
+	}
+	if strings.Join(modules, ",") != "MyApp.Accounts.User,MyApp.Accounts.User.Query,MyApp.Cache,MyApp.Sizeable,MyApp.Sizeable.Map" {
+		t.Fatalf("modules = %v", modules)
+	}
+
+	user := sk.Classes[0]
+	if strings.Join(user.Implements, ",") != "Ecto.Schema" {
+		t.Errorf("User implements %v", user.Implements)
+	}
+	var methods []string
+	for _, m := range user.Methods {
+		methods = append(methods, m.Name)
+	}
+	if strings.Join(methods, ",") != "changeset,active?,normalize" {
+		t.Errorf("User methods = %v, want one per name and arity", methods)
+	}
+	cs := user.Methods[0]
+	if cs.DocComment != "Validates a user." || cs.ReturnType != "Ecto.Changeset.t()" ||
+		len(cs.Params) != 2 || cs.Params[1].Name != "attrs" || cs.Params[1].Default != "%{}" {
+		t.Errorf("changeset = %+v", cs)
+	}
+	if !user.Methods[2].IsPrivate || user.Methods[0].IsPrivate {
+		t.Errorf("defp should be private: %+v", user.Methods)
+	}
+	var fields []string
+	for _, p := range user.Properties {
+		fields = append(fields, p.Name+":"+p.Type)
+	}
+	if strings.Join(fields, ",") != "name:string,roles:{:array, :string},org:belongs_to MyApp.Org" {
+		t.Errorf("User fields = %v", fields)
+	}
+	if len(sk.Constants) != 1 || sk.Constants[0].Name != "@max_name" || sk.Constants[0].Value != "80" {
+		t.Errorf("constants = %+v", sk.Constants)
+	}
+
+	cache := sk.Classes[2]
+	if strings.Join(cache.Implements, ",") != "MyApp.Store,GenServer" {
+		t.Errorf("Cache implements %v", cache.Implements)
+	}
+	if len(cache.Properties) != 2 || cache.Properties[0].Name != "table" || cache.Properties[1].Name != "ttl" {
+		t.Errorf("Cache struct = %+v", cache.Properties)
+	}
+	if len(cache.Methods) != 1 || len(cache.Methods[0].Decorators) != 1 || cache.Methods[0].Decorators[0] != "@impl true" {
+		t.Errorf("Cache methods = %+v", cache.Methods)
+	}
+	if len(sk.Interfaces) != 1 || sk.Interfaces[0].Kind != "behaviour" || sk.Interfaces[0].Properties[0].Name != "fetch" {
+		t.Errorf("behaviours = %+v", sk.Interfaces)
+	}
+	if !sk.Classes[3].IsAbstract || strings.Join(sk.Classes[4].Implements, ",") != "MyApp.Sizeable" {
+		t.Errorf("protocol = %+v, impl = %+v", sk.Classes[3], sk.Classes[4])
+	}
+}
+
+func TestErlang(t *testing.T) {
+	content := `-module(cache_server).
+-behaviour(gen_server).
+
+-export([start_link/0,
+         get/1]).
+-export([init/1]).
+
+-define(TIMEOUT, 5000).
+
+-record(state, {table :: ets:tid(),
+                ttl = 60 :: integer()}).
+
+-type key() :: binary().
+
+%% @doc Starts the server.
+-spec start_link() -> {ok, pid()}.
+start_link() ->
+    gen_server:start_link({local, ?MODULE}, ?MODULE, [], []).
+
+get(Key) ->
+    gen_server:call(?MODULE, {get, Key}, ?TIMEOUT).
+
+init([]) ->
+    {ok, #state{}}.
+
+lookup(Key, #state{table = T}) when is_binary(Key) ->
+    ets:lookup(T, Key);
+lookup(_, _) ->
+    undefined.
+`
+	path, cleanup := setupTestFile(t, content, ".erl")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "erlang" || len(sk.Classes) != 1 {
+		t.Fatalf("skeleton = %+v", sk)
+	}
+	mod := sk.Classes[0]
+	if mod.Name != "cache_server" || strings.Join(mod.Implements, ",") != "gen_server" {
+		t.Errorf("module = %+v", mod)
+	}
+	var methods []string
+	for _, m := range mod.Methods {
+		methods = append(methods, fmt.Sprintf("%s/%d:%v", m.Name, len(m.Params), m.IsExported))
+	}
+	if strings.Join(methods, ",") != "start_link/0:true,get/1:true,init/1:true,lookup/2:false" {
+		t.Errorf("functions = %v", methods)
+	}
+	if start := mod.Methods[0]; start.ReturnType != "{ok, pid()}" || start.DocComment != "Starts the server." {
+		t.Errorf("start_link = %+v", start)
+	}
+	if len(sk.Types) != 2 || sk.Types[0].Kind != "record" || len(sk.Types[0].Properties) != 2 ||
+		sk.Types[0].Properties[1].Name != "ttl" || sk.Types[0].Properties[1].Type != "integer()" {
+		t.Errorf("types = %+v", sk.Types)
+	}
+	if len(sk.Constants) != 1 || sk.Constants[0].Name != "?TIMEOUT" || sk.Constants[0].Value != "5000" {
+		t.Errorf("constants = %+v", sk.Constants)
+	}
+}
+
+func TestElixirErlangTruncated(t *testing.T) {
+	// Files saved mid-edit leave parameter and struct lists open
+	erl, err := ParseContent("src/a.erl", []byte("-module(a).\nfoo("))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(erl.Classes) != 1 || len(erl.Classes[0].Methods) != 1 || erl.Classes[0].Methods[0].Name != "foo" {
+		t.Errorf("erlang module = %+v", erl.Classes)
+	}
+
+	ex, err := ParseContent("lib/a.ex", []byte("defmodule A do\n  def foo(a, b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Classes) != 1 || len(ex.Classes[0].Methods) != 1 || len(ex.Classes[0].Methods[0].Params) != 2 {
+		t.Errorf("elixir classes = %+v", ex.Classes)
+	}
+	for _, content := range []string{"defmodule A do\n  def foo(", "defmodule A do\n  defstruct ["} {
+		if _, err := ParseContent("lib/a.ex", []byte(content)); err != nil {
+			t.Errorf("%q: %v", content, err)
+		}
+	}
+	if props := exStructFields("[:id, :name"); len(props) != 2 || props[1].Name != "name" {
+		t.Errorf("fields of an unclosed defstruct = %+v", props)
+	}
+}
+
+func TestProto(t *testing.T) {
+	content := `syntax = "proto3";
+
+package billing.v1;
+
+import "google/api/annotations.proto";
+
+/* Payments, charged
+   through the gateway */
+service Payments {
+  // Charges a card.
+  rpc Charge(ChargeRequest) returns (Charge) {
+    option (google.api.http) = { post: "/v1/charges" body: "*" };
+  }
+  rpc WatchCharges(WatchRequest) returns (stream Charge);
+}
+
+message Charge {
+  string id = 1;
+  int64 amount = 2; // in cents
+  repeated string tags = 3;
+  map<string, string> metadata = 4;
+  Status status = 5;
+  oneof source {
+    string card_id = 6;
+    string wallet_id = 7 [(validate.rules).string = {min_len: 1}];
+  }
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_PAID = 1;
+  }
+  message Refund {
+    optional string reason = 1;
+  }
+}
+`
+	path, cleanup := setupTestFile(t, content, ".proto")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "protobuf" || len(sk.Classes) != 1 {
+		t.Fatalf("skeleton = %+v", sk)
+	}
+	svc := sk.Classes[0]
+	if svc.Name != "Payments" || svc.Line != 9 || len(svc.Methods) != 2 {
+		t.Fatalf("service = %+v", svc)
+	}
+	if charge := svc.Methods[0]; charge.Name != "Charge" || charge.Line != 11 || charge.Params[0].Type != "ChargeRequest" ||
+		charge.ReturnType != "Charge" || charge.DocComment != "Charges a card." {
+		t.Errorf("Charge = %+v", charge)
+	}
+	if watch := svc.Methods[1]; watch.Params[0].Type != "WatchRequest" || watch.ReturnType != "stream Charge" {
+		t.Errorf("WatchCharges = %+v", watch)
+	}
+
+	if len(sk.Types) != 2 || sk.Types[0].Name != "Charge" || sk.Types[1].Name != "Charge.Refund" {
+		t.Fatalf("types = %+v", sk.Types)
+	}
+	var fields []string
+	for _, f := range sk.Types[0].Properties {
+		fields = append(fields, f.Name+":"+f.Type+"="+f.Tag)
+	}
+	if strings.Join(fields, ",") != "id:string=1,amount:int64=2,tags:repeated string=3,metadata:map<string, string>=4,status:Status=5,card_id:string=6,wallet_id:string=7" {
+		t.Errorf("fields = %v", fields)
+	}
+	if reason := sk.Types[1].Properties; len(reason) != 1 || reason[0].Type != "optional string" {
+		t.Errorf("refund fields = %+v", reason)
+	}
+	if len(sk.Enums) != 1 || sk.Enums[0].Name != "Charge.Status" || strings.Join(sk.Enums[0].Members, ",") != "STATUS_UNSPECIFIED,STATUS_PAID" {
+		t.Errorf("enums = %+v", sk.Enums)
+	}
+}
+
+func TestTerraform(t *testing.T) {
+	content := `terraform {
+  required_providers {
+    aws = { source = "hashicorp/aws" }
+  }
+}
+
+provider "aws" {
+  region = var.region
+  alias  = "west"
+}
+
+# Access logs, kept for a year
+resource "aws_s3_bucket" "logs" {
+  bucket = "acme-logs-${var.env}" # {not a block}
+  tags = {
+    Team = "platform"
+  }
+  lifecycle_rule {
+    enabled = true
+  }
+  policy = <<EOF
+{ "Version": "2012-10-17" }
+EOF
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+
+module "vpc" {
+  source     = "./modules/vpc"
+  cidr_block = "10.0.0.0/16"
+}
+
+variable "region" {
+  type        = string
+  default     = "eu-west-1"
+  description = "AWS region"
+}
+
+variable "env" { type = string }
+
+output "bucket_arn" {
+  value = aws_s3_bucket.logs.arn
+}
+
+locals {
+  prefix = "acme-${var.env}"
+  subnets = [
+    "a", "b",
+  ]
+}
+`
+	path, cleanup := setupTestFile(t, content, ".tf")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "terraform" || len(sk.Types) != 4 {
+		t.Fatalf("types = %+v", sk.Types)
+	}
+	if provider := sk.Types[0]; provider.Name != "aws.west" || provider.Kind != "provider" || provider.Line != 7 {
+		t.Errorf("provider = %+v", provider)
+	}
+	bucket := sk.Types[1]
+	if bucket.Name != "aws_s3_bucket.logs" || bucket.Kind != "resource" || bucket.Line != 13 {
+		t.Errorf("bucket = %+v", bucket)
+	}
+	want := []types.PropertyDef{
+		{Name: "bucket", Type: `"acme-logs-${var.env}"`},
+		{Name: "tags", Type: "{ ..."},
+		{Name: "lifecycle_rule", Type: "block"},
+		{Name: "policy", Type: "<<EOF ..."},
+	}
+	if !reflect.DeepEqual(bucket.Properties, want) {
+		t.Errorf("bucket properties = %+v", bucket.Properties)
+	}
+	if data := sk.Types[2]; data.Name != "data.aws_ami.ubuntu" || data.Kind != "data" {
+		t.Errorf("data = %+v", data)
+	}
+	if vpc := sk.Types[3]; vpc.Name != "module.vpc" || vpc.RawDef != "./modules/vpc" || len(vpc.Properties) != 1 {
+		t.Errorf("module = %+v", vpc)
+	}
+
+	wantConsts := []types.ConstDef{
+		{Name: "var.region", Line: 35, Type: "string", Value: `"eu-west-1"`, IsExported: true},
+		{Name: "var.env", Line: 41, Type: "string", IsExported: true},
+		{Name: "output.bucket_arn", Line: 43, Value: "aws_s3_bucket.logs.arn", IsExported: true},
+		{Name: "local.prefix", Line: 48, Value: `"acme-${var.env}"`},
+		{Name: "local.subnets", Line: 49, Value: "[ ..."},
+	}
+	if !reflect.DeepEqual(sk.Constants, wantConsts) {
+		t.Errorf("constants = %+v", sk.Constants)
+	}
+}
+
+func TestObjectiveC(t *testing.T) {
+	header := `#import <Foundation/Foundation.h>
+
+NS_ASSUME_NONNULL_BEGIN
+
+typedef NS_ENUM(NSInteger, FeedState) {
+    FeedStateIdle = 0, // nothing loaded
+    FeedStateLoading,
+};
+
+typedef void (^FeedCompletion)(NSArray *items, NSError *_Nullable error);
+
+@protocol FeedDelegate <NSObject>
+@property (nonatomic, readonly) NSString *identifier;
+- (void)feedDidLoad:(id)feed;
+@end
+
+@interface Feed<ObjectType> : NSObject <NSCopying> {
+    NSString *_token;
+}
+@property (nonatomic, weak, nullable) id<FeedDelegate> delegate;
+@property (nonatomic, copy) void (^onError)(NSError *error);
+@property (class, readonly) Feed *shared;
+- (instancetype)initWithURL:(NSURL *)url NS_DESIGNATED_INITIALIZER;
+- (void)loadPage:(NSInteger)page
+      completion:(FeedCompletion)completion;
++ (instancetype)feedWithURL:(NSURL *)url;
+@end
+
+@interface NSString (Feed)
+- (NSString *)feed_slug;
+@end
+
+NS_ASSUME_NONNULL_END
+`
+	path, cleanup := setupTestFile(t, header, ".h")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "objc" {
+		t.Fatalf("language = %q, want objc for an Objective-C header", sk.Language)
+	}
+	if len(sk.Classes) != 2 || sk.Classes[1].Name != "NSString+Feed" {
+		t.Fatalf("classes = %+v", sk.Classes)
+	}
+	feed := sk.Classes[0]
+	if feed.Name != "Feed" || feed.Line != 17 || feed.Extends != "NSObject" || strings.Join(feed.Implements, ",") != "NSCopying" {
+		t.Errorf("Feed = %+v", feed)
+	}
+	wantProps := []types.PropertyDef{
+		{Name: "delegate", Type: "id<FeedDelegate>"},
+		{Name: "onError", Type: "void (^)(NSError *error)"},
+		{Name: "shared", Type: "Feed *", IsReadonly: true, IsStatic: true},
+	}
+	if !reflect.DeepEqual(feed.Properties, wantProps) {
+		t.Errorf("properties = %+v", feed.Properties)
+	}
+	var selectors []string
+	for _, m := range feed.Methods {
+		selectors = append(selectors, m.Name)
+	}
+	if got := strings.Join(selectors, " "); got != "initWithURL: loadPage:completion: feedWithURL:" {
+		t.Errorf("methods = %q", got)
+	}
+	load := feed.Methods[1]
+	if load.Line != 24 || load.ReturnType != "void" || !load.IsExported ||
+		!reflect.DeepEqual(load.Params, []types.ParamDef{{Name: "page", Type: "NSInteger"}, {Name: "completion", Type: "FeedCompletion"}}) {
+		t.Errorf("loadPage:completion: = %+v", load)
+	}
+	if !feed.Methods[2].IsStatic {
+		t.Error("+ methods should be static")
+	}
+	if len(sk.Interfaces) != 1 || sk.Interfaces[0].Kind != "protocol" || sk.Interfaces[0].Properties[0].Name != "identifier" {
+		t.Errorf("protocols = %+v", sk.Interfaces)
+	}
+	if len(sk.Enums) != 1 || strings.Join(sk.Enums[0].Members, ",") != "FeedStateIdle,FeedStateLoading" {
+		t.Errorf("enums = %+v", sk.Enums)
+	}
+	if len(sk.Types) != 1 || sk.Types[0].RawDef != "void (^)(NSArray *items, NSError *_Nullable error)" {
+		t.Errorf("types = %+v", sk.Types)
+	}
+
+	impl := `#import "Feed.h"
+
+NSString *const FeedErrorDomain = @"com.acme.feed; {";
+
+static BOOL FeedIsValid(NSURL *url) {
+    return url != nil;
+}
+
+@interface Feed ()
+@property (nonatomic, strong) NSMutableArray *pages;
+- (void)reset;
+@end
+
+@implementation Feed
+
+- (instancetype)initWithURL:(NSURL *)url {
+    if (self = [super init]) {
+        [self reset];
+    }
+    return self;
+}
+
+- (void)reset {}
+
+- (NSArray<NSString *> *)titlesFrom:(NSInteger)start {
+    return @[];
+}
+
+@end
+`
+	path, cleanup = setupTestFile(t, impl, ".m")
+	defer cleanup()
+	sk, err = ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if sk.Language != "objc" || len(sk.Classes) != 1 {
+		t.Fatalf("implementation = %+v", sk)
+	}
+	cls := sk.Classes[0]
+	if cls.Line != 9 || len(cls.Properties) != 1 || !cls.Properties[0].IsPrivate {
+		t.Errorf("class extension = %+v", cls)
+	}
+	// The extension declares reset; the implementation adds the rest
+	want := []types.FunctionSig{
+		{Name: "reset", Line: 11, ReturnType: "void", IsPrivate: true},
+		{Name: "initWithURL:", Line: 16, Params: []types.ParamDef{{Name: "url", Type: "NSURL *"}}, ReturnType: "instancetype"},
+		{Name: "titlesFrom:", Line: 25, Params: []types.ParamDef{{Name: "start", Type: "NSInteger"}}, ReturnType: "NSArray<NSString *> *"},
+	}
+	if !reflect.DeepEqual(cls.Methods, want) {
+		t.Errorf("methods = %+v", cls.Methods)
+	}
+	if !hasFunction(sk, "FeedIsValid") || len(sk.Constants) != 1 || sk.Constants[0].Value != `@"com.acme.feed; {"` {
+		t.Errorf("functions %+v, constants %+v", sk.Functions, sk.Constants)
+	}
+
+	// Plain C headers stay C
+	path, cleanup = setupTestFile(t, "#include <stdio.h>\nint add(int a, int b);\n", ".h")
+	defer cleanup()
+	if sk, _ := ParseFile(path); sk.Language != "c" {
+		t.Errorf("C header language = %q", sk.Language)
+	}
+}
+
 func TestEmptyFile(t *testing.T) {
 	filePath, cleanup := setupTestFile(t, "", ".ts")
 	defer cleanup()
@@ -463,3 +1720,70 @@ export class UsersService {
 		t.Error("Should have identified UsersService class")
 	}
 }
+
+func TestEnumMembers(t *testing.T) {
+	tests := []struct {
+		ext  string
+		code string
+		want []string
+	}{
+		{".ts", `export enum Status {
+  Active = 'active', // shown to users
+  'on-hold' = 2,
+  /* retired */ Closed,
+}
+`, []string{"Active", "on-hold", "Closed"}},
+		{".java", `public enum Planet {
+    MERCURY(3.303e+23, 2.4397e6),
+    @Deprecated
+    PLUTO(1.3e+22, 1.1e6) {
+        double mass() { return 0; }
+    };
+
+    private final double mass;
+}
+`, []string{"MERCURY", "PLUTO"}},
+		{".cs", `public enum Color
+{
+    [Description("Red, bright")]
+    Red = 1,
+    Green
+}
+`, []string{"Red", "Green"}},
+		{".rs", `pub enum Event<'a> {
+    #[serde(rename = "created")]
+    Created { id: u64, tags: HashMap<String, String> },
+    Renamed(&'a str, String),
+    Deleted,
+}
+`, []string{"Created", "Renamed", "Deleted"}},
+		{".cpp", "enum class Mode : int { Read = 1, Write = 2 };\n", []string{"Read", "Write"}},
+		{".swift", `public enum Shape {
+    case circle(Double), square
+    indirect case group([Shape])
+
+    var sides: Int { 0 }
+}
+`, []string{"circle", "square", "group"}},
+		{".kt", "enum class Level {\n    LOW, HIGH;\n\n    fun up() = HIGH\n}\n", []string{"LOW", "HIGH"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ext, func(t *testing.T) {
+			filePath, cleanup := setupTestFile(t, tt.code, tt.ext)
+			defer cleanup()
+			sk, err := ParseFile(filePath)
+			if err != nil {
+				t.Fatalf("ParseFile: %v", err)
+			}
+			if len(sk.Enums) != 1 {
+				t.Fatalf("enums = %+v, want one", sk.Enums)
+			}
+			if !reflect.DeepEqual(sk.Enums[0].Members, tt.want) {
+				t.Errorf("members = %q, want %q", sk.Enums[0].Members, tt.want)
+			}
+			if want := "enum " + sk.Enums[0].Name + " { " + strings.Join(tt.want, ", ") + " }"; !strings.Contains(FormatSkeleton(sk), want) {
+				t.Errorf("skeleton is missing %q:\n%s", want, FormatSkeleton(sk))
+			}
+		})
+	}
+}
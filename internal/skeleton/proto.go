@@ -0,0 +1,228 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Protocol Buffers patterns, matched against statements: the text up to
+// the ;, { or } ending each one, with comments removed
+var (
+	protoBlock = regexp.MustCompile(`^(message|enum|service|oneof)\s+(\w+)$`)
+	protoRPC   = regexp.MustCompile(`^rpc\s+(\w+)\s*\(\s*(stream\s+)?([\w.]+)\s*\)\s*returns\s*\(\s*(stream\s+)?([\w.]+)\s*\)$`)
+	protoField = regexp.MustCompile(`^(?:(repeated|optional|required)\s+)?(map\s*<[^>]+>|[\w.]+)\s+(\w+)\s*=\s*(\d+)`)
+	protoValue = regexp.MustCompile(`^(\w+)\s*=\s*-?\w+`)
+)
+
+// parseProto extracts services as classes with their RPCs as methods
+// (the request as the parameter, the response as the return type, both
+// marked stream when streamed), messages as types with their fields and
+// field numbers, and enums with their values. Nested messages and enums
+// are named Outer.Inner, as generated code refers to them.
+func parseProto(content string, skeleton *types.CodeSkeleton) {
+	lines := strings.Split(content, "\n")
+
+	type scope struct {
+		kind  string // message, enum, service, oneof, or block for option values and extends
+		name  string // qualified name of a message or enum
+		index int    // in skeleton.Types, Enums or Classes
+	}
+	var stack []scope
+	enclosing := func() *scope {
+		if len(stack) == 0 {
+			return nil
+		}
+		return &stack[len(stack)-1]
+	}
+	// message is the message fields are added to, skipping oneofs
+	message := func() *types.TypeDef {
+		for i := len(stack) - 1; i >= 0; i-- {
+			switch stack[i].kind {
+			case "message":
+				return &skeleton.Types[stack[i].index]
+			case "oneof":
+				continue
+			}
+			return nil
+		}
+		return nil
+	}
+
+	// addField adds a field statement to its message, its type marked
+	// repeated or optional and its field number as the tag
+	addField := func(text string) {
+		msg := message()
+		m := protoField.FindStringSubmatch(text)
+		if msg == nil || m == nil || m[2] == "option" || m[2] == "reserved" || m[2] == "extensions" {
+			return
+		}
+		typ := strings.ReplaceAll(m[2], " ", "")
+		typ = strings.Replace(typ, ",", ", ", 1)
+		if m[1] != "" && m[1] != "required" {
+			typ = m[1] + " " + typ
+		}
+		msg.Properties = append(msg.Properties, types.PropertyDef{Name: m[3], Type: typ, Tag: m[4]})
+	}
+
+	for _, st := range protoStatements(content) {
+		text := oneLine(st.text)
+		switch st.end {
+		case '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+
+		case '{':
+			parent := enclosing()
+			if parent != nil && parent.kind == "block" {
+				stack = append(stack, scope{kind: "block"})
+				continue
+			}
+			if m := protoRPC.FindStringSubmatch(text); m != nil && parent != nil && parent.kind == "service" {
+				addProtoRPC(skeleton, parent.index, m, st.line, lines)
+				stack = append(stack, scope{kind: "block"})
+				continue
+			}
+			m := protoBlock.FindStringSubmatch(text)
+			if m == nil {
+				// An option value, or the options of a field:
+				// string email = 1 [(validate.rules).string = {email: true}];
+				if parent != nil && (parent.kind == "message" || parent.kind == "oneof") {
+					addField(text)
+				}
+				stack = append(stack, scope{kind: "block"})
+				continue
+			}
+			name := m[2]
+			if parent != nil && parent.kind == "message" {
+				name = parent.name + "." + name
+			}
+			switch m[1] {
+			case "message":
+				skeleton.Types = append(skeleton.Types, types.TypeDef{
+					Name:       name,
+					Line:       st.line,
+					Kind:       "message",
+					IsExported: true,
+				})
+				stack = append(stack, scope{kind: "message", name: name, index: len(skeleton.Types) - 1})
+			case "enum":
+				skeleton.Enums = append(skeleton.Enums, types.EnumDef{Name: name, Line: st.line, IsExported: true})
+				stack = append(stack, scope{kind: "enum", name: name, index: len(skeleton.Enums) - 1})
+			case "service":
+				skeleton.Classes = append(skeleton.Classes, types.ClassSkeleton{Name: m[2], Line: st.line, IsExported: true})
+				stack = append(stack, scope{kind: "service", index: len(skeleton.Classes) - 1})
+			case "oneof":
+				stack = append(stack, scope{kind: "oneof"})
+			}
+
+		case ';':
+			parent := enclosing()
+			if parent == nil {
+				continue
+			}
+			switch parent.kind {
+			case "service":
+				if m := protoRPC.FindStringSubmatch(text); m != nil {
+					addProtoRPC(skeleton, parent.index, m, st.line, lines)
+				}
+			case "enum":
+				if m := protoValue.FindStringSubmatch(text); m != nil && m[1] != "option" && m[1] != "reserved" {
+					enum := &skeleton.Enums[parent.index]
+					enum.Members = append(enum.Members, m[1])
+				}
+			case "message", "oneof":
+				addField(text)
+			}
+		}
+	}
+}
+
+// addProtoRPC adds an rpc to the service at index, with the // comments
+// right above it as its doc comment
+func addProtoRPC(skeleton *types.CodeSkeleton, index int, m []string, line int, lines []string) {
+	request, response := m[3], m[5]
+	if m[2] != "" {
+		request = "stream " + request
+	}
+	if m[4] != "" {
+		response = "stream " + response
+	}
+	var doc []string
+	for i := line - 2; i >= 0; i-- {
+		comment := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(comment, "//") {
+			break
+		}
+		doc = append([]string{strings.TrimSpace(strings.TrimLeft(comment, "/"))}, doc...)
+	}
+	service := &skeleton.Classes[index]
+	service.Methods = append(service.Methods, types.FunctionSig{
+		Name:       m[1],
+		Line:       line,
+		Params:     []types.ParamDef{{Name: "request", Type: request}},
+		ReturnType: response,
+		IsExported: true,
+		DocComment: strings.Join(doc, " "),
+	})
+}
+
+// protoStatement is the text of a statement, the line it starts on and
+// the character ending it
+type protoStatement struct {
+	text string
+	line int
+	end  byte
+}
+
+// protoStatements splits a .proto file at the ;, { and } outside strings
+// and comments
+func protoStatements(content string) []protoStatement {
+	var statements []protoStatement
+	var sb strings.Builder
+	line, start := 1, 0
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+		case strings.HasPrefix(content[i:], "//"):
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 4
+			}
+			line += strings.Count(content[i:i+end+4], "\n")
+			i += end + 3
+			sb.WriteByte(' ')
+			continue
+		case c == '"' || c == '\'':
+			if end := quoteEnd(content, i); end > i {
+				if start == 0 {
+					start = line
+				}
+				sb.WriteString(content[i : end+1])
+				i = end
+				continue
+			}
+		case c == ';' || c == '{' || c == '}':
+			statements = append(statements, protoStatement{text: strings.TrimSpace(sb.String()), line: start, end: c})
+			sb.Reset()
+			start = 0
+			continue
+		}
+		if start == 0 && c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			start = line
+		}
+		sb.WriteByte(c)
+	}
+	return statements
+}
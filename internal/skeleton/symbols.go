@@ -0,0 +1,329 @@
+package skeleton
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Symbol is one definition in the symbol index: a class, function,
+// method, interface, type, enum or constant, or an export the indexer
+// recorded for a file no skeleton parser reads
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"` // class, function, method, constructor, interface, type, enum, constant
+	Path      string `json:"path"` // project-relative, forward slashes
+	Line      int    `json:"line"` // 1-based
+	Column    int    `json:"column"`
+	Scope     string `json:"scope,omitempty"` // enclosing class of a method
+	Signature string `json:"signature,omitempty"`
+	Exported  bool   `json:"exported,omitempty"`
+	Language  string `json:"language,omitempty"`
+}
+
+// Symbols flattens a skeleton into its definitions. content, when given,
+// locates each name in its line; otherwise columns are 0. Exports from the
+// file index mark symbols exported, and exports the skeleton does not have
+// are added as they are.
+func Symbols(relPath string, sk *types.CodeSkeleton, content []byte, exports []types.Export) []Symbol {
+	var lines []string
+	if content != nil {
+		lines = strings.Split(string(content), "\n")
+	}
+	var symbols []Symbol
+	add := func(s Symbol) {
+		if s.Name == "" || s.Line <= 0 {
+			return
+		}
+		s.Path = relPath
+		if sk != nil {
+			s.Language = sk.Language
+		}
+		if s.Line <= len(lines) {
+			if i := strings.Index(lines[s.Line-1], s.Name); i >= 0 {
+				s.Column = i
+			}
+		}
+		symbols = append(symbols, s)
+	}
+
+	if sk != nil {
+		for _, cls := range sk.Classes {
+			add(Symbol{Name: cls.Name, Kind: "class", Line: cls.Line, Signature: classSignature(cls), Exported: cls.IsExported})
+			if cls.Constructor != nil {
+				add(Symbol{Name: cls.Constructor.Name, Kind: "constructor", Line: cls.Constructor.Line, Scope: cls.Name, Signature: markdownSignature(*cls.Constructor)})
+			}
+			for _, m := range cls.Methods {
+				add(Symbol{Name: m.Name, Kind: "method", Line: m.Line, Scope: cls.Name, Signature: markdownSignature(m), Exported: m.IsExported || (cls.IsExported && !m.IsPrivate)})
+			}
+		}
+		for _, fn := range sk.Functions {
+			add(Symbol{Name: fn.Name, Kind: "function", Line: fn.Line, Signature: markdownSignature(fn), Exported: fn.IsExported})
+		}
+		for _, t := range sk.Interfaces {
+			add(Symbol{Name: t.Name, Kind: "interface", Line: t.Line, Signature: typeSignature("interface", t), Exported: t.IsExported})
+		}
+		for _, t := range sk.Types {
+			add(Symbol{Name: t.Name, Kind: "type", Line: t.Line, Signature: typeSignature(t.Kind, t), Exported: t.IsExported})
+		}
+		for _, e := range sk.Enums {
+			add(Symbol{Name: e.Name, Kind: "enum", Line: e.Line, Exported: e.IsExported})
+		}
+		for _, c := range sk.Constants {
+			add(Symbol{Name: c.Name, Kind: "constant", Line: c.Line, Exported: c.IsExported})
+		}
+	}
+
+	for _, exp := range exports {
+		found := false
+		for i := range symbols {
+			if symbols[i].Name == exp.Name && symbols[i].Scope == "" {
+				symbols[i].Exported = true
+				found = true
+			}
+		}
+		if !found {
+			add(Symbol{Name: exp.Name, Kind: exportKind(exp.Kind), Line: exp.Line, Exported: true})
+		}
+	}
+	return symbols
+}
+
+// exportKind maps the indexer's export kinds onto symbol kinds
+func exportKind(kind string) string {
+	switch kind {
+	case "struct":
+		return "class"
+	case "const", "variable":
+		return "constant"
+	case "":
+		return "function"
+	}
+	return kind
+}
+
+// ctagsEscape keeps a field on its line: tabs and newlines end ctags fields
+var ctagsEscape = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// FormatCtags renders symbols as a sorted Universal Ctags tags file in the
+// extended format, addressed by line number so it stays valid however the
+// lines around a definition change
+func FormatCtags(symbols []Symbol) string {
+	sorted := make([]Symbol, len(symbols))
+	copy(sorted, symbols)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+
+	var sb strings.Builder
+	sb.WriteString("!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n")
+	sb.WriteString("!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+	sb.WriteString("!_TAG_PROGRAM_NAME\tteamcontext\t//\n")
+	for _, s := range sorted {
+		fmt.Fprintf(&sb, "%s\t%s\t%d;\"\tkind:%s\tline:%d", ctagsEscape.Replace(s.Name), s.Path, s.Line, s.Kind, s.Line)
+		if s.Language != "" {
+			fmt.Fprintf(&sb, "\tlanguage:%s", s.Language)
+		}
+		if s.Scope != "" {
+			fmt.Fprintf(&sb, "\tclass:%s", ctagsEscape.Replace(s.Scope))
+		}
+		if s.Signature != "" {
+			fmt.Fprintf(&sb, "\tsignature:%s", ctagsEscape.Replace(s.Signature))
+		}
+		if !s.Exported {
+			sb.WriteString("\tfile:")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// lsifSymbolKinds are the LSP SymbolKind numbers of each kind
+var lsifSymbolKinds = map[string]int{
+	"class":       5,
+	"method":      6,
+	"constructor": 9,
+	"enum":        10,
+	"interface":   11,
+	"function":    12,
+	"constant":    14,
+	"type":        26,
+}
+
+// lsifWriter numbers and writes the vertices and edges of an LSIF dump
+type lsifWriter struct {
+	sb     strings.Builder
+	nextID int
+	err    error
+}
+
+func (w *lsifWriter) emit(element map[string]interface{}) int {
+	w.nextID++
+	element["id"] = w.nextID
+	data, err := json.Marshal(element)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	w.sb.Write(data)
+	w.sb.WriteString("\n")
+	return w.nextID
+}
+
+func (w *lsifWriter) vertex(label string, fields map[string]interface{}) int {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	fields["type"] = "vertex"
+	fields["label"] = label
+	return w.emit(fields)
+}
+
+func (w *lsifWriter) edge(label string, out int, in ...int) {
+	fields := map[string]interface{}{"type": "edge", "label": label, "outV": out}
+	if label != "contains" {
+		fields["inV"] = in[0]
+	} else {
+		fields["inVs"] = in
+	}
+	w.emit(fields)
+}
+
+// FormatLSIF renders symbols as an LSIF 0.6 dump (one JSON element per
+// line): a document per file with a definition range, hover and document
+// symbol entry per symbol, and an export moniker for exported symbols so
+// other indexes can link to them. root is the absolute project root the
+// paths are relative to.
+func FormatLSIF(root string, symbols []Symbol) (string, error) {
+	byPath := make(map[string][]Symbol)
+	var paths []string
+	for _, s := range symbols {
+		if _, ok := byPath[s.Path]; !ok {
+			paths = append(paths, s.Path)
+		}
+		byPath[s.Path] = append(byPath[s.Path], s)
+	}
+	sort.Strings(paths)
+
+	rootURI := fileURI(root)
+	w := &lsifWriter{}
+	w.vertex("metaData", map[string]interface{}{
+		"version":          "0.6.0",
+		"projectRoot":      rootURI,
+		"positionEncoding": "utf-16",
+		"toolInfo":         map[string]interface{}{"name": "teamcontext"},
+	})
+	project := w.vertex("project", map[string]interface{}{"kind": "multi"})
+	w.vertex("$event", map[string]interface{}{"kind": "begin", "scope": "project", "data": project})
+
+	var documents []int
+	for _, p := range paths {
+		fileSymbols := byPath[p]
+		sort.SliceStable(fileSymbols, func(i, j int) bool { return fileSymbols[i].Line < fileSymbols[j].Line })
+
+		doc := w.vertex("document", map[string]interface{}{
+			"uri":        strings.TrimSuffix(rootURI, "/") + "/" + (&url.URL{Path: p}).EscapedPath(),
+			"languageId": lsifLanguage(fileSymbols[0].Language, p),
+		})
+		documents = append(documents, doc)
+		w.vertex("$event", map[string]interface{}{"kind": "begin", "scope": "document", "data": doc})
+
+		var ranges []int
+		rangeOf := make(map[string]int) // class name -> range, to nest methods
+		var outline []map[string]interface{}
+		children := make(map[int][]map[string]interface{})
+		for _, s := range fileSymbols {
+			pos := map[string]interface{}{"line": s.Line - 1, "character": s.Column}
+			end := map[string]interface{}{"line": s.Line - 1, "character": s.Column + len(s.Name)}
+			rng := w.vertex("range", map[string]interface{}{
+				"start": pos,
+				"end":   end,
+				"tag": map[string]interface{}{
+					"type":      "definition",
+					"text":      s.Name,
+					"kind":      lsifSymbolKinds[s.Kind],
+					"fullRange": map[string]interface{}{"start": pos, "end": end},
+				},
+			})
+			ranges = append(ranges, rng)
+
+			resultSet := w.vertex("resultSet", nil)
+			w.edge("next", rng, resultSet)
+			definition := w.vertex("definitionResult", nil)
+			w.edge("textDocument/definition", resultSet, definition)
+			w.emit(map[string]interface{}{"type": "edge", "label": "item", "outV": definition, "inVs": []int{rng}, "document": doc})
+			if s.Signature != "" {
+				hover := w.vertex("hoverResult", map[string]interface{}{
+					"result": map[string]interface{}{
+						"contents": []map[string]interface{}{{"language": lsifLanguage(s.Language, p), "value": s.Signature}},
+					},
+				})
+				w.edge("textDocument/hover", resultSet, hover)
+			}
+			if s.Exported {
+				identifier := p + ":" + s.Name
+				if s.Scope != "" {
+					identifier = p + ":" + s.Scope + "." + s.Name
+				}
+				moniker := w.vertex("moniker", map[string]interface{}{"scheme": "teamcontext", "identifier": identifier, "kind": "export", "unique": "project"})
+				w.edge("moniker", resultSet, moniker)
+			}
+
+			entry := map[string]interface{}{"id": rng}
+			if s.Scope != "" {
+				if parent, ok := rangeOf[s.Scope]; ok {
+					children[parent] = append(children[parent], entry)
+					continue
+				}
+			}
+			if s.Kind == "class" {
+				rangeOf[s.Name] = rng
+			}
+			outline = append(outline, entry)
+		}
+		for _, entry := range outline {
+			if kids := children[entry["id"].(int)]; len(kids) > 0 {
+				entry["children"] = kids
+			}
+		}
+
+		w.edge("contains", doc, ranges...)
+		symbolResult := w.vertex("documentSymbolResult", map[string]interface{}{"result": outline})
+		w.edge("textDocument/documentSymbol", doc, symbolResult)
+		w.vertex("$event", map[string]interface{}{"kind": "end", "scope": "document", "data": doc})
+	}
+	if len(documents) > 0 {
+		w.edge("contains", project, documents...)
+	}
+	w.vertex("$event", map[string]interface{}{"kind": "end", "scope": "project", "data": project})
+	return w.sb.String(), w.err
+}
+
+// fileURI is the file:// URI of an absolute path, with a trailing slash
+// for directories
+func fileURI(dir string) string {
+	p := strings.ReplaceAll(dir, "\\", "/")
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive letters
+	}
+	return "file://" + (&url.URL{Path: strings.TrimSuffix(p, "/") + "/"}).EscapedPath()
+}
+
+// lsifLanguage is the language identifier of a document, falling back to
+// the file extension for exports of files no skeleton parser reads
+func lsifLanguage(language, file string) string {
+	if language == "" {
+		return strings.TrimPrefix(path.Ext(file), ".")
+	}
+	return language
+}
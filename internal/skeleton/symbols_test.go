@@ -0,0 +1,82 @@
+package skeleton
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestFormatCtagsAndLSIF(t *testing.T) {
+	content := []byte(`export class Invoice {
+  constructor(id: string) {}
+  total(): number {}
+  private round(n: number): number {}
+}
+
+function helper(a: string) {}
+`)
+	sk, err := ParseContent("billing/invoice.ts", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := Symbols("billing/invoice.ts", sk, content, nil)
+	// An export of a file no parser reads
+	symbols = append(symbols, Symbols("db/schema.prisma", nil, nil, []types.Export{{Name: "User", Kind: "struct", Line: 3}})...)
+
+	tags := FormatCtags(symbols)
+	for _, want := range []string{
+		"!_TAG_FILE_SORTED\t1\t",
+		"Invoice\tbilling/invoice.ts\t1;\"\tkind:class\tline:1\tlanguage:typescript\tsignature:export class Invoice\n",
+		"total\tbilling/invoice.ts\t3;\"\tkind:method\tline:3\tlanguage:typescript\tclass:Invoice\tsignature:total(): number\n",
+		"helper\tbilling/invoice.ts\t7;\"\tkind:function\tline:7\tlanguage:typescript\tsignature:helper(a: string)\tfile:\n",
+		"User\tdb/schema.prisma\t3;\"\tkind:class\tline:3\n",
+	} {
+		if !strings.Contains(tags, want) {
+			t.Errorf("tags missing %q:\n%s", want, tags)
+		}
+	}
+	// Sorted by name, after the pseudo tags
+	lines := strings.Split(strings.TrimSpace(tags), "\n")
+	for i := 4; i < len(lines); i++ {
+		if lines[i-1] > lines[i] && !strings.HasPrefix(lines[i-1], "!_") {
+			t.Errorf("tags not sorted: %q before %q", lines[i-1], lines[i])
+		}
+	}
+
+	dump, err := FormatLSIF("/work/app", symbols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[float64]map[string]interface{})
+	var documents, monikers []string
+	for _, line := range strings.Split(strings.TrimSpace(dump), "\n") {
+		var element map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &element); err != nil {
+			t.Fatalf("invalid LSIF line %q: %v", line, err)
+		}
+		ids[element["id"].(float64)] = element
+		switch element["type"].(string) + ":" + element["label"].(string) {
+		case "vertex:document":
+			documents = append(documents, element["uri"].(string))
+		case "vertex:moniker":
+			monikers = append(monikers, element["identifier"].(string))
+		}
+		// Edges point at elements emitted before them
+		for _, key := range []string{"outV", "inV"} {
+			if v, ok := element[key].(float64); ok && ids[v] == nil {
+				t.Errorf("%s of %q refers to unknown id %v", key, line, v)
+			}
+		}
+	}
+	if want := []string{"file:///work/app/billing/invoice.ts", "file:///work/app/db/schema.prisma"}; strings.Join(documents, ",") != strings.Join(want, ",") {
+		t.Errorf("documents = %v, want %v", documents, want)
+	}
+	if want := "billing/invoice.ts:Invoice,billing/invoice.ts:Invoice.total,db/schema.prisma:User"; strings.Join(monikers, ",") != want {
+		t.Errorf("monikers = %v, want %s", monikers, want)
+	}
+	if ids[1]["label"] != "metaData" || ids[1]["projectRoot"] != "file:///work/app/" {
+		t.Errorf("metaData = %v", ids[1])
+	}
+}
@@ -0,0 +1,201 @@
+package skeleton
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Terraform (HCL) patterns, matched against lines with comments removed
+var (
+	tfLabeledBlock = regexp.MustCompile(`^(resource|data)\s+"([^"]+)"\s+"([^"]+)"\s*\{`)
+	tfNamedBlock   = regexp.MustCompile(`^(module|variable|output|provider)\s+"([^"]+)"\s*\{`)
+	tfLocals       = regexp.MustCompile(`^locals\s*\{`)
+	tfAttribute    = regexp.MustCompile(`^([\w-]+)\s*=\s*(.*)$`)
+	tfNestedBlock  = regexp.MustCompile(`^([\w-]+)\s*(?:"[^"]*"\s*)*\{`)
+	tfHeredoc      = regexp.MustCompile(`<<-?\s*"?(\w+)"?\s*$`)
+)
+
+// maxTFValue is the longest attribute expression kept; longer or
+// multi-line ones are cut with "..."
+const maxTFValue = 80
+
+// parseTerraform extracts the blocks of a Terraform file. Resources, data
+// sources, modules and providers are types named by their address
+// (aws_s3_bucket.logs, data.aws_ami.ubuntu, module.vpc) with their
+// arguments and nested blocks as properties; a module's source is its raw
+// definition. Variables, outputs and locals are constants (var.region,
+// output.vpc_id, local.tags) with their type, default or value; variables
+// and outputs are a module's interface, so they are exported.
+func parseTerraform(content string, skeleton *types.CodeSkeleton) {
+	lines := hclLines(content)
+
+	// The top-level block being read; its arguments sit at depth 1
+	var typeDef *types.TypeDef
+	var constant *types.ConstDef
+	inLocals := false
+	depth := 0
+
+	for i, line := range lines {
+		text := strings.TrimSpace(line.text)
+		before := depth
+		depth += line.opens - line.closes
+		if text == "" {
+			continue
+		}
+
+		if before == 0 {
+			typeDef, constant, inLocals = nil, nil, false
+			switch {
+			case tfLabeledBlock.MatchString(text):
+				m := tfLabeledBlock.FindStringSubmatch(text)
+				name := m[2] + "." + m[3]
+				if m[1] == "data" {
+					name = "data." + name
+				}
+				skeleton.Types = append(skeleton.Types, types.TypeDef{Name: name, Line: i + 1, Kind: m[1], IsExported: true})
+				typeDef = &skeleton.Types[len(skeleton.Types)-1]
+			case tfNamedBlock.MatchString(text):
+				m := tfNamedBlock.FindStringSubmatch(text)
+				switch m[1] {
+				case "module", "provider":
+					name := m[2]
+					if m[1] == "module" {
+						name = "module." + name
+					}
+					skeleton.Types = append(skeleton.Types, types.TypeDef{Name: name, Line: i + 1, Kind: m[1], IsExported: true})
+					typeDef = &skeleton.Types[len(skeleton.Types)-1]
+				case "variable", "output":
+					prefix := "var."
+					if m[1] == "output" {
+						prefix = "output."
+					}
+					skeleton.Constants = append(skeleton.Constants, types.ConstDef{Name: prefix + m[2], Line: i + 1, IsExported: true})
+					constant = &skeleton.Constants[len(skeleton.Constants)-1]
+				}
+			case tfLocals.MatchString(text):
+				inLocals = true
+			}
+			// variable "region" { type = string } on one line
+			if open := strings.Index(text, "{"); open >= 0 && depth == 0 {
+				text = strings.TrimSuffix(strings.TrimSpace(text[open+1:]), "}")
+				before = 1
+			} else {
+				continue
+			}
+		}
+		if before != 1 || text == "" {
+			continue
+		}
+
+		// A line of the block's body: an argument or a nested block
+		value := ""
+		name := ""
+		if m := tfAttribute.FindStringSubmatch(text); m != nil {
+			name, value = m[1], tfValue(m[2], depth > 1 || line.heredoc)
+		} else if m := tfNestedBlock.FindStringSubmatch(text); m != nil {
+			name = m[1]
+		} else {
+			continue
+		}
+
+		switch {
+		case inLocals && value != "":
+			skeleton.Constants = append(skeleton.Constants, types.ConstDef{Name: "local." + name, Line: i + 1, Value: value})
+		case typeDef != nil && typeDef.Kind == "module" && name == "source":
+			typeDef.RawDef = strings.Trim(value, `"`)
+		case typeDef != nil && typeDef.Kind == "provider" && name == "alias":
+			typeDef.Name += "." + strings.Trim(value, `"`)
+		case typeDef != nil && value == "":
+			typeDef.Properties = append(typeDef.Properties, types.PropertyDef{Name: name, Type: "block"})
+		case typeDef != nil:
+			typeDef.Properties = append(typeDef.Properties, types.PropertyDef{Name: name, Type: value})
+		case constant != nil && name == "type":
+			constant.Type = value
+		case constant != nil && (name == "default" || name == "value"):
+			constant.Value = value
+		}
+	}
+}
+
+// tfValue shortens an attribute's expression, marking one that continues
+// on later lines (an open brace, bracket or heredoc) with "..."
+func tfValue(expr string, multiline bool) string {
+	expr = strings.TrimSpace(expr)
+	if multiline || strings.HasSuffix(expr, "[") || strings.HasSuffix(expr, "(") {
+		expr += " ..."
+	}
+	if len(expr) > maxTFValue {
+		expr = expr[:maxTFValue-4] + " ..."
+	}
+	return expr
+}
+
+// hclLine is a line of HCL with its comments removed and the braces it
+// opens and closes outside strings counted
+type hclLine struct {
+	text    string
+	opens   int
+	closes  int
+	heredoc bool // starts a heredoc, whose lines are blanked
+}
+
+// hclLines splits HCL into lines without comments, counting braces outside
+// strings, heredocs and comments
+func hclLines(content string) []hclLine {
+	raw := strings.Split(content, "\n")
+	lines := make([]hclLine, len(raw))
+	inComment := false
+	heredoc := ""
+	for i, line := range raw {
+		if heredoc != "" {
+			if strings.TrimSpace(line) == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+		var sb strings.Builder
+		inString := false
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case inComment:
+				if strings.HasPrefix(line[j:], "*/") {
+					inComment = false
+					j++
+				}
+				continue
+			case inString:
+				sb.WriteByte(c)
+				if c == '\\' && j+1 < len(line) {
+					sb.WriteByte(line[j+1])
+					j++
+				} else if c == '"' {
+					inString = false
+				}
+				continue
+			case c == '"':
+				inString = true
+			case c == '#' || strings.HasPrefix(line[j:], "//"):
+				j = len(line)
+				continue
+			case strings.HasPrefix(line[j:], "/*"):
+				inComment = true
+				j++
+				continue
+			case c == '{':
+				lines[i].opens++
+			case c == '}':
+				lines[i].closes++
+			}
+			sb.WriteByte(c)
+		}
+		lines[i].text = sb.String()
+		if m := tfHeredoc.FindStringSubmatch(lines[i].text); m != nil {
+			heredoc = m[1]
+			lines[i].heredoc = true
+		}
+	}
+	return lines
+}
@@ -0,0 +1,705 @@
+//go:build treesitter && cgo
+
+package skeleton
+
+import (
+	"context"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Built with -tags treesitter (and cgo), skeletons of Go, Python,
+// TypeScript/JavaScript and Java come from tree-sitter grammars. Other
+// languages keep the regex parsers.
+func init() {
+	registerBackend(treeSitterBackend{})
+}
+
+type treeSitterBackend struct{}
+
+func (treeSitterBackend) Name() string { return ParserTreeSitter }
+
+// Parse builds the skeleton from the syntax tree. Files with syntax errors
+// are left to the regex parser, which degrades line by line instead.
+func (treeSitterBackend) Parse(path, language string, content []byte, skeleton *types.CodeSkeleton) bool {
+	var lang *sitter.Language
+	switch language {
+	case "go":
+		lang = golang.GetLanguage()
+	case "python":
+		lang = python.GetLanguage()
+	case "typescript":
+		lang = typescript.GetLanguage()
+		if strings.HasSuffix(strings.ToLower(path), ".tsx") {
+			lang = tsx.GetLanguage()
+		}
+	case "javascript":
+		lang = javascript.GetLanguage()
+	case "java":
+		lang = java.GetLanguage()
+	default:
+		return false
+	}
+
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(lang)
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return false
+	}
+	defer tree.Close()
+	root := tree.RootNode()
+	if root.HasError() {
+		return false
+	}
+
+	ts := &tsWalker{src: content, skeleton: skeleton}
+	switch language {
+	case "go":
+		ts.goFile(root)
+	case "python":
+		ts.pythonBlock(root, nil)
+	case "typescript", "javascript":
+		ts.tsProgram(root)
+	case "java":
+		ts.javaBody(root, nil)
+	}
+	return true
+}
+
+// tsWalker fills a skeleton from a syntax tree
+type tsWalker struct {
+	src      []byte
+	skeleton *types.CodeSkeleton
+}
+
+func (w *tsWalker) text(n *sitter.Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.Content(w.src)
+}
+
+func (w *tsWalker) field(n *sitter.Node, name string) string {
+	return w.text(n.ChildByFieldName(name))
+}
+
+func nodeLine(n *sitter.Node) int {
+	return int(n.StartPoint().Row) + 1
+}
+
+func namedChildren(n *sitter.Node) []*sitter.Node {
+	if n == nil {
+		return nil
+	}
+	var nodes []*sitter.Node
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		nodes = append(nodes, n.NamedChild(i))
+	}
+	return nodes
+}
+
+// hasToken reports whether n has an anonymous child such as async or static
+func hasToken(n *sitter.Node, token string) bool {
+	for i := 0; i < int(n.ChildCount()); i++ {
+		if c := n.Child(i); !c.IsNamed() && c.Type() == token {
+			return true
+		}
+	}
+	return false
+}
+
+// addClass lists cls ahead of the nested classes body adds. Members are
+// filled on a copy, since appending nested classes can move the slice.
+func (w *tsWalker) addClass(cls types.ClassSkeleton, body func(*types.ClassSkeleton)) {
+	idx := len(w.skeleton.Classes)
+	w.skeleton.Classes = append(w.skeleton.Classes, cls)
+	body(&cls)
+	w.skeleton.Classes[idx] = cls
+}
+
+// --- Go -------------------------------------------------------------------
+
+func (w *tsWalker) goFile(root *sitter.Node) {
+	for _, n := range namedChildren(root) {
+		switch n.Type() {
+		case "function_declaration", "method_declaration":
+			name := w.field(n, "name")
+			fn := types.FunctionSig{
+				Name:       name,
+				Line:       nodeLine(n),
+				IsExported: isExportedGo(name),
+				Params:     w.goParams(n.ChildByFieldName("parameters")),
+				ReturnType: oneLine(w.field(n, "result")),
+			}
+			if receiver := w.goParams(n.ChildByFieldName("receiver")); len(receiver) > 0 {
+				addGoMethod(w.skeleton, goReceiverType(receiver[0].Type), fn)
+			} else {
+				w.skeleton.Functions = append(w.skeleton.Functions, fn)
+			}
+		case "type_declaration":
+			for _, spec := range namedChildren(n) {
+				if spec.Type() != "type_spec" && spec.Type() != "type_alias" {
+					continue
+				}
+				name := w.field(spec, "name")
+				td := types.TypeDef{Name: name, Line: nodeLine(spec), IsExported: isExportedGo(name)}
+				switch t := spec.ChildByFieldName("type"); t.Type() {
+				case "interface_type":
+					td.Kind = "interface"
+					w.skeleton.Interfaces = append(w.skeleton.Interfaces, td)
+				case "struct_type":
+					td.Kind = "struct"
+					td.Properties = w.goStructFields(t)
+					w.skeleton.Types = append(w.skeleton.Types, td)
+				default:
+					td.Kind = "type"
+					td.RawDef = oneLine(w.text(t))
+					w.skeleton.Types = append(w.skeleton.Types, td)
+				}
+			}
+		case "const_declaration":
+			block := newGoConstBlock(w.skeleton)
+			for _, spec := range namedChildren(n) {
+				if spec.Type() != "const_spec" {
+					continue
+				}
+				var names []string
+				for i := 0; i < int(spec.ChildCount()); i++ {
+					if spec.FieldNameForChild(i) == "name" {
+						names = append(names, w.text(spec.Child(i)))
+					}
+				}
+				typ, value := w.field(spec, "type"), w.field(spec, "value")
+				if block.add(names, nodeLine(spec), typ, goIota.MatchString(value), typ == "" && value == "") {
+					continue
+				}
+				for _, name := range names {
+					if name == "_" {
+						continue
+					}
+					w.skeleton.Constants = append(w.skeleton.Constants, types.ConstDef{
+						Name:       name,
+						Line:       nodeLine(spec),
+						Type:       typ,
+						IsExported: isExportedGo(name),
+					})
+				}
+			}
+		}
+	}
+	linkGoReceivers(w.skeleton)
+}
+
+// goStructFields lists the fields of a struct type with their tags
+func (w *tsWalker) goStructFields(st *sitter.Node) []types.PropertyDef {
+	var fields []types.PropertyDef
+	for _, list := range namedChildren(st) {
+		if list.Type() != "field_declaration_list" {
+			continue
+		}
+		for _, f := range namedChildren(list) {
+			if f.Type() != "field_declaration" {
+				continue
+			}
+			typ := oneLine(w.field(f, "type"))
+			tag := ""
+			if t := f.ChildByFieldName("tag"); t != nil {
+				tag = goFieldTag(w.text(t))
+			}
+			names := 0
+			for _, c := range namedChildren(f) {
+				if c.Type() == "field_identifier" {
+					names++
+					if name := w.text(c); name != "_" {
+						fields = append(fields, goField(name, typ, tag))
+					}
+				}
+			}
+			if names == 0 {
+				if hasToken(f, "*") {
+					typ = "*" + typ // the grammar leaves the pointer out of an embedded type
+				}
+				fields = append(fields, goField(goEmbeddedName(typ), typ, tag))
+			}
+		}
+	}
+	return fields
+}
+
+func (w *tsWalker) goParams(list *sitter.Node) []types.ParamDef {
+	var params []types.ParamDef
+	for _, p := range namedChildren(list) {
+		if p.Type() != "parameter_declaration" && p.Type() != "variadic_parameter_declaration" {
+			continue
+		}
+		typ := oneLine(w.field(p, "type"))
+		if p.Type() == "variadic_parameter_declaration" {
+			typ = "..." + typ
+		}
+		names := 0
+		for _, c := range namedChildren(p) {
+			if c.Type() == "identifier" {
+				params = append(params, types.ParamDef{Name: w.text(c), Type: typ})
+				names++
+			}
+		}
+		if names == 0 {
+			params = append(params, types.ParamDef{Type: typ})
+		}
+	}
+	return params
+}
+
+// --- Python ---------------------------------------------------------------
+
+// pythonBlock reads the definitions in a module or class body. Functions
+// nested in functions are not part of the skeleton.
+func (w *tsWalker) pythonBlock(block *sitter.Node, class *types.ClassSkeleton) {
+	for _, n := range namedChildren(block) {
+		var decorators []string
+		def := n
+		if n.Type() == "decorated_definition" {
+			for _, d := range namedChildren(n) {
+				if d.Type() == "decorator" {
+					decorators = append(decorators, "@"+pythonDecoratorName(w.text(d)))
+				}
+			}
+			def = n.ChildByFieldName("definition")
+		}
+		switch def.Type() {
+		case "class_definition":
+			cls := types.ClassSkeleton{Name: w.field(def, "name"), Line: nodeLine(def)}
+			if bases := namedChildren(def.ChildByFieldName("superclasses")); len(bases) > 0 {
+				cls.Extends = w.text(bases[0])
+			}
+			if class != nil {
+				// Nested classes are listed after their parent, as Parent.Inner
+				cls.Name = class.Name + "." + cls.Name
+			}
+			w.addClass(cls, func(c *types.ClassSkeleton) { w.pythonBlock(def.ChildByFieldName("body"), c) })
+		case "function_definition":
+			fn := types.FunctionSig{
+				Name:       w.field(def, "name"),
+				Line:       nodeLine(def),
+				IsAsync:    hasToken(def, "async"),
+				Params:     w.pythonParams(def.ChildByFieldName("parameters")),
+				ReturnType: oneLine(w.field(def, "return_type")),
+				Decorators: decorators,
+			}
+			fn.IsPrivate = strings.HasPrefix(fn.Name, "_")
+			switch {
+			case class == nil:
+				w.skeleton.Functions = append(w.skeleton.Functions, fn)
+			case fn.Name == "__init__":
+				class.Constructor = &fn
+			default:
+				class.Methods = append(class.Methods, fn)
+			}
+		}
+	}
+}
+
+// pythonDecoratorName turns "@app.get('/x')" into "app.get"
+func pythonDecoratorName(d string) string {
+	d = strings.TrimPrefix(strings.TrimSpace(d), "@")
+	if i := strings.IndexAny(d, "( \n"); i >= 0 {
+		d = d[:i]
+	}
+	return d
+}
+
+func (w *tsWalker) pythonParams(list *sitter.Node) []types.ParamDef {
+	var params []types.ParamDef
+	for _, p := range namedChildren(list) {
+		var param types.ParamDef
+		switch p.Type() {
+		case "identifier":
+			param.Name = w.text(p)
+		case "typed_parameter":
+			for _, c := range namedChildren(p) {
+				if c.Type() == "identifier" || c.Type() == "list_splat_pattern" || c.Type() == "dictionary_splat_pattern" {
+					param.Name = w.text(c)
+					break
+				}
+			}
+			param.Type = oneLine(w.field(p, "type"))
+		case "default_parameter", "typed_default_parameter":
+			param.Name = w.field(p, "name")
+			param.Type = oneLine(w.field(p, "type"))
+			param.Default = oneLine(w.field(p, "value"))
+			param.Optional = true
+		case "list_splat_pattern", "dictionary_splat_pattern":
+			param.Name = w.text(p)
+		default:
+			continue
+		}
+		if param.Name == "self" || param.Name == "cls" {
+			continue
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// --- TypeScript / JavaScript ----------------------------------------------
+
+func (w *tsWalker) tsProgram(root *sitter.Node) {
+	for _, n := range namedChildren(root) {
+		exported := false
+		decl := n
+		if n.Type() == "export_statement" {
+			exported = true
+			decl = n.ChildByFieldName("declaration")
+			if decl == nil {
+				continue
+			}
+		}
+		w.tsDeclaration(decl, exported, w.tsDecorators(n))
+	}
+}
+
+func (w *tsWalker) tsDeclaration(n *sitter.Node, exported bool, decorators decoratorList) {
+	switch n.Type() {
+	case "class_declaration", "abstract_class_declaration", "class":
+		// An exported class's decorators may hang off the export
+		// statement, already collected, or the class itself
+		if exported {
+			for _, c := range namedChildren(n) {
+				if c.Type() == "decorator" {
+					decorators.add(w.text(c))
+				}
+			}
+		}
+		cls := types.ClassSkeleton{
+			Name:          w.field(n, "name"),
+			Line:          nodeLine(n),
+			IsAbstract:    n.Type() == "abstract_class_declaration",
+			IsExported:    exported,
+			Decorators:    decorators.names,
+			DecoratorArgs: decorators.args,
+		}
+		for _, c := range namedChildren(n) {
+			if c.Type() != "class_heritage" {
+				continue
+			}
+			for _, clause := range namedChildren(c) {
+				switch clause.Type() {
+				case "extends_clause":
+					cls.Extends = oneLine(w.field(clause, "value"))
+				case "implements_clause":
+					for _, t := range namedChildren(clause) {
+						cls.Implements = append(cls.Implements, oneLine(w.text(t)))
+					}
+				}
+			}
+		}
+		w.tsClassBody(n.ChildByFieldName("body"), &cls)
+		w.skeleton.Classes = append(w.skeleton.Classes, cls)
+	case "function_declaration", "generator_function_declaration":
+		w.skeleton.Functions = append(w.skeleton.Functions, types.FunctionSig{
+			Name:          w.field(n, "name"),
+			Line:          nodeLine(n),
+			IsExported:    exported,
+			IsAsync:       hasToken(n, "async"),
+			Params:        w.tsParams(n.ChildByFieldName("parameters")),
+			ReturnType:    tsReturnType(w.field(n, "return_type")),
+			Decorators:    decorators.names,
+			DecoratorArgs: decorators.args,
+		})
+	case "lexical_declaration", "variable_declaration":
+		// export const handler = async (req: Request): Promise<void> => {}
+		for _, d := range namedChildren(n) {
+			value := d.ChildByFieldName("value")
+			if d.Type() != "variable_declarator" || value == nil {
+				continue
+			}
+			if value.Type() != "arrow_function" && value.Type() != "function" && value.Type() != "function_expression" {
+				continue
+			}
+			w.skeleton.Functions = append(w.skeleton.Functions, types.FunctionSig{
+				Name:       w.field(d, "name"),
+				Line:       nodeLine(d),
+				IsExported: exported,
+				IsAsync:    hasToken(value, "async"),
+				Params:     w.tsParams(value.ChildByFieldName("parameters")),
+				ReturnType: tsReturnType(w.field(value, "return_type")),
+			})
+		}
+	case "interface_declaration":
+		td := types.TypeDef{Name: w.field(n, "name"), Line: nodeLine(n), Kind: "interface", IsExported: exported}
+		for _, c := range namedChildren(n) {
+			if c.Type() == "extends_type_clause" {
+				for _, t := range namedChildren(c) {
+					td.Extends = append(td.Extends, oneLine(w.text(t)))
+				}
+			}
+		}
+		for _, member := range namedChildren(n.ChildByFieldName("body")) {
+			if member.Type() == "property_signature" {
+				td.Properties = append(td.Properties, types.PropertyDef{
+					Name:       w.field(member, "name"),
+					Type:       tsReturnType(w.field(member, "type")),
+					IsReadonly: hasToken(member, "readonly"),
+				})
+			}
+		}
+		w.skeleton.Interfaces = append(w.skeleton.Interfaces, td)
+	case "type_alias_declaration":
+		w.skeleton.Types = append(w.skeleton.Types, types.TypeDef{
+			Name:       w.field(n, "name"),
+			Line:       nodeLine(n),
+			Kind:       "type",
+			IsExported: exported,
+			RawDef:     oneLine(w.field(n, "value")),
+		})
+	case "enum_declaration":
+		enum := types.EnumDef{Name: w.field(n, "name"), Line: nodeLine(n), IsExported: exported}
+		for _, m := range namedChildren(n.ChildByFieldName("body")) {
+			switch m.Type() {
+			case "property_identifier", "string":
+				enum.Members = append(enum.Members, memberName(w.text(m)))
+			case "enum_assignment":
+				enum.Members = append(enum.Members, memberName(w.field(m, "name")))
+			}
+		}
+		w.skeleton.Enums = append(w.skeleton.Enums, enum)
+	}
+}
+
+func (w *tsWalker) tsClassBody(body *sitter.Node, cls *types.ClassSkeleton) {
+	var pending decoratorList
+	for _, m := range namedChildren(body) {
+		switch m.Type() {
+		case "decorator":
+			// Decorators of a member come before it in the class body
+			pending.add(w.text(m))
+		case "method_definition", "method_signature", "abstract_method_signature":
+			decorators := pending
+			for _, c := range namedChildren(m) {
+				if c.Type() == "decorator" {
+					decorators.add(w.text(c))
+				}
+			}
+			pending = decoratorList{}
+			name := w.field(m, "name")
+			fn := types.FunctionSig{
+				Name:          name,
+				Line:          nodeLine(m),
+				IsPrivate:     w.tsAccessibility(m) == "private" || strings.HasPrefix(name, "#"),
+				IsStatic:      hasToken(m, "static"),
+				IsAsync:       hasToken(m, "async"),
+				Params:        w.tsParams(m.ChildByFieldName("parameters")),
+				ReturnType:    tsReturnType(w.field(m, "return_type")),
+				Decorators:    decorators.names,
+				DecoratorArgs: decorators.args,
+			}
+			if name == "constructor" {
+				cls.Constructor = &fn
+				continue
+			}
+			cls.Methods = append(cls.Methods, fn)
+		case "public_field_definition", "field_definition":
+			pending = decoratorList{}
+			cls.Properties = append(cls.Properties, types.PropertyDef{
+				Name:       w.field(m, "name"),
+				Type:       tsReturnType(w.field(m, "type")),
+				IsPrivate:  w.tsAccessibility(m) == "private",
+				IsReadonly: hasToken(m, "readonly"),
+				IsStatic:   hasToken(m, "static"),
+			})
+		}
+	}
+}
+
+// tsDecorators collects the decorators attached to a node as its children
+func (w *tsWalker) tsDecorators(n *sitter.Node) decoratorList {
+	var decorators decoratorList
+	for _, c := range namedChildren(n) {
+		if c.Type() == "decorator" {
+			decorators.add(w.text(c))
+		}
+	}
+	return decorators
+}
+
+func (w *tsWalker) tsAccessibility(n *sitter.Node) string {
+	for _, c := range namedChildren(n) {
+		if c.Type() == "accessibility_modifier" {
+			return w.text(c)
+		}
+	}
+	return ""
+}
+
+// tsReturnType drops the colon of a type annotation
+func tsReturnType(annotation string) string {
+	return oneLine(strings.TrimPrefix(strings.TrimSpace(annotation), ":"))
+}
+
+func (w *tsWalker) tsParams(list *sitter.Node) []types.ParamDef {
+	var params []types.ParamDef
+	for _, p := range namedChildren(list) {
+		switch p.Type() {
+		case "required_parameter", "optional_parameter":
+			params = append(params, types.ParamDef{
+				Name:     oneLine(w.field(p, "pattern")),
+				Type:     tsReturnType(w.field(p, "type")),
+				Optional: p.Type() == "optional_parameter",
+				Default:  oneLine(w.field(p, "value")),
+			})
+		case "identifier":
+			params = append(params, types.ParamDef{Name: w.text(p)})
+		case "assignment_pattern":
+			params = append(params, types.ParamDef{Name: w.field(p, "left"), Default: oneLine(w.field(p, "right")), Optional: true})
+		case "rest_pattern", "object_pattern", "array_pattern":
+			params = append(params, types.ParamDef{Name: oneLine(w.text(p))})
+		}
+	}
+	return params
+}
+
+// --- Java -----------------------------------------------------------------
+
+// javaBody reads the types declared in a file or class body; members of
+// class go to it, nested types are listed as Outer.Inner
+func (w *tsWalker) javaBody(body *sitter.Node, class *types.ClassSkeleton) {
+	for _, n := range namedChildren(body) {
+		switch n.Type() {
+		case "class_declaration", "record_declaration":
+			modifiers := w.javaModifiers(n)
+			cls := types.ClassSkeleton{
+				Name:       w.javaNestedName(class, w.field(n, "name")),
+				Line:       nodeLine(n),
+				IsAbstract: strings.Contains(modifiers, "abstract"),
+				IsExported: strings.Contains(modifiers, "public"),
+			}
+			if sc := n.ChildByFieldName("superclass"); sc != nil {
+				if t := namedChildren(sc); len(t) > 0 {
+					cls.Extends = oneLine(w.text(t[0]))
+				}
+			}
+			if si := n.ChildByFieldName("interfaces"); si != nil {
+				for _, list := range namedChildren(si) {
+					for _, t := range namedChildren(list) {
+						cls.Implements = append(cls.Implements, oneLine(w.text(t)))
+					}
+				}
+			}
+			w.addClass(cls, func(c *types.ClassSkeleton) { w.javaBody(n.ChildByFieldName("body"), c) })
+		case "interface_declaration":
+			td := types.TypeDef{
+				Name:       w.javaNestedName(class, w.field(n, "name")),
+				Line:       nodeLine(n),
+				Kind:       "interface",
+				IsExported: strings.Contains(w.javaModifiers(n), "public"),
+			}
+			w.skeleton.Interfaces = append(w.skeleton.Interfaces, td)
+		case "enum_declaration":
+			enum := types.EnumDef{
+				Name:       w.javaNestedName(class, w.field(n, "name")),
+				Line:       nodeLine(n),
+				IsExported: strings.Contains(w.javaModifiers(n), "public"),
+			}
+			for _, c := range namedChildren(n.ChildByFieldName("body")) {
+				if c.Type() == "enum_constant" {
+					enum.Members = append(enum.Members, w.field(c, "name"))
+				}
+			}
+			w.skeleton.Enums = append(w.skeleton.Enums, enum)
+		case "method_declaration", "constructor_declaration":
+			if class == nil {
+				continue
+			}
+			modifiers := w.javaModifiers(n)
+			fn := types.FunctionSig{
+				Name:       w.field(n, "name"),
+				Line:       nodeLine(n),
+				IsStatic:   strings.Contains(modifiers, "static"),
+				IsPrivate:  strings.Contains(modifiers, "private"),
+				Params:     w.javaParams(n.ChildByFieldName("parameters")),
+				ReturnType: oneLine(w.field(n, "type")),
+				Decorators: w.javaAnnotations(n),
+			}
+			if n.Type() == "constructor_declaration" {
+				class.Constructor = &fn
+				continue
+			}
+			class.Methods = append(class.Methods, fn)
+		case "field_declaration":
+			if class == nil {
+				continue
+			}
+			modifiers := w.javaModifiers(n)
+			typ := oneLine(w.field(n, "type"))
+			for _, d := range namedChildren(n) {
+				if d.Type() != "variable_declarator" {
+					continue
+				}
+				class.Properties = append(class.Properties, types.PropertyDef{
+					Name:       w.field(d, "name"),
+					Type:       typ,
+					IsPrivate:  strings.Contains(modifiers, "private"),
+					IsReadonly: strings.Contains(modifiers, "final"),
+					IsStatic:   strings.Contains(modifiers, "static"),
+				})
+			}
+		}
+	}
+}
+
+func (w *tsWalker) javaNestedName(class *types.ClassSkeleton, name string) string {
+	if class == nil {
+		return name
+	}
+	return class.Name + "." + name
+}
+
+func (w *tsWalker) javaModifiers(n *sitter.Node) string {
+	for _, c := range namedChildren(n) {
+		if c.Type() == "modifiers" {
+			return w.text(c)
+		}
+	}
+	return ""
+}
+
+// javaAnnotations lists a declaration's annotations as "@Name"
+func (w *tsWalker) javaAnnotations(n *sitter.Node) []string {
+	var annotations []string
+	for _, c := range namedChildren(n) {
+		if c.Type() != "modifiers" {
+			continue
+		}
+		for _, a := range namedChildren(c) {
+			if a.Type() == "annotation" || a.Type() == "marker_annotation" {
+				annotations = append(annotations, "@"+w.field(a, "name"))
+			}
+		}
+	}
+	return annotations
+}
+
+func (w *tsWalker) javaParams(list *sitter.Node) []types.ParamDef {
+	var params []types.ParamDef
+	for _, p := range namedChildren(list) {
+		if p.Type() != "formal_parameter" && p.Type() != "spread_parameter" {
+			continue
+		}
+		params = append(params, types.ParamDef{
+			Name: w.field(p, "name"),
+			Type: oneLine(w.field(p, "type")),
+		})
+	}
+	return params
+}
@@ -0,0 +1,196 @@
+//go:build treesitter && cgo
+
+package skeleton
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestTreeSitterMultiLineSignatures(t *testing.T) {
+	defer SetParser(ParserAuto)
+	if err := SetParser(ParserTreeSitter); err != nil {
+		t.Fatalf("SetParser: %v", err)
+	}
+
+	content := `import { Injectable } from '@nestjs/common';
+
+export interface User {
+  id: string;
+  readonly email: string;
+}
+
+export type UserId = string | number;
+
+export enum Role { Admin, Member = 'member' }
+
+@Injectable()
+export class UsersService extends BaseService implements OnInit {
+  private cache: Map<string, User>;
+
+  constructor(
+    private readonly repo: UserRepository,
+  ) {
+    super();
+  }
+
+  @Get(':id')
+  async findOne(
+    id: string,
+    opts?: FindOptions,
+  ): Promise<User> {
+    return this.repo.find(id);
+  }
+}
+
+export const handler = async (req: Request): Promise<void> => {};
+`
+	path, cleanup := setupTestFile(t, content, ".ts")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(sk.Classes) != 1 {
+		t.Fatalf("classes = %+v, want UsersService", sk.Classes)
+	}
+	cls := sk.Classes[0]
+	if cls.Name != "UsersService" || cls.Extends != "BaseService" || len(cls.Implements) != 1 || !cls.IsExported {
+		t.Errorf("class = %+v", cls)
+	}
+	if cls.Constructor == nil || len(cls.Constructor.Params) != 1 {
+		t.Errorf("constructor = %+v, want one param", cls.Constructor)
+	}
+	if len(cls.Methods) != 1 {
+		t.Fatalf("methods = %+v, want findOne", cls.Methods)
+	}
+	m := cls.Methods[0]
+	if m.Name != "findOne" || !m.IsAsync || m.ReturnType != "Promise<User>" || len(m.Params) != 2 || !m.Params[1].Optional {
+		t.Errorf("findOne = %+v", m)
+	}
+	if len(m.Decorators) != 1 || m.Decorators[0] != "@Get" {
+		t.Errorf("findOne decorators = %v, want [@Get]", m.Decorators)
+	}
+	if len(sk.Interfaces) != 1 || len(sk.Interfaces[0].Properties) != 2 {
+		t.Errorf("interfaces = %+v", sk.Interfaces)
+	}
+	if len(sk.Types) != 1 || sk.Types[0].RawDef != "string | number" {
+		t.Errorf("types = %+v", sk.Types)
+	}
+	if len(sk.Enums) != 1 || len(sk.Enums[0].Members) != 2 {
+		t.Errorf("enums = %+v", sk.Enums)
+	}
+	if !hasFunction(sk, "handler") {
+		t.Errorf("functions = %+v, want handler", sk.Functions)
+	}
+}
+
+func TestTreeSitterNestedClasses(t *testing.T) {
+	defer SetParser(ParserAuto)
+	if err := SetParser(ParserTreeSitter); err != nil {
+		t.Fatalf("SetParser: %v", err)
+	}
+
+	content := `class Outer(Base):
+    class Meta:
+        ordering = ["id"]
+
+    def __init__(self, name: str):
+        self.name = name
+
+    @property
+    def label(self) -> str:
+        return self.name
+
+async def fetch(url, retries=3):
+    pass
+`
+	path, cleanup := setupTestFile(t, content, ".py")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(sk.Classes) != 2 || sk.Classes[0].Name != "Outer" || sk.Classes[1].Name != "Outer.Meta" {
+		t.Fatalf("classes = %+v, want Outer then Outer.Meta", sk.Classes)
+	}
+	outer := sk.Classes[0]
+	if outer.Constructor == nil || len(outer.Constructor.Params) != 1 {
+		t.Errorf("constructor = %+v", outer.Constructor)
+	}
+	if len(outer.Methods) != 1 || outer.Methods[0].Decorators[0] != "@property" {
+		t.Errorf("methods = %+v", outer.Methods)
+	}
+	if len(sk.Functions) != 1 || !sk.Functions[0].IsAsync || !sk.Functions[0].Params[1].Optional {
+		t.Errorf("functions = %+v", sk.Functions)
+	}
+}
+
+// Source tree-sitter cannot parse is left to the regex parser
+func TestTreeSitterFallsBackOnSyntaxErrors(t *testing.T) {
+	defer SetParser(ParserAuto)
+	if err := SetParser(ParserTreeSitter); err != nil {
+		t.Fatalf("SetParser: %v", err)
+	}
+
+	path, cleanup := setupTestFile(t, "package x\n\nfunc Broken( {\n\nfunc Fine() {}\n", ".go")
+	defer cleanup()
+
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if !hasFunction(sk, "Fine") {
+		t.Errorf("functions = %+v, want Fine from the regex parser", sk.Functions)
+	}
+}
+
+func TestTreeSitterGoStructFields(t *testing.T) {
+	defer SetParser(ParserAuto)
+	if err := SetParser(ParserTreeSitter); err != nil {
+		t.Fatalf("SetParser: %v", err)
+	}
+
+	content := "package users\n\ntype User struct {\n\tbase.Model\n\t*Audit `json:\"-\"`\n\tID, email string `json:\"id\"`\n}\n"
+	path, cleanup := setupTestFile(t, content, ".go")
+	defer cleanup()
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	want := []types.PropertyDef{
+		{Name: "Model", Type: "base.Model"},
+		{Name: "Audit", Type: "*Audit", Tag: `json:"-"`},
+		{Name: "ID", Type: "string", Tag: `json:"id"`},
+		{Name: "email", Type: "string", Tag: `json:"id"`, IsPrivate: true},
+	}
+	if len(sk.Types) != 1 || !reflect.DeepEqual(sk.Types[0].Properties, want) {
+		t.Errorf("types = %+v, want User with fields %+v", sk.Types, want)
+	}
+}
+
+func TestTreeSitterGoIotaEnums(t *testing.T) {
+	defer SetParser(ParserAuto)
+	if err := SetParser(ParserTreeSitter); err != nil {
+		t.Fatalf("SetParser: %v", err)
+	}
+
+	content := "package orders\n\nconst (\n\tPending Status = iota\n\tPaid\n\t_\n\tLimit = 3\n)\n"
+	path, cleanup := setupTestFile(t, content, ".go")
+	defer cleanup()
+	sk, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	want := []types.EnumDef{{Name: "Status", Line: 4, IsExported: true, Members: []string{"Pending", "Paid"}}}
+	if !reflect.DeepEqual(sk.Enums, want) {
+		t.Errorf("enums = %+v, want %+v", sk.Enums, want)
+	}
+	if len(sk.Constants) != 1 || sk.Constants[0].Name != "Limit" {
+		t.Errorf("constants = %+v, want Limit", sk.Constants)
+	}
+}
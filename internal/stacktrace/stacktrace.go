@@ -0,0 +1,163 @@
+// Package stacktrace extracts frames from stack traces and error messages
+// of common runtimes (Go, Python, Node, JVM, .NET, Ruby, Rust) so they can
+// be mapped to files in the index.
+package stacktrace
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Frame is one location in a trace
+type Frame struct {
+	File     string `json:"file"` // path as printed, or a package path for JVM frames
+	Line     int    `json:"line,omitempty"`
+	Function string `json:"function,omitempty"`
+	JVMClass bool   `json:"-"` // File was derived from a class name and only its suffix is known
+}
+
+// Trace is a parsed stack trace. Frames are ordered innermost first, so
+// Frames[0] is where the error was raised.
+type Trace struct {
+	ErrorType string  `json:"error_type,omitempty"`
+	Message   string  `json:"message,omitempty"`
+	Frames    []Frame `json:"frames"`
+}
+
+var (
+	// Python: File "app/models.py", line 42, in save
+	pythonFrameRe = regexp.MustCompile(`File "([^"]+)", line (\d+)(?:, in (\S+))?`)
+
+	// JVM: at com.acme.billing.Invoice.total(Invoice.java:42), optionally
+	// with a module prefix (java.base/...)
+	jvmFrameRe = regexp.MustCompile(`at\s+(?:[\w.$-]+/)?([\w$.]+)\.([\w$<>]+)\(([\w$]+\.(?:java|kt|scala|groovy)):(\d+)\)`)
+
+	// .NET: at Acme.Billing.Invoice.Total() in C:\src\Billing\Invoice.cs:line 42
+	dotnetFrameRe = regexp.MustCompile(`at\s+([\w.<>` + "`" + `]+)\(.*?\)\s+in\s+(.+?):line (\d+)`)
+
+	// Node: at save (/srv/app/src/models.ts:42:7) or at /srv/app/src/models.ts:42:7
+	nodeFrameRe = regexp.MustCompile(`at\s+(?:(?:async\s+)?([^\s(]+)\s+\()?(?:file://)?([^\s()]+?):(\d+):\d+\)?`)
+
+	// Ruby: app/models/user.rb:42:in 'save'
+	rubyFrameRe = regexp.MustCompile(`([\w./\\-]+\.(?:rb|erb|rake)):(\d+):in [` + "`" + `']([^'` + "`" + `]+)'`)
+
+	// Go: main.(*Server).handle(...) followed by \t/src/app/server.go:42 +0x1d
+	goFuncRe  = regexp.MustCompile(`^([\w./*()-]+)\(.*\)$`)
+	goFrameRe = regexp.MustCompile(`^\s+(\S+\.go):(\d+)(?:\s+\+0x[0-9a-f]+)?$`)
+
+	// Anything that looks like path:line, e.g. compiler errors and Rust panics
+	genericFrameRe = regexp.MustCompile(`((?:[A-Za-z]:)?[\w./\\@~-]*[\w-]+\.(?:go|py|js|jsx|mjs|cjs|ts|tsx|java|kt|scala|rb|rs|c|cc|cpp|h|hpp|cs|php|swift|dart|ex|exs|erl|vue|svelte)):(\d+)`)
+
+	// ValueError: ..., java.lang.IllegalStateException: ..., TypeError: ...
+	errorTypeRe = regexp.MustCompile(`^(?:Caused by:\s+|Uncaught\s+|Unhandled exception\.\s+)?([A-Za-z_][\w.$]*(?:Error|Exception|Panic|Failure|Fault))(?::\s*(.*))?$`)
+)
+
+// Parse extracts the error type, message and frames from text. Lines that
+// match no known frame format are scanned for path:line references.
+func Parse(text string) *Trace {
+	t := &Trace{}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	pythonTrace := strings.Contains(text, "Traceback (most recent call last)")
+	goTrace := strings.Contains(text, "goroutine ")
+	var pythonFrames []Frame
+	seen := make(map[string]bool)
+	add := func(f Frame) {
+		key := f.File + ":" + strconv.Itoa(f.Line)
+		if f.File == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		if pythonTrace {
+			pythonFrames = append(pythonFrames, f)
+			return
+		}
+		t.Frames = append(t.Frames, f)
+	}
+
+	pendingGoFunc := ""
+	for _, raw := range lines {
+		line := strings.TrimRight(raw, " \t")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if m := pythonFrameRe.FindStringSubmatch(trimmed); m != nil {
+			add(Frame{File: m[1], Line: atoi(m[2]), Function: m[3]})
+			continue
+		}
+		if m := jvmFrameRe.FindStringSubmatch(trimmed); m != nil {
+			add(jvmFrame(m[1], m[2], m[3], atoi(m[4])))
+			continue
+		}
+		if m := dotnetFrameRe.FindStringSubmatch(trimmed); m != nil {
+			add(Frame{File: m[2], Line: atoi(m[3]), Function: m[1]})
+			continue
+		}
+		if m := goFrameRe.FindStringSubmatch(line); m != nil {
+			add(Frame{File: m[1], Line: atoi(m[2]), Function: pendingGoFunc})
+			pendingGoFunc = ""
+			continue
+		}
+		if m := goFuncRe.FindStringSubmatch(trimmed); goTrace && m != nil {
+			pendingGoFunc = m[1]
+			continue
+		}
+		if strings.HasPrefix(trimmed, "at ") {
+			if m := nodeFrameRe.FindStringSubmatch(trimmed); m != nil {
+				add(Frame{File: m[2], Line: atoi(m[3]), Function: m[1]})
+				continue
+			}
+		}
+		if m := rubyFrameRe.FindStringSubmatch(trimmed); m != nil {
+			add(Frame{File: m[1], Line: atoi(m[2]), Function: m[3]})
+			continue
+		}
+
+		// Error type and message: Python prints them last, most others first
+		if m := errorTypeRe.FindStringSubmatch(trimmed); m != nil {
+			if t.ErrorType == "" || pythonTrace {
+				t.ErrorType = m[1]
+				t.Message = strings.TrimSpace(m[2])
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "panic: ") && t.ErrorType == "" {
+			t.ErrorType = "panic"
+			t.Message = strings.TrimPrefix(trimmed, "panic: ")
+			continue
+		}
+
+		found := false
+		for _, m := range genericFrameRe.FindAllStringSubmatch(trimmed, -1) {
+			add(Frame{File: m[1], Line: atoi(m[2])})
+			found = true
+		}
+		if !found && t.Message == "" && !strings.HasPrefix(trimmed, "Traceback") && !strings.HasPrefix(trimmed, "goroutine ") {
+			t.Message = trimmed
+		}
+	}
+
+	// Python prints the innermost frame last
+	for i := len(pythonFrames) - 1; i >= 0; i-- {
+		t.Frames = append(t.Frames, pythonFrames[i])
+	}
+	return t
+}
+
+// jvmFrame turns a class and source file name into a path suffix:
+// com.acme.billing.Invoice$Line + Invoice.java -> com/acme/billing/Invoice.java
+func jvmFrame(class, method, file string, line int) Frame {
+	path := file
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		path = strings.ReplaceAll(class[:i], ".", "/") + "/" + file
+	}
+	return Frame{File: path, Line: line, Function: class + "." + method, JVMClass: true}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
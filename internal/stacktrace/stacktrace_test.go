@@ -0,0 +1,97 @@
+package stacktrace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		trace     string
+		errorType string
+		message   string
+		frames    []Frame
+	}{
+		{
+			name: "python",
+			trace: `Traceback (most recent call last):
+  File "/srv/app/api/views.py", line 18, in post
+    order = create_order(data)
+  File "/srv/app/orders/service.py", line 42, in create_order
+    raise ValueError("quantity must be positive")
+ValueError: quantity must be positive`,
+			errorType: "ValueError",
+			message:   "quantity must be positive",
+			frames: []Frame{
+				{File: "/srv/app/orders/service.py", Line: 42, Function: "create_order"},
+				{File: "/srv/app/api/views.py", Line: 18, Function: "post"},
+			},
+		},
+		{
+			name: "go panic",
+			trace: `panic: runtime error: invalid memory address or nil pointer dereference
+
+goroutine 1 [running]:
+github.com/acme/app/internal/store.(*Store).Get(0x0, {0x10, 0x3})
+	/home/dev/app/internal/store/store.go:31 +0x1d
+main.main()
+	/home/dev/app/cmd/app/main.go:12 +0x25`,
+			errorType: "panic",
+			message:   "runtime error: invalid memory address or nil pointer dereference",
+			frames: []Frame{
+				{File: "/home/dev/app/internal/store/store.go", Line: 31, Function: "github.com/acme/app/internal/store.(*Store).Get"},
+				{File: "/home/dev/app/cmd/app/main.go", Line: 12, Function: "main.main"},
+			},
+		},
+		{
+			name: "node",
+			trace: `TypeError: Cannot read properties of undefined (reading 'id')
+    at UserService.find (/srv/app/src/users/user.service.ts:27:19)
+    at async handler (file:///srv/app/src/routes.ts:8:3)
+    at /srv/app/src/index.ts:3:1`,
+			errorType: "TypeError",
+			message:   "Cannot read properties of undefined (reading 'id')",
+			frames: []Frame{
+				{File: "/srv/app/src/users/user.service.ts", Line: 27, Function: "UserService.find"},
+				{File: "/srv/app/src/routes.ts", Line: 8, Function: "handler"},
+				{File: "/srv/app/src/index.ts", Line: 3},
+			},
+		},
+		{
+			name: "jvm",
+			trace: `java.lang.IllegalStateException: invoice already paid
+	at com.acme.billing.Invoice.pay(Invoice.java:88)
+	at com.acme.billing.InvoiceService$1.run(InvoiceService.java:40)
+	at java.base/java.lang.Thread.run(Thread.java:833)`,
+			errorType: "java.lang.IllegalStateException",
+			message:   "invoice already paid",
+			frames: []Frame{
+				{File: "com/acme/billing/Invoice.java", Line: 88, Function: "com.acme.billing.Invoice.pay", JVMClass: true},
+				{File: "com/acme/billing/InvoiceService.java", Line: 40, Function: "com.acme.billing.InvoiceService$1.run", JVMClass: true},
+				{File: "java/lang/Thread.java", Line: 833, Function: "java.lang.Thread.run", JVMClass: true},
+			},
+		},
+		{
+			name:    "compiler error",
+			trace:   "src/main.rs:14:9: error[E0308]: mismatched types",
+			message: "",
+			frames:  []Frame{{File: "src/main.rs", Line: 14}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.trace)
+			if got.ErrorType != tt.errorType {
+				t.Errorf("error type = %q, want %q", got.ErrorType, tt.errorType)
+			}
+			if got.Message != tt.message {
+				t.Errorf("message = %q, want %q", got.Message, tt.message)
+			}
+			if !reflect.DeepEqual(got.Frames, tt.frames) {
+				t.Errorf("frames = %+v\nwant %+v", got.Frames, tt.frames)
+			}
+		})
+	}
+}
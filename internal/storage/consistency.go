@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"database/sql"
+	"sort"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// IndexDrift lists where the SQLite index disagrees with the JSON store.
+// The JSON store is the source of truth; the index drifts when one of the
+// two writes of an update fails.
+type IndexDrift struct {
+	Missing     map[string][]string `json:"missing,omitempty"`      // by table: records without a row
+	Stale       map[string][]string `json:"stale,omitempty"`        // by table: rows that differ from their record
+	Orphaned    map[string][]string `json:"orphaned,omitempty"`     // by table: rows whose record is gone
+	StaleChunks []string            `json:"stale_chunks,omitempty"` // files with code chunks but no live record
+}
+
+// Count is the number of mismatches found
+func (d *IndexDrift) Count() int {
+	n := len(d.StaleChunks)
+	for _, m := range []map[string][]string{d.Missing, d.Stale, d.Orphaned} {
+		for _, ids := range m {
+			n += len(ids)
+		}
+	}
+	return n
+}
+
+func (d *IndexDrift) add(m *map[string][]string, table, id string) {
+	if *m == nil {
+		*m = make(map[string][]string)
+	}
+	(*m)[table] = append((*m)[table], id)
+}
+
+// indexRow is what the consistency check compares of a row: its key and
+// the columns that must match the record
+type indexRow struct {
+	id     string
+	fields [3]string
+}
+
+// CheckConsistency compares the index with the JSON store. Deleted files
+// keep their tombstone in the JSON store but must be gone from the index.
+func (idx *SQLiteIndex) CheckConsistency(store *JSONStore) (*IndexDrift, error) {
+	want := make(map[string]map[string][3]string)
+
+	files, err := store.GetFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+	want["files"] = make(map[string][3]string)
+	for path, f := range files {
+		if f.DeletedAt == nil {
+			want["files"][relpath.Normalize(path)] = [3]string{f.Summary, f.Language}
+		}
+	}
+
+	decisions, err := store.GetDecisions()
+	if err != nil {
+		return nil, err
+	}
+	want["decisions"] = make(map[string][3]string)
+	for _, d := range decisions {
+		want["decisions"][d.ID] = [3]string{d.Content, d.Status, d.Feature}
+	}
+
+	warnings, err := store.GetWarnings()
+	if err != nil {
+		return nil, err
+	}
+	want["warnings"] = make(map[string][3]string)
+	for _, w := range warnings {
+		want["warnings"][w.ID] = [3]string{w.Content, w.Severity, w.Feature}
+	}
+
+	features, err := store.GetFeatures()
+	if err != nil {
+		return nil, err
+	}
+	want["features"] = make(map[string][3]string)
+	for _, f := range features {
+		want["features"][f.ID] = [3]string{f.Status, f.CurrentState}
+	}
+
+	queries := map[string]string{
+		"files":     "SELECT path, COALESCE(summary, ''), COALESCE(language, ''), '' FROM files",
+		"decisions": "SELECT id, COALESCE(content, ''), COALESCE(status, ''), COALESCE(feature, '') FROM decisions",
+		"warnings":  "SELECT id, COALESCE(content, ''), COALESCE(severity, ''), COALESCE(feature, '') FROM warnings",
+		"features":  "SELECT id, COALESCE(status, ''), COALESCE(current_state, ''), '' FROM features",
+	}
+
+	drift := &IndexDrift{}
+	for _, table := range []string{"files", "decisions", "warnings", "features"} {
+		rows, err := idx.indexRows(queries[table])
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			seen[row.id] = true
+			fields, ok := want[table][row.id]
+			switch {
+			case !ok:
+				drift.add(&drift.Orphaned, table, row.id)
+			case fields != row.fields:
+				drift.add(&drift.Stale, table, row.id)
+			}
+		}
+		for id := range want[table] {
+			if !seen[id] {
+				drift.add(&drift.Missing, table, id)
+			}
+		}
+	}
+
+	chunked, err := idx.chunkedFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range chunked {
+		if _, ok := want["files"][path]; !ok {
+			drift.StaleChunks = append(drift.StaleChunks, path)
+		}
+	}
+
+	for _, m := range []map[string][]string{drift.Missing, drift.Stale, drift.Orphaned} {
+		for _, ids := range m {
+			sort.Strings(ids)
+		}
+	}
+	return drift, nil
+}
+
+func (idx *SQLiteIndex) indexRows(query string) ([]indexRow, error) {
+	rows, err := idx.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []indexRow
+	for rows.Next() {
+		var row indexRow
+		if err := rows.Scan(&row.id, &row.fields[0], &row.fields[1], &row.fields[2]); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// chunkedFiles lists the files that have code chunks, sorted
+func (idx *SQLiteIndex) chunkedFiles() ([]string, error) {
+	rows, err := idx.db.Query("SELECT DISTINCT file_path FROM code_chunks ORDER BY file_path")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// RepairDrift brings the index back in line with the JSON store: missing
+// and stale rows are written again from their records, and orphaned rows
+// and stale chunks are deleted. It repairs in one transaction and returns
+// how many mismatches it fixed.
+func (idx *SQLiteIndex) RepairDrift(store *JSONStore, drift *IndexDrift) (int, error) {
+	if drift.Count() == 0 {
+		return 0, nil
+	}
+	files, _ := store.GetFilesIndex()
+	decisions, _ := store.GetDecisions()
+	warnings, _ := store.GetWarnings()
+	features, _ := store.GetFeatures()
+
+	decisionsByID := make(map[string]types.Decision, len(decisions))
+	for _, d := range decisions {
+		decisionsByID[d.ID] = d
+	}
+	warningsByID := make(map[string]types.Warning, len(warnings))
+	for _, w := range warnings {
+		warningsByID[w.ID] = w
+	}
+	featuresByID := make(map[string]types.Feature, len(features))
+	for _, f := range features {
+		featuresByID[f.ID] = f
+	}
+	filesByPath := make(map[string]types.FileIndex, len(files))
+	for path, f := range files {
+		filesByPath[relpath.Normalize(path)] = f
+	}
+
+	repaired := 0
+	err := idx.WithTransaction(func(tx *sql.Tx) error {
+		for _, m := range []map[string][]string{drift.Missing, drift.Stale} {
+			for table, ids := range m {
+				for _, id := range ids {
+					var err error
+					switch table {
+					case "files":
+						f := filesByPath[id]
+						err = idx.indexFile(tx, &f)
+					case "decisions":
+						d := decisionsByID[id]
+						err = idx.indexDecision(tx, &d)
+					case "warnings":
+						w := warningsByID[id]
+						err = idx.indexWarning(tx, &w)
+					case "features":
+						f := featuresByID[id]
+						err = idx.indexFeature(tx, &f)
+					}
+					if err != nil {
+						return err
+					}
+					repaired++
+				}
+			}
+		}
+		keys := map[string]string{"files": "path", "decisions": "id", "warnings": "id", "features": "id"}
+		for table, ids := range drift.Orphaned {
+			for _, id := range ids {
+				// Table and key names come from the map above, never from input
+				if _, err := tx.Exec("DELETE FROM "+table+" WHERE "+keys[table]+" = ?", id); err != nil {
+					return err
+				}
+				repaired++
+			}
+		}
+		for _, path := range drift.StaleChunks {
+			if _, err := tx.Exec("DELETE FROM code_chunks WHERE file_path = ?", path); err != nil {
+				return err
+			}
+			repaired++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return repaired, nil
+}
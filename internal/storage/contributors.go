@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// agentAuthorWords mark an author as a bot or agent account rather than a
+// person, e.g. "ci-bot", "dependabot[bot]" or "Release Agent"
+var agentAuthorWords = map[string]bool{
+	"bot": true, "agent": true, "ci": true, "automation": true,
+}
+
+// IsAgentAuthor reports whether knowledge by author came from an agent or
+// bot account, or was recorded without an author at all
+func IsAgentAuthor(author string) bool {
+	author = strings.TrimSpace(author)
+	if author == "" || author == types.UnattributedAuthor {
+		return true
+	}
+	name := author
+	if i := strings.IndexByte(name, '<'); i > 0 {
+		name = name[:i] // "Name <email>": the email says nothing about a bot
+	}
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if agentAuthorWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// Contributors counts the decisions, warnings, insights, file summaries and
+// conversations each author recorded since the given time (all of them
+// for the zero time), most knowledge first. File summaries only count when
+// an agent wrote them with index_file; the indexer's own are nobody's.
+func (s *JSONStore) Contributors(since time.Time) []types.AuthorStats {
+	byAuthor := make(map[string]*types.AuthorStats)
+	add := func(author string, at time.Time) *types.AuthorStats {
+		if !since.IsZero() && at.Before(since) {
+			return nil
+		}
+		author = strings.TrimSpace(author)
+		if author == "" {
+			author = types.UnattributedAuthor
+		}
+		st, ok := byAuthor[author]
+		if !ok {
+			st = &types.AuthorStats{Author: author, Agent: IsAgentAuthor(author)}
+			byAuthor[author] = st
+		}
+		st.Total++
+		return st
+	}
+
+	decisions, _ := s.GetDecisions()
+	for _, d := range decisions {
+		if st := add(d.Author, d.CreatedAt); st != nil {
+			st.Decisions++
+		}
+	}
+	warnings, _ := s.GetWarnings()
+	for _, w := range warnings {
+		if st := add(w.Author, w.CreatedAt); st != nil {
+			st.Warnings++
+		}
+	}
+	insights, _ := s.GetInsights()
+	for _, ins := range insights {
+		if st := add(ins.Author, ins.CreatedAt); st != nil {
+			st.Insights++
+		}
+	}
+	files, _ := s.GetFilesIndex()
+	for _, f := range files {
+		if f.SummarySource != types.SummarySourceAgent {
+			continue
+		}
+		var at time.Time
+		if f.SummarizedAt != nil {
+			at = *f.SummarizedAt
+		}
+		if st := add(f.SummaryAuthor, at); st != nil {
+			st.FileSummaries++
+		}
+	}
+	convs, _ := s.GetAllConversations()
+	for _, c := range convs {
+		if st := add(c.Author, c.CreatedAt); st != nil {
+			st.Conversations++
+		}
+	}
+
+	contributors := make([]types.AuthorStats, 0, len(byAuthor))
+	for _, st := range byAuthor {
+		contributors = append(contributors, *st)
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Total != contributors[j].Total {
+			return contributors[i].Total > contributors[j].Total
+		}
+		return contributors[i].Author < contributors[j].Author
+	})
+	return contributors
+}
+
+// AgentShare is the fraction of the contributors' knowledge that came from
+// agents, bots or no one, rounded to two decimals
+func AgentShare(contributors []types.AuthorStats) float64 {
+	var agent, total int
+	for _, c := range contributors {
+		total += c.Total
+		if c.Agent {
+			agent += c.Total
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(agent)/float64(total)*100) / 100
+}
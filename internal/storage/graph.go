@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// ErrInvalidEdge is wrapped by errors for edges the knowledge graph rejects
+var ErrInvalidEdge = errors.New("invalid graph edge")
+
+// edgeEndpoints lists, per relation, the node types it may connect as
+// "from->to". Relations not listed here are rejected.
+var edgeEndpoints = map[string][]string{
+	"affects":     {"decision->file"},
+	"mentions":    {"decision->file"},
+	"warns":       {"warning->file"},
+	"related_to":  {"decision->decision", "warning->decision", "file->file"},
+	"belongs_to":  {"decision->feature", "warning->feature"},
+	"supersedes":  {"decision->decision"},
+	"follows":     {"file->pattern"},
+	"imports":     {"file->file"},
+	"imported_by": {"file->file"},
+	"built_by":    {"file->build_target"},
+	"depends_on":  {"build_target->build_target"},
+}
+
+// ValidateEdge checks an edge on its own: both ends set, no self-loop and a
+// relation allowed between the two node types
+func ValidateEdge(e types.Edge) error {
+	if problem := edgeProblem(e); problem != "" {
+		return fmt.Errorf("%w: %s", ErrInvalidEdge, problem)
+	}
+	return nil
+}
+
+// edgeProblem describes what is wrong with an edge, or returns ""
+func edgeProblem(e types.Edge) string {
+	if e.FromType == "" || e.FromID == "" || e.ToType == "" || e.ToID == "" {
+		return "both ends need a type and an ID"
+	}
+	if e.FromType == e.ToType && e.FromID == e.ToID {
+		return fmt.Sprintf("%s %s %s itself", e.FromType, e.FromID, e.Relation)
+	}
+	allowed, ok := edgeEndpoints[e.Relation]
+	if !ok {
+		return fmt.Sprintf("unknown relation %q", e.Relation)
+	}
+	pair := e.FromType + "->" + e.ToType
+	for _, a := range allowed {
+		if a == pair {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s cannot link %s to %s", e.Relation, e.FromType, e.ToType)
+}
+
+// graphNodeID returns a node ID in stored form. File nodes are project
+// paths and use forward slashes on every OS.
+func graphNodeID(nodeType, nodeID string) string {
+	if nodeType == "file" {
+		return relpath.Normalize(nodeID)
+	}
+	return nodeID
+}
+
+// normalizeEdge puts both ends of an edge in stored form
+func normalizeEdge(e types.Edge) types.Edge {
+	e.FromID = graphNodeID(e.FromType, e.FromID)
+	e.ToID = graphNodeID(e.ToType, e.ToID)
+	return e
+}
+
+// edgeKey identifies an edge for duplicate detection
+func edgeKey(e types.Edge) string {
+	return e.FromType + "\x00" + e.FromID + "\x00" + e.Relation + "\x00" + e.ToType + "\x00" + e.ToID
+}
+
+// closesSupersedesCycle reports whether adding e would make a decision
+// (transitively) supersede itself
+func closesSupersedesCycle(edges []types.Edge, e types.Edge) bool {
+	if e.Relation != "supersedes" {
+		return false
+	}
+	next := make(map[string][]string)
+	for _, existing := range edges {
+		if existing.Relation == "supersedes" {
+			next[existing.FromID] = append(next[existing.FromID], existing.ToID)
+		}
+	}
+	seen := map[string]bool{e.ToID: true}
+	queue := []string{e.ToID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == e.FromID {
+			return true
+		}
+		for _, n := range next[id] {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return false
+}
+
+// GraphIssue is an edge the audit found wrong, and why
+type GraphIssue struct {
+	Edge   types.Edge `json:"edge"`
+	Reason string     `json:"reason"`
+}
+
+// GraphAudit is the result of checking every edge in the knowledge graph
+type GraphAudit struct {
+	TotalEdges int          `json:"total_edges"`
+	Invalid    []GraphIssue `json:"invalid,omitempty"`
+	Duplicates []types.Edge `json:"duplicates,omitempty"` // extra copies; the first occurrence is kept
+	Cycles     []GraphIssue `json:"cycles,omitempty"`     // supersedes edges closing a cycle
+	Fixed      bool         `json:"fixed"`
+}
+
+// Problems returns how many edges the audit would remove
+func (a *GraphAudit) Problems() int {
+	return len(a.Invalid) + len(a.Duplicates) + len(a.Cycles)
+}
+
+// AuditGraph checks every edge in the knowledge graph for invalid endpoints,
+// duplicates and supersedes cycles. With fix, the offending edges are
+// removed and the graph rewritten.
+func (s *JSONStore) AuditGraph(fix bool) (*GraphAudit, error) {
+	s.lock(storeGraph).Lock()
+	defer s.lock(storeGraph).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "graph.json")
+	graph, err := readJSON[types.KnowledgeGraph](path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &GraphAudit{}, nil
+		}
+		return nil, err
+	}
+	if graph == nil {
+		return &GraphAudit{}, nil
+	}
+
+	audit := &GraphAudit{TotalEdges: len(graph.Edges)}
+	kept := make([]types.Edge, 0, len(graph.Edges))
+	seen := make(map[string]bool, len(graph.Edges))
+	for _, e := range graph.Edges {
+		if problem := edgeProblem(e); problem != "" {
+			audit.Invalid = append(audit.Invalid, GraphIssue{Edge: e, Reason: problem})
+			continue
+		}
+		key := edgeKey(e)
+		if seen[key] {
+			audit.Duplicates = append(audit.Duplicates, e)
+			continue
+		}
+		if closesSupersedesCycle(kept, e) {
+			audit.Cycles = append(audit.Cycles, GraphIssue{Edge: e, Reason: fmt.Sprintf("%s would transitively supersede itself", e.FromID)})
+			continue
+		}
+		seen[key] = true
+		kept = append(kept, e)
+	}
+
+	if fix && audit.Problems() > 0 {
+		graph.Edges = kept
+		if err := writeJSON(path, graph); err != nil {
+			return nil, err
+		}
+		audit.Fixed = true
+	}
+	return audit, nil
+}
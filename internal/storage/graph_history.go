@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// graphHistoryLimit caps the snapshots kept; the oldest are dropped first
+const graphHistoryLimit = 500
+
+// couplingDirDepth is how many path segments name an area when no
+// architecture services are documented: "internal/mcp", "src/payments"
+const couplingDirDepth = 2
+
+// GetGraphHistory returns the recorded graph snapshots, oldest first
+func (s *JSONStore) GetGraphHistory() (*types.GraphHistory, error) {
+	s.lock(storeGraphHistory).RLock()
+	defer s.lock(storeGraphHistory).RUnlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "graph-history.json")
+	history, err := readJSON[types.GraphHistory](path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &types.GraphHistory{}, nil
+		}
+		return nil, err
+	}
+	return history, nil
+}
+
+// SnapshotGraph records the current edge count per relation and the import
+// coupling between areas — documented architecture services, else top-level
+// directories. A snapshot identical to the previous one is not recorded
+// again; the returned bool says whether this one was.
+func (s *JSONStore) SnapshotGraph(commit string) (*types.GraphSnapshot, bool, error) {
+	graph, err := s.GetKnowledgeGraph()
+	if err != nil {
+		return nil, false, err
+	}
+	var services []types.ServiceNode
+	if arch, err := s.GetArchitecture(); err == nil && arch != nil {
+		services = arch.Services
+	}
+
+	snap := types.GraphSnapshot{
+		TakenAt:   time.Now(),
+		Commit:    commit,
+		Edges:     len(graph.Edges),
+		Relations: make(map[string]int),
+		Coupling:  make(map[string]int),
+	}
+	for _, e := range graph.Edges {
+		snap.Relations[e.Relation]++
+		if e.Relation != "imports" || e.FromType != "file" || e.ToType != "file" {
+			continue
+		}
+		from, to := GraphArea(e.FromID, services), GraphArea(e.ToID, services)
+		if from != "" && to != "" && from != to {
+			snap.Coupling[from+" -> "+to]++
+		}
+	}
+
+	s.lock(storeGraphHistory).Lock()
+	defer s.lock(storeGraphHistory).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "graph-history.json")
+	history, err := readJSON[types.GraphHistory](path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, err
+	}
+	if history == nil {
+		history = &types.GraphHistory{}
+	}
+	if n := len(history.Snapshots); n > 0 && sameGraphShape(history.Snapshots[n-1], snap) {
+		return &snap, false, nil
+	}
+	history.Snapshots = append(history.Snapshots, snap)
+	if len(history.Snapshots) > graphHistoryLimit {
+		history.Snapshots = history.Snapshots[len(history.Snapshots)-graphHistoryLimit:]
+	}
+	if err := writeJSON(path, history); err != nil {
+		return nil, false, err
+	}
+	return &snap, true, nil
+}
+
+// GraphArea names the area a file belongs to: the first documented service
+// owning it, else its leading directories. Files at the project root have
+// no area.
+func GraphArea(file string, services []types.ServiceNode) string {
+	file = relpath.Normalize(file)
+	for _, svc := range services {
+		for _, f := range svc.Files {
+			if relpath.Normalize(f) != "" && relpath.Within(file, f) {
+				return svc.Name
+			}
+		}
+	}
+	parts := strings.Split(file, "/")
+	if len(parts) < 2 || strings.HasPrefix(file, "/") {
+		return ""
+	}
+	parts = parts[:len(parts)-1]
+	if len(parts) > couplingDirDepth {
+		parts = parts[:couplingDirDepth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// sameGraphShape reports whether two snapshots have the same counts
+func sameGraphShape(a, b types.GraphSnapshot) bool {
+	return a.Edges == b.Edges && sameCounts(a.Relations, b.Relations) && sameCounts(a.Coupling, b.Coupling)
+}
+
+func sameCounts(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
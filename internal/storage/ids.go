@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// ErrDuplicate is returned when adding a knowledge item that is already
+// recorded. The item passed in is then filled with the stored one, so a
+// retried write reports the ID of the first.
+var ErrDuplicate = errors.New("already recorded")
+
+// DecisionID derives a decision's ID from its idempotency key, or from its
+// content and feature when it has none
+func DecisionID(d *types.Decision) string {
+	return contentID("dec", d.IdempotencyKey, d.Content, d.Feature)
+}
+
+// WarningID derives a warning's ID like DecisionID
+func WarningID(w *types.Warning) string {
+	return contentID("warn", w.IdempotencyKey, w.Content, w.Feature)
+}
+
+// InsightID derives an insight's ID like DecisionID
+func InsightID(i *types.Insight) string {
+	return contentID("ins", i.IdempotencyKey, i.Content, i.Feature)
+}
+
+// PatternID derives a pattern's ID from its idempotency key or its name
+func PatternID(p *types.Pattern) string {
+	return contentID("pat", p.IdempotencyKey, p.Name)
+}
+
+// contentID hashes the parts, ignoring case and whitespace so a retry that
+// re-wraps a line gets the same ID: "dec-3f9a1c0b7e2d"
+func contentID(prefix, key string, parts ...string) string {
+	if key != "" {
+		parts = []string{"key", key}
+	}
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(strings.ToLower(strings.Join(strings.Fields(p), " "))))
+		h.Write([]byte{0})
+	}
+	return prefix + "-" + hex.EncodeToString(h.Sum(nil))[:12]
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// GetIndexCapReport returns what the last index run left out to stay within
+// the index caps; nil when no run has been recorded
+func (s *JSONStore) GetIndexCapReport() (*types.IndexCapReport, error) {
+	s.lock(storeIndexCaps).RLock()
+	defer s.lock(storeIndexCaps).RUnlock()
+
+	report, err := readJSON[types.IndexCapReport](filepath.Join(s.basePath, "index", "caps.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// SaveIndexCapReport records the index caps report
+func (s *JSONStore) SaveIndexCapReport(report *types.IndexCapReport) error {
+	s.lock(storeIndexCaps).Lock()
+	defer s.lock(storeIndexCaps).Unlock()
+	return writeJSON(filepath.Join(s.basePath, "index", "caps.json"), report)
+}
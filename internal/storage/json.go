@@ -5,17 +5,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sync"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/saeedalam/teamcontext/internal/relpath"
 	"github.com/saeedalam/teamcontext/pkg/types"
 )
 
 // JSONStore handles JSON file storage operations
 type JSONStore struct {
 	basePath string
-	mu       sync.RWMutex
+	locks    storeLocks // one read/write lock per store
 }
 
 // NewJSONStore creates a new JSON store
@@ -33,16 +35,16 @@ func (s *JSONStore) BasePath() string {
 // --- Config ---
 
 func (s *JSONStore) GetConfig() (*types.Config, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeConfig).RLock()
+	defer s.lock(storeConfig).RUnlock()
 
 	path := filepath.Join(s.basePath, "config.json")
 	return readJSON[types.Config](path)
 }
 
 func (s *JSONStore) SaveConfig(config *types.Config) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeConfig).Lock()
+	defer s.lock(storeConfig).Unlock()
 
 	path := filepath.Join(s.basePath, "config.json")
 	return writeJSON(path, config)
@@ -51,28 +53,84 @@ func (s *JSONStore) SaveConfig(config *types.Config) error {
 // --- Project ---
 
 func (s *JSONStore) GetProject() (*types.Project, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeProject).RLock()
+	defer s.lock(storeProject).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "project.json")
 	return readJSON[types.Project](path)
 }
 
 func (s *JSONStore) SaveProject(project *types.Project) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeProject).Lock()
+	defer s.lock(storeProject).Unlock()
 
 	project.UpdatedAt = time.Now()
 	path := filepath.Join(s.basePath, "knowledge", "project.json")
 	return writeJSON(path, project)
 }
 
+// UpdateProject reads, changes and saves project knowledge under one lock,
+// so a background refresh and a tool call can't overwrite each other's
+// fields. update returns false to leave the project as it is. A project
+// that doesn't exist yet starts empty.
+func (s *JSONStore) UpdateProject(update func(project *types.Project) bool) (*types.Project, error) {
+	s.lock(storeProject).Lock()
+	defer s.lock(storeProject).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "project.json")
+	project, err := readJSON[types.Project](path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if project == nil {
+		project = &types.Project{}
+	}
+	if !update(project) {
+		return project, nil
+	}
+	project.UpdatedAt = time.Now()
+	return project, writeJSON(path, project)
+}
+
 // --- Files Index ---
 
+// GetFilesIndex returns all live (non-deleted) files in the index.
 func (s *JSONStore) GetFilesIndex() (map[string]types.FileIndex, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeFiles).RLock()
+	defer s.lock(storeFiles).RUnlock()
 
+	files, err := s.readFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+	for path, f := range files {
+		if f.DeletedAt != nil {
+			delete(files, path)
+		}
+	}
+	return files, nil
+}
+
+// GetDeletedFiles returns tombstoned entries for files removed from disk.
+func (s *JSONStore) GetDeletedFiles() (map[string]types.FileIndex, error) {
+	s.lock(storeFiles).RLock()
+	defer s.lock(storeFiles).RUnlock()
+
+	files, err := s.readFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+	for path, f := range files {
+		if f.DeletedAt == nil {
+			delete(files, path)
+		}
+	}
+	return files, nil
+}
+
+// readFilesIndex reads the raw files index, including tombstones.
+// Callers must hold s.lock(storeFiles).
+func (s *JSONStore) readFilesIndex() (map[string]types.FileIndex, error) {
 	path := filepath.Join(s.basePath, "index", "files.json")
 	result, err := readJSON[map[string]types.FileIndex](path)
 	if err != nil {
@@ -84,14 +142,27 @@ func (s *JSONStore) GetFilesIndex() (map[string]types.FileIndex, error) {
 	if result == nil {
 		return make(map[string]types.FileIndex), nil
 	}
+	// Indexes written on Windows before paths were normalized used
+	// backslash keys; re-key them so lookups by stored path find them.
+	for key, f := range *result {
+		if norm := relpath.Normalize(key); norm != key {
+			delete(*result, key)
+			f.Path = norm
+			(*result)[norm] = f
+		}
+	}
 	return *result, nil
 }
 
 // pruneFileIndex removes metadata that isn't strictly needed for the search/tool context
-// This saves massive amounts of tokens and space
+// This saves massive amounts of tokens and space. Only the indexer's
+// placeholder summary is dropped: docs summarize themselves and other
+// summaries were written for a reason.
 func (s *JSONStore) pruneFileIndex(file *types.FileIndex) {
-	file.Summary = ""     // Placeholder not used yet
-	file.Patterns = nil    // Not used by MCP yet
+	if file.Language != "markdown" && file.Summary == types.SummaryPlaceholder {
+		file.Summary = "" // Placeholder not used yet
+	}
+	file.Patterns = nil // Not used by MCP yet
 	file.RelatedFiles = nil
 	file.ContentHash = ""
 	file.SizeBytes = 0
@@ -104,9 +175,18 @@ func (s *JSONStore) pruneFileIndex(file *types.FileIndex) {
 	}
 }
 
+// keepAgentSummary keeps the summary an agent wrote for a file when the
+// indexer saves the file again with a generated one
+func keepAgentSummary(file *types.FileIndex, existing types.FileIndex) {
+	if existing.SummarySource == types.SummarySourceAgent && file.SummarySource == types.SummarySourceAuto {
+		file.Summary, file.SummarySource = existing.Summary, existing.SummarySource
+		file.SummaryAuthor, file.SummarizedAt = existing.SummaryAuthor, existing.SummarizedAt
+	}
+}
+
 func (s *JSONStore) SaveFileIndex(file *types.FileIndex) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFiles).Lock()
+	defer s.lock(storeFiles).Unlock()
 
 	path := filepath.Join(s.basePath, "index", "files.json")
 
@@ -120,42 +200,124 @@ func (s *JSONStore) SaveFileIndex(file *types.FileIndex) error {
 	}
 
 	s.pruneFileIndex(file)
+	file.Path = relpath.Normalize(file.Path)
+	file.DeletedAt = nil // saving an entry means the file exists again
+	if existing, ok := (*files)[file.Path]; ok {
+		keepAgentSummary(file, existing)
+	}
 	(*files)[file.Path] = *file
 
 	return writeJSON(path, files)
 }
 
 func (s *JSONStore) SaveFilesIndexBulk(files map[string]types.FileIndex) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFiles).Lock()
+	defer s.lock(storeFiles).Unlock()
 
-	// Prune all files before saving
-	for path, file := range files {
+	// Prune all files before saving, keyed by stored path
+	for key, file := range files {
 		s.pruneFileIndex(&file)
-		files[path] = file
+		file.Path = relpath.Normalize(file.Path)
+		if norm := relpath.Normalize(key); norm != key {
+			delete(files, key)
+			key = norm
+		}
+		files[key] = file
+	}
+
+	// Keep tombstones for files that weren't re-indexed, and summaries
+	// agents wrote over the ones the indexer generates again
+	if existing, err := s.readFilesIndex(); err == nil {
+		for path, f := range existing {
+			file, ok := files[path]
+			if !ok {
+				if f.DeletedAt != nil {
+					files[path] = f
+				}
+				continue
+			}
+			keepAgentSummary(&file, f)
+			files[path] = file
+		}
 	}
 
 	path := filepath.Join(s.basePath, "index", "files.json")
 	return writeJSON(path, files)
 }
 
+// GetFileIndex looks up a single file. Tombstoned entries are returned too
+// (with DeletedAt set) so history and correlations still resolve.
 func (s *JSONStore) GetFileIndex(filePath string) (*types.FileIndex, error) {
-	files, err := s.GetFilesIndex()
+	s.lock(storeFiles).RLock()
+	files, err := s.readFilesIndex()
+	s.lock(storeFiles).RUnlock()
 	if err != nil {
 		return nil, err
 	}
 
+	filePath = relpath.Normalize(filePath)
 	if file, ok := files[filePath]; ok {
 		return &file, nil
 	}
 	return nil, fmt.Errorf("file not indexed: %s", filePath)
 }
 
+// MarkFileDeleted tombstones a file that no longer exists on disk.
+// Files that were never indexed are ignored.
+func (s *JSONStore) MarkFileDeleted(filePath string) error {
+	s.lock(storeFiles).Lock()
+	defer s.lock(storeFiles).Unlock()
+
+	files, err := s.readFilesIndex()
+	if err != nil {
+		return err
+	}
+
+	filePath = relpath.Normalize(filePath)
+	file, ok := files[filePath]
+	if !ok || file.DeletedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	file.DeletedAt = &now
+	files[filePath] = file
+
+	path := filepath.Join(s.basePath, "index", "files.json")
+	return writeJSON(path, files)
+}
+
+// PurgeDeletedFiles permanently removes tombstones older than retention and
+// returns the purged paths.
+func (s *JSONStore) PurgeDeletedFiles(retention time.Duration) ([]string, error) {
+	s.lock(storeFiles).Lock()
+	defer s.lock(storeFiles).Unlock()
+
+	files, err := s.readFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-retention)
+	var purged []string
+	for path, f := range files {
+		if f.DeletedAt != nil && f.DeletedAt.Before(cutoff) {
+			delete(files, path)
+			purged = append(purged, path)
+		}
+	}
+	if len(purged) == 0 {
+		return nil, nil
+	}
+
+	path := filepath.Join(s.basePath, "index", "files.json")
+	return purged, writeJSON(path, files)
+}
+
 // --- Decisions ---
 
 func (s *JSONStore) GetDecisions() ([]types.Decision, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeDecisions).RLock()
+	defer s.lock(storeDecisions).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "decisions.json")
 	result, err := readJSON[[]types.Decision](path)
@@ -171,9 +333,11 @@ func (s *JSONStore) GetDecisions() ([]types.Decision, error) {
 	return *result, nil
 }
 
+// AddDecision stores a decision under an ID derived from its content, or
+// returns ErrDuplicate when the same decision is already stored
 func (s *JSONStore) AddDecision(decision *types.Decision) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeDecisions).Lock()
+	defer s.lock(storeDecisions).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "decisions.json")
 
@@ -186,7 +350,13 @@ func (s *JSONStore) AddDecision(decision *types.Decision) error {
 		decisions = &empty
 	}
 
-	decision.ID = generateID("dec")
+	decision.ID = DecisionID(decision)
+	for _, existing := range *decisions {
+		if existing.ID == decision.ID || DecisionID(&existing) == decision.ID {
+			*decision = existing
+			return ErrDuplicate
+		}
+	}
 	decision.CreatedAt = time.Now()
 	if decision.Status == "" {
 		decision.Status = "active"
@@ -215,8 +385,8 @@ func (s *JSONStore) GetDecisionsByFeature(feature string) ([]types.Decision, err
 // --- Warnings ---
 
 func (s *JSONStore) GetWarnings() ([]types.Warning, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeWarnings).RLock()
+	defer s.lock(storeWarnings).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "warnings.json")
 	result, err := readJSON[[]types.Warning](path)
@@ -232,9 +402,11 @@ func (s *JSONStore) GetWarnings() ([]types.Warning, error) {
 	return *result, nil
 }
 
+// AddWarning stores a warning under an ID derived from its content, or
+// returns ErrDuplicate when the same warning is already stored
 func (s *JSONStore) AddWarning(warning *types.Warning) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeWarnings).Lock()
+	defer s.lock(storeWarnings).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "warnings.json")
 
@@ -247,7 +419,13 @@ func (s *JSONStore) AddWarning(warning *types.Warning) error {
 		warnings = &empty
 	}
 
-	warning.ID = generateID("warn")
+	warning.ID = WarningID(warning)
+	for _, existing := range *warnings {
+		if existing.ID == warning.ID || WarningID(&existing) == warning.ID {
+			*warning = existing
+			return ErrDuplicate
+		}
+	}
 	warning.CreatedAt = time.Now()
 	if warning.Severity == "" {
 		warning.Severity = "warning"
@@ -261,8 +439,8 @@ func (s *JSONStore) AddWarning(warning *types.Warning) error {
 // --- Insights ---
 
 func (s *JSONStore) GetInsights() ([]types.Insight, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeInsights).RLock()
+	defer s.lock(storeInsights).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "insights.json")
 	result, err := readJSON[[]types.Insight](path)
@@ -278,9 +456,11 @@ func (s *JSONStore) GetInsights() ([]types.Insight, error) {
 	return *result, nil
 }
 
+// AddInsight stores a insight under an ID derived from its content, or
+// returns ErrDuplicate when the same insight is already stored
 func (s *JSONStore) AddInsight(insight *types.Insight) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeInsights).Lock()
+	defer s.lock(storeInsights).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "insights.json")
 
@@ -293,7 +473,13 @@ func (s *JSONStore) AddInsight(insight *types.Insight) error {
 		insights = &empty
 	}
 
-	insight.ID = generateID("ins")
+	insight.ID = InsightID(insight)
+	for _, existing := range *insights {
+		if existing.ID == insight.ID || InsightID(&existing) == insight.ID {
+			*insight = existing
+			return ErrDuplicate
+		}
+	}
 	insight.CreatedAt = time.Now()
 
 	*insights = append(*insights, *insight)
@@ -301,11 +487,170 @@ func (s *JSONStore) AddInsight(insight *types.Insight) error {
 	return writeJSON(path, insights)
 }
 
+// GetUnreviewedInsights returns insights that were neither promoted nor
+// dismissed, oldest first.
+func (s *JSONStore) GetUnreviewedInsights() ([]types.Insight, error) {
+	insights, err := s.GetInsights()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.Insight
+	for _, ins := range insights {
+		if ins.ReviewedAt == nil {
+			result = append(result, ins)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// PromoteInsight marks an insight as reviewed and graduated into the
+// decision or warning with the given ID.
+func (s *JSONStore) PromoteInsight(id, promotedTo string) error {
+	s.lock(storeInsights).Lock()
+	defer s.lock(storeInsights).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "insights.json")
+	insights, err := readJSON[[]types.Insight](path)
+	if err != nil {
+		return err
+	}
+	for i := range *insights {
+		if (*insights)[i].ID == id {
+			now := time.Now()
+			(*insights)[i].PromotedTo = promotedTo
+			(*insights)[i].ReviewedAt = &now
+			return writeJSON(path, insights)
+		}
+	}
+	return fmt.Errorf("insight not found: %s", id)
+}
+
+// DeleteInsight removes an insight that was reviewed and not worth keeping.
+func (s *JSONStore) DeleteInsight(id string) (*types.Insight, error) {
+	s.lock(storeInsights).Lock()
+	defer s.lock(storeInsights).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "insights.json")
+	insights, err := readJSON[[]types.Insight](path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range *insights {
+		if (*insights)[i].ID == id {
+			removed := (*insights)[i]
+			*insights = append((*insights)[:i], (*insights)[i+1:]...)
+			return &removed, writeJSON(path, insights)
+		}
+	}
+	return nil, fmt.Errorf("insight not found: %s", id)
+}
+
+// --- Translations ---
+
+// AddTranslation stores a translated variant of a decision, warning or
+// insight. Fields left empty keep any earlier translation of that field.
+func (s *JSONStore) AddTranslation(itemType, id, language string, t types.Translation) error {
+	store, ok := itemStores[itemType]
+	if !ok {
+		return fmt.Errorf("unsupported item type: %s", itemType)
+	}
+	s.lock(store).Lock()
+	defer s.lock(store).Unlock()
+
+	merge := func(translations map[string]types.Translation) map[string]types.Translation {
+		if translations == nil {
+			translations = make(map[string]types.Translation)
+		}
+		existing := translations[language]
+		if t.Content != "" {
+			existing.Content = t.Content
+		}
+		if t.Reason != "" {
+			existing.Reason = t.Reason
+		}
+		if t.Context != "" {
+			existing.Context = t.Context
+		}
+		if t.Evidence != "" {
+			existing.Evidence = t.Evidence
+		}
+		translations[language] = existing
+		return translations
+	}
+
+	switch itemType {
+	case "decision":
+		path := filepath.Join(s.basePath, "knowledge", "decisions.json")
+		decisions, err := readJSON[[]types.Decision](path)
+		if err != nil {
+			return err
+		}
+		for i := range *decisions {
+			if (*decisions)[i].ID == id {
+				(*decisions)[i].Translations = merge((*decisions)[i].Translations)
+				return writeJSON(path, decisions)
+			}
+		}
+	case "warning":
+		path := filepath.Join(s.basePath, "knowledge", "warnings.json")
+		warnings, err := readJSON[[]types.Warning](path)
+		if err != nil {
+			return err
+		}
+		for i := range *warnings {
+			if (*warnings)[i].ID == id {
+				(*warnings)[i].Translations = merge((*warnings)[i].Translations)
+				return writeJSON(path, warnings)
+			}
+		}
+	case "insight":
+		path := filepath.Join(s.basePath, "knowledge", "insights.json")
+		insights, err := readJSON[[]types.Insight](path)
+		if err != nil {
+			return err
+		}
+		for i := range *insights {
+			if (*insights)[i].ID == id {
+				(*insights)[i].Translations = merge((*insights)[i].Translations)
+				return writeJSON(path, insights)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported item type: %s", itemType)
+	}
+	return fmt.Errorf("%s not found: %s", itemType, id)
+}
+
+// TranslationText joins all translated text of an item, so search and
+// semantic vectors match queries in any of its languages.
+func TranslationText(translations map[string]types.Translation) string {
+	languages := make([]string, 0, len(translations))
+	for lang := range translations {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+
+	var parts []string
+	for _, lang := range languages {
+		t := translations[lang]
+		for _, field := range []string{t.Content, t.Reason, t.Context, t.Evidence} {
+			if field != "" {
+				parts = append(parts, field)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // --- Features ---
 
 func (s *JSONStore) GetFeatures() ([]types.Feature, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeFeatures).RLock()
+	defer s.lock(storeFeatures).RUnlock()
 
 	featuresDir := filepath.Join(s.basePath, "features")
 	entries, err := os.ReadDir(featuresDir)
@@ -330,17 +675,44 @@ func (s *JSONStore) GetFeatures() ([]types.Feature, error) {
 	return features, nil
 }
 
+// GetArchivedFeatures returns the features moved to archive/
+func (s *JSONStore) GetArchivedFeatures() ([]types.Feature, error) {
+	s.lock(storeFeatures).RLock()
+	defer s.lock(storeFeatures).RUnlock()
+
+	archiveDir := filepath.Join(s.basePath, "archive")
+	entries, err := os.ReadDir(archiveDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.Feature{}, nil
+		}
+		return nil, err
+	}
+
+	var features []types.Feature
+	for _, entry := range entries {
+		if entry.IsDir() {
+			feature, err := readJSON[types.Feature](filepath.Join(archiveDir, entry.Name(), "meta.json"))
+			if err == nil && feature != nil {
+				features = append(features, *feature)
+			}
+		}
+	}
+
+	return features, nil
+}
+
 func (s *JSONStore) GetFeature(id string) (*types.Feature, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeFeatures).RLock()
+	defer s.lock(storeFeatures).RUnlock()
 
 	metaPath := filepath.Join(s.basePath, "features", id, "meta.json")
 	return readJSON[types.Feature](metaPath)
 }
 
 func (s *JSONStore) CreateFeature(feature *types.Feature) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
 
 	featureDir := filepath.Join(s.basePath, "features", feature.ID)
 
@@ -363,8 +735,8 @@ func (s *JSONStore) CreateFeature(feature *types.Feature) error {
 }
 
 func (s *JSONStore) UpdateFeature(feature *types.Feature) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
 
 	feature.LastAccessed = time.Now()
 	metaPath := filepath.Join(s.basePath, "features", feature.ID, "meta.json")
@@ -372,8 +744,8 @@ func (s *JSONStore) UpdateFeature(feature *types.Feature) error {
 }
 
 func (s *JSONStore) ArchiveFeature(id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
 
 	// Read current feature
 	metaPath := filepath.Join(s.basePath, "features", id, "meta.json")
@@ -399,8 +771,8 @@ func (s *JSONStore) ArchiveFeature(id string) error {
 }
 
 func (s *JSONStore) RecallFeature(id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
 
 	// Move from archive to features
 	srcDir := filepath.Join(s.basePath, "archive", id)
@@ -467,8 +839,8 @@ func (s *JSONStore) GetFeatureAncestors(featureID string, maxDepth int) ([]types
 // --- Conversations ---
 
 func (s *JSONStore) GetConversations(featureID string) ([]types.Conversation, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeFeatures).RLock()
+	defer s.lock(storeFeatures).RUnlock()
 
 	convDir := filepath.Join(s.basePath, "features", featureID, "conversations")
 	entries, err := os.ReadDir(convDir)
@@ -494,8 +866,8 @@ func (s *JSONStore) GetConversations(featureID string) ([]types.Conversation, er
 }
 
 func (s *JSONStore) SaveConversation(conv *types.Conversation) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
 
 	conv.ID = generateID("conv")
 	conv.CreatedAt = time.Now()
@@ -509,6 +881,34 @@ func (s *JSONStore) SaveConversation(conv *types.Conversation) error {
 	return writeJSON(convPath, conv)
 }
 
+// ReplaceConversations saves new conversations for a feature and removes
+// the ones they replace, e.g. after a merge or split
+func (s *JSONStore) ReplaceConversations(featureID string, add []*types.Conversation, removeIDs []string) error {
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
+
+	convDir := filepath.Join(s.basePath, "features", featureID, "conversations")
+	if err := os.MkdirAll(convDir, 0755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, conv := range add {
+		conv.ID = generateID("conv")
+		conv.Feature = featureID
+		conv.CreatedAt = now
+		if err := writeJSON(filepath.Join(convDir, conv.ID+".json"), conv); err != nil {
+			return err
+		}
+	}
+	for _, id := range removeIDs {
+		if err := os.Remove(filepath.Join(convDir, id+".json")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetAllConversations returns conversations from all features.
 func (s *JSONStore) GetAllConversations() ([]types.Conversation, error) {
 	features, err := s.GetFeatures()
@@ -523,11 +923,109 @@ func (s *JSONStore) GetAllConversations() ([]types.Conversation, error) {
 	return all, nil
 }
 
+// --- Plans ---
+
+// GetPlans returns the plans of a feature, oldest first
+func (s *JSONStore) GetPlans(featureID string) ([]types.Plan, error) {
+	s.lock(storeFeatures).RLock()
+	defer s.lock(storeFeatures).RUnlock()
+
+	planDir := filepath.Join(s.basePath, "features", featureID, "plans")
+	entries, err := os.ReadDir(planDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.Plan{}, nil
+		}
+		return nil, err
+	}
+
+	plans := []types.Plan{}
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
+			plan, err := readJSON[types.Plan](filepath.Join(planDir, entry.Name()))
+			if err == nil && plan != nil {
+				plans = append(plans, *plan)
+			}
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].CreatedAt.Before(plans[j].CreatedAt) })
+	return plans, nil
+}
+
+// GetPlan returns a plan by ID, whichever feature it belongs to
+func (s *JSONStore) GetPlan(id string) (*types.Plan, error) {
+	s.lock(storeFeatures).RLock()
+	defer s.lock(storeFeatures).RUnlock()
+
+	path, err := s.planPath(id)
+	if err != nil {
+		return nil, err
+	}
+	return readJSON[types.Plan](path)
+}
+
+// SavePlan saves a new plan under its feature. Steps without a status are
+// pending.
+func (s *JSONStore) SavePlan(plan *types.Plan) error {
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
+
+	plan.ID = generateID("plan")
+	plan.CreatedAt = time.Now()
+	plan.UpdatedAt = plan.CreatedAt
+	if plan.Status == "" {
+		plan.Status = "active"
+	}
+	for i := range plan.Steps {
+		if plan.Steps[i].Status == "" {
+			plan.Steps[i].Status = "pending"
+		}
+	}
+
+	planDir := filepath.Join(s.basePath, "features", plan.Feature, "plans")
+	if err := os.MkdirAll(planDir, 0755); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(planDir, plan.ID+".json"), plan)
+}
+
+// UpdatePlan applies update to a plan and saves it, unless update fails
+func (s *JSONStore) UpdatePlan(id string, update func(plan *types.Plan) error) (*types.Plan, error) {
+	s.lock(storeFeatures).Lock()
+	defer s.lock(storeFeatures).Unlock()
+
+	path, err := s.planPath(id)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := readJSON[types.Plan](path)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(plan); err != nil {
+		return nil, err
+	}
+	plan.UpdatedAt = time.Now()
+	return plan, writeJSON(path, plan)
+}
+
+// planPath finds the file of a plan among the features' plans
+func (s *JSONStore) planPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid plan id: %q", id)
+	}
+	matches, _ := filepath.Glob(filepath.Join(s.basePath, "features", "*", "plans", id+".json"))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("plan not found: %s", id)
+	}
+	return matches[0], nil
+}
+
 // --- Patterns ---
 
 func (s *JSONStore) GetPatterns() ([]types.Pattern, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storePatterns).RLock()
+	defer s.lock(storePatterns).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "patterns.json")
 	result, err := readJSON[[]types.Pattern](path)
@@ -543,9 +1041,11 @@ func (s *JSONStore) GetPatterns() ([]types.Pattern, error) {
 	return *result, nil
 }
 
+// AddPattern stores a pattern under an ID derived from its name, or
+// returns ErrDuplicate when the same pattern is already stored
 func (s *JSONStore) AddPattern(pattern *types.Pattern) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storePatterns).Lock()
+	defer s.lock(storePatterns).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "patterns.json")
 
@@ -558,7 +1058,13 @@ func (s *JSONStore) AddPattern(pattern *types.Pattern) error {
 		patterns = &empty
 	}
 
-	pattern.ID = generateID("pat")
+	pattern.ID = PatternID(pattern)
+	for _, existing := range *patterns {
+		if existing.ID == pattern.ID || PatternID(&existing) == pattern.ID {
+			*pattern = existing
+			return ErrDuplicate
+		}
+	}
 	pattern.CreatedAt = time.Now()
 	if pattern.Source == "" {
 		pattern.Source = "manual"
@@ -586,8 +1092,8 @@ func (s *JSONStore) GetPattern(id string) (*types.Pattern, error) {
 // --- Knowledge Graph ---
 
 func (s *JSONStore) GetKnowledgeGraph() (*types.KnowledgeGraph, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeGraph).RLock()
+	defer s.lock(storeGraph).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "graph.json")
 	result, err := readJSON[types.KnowledgeGraph](path)
@@ -603,9 +1109,17 @@ func (s *JSONStore) GetKnowledgeGraph() (*types.KnowledgeGraph, error) {
 	return result, nil
 }
 
+// AddEdge adds an edge unless it already exists. Edges failing ValidateEdge,
+// and supersedes edges that would close a cycle, are rejected with an error
+// wrapping ErrInvalidEdge.
 func (s *JSONStore) AddEdge(edge *types.Edge) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	*edge = normalizeEdge(*edge)
+	if err := ValidateEdge(*edge); err != nil {
+		return err
+	}
+
+	s.lock(storeGraph).Lock()
+	defer s.lock(storeGraph).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "graph.json")
 
@@ -624,15 +1138,20 @@ func (s *JSONStore) AddEdge(edge *types.Edge) error {
 			return nil // Edge already exists
 		}
 	}
+	if closesSupersedesCycle(graph.Edges, *edge) {
+		return fmt.Errorf("%w: %s would transitively supersede itself", ErrInvalidEdge, edge.FromID)
+	}
 
 	graph.Edges = append(graph.Edges, *edge)
 
 	return writeJSON(path, graph)
 }
 
+// AddEdgesBulk adds edges in a single write. Invalid edges and ones already
+// in the graph are skipped rather than failing the batch.
 func (s *JSONStore) AddEdgesBulk(edges []types.Edge) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeGraph).Lock()
+	defer s.lock(storeGraph).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "graph.json")
 
@@ -644,7 +1163,54 @@ func (s *JSONStore) AddEdgesBulk(edges []types.Edge) error {
 		graph = &types.KnowledgeGraph{Edges: []types.Edge{}}
 	}
 
-	graph.Edges = append(graph.Edges, edges...)
+	seen := make(map[string]bool, len(graph.Edges))
+	for _, e := range graph.Edges {
+		seen[edgeKey(e)] = true
+	}
+	for _, e := range edges {
+		e = normalizeEdge(e)
+		if key := edgeKey(e); !seen[key] && ValidateEdge(e) == nil {
+			seen[key] = true
+			graph.Edges = append(graph.Edges, e)
+		}
+	}
+
+	return writeJSON(path, graph)
+}
+
+// ReplaceEdgesByRelation removes every edge with one of the given relations
+// and adds edges in their place, in a single write. It is used for edge sets
+// that are always rebuilt as a whole, such as the build graph.
+func (s *JSONStore) ReplaceEdgesByRelation(relations []string, edges []types.Edge) error {
+	s.lock(storeGraph).Lock()
+	defer s.lock(storeGraph).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "graph.json")
+
+	graph, err := readJSON[types.KnowledgeGraph](path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if graph == nil {
+		graph = &types.KnowledgeGraph{Edges: []types.Edge{}}
+	}
+
+	replace := make(map[string]bool, len(relations))
+	for _, r := range relations {
+		replace[r] = true
+	}
+	kept := make([]types.Edge, 0, len(graph.Edges)+len(edges))
+	for _, e := range graph.Edges {
+		if !replace[e.Relation] {
+			kept = append(kept, e)
+		}
+	}
+	for _, e := range edges {
+		if e = normalizeEdge(e); ValidateEdge(e) == nil {
+			kept = append(kept, e)
+		}
+	}
+	graph.Edges = kept
 
 	return writeJSON(path, graph)
 }
@@ -655,6 +1221,7 @@ func (s *JSONStore) GetEdgesFrom(nodeType, nodeID string) ([]types.Edge, error)
 		return nil, err
 	}
 
+	nodeID = graphNodeID(nodeType, nodeID)
 	var edges []types.Edge
 	for _, e := range graph.Edges {
 		if e.FromType == nodeType && e.FromID == nodeID {
@@ -670,6 +1237,7 @@ func (s *JSONStore) GetEdgesTo(nodeType, nodeID string) ([]types.Edge, error) {
 		return nil, err
 	}
 
+	nodeID = graphNodeID(nodeType, nodeID)
 	var edges []types.Edge
 	for _, e := range graph.Edges {
 		if e.ToType == nodeType && e.ToID == nodeID {
@@ -695,6 +1263,7 @@ func (s *JSONStore) TraverseGraph(startType, startID string, maxDepth int) ([]ty
 	if err != nil {
 		return nil, err
 	}
+	startID = graphNodeID(startType, startID)
 
 	type node struct {
 		nodeType string
@@ -752,8 +1321,8 @@ func (s *JSONStore) TraverseGraph(startType, startID string, maxDepth int) ([]ty
 // --- Evolution Timeline ---
 
 func (s *JSONStore) GetEvolutionTimeline() (*types.EvolutionTimeline, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeEvolution).RLock()
+	defer s.lock(storeEvolution).RUnlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "evolution.json")
 	result, err := readJSON[types.EvolutionTimeline](path)
@@ -770,8 +1339,8 @@ func (s *JSONStore) GetEvolutionTimeline() (*types.EvolutionTimeline, error) {
 }
 
 func (s *JSONStore) AddEvolutionEvent(event *types.EvolutionEvent) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeEvolution).Lock()
+	defer s.lock(storeEvolution).Unlock()
 
 	path := filepath.Join(s.basePath, "knowledge", "evolution.json")
 
@@ -794,11 +1363,304 @@ func (s *JSONStore) AddEvolutionEvent(event *types.EvolutionEvent) error {
 	return writeJSON(path, timeline)
 }
 
+// --- Pending Hooks ---
+
+// AddPendingHook queues a hook for delivery on the next MCP tool call.
+// Pending hooks live in cache/ since they are local to one developer.
+func (s *JSONStore) AddPendingHook(hook *types.PendingHook) error {
+	s.lock(storeHooks).Lock()
+	defer s.lock(storeHooks).Unlock()
+
+	path := filepath.Join(s.basePath, "cache", "pending-hooks.json")
+
+	hooks, err := readJSON[[]types.PendingHook](path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if hooks == nil {
+		hooks = &[]types.PendingHook{}
+	}
+
+	hook.CreatedAt = time.Now()
+	*hooks = append(*hooks, *hook)
+
+	return writeJSON(path, hooks)
+}
+
+// TakePendingHooks returns all queued hooks and clears the queue
+func (s *JSONStore) TakePendingHooks() ([]types.PendingHook, error) {
+	s.lock(storeHooks).Lock()
+	defer s.lock(storeHooks).Unlock()
+
+	path := filepath.Join(s.basePath, "cache", "pending-hooks.json")
+
+	hooks, err := readJSON[[]types.PendingHook](path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if hooks == nil || len(*hooks) == 0 {
+		return nil, nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return *hooks, nil
+}
+
+// --- Search Feedback ---
+
+// maxSearchFeedback caps the feedback log; the oldest judgments are dropped first
+const maxSearchFeedback = 5000
+
+// GetSearchFeedback returns all recorded relevance feedback
+func (s *JSONStore) GetSearchFeedback() ([]types.SearchFeedback, error) {
+	s.lock(storeFeedback).RLock()
+	defer s.lock(storeFeedback).RUnlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "feedback.json")
+	result, err := readJSON[[]types.SearchFeedback](path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.SearchFeedback{}, nil
+		}
+		return nil, err
+	}
+	if result == nil {
+		return []types.SearchFeedback{}, nil
+	}
+	return *result, nil
+}
+
+// AddSearchFeedback records a judgment. A new judgment of the same result for
+// the same query replaces the earlier one.
+func (s *JSONStore) AddSearchFeedback(fb *types.SearchFeedback) error {
+	s.lock(storeFeedback).Lock()
+	defer s.lock(storeFeedback).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "feedback.json")
+
+	feedback, err := readJSON[[]types.SearchFeedback](path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if feedback == nil {
+		empty := []types.SearchFeedback{}
+		feedback = &empty
+	}
+
+	fb.ID = generateID("fb")
+	fb.CreatedAt = time.Now()
+
+	query := strings.ToLower(strings.TrimSpace(fb.Query))
+	kept := (*feedback)[:0]
+	for _, existing := range *feedback {
+		if existing.DocType == fb.DocType && existing.DocID == fb.DocID &&
+			strings.ToLower(strings.TrimSpace(existing.Query)) == query {
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	kept = append(kept, *fb)
+	if len(kept) > maxSearchFeedback {
+		kept = kept[len(kept)-maxSearchFeedback:]
+	}
+
+	return writeJSON(path, kept)
+}
+
+// --- Issues ---
+
+// GetIssues returns cached issue tracker metadata
+func (s *JSONStore) GetIssues() ([]types.Issue, error) {
+	s.lock(storeIssues).RLock()
+	defer s.lock(storeIssues).RUnlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "issues.json")
+	result, err := readJSON[[]types.Issue](path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.Issue{}, nil
+		}
+		return nil, err
+	}
+	if result == nil {
+		return []types.Issue{}, nil
+	}
+	return *result, nil
+}
+
+// SaveIssue adds or replaces the cached metadata of an issue by key
+func (s *JSONStore) SaveIssue(issue *types.Issue) error {
+	s.lock(storeIssues).Lock()
+	defer s.lock(storeIssues).Unlock()
+
+	path := filepath.Join(s.basePath, "knowledge", "issues.json")
+	issues, err := readJSON[[]types.Issue](path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if issues == nil {
+		empty := []types.Issue{}
+		issues = &empty
+	}
+
+	for i := range *issues {
+		if (*issues)[i].Key == issue.Key {
+			(*issues)[i] = *issue
+			return writeJSON(path, issues)
+		}
+	}
+	*issues = append(*issues, *issue)
+	return writeJSON(path, issues)
+}
+
+// LinkIssue adds an issue key to a decision, warning or feature
+func (s *JSONStore) LinkIssue(itemType, id, key string) error {
+	store, ok := itemStores[itemType]
+	if !ok {
+		return fmt.Errorf("unsupported item type: %s", itemType)
+	}
+	s.lock(store).Lock()
+	defer s.lock(store).Unlock()
+
+	link := func(keys []string) []string {
+		for _, k := range keys {
+			if k == key {
+				return keys
+			}
+		}
+		return append(keys, key)
+	}
+
+	switch itemType {
+	case "decision":
+		path := filepath.Join(s.basePath, "knowledge", "decisions.json")
+		decisions, err := readJSON[[]types.Decision](path)
+		if err != nil {
+			return err
+		}
+		for i := range *decisions {
+			if (*decisions)[i].ID == id {
+				(*decisions)[i].Issues = link((*decisions)[i].Issues)
+				return writeJSON(path, decisions)
+			}
+		}
+	case "warning":
+		path := filepath.Join(s.basePath, "knowledge", "warnings.json")
+		warnings, err := readJSON[[]types.Warning](path)
+		if err != nil {
+			return err
+		}
+		for i := range *warnings {
+			if (*warnings)[i].ID == id {
+				(*warnings)[i].Issues = link((*warnings)[i].Issues)
+				return writeJSON(path, warnings)
+			}
+		}
+	case "feature":
+		path := filepath.Join(s.basePath, "features", id, "meta.json")
+		feature, err := readJSON[types.Feature](path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("feature not found: %s", id)
+			}
+			return err
+		}
+		feature.Issues = link(feature.Issues)
+		return writeJSON(path, feature)
+	default:
+		return fmt.Errorf("unsupported item type: %s", itemType)
+	}
+	return fmt.Errorf("%s not found: %s", itemType, id)
+}
+
+// --- Token Spend ---
+// Token spend is per developer, so it lives in cache/ next to other local state.
+
+// MergeTokenSpend adds spend recorded since the last merge (keyed by
+// feature ID) to the token ledger
+func (s *JSONStore) MergeTokenSpend(pending map[string]*types.FeatureTokenSpend) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	s.lock(storeTokenSpend).Lock()
+	defer s.lock(storeTokenSpend).Unlock()
+
+	path := filepath.Join(s.basePath, "cache", "token-spend.json")
+
+	ledger, err := readJSON[map[string]*types.FeatureTokenSpend](path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if ledger == nil || *ledger == nil {
+		ledger = &map[string]*types.FeatureTokenSpend{}
+	}
+
+	for feature, add := range pending {
+		spend, ok := (*ledger)[feature]
+		if !ok {
+			spend = &types.FeatureTokenSpend{Feature: feature, FirstSeen: add.FirstSeen}
+			(*ledger)[feature] = spend
+		}
+		if spend.ByTool == nil {
+			spend.ByTool = make(map[string]*types.ToolTokenSpend)
+		}
+		if spend.ByArea == nil {
+			spend.ByArea = make(map[string]int)
+		}
+
+		spend.ToolCalls += add.ToolCalls
+		spend.TokensUsed += add.TokensUsed
+		spend.TokensSaved += add.TokensSaved
+		if add.LastSeen.After(spend.LastSeen) {
+			spend.LastSeen = add.LastSeen
+		}
+		for tool, a := range add.ByTool {
+			ts, ok := spend.ByTool[tool]
+			if !ok {
+				ts = &types.ToolTokenSpend{}
+				spend.ByTool[tool] = ts
+			}
+			ts.Calls += a.Calls
+			ts.TokensUsed += a.TokensUsed
+			ts.TokensSaved += a.TokensSaved
+		}
+		for area, used := range add.ByArea {
+			spend.ByArea[area] += used
+		}
+	}
+
+	return writeJSON(path, ledger)
+}
+
+// GetTokenSpend returns the token ledger keyed by feature ID
+func (s *JSONStore) GetTokenSpend() (map[string]*types.FeatureTokenSpend, error) {
+	s.lock(storeTokenSpend).RLock()
+	defer s.lock(storeTokenSpend).RUnlock()
+
+	path := filepath.Join(s.basePath, "cache", "token-spend.json")
+	ledger, err := readJSON[map[string]*types.FeatureTokenSpend](path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*types.FeatureTokenSpend{}, nil
+		}
+		return nil, err
+	}
+	if ledger == nil || *ledger == nil {
+		return map[string]*types.FeatureTokenSpend{}, nil
+	}
+	return *ledger, nil
+}
+
 // --- Architecture ---
 
 func (s *JSONStore) GetArchitecture() (*types.Architecture, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeArchitecture).RLock()
+	defer s.lock(storeArchitecture).RUnlock()
 
 	path := filepath.Join(s.basePath, "index", "architecture.json")
 	result, err := readJSON[types.Architecture](path)
@@ -812,8 +1674,8 @@ func (s *JSONStore) GetArchitecture() (*types.Architecture, error) {
 }
 
 func (s *JSONStore) SaveArchitecture(arch *types.Architecture) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeArchitecture).Lock()
+	defer s.lock(storeArchitecture).Unlock()
 
 	arch.UpdatedAt = time.Now()
 	path := filepath.Join(s.basePath, "index", "architecture.json")
@@ -823,8 +1685,8 @@ func (s *JSONStore) SaveArchitecture(arch *types.Architecture) error {
 // --- API Endpoints ---
 
 func (s *JSONStore) GetApiEndpoints() ([]types.ApiEndpoint, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.lock(storeEndpoints).RLock()
+	defer s.lock(storeEndpoints).RUnlock()
 
 	path := filepath.Join(s.basePath, "index", "api-surface.json")
 	result, err := readJSON[[]types.ApiEndpoint](path)
@@ -841,8 +1703,8 @@ func (s *JSONStore) GetApiEndpoints() ([]types.ApiEndpoint, error) {
 }
 
 func (s *JSONStore) SaveApiEndpoints(endpoints []types.ApiEndpoint) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.lock(storeEndpoints).Lock()
+	defer s.lock(storeEndpoints).Unlock()
 
 	path := filepath.Join(s.basePath, "index", "api-surface.json")
 	return writeJSON(path, endpoints)
@@ -874,7 +1736,10 @@ func (s *JSONStore) GetStats() (*types.Stats, error) {
 		totalConversations += len(convs)
 	}
 
+	contributors := s.Contributors(time.Time{})
 	return &types.Stats{
+		Contributors:     contributors,
+		AgentShare:       AgentShare(contributors),
 		FilesIndexed:     len(files),
 		Decisions:        len(decisions),
 		Warnings:         len(warnings),
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -73,6 +76,55 @@ func TestConfigSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestSensitivePathsFor(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	if got := store.SensitivePathsFor("services/payments/charge.go"); got != nil {
+		t.Errorf("Expected no policies without config, got %v", got)
+	}
+
+	config := &types.Config{
+		Name: "test-project",
+		SensitivePaths: []types.SensitivePath{
+			{Path: "services/payments", Reason: "PCI scope", Reviewers: []string{"@payments-team"}},
+			{Path: "apps/*/auth/", Warnings: []string{"Never log tokens"}},
+		},
+	}
+	if err := store.SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	cases := []struct {
+		paths []string
+		want  []string
+	}{
+		{[]string{"services/payments/charge.go"}, []string{"services/payments"}},
+		{[]string{"./services/payments"}, []string{"services/payments"}},
+		{[]string{"services"}, []string{"services/payments"}}, // contains a sensitive directory
+		{[]string{"services/payments-legacy/old.go"}, nil},
+		{[]string{"apps/web/auth/login.ts"}, []string{"apps/*/auth/"}},
+		{[]string{"apps/web/profile.ts"}, nil},
+		{[]string{"README.md", "apps/api/auth/jwt.ts", "services/payments/x.go"}, []string{"services/payments", "apps/*/auth/"}},
+	}
+	for _, c := range cases {
+		got := store.SensitivePathsFor(c.paths...)
+		var paths []string
+		for _, policy := range got {
+			paths = append(paths, policy.Path)
+		}
+		if len(paths) != len(c.want) {
+			t.Errorf("SensitivePathsFor(%v) = %v, want %v", c.paths, paths, c.want)
+			continue
+		}
+		for i := range paths {
+			if paths[i] != c.want[i] {
+				t.Errorf("SensitivePathsFor(%v) = %v, want %v", c.paths, paths, c.want)
+			}
+		}
+	}
+}
+
 // =============================================================================
 // DECISION TESTS
 // =============================================================================
@@ -148,6 +200,49 @@ func TestDecisionMultiple(t *testing.T) {
 	}
 }
 
+func TestDecisionIdempotent(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	
+	first := &types.Decision{Content: "Use Zod for validation", Reason: "Better TypeScript inference"}
+	if err := store.AddDecision(first); err != nil {
+		t.Fatalf("AddDecision failed: %v", err)
+	}
+	
+	// A retry, even re-wrapped, gets the first decision back
+	retry := &types.Decision{Content: "use zod  for\nvalidation", Reason: "Retried"}
+	if err := store.AddDecision(retry); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate, got %v", err)
+	}
+	if retry.ID != first.ID || retry.Reason != "Better TypeScript inference" {
+		t.Errorf("retry = %+v, want the stored decision %s", retry, first.ID)
+	}
+	
+	// The same content in another feature is another decision
+	other := &types.Decision{Content: "Use Zod for validation", Reason: "Same", Feature: "billing"}
+	if err := store.AddDecision(other); err != nil || other.ID == first.ID {
+		t.Errorf("feature decision = %s, %v", other.ID, err)
+	}
+	
+	// Idempotency keys identify writes regardless of content
+	keyed := &types.Decision{Content: "Cache sessions in Redis", Reason: "Speed", IdempotencyKey: "req-42"}
+	if err := store.AddDecision(keyed); err != nil {
+		t.Fatalf("AddDecision failed: %v", err)
+	}
+	again := &types.Decision{Content: "Cache sessions in Redis for 1h", Reason: "Speed", IdempotencyKey: "req-42"}
+	if err := store.AddDecision(again); !errors.Is(err, ErrDuplicate) || again.ID != keyed.ID {
+		t.Errorf("keyed retry = %s, %v", again.ID, err)
+	}
+	if keyed.ID != DecisionID(keyed) || !strings.HasPrefix(keyed.ID, "dec-") {
+		t.Errorf("keyed ID = %s", keyed.ID)
+	}
+	
+	decisions, _ := store.GetDecisions()
+	if len(decisions) != 3 {
+		t.Errorf("Expected 3 decisions, got %d", len(decisions))
+	}
+}
+
 // =============================================================================
 // WARNING TESTS
 // =============================================================================
@@ -190,6 +285,57 @@ func TestWarningCRUD(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// INSIGHT TESTS
+// =============================================================================
+
+func TestInsightReview(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	var ids []string
+	for _, content := range []string{"Retries hide flaky upstreams", "Cache keys include the tenant", "Typo in README"} {
+		ins := &types.Insight{Content: content}
+		if err := store.AddInsight(ins); err != nil {
+			t.Fatalf("AddInsight failed: %v", err)
+		}
+		ids = append(ids, ins.ID)
+	}
+
+	if err := store.PromoteInsight(ids[1], "dec-123"); err != nil {
+		t.Fatalf("PromoteInsight failed: %v", err)
+	}
+	removed, err := store.DeleteInsight(ids[2])
+	if err != nil || removed.Content != "Typo in README" {
+		t.Fatalf("DeleteInsight returned %+v (%v)", removed, err)
+	}
+
+	unreviewed, err := store.GetUnreviewedInsights()
+	if err != nil {
+		t.Fatalf("GetUnreviewedInsights failed: %v", err)
+	}
+	if len(unreviewed) != 1 || unreviewed[0].ID != ids[0] {
+		t.Errorf("Expected only %s to await review, got %+v", ids[0], unreviewed)
+	}
+
+	insights, _ := store.GetInsights()
+	if len(insights) != 2 {
+		t.Fatalf("Expected the promoted insight to be kept, got %d insights", len(insights))
+	}
+	for _, ins := range insights {
+		if ins.ID == ids[1] && (ins.PromotedTo != "dec-123" || ins.ReviewedAt == nil) {
+			t.Errorf("Expected the insight to be marked as promoted, got %+v", ins)
+		}
+	}
+
+	if err := store.PromoteInsight("ins-missing", "dec-1"); err == nil {
+		t.Error("Expected an error for an unknown insight")
+	}
+	if _, err := store.DeleteInsight(ids[2]); err == nil {
+		t.Error("Expected an error for an already deleted insight")
+	}
+}
+
 // =============================================================================
 // FEATURE TESTS
 // =============================================================================
@@ -308,6 +454,93 @@ func TestFileIndexCRUD(t *testing.T) {
 	}
 }
 
+func TestFileIndexTombstones(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	for _, path := range []string{"src/a.ts", "src/b.ts"} {
+		if err := store.SaveFileIndex(&types.FileIndex{Path: path, Language: "typescript"}); err != nil {
+			t.Fatalf("SaveFileIndex failed: %v", err)
+		}
+	}
+
+	if err := store.MarkFileDeleted("src/a.ts"); err != nil {
+		t.Fatalf("MarkFileDeleted failed: %v", err)
+	}
+
+	// Live index excludes the tombstone
+	files, _ := store.GetFilesIndex()
+	if _, ok := files["src/a.ts"]; ok || len(files) != 1 {
+		t.Errorf("Expected only src/b.ts in live index, got %v", files)
+	}
+
+	// Direct lookups still resolve it
+	f, err := store.GetFileIndex("src/a.ts")
+	if err != nil || f.DeletedAt == nil {
+		t.Fatalf("Expected tombstone for src/a.ts, got %v (err %v)", f, err)
+	}
+
+	// Retention not reached: nothing purged
+	purged, _ := store.PurgeDeletedFiles(time.Hour)
+	if len(purged) != 0 {
+		t.Errorf("Expected no purge within retention, got %v", purged)
+	}
+
+	// Re-saving revives the file
+	store.SaveFileIndex(&types.FileIndex{Path: "src/a.ts"})
+	if deleted, _ := store.GetDeletedFiles(); len(deleted) != 0 {
+		t.Errorf("Expected revived file to clear tombstone, got %v", deleted)
+	}
+
+	// Zero retention purges tombstones
+	store.MarkFileDeleted("src/a.ts")
+	purged, err = store.PurgeDeletedFiles(0)
+	if err != nil || len(purged) != 1 {
+		t.Fatalf("Expected 1 purged file, got %v (err %v)", purged, err)
+	}
+	if _, err := store.GetFileIndex("src/a.ts"); err == nil {
+		t.Error("Expected purged file to be gone")
+	}
+}
+
+func TestFileIndexWindowsPaths(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	// Paths arriving from Windows are stored with forward slashes
+	if err := store.SaveFileIndex(&types.FileIndex{Path: `src\auth\login.ts`}); err != nil {
+		t.Fatalf("SaveFileIndex failed: %v", err)
+	}
+	store.SaveFilesIndexBulk(map[string]types.FileIndex{
+		`.\src\auth\logout.ts`: {Path: `.\src\auth\logout.ts`},
+	})
+	files, _ := store.GetFilesIndex()
+	if _, ok := files["src/auth/logout.ts"]; !ok || len(files) != 1 {
+		t.Fatalf("Expected src/auth/logout.ts after bulk save, got %v", files)
+	}
+
+	// Lookups accept either separator
+	f, err := store.GetFileIndex(`src\auth\logout.ts`)
+	if err != nil || f.Path != "src/auth/logout.ts" {
+		t.Fatalf("Expected lookup by Windows path to resolve, got %v (err %v)", f, err)
+	}
+	if err := store.MarkFileDeleted(`src\auth\logout.ts`); err != nil {
+		t.Fatalf("MarkFileDeleted failed: %v", err)
+	}
+	if deleted, _ := store.GetDeletedFiles(); len(deleted) != 1 {
+		t.Errorf("Expected tombstone for src/auth/logout.ts, got %v", deleted)
+	}
+
+	// File nodes in the graph are normalized the same way
+	store.AddEdge(&types.Edge{FromType: "decision", FromID: "dec-001", ToType: "file", ToID: `src\auth\login.ts`, Relation: "affects"})
+	if edges, _ := store.GetEdgesTo("file", "src/auth/login.ts"); len(edges) != 1 {
+		t.Errorf("Expected 1 edge to src/auth/login.ts, got %v", edges)
+	}
+	if edges, _ := store.GetEdgesTo("file", `src\auth\login.ts`); len(edges) != 1 {
+		t.Errorf("Expected lookup by Windows path to find the edge, got %v", edges)
+	}
+}
+
 // =============================================================================
 // EVOLUTION EVENT TESTS
 // =============================================================================
@@ -380,6 +613,140 @@ func TestKnowledgeGraphEdges(t *testing.T) {
 	}
 }
 
+func TestKnowledgeGraphValidation(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	invalid := []types.Edge{
+		{FromType: "decision", FromID: "dec-001", ToType: "decision", ToID: "dec-001", Relation: "related_to"},
+		{FromType: "decision", FromID: "dec-001", ToType: "file", ToID: "a.go", Relation: "warns"},
+		{FromType: "file", FromID: "a.go", ToType: "file", ToID: "b.go", Relation: "calls_into"},
+		{FromType: "file", FromID: "", ToType: "file", ToID: "b.go", Relation: "imports"},
+	}
+	for _, e := range invalid {
+		e := e
+		if err := store.AddEdge(&e); !errors.Is(err, ErrInvalidEdge) {
+			t.Errorf("AddEdge(%+v) = %v, want ErrInvalidEdge", e, err)
+		}
+	}
+
+	chain := []types.Edge{
+		{FromType: "decision", FromID: "dec-003", ToType: "decision", ToID: "dec-002", Relation: "supersedes"},
+		{FromType: "decision", FromID: "dec-002", ToType: "decision", ToID: "dec-001", Relation: "supersedes"},
+	}
+	for _, e := range chain {
+		e := e
+		if err := store.AddEdge(&e); err != nil {
+			t.Fatalf("AddEdge failed: %v", err)
+		}
+	}
+	cycle := types.Edge{FromType: "decision", FromID: "dec-001", ToType: "decision", ToID: "dec-003", Relation: "supersedes"}
+	if err := store.AddEdge(&cycle); !errors.Is(err, ErrInvalidEdge) {
+		t.Errorf("AddEdge closing a supersedes cycle = %v, want ErrInvalidEdge", err)
+	}
+
+	// Bulk adds skip invalid edges and duplicates instead of failing
+	imports := types.Edge{FromType: "file", FromID: "a.go", ToType: "file", ToID: "b.go", Relation: "imports"}
+	if err := store.AddEdgesBulk([]types.Edge{imports, imports, invalid[0]}); err != nil {
+		t.Fatalf("AddEdgesBulk failed: %v", err)
+	}
+	graph, _ := store.GetKnowledgeGraph()
+	if len(graph.Edges) != 3 {
+		t.Errorf("Expected 3 edges, got %d: %+v", len(graph.Edges), graph.Edges)
+	}
+}
+
+func TestGraphSnapshots(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	edges := []types.Edge{
+		{FromType: "file", FromID: "src/api/handlers/user.go", ToType: "file", ToID: "src/billing/invoice.go", Relation: "imports"},
+		{FromType: "file", FromID: "src/api/routes.go", ToType: "file", ToID: "src/billing/plan.go", Relation: "imports"},
+		{FromType: "file", FromID: "src/api/routes.go", ToType: "file", ToID: "src/api/handlers/user.go", Relation: "imports"},
+		{FromType: "file", FromID: "main.go", ToType: "file", ToID: "src/api/routes.go", Relation: "imports"},
+		{FromType: "decision", FromID: "dec-001", ToType: "file", ToID: "src/billing/plan.go", Relation: "affects"},
+	}
+	if err := store.AddEdgesBulk(edges); err != nil {
+		t.Fatalf("AddEdgesBulk failed: %v", err)
+	}
+
+	snap, added, err := store.SnapshotGraph("abc123")
+	if err != nil || !added {
+		t.Fatalf("SnapshotGraph = %v, %v", added, err)
+	}
+	if snap.Edges != 5 || snap.Relations["imports"] != 4 || snap.Relations["affects"] != 1 {
+		t.Errorf("Unexpected counts: %+v", snap)
+	}
+	// Imports inside an area and from the project root are not coupling
+	if len(snap.Coupling) != 1 || snap.Coupling["src/api -> src/billing"] != 2 {
+		t.Errorf("Unexpected coupling: %v", snap.Coupling)
+	}
+
+	// An unchanged graph is not recorded twice
+	if _, added, _ := store.SnapshotGraph("def456"); added {
+		t.Error("Expected identical snapshot to be skipped")
+	}
+
+	// Documented services name areas instead of directories
+	store.SaveArchitecture(&types.Architecture{Services: []types.ServiceNode{
+		{Name: "api", Files: []string{"src/api"}},
+		{Name: "billing", Files: []string{"src/billing/"}},
+	}})
+	if _, added, _ := store.SnapshotGraph("def456"); !added {
+		t.Error("Expected snapshot after architecture change")
+	}
+	history, err := store.GetGraphHistory()
+	if err != nil {
+		t.Fatalf("GetGraphHistory failed: %v", err)
+	}
+	if len(history.Snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(history.Snapshots))
+	}
+	if got := history.Snapshots[1].Coupling["api -> billing"]; got != 2 {
+		t.Errorf("Expected api -> billing coupling 2, got %d", got)
+	}
+	if history.Snapshots[0].Commit != "abc123" {
+		t.Errorf("Expected commit abc123, got %q", history.Snapshots[0].Commit)
+	}
+}
+
+func TestAuditGraph(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	// Written directly, as older versions or hand edits would have
+	graph := types.KnowledgeGraph{Edges: []types.Edge{
+		{FromType: "file", FromID: "a.go", ToType: "file", ToID: "b.go", Relation: "imports"},
+		{FromType: "file", FromID: "a.go", ToType: "file", ToID: "b.go", Relation: "imports"},
+		{FromType: "file", FromID: "a.go", ToType: "file", ToID: "a.go", Relation: "imports"},
+		{FromType: "decision", FromID: "dec-001", ToType: "decision", ToID: "dec-002", Relation: "supersedes"},
+		{FromType: "decision", FromID: "dec-002", ToType: "decision", ToID: "dec-001", Relation: "supersedes"},
+	}}
+	if err := writeJSON(filepath.Join(store.basePath, "knowledge", "graph.json"), &graph); err != nil {
+		t.Fatal(err)
+	}
+
+	audit, err := store.AuditGraph(false)
+	if err != nil {
+		t.Fatalf("AuditGraph failed: %v", err)
+	}
+	if len(audit.Invalid) != 1 || len(audit.Duplicates) != 1 || len(audit.Cycles) != 1 || audit.Fixed {
+		t.Fatalf("unexpected audit: %+v", audit)
+	}
+
+	if _, err := store.AuditGraph(true); err != nil {
+		t.Fatalf("AuditGraph fix failed: %v", err)
+	}
+	fixed, _ := store.GetKnowledgeGraph()
+	if len(fixed.Edges) != 2 {
+		t.Errorf("Expected 2 edges after fix, got %d: %+v", len(fixed.Edges), fixed.Edges)
+	}
+	if again, _ := store.AuditGraph(false); again.Problems() != 0 {
+		t.Errorf("Expected a clean graph after fix, got %+v", again)
+	}
+}
+
 // =============================================================================
 // CONVERSATION TESTS
 // =============================================================================
@@ -429,6 +796,142 @@ func TestConversationCRUD(t *testing.T) {
 	}
 }
 
+func TestReplaceConversations(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	if err := store.CreateFeature(&types.Feature{ID: "payments", Status: "active"}); err != nil {
+		t.Fatalf("CreateFeature failed: %v", err)
+	}
+	first := &types.Conversation{Feature: "payments", Summary: "Retry design", KeyPoints: []string{"Retry with backoff"}}
+	second := &types.Conversation{Feature: "payments", Summary: "Retry limits", KeyPoints: []string{"Cap retries at 5"}}
+	for _, c := range []*types.Conversation{first, second} {
+		if err := store.SaveConversation(c); err != nil {
+			t.Fatalf("SaveConversation failed: %v", err)
+		}
+	}
+
+	merged := &types.Conversation{
+		Summary:    "Retry design and limits",
+		KeyPoints:  []string{"Retry with backoff", "Cap retries at 5"},
+		MergedFrom: []string{first.ID, second.ID},
+	}
+	if err := store.ReplaceConversations("payments", []*types.Conversation{merged}, merged.MergedFrom); err != nil {
+		t.Fatalf("ReplaceConversations failed: %v", err)
+	}
+	if merged.ID == "" || merged.Feature != "payments" {
+		t.Errorf("merged conversation not initialized: %+v", merged)
+	}
+
+	convs, err := store.GetConversations("payments")
+	if err != nil {
+		t.Fatalf("GetConversations failed: %v", err)
+	}
+	if len(convs) != 1 || convs[0].ID != merged.ID {
+		t.Fatalf("Expected only the merged conversation, got %+v", convs)
+	}
+	if len(convs[0].MergedFrom) != 2 {
+		t.Errorf("Expected merged_from to list 2 sources, got %v", convs[0].MergedFrom)
+	}
+}
+
+func TestSearchFeedback(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	votes := []types.SearchFeedback{
+		{Query: "jwt refresh", DocType: "file", DocID: "src/auth/jwt.ts", Helpful: false},
+		{Query: "retry policy", DocType: "file", DocID: "src/auth/jwt.ts", Helpful: false},
+		{Query: "JWT Refresh ", DocType: "file", DocID: "src/auth/jwt.ts", Helpful: true},
+	}
+	for i := range votes {
+		if err := store.AddSearchFeedback(&votes[i]); err != nil {
+			t.Fatalf("AddSearchFeedback failed: %v", err)
+		}
+	}
+
+	feedback, err := store.GetSearchFeedback()
+	if err != nil {
+		t.Fatalf("GetSearchFeedback failed: %v", err)
+	}
+
+	// Re-rating the same result for the same query replaces the earlier vote
+	if len(feedback) != 2 {
+		t.Fatalf("Expected 2 feedback entries, got %d", len(feedback))
+	}
+	if feedback[1].Query != "JWT Refresh " || !feedback[1].Helpful {
+		t.Errorf("Expected the latest vote to win, got %+v", feedback[1])
+	}
+	if feedback[0].ID == "" || feedback[0].CreatedAt.IsZero() {
+		t.Error("Feedback ID and CreatedAt should be set")
+	}
+}
+
+func TestAddTranslation(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	dec := &types.Decision{Content: "Use JWT", Reason: "Stateless auth", Language: "en"}
+	if err := store.AddDecision(dec); err != nil {
+		t.Fatalf("AddDecision failed: %v", err)
+	}
+
+	if err := store.AddTranslation("decision", dec.ID, "de", types.Translation{Content: "JWT verwenden", Reason: "Zustandslos"}); err != nil {
+		t.Fatalf("AddTranslation failed: %v", err)
+	}
+	// A later partial translation keeps the fields it doesn't set
+	if err := store.AddTranslation("decision", dec.ID, "de", types.Translation{Reason: "Zustandslose Authentifizierung"}); err != nil {
+		t.Fatalf("AddTranslation failed: %v", err)
+	}
+
+	decisions, _ := store.GetDecisions()
+	de := decisions[0].Translations["de"]
+	if de.Content != "JWT verwenden" || de.Reason != "Zustandslose Authentifizierung" {
+		t.Errorf("Unexpected translation: %+v", de)
+	}
+
+	if err := store.AddTranslation("decision", "dec-missing", "de", types.Translation{Content: "x"}); err == nil {
+		t.Error("Expected an error for an unknown decision")
+	}
+	if err := store.AddTranslation("pattern", dec.ID, "de", types.Translation{Content: "x"}); err == nil {
+		t.Error("Expected an error for an unsupported item type")
+	}
+}
+
+func TestLinkIssue(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	dec := &types.Decision{Content: "Retry payouts with backoff", Reason: "Provider rate limits"}
+	if err := store.AddDecision(dec); err != nil {
+		t.Fatalf("AddDecision failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := store.LinkIssue("decision", dec.ID, "PAY-142"); err != nil {
+			t.Fatalf("LinkIssue failed: %v", err)
+		}
+	}
+	decisions, _ := store.GetDecisions()
+	if len(decisions[0].Issues) != 1 || decisions[0].Issues[0] != "PAY-142" {
+		t.Errorf("Expected a single PAY-142 link, got %v", decisions[0].Issues)
+	}
+
+	if err := store.SaveIssue(&types.Issue{Key: "PAY-142", Provider: "jira", Status: "To Do"}); err != nil {
+		t.Fatalf("SaveIssue failed: %v", err)
+	}
+	if err := store.SaveIssue(&types.Issue{Key: "PAY-142", Provider: "jira", Status: "Done"}); err != nil {
+		t.Fatalf("SaveIssue failed: %v", err)
+	}
+	issues, _ := store.GetIssues()
+	if len(issues) != 1 || issues[0].Status != "Done" {
+		t.Errorf("Expected the cached issue to be replaced, got %+v", issues)
+	}
+
+	if err := store.LinkIssue("feature", "missing-feature", "PAY-142"); err == nil {
+		t.Error("Expected an error for an unknown feature")
+	}
+}
+
 // =============================================================================
 // CONCURRENT ACCESS TESTS
 // =============================================================================
@@ -469,3 +972,215 @@ func TestConcurrentDecisionWrites(t *testing.T) {
 		t.Errorf("Expected 10 decisions after concurrent writes, got %d", len(decisions))
 	}
 }
+
+func TestIndexCapReport(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	if report, err := store.GetIndexCapReport(); err != nil || report != nil {
+		t.Fatalf("expected no report before the first run, got %+v, %v", report, err)
+	}
+
+	report := &types.IndexCapReport{
+		MaxFiles:      2,
+		FilesFound:    3,
+		FilesIndexed:  2,
+		FilesSkipped:  1,
+		SkippedByKind: map[string]int{"config": 1},
+		SkippedSample: []string{"conf.json"},
+	}
+	if err := store.SaveIndexCapReport(report); err != nil {
+		t.Fatalf("SaveIndexCapReport failed: %v", err)
+	}
+	got, err := store.GetIndexCapReport()
+	if err != nil || got == nil {
+		t.Fatalf("GetIndexCapReport failed: %v", err)
+	}
+	if got.FilesSkipped != 1 || got.SkippedByKind["config"] != 1 || !got.Degraded() {
+		t.Errorf("report not round-tripped: %+v", got)
+	}
+	if (&types.IndexCapReport{FilesFound: 3, FilesIndexed: 3}).Degraded() {
+		t.Error("a complete index is not degraded")
+	}
+}
+
+func TestFilesNeedingSummary(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	files := []types.FileIndex{
+		{Path: "a.go", Summary: "2 function(s): Load, Save", SummarySource: types.SummarySourceAuto, Exports: []types.Export{{Name: "Load"}, {Name: "Save"}}},
+		{Path: "b.go", Summary: types.SummaryPlaceholder, SummarySource: types.SummarySourceAuto},
+		{Path: "c.go", Summary: "Parses the config file", SummarySource: types.SummarySourceAgent},
+		{Path: "d.go", Summary: "1 function(s): Run"}, // indexed before summary_source
+		{Path: "README.md", Summary: "Project - What it does"},
+	}
+	for i := range files {
+		if err := store.SaveFileIndex(&files[i]); err != nil {
+			t.Fatalf("SaveFileIndex failed: %v", err)
+		}
+	}
+	store.AddEdge(&types.Edge{FromType: "file", FromID: "d.go", ToType: "file", ToID: "a.go", Relation: "imports"})
+
+	// Reindexing must not replace what an agent wrote
+	reindexed := types.FileIndex{Path: "c.go", Summary: "1 function(s): Parse", SummarySource: types.SummarySourceAuto}
+	if err := store.SaveFileIndex(&reindexed); err != nil {
+		t.Fatalf("SaveFileIndex failed: %v", err)
+	}
+	if c, _ := store.GetFileIndex("c.go"); c == nil || c.Summary != "Parses the config file" {
+		t.Errorf("agent summary overwritten: %+v", c)
+	}
+
+	queue, err := store.FilesNeedingSummary(map[string]int{"b.go": 1})
+	if err != nil {
+		t.Fatalf("FilesNeedingSummary failed: %v", err)
+	}
+	var got []string
+	for _, c := range queue {
+		got = append(got, c.Path+":"+c.Reason)
+	}
+	want := []string{"b.go:placeholder", "a.go:heuristic", "d.go:heuristic"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("queue = %v, want %v", got, want)
+	}
+	if len(queue) > 1 && queue[1].Importers != 1 {
+		t.Errorf("a.go importers = %d, want 1", queue[1].Importers)
+	}
+}
+
+func TestContributors(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	alice := "Alice <alice@example.com>"
+	store.AddDecision(&types.Decision{Content: "Use Postgres", Author: alice})
+	store.AddDecision(&types.Decision{Content: "Use JWT", Author: alice})
+	store.AddWarning(&types.Warning{Content: "Don't touch billing", Author: "ci-bot"})
+	store.AddInsight(&types.Insight{Content: "Cache is cold on deploy"})
+	if err := store.CreateFeature(&types.Feature{ID: "auth", Status: "active"}); err != nil {
+		t.Fatalf("CreateFeature failed: %v", err)
+	}
+	store.SaveConversation(&types.Conversation{Feature: "auth", Summary: "Token refresh", Author: alice})
+
+	lastMonth := time.Now().AddDate(0, -1, 0)
+	store.SaveFilesIndexBulk(map[string]types.FileIndex{
+		"src/auth.go": {Path: "src/auth.go", Summary: "Issues tokens", SummarySource: types.SummarySourceAgent, SummaryAuthor: alice, SummarizedAt: &lastMonth},
+		"src/main.go": {Path: "src/main.go", Summary: types.SummaryPlaceholder, SummarySource: types.SummarySourceAuto},
+	})
+
+	stats, err := store.GetStats()
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	want := []types.AuthorStats{
+		{Author: alice, Decisions: 2, FileSummaries: 1, Conversations: 1, Total: 4},
+		{Author: types.UnattributedAuthor, Insights: 1, Total: 1, Agent: true},
+		{Author: "ci-bot", Warnings: 1, Total: 1, Agent: true},
+	}
+	if len(stats.Contributors) != len(want) {
+		t.Fatalf("contributors = %+v, want %+v", stats.Contributors, want)
+	}
+	for i := range want {
+		if stats.Contributors[i] != want[i] {
+			t.Errorf("contributor %d = %+v, want %+v", i, stats.Contributors[i], want[i])
+		}
+	}
+	if stats.AgentShare != 0.33 {
+		t.Errorf("agent share = %v, want 0.33", stats.AgentShare)
+	}
+
+	// The summary written last month falls outside a one-week window
+	recent := store.Contributors(time.Now().AddDate(0, 0, -7))
+	if len(recent) != 3 || recent[0].Author != alice || recent[0].FileSummaries != 0 || recent[0].Total != 3 {
+		t.Errorf("contributors this week = %+v, want alice's summary left out", recent)
+	}
+}
+
+func TestIsAgentAuthor(t *testing.T) {
+	tests := map[string]bool{
+		"":                              true,
+		"Alice <alice@example.com>":     false,
+		"Abbot Costello":                false,
+		"ci-bot":                        true,
+		"dependabot[bot]":               true,
+		"Release Agent <agent@corp.io>": true,
+		"Bob <bob@agent.example.com>":   false,
+		types.UnattributedAuthor:        true,
+	}
+	for author, want := range tests {
+		if got := IsAgentAuthor(author); got != want {
+			t.Errorf("IsAgentAuthor(%q) = %v, want %v", author, got, want)
+		}
+	}
+}
+
+func TestStoreLocksAreIndependent(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	// A long write to the files index must not hold up other stores
+	store.lock(storeFiles).Lock()
+	done := make(chan error, 1)
+	go func() {
+		done <- store.AddDecision(&types.Decision{Content: "Use Postgres"})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("AddDecision failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("AddDecision waited for the files index lock")
+	}
+	store.lock(storeFiles).Unlock()
+
+	if store.lock(storeFiles) != store.lock(storeFiles) {
+		t.Error("a store should always get the same lock")
+	}
+}
+
+func TestConcurrentProjectUpdates(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	const writers = 10
+	errs := make(chan error, writers*2)
+	for i := 0; i < writers; i++ {
+		i := i
+		go func() {
+			_, err := store.UpdateProject(func(p *types.Project) bool {
+				p.Frameworks = append(p.Frameworks, fmt.Sprintf("fw-%d", i))
+				return true
+			})
+			errs <- err
+		}()
+		go func() {
+			errs <- store.SaveFileIndex(&types.FileIndex{Path: fmt.Sprintf("src/%d.go", i), Summary: "generated"})
+		}()
+	}
+	for i := 0; i < writers*2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("concurrent write failed: %v", err)
+		}
+	}
+
+	project, err := store.GetProject()
+	if err != nil {
+		t.Fatalf("GetProject failed: %v", err)
+	}
+	if len(project.Frameworks) != writers {
+		t.Errorf("frameworks = %v, want an update from each of %d writers", project.Frameworks, writers)
+	}
+	if files, _ := store.GetFilesIndex(); len(files) != writers {
+		t.Errorf("indexed %d files, want %d", len(files), writers)
+	}
+
+	// Returning false leaves the project untouched
+	before := project.UpdatedAt
+	if _, err := store.UpdateProject(func(*types.Project) bool { return false }); err != nil {
+		t.Fatalf("UpdateProject failed: %v", err)
+	}
+	if after, _ := store.GetProject(); !after.UpdatedAt.Equal(before) {
+		t.Error("a declined update should not save the project")
+	}
+}
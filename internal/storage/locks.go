@@ -0,0 +1,63 @@
+package storage
+
+import "sync"
+
+// Each store — one JSON file, or the directory of files behind features —
+// has its own read/write lock, so a reindex rewriting the files index
+// doesn't block tool calls that record decisions, and a reader never sees
+// another store's write half done. Methods that touch several stores take
+// their locks one after another, never nested.
+const (
+	storeConfig       = "config"
+	storeProject      = "project"
+	storeFiles        = "files"
+	storeDecisions    = "decisions"
+	storeWarnings     = "warnings"
+	storeInsights     = "insights"
+	storeFeatures     = "features" // features/ and archive/, conversations included
+	storePatterns     = "patterns"
+	storeGraph        = "graph"
+	storeGraphHistory = "graph-history"
+	storeEvolution    = "evolution"
+	storeHooks        = "pending-hooks"
+	storeFeedback     = "search-feedback"
+	storeIssues       = "issues"
+	storeTokenSpend   = "token-spend"
+	storeArchitecture = "architecture"
+	storeEndpoints    = "api-endpoints"
+	storeIndexCaps    = "index-caps"
+)
+
+// itemStores maps the item types that AddTranslation and LinkIssue take to
+// their stores
+var itemStores = map[string]string{
+	"decision": storeDecisions,
+	"warning":  storeWarnings,
+	"insight":  storeInsights,
+	"feature":  storeFeatures,
+}
+
+// storeLocks hands out the lock of each store, creating it on first use
+type storeLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+func (l *storeLocks) get(store string) *sync.RWMutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks == nil {
+		l.locks = make(map[string]*sync.RWMutex)
+	}
+	lock, ok := l.locks[store]
+	if !ok {
+		lock = &sync.RWMutex{}
+		l.locks[store] = lock
+	}
+	return lock
+}
+
+// lock returns the read/write lock of a store
+func (s *JSONStore) lock(store string) *sync.RWMutex {
+	return s.locks.get(store)
+}
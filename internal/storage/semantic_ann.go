@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+)
+
+// annMinVectors is how many semantic vectors it takes before searches go
+// through the HNSW graph; below it a full scan is fast and exact
+var annMinVectors = 2000
+
+// annMinSimilarity matches the threshold of the full scan
+const annMinSimilarity = 0.15
+
+func (idx *SQLiteIndex) annPath() string {
+	return filepath.Join(idx.basePath, "cache", "semantic.hnsw")
+}
+
+// vectorsFingerprint identifies the stored vectors, so a graph built by
+// another process, or before a reindex, is noticed as stale
+func (idx *SQLiteIndex) vectorsFingerprint() (string, int, error) {
+	var count, updated, size int64
+	err := idx.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(MAX(updated_at), 0), COALESCE(SUM(LENGTH(vector)), 0)
+		FROM semantic_vectors
+	`).Scan(&count, &updated, &size)
+	if err != nil {
+		return "", 0, err
+	}
+	return fmt.Sprintf("%d:%d:%d", count, updated, size), int(count), nil
+}
+
+// annSearch searches the HNSW graph, loading or rebuilding it first when
+// needed. ok is false when the corpus is small enough to scan instead.
+func (idx *SQLiteIndex) annSearch(queryVector []float64, docType string, limit int) ([]search.ANNResult, bool) {
+	idx.annMu.Lock()
+	defer idx.annMu.Unlock()
+
+	fingerprint, count, err := idx.vectorsFingerprint()
+	if err != nil || count < annMinVectors {
+		return nil, false
+	}
+	if idx.ann == nil {
+		if h, err := search.LoadHNSW(idx.annPath()); err == nil {
+			idx.ann = h
+		}
+	}
+	// Rebuild when the vectors changed behind the graph's back, or when
+	// removed documents outnumber the live ones
+	if idx.ann == nil || idx.ann.Fingerprint != fingerprint || idx.ann.Removed() > idx.ann.Len() {
+		h, err := idx.buildANN(fingerprint)
+		if err != nil {
+			return nil, false
+		}
+		idx.ann = h
+		idx.ann.Save(idx.annPath())
+		idx.annDirty = false
+	}
+
+	var accept func(string) bool
+	if docType != "" {
+		accept = func(dt string) bool { return dt == docType }
+	}
+	// A type filter discards candidates, so look further until enough pass
+	var results []search.ANNResult
+	for ef := max(limit*4, 64); ; ef *= 4 {
+		results = idx.ann.Search(queryVector, limit, ef, accept)
+		if len(results) >= limit || ef >= idx.ann.Len() {
+			break
+		}
+	}
+	return results, true
+}
+
+// buildANN builds the graph from every stored vector
+func (idx *SQLiteIndex) buildANN(fingerprint string) (*search.HNSW, error) {
+	rows, err := idx.db.Query("SELECT id, doc_type, vector FROM semantic_vectors ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	h := search.NewHNSW()
+	for rows.Next() {
+		var id, docType string
+		var blob []byte
+		if err := rows.Scan(&id, &docType, &blob); err != nil {
+			continue
+		}
+		if v := search.UnpackVector(blob); v != nil {
+			h.Add(id, docType, v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	h.Fingerprint = fingerprint
+	return h, nil
+}
+
+// annStored keeps a loaded graph in step with a vector this process
+// stored (vector nil: deleted), so it needn't be rebuilt
+func (idx *SQLiteIndex) annStored(id, docType string, vector []byte) {
+	idx.annMu.Lock()
+	defer idx.annMu.Unlock()
+	if idx.ann == nil {
+		return
+	}
+	if vector == nil {
+		idx.ann.Remove(id)
+	} else if v := search.UnpackVector(vector); v != nil {
+		idx.ann.Add(id, docType, v)
+	}
+	if fingerprint, _, err := idx.vectorsFingerprint(); err == nil {
+		idx.ann.Fingerprint = fingerprint
+	}
+	idx.annDirty = true
+}
+
+// dropANN discards the graph; a new vocabulary changes every vector
+func (idx *SQLiteIndex) dropANN() {
+	idx.annMu.Lock()
+	defer idx.annMu.Unlock()
+	idx.ann = nil
+	idx.annDirty = false
+	os.Remove(idx.annPath())
+}
+
+// saveANN persists a graph updated since it was built or loaded
+func (idx *SQLiteIndex) saveANN() {
+	idx.annMu.Lock()
+	defer idx.annMu.Unlock()
+	if idx.ann != nil && idx.annDirty {
+		idx.ann.Save(idx.annPath())
+		idx.annDirty = false
+	}
+}
+
+// searchSemanticANN answers SearchSemantic from the graph, reading the
+// found documents' text from the table
+func (idx *SQLiteIndex) searchSemanticANN(found []search.ANNResult) ([]search.SemanticResult, error) {
+	var ids []string
+	var args []interface{}
+	for _, r := range found {
+		if r.Similarity > annMinSimilarity {
+			ids = append(ids, "?")
+			args = append(args, r.ID)
+		}
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	rows, err := idx.db.Query("SELECT id, doc_text FROM semantic_vectors WHERE id IN ("+strings.Join(ids, ",")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	texts := make(map[string]string, len(args))
+	for rows.Next() {
+		var id, text string
+		if err := rows.Scan(&id, &text); err == nil {
+			texts[id] = text
+		}
+	}
+
+	var results []search.SemanticResult
+	for _, r := range found {
+		if text, ok := texts[r.ID]; ok && r.Similarity > annMinSimilarity {
+			results = append(results, search.SemanticResult{
+				ID:         r.ID,
+				DocType:    r.DocType,
+				Text:       text,
+				Similarity: r.Similarity,
+			})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"path"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// MatchSensitivePaths returns the policies covering any of paths: a path
+// inside a policy's directory, or a directory containing it. Paths are
+// project-relative.
+func MatchSensitivePaths(policies []types.SensitivePath, paths ...string) []types.SensitivePath {
+	var matched []types.SensitivePath
+	for _, policy := range policies {
+		pattern := strings.Trim(relpath.Normalize(policy.Path), "/")
+		if pattern == "" {
+			continue
+		}
+		for _, p := range paths {
+			if sensitivePathMatch(pattern, p) {
+				matched = append(matched, policy)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// sensitivePathMatch reports whether p is under the directory pattern, or
+// is a directory above it
+func sensitivePathMatch(pattern, p string) bool {
+	p = strings.Trim(relpath.Normalize(p), "/")
+	if p == "" {
+		return false
+	}
+	patternParts := strings.Split(pattern, "/")
+	parts := strings.Split(p, "/")
+	for i := range patternParts {
+		if i == len(parts) {
+			// p is a directory containing the sensitive one
+			return true
+		}
+		if ok, err := path.Match(patternParts[i], parts[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// SensitivePathsFor returns the configured sensitive path policies covering
+// any of paths
+func (s *JSONStore) SensitivePathsFor(paths ...string) []types.SensitivePath {
+	config, err := s.GetConfig()
+	if err != nil || len(config.SensitivePaths) == 0 {
+		return nil
+	}
+	return MatchSensitivePaths(config.SensitivePaths, paths...)
+}
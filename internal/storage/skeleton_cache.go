@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// --- Skeleton Cache ---
+
+// GetSkeleton returns the cached skeleton of a file when it was parsed from
+// content with this hash by this parser
+func (idx *SQLiteIndex) GetSkeleton(path, contentHash, parser string) (*types.CodeSkeleton, bool) {
+	return idx.getSkeleton(idx.db, path, contentHash, parser)
+}
+
+// GetSkeletonTx looks up a cached skeleton within a transaction
+func (idx *SQLiteIndex) GetSkeletonTx(tx *sql.Tx, path, contentHash, parser string) (*types.CodeSkeleton, bool) {
+	return idx.getSkeleton(tx, path, contentHash, parser)
+}
+
+func (idx *SQLiteIndex) getSkeleton(q queryer, path, contentHash, parser string) (*types.CodeSkeleton, bool) {
+	var data []byte
+	err := q.QueryRow(
+		"SELECT skeleton FROM skeleton_cache WHERE path = ? AND content_hash = ? AND parser = ?",
+		path, contentHash, parser,
+	).Scan(&data)
+	if err != nil {
+		return nil, false
+	}
+	var sk types.CodeSkeleton
+	if err := json.Unmarshal(data, &sk); err != nil {
+		return nil, false
+	}
+	return &sk, true
+}
+
+// StoreSkeleton caches a file's skeleton, replacing the one parsed from an
+// earlier version of the file
+func (idx *SQLiteIndex) StoreSkeleton(path, contentHash, parser string, sk *types.CodeSkeleton) error {
+	return idx.storeSkeleton(idx.db, path, contentHash, parser, sk)
+}
+
+// StoreSkeletonTx caches a skeleton within a transaction
+func (idx *SQLiteIndex) StoreSkeletonTx(tx *sql.Tx, path, contentHash, parser string, sk *types.CodeSkeleton) error {
+	return idx.storeSkeleton(tx, path, contentHash, parser, sk)
+}
+
+func (idx *SQLiteIndex) storeSkeleton(q queryer, path, contentHash, parser string, sk *types.CodeSkeleton) error {
+	data, err := json.Marshal(sk)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(`
+		INSERT OR REPLACE INTO skeleton_cache (path, content_hash, parser, skeleton, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, path, contentHash, parser, data, nowUnix())
+	return err
+}
+
+// DeleteSkeleton drops the cached skeleton of a removed file
+func (idx *SQLiteIndex) DeleteSkeleton(path string) error {
+	_, err := idx.db.Exec("DELETE FROM skeleton_cache WHERE path = ?", path)
+	return err
+}
+
+// ClearSkeletons drops every cached skeleton
+func (idx *SQLiteIndex) ClearSkeletons() error {
+	_, err := idx.db.Exec("DELETE FROM skeleton_cache")
+	return err
+}
+
+// SkeletonCount returns how many skeletons are cached
+func (idx *SQLiteIndex) SkeletonCount() (int, error) {
+	var count int
+	err := idx.db.QueryRow("SELECT COUNT(*) FROM skeleton_cache").Scan(&count)
+	return count, err
+}
@@ -8,10 +8,13 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	_ "modernc.org/sqlite"
 
+	"github.com/saeedalam/teamcontext/internal/relpath"
 	"github.com/saeedalam/teamcontext/internal/search"
 	"github.com/saeedalam/teamcontext/pkg/types"
 )
@@ -20,6 +23,12 @@ import (
 type SQLiteIndex struct {
 	db       *sql.DB
 	basePath string
+
+	// Approximate nearest neighbor graph over semantic vectors, loaded on
+	// the first search of a large corpus
+	ann      *search.HNSW
+	annMu    sync.Mutex
+	annDirty bool
 }
 
 // NewSQLiteIndex creates a new SQLite index
@@ -31,30 +40,113 @@ func NewSQLiteIndex(basePath string) (*SQLiteIndex, error) {
 		return nil, err
 	}
 
-	// Add connection parameters for better concurrency
-	dsn := fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=10000", dbPath)
-	db, err := sql.Open("sqlite", dsn)
+	db, err := sql.Open("sqlite", sqliteDSN(dbPath))
 	if err != nil {
 		return nil, err
 	}
 
-	// Set connection limits
-	db.SetMaxOpenConns(1) // Keep it simple for now to avoid locks
+	// One connection serializes this process's writes in the pool instead of
+	// on SQLite's lock; WAL keeps other processes' readers and writers going
+	db.SetMaxOpenConns(1)
 
 	idx := &SQLiteIndex{
 		db:       db,
 		basePath: basePath,
 	}
 
-	if err := idx.createTables(); err != nil {
-		return nil, err
+	if err := idx.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating index schema: %w", err)
 	}
 
 	return idx, nil
 }
 
-func (idx *SQLiteIndex) createTables() error {
-	schema := `
+// sqliteBusyTimeout is how long a connection waits for another process's
+// write lock before failing with "database is locked"
+const sqliteBusyTimeout = 10 * time.Second
+
+// sqliteDSN opens the index in WAL mode, so readers never block the writer
+// and the writer never blocks readers, across the MCP server, its worker and
+// CLI commands. The pragmas are applied to every new connection. Immediate
+// transactions take the write lock up front, where the busy timeout applies,
+// instead of failing when a read transaction upgrades.
+func sqliteDSN(dbPath string) string {
+	return fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate",
+		dbPath, sqliteBusyTimeout.Milliseconds())
+}
+
+// schemaMigrations upgrade the index in place, in order. Each runs once in
+// its own transaction, which also records it in PRAGMA user_version, so an
+// index written by an older version is upgraded on open while other
+// processes keep using it. Append new migrations; never edit released ones.
+var schemaMigrations = []string{
+	// 1: base schema. CREATE ... IF NOT EXISTS lets indexes created before
+	// versioning adopt it as is.
+	baseSchema,
+	// 2: parsed skeletons, so unchanged files are not parsed again after a
+	// restart
+	`CREATE TABLE IF NOT EXISTS skeleton_cache (
+		path TEXT PRIMARY KEY,
+		content_hash TEXT NOT NULL,
+		parser TEXT NOT NULL,
+		skeleton BLOB NOT NULL,
+		updated_at INTEGER
+	)`,
+}
+
+// migrate applies the migrations the index has not seen yet. Concurrent
+// openers serialize on the write lock and skip what the first one applied.
+// An index from a newer version is left alone.
+func (idx *SQLiteIndex) migrate() error {
+	for {
+		done, err := idx.migrateOnce()
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+func (idx *SQLiteIndex) migrateOnce() (bool, error) {
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return false, err
+	}
+	if version >= len(schemaMigrations) {
+		return true, nil
+	}
+	if _, err := tx.Exec(schemaMigrations[version]); err != nil {
+		return false, fmt.Errorf("migration %d: %w", version+1, err)
+	}
+	// PRAGMA takes no parameters; the version is our own integer
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+		return false, err
+	}
+	return false, tx.Commit()
+}
+
+// SchemaVersion returns the index schema version and the version this build
+// migrates to
+func (idx *SQLiteIndex) SchemaVersion() (current, latest int, err error) {
+	err = idx.db.QueryRow("PRAGMA user_version").Scan(&current)
+	return current, len(schemaMigrations), err
+}
+
+// JournalMode returns the index's journal mode ("wal" when concurrent
+// access is safe)
+func (idx *SQLiteIndex) JournalMode() (string, error) {
+	var mode string
+	err := idx.db.QueryRow("PRAGMA journal_mode").Scan(&mode)
+	return mode, err
+}
+
+const baseSchema = `
 	-- Files table
 	CREATE TABLE IF NOT EXISTS files (
 		path TEXT PRIMARY KEY,
@@ -227,17 +319,24 @@ func (idx *SQLiteIndex) createTables() error {
 		idf TEXT,
 		doc_count INTEGER
 	);
-	`
-
-	_, err := idx.db.Exec(schema)
-	return err
-}
+`
 
 // Close closes the database connection
 func (idx *SQLiteIndex) Close() error {
+	idx.saveANN()
 	return idx.db.Close()
 }
 
+// SnapshotTo writes a consistent, self-contained copy of the database to
+// dest, including changes still in the WAL
+func (idx *SQLiteIndex) SnapshotTo(dest string) error {
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	_, err := idx.db.Exec("VACUUM INTO ?", dest)
+	return err
+}
+
 type queryer interface {
 	Exec(query string, args ...any) (sql.Result, error)
 	Prepare(query string) (*sql.Stmt, error)
@@ -285,15 +384,25 @@ func (idx *SQLiteIndex) indexFile(q queryer, file *types.FileIndex) error {
 	_, err := q.Exec(`
 		INSERT OR REPLACE INTO files (path, summary, exports, imports, language, patterns, content_hash, indexed_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, file.Path, file.Summary, string(exportsJSON), string(importsJSON),
+	`, relpath.Normalize(file.Path), file.Summary, string(exportsJSON), string(importsJSON),
 		file.Language, string(patternsJSON), file.ContentHash, file.IndexedAt.Unix())
 
 	return err
 }
 
+// DeleteFile removes a file from the search index
+func (idx *SQLiteIndex) DeleteFile(path string) error {
+	_, err := idx.db.Exec("DELETE FROM files WHERE path = ?", relpath.Normalize(path))
+	return err
+}
+
 // IndexDecision indexes a decision for search
 func (idx *SQLiteIndex) IndexDecision(dec *types.Decision) error {
-	_, err := idx.db.Exec(`
+	return idx.indexDecision(idx.db, dec)
+}
+
+func (idx *SQLiteIndex) indexDecision(q queryer, dec *types.Decision) error {
+	_, err := q.Exec(`
 		INSERT OR REPLACE INTO decisions (id, content, reason, context, feature, status, created_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`, dec.ID, dec.Content, dec.Reason, dec.Context, dec.Feature, dec.Status, dec.CreatedAt.Unix())
@@ -303,7 +412,11 @@ func (idx *SQLiteIndex) IndexDecision(dec *types.Decision) error {
 
 // IndexWarning indexes a warning for search
 func (idx *SQLiteIndex) IndexWarning(warn *types.Warning) error {
-	_, err := idx.db.Exec(`
+	return idx.indexWarning(idx.db, warn)
+}
+
+func (idx *SQLiteIndex) indexWarning(q queryer, warn *types.Warning) error {
+	_, err := q.Exec(`
 		INSERT OR REPLACE INTO warnings (id, content, reason, evidence, severity, feature, created_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`, warn.ID, warn.Content, warn.Reason, warn.Evidence, warn.Severity, warn.Feature, warn.CreatedAt.Unix())
@@ -313,9 +426,13 @@ func (idx *SQLiteIndex) IndexWarning(warn *types.Warning) error {
 
 // IndexFeature indexes a feature for search
 func (idx *SQLiteIndex) IndexFeature(feat *types.Feature) error {
+	return idx.indexFeature(idx.db, feat)
+}
+
+func (idx *SQLiteIndex) indexFeature(q queryer, feat *types.Feature) error {
 	filesJSON, _ := json.Marshal(feat.RelevantFiles)
 
-	_, err := idx.db.Exec(`
+	_, err := q.Exec(`
 		INSERT OR REPLACE INTO features (id, status, current_state, relevant_files, created_at, last_accessed)
 		VALUES (?, ?, ?, ?, ?, ?)
 	`, feat.ID, feat.Status, feat.CurrentState, string(filesJSON), feat.CreatedAt.Unix(), feat.LastAccessed.Unix())
@@ -526,7 +643,10 @@ func (idx *SQLiteIndex) RebuildFromJSON(jsonStore *JSONStore) error {
 	files, err := jsonStore.GetFilesIndex()
 	if err == nil {
 		for _, f := range files {
-			idx.IndexFile(&f)
+			// Tombstones stay in the JSON store only
+			if f.DeletedAt == nil {
+				idx.IndexFile(&f)
+			}
 		}
 	}
 
@@ -581,7 +701,7 @@ func (idx *SQLiteIndex) GetStats() (map[string]int, error) {
 type CodeChunk struct {
 	ID        int64  `json:"id"`
 	FilePath  string `json:"file_path"`
-	ChunkType string `json:"chunk_type"` // "function", "class", "block", "lines"
+	ChunkType string `json:"chunk_type"` // "function", "class", "block", "lines", "doc"
 	ChunkName string `json:"chunk_name"` // function/class name or "lines:10-50"
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
@@ -601,6 +721,7 @@ func (idx *SQLiteIndex) IndexCodeChunksTx(tx *sql.Tx, filePath string, chunks []
 
 func (idx *SQLiteIndex) indexCodeChunks(q queryer, filePath string, chunks []CodeChunk) error {
 	// Delete existing chunks for this file
+	filePath = relpath.Normalize(filePath)
 	_, err := q.Exec("DELETE FROM code_chunks WHERE file_path = ?", filePath)
 	if err != nil {
 		return err
@@ -618,7 +739,7 @@ func (idx *SQLiteIndex) indexCodeChunks(q queryer, filePath string, chunks []Cod
 
 	now := nowUnix()
 	for _, chunk := range chunks {
-		_, err := stmt.Exec(chunk.FilePath, chunk.ChunkType, chunk.ChunkName,
+		_, err := stmt.Exec(relpath.Normalize(chunk.FilePath), chunk.ChunkType, chunk.ChunkName,
 			chunk.StartLine, chunk.EndLine, chunk.Content, chunk.Language, now)
 		if err != nil {
 			return err
@@ -648,6 +769,9 @@ func (idx *SQLiteIndex) SearchCodeContent(query string, language string, limit i
 	if language != "" {
 		conditions = append(conditions, "language = ?")
 		args = append(args, language)
+	} else {
+		// Documentation sections are searched separately via SearchDocs
+		conditions = append(conditions, "chunk_type != 'doc'")
 	}
 
 	whereClause := ""
@@ -684,6 +808,73 @@ func (idx *SQLiteIndex) SearchCodeContent(query string, language string, limit i
 	return chunks, nil
 }
 
+// SearchDocs searches documentation sections (markdown chunks) by keywords.
+// The query may be a natural-language question; it is reduced to an FTS
+// OR-query of its significant words.
+func (idx *SQLiteIndex) SearchDocs(query string, limit int) ([]CodeChunk, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+
+	match := ftsKeywordQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := idx.db.Query(`
+		SELECT code_chunks.id, file_path, chunk_type, chunk_name, start_line, end_line, content, language
+		FROM code_chunks
+		JOIN (
+			SELECT rowid, rank FROM code_chunks_fts WHERE code_chunks_fts MATCH ?
+		) AS m ON m.rowid = code_chunks.id
+		WHERE chunk_type = 'doc'
+		ORDER BY m.rank
+		LIMIT ?
+	`, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []CodeChunk
+	for rows.Next() {
+		var c CodeChunk
+		err := rows.Scan(&c.ID, &c.FilePath, &c.ChunkType, &c.ChunkName,
+			&c.StartLine, &c.EndLine, &c.Content, &c.Language)
+		if err != nil {
+			continue
+		}
+		chunks = append(chunks, c)
+	}
+
+	return chunks, nil
+}
+
+// ftsKeywordQuery turns free text into a safe FTS5 query: significant words
+// quoted and OR-ed together.
+func ftsKeywordQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words {
+		if len(w) < 3 || seen[w] || ftsStopWords[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, `"`+w+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
+
+var ftsStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "how": true, "what": true, "does": true,
+	"who": true, "why": true, "where": true, "when": true, "with": true, "this": true,
+	"that": true, "are": true, "was": true, "can": true, "from": true, "into": true,
+}
+
 // GetCodeChunksForFile returns all code chunks for a specific file
 func (idx *SQLiteIndex) GetCodeChunksForFile(filePath string) ([]CodeChunk, error) {
 	rows, err := idx.db.Query(`
@@ -691,7 +882,7 @@ func (idx *SQLiteIndex) GetCodeChunksForFile(filePath string) ([]CodeChunk, erro
 		FROM code_chunks
 		WHERE file_path = ?
 		ORDER BY start_line
-	`, filePath)
+	`, relpath.Normalize(filePath))
 	if err != nil {
 		return nil, err
 	}
@@ -713,7 +904,7 @@ func (idx *SQLiteIndex) GetCodeChunksForFile(filePath string) ([]CodeChunk, erro
 
 // DeleteCodeChunksForFile removes all code chunks for a file
 func (idx *SQLiteIndex) DeleteCodeChunksForFile(filePath string) error {
-	_, err := idx.db.Exec("DELETE FROM code_chunks WHERE file_path = ?", filePath)
+	_, err := idx.db.Exec("DELETE FROM code_chunks WHERE file_path = ?", relpath.Normalize(filePath))
 	return err
 }
 
@@ -729,17 +920,34 @@ func (idx *SQLiteIndex) StoreSemanticVector(id, docType, text string, vector []b
 		INSERT OR REPLACE INTO semantic_vectors (id, doc_type, doc_text, vector, updated_at)
 		VALUES (?, ?, ?, ?, ?)
 	`, id, docType, text, vector, nowUnix())
-	return err
+	if err != nil {
+		return err
+	}
+	idx.annStored(id, docType, vector)
+	return nil
 }
 
-// SearchSemantic loads all vectors of the given type, computes cosine similarity, and returns top-N results.
-// Uses brute-force scan with early termination. For corpora exceeding ~10K documents,
-// consider replacing with an approximate nearest-neighbor index (e.g. HNSW or IVF).
+// DeleteSemanticVector removes a document's vector, e.g. when it was merged away
+func (idx *SQLiteIndex) DeleteSemanticVector(id string) error {
+	if _, err := idx.db.Exec("DELETE FROM semantic_vectors WHERE id = ?", id); err != nil {
+		return err
+	}
+	idx.annStored(id, "", nil)
+	return nil
+}
+
+// SearchSemantic returns the top-N vectors of the given type most similar to the query.
+// Large corpora are searched through an HNSW graph persisted in cache/semantic.hnsw;
+// small ones by a brute-force scan with early termination.
 func (idx *SQLiteIndex) SearchSemantic(queryVector []float64, docType string, limit int) ([]search.SemanticResult, error) {
 	if limit <= 0 {
 		limit = 10
 	}
 
+	if found, ok := idx.annSearch(queryVector, docType, limit); ok {
+		return idx.searchSemanticANN(found)
+	}
+
 	var args []interface{}
 	query := "SELECT id, doc_type, doc_text, vector FROM semantic_vectors"
 	if docType != "" {
@@ -813,7 +1021,12 @@ func (idx *SQLiteIndex) StoreVocab(engine *search.TFIDFEngine) error {
 		INSERT OR REPLACE INTO semantic_vocab (id, vocabulary, idf, doc_count)
 		VALUES (1, ?, ?, ?)
 	`, string(vocabJSON), string(idfJSON), engine.DocCount)
-	return err
+	if err != nil {
+		return err
+	}
+	// Every vector is about to be recomputed against the new vocabulary
+	idx.dropANN()
+	return nil
 }
 
 // LoadVocab loads the TF-IDF engine from SQLite.
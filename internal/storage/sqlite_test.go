@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/search"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func setupTestIndex(t *testing.T) (string, *SQLiteIndex) {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "teamcontext-sqlite-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	idx, err := NewSQLiteIndex(tmpDir)
+	if err != nil {
+		t.Fatalf("NewSQLiteIndex failed: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	return tmpDir, idx
+}
+
+func TestSQLiteIndexSchemaMigration(t *testing.T) {
+	dir, idx := setupTestIndex(t)
+
+	mode, err := idx.JournalMode()
+	if err != nil || mode != "wal" {
+		t.Errorf("Expected WAL journal mode, got %q (%v)", mode, err)
+	}
+	current, latest, err := idx.SchemaVersion()
+	if err != nil || current != latest {
+		t.Fatalf("Expected schema v%d, got v%d (%v)", latest, current, err)
+	}
+
+	if err := idx.IndexDecision(&types.Decision{ID: "dec-1", Content: "Use PostgreSQL", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("IndexDecision failed: %v", err)
+	}
+
+	// An index from before schema versioning is upgraded in place
+	if _, err := idx.db.Exec("PRAGMA user_version = 0"); err != nil {
+		t.Fatalf("Resetting user_version failed: %v", err)
+	}
+	idx.Close()
+
+	reopened, err := NewSQLiteIndex(dir)
+	if err != nil {
+		t.Fatalf("Reopening failed: %v", err)
+	}
+	defer reopened.Close()
+	if current, latest, _ := reopened.SchemaVersion(); current != latest {
+		t.Errorf("Expected the index to be migrated to v%d, got v%d", latest, current)
+	}
+	decisions, err := reopened.SearchDecisions("PostgreSQL", "", "", 10)
+	if err != nil || len(decisions) != 1 {
+		t.Errorf("Expected the decision to survive the migration, got %v (%v)", decisions, err)
+	}
+}
+
+func TestSQLiteIndexConcurrentProcesses(t *testing.T) {
+	dir, worker := setupTestIndex(t)
+
+	// A second index on the same database, like an MCP server next to a CLI reindex
+	server, err := NewSQLiteIndex(dir)
+	if err != nil {
+		t.Fatalf("Opening a second index failed: %v", err)
+	}
+	defer server.Close()
+
+	if err := worker.IndexFile(&types.FileIndex{Path: "a.go", Summary: "existing file", IndexedAt: time.Now()}); err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+
+	inTx := make(chan struct{})
+	release := make(chan struct{})
+	txDone := make(chan error, 1)
+	go func() {
+		txDone <- worker.WithTransaction(func(tx *sql.Tx) error {
+			if err := worker.IndexFileTx(tx, &types.FileIndex{Path: "b.go", Summary: "reindexed file", IndexedAt: time.Now()}); err != nil {
+				return err
+			}
+			close(inTx)
+			<-release
+			return nil
+		})
+	}()
+	<-inTx
+
+	// Readers are not blocked by the open write transaction
+	files, err := server.SearchFiles("existing", "", 10)
+	if err != nil || len(files) != 1 {
+		t.Errorf("Expected to read during the reindex, got %v (%v)", files, err)
+	}
+
+	// Writers wait for the lock instead of failing
+	writeDone := make(chan error, 1)
+	go func() {
+		writeDone <- server.IndexDecision(&types.Decision{ID: "dec-1", Content: "Written during reindex", CreatedAt: time.Now()})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	if err := <-txDone; err != nil {
+		t.Fatalf("Reindex transaction failed: %v", err)
+	}
+	if err := <-writeDone; err != nil {
+		t.Errorf("Expected the concurrent write to wait for the lock, got %v", err)
+	}
+}
+
+func TestIndexConsistencyCheckAndRepair(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+	_, idx := setupTestIndex(t)
+
+	// In sync
+	for _, path := range []string{"src/a.go", "src/gone.go"} {
+		file := &types.FileIndex{Path: path, Summary: "Handles " + path, Language: "go"}
+		if err := store.SaveFileIndex(file); err != nil {
+			t.Fatalf("SaveFileIndex failed: %v", err)
+		}
+		if err := idx.IndexFile(file); err != nil {
+			t.Fatalf("IndexFile failed: %v", err)
+		}
+	}
+	// Missing from the index
+	if err := store.SaveFileIndex(&types.FileIndex{Path: "src/b.go", Summary: "Parses input", Language: "go"}); err != nil {
+		t.Fatalf("SaveFileIndex failed: %v", err)
+	}
+	// Deleted, but its row and chunks stay behind
+	if err := idx.IndexCodeChunks("src/gone.go", []CodeChunk{{FilePath: "src/gone.go", ChunkType: "function", ChunkName: "Gone", StartLine: 1, EndLine: 3, Content: "func Gone() {}", Language: "go"}}); err != nil {
+		t.Fatalf("IndexCodeChunks failed: %v", err)
+	}
+	if err := store.MarkFileDeleted("src/gone.go"); err != nil {
+		t.Fatalf("MarkFileDeleted failed: %v", err)
+	}
+	// Stale: the index has an older version of the decision
+	dec := &types.Decision{Content: "Use PostgreSQL", Status: "active"}
+	if err := store.AddDecision(dec); err != nil {
+		t.Fatalf("AddDecision failed: %v", err)
+	}
+	if err := idx.IndexDecision(&types.Decision{ID: dec.ID, Content: "Use MySQL", Status: "active"}); err != nil {
+		t.Fatalf("IndexDecision failed: %v", err)
+	}
+
+	drift, err := idx.CheckConsistency(store)
+	if err != nil {
+		t.Fatalf("CheckConsistency failed: %v", err)
+	}
+	if got := drift.Missing["files"]; len(got) != 1 || got[0] != "src/b.go" {
+		t.Errorf("Expected src/b.go missing, got %v", got)
+	}
+	if got := drift.Orphaned["files"]; len(got) != 1 || got[0] != "src/gone.go" {
+		t.Errorf("Expected src/gone.go orphaned, got %v", got)
+	}
+	if got := drift.Stale["decisions"]; len(got) != 1 || got[0] != dec.ID {
+		t.Errorf("Expected %s stale, got %v", dec.ID, got)
+	}
+	if len(drift.StaleChunks) != 1 || drift.StaleChunks[0] != "src/gone.go" {
+		t.Errorf("Expected stale chunks of src/gone.go, got %v", drift.StaleChunks)
+	}
+	if drift.Count() != 4 {
+		t.Errorf("Expected 4 mismatches, got %d", drift.Count())
+	}
+
+	repaired, err := idx.RepairDrift(store, drift)
+	if err != nil || repaired != 4 {
+		t.Fatalf("Expected 4 repairs, got %d (%v)", repaired, err)
+	}
+	drift, err = idx.CheckConsistency(store)
+	if err != nil || drift.Count() != 0 {
+		t.Fatalf("Expected no drift after repair, got %+v (%v)", drift, err)
+	}
+	decisions, _ := idx.SearchDecisions("PostgreSQL", "", "", 10)
+	if len(decisions) != 1 {
+		t.Errorf("Expected the repaired decision to be searchable, got %d", len(decisions))
+	}
+}
+
+func TestSemanticSearchANN(t *testing.T) {
+	dir, idx := setupTestIndex(t)
+	saved := annMinVectors
+	annMinVectors = 50
+	t.Cleanup(func() { annMinVectors = saved })
+
+	vector := func(i int) []float64 {
+		v := make([]float64, 200)
+		v[i%20] = 1
+		v[20+i%7] = 0.5
+		v[40+i] = 0.3
+		return v
+	}
+	for i := 0; i < 120; i++ {
+		docType := "decision"
+		if i%3 == 0 {
+			docType = "warning"
+		}
+		if err := idx.StoreSemanticVector(fmt.Sprintf("doc-%d", i), docType, "text", search.PackVector(vector(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := idx.SearchSemantic(vector(5), "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 || results[0].ID != "doc-5" || results[0].Text != "text" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	annFile := filepath.Join(dir, "cache", "semantic.hnsw")
+	if _, err := os.Stat(annFile); err != nil {
+		t.Fatalf("graph not saved: %v", err)
+	}
+
+	filtered, err := idx.SearchSemantic(vector(5), "warning", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range filtered {
+		if r.DocType != "warning" {
+			t.Errorf("filter let %s through", r.DocType)
+		}
+	}
+
+	// A deleted vector is gone from the loaded graph without a rebuild
+	if err := idx.DeleteSemanticVector("doc-5"); err != nil {
+		t.Fatal(err)
+	}
+	results, _ = idx.SearchSemantic(vector(5), "", 5)
+	for _, r := range results {
+		if r.ID == "doc-5" {
+			t.Error("deleted vector returned")
+		}
+	}
+
+	// A new vocabulary invalidates every vector, and the graph with them
+	if err := idx.StoreVocab(search.NewTFIDFEngine()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(annFile); !os.IsNotExist(err) {
+		t.Errorf("graph should be dropped with the vocabulary, stat err = %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+var (
+	// Summaries the indexer wrote before it recorded summary_source
+	placeholderSummaryRe = regexp.MustCompile(`^Auto-indexed \w+ file$`)
+	heuristicSummaryRe   = regexp.MustCompile(`^(?:\d+ (?:class\(es\)|interface\(s\)|function\(s\)|type\(s\)): |NestJS [\w ]+: \w+$|\w+ file with \d+ exports$)`)
+)
+
+// SummaryReason says why a file's summary should be rewritten:
+// "placeholder" when it says nothing, "heuristic" when the indexer derived it
+// from the file's exports, "" when an agent wrote it or it came from the
+// document itself
+func SummaryReason(f types.FileIndex) string {
+	summary := strings.TrimSpace(f.Summary)
+	switch {
+	case f.SummarySource == types.SummarySourceAgent:
+		return ""
+	case summary == "" || summary == types.SummaryPlaceholder || placeholderSummaryRe.MatchString(summary):
+		return "placeholder"
+	case f.SummarySource == types.SummarySourceAuto || heuristicSummaryRe.MatchString(summary):
+		return "heuristic"
+	}
+	return ""
+}
+
+// FilesNeedingSummary returns the indexed files with placeholder or
+// heuristic summaries, most important first: files that sessions keep
+// working with, that many files import and that export the most. accesses
+// adds uses not yet saved in a conversation, such as the current session's.
+func (s *JSONStore) FilesNeedingSummary(accesses map[string]int) ([]types.SummaryCandidate, error) {
+	files, err := s.GetFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	importers := make(map[string]map[string]bool)
+	if graph, err := s.GetKnowledgeGraph(); err == nil {
+		for _, e := range graph.Edges {
+			if e.Relation != "imports" || e.FromType != "file" || e.ToType != "file" {
+				continue
+			}
+			if importers[e.ToID] == nil {
+				importers[e.ToID] = make(map[string]bool)
+			}
+			importers[e.ToID][e.FromID] = true
+		}
+	}
+
+	used := make(map[string]int)
+	if convs, err := s.GetAllConversations(); err == nil {
+		for _, conv := range convs {
+			for _, f := range relpath.NormalizeAll(conv.FilesDiscussed) {
+				used[f]++
+			}
+		}
+	}
+	for f, n := range accesses {
+		used[relpath.Normalize(f)] += n
+	}
+
+	var queue []types.SummaryCandidate
+	for path, f := range files {
+		reason := SummaryReason(f)
+		if reason == "" {
+			continue
+		}
+		c := types.SummaryCandidate{
+			Path:      path,
+			Summary:   f.Summary,
+			Reason:    reason,
+			Language:  f.Language,
+			Exports:   len(f.Exports),
+			Importers: len(importers[path]),
+			Accesses:  used[path],
+		}
+		c.Score = c.Exports + 3*c.Importers + 5*c.Accesses
+		if reason == "placeholder" {
+			c.Score += 5
+		}
+		queue = append(queue, c)
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		if queue[i].Score != queue[j].Score {
+			return queue[i].Score > queue[j].Score
+		}
+		return queue[i].Path < queue[j].Path
+	})
+	return queue, nil
+}
@@ -0,0 +1,45 @@
+// Package tokenest counts LLM tokens for tool responses.
+//
+// Counts are exact cl100k_base encodings, the BPE vocabulary of GPT-4 class
+// models. Other models, Claude included, use their own vocabularies, so for
+// them a count is close but not exact. All token numbers reported by the
+// server come from here, so they are at least comparable across tools.
+package tokenest
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/tiktoken-go/tokenizer"
+)
+
+// codec loads the cl100k vocabulary on first use rather than at startup,
+// as most CLI commands never count tokens
+var codec = sync.OnceValue(func() tokenizer.Codec {
+	c, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		panic("tokenest: " + err.Error())
+	}
+	return c
+})
+
+// Count returns the number of cl100k tokens in text.
+func Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	// Encoding only fails when the split pattern times out, which it has
+	// no timeout to do; the tokens counted so far are returned regardless
+	n, _ := codec().Count(text)
+	return n
+}
+
+// CountJSON counts the tokens in the indented JSON encoding of v, which is
+// how tool results are delivered to clients.
+func CountJSON(v interface{}) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return 0
+	}
+	return Count(string(data))
+}
@@ -0,0 +1,40 @@
+package tokenest
+
+import (
+	"testing"
+)
+
+// Known counts are cl100k_base encodings of the text
+func TestCountMatchesCl100k(t *testing.T) {
+	tests := []struct {
+		text  string
+		known int
+	}{
+		{"hello world", 2},
+		{"Hello, world!", 4},
+		{"The quick brown fox jumps over the lazy dog", 9},
+		{"12345", 2},
+		{"    return nil", 3},
+		{`{"name": "value"}`, 6},
+		{"func main() {}", 4},
+		{"supercalifragilistic", 7},
+		{"hello   world", 3},
+		{"We know what we are, but know not what we may be.", 14},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.text); got != tt.known {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.known)
+		}
+	}
+}
+
+func TestCountJSON(t *testing.T) {
+	v := map[string]interface{}{"name": "value"}
+	if got, want := CountJSON(v), Count("{\n  \"name\": \"value\"\n}"); got != want {
+		t.Errorf("CountJSON = %d, want the count of the indented encoding (%d)", got, want)
+	}
+	if got := CountJSON(make(chan int)); got != 0 {
+		t.Errorf("CountJSON(unmarshalable) = %d, want 0", got)
+	}
+}
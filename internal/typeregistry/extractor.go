@@ -240,6 +240,20 @@ func FormatTypeDefs(typeDefs []types.TypeDef, enumDefs []types.EnumDef) string {
 				sb.WriteString(";\n")
 			}
 			sb.WriteString("}\n\n")
+		} else if len(td.Properties) > 0 {
+			// Go and Rust structs
+			sb.WriteString(td.Kind + " " + td.Name + " {\n")
+			for _, p := range td.Properties {
+				sb.WriteString("  " + p.Name)
+				if p.Type != "" {
+					sb.WriteString(": " + p.Type)
+				}
+				if p.Tag != "" {
+					sb.WriteString(" `" + p.Tag + "`")
+				}
+				sb.WriteString(";\n")
+			}
+			sb.WriteString("}\n\n")
 		} else {
 			sb.WriteString("type " + td.Name + " = " + td.RawDef + "\n\n")
 		}
@@ -283,10 +297,14 @@ func parseEnumMembers(body string) []string {
 	parts := regexp.MustCompile(`[,\n]+`).Split(body, -1)
 	for _, p := range parts {
 		p = strings.TrimSpace(p)
+		if strings.HasPrefix(p, "//") {
+			continue
+		}
 		// Extract just the member name (before = if present)
 		if idx := strings.Index(p, "="); idx != -1 {
 			p = strings.TrimSpace(p[:idx])
 		}
+		p = strings.Trim(p, `"'`)
 		if p != "" {
 			members = append(members, p)
 		}
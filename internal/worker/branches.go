@@ -0,0 +1,199 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Branch events that change a feature's lifecycle
+const (
+	branchMerged   = "merged"
+	branchDeleted  = "deleted"
+	branchReturned = "returned"
+)
+
+// featureBranch is what the watcher last saw of a feature's branch. It is
+// kept in cache/feature-branches.json so each event is suggested once.
+type featureBranch struct {
+	Branch   string `json:"branch"`
+	Tip      string `json:"tip,omitempty"`
+	Present  bool   `json:"present"`
+	Ahead    bool   `json:"ahead"` // had commits the default branch lacked
+	Notified string `json:"notified,omitempty"`
+}
+
+func (m *Manager) featureBranchesPath() string {
+	return filepath.Join(m.basePath, "cache", "feature-branches.json")
+}
+
+// syncFeatureBranches follows the branches of features. A merged or deleted
+// branch pauses its feature and suggests archiving it with a summary; a
+// branch that reappears for an archived feature suggests recalling it.
+func (m *Manager) syncFeatureBranches() {
+	branches, err := git.ListBranches(m.projectRoot)
+	if err != nil {
+		return
+	}
+	defaultBranch := git.DefaultBranch(m.projectRoot)
+
+	active, _ := m.jsonStore.GetFeatures()
+	archived, _ := m.jsonStore.GetArchivedFeatures()
+	features := append(active, archived...)
+
+	// Nothing to do until a branch moves, appears or goes away, or a
+	// feature's branch or status changes
+	refs := make([]string, 0, len(branches)+len(features))
+	for name, tip := range branches {
+		refs = append(refs, name+" "+tip)
+	}
+	for _, f := range features {
+		refs = append(refs, "feature "+f.ID+" "+f.Branch+" "+f.Status)
+	}
+	sort.Strings(refs)
+	fingerprint := strings.Join(refs, "\n")
+	if fingerprint == m.lastRefs {
+		return
+	}
+	m.lastRefs = fingerprint
+
+	state := make(map[string]featureBranch)
+	if data, err := os.ReadFile(m.featureBranchesPath()); err == nil {
+		json.Unmarshal(data, &state)
+	}
+
+	for _, f := range features {
+		if f.Branch == "" || f.Branch == defaultBranch {
+			continue
+		}
+		st, known := state[f.ID]
+		if st.Branch != f.Branch {
+			st, known = featureBranch{Branch: f.Branch}, false
+		}
+		tip, present := branches[f.Branch]
+
+		event := ""
+		if f.Status == "archived" {
+			// Only a branch that was gone, or has moved on, counts as back
+			if present && known && (!st.Present || tip != st.Tip) {
+				event = branchReturned
+			}
+		} else {
+			merged := present && git.IsAncestor(m.projectRoot, tip, defaultBranch)
+			switch {
+			case present && !merged:
+				st.Ahead = true
+				st.Notified = ""
+			case merged && st.Ahead:
+				event = branchMerged
+			case !present && st.Present:
+				event = branchDeleted
+				if st.Ahead && git.IsAncestor(m.projectRoot, st.Tip, defaultBranch) {
+					event = branchMerged
+				}
+			}
+		}
+
+		if event != "" && event != st.Notified {
+			m.applyBranchEvent(f, event, defaultBranch)
+			st.Notified = event
+		}
+		st.Present, st.Tip = present, tip
+		state[f.ID] = st
+	}
+
+	if data, err := json.MarshalIndent(state, "", "  "); err == nil {
+		if err := os.WriteFile(m.featureBranchesPath(), data, 0644); err != nil {
+			m.recordError("save feature branches", err)
+		}
+	}
+}
+
+// applyBranchEvent pauses a feature whose branch is merged or deleted and
+// queues the archive or recall suggestion for the next tool call
+func (m *Manager) applyBranchEvent(f types.Feature, event, defaultBranch string) {
+	var hook types.ConversationHook
+	switch event {
+	case branchReturned:
+		hook = types.ConversationHook{
+			Action:    types.HookActionRecallFeature,
+			Tool:      "recall_feature",
+			Priority:  types.HookPrioritySuggested,
+			Reason:    fmt.Sprintf("Branch %s is back, and its feature %s is archived. Ask the user whether to recall the feature and its context.", f.Branch, f.ID),
+			Arguments: map[string]interface{}{"id": f.ID},
+		}
+	default:
+		what := "was deleted"
+		if event == branchMerged {
+			what = "was merged into " + defaultBranch
+		}
+		if f.Status == "active" {
+			f.Status = "paused"
+			f.CurrentState = fmt.Sprintf("Branch %s %s on %s", f.Branch, what, time.Now().Format("2006-01-02"))
+			if err := m.jsonStore.UpdateFeature(&f); err != nil {
+				m.recordError("pause feature "+f.ID, err)
+			}
+		}
+		hook = types.ConversationHook{
+			Action:    types.HookActionArchiveFeature,
+			Tool:      "archive_feature",
+			Priority:  types.HookPrioritySuggested,
+			Reason:    fmt.Sprintf("Branch %s of feature %s %s, so the feature was paused. Ask the user whether to archive it.", f.Branch, f.ID, what),
+			Arguments: map[string]interface{}{"id": f.ID, "summary": m.featureArchiveSummary(f, what)},
+			Condition: "Edit the summary if the user adds what was left undone",
+		}
+	}
+
+	if err := m.jsonStore.AddPendingHook(&types.PendingHook{
+		ConversationHook: hook,
+		Source:           "branch-watcher",
+		CreatedAt:        time.Now(),
+	}); err != nil {
+		m.recordError("queue "+hook.Tool+" hook", err)
+		return
+	}
+	m.logEvent(fmt.Sprintf("Feature %s: branch %s %s", f.ID, f.Branch, event), map[string]string{"suggested": hook.Tool})
+}
+
+// featureArchiveSummary sums up a feature from its description, latest
+// conversations and decisions, for the archive_feature suggestion
+func (m *Manager) featureArchiveSummary(f types.Feature, what string) string {
+	var parts []string
+	if f.Description != "" {
+		parts = append(parts, strings.TrimSuffix(f.Description, ".")+".")
+	}
+	parts = append(parts, fmt.Sprintf("Branch %s %s.", f.Branch, what))
+
+	conversations, _ := m.jsonStore.GetConversations(f.ID)
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].CreatedAt.After(conversations[j].CreatedAt)
+	})
+	for i, c := range conversations {
+		if i == 3 {
+			break
+		}
+		if summary := strings.TrimSpace(c.Summary); summary != "" {
+			parts = append(parts, strings.TrimSuffix(summary, ".")+".")
+		}
+	}
+
+	decisions, _ := m.jsonStore.GetDecisionsByFeature(f.ID)
+	var contents []string
+	for _, d := range decisions {
+		if len(contents) == 5 {
+			break
+		}
+		contents = append(contents, strings.TrimSuffix(d.Content, "."))
+	}
+	if len(contents) > 0 {
+		parts = append(parts, "Decisions: "+strings.Join(contents, "; ")+".")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestFeatureBranchLifecycle(t *testing.T) {
+	r := newWatchedRepo(t)
+	r.write("main.go", "package main\n")
+	r.commit("initial")
+	r.git("checkout", "-q", "-b", "feature/refunds")
+	r.write("refund.go", "package main\n\nfunc Refund() {}\n")
+	r.commit("add refunds")
+	r.git("checkout", "-q", "main")
+
+	store := r.m.jsonStore
+	if err := store.CreateFeature(&types.Feature{ID: "refunds", Branch: "feature/refunds", Description: "Partial refunds"}); err != nil {
+		t.Fatal(err)
+	}
+	store.AddDecision(&types.Decision{Content: "Refunds go through the ledger", Feature: "refunds"})
+
+	pending := func() []types.PendingHook {
+		t.Helper()
+		hooks, err := store.TakePendingHooks()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hooks
+	}
+
+	r.m.syncFeatureBranches()
+	if hooks := pending(); len(hooks) != 0 {
+		t.Fatalf("an unmerged branch should suggest nothing: %+v", hooks)
+	}
+
+	r.git("merge", "-q", "--no-ff", "-m", "merge refunds", "feature/refunds")
+	r.m.syncFeatureBranches()
+	hooks := pending()
+	if len(hooks) != 1 || hooks[0].Tool != "archive_feature" {
+		t.Fatalf("after merge = %+v, want an archive_feature suggestion", hooks)
+	}
+	summary, _ := hooks[0].Arguments["summary"].(string)
+	for _, want := range []string{"Partial refunds.", "Branch feature/refunds was merged into main.", "Decisions: Refunds go through the ledger."} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary %q lacks %q", summary, want)
+		}
+	}
+	if f, _ := store.GetFeature("refunds"); f.Status != "paused" {
+		t.Errorf("status after merge = %s, want paused", f.Status)
+	}
+
+	// Deleting the merged branch is the same event, so nothing new
+	r.git("branch", "-q", "-d", "feature/refunds")
+	r.m.syncFeatureBranches()
+	if hooks := pending(); len(hooks) != 0 {
+		t.Errorf("deleting a merged branch suggested again: %+v", hooks)
+	}
+
+	os.MkdirAll(filepath.Join(r.m.basePath, "archive"), 0755)
+	if err := store.ArchiveFeature("refunds"); err != nil {
+		t.Fatal(err)
+	}
+	r.m.syncFeatureBranches()
+	r.git("checkout", "-q", "-b", "feature/refunds")
+	r.m.syncFeatureBranches()
+	hooks = pending()
+	if len(hooks) != 1 || hooks[0].Tool != "recall_feature" || hooks[0].Arguments["id"] != "refunds" {
+		t.Errorf("after the branch came back = %+v, want a recall_feature suggestion", hooks)
+	}
+}
+
+func TestFeatureBranchDeletedUnmerged(t *testing.T) {
+	r := newWatchedRepo(t)
+	r.write("main.go", "package main\n")
+	r.commit("initial")
+	r.git("checkout", "-q", "-b", "spike")
+	r.write("spike.go", "package main\n")
+	r.commit("try something")
+	r.git("checkout", "-q", "main")
+	r.m.jsonStore.CreateFeature(&types.Feature{ID: "spike", Branch: "spike"})
+
+	r.m.syncFeatureBranches()
+	r.git("branch", "-q", "-D", "spike")
+	r.m.syncFeatureBranches()
+
+	hooks, _ := r.m.jsonStore.TakePendingHooks()
+	if len(hooks) != 1 || !strings.Contains(hooks[0].Reason, "was deleted") {
+		t.Errorf("hooks = %+v, want one archive suggestion for a deleted branch", hooks)
+	}
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/buildgraph"
+)
+
+// IngestBuildGraph parses the project's Bazel or Pants BUILD files and
+// replaces the build edges in the knowledge graph. It returns the number of
+// targets, or 0 when the project doesn't use either build tool.
+func (m *Manager) IngestBuildGraph() (int, error) {
+	graph, err := buildgraph.Ingest(m.projectRoot)
+	if err != nil || graph == nil {
+		return 0, err
+	}
+
+	edges := graph.Edges()
+	relations := []string{buildgraph.RelationBuiltBy, buildgraph.RelationDependsOn}
+	if err := m.jsonStore.ReplaceEdgesByRelation(relations, edges); err != nil {
+		return 0, err
+	}
+
+	for _, e := range graph.Errors {
+		m.recordError("build graph", fmt.Errorf("%s", e))
+	}
+	m.logEvent(fmt.Sprintf("Build graph ingested (%s): %d targets, %d edges", graph.Tool, len(graph.Targets), len(edges)), nil)
+	return len(graph.Targets), nil
+}
+
+// buildGraphChanged reports whether changed files can alter the build
+// graph: BUILD files themselves, or files added to or removed from a
+// package whose sources are globbed.
+func buildGraphChanged(changedFiles []string, added, deleted int) bool {
+	if added > 0 || deleted > 0 {
+		return true
+	}
+	for _, f := range changedFiles {
+		if buildgraph.IsBuildFile(f) || strings.HasSuffix(f, ".bzl") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// Index caps keep huge repos indexable: past them only the highest
+// priority files are indexed and the rest are reported, instead of init
+// running out of disk or memory.
+const (
+	defaultMaxFiles      = 20000
+	defaultMaxChunkRows  = 250000
+	defaultMaxVectorRows = 5000
+	defaultMaxFileSize   = 1024 * 1024
+)
+
+// notebookSizeFactor raises the size cap for Jupyter notebooks, whose
+// saved outputs (plots, tables) usually outweigh their code many times
+const notebookSizeFactor = 10
+
+// activityWindow is how far back commits count towards a directory being
+// active
+const activityWindow = 90 * 24 * time.Hour
+
+// maxActivityCommits caps the commits read to rank directories
+const maxActivityCommits = 2000
+
+// maxSkippedSample caps the skipped paths listed in the report
+const maxSkippedSample = 20
+
+// indexCaps are the effective caps for this project
+type indexCaps struct {
+	maxFiles      int
+	maxChunkRows  int
+	maxVectorRows int
+	maxFileSize   int64
+}
+
+// indexCaps reads the caps from the project config, filling in defaults
+func (m *Manager) indexCaps() indexCaps {
+	caps := indexCaps{
+		maxFiles:      defaultMaxFiles,
+		maxChunkRows:  defaultMaxChunkRows,
+		maxVectorRows: defaultMaxVectorRows,
+		maxFileSize:   defaultMaxFileSize,
+	}
+	cfg, err := m.jsonStore.GetConfig()
+	if err != nil {
+		return caps
+	}
+	if cfg.Index.MaxFiles > 0 {
+		caps.maxFiles = cfg.Index.MaxFiles
+	}
+	if cfg.Index.MaxChunkRows > 0 {
+		caps.maxChunkRows = cfg.Index.MaxChunkRows
+	}
+	if cfg.Index.MaxVectorRows > 0 {
+		caps.maxVectorRows = cfg.Index.MaxVectorRows
+	}
+	if cfg.Index.MaxFileSize > 0 {
+		caps.maxFileSize = cfg.Index.MaxFileSize
+	}
+	return caps
+}
+
+// sizeLimit is the largest file at path that is indexed
+func (caps indexCaps) sizeLimit(path string) int64 {
+	if strings.EqualFold(filepath.Ext(path), ".ipynb") {
+		return caps.maxFileSize * notebookSizeFactor
+	}
+	return caps.maxFileSize
+}
+
+// newCapReport starts a caps report; only a full index run stamps RunAt
+func (caps indexCaps) newCapReport() *types.IndexCapReport {
+	return &types.IndexCapReport{
+		MaxFiles:      caps.maxFiles,
+		MaxChunkRows:  caps.maxChunkRows,
+		MaxVectorRows: caps.maxVectorRows,
+	}
+}
+
+// fileKind classifies a file for prioritizing: source, docs or config
+func fileKind(path string) string {
+	switch fileLanguage(path) {
+	case "markdown":
+		return "docs"
+	case "json", "yaml", "toml", "xml", "dockerfile", "makefile", "unknown":
+		return "config"
+	}
+	return "source"
+}
+
+// kindRank orders kinds: source is indexed first, config last
+var kindRank = map[string]int{"source": 0, "docs": 1, "config": 2}
+
+// topDir is the directory a file counts towards when ranking by activity
+// and reporting skipped files: its first two path segments
+func topDir(rel string) string {
+	parts := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, "/")
+}
+
+// directoryActivity counts recent commits touching each top directory, so
+// the code people are working on is indexed before dormant code. Empty
+// when the project is not a git repository.
+func (m *Manager) directoryActivity() map[string]int {
+	activity := make(map[string]int)
+	commits, err := git.NewHistoryAnalyzer(m.projectRoot).GetCommitHistory(time.Now().Add(-activityWindow), maxActivityCommits)
+	if err != nil {
+		return activity
+	}
+	for _, c := range commits {
+		seen := make(map[string]bool)
+		for _, f := range c.FilesChanged {
+			dir := topDir(f)
+			if !seen[dir] {
+				seen[dir] = true
+				activity[dir]++
+			}
+		}
+	}
+	return activity
+}
+
+// prioritizeFiles orders absolute paths by how much they are worth
+// indexing: source before docs before config, then files in recently
+// active directories, then by path for a stable order
+func (m *Manager) prioritizeFiles(paths []string, activity map[string]int) {
+	type ranked struct {
+		path     string
+		kind     int
+		activity int
+	}
+	entries := make([]ranked, len(paths))
+	for i, p := range paths {
+		rel := m.toRelativePath(p)
+		entries[i] = ranked{path: p, kind: kindRank[fileKind(p)], activity: activity[topDir(rel)]}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].kind != entries[j].kind {
+			return entries[i].kind < entries[j].kind
+		}
+		if entries[i].activity != entries[j].activity {
+			return entries[i].activity > entries[j].activity
+		}
+		return entries[i].path < entries[j].path
+	})
+	for i, e := range entries {
+		paths[i] = e.path
+	}
+}
+
+// recordSkipped adds a file left out by the caps to the report
+func (m *Manager) recordSkipped(report *types.IndexCapReport, path string) {
+	rel := m.toRelativePath(path)
+	report.FilesSkipped++
+	if report.SkippedByKind == nil {
+		report.SkippedByKind = make(map[string]int)
+		report.SkippedDirs = make(map[string]int)
+	}
+	report.SkippedByKind[fileKind(path)]++
+	report.SkippedDirs[topDir(rel)]++
+	if len(report.SkippedSample) < maxSkippedSample {
+		report.SkippedSample = append(report.SkippedSample, rel)
+	}
+}
+
+// saveCapReport stores the report and says on stderr what was left out
+func (m *Manager) saveCapReport(report *types.IndexCapReport) {
+	if err := m.jsonStore.SaveIndexCapReport(report); err != nil {
+		m.recordError("save index caps report", err)
+	}
+	if report.FilesSkipped > 0 {
+		fmt.Fprintf(os.Stderr, "  [WARNING] index.max_files (%d) reached: skipped %d lower-priority files; see index_status\n", report.MaxFiles, report.FilesSkipped)
+	}
+	if report.FilesWithoutChunks > 0 {
+		fmt.Fprintf(os.Stderr, "  [WARNING] index.max_chunk_rows (%d) reached: %d files are indexed but not searchable by content\n", report.MaxChunkRows, report.FilesWithoutChunks)
+	}
+}
+
+// chunkRoom reports whether new files may still add code chunks
+func (m *Manager) chunkRoom(caps indexCaps) bool {
+	stats, err := m.sqliteIndex.GetStats()
+	return err != nil || stats["code_chunks"] < caps.maxChunkRows
+}
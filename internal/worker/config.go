@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// ConfigReloaded starts the worker log entry written when a changed
+// config.json is applied; get_feed lists these entries
+const ConfigReloaded = "Config reloaded"
+
+// ApplyConfig applies the project config to the workers while they run:
+// the intervals under worker, index.exclude and index.parser. Running
+// loops pick up new intervals at once instead of after their next tick.
+func (m *Manager) ApplyConfig(cfg *types.Config) {
+	if err := skeleton.SetParser(cfg.Index.Parser); err != nil {
+		fmt.Fprintf(os.Stderr, "  [WARNING] index.parser: %v\n", err)
+	}
+
+	defaults := DefaultConfig()
+	m.mu.Lock()
+	m.config.GitWatchInterval = interval(cfg.Worker.GitWatchSeconds, time.Second, defaults.GitWatchInterval)
+	m.config.ReindexInterval = interval(cfg.Worker.ReindexMinutes, time.Minute, defaults.ReindexInterval)
+	m.config.AutoDiscoverInterval = interval(cfg.Worker.AutoDiscoverMinutes, time.Minute, defaults.AutoDiscoverInterval)
+	m.exclude = append([]string(nil), cfg.Index.Exclude...)
+	close(m.reload)
+	m.reload = make(chan struct{})
+	m.mu.Unlock()
+}
+
+// LogConfigReload records in the worker log that config changes took
+// effect, naming the settings that changed
+func (m *Manager) LogConfigReload(changed []string) {
+	m.logEvent(fmt.Sprintf("%s: %s", ConfigReloaded, strings.Join(changed, ", ")), map[string]interface{}{"changed": changed})
+}
+
+// interval is n units, or the default when n is not set
+func interval(n int, unit, def time.Duration) time.Duration {
+	if n <= 0 {
+		return def
+	}
+	return time.Duration(n) * unit
+}
+
+// reloaded returns a channel closed at the next ApplyConfig
+func (m *Manager) reloaded() <-chan struct{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.reload
+}
+
+// tickEvery runs fn every time the interval picked from the config passes,
+// until the workers stop. A config change restarts the wait with the new
+// interval.
+func (m *Manager) tickEvery(pick func(WorkerConfig) time.Duration, fn func()) {
+	ticker := time.NewTicker(pick(m.GetConfig()))
+	defer ticker.Stop()
+	reload := m.reloaded()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-reload:
+			reload = m.reloaded()
+			ticker.Reset(pick(m.GetConfig()))
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// excluded reports whether index.exclude keeps a file or directory, given
+// relative to the project root, out of the index
+func (m *Manager) excluded(rel string) bool {
+	m.mu.RLock()
+	patterns := m.exclude
+	m.mu.RUnlock()
+	return matchExclude(patterns, rel)
+}
+
+// excludedPath is excluded for an absolute path
+func (m *Manager) excludedPath(path string) bool {
+	return path != m.projectRoot && m.excluded(m.toRelativePath(path))
+}
+
+// matchExclude reports whether a relative path, or a directory it lies in,
+// matches one of the patterns. As in .gitignore, a pattern without a slash
+// matches a name anywhere ("*.min.js", "fixtures"); one with a slash, a
+// leading one included, matches from the project root, with "**" spanning
+// any number of directories ("apps/*/generated", "docs/**/*.pdf", "/tmp").
+func matchExclude(patterns []string, rel string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimRight(filepath.ToSlash(strings.TrimSpace(pattern)), "/")
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimLeft(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		if !anchored {
+			for _, part := range parts {
+				if ok, _ := filepath.Match(pattern, part); ok {
+					return true
+				}
+			}
+			continue
+		}
+		patParts := strings.Split(pattern, "/")
+		for i := 1; i <= len(parts); i++ {
+			if matchSegments(patParts, parts[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where
+// "**" matches any number of segments
+func matchSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for k := 0; k <= len(path); k++ {
+			if matchSegments(pattern[1:], path[k:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pattern[0], path[0])
+	return ok && matchSegments(pattern[1:], path[1:])
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestMatchExclude(t *testing.T) {
+	patterns := []string{"fixtures", "*.min.js", "apps/*/generated", "docs/**/*.pdf", " /tmp/ "}
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"test/fixtures/user.json", true},
+		{"fixtures", true},
+		{"public/vendor.min.js", true},
+		{"apps/web/generated/api.ts", true},
+		{"apps/web/generated", true},
+		{"apps/web/src/generated.ts", false},
+		{"docs/guide/v2/manual.pdf", true},
+		{"docs/manual.pdf", true},
+		{"tmp/cache.txt", true},
+		{"src/tmp/cache.txt", false},
+		{"src/app.ts", false},
+	} {
+		if got := matchExclude(patterns, tc.path); got != tc.want {
+			t.Errorf("matchExclude(%s) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	m := &Manager{config: DefaultConfig(), reload: make(chan struct{})}
+	reload := m.reloaded()
+
+	m.ApplyConfig(&types.Config{
+		Index:  types.IndexConfig{Exclude: []string{"fixtures"}},
+		Worker: types.WorkerSettings{GitWatchSeconds: 5, AutoDiscoverMinutes: 1},
+	})
+
+	select {
+	case <-reload:
+	default:
+		t.Error("running loops were not told about the change")
+	}
+	cfg := m.GetConfig()
+	if cfg.GitWatchInterval != 5*time.Second || cfg.AutoDiscoverInterval != time.Minute || cfg.ReindexInterval != DefaultConfig().ReindexInterval {
+		t.Errorf("intervals = %v, %v, %v", cfg.GitWatchInterval, cfg.ReindexInterval, cfg.AutoDiscoverInterval)
+	}
+	if !m.excluded("test/fixtures/a.json") || m.excluded("src/a.ts") {
+		t.Error("index.exclude not applied")
+	}
+}
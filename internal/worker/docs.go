@@ -0,0 +1,236 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saeedalam/teamcontext/internal/storage"
+)
+
+// maxDocSectionLines caps a single documentation chunk; longer sections are
+// split into consecutive parts under the same heading.
+const maxDocSectionLines = 80
+
+// isDocFile reports whether ext is a markdown documentation file
+func isDocFile(ext string) bool {
+	return ext == ".md" || ext == ".mdx" || ext == ".markdown"
+}
+
+// markdownHeading returns the level and title of an ATX heading line
+// ("## Setup" -> 2, "Setup"), or 0 if the line is not a heading.
+func markdownHeading(line string) (int, string) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || !strings.HasPrefix(trimmed, "#") {
+		return 0, ""
+	}
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level > 6 || (level < len(trimmed) && trimmed[level] != ' ' && trimmed[level] != '\t') {
+		return 0, ""
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(trimmed[level:]), "#"))
+	return level, title
+}
+
+// setextLevel returns 1 or 2 when line is a setext underline ("=====" or
+// "-----") making the line above it a heading, else 0
+func setextLevel(line string) int {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || len(line)-len(strings.TrimLeft(line, " ")) > 3 {
+		return 0
+	}
+	switch {
+	case strings.Trim(trimmed, "=") == "":
+		return 1
+	case strings.Trim(trimmed, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+// docLine is what scanMarkdown found on a line
+type docLine struct {
+	level int    // heading level, 0 if the line is not a heading
+	title string // heading title
+	skip  bool   // fence marker, setext underline or front matter
+	front bool   // YAML front matter
+	code  bool   // inside a fenced code block
+}
+
+// scanMarkdown finds the ATX and setext headings of a document, ignoring
+// fenced code blocks and YAML front matter
+func scanMarkdown(lines []string) []docLine {
+	out := make([]docLine, len(lines))
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for j := 1; j < len(lines); j++ {
+			if t := strings.TrimSpace(lines[j]); t == "---" || t == "..." {
+				for k := 0; k <= j; k++ {
+					out[k].skip, out[k].front = true, true
+				}
+				start = j + 1
+				break
+			}
+		}
+	}
+
+	inFence := false
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			out[i].skip = true
+			continue
+		}
+		if inFence {
+			out[i].code = true
+			continue
+		}
+		if out[i].skip {
+			continue // underline of the heading above
+		}
+		if level, title := markdownHeading(lines[i]); level > 0 {
+			out[i].level, out[i].title = level, title
+			continue
+		}
+		if trimmed != "" && i+1 < len(lines) {
+			if level := setextLevel(lines[i+1]); level > 0 {
+				out[i].level, out[i].title = level, trimmed
+				out[i+1].skip = true
+			}
+		}
+	}
+	return out
+}
+
+// chunkMarkdown splits a markdown document into heading-scoped sections.
+// Each chunk is named by its heading breadcrumb ("Install > Linux") so search
+// hits can be cited precisely. Headings inside fenced code blocks are ignored,
+// and sections with nothing under their heading are left out.
+func chunkMarkdown(relPath string, lines []string) []storage.CodeChunk {
+	var chunks []storage.CodeChunk
+	var trail []string // heading titles by level
+	scan := scanMarkdown(lines)
+	start := 0
+	for start < len(scan) && scan[start].front {
+		start++
+	}
+	body := start // first line after the section's heading
+	name := ""
+
+	flush := func(end int) {
+		if strings.TrimSpace(strings.Join(lines[body:end], "\n")) == "" {
+			return
+		}
+		for s := start; s < end; s += maxDocSectionLines {
+			e := s + maxDocSectionLines
+			if e > end {
+				e = end
+			}
+			content := strings.Join(lines[s:e], "\n")
+			if strings.TrimSpace(content) == "" {
+				continue
+			}
+			chunkName := name
+			if chunkName == "" {
+				chunkName = "(preamble)"
+			}
+			if s > start {
+				chunkName += fmt.Sprintf(" (part %d)", (s-start)/maxDocSectionLines+1)
+			}
+			chunks = append(chunks, storage.CodeChunk{
+				FilePath:  relPath,
+				ChunkType: "doc",
+				ChunkName: chunkName,
+				StartLine: s + 1,
+				EndLine:   e,
+				Content:   content,
+				Language:  "markdown",
+			})
+		}
+	}
+
+	for i, dl := range scan {
+		if dl.level == 0 {
+			continue
+		}
+
+		flush(i)
+		start, body = i, i+1
+		if body < len(scan) && scan[body].skip {
+			body++ // setext underline
+		}
+
+		level := dl.level
+		if len(trail) >= level {
+			trail = trail[:level-1]
+		}
+		for len(trail) < level-1 {
+			trail = append(trail, "")
+		}
+		trail = append(trail, dl.title)
+
+		var parts []string
+		for _, t := range trail {
+			if t != "" {
+				parts = append(parts, t)
+			}
+		}
+		name = strings.Join(parts, " > ")
+	}
+	flush(len(lines))
+
+	return chunks
+}
+
+// buildDocSummary summarizes a markdown document by its title and the
+// first paragraph of prose.
+func buildDocSummary(lines []string) string {
+	title := ""
+	var para []string
+
+	for i, dl := range scanMarkdown(lines) {
+		if dl.skip || dl.code {
+			continue
+		}
+		trimmed := strings.TrimSpace(lines[i])
+		if dl.level > 0 {
+			if title == "" {
+				title = dl.title
+			}
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		if trimmed == "" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		// Skip badges, images, html and rule noise
+		if strings.HasPrefix(trimmed, "[![") || strings.HasPrefix(trimmed, "![") ||
+			strings.HasPrefix(trimmed, "<") || trimmed == "---" {
+			continue
+		}
+		para = append(para, trimmed)
+	}
+
+	summary := strings.Join(para, " ")
+	if utf8.RuneCountInString(summary) > 200 {
+		summary = string([]rune(summary)[:197]) + "..."
+	}
+	switch {
+	case title != "" && summary != "":
+		return "Doc: " + title + " — " + summary
+	case title != "":
+		return "Doc: " + title
+	case summary != "":
+		return "Doc: " + summary
+	}
+	return "Markdown document"
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestMarkdownHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		title string
+	}{
+		{"# Title", 1, "Title"},
+		{"### Setup ###", 3, "Setup"},
+		{"   ## Indented", 2, "Indented"},
+		{"    # Code block", 0, ""},
+		{"#hashtag", 0, ""},
+		{"####### Seven", 0, ""},
+		{"Plain text", 0, ""},
+	}
+	for _, tt := range tests {
+		level, title := markdownHeading(tt.line)
+		if level != tt.level || title != tt.title {
+			t.Errorf("markdownHeading(%q) = %d, %q; want %d, %q", tt.line, level, title, tt.level, tt.title)
+		}
+	}
+}
+
+func TestChunkMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string // chunk names
+	}{
+		{
+			name: "breadcrumbs",
+			doc:  "intro\n# Install\ntext\n## Linux\napt install\n# Usage\nrun it",
+			want: []string{"(preamble)", "Install", "Install > Linux", "Usage"},
+		},
+		{
+			name: "fenced code containing #",
+			doc:  "# Build\n```sh\n# not a heading\nmake\n```\n~~~\n## nor this\n~~~\n# Test\ngo test",
+			want: []string{"Build", "Test"},
+		},
+		{
+			name: "setext headings",
+			doc:  "Project\n=======\n\nAbout it.\n\nSetup\n-----\nSteps.\n\n### Details\nMore.",
+			want: []string{"Project", "Project > Setup", "Project > Setup > Details"},
+		},
+		{
+			name: "empty sections",
+			doc:  "# Guide\n\n## Empty\n\n## Filled\ncontent\n# Trailing\n\n",
+			want: []string{"Guide > Filled"},
+		},
+		{
+			name: "front matter is not a setext heading",
+			doc:  "---\ntitle: Guide\n---\n# Guide\ntext",
+			want: []string{"Guide"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkMarkdown("docs/guide.md", strings.Split(tt.doc, "\n"))
+			var got []string
+			for _, c := range chunks {
+				got = append(got, c.ChunkName)
+				if c.ChunkType != "doc" || c.FilePath != "docs/guide.md" {
+					t.Errorf("chunk %+v", c)
+				}
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("chunks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkMarkdownSplitsLongSections(t *testing.T) {
+	lines := []string{"# Long"}
+	for i := 0; i < maxDocSectionLines+10; i++ {
+		lines = append(lines, "line")
+	}
+	chunks := chunkMarkdown("a.md", lines)
+	if len(chunks) != 2 || chunks[1].ChunkName != "Long (part 2)" || chunks[1].StartLine != maxDocSectionLines+1 {
+		t.Fatalf("chunks = %+v", chunks)
+	}
+}
+
+func TestBuildDocSummary(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{"# Payments\n\n[![ci](x)](y)\nHandles refunds\nand retries.\n\nMore.", "Doc: Payments — Handles refunds and retries."},
+		{"Payments\n========\n\nHandles refunds.", "Doc: Payments — Handles refunds."},
+		{"---\ntitle: x\n---\n# Guide", "Doc: Guide"},
+		{"```\n# code\n```\nJust prose.", "Doc: Just prose."},
+		{"", "Markdown document"},
+	}
+	for _, tt := range tests {
+		if got := buildDocSummary(strings.Split(tt.doc, "\n")); got != tt.want {
+			t.Errorf("buildDocSummary(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestBuildDocSummaryTruncatesByRune(t *testing.T) {
+	got := buildDocSummary([]string{"# Ü", strings.Repeat("é", 300)})
+	if !utf8.ValidString(got) {
+		t.Fatalf("summary is not valid UTF-8: %q", got)
+	}
+	if !strings.HasSuffix(got, "...") || utf8.RuneCountInString(got) != utf8.RuneCountInString("Doc: Ü — ")+200 {
+		t.Errorf("summary = %d runes: %q", utf8.RuneCountInString(got), got)
+	}
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/blueprint"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// manifestChanged reports whether changed files include a package manifest
+// framework detection reads
+func manifestChanged(changedFiles []string) bool {
+	for _, f := range changedFiles {
+		if blueprint.IsManifest(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshFrameworks drops the cached framework detection, detects again and
+// records the frameworks in project knowledge, so query can say what the
+// project is built with. It returns the frameworks detected.
+func (m *Manager) refreshFrameworks() []string {
+	blueprint.InvalidateFrameworks(m.projectRoot)
+	names := blueprint.DetectFrameworks(m.projectRoot).Names()
+
+	changed := false
+	_, err := m.jsonStore.UpdateProject(func(project *types.Project) bool {
+		if reflect.DeepEqual(project.Frameworks, names) {
+			return false
+		}
+		project.Frameworks = names
+		changed = true
+		return true
+	})
+	if err != nil {
+		m.recordError("save frameworks", err)
+		return names
+	}
+	if !changed {
+		return names
+	}
+	m.logEvent(fmt.Sprintf("Frameworks detected: %s", strings.Join(names, ", ")), names)
+	return names
+}
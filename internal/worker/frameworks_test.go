@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/blueprint"
+)
+
+func TestManifestChangeRefreshesFrameworks(t *testing.T) {
+	r := newWatchedRepo(t)
+	r.write("go.mod", "module example.com/shop\n\nrequire github.com/gin-gonic/gin v1.9.0\n")
+	r.write("main.go", "package main\n")
+	r.commit("initial")
+	if _, err := r.m.InitProject(); err != nil {
+		t.Fatalf("InitProject: %v", err)
+	}
+	frameworks := func() []string {
+		t.Helper()
+		project, err := r.m.jsonStore.GetProject()
+		if err != nil {
+			t.Fatalf("GetProject: %v", err)
+		}
+		return project.Frameworks
+	}
+	if got := frameworks(); !reflect.DeepEqual(got, []string{"go-gin"}) {
+		t.Fatalf("frameworks after init = %v, want go-gin", got)
+	}
+
+	r.write("go.mod", "module example.com/shop\n\nrequire github.com/labstack/echo/v4 v4.11.0\n")
+	r.m.applyFileChanges([]string{"main.go"})
+	if got := blueprint.DetectFrameworks(r.m.projectRoot).Framework; got != "go-gin" {
+		t.Errorf("detection without a manifest change = %s, want the cached go-gin", got)
+	}
+
+	r.m.applyFileChanges([]string{"go.mod"})
+	if got := blueprint.DetectFrameworks(r.m.projectRoot).Framework; got != "go-echo" {
+		t.Errorf("detection after go.mod changed = %s, want go-echo", got)
+	}
+	if got := frameworks(); !reflect.DeepEqual(got, []string{"go-echo"}) {
+		t.Errorf("recorded frameworks = %v, want go-echo", got)
+	}
+}
@@ -0,0 +1,175 @@
+package worker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels of worker log entries, from least to most severe
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Worker log rotation defaults: worker.log is rotated to worker.log.1 once
+// it passes 5 MB, and worker.log.3 is the oldest kept
+const (
+	defaultLogMaxBytes = 5 << 20
+	defaultLogBackups  = 3
+)
+
+// logMu serializes writes and rotation of the worker log
+var logMu sync.Mutex
+
+// LogEntry is one JSON line of the worker log. Entries written before
+// levels were recorded read as info.
+type LogEntry struct {
+	Time    time.Time   `json:"time"`
+	Level   string      `json:"level,omitempty"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// LogQuery filters worker log entries. Level is the least severe level
+// returned: "warn" returns warnings and errors.
+type LogQuery struct {
+	Level    string
+	Since    time.Time
+	Contains string
+	Limit    int
+}
+
+// LogPath is the worker log of a .teamcontext directory
+func LogPath(basePath string) string {
+	return filepath.Join(basePath, "cache", "worker.log")
+}
+
+// logEvent writes an info event to the worker log
+func (m *Manager) logEvent(message string, data interface{}) {
+	m.writeLog(LevelInfo, message, data)
+}
+
+// logWarning writes a warning to the worker log
+func (m *Manager) logWarning(message string, data interface{}) {
+	m.writeLog(LevelWarn, message, data)
+}
+
+func (m *Manager) writeLog(level, message string, data interface{}) {
+	line, err := json.Marshal(LogEntry{Time: time.Now(), Level: level, Message: message, Data: data})
+	if err != nil {
+		return
+	}
+	m.mu.RLock()
+	maxBytes, backups := m.config.LogMaxBytes, m.config.LogBackups
+	m.mu.RUnlock()
+	appendLog(LogPath(m.basePath), append(line, '\n'), maxBytes, backups)
+}
+
+// appendLog appends a line to the log, rotating it first when the line
+// would take it past maxBytes
+func appendLog(path string, line []byte, maxBytes int64, backups int) {
+	if maxBytes <= 0 {
+		maxBytes = defaultLogMaxBytes
+	}
+	if backups <= 0 {
+		backups = defaultLogBackups
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > maxBytes {
+		rotateLog(path, backups)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(line)
+}
+
+// rotateLog shifts worker.log to worker.log.1, worker.log.1 to
+// worker.log.2 and so on, dropping the oldest backup
+func rotateLog(path string, backups int) {
+	os.Remove(fmt.Sprintf("%s.%d", path, backups))
+	for i := backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	os.Rename(path, path+".1")
+}
+
+// ReadLog returns the worker log entries matching a query, newest first,
+// from the current log and its rotated backups
+func ReadLog(basePath string, q LogQuery) ([]LogEntry, error) {
+	path := LogPath(basePath)
+	minSeverity := levelSeverity(q.Level)
+	contains := strings.ToLower(q.Contains)
+
+	var entries []LogEntry
+	// Oldest backup first, so entries come out in write order
+	files := []string{path}
+	for i := 1; ; i++ {
+		backup := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(backup); err != nil {
+			break
+		}
+		files = append([]string{backup}, files...)
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			var e LogEntry
+			if json.Unmarshal(scanner.Bytes(), &e) != nil {
+				continue
+			}
+			if e.Level == "" {
+				e.Level = LevelInfo
+			}
+			if levelSeverity(e.Level) < minSeverity || (!q.Since.IsZero() && e.Time.Before(q.Since)) {
+				continue
+			}
+			if contains != "" && !strings.Contains(strings.ToLower(e.Message), contains) {
+				continue
+			}
+			entries = append(entries, e)
+		}
+		f.Close()
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+func levelSeverity(level string) int {
+	switch strings.ToLower(level) {
+	case LevelWarn, "warning":
+		return 1
+	case LevelError:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogRotationAndQuery(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), ".teamcontext")
+	if err := os.MkdirAll(filepath.Join(basePath, "cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := LogPath(basePath)
+
+	// A line from before levels were recorded reads as info
+	old := `{"time":"2020-01-01T00:00:00Z","message":"Git change detected"}` + "\n"
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{basePath: basePath, config: WorkerConfig{LogMaxBytes: 400, LogBackups: 2}}
+	for i := 0; i < 20; i++ {
+		m.logEvent(fmt.Sprintf("Reindexed %d files", i), nil)
+	}
+	m.logWarning("index.max_files (10) reached", nil)
+	m.recordError("git diff", fmt.Errorf("exit status 128"))
+
+	for _, file := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+		if info.Size() > 400 {
+			t.Errorf("%s is %d bytes, past the 400 byte cap", filepath.Base(file), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("more backups kept than configured")
+	}
+	if m.stats.ErrorCount != 1 || m.stats.LastError != "git diff: exit status 128" {
+		t.Errorf("stats = %+v", m.stats)
+	}
+
+	all, err := ReadLog(basePath, LogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) == 0 || all[0].Level != LevelError || all[1].Level != LevelWarn {
+		t.Fatalf("entries should be newest first: %+v", all)
+	}
+
+	warnings, _ := ReadLog(basePath, LogQuery{Level: LevelWarn})
+	if len(warnings) != 2 {
+		t.Errorf("warn and above = %+v, want the warning and the error", warnings)
+	}
+	matching, _ := ReadLog(basePath, LogQuery{Contains: "REINDEXED 19"})
+	if len(matching) != 1 || matching[0].Message != "Reindexed 19 files" {
+		t.Errorf("contains = %+v", matching)
+	}
+	limited, _ := ReadLog(basePath, LogQuery{Limit: 3})
+	if len(limited) != 3 {
+		t.Errorf("limit 3 returned %d entries", len(limited))
+	}
+
+	since := time.Now()
+	m.logEvent("after", nil)
+	recent, _ := ReadLog(basePath, LogQuery{Since: since})
+	if len(recent) != 1 || recent[0].Message != "after" {
+		t.Errorf("since = %+v, want only the last entry", recent)
+	}
+}
+
+func TestReadLogLegacyLevel(t *testing.T) {
+	basePath := filepath.Join(t.TempDir(), ".teamcontext")
+	os.MkdirAll(filepath.Join(basePath, "cache"), 0755)
+	os.WriteFile(LogPath(basePath), []byte(`{"time":"2020-01-01T00:00:00Z","message":"Git change detected"}`+"\nnot json\n"), 0644)
+
+	entries, err := ReadLog(basePath, LogQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Level != LevelInfo {
+		t.Errorf("entries = %+v", entries)
+	}
+	if errors, _ := ReadLog(basePath, LogQuery{Level: LevelError}); len(errors) != 0 {
+		t.Errorf("legacy entries should not count as errors: %+v", errors)
+	}
+}
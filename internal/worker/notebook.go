@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/internal/storage"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// maxCellChunkLines splits very long notebook cells into several chunks
+const maxCellChunkLines = 100
+
+// notebookChunks makes one search chunk per code cell of a notebook, named
+// after the cell and the markdown heading above it, and one doc chunk per
+// markdown cell so the prose explaining an analysis is searchable too.
+// Lines refer to the notebook's script form; a markdown cell has no lines
+// there and points at the code cell that follows it.
+func notebookChunks(relPath string, nb *skeleton.Notebook) []storage.CodeChunk {
+	var chunks []storage.CodeChunk
+	section := ""
+	next := 1 // where the next code cell's marker goes in the script
+	for _, cell := range nb.Cells {
+		if cell.Kind == "markdown" {
+			if heading := cell.Heading(); heading != "" {
+				section = heading
+			}
+			if text := strings.TrimSpace(cell.Source); text != "" {
+				name := fmt.Sprintf("cell %d", cell.Index)
+				if section != "" {
+					name = section + " > " + name
+				}
+				chunks = append(chunks, storage.CodeChunk{
+					FilePath:  relPath,
+					ChunkType: "doc",
+					ChunkName: name,
+					StartLine: next,
+					EndLine:   next,
+					Content:   text,
+					Language:  "markdown",
+				})
+			}
+			continue
+		}
+		if cell.Kind != "code" {
+			continue
+		}
+		next = cell.Line + cell.Lines
+		if strings.TrimSpace(cell.Source) == "" {
+			continue
+		}
+
+		name := fmt.Sprintf("cell %d", cell.Index)
+		if section != "" {
+			name = section + " > " + name
+		}
+		lines := strings.Split(strings.TrimRight(cell.Source, "\n"), "\n")
+		for s := 0; s < len(lines); s += maxCellChunkLines {
+			e := s + maxCellChunkLines
+			if e > len(lines) {
+				e = len(lines)
+			}
+			chunkName := name
+			if s > 0 {
+				chunkName += fmt.Sprintf(" (part %d)", s/maxCellChunkLines+1)
+			}
+			chunks = append(chunks, storage.CodeChunk{
+				FilePath:  relPath,
+				ChunkType: "cell",
+				ChunkName: chunkName,
+				StartLine: cell.Line + s,
+				EndLine:   cell.Line + e - 1,
+				Content:   strings.Join(lines[s:e], "\n"),
+				Language:  "jupyter",
+			})
+		}
+	}
+	return chunks
+}
+
+// notebookSummary describes a notebook by its title and introduction, then
+// its code cells and what they import and define
+func notebookSummary(sk *types.CodeSkeleton) string {
+	cells := 0
+	var intro string
+	var imports, defines []string
+	for _, c := range sk.Cells {
+		if c.Kind != "code" {
+			// The first markdown cell before any code introduces the notebook
+			if cells == 0 && intro == "" {
+				intro = c.Title
+				if c.Summary != "" {
+					if intro != "" {
+						intro += ": "
+					}
+					intro += c.Summary
+				}
+			}
+			continue
+		}
+		cells++
+		for _, imp := range c.Imports {
+			if !containsName(imports, imp) {
+				imports = append(imports, imp)
+			}
+		}
+		defines = append(defines, c.Defines...)
+	}
+
+	summary := fmt.Sprintf("Jupyter notebook, %d code cell(s)", cells)
+	if intro != "" {
+		if !strings.HasSuffix(intro, ".") && !strings.HasSuffix(intro, "!") && !strings.HasSuffix(intro, "?") {
+			intro += "."
+		}
+		summary = intro + " " + summary
+	}
+	if len(imports) > 0 {
+		summary += "; imports " + shortList(imports, 4)
+	}
+	if len(defines) > 0 {
+		summary += "; defines " + shortList(defines, 3)
+	}
+	return summary
+}
+
+// shortList joins the first max names and counts the rest
+func shortList(names []string, max int) string {
+	if len(names) > max {
+		return strings.Join(names[:max], ", ") + fmt.Sprintf(" (+%d more)", len(names)-max)
+	}
+	return strings.Join(names, ", ")
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
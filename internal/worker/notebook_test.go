@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+func TestNotebookChunks(t *testing.T) {
+	nb, err := skeleton.ParseNotebook([]byte(`{
+ "cells": [
+  {"cell_type": "markdown", "source": "# Churn model\n\nLoads events and trains a model, e.g. a logistic regression. Then it scores.\n\n![plot](plot.png)"},
+  {"cell_type": "code", "source": "import pandas as pd\ndf = pd.read_csv('events.csv')"},
+  {"cell_type": "markdown", "source": "Features are scaled first."},
+  {"cell_type": "code", "source": "df = scale(df)"}
+ ],
+ "metadata": {}
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := nb.Cells[0].Summary(); got != "Loads events and trains a model, e.g. a logistic regression." {
+		t.Errorf("summary = %q", got)
+	}
+
+	chunks := notebookChunks("analysis/churn.ipynb", nb)
+	want := []struct {
+		typ, name  string
+		start, end int
+	}{
+		{"doc", "Churn model > cell 1", 1, 1},
+		{"cell", "Churn model > cell 2", 2, 3},
+		{"doc", "Churn model > cell 3", 4, 4},
+		{"cell", "Churn model > cell 4", 5, 5},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("chunks = %+v", chunks)
+	}
+	for i, w := range want {
+		c := chunks[i]
+		if c.ChunkType != w.typ || c.ChunkName != w.name || c.StartLine != w.start || c.EndLine != w.end {
+			t.Errorf("chunk %d = %s %q L%d-%d, want %s %q L%d-%d", i, c.ChunkType, c.ChunkName, c.StartLine, c.EndLine, w.typ, w.name, w.start, w.end)
+		}
+	}
+	if chunks[2].Content != "Features are scaled first." {
+		t.Errorf("markdown chunk content = %q", chunks[2].Content)
+	}
+}
+
+func TestNotebookSummary(t *testing.T) {
+	sk := &types.CodeSkeleton{Cells: []types.CellOutline{
+		{Index: 1, Kind: "markdown", Title: "Churn model", Summary: "Loads events and trains."},
+		{Index: 2, Kind: "code", Imports: []string{"pandas"}},
+		{Index: 3, Kind: "markdown", Title: "Scoring"},
+		{Index: 4, Kind: "code", Defines: []string{"score"}},
+	}}
+	want := "Churn model: Loads events and trains. Jupyter notebook, 2 code cell(s); imports pandas; defines score"
+	if got := notebookSummary(sk); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+
+	sk.Cells[0].Summary = ""
+	if got := notebookSummary(sk); !strings.HasPrefix(got, "Churn model. Jupyter notebook") {
+		t.Errorf("title-only summary = %q", got)
+	}
+}
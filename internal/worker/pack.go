@@ -0,0 +1,407 @@
+package worker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+)
+
+// indexArtifactVersion is bumped when the artifact layout changes
+const indexArtifactVersion = 1
+
+// manifestName is the first entry of every index artifact
+const manifestName = "manifest.json"
+
+// IndexManifest describes a packed index: the commit it was built at and
+// the content hash of every indexed file, used to find what differs in the
+// working tree it is unpacked into
+type IndexManifest struct {
+	Version   int               `json:"version"`
+	Commit    string            `json:"commit,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Files     map[string]string `json:"files"` // project-relative path -> sha256 of content
+}
+
+// ReconcileReport summarizes how an unpacked index was brought up to date
+type ReconcileReport struct {
+	ArtifactCommit string `json:"artifact_commit,omitempty"`
+	LocalCommit    string `json:"local_commit,omitempty"`
+	Unchanged      int    `json:"unchanged"`
+	Modified       int    `json:"modified"`
+	Added          int    `json:"added"`
+	Removed        int    `json:"removed"`
+	Indexed        int    `json:"indexed"`
+}
+
+// indexArtifactEntry reports whether a path inside .teamcontext belongs in
+// an artifact. Knowledge is left out: it is shared through the repository.
+func indexArtifactEntry(name string) bool {
+	switch name {
+	case manifestName, "tree.yaml", "cache/index.db", "cache/git-analysis.json":
+		return true
+	}
+	return strings.HasPrefix(name, "index/") && safeRelativePath(name)
+}
+
+// safeRelativePath reports whether a slash-separated path stays below the
+// directory it is relative to: not absolute and without ".." segments
+func safeRelativePath(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return false
+	}
+	for _, segment := range strings.Split(strings.ReplaceAll(name, "\\", "/"), "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// validate rejects manifests naming files outside the project, which
+// reconciling would otherwise hash and reindex
+func (manifest *IndexManifest) validate() error {
+	for path := range manifest.Files {
+		if !safeRelativePath(path) {
+			return fmt.Errorf("manifest lists a path outside the project: %s", path)
+		}
+	}
+	return nil
+}
+
+// renameFile is os.Rename, replaced in tests to simulate failures
+var renameFile = os.Rename
+
+// PackIndex writes the index (JSON, SQLite and code tree) to a gzipped tar
+// at out, keyed by the current commit. It returns the manifest written.
+func (m *Manager) PackIndex(out string) (*IndexManifest, error) {
+	files, err := m.jsonStore.GetFilesIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &IndexManifest{
+		Version:   indexArtifactVersion,
+		CreatedAt: time.Now(),
+		Files:     make(map[string]string, len(files)),
+	}
+	manifest.Commit, _ = git.GetHeadHash(m.projectRoot)
+	for path, file := range files {
+		if file.DeletedAt != nil {
+			continue
+		}
+		if hash, err := hashFile(relpath.ToAbs(m.projectRoot, path)); err == nil {
+			manifest.Files[path] = hash
+		}
+	}
+
+	// The live database may have pages in its WAL; pack a compacted copy
+	snapshot, err := os.CreateTemp("", "teamcontext-index-*.db")
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Close()
+	defer os.Remove(snapshot.Name())
+	if err := m.sqliteIndex.SnapshotTo(snapshot.Name()); err != nil {
+		return nil, fmt.Errorf("snapshot SQLite index: %w", err)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarEntry(tw, manifestName, manifestJSON); err != nil {
+		return nil, err
+	}
+	if err := addTarFile(tw, "cache/index.db", snapshot.Name()); err != nil {
+		return nil, err
+	}
+
+	var extra []string
+	filepath.Walk(filepath.Join(m.basePath, "index"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			extra = append(extra, path)
+		}
+		return nil
+	})
+	extra = append(extra, filepath.Join(m.basePath, "tree.yaml"), filepath.Join(m.basePath, "cache", "git-analysis.json"))
+	for _, path := range extra {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		rel, _ := filepath.Rel(m.basePath, path)
+		if err := addTarFile(tw, filepath.ToSlash(rel), path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, f.Close()
+}
+
+// UnpackIndex replaces the index in basePath with the one in artifact. It
+// must run while no store has the SQLite index open. Call ReconcileIndex
+// afterwards to catch up with the working tree.
+func UnpackIndex(basePath, artifact string) (*IndexManifest, error) {
+	f, err := os.Open(artifact)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an index artifact: %w", artifact, err)
+	}
+	tr := tar.NewReader(gz)
+
+	// Stage next to the live index so the final renames stay on one device
+	staging, err := os.MkdirTemp(basePath, ".unpack-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	var manifest *IndexManifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read artifact: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := filepath.ToSlash(filepath.Clean(hdr.Name))
+		if !indexArtifactEntry(name) {
+			return nil, fmt.Errorf("unexpected entry in artifact: %s", hdr.Name)
+		}
+		if name == manifestName {
+			manifest = &IndexManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("read manifest: %w", err)
+			}
+			if manifest.Version > indexArtifactVersion {
+				return nil, fmt.Errorf("artifact version %d is newer than supported (%d); upgrade teamcontext", manifest.Version, indexArtifactVersion)
+			}
+			if err := manifest.validate(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		dest := filepath.Join(staging, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, err
+		}
+		out, err := os.Create(dest)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s has no %s", artifact, manifestName)
+	}
+	if _, err := os.Stat(filepath.Join(staging, "index")); err != nil {
+		return nil, fmt.Errorf("%s has no index", artifact)
+	}
+
+	if err := installStaged(basePath, staging); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// installStaged moves the unpacked files from staging into basePath. The
+// files they replace are moved aside first, along with stale WAL pages that
+// would be replayed into the new database, and moved back if any rename
+// fails, so a failed unpack leaves the previous index in place.
+func installStaged(basePath, staging string) error {
+	live := func(name string) string { return filepath.Join(basePath, filepath.FromSlash(name)) }
+	backup := filepath.Join(staging, ".previous")
+	for _, dir := range []string{filepath.Join(backup, "cache"), filepath.Join(basePath, "cache")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	var staged []string
+	for _, name := range []string{"index", "tree.yaml", "cache/index.db", "cache/git-analysis.json"} {
+		if _, err := os.Stat(filepath.Join(staging, filepath.FromSlash(name))); err == nil {
+			staged = append(staged, name)
+		}
+	}
+	replaced := append([]string{"cache/index.db-wal", "cache/index.db-shm"}, staged...)
+
+	var movedAside, installed []string
+	restore := func() {
+		for _, name := range installed {
+			os.RemoveAll(live(name))
+		}
+		for _, name := range movedAside {
+			os.Rename(filepath.Join(backup, filepath.FromSlash(name)), live(name))
+		}
+	}
+
+	for _, name := range replaced {
+		if _, err := os.Lstat(live(name)); err != nil {
+			continue
+		}
+		if err := renameFile(live(name), filepath.Join(backup, filepath.FromSlash(name))); err != nil {
+			restore()
+			return fmt.Errorf("move aside %s: %w", name, err)
+		}
+		movedAside = append(movedAside, name)
+	}
+	for _, name := range staged {
+		if err := renameFile(filepath.Join(staging, filepath.FromSlash(name)), live(name)); err != nil {
+			restore()
+			return fmt.Errorf("install %s: %w", name, err)
+		}
+		installed = append(installed, name)
+	}
+	return nil
+}
+
+// ReconcileIndex brings an unpacked index in line with the working tree:
+// files whose content differs from the manifest are reindexed, files gone
+// from disk are tombstoned and source files the artifact didn't know about
+// are indexed. Knowledge in the SQLite index is resynced from local JSON.
+func (m *Manager) ReconcileIndex(manifest *IndexManifest) (*ReconcileReport, error) {
+	if err := manifest.validate(); err != nil {
+		return nil, err
+	}
+	report := &ReconcileReport{ArtifactCommit: manifest.Commit}
+	report.LocalCommit, _ = git.GetHeadHash(m.projectRoot)
+
+	if err := m.sqliteIndex.RebuildFromJSON(m.jsonStore); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for path, hash := range manifest.Files {
+		local, err := hashFile(relpath.ToAbs(m.projectRoot, path))
+		switch {
+		case os.IsNotExist(err):
+			report.Removed++
+			changed = append(changed, path)
+		case err != nil:
+			m.recordError("hash "+path, err)
+		case local != hash:
+			report.Modified++
+			changed = append(changed, path)
+		default:
+			report.Unchanged++
+		}
+	}
+
+	indexed, _ := m.jsonStore.GetFilesIndex()
+	filepath.Walk(m.projectRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != m.projectRoot && skipDiscoverDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !isSourceFile(ext) && !isDocFile(ext) && skeleton.DetectFileLanguage(path) == "" {
+			return nil
+		}
+		rel := m.toRelativePath(path)
+		if _, ok := manifest.Files[rel]; ok {
+			return nil
+		}
+		if existing, ok := indexed[rel]; ok && existing.DeletedAt == nil {
+			return nil
+		}
+		report.Added++
+		changed = append(changed, rel)
+		return nil
+	})
+
+	if len(changed) == 0 {
+		return report, nil
+	}
+	sort.Strings(changed)
+	report.Indexed, _ = m.applyFileChanges(changed)
+	if err := m.GenerateCodeTree(); err != nil {
+		m.recordError("code tree", err)
+	}
+	m.logEvent(fmt.Sprintf("Unpacked index reconciled: %d modified, %d added, %d removed", report.Modified, report.Added, report.Removed), nil)
+	return report, nil
+}
+
+// hashFile returns the hex sha256 of a file's content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+func addTarFile(tw *tar.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -0,0 +1,204 @@
+package worker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/saeedalam/teamcontext/internal/storage"
+)
+
+// packedRepo indexes a small repository and packs it, returning the
+// artifact path and its manifest
+func packedRepo(t *testing.T) (*watchedRepo, string, *IndexManifest) {
+	t.Helper()
+	r := newWatchedRepo(t)
+	r.write("a.go", "package main\n\nfunc A() {}\n")
+	r.write("b.go", "package main\n\nfunc B() {}\n")
+	r.commit("initial")
+	if _, err := r.m.InitProject(); err != nil {
+		t.Fatalf("InitProject: %v", err)
+	}
+	artifact := filepath.Join(t.TempDir(), "index.tar.gz")
+	manifest, err := r.m.PackIndex(artifact)
+	if err != nil {
+		t.Fatalf("PackIndex: %v", err)
+	}
+	return r, artifact, manifest
+}
+
+// clone copies the working tree of r into a fresh directory with an empty
+// .teamcontext, as a teammate's checkout would be
+func clone(t *testing.T, r *watchedRepo) (root, basePath string) {
+	t.Helper()
+	root = t.TempDir()
+	for _, name := range []string{"a.go", "b.go"} {
+		data, err := os.ReadFile(filepath.Join(r.root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	basePath = filepath.Join(root, ".teamcontext")
+	for _, dir := range []string{"knowledge", "index", "features", "cache"} {
+		if err := os.MkdirAll(filepath.Join(basePath, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, basePath
+}
+
+// writeArtifact builds an artifact from raw entries
+func writeArtifact(t *testing.T, entries map[string][]byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "artifact.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{manifestName, "index/files.json", "tree.yaml"} {
+		if data, ok := entries[name]; ok {
+			if err := writeTarEntry(tw, name, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return path
+}
+
+func TestPackUnpackRoundTrip(t *testing.T) {
+	r, artifact, manifest := packedRepo(t)
+	if len(manifest.Files) != 2 || manifest.Commit == "" {
+		t.Fatalf("manifest = %+v, want two files at a commit", manifest)
+	}
+
+	root, basePath := clone(t, r)
+	if err := os.WriteFile(filepath.Join(root, "b.go"), []byte("package main\n\nfunc B2() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "c.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unpacked, err := UnpackIndex(basePath, artifact)
+	if err != nil {
+		t.Fatalf("UnpackIndex: %v", err)
+	}
+	if !reflect.DeepEqual(unpacked.Files, manifest.Files) || unpacked.Commit != manifest.Commit {
+		t.Errorf("unpacked manifest = %+v, want %+v", unpacked, manifest)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(basePath, ".unpack-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("staging left behind: %v", leftovers)
+	}
+
+	jsonStore := storage.NewJSONStore(basePath)
+	sqliteIndex, err := storage.NewSQLiteIndex(basePath)
+	if err != nil {
+		t.Fatalf("NewSQLiteIndex: %v", err)
+	}
+	defer sqliteIndex.Close()
+	m := NewManager(basePath, jsonStore, sqliteIndex)
+	m.projectRoot = root
+
+	report, err := m.ReconcileIndex(unpacked)
+	if err != nil {
+		t.Fatalf("ReconcileIndex: %v", err)
+	}
+	if report.Unchanged != 1 || report.Modified != 1 || report.Added != 1 || report.Removed != 0 {
+		t.Errorf("report = %+v, want a.go unchanged, b.go modified and c.go added", report)
+	}
+	files, err := jsonStore.GetFilesIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if f, ok := files[name]; !ok || f.DeletedAt != nil {
+			t.Errorf("%s is not indexed after reconciling", name)
+		}
+	}
+}
+
+func TestUnpackIndexRestoresOnFailure(t *testing.T) {
+	r, artifact, _ := packedRepo(t)
+	_, basePath := clone(t, r)
+	previous := map[string]string{
+		"index/files.json":   `{"old.go": {}}`,
+		"tree.yaml":          "old tree\n",
+		"cache/index.db":     "old database",
+		"cache/index.db-wal": "old wal",
+	}
+	for name, content := range previous {
+		if err := os.WriteFile(filepath.Join(basePath, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	old := renameFile
+	renameFile = func(from, to string) error {
+		if strings.Contains(from, ".unpack-") && strings.HasSuffix(to, "tree.yaml") {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	t.Cleanup(func() { renameFile = old })
+
+	if _, err := UnpackIndex(basePath, artifact); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("UnpackIndex err = %v, want the rename failure", err)
+	}
+	for name, want := range previous {
+		data, err := os.ReadFile(filepath.Join(basePath, filepath.FromSlash(name)))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q (%v), want the previous %q", name, data, err, want)
+		}
+	}
+	entries, _ := os.ReadDir(filepath.Join(basePath, "index"))
+	if len(entries) != 1 {
+		t.Errorf("index holds %d files, want only the previous files.json", len(entries))
+	}
+}
+
+func TestManifestRejectsPathsOutsideProject(t *testing.T) {
+	for _, path := range []string{"../secret.go", "src/../../secret.go", "/etc/passwd", ""} {
+		t.Run(path, func(t *testing.T) {
+			manifest := &IndexManifest{Version: indexArtifactVersion, Files: map[string]string{"a.go": "x", path: "y"}}
+
+			// Validation runs before the manager touches any store
+			if _, err := (&Manager{}).ReconcileIndex(manifest); err == nil {
+				t.Error("ReconcileIndex accepted the manifest")
+			}
+
+			data, _ := json.Marshal(manifest)
+			artifact := writeArtifact(t, map[string][]byte{
+				manifestName:       data,
+				"index/files.json": []byte("{}"),
+			})
+			basePath := t.TempDir()
+			if _, err := UnpackIndex(basePath, artifact); err == nil {
+				t.Error("UnpackIndex accepted the manifest")
+			}
+			if _, err := os.Stat(filepath.Join(basePath, "index")); !os.IsNotExist(err) {
+				t.Error("a rejected artifact was installed")
+			}
+		})
+	}
+
+	for _, path := range []string{"a.go", "src/pkg/b..c.go", "./src/x.go"} {
+		if !safeRelativePath(path) {
+			t.Errorf("%s rejected", path)
+		}
+	}
+}
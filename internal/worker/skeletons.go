@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"os"
+
+	"github.com/saeedalam/teamcontext/internal/relpath"
+	"github.com/saeedalam/teamcontext/internal/skeleton"
+	"github.com/saeedalam/teamcontext/pkg/types"
+)
+
+// cachedSkeleton is a parsed skeleton with what it was parsed from
+type cachedSkeleton struct {
+	sk     *types.CodeSkeleton
+	hash   string // sha256 of the file content
+	parser string // skeleton.CacheKey at parse time
+}
+
+// skeletonOf returns the skeleton of a file, parsing it only when its
+// content changed since it was last parsed. The memory cache is checked
+// first, then the SQLite cache, which keeps skeletons across restarts.
+// Callers inside a transaction pass it, as the index has one connection.
+func (m *Manager) skeletonOf(tx *sql.Tx, path string) (*types.CodeSkeleton, error) {
+	if !m.config.SkeletonCacheEnable {
+		return skeleton.ParseFile(path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	parser := skeleton.CacheKey()
+
+	m.cacheMu.RLock()
+	cached, ok := m.skeletonCache[path]
+	m.cacheMu.RUnlock()
+	if ok && cached.hash == hash && cached.parser == parser {
+		return cached.sk, nil
+	}
+
+	relPath := m.toRelativePath(path)
+	var sk *types.CodeSkeleton
+	var stored bool
+	if tx != nil {
+		sk, stored = m.sqliteIndex.GetSkeletonTx(tx, relPath, hash, parser)
+	} else {
+		sk, stored = m.sqliteIndex.GetSkeleton(relPath, hash, parser)
+	}
+	if stored {
+		sk.Path = path
+	} else {
+		if sk, err = skeleton.ParseContent(path, content); err != nil {
+			return nil, err
+		}
+		if tx != nil {
+			err = m.sqliteIndex.StoreSkeletonTx(tx, relPath, hash, parser, sk)
+		} else {
+			err = m.sqliteIndex.StoreSkeleton(relPath, hash, parser, sk)
+		}
+		if err != nil {
+			m.recordError("cache skeleton "+relPath, err)
+		}
+	}
+
+	m.cacheMu.Lock()
+	m.skeletonCache[path] = cachedSkeleton{sk: sk, hash: hash, parser: parser}
+	cachedCount := len(m.skeletonCache)
+	m.cacheMu.Unlock()
+	m.mu.Lock()
+	m.stats.SkeletonsCached = cachedCount
+	if stored {
+		m.stats.SkeletonsReused++
+	} else {
+		m.stats.SkeletonsParsed++
+	}
+	m.mu.Unlock()
+	return sk, nil
+}
+
+// Skeleton returns the skeleton of a file by its absolute path, from the
+// caches when the file is unchanged
+func (m *Manager) Skeleton(path string) (*types.CodeSkeleton, error) {
+	return m.skeletonOf(nil, path)
+}
+
+// forgetSkeleton drops a removed file, by its project-relative path, from
+// both skeleton caches
+func (m *Manager) forgetSkeleton(relPath string) {
+	m.cacheMu.Lock()
+	delete(m.skeletonCache, relpath.ToAbs(m.projectRoot, relPath))
+	m.cacheMu.Unlock()
+	m.sqliteIndex.DeleteSkeleton(relPath)
+}
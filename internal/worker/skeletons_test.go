@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSkeletonCacheSurvivesRestart(t *testing.T) {
+	r := newWatchedRepo(t)
+	r.write("billing/invoice.go", "package billing\n\nfunc Charge() error { return nil }\n")
+	path := filepath.Join(r.root, "billing", "invoice.go")
+
+	sk, err := r.m.skeletonOf(nil, path)
+	if err != nil || len(sk.Functions) != 1 {
+		t.Fatalf("skeletonOf = %+v, %v", sk, err)
+	}
+	if again, _ := r.m.skeletonOf(nil, path); again != sk {
+		t.Error("an unchanged file should come from the memory cache")
+	}
+	if stats := r.m.GetStats(); stats.SkeletonsParsed != 1 || stats.SkeletonsReused != 0 {
+		t.Errorf("after first parse: %+v", stats)
+	}
+
+	// A new manager, as after a restart, finds the skeleton in SQLite
+	restarted := NewManager(r.m.basePath, r.m.jsonStore, r.m.sqliteIndex)
+	sk, err = restarted.skeletonOf(nil, path)
+	if err != nil || len(sk.Functions) != 1 || sk.Functions[0].Name != "Charge" || sk.Path != path {
+		t.Fatalf("skeleton after restart = %+v, %v", sk, err)
+	}
+	if stats := restarted.GetStats(); stats.SkeletonsParsed != 0 || stats.SkeletonsReused != 1 {
+		t.Errorf("after restart: %+v", stats)
+	}
+
+	// Changed content is parsed again
+	r.write("billing/invoice.go", "package billing\n\nfunc Charge() error { return nil }\n\nfunc Refund() {}\n")
+	sk, _ = restarted.skeletonOf(nil, path)
+	if len(sk.Functions) != 2 || restarted.GetStats().SkeletonsParsed != 1 {
+		t.Errorf("changed file: %d functions, stats %+v", len(sk.Functions), restarted.GetStats())
+	}
+
+	// A deleted file leaves both caches
+	restarted.handleDeletedFile(path)
+	if _, ok := restarted.GetCachedSkeleton(path); ok {
+		t.Error("deleted file still in the memory cache")
+	}
+	if n, _ := r.m.sqliteIndex.SkeletonCount(); n != 0 {
+		t.Errorf("%d skeletons left in SQLite after delete", n)
+	}
+}
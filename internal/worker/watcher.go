@@ -3,8 +3,10 @@ package worker
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/saeedalam/teamcontext/internal/git"
 	"github.com/saeedalam/teamcontext/internal/imports"
+	"github.com/saeedalam/teamcontext/internal/relpath"
 	"github.com/saeedalam/teamcontext/internal/search"
 	"github.com/saeedalam/teamcontext/internal/skeleton"
 	"github.com/saeedalam/teamcontext/internal/storage"
@@ -27,8 +30,14 @@ type WorkerConfig struct {
 	SkeletonCacheEnable bool          `json:"skeleton_cache_enable"` // Cache skeletons on index
 	AutoDiscoverEnable  bool          `json:"auto_discover_enable"`  // Auto-discover and index new files
 	Enabled             bool          `json:"enabled"`
+	LogMaxBytes         int64         `json:"log_max_bytes"`         // Rotate worker.log past this size
+	LogBackups          int           `json:"log_backups"`           // Rotated logs kept: worker.log.1 to .N
 }
 
+// defaultTombstoneRetentionDays is how long deleted files stay in the index
+// when the project config doesn't say otherwise.
+const defaultTombstoneRetentionDays = 30
+
 // DefaultConfig returns sensible defaults
 func DefaultConfig() WorkerConfig {
 	return WorkerConfig{
@@ -38,6 +47,8 @@ func DefaultConfig() WorkerConfig {
 		SkeletonCacheEnable:  true,
 		AutoDiscoverEnable:   true,
 		Enabled:              true,
+		LogMaxBytes:          defaultLogMaxBytes,
+		LogBackups:           defaultLogBackups,
 	}
 }
 
@@ -54,9 +65,12 @@ type Manager struct {
 	mu          sync.RWMutex
 	running     bool
 	lastGitHash string
+	lastRefs    string // branch tips the feature branch check last saw
+	exclude     []string      // index.exclude patterns
+	reload      chan struct{} // closed when the config changes
 
 	// Cached data
-	skeletonCache map[string]*types.CodeSkeleton
+	skeletonCache map[string]cachedSkeleton
 	cacheMu       sync.RWMutex
 
 	// Statistics
@@ -68,6 +82,8 @@ type WorkerStats struct {
 	GitChecks        int       `json:"git_checks"`
 	FilesReindexed   int       `json:"files_reindexed"`
 	SkeletonsCached  int       `json:"skeletons_cached"`
+	SkeletonsParsed  int       `json:"skeletons_parsed"`
+	SkeletonsReused  int       `json:"skeletons_reused"` // found in the SQLite cache instead of parsed
 	LastGitCheck     time.Time `json:"last_git_check"`
 	LastReindex      time.Time `json:"last_reindex"`
 	ChangesDetected  int       `json:"changes_detected"`
@@ -80,15 +96,20 @@ func NewManager(basePath string, jsonStore *storage.JSONStore, sqliteIndex *stor
 	// Project root is parent of .teamcontext
 	projectRoot := filepath.Dir(basePath)
 
-	return &Manager{
+	m := &Manager{
 		config:        DefaultConfig(),
 		jsonStore:     jsonStore,
 		sqliteIndex:   sqliteIndex,
 		projectRoot:   projectRoot,
 		basePath:      basePath,
 		stopChan:      make(chan struct{}),
-		skeletonCache: make(map[string]*types.CodeSkeleton),
+		reload:        make(chan struct{}),
+		skeletonCache: make(map[string]cachedSkeleton),
+	}
+	if cfg, err := jsonStore.GetConfig(); err == nil {
+		m.ApplyConfig(cfg)
 	}
+	return m
 }
 
 // SetConfig updates the worker configuration
@@ -169,17 +190,14 @@ func (m *Manager) GetStats() WorkerStats {
 func (m *Manager) GetCachedSkeleton(path string) (*types.CodeSkeleton, bool) {
 	m.cacheMu.RLock()
 	defer m.cacheMu.RUnlock()
-	sk, ok := m.skeletonCache[path]
-	return sk, ok
+	cached, ok := m.skeletonCache[path]
+	return cached.sk, ok
 }
 
-// toRelativePath converts an absolute path to a relative path from projectRoot
+// toRelativePath converts an absolute path to the stored form: relative to
+// projectRoot, with forward slashes on every OS
 func (m *Manager) toRelativePath(absPath string) string {
-	rel, err := filepath.Rel(m.projectRoot, absPath)
-	if err != nil {
-		return absPath
-	}
-	return rel
+	return relpath.FromAbs(m.projectRoot, absPath)
 }
 
 
@@ -187,35 +205,13 @@ func (m *Manager) toRelativePath(absPath string) string {
 // gitWatcher monitors git for changes and triggers reindexing
 func (m *Manager) gitWatcher() {
 	defer m.wg.Done()
-
-	ticker := time.NewTicker(m.config.GitWatchInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-m.stopChan:
-			return
-		case <-ticker.C:
-			m.checkGitChanges()
-		}
-	}
+	m.tickEvery(func(c WorkerConfig) time.Duration { return c.GitWatchInterval }, m.checkGitChanges)
 }
 
 // periodicReindexer does periodic full reindex checks
 func (m *Manager) periodicReindexer() {
 	defer m.wg.Done()
-
-	ticker := time.NewTicker(m.config.ReindexInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-m.stopChan:
-			return
-		case <-ticker.C:
-			m.periodicReindex()
-		}
-	}
+	m.tickEvery(func(c WorkerConfig) time.Duration { return c.ReindexInterval }, m.periodicReindex)
 }
 
 // autoDiscoverer scans for new files and auto-indexes them
@@ -225,29 +221,25 @@ func (m *Manager) autoDiscoverer() {
 	// Run immediately on start
 	m.discoverAndIndexFiles()
 
-	ticker := time.NewTicker(m.config.AutoDiscoverInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-m.stopChan:
-			return
-		case <-ticker.C:
-			m.discoverAndIndexFiles()
-		}
-	}
+	m.tickEvery(func(c WorkerConfig) time.Duration { return c.AutoDiscoverInterval }, m.discoverAndIndexFiles)
 }
 
 // discoverAndIndexFiles walks the project and indexes new source files
 func (m *Manager) discoverAndIndexFiles() {
 	// Get already indexed files
 	existingFiles, _ := m.jsonStore.GetFilesIndex()
+	// Tombstoned files that reappear are indexed again
 	existingPaths := make(map[string]bool)
-	for path := range existingFiles {
-		existingPaths[path] = true
+	for path, f := range existingFiles {
+		if f.DeletedAt == nil {
+			existingPaths[path] = true
+		}
 	}
 
 	newFilesIndexed := 0
+	skipped := 0
+	caps := m.indexCaps()
+	indexedCount := len(existingPaths)
 
 	// Walk the project looking for source files
 	err := filepath.Walk(m.projectRoot, func(path string, info os.FileInfo, err error) error {
@@ -257,23 +249,27 @@ func (m *Manager) discoverAndIndexFiles() {
 
 		// Skip directories we don't care about
 		if info.IsDir() {
-			name := info.Name()
-			if name == "node_modules" || name == ".git" || name == "dist" ||
-				name == "vendor" || name == ".teamcontext" || name == "coverage" ||
-				name == "__pycache__" || name == ".next" || name == "build" {
+			if skipDiscoverDir(info.Name()) || isMixDeps(path) || m.excludedPath(path) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Skip if already indexed
-		if existingPaths[path] {
+		// Skip if already indexed; the index is keyed by relative path
+		if existingPaths[m.toRelativePath(path)] || m.excludedPath(path) {
 			return nil
 		}
 
-		// Check if it's a source file we care about
+		// Check if it's a source or documentation file we care about
 		ext := strings.ToLower(filepath.Ext(path))
-		if !isSourceFile(ext) {
+		if !isSourceFile(ext) && !isDocFile(ext) && skeleton.DetectFileLanguage(path) == "" {
+			return nil
+		}
+
+		// Past index.max_files new files wait for the next full index,
+		// which decides by priority which ones make the cut
+		if indexedCount+newFilesIndexed >= caps.maxFiles {
+			skipped++
 			return nil
 		}
 
@@ -289,11 +285,44 @@ func (m *Manager) discoverAndIndexFiles() {
 		m.recordError("auto-discover walk", err)
 	}
 
+	report, _ := m.jsonStore.GetIndexCapReport()
+	if report == nil && skipped > 0 {
+		report = caps.newCapReport()
+	}
+	if report != nil && report.DiscoverSkipped != skipped {
+		report.DiscoverSkipped = skipped
+		if err := m.jsonStore.SaveIndexCapReport(report); err != nil {
+			m.recordError("save index caps report", err)
+		}
+	}
+	if skipped > 0 {
+		m.logWarning(fmt.Sprintf("index.max_files (%d) reached: %d new files not indexed", caps.maxFiles, skipped), nil)
+	}
+
 	if newFilesIndexed > 0 {
 		m.logEvent(fmt.Sprintf("Auto-discovered and indexed %d new files", newFilesIndexed), nil)
 	}
 }
 
+// skipDiscoverDir reports whether a directory holds dependencies, build
+// output or tool state that is never indexed
+func skipDiscoverDir(name string) bool {
+	return name == "node_modules" || name == ".git" || name == "dist" ||
+		name == "vendor" || name == ".teamcontext" || name == "coverage" ||
+		name == "__pycache__" || name == ".next" || name == "build" ||
+		name == "_build"
+}
+
+// isMixDeps reports whether dir is the deps directory of an Elixir
+// project, where mix fetches dependencies; elsewhere deps may be source
+func isMixDeps(dir string) bool {
+	if filepath.Base(dir) != "deps" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(dir), "mix.exs"))
+	return err == nil
+}
+
 // autoIndexFile creates a basic index entry for a file without LLM summary
 func (m *Manager) autoIndexFile(path string) error {
 	info, err := os.Stat(path)
@@ -302,20 +331,36 @@ func (m *Manager) autoIndexFile(path string) error {
 	}
 
 	// Detect language
-	ext := strings.ToLower(filepath.Ext(path))
-	language := extToLanguage(ext)
+	language := fileLanguage(path)
 
 	// Create basic file index without summary (agent can add summary later)
 	fileIndex := &types.FileIndex{
-		Path:        m.toRelativePath(path),
-		Summary:     "[auto-indexed - needs summary]",
-		Language:    language,
-		IndexedAt:   time.Now(),
-		ContentHash: fmt.Sprintf("%d", info.ModTime().UnixNano()),
+		Path:          m.toRelativePath(path),
+		Summary:       types.SummaryPlaceholder,
+		SummarySource: types.SummarySourceAuto,
+		Language:      language,
+		IndexedAt:     time.Now(),
+		ContentHash:   fmt.Sprintf("%d", info.ModTime().UnixNano()),
+	}
+
+	// Documentation summarizes itself; no skeleton or imports to extract
+	if language == "markdown" {
+		if content, err := os.ReadFile(path); err == nil {
+			fileIndex.Summary = buildDocSummary(strings.Split(string(content), "\n"))
+			fileIndex.SummarySource = ""
+		}
+		if err := m.jsonStore.SaveFileIndex(fileIndex); err != nil {
+			return err
+		}
+		m.sqliteIndex.IndexFile(fileIndex)
+		if m.chunkRoom(m.indexCaps()) {
+			m.indexFileContent(nil, path, language)
+		}
+		return nil
 	}
 
 	// Try to extract exports from skeleton
-	if sk, err := skeleton.ParseFile(path); err == nil && sk != nil {
+	if sk, err := m.skeletonOf(nil, path); err == nil && sk != nil {
 		for _, fn := range sk.Functions {
 			fileIndex.Exports = append(fileIndex.Exports, types.Export{
 				Name: fn.Name,
@@ -347,37 +392,51 @@ func (m *Manager) autoIndexFile(path string) error {
 	// Index in SQLite
 	m.sqliteIndex.IndexFile(fileIndex)
 
-	// Index content for search
-	m.indexFileContent(nil, path, language)
-
-	// Cache skeleton if enabled
-	if m.config.SkeletonCacheEnable {
-		if sk, err := skeleton.ParseFile(path); err == nil {
-			m.cacheMu.Lock()
-			m.skeletonCache[path] = sk
-			m.stats.SkeletonsCached = len(m.skeletonCache)
-			m.cacheMu.Unlock()
-		}
+	// Index content for search, unless index.max_chunk_rows is reached
+	if m.chunkRoom(m.indexCaps()) {
+		m.indexFileContent(nil, path, language)
 	}
 
 	return nil
 }
 
-// indexFileContent indexes file content chunks for search
-func (m *Manager) indexFileContent(tx *sql.Tx, path string, language string) {
+// indexFileContent indexes file content chunks for search and returns how
+// many it stored
+func (m *Manager) indexFileContent(tx *sql.Tx, path string, language string) int {
 	content, err := os.ReadFile(path)
 	if err != nil {
-		return
+		return 0
 	}
 
 	relPath := m.toRelativePath(path)
 	lines := strings.Split(string(content), "\n")
 	chunkSize := 50
 
+	// Documentation is chunked by heading instead of by code structure
+	if language == "markdown" {
+		chunks := chunkMarkdown(relPath, lines)
+		if tx != nil {
+			m.sqliteIndex.IndexCodeChunksTx(tx, relPath, chunks)
+		} else {
+			m.sqliteIndex.IndexCodeChunks(relPath, chunks)
+		}
+		return len(chunks)
+	}
+
+	// Notebooks are chunked in their script form, by code cell instead of
+	// by fixed line windows
 	var chunks []storage.CodeChunk
+	if language == "jupyter" {
+		nb, err := skeleton.ParseNotebook(content)
+		if err != nil {
+			return 0
+		}
+		lines = strings.Split(nb.Script(), "\n")
+		chunks = notebookChunks(relPath, nb)
+	}
 
 	// Try semantic chunks first
-	if sk, err := skeleton.ParseFile(path); err == nil && sk != nil {
+	if sk, err := m.skeletonOf(tx, path); err == nil && sk != nil {
 		for _, fn := range sk.Functions {
 			startLine := fn.Line
 			endLine := findBlockEndLines(lines, startLine-1, chunkSize)
@@ -407,7 +466,7 @@ func (m *Manager) indexFileContent(tx *sql.Tx, path string, language string) {
 	}
 
 	// Add line-based chunks for coverage
-	if len(chunks) == 0 || len(lines) > len(chunks)*chunkSize*2 {
+	if language != "jupyter" && (len(chunks) == 0 || len(lines) > len(chunks)*chunkSize*2) {
 		for i := 0; i < len(lines); i += chunkSize {
 			endLine := i + chunkSize
 			if endLine > len(lines) {
@@ -433,6 +492,7 @@ func (m *Manager) indexFileContent(tx *sql.Tx, path string, language string) {
 	} else {
 		m.sqliteIndex.IndexCodeChunks(relPath, chunks)
 	}
+	return len(chunks)
 }
 
 // Helper functions
@@ -442,7 +502,13 @@ func isSourceFile(ext string) bool {
 		".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".mjs": true,
 		".go": true, ".py": true, ".java": true, ".cs": true, ".rs": true,
 		".c": true, ".cpp": true, ".h": true, ".hpp": true,
-		".rb": true, ".php": true, ".swift": true, ".kt": true, ".scala": true,
+		".rb": true, ".php": true, ".swift": true, ".kt": true, ".kts": true, ".scala": true,
+		".dart": true, ".m": true, ".mm": true,
+		".ex": true, ".exs": true, ".erl": true, ".hrl": true,
+		".proto": true, ".tf": true,
+		".sh": true, ".bash": true, ".zsh": true,
+		".ipynb": true,
+		".vue": true, ".svelte": true,
 	}
 	return sourceExts[ext]
 }
@@ -453,11 +519,16 @@ func extToLanguage(ext string) string {
 		".js": "javascript", ".jsx": "javascript", ".mjs": "javascript",
 		".go": "go", ".py": "python", ".java": "java", ".cs": "csharp",
 		".rs": "rust", ".c": "c", ".cpp": "cpp", ".h": "c", ".hpp": "cpp",
-		".rb": "ruby", ".php": "php", ".swift": "swift", ".kt": "kotlin", ".scala": "scala",
+		".rb": "ruby", ".php": "php", ".swift": "swift", ".kt": "kotlin", ".kts": "kotlin", ".scala": "scala",
+		".dart": "dart", ".m": "objc", ".mm": "objcpp",
+		".ex": "elixir", ".exs": "elixir", ".erl": "erlang", ".hrl": "erlang",
+		".proto": "protobuf", ".tf": "terraform",
 		".json": "json", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml",
 		".sql": "sql", ".prisma": "prisma", ".graphql": "graphql", ".gql": "graphql",
-		".md": "markdown", ".mdx": "markdown",
+		".md": "markdown", ".mdx": "markdown", ".markdown": "markdown",
 		".sh": "shell", ".bash": "shell", ".zsh": "shell",
+		".ipynb": "jupyter",
+		".vue": "vue", ".svelte": "svelte",
 		".dockerfile": "dockerfile",
 		".xml": "xml", ".html": "html", ".css": "css", ".scss": "scss", ".less": "less",
 	}
@@ -467,6 +538,18 @@ func extToLanguage(ext string) string {
 	return "unknown"
 }
 
+// fileLanguage returns the language of a file from its extension, else from
+// its name or content: bin/ scripts, Dockerfile.dev, Makefile includes
+func fileLanguage(path string) string {
+	if lang := extToLanguage(strings.ToLower(filepath.Ext(path))); lang != "unknown" {
+		return lang
+	}
+	if lang := skeleton.DetectFileLanguage(path); lang != "" {
+		return lang
+	}
+	return "unknown"
+}
+
 func findBlockEndLines(lines []string, startIdx int, maxLines int) int {
 	if startIdx >= len(lines) {
 		return len(lines)
@@ -506,6 +589,9 @@ func buildFileSummary(language string, sk *types.CodeSkeleton) string {
 	if sk == nil {
 		return fmt.Sprintf("Auto-indexed %s file", language)
 	}
+	if len(sk.Cells) > 0 {
+		return notebookSummary(sk)
+	}
 
 	// Collect all export names by kind
 	var classes, functions, interfaces, typeDefs []string
@@ -522,6 +608,90 @@ func buildFileSummary(language string, sk *types.CodeSkeleton) string {
 		typeDefs = append(typeDefs, t.Name)
 	}
 
+	// Build summary from what we found
+	nameList := func(names []string, max int) string {
+		if len(names) > max {
+			return strings.Join(names[:max], ", ") + fmt.Sprintf(" (+%d more)", len(names)-max)
+		}
+		return strings.Join(names, ", ")
+	}
+
+	// Elixir and Erlang modules: the behaviours they implement say what they are
+	if language == "elixir" || language == "erlang" {
+		if len(sk.Classes) > 0 {
+			mod := sk.Classes[0]
+			summary := strings.ToUpper(language[:1]) + language[1:] + " module " + mod.Name
+			if len(mod.Implements) > 0 {
+				summary += " (" + nameList(mod.Implements, 3) + ")"
+			}
+			var public []string
+			for _, fn := range mod.Methods {
+				if !fn.IsPrivate {
+					public = append(public, fn.Name)
+				}
+			}
+			if len(public) > 0 {
+				summary += fmt.Sprintf(", %d function(s): %s", len(public), nameList(public, 3))
+			}
+			if len(sk.Classes) > 1 {
+				summary += fmt.Sprintf(" (+%d more modules)", len(sk.Classes)-1)
+			}
+			return summary
+		}
+	}
+
+	// Protobuf: the gRPC services a contract defines, else its messages
+	if language == "protobuf" {
+		var services []string
+		rpcs := 0
+		for _, svc := range sk.Classes {
+			services = append(services, svc.Name)
+			rpcs += len(svc.Methods)
+		}
+		if len(services) > 0 {
+			return fmt.Sprintf("gRPC service %s, %d rpc(s), %d message(s)", nameList(services, 3), rpcs, len(typeDefs))
+		}
+		if len(typeDefs) > 0 {
+			return fmt.Sprintf("Protobuf messages: %s", nameList(typeDefs, 5))
+		}
+	}
+
+	// Terraform: the infrastructure a module manages and its interface
+	if language == "terraform" {
+		kinds := make(map[string][]string)
+		for _, t := range sk.Types {
+			kinds[t.Kind] = append(kinds[t.Kind], t.Name)
+		}
+		var parts []string
+		if resources := kinds["resource"]; len(resources) > 0 {
+			parts = append(parts, fmt.Sprintf("%d resource(s): %s", len(resources), nameList(resources, 3)))
+		}
+		if modules := kinds["module"]; len(modules) > 0 {
+			parts = append(parts, fmt.Sprintf("%d module(s): %s", len(modules), nameList(modules, 3)))
+		}
+		if data := kinds["data"]; len(data) > 0 {
+			parts = append(parts, fmt.Sprintf("%d data source(s)", len(data)))
+		}
+		variables, outputs := 0, 0
+		for _, c := range sk.Constants {
+			switch {
+			case strings.HasPrefix(c.Name, "var."):
+				variables++
+			case strings.HasPrefix(c.Name, "output."):
+				outputs++
+			}
+		}
+		if variables > 0 {
+			parts = append(parts, fmt.Sprintf("%d variable(s)", variables))
+		}
+		if outputs > 0 {
+			parts = append(parts, fmt.Sprintf("%d output(s)", outputs))
+		}
+		if len(parts) > 0 {
+			return "Terraform " + strings.Join(parts, ", ")
+		}
+	}
+
 	// Detect NestJS patterns from class names
 	nestPatterns := map[string]string{
 		"Controller":  "NestJS controller",
@@ -536,6 +706,15 @@ func buildFileSummary(language string, sk *types.CodeSkeleton) string {
 		"Filter":      "NestJS exception filter",
 	}
 
+	// A controller's route prefix says what it serves
+	for _, cls := range sk.Classes {
+		if args := cls.DecoratorArgs["@Controller"]; len(args) > 0 {
+			if prefix, ok := skeleton.StringArg(args[0]); ok {
+				return fmt.Sprintf("NestJS controller: %s (/%s)", cls.Name, strings.Trim(prefix, "/"))
+			}
+		}
+	}
+
 	for _, name := range classes {
 		for suffix, label := range nestPatterns {
 			if strings.HasSuffix(name, suffix) {
@@ -544,8 +723,13 @@ func buildFileSummary(language string, sk *types.CodeSkeleton) string {
 		}
 	}
 
-	// Also check function decorators for NestJS patterns
+	// Also check class and method decorators for NestJS patterns
 	for _, cls := range sk.Classes {
+		for _, d := range cls.Decorators {
+			if d == "@Controller" || d == "@Injectable" || d == "@Module" {
+				return fmt.Sprintf("NestJS class: %s", cls.Name)
+			}
+		}
 		for _, dec := range cls.Methods {
 			for _, d := range dec.Decorators {
 				if strings.Contains(d, "Controller") || strings.Contains(d, "Injectable") {
@@ -555,14 +739,29 @@ func buildFileSummary(language string, sk *types.CodeSkeleton) string {
 		}
 	}
 
-	// Build summary from what we found
-	nameList := func(names []string, max int) string {
-		if len(names) > max {
-			return strings.Join(names[:max], ", ") + fmt.Sprintf(" (+%d more)", len(names)-max)
+	var widgets []string
+	for _, c := range sk.Classes {
+		if skeleton.IsDartWidget(c) {
+			widgets = append(widgets, c.Name)
 		}
-		return strings.Join(names, ", ")
 	}
-
+	if len(widgets) > 0 {
+		return fmt.Sprintf("Flutter widget(s): %s", nameList(widgets, 3))
+	}
+	if c := sk.Component; c != nil {
+		summary := strings.ToUpper(c.Framework[:1]) + c.Framework[1:] + " component " + c.Name
+		var props []string
+		for _, p := range c.Props {
+			props = append(props, p.Name)
+		}
+		if len(props) > 0 {
+			summary += ", props: " + nameList(props, 5)
+		}
+		if len(functions) > 0 {
+			summary += fmt.Sprintf("; %d function(s): %s", len(functions), nameList(functions, 3))
+		}
+		return summary
+	}
 	if len(classes) > 0 {
 		return fmt.Sprintf("%d class(es): %s", len(classes), nameList(classes, 3))
 	}
@@ -585,6 +784,11 @@ func buildFileSummary(language string, sk *types.CodeSkeleton) string {
 	return fmt.Sprintf("Auto-indexed %s file", language)
 }
 
+// maxGitChangeFiles bounds how many files one git check reindexes. A rebase
+// onto a busy branch can touch thousands; files past the cap are picked up by
+// the periodic reindexer, which compares modification times.
+var maxGitChangeFiles = 1000
+
 // checkGitChanges looks for git changes and reindexes affected files
 func (m *Manager) checkGitChanges() {
 	m.mu.Lock()
@@ -599,6 +803,9 @@ func (m *Manager) checkGitChanges() {
 		return
 	}
 
+	// Branches are merged and deleted without moving HEAD
+	m.syncFeatureBranches()
+
 	// First run - just record the hash
 	if m.lastGitHash == "" {
 		m.lastGitHash = currentHash
@@ -611,8 +818,12 @@ func (m *Manager) checkGitChanges() {
 	}
 
 	// Get changed files since last check
-	changedFiles, err := m.getChangedFiles(m.lastGitHash, currentHash)
-	if err != nil {
+	changedFiles, rewritten, err := m.getChangedFiles(m.lastGitHash, currentHash)
+	if errors.Is(err, git.ErrUnknownCommit) || errors.Is(err, git.ErrUnrelatedHistory) {
+		// The recorded HEAD is gone or unrelated to the new one, so there
+		// is nothing meaningful to diff against
+		changedFiles = m.filesChangedOnDisk()
+	} else if err != nil {
 		m.recordError("git diff", err)
 		return
 	}
@@ -623,21 +834,51 @@ func (m *Manager) checkGitChanges() {
 		return
 	}
 
+	deferred := 0
+	if len(changedFiles) > maxGitChangeFiles {
+		deferred = len(changedFiles) - maxGitChangeFiles
+		changedFiles = changedFiles[:maxGitChangeFiles]
+	}
+
 	m.mu.Lock()
 	m.stats.ChangesDetected += len(changedFiles)
 	m.mu.Unlock()
 
+	indexed, graphEdgesCreated := m.applyFileChanges(changedFiles)
+
+	message := fmt.Sprintf("Git change: indexed %d files, created %d graph edges", indexed, graphEdgesCreated)
+	if rewritten {
+		message += " (history rewritten since last check)"
+	}
+	if deferred > 0 {
+		message += fmt.Sprintf(", %d files deferred to periodic reindex", deferred)
+	}
+	m.logEvent(message, changedFiles)
+}
+
+// applyFileChanges reindexes changed files given relative to the project
+// root: new files are indexed, existing ones reindexed and missing ones
+// tombstoned. It returns the files indexed and the graph edges created.
+func (m *Manager) applyFileChanges(changedFiles []string) (int, int) {
 	// Process ALL changed files (new or existing)
 	indexed := 0
 	graphEdgesCreated := 0
+	added, deleted := 0, 0
 
 	for _, file := range changedFiles {
-		fullPath := filepath.Join(m.projectRoot, file)
+		fullPath := relpath.ToAbs(m.projectRoot, file)
 
 		// Check if file exists (might be deleted)
 		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 			// File deleted - remove from index and graph
 			m.handleDeletedFile(fullPath)
+			deleted++
+			continue
+		}
+
+		// index.exclude keeps it out, or stops updating it until the next
+		// full index drops it
+		if m.excluded(file) {
 			continue
 		}
 
@@ -645,6 +886,7 @@ func (m *Manager) checkGitChanges() {
 		existing, err := m.jsonStore.GetFileIndex(fullPath)
 		if err != nil {
 			// NEW file - auto-index it!
+			added++
 			if err := m.autoIndexNewFile(fullPath); err != nil {
 				m.recordError("auto-index "+file, err)
 			} else {
@@ -666,11 +908,121 @@ func (m *Manager) checkGitChanges() {
 		}
 	}
 
+	// Build targets own files by glob, so re-ingest on BUILD edits and
+	// whenever files come or go
+	if buildGraphChanged(changedFiles, added, deleted) {
+		if _, err := m.IngestBuildGraph(); err != nil {
+			m.recordError("build graph", err)
+		}
+	}
+
+	// Blueprints cache framework detection until a manifest changes
+	if manifestChanged(changedFiles) {
+		m.refreshFrameworks()
+	}
+
 	m.mu.Lock()
 	m.stats.FilesReindexed += indexed
 	m.mu.Unlock()
 
-	m.logEvent(fmt.Sprintf("Git change: indexed %d files, created %d graph edges", indexed, graphEdgesCreated), changedFiles)
+	return indexed, graphEdgesCreated
+}
+
+// filesChangedOnDisk is the rescan used when git can't say what changed:
+// indexed files modified since they were indexed or gone from disk, then
+// tracked source files not yet indexed, each group in path order so the
+// caller's maxGitChangeFiles cap always keeps the same files. Paths are
+// relative to the project root.
+func (m *Manager) filesChangedOnDisk() []string {
+	files, err := m.jsonStore.GetFilesIndex()
+	if err != nil {
+		m.recordError("get index", err)
+		return nil
+	}
+
+	// Entries lose IndexedAt when saved, so compare against the last write
+	// of the index itself for those
+	var indexWritten time.Time
+	if info, err := os.Stat(filepath.Join(m.basePath, "index", "files.json")); err == nil {
+		indexWritten = info.ModTime()
+	}
+
+	paths := make([]string, 0, len(files))
+	for path, file := range files {
+		if file.DeletedAt == nil {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var changed []string
+	indexed := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		file := files[path]
+		fullPath := path
+		if !filepath.IsAbs(fullPath) {
+			fullPath = relpath.ToAbs(m.projectRoot, path)
+		}
+		rel := m.toRelativePath(fullPath)
+		indexed[rel] = true
+
+		indexedAt := file.IndexedAt
+		if indexedAt.IsZero() {
+			indexedAt = indexWritten
+		}
+		info, err := os.Stat(fullPath)
+		if os.IsNotExist(err) || (err == nil && info.ModTime().After(indexedAt)) {
+			changed = append(changed, rel)
+		}
+	}
+
+	if m.config.AutoDiscoverEnable {
+		changed = append(changed, m.untrackedInIndex(indexed)...)
+	}
+	return changed
+}
+
+// untrackedInIndex returns the files git tracks that are indexable but not
+// in the index, in path order and within index.max_files
+func (m *Manager) untrackedInIndex(indexed map[string]bool) []string {
+	tracked, err := git.GetTrackedFiles(m.projectRoot)
+	if err != nil {
+		m.recordError("git ls-files", err)
+		return nil
+	}
+	sort.Strings(tracked)
+
+	room := m.indexCaps().maxFiles - len(indexed)
+	var added []string
+	for _, file := range tracked {
+		if len(added) >= room {
+			break
+		}
+		if indexed[file] || inSkippedDir(file) || m.excluded(file) {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(file))
+		if !isSourceFile(ext) && !isDocFile(ext) && skeleton.DetectFileLanguage(file) == "" {
+			continue
+		}
+		if _, err := os.Stat(relpath.ToAbs(m.projectRoot, file)); err != nil {
+			continue
+		}
+		added = append(added, file)
+	}
+	return added
+}
+
+// inSkippedDir reports whether a relative path lies under a directory the
+// discoverer never indexes
+func inSkippedDir(file string) bool {
+	dirs := strings.Split(filepath.ToSlash(file), "/")
+	for _, dir := range dirs[:len(dirs)-1] {
+		if skipDiscoverDir(dir) {
+			return true
+		}
+	}
+	return false
 }
 
 // periodicReindex validates index integrity
@@ -689,9 +1041,16 @@ func (m *Manager) periodicReindex() {
 	reindexed := 0
 	for path, file := range files {
 		// Check if file still exists
-		info, err := os.Stat(path)
+		fullPath := path
+		if !filepath.IsAbs(fullPath) {
+			fullPath = relpath.ToAbs(m.projectRoot, path)
+		}
+		info, err := os.Stat(fullPath)
 		if os.IsNotExist(err) {
-			// File deleted - could remove from index
+			m.handleDeletedFile(path)
+			continue
+		}
+		if err != nil {
 			continue
 		}
 
@@ -710,6 +1069,22 @@ func (m *Manager) periodicReindex() {
 		m.stats.FilesReindexed += reindexed
 		m.mu.Unlock()
 		m.logEvent(fmt.Sprintf("Periodic reindex: %d files", reindexed), nil)
+		m.snapshotGraph()
+	}
+	m.purgeTombstones()
+	if cfg, err := m.jsonStore.GetConfig(); err == nil && cfg.Index.ConsistencyCheck {
+		m.repairIndexDrift()
+	}
+}
+
+// snapshotGraph records the graph's edge counts and coupling for
+// get_graph_evolution
+func (m *Manager) snapshotGraph() {
+	commit, _ := m.getCurrentGitHash()
+	if _, added, err := m.jsonStore.SnapshotGraph(commit); err != nil {
+		m.recordError("graph snapshot", err)
+	} else if added {
+		m.logEvent("Recorded graph snapshot", commit)
 	}
 }
 
@@ -726,13 +1101,7 @@ func (m *Manager) reindexFile(path string) error {
 
 	// If skeleton caching is enabled, update skeleton cache
 	if m.config.SkeletonCacheEnable {
-		sk, err := skeleton.ParseFile(path)
-		if err == nil {
-			m.cacheMu.Lock()
-			m.skeletonCache[path] = sk
-			m.stats.SkeletonsCached = len(m.skeletonCache)
-			m.cacheMu.Unlock()
-		}
+		m.skeletonOf(nil, path)
 	}
 
 	// Save back to JSON store
@@ -748,81 +1117,23 @@ func (m *Manager) reindexFile(path string) error {
 
 // getCurrentGitHash returns current HEAD hash
 func (m *Manager) getCurrentGitHash() (string, error) {
-	changes, err := git.GetRecentChanges(m.projectRoot, "", 1)
-	if err != nil {
-		return "", err
-	}
-	if len(changes) == 0 {
-		return "", fmt.Errorf("no git commits found")
-	}
-	return changes[0].Hash, nil
+	return git.GetHeadHash(m.projectRoot)
 }
 
-// getChangedFiles returns files changed between two commits
-func (m *Manager) getChangedFiles(fromHash, toHash string) ([]string, error) {
-	changes, err := git.GetRecentChanges(m.projectRoot, "", 50)
-	if err != nil {
-		return nil, err
-	}
-
-	var files []string
-	collecting := false
-	for _, c := range changes {
-		if c.Hash == toHash {
-			collecting = true
-		}
-		if collecting {
-			files = append(files, c.FilesChanged...)
-		}
-		if c.Hash == fromHash {
-			break
-		}
-	}
-
-	// Deduplicate
-	seen := make(map[string]bool)
-	var unique []string
-	for _, f := range files {
-		if !seen[f] {
-			seen[f] = true
-			unique = append(unique, f)
-		}
-	}
-
-	return unique, nil
+// getChangedFiles returns files changed between two commits, diffing the
+// trees so rebases and force pushes don't lose or replay changes
+func (m *Manager) getChangedFiles(fromHash, toHash string) ([]string, bool, error) {
+	return git.GetChangedFilesBetween(m.projectRoot, fromHash, toHash)
 }
 
 // recordError records an error in stats
 func (m *Manager) recordError(context string, err error) {
+	message := fmt.Sprintf("%s: %v", context, err)
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	m.stats.ErrorCount++
-	m.stats.LastError = fmt.Sprintf("%s: %v", context, err)
-}
-
-// logEvent writes an event to the worker log
-func (m *Manager) logEvent(message string, data interface{}) {
-	logPath := filepath.Join(m.basePath, "cache", "worker.log")
-
-	entry := struct {
-		Time    time.Time   `json:"time"`
-		Message string      `json:"message"`
-		Data    interface{} `json:"data,omitempty"`
-	}{
-		Time:    time.Now(),
-		Message: message,
-		Data:    data,
-	}
-
-	line, _ := json.Marshal(entry)
-
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-
-	f.WriteString(string(line) + "\n")
+	m.stats.LastError = message
+	m.mu.Unlock()
+	m.writeLog(LevelError, message, nil)
 }
 
 // TriggerReindex manually triggers a reindex of specific files
@@ -838,12 +1149,13 @@ func (m *Manager) TriggerReindex(paths []string) (int, error) {
 	return reindexed, nil
 }
 
-// ClearCache clears the skeleton cache
+// ClearCache clears the skeleton cache, in memory and in SQLite
 func (m *Manager) ClearCache() {
 	m.cacheMu.Lock()
 	defer m.cacheMu.Unlock()
-	m.skeletonCache = make(map[string]*types.CodeSkeleton)
+	m.skeletonCache = make(map[string]cachedSkeleton)
 	m.stats.SkeletonsCached = 0
+	m.sqliteIndex.ClearSkeletons()
 }
 
 // =============================================================================
@@ -855,23 +1167,32 @@ func (m *Manager) ClearCache() {
 func (m *Manager) InitProject() (int, error) {
 	m.logEvent("Starting project initialization", nil)
 
-	var err error
 	indexed := 0
 	graphEdges := 0
+	caps := m.indexCaps()
+	report := caps.newCapReport()
+	report.RunAt = time.Now()
 
 	supportedExts := map[string]bool{
 		".ts": true, ".tsx": true, ".js": true, ".jsx": true,
 		".go": true, ".py": true, ".java": true, ".cs": true,
-		".rb": true, ".rs": true, ".kt": true, ".swift": true,
+		".rb": true, ".rs": true, ".kt": true, ".kts": true, ".gradle": true, ".swift": true, ".dart": true,
+		".m": true, ".mm": true, ".h": true,
+		".ex": true, ".exs": true, ".erl": true, ".hrl": true,
+		".proto": true, ".tf": true,
 		".prisma": true, ".sql": true,
 		".json": true, ".yaml": true, ".yml": true, ".toml": true,
+		".md": true, ".mdx": true, ".markdown": true,
+		".sh": true, ".bash": true, ".zsh": true,
+		".ipynb": true,
+		".vue": true, ".svelte": true,
 	}
 
 	skipDirs := map[string]bool{
 		"node_modules": true, ".git": true, "vendor": true,
 		"dist": true, "build": true, "target": true,
 		"__pycache__": true, ".next": true, ".nuxt": true,
-		"coverage": true, ".cache": true,
+		"coverage": true, ".cache": true, "_build": true,
 	}
 
 	allFiles := make(map[string]types.FileIndex)
@@ -887,18 +1208,22 @@ func (m *Manager) InitProject() (int, error) {
 
 		if info.IsDir() {
 			name := info.Name()
-			if skipDirs[name] || strings.HasPrefix(name, ".") {
+			if skipDirs[name] || strings.HasPrefix(name, ".") || isMixDeps(path) || m.excludedPath(path) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
 		ext := strings.ToLower(filepath.Ext(path))
-		if !supportedExts[ext] {
+		if !supportedExts[ext] && skeleton.DetectFileLanguage(path) == "" {
+			return nil
+		}
+		if m.excludedPath(path) {
 			return nil
 		}
 
-		if info.Size() > 1024*1024 {
+		if info.Size() > caps.sizeLimit(path) {
+			report.TooLarge++
 			return nil
 		}
 
@@ -906,6 +1231,16 @@ func (m *Manager) InitProject() (int, error) {
 		return nil
 	})
 
+	// Most valuable first, so the caps cut what matters least
+	report.FilesFound = len(filesToIndex)
+	m.prioritizeFiles(filesToIndex, m.directoryActivity())
+	if len(filesToIndex) > caps.maxFiles {
+		for _, path := range filesToIndex[caps.maxFiles:] {
+			m.recordSkipped(report, path)
+		}
+		filesToIndex = filesToIndex[:caps.maxFiles]
+	}
+
 	fmt.Fprintf(os.Stderr, "  ... found %d files to index\n", len(filesToIndex))
 	fmt.Fprintf(os.Stderr, "  ... preparing database\n")
 
@@ -918,22 +1253,31 @@ func (m *Manager) InitProject() (int, error) {
 		}
 
 		batch := filesToIndex[i:end]
+		var batchFiles []string
+		batchChunks := 0
 		batchErr := m.sqliteIndex.WithTransaction(func(tx *sql.Tx) error {
 			for _, path := range batch {
 				// Prepare file index entry
-				fileIndex, err := m.prepareFileIndex(path)
+				fileIndex, err := m.prepareFileIndex(tx, path)
 				if err != nil {
 					m.recordError("init-index-prep "+path, err)
+					report.FailedFiles++
 					continue
 				}
 				allFiles[fileIndex.Path] = *fileIndex
+				batchFiles = append(batchFiles, fileIndex.Path)
 				indexed++
 
 				// Save to SQLite within transaction
 				m.sqliteIndex.IndexFileTx(tx, fileIndex)
 
-				// Index code chunks for content search in SQLite within transaction
-				m.indexFileContent(tx, path, fileIndex.Language)
+				// Index code chunks for content search in SQLite within
+				// transaction, while index.max_chunk_rows allows
+				if report.ChunkRows+batchChunks < caps.maxChunkRows {
+					batchChunks += m.indexFileContent(tx, path, fileIndex.Language)
+				} else {
+					report.FilesWithoutChunks++
+				}
 
 				// Collect graph edges (imports)
 				importResults, _ := imports.ScanFile(path)
@@ -972,8 +1316,17 @@ func (m *Manager) InitProject() (int, error) {
 		})
 
 		if batchErr != nil {
-			err = batchErr
+			// The batch was rolled back; skip its files rather than fail the
+			// whole init
 			fmt.Fprintf(os.Stderr, "  [ERROR] Batch %d-%d failed: %v\n", i, end, batchErr)
+			m.recordError(fmt.Sprintf("init-index batch %d-%d", i, end), batchErr)
+			for _, rel := range batchFiles {
+				delete(allFiles, rel)
+			}
+			indexed -= len(batchFiles)
+			report.FailedFiles += len(batchFiles)
+		} else {
+			report.ChunkRows += batchChunks
 		}
 
 		if indexed%batchSize == 0 || indexed == len(filesToIndex) {
@@ -982,19 +1335,28 @@ func (m *Manager) InitProject() (int, error) {
 	}
 
 	// Perform bulk saves to JSON store—this is the primary performance win
-	if err == nil {
-		fmt.Fprintf(os.Stderr, "  [DEBUG] Saving bulk index for %d files...\n", indexed)
-		if saveErr := m.jsonStore.SaveFilesIndexBulk(allFiles); saveErr != nil {
-			fmt.Fprintf(os.Stderr, "  [WARNING] Error saving bulk file index: %v\n", saveErr)
-		}
-		fmt.Fprintf(os.Stderr, "  [DEBUG] Saving bulk graph for %d edges...\n", len(allEdges))
-		if saveErr := m.jsonStore.AddEdgesBulk(allEdges); saveErr != nil {
-			fmt.Fprintf(os.Stderr, "  [WARNING] Error saving bulk graph edges: %v\n", saveErr)
-		}
-		graphEdges = len(allEdges)
+	fmt.Fprintf(os.Stderr, "  [DEBUG] Saving bulk index for %d files...\n", indexed)
+	if saveErr := m.jsonStore.SaveFilesIndexBulk(allFiles); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "  [WARNING] Error saving bulk file index: %v\n", saveErr)
 	}
+	fmt.Fprintf(os.Stderr, "  [DEBUG] Saving bulk graph for %d edges...\n", len(allEdges))
+	if saveErr := m.jsonStore.AddEdgesBulk(allEdges); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "  [WARNING] Error saving bulk graph edges: %v\n", saveErr)
+	}
+	graphEdges = len(allEdges)
+
+	// Bazel/Pants declared deps are authoritative over scanned imports
+	if targets, buildErr := m.IngestBuildGraph(); buildErr != nil {
+		fmt.Fprintf(os.Stderr, "  [WARNING] Error ingesting build graph: %v\n", buildErr)
+	} else if targets > 0 {
+		fmt.Fprintf(os.Stderr, "  ✓ Ingested build graph: %d targets\n", targets)
+	}
+	m.snapshotGraph()
+	m.refreshFrameworks()
 
 	m.logEvent(fmt.Sprintf("Project init complete: %d files indexed, %d graph edges", indexed, graphEdges), nil)
+	report.FilesIndexed = indexed
+	m.saveCapReport(report)
 
 	// Build semantic index for TF-IDF search (only if knowledge items exist;
 	// on first init there are typically none yet, so skip to avoid OOM on large codebases)
@@ -1006,7 +1368,7 @@ func (m *Manager) InitProject() (int, error) {
 	if knowledgeCount > 0 {
 		semanticCount, semanticErr := m.BuildSemanticIndex()
 		if semanticErr != nil {
-			m.logEvent("Semantic index build failed: "+semanticErr.Error(), nil)
+			m.writeLog(LevelError, "Semantic index build failed: "+semanticErr.Error(), nil)
 		} else {
 			m.logEvent(fmt.Sprintf("Semantic index built: %d documents", semanticCount), nil)
 		}
@@ -1021,7 +1383,7 @@ func (m *Manager) InitProject() (int, error) {
 		fmt.Fprintf(os.Stderr, "  Generated tree.yaml\n")
 	}
 
-	return indexed, err
+	return indexed, nil
 }
 
 
@@ -1029,13 +1391,66 @@ func (m *Manager) InitProject() (int, error) {
 
 // handleDeletedFile removes a file from the index and graph
 func (m *Manager) handleDeletedFile(path string) {
-	// Remove from JSON store (if exists)
-	// Note: We don't have a delete method, but could add one
-	// For now, just log
+	// Index entries are keyed by project-relative path
+	if filepath.IsAbs(path) {
+		path = m.toRelativePath(path)
+	}
+
+	// Tombstone in the JSON store so history and correlations still resolve,
+	// but drop it from search.
+	if err := m.jsonStore.MarkFileDeleted(path); err != nil {
+		m.recordError("tombstone "+path, err)
+		return
+	}
+	m.sqliteIndex.DeleteFile(path)
+	m.sqliteIndex.DeleteCodeChunksForFile(path)
+
+	m.forgetSkeleton(path)
+
 	m.logEvent("File deleted", path)
 
-	// Note: Edge cleanup would require a DeleteEdge method
-	// For now, edges will become stale but won't cause issues
+	// Note: graph edges are kept on purpose; they point at the tombstone
+}
+
+// purgeTombstones removes deleted-file tombstones past the retention window
+func (m *Manager) purgeTombstones() {
+	retentionDays := defaultTombstoneRetentionDays
+	if cfg, err := m.jsonStore.GetConfig(); err == nil && cfg.Index.TombstoneRetentionDays > 0 {
+		retentionDays = cfg.Index.TombstoneRetentionDays
+	}
+
+	purged, err := m.jsonStore.PurgeDeletedFiles(time.Duration(retentionDays) * 24 * time.Hour)
+	if err != nil {
+		m.recordError("purge tombstones", err)
+		return
+	}
+	if len(purged) > 0 {
+		m.logEvent(fmt.Sprintf("Purged %d deleted-file tombstones older than %d days", len(purged), retentionDays), purged)
+	}
+}
+
+// CheckIndexConsistency compares the SQLite index with the JSON store and,
+// with repair, fixes what differs
+func (m *Manager) CheckIndexConsistency(repair bool) (*storage.IndexDrift, int, error) {
+	drift, err := m.sqliteIndex.CheckConsistency(m.jsonStore)
+	if err != nil || !repair {
+		return drift, 0, err
+	}
+	repaired, err := m.sqliteIndex.RepairDrift(m.jsonStore, drift)
+	return drift, repaired, err
+}
+
+// repairIndexDrift is the periodic consistency check, on when the config
+// sets index.consistency_check
+func (m *Manager) repairIndexDrift() {
+	drift, repaired, err := m.CheckIndexConsistency(true)
+	if err != nil {
+		m.recordError("index consistency", err)
+		return
+	}
+	if repaired > 0 {
+		m.logEvent(fmt.Sprintf("Repaired %d index mismatches", repaired), drift)
+	}
 }
 
 // treeNode represents a node in the code tree for ultra-compact YAML generation
@@ -1063,8 +1478,8 @@ func (m *Manager) GenerateCodeTree() error {
 	// Build nested structure
 	root := &treeNode{Dirs: make(map[string]*treeNode)}
 
-	for path := range files {
-		parts := strings.Split(filepath.Dir(path), string(filepath.Separator))
+	for p := range files {
+		parts := strings.Split(path.Dir(p), "/")
 		if len(parts) > 0 && parts[0] == "." {
 			parts = parts[1:]
 		}
@@ -1079,7 +1494,7 @@ func (m *Manager) GenerateCodeTree() error {
 			}
 			curr = curr.Dirs[part]
 		}
-		curr.Files = append(curr.Files, filepath.Base(path))
+		curr.Files = append(curr.Files, path.Base(p))
 	}
 
 	// Generate YAML string recursively with heuristics
@@ -1178,7 +1593,7 @@ func (m *Manager) GenerateCodeTree() error {
 
 // autoIndexNewFile creates a full index entry for a new file and saves it
 func (m *Manager) autoIndexNewFile(path string) error {
-	fileIndex, err := m.prepareFileIndex(path)
+	fileIndex, err := m.prepareFileIndex(nil, path)
 	if err != nil {
 		return err
 	}
@@ -1197,8 +1612,9 @@ func (m *Manager) autoIndexNewFile(path string) error {
 	return nil
 }
 
-// prepareFileIndex builds a FileIndex struct for a file without saving it
-func (m *Manager) prepareFileIndex(path string) (*types.FileIndex, error) {
+// prepareFileIndex builds a FileIndex struct for a file without saving it;
+// tx is the init batch it is part of, if any
+func (m *Manager) prepareFileIndex(tx *sql.Tx, path string) (*types.FileIndex, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -1209,12 +1625,22 @@ func (m *Manager) prepareFileIndex(path string) (*types.FileIndex, error) {
 		return nil, err
 	}
 
-	ext := strings.ToLower(filepath.Ext(path))
-	language := extToLanguage(ext)
+	language := fileLanguage(path)
+
+	if language == "markdown" {
+		return &types.FileIndex{
+			Path:      m.toRelativePath(path),
+			Summary:   buildDocSummary(strings.Split(string(content), "\n")),
+			Language:  language,
+			SizeBytes: info.Size(),
+			LineCount: strings.Count(string(content), "\n") + 1,
+			IndexedAt: time.Now(),
+		}, nil
+	}
 
 	var sk *types.CodeSkeleton
 	if m.config.SkeletonCacheEnable {
-		sk, _ = skeleton.ParseFile(path)
+		sk, _ = m.skeletonOf(tx, path)
 	}
 
 	importResults, _ := imports.ScanFile(path)
@@ -1235,22 +1661,51 @@ func (m *Manager) prepareFileIndex(path string) (*types.FileIndex, error) {
 			exports = append(exports, types.Export{Name: iface.Name, Kind: "interface", Line: iface.Line})
 		}
 		for _, t := range sk.Types {
-			exports = append(exports, types.Export{Name: t.Name, Kind: "type", Line: t.Line})
+			exports = append(exports, types.Export{Name: t.Name, Kind: typeExportKind(sk, t), Line: t.Line})
 		}
+		exports = append(exports, terraformInterface(sk)...)
 	}
 
 	return &types.FileIndex{
-		Path:      m.toRelativePath(path),
-		Summary:   buildFileSummary(language, sk),
-		Exports:   exports,
-		Imports:   relImportPaths,
-		Language:  language,
-		SizeBytes: info.Size(),
-		LineCount: strings.Count(string(content), "\n") + 1,
-		IndexedAt: time.Now(),
+		Path:          m.toRelativePath(path),
+		Summary:       buildFileSummary(language, sk),
+		SummarySource: types.SummarySourceAuto,
+		Exports:       exports,
+		Imports:       relImportPaths,
+		Language:      language,
+		SizeBytes:     info.Size(),
+		LineCount:     strings.Count(string(content), "\n") + 1,
+		IndexedAt:     time.Now(),
 	}, nil
 }
 
+// typeExportKind is the export kind of a type: its block kind for
+// Terraform (resource, data, module, provider), else type
+func typeExportKind(sk *types.CodeSkeleton, t types.TypeDef) string {
+	if sk.Language == "terraform" {
+		return t.Kind
+	}
+	return "type"
+}
+
+// terraformInterface exports a Terraform module's variables and outputs
+func terraformInterface(sk *types.CodeSkeleton) []types.Export {
+	if sk.Language != "terraform" {
+		return nil
+	}
+	var exports []types.Export
+	for _, c := range sk.Constants {
+		switch {
+		case !c.IsExported:
+		case strings.HasPrefix(c.Name, "var."):
+			exports = append(exports, types.Export{Name: c.Name, Kind: "variable", Line: c.Line})
+		case strings.HasPrefix(c.Name, "output."):
+			exports = append(exports, types.Export{Name: c.Name, Kind: "output", Line: c.Line})
+		}
+	}
+	return exports
+}
+
 // fullReindexFile does a complete reindex of an existing file
 func (m *Manager) fullReindexFile(path string, existing *types.FileIndex) error {
 	// Read current content
@@ -1267,7 +1722,7 @@ func (m *Manager) fullReindexFile(path string, existing *types.FileIndex) error
 	// Parse skeleton
 	var sk *types.CodeSkeleton
 	if m.config.SkeletonCacheEnable {
-		sk, _ = skeleton.ParseFile(path)
+		sk, _ = m.skeletonOf(nil, path)
 	}
 
 	// Extract imports
@@ -1290,11 +1745,15 @@ func (m *Manager) fullReindexFile(path string, existing *types.FileIndex) error
 			exports = append(exports, types.Export{Name: iface.Name, Kind: "interface", Line: iface.Line})
 		}
 		for _, t := range sk.Types {
-			exports = append(exports, types.Export{Name: t.Name, Kind: "type", Line: t.Line})
+			exports = append(exports, types.Export{Name: t.Name, Kind: typeExportKind(sk, t), Line: t.Line})
 		}
+		exports = append(exports, terraformInterface(sk)...)
 	}
 
 	// Update existing entry
+	if existing.Language == "markdown" {
+		existing.Summary = buildDocSummary(strings.Split(string(content), "\n"))
+	}
 	existing.Exports = exports
 	existing.Imports = importPaths
 	existing.SizeBytes = info.Size()
@@ -1312,14 +1771,6 @@ func (m *Manager) fullReindexFile(path string, existing *types.FileIndex) error
 	// Re-index code chunks
 	m.indexFileContent(nil, path, existing.Language)
 
-	// Update skeleton cache
-	if sk != nil {
-		m.cacheMu.Lock()
-		m.skeletonCache[path] = sk
-		m.stats.SkeletonsCached = len(m.skeletonCache)
-		m.cacheMu.Unlock()
-	}
-
 	return nil
 }
 
@@ -1407,7 +1858,7 @@ func (m *Manager) BuildSemanticIndex() (int, error) {
 		docs = append(docs, docEntry{
 			id:      d.ID,
 			docType: "decision",
-			text:    d.Content + " " + d.Reason + " " + d.Context,
+			text:    d.Content + " " + d.Reason + " " + d.Context + " " + storage.TranslationText(d.Translations),
 		})
 	}
 
@@ -1417,7 +1868,7 @@ func (m *Manager) BuildSemanticIndex() (int, error) {
 		docs = append(docs, docEntry{
 			id:      w.ID,
 			docType: "warning",
-			text:    w.Content + " " + w.Reason + " " + w.Evidence,
+			text:    w.Content + " " + w.Reason + " " + w.Evidence + " " + storage.TranslationText(w.Translations),
 		})
 	}
 
@@ -1434,10 +1885,13 @@ func (m *Manager) BuildSemanticIndex() (int, error) {
 	// Collect insights
 	insights, _ := m.jsonStore.GetInsights()
 	for _, i := range insights {
+		if i.PromotedTo != "" {
+			continue // indexed as the decision or warning it became
+		}
 		docs = append(docs, docEntry{
 			id:      i.ID,
 			docType: "insight",
-			text:    i.Content + " " + i.Context,
+			text:    i.Content + " " + i.Context + " " + storage.TranslationText(i.Translations),
 		})
 	}
 
@@ -1457,7 +1911,7 @@ func (m *Manager) BuildSemanticIndex() (int, error) {
 	for path, f := range files {
 		score := len(f.Exports) // more exports = more important
 		// Boost files in key directories
-		parts := strings.Split(filepath.ToSlash(path), "/")
+		parts := strings.Split(path, "/")
 		for _, p := range parts {
 			if keyDirs[p] {
 				score += 5
@@ -1541,6 +1995,15 @@ func (m *Manager) BuildSemanticIndex() (int, error) {
 		return 0, nil
 	}
 
+	// Knowledge comes first in docs, so index.max_vector_rows drops file
+	// summaries and git and conversation documents before decisions
+	caps := m.indexCaps()
+	vectorsSkipped := 0
+	if len(docs) > caps.maxVectorRows {
+		vectorsSkipped = len(docs) - caps.maxVectorRows
+		docs = docs[:caps.maxVectorRows]
+	}
+
 	// Build corpus text for vocabulary
 	corpus := make([]string, len(docs))
 	for i, d := range docs {
@@ -1570,6 +2033,16 @@ func (m *Manager) BuildSemanticIndex() (int, error) {
 		stored++
 	}
 
+	report, _ := m.jsonStore.GetIndexCapReport()
+	if report == nil {
+		report = caps.newCapReport()
+	}
+	report.VectorRows = stored
+	report.VectorsSkipped = vectorsSkipped
+	if err := m.jsonStore.SaveIndexCapReport(report); err != nil {
+		m.recordError("save index caps report", err)
+	}
+
 	return stored, nil
 }
 
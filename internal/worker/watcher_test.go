@@ -0,0 +1,227 @@
+package worker
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saeedalam/teamcontext/internal/git"
+	"github.com/saeedalam/teamcontext/internal/storage"
+)
+
+// watchedRepo is a git repository with an indexed .teamcontext
+type watchedRepo struct {
+	t    *testing.T
+	root string
+	m    *Manager
+}
+
+func newWatchedRepo(t *testing.T) *watchedRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	basePath := filepath.Join(root, ".teamcontext")
+	for _, dir := range []string{"knowledge", "index", "features", "cache"} {
+		if err := os.MkdirAll(filepath.Join(basePath, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jsonStore := storage.NewJSONStore(basePath)
+	sqliteIndex, err := storage.NewSQLiteIndex(basePath)
+	if err != nil {
+		t.Fatalf("NewSQLiteIndex: %v", err)
+	}
+	t.Cleanup(func() { sqliteIndex.Close() })
+
+	r := &watchedRepo{t: t, root: root, m: NewManager(basePath, jsonStore, sqliteIndex)}
+	r.git("init", "-q", "-b", "main")
+	r.write(".gitignore", ".teamcontext/\n")
+	return r
+}
+
+func (r *watchedRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.root
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_AUTHOR_NAME=dev", "GIT_AUTHOR_EMAIL=dev@example.com",
+		"GIT_COMMITTER_NAME=dev", "GIT_COMMITTER_EMAIL=dev@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *watchedRepo) write(rel, content string) {
+	r.t.Helper()
+	path := filepath.Join(r.root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *watchedRepo) commit(msg string) string {
+	r.t.Helper()
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", msg)
+	return r.git("rev-parse", "HEAD")
+}
+
+// touch moves a file's modification time past the index's last write
+func (r *watchedRepo) touch(rel string) {
+	r.t.Helper()
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(r.root, rel), future, future); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *watchedRepo) indexed() []string {
+	r.t.Helper()
+	files, err := r.m.jsonStore.GetFilesIndex()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	var paths []string
+	for path, f := range files {
+		if f.DeletedAt == nil {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestFilesChangedOnDiskIsSortedAndBounded(t *testing.T) {
+	r := newWatchedRepo(t)
+	for _, name := range []string{"e.go", "c.go", "a.go", "d.go", "b.go"} {
+		r.write(name, "package main\n")
+	}
+	r.commit("initial")
+	if _, err := r.m.InitProject(); err != nil {
+		t.Fatalf("InitProject: %v", err)
+	}
+
+	for _, name := range []string{"e.go", "b.go", "d.go"} {
+		r.touch(name)
+	}
+	os.Remove(filepath.Join(r.root, "c.go"))
+	r.write("new/z.go", "package z\n")
+	r.write("new/y.go", "package y\n")
+	r.write("node_modules/dep/index.js", "module.exports = 1\n")
+	r.write("notes.bin", "\x00\x01")
+	r.git("add", "-A", "-f")
+
+	want := []string{"b.go", "c.go", "d.go", "e.go", "new/y.go", "new/z.go"}
+	for i := 0; i < 5; i++ {
+		if got := r.m.filesChangedOnDisk(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: filesChangedOnDisk = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestCheckGitChangesRescansWhenHistoryIsGone(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace func(r *watchedRepo)
+	}{
+		{
+			name: "unknown commit",
+			replace: func(r *watchedRepo) {
+				r.m.lastGitHash = strings.Repeat("0", 40)
+				r.write("b.go", "package main\n\nfunc B() {}\n")
+				r.write("c.go", "package main\n")
+				r.commit("more")
+			},
+		},
+		{
+			name: "unrelated history",
+			replace: func(r *watchedRepo) {
+				r.git("checkout", "-q", "--orphan", "rewrite")
+				r.write("b.go", "package main\n\nfunc B() {}\n")
+				r.write("c.go", "package main\n")
+				r.commit("replaced")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newWatchedRepo(t)
+			r.write("a.go", "package main\n")
+			r.write("b.go", "package main\n")
+			head := r.commit("initial")
+			if _, err := r.m.InitProject(); err != nil {
+				t.Fatalf("InitProject: %v", err)
+			}
+			r.m.lastGitHash = head
+
+			tt.replace(r)
+			r.touch("b.go")
+
+			before := r.m.stats.ChangesDetected
+			r.m.checkGitChanges()
+			if r.m.stats.LastError != "" {
+				t.Fatalf("checkGitChanges error: %s", r.m.stats.LastError)
+			}
+			if got := r.m.stats.ChangesDetected - before; got != 2 {
+				t.Errorf("changes detected = %d, want 2 (b.go modified, c.go new)", got)
+			}
+			if got, want := r.indexed(), []string{"a.go", "b.go", "c.go"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("indexed = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckGitChangesCapsDeterministically(t *testing.T) {
+	old := maxGitChangeFiles
+	maxGitChangeFiles = 2
+	t.Cleanup(func() { maxGitChangeFiles = old })
+
+	r := newWatchedRepo(t)
+	r.write("keep.go", "package main\n")
+	r.commit("initial")
+	if _, err := r.m.InitProject(); err != nil {
+		t.Fatalf("InitProject: %v", err)
+	}
+	r.m.lastGitHash = strings.Repeat("0", 40)
+
+	for _, name := range []string{"d.go", "a.go", "c.go", "b.go"} {
+		r.write(name, "package main\n")
+	}
+	r.commit("four new files")
+	r.m.checkGitChanges()
+
+	if got, want := r.indexed(), []string{"a.go", "b.go", "keep.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("indexed = %v, want %v (the first files in path order)", got, want)
+	}
+}
+
+func TestGetChangedFilesBetweenUnrelatedHistory(t *testing.T) {
+	r := newWatchedRepo(t)
+	r.write("a.go", "package main\n")
+	first := r.commit("initial")
+	r.git("checkout", "-q", "--orphan", "other")
+	r.write("b.go", "package main\n")
+	second := r.commit("unrelated")
+
+	if _, _, err := git.GetChangedFilesBetween(r.root, first, second); !errors.Is(err, git.ErrUnrelatedHistory) {
+		t.Errorf("err = %v, want ErrUnrelatedHistory", err)
+	}
+}
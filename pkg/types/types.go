@@ -10,6 +10,9 @@ import "time"
 type FileIndex struct {
 	Path           string    `json:"path"`
 	Summary        string    `json:"summary,omitempty"`
+	SummarySource  string    `json:"summary_source,omitempty"` // "auto" (generated by the indexer) or "agent" (written with index_file)
+	SummaryAuthor  string    `json:"summary_author,omitempty"` // Who index_file wrote the summary for
+	SummarizedAt   *time.Time `json:"summarized_at,omitempty"`  // When index_file wrote the summary
 	Exports        []Export  `json:"exports,omitempty"`
 	Imports        []string  `json:"imports,omitempty"`
 	Dependencies   []string  `json:"dependencies,omitempty"` // Internal dependencies
@@ -20,8 +23,19 @@ type FileIndex struct {
 	SizeBytes      int64     `json:"size_bytes,omitempty"`
 	LineCount      int       `json:"line_count,omitempty"`
 	IndexedAt      time.Time `json:"indexed_at,omitempty"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"` // Tombstone: file removed from disk, kept so history resolves
 }
 
+// Where a file summary came from
+const (
+	SummarySourceAuto  = "auto"  // placeholder or heuristic summary from the indexer
+	SummarySourceAgent = "agent" // written by an agent with index_file
+)
+
+// SummaryPlaceholder is the summary of a code file the indexer could not
+// describe
+const SummaryPlaceholder = "[auto-indexed - needs summary]"
+
 // Export represents an exported symbol from a file
 type Export struct {
 	Name string `json:"name"`
@@ -31,46 +45,85 @@ type Export struct {
 
 // Decision represents a recorded decision with full reasoning
 type Decision struct {
-	ID               string    `json:"id"`
-	Content          string    `json:"content"`
-	Reason           string    `json:"reason"`
-	Context          string    `json:"context,omitempty"`
-	Alternatives     []string  `json:"alternatives,omitempty"` // What else was considered
-	Feature          string    `json:"feature,omitempty"`
-	Author           string    `json:"author,omitempty"`
-	Status           string    `json:"status"` // active, superseded, archived
-	Supersedes       string    `json:"supersedes,omitempty"`
-	RelatedFiles     []string  `json:"related_files,omitempty"`
-	RelatedDecisions []string  `json:"related_decisions,omitempty"`
-	Tags             []string  `json:"tags,omitempty"`
-	CreatedAt        time.Time `json:"created_at"`
+	ID               string                 `json:"id"`
+	Content          string                 `json:"content"`
+	Reason           string                 `json:"reason"`
+	Context          string                 `json:"context,omitempty"`
+	Alternatives     []string               `json:"alternatives,omitempty"` // What else was considered
+	Feature          string                 `json:"feature,omitempty"`
+	Author           string                 `json:"author,omitempty"`
+	Status           string                 `json:"status"` // active, superseded, archived
+	Supersedes       string                 `json:"supersedes,omitempty"`
+	RelatedFiles     []string               `json:"related_files,omitempty"`
+	RelatedDecisions []string               `json:"related_decisions,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+	Issues           []string               `json:"issues,omitempty"`          // issue tracker keys, e.g. "PAY-142", "acme/api#87"
+	Language         string                 `json:"language,omitempty"`        // language the item was written in, e.g. "en", "de"
+	Translations     map[string]Translation `json:"translations,omitempty"`    // by language
+	TranslatedFrom   string                 `json:"translated_from,omitempty"` // set on responses served in another language
+	IdempotencyKey   string                 `json:"idempotency_key,omitempty"` // client key the ID is derived from instead of the content
+	CreatedAt        time.Time              `json:"created_at"`
 }
 
 // Warning represents a documented pitfall to avoid
 type Warning struct {
-	ID               string    `json:"id"`
-	Content          string    `json:"content"`
-	Reason           string    `json:"reason"`
-	Evidence         string    `json:"evidence,omitempty"`
-	Severity         string    `json:"severity"` // info, warning, critical
-	Feature          string    `json:"feature,omitempty"`
-	Author           string    `json:"author,omitempty"`
-	RelatedFiles     []string  `json:"related_files,omitempty"`
-	RelatedDecisions []string  `json:"related_decisions,omitempty"`
-	Tags             []string  `json:"tags,omitempty"`
-	CreatedAt        time.Time `json:"created_at"`
+	ID               string                 `json:"id"`
+	Content          string                 `json:"content"`
+	Reason           string                 `json:"reason"`
+	Evidence         string                 `json:"evidence,omitempty"`
+	Severity         string                 `json:"severity"` // info, warning, critical
+	Feature          string                 `json:"feature,omitempty"`
+	Author           string                 `json:"author,omitempty"`
+	RelatedFiles     []string               `json:"related_files,omitempty"`
+	Anchors          []CodeAnchor           `json:"anchors,omitempty"` // regions of related files the warning is about
+	RelatedDecisions []string               `json:"related_decisions,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+	Issues           []string               `json:"issues,omitempty"`
+	Language         string                 `json:"language,omitempty"`
+	Translations     map[string]Translation `json:"translations,omitempty"`
+	TranslatedFrom   string                 `json:"translated_from,omitempty"`
+	IdempotencyKey   string                 `json:"idempotency_key,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+// CodeAnchor pins a warning to a region of a file instead of the whole
+// file: a line range, a symbol, or both. Context keeps the anchored lines
+// as they were written, so the region is found again after edits move it.
+type CodeAnchor struct {
+	File      string   `json:"file"`
+	StartLine int      `json:"start_line,omitempty"`
+	EndLine   int      `json:"end_line,omitempty"`
+	Symbol    string   `json:"symbol,omitempty"` // function, class or Class.method
+	Context   []string `json:"context,omitempty"`
+	Moved     bool     `json:"moved,omitempty"` // set on responses when the region is no longer where it was anchored
+	Lost      bool     `json:"lost,omitempty"`  // set on responses when the region can't be found; the warning then applies to the whole file
 }
 
 // Insight represents a captured insight
 type Insight struct {
-	ID           string    `json:"id"`
-	Content      string    `json:"content"`
-	Context      string    `json:"context,omitempty"`
-	Feature      string    `json:"feature,omitempty"`
-	Author       string    `json:"author,omitempty"`
-	RelatedFiles []string  `json:"related_files,omitempty"`
-	Tags         []string  `json:"tags,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID             string                 `json:"id"`
+	Content        string                 `json:"content"`
+	Context        string                 `json:"context,omitempty"`
+	Feature        string                 `json:"feature,omitempty"`
+	Author         string                 `json:"author,omitempty"`
+	RelatedFiles   []string               `json:"related_files,omitempty"`
+	Tags           []string               `json:"tags,omitempty"`
+	Language       string                 `json:"language,omitempty"`
+	Translations   map[string]Translation `json:"translations,omitempty"`
+	TranslatedFrom string                 `json:"translated_from,omitempty"`
+	PromotedTo     string                 `json:"promoted_to,omitempty"` // decision or warning ID once reviewed
+	ReviewedAt     *time.Time             `json:"reviewed_at,omitempty"`
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+// Translation is a knowledge item's text in another language. Empty fields
+// fall back to the original.
+type Translation struct {
+	Content  string `json:"content,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Context  string `json:"context,omitempty"`
+	Evidence string `json:"evidence,omitempty"`
 }
 
 // Pattern represents a recognized way of doing things
@@ -83,6 +136,7 @@ type Pattern struct {
 	AntiPatterns []string `json:"anti_patterns,omitempty"` // What violates it
 	Confidence   float64  `json:"confidence,omitempty"`    // For auto-detected patterns
 	Source       string   `json:"source"`                  // manual, detected
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
@@ -100,6 +154,8 @@ type Feature struct {
 	Warnings       []string  `json:"warnings,omitempty"`       // Warning IDs
 	Contributors   []string  `json:"contributors,omitempty"`
 	ArchiveSummary string    `json:"archive_summary,omitempty"`
+	TokenBudget    int       `json:"token_budget,omitempty"` // Estimated agent tokens the feature may consume
+	Issues         []string  `json:"issues,omitempty"`       // Issue tracker keys
 	CreatedAt      time.Time `json:"created_at"`
 	LastAccessed   time.Time `json:"last_accessed"`
 	ArchivedAt     time.Time `json:"archived_at,omitempty"`
@@ -107,17 +163,43 @@ type Feature struct {
 
 // Conversation represents a compressed conversation
 type Conversation struct {
-	ID              string    `json:"id"`
-	Feature         string    `json:"feature"`
-	Summary         string    `json:"summary"`
-	KeyPoints       []string  `json:"key_points,omitempty"`
-	FilesDiscussed  []string  `json:"files_discussed,omitempty"`
-	DecisionsMade   []string  `json:"decisions_made,omitempty"`
-	OriginalTokens  int       `json:"original_tokens,omitempty"`
-	CompressedTokens int      `json:"compressed_tokens,omitempty"`
-	StartTime       time.Time `json:"start_time,omitempty"`
-	EndTime         time.Time `json:"end_time,omitempty"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID               string    `json:"id"`
+	Feature          string    `json:"feature"`
+	Topic            string    `json:"topic,omitempty"` // set on conversations split by topic
+	Summary          string    `json:"summary"`
+	Author           string    `json:"author,omitempty"`
+	KeyPoints        []string  `json:"key_points,omitempty"`
+	FilesDiscussed   []string  `json:"files_discussed,omitempty"`
+	DecisionsMade    []string  `json:"decisions_made,omitempty"`
+	OriginalTokens   int       `json:"original_tokens,omitempty"`
+	CompressedTokens int       `json:"compressed_tokens,omitempty"`
+	MergedFrom       []string  `json:"merged_from,omitempty"` // IDs of conversations consolidated into this one
+	SplitFrom        string    `json:"split_from,omitempty"`  // ID of the conversation this part was split from
+	StartTime        time.Time `json:"start_time,omitempty"`
+	EndTime          time.Time `json:"end_time,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Plan is an agent's execution plan for a feature: ordered steps with the
+// files each touches and how far it got, so a later session can resume it
+type Plan struct {
+	ID        string     `json:"id"`
+	Feature   string     `json:"feature"`
+	Goal      string     `json:"goal"`
+	Status    string     `json:"status"` // active, completed, abandoned
+	Steps     []PlanStep `json:"steps"`
+	Author    string     `json:"author,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// PlanStep is one step of a plan
+type PlanStep struct {
+	Description string     `json:"description"`
+	Files       []string   `json:"files,omitempty"`
+	Status      string     `json:"status"`         // pending, in_progress, done, blocked, skipped
+	Note        string     `json:"note,omitempty"` // why it is blocked, what was done differently
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
 }
 
 // =============================================================================
@@ -138,6 +220,62 @@ type Edge struct {
 	Relation string `json:"relation"` // affects, warns, follows, supersedes, related_to, belongs_to
 }
 
+// GraphSnapshot records the shape of the knowledge graph at one reindex
+type GraphSnapshot struct {
+	TakenAt   time.Time      `json:"taken_at"`
+	Commit    string         `json:"commit,omitempty"`
+	Edges     int            `json:"edges"`
+	Relations map[string]int `json:"relations"`          // edge count per relation
+	Coupling  map[string]int `json:"coupling,omitempty"` // "from -> to": import edges between services or directories
+}
+
+// GraphHistory is the list of graph snapshots, oldest first
+type GraphHistory struct {
+	Snapshots []GraphSnapshot `json:"snapshots"`
+}
+
+// IndexCapReport records what the last index run left out to stay within
+// the index caps
+type IndexCapReport struct {
+	RunAt         time.Time `json:"run_at"` // when the last full index or reindex started
+	MaxFiles      int       `json:"max_files"`
+	MaxChunkRows  int       `json:"max_chunk_rows"`
+	MaxVectorRows int       `json:"max_vector_rows"`
+
+	FilesFound    int            `json:"files_found"`
+	FilesIndexed  int            `json:"files_indexed"`
+	FilesSkipped  int            `json:"files_skipped"`
+	SkippedByKind map[string]int `json:"skipped_by_kind,omitempty"` // source, docs, config
+	SkippedDirs   map[string]int `json:"skipped_dirs,omitempty"`    // top directories -> files skipped
+	SkippedSample []string       `json:"skipped_sample,omitempty"`  // a few skipped paths
+	TooLarge      int            `json:"too_large,omitempty"`       // files over index.max_file_size
+	FailedFiles   int            `json:"failed_files,omitempty"`    // files that could not be indexed
+
+	ChunkRows          int `json:"chunk_rows"`
+	FilesWithoutChunks int `json:"files_without_chunks,omitempty"` // indexed, but not searchable by content
+	VectorRows         int `json:"vector_rows,omitempty"`
+	VectorsSkipped     int `json:"vectors_skipped,omitempty"`
+	DiscoverSkipped    int `json:"discover_skipped,omitempty"` // new files auto-discovery left out since the run
+}
+
+// Degraded reports whether anything was left out
+func (r *IndexCapReport) Degraded() bool {
+	return r.FilesSkipped > 0 || r.FilesWithoutChunks > 0 || r.VectorsSkipped > 0 || r.DiscoverSkipped > 0
+}
+
+// SummaryCandidate is an indexed file whose summary the indexer generated,
+// queued for an agent to write a proper one
+type SummaryCandidate struct {
+	Path      string `json:"path"`
+	Summary   string `json:"summary"`
+	Reason    string `json:"reason"` // placeholder or heuristic
+	Language  string `json:"language,omitempty"`
+	Exports   int    `json:"exports"`
+	Importers int    `json:"importers"` // files importing it
+	Accesses  int    `json:"accesses"`  // sessions that worked with it
+	Score     int    `json:"score"`
+}
+
 // =============================================================================
 // EVOLUTION TIMELINE TYPES
 // =============================================================================
@@ -205,6 +343,7 @@ type Project struct {
 	Name         string       `json:"name"`
 	Description  string       `json:"description,omitempty"`
 	Languages    []string     `json:"languages,omitempty"`
+	Frameworks   []string     `json:"frameworks,omitempty"` // detected from package manifests, kept current by the worker
 	Architecture *Architecture `json:"architecture,omitempty"`
 	Goals        []Goal       `json:"goals,omitempty"`
 	TeamMindset  TeamMindset  `json:"team_mindset,omitempty"`
@@ -238,12 +377,97 @@ type Resource struct {
 
 // Config represents TeamContext configuration
 type Config struct {
-	Name        string       `json:"name"`
-	Version     string       `json:"version"`
-	CreatedAt   time.Time    `json:"created_at"`
-	Index       IndexConfig  `json:"index,omitempty"`
-	Server      ServerConfig `json:"server,omitempty"`
-	LinkedRepos []string     `json:"linked_repos,omitempty"` // sibling repo paths for cross-repo activity
+	Name           string              `json:"name"`
+	Version        string              `json:"version"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Index          IndexConfig         `json:"index,omitempty"`
+	Server         ServerConfig        `json:"server,omitempty"`
+	Worker         WorkerSettings      `json:"worker,omitempty"`
+	Search         SearchConfig        `json:"search,omitempty"`
+	LinkedRepos    []string            `json:"linked_repos,omitempty"` // sibling repo paths for cross-repo activity
+	Sandbox        SandboxConfig       `json:"sandbox,omitempty"`
+	Issues         IssueConfig         `json:"issues,omitempty"`
+	Correlations   CorrelationConfig   `json:"correlations,omitempty"`
+	Blueprint      BlueprintConfig     `json:"blueprint,omitempty"`
+	DocsCoverage   DocsCoverageConfig  `json:"docs_coverage,omitempty"`
+	FeatureFlags   FeatureFlagConfig   `json:"feature_flags,omitempty"`
+	Share          ShareConfig         `json:"share,omitempty"`
+	SensitivePaths []SensitivePath     `json:"sensitive_paths,omitempty"`
+	Glossary       map[string][]string `json:"glossary,omitempty"` // domain term -> aliases used in code, e.g. "rma": ["return", "return-request"]
+}
+
+// SensitivePath marks a directory (payments, auth) where changes need extra
+// care. Blueprints, get_context and check_compliance that touch files under
+// it always include its warnings, reviewers and checklist items.
+type SensitivePath struct {
+	Path      string   `json:"path"`                // project-relative directory; * matches one segment (services/*/auth)
+	Reason    string   `json:"reason,omitempty"`    // why it is sensitive, e.g. "PCI scope"
+	Warnings  []string `json:"warnings,omitempty"`  // mandatory warnings
+	Reviewers []string `json:"reviewers,omitempty"` // required reviewers: people, emails or teams
+	Checklist []string `json:"checklist,omitempty"` // extra checklist items
+}
+
+// CorrelationConfig tunes how co-change correlations are mined from git
+// history, for repos whose commit style (squash merges, tiny commits, bulk
+// refactors) makes the defaults too strict or too noisy. Zero values use
+// the defaults.
+type CorrelationConfig struct {
+	MinCoChanges   int     `json:"min_co_changes,omitempty"`  // commits a pair must share (default 9)
+	MinCorrelation float64 `json:"min_correlation,omitempty"` // confidence floor: co-changes / commits of the less-changed file (default 0.5)
+	WindowDays     int     `json:"window_days,omitempty"`     // only mine commits from the last N days (default: all analyzed history)
+	MaxResults     int     `json:"max_results,omitempty"`     // correlations kept (default 50)
+
+	// Paths overrides the thresholds for files under a project-relative
+	// directory; unset fields inherit the values above
+	Paths map[string]CorrelationConfig `json:"paths,omitempty"`
+}
+
+// BlueprintConfig tunes how much a blueprint quotes from the codebase:
+// large teams want more examples for complex modules, tiny services fewer.
+// Zero values use the defaults; get_blueprint parameters override them.
+type BlueprintConfig struct {
+	MaxExamples       int `json:"max_examples,omitempty"`         // example files (default 3, at most 10)
+	MaxImportsPerType int `json:"max_imports_per_type,omitempty"` // imports per file type (default 5, at most 20)
+}
+
+// DocsCoverageConfig sets the share of public exports that must have doc
+// comments, for CI to enforce with teamcontext docs-coverage --strict
+type DocsCoverageConfig struct {
+	Min   float64            `json:"min,omitempty"`   // percent, across everything scanned
+	Paths map[string]float64 `json:"paths,omitempty"` // percent per project-relative directory, for critical areas
+}
+
+// FeatureFlagConfig names the files that define the project's feature
+// flags, for list_feature_flags to check flag keys read in code against.
+// flags.json, feature-flags.yaml, flags.ts and the like are found without it.
+type FeatureFlagConfig struct {
+	Sources []string `json:"sources,omitempty"` // project-relative flag definition files (JSON, YAML or a TS/JS object)
+}
+
+// ShareConfig sets where teamcontext share --upload publishes artifacts and
+// how often. The upload token comes from TEAMCONTEXT_SHARE_TOKEN, never from
+// this git-tracked file.
+type ShareConfig struct {
+	UploadURL  string `json:"upload_url,omitempty"`   // HTTP endpoint artifacts are PUT to; {name} is replaced by the file name, else it is appended
+	UploadDir  string `json:"upload_dir,omitempty"`   // directory artifacts are copied to instead, e.g. a mounted docs site
+	PublicURL  string `json:"public_url,omitempty"`   // base URL uploaded artifacts are served from, for the printed link
+	MaxPerHour int    `json:"max_per_hour,omitempty"` // uploads allowed per hour, default 10
+}
+
+// IssueConfig configures the issue tracker. Credentials come from the
+// environment (JIRA_EMAIL and JIRA_API_TOKEN, or GITHUB_TOKEN), never from
+// this git-tracked file.
+type IssueConfig struct {
+	Provider string `json:"provider,omitempty"` // jira or github
+	BaseURL  string `json:"base_url,omitempty"` // Jira site (https://acme.atlassian.net) or GitHub Enterprise API URL
+	Repo     string `json:"repo,omitempty"`     // default GitHub owner/repo for bare #87 keys
+}
+
+// SandboxConfig restricts which paths file-reading tools may open. Reads are
+// allowed under the project root, linked repos and AllowPaths.
+type SandboxConfig struct {
+	AllowPaths []string `json:"allow_paths,omitempty"` // extra readable directories, absolute or project-relative
+	Disabled   bool     `json:"disabled,omitempty"`    // allow reads anywhere (trusted local setups only)
 }
 
 // IndexConfig represents indexing configuration
@@ -251,6 +475,19 @@ type IndexConfig struct {
 	Exclude    []string `json:"exclude,omitempty"`    // Patterns to exclude
 	Include    []string `json:"include,omitempty"`    // Patterns to include
 	MaxFileSize int64   `json:"max_file_size,omitempty"` // Max file size in bytes
+	TombstoneRetentionDays int `json:"tombstone_retention_days,omitempty"` // Days to keep deleted-file tombstones (default 30)
+	ConsistencyCheck bool `json:"consistency_check,omitempty"` // Periodically repair drift between the JSON store and SQLite
+
+	// Caps that keep huge repos indexable. Past them, only the highest
+	// priority files are indexed (source over docs over config, recently
+	// active directories first) and index_status reports the rest.
+	MaxFiles      int `json:"max_files,omitempty"`       // files indexed (default 20000)
+	MaxChunkRows  int `json:"max_chunk_rows,omitempty"`  // code search chunks stored (default 250000)
+	MaxVectorRows int `json:"max_vector_rows,omitempty"` // semantic vectors stored (default 5000)
+
+	// Skeleton parser: "auto" (default; tree-sitter when built with
+	// -tags treesitter), "regex" or "tree-sitter"
+	Parser string `json:"parser,omitempty"`
 }
 
 // ServerConfig represents server configuration
@@ -258,6 +495,36 @@ type ServerConfig struct {
 	MCPEnabled  bool   `json:"mcp_enabled"`
 	RESTEnabled bool   `json:"rest_enabled"`
 	RESTPort    int    `json:"rest_port,omitempty"`
+
+	// RequireConfirmation makes tools that archive, delete or overwrite
+	// knowledge run only with the confirm token of a matching dry run.
+	// Meant for servers shared by a team.
+	RequireConfirmation bool `json:"require_confirmation,omitempty"`
+
+	// Response size caps in bytes: MaxResponseBytes for every tool
+	// (default 64 KB), ResponseLimits per tool, e.g. "get_graph": 65536
+	MaxResponseBytes int            `json:"max_response_bytes,omitempty"`
+	ResponseLimits   map[string]int `json:"response_limits,omitempty"`
+}
+
+// WorkerSettings sets how often the background workers run. Zero values
+// use the defaults.
+type WorkerSettings struct {
+	GitWatchSeconds     int `json:"git_watch_seconds,omitempty"`     // check git for changes (default 30)
+	ReindexMinutes      int `json:"reindex_minutes,omitempty"`       // validate the index (default 5)
+	AutoDiscoverMinutes int `json:"auto_discover_minutes,omitempty"` // scan for new files (default 10)
+}
+
+// SearchConfig tunes how query, search and get_context rank results. Zero
+// values use the defaults.
+type SearchConfig struct {
+	// Weights scales the relevance of hits by the path that found them:
+	// keyword, semantic, graph or history (default 1 each)
+	Weights map[string]float64 `json:"weights,omitempty"`
+
+	// FeedbackWeight is how far rate_result feedback moves a score; 1 (the
+	// default) lets a fully boosted result score up to 2x
+	FeedbackWeight float64 `json:"feedback_weight,omitempty"`
 }
 
 // =============================================================================
@@ -281,6 +548,50 @@ type FeatureSummary struct {
 	LastAccessed time.Time `json:"last_accessed"`
 }
 
+// FeatureHandoff bundles what someone taking over a feature needs to
+// carry on without the person handing it over
+type FeatureHandoff struct {
+	Feature          string           `json:"feature"`
+	Description      string           `json:"description,omitempty"`
+	Status           string           `json:"status"`
+	Branch           string           `json:"branch,omitempty"`
+	CurrentState     string           `json:"current_state,omitempty"`
+	From             string           `json:"from,omitempty"` // who hands the feature over
+	To               string           `json:"to,omitempty"`
+	OpenItems        []HandoffItem    `json:"open_items,omitempty"`
+	PendingDecisions []HandoffItem    `json:"pending_decisions,omitempty"` // unreviewed insights
+	Decisions        []Decision       `json:"decisions,omitempty"`
+	Warnings         []Warning        `json:"warnings,omitempty"`
+	Conversations    []Conversation   `json:"recent_conversations,omitempty"` // newest first
+	Files            []HandoffFile    `json:"files,omitempty"`
+	Contacts         []HandoffContact `json:"contacts,omitempty"`
+	GeneratedAt      time.Time        `json:"generated_at"`
+}
+
+// HandoffItem is an open item or pending decision in a handoff
+type HandoffItem struct {
+	Kind   string `json:"kind"` // issue, insight, plan_step
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// HandoffFile is a file the feature touched, with its summary
+type HandoffFile struct {
+	Path    string `json:"path"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// HandoffContact is someone to ask about a feature: who worked on it, or an
+// expert in the code it touched
+type HandoffContact struct {
+	Name   string   `json:"name"`
+	Email  string   `json:"email,omitempty"`
+	Role   string   `json:"role"`            // owner, contributor, expert
+	Areas  []string `json:"areas,omitempty"` // directories an expert knows
+	Active bool     `json:"active,omitempty"`
+}
 // QueryResponse represents a response to a knowledge query
 type QueryResponse struct {
 	Answer        string            `json:"answer,omitempty"`
@@ -291,10 +602,43 @@ type QueryResponse struct {
 	Patterns      []Pattern         `json:"patterns,omitempty"`
 	GitExperts    []GitExpertHit    `json:"git_experts,omitempty"`
 	Conversations []Conversation    `json:"conversations,omitempty"`
+	Docs          []DocHit          `json:"docs,omitempty"`
+	Snippets      []CodeSnippet     `json:"snippets,omitempty"` // with include_code
+	Frameworks    []string          `json:"frameworks,omitempty"` // when the question is about the stack or names a detected framework
+	Merged        int               `json:"duplicates_merged,omitempty"` // hits found by more than one search path, listed once
+	History       []HistoryHit      `json:"history,omitempty"` // with deep: commits whose message matches the question
+	Deep          *DeepAnalysis     `json:"deep,omitempty"`
 	TokensUsed    int               `json:"tokens_used,omitempty"`
 	TokensSaved   int               `json:"tokens_saved,omitempty"`
 }
 
+// DeepAnalysis reports what a deep query searched within its latency budget
+type DeepAnalysis struct {
+	BudgetMs  int64    `json:"budget_ms"`
+	ElapsedMs int64    `json:"elapsed_ms"`
+	Steps     []string `json:"steps"`             // searches completed, in order
+	Skipped   []string `json:"skipped,omitempty"` // searches not run, or cut short, when the budget ran out
+}
+
+// HistoryHit is a commit whose message matches a deep query
+type HistoryHit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+	Files   []string  `json:"files,omitempty"`
+}
+
+// DocHit is a section of human-written documentation (README, docs/) matched by a query
+type DocHit struct {
+	Type      string `json:"type"` // always "doc"
+	Path      string `json:"path"`
+	Heading   string `json:"heading"` // heading breadcrumb, e.g. "Setup > Linux"
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Excerpt   string `json:"excerpt"`
+}
+
 // GitExpertHit represents a git expert matched by a query
 type GitExpertHit struct {
 	Name      string  `json:"name"`
@@ -302,25 +646,65 @@ type GitExpertHit struct {
 	Area      string  `json:"area"`
 	Ownership float64 `json:"ownership"`
 	Active    bool    `json:"active"`
+	Reason    string  `json:"reason,omitempty"` // why they were listed, e.g. "owns 62% of internal/auth"
+}
+
+// CoChangeHit is a file that usually changes together with a target file
+type CoChangeHit struct {
+	File        string  `json:"file"`
+	ChangesWith string  `json:"changes_with"` // the target file
+	Correlation float64 `json:"correlation"`
+}
+
+// ComplianceViolation is a decision, warning or pattern that code may break
+type ComplianceViolation struct {
+	Type      string `json:"type"`           // "decision", "warning", "pattern"
+	ID        string `json:"id"`             // ID of the violated item
+	Severity  string `json:"severity"`       // "blocker", "warning", "info"
+	Message   string `json:"message"`        // What was violated
+	Reference string `json:"reference"`      // The original decision/pattern text
+	File      string `json:"file,omitempty"` // Set when several files were checked
+}
+
+// DriftFinding is a place where the code has moved away from what the
+// recorded knowledge or architecture says
+type DriftFinding struct {
+	Kind   string `json:"kind"` // deleted_file, undeclared_dependency
+	File   string `json:"file"`
+	ID     string `json:"id,omitempty"` // decision or warning that refers to the file
+	Detail string `json:"detail"`
 }
 
 // Source represents a source reference in a query response
 type Source struct {
-	Type      string  `json:"type"` // decision, warning, file, conversation
-	ID        string  `json:"id"`
-	Relevance float64 `json:"relevance,omitempty"` // 0-1
+	Type      string   `json:"type"` // decision, warning, file, conversation
+	ID        string   `json:"id"`
+	Relevance float64  `json:"relevance,omitempty"`  // 0-1
+	MatchedBy []string `json:"matched_by,omitempty"` // search paths that found it: keyword, semantic, graph, ...
+	Reasons   []string `json:"reasons,omitempty"`    // why it matched, e.g. "matched tags: auth, jwt"
 }
 
 // ContextResponse represents relevant context for an intent
 type ContextResponse struct {
-	Intent       string        `json:"intent"`
-	Decisions    []Decision    `json:"decisions,omitempty"`
-	Warnings     []Warning     `json:"warnings,omitempty"`
-	Patterns     []Pattern     `json:"patterns,omitempty"`
-	Files        []string      `json:"files,omitempty"` // Recommended files to load
-	Suggestions  []string      `json:"suggestions,omitempty"`
-	TokenBudget  *TokenBudget  `json:"token_budget,omitempty"`
-	GitExperts   []GitExpertHit `json:"git_experts,omitempty"`
+	Intent            string          `json:"intent"`
+	Decisions         []Decision      `json:"decisions,omitempty"`
+	Warnings          []Warning       `json:"warnings,omitempty"`
+	Patterns          []Pattern       `json:"patterns,omitempty"`
+	Files             []string        `json:"files,omitempty"` // Recommended files to load
+	Suggestions       []string        `json:"suggestions,omitempty"`
+	TokenBudget       *TokenBudget    `json:"token_budget,omitempty"`
+	GitExperts        []GitExpertHit  `json:"git_experts,omitempty"`
+	Sensitive         []SensitivePath `json:"sensitive,omitempty"` // policies covering the target files; always included
+	RequiredReviewers []string        `json:"required_reviewers,omitempty"`
+	Merged            int             `json:"duplicates_merged,omitempty"` // knowledge found by more than one search path, listed once
+
+	// Persona-specific sections (see get_context's persona parameter)
+	Persona      string                `json:"persona,omitempty"`
+	Snippets     []CodeSnippet         `json:"snippets,omitempty"`     // implementer
+	Compliance   []ComplianceViolation `json:"compliance,omitempty"`   // reviewer
+	Correlations []CoChangeHit         `json:"correlations,omitempty"` // reviewer
+	Architecture *Architecture         `json:"architecture,omitempty"` // architect
+	Drift        []DriftFinding        `json:"drift,omitempty"`        // architect
 }
 
 // TokenBudget tracks context loading token usage
@@ -351,11 +735,12 @@ type CodeMapNode struct {
 
 // DependencyNode represents a node in the dependency tree
 type DependencyNode struct {
-	Path     string           `json:"path"`
-	Summary  string           `json:"summary,omitempty"`
-	Language string           `json:"language,omitempty"`
-	Depth    int              `json:"depth"`
-	Children []DependencyNode `json:"children,omitempty"`
+	Path        string           `json:"path"`
+	Summary     string           `json:"summary,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Depth       int              `json:"depth"`
+	BuildTarget string           `json:"build_target,omitempty"` // set when the edge comes from a BUILD file
+	Children    []DependencyNode `json:"children,omitempty"`
 }
 
 // FlowNode represents a node in a flow trace
@@ -386,6 +771,49 @@ type CodeSkeleton struct {
 	Constants  []ConstDef      `json:"constants,omitempty"`
 	LineCount  int             `json:"line_count"`
 	SkeletonLines int          `json:"skeleton_lines"` // Lines in skeleton vs original
+	Generated  bool            `json:"generated,omitempty"` // generated code, detected from its header or path
+	Counts     *SkeletonCounts `json:"counts,omitempty"`    // set when the skeleton was compacted to top-level exports
+	Cells      []CellOutline   `json:"cells,omitempty"`     // notebooks: what each cell imports and defines
+	Component  *ComponentDef   `json:"component,omitempty"` // Vue and Svelte single-file components
+}
+
+// ComponentDef is the component a Vue or Svelte single-file component
+// defines. The skeleton's functions and types come from its script blocks,
+// with lines counted in the whole file.
+type ComponentDef struct {
+	Name      string        `json:"name"`
+	Line      int           `json:"line"`
+	Framework string        `json:"framework"`       // vue or svelte
+	Script    string        `json:"script"`          // typescript or javascript
+	Setup     bool          `json:"setup,omitempty"` // Vue <script setup>
+	Props     []PropertyDef `json:"props,omitempty"`
+	Emits     []string      `json:"emits,omitempty"` // Vue events
+}
+
+// CellOutline is one notebook cell in a skeleton: what a code cell imports
+// and defines, or the heading and first sentence of a markdown cell. Lines refer to the
+// notebook's script form, where code cells follow each other.
+type CellOutline struct {
+	Index   int      `json:"index"` // 1-based, counting every cell
+	Kind    string   `json:"kind"`  // code or markdown
+	Line    int      `json:"line,omitempty"`
+	Lines   int      `json:"lines,omitempty"`
+	Title   string   `json:"title,omitempty"`
+	Summary string   `json:"summary,omitempty"` // markdown: first sentence of prose
+	Imports []string `json:"imports,omitempty"`
+	Defines []string `json:"defines,omitempty"` // functions and classes
+}
+
+// SkeletonCounts are the sizes of a full skeleton, kept when it is compacted
+type SkeletonCounts struct {
+	Classes    int `json:"classes"`
+	Methods    int `json:"methods"`
+	Properties int `json:"properties"`
+	Functions  int `json:"functions"`
+	Interfaces int `json:"interfaces"`
+	Types      int `json:"types"`
+	Enums      int `json:"enums"`
+	Constants  int `json:"constants"`
 }
 
 // ClassSkeleton represents a class without method bodies
@@ -399,6 +827,8 @@ type ClassSkeleton struct {
 	Properties []PropertyDef `json:"properties,omitempty"`
 	Methods    []FunctionSig `json:"methods,omitempty"`
 	Constructor *FunctionSig `json:"constructor,omitempty"`
+	Decorators    []string            `json:"decorators,omitempty"`     // e.g. @Controller, @Injectable
+	DecoratorArgs map[string][]string `json:"decorator_args,omitempty"` // arguments of the decorators called with any, as source text: "@Controller": ["'users'"]
 }
 
 // FunctionSig represents a function/method signature
@@ -412,7 +842,9 @@ type FunctionSig struct {
 	IsPrivate  bool        `json:"is_private,omitempty"`
 	IsExported bool        `json:"is_exported,omitempty"`
 	Decorators []string    `json:"decorators,omitempty"`
+	DecoratorArgs map[string][]string `json:"decorator_args,omitempty"` // like ClassSkeleton's: "@Get": ["':id'"], "@UseGuards": ["AuthGuard", "RolesGuard"]
 	DocComment string      `json:"doc_comment,omitempty"`
+	Calls      []string    `json:"calls,omitempty"` // functions of the same file it calls; methods as Class.method
 }
 
 // ParamDef represents a function parameter
@@ -430,6 +862,7 @@ type PropertyDef struct {
 	IsPrivate  bool   `json:"is_private,omitempty"`
 	IsReadonly bool   `json:"is_readonly,omitempty"`
 	IsStatic   bool   `json:"is_static,omitempty"`
+	Tag        string `json:"tag,omitempty"` // Go struct tag, Rust field attributes or protobuf field number, e.g. json:"id"
 }
 
 // TypeDef represents a TypeScript interface or type alias
@@ -544,8 +977,27 @@ type Stats struct {
 	ActiveFeatures  int `json:"active_features"`
 	ArchivedFeatures int `json:"archived_features"`
 	Conversations   int `json:"conversations"`
+
+	Contributors []AuthorStats `json:"contributors,omitempty"` // Most knowledge first
+	AgentShare   float64       `json:"agent_share"`            // Fraction of contributed knowledge from bots, agents or no one
+}
+
+// AuthorStats counts the knowledge one author contributed. Author is
+// UnattributedAuthor for knowledge recorded without one.
+type AuthorStats struct {
+	Author        string `json:"author"`
+	Decisions     int    `json:"decisions"`
+	Warnings      int    `json:"warnings"`
+	Insights      int    `json:"insights"`
+	FileSummaries int    `json:"file_summaries"`
+	Conversations int    `json:"conversations"`
+	Total         int    `json:"total"`
+	Agent         bool   `json:"agent,omitempty"` // A bot or agent account, or unattributed
 }
 
+// UnattributedAuthor stands in for the author of knowledge recorded without one
+const UnattributedAuthor = "(unattributed)"
+
 // =============================================================================
 // CONVERSATION HOOKS (Auto-delegation to AI)
 // =============================================================================
@@ -569,6 +1021,8 @@ const (
 	HookActionAddWarning       = "add_warning"            // Record a discovered pitfall
 	HookActionIndexFile        = "index_file"             // Index a modified/new file
 	HookActionUpdateFeature    = "update_feature"         // Update feature progress
+	HookActionArchiveFeature   = "archive_feature"        // Archive a feature whose branch is gone
+	HookActionRecallFeature    = "recall_feature"         // Recall an archived feature whose branch is back
 	HookActionNotifyUser       = "notify_user"            // Prompt user about something
 )
 
@@ -579,6 +1033,60 @@ const (
 	HookPriorityOptional  = "optional"  // Low priority, user can skip
 )
 
+// PendingHook is a hook raised outside an MCP session (for example by the
+// post-commit git hook) and delivered with the next tool response.
+type PendingHook struct {
+	ConversationHook
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FeatureTokenSpend is the estimated agent context consumed by tool
+// responses while a feature was active.
+type FeatureTokenSpend struct {
+	Feature     string                     `json:"feature"`
+	ToolCalls   int                        `json:"tool_calls"`
+	TokensUsed  int                        `json:"tokens_used"`
+	TokensSaved int                        `json:"tokens_saved"`
+	ByTool      map[string]*ToolTokenSpend `json:"by_tool"`
+	ByArea      map[string]int             `json:"by_area"` // tokens used per directory the calls targeted
+	FirstSeen   time.Time                  `json:"first_seen"`
+	LastSeen    time.Time                  `json:"last_seen"`
+}
+
+// ToolTokenSpend is the token spend of one tool within a feature
+type ToolTokenSpend struct {
+	Calls       int `json:"calls"`
+	TokensUsed  int `json:"tokens_used"`
+	TokensSaved int `json:"tokens_saved"`
+}
+
+// SearchFeedback is an agent's judgment of one search result for a query.
+// Feedback is shared with the team and used to boost or demote the result
+// for similar queries.
+type SearchFeedback struct {
+	ID        string    `json:"id"`
+	Query     string    `json:"query"`
+	DocType   string    `json:"doc_type"` // file, decision, warning, pattern, doc, conversation
+	DocID     string    `json:"doc_id"`   // ID, or path for files and docs
+	Helpful   bool      `json:"helpful"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Issue is a ticket in an external tracker (Jira, GitHub Issues) linked
+// from decisions, warnings or features. Title and status are cached from
+// the tracker's API.
+type Issue struct {
+	Key       string    `json:"key"`      // "PAY-142" for Jira, "owner/repo#87" for GitHub
+	Provider  string    `json:"provider"` // jira, github
+	URL       string    `json:"url,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Status    string    `json:"status,omitempty"` // tracker status, e.g. "In Progress", "open", "closed"
+	Assignee  string    `json:"assignee,omitempty"`
+	FetchedAt time.Time `json:"fetched_at,omitempty"` // zero when the tracker couldn't be reached
+}
+
 // HookableResponse is a wrapper that tool handlers can use to return hooks
 type HookableResponse struct {
 	Data  interface{}        `json:"data"`